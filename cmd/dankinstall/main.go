@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/distros"
 	"github.com/AvengeMedia/danklinux/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -11,6 +16,35 @@ import (
 var Version = "dev"
 
 func main() {
+	bootstrap := flag.Bool("bootstrap", false, "Install packages from inside an arch-chroot and defer session setup to first login")
+	bootstrapUser := flag.String("user", "", "User to install for in bootstrap mode")
+	wmName := flag.String("wm", "niri", "Window manager for bootstrap mode (niri|hyprland)")
+	terminalName := flag.String("terminal", "ghostty", "Terminal for bootstrap mode (ghostty|kitty|alacritty)")
+	firstLogin := flag.Bool("first-login", false, "Finish a bootstrap install (run by dms-first-login.service)")
+	revertSession := flag.Bool("revert-session", false, "Undo the login session, shell and migration changes recorded in the install manifest")
+	flag.Parse()
+
+	switch {
+	case *bootstrap:
+		if err := runBootstrap(*bootstrapUser, *wmName, *terminalName); err != nil {
+			fmt.Fprintf(os.Stderr, "Bootstrap failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case *firstLogin:
+		if err := runFirstLogin(); err != nil {
+			fmt.Fprintf(os.Stderr, "First login setup failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case *revertSession:
+		if err := runRevertSession(); err != nil {
+			fmt.Fprintf(os.Stderr, "Revert failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	model := tui.NewModel(Version)
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
@@ -18,3 +52,139 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func runBootstrap(username, wmName, terminalName string) error {
+	if username == "" {
+		return fmt.Errorf("--user is required")
+	}
+	wm, err := distros.ParseWindowManager(wmName)
+	if err != nil {
+		return err
+	}
+	terminal, err := distros.ParseTerminal(terminalName)
+	if err != nil {
+		return err
+	}
+
+	osInfo, err := distros.GetOSInfo()
+	if err != nil {
+		return err
+	}
+	if distros.Registry[osInfo.Distribution.ID].Family != distros.FamilyArch {
+		return fmt.Errorf("bootstrap mode only supports Arch-based systems, found %s", osInfo.Distribution.ID)
+	}
+	if !distros.IsChroot() {
+		fmt.Println("Warning: not running inside a chroot, continuing anyway")
+	}
+
+	logChan := make(chan string, 100)
+	progressChan := make(chan distros.InstallProgressMsg, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case line, ok := <-logChan:
+				if !ok {
+					return
+				}
+				fmt.Println(line)
+			case msg := <-progressChan:
+				if msg.LogOutput != "" {
+					fmt.Printf("[%3.0f%%] %s\n", msg.Progress*100, msg.LogOutput)
+				} else {
+					fmt.Printf("[%3.0f%%] %s\n", msg.Progress*100, msg.Step)
+				}
+			}
+		}
+	}()
+
+	distro, err := distros.NewDistribution(osInfo.Distribution.ID, logChan)
+	if err != nil {
+		return err
+	}
+	arch, ok := distro.(*distros.ArchDistribution)
+	if !ok {
+		return fmt.Errorf("bootstrap mode is not available for %s", osInfo.Distribution.ID)
+	}
+
+	err = arch.Bootstrap(context.Background(), distros.BootstrapOptions{
+		User:          username,
+		WindowManager: wm,
+		Terminal:      terminal,
+	}, progressChan)
+	close(logChan)
+	<-done
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("DMS packages installed. Configuration will be deployed when %s first logs in.\n", username)
+	return nil
+}
+
+func runFirstLogin() error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	statePath := distros.FirstLoginStatePath(homeDir)
+
+	wm, terminal, err := distros.ReadFirstLoginState(statePath)
+	if err != nil {
+		return err
+	}
+
+	logChan := make(chan string, 100)
+	go func() {
+		for line := range logChan {
+			fmt.Println(line)
+		}
+	}()
+	defer close(logChan)
+
+	deployer := config.NewConfigDeployer(logChan)
+	if _, err := deployer.DeployConfigurationsWithTerminal(context.Background(), wm, terminal); err != nil {
+		return err
+	}
+
+	return os.Remove(statePath)
+}
+
+// runRevertSession undoes the optional session steps. sudo is primed on the
+// terminal first so the individual steps do not each prompt.
+func runRevertSession() error {
+	if os.Geteuid() != 0 {
+		sudo := exec.Command("sudo", "-v")
+		sudo.Stdin, sudo.Stdout, sudo.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := sudo.Run(); err != nil {
+			return fmt.Errorf("sudo authentication failed: %w", err)
+		}
+	}
+
+	logChan := make(chan string, 100)
+	go func() {
+		for line := range logChan {
+			fmt.Println(line)
+		}
+	}()
+	defer close(logChan)
+
+	report, err := distros.NewBaseDistribution(logChan).RevertInstallSteps(context.Background(), "")
+	for _, line := range report.Applied {
+		fmt.Println("✓ " + line)
+	}
+	for _, line := range report.Warnings {
+		fmt.Println("⚠ " + line)
+	}
+	if err != nil {
+		return err
+	}
+	if len(report.Applied) == 0 && len(report.Warnings) == 0 {
+		fmt.Println("Nothing to revert")
+	}
+	if len(report.Warnings) > 0 {
+		return fmt.Errorf("%d step(s) could not be reverted and were kept in the manifest", len(report.Warnings))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/backup"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export or restore DMS settings",
+	Long:  "Bundle DMS settings, session state, the generated theme and installer-managed terminal colors into a single archive for moving to another machine",
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create [archive]",
+	Short: "Create a settings archive",
+	Long:  "Write all DMS settings to a .tar.gz archive (defaults to dms-backup-<timestamp>.tar.gz in the current directory)",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runBackupCreate,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "Restore a settings archive",
+	Long:  "Restore DMS settings from an archive. Existing files that differ are kept as <file>.backup.<timestamp>",
+	Args:  cobra.ExactArgs(1),
+	Run:   runBackupRestore,
+}
+
+func init() {
+	backupCmd.AddCommand(backupCreateCmd, backupRestoreCmd)
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) {
+	dest := backup.DefaultArchiveName(time.Now())
+	if len(args) > 0 {
+		dest = args[0]
+	}
+
+	manifest, err := backup.NewArchiver().CreateFile(dest)
+	if err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+
+	fmt.Printf("Wrote %d files to %s\n", len(manifest.Files), dest)
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) {
+	result, err := backup.NewArchiver().RestoreFile(args[0])
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+
+	fmt.Printf("Restored %d files from backup created %s", len(result.Restored), result.Manifest.CreatedAt.Local().Format(time.RFC1123))
+	if result.Manifest.Hostname != "" {
+		fmt.Printf(" on %s", result.Manifest.Hostname)
+	}
+	fmt.Println()
+	for _, path := range result.Backups {
+		fmt.Printf("  kept previous copy: %s\n", path)
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("Skipped %d unrecognized entries\n", len(result.Skipped))
+	}
+	if len(result.Restored) > 0 {
+		fmt.Println("Restart the shell with 'dms restart' to apply restored settings.")
+	}
+}
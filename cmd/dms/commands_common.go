@@ -364,7 +364,16 @@ func getCommonCommands() []*cobra.Command {
 		pluginsCmd,
 		dank16Cmd,
 		brightnessCmd,
+		doctorCmd,
+		printCmd,
+		backupCmd,
+		crashReportCmd,
+		debugCmd,
+		configCmd,
+		scratchpadCmd,
+		shellInitCmd,
 		hyprlandCmd,
 		greeterCmd,
+		netacctCmd,
 	}
 }
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/daemonconfig"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/pkg/dmsclient"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change daemon options",
+	Long:  "View and change the dms server options stored in daemon.toml (scan intervals, module toggles, socket directory, night light schedule)",
+	// The daemon config does not need the shell config to be found
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all daemon options",
+	Long:  "List every daemon option with its current value; options set in daemon.toml are marked with *",
+	Args:  cobra.NoArgs,
+	Run:   runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get <key>",
+	Short:             "Show a daemon option",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	Run:               runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <key> [value]",
+	Short:             "Change a daemon option",
+	Long:              "Validate and write a daemon option to daemon.toml. Without a value the option is described and the value is asked for. Use --reload to apply it to the running server.",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeConfigSet,
+	Run:               runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Reset a daemon option to its default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	Run:               runConfigUnset,
+}
+
+var configReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Make the running server re-read daemon.toml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := reloadDaemon(); err != nil {
+			log.Fatalf("Reload failed: %v", err)
+		}
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check deployed compositor and terminal configs",
+	Long:  "Check the Niri, Hyprland, Ghostty, Kitty and Alacritty configs written by the installer, and daemon.toml, for placeholders that were never filled in, duplicate key binds, invalid colors and missing includes. Exits with status 1 when a problem would break the session.",
+	Args:  cobra.NoArgs,
+	Run:   runConfigValidate,
+}
+
+func init() {
+	configSetCmd.Flags().BoolP("reload", "r", false, "Apply the change to the running server")
+	configUnsetCmd.Flags().BoolP("reload", "r", false, "Apply the change to the running server")
+
+	configCmd.AddCommand(configListCmd, configGetCmd, configSetCmd, configUnsetCmd, configReloadCmd, configValidateCmd)
+}
+
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []string
+	for _, opt := range daemonconfig.Options {
+		if strings.HasPrefix(opt.Key, toComplete) {
+			out = append(out, opt.Key+"\t"+opt.Description)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeConfigSet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return completeConfigKeys(cmd, args, toComplete)
+	case 1:
+		opt, ok := daemonconfig.Lookup(args[0])
+		if !ok {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if opt.Kind == daemonconfig.KindPath {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+		return opt.Suggestions(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func loadDaemonConfig() *daemonconfig.Config {
+	cfg, err := daemonconfig.Load()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return cfg
+}
+
+func lookupOption(key string) daemonconfig.Option {
+	opt, ok := daemonconfig.Lookup(key)
+	if !ok {
+		log.Fatalf("Unknown option %s (see dms config list)", key)
+	}
+	return opt
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	cfg := loadDaemonConfig()
+
+	fmt.Printf("%s\n\n", cfg.Path)
+	width := 0
+	for _, opt := range daemonconfig.Options {
+		width = max(width, len(opt.Key))
+	}
+
+	section := ""
+	for _, opt := range daemonconfig.Options {
+		table, _, _ := strings.Cut(opt.Key, ".")
+		if table != section {
+			if section != "" {
+				fmt.Println()
+			}
+			fmt.Printf("[%s]\n", table)
+			section = table
+		}
+		marker := " "
+		if cfg.IsSet(opt.Key) {
+			marker = "*"
+		}
+		value := opt.Format(cfg.Get(opt.Key))
+		if value == "" {
+			value = `""`
+		}
+		fmt.Printf("%s %-*s  %-10s %s\n", marker, width, opt.Key, value, restartNote(opt))
+	}
+
+	if len(cfg.Problems) > 0 {
+		fmt.Println("\nProblems in daemon.toml:")
+		for _, problem := range cfg.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatalf("Failed to find the home directory: %v", err)
+	}
+
+	report := config.NewValidator(home).ValidateDeployed()
+	for _, f := range report.Checked {
+		fmt.Printf("Checked %-16s %s\n", f.ConfigType, f.Path)
+	}
+	if len(report.Checked) == 0 {
+		fmt.Println("No deployed compositor or terminal configs found")
+	}
+
+	failed := report.HasErrors()
+	if len(report.Problems) > 0 {
+		fmt.Println()
+		for _, p := range report.Problems {
+			fmt.Println(p)
+		}
+	}
+
+	if cfg, err := daemonconfig.Load(); err != nil {
+		fmt.Printf("\n%v\n", err)
+		failed = true
+	} else if len(cfg.Problems) > 0 {
+		fmt.Printf("\nProblems in %s:\n", cfg.Path)
+		for _, problem := range cfg.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	if len(report.Problems) == 0 {
+		fmt.Println("\nNo problems found")
+	}
+}
+
+func restartNote(opt daemonconfig.Option) string {
+	if opt.HotReload {
+		return ""
+	}
+	return "(restart)"
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	opt := lookupOption(args[0])
+	cfg := loadDaemonConfig()
+	fmt.Println(opt.Format(cfg.Get(opt.Key)))
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	opt := lookupOption(args[0])
+
+	var value string
+	if len(args) == 2 {
+		value = args[1]
+	} else {
+		value = promptOptionValue(opt, loadDaemonConfig())
+	}
+
+	parsed, err := daemonconfig.Set(daemonconfig.Path(), opt.Key, value)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	fmt.Printf("%s = %s\n", opt.Key, opt.Format(parsed))
+	finishConfigChange(cmd, opt)
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	opt := lookupOption(args[0])
+
+	removed, err := daemonconfig.Unset(daemonconfig.Path(), opt.Key)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if !removed {
+		fmt.Printf("%s is not set\n", opt.Key)
+		return
+	}
+	fmt.Printf("%s reset to %s\n", opt.Key, opt.Format(opt.Default))
+	finishConfigChange(cmd, opt)
+}
+
+func finishConfigChange(cmd *cobra.Command, opt daemonconfig.Option) {
+	reload, _ := cmd.Flags().GetBool("reload")
+	switch {
+	case !opt.HotReload:
+		fmt.Println("Restart dms for this option to take effect.")
+	case reload:
+		if err := reloadDaemon(); err != nil {
+			log.Warnf("Saved, but the running server was not reloaded: %v", err)
+		}
+	default:
+		fmt.Println("Run 'dms config reload' to apply it to the running server.")
+	}
+}
+
+// promptOptionValue describes the option and reads a value from stdin
+func promptOptionValue(opt daemonconfig.Option, cfg *daemonconfig.Config) string {
+	fmt.Printf("%s\n", opt.Description)
+	fmt.Printf("  type:    %s\n", opt.Kind)
+	fmt.Printf("  current: %s\n", opt.Format(cfg.Get(opt.Key)))
+	fmt.Printf("  default: %s\n", opt.Format(opt.Default))
+	if suggestions := opt.Suggestions(); len(suggestions) > 0 {
+		fmt.Printf("  e.g.:    %s\n", strings.Join(suggestions, ", "))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("New value: ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("No value given")
+		}
+		line = strings.TrimSpace(line)
+		if _, parseErr := opt.Parse(line); parseErr != nil {
+			fmt.Println(parseErr)
+			if err != nil {
+				os.Exit(1)
+			}
+			continue
+		}
+		return line
+	}
+}
+
+func reloadDaemon() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := dialDaemon(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	result, err := client.ReloadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Applied) > 0 {
+		fmt.Printf("Applied: %s\n", strings.Join(result.Applied, ", "))
+	} else {
+		fmt.Println("No runtime options changed")
+	}
+	if len(result.RestartRequired) > 0 {
+		fmt.Printf("Needs a restart: %s\n", strings.Join(result.RestartRequired, ", "))
+	}
+	for _, problem := range result.Problems {
+		fmt.Printf("Problem: %s\n", problem)
+	}
+	return nil
+}
+
+// findDaemonSocket honours server.socket-dir, which dmsclient cannot know
+// about on its own
+func dialDaemon(ctx context.Context) (*dmsclient.Client, error) {
+	socket, err := findDaemonSocket()
+	if err != nil {
+		return nil, err
+	}
+	return dmsclient.Dial(ctx, socket)
+}
+
+func findDaemonSocket() (string, error) {
+	if os.Getenv("DMS_SOCKET") == "" {
+		cfg, err := daemonconfig.Load()
+		if err == nil {
+			if dir := cfg.String("server.socket-dir"); dir != "" {
+				return dmsclient.FindSocketIn(dir)
+			}
+		}
+	}
+	return dmsclient.FindSocket()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var crashReportCmd = &cobra.Command{
+	Use:   "crash-report [archive]",
+	Short: "Bundle crash logs for a bug report",
+	Long:  "Collect recorded server panics, the fatal crash log, system details and the recent dms.service journal into a .tar.gz (defaults to dms-crash-report-<timestamp>.tar.gz). Home directory, user and host names, IP and MAC addresses, SSIDs and email addresses are redacted.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runCrashReport,
+}
+
+func runCrashReport(cmd *cobra.Command, args []string) {
+	dest := fmt.Sprintf("dms-crash-report-%s.tar.gz", time.Now().Format("20060102-150405"))
+	if len(args) > 0 {
+		dest = args[0]
+	}
+
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", dest, err)
+	}
+
+	info, err := crash.WriteReport(f, Version)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		log.Fatalf("Crash report failed: %v", err)
+	}
+
+	fmt.Printf("Wrote %s (%d crash files", dest, info.CrashFiles)
+	if info.FatalLog {
+		fmt.Print(", fatal log")
+	}
+	if info.Journal {
+		fmt.Print(", journal")
+	}
+	fmt.Println(")")
+	if info.CrashFiles == 0 && !info.FatalLog {
+		fmt.Printf("No crashes were recorded in %s\n", crash.Dir())
+	}
+	fmt.Println("Review the archive before attaching it to a bug report.")
+}
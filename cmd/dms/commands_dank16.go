@@ -1,90 +1,768 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/dank16"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var dank16Cmd = &cobra.Command{
-	Use:   "dank16 <hex_color>",
+	Use:   "dank16 [hex_color]",
 	Short: "Generate Base16 color palettes",
-	Long:  "Generate Base16 color palettes from a color with support for various output formats",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Generate Base16 color palettes from a color (#rrggbb, #rgb, rgb() or a CSS color name), or from the dominant accent of a wallpaper or a random seed, with support for various output formats. --preset uses a bundled scheme such as nord with the same outputs. Several output flags with --out-dir write all of them from one palette.",
+	Args:  cobra.MaximumNArgs(1),
 	Run:   runDank16,
 }
 
+var dank16NearestCmd = &cobra.Command{
+	Use:   "nearest <hex_color>",
+	Short: "Find the closest well-known color scheme",
+	Long:  "Compare the generated palette against bundled schemes (Catppuccin, Gruvbox, Nord, Solarized, Tokyo Night) by CIEDE2000 distance",
+	Args:  cobra.ExactArgs(1),
+	Run:   runDank16Nearest,
+}
+
+var dank16VerifyCmd = &cobra.Command{
+	Use:   "verify <hex_color>",
+	Short: "Compare rendered theme output against golden images",
+	Long:  "Render the terminal and VSCode output for a palette into swatch images and compare them with goldens by CIEDE2000 ΔE, exiting 1 on any difference. Use --update to write the goldens.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runDank16Verify,
+}
+
+var dank16FormatsCmd = &cobra.Command{
+	Use:   "formats",
+	Short: "List the template output formats",
+	Long:  "List the formats --format accepts: the built-in terminal and compositor (niri, hyprland) templates and the *.tmpl text/templates in ~/.config/dms/templates, which are executed with the palette slots (.Colors), the named roles (.Red.Hex, .Accent.Hex, ...) and .IsLight",
+	Args:  cobra.NoArgs,
+	Run:   runDank16Formats,
+}
+
+var dank16ApplyCmd = &cobra.Command{
+	Use:   "apply <hex_color>",
+	Short: "Write terminal themes to their config locations",
+	Long:  "Generate the palette and write the kitty, Alacritty, Ghostty and foot themes where those terminals look for them (kitty/dank-theme.conf, alacritty/dank-theme.toml, ghostty/config-dankcolors, foot/dank-colors.ini), adding the include to kitty.conf, the Ghostty config and foot.ini when they lack it. Each file is replaced atomically, and previous contents are kept as <file>.backup.<timestamp>.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runDank16Apply,
+}
+
+var dank16PreviewCmd = &cobra.Command{
+	Use:   "preview <hex_color>",
+	Short: "Show the palette in the terminal",
+	Long:  "Generate the palette and draw it with 24-bit ANSI colors: the swatches, then each slot as text on the background with its Lc and WCAG contrast ratio, marking slots below the contrast target. Takes the same --light, --background, --contrast and --honor-* flags as dank16.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runDank16Preview,
+}
+
+var dank16TransitionCmd = &cobra.Command{
+	Use:   "transition <from_hex> <to_hex>",
+	Short: "Output the steps of an animated theme change",
+	Long:  "Generate the palettes for two colors and print, as JSON, the per-slot CIEDE2000 ΔE between them and intermediate palettes blended in OKLab, so the shell can fade between themes when the wallpaper changes",
+	Args:  cobra.ExactArgs(2),
+	Run:   runDank16Transition,
+}
+
 func init() {
-	dank16Cmd.Flags().Bool("light", false, "Generate light theme variant")
-	dank16Cmd.Flags().Bool("json", false, "Output in JSON format")
-	dank16Cmd.Flags().Bool("kitty", false, "Output in Kitty terminal format")
-	dank16Cmd.Flags().Bool("foot", false, "Output in Foot terminal format")
-	dank16Cmd.Flags().Bool("alacritty", false, "Output in Alacritty terminal format")
-	dank16Cmd.Flags().Bool("ghostty", false, "Output in Ghostty terminal format")
+	dank16Cmd.PersistentFlags().Bool("light", false, "Generate light theme variant")
+	dank16Cmd.Flags().Bool("lint", false, "Check the palette for contrast failures, hue collisions and saturation outliers; exits 1 on errors (with --json, print diagnostics as JSON)")
+	dank16Cmd.Flags().Bool("pair", false, "Output the dark and light variants as JSON, with matching hues so toggling the mode keeps colors recognisable")
+	addDank16OutputFlags(dank16Cmd.Flags())
+	dank16Cmd.Flags().String("from-wallpaper", "", "Seed the palette with the dominant accent of this image (PNG, JPEG, GIF or WebP)")
+	dank16Cmd.Flags().String("preset", "", fmt.Sprintf("Use a bundled scheme instead of generating one (%s; gruvbox, catppuccin and solarized pick the dark variant, or the light one with --light)", strings.Join(dank16.PresetNames(), ", ")))
+	dank16Cmd.Flags().Bool("random", false, "Seed the palette with a random but tasteful color, a new one each day unless --random-seed is given")
+	dank16Cmd.Flags().Int64("random-seed", 0, "With --random, the seed to reproduce a palette from (printed on stderr)")
 	dank16Cmd.Flags().String("vscode-enrich", "", "Enrich existing VSCode theme file with terminal colors")
-	dank16Cmd.Flags().String("background", "", "Custom background color")
-	dank16Cmd.Flags().String("contrast", "dps", "Contrast algorithm: dps (Delta Phi Star, default) or wcag")
+	dank16Cmd.Flags().Float64("vscode-min-lc", dank16.VSCodeMinContrast, "With --vscode-enrich, the Delta Phi Star Lc token colors must reach against the theme's editor.background (0 to leave them as they are)")
+	dank16Cmd.PersistentFlags().String("background", "", "Custom background color")
+	dank16Cmd.PersistentFlags().String("contrast", "dps", "Contrast algorithm: dps (Delta Phi Star, default), apca or wcag")
+	dank16Cmd.PersistentFlags().String("honor-primary", "", "Use this accent for the blue slots, and as the GTK, Qt, VSCode and compositor accent, instead of deriving it")
+	dank16Cmd.PersistentFlags().String("honor-secondary", "", "Use this accent for the magenta slots and background tint")
+	dank16Cmd.PersistentFlags().String("honor-tertiary", "", "Use this accent for the cyan slots and bright black tint")
+	dank16Cmd.PersistentFlags().Bool("no-cache", false, "Generate the palette even if it is cached under $XDG_CACHE_HOME/DankMaterialShell")
+
+	dank16NearestCmd.Flags().Bool("snap", false, "Output the closest scheme instead of the ranking, in the format the output flags pick (Ghostty by default)")
+	dank16NearestCmd.Flags().Int("limit", 3, "Number of matches to show")
+	addDank16OutputFlags(dank16NearestCmd.Flags())
+	dank16Cmd.AddCommand(dank16NearestCmd)
+
+	dank16VerifyCmd.Flags().String("goldens", "dank16-goldens", "Directory holding the golden PNGs")
+	dank16VerifyCmd.Flags().Bool("update", false, "Write the rendered images as the new goldens")
+	dank16VerifyCmd.Flags().Float64("threshold", 1.0, "Largest ΔE a pixel may drift before it fails")
+	dank16Cmd.AddCommand(dank16VerifyCmd)
+
+	dank16ApplyCmd.Flags().StringSlice("only", nil, "Write only these terminals (kitty, alacritty, ghostty, foot)")
+	dank16ApplyCmd.Flags().String("config-dir", "", "Config directory to write under (default $XDG_CONFIG_HOME or ~/.config)")
+	dank16Cmd.AddCommand(dank16ApplyCmd)
+
+	dank16TransitionCmd.Flags().Int("steps", 8, "Number of palettes to output, including both ends")
+	dank16Cmd.AddCommand(dank16TransitionCmd)
+	dank16Cmd.AddCommand(dank16FormatsCmd)
+	dank16Cmd.AddCommand(dank16PreviewCmd)
 }
 
-func runDank16(cmd *cobra.Command, args []string) {
-	primaryColor := args[0]
-	if !strings.HasPrefix(primaryColor, "#") {
-		primaryColor = "#" + primaryColor
+// addDank16OutputFlags registers the output format flags, shared by dank16
+// and nearest --snap
+func addDank16OutputFlags(flags *pflag.FlagSet) {
+	flags.Bool("json", false, "Output in JSON format")
+	flags.Bool("json-roles", false, "Output JSON with named roles (background, red, brightRed, accent, ...) in hex and rgb, and the Material 3 surface container ramp")
+	flags.Bool("kitty", false, "Output in Kitty terminal format")
+	flags.Bool("foot", false, "Output in Foot terminal format")
+	flags.Bool("alacritty", false, "Output in Alacritty terminal format")
+	flags.Bool("ghostty", false, "Output in Ghostty terminal format")
+	flags.Bool("wezterm", false, "Output a WezTerm Lua color scheme (save as ~/.config/wezterm/dank16.lua)")
+	flags.String("format", "", "Output with a template format, built in or from ~/.config/dms/templates (see dms dank16 formats)")
+	flags.Float64("min-contrast", 0, "For terminal output, lift text colors to this WCAG ratio against the background so a terminal minimum-contrast setting (kitty text_fg_override_threshold, Ghostty minimum-contrast) has nothing to adjust")
+	flags.Bool("no-terminal-contrast", false, "With --kitty or --ghostty (the default), also turn off the terminal's own minimum-contrast adjustment")
+	flags.Bool("gtk", false, "Output GTK3/GTK4 gtk.css")
+	flags.Bool("nvim", false, "Output a Neovim Lua colorscheme (save as ~/.config/nvim/colors/dank16.lua)")
+	flags.Bool("zed", false, "Output a Zed theme (save under ~/.config/zed/themes/)")
+	flags.Bool("emacs", false, "Output a doom-themes Emacs theme (save as ~/.config/doom/themes/doom-dank16-theme.el)")
+	flags.Bool("jetbrains", false, "Output a JetBrains IDE color scheme (.icls, import under Settings > Editor > Color Scheme)")
+	flags.Bool("dircolors", false, "Output a dircolors database for LS_COLORS (load with eval \"$(dircolors <file>)\")")
+	flags.String("shell", "", "Output LS_COLORS and shell colors to source from the rc file: fish (fish_color_*), zsh (zstyle, zsh-syntax-highlighting) or bash (prompt)")
+	flags.Bool("eza", false, "Output an eza theme (save as ~/.config/eza/theme.yml)")
+	flags.Bool("bat", false, "Output a bat .tmTheme (save under ~/.config/bat/themes/ and run bat cache --build)")
+	flags.Bool("delta", false, "Output a [delta] gitconfig section using the bat theme")
+	flags.Bool("base16-yaml", false, "Output a base16 scheme (base00–base0F) for flavours and tinted-theming builders")
+	flags.Bool("base24-yaml", false, "Output a base24 scheme (base00–base17)")
+	flags.Bool("rofi", false, "Output a rofi theme (save as ~/.config/rofi/themes/dank16.rasi)")
+	flags.Bool("fuzzel", false, "Output the [colors] section of fuzzel.ini")
+	flags.Bool("fzf", false, "Output an fzf --color option for FZF_DEFAULT_OPTS")
+	flags.Bool("wofi", false, "Output a wofi style.css")
+	flags.Bool("waybar", false, "Output a waybar style.css fragment (colors as @define-color dank_*)")
+	flags.Bool("p3", false, "Output CSS custom properties in sRGB hex with Display P3 overrides for wide-gamut screens")
+	flags.Float64("p3-boost", 1, "With --p3, scale accent chroma by this factor, mapped into the P3 gamut (1 keeps the sRGB look)")
+	flags.Bool("btop", false, "Output a btop theme (save under ~/.config/btop/themes/ and set color_theme)")
+	flags.Bool("htop", false, "Output the htoprc color settings matching the palette")
+	flags.Bool("discord", false, "Output a Vencord/Vesktop theme (save as dank16.theme.css in the themes folder)")
+	flags.Bool("spicetify", false, "Output a Spicetify color.ini (save under ~/.config/spicetify/Themes/Dank16/)")
+	flags.Bool("tmux", false, "Output a tmux.conf fragment (status bar, pane borders, messages)")
+	flags.Bool("qt", false, "Output a qt5ct/qt6ct color scheme")
+	flags.String("qt-dir", "", "Write qt5ct/qt6ct color schemes and a Kvantum theme under this config directory (e.g. ~/.config)")
+	flags.Bool("firefox", false, "Output a Firefox/Zen userChrome.css that sets the browser's color variables")
+	flags.Bool("firefox-theme", false, "Output a static theme manifest.json for Firefox (install without legacy stylesheets)")
+	flags.String("firefox-dir", "", "Write chrome and about: page stylesheets into this Firefox or Zen profile and import them from userChrome.css and userContent.css")
+	flags.Bool("gpl", false, "Output a GIMP/Inkscape palette (save under ~/.config/GIMP/<version>/palettes/ or ~/.config/inkscape/palettes/)")
+	flags.Bool("kpl", false, "Output a Krita palette, a zip archive to redirect into ~/.local/share/krita/palettes/dank16.kpl")
+	flags.Bool("vscode", false, "Output a VSCode color theme (save under an extension's themes/ folder)")
+	flags.String("out-dir", "", "Write every output format given (--kitty --gtk --vscode ...) into this directory instead of printing one")
+}
+
+// dank16Color parses a color given on the command line, exiting with the
+// reason when it isn't one
+func dank16Color(what, color string) string {
+	hex, err := dank16.NormalizeHex(color)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", what, err)
+	}
+	return hex
+}
+
+func dank16PaletteFromFlags(cmd *cobra.Command, primaryColor string) ([]string, dank16.PaletteOptions) {
+	primaryColor = dank16Color("color", primaryColor)
+	opts := dank16OptionsFromFlags(cmd)
+
+	var colors []string
+	var err error
+	if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+		colors, err = dank16.ParsePalette(primaryColor, opts)
+	} else {
+		colors, err = dank16.NewPaletteCache(dank16.DefaultPaletteCacheSize).Palette(primaryColor, opts)
 	}
+	if err != nil {
+		log.Fatalf("Invalid color: %v", err)
+	}
+	return colors, opts
+}
+
+// dank16PresetFromFlags loads a bundled scheme. With --light a family name
+// such as gruvbox picks its light variant.
+func dank16PresetFromFlags(cmd *cobra.Command, name string) ([]string, dank16.PaletteOptions) {
+	opts := dank16OptionsFromFlags(cmd)
+
+	scheme, err := dank16.LoadPreset(name)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if opts.IsLight && !scheme.IsLight {
+		scheme, err = dank16.LoadPreset(name + "-light")
+		if err != nil {
+			log.Fatalf("Preset %s has no light variant", name)
+		}
+	}
+	opts.IsLight = scheme.IsLight
+	return scheme.Colors, opts
+}
 
+func dank16OptionsFromFlags(cmd *cobra.Command) dank16.PaletteOptions {
 	isLight, _ := cmd.Flags().GetBool("light")
-	isJson, _ := cmd.Flags().GetBool("json")
-	isKitty, _ := cmd.Flags().GetBool("kitty")
-	isFoot, _ := cmd.Flags().GetBool("foot")
-	isAlacritty, _ := cmd.Flags().GetBool("alacritty")
-	isGhostty, _ := cmd.Flags().GetBool("ghostty")
-	vscodeEnrich, _ := cmd.Flags().GetString("vscode-enrich")
 	background, _ := cmd.Flags().GetString("background")
 	contrastAlgo, _ := cmd.Flags().GetString("contrast")
 
-	if background != "" && !strings.HasPrefix(background, "#") {
-		background = "#" + background
+	if background != "" {
+		background = dank16Color("--background color", background)
+	}
+
+	accents := make(map[string]string)
+	for _, flag := range []string{"honor-primary", "honor-secondary", "honor-tertiary"} {
+		accent, _ := cmd.Flags().GetString(flag)
+		if accent == "" {
+			continue
+		}
+		accents[flag] = dank16Color("--"+flag+" color", accent)
 	}
 
 	contrastAlgo = strings.ToLower(contrastAlgo)
-	if contrastAlgo != "dps" && contrastAlgo != "wcag" {
-		log.Fatalf("Invalid contrast algorithm: %s (must be 'dps' or 'wcag')", contrastAlgo)
+	if contrastAlgo != "dps" && contrastAlgo != "apca" && contrastAlgo != "wcag" {
+		log.Fatalf("Invalid contrast algorithm: %s (must be 'dps', 'apca' or 'wcag')", contrastAlgo)
 	}
 
 	opts := dank16.PaletteOptions{
-		IsLight:    isLight,
-		Background: background,
-		UseDPS:     contrastAlgo == "dps",
+		IsLight:        isLight,
+		Background:     background,
+		UseDPS:         contrastAlgo == "dps",
+		UseAPCA:        contrastAlgo == "apca",
+		HonorPrimary:   accents["honor-primary"],
+		HonorSecondary: accents["honor-secondary"],
+		HonorTertiary:  accents["honor-tertiary"],
+	}
+	return opts
+}
+
+// enrichVSCodeTheme prints the theme at path with the palette written in
+func enrichVSCodeTheme(path string, colors []string, minLc float64) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Error reading file: %v", err)
+	}
+
+	enriched, err := dank16.EnrichVSCodeTheme(data, colors, minLc)
+	if err != nil {
+		log.Fatalf("Error enriching theme: %v", err)
+	}
+	fmt.Println(string(enriched))
+}
+
+func runDank16(cmd *cobra.Command, args []string) {
+	isLint, _ := cmd.Flags().GetBool("lint")
+	isJson, _ := cmd.Flags().GetBool("json")
+	isPair, _ := cmd.Flags().GetBool("pair")
+	vscodeEnrich, _ := cmd.Flags().GetString("vscode-enrich")
+	vscodeMinLc, _ := cmd.Flags().GetFloat64("vscode-min-lc")
+	wallpaper, _ := cmd.Flags().GetString("from-wallpaper")
+	isRandom, _ := cmd.Flags().GetBool("random")
+	randomSeed, _ := cmd.Flags().GetInt64("random-seed")
+	preset, _ := cmd.Flags().GetString("preset")
+
+	var seed string
+	switch {
+	case preset != "":
+		if isPair {
+			log.Fatal("--pair needs a color to generate both variants from, not a preset")
+		}
+	case isRandom:
+		if !cmd.Flags().Changed("random-seed") {
+			// The date, as YYYYMMDD, so every run on one day agrees
+			now := time.Now()
+			randomSeed = int64(now.Year()*10000 + int(now.Month())*100 + now.Day())
+		}
+		seed = dank16.RandomPrimary(randomSeed)
+		fmt.Fprintf(os.Stderr, "Using accent %s from random seed %d\n", seed, randomSeed)
+	case wallpaper != "":
+		extracted, err := dank16.ExtractPaletteFromImage(wallpaper)
+		if err != nil {
+			log.Fatalf("Error reading wallpaper: %v", err)
+		}
+		seed = extracted.Accent
+		fmt.Fprintf(os.Stderr, "Using accent %s from %s\n", seed, wallpaper)
+	case len(args) == 1:
+		seed = args[0]
+	default:
+		log.Fatal("Provide a hex color, --from-wallpaper <path>, --random or --preset <name>")
+	}
+
+	var colors []string
+	var opts dank16.PaletteOptions
+	if preset != "" {
+		colors, opts = dank16PresetFromFlags(cmd, preset)
+	} else {
+		colors, opts = dank16PaletteFromFlags(cmd, seed)
 	}
 
-	colors := dank16.GeneratePalette(primaryColor, opts)
+	if isPair {
+		seed = dank16Color("color", seed)
+		fmt.Print(dank16.GeneratePairJSON(dank16.GeneratePalettePair(seed, opts)))
+		return
+	}
+
+	if isLint {
+		lintDank16Palette(colors, opts, isJson)
+		return
+	}
 
 	if vscodeEnrich != "" {
-		data, err := os.ReadFile(vscodeEnrich)
+		if cmd.Flags().Changed("min-contrast") {
+			log.Fatal("--min-contrast is a WCAG ratio for terminal output; use --vscode-min-lc with --vscode-enrich")
+		}
+		if vscodeMinLc < 0 || vscodeMinLc > 100 {
+			log.Fatalf("Invalid --vscode-min-lc: %g (Delta Phi Star Lc runs from 0 to 100)", vscodeMinLc)
+		}
+		enrichVSCodeTheme(vscodeEnrich, colors, vscodeMinLc)
+		return
+	}
+
+	writeDank16Outputs(cmd, colors, opts)
+}
+
+// writeDank16Outputs renders colors in the formats whose flags are set, into
+// --out-dir, --qt-dir or --firefox-dir or else the first of them to stdout
+func writeDank16Outputs(cmd *cobra.Command, colors []string, opts dank16.PaletteOptions) {
+	minContrast, _ := cmd.Flags().GetFloat64("min-contrast")
+	qtDir, _ := cmd.Flags().GetString("qt-dir")
+	firefoxDir, _ := cmd.Flags().GetString("firefox-dir")
+	outDir, _ := cmd.Flags().GetString("out-dir")
+
+	if minContrast < 0 || minContrast > 21 {
+		log.Fatalf("Invalid --min-contrast: %g (WCAG ratios run from 1 to 21)", minContrast)
+	}
+	termColors := dank16.CompensateMinContrast(colors, minContrast, opts.IsLight)
+
+	if qtDir != "" {
+		if err := writeQtTheme(qtDir, dank16.GenerateQtTheme(colors, opts.IsLight)); err != nil {
+			log.Fatalf("Error writing Qt theme: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s theme; select it in qt5ct/qt6ct and Kvantum Manager\n", dank16.QtThemeName)
+		return
+	}
+	if firefoxDir != "" {
+		if err := writeFirefoxTheme(firefoxDir, dank16.GenerateFirefoxTheme(colors, opts.IsLight)); err != nil {
+			log.Fatalf("Error writing Firefox theme: %v", err)
+		}
+		fmt.Fprintln(os.Stderr, "Wrote Firefox theme; set toolkit.legacyUserProfileCustomizations.stylesheets to true in about:config and restart the browser")
+		return
+	}
+
+	outputs := selectedDank16Outputs(cmd, colors, termColors, opts)
+
+	if outDir != "" {
+		if len(outputs) == 0 {
+			log.Fatal("--out-dir needs at least one output format, such as --kitty or --gtk")
+		}
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			log.Fatalf("Error creating %s: %v", outDir, err)
+		}
+		for _, out := range outputs {
+			theme, err := out.render()
+			if err != nil {
+				log.Fatalf("Error rendering %s: %v", out.file, err)
+			}
+			path := filepath.Join(outDir, out.file)
+			if err := os.WriteFile(path, []byte(theme), 0644); err != nil {
+				log.Fatalf("Error writing %s: %v", path, err)
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+		}
+		return
+	}
+
+	// Without --out-dir only one output can go to stdout: the first of the
+	// flags given, or the Ghostty theme
+	if len(outputs) == 0 {
+		outputs = []dank16Output{dank16FormatOutput(cmd, "ghostty", termColors, opts.IsLight)}
+	}
+	theme, err := outputs[0].render()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	fmt.Print(theme)
+}
+
+// dank16Output is one theme file runDank16 can produce, with the name it is
+// given under --out-dir
+type dank16Output struct {
+	file   string
+	render func() (string, error)
+}
+
+// dank16FormatFiles names the files of the template formats under
+// --out-dir; formats not listed are saved under their own name
+var dank16FormatFiles = map[string]string{
+	"kitty":     "kitty.conf",
+	"foot":      "foot.ini",
+	"alacritty": "alacritty.toml",
+	"ghostty":   "ghostty",
+	"wezterm":   "wezterm.lua",
+	"niri":      "niri.kdl",
+	"hyprland":  "hyprland.conf",
+}
+
+func dank16FormatOutput(cmd *cobra.Command, name string, termColors []string, isLight bool) dank16Output {
+	noTerminalContrast, _ := cmd.Flags().GetBool("no-terminal-contrast")
+	file, ok := dank16FormatFiles[name]
+	if !ok {
+		file = name
+	}
+	return dank16Output{file: file, render: func() (string, error) {
+		loadDank16Templates()
+		theme, err := dank16.RenderFormat(name, termColors, isLight)
 		if err != nil {
-			log.Fatalf("Error reading file: %v", err)
+			return "", fmt.Errorf("error rendering %s: %w", name, err)
 		}
+		if noTerminalContrast {
+			theme = dank16.DisableTerminalMinContrast(name, theme)
+		}
+		return theme, nil
+	}}
+}
+
+// selectedDank16Outputs lists the outputs whose flags are set, in the order
+// that decides which one is printed when there is no --out-dir
+func selectedDank16Outputs(cmd *cobra.Command, colors, termColors []string, opts dank16.PaletteOptions) []dank16Output {
+	isLight := opts.IsLight
+	text := func(theme string) func() (string, error) {
+		return func() (string, error) { return theme, nil }
+	}
+	lazy := func(generate func([]string, bool) string) func() (string, error) {
+		return func() (string, error) { return generate(colors, isLight), nil }
+	}
 
-		enriched, err := dank16.EnrichVSCodeTheme(data, colors)
+	var outputs []dank16Output
+	add := func(flag, file string, render func() (string, error)) {
+		if set, _ := cmd.Flags().GetBool(flag); set {
+			outputs = append(outputs, dank16Output{file: file, render: render})
+		}
+	}
+
+	add("json", "dank16.json", text(dank16.GenerateJSON(colors)))
+	add("json-roles", "dank16-roles.json", lazy(dank16.GenerateRolesJSON))
+	if format, _ := cmd.Flags().GetString("format"); format != "" {
+		outputs = append(outputs, dank16FormatOutput(cmd, format, termColors, isLight))
+	}
+	for _, terminal := range []string{"kitty", "foot", "alacritty", "ghostty", "wezterm"} {
+		if set, _ := cmd.Flags().GetBool(terminal); set {
+			outputs = append(outputs, dank16FormatOutput(cmd, terminal, termColors, isLight))
+		}
+	}
+	add("gtk", "gtk.css", lazy(dank16.GenerateGTKTheme))
+	add("nvim", "dank16.lua", lazy(dank16.GenerateNeovimTheme))
+	add("zed", "dank16-zed.json", lazy(dank16.GenerateZedTheme))
+	add("emacs", "doom-dank16-theme.el", lazy(dank16.GenerateEmacsTheme))
+	add("jetbrains", "Dank16.icls", lazy(dank16.GenerateJetBrainsScheme))
+	add("dircolors", "dircolors", lazy(dank16.GenerateDircolors))
+	if shell, _ := cmd.Flags().GetString("shell"); shell != "" {
+		outputs = append(outputs, dank16Output{file: "colors." + shell, render: func() (string, error) {
+			return dank16.GenerateShellTheme(colors, isLight, shell)
+		}})
+	}
+	add("eza", "eza-theme.yml", lazy(dank16.GenerateEzaTheme))
+	add("bat", "Dank16.tmTheme", lazy(dank16.GenerateBatTheme))
+	add("delta", "delta.gitconfig", lazy(dank16.GenerateDeltaConfig))
+	add("base16-yaml", "base16.yaml", lazy(dank16.GenerateBase16YAML))
+	add("base24-yaml", "base24.yaml", lazy(dank16.GenerateBase24YAML))
+	add("tmux", "tmux.conf", lazy(dank16.GenerateTmuxTheme))
+	add("rofi", "dank16.rasi", lazy(dank16.GenerateRofiTheme))
+	add("fuzzel", "fuzzel.ini", lazy(dank16.GenerateFuzzelTheme))
+	add("fzf", "fzf-colors", lazy(dank16.GenerateFzfColors))
+	add("wofi", "wofi.css", lazy(dank16.GenerateWofiStyle))
+	add("waybar", "waybar.css", lazy(dank16.GenerateWaybarCSS))
+	add("p3", "p3.css", func() (string, error) {
+		boost, _ := cmd.Flags().GetFloat64("p3-boost")
+		if boost < 1 || boost > 2 {
+			return "", fmt.Errorf("invalid --p3-boost: %g (must be between 1 and 2)", boost)
+		}
+		return dank16.GenerateP3CSS(colors, isLight, boost), nil
+	})
+	add("btop", "dank16.theme", lazy(dank16.GenerateBtopTheme))
+	add("htop", "htoprc", text(dank16.GenerateHtoprc(isLight)))
+	add("discord", "dank16.theme.css", lazy(dank16.GenerateDiscordTheme))
+	add("spicetify", "color.ini", lazy(dank16.GenerateSpicetifyTheme))
+	add("firefox", "userChrome.css", func() (string, error) {
+		return dank16.GenerateFirefoxTheme(colors, isLight).UserChrome, nil
+	})
+	add("firefox-theme", "manifest.json", func() (string, error) {
+		return dank16.GenerateFirefoxTheme(colors, isLight).Manifest, nil
+	})
+	add("qt", dank16.QtThemeName+".conf", func() (string, error) {
+		return dank16.GenerateQtTheme(colors, isLight).ColorScheme, nil
+	})
+	add("vscode", "dank16-vscode.json", lazy(dank16.GenerateVSCodeTheme))
+	add("gpl", "dank16.gpl", lazy(dank16.GenerateGIMPPalette))
+	add("kpl", "dank16.kpl", func() (string, error) {
+		palette, err := dank16.GenerateKritaPalette(colors, isLight)
+		return string(palette), err
+	})
+	return outputs
+}
+
+// loadDank16Templates registers the user's templates, which may also
+// replace the built-in terminal formats
+func loadDank16Templates() {
+	if _, err := dank16.LoadTemplates(dank16.TemplateDir()); err != nil {
+		log.Warnf("Skipping templates: %v", err)
+	}
+}
+
+func runDank16Formats(cmd *cobra.Command, args []string) {
+	loadDank16Templates()
+	for _, name := range dank16.Formats() {
+		fmt.Println(name)
+	}
+}
+
+func lintDank16Palette(colors []string, opts dank16.PaletteOptions, asJSON bool) {
+	diags := dank16.ValidatePalette(colors, opts)
+
+	if asJSON {
+		if diags == nil {
+			diags = []dank16.Diagnostic{}
+		}
+		data, err := json.MarshalIndent(diags, "", "  ")
 		if err != nil {
-			log.Fatalf("Error enriching theme: %v", err)
-		}
-		fmt.Println(string(enriched))
-	} else if isJson {
-		fmt.Print(dank16.GenerateJSON(colors))
-	} else if isKitty {
-		fmt.Print(dank16.GenerateKittyTheme(colors))
-	} else if isFoot {
-		fmt.Print(dank16.GenerateFootTheme(colors))
-	} else if isAlacritty {
-		fmt.Print(dank16.GenerateAlacrittyTheme(colors))
-	} else if isGhostty {
-		fmt.Print(dank16.GenerateGhosttyTheme(colors))
+			log.Fatalf("Error encoding diagnostics: %v", err)
+		}
+		fmt.Println(string(data))
 	} else {
-		fmt.Print(dank16.GenerateGhosttyTheme(colors))
+		for _, d := range diags {
+			fmt.Printf("%-8s %-19s %s\n", d.Severity, d.Kind, d.Message)
+		}
+		if len(diags) == 0 {
+			fmt.Println("Palette OK")
+		}
+	}
+
+	if dank16.HasErrors(diags) {
+		os.Exit(1)
+	}
+}
+
+func writeQtTheme(configDir string, theme dank16.QtTheme) error {
+	name := dank16.QtThemeName
+	files := map[string]string{
+		filepath.Join(configDir, "qt5ct", "colors", name+".conf"):   theme.ColorScheme,
+		filepath.Join(configDir, "qt6ct", "colors", name+".conf"):   theme.ColorScheme,
+		filepath.Join(configDir, "Kvantum", name, name+".kvconfig"): theme.KvConfig,
+		filepath.Join(configDir, "Kvantum", name, name+".svg"):      theme.KvSVG,
 	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFirefoxTheme(profileDir string, theme dank16.FirefoxTheme) error {
+	chromeDir := filepath.Join(profileDir, "chrome")
+	if err := os.MkdirAll(chromeDir, 0755); err != nil {
+		return err
+	}
+
+	for _, f := range []struct{ name, content, importer string }{
+		{dank16.FirefoxChromeFile, theme.UserChrome, "userChrome.css"},
+		{dank16.FirefoxContentFile, theme.UserContent, "userContent.css"},
+	} {
+		if err := os.WriteFile(filepath.Join(chromeDir, f.name), []byte(f.content), 0644); err != nil {
+			return err
+		}
+
+		importer := filepath.Join(chromeDir, f.importer)
+		existing, err := os.ReadFile(importer)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		updated := dank16.AddFirefoxImport(string(existing), f.name)
+		if updated == string(existing) {
+			continue
+		}
+		if err := os.WriteFile(importer, []byte(updated), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDank16Apply(cmd *cobra.Command, args []string) {
+	only, _ := cmd.Flags().GetStringSlice("only")
+	configDir, _ := cmd.Flags().GetString("config-dir")
+
+	if configDir == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			log.Fatalf("Error finding config directory: %v", err)
+		}
+		configDir = dir
+	}
+
+	colors, _ := dank16PaletteFromFlags(cmd, args[0])
+	applied, err := dank16.ApplyThemes(configDir, only, colors)
+	for _, f := range applied {
+		if f.Backup != "" {
+			fmt.Printf("Wrote %s (previous contents in %s)\n", f.Path, f.Backup)
+		} else {
+			fmt.Printf("Wrote %s\n", f.Path)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Error applying theme: %v", err)
+	}
+
+	// Say how to load the themes whose terminal config could not be updated
+	for _, f := range applied {
+		t, ok := dank16.ApplyTargetNamed(f.Target)
+		if !ok || f.Path != filepath.Join(configDir, t.Path) {
+			continue
+		}
+		configPath := filepath.Join(configDir, t.Config)
+		switch {
+		case t.Hint != "":
+			fmt.Fprintf(os.Stderr, "%s: %s\n", t.Name, t.HintFor(f.Path))
+		case t.Include != "":
+			if _, err := os.Stat(configPath); os.IsNotExist(err) {
+				fmt.Fprintf(os.Stderr, "%s: add %q to %s\n", t.Name, t.IncludeLine(f.Path), configPath)
+			}
+		}
+	}
+}
+
+func runDank16Nearest(cmd *cobra.Command, args []string) {
+	snap, _ := cmd.Flags().GetBool("snap")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	colors, opts := dank16PaletteFromFlags(cmd, args[0])
+	matches := dank16.NearestSchemes(colors, opts.IsLight)
+	if len(matches) == 0 {
+		log.Fatal("No bundled schemes available for this variant")
+	}
+
+	if snap {
+		fmt.Fprintf(os.Stderr, "Snapped to %s (ΔE %.1f)\n", matches[0].Scheme.Name, matches[0].Distance)
+		writeDank16Outputs(cmd, matches[0].Scheme.Colors, opts)
+		return
+	}
+
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+	for _, m := range matches {
+		fmt.Printf("%-20s ΔE %5.1f\n", m.Scheme.Name, m.Distance)
+	}
+	fmt.Println("\nUse --snap to output the closest scheme")
+}
+
+func runDank16Transition(cmd *cobra.Command, args []string) {
+	steps, _ := cmd.Flags().GetInt("steps")
+	if steps < 2 {
+		log.Fatalf("Invalid --steps: %d (need at least 2)", steps)
+	}
+
+	from, _ := dank16PaletteFromFlags(cmd, args[0])
+	to, _ := dank16PaletteFromFlags(cmd, args[1])
+	fmt.Print(dank16.GenerateTransitionJSON(from, to, steps))
+}
+
+func runDank16Verify(cmd *cobra.Command, args []string) {
+	dir, _ := cmd.Flags().GetString("goldens")
+	update, _ := cmd.Flags().GetBool("update")
+	threshold, _ := cmd.Flags().GetFloat64("threshold")
+
+	colors, opts := dank16PaletteFromFlags(cmd, args[0])
+	variant := "dark"
+	if opts.IsLight {
+		variant = "light"
+	}
+
+	if update {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Fatalf("Error creating %s: %v", dir, err)
+		}
+	}
+
+	failed := false
+	for _, target := range dank16.VerifyTargets {
+		img, err := target.Render(colors, opts.IsLight)
+		if err != nil {
+			fmt.Printf("FAIL %-10s render: %v\n", target.Name, err)
+			failed = true
+			continue
+		}
+
+		golden := filepath.Join(dir, fmt.Sprintf("%s-%s.png", target.Name, variant))
+		if update {
+			if err := writePNG(golden, img); err != nil {
+				log.Fatalf("Error writing %s: %v", golden, err)
+			}
+			fmt.Printf("wrote %s\n", golden)
+			continue
+		}
+
+		want, err := readPNG(golden)
+		if err != nil {
+			fmt.Printf("FAIL %-10s %v (run with --update to create it)\n", target.Name, err)
+			failed = true
+			continue
+		}
+
+		result, err := dank16.CompareImages(img, want, threshold)
+		if err != nil {
+			fmt.Printf("FAIL %-10s %v\n", target.Name, err)
+			failed = true
+			continue
+		}
+		if result.Passed() {
+			fmt.Printf("ok   %-10s max ΔE %.2f\n", target.Name, result.MaxDeltaE)
+			continue
+		}
+
+		failed = true
+		actual := strings.TrimSuffix(golden, ".png") + ".actual.png"
+		if err := writePNG(actual, img); err != nil {
+			log.Warnf("Error writing %s: %v", actual, err)
+		}
+		fmt.Printf("FAIL %-10s %d/%d pixels over ΔE %.1f (max %.2f), see %s\n",
+			target.Name, result.FailedPixels, result.TotalPixels, threshold, result.MaxDeltaE, actual)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runDank16Preview(cmd *cobra.Command, args []string) {
+	colors, opts := dank16PaletteFromFlags(cmd, args[0])
+	fmt.Print(dank16.RenderPreview(colors, opts))
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/pkg/dmsclient"
+	"github.com/spf13/cobra"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Tools for reporting shell and server bugs",
+	// Recording only needs the daemon socket, not the shell config
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var debugRecordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record IPC traffic between the shell and the server",
+	Long:  "Record every request and response on the server socket as JSON lines while you reproduce a bug, then attach the file to the report. Passwords, tokens and other secrets are removed; home directory, user and host names, IP and MAC addresses, SSIDs and email addresses are redacted.",
+}
+
+var debugRecordStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start recording",
+	Args:  cobra.NoArgs,
+	Run:   runDebugRecordStart,
+}
+
+var debugRecordStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop recording and print where the file is",
+	Args:  cobra.NoArgs,
+	Run:   runDebugRecordStop,
+}
+
+var debugRecordStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether a recording is running",
+	Args:  cobra.NoArgs,
+	Run:   runDebugRecordStatus,
+}
+
+func init() {
+	debugRecordStartCmd.Flags().StringP("output", "o", "", "Recording file name, kept in the crash directory (default: ipc-recording.jsonl)")
+	debugRecordStartCmd.Flags().Int64("max-size", 0, "Size in bytes after which the file is rotated to <output>.1 (default: 8 MiB)")
+
+	debugRecordCmd.AddCommand(debugRecordStartCmd, debugRecordStopCmd, debugRecordStatusCmd)
+	debugCmd.AddCommand(debugRecordCmd)
+}
+
+func withDebugClient(action string, fn func(context.Context, *dmsclient.Client) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := dialDaemon(ctx)
+	if err != nil {
+		log.Fatalf("%s failed: %v", action, err)
+	}
+	defer client.Close()
+
+	if err := fn(ctx, client); err != nil {
+		log.Fatalf("%s failed: %v", action, err)
+	}
+}
+
+func runDebugRecordStart(cmd *cobra.Command, args []string) {
+	output, _ := cmd.Flags().GetString("output")
+	maxSize, _ := cmd.Flags().GetInt64("max-size")
+
+	withDebugClient("Starting the recording", func(ctx context.Context, client *dmsclient.Client) error {
+		status, err := client.Debug().StartRecording(ctx, output, maxSize)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Recording IPC traffic to %s (rotated at %d bytes)\n", status.Path, status.MaxBytes)
+		fmt.Println("Reproduce the problem, then run 'dms debug record stop'")
+		return nil
+	})
+}
+
+func runDebugRecordStop(cmd *cobra.Command, args []string) {
+	withDebugClient("Stopping the recording", func(ctx context.Context, client *dmsclient.Client) error {
+		status, err := client.Debug().StopRecording(ctx)
+		if err != nil {
+			return err
+		}
+		printRecordingStatus(status)
+		return nil
+	})
+}
+
+func runDebugRecordStatus(cmd *cobra.Command, args []string) {
+	withDebugClient("Reading the recording status", func(ctx context.Context, client *dmsclient.Client) error {
+		status, err := client.Debug().RecordingStatus(ctx)
+		if err != nil {
+			return err
+		}
+		printRecordingStatus(status)
+		return nil
+	})
+}
+
+func printRecordingStatus(status dmsclient.RecordingStatus) {
+	if status.Path == "" {
+		fmt.Println("Not recording")
+		return
+	}
+	state := "Stopped"
+	if status.Active {
+		state = "Recording"
+	}
+	fmt.Printf("%s: %s (%d entries, %d bytes)\n", state, status.Path, status.Entries, status.Written)
+	if status.Rotated {
+		fmt.Printf("Older traffic was moved to %s.1\n", status.Path)
+	}
+}
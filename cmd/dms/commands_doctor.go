@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AvengeMedia/danklinux/internal/server/brightness"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the system lets DMS do its job",
+	Long:  "Check the permissions DMS relies on and explain how to fix what is missing. Exits with status 1 when a check fails.",
+	Args:  cobra.NoArgs,
+	Run:   runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	if !doctorBrightness() {
+		os.Exit(1)
+	}
+}
+
+// doctorBrightness runs the same preflight that explains failed brightness
+// changes in the server
+func doctorBrightness() bool {
+	logind, problems, err := brightness.CheckAccess()
+	switch {
+	case err != nil:
+		fmt.Printf("✗ Brightness: %v\n", err)
+		return false
+	case logind:
+		fmt.Println("✓ Brightness: logind session can set backlight and LED brightness")
+		return true
+	case len(problems) == 0:
+		fmt.Println("✓ Brightness: logind unavailable, but sysfs brightness files are writable")
+		return true
+	}
+
+	fmt.Println("✗ Brightness: logind unavailable and these devices are not writable:")
+	for _, p := range problems {
+		fmt.Printf("    %s (%s)\n", p.Device, p.Path)
+	}
+	fix := problems[0]
+	fmt.Printf("  Join the %s group:\n    sudo usermod -aG %s $USER\n", fix.Group, fix.Group)
+	fmt.Println("  then log in again, or add to /etc/udev/rules.d/90-dms-brightness.rules:")
+	seen := make(map[string]bool)
+	for _, p := range problems {
+		if rule := p.UdevRule(); !seen[rule] {
+			seen[rule] = true
+			fmt.Printf("    %s\n", rule)
+		}
+	}
+	return false
+}
@@ -24,7 +24,8 @@ var greeterInstallCmd = &cobra.Command{
 	Short: "Install and configure DMS greeter",
 	Long:  "Install greetd and configure it to use DMS as the greeter interface",
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := installGreeter(); err != nil {
+		skipValidation, _ := cmd.Flags().GetBool("skip-validation")
+		if err := installGreeter(skipValidation); err != nil {
 			log.Fatalf("Error installing greeter: %v", err)
 		}
 	},
@@ -46,7 +47,8 @@ var greeterEnableCmd = &cobra.Command{
 	Short: "Enable DMS greeter in greetd config",
 	Long:  "Configure greetd to use DMS as the greeter",
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := enableGreeter(); err != nil {
+		skipValidation, _ := cmd.Flags().GetBool("skip-validation")
+		if err := enableGreeter(skipValidation); err != nil {
 			log.Fatalf("Error enabling greeter: %v", err)
 		}
 	},
@@ -63,7 +65,7 @@ var greeterStatusCmd = &cobra.Command{
 	},
 }
 
-func installGreeter() error {
+func installGreeter(skipValidation bool) error {
 	fmt.Println("=== DMS Greeter Installation ===")
 
 	logFunc := func(msg string) {
@@ -110,11 +112,22 @@ func installGreeter() error {
 		return err
 	}
 
+	if !skipValidation {
+		if err := validateGreeter(dmsPath, selectedCompositor); err != nil {
+			return err
+		}
+	}
+
 	fmt.Println("\nConfiguring greetd...")
 	if err := greeter.ConfigureGreetd(dmsPath, selectedCompositor, logFunc, ""); err != nil {
 		return err
 	}
 
+	fmt.Println("\nConfiguring keyring unlock...")
+	if err := greeter.ConfigureKeyringPAM(logFunc, ""); err != nil {
+		fmt.Printf("⚠ %v\n", err)
+	}
+
 	fmt.Println("\nSynchronizing DMS configurations...")
 	if err := greeter.SyncDMSConfigs(dmsPath, logFunc, ""); err != nil {
 		return err
@@ -190,6 +203,11 @@ func syncGreeter() error {
 		return err
 	}
 
+	fmt.Println("\nConfiguring keyring unlock...")
+	if err := greeter.ConfigureKeyringPAM(logFunc, ""); err != nil {
+		fmt.Printf("⚠ %v\n", err)
+	}
+
 	fmt.Println("\nSynchronizing DMS configurations...")
 	if err := greeter.SyncDMSConfigs(dmsPath, logFunc, ""); err != nil {
 		return err
@@ -217,7 +235,7 @@ func checkGroupExists(groupName string) bool {
 	return false
 }
 
-func enableGreeter() error {
+func enableGreeter(skipValidation bool) error {
 	fmt.Println("=== DMS Greeter Enable ===")
 	fmt.Println()
 
@@ -234,6 +252,9 @@ func enableGreeter() error {
 	configContent := string(data)
 	if strings.Contains(configContent, "dms-greeter") {
 		fmt.Println("✓ Greeter is already configured with dms-greeter")
+		if err := greeter.ConfigureKeyringPAM(func(msg string) { fmt.Println(msg) }, ""); err != nil {
+			fmt.Printf("⚠ %v\n", err)
+		}
 		return nil
 	}
 
@@ -261,6 +282,12 @@ func enableGreeter() error {
 		fmt.Printf("✓ Selected compositor: %s\n", selectedCompositor)
 	}
 
+	if !skipValidation {
+		if err := validateGreeter("", selectedCompositor); err != nil {
+			return err
+		}
+	}
+
 	backupPath := configPath + ".backup"
 	backupCmd := exec.Command("sudo", "cp", configPath, backupPath)
 	if err := backupCmd.Run(); err != nil {
@@ -277,13 +304,7 @@ func enableGreeter() error {
 		}
 	}
 
-	wrapperCmd := "dms-greeter"
-	if !commandExists("dms-greeter") {
-		wrapperCmd = "/usr/local/bin/dms-greeter"
-	}
-
-	compositorLower := strings.ToLower(selectedCompositor)
-	commandLine := fmt.Sprintf(`command = "%s --command %s"`, wrapperCmd, compositorLower)
+	commandLine := fmt.Sprintf(`command = "%s"`, greeter.GreetdCommand("", selectedCompositor))
 
 	var finalLines []string
 	inDefaultSession := false
@@ -309,19 +330,17 @@ func enableGreeter() error {
 		finalLines = append(finalLines, commandLine)
 	}
 
-	newConfig := strings.Join(finalLines, "\n")
-
-	tmpFile := "/tmp/greetd-config.toml"
-	if err := os.WriteFile(tmpFile, []byte(newConfig), 0644); err != nil {
-		return fmt.Errorf("failed to write temp config: %w", err)
+	if err := greeter.WriteGreetdConfig(configPath, strings.Join(finalLines, "\n"), ""); err != nil {
+		return err
 	}
 
-	moveCmd := exec.Command("sudo", "mv", tmpFile, configPath)
-	if err := moveCmd.Run(); err != nil {
-		return fmt.Errorf("failed to update config: %w", err)
+	fmt.Printf("✓ Updated greetd configuration to use %s\n", selectedCompositor)
+
+	fmt.Println("\nConfiguring keyring unlock...")
+	if err := greeter.ConfigureKeyringPAM(func(msg string) { fmt.Println(msg) }, ""); err != nil {
+		fmt.Printf("⚠ %v\n", err)
 	}
 
-	fmt.Printf("✓ Updated greetd configuration to use %s\n", selectedCompositor)
 	fmt.Println("\n=== Enable Complete ===")
 	fmt.Println("\nTo start the greeter, run:")
 	fmt.Println("  sudo systemctl start greetd")
@@ -331,6 +350,27 @@ func enableGreeter() error {
 	return nil
 }
 
+// validateGreeter runs the session-free greeter checks, then test-runs the
+// greeter nested in the current session. From a TTY there is no session to
+// nest it in, so only the test-run is skipped there.
+func validateGreeter(dmsPath, compositor string) error {
+	fmt.Println("\nValidating greeter...")
+	logFunc := func(msg string) {
+		fmt.Println(msg)
+	}
+	if err := greeter.CheckGreeter(dmsPath, compositor, logFunc); err != nil {
+		return fmt.Errorf("greeter validation failed, greetd config was not changed: %w\nRe-run with --skip-validation to bypass", err)
+	}
+	if !greeter.CanTestRun() {
+		fmt.Println("- Skipped greeter test-run: no Wayland session (WAYLAND_DISPLAY not set)")
+		return nil
+	}
+	if err := greeter.TestRunGreeter(greeter.GreeterCommand(dmsPath, compositor), greeter.DefaultTestRunTimeout, logFunc); err != nil {
+		return fmt.Errorf("greeter validation failed, greetd config was not changed: %w\nRe-run with --skip-validation to bypass", err)
+	}
+	return nil
+}
+
 func promptCompositorChoice(compositors []string) (string, error) {
 	fmt.Println("\nMultiple compositors detected:")
 	for i, comp := range compositors {
@@ -417,6 +457,8 @@ func checkGreeterStatus() error {
 		fmt.Println("    Run 'dms greeter install' to add user to greeter group")
 	}
 
+	keyringOK := checkKeyringStatus()
+
 	cacheDir := "/var/cache/dms-greeter"
 	fmt.Println("\nGreeter Cache Directory:")
 	if stat, err := os.Stat(cacheDir); err == nil && stat.IsDir() {
@@ -490,7 +532,7 @@ func checkGreeterStatus() error {
 	}
 
 	fmt.Println()
-	if allGood && inGreeterGroup {
+	if allGood && inGreeterGroup && keyringOK {
 		fmt.Println("✓ All checks passed! Greeter is properly configured.")
 	} else if !allGood {
 		fmt.Println("⚠ Some issues detected. Run 'dms greeter sync' to fix symlinks.")
@@ -498,3 +540,44 @@ func checkGreeterStatus() error {
 
 	return nil
 }
+
+// checkKeyringStatus prints whether logging in through greetd unlocks the
+// keyrings in use, and reports whether it does for all of them
+func checkKeyringStatus() bool {
+	fmt.Println("\nKeyring Unlock:")
+
+	pam, err := os.ReadFile(greeter.GreetdPAMPath)
+	if err != nil {
+		fmt.Printf("  ✗ %s not found\n", greeter.GreetdPAMPath)
+		return false
+	}
+
+	ok := true
+	found := false
+	for _, k := range greeter.Keyrings {
+		if !k.Installed() {
+			continue
+		}
+		found = true
+		switch {
+		case greeter.PAMHasKeyring(string(pam), k):
+			fmt.Printf("  ✓ %s is unlocked at login\n", k.Name)
+		case !k.ModuleInstalled():
+			ok = false
+			fmt.Printf("  ✗ %s is installed but its PAM module %s is not\n", k.Name, k.Module)
+			if pkg := greeter.KeyringPackage(k); pkg != "" {
+				fmt.Printf("    Install %s, then run 'dms greeter enable'\n", pkg)
+			}
+		default:
+			ok = false
+			fmt.Printf("  ✗ %s is NOT unlocked at login; Wi-Fi secrets and apps will ask for its password\n", k.Name)
+			fmt.Printf("    Add to %s:\n", greeter.GreetdPAMPath)
+			fmt.Printf("      %s\n", k.AuthLine())
+			fmt.Printf("      %s\n", k.SessionLine())
+		}
+	}
+	if !found {
+		fmt.Println("  No gnome-keyring or KWallet installed")
+	}
+	return ok
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var ipcSchemaCmd = &cobra.Command{
+	Use:   "schema [method]",
+	Short: "Print the JSON Schema of a server IPC method",
+	Long:  "Print the JSON Schemas of a server IPC method's params and result, for validating requests and editor completion. Without a method, list the methods; with --all, print every schema.",
+	Args:  cobra.MaximumNArgs(1),
+	// Schemas come from this binary and need neither the shell nor the daemon
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	Run:               runIPCSchema,
+}
+
+func init() {
+	ipcSchemaCmd.Flags().Bool("all", false, "Print the schemas of every method as a JSON array")
+	ipcCmd.AddCommand(ipcSchemaCmd)
+}
+
+func runIPCSchema(cmd *cobra.Command, args []string) {
+	all, _ := cmd.Flags().GetBool("all")
+
+	switch {
+	case len(args) == 1:
+		info, ok := server.LookupMethod(args[0])
+		if !ok {
+			log.Fatalf("Unknown method: %s", args[0])
+		}
+		printJSON(info)
+	case all:
+		printJSON(server.Methods())
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, info := range server.Methods() {
+			description := info.Description
+			if info.Streaming {
+				description += " (streaming)"
+			}
+			fmt.Fprintf(w, "%s\t%s\n", info.Method, description)
+		}
+		w.Flush()
+	}
+}
+
+func printJSON(v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode schema: %v", err)
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/netacct"
+	"github.com/spf13/cobra"
+)
+
+var netacctCmd = &cobra.Command{
+	Use:   "netacct",
+	Short: "Per-application network accounting",
+	Long:  "Manage the root helper that counts traffic per application for network.appUsage. It keeps nftables counters per systemd scope, which the user daemon cannot read itself.",
+	// The helper runs as root from a system service, without a shell config
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var netacctEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Install and start the accounting service",
+	Long:  "Install " + netacct.ServicePath + " for this dms binary and start it. Asks for authentication through pkexec when not run as root.",
+	Args:  cobra.NoArgs,
+	Run:   runNetacctEnable,
+}
+
+var netacctDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop and remove the accounting service",
+	Args:  cobra.NoArgs,
+	Run:   runNetacctDisable,
+}
+
+var netacctStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether accounting is running",
+	Args:  cobra.NoArgs,
+	Run:   runNetacctStatus,
+}
+
+var netacctRunCmd = &cobra.Command{
+	Use:    "run",
+	Short:  "Run the accounting helper in the foreground (as root)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run:    runNetacctRun,
+}
+
+func init() {
+	netacctCmd.AddCommand(netacctEnableCmd, netacctDisableCmd, netacctStatusCmd, netacctRunCmd)
+}
+
+// reexecAsRoot runs this command again through pkexec and exits with its
+// status, so enabling asks for authentication once
+func reexecAsRoot(args ...string) {
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to locate dms binary: %v", err)
+	}
+	cmd := exec.Command("pkexec", append([]string{exe}, args...)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		log.Fatalf("pkexec failed: %v", err)
+	}
+	os.Exit(0)
+}
+
+func runNetacctEnable(cmd *cobra.Command, args []string) {
+	if os.Geteuid() != 0 {
+		reexecAsRoot("netacct", "enable")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to locate dms binary: %v", err)
+	}
+	if err := netacct.Install(exe); err != nil {
+		log.Fatalf("Failed to enable accounting: %v", err)
+	}
+	fmt.Printf("Per-application accounting enabled (%s)\n", netacct.ServiceName)
+}
+
+func runNetacctDisable(cmd *cobra.Command, args []string) {
+	if os.Geteuid() != 0 {
+		reexecAsRoot("netacct", "disable")
+	}
+
+	if err := netacct.Uninstall(); err != nil {
+		log.Fatalf("Failed to disable accounting: %v", err)
+	}
+	fmt.Println("Per-application accounting disabled")
+}
+
+func runNetacctStatus(cmd *cobra.Command, args []string) {
+	if !netacct.ServiceActive() {
+		fmt.Printf("%s is not running, enable it with 'dms netacct enable'\n", netacct.ServiceName)
+		os.Exit(1)
+	}
+
+	snap, err := netacct.ReadSnapshot(netacct.RuntimeDir, os.Getuid(), time.Now())
+	if err != nil {
+		fmt.Printf("%s is running but has no counters for this user yet: %v\n", netacct.ServiceName, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Counting since %s, %d units\n", snap.Since.Local().Format(time.DateTime), len(snap.Units))
+}
+
+func runNetacctRun(cmd *cobra.Command, args []string) {
+	if os.Geteuid() != 0 {
+		log.Fatal("The accounting helper must run as root")
+	}
+
+	stop := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		close(stop)
+	}()
+
+	if err := netacct.NewAccountant().Run(stop); err != nil {
+		log.Fatalf("Accounting failed: %v", err)
+	}
+}
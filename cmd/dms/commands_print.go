@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/cups"
+	"github.com/spf13/cobra"
+)
+
+// printTimeout leaves room for the daemon to download a URL before it
+// submits the job
+const printTimeout = 2 * time.Minute
+
+var printCmd = &cobra.Command{
+	Use:   "print <file|url|->",
+	Short: "Print a file, URL or stdin",
+	Long:  "Submit a print job to CUPS through the dms daemon from a local file, an http(s) URL (downloaded by the daemon) or stdin when '-' is given",
+	Args:  cobra.ExactArgs(1),
+	Run:   runPrint,
+}
+
+func init() {
+	printCmd.Flags().StringP("printer", "P", "", "Destination printer name")
+	printCmd.Flags().String("title", "", "Job title (defaults to the file name)")
+	printCmd.MarkFlagRequired("printer")
+}
+
+func runPrint(cmd *cobra.Command, args []string) {
+	printer, _ := cmd.Flags().GetString("printer")
+	title, _ := cmd.Flags().GetString("title")
+	source := args[0]
+
+	var data []byte
+	switch {
+	case source == "-":
+		// Read before dialing so a slow pipe does not eat the timeout
+		var err error
+		data, err = io.ReadAll(io.LimitReader(os.Stdin, cups.MaxPrintSize+1))
+		if err != nil {
+			log.Fatalf("Print failed: %v", err)
+		}
+		if len(data) > cups.MaxPrintSize {
+			log.Fatalf("Print failed: document too large (max %d bytes)", cups.MaxPrintSize)
+		}
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+	default:
+		// The daemon resolves paths against its own working directory
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			log.Fatalf("Invalid file path: %v", err)
+		}
+		source = abs
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), printTimeout)
+	defer cancel()
+
+	client, err := dialDaemon(ctx)
+	if err != nil {
+		log.Fatalf("Print failed: %v", err)
+	}
+	defer client.Close()
+
+	var jobID int
+	switch {
+	case source == "-":
+		jobID, err = client.CUPS().PrintData(ctx, printer, data, title)
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		jobID, err = client.CUPS().PrintURL(ctx, printer, source, title)
+	default:
+		jobID, err = client.CUPS().PrintFile(ctx, printer, source, title)
+	}
+	if err != nil {
+		log.Fatalf("Print failed: %v", err)
+	}
+
+	fmt.Printf("Submitted job %d to %s\n", jobID, printer)
+}
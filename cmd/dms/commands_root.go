@@ -33,6 +33,11 @@ func init() {
 }
 
 func findConfig(cmd *cobra.Command, args []string) error {
+	// Completions run before any shell is installed, e.g. for dms config
+	if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+		return nil
+	}
+
 	if customConfigPath != "" {
 		log.Debug("Custom config path provided via -c flag: %s", customConfigPath)
 		shellPath := filepath.Join(customConfigPath, "shell.qml")
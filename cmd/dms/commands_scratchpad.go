@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var scratchpadCmd = &cobra.Command{
+	Use:   "scratchpad",
+	Short: "Toggle drop-down terminals and apps",
+	Long:  "Start, show and hide the scratchpads declared in ~/.config/DankMaterialShell/scratchpads.toml through the running server",
+	// Scratchpads only need the daemon socket, not the shell config
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var scratchpadToggleCmd = &cobra.Command{
+	Use:   "toggle <name>",
+	Short: "Start, show or hide a scratchpad",
+	Long:  "Start the scratchpad when it is not running, bring it to the current workspace when hidden and hide it when focused. Bind this to a key, e.g. 'dms scratchpad toggle term'. With --command the pad does not need to be declared in scratchpads.toml.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runScratchpadToggle,
+}
+
+var scratchpadListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scratchpads and whether they are running",
+	Args:  cobra.NoArgs,
+	Run:   runScratchpadList,
+}
+
+func init() {
+	scratchpadToggleCmd.Flags().String("command", "", "Command that starts the pad when it is not declared")
+	scratchpadToggleCmd.Flags().String("app-id", "", "App ID (Wayland) or class (Hyprland) of the pad's window")
+	scratchpadToggleCmd.MarkFlagsRequiredTogether("command", "app-id")
+
+	scratchpadCmd.AddCommand(scratchpadToggleCmd, scratchpadListCmd)
+}
+
+func runScratchpadToggle(cmd *cobra.Command, args []string) {
+	command, _ := cmd.Flags().GetString("command")
+	appID, _ := cmd.Flags().GetString("app-id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := dialDaemon(ctx)
+	if err != nil {
+		log.Fatalf("Scratchpad toggle failed: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Scratchpad().Toggle(ctx, args[0], command, appID); err != nil {
+		log.Fatalf("Scratchpad toggle failed: %v", err)
+	}
+}
+
+func runScratchpadList(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := dialDaemon(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list scratchpads: %v", err)
+	}
+	defer client.Close()
+
+	state, err := client.Scratchpad().GetState(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list scratchpads: %v", err)
+	}
+
+	for _, e := range state.Errors {
+		fmt.Printf("Problem: %s\n", e)
+	}
+	if len(state.Pads) == 0 {
+		fmt.Printf("No scratchpads declared in %s\n", state.Path)
+		return
+	}
+	for _, pad := range state.Pads {
+		status := "stopped"
+		switch {
+		case pad.Running && pad.Visible:
+			status = "visible"
+		case pad.Running:
+			status = "hidden"
+		}
+		fmt.Printf("%-16s %-8s %s\n", pad.Name, status, pad.Command)
+	}
+}
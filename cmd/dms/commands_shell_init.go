@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/AvengeMedia/danklinux/internal/dank16"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var shellInitCmd = &cobra.Command{
+	Use:       "shell-init <bash|zsh|fish>",
+	Short:     "Print shell integration for the dank16 palette",
+	Long:      "Print a snippet that exports DANK16_COLOR0..15, suggests LS_COLORS and re-reads the palette before each prompt when the DMS theme changes.\n\nAdd to your shell config:\n  bash: eval \"$(dms shell-init bash)\"\n  zsh:  eval \"$(dms shell-init zsh)\"\n  fish: dms shell-init fish | source",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Run:       runShellInit,
+}
+
+func init() {
+	shellInitCmd.Flags().Bool("exports", false, "Only print the palette exports (used by the prompt hook)")
+	shellInitCmd.Flags().String("primary", "", "Use a fixed primary color instead of the DMS theme")
+	shellInitCmd.Flags().Bool("light", false, "Generate the light palette variant")
+}
+
+// shellStampPrefix names the per-shell stamp files the bash and zsh hooks
+// touch after re-reading the palette
+const shellStampPrefix = "dms-shell-init."
+
+func shellStampDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return dir
+	}
+	return "/tmp"
+}
+
+// cleanupStaleShellStamps removes the stamps of shells that have exited,
+// since nothing else deletes them.
+func cleanupStaleShellStamps(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		rest, ok := strings.CutPrefix(entry.Name(), shellStampPrefix)
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(rest)
+		if err != nil || pid <= 0 {
+			continue
+		}
+
+		// EPERM means the pid belongs to another user's live process
+		if syscall.Kill(pid, 0) == syscall.ESRCH {
+			stampPath := filepath.Join(dir, entry.Name())
+			os.Remove(stampPath)
+			log.Debugf("Removed stale shell stamp: %s", stampPath)
+		}
+	}
+}
+
+func shellThemeColorsPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".cache", "quickshell", "dankshell", "dms-colors.json")
+}
+
+// readShellThemePrimary extracts the primary color from the matugen output DMS
+// writes on every theme change.
+func readShellThemePrimary(path string) (string, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	var theme struct {
+		Mode   string                       `json:"mode"`
+		Colors map[string]map[string]string `json:"colors"`
+	}
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return "", false, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	isLight := strings.EqualFold(theme.Mode, "light")
+	mode := "dark"
+	if isLight {
+		mode = "light"
+	}
+
+	primary := theme.Colors[mode]["primary"]
+	if primary == "" {
+		return "", false, fmt.Errorf("no primary color in %s", path)
+	}
+	return primary, isLight, nil
+}
+
+func runShellInit(cmd *cobra.Command, args []string) {
+	shell := args[0]
+	exportsOnly, _ := cmd.Flags().GetBool("exports")
+	primary, _ := cmd.Flags().GetString("primary")
+	forceLight, _ := cmd.Flags().GetBool("light")
+
+	colorsPath := shellThemeColorsPath()
+
+	if exportsOnly || primary != "" {
+		isLight := forceLight
+		if primary == "" {
+			themePrimary, themeLight, err := readShellThemePrimary(colorsPath)
+			if err != nil {
+				log.Fatalf("Failed to read DMS theme: %v", err)
+			}
+			primary = themePrimary
+			isLight = isLight || themeLight
+		}
+		colors, err := dank16.ParsePalette(primary, dank16.PaletteOptions{IsLight: isLight, UseDPS: true})
+		if err != nil {
+			log.Fatalf("Invalid primary color: %v", err)
+		}
+		exports, err := dank16.GenerateShellExports(colors, shell)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		fmt.Print(exports)
+
+		if !exportsOnly {
+			fmt.Print(shellLSColorsSnippet(shell))
+		}
+		return
+	}
+
+	cleanupStaleShellStamps(shellStampDir())
+
+	dmsPath, err := os.Executable()
+	if err != nil {
+		dmsPath = "dms"
+	}
+
+	refresh := fmt.Sprintf("%s shell-init %s --exports", shellQuote(shell, dmsPath), shell)
+	if forceLight {
+		refresh += " --light"
+	}
+
+	switch shell {
+	case "bash", "zsh":
+		hook := `PROMPT_COMMAND="_dms_theme_refresh${PROMPT_COMMAND:+;$PROMPT_COMMAND}"`
+		guard := `[[ ";${PROMPT_COMMAND};" == *";_dms_theme_refresh;"* ]] || `
+		if shell == "zsh" {
+			hook = "autoload -Uz add-zsh-hook\nadd-zsh-hook precmd _dms_theme_refresh"
+			guard = ""
+		}
+		fmt.Printf(`# DMS shell integration
+_dms_colors_file=%s
+_dms_theme_stamp="${XDG_RUNTIME_DIR:-/tmp}/%s$$"
+_dms_theme_refresh() {
+    [[ -f "$_dms_colors_file" ]] || return 0
+    [[ -f "$_dms_theme_stamp" && ! "$_dms_colors_file" -nt "$_dms_theme_stamp" ]] && return 0
+    eval "$(%s 2>/dev/null)"
+    : > "$_dms_theme_stamp"
+}
+_dms_theme_refresh
+%s%s
+%s`, shellQuote(shell, colorsPath), shellStampPrefix, refresh, guard, hook, shellLSColorsSnippet(shell))
+	case "fish":
+		fmt.Printf(`# DMS shell integration
+set -g _dms_colors_file %s
+set -g _dms_theme_mtime 0
+function _dms_theme_refresh --on-event fish_prompt
+    test -f $_dms_colors_file; or return 0
+    set -l mtime (path mtime $_dms_colors_file)
+    test "$mtime" = "$_dms_theme_mtime"; and return 0
+    set -g _dms_theme_mtime $mtime
+    %s 2>/dev/null | source
+end
+_dms_theme_refresh
+%s`, shellQuote(shell, colorsPath), refresh, shellLSColorsSnippet(shell))
+	}
+}
+
+// shellLSColorsSnippet only fills LS_COLORS when the user hasn't set their own.
+func shellLSColorsSnippet(shell string) string {
+	if shell == "fish" {
+		return fmt.Sprintf("set -q LS_COLORS; or set -gx LS_COLORS '%s'\n", dank16.LSColors)
+	}
+	return fmt.Sprintf("[[ -n \"$LS_COLORS\" ]] || export LS_COLORS='%s'\n", dank16.LSColors)
+}
+
+func shellQuote(shell, s string) string {
+	if shell == "fish" {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
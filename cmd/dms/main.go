@@ -17,6 +17,8 @@ func init() {
 	runCmd.Flags().MarkHidden("daemon-child")
 
 	// Add subcommands to greeter
+	greeterInstallCmd.Flags().Bool("skip-validation", false, "Skip the nested greeter test-run before changing greetd config (skipped anyway outside a Wayland session)")
+	greeterEnableCmd.Flags().Bool("skip-validation", false, "Skip the nested greeter test-run before changing greetd config (skipped anyway outside a Wayland session)")
 	greeterCmd.AddCommand(greeterInstallCmd, greeterSyncCmd, greeterEnableCmd, greeterStatusCmd)
 
 	// Add subcommands to update
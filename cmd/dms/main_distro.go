@@ -18,6 +18,7 @@ func init() {
 	runCmd.Flags().MarkHidden("daemon-child")
 
 	// Add subcommands to greeter
+	greeterEnableCmd.Flags().Bool("skip-validation", false, "Skip the nested greeter test-run before changing greetd config (skipped anyway outside a Wayland session)")
 	greeterCmd.AddCommand(greeterSyncCmd, greeterEnableCmd, greeterStatusCmd)
 
 	// Add subcommands to plugins
@@ -12,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/greeter"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/internal/server"
 )
@@ -144,6 +145,8 @@ func runShellInteractive(session bool) {
 	}
 	defer os.Remove(configStateFile)
 
+	startSessionKeyring()
+
 	errChan := make(chan error, 2)
 
 	go func() {
@@ -222,6 +225,18 @@ func runShellInteractive(session bool) {
 	}
 }
 
+// startSessionKeyring hands a keyring PAM unlocked at login to the session
+// before the server and quickshell start, so both inherit it
+func startSessionKeyring() {
+	started, err := greeter.StartSessionKeyring()
+	if err != nil {
+		log.Warnf("Failed to connect to the login keyring: %v", err)
+	}
+	for _, name := range started {
+		log.Infof("Connected to %s unlocked at login", name)
+	}
+}
+
 func restartShell() {
 	pids := getAllDMSPIDs()
 
@@ -355,6 +370,8 @@ func runShellDaemon(session bool) {
 	}
 	defer os.Remove(configStateFile)
 
+	startSessionKeyring()
+
 	errChan := make(chan error, 2)
 
 	go func() {
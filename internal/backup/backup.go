@@ -0,0 +1,400 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// FormatVersion is bumped when the archive layout changes incompatibly
+const FormatVersion = 1
+
+const (
+	manifestName = "manifest.json"
+	maxFileSize  = 16 << 20
+	// defaultMode is used for files whose mode was not recorded, erring on
+	// the side of keeping a config private
+	defaultMode fs.FileMode = 0600
+)
+
+// Source is a directory whose files are carried in the archive under Name.
+// Include limits it to specific relative paths; Exclude skips subtrees.
+type Source struct {
+	Name    string
+	Dir     string
+	Include []string
+	Exclude []string
+}
+
+type FileEntry struct {
+	Source string      `json:"source"`
+	Path   string      `json:"path"`
+	Mode   fs.FileMode `json:"mode"`
+	Size   int64       `json:"size"`
+}
+
+type Manifest struct {
+	Version   int         `json:"version"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Hostname  string      `json:"hostname,omitempty"`
+	Files     []FileEntry `json:"files"`
+}
+
+// RestoreResult lists what was written and where previous copies were kept
+type RestoreResult struct {
+	Manifest Manifest `json:"manifest"`
+	Restored []string `json:"restored"`
+	Backups  []string `json:"backups,omitempty"`
+	Skipped  []string `json:"skipped,omitempty"`
+}
+
+type Archiver struct {
+	fs      afero.Fs
+	sources []Source
+}
+
+func NewArchiver() *Archiver {
+	return NewArchiverWithFs(afero.NewOsFs(), DefaultSources())
+}
+
+func NewArchiverWithFs(fs afero.Fs, sources []Source) *Archiver {
+	return &Archiver{fs: fs, sources: sources}
+}
+
+// DefaultSources covers shell settings including daemon.toml, session
+// state, the generated theme and the files the installer deploys. Niri and
+// Hyprland configs are deployed whole, not as blocks inside a user's file,
+// so the whole file is carried; restore keeps the replaced one as a backup.
+// Brightness keeps no scenes of its own, only the step curves in
+// daemon.toml. Installed plugins are left out since they are reinstalled
+// from the registry.
+func DefaultSources() []Source {
+	homeDir, _ := os.UserHomeDir()
+	configHome := xdgDir("XDG_CONFIG_HOME", filepath.Join(homeDir, ".config"))
+	stateHome := xdgDir("XDG_STATE_HOME", filepath.Join(homeDir, ".local", "state"))
+	cacheHome := xdgDir("XDG_CACHE_HOME", filepath.Join(homeDir, ".cache"))
+
+	return []Source{
+		{Name: "config", Dir: filepath.Join(configHome, "DankMaterialShell"), Exclude: []string{"plugins"}},
+		{Name: "state", Dir: filepath.Join(stateHome, "DankMaterialShell")},
+		{Name: "theme", Dir: filepath.Join(cacheHome, "quickshell", "dankshell"), Include: []string{"dms-colors.json"}},
+		{Name: "managed", Dir: configHome, Include: []string{
+			"niri/config.kdl",
+			"hypr/hyprland.conf",
+			"ghostty/config-dankcolors",
+			"kitty/dank-theme.conf",
+			"kitty/dank-tabs.conf",
+			"alacritty/dank-theme.toml",
+		}},
+	}
+}
+
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); dir != "" {
+		return dir
+	}
+	return fallback
+}
+
+// Create writes a gzipped tar with a manifest followed by every file found in
+// the sources. Missing sources are skipped.
+func (a *Archiver) Create(w io.Writer) (*Manifest, error) {
+	hostname, _ := os.Hostname()
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Hostname:  hostname,
+	}
+
+	type pending struct {
+		entry FileEntry
+		path  string
+	}
+	var files []pending
+
+	for _, src := range a.sources {
+		err := a.walkSource(src, func(rel string, info fs.FileInfo) {
+			files = append(files, pending{
+				entry: FileEntry{Source: src.Name, Path: rel, Mode: info.Mode().Perm(), Size: info.Size()},
+				path:  filepath.Join(src.Dir, filepath.FromSlash(rel)),
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", src.Dir, err)
+		}
+	}
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.entry)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, manifestName, 0644, manifestData); err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		data, err := afero.ReadFile(a.fs, f.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+		if err := writeTarFile(tw, path.Join(f.entry.Source, f.entry.Path), f.entry.Mode, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (a *Archiver) walkSource(src Source, fn func(rel string, info fs.FileInfo)) error {
+	if len(src.Include) > 0 {
+		for _, rel := range src.Include {
+			info, err := a.fs.Stat(filepath.Join(src.Dir, filepath.FromSlash(rel)))
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			fn(rel, info)
+		}
+		return nil
+	}
+
+	if _, err := a.fs.Stat(src.Dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return afero.Walk(a.fs, src.Dir, func(p string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src.Dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			for _, ex := range src.Exclude {
+				if rel == ex {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		// Leave out copies made by earlier restores and config deploys
+		if !info.Mode().IsRegular() || info.Size() > maxFileSize || strings.Contains(info.Name(), ".backup.") {
+			return nil
+		}
+		fn(rel, info)
+		return nil
+	})
+}
+
+func writeTarFile(tw *tar.Writer, name string, mode fs.FileMode, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    int64(mode),
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Restore unpacks an archive made by Create into the matching sources. Files
+// that already exist with different content are kept as .backup.<timestamp>.
+func (a *Archiver) Restore(r io.Reader) (*RestoreResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a DMS backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != manifestName {
+		return nil, fmt.Errorf("not a DMS backup archive: missing %s", manifestName)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(io.LimitReader(tr, maxFileSize)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestName, err)
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("backup format %d is newer than supported version %d", manifest.Version, FormatVersion)
+	}
+
+	sources := make(map[string]Source, len(a.sources))
+	for _, src := range a.sources {
+		sources[src.Name] = src
+	}
+	modes := make(map[string]fs.FileMode, len(manifest.Files))
+	for _, f := range manifest.Files {
+		modes[path.Join(f.Source, f.Path)] = f.Mode.Perm()
+	}
+
+	result := &RestoreResult{Manifest: manifest}
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name, rel, ok := strings.Cut(hdr.Name, "/")
+		src, known := sources[name]
+		if !ok || !known || !validRelPath(rel) || !src.allows(rel) {
+			result.Skipped = append(result.Skipped, hdr.Name)
+			continue
+		}
+		if hdr.Size > maxFileSize {
+			return result, fmt.Errorf("%s exceeds %d bytes", hdr.Name, maxFileSize)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+
+		mode, ok := modes[hdr.Name]
+		if !ok {
+			mode = fs.FileMode(hdr.Mode).Perm()
+		}
+		if mode == 0 {
+			mode = defaultMode
+		}
+
+		dest := filepath.Join(src.Dir, filepath.FromSlash(rel))
+		if existing, err := afero.ReadFile(a.fs, dest); err == nil {
+			info, err := a.fs.Stat(dest)
+			if err != nil {
+				return result, fmt.Errorf("failed to stat %s: %w", dest, err)
+			}
+			if bytes.Equal(existing, data) && info.Mode().Perm() == mode {
+				continue
+			}
+			backupPath := dest + ".backup." + timestamp
+			if err := a.writeFileMode(backupPath, existing, info.Mode().Perm()); err != nil {
+				return result, fmt.Errorf("failed to back up %s: %w", dest, err)
+			}
+			result.Backups = append(result.Backups, backupPath)
+		}
+
+		if err := a.fs.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return result, fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+		}
+		if err := a.writeFileMode(dest, data, mode); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+		result.Restored = append(result.Restored, dest)
+	}
+
+	return result, nil
+}
+
+// writeFileMode writes data with exactly mode. WriteFile alone keeps the
+// mode of a file that already exists, and the umask can drop bits.
+func (a *Archiver) writeFileMode(name string, data []byte, mode fs.FileMode) error {
+	if err := afero.WriteFile(a.fs, name, data, mode); err != nil {
+		return err
+	}
+	return a.fs.Chmod(name, mode)
+}
+
+func validRelPath(rel string) bool {
+	if rel == "" || path.IsAbs(rel) {
+		return false
+	}
+	clean := path.Clean(rel)
+	return clean == rel && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+func (s Source) allows(rel string) bool {
+	if len(s.Include) > 0 {
+		for _, inc := range s.Include {
+			if inc == rel {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ex := range s.Exclude {
+		if rel == ex || strings.HasPrefix(rel, ex+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateFile writes an archive to dest atomically
+func (a *Archiver) CreateFile(dest string) (*Manifest, error) {
+	if err := a.fs.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, err
+	}
+	tmp := dest + ".tmp"
+	f, err := a.fs.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := a.Create(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		a.fs.Remove(tmp)
+		return nil, err
+	}
+	if err := a.fs.Rename(tmp, dest); err != nil {
+		a.fs.Remove(tmp)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (a *Archiver) RestoreFile(src string) (*RestoreResult, error) {
+	f, err := a.fs.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return a.Restore(f)
+}
+
+// DefaultArchiveName is used when no destination is given
+func DefaultArchiveName(now time.Time) string {
+	return "dms-backup-" + now.Format("2006-01-02_15-04-05") + ".tar.gz"
+}
@@ -0,0 +1,118 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSources() []Source {
+	return []Source{
+		{Name: "config", Dir: "/home/a/.config/DankMaterialShell", Exclude: []string{"plugins"}},
+		{Name: "theme", Dir: "/home/a/.cache/quickshell/dankshell", Include: []string{"dms-colors.json"}},
+	}
+}
+
+func TestCreateRestore_RoundTrip(t *testing.T) {
+	src := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(src, "/home/a/.config/DankMaterialShell/settings.json", []byte(`{"a":1}`), 0644))
+	require.NoError(t, afero.WriteFile(src, "/home/a/.config/DankMaterialShell/plugins/foo/qmldir", []byte("x"), 0644))
+	require.NoError(t, afero.WriteFile(src, "/home/a/.cache/quickshell/dankshell/dms-colors.json", []byte(`{}`), 0644))
+	require.NoError(t, afero.WriteFile(src, "/home/a/.cache/quickshell/dankshell/other.json", []byte(`{}`), 0644))
+
+	var buf bytes.Buffer
+	manifest, err := NewArchiverWithFs(src, testSources()).Create(&buf)
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "settings.json", manifest.Files[0].Path)
+	assert.Equal(t, "dms-colors.json", manifest.Files[1].Path)
+
+	dst := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(dst, "/home/a/.config/DankMaterialShell/settings.json", []byte(`{"a":0}`), 0644))
+
+	result, err := NewArchiverWithFs(dst, testSources()).Restore(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Len(t, result.Restored, 2)
+	require.Len(t, result.Backups, 1)
+
+	data, err := afero.ReadFile(dst, "/home/a/.config/DankMaterialShell/settings.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(data))
+
+	old, err := afero.ReadFile(dst, result.Backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":0}`, string(old))
+}
+
+func TestRestore_RejectsUnsafePaths(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, writeTarFile(tw, manifestName, 0644, []byte(`{"version":1}`)))
+	require.NoError(t, writeTarFile(tw, "config/../../../etc/passwd", 0644, []byte("x")))
+	require.NoError(t, writeTarFile(tw, "theme/other.json", 0644, []byte("x")))
+	require.NoError(t, writeTarFile(tw, "unknown/file", 0644, []byte("x")))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	fs := afero.NewMemMapFs()
+	result, err := NewArchiverWithFs(fs, testSources()).Restore(&buf)
+	require.NoError(t, err)
+	assert.Empty(t, result.Restored)
+	assert.Len(t, result.Skipped, 3)
+
+	exists, _ := afero.Exists(fs, "/etc/passwd")
+	assert.False(t, exists)
+}
+
+func TestRestore_NotAnArchive(t *testing.T) {
+	_, err := NewArchiverWithFs(afero.NewMemMapFs(), testSources()).Restore(bytes.NewReader([]byte("nope")))
+	assert.Error(t, err)
+}
+
+func TestDefaultSources_CarryCompositorConfigs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/a/.config")
+
+	var managed []string
+	for _, src := range DefaultSources() {
+		if src.Name == "managed" {
+			assert.Equal(t, "/home/a/.config", src.Dir)
+			managed = src.Include
+		}
+	}
+	assert.Contains(t, managed, "niri/config.kdl")
+	assert.Contains(t, managed, "hypr/hyprland.conf")
+}
+
+func TestCreateRestore_KeepsFileModes(t *testing.T) {
+	src := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(src, "/home/a/.config/DankMaterialShell/settings.json", []byte(`{"a":1}`), 0644))
+	require.NoError(t, afero.WriteFile(src, "/home/a/.config/DankMaterialShell/secrets.json", []byte(`{"token":"new"}`), 0600))
+
+	var buf bytes.Buffer
+	_, err := NewArchiverWithFs(src, testSources()).Create(&buf)
+	require.NoError(t, err)
+
+	dst := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(dst, "/home/a/.config/DankMaterialShell/secrets.json", []byte(`{"token":"old"}`), 0640))
+
+	result, err := NewArchiverWithFs(dst, testSources()).Restore(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, result.Backups, 1)
+
+	for file, mode := range map[string]fs.FileMode{
+		"/home/a/.config/DankMaterialShell/settings.json": 0644,
+		"/home/a/.config/DankMaterialShell/secrets.json":  0600,
+		result.Backups[0]: 0640,
+	} {
+		info, err := dst.Stat(file)
+		require.NoError(t, err)
+		assert.Equal(t, mode, info.Mode().Perm(), file)
+	}
+}
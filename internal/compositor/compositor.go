@@ -0,0 +1,53 @@
+// Package compositor finds the running niri or Hyprland instance and talks
+// to it: hyprctl and socket2 for Hyprland, niri msg and its event stream for
+// niri. Server modules that follow or move windows share it so each does not
+// carry its own copy of the plumbing.
+package compositor
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type Kind string
+
+const (
+	None     Kind = ""
+	Niri     Kind = "niri"
+	Hyprland Kind = "hyprland"
+)
+
+// Detect names the compositor from the variables it exports to its session
+func Detect(getenv func(string) string) Kind {
+	switch {
+	case getenv("NIRI_SOCKET") != "":
+		return Niri
+	case getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return Hyprland
+	}
+	return None
+}
+
+// Run executes a compositor CLI such as hyprctl or niri and returns its
+// stdout, so JSON replies parse cleanly. Errors carry the command and what
+// it printed.
+func Run(name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(string(out))
+		}
+		if msg == "" {
+			return out, fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+		}
+		return out, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, msg)
+	}
+	return out, nil
+}
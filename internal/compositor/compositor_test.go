@@ -0,0 +1,79 @@
+package compositor
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	assert.Equal(t, Niri, Detect(env(map[string]string{"NIRI_SOCKET": "/run/niri.sock"})))
+	assert.Equal(t, Hyprland, Detect(env(map[string]string{"HYPRLAND_INSTANCE_SIGNATURE": "abc"})))
+	assert.Equal(t, None, Detect(env(nil)))
+}
+
+func TestRun(t *testing.T) {
+	out, err := Run("sh", "-c", "echo '{}'; echo noise >&2")
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(out), "stderr stays out of the reply")
+
+	_, err = Run("sh", "-c", "echo 'no such window' >&2; exit 1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such window")
+}
+
+func TestWatchHyprland(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "sig")
+
+	dir := filepath.Join(runtimeDir, "hypr", "sig")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	listener, err := net.Listen("unix", filepath.Join(dir, ".socket2.sock"))
+	require.NoError(t, err)
+	defer listener.Close()
+	assert.Equal(t, filepath.Join(dir, ".socket2.sock"), HyprlandEventSocket())
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("workspace>>2\nopenwindow>>abc,2,kitty,title\n"))
+		conn.Close()
+	}()
+
+	var events []string
+	require.NoError(t, WatchHyprland(make(chan struct{}), func(event string) {
+		events = append(events, event)
+	}))
+	assert.Equal(t, []string{"workspace>>2", "openwindow>>abc,2,kitty,title"}, events)
+}
+
+func TestWatchNiri(t *testing.T) {
+	original := niriCommand
+	defer func() { niriCommand = original }()
+	niriCommand = func() *exec.Cmd {
+		return exec.Command("printf", `{"WorkspacesChanged":{}}\n{"WindowClosed":{"id":1}}\n`)
+	}
+
+	var events []string
+	require.NoError(t, WatchNiri(make(chan struct{}), func(event []byte) {
+		events = append(events, string(event))
+	}))
+	assert.Equal(t, []string{`{"WorkspacesChanged":{}}`, `{"WindowClosed":{"id":1}}`}, events)
+
+	niriCommand = func() *exec.Cmd { return exec.Command("sleep", "10") }
+	stop := make(chan struct{})
+	close(stop)
+	assert.NoError(t, WatchNiri(stop, func([]byte) {}), "closing stop ends the stream")
+}
@@ -0,0 +1,48 @@
+package compositor
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// HyprlandEventSocket is socket2 of the running instance. Hyprland 0.40 and
+// newer keep it under $XDG_RUNTIME_DIR, older releases under /tmp.
+func HyprlandEventSocket() string {
+	sig := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		path := filepath.Join(runtimeDir, "hypr", sig, ".socket2.sock")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join("/tmp", "hypr", sig, ".socket2.sock")
+}
+
+// WatchHyprland passes every socket2 line, such as "openwindow>>...", to
+// handle until stop is closed or Hyprland drops the connection
+func WatchHyprland(stop <-chan struct{}, handle func(event string)) error {
+	conn, err := net.Dial("unix", HyprlandEventSocket())
+	if err != nil {
+		return fmt.Errorf("failed to connect to Hyprland events: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			conn.Close()
+		case <-done:
+		}
+	}()
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		handle(scanner.Text())
+	}
+	return scanner.Err()
+}
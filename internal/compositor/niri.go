@@ -0,0 +1,46 @@
+package compositor
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+)
+
+// niriCommand is swapped in tests for something that prints events
+var niriCommand = func() *exec.Cmd {
+	return exec.Command("niri", "msg", "--json", "event-stream")
+}
+
+// WatchNiri passes every line of `niri msg --json event-stream` to handle
+// until stop is closed or niri goes away. The line is only valid during
+// the call.
+func WatchNiri(stop <-chan struct{}, handle func(event []byte)) error {
+	cmd := niriCommand()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start niri event stream: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		handle(scanner.Bytes())
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	return scanner.Err()
+}
@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/outputs"
 )
 
 type ConfigDeployer struct {
-	logChan chan<- string
+	logChan      chan<- string
+	probeOutputs func() ([]outputs.Info, error)
 }
 
 type DeploymentResult struct {
@@ -26,7 +28,8 @@ type DeploymentResult struct {
 
 func NewConfigDeployer(logChan chan<- string) *ConfigDeployer {
 	return &ConfigDeployer{
-		logChan: logChan,
+		logChan:      logChan,
+		probeOutputs: outputs.Query,
 	}
 }
 
@@ -167,7 +170,9 @@ func (cd *ConfigDeployer) deployNiriConfig(terminal deps.Terminal) (DeploymentRe
 	newConfig = strings.ReplaceAll(newConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
 
 	// If there was an existing config, merge the output sections
-	if existingConfig != "" {
+	if existingConfig == "" {
+		newConfig = addNiriScaleSuggestions(newConfig, cd.hiDPIOutputs())
+	} else {
 		mergedConfig, err := cd.mergeNiriOutputSections(newConfig, existingConfig)
 		if err != nil {
 			cd.log(fmt.Sprintf("Warning: Failed to merge output sections: %v", err))
@@ -509,7 +514,9 @@ func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal) (Deployme
 	newConfig = strings.ReplaceAll(newConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
 
 	// If there was an existing config, merge the monitor sections
-	if existingConfig != "" {
+	if existingConfig == "" {
+		newConfig = addHyprlandScaleSuggestions(newConfig, cd.hiDPIOutputs())
+	} else {
 		mergedConfig, err := cd.mergeHyprlandMonitorSections(newConfig, existingConfig)
 		if err != nil {
 			cd.log(fmt.Sprintf("Warning: Failed to merge monitor sections: %v", err))
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/outputs"
+)
+
+var (
+	niriExampleOutputRegex      = regexp.MustCompile(`(?m)^/-output "eDP-2" \{[^{}]*(?:\{[^{}]*\}[^{}]*)*\}\n`)
+	hyprlandExampleMonitorRegex = regexp.MustCompile(`(?m)^# monitor = eDP-2.*\n`)
+)
+
+// hiDPIOutputs returns connected outputs dense enough to want a scale above 1.
+// Errors are ignored since the wizard may run outside a Wayland session.
+func (cd *ConfigDeployer) hiDPIOutputs() []outputs.Info {
+	if cd.probeOutputs == nil {
+		return nil
+	}
+	infos, err := cd.probeOutputs()
+	if err != nil {
+		return nil
+	}
+
+	var hiDPI []outputs.Info
+	for _, out := range infos {
+		if out.Name != "" && out.SuggestedScale > 1 {
+			hiDPI = append(hiDPI, out)
+		}
+	}
+	return hiDPI
+}
+
+// addNiriScaleSuggestions replaces the example output with commented
+// outputs carrying a proposed scale for each HiDPI monitor.
+func addNiriScaleSuggestions(config string, infos []outputs.Info) string {
+	if len(infos) == 0 {
+		return config
+	}
+
+	var b strings.Builder
+	for _, out := range infos {
+		fmt.Fprintf(&b, "// %s is %.0f DPI, a scale of %g is suggested\n", out.Name, out.DPI, out.SuggestedScale)
+		fmt.Fprintf(&b, "/-output %q {\n    scale %g\n}\n", out.Name, out.SuggestedScale)
+	}
+	return replaceFirst(niriExampleOutputRegex, config, b.String())
+}
+
+// addHyprlandScaleSuggestions does the same for the Hyprland monitor section
+func addHyprlandScaleSuggestions(config string, infos []outputs.Info) string {
+	if len(infos) == 0 {
+		return config
+	}
+
+	var b strings.Builder
+	for _, out := range infos {
+		fmt.Fprintf(&b, "# %s is %.0f DPI, a scale of %g is suggested\n", out.Name, out.DPI, out.SuggestedScale)
+		fmt.Fprintf(&b, "# monitor = %s, preferred, auto, %g\n", out.Name, out.SuggestedScale)
+	}
+	return replaceFirst(hyprlandExampleMonitorRegex, config, b.String())
+}
+
+func replaceFirst(re *regexp.Regexp, s, replacement string) string {
+	loc := re.FindStringIndex(s)
+	if loc == nil {
+		return s
+	}
+	return s[:loc[0]] + replacement + s[loc[1]:]
+}
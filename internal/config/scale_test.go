@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/outputs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleSuggestions(t *testing.T) {
+	cd := &ConfigDeployer{probeOutputs: func() ([]outputs.Info, error) {
+		return []outputs.Info{
+			{Name: "eDP-1", DPI: 242, SuggestedScale: 2.5},
+			{Name: "HDMI-A-1", DPI: 92, SuggestedScale: 1},
+		}, nil
+	}}
+	outputs := cd.hiDPIOutputs()
+	assert.Len(t, outputs, 1)
+
+	niri := addNiriScaleSuggestions(NiriConfig, outputs)
+	assert.NotContains(t, niri, `/-output "eDP-2"`)
+	assert.Contains(t, niri, "/-output \"eDP-1\" {\n    scale 2.5\n}")
+	assert.Contains(t, niri, "eDP-1 is 242 DPI")
+
+	hypr := addHyprlandScaleSuggestions(HyprlandConfig, outputs)
+	assert.NotContains(t, hypr, "# monitor = eDP-2")
+	assert.Contains(t, hypr, "# monitor = eDP-1, preferred, auto, 2.5\n")
+	assert.Contains(t, hypr, "monitor = , preferred,auto,auto", "the catch-all monitor line stays")
+
+	assert.Equal(t, NiriConfig, addNiriScaleSuggestions(NiriConfig, nil))
+}
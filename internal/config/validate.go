@@ -0,0 +1,676 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Severity string
+
+const (
+	// SeverityError is a problem the program will refuse or that breaks
+	// the session, e.g. a placeholder spawned as a command
+	SeverityError Severity = "error"
+	// SeverityWarning is accepted but probably not what was meant
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is one finding in a config file. Line is 1-based, 0 when the
+// problem concerns the whole file.
+type Problem struct {
+	ConfigType string   `json:"configType"`
+	Path       string   `json:"path"`
+	Line       int      `json:"line,omitempty"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", p.Path, p.Line, p.Severity, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Severity, p.Message)
+}
+
+// ConfigFile is a config the deployer writes, named like its
+// DeploymentResult
+type ConfigFile struct {
+	ConfigType string `json:"configType"`
+	Path       string `json:"path"`
+}
+
+// ValidationReport lists which deployed configs were found and what is
+// wrong with them
+type ValidationReport struct {
+	Checked  []ConfigFile `json:"checked"`
+	Problems []Problem    `json:"problems"`
+}
+
+func (r ValidationReport) HasErrors() bool {
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// DeployedConfigFiles returns every file the deployer can write under home
+func DeployedConfigFiles(home string) []ConfigFile {
+	dir := filepath.Join(home, ".config")
+	return []ConfigFile{
+		{"Niri", filepath.Join(dir, "niri", "config.kdl")},
+		{"Hyprland", filepath.Join(dir, "hypr", "hyprland.conf")},
+		{"Ghostty", filepath.Join(dir, "ghostty", "config")},
+		{"Ghostty Colors", filepath.Join(dir, "ghostty", "config-dankcolors")},
+		{"Kitty", filepath.Join(dir, "kitty", "kitty.conf")},
+		{"Kitty Theme", filepath.Join(dir, "kitty", "dank-theme.conf")},
+		{"Kitty Tabs", filepath.Join(dir, "kitty", "dank-tabs.conf")},
+		{"Alacritty", filepath.Join(dir, "alacritty", "alacritty.toml")},
+		{"Alacritty Theme", filepath.Join(dir, "alacritty", "dank-theme.toml")},
+	}
+}
+
+// Validator checks compositor and terminal configs for the mistakes that
+// otherwise only show up after logging in: placeholders the deployer did
+// not fill, duplicate key binds, malformed colors and missing includes.
+type Validator struct {
+	home   string
+	exists func(path string) bool
+}
+
+func NewValidator(home string) *Validator {
+	return &Validator{
+		home: home,
+		exists: func(path string) bool {
+			_, err := os.Stat(path)
+			return err == nil
+		},
+	}
+}
+
+// ValidateDeployed checks the deployed configs that exist. Configs of
+// compositors and terminals that are not in use are simply absent.
+func (v *Validator) ValidateDeployed() ValidationReport {
+	report := ValidationReport{Checked: []ConfigFile{}, Problems: []Problem{}}
+	for _, f := range DeployedConfigFiles(v.home) {
+		if _, err := os.Stat(f.Path); os.IsNotExist(err) {
+			continue
+		}
+		report.Checked = append(report.Checked, f)
+		report.Problems = append(report.Problems, v.ValidateFile(f.ConfigType, f.Path)...)
+	}
+	return report
+}
+
+// ValidateFile reads and checks one config
+func (v *Validator) ValidateFile(configType, path string) []Problem {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Problem{{
+			ConfigType: configType,
+			Path:       path,
+			Severity:   SeverityError,
+			Message:    fmt.Sprintf("cannot be read: %v", err),
+		}}
+	}
+	return v.Validate(configType, path, string(data))
+}
+
+// Validate checks content as the given config type
+func (v *Validator) Validate(configType, path, content string) []Problem {
+	c := &checker{configType: configType, path: path, validator: v}
+	lines := strings.Split(content, "\n")
+	c.checkPlaceholders(lines)
+
+	switch configType {
+	case "Niri":
+		c.checkNiri(lines)
+	case "Hyprland":
+		c.checkHyprland(lines)
+	case "Ghostty", "Ghostty Colors":
+		c.checkGhostty(lines)
+	case "Kitty", "Kitty Theme", "Kitty Tabs":
+		c.checkKitty(lines)
+	case "Alacritty", "Alacritty Theme":
+		c.checkAlacritty(lines)
+	}
+
+	sort.SliceStable(c.problems, func(i, j int) bool { return c.problems[i].Line < c.problems[j].Line })
+	return c.problems
+}
+
+// expandHome resolves ~ the way the programs reading these configs do
+func (v *Validator) expandHome(path string) string {
+	if path == "~" {
+		return v.home
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(v.home, path[2:])
+	}
+	return path
+}
+
+type checker struct {
+	configType string
+	path       string
+	validator  *Validator
+	problems   []Problem
+}
+
+func (c *checker) add(line int, severity Severity, format string, args ...any) {
+	c.problems = append(c.problems, Problem{
+		ConfigType: c.configType,
+		Path:       c.path,
+		Line:       line,
+		Severity:   severity,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// checkInclude reports a referenced file that does not exist. Relative
+// paths are resolved against the including file.
+func (c *checker) checkInclude(line int, target string) {
+	target = strings.Trim(strings.TrimSpace(target), `"'`)
+	if target == "" || strings.ContainsAny(target, "*?$") {
+		return
+	}
+	target = c.validator.expandHome(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(c.path), target)
+	}
+	if !c.validator.exists(target) {
+		c.add(line, SeverityError, "included file %s does not exist", target)
+	}
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*[A-Za-z_][A-Za-z0-9_.]*\s*\}\}`)
+
+func (c *checker) checkPlaceholders(lines []string) {
+	for i, line := range lines {
+		for _, m := range placeholderPattern.FindAllString(line, -1) {
+			c.add(i+1, SeverityError, "placeholder %s was never substituted", m)
+		}
+	}
+}
+
+// bindSeen remembers where each normalized key combination was first bound
+type bindSeen map[string]int
+
+func (c *checker) checkDuplicate(seen bindSeen, key, display string, line int, severity Severity) {
+	if first, ok := seen[key]; ok {
+		c.add(line, severity, "%s is already bound on line %d", display, first)
+		return
+	}
+	seen[key] = line
+}
+
+// modAliases maps the spellings compositors accept to one name
+var modAliases = map[string]string{
+	"control": "ctrl",
+	"ctl":     "ctrl",
+	"win":     "super",
+	"mod4":    "super",
+	"logo":    "super",
+	"meta":    "super",
+	"mod1":    "alt",
+	"option":  "alt",
+	"opt":     "alt",
+	"cmd":     "super",
+	"command": "super",
+}
+
+// normalizeCombo turns modifiers and a key into a comparable string
+func normalizeCombo(mods []string, key string) string {
+	normalized := make([]string, 0, len(mods))
+	for _, m := range mods {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if m == "" {
+			continue
+		}
+		if alias, ok := modAliases[m]; ok {
+			m = alias
+		}
+		normalized = append(normalized, m)
+	}
+	sort.Strings(normalized)
+	return strings.Join(append(normalized, strings.ToLower(strings.TrimSpace(key))), "+")
+}
+
+// Colors
+
+var hexDigits = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+
+func isHex(s string, lengths ...int) bool {
+	if !hexDigits.MatchString(s) {
+		return false
+	}
+	for _, n := range lengths {
+		if len(s) == n {
+			return true
+		}
+	}
+	return false
+}
+
+var colorName = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// validCSSColor accepts what niri's CSS color parser takes: hex, named
+// colors and functional notation
+func validCSSColor(s string) bool {
+	switch {
+	case strings.HasPrefix(s, "#"):
+		return isHex(s[1:], 3, 4, 6, 8)
+	case colorName.MatchString(s):
+		return true
+	case strings.Contains(s, "("):
+		return strings.HasSuffix(s, ")")
+	}
+	return false
+}
+
+// KDL
+
+// stripKDL removes strings and comments from a line so braces can be
+// counted. inComment carries an unterminated /* */ across lines.
+func stripKDL(line string, inComment *bool) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if *inComment {
+			if strings.HasPrefix(line[i:], "*/") {
+				*inComment = false
+				i++
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line[i:], "/*"):
+			*inComment = true
+			i++
+		case strings.HasPrefix(line[i:], "//"):
+			return b.String()
+		case line[i] == '"':
+			b.WriteString(`""`)
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return b.String()
+}
+
+var (
+	niriColorKey  = regexp.MustCompile(`^\s*((?:[a-z]+-)*color)\s+"([^"]*)"`)
+	niriColorAttr = regexp.MustCompile(`\b(from|to)="([^"]*)"`)
+)
+
+func (c *checker) checkNiri(lines []string) {
+	depth, bindsDepth := 0, -1
+	inComment := false
+	seen := bindSeen{}
+
+	for i, raw := range lines {
+		lineNo := i + 1
+		code := strings.TrimSpace(stripKDL(raw, &inComment))
+		if code == "" {
+			continue
+		}
+		disabled := strings.HasPrefix(code, "/-")
+
+		if bindsDepth >= 0 && depth == bindsDepth+1 && !disabled && !strings.HasPrefix(code, "}") {
+			if fields := strings.FieldsFunc(code, func(r rune) bool { return r == ' ' || r == '\t' || r == '{' }); len(fields) > 0 {
+				combo := fields[0]
+				parts := strings.Split(combo, "+")
+				c.checkDuplicate(seen, normalizeCombo(parts[:len(parts)-1], parts[len(parts)-1]), combo, lineNo, SeverityError)
+			}
+		}
+		if bindsDepth < 0 && depth == 0 && strings.HasPrefix(code, "binds") && strings.Contains(code, "{") {
+			bindsDepth = 0
+		}
+
+		if !disabled {
+			if m := niriColorKey.FindStringSubmatch(raw); m != nil && !validCSSColor(m[2]) {
+				c.add(lineNo, SeverityError, "%s %q is not a valid color", m[1], m[2])
+			}
+			for _, m := range niriColorAttr.FindAllStringSubmatch(raw, -1) {
+				if !validCSSColor(m[2]) {
+					c.add(lineNo, SeverityError, "gradient %s %q is not a valid color", m[1], m[2])
+				}
+			}
+		}
+
+		for _, r := range code {
+			switch r {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth < 0 {
+					c.add(lineNo, SeverityError, "unmatched closing brace")
+					depth = 0
+				}
+				if depth == bindsDepth {
+					bindsDepth = -1
+				}
+			}
+		}
+	}
+	if depth > 0 {
+		c.add(len(lines), SeverityError, "%d unclosed brace(s) at end of file", depth)
+	}
+}
+
+// Hyprland
+
+var (
+	hyprVarDef = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+	hyprVarUse = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+	hyprBind   = regexp.MustCompile(`^(bind|unbind)([a-z]*)\s*=\s*(.*)$`)
+	hyprColor  = regexp.MustCompile(`^(rgba?)\((.*)\)$`)
+)
+
+func stripHyprComment(line string) string {
+	// ## escapes a literal #
+	for i := 0; i < len(line); i++ {
+		if line[i] != '#' {
+			continue
+		}
+		if i+1 < len(line) && line[i+1] == '#' {
+			i++
+			continue
+		}
+		return line[:i]
+	}
+	return line
+}
+
+// validHyprColor accepts rgb(RRGGBB), rgba(RRGGBBAA), their decimal forms
+// and 0xAARRGGBB
+func validHyprColor(s string) bool {
+	if strings.HasPrefix(s, "0x") {
+		return isHex(s[2:], 8)
+	}
+	m := hyprColor.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	if !strings.Contains(m[2], ",") {
+		if m[1] == "rgb" {
+			return isHex(m[2], 6)
+		}
+		return isHex(m[2], 8)
+	}
+	parts := strings.Split(m[2], ",")
+	if len(parts) != len(m[1]) {
+		return false
+	}
+	for i, p := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || n < 0 || (i < 3 && n > 255) || (i == 3 && n > 1) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitHyprColors splits a gradient on the spaces between colors, keeping
+// rgba(0, 0, 0, 0.5) together
+func splitHyprColors(value string) []string {
+	var tokens []string
+	depth, start := 0, -1
+	for i, r := range value + " " {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case (r == ' ' || r == '\t') && depth == 0:
+			if start >= 0 {
+				tokens = append(tokens, strings.ReplaceAll(value[start:i], " ", ""))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return tokens
+}
+
+func isHyprColorKey(key string) bool {
+	key = key[strings.LastIndex(key, ":")+1:]
+	return key == "color" || strings.HasPrefix(key, "col.") || strings.HasPrefix(key, "color_")
+}
+
+func (c *checker) checkHyprland(lines []string) {
+	vars := map[string]string{}
+	for _, raw := range lines {
+		if m := hyprVarDef.FindStringSubmatch(strings.TrimSpace(stripHyprComment(raw))); m != nil {
+			vars[m[1]] = strings.TrimSpace(m[2])
+		}
+	}
+	expand := func(s string) string {
+		return hyprVarUse.ReplaceAllStringFunc(s, func(v string) string {
+			if value, ok := vars[v[1:]]; ok {
+				return value
+			}
+			return v
+		})
+	}
+
+	seen := bindSeen{}
+	submap := ""
+	reported := map[string]bool{}
+	for i, raw := range lines {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripHyprComment(raw))
+		if line == "" || hyprVarDef.MatchString(line) {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		// Commands may use shell variables, which Hyprland passes through
+		checked := value
+		if strings.HasPrefix(key, "exec") || strings.HasPrefix(key, "env") {
+			checked = ""
+		} else if hyprBind.MatchString(line) {
+			// Only the key combination; the rest may be a command
+			fields := strings.SplitN(value, ",", 3)
+			checked = strings.Join(fields[:min(2, len(fields))], ",")
+		}
+		for _, m := range hyprVarUse.FindAllStringSubmatch(checked, -1) {
+			if _, ok := vars[m[1]]; !ok && !reported[m[1]] {
+				reported[m[1]] = true
+				c.add(lineNo, SeverityWarning, "variable $%s is not defined in this file", m[1])
+			}
+		}
+
+		switch {
+		case key == "source":
+			c.checkInclude(lineNo, expand(value))
+		case key == "submap":
+			submap = value
+			if submap == "reset" {
+				submap = ""
+			}
+		case isHyprColorKey(key):
+			for _, token := range splitHyprColors(expand(value)) {
+				if strings.HasSuffix(token, "deg") {
+					continue
+				}
+				if !validHyprColor(token) {
+					c.add(lineNo, SeverityError, "%s: %q is not a valid color", key, token)
+				}
+			}
+		}
+
+		m := hyprBind.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		fields := strings.Split(expand(m[3]), ",")
+		if len(fields) < 2 {
+			c.add(lineNo, SeverityError, "%s needs at least modifiers and a key", m[1]+m[2])
+			continue
+		}
+		mods := strings.FieldsFunc(fields[0], func(r rune) bool { return r == ' ' || r == '+' || r == '_' })
+		combo := normalizeCombo(mods, fields[1])
+		// Release binds fire on key up, so they may share a combination
+		// with a press bind
+		if strings.Contains(m[2], "r") {
+			combo += "/release"
+		}
+		combo = submap + ":" + combo
+		if m[1] == "unbind" {
+			delete(seen, combo)
+			continue
+		}
+		display := strings.TrimSpace(fields[0] + " " + strings.TrimSpace(fields[1]))
+		c.checkDuplicate(seen, combo, display, lineNo, SeverityWarning)
+	}
+}
+
+// Terminals
+
+// validTerminalColor accepts #RGB and #RRGGBB, the same without # when
+// bare is set (Ghostty), and color names
+func validTerminalColor(s string, bare bool) bool {
+	switch {
+	case strings.HasPrefix(s, "#"):
+		return isHex(s[1:], 3, 6)
+	case bare && isHex(s, 3, 6):
+		return true
+	case s == "cell-foreground" || s == "cell-background":
+		return true
+	}
+	return colorName.MatchString(s)
+}
+
+func isTerminalColorKey(key string) bool {
+	if strings.HasPrefix(key, "color") {
+		_, err := strconv.Atoi(strings.TrimPrefix(key, "color"))
+		return err == nil
+	}
+	switch key {
+	case "foreground", "background", "cursor":
+		return true
+	}
+	for _, suffix := range []string{"_color", "_foreground", "_background", "-color", "-foreground", "-background"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) checkKitty(lines []string) {
+	seen := bindSeen{}
+	for i, raw := range lines {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		key := fields[0]
+		switch {
+		case key == "include" && len(fields) > 1:
+			c.checkInclude(lineNo, strings.Join(fields[1:], " "))
+		case key == "map" && len(fields) > 2 && !strings.HasPrefix(fields[1], "-"):
+			c.checkDuplicate(seen, normalizeCombo(strings.Split(fields[1], "+"), ""), fields[1], lineNo, SeverityWarning)
+		case isTerminalColorKey(key) && len(fields) == 2:
+			if fields[1] != "none" && !validTerminalColor(fields[1], false) {
+				c.add(lineNo, SeverityError, "%s %q is not a valid color", key, fields[1])
+			}
+		}
+	}
+}
+
+func (c *checker) checkGhostty(lines []string) {
+	seen := bindSeen{}
+	for i, raw := range lines {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch {
+		case key == "config-file":
+			// A leading ? makes the include optional
+			if !strings.HasPrefix(value, "?") {
+				c.checkInclude(lineNo, value)
+			}
+		case key == "keybind":
+			trigger, _, _ := strings.Cut(value, "=")
+			if trigger == "clear" {
+				continue
+			}
+			parts := strings.Split(trigger, "+")
+			c.checkDuplicate(seen, normalizeCombo(parts[:len(parts)-1], parts[len(parts)-1]), trigger, lineNo, SeverityWarning)
+		case key == "palette":
+			index, color, ok := strings.Cut(value, "=")
+			n, err := strconv.Atoi(strings.TrimSpace(index))
+			if !ok || err != nil || n < 0 || n > 255 {
+				c.add(lineNo, SeverityError, "palette entry %q should be <0-255>=<color>", value)
+				continue
+			}
+			if !validTerminalColor(strings.TrimSpace(color), true) {
+				c.add(lineNo, SeverityError, "palette %d %q is not a valid color", n, color)
+			}
+		case isTerminalColorKey(key):
+			if !validTerminalColor(value, true) {
+				c.add(lineNo, SeverityError, "%s %q is not a valid color", key, value)
+			}
+		}
+	}
+}
+
+var alacrittyColorLine = regexp.MustCompile(`^([A-Za-z_]+)\s*=\s*['"]([^'"]*)['"]`)
+
+// checkAlacritty only looks at the [colors.*] tables; Alacritty reports
+// everything else itself when it starts
+func (c *checker) checkAlacritty(lines []string) {
+	table := ""
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "[[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+		if table != "colors" && !strings.HasPrefix(table, "colors.") {
+			continue
+		}
+		m := alacrittyColorLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := m[2]
+		valid := strings.HasPrefix(value, "#") && isHex(value[1:], 3, 6) ||
+			strings.HasPrefix(value, "0x") && isHex(value[2:], 6) ||
+			value == "CellForeground" || value == "CellBackground" || value == "None"
+		if !valid {
+			c.add(i+1, SeverityError, "[%s] %s %q is not a valid color", table, m[1], value)
+		}
+	}
+}
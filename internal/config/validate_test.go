@@ -0,0 +1,225 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testValidator(existing ...string) *Validator {
+	v := NewValidator("/home/test")
+	v.exists = func(path string) bool {
+		for _, e := range existing {
+			if e == path {
+				return true
+			}
+		}
+		return false
+	}
+	return v
+}
+
+func problemMessages(problems []Problem) []string {
+	var out []string
+	for _, p := range problems {
+		out = append(out, p.Message)
+	}
+	return out
+}
+
+func TestValidateEmbeddedConfigs(t *testing.T) {
+	v := testValidator("/home/test/.config/alacritty/dank-theme.toml", "/home/test/.config/ghostty/config-dankcolors",
+		"/home/test/.config/kitty/dank-theme.conf", "/home/test/.config/kitty/dank-tabs.conf")
+	fill := func(s string) string {
+		s = strings.ReplaceAll(s, "{{POLKIT_AGENT_PATH}}", "/usr/libexec/polkit-agent")
+		return strings.ReplaceAll(s, "{{TERMINAL_COMMAND}}", "ghostty")
+	}
+
+	for _, f := range DeployedConfigFiles("/home/test") {
+		content := map[string]string{
+			"Niri":            NiriConfig,
+			"Hyprland":        HyprlandConfig,
+			"Ghostty":         GhosttyConfig,
+			"Ghostty Colors":  GhosttyColorConfig,
+			"Kitty":           KittyConfig,
+			"Kitty Theme":     KittyThemeConfig,
+			"Kitty Tabs":      KittyTabsConfig,
+			"Alacritty":       AlacrittyConfig,
+			"Alacritty Theme": AlacrittyThemeConfig,
+		}[f.ConfigType]
+		require.NotEmpty(t, content, f.ConfigType)
+		assert.Empty(t, v.Validate(f.ConfigType, f.Path, fill(content)), f.ConfigType)
+	}
+}
+
+func TestValidatePlaceholders(t *testing.T) {
+	problems := testValidator().Validate("Niri", "config.kdl", NiriConfig)
+	require.NotEmpty(t, problems)
+	for _, p := range problems {
+		assert.Equal(t, SeverityError, p.Severity)
+		assert.Contains(t, p.Message, "was never substituted")
+		assert.Positive(t, p.Line)
+	}
+}
+
+func TestValidateNiri(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected []string
+	}{
+		{
+			name: "duplicate bind with different spelling",
+			config: `binds {
+    Mod+T { spawn "kitty"; }
+    Mod+Shift+E { quit; }
+    mod+t hotkey-overlay-title="Terminal" { spawn "foot"; }
+    Shift+Mod+E { quit; }
+}`,
+			expected: []string{"mod+t is already bound on line 2", "Shift+Mod+E is already bound on line 3"},
+		},
+		{
+			name: "disabled and nested nodes are not binds",
+			config: `binds {
+    Mod+T { spawn "kitty"; }
+    /-Mod+T { spawn "foot"; }
+    Mod+Space {
+        spawn "dms" "ipc" "call" "spotlight" "toggle";
+    }
+}
+spawn-at-startup "Mod+T"`,
+		},
+		{
+			name: "colors",
+			config: `layout {
+    focus-ring {
+        active-color "#70707"
+        inactive-color "#12345g"
+        urgent-color "red"
+        active-gradient from="#80c8ff" to="#zz" angle=45
+    }
+    shadow {
+        color "#0007"
+    }
+}`,
+			expected: []string{`active-color "#70707" is not a valid color`, `inactive-color "#12345g" is not a valid color`, `gradient to "#zz" is not a valid color`},
+		},
+		{
+			name:     "unclosed brace",
+			config:   "layout {\n    gaps 5\n",
+			expected: []string{"1 unclosed brace(s) at end of file"},
+		},
+		{
+			name:     "braces in strings and comments are ignored",
+			config:   "// }\nspawn-at-startup \"sh\" \"-c\" \"echo }\"\n/* {\n */\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := testValidator().Validate("Niri", "config.kdl", tt.config)
+			assert.Equal(t, tt.expected, problemMessages(problems))
+			for _, p := range problems {
+				assert.Equal(t, SeverityError, p.Severity)
+			}
+		})
+	}
+}
+
+func TestValidateHyprland(t *testing.T) {
+	config := `$mod = SUPER
+source = ~/.config/hypr/colors.conf
+source = ~/.config/hypr/missing.conf
+
+general {
+    col.active_border = rgba(33ccffee) rgba(00ff99ee) 45deg
+    col.inactive_border = rgba(595959)
+}
+decoration {
+    shadow {
+        color = rgba(0, 0, 0, 0.5)
+    }
+}
+
+exec-once = echo $HOME
+bind = $mod, T, exec, kitty
+bind = SUPER, t, exec, foot
+bindr = SUPER, T, exec, wofi
+bind = $other, Q, killactive
+unbind = SUPER, T
+bind = SUPER, T, exec, ghostty
+
+submap = resize
+bind = SUPER, T, exec, kitty
+submap = reset
+`
+	v := testValidator("/home/test/.config/hypr/colors.conf")
+	problems := v.Validate("Hyprland", "/home/test/.config/hypr/hyprland.conf", config)
+
+	assert.Equal(t, []string{
+		"included file /home/test/.config/hypr/missing.conf does not exist",
+		`col.inactive_border: "rgba(595959)" is not a valid color`,
+		"SUPER t is already bound on line 16",
+		"variable $other is not defined in this file",
+	}, problemMessages(problems))
+	assert.Equal(t, SeverityWarning, problems[2].Severity)
+	assert.Equal(t, 17, problems[2].Line)
+}
+
+func TestValidHyprColor(t *testing.T) {
+	for _, c := range []string{"rgb(ffffff)", "rgba(ffffffaa)", "rgba(255,255,255,0.5)", "rgb(0, 0, 0)", "0xffaabbcc"} {
+		assert.True(t, validHyprColor(c), c)
+	}
+	for _, c := range []string{"#ffffff", "rgb(fffff)", "rgba(300,0,0,1)", "rgba(0,0,0,2)", "0xfff", "red"} {
+		assert.False(t, validHyprColor(c), c)
+	}
+}
+
+func TestValidateTerminals(t *testing.T) {
+	t.Run("kitty", func(t *testing.T) {
+		config := "include dank-theme.conf\nforeground #e0e2e8\nbackground e0e2e8\ncolor1 #xyz\ntab_bar_background none\nmap ctrl+t new_tab\nmap ctrl+T new_window\n"
+		v := testValidator()
+		problems := v.Validate("Kitty", "/home/test/.config/kitty/kitty.conf", config)
+		assert.Equal(t, []string{
+			"included file /home/test/.config/kitty/dank-theme.conf does not exist",
+			`background "e0e2e8" is not a valid color`,
+			`color1 "#xyz" is not a valid color`,
+			"ctrl+T is already bound on line 6",
+		}, problemMessages(problems))
+	})
+
+	t.Run("ghostty", func(t *testing.T) {
+		config := "config-file = ?optional\nbackground = 101418\nforeground = #e0e2e8\npalette = 1=#zzzzzz\npalette = 300=#ffffff\nselection-foreground = cell-foreground\nkeybind = ctrl+t=new_tab\nkeybind = ctrl+t=new_window\n"
+		problems := testValidator().Validate("Ghostty", "/home/test/.config/ghostty/config", config)
+		assert.Equal(t, []string{
+			`palette 1 "#zzzzzz" is not a valid color`,
+			`palette entry "300=#ffffff" should be <0-255>=<color>`,
+			"ctrl+t is already bound on line 7",
+		}, problemMessages(problems))
+	})
+
+	t.Run("alacritty", func(t *testing.T) {
+		config := "[window]\nopacity = 1.0\n[colors.primary]\nbackground = '#101418'\nforeground = '0xe0e2e8'\n[colors.normal]\nred = 'd75a59'\n[colors.cursor]\ntext = 'CellBackground'\n"
+		problems := testValidator().Validate("Alacritty Theme", "dank-theme.toml", config)
+		assert.Equal(t, []string{`[colors.normal] red "d75a59" is not a valid color`}, problemMessages(problems))
+		assert.Equal(t, 7, problems[0].Line)
+	})
+}
+
+func TestValidateDeployed(t *testing.T) {
+	home := t.TempDir()
+	niri := filepath.Join(home, ".config", "niri", "config.kdl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(niri), 0755))
+	require.NoError(t, os.WriteFile(niri, []byte(`spawn-at-startup "{{POLKIT_AGENT_PATH}}"`+"\n"), 0644))
+
+	report := NewValidator(home).ValidateDeployed()
+	assert.Equal(t, []ConfigFile{{ConfigType: "Niri", Path: niri}}, report.Checked)
+	require.Len(t, report.Problems, 1)
+	assert.True(t, report.HasErrors())
+	assert.Equal(t, niri+":1: error: placeholder {{POLKIT_AGENT_PATH}} was never substituted", report.Problems[0].String())
+}
@@ -0,0 +1,123 @@
+// Package crash records panics in the server so that a daemon that died or
+// lost a manager leaves something behind to report.
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// fatalLogName receives panics nobody recovered, written by the runtime
+const fatalLogName = "fatal.log"
+
+var (
+	count    atomic.Int64
+	dirMutex sync.Mutex
+	dirPath  string
+)
+
+// Dir returns where crash files are kept
+func Dir() string {
+	dirMutex.Lock()
+	defer dirMutex.Unlock()
+	if dirPath != "" {
+		return dirPath
+	}
+
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			stateHome = filepath.Join(home, ".local", "state")
+		} else {
+			stateHome = os.TempDir()
+		}
+	}
+	return filepath.Join(stateHome, "DankMaterialShell", "crashes")
+}
+
+// SetDir overrides the crash directory
+func SetDir(dir string) {
+	dirMutex.Lock()
+	dirPath = dir
+	dirMutex.Unlock()
+}
+
+// Count returns how many panics were recovered since the process started
+func Count() int {
+	return int(count.Load())
+}
+
+// Capture recovers a panic, logs it with its stack and writes a crash file.
+// It must be deferred directly:
+//
+//	defer crash.Capture("network signals", nil)
+//
+// onPanic, when set, runs after the crash is recorded, e.g. to answer the
+// client whose request blew up.
+func Capture(name string, onPanic func(v any)) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	stack := debug.Stack()
+	count.Add(1)
+	log.Errorf("Recovered panic in %s: %v\n%s", name, v, stack)
+
+	if path, err := record(name, v, stack, time.Now()); err != nil {
+		log.Warnf("Failed to write crash file: %v", err)
+	} else {
+		log.Errorf("Crash details written to %s", path)
+	}
+
+	if onPanic != nil {
+		onPanic(v)
+	}
+}
+
+// Go runs fn on a new goroutine that records instead of crashing the server
+func Go(name string, fn func()) {
+	go func() {
+		defer Capture(name, nil)
+		fn()
+	}()
+}
+
+func record(name string, v any, stack []byte, now time.Time) (string, error) {
+	dir := Dir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s-%d-%d.log", now.Format("20060102-150405"), os.Getpid(), count.Load()))
+	content := fmt.Sprintf("time: %s\npid: %d\nwhere: %s\npanic: %v\n\n%s", now.Format(time.RFC3339), os.Getpid(), name, v, stack)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// CaptureFatal makes the runtime append unrecovered panics and fatal errors
+// to fatal.log, which covers the cases Capture cannot see
+func CaptureFatal() error {
+	dir := Dir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, fatalLogName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "=== pid %d started %s ===\n", os.Getpid(), time.Now().Format(time.RFC3339))
+	return debug.SetCrashOutput(f, debug.CrashOptions{})
+}
@@ -0,0 +1,118 @@
+package crash
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	dir := t.TempDir()
+	SetDir(dir)
+	defer SetDir("")
+
+	before := Count()
+	var seen any
+	func() {
+		defer Capture("test worker", func(v any) { seen = v })
+		panic("boom")
+	}()
+
+	assert.Equal(t, "boom", seen)
+	assert.Equal(t, before+1, Count())
+
+	files, err := filepath.Glob(filepath.Join(dir, "crash-*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "where: test worker")
+	assert.Contains(t, string(data), "panic: boom")
+	assert.Contains(t, string(data), "TestCapture")
+}
+
+func TestCapture_NoPanic(t *testing.T) {
+	before := Count()
+	func() {
+		defer Capture("quiet", func(any) { t.Fatal("onPanic called without a panic") })
+	}()
+	assert.Equal(t, before, Count())
+}
+
+func TestGo(t *testing.T) {
+	SetDir(t.TempDir())
+	defer SetDir("")
+
+	before := Count()
+	done := make(chan struct{})
+	Go("goroutine", func() {
+		defer close(done)
+		var m map[string]int
+		m["x"] = 1
+	})
+	<-done
+	assert.Eventually(t, func() bool { return Count() == before+1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestRedactor(t *testing.T) {
+	r := newRedactor("/home/alice", "alice", "thinkpad")
+	out := r.Redact("open /home/alice/.config/x: user alice@thinkpad from 192.168.1.20 via aa:bb:cc:dd:ee:ff ssid=HomeNet mail bob@example.com local 127.0.0.1")
+
+	assert.NotContains(t, out, "alice")
+	assert.NotContains(t, out, "thinkpad")
+	assert.NotContains(t, out, "192.168.1.20")
+	assert.NotContains(t, out, "aa:bb:cc")
+	assert.NotContains(t, out, "HomeNet")
+	assert.NotContains(t, out, "bob@example.com")
+	assert.Contains(t, out, "~/.config/x")
+	assert.Contains(t, out, "127.0.0.1")
+}
+
+func TestWriteReport(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crash-20250101-120000-1-1.log"), []byte("panic in /home/alice/x"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, fatalLogName), []byte("fatal error: concurrent map writes"), 0600))
+
+	var buf bytes.Buffer
+	info, err := writeReport(&buf, dir, "1.2.3", newRedactor("/home/alice", "alice", "box"), func() ([]byte, error) {
+		return []byte("dms[42]: started by alice"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ReportInfo{CrashFiles: 1, FatalLog: true, Journal: true}, info)
+
+	files := readTarGz(t, &buf)
+	assert.Contains(t, files["dms-crash-report/system.txt"], "dms version: 1.2.3")
+	assert.Equal(t, "panic in ~/x", files["dms-crash-report/crashes/crash-20250101-120000-1-1.log"])
+	assert.Equal(t, "fatal error: concurrent map writes", files["dms-crash-report/fatal.log"])
+	assert.Equal(t, "dms[42]: started by <user>", files["dms-crash-report/journal.txt"])
+}
+
+func readTarGz(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		var b strings.Builder
+		_, err = io.Copy(&b, tr)
+		require.NoError(t, err)
+		files[hdr.Name] = b.String()
+	}
+	return files
+}
@@ -0,0 +1,191 @@
+package crash
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// maxCrashFiles keeps the bundle small when something panics in a loop
+	maxCrashFiles = 20
+	maxFatalBytes = 256 << 10
+	journalLines  = "500"
+)
+
+// ReportInfo describes what went into a bundle
+type ReportInfo struct {
+	CrashFiles int
+	FatalLog   bool
+	Journal    bool
+}
+
+// Redactor strips identifying details from text going into a report
+type Redactor struct {
+	replacements []string
+}
+
+var (
+	ipv4Pattern  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	macPattern   = regexp.MustCompile(`\b(?:[0-9A-Fa-f]{2}[:-]){5}[0-9A-Fa-f]{2}\b`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	ssidPattern  = regexp.MustCompile(`(?i)(ssid[=: ]+)("[^"]*"|\S+)`)
+)
+
+// NewRedactor replaces the home directory, user name and host name of the
+// current machine
+func NewRedactor() *Redactor {
+	var home, username, hostname string
+	home, _ = os.UserHomeDir()
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	hostname, _ = os.Hostname()
+	return newRedactor(home, username, hostname)
+}
+
+func newRedactor(home, username, hostname string) *Redactor {
+	var r []string
+	// Longest first so the home directory wins over the user name inside it
+	if home != "" && home != "/" {
+		r = append(r, home, "~")
+	}
+	if hostname != "" && hostname != "localhost" {
+		r = append(r, hostname, "<host>")
+	}
+	if len(username) > 2 {
+		r = append(r, username, "<user>")
+	}
+	return &Redactor{replacements: r}
+}
+
+func (r *Redactor) Redact(s string) string {
+	s = strings.NewReplacer(r.replacements...).Replace(s)
+	s = emailPattern.ReplaceAllString(s, "<email>")
+	s = macPattern.ReplaceAllString(s, "<mac>")
+	s = ipv4Pattern.ReplaceAllStringFunc(s, func(ip string) string {
+		if strings.HasPrefix(ip, "127.") || ip == "0.0.0.0" {
+			return ip
+		}
+		return "<ip>"
+	})
+	s = ssidPattern.ReplaceAllString(s, "${1}<ssid>")
+	return s
+}
+
+// WriteReport writes a redacted tar.gz with recent crash files, the fatal
+// log, system details and the recent journal of the dms user unit
+func WriteReport(w io.Writer, version string) (ReportInfo, error) {
+	return writeReport(w, Dir(), version, NewRedactor(), userJournal)
+}
+
+func writeReport(w io.Writer, dir, version string, redactor *Redactor, journal func() ([]byte, error)) (ReportInfo, error) {
+	var info ReportInfo
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+
+	add := func(name string, data []byte) error {
+		data = []byte(redactor.Redact(string(data)))
+		hdr := &tar.Header{
+			Name:    "dms-crash-report/" + name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := add("system.txt", []byte(systemSummary(version))); err != nil {
+		return info, err
+	}
+
+	crashes, _ := filepath.Glob(filepath.Join(dir, "crash-*.log"))
+	sort.Sort(sort.Reverse(sort.StringSlice(crashes)))
+	if len(crashes) > maxCrashFiles {
+		crashes = crashes[:maxCrashFiles]
+	}
+	for _, path := range crashes {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if err := add("crashes/"+filepath.Base(path), data); err != nil {
+			return info, err
+		}
+		info.CrashFiles++
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, fatalLogName)); err == nil && len(data) > 0 {
+		if len(data) > maxFatalBytes {
+			data = data[len(data)-maxFatalBytes:]
+		}
+		if err := add(fatalLogName, data); err != nil {
+			return info, err
+		}
+		info.FatalLog = true
+	}
+
+	if journal != nil {
+		if data, err := journal(); err == nil && len(bytes.TrimSpace(data)) > 0 {
+			if err := add("journal.txt", data); err != nil {
+				return info, err
+			}
+			info.Journal = true
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return info, err
+	}
+	return info, gz.Close()
+}
+
+func systemSummary(version string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "dms version: %s\n", version)
+	fmt.Fprintf(&b, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if data, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		fmt.Fprintf(&b, "kernel: %s\n", strings.TrimSpace(string(data)))
+	}
+	if data, err := os.ReadFile("/etc/os-release"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if value, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+				fmt.Fprintf(&b, "os: %s\n", strings.Trim(value, `"`))
+			}
+		}
+	}
+	for _, key := range []string{"XDG_CURRENT_DESKTOP", "XDG_SESSION_TYPE", "WAYLAND_DISPLAY"} {
+		fmt.Fprintf(&b, "%s: %s\n", key, os.Getenv(key))
+	}
+	for _, key := range []string{"NIRI_SOCKET", "HYPRLAND_INSTANCE_SIGNATURE", "SWAYSOCK"} {
+		fmt.Fprintf(&b, "%s set: %t\n", key, os.Getenv(key) != "")
+	}
+	return b.String()
+}
+
+func userJournal() ([]byte, error) {
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		return nil, err
+	}
+	out, err := exec.Command("journalctl", "--user", "--user-unit", "dms.service", "-n", journalLines, "--no-pager", "-o", "short-iso").Output()
+	if err != nil || strings.TrimSpace(string(out)) == "-- No entries --" {
+		return nil, err
+	}
+	return out, nil
+}
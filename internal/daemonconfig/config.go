@@ -0,0 +1,202 @@
+// Package daemonconfig reads and writes daemon.toml, the settings of the
+// dms server itself as opposed to the shell's settings.json.
+package daemonconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+)
+
+// Config holds the values set in daemon.toml. Options not in the file fall
+// back to their defaults.
+type Config struct {
+	Path   string
+	values map[string]any
+	// Problems are unknown keys and invalid values, which are skipped
+	Problems []string
+}
+
+// Path is where daemon.toml lives
+func Path() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "daemon.toml")
+}
+
+// Load reads daemon.toml from its default location
+func Load() (*Config, error) {
+	return LoadFile(Path())
+}
+
+// LoadFile reads a daemon config. A missing file gives an all-default
+// config; a file that cannot be parsed is an error.
+func LoadFile(path string) (*Config, error) {
+	cfg := &Config{Path: path, values: make(map[string]any)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	tables, err := tomlite.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, t := range tables {
+		if t.Array {
+			cfg.Problems = append(cfg.Problems, fmt.Sprintf("line %d: [[%s]] is not a daemon option table", t.Line, t.Name))
+			continue
+		}
+		keys := make([]string, 0, len(t.Values))
+		for name := range t.Values {
+			keys = append(keys, name)
+		}
+		sort.Slice(keys, func(i, j int) bool { return t.Lines[keys[i]] < t.Lines[keys[j]] })
+
+		for _, name := range keys {
+			key := name
+			if t.Name != "" {
+				key = t.Name + "." + name
+			}
+			opt, ok := Lookup(key)
+			if !ok {
+				cfg.Problems = append(cfg.Problems, fmt.Sprintf("line %d: unknown option %s", t.Lines[name], key))
+				continue
+			}
+			value, err := opt.fromTOML(t.Values[name])
+			if err != nil {
+				cfg.Problems = append(cfg.Problems, fmt.Sprintf("line %d: %v", t.Lines[name], err))
+				continue
+			}
+			cfg.values[key] = value
+		}
+	}
+	return cfg, nil
+}
+
+// Default is an all-default config, used when daemon.toml is unreadable
+func Default() *Config {
+	return &Config{Path: Path(), values: make(map[string]any)}
+}
+
+// IsSet reports whether key is set in the file rather than defaulted
+func (c *Config) IsSet(key string) bool {
+	_, ok := c.values[key]
+	return ok
+}
+
+// Get returns the value of key, or its default
+func (c *Config) Get(key string) any {
+	if v, ok := c.values[key]; ok {
+		return v
+	}
+	if opt, ok := Lookup(key); ok {
+		return opt.Default
+	}
+	return nil
+}
+
+func (c *Config) Bool(key string) bool {
+	b, _ := c.Get(key).(bool)
+	return b
+}
+
+func (c *Config) Int(key string) int {
+	n, _ := c.Get(key).(int)
+	return n
+}
+
+func (c *Config) Duration(key string) time.Duration {
+	d, _ := c.Get(key).(time.Duration)
+	return d
+}
+
+func (c *Config) String(key string) string {
+	s, _ := c.Get(key).(string)
+	return s
+}
+
+// With returns a copy of the config with key set to value, so a caller
+// that wrote the value with Set can swap it in without rereading the file
+func (c *Config) With(key string, value any) *Config {
+	values := make(map[string]any, len(c.values)+1)
+	for k, v := range c.values {
+		values[k] = v
+	}
+	values[key] = value
+	return &Config{Path: c.Path, values: values, Problems: c.Problems}
+}
+
+// ModuleEnabled reports whether a service is switched on under [modules]
+func (c *Config) ModuleEnabled(module string) bool {
+	return c.Bool("modules." + module)
+}
+
+// Set validates value and writes it to the config file, keeping the rest of
+// the file as it was
+func Set(path, key, value string) (any, error) {
+	opt, ok := Lookup(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown option %s", key)
+	}
+	parsed, err := opt.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if _, err := tomlite.Parse(string(data)); err != nil {
+		return nil, fmt.Errorf("refusing to edit %s: %w", path, err)
+	}
+
+	updated := tomlite.Set(string(data), opt.table(), opt.name(), tomlite.FormatValue(opt.literal(parsed)))
+	return parsed, writeFile(path, updated)
+}
+
+// Unset removes key from the config file so its default applies again
+func Unset(path, key string) (bool, error) {
+	opt, ok := Lookup(key)
+	if !ok {
+		return false, fmt.Errorf("unknown option %s", key)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated, removed := tomlite.Unset(string(data), opt.table(), opt.name())
+	if !removed {
+		return false, nil
+	}
+	return true, writeFile(path, updated)
+}
+
+func writeFile(path, data string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
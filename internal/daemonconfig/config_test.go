@@ -0,0 +1,123 @@
+package daemonconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "daemon.toml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	return path
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "daemon.toml"))
+	require.NoError(t, err)
+
+	assert.False(t, cfg.IsSet("sensors.poll-interval"))
+	assert.Equal(t, 3*time.Second, cfg.Duration("sensors.poll-interval"))
+	assert.True(t, cfg.ModuleEnabled("cups"))
+	assert.Empty(t, cfg.Problems)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, `[sensors]
+poll-interval = "10s"
+
+[modules]
+cups = false
+bogus = true
+
+[nightlight]
+low-temp = 99999
+sunset = "20:30"
+`)
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, cfg.Duration("sensors.poll-interval"))
+	assert.False(t, cfg.ModuleEnabled("cups"))
+	assert.True(t, cfg.ModuleEnabled("network"))
+	assert.Equal(t, "20:30", cfg.String("nightlight.sunset"))
+	assert.Equal(t, 4000, cfg.Int("nightlight.low-temp"))
+	assert.Equal(t, []string{
+		"line 6: unknown option modules.bogus",
+		"line 9: nightlight.low-temp must be between 1000 and 10000",
+	}, cfg.Problems)
+}
+
+func TestLoadFile_ParseError(t *testing.T) {
+	_, err := LoadFile(writeConfig(t, "[sensors\n"))
+	assert.Error(t, err)
+}
+
+func TestSet(t *testing.T) {
+	path := writeConfig(t, "# my settings\n[modules]\ncups = false # printers live elsewhere\n")
+
+	value, err := Set(path, "modules.cups", "on")
+	require.NoError(t, err)
+	assert.Equal(t, true, value)
+
+	_, err = Set(path, "brightness.ddc-scan-interval", "2m")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# my settings\n[modules]\ncups = true # printers live elsewhere\n\n[brightness]\nddc-scan-interval = \"2m0s\"\n", string(data))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, cfg.Duration("brightness.ddc-scan-interval"))
+}
+
+func TestSet_Rejects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.toml")
+
+	_, err := Set(path, "nope.key", "1")
+	assert.ErrorContains(t, err, "unknown option")
+	_, err = Set(path, "sensors.poll-interval", "1ms")
+	assert.ErrorContains(t, err, "must be between")
+	_, err = Set(path, "nightlight.sunrise", "7am")
+	assert.ErrorContains(t, err, "HH:MM")
+	_, err = Set(path, "server.socket-dir", "relative/dir")
+	assert.ErrorContains(t, err, "absolute path")
+
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr))
+
+	broken := writeConfig(t, "[a\n")
+	_, err = Set(broken, "modules.cups", "false")
+	assert.ErrorContains(t, err, "refusing to edit")
+}
+
+func TestUnset(t *testing.T) {
+	path := writeConfig(t, "[sensors]\npoll-interval = \"5s\"\n")
+
+	removed, err := Unset(path, "sensors.poll-interval")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = Unset(path, "sensors.poll-interval")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.False(t, cfg.IsSet("sensors.poll-interval"))
+}
+
+func TestWith(t *testing.T) {
+	cfg := Default()
+	updated := cfg.With("power.battery-brightness-cap", 40)
+
+	assert.Equal(t, 40, updated.Int("power.battery-brightness-cap"))
+	assert.False(t, cfg.IsSet("power.battery-brightness-cap"))
+	assert.Equal(t, cfg.Path, updated.Path)
+}
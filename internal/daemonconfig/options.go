@@ -0,0 +1,292 @@
+package daemonconfig
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindBool     Kind = "bool"
+	KindInt      Kind = "int"
+	KindDuration Kind = "duration"
+	KindPath     Kind = "path"
+	// KindClock is a time of day written as HH:MM
+	KindClock Kind = "time"
+	// KindURL is an http or https URL, or empty for the built-in default
+	KindURL Kind = "url"
+	// KindList is a comma-separated list of names, kept as a string
+	KindList Kind = "list"
+	// KindName is a single name such as a power profile, or empty
+	KindName Kind = "name"
+	// KindSteps is a brightness step curve of percent:step pairs such as
+	// "10:1,30:5,100:10", or empty for the built-in curve
+	KindSteps Kind = "steps"
+)
+
+// Option describes one daemon setting. Values are bool, int, time.Duration
+// or string depending on Kind; clock times are kept as "HH:MM" strings.
+type Option struct {
+	Key         string
+	Kind        Kind
+	Default     any
+	Description string
+	// Min and Max bound ints and durations when Max is non-zero
+	Min, Max int64
+	// HotReload options take effect on config.reload, the rest need a
+	// restart of the daemon
+	HotReload bool
+}
+
+// NotificationUrgencies are the urgencies with forwarding options under
+// [notifications]
+var NotificationUrgencies = []string{"low", "normal", "critical"}
+
+// Modules are the services that can be turned off under [modules]
+var Modules = []string{
+	"network", "loginctl", "freedesktop", "wayland", "bluetooth", "cups", "dwl",
+	"brightness", "notifications", "prompts", "sensors", "launcher", "power", "rules",
+	"health", "timers", "calendar", "scratchpad", "termcolors", "thermal", "remap",
+	"a11y", "audio", "wallpaper", "sounds", "jobs", "appcolor",
+}
+
+var (
+	listItemPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	stepsPattern    = regexp.MustCompile(`^\d{1,3}:\d{1,3}(,\d{1,3}:\d{1,3})*$`)
+)
+
+var Options = buildOptions()
+
+func buildOptions() []Option {
+	opts := []Option{
+		{Key: "server.socket-dir", Kind: KindPath, Default: "", Description: "Directory for the IPC socket (empty uses $XDG_RUNTIME_DIR)"},
+		{Key: "server.safeguard", Kind: KindBool, Default: false, HotReload: true, Description: "Ask clients to confirm destructive calls such as deleting printers or forgetting WiFi networks (clients must handle confirmation tokens)"},
+		{Key: "brightness.ddc-scan-interval", Kind: KindDuration, Default: 30 * time.Second, Min: int64(5 * time.Second), Max: int64(time.Hour), HotReload: true, Description: "Minimum time between DDC/I2C monitor scans"},
+		{Key: "brightness.key-steps", Kind: KindSteps, Default: "", HotReload: true, Description: "Brightness step curve for keys as percent:step pairs (empty uses 10:1,30:5,100:10)"},
+		{Key: "brightness.slider-steps", Kind: KindSteps, Default: "", HotReload: true, Description: "Brightness step curve for slider scrolling and drags (empty uses 10:1,100:5)"},
+		{Key: "sensors.poll-interval", Kind: KindDuration, Default: 3 * time.Second, Min: int64(time.Second), Max: int64(5 * time.Minute), HotReload: true, Description: "How often temperature and fan sensors are read"},
+		{Key: "health.check-interval", Kind: KindDuration, Default: 30 * time.Second, Min: int64(5 * time.Second), Max: int64(10 * time.Minute), HotReload: true, Description: "How often the watchdog checks that backends still respond"},
+		{Key: "network.speedtest-url", Kind: KindURL, Default: "", HotReload: true, Description: "Download URL for speed tests (empty uses Cloudflare)"},
+		{Key: "network.speedtest-upload-url", Kind: KindURL, Default: "", HotReload: true, Description: "URL speed tests POST to (empty uses Cloudflare with the default download URL, otherwise skips the upload)"},
+		{Key: "calendar.refresh-interval", Kind: KindDuration, Default: 15 * time.Minute, Min: int64(time.Minute), Max: int64(24 * time.Hour), HotReload: true, Description: "How often remote calendars are synced"},
+		{Key: "termcolors.terminals", Kind: KindList, Default: "", HotReload: true, Description: "Terminals whose open shells are recoloured when the theme changes (comma-separated, e.g. foot,alacritty; empty turns it off)"},
+		{Key: "cups.polkit", Kind: KindBool, Default: false, HotReload: true, Description: "Ask polkit before deleting printers or cancelling other users' jobs (for shared machines)"},
+		{Key: "nightlight.enabled", Kind: KindBool, Default: false, HotReload: true, Description: "Turn night light on when the daemon starts"},
+		{Key: "nightlight.sunset", Kind: KindClock, Default: "", HotReload: true, Description: "Fixed time night light starts (HH:MM, empty follows the sun)"},
+		{Key: "nightlight.sunrise", Kind: KindClock, Default: "", HotReload: true, Description: "Fixed time night light ends (HH:MM, empty follows the sun)"},
+		{Key: "nightlight.low-temp", Kind: KindInt, Default: 4000, Min: 1000, Max: 10000, HotReload: true, Description: "Night colour temperature in kelvin"},
+		{Key: "nightlight.high-temp", Kind: KindInt, Default: 6500, Min: 1000, Max: 10000, HotReload: true, Description: "Day colour temperature in kelvin"},
+		{Key: "power.policy", Kind: KindBool, Default: false, HotReload: true, Description: "Switch power profile and battery limits when the charger is plugged in or pulled"},
+		{Key: "power.ac-profile", Kind: KindName, Default: "balanced", HotReload: true, Description: "Power profile switched to when the charger is plugged in (empty leaves it alone)"},
+		{Key: "power.battery-profile", Kind: KindName, Default: "power-saver", HotReload: true, Description: "Power profile switched to when the charger is pulled (empty leaves it alone)"},
+		{Key: "power.battery-brightness-cap", Kind: KindInt, Default: 0, Min: 0, Max: 100, HotReload: true, Description: "Highest backlight brightness on battery in percent, restored on AC (0 leaves it alone)"},
+		{Key: "power.battery-refresh-rate", Kind: KindInt, Default: 0, Min: 0, Max: 1000, HotReload: true, Description: "Highest refresh rate on battery in Hz, restored on AC (0 leaves it alone)"},
+	}
+	for _, urgency := range NotificationUrgencies {
+		opts = append(opts,
+			Option{Key: "notifications." + urgency + "-file", Kind: KindPath, Default: "", HotReload: true, Description: fmt.Sprintf("File or FIFO %s urgency notifications are appended to as JSON lines (empty turns it off)", urgency)},
+			Option{Key: "notifications." + urgency + "-terminal", Kind: KindBool, Default: false, HotReload: true, Description: fmt.Sprintf("Show %s urgency notifications in your open terminals", urgency)},
+			Option{Key: "notifications." + urgency + "-bell", Kind: KindBool, Default: false, HotReload: true, Description: fmt.Sprintf("Ring the bell in your open terminals for %s urgency notifications", urgency)},
+		)
+	}
+	for _, module := range Modules {
+		opts = append(opts, Option{
+			Key:         "modules." + module,
+			Kind:        KindBool,
+			Default:     true,
+			Description: fmt.Sprintf("Start the %s service", module),
+		})
+	}
+	return opts
+}
+
+// Lookup finds an option by its dotted key
+func Lookup(key string) (Option, bool) {
+	for _, opt := range Options {
+		if opt.Key == key {
+			return opt, true
+		}
+	}
+	return Option{}, false
+}
+
+// Keys lists every option key, for completion
+func Keys() []string {
+	keys := make([]string, len(Options))
+	for i, opt := range Options {
+		keys[i] = opt.Key
+	}
+	return keys
+}
+
+// Suggestions lists typical values for completion
+func (o Option) Suggestions() []string {
+	switch o.Kind {
+	case KindBool:
+		return []string{"true", "false"}
+	case KindDuration:
+		return []string{"1s", "5s", "30s", "1m"}
+	case KindClock:
+		return []string{"06:30", "07:00", "19:00", "20:00", "21:00"}
+	case KindInt:
+		return []string{o.Format(o.Default)}
+	}
+	return nil
+}
+
+func (o Option) table() string {
+	table, _, _ := strings.Cut(o.Key, ".")
+	return table
+}
+
+func (o Option) name() string {
+	_, name, _ := strings.Cut(o.Key, ".")
+	return name
+}
+
+// Parse converts command line input into the option's value
+func (o Option) Parse(s string) (any, error) {
+	s = strings.TrimSpace(s)
+	switch o.Kind {
+	case KindBool:
+		switch strings.ToLower(s) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%s expects true or false, got %q", o.Key, s)
+	case KindInt:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a whole number, got %q", o.Key, s)
+		}
+		return o.check(int(n))
+	case KindDuration:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a duration like 30s or 2m, got %q", o.Key, s)
+		}
+		return o.check(d)
+	case KindClock:
+		return o.check(s)
+	case KindPath:
+		if s == "" {
+			return "", nil
+		}
+		if !filepath.IsAbs(s) {
+			return nil, fmt.Errorf("%s expects an absolute path, got %q", o.Key, s)
+		}
+		return filepath.Clean(s), nil
+	case KindURL:
+		if s == "" {
+			return "", nil
+		}
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%s expects an http or https URL, got %q", o.Key, s)
+		}
+		return s, nil
+	case KindList:
+		var items []string
+		seen := make(map[string]bool)
+		for _, item := range strings.Split(s, ",") {
+			item = strings.ToLower(strings.TrimSpace(item))
+			if item == "" || seen[item] {
+				continue
+			}
+			if !listItemPattern.MatchString(item) {
+				return nil, fmt.Errorf("%s expects comma-separated names, got %q", o.Key, item)
+			}
+			seen[item] = true
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), nil
+	case KindName:
+		s = strings.ToLower(s)
+		if s != "" && !listItemPattern.MatchString(s) {
+			return nil, fmt.Errorf("%s expects a name such as balanced, got %q", o.Key, s)
+		}
+		return s, nil
+	case KindSteps:
+		s = strings.ReplaceAll(s, " ", "")
+		if s != "" && !stepsPattern.MatchString(s) {
+			return nil, fmt.Errorf("%s expects percent:step pairs like 10:1,30:5,100:10, got %q", o.Key, s)
+		}
+		return s, nil
+	}
+	return s, nil
+}
+
+// fromTOML converts a value read from daemon.toml
+func (o Option) fromTOML(v any) (any, error) {
+	switch o.Kind {
+	case KindBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case KindInt:
+		if n, ok := v.(int64); ok {
+			return o.check(int(n))
+		}
+	case KindDuration, KindClock:
+		if s, ok := v.(string); ok {
+			return o.Parse(s)
+		}
+	case KindPath, KindURL, KindList, KindName, KindSteps:
+		if s, ok := v.(string); ok {
+			return o.Parse(s)
+		}
+	}
+	return nil, fmt.Errorf("%s expects a %s value", o.Key, o.Kind)
+}
+
+func (o Option) check(v any) (any, error) {
+	switch v := v.(type) {
+	case int:
+		if o.Max != 0 && (int64(v) < o.Min || int64(v) > o.Max) {
+			return nil, fmt.Errorf("%s must be between %d and %d", o.Key, o.Min, o.Max)
+		}
+	case time.Duration:
+		if o.Max != 0 && (int64(v) < o.Min || int64(v) > o.Max) {
+			return nil, fmt.Errorf("%s must be between %s and %s", o.Key, time.Duration(o.Min), time.Duration(o.Max))
+		}
+	case string:
+		if o.Kind == KindClock && v != "" {
+			if _, err := time.Parse("15:04", v); err != nil {
+				return nil, fmt.Errorf("%s expects a time as HH:MM, got %q", o.Key, v)
+			}
+		}
+	}
+	return v, nil
+}
+
+// Format renders a value the way it is typed on the command line
+func (o Option) Format(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case time.Duration:
+		return v.String()
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// literal renders a value for daemon.toml
+func (o Option) literal(v any) any {
+	switch v := v.(type) {
+	case time.Duration:
+		return v.String()
+	}
+	return v
+}
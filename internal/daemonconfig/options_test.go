@@ -0,0 +1,75 @@
+package daemonconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	opt, ok := Lookup("modules.bluetooth")
+	require.True(t, ok)
+	assert.Equal(t, KindBool, opt.Kind)
+	assert.False(t, opt.HotReload)
+
+	_, ok = Lookup("modules")
+	assert.False(t, ok)
+	assert.Len(t, Keys(), len(Options))
+}
+
+func TestOptionParse(t *testing.T) {
+	tests := []struct {
+		key   string
+		input string
+		want  any
+	}{
+		{"modules.cups", "no", false},
+		{"modules.cups", "TRUE", true},
+		{"nightlight.high-temp", " 6000 ", 6000},
+		{"sensors.poll-interval", "1m", time.Minute},
+		{"nightlight.sunset", "19:45", "19:45"},
+		{"nightlight.sunset", "", ""},
+		{"server.socket-dir", "/run/dms/", "/run/dms"},
+		{"server.socket-dir", "", ""},
+		{"network.speedtest-url", "https://speed.example.com/10mb.bin", "https://speed.example.com/10mb.bin"},
+		{"network.speedtest-url", "", ""},
+		{"termcolors.terminals", " Foot, alacritty,,foot ", "foot,alacritty"},
+		{"termcolors.terminals", "", ""},
+		{"brightness.key-steps", "10:1, 30:5, 100:10", "10:1,30:5,100:10"},
+		{"power.battery-profile", " Power-Saver ", "power-saver"},
+		{"power.ac-profile", "", ""},
+	}
+	for _, tt := range tests {
+		opt, ok := Lookup(tt.key)
+		require.True(t, ok, tt.key)
+		got, err := opt.Parse(tt.input)
+		require.NoError(t, err, tt.key)
+		assert.Equal(t, tt.want, got, tt.key)
+	}
+
+	opt, _ := Lookup("network.speedtest-url")
+	for _, bad := range []string{"ftp://example.com/file", "speed.example.com", "https://"} {
+		_, err := opt.Parse(bad)
+		assert.Error(t, err, bad)
+	}
+
+	opt, _ = Lookup("termcolors.terminals")
+	_, err := opt.Parse("foot, gnome terminal")
+	assert.Error(t, err)
+
+	opt, _ = Lookup("brightness.key-steps")
+	_, err = opt.Parse("10-1,100-10")
+	assert.Error(t, err)
+
+	opt, _ = Lookup("power.ac-profile")
+	_, err = opt.Parse("balanced,performance")
+	assert.Error(t, err)
+}
+
+func TestOptionFormat(t *testing.T) {
+	opt, _ := Lookup("brightness.ddc-scan-interval")
+	assert.Equal(t, "30s", opt.Format(opt.Default))
+	assert.Equal(t, []string{"true", "false"}, Options[len(Options)-1].Suggestions())
+}
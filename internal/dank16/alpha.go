@@ -0,0 +1,103 @@
+package dank16
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RGBA is a color with opacity. Palette slots are opaque hex strings, but
+// translucent UI colors such as selections and scrollbar thumbs need alpha,
+// and every format writes it differently.
+type RGBA struct {
+	R, G, B uint8
+	// A is the opacity in 0-1
+	A float64
+}
+
+// AlphaFormat is how a target file expects a translucent color
+type AlphaFormat int
+
+const (
+	// AlphaHex is #rrggbbaa, read by JSON themes (VSCode, Zed), TextMate
+	// plists and Chromium CSS
+	AlphaHex AlphaFormat = iota
+	// AlphaBareHex is rrggbbaa without the #, as fuzzel.ini wants
+	AlphaBareHex
+	// AlphaARGB is #aarrggbb, the order Qt and KDE color schemes use
+	AlphaARGB
+	// AlphaCSS is rgba(r, g, b, a), which GTK CSS needs as it has no
+	// eight-digit hex
+	AlphaCSS
+	// AlphaFlatten blends onto the background and writes #rrggbb, for
+	// formats with no alpha at all such as Alacritty's TOML
+	AlphaFlatten
+)
+
+// NewRGBA gives an opaque palette color the opacity alpha, clamped to 0-1.
+// Any alpha already on hex is replaced.
+func NewRGBA(hex string, alpha float64) RGBA {
+	r, g, b, _, _ := parseColor(hex)
+	return RGBA{R: r, G: g, B: b, A: math.Max(0, math.Min(1, alpha))}
+}
+
+// ParseRGBA reads any color ParseHex does, keeping its alpha
+func ParseRGBA(color string) (RGBA, error) {
+	r, g, b, a, err := parseColor(color)
+	if err != nil {
+		return RGBA{}, err
+	}
+	return RGBA{R: r, G: g, B: b, A: a}, nil
+}
+
+// alphaByte is A as 0-255
+func (c RGBA) alphaByte() uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, c.A)) * 255))
+}
+
+// Opaque drops the alpha, returning #rrggbb
+func (c RGBA) Opaque() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Hex returns #rrggbbaa
+func (c RGBA) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.alphaByte())
+}
+
+// ARGB returns #aarrggbb
+func (c RGBA) ARGB() string {
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.alphaByte(), c.R, c.G, c.B)
+}
+
+// CSS returns rgba(), or plain #rrggbb when the color is opaque
+func (c RGBA) CSS() string {
+	if c.alphaByte() == 255 {
+		return c.Opaque()
+	}
+	alpha := strconv.FormatFloat(math.Round(c.A*1000)/1000, 'f', -1, 64)
+	return fmt.Sprintf("rgba(%d, %d, %d, %s)", c.R, c.G, c.B, alpha)
+}
+
+// Over is the opaque color c shows as on bg, blended in linear light like
+// Mix
+func (c RGBA) Over(bg string) string {
+	return Mix(bg, c.Opaque(), c.A)
+}
+
+// Encode writes c in format. bg is only read by AlphaFlatten.
+func (c RGBA) Encode(format AlphaFormat, bg string) string {
+	switch format {
+	case AlphaBareHex:
+		return strings.TrimPrefix(c.Hex(), "#")
+	case AlphaARGB:
+		return c.ARGB()
+	case AlphaCSS:
+		return c.CSS()
+	case AlphaFlatten:
+		return c.Over(bg)
+	default:
+		return c.Hex()
+	}
+}
@@ -0,0 +1,81 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRGBAEncode(t *testing.T) {
+	c := NewRGBA("#625690", 0.25)
+	tests := []struct {
+		format   AlphaFormat
+		expected string
+	}{
+		{AlphaHex, "#62569040"},
+		{AlphaBareHex, "62569040"},
+		{AlphaARGB, "#40625690"},
+		{AlphaCSS, "rgba(98, 86, 144, 0.25)"},
+		{AlphaFlatten, Mix("#000000", "#625690", 0.25)},
+	}
+	for _, tt := range tests {
+		if got := c.Encode(tt.format, "#000000"); got != tt.expected {
+			t.Errorf("format %d: got %s, expected %s", tt.format, got, tt.expected)
+		}
+	}
+
+	if got := NewRGBA("#625690", 1).CSS(); got != "#625690" {
+		t.Errorf("opaque CSS should stay hex, got %s", got)
+	}
+	if got := NewRGBA("#625690cc", 2).Hex(); got != "#625690ff" {
+		t.Errorf("alpha should be replaced and clamped, got %s", got)
+	}
+}
+
+func TestRGBAOver(t *testing.T) {
+	if got := NewRGBA("#625690", 0).Over("#1a1a1a"); got != "#1a1a1a" {
+		t.Errorf("transparent over bg = %s, expected the background", got)
+	}
+	if got := NewRGBA("#625690", 1).Over("#1a1a1a"); got != "#625690" {
+		t.Errorf("opaque over bg = %s, expected the color", got)
+	}
+}
+
+func TestParseRGBA(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#62569080", "#62569080"},
+		{"#625690", "#625690ff"},
+		{"rgba(98, 86, 144, 0.5)", "#62569080"},
+		{"rgb(98 86 144 / 25%)", "#62569040"},
+	}
+	for _, tt := range tests {
+		c, err := ParseRGBA(tt.input)
+		if err != nil {
+			t.Errorf("ParseRGBA(%q): %v", tt.input, err)
+			continue
+		}
+		if got := c.Hex(); got != tt.expected {
+			t.Errorf("ParseRGBA(%q) = %s, expected %s", tt.input, got, tt.expected)
+		}
+	}
+	if _, err := ParseRGBA("#62569"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestTranslucentOutputs(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{})
+
+	alacritty := GenerateAlacrittyTheme(colors)
+	want := "background = '" + NewRGBA(colors[4], 0.35).Over(colors[0]) + "'\n"
+	if !strings.Contains(alacritty, "[colors.selection]\n") || !strings.Contains(alacritty, want) {
+		t.Errorf("alacritty selection missing or not flattened:\n%s", alacritty)
+	}
+
+	gtk := GenerateGTKTheme(colors, false)
+	if !strings.Contains(gtk, "scrollbar slider {\n  background-color: rgba(") {
+		t.Errorf("gtk scrollbar should use rgba():\n%s", gtk)
+	}
+}
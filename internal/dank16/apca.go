@@ -0,0 +1,95 @@
+package dank16
+
+import (
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// APCA 0.0.98G-4g constants, from the W3C Silver reference implementation
+const (
+	apcaMainTRC  = 2.4
+	apcaBlkThrs  = 0.022
+	apcaBlkClmp  = 1.414
+	apcaDeltaMin = 0.0005
+
+	apcaNormBG  = 0.56
+	apcaNormTXT = 0.57
+	apcaRevTXT  = 0.62
+	apcaRevBG   = 0.65
+
+	apcaScale    = 1.14
+	apcaLoOffset = 0.027
+	apcaLoClip   = 0.1
+)
+
+// apcaY is the APCA screen luminance, which uses a plain 2.4 exponent
+// rather than the piecewise sRGB curve, with a soft clamp near black
+func apcaY(hex string) float64 {
+	rgb := HexToRGB(hex)
+	y := 0.2126729*math.Pow(rgb.R, apcaMainTRC) +
+		0.7151522*math.Pow(rgb.G, apcaMainTRC) +
+		0.0721750*math.Pow(rgb.B, apcaMainTRC)
+	if y < apcaBlkThrs {
+		y += math.Pow(apcaBlkThrs-y, apcaBlkClmp)
+	}
+	return y
+}
+
+// APCAContrast returns the APCA lightness contrast (Lc) of text on a
+// background. It is positive for dark text on a light background and
+// negative for light text on a dark one; |Lc| 60 suits body text and 45
+// large or secondary text.
+func APCAContrast(hexFg, hexBg string) float64 {
+	yText, yBg := apcaY(hexFg), apcaY(hexBg)
+	if math.Abs(yBg-yText) < apcaDeltaMin {
+		return 0
+	}
+
+	if yBg > yText {
+		sapc := (math.Pow(yBg, apcaNormBG) - math.Pow(yText, apcaNormTXT)) * apcaScale
+		if sapc < apcaLoClip {
+			return 0
+		}
+		return (sapc - apcaLoOffset) * 100
+	}
+
+	sapc := (math.Pow(yBg, apcaRevBG) - math.Pow(yText, apcaRevTXT)) * apcaScale
+	if sapc > -apcaLoClip {
+		return 0
+	}
+	return (sapc + apcaLoOffset) * 100
+}
+
+// APCAContrastForMode is the Lc in the polarity the mode expects: dark text
+// in light mode, light text in dark mode. Text of the wrong polarity scores
+// negative however much it contrasts.
+func APCAContrastForMode(hexFg, hexBg string, isLightMode bool) float64 {
+	lc := APCAContrast(hexFg, hexBg)
+	if isLightMode {
+		return lc
+	}
+	return -lc
+}
+
+// EnsureContrastAPCA moves L* toward the mode's polarity, keeping the hue,
+// until the color reaches minLc against the background
+func EnsureContrastAPCA(hexColor, hexBg string, minLc float64, isLightMode bool) string {
+	if APCAContrastForMode(hexColor, hexBg, isLightMode) >= minLc {
+		return hexColor
+	}
+
+	rgb := HexToRGB(hexColor)
+	L, a, b := colorful.Color{R: rgb.R, G: rgb.G, B: rgb.B}.Lab()
+
+	dir := 1.0
+	if isLightMode {
+		dir = -1.0
+	}
+	for L100 := L * 100; L100 >= 0 && L100 <= 100; L100 += dir * 0.5 {
+		if cand := labToHex(L100, a, b); APCAContrastForMode(cand, hexBg, isLightMode) >= minLc {
+			return cand
+		}
+	}
+	return hexColor
+}
@@ -0,0 +1,91 @@
+package dank16
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAPCAContrast(t *testing.T) {
+	// Reference values from the apca-w3 0.0.98G-4g test suite
+	tests := []struct {
+		fg, bg string
+		want   float64
+	}{
+		{"#888888", "#ffffff", 63.056469930209424},
+		{"#ffffff", "#888888", -68.54146436644962},
+		{"#000000", "#aaaaaa", 58.146262578561334},
+		{"#aaaaaa", "#000000", -56.24113336839742},
+		{"#112233", "#ddeeff", 91.66830811481631},
+		{"#ddeeff", "#112233", -93.06770049484275},
+		{"#000000", "#ffffff", 106.04067321268862},
+		{"#ffffff", "#000000", -107.88473318309848},
+	}
+
+	for _, tt := range tests {
+		got := APCAContrast(tt.fg, tt.bg)
+		if math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("APCAContrast(%s, %s) = %f, want %f", tt.fg, tt.bg, got, tt.want)
+		}
+	}
+}
+
+func TestAPCAContrastLowClip(t *testing.T) {
+	if lc := APCAContrast("#808080", "#808080"); lc != 0 {
+		t.Errorf("same color Lc = %f, want 0", lc)
+	}
+	if lc := APCAContrast("#7f7f7f", "#808080"); lc != 0 {
+		t.Errorf("near-identical colors Lc = %f, want 0", lc)
+	}
+}
+
+func TestAPCAContrastForMode(t *testing.T) {
+	if lc := APCAContrastForMode("#ffffff", "#000000", false); lc < 100 {
+		t.Errorf("white on black in dark mode = %f, want >= 100", lc)
+	}
+	if lc := APCAContrastForMode("#000000", "#ffffff", true); lc < 100 {
+		t.Errorf("black on white in light mode = %f, want >= 100", lc)
+	}
+	if lc := APCAContrastForMode("#000000", "#ffffff", false); lc > 0 {
+		t.Errorf("dark text in dark mode = %f, want negative", lc)
+	}
+}
+
+func TestEnsureContrastAPCA(t *testing.T) {
+	tests := []struct {
+		name        string
+		color, bg   string
+		minLc       float64
+		isLightMode bool
+	}{
+		{"dark mode dim blue", "#1a237e", "#1a1a1a", 60, false},
+		{"light mode pale yellow", "#fff59d", "#f8f8f8", 60, true},
+		{"already sufficient", "#ffffff", "#000000", 60, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := EnsureContrastAPCA(tt.color, tt.bg, tt.minLc, tt.isLightMode)
+			if lc := APCAContrastForMode(result, tt.bg, tt.isLightMode); lc < tt.minLc {
+				t.Errorf("EnsureContrastAPCA(%s, %s) = %s with Lc %f, want >= %f", tt.color, tt.bg, result, lc, tt.minLc)
+			}
+		})
+	}
+
+	if got := EnsureContrastAPCA("#ffffff", "#000000", 60, false); got != "#ffffff" {
+		t.Errorf("sufficient color changed to %s", got)
+	}
+}
+
+func TestGeneratePaletteWithAPCA(t *testing.T) {
+	for _, isLight := range []bool{false, true} {
+		palette := GeneratePalette("#625690", PaletteOptions{IsLight: isLight, UseAPCA: true})
+		if len(palette) != 16 {
+			t.Fatalf("expected 16 colors, got %d", len(palette))
+		}
+		for i := 1; i <= 6; i++ {
+			if lc := APCAContrastForMode(palette[i], palette[0], isLight); lc < 60 {
+				t.Errorf("light=%v: color %d (%s) has Lc %f on %s, want >= 60", isLight, i, palette[i], lc, palette[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package dank16
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ApplyTarget is a terminal whose theme ApplyThemes writes to its usual
+// place under the config directory. Include is the line the terminal's own
+// config needs to load the theme, with {path} standing for the theme's
+// absolute path; it is empty when that cannot be added with a single line
+// and Hint says what to do instead.
+type ApplyTarget struct {
+	Name    string
+	Path    string
+	Config  string
+	Include string
+	Hint    string
+}
+
+// ApplyTargets are the terminals dms dank16 apply knows, with paths
+// relative to the config directory
+var ApplyTargets = []ApplyTarget{
+	{Name: "kitty", Path: "kitty/dank-theme.conf", Config: "kitty/kitty.conf", Include: "include dank-theme.conf"},
+	{Name: "alacritty", Path: "alacritty/dank-theme.toml", Config: "alacritty/alacritty.toml",
+		Hint: `add "{path}" to import under [general] in alacritty.toml`},
+	{Name: "ghostty", Path: "ghostty/config-dankcolors", Config: "ghostty/config", Include: "config-file = ./config-dankcolors"},
+	// foot only honours include in [main], which is where the top of the
+	// file is
+	{Name: "foot", Path: "foot/dank-colors.ini", Config: "foot/foot.ini", Include: "include={path}"},
+}
+
+// AppliedFile is a file ApplyThemes wrote. Backup is where the previous
+// contents went, if there were any and they differed.
+type AppliedFile struct {
+	Target string `json:"target"`
+	Path   string `json:"path"`
+	Backup string `json:"backup,omitempty"`
+}
+
+// ApplyThemes writes the theme of each named target, all of ApplyTargets
+// when names is empty, under configDir. A terminal's config that exists
+// but does not load the theme gets the include line added at the top.
+// Every file is replaced in one rename after backing up what was there.
+func ApplyThemes(configDir string, names []string, colors []string) ([]AppliedFile, error) {
+	targets, err := applyTargetsByName(names)
+	if err != nil {
+		return nil, err
+	}
+
+	stamp := time.Now().Format("2006-01-02_15-04-05")
+	var applied []AppliedFile
+	for _, t := range targets {
+		path := filepath.Join(configDir, t.Path)
+		backup, err := writeFileWithBackup(path, []byte(renderTerminalFormat(t.Name, colors)), stamp)
+		if err != nil {
+			return applied, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		applied = append(applied, AppliedFile{Target: t.Name, Path: path, Backup: backup})
+
+		if t.Include == "" {
+			continue
+		}
+		configPath := filepath.Join(configDir, t.Config)
+		existing, err := os.ReadFile(configPath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return applied, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		updated := AddConfigInclude(string(existing), t.IncludeLine(path))
+		if updated == string(existing) {
+			continue
+		}
+		backup, err = writeFileWithBackup(configPath, []byte(updated), stamp)
+		if err != nil {
+			return applied, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		applied = append(applied, AppliedFile{Target: t.Name, Path: configPath, Backup: backup})
+	}
+	return applied, nil
+}
+
+// IncludeLine is Include for the theme written to path
+func (t ApplyTarget) IncludeLine(path string) string {
+	return strings.ReplaceAll(t.Include, "{path}", path)
+}
+
+// HintFor is Hint for the theme written to path
+func (t ApplyTarget) HintFor(path string) string {
+	return strings.ReplaceAll(t.Hint, "{path}", path)
+}
+
+// ApplyTargetNamed looks up a target by name
+func ApplyTargetNamed(name string) (ApplyTarget, bool) {
+	for _, t := range ApplyTargets {
+		if t.Name == strings.ToLower(name) {
+			return t, true
+		}
+	}
+	return ApplyTarget{}, false
+}
+
+func applyTargetsByName(names []string) ([]ApplyTarget, error) {
+	if len(names) == 0 {
+		return ApplyTargets, nil
+	}
+	var targets []ApplyTarget
+	for _, name := range names {
+		t, ok := ApplyTargetNamed(name)
+		if !ok {
+			var known []string
+			for _, t := range ApplyTargets {
+				known = append(known, t.Name)
+			}
+			return nil, fmt.Errorf("unknown target %q (available: %s)", name, strings.Join(known, ", "))
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// AddConfigInclude returns config with line first, unless it already has
+// it. Going first keeps the user's own settings after it able to override
+// the theme.
+func AddConfigInclude(config, line string) string {
+	for _, l := range strings.Split(config, "\n") {
+		if strings.TrimSpace(l) == line {
+			return config
+		}
+	}
+	if config == "" {
+		return line + "\n"
+	}
+	return line + "\n" + config
+}
+
+// writeFileWithBackup replaces path with data in one rename, first copying
+// differing contents to path.backup.<stamp>, and returns the backup's path.
+// A symlinked path, as dotfile managers leave them, has its target
+// replaced instead of the link.
+func writeFileWithBackup(path string, data []byte, stamp string) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var backup string
+	mode := os.FileMode(0644)
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if bytes.Equal(existing, data) {
+			return "", nil
+		}
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		backup = path + ".backup." + stamp
+		if err := os.WriteFile(backup, existing, mode); err != nil {
+			return "", fmt.Errorf("failed to create backup: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
@@ -0,0 +1,109 @@
+package dank16
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddConfigInclude(t *testing.T) {
+	if got := AddConfigInclude("", "include dank-theme.conf"); got != "include dank-theme.conf\n" {
+		t.Errorf("empty config: got %q", got)
+	}
+	config := "font_size 12\n"
+	got := AddConfigInclude(config, "include dank-theme.conf")
+	if got != "include dank-theme.conf\nfont_size 12\n" {
+		t.Errorf("got %q", got)
+	}
+	if again := AddConfigInclude(got, "include dank-theme.conf"); again != got {
+		t.Errorf("include added twice: %q", again)
+	}
+}
+
+func TestApplyThemes(t *testing.T) {
+	dir := t.TempDir()
+	colors := GeneratePalette("#625690", PaletteOptions{UseDPS: true})
+
+	if err := os.MkdirAll(filepath.Join(dir, "foot"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	footConfig := filepath.Join(dir, "foot", "foot.ini")
+	if err := os.WriteFile(footConfig, []byte("font=monospace:size=11\n[colors]\nalpha=0.9\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "foot", "dank-colors.ini"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := ApplyThemes(dir, []string{"Foot", "kitty"}, colors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 3 {
+		t.Fatalf("expected the foot theme, foot.ini and the kitty theme, got %+v", applied)
+	}
+
+	theme, err := os.ReadFile(filepath.Join(dir, "foot", "dank-colors.ini"))
+	if err != nil || string(theme) != GenerateFootTheme(colors) {
+		t.Errorf("foot theme not written: %v", err)
+	}
+	if applied[0].Backup == "" {
+		t.Error("expected a backup of the old foot theme")
+	} else if old, _ := os.ReadFile(applied[0].Backup); string(old) != "old" {
+		t.Errorf("backup holds %q", old)
+	}
+
+	config, _ := os.ReadFile(footConfig)
+	if !strings.HasPrefix(string(config), "include="+filepath.Join(dir, "foot", "dank-colors.ini")+"\nfont=") {
+		t.Errorf("foot.ini should include the theme first, got %q", config)
+	}
+	if info, _ := os.Stat(footConfig); info.Mode().Perm() != 0600 {
+		t.Errorf("foot.ini mode changed to %v", info.Mode().Perm())
+	}
+
+	// kitty.conf does not exist, so only the theme is written
+	if applied[2].Path != filepath.Join(dir, "kitty", "dank-theme.conf") || applied[2].Backup != "" {
+		t.Errorf("unexpected kitty result %+v", applied[2])
+	}
+
+	// A second run with the same palette changes nothing
+	again, err := ApplyThemes(dir, []string{"foot"}, colors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 1 || again[0].Backup != "" {
+		t.Errorf("unchanged theme should not be backed up: %+v", again)
+	}
+
+	if _, err := ApplyThemes(dir, []string{"xterm"}, colors); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+}
+
+func TestApplyThemesFollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	dotfiles := t.TempDir()
+	target := filepath.Join(dotfiles, "dank-theme.conf")
+	if err := os.WriteFile(target, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "kitty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "kitty", "dank-theme.conf")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	colors := GeneratePalette("#625690", PaletteOptions{UseDPS: true})
+	if _, err := ApplyThemes(dir, []string{"kitty"}, colors); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("symlink was replaced")
+	}
+	if data, _ := os.ReadFile(target); string(data) != GenerateKittyTheme(colors) {
+		t.Error("symlink target not updated")
+	}
+}
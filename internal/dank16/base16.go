@@ -0,0 +1,75 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Base16Slots maps the palette onto base00–base0F: a ramp of eight shades
+// from background to foreground followed by eight accents
+func Base16Slots(colors []string) []string {
+	bg, fg := colors[0], colors[7]
+	return []string{
+		bg,
+		Mix(bg, fg, 0.08),
+		Mix(bg, fg, 0.16),
+		colors[8],
+		Mix(fg, bg, 0.3),
+		fg,
+		Mix(fg, colors[15], 0.5),
+		colors[15],
+		colors[1],
+		Mix(colors[1], colors[3], 0.5),
+		colors[3],
+		colors[2],
+		colors[6],
+		colors[4],
+		colors[5],
+		Mix(colors[1], bg, 0.35),
+	}
+}
+
+// Base24Slots extends Base16Slots with base10–base17: two deeper
+// backgrounds and the bright accents
+func Base24Slots(colors []string, isLight bool) []string {
+	away := "#000000"
+	if isLight {
+		away = "#ffffff"
+	}
+	return append(Base16Slots(colors),
+		Mix(colors[0], away, 0.15),
+		Mix(colors[0], away, 0.3),
+		colors[9],
+		colors[11],
+		colors[10],
+		colors[14],
+		colors[12],
+		colors[13],
+	)
+}
+
+// GenerateBase16YAML emits a base16 scheme file that flavours and the
+// tinted-theming builders read
+func GenerateBase16YAML(colors []string, isLight bool) string {
+	return schemeYAML(Base16Slots(colors), isLight)
+}
+
+// GenerateBase24YAML emits a base24 scheme file
+func GenerateBase24YAML(colors []string, isLight bool) string {
+	return schemeYAML(Base24Slots(colors, isLight), isLight)
+}
+
+func schemeYAML(slots []string, isLight bool) string {
+	variant := "Dark"
+	if isLight {
+		variant = "Light"
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "scheme: \"Dank16 %s\"\n", variant)
+	result.WriteString("author: \"dank16\"\n")
+	for i, color := range slots {
+		fmt.Fprintf(&result, "base%02X: \"%s\"\n", i, strings.TrimPrefix(color, "#"))
+	}
+	return result.String()
+}
@@ -0,0 +1,67 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGenerateBase16YAML(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	scheme := GenerateBase16YAML(colors, false)
+
+	lines := strings.Split(strings.TrimSpace(scheme), "\n")
+	if len(lines) != 18 {
+		t.Fatalf("expected scheme, author and 16 slots, got %d lines:\n%s", len(lines), scheme)
+	}
+	if lines[0] != `scheme: "Dank16 Dark"` || lines[1] != `author: "dank16"` {
+		t.Errorf("unexpected metadata %q %q", lines[0], lines[1])
+	}
+	for i, line := range lines[2:] {
+		if !strings.HasPrefix(line, fmt.Sprintf("base%02X: \"", i)) {
+			t.Errorf("line %q out of order", line)
+		}
+		if strings.Contains(line, "#") {
+			t.Errorf("base16 colors are written without '#': %q", line)
+		}
+	}
+
+	for _, want := range []string{
+		"base00: \"" + strings.TrimPrefix(colors[0], "#") + "\"\n",
+		"base05: \"" + strings.TrimPrefix(colors[7], "#") + "\"\n",
+		"base08: \"" + strings.TrimPrefix(colors[1], "#") + "\"\n",
+		"base0D: \"" + strings.TrimPrefix(colors[4], "#") + "\"\n",
+	} {
+		if !strings.Contains(scheme, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestBase16Ramp(t *testing.T) {
+	for _, isLight := range []bool{false, true} {
+		colors := GeneratePalette("#625690", PaletteOptions{IsLight: isLight})
+		slots := Base16Slots(colors)
+		// base00..base02 step away from the background towards the text
+		for i := 1; i <= 2; i++ {
+			if ContrastRatio(slots[i], slots[0]) <= ContrastRatio(slots[i-1], slots[0]) {
+				t.Errorf("light=%v: base%02X does not step away from base00", isLight, i)
+			}
+		}
+	}
+}
+
+func TestGenerateBase24YAML(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: true})
+	scheme := GenerateBase24YAML(colors, true)
+
+	if !strings.HasPrefix(scheme, "scheme: \"Dank16 Light\"\n") {
+		t.Errorf("unexpected header:\n%s", scheme)
+	}
+	if !strings.Contains(scheme, "base12: \""+strings.TrimPrefix(colors[9], "#")+"\"\n") {
+		t.Errorf("base12 should be bright red:\n%s", scheme)
+	}
+	if !strings.HasSuffix(scheme, "base17: \""+strings.TrimPrefix(colors[13], "#")+"\"\n") {
+		t.Errorf("base17 should be bright magenta and last:\n%s", scheme)
+	}
+}
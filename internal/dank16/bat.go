@@ -0,0 +1,132 @@
+package dank16
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatThemeName is the theme name bat and delta know the generated theme by
+const BatThemeName = "dank16"
+
+// batDiffScopes color diffs, which bat shows for patches and delta for every
+// line it does not highlight itself
+func batDiffScopes(colors []string) map[string]string {
+	return map[string]string{
+		"markup.inserted":  colors[2],
+		"markup.deleted":   colors[1],
+		"markup.changed":   colors[3],
+		"meta.diff.header": colors[4],
+		"meta.diff.range":  colors[5],
+		"markup.heading":   colors[12],
+		"markup.quote":     colors[8],
+		"markup.raw":       colors[3],
+	}
+}
+
+// GenerateBatTheme emits a TextMate .tmTheme for bat, using the scope colors
+// of the VSCode theme. Save it under ~/.config/bat/themes/, run
+// `bat cache --build` and select it with --theme=dank16.
+func GenerateBatTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	scopes := textMateColors(colors)
+	for scope, color := range batDiffScopes(colors) {
+		scopes[scope] = color
+	}
+	names := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		names = append(names, scope)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	result.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>name</key>
+	<string>` + BatThemeName + `</string>
+	<key>settings</key>
+	<array>
+		<dict>
+			<key>settings</key>
+			<dict>
+`)
+	for _, g := range []struct {
+		key   string
+		value string
+	}{
+		{"background", u.bg},
+		{"foreground", u.fg},
+		{"caret", u.accent},
+		{"lineHighlight", u.raised},
+		{"selection", WithAlpha(u.accent, 0.3)},
+		{"findHighlight", WithAlpha(colors[3], 0.3)},
+		{"gutter", u.bg},
+		{"gutterForeground", Mix(u.fg, u.bg, 0.55)},
+		{"invisibles", u.border},
+	} {
+		fmt.Fprintf(&result, "\t\t\t\t<key>%s</key>\n\t\t\t\t<string>%s</string>\n", g.key, g.value)
+	}
+	result.WriteString("\t\t\t</dict>\n\t\t</dict>\n")
+
+	for _, scope := range names {
+		result.WriteString("\t\t<dict>\n")
+		fmt.Fprintf(&result, "\t\t\t<key>scope</key>\n\t\t\t<string>%s</string>\n", scope)
+		result.WriteString("\t\t\t<key>settings</key>\n\t\t\t<dict>\n")
+		fmt.Fprintf(&result, "\t\t\t\t<key>foreground</key>\n\t\t\t\t<string>%s</string>\n", scopes[scope])
+		if strings.HasPrefix(scope, "comment") || scope == "punctuation.definition.comment" {
+			result.WriteString("\t\t\t\t<key>fontStyle</key>\n\t\t\t\t<string>italic</string>\n")
+		}
+		result.WriteString("\t\t\t</dict>\n\t\t</dict>\n")
+	}
+
+	result.WriteString("\t</array>\n</dict>\n</plist>\n")
+	return result.String()
+}
+
+// GenerateDeltaConfig emits a [delta] section for ~/.gitconfig. Syntax
+// highlighting comes from the bat theme, so install that first.
+func GenerateDeltaConfig(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.35)
+
+	mode := "dark"
+	if isLight {
+		mode = "light"
+	}
+
+	options := []struct {
+		name  string
+		value string
+	}{
+		{"syntax-theme", BatThemeName},
+		{mode, "true"},
+		{"minus-style", fmt.Sprintf("syntax \"%s\"", Mix(u.bg, colors[1], 0.15))},
+		{"minus-emph-style", fmt.Sprintf("syntax \"%s\"", Mix(u.bg, colors[1], 0.35))},
+		{"plus-style", fmt.Sprintf("syntax \"%s\"", Mix(u.bg, colors[2], 0.15))},
+		{"plus-emph-style", fmt.Sprintf("syntax \"%s\"", Mix(u.bg, colors[2], 0.35))},
+		{"map-styles", fmt.Sprintf("bold purple => syntax \"%s\", bold cyan => syntax \"%s\"", Mix(u.bg, colors[5], 0.2), Mix(u.bg, colors[6], 0.2))},
+		{"line-numbers-minus-style", fmt.Sprintf("\"%s\"", colors[1])},
+		{"line-numbers-plus-style", fmt.Sprintf("\"%s\"", colors[2])},
+		{"line-numbers-zero-style", fmt.Sprintf("\"%s\"", muted)},
+		{"line-numbers-left-style", fmt.Sprintf("\"%s\"", u.border)},
+		{"line-numbers-right-style", fmt.Sprintf("\"%s\"", u.border)},
+		{"file-style", fmt.Sprintf("bold \"%s\"", u.accentText)},
+		{"file-decoration-style", fmt.Sprintf("\"%s\" ul", u.accent)},
+		{"hunk-header-style", "file line-number syntax"},
+		{"hunk-header-decoration-style", fmt.Sprintf("\"%s\" box", u.border)},
+		{"hunk-header-file-style", fmt.Sprintf("\"%s\"", u.accentText)},
+		{"hunk-header-line-number-style", fmt.Sprintf("\"%s\"", muted)},
+		{"commit-decoration-style", fmt.Sprintf("\"%s\" box", u.accent)},
+		{"blame-palette", fmt.Sprintf("\"%s %s\"", u.bg, u.raised)},
+	}
+
+	var result strings.Builder
+	result.WriteString("# Generated by dank16\n[delta]\n")
+	for _, o := range options {
+		fmt.Fprintf(&result, "\t%s = %s\n", o.name, o.value)
+	}
+	return result.String()
+}
@@ -0,0 +1,50 @@
+package dank16
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGenerateBatTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	theme := GenerateBatTheme(colors, false)
+
+	decoder := xml.NewDecoder(strings.NewReader(theme))
+	decoder.Strict = false
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("invalid plist: %v", err)
+			}
+			break
+		}
+	}
+
+	for _, want := range []string{
+		"<string>" + BatThemeName + "</string>",
+		"<key>background</key>\n\t\t\t\t<string>" + colors[0] + "</string>",
+		"<string>entity.name.function</string>\n\t\t\t<key>settings</key>\n\t\t\t<dict>\n\t\t\t\t<key>foreground</key>\n\t\t\t\t<string>" + colors[2] + "</string>",
+		"<string>markup.inserted</string>",
+	} {
+		if !strings.Contains(theme, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestGenerateDeltaConfig(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: true})
+	conf := GenerateDeltaConfig(colors, true)
+
+	if !strings.Contains(conf, "[delta]\n") {
+		t.Errorf("missing section header:\n%s", conf)
+	}
+	for _, want := range []string{"\tsyntax-theme = dank16\n", "\tlight = true\n", "\tline-numbers-plus-style = \"" + colors[2] + "\"\n"} {
+		if !strings.Contains(conf, want) {
+			t.Errorf("missing %q in:\n%s", want, conf)
+		}
+	}
+}
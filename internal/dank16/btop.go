@@ -0,0 +1,75 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateBtopTheme emits a btop .theme file. Save it under
+// ~/.config/btop/themes/ and pick it with color_theme in btop.conf. Graph
+// gradients run from the calm to the alarming end of the palette, so a busy
+// CPU or a hot sensor reads red.
+func GenerateBtopTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.35)
+	red, green, yellow, blue, magenta, cyan := colors[1], colors[2], colors[3], colors[4], colors[5], colors[6]
+
+	keys := []struct{ name, value string }{
+		{"main_bg", u.bg},
+		{"main_fg", u.fg},
+		{"title", u.fg},
+		{"hi_fg", u.accentText},
+		{"selected_bg", u.accent},
+		{"selected_fg", u.onAccent},
+		{"inactive_fg", muted},
+		{"graph_text", muted},
+		{"meter_bg", u.raised},
+		{"proc_misc", cyan},
+		{"cpu_box", blue},
+		{"mem_box", green},
+		{"net_box", magenta},
+		{"proc_box", u.border},
+		{"div_line", u.border},
+	}
+	gradients := []struct{ name, start, end string }{
+		{"temp", blue, red},
+		{"cpu", green, red},
+		{"free", cyan, blue},
+		{"cached", blue, magenta},
+		{"available", yellow, green},
+		{"used", yellow, red},
+		{"download", blue, cyan},
+		{"upload", magenta, red},
+		{"process", green, red},
+	}
+	for _, g := range gradients {
+		keys = append(keys,
+			struct{ name, value string }{g.name + "_start", g.start},
+			struct{ name, value string }{g.name + "_mid", Mix(g.start, g.end, 0.5)},
+			struct{ name, value string }{g.name + "_end", g.end},
+		)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by dank16\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "theme[%s]=\"%s\"\n", k.name, k.value)
+	}
+	return b.String()
+}
+
+// GenerateHtoprc emits the color settings of an htoprc. htop has no custom
+// colors; its default scheme draws with the terminal's 16 ANSI colors, which
+// already follow the dank16 terminal theme, and light palettes need the
+// scheme meant for light terminals.
+func GenerateHtoprc(isLight bool) string {
+	scheme := 0
+	if isLight {
+		scheme = 3
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by dank16; merge into ~/.config/htop/htoprc\n")
+	fmt.Fprintf(&b, "color_scheme=%d\n", scheme)
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package dank16
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateBtopTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	theme := GenerateBtopTheme(colors, false)
+
+	line := regexp.MustCompile(`^theme\[[a-z_]+\]="#[0-9a-f]{6}"$`)
+	keys := make(map[string]bool)
+	for _, l := range strings.Split(strings.TrimSpace(theme), "\n")[1:] {
+		if !line.MatchString(l) {
+			t.Errorf("malformed line %q", l)
+			continue
+		}
+		keys[l[len("theme["):strings.Index(l, "]")]] = true
+	}
+
+	for _, want := range []string{"main_bg", "main_fg", "selected_bg", "cpu_box", "mem_box", "net_box", "proc_box"} {
+		if !keys[want] {
+			t.Errorf("missing %s", want)
+		}
+	}
+	for _, g := range []string{"temp", "cpu", "free", "cached", "available", "used", "download", "upload", "process"} {
+		for _, stop := range []string{"_start", "_mid", "_end"} {
+			if !keys[g+stop] {
+				t.Errorf("missing %s%s", g, stop)
+			}
+		}
+	}
+
+	if !strings.Contains(theme, `theme[main_bg]="`+colors[0]+`"`) {
+		t.Errorf("main_bg is not the palette background:\n%s", theme)
+	}
+	if !strings.Contains(theme, `theme[cpu_end]="`+colors[1]+`"`) {
+		t.Errorf("cpu gradient does not end in red:\n%s", theme)
+	}
+}
+
+func TestGenerateHtoprc(t *testing.T) {
+	if got := GenerateHtoprc(false); !strings.Contains(got, "color_scheme=0\n") {
+		t.Errorf("dark htoprc = %q", got)
+	}
+	if got := GenerateHtoprc(true); !strings.Contains(got, "color_scheme=3\n") {
+		t.Errorf("light htoprc = %q", got)
+	}
+}
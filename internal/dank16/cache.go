@@ -0,0 +1,143 @@
+package dank16
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultPaletteCacheSize covers a wallpaper rotation many times over
+	DefaultPaletteCacheSize = 32
+
+	// paletteCacheVersion is hashed into every key. Bump it when
+	// GeneratePalette changes its output so old entries stop matching.
+	paletteCacheVersion = 2
+)
+
+// PaletteCache keeps recently generated palettes on disk, least recently
+// used first out. The shell regenerates on every wallpaper change, usually
+// for colors it has seen before.
+type PaletteCache struct {
+	path     string
+	size     int
+	generate func(string, PaletteOptions) []string
+}
+
+type paletteCacheEntry struct {
+	Key    string   `json:"key"`
+	Colors []string `json:"colors"`
+}
+
+// NewPaletteCache stores up to size palettes under XDG_CACHE_HOME. Without
+// a cache directory it still works, generating every time.
+func NewPaletteCache(size int) *PaletteCache {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			cacheHome = filepath.Join(home, ".cache")
+		}
+	}
+
+	path := ""
+	if cacheHome != "" {
+		path = filepath.Join(cacheHome, "DankMaterialShell", "dank16-palettes.json")
+	}
+	return &PaletteCache{path: path, size: size, generate: GeneratePalette}
+}
+
+// paletteCacheKey hashes everything GeneratePalette reads
+func paletteCacheKey(primaryColor string, opts PaletteOptions) string {
+	data, _ := json.Marshal(struct {
+		Version int
+		Primary string
+		Options PaletteOptions
+	}{paletteCacheVersion, strings.ToLower(primaryColor), opts})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Palette returns the cached palette for these inputs, generating and
+// storing it on a miss. Cache files that can't be read or written are
+// ignored rather than failing the palette; colors that don't parse are an
+// error, as with ParsePalette.
+func (c *PaletteCache) Palette(primaryColor string, opts PaletteOptions) ([]string, error) {
+	primaryColor, err := NormalizeHex(primaryColor)
+	if err != nil {
+		return nil, err
+	}
+	if opts, err = opts.Normalize(); err != nil {
+		return nil, err
+	}
+	if c.path == "" || c.size <= 0 {
+		return c.generate(primaryColor, opts), nil
+	}
+
+	key := paletteCacheKey(primaryColor, opts)
+	entries := c.load()
+
+	var colors []string
+	for i, e := range entries {
+		if e.Key == key && len(e.Colors) == 16 {
+			colors = e.Colors
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if colors == nil {
+		colors = c.generate(primaryColor, opts)
+	}
+
+	entries = append([]paletteCacheEntry{{Key: key, Colors: colors}}, entries...)
+	if len(entries) > c.size {
+		entries = entries[:c.size]
+	}
+	c.save(entries)
+
+	return append([]string(nil), colors...), nil
+}
+
+func (c *PaletteCache) load() []paletteCacheEntry {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil
+	}
+	var entries []paletteCacheEntry
+	if json.Unmarshal(data, &entries) != nil {
+		return nil
+	}
+	return entries
+}
+
+// save replaces the file in one rename so a shell and a terminal running
+// dank16 at once never read half a cache
+func (c *PaletteCache) save(entries []paletteCacheEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".dank16-palettes-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to replace palette cache: %w", err)
+	}
+	return nil
+}
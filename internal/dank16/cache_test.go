@@ -0,0 +1,156 @@
+package dank16
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// paletteOutputHashes records, per paletteCacheVersion, a hash of what
+// GeneratePalette produced. Add an entry with each bump; never edit one.
+var paletteOutputHashes = map[int]string{
+	2: "e04059ccae7ec2553ff27e4cc6c8ec50",
+	3: "22b805f819067a896a1085e310dbb006",
+}
+
+// paletteOutputHash covers every mode and contrast algorithm, with and
+// without honored accents and a custom background
+func paletteOutputHash() string {
+	h := sha256.New()
+	for _, seed := range []string{"#625690", "#42a5f5", "#e06c75", "#98c379", "#ffb300", "#00bcd4", "#101010", "#f0f0f0"} {
+		for _, base := range []PaletteOptions{{}, {UseDPS: true}, {UseAPCA: true}} {
+			for _, opts := range []PaletteOptions{
+				base,
+				{UseDPS: base.UseDPS, UseAPCA: base.UseAPCA, IsLight: true},
+				{UseDPS: base.UseDPS, UseAPCA: base.UseAPCA, Background: "#202030"},
+				{UseDPS: base.UseDPS, UseAPCA: base.UseAPCA, IsLight: true, HonorPrimary: "#1a5fb4", HonorSecondary: "#c2185b", HonorTertiary: "#00695c"},
+			} {
+				h.Write([]byte(strings.Join(GeneratePalette(seed, opts), ",") + "\n"))
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func TestPaletteCacheVersionTracksOutput(t *testing.T) {
+	got := paletteOutputHash()
+	want, ok := paletteOutputHashes[paletteCacheVersion]
+	if !ok {
+		t.Fatalf("no output hash for paletteCacheVersion %d; add %d: %q", paletteCacheVersion, paletteCacheVersion, got)
+	}
+	if got != want {
+		t.Fatalf("GeneratePalette output changed (hash %s, version %d recorded %s); bump paletteCacheVersion and record the new hash", got, paletteCacheVersion, want)
+	}
+	for version, hash := range paletteOutputHashes {
+		if version != paletteCacheVersion && hash == got {
+			t.Errorf("output matches version %d; paletteCacheVersion %d should not have been bumped", version, paletteCacheVersion)
+		}
+	}
+}
+
+func newTestCache(t *testing.T, size int) (*PaletteCache, *int) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache := NewPaletteCache(size)
+	calls := 0
+	cache.generate = func(primary string, opts PaletteOptions) []string {
+		calls++
+		return GeneratePalette(primary, opts)
+	}
+	return cache, &calls
+}
+
+func TestPaletteCache(t *testing.T) {
+	cache, calls := newTestCache(t, 4)
+
+	first, err := cache.Palette("#625690", PaletteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, GeneratePalette("#625690", PaletteOptions{})) {
+		t.Fatal("cached palette differs from GeneratePalette")
+	}
+	if _, err := os.Stat(filepath.Join(os.Getenv("XDG_CACHE_HOME"), "DankMaterialShell", "dank16-palettes.json")); err != nil {
+		t.Fatalf("cache file not written: %v", err)
+	}
+
+	// The options are part of the key
+	again, _ := cache.Palette("#625690", PaletteOptions{})
+	cache.Palette("#625690", PaletteOptions{IsLight: true})
+	cache.Palette("#625690", PaletteOptions{HonorPrimary: "#ff0000"})
+	if *calls != 3 {
+		t.Errorf("expected 3 generations, got %d", *calls)
+	}
+	if !reflect.DeepEqual(first, again) {
+		t.Error("hit returned a different palette")
+	}
+
+	// Callers may modify what they get back
+	again[0] = "#000000"
+	if hit, _ := cache.Palette("#625690", PaletteOptions{}); hit[0] == "#000000" {
+		t.Error("modifying a returned palette changed the cache")
+	}
+}
+
+func TestPaletteCache_LRU(t *testing.T) {
+	cache, calls := newTestCache(t, 2)
+
+	cache.Palette("#111111", PaletteOptions{})
+	cache.Palette("#222222", PaletteOptions{})
+	cache.Palette("#111111", PaletteOptions{}) // #222222 is now the oldest
+	cache.Palette("#333333", PaletteOptions{}) // evicts #222222
+	if *calls != 3 {
+		t.Fatalf("expected 3 generations, got %d", *calls)
+	}
+
+	cache.Palette("#111111", PaletteOptions{})
+	if *calls != 3 {
+		t.Error("recently used entry was evicted")
+	}
+	cache.Palette("#222222", PaletteOptions{})
+	if *calls != 4 {
+		t.Error("least recently used entry was kept")
+	}
+}
+
+func TestPaletteCache_CorruptFile(t *testing.T) {
+	cache, calls := newTestCache(t, 4)
+	if err := os.MkdirAll(filepath.Dir(cache.path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cache.path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := cache.Palette("#625690", PaletteOptions{}); len(got) != 16 {
+		t.Fatalf("expected a palette, got %v", got)
+	}
+	cache.Palette("#625690", PaletteOptions{})
+	if *calls != 1 {
+		t.Errorf("corrupt cache should be replaced, got %d generations", *calls)
+	}
+}
+
+func TestPaletteCache_InvalidColor(t *testing.T) {
+	cache, calls := newTestCache(t, 4)
+
+	if _, err := cache.Palette("not a color", PaletteOptions{}); err == nil {
+		t.Error("expected an error for an invalid primary")
+	}
+	if _, err := cache.Palette("#625690", PaletteOptions{HonorPrimary: "#12345"}); err == nil {
+		t.Error("expected an error for an invalid honored primary")
+	}
+	if *calls != 0 {
+		t.Errorf("invalid colors were generated from %d times", *calls)
+	}
+
+	// Spellings of the same color share an entry
+	cache.Palette("#625690", PaletteOptions{})
+	cache.Palette("625690", PaletteOptions{})
+	if *calls != 1 {
+		t.Errorf("expected 1 generation, got %d", *calls)
+	}
+}
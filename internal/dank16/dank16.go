@@ -15,17 +15,12 @@ type HSV struct {
 	H, S, V float64
 }
 
+// HexToRGB is ParseHex for colors already known to be valid, such as
+// palette slots. Anything it can't parse, including an empty string, is
+// black; parse input from users with ParseHex or NormalizeHex instead.
 func HexToRGB(hex string) RGB {
-	if hex[0] == '#' {
-		hex = hex[1:]
-	}
-	var r, g, b uint8
-	fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b)
-	return RGB{
-		R: float64(r) / 255.0,
-		G: float64(g) / 255.0,
-		B: float64(b) / 255.0,
-	}
+	rgb, _ := ParseHex(hex)
+	return rgb
 }
 
 func RGBToHex(rgb RGB) string {
@@ -265,6 +260,7 @@ func EnsureContrastDPSLstar(hexColor, hexBg string, minLc float64, isLightMode b
 	fg := HexToRGB(hexColor)
 	cf := colorful.Color{R: fg.R, G: fg.G, B: fg.B}
 	Lf, af, bf := cf.Lab()
+	Lf *= 100 // labToHex takes L* in 0-100
 
 	dir := 1.0
 	if isLightMode {
@@ -287,9 +283,106 @@ type PaletteOptions struct {
 	IsLight    bool
 	Background string
 	UseDPS     bool
+	// UseAPCA measures contrast as APCA Lc and takes precedence over UseDPS
+	UseAPCA bool
+	// HonorPrimary, HonorSecondary and HonorTertiary are accent colors, as
+	// extracted by Material You, placed in the blue, magenta and cyan slots
+	// in place of the hues derived from the seed. They are only moved as far
+	// as contrast requires. Secondary and tertiary also tint the background
+	// and bright black unless Background is set.
+	HonorPrimary   string
+	HonorSecondary string
+	HonorTertiary  string
+}
+
+// Chroma (go-colorful Lab units) of the accent tint on neutral slots
+const (
+	surfaceTint = 0.02
+	dimTint     = 0.04
+)
+
+// tint gives a neutral color a trace of accent's hue, keeping its lightness
+func tint(neutral, accent string, chroma float64) string {
+	rgb := HexToRGB(accent)
+	_, a, b := colorful.Color{R: rgb.R, G: rgb.G, B: rgb.B}.Lab()
+	c := math.Hypot(a, b)
+	if c == 0 {
+		return neutral
+	}
+	return labToHex(getLstar(neutral), a/c*chroma, b/c*chroma)
+}
+
+// honoredAccent places an accent in a normal/bright slot pair
+func honoredAccent(accent, bg string, normalTarget, brightTarget float64, opts PaletteOptions) (string, string) {
+	normal := liftContrast(ensureContrastAuto(accent, bg, normalTarget, opts), bg, normalTarget, opts)
+
+	var bright string
+	if opts.IsLight {
+		bright = Lighten(accent, 0.12)
+	} else {
+		bright = Lighten(accent, 0.25)
+	}
+	return normal, liftContrast(ensureContrastAuto(bright, bg, brightTarget, opts), bg, brightTarget, opts)
+}
+
+// liftContrast moves L* away from the background, keeping the hue, until
+// target is met. The HSV search gives up on accents that start out very
+// close to the background; this does not.
+func liftContrast(hexColor, hexBg string, target float64, opts PaletteOptions) string {
+	meets := func(c string) bool {
+		return measureContrast(c, hexBg, opts) >= target
+	}
+	if meets(hexColor) {
+		return hexColor
+	}
+
+	rgb := HexToRGB(hexColor)
+	L, a, b := colorful.Color{R: rgb.R, G: rgb.G, B: rgb.B}.Lab()
+
+	dir := 1.0
+	if opts.IsLight {
+		dir = -1.0
+	}
+	for L100 := L * 100; L100 >= 0 && L100 <= 100; L100 += dir {
+		if cand := labToHex(L100, a, b); meets(cand) {
+			return cand
+		}
+	}
+	return hexColor
+}
+
+// measureContrast is the contrast of fg on bg in the units of the selected
+// algorithm: APCA Lc, DPS Lc or a WCAG ratio
+func measureContrast(fg, bg string, opts PaletteOptions) float64 {
+	if opts.UseAPCA {
+		return APCAContrastForMode(fg, bg, opts.IsLight)
+	}
+	if opts.UseDPS {
+		return DeltaPhiStarContrast(fg, bg, opts.IsLight)
+	}
+	return ContrastRatio(fg, bg)
+}
+
+// contrastTargets are the minimum contrast of the normal and bright slots.
+// Delta Phi Star grows faster near white, so light mode needs higher Lc
+// targets to stay near WCAG 4.5 and 3 like the dark ones do.
+func contrastTargets(opts PaletteOptions) (normal, bright float64) {
+	switch {
+	case opts.UseAPCA:
+		return 60.0, 45.0
+	case opts.UseDPS && opts.IsLight:
+		return 70.0, 55.0
+	case opts.UseDPS:
+		return 40.0, 35.0
+	default:
+		return 4.5, 3.0
+	}
 }
 
 func ensureContrastAuto(hexColor, hexBg string, target float64, opts PaletteOptions) string {
+	if opts.UseAPCA {
+		return EnsureContrastAPCA(hexColor, hexBg, target, opts.IsLight)
+	}
 	if opts.UseDPS {
 		return EnsureContrastDPSLstar(hexColor, hexBg, target, opts.IsLight)
 	}
@@ -310,6 +403,40 @@ func DeriveContainer(primary string, isLight bool) string {
 	return RGBToHex(HSVToRGB(HSV{H: hsv.H, S: containerS, V: containerV}))
 }
 
+// AccentSlot is the palette slot holding the accent; its bright variant is
+// AccentSlot+8. GTK, Qt, VSCode, the compositor formats and the JSON roles
+// all take their accent from here, so no output derives its own.
+const AccentSlot = 4
+
+// AccentColor is the accent GeneratePalette resolved into colors
+func AccentColor(colors []string) string {
+	return colors[AccentSlot]
+}
+
+// accentSlots resolves the accent and its bright variant once:
+// HonorPrimary when given, moved only as far as contrast requires,
+// otherwise a color of the seed's hue. container is the seed's container
+// color the other slots are derived from.
+func accentSlots(primaryColor string, container HSV, bgColor string, normalTarget, brightTarget float64, opts PaletteOptions) (string, string) {
+	if opts.HonorPrimary != "" {
+		return honoredAccent(opts.HonorPrimary, bgColor, normalTarget, brightTarget, opts)
+	}
+
+	seed := RGBToHSV(HexToRGB(primaryColor))
+	if opts.IsLight {
+		accent := RGBToHex(HSVToRGB(HSV{H: container.H, S: math.Max(container.S*0.9, 0.7), V: container.V * 1.1}))
+		bright := RGBToHex(HSVToRGB(HSV{H: seed.H, S: math.Min(seed.S*1.1, 1.0), V: math.Min(seed.V*1.2, 1.0)}))
+		return ensureContrastAuto(accent, bgColor, normalTarget, opts), ensureContrastAuto(bright, bgColor, brightTarget, opts)
+	}
+
+	accent := RGBToHex(HSVToRGB(HSV{H: container.H, S: math.Max(container.S*0.8, 0.6), V: math.Min(container.V*1.6, 1.0)}))
+	// Make the bright one way brighter for type names in dark mode
+	return ensureContrastAuto(accent, bgColor, normalTarget, opts), retoneToL(primaryColor, 85.0)
+}
+
+// GeneratePalette derives the 16 colors from primaryColor. It expects the
+// primary and every color in opts as #rrggbb and reads anything else as
+// black; colors from users go through ParsePalette, which reports them.
 func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 	baseColor := DeriveContainer(primaryColor, opts.IsLight)
 
@@ -318,14 +445,7 @@ func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 
 	palette := make([]string, 0, 16)
 
-	var normalTextTarget, secondaryTarget float64
-	if opts.UseDPS {
-		normalTextTarget = 40.0
-		secondaryTarget = 35.0
-	} else {
-		normalTextTarget = 4.5
-		secondaryTarget = 3.0
-	}
+	normalTextTarget, secondaryTarget := contrastTargets(opts)
 
 	var bgColor string
 	if opts.Background != "" {
@@ -335,6 +455,9 @@ func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 	} else {
 		bgColor = "#1a1a1a"
 	}
+	if opts.Background == "" && opts.HonorSecondary != "" {
+		bgColor = tint(bgColor, opts.HonorSecondary, surfaceTint)
+	}
 	palette = append(palette, bgColor)
 
 	hueShift := (hsv.H - 0.6) * 0.12
@@ -370,23 +493,20 @@ func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 		palette = append(palette, ensureContrastAuto(yellowColor, bgColor, normalTextTarget, opts))
 	}
 
-	var blueColor string
-	if opts.IsLight {
-		blueColor = RGBToHex(HSVToRGB(HSV{H: hsv.H, S: math.Max(hsv.S*0.9, 0.7), V: hsv.V * 1.1}))
-		palette = append(palette, ensureContrastAuto(blueColor, bgColor, normalTextTarget, opts))
-	} else {
-		blueColor = RGBToHex(HSVToRGB(HSV{H: hsv.H, S: math.Max(hsv.S*0.8, 0.6), V: math.Min(hsv.V*1.6, 1.0)}))
-		palette = append(palette, ensureContrastAuto(blueColor, bgColor, normalTextTarget, opts))
-	}
+	accent, brightAccent := accentSlots(primaryColor, hsv, bgColor, normalTextTarget, secondaryTarget, opts)
+	palette = append(palette, accent)
 
 	magH := hsv.H - 0.03
 	if magH < 0 {
 		magH += 1.0
 	}
-	var magColor string
+	var magColor, honoredBrightMag string
 	hr := HexToRGB(primaryColor)
 	hh := RGBToHSV(hr)
-	if opts.IsLight {
+	if opts.HonorSecondary != "" {
+		magColor, honoredBrightMag = honoredAccent(opts.HonorSecondary, bgColor, normalTextTarget, secondaryTarget, opts)
+		palette = append(palette, magColor)
+	} else if opts.IsLight {
 		magColor = RGBToHex(HSVToRGB(HSV{H: hh.H, S: math.Max(hh.S*0.9, 0.7), V: hh.V * 0.85}))
 		palette = append(palette, ensureContrastAuto(magColor, bgColor, normalTextTarget, opts))
 	} else {
@@ -398,15 +518,26 @@ func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 	if cyanH > 1.0 {
 		cyanH -= 1.0
 	}
-	palette = append(palette, ensureContrastAuto(primaryColor, bgColor, normalTextTarget, opts))
+	var honoredBrightCyan string
+	if opts.HonorTertiary != "" {
+		var cyanColor string
+		cyanColor, honoredBrightCyan = honoredAccent(opts.HonorTertiary, bgColor, normalTextTarget, secondaryTarget, opts)
+		palette = append(palette, cyanColor)
+	} else {
+		palette = append(palette, ensureContrastAuto(primaryColor, bgColor, normalTextTarget, opts))
+	}
 
+	dimColor := "#5c6370"
 	if opts.IsLight {
 		palette = append(palette, "#1a1a1a")
-		palette = append(palette, "#2e2e2e")
+		dimColor = "#2e2e2e"
 	} else {
 		palette = append(palette, "#abb2bf")
-		palette = append(palette, "#5c6370")
 	}
+	if opts.Background == "" && opts.HonorTertiary != "" {
+		dimColor = tint(dimColor, opts.HonorTertiary, dimTint)
+	}
+	palette = append(palette, dimColor)
 
 	if opts.IsLight {
 		brightRed := RGBToHex(HSVToRGB(HSV{H: redH, S: math.Min(0.70*satBoost, 1.0), V: 0.65}))
@@ -415,10 +546,7 @@ func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 		palette = append(palette, ensureContrastAuto(brightGreen, bgColor, secondaryTarget, opts))
 		brightYellow := RGBToHex(HSVToRGB(HSV{H: yellowH, S: math.Min(0.68*satBoost, 1.0), V: 0.60}))
 		palette = append(palette, ensureContrastAuto(brightYellow, bgColor, secondaryTarget, opts))
-		hr := HexToRGB(primaryColor)
-		hh := RGBToHSV(hr)
-		brightBlue := RGBToHex(HSVToRGB(HSV{H: hh.H, S: math.Min(hh.S*1.1, 1.0), V: math.Min(hh.V*1.2, 1.0)}))
-		palette = append(palette, ensureContrastAuto(brightBlue, bgColor, secondaryTarget, opts))
+		palette = append(palette, brightAccent)
 		brightMag := RGBToHex(HSVToRGB(HSV{H: magH, S: math.Max(hsv.S*0.9, 0.75), V: math.Min(hsv.V*1.25, 1.0)}))
 		palette = append(palette, ensureContrastAuto(brightMag, bgColor, secondaryTarget, opts))
 		brightCyan := RGBToHex(HSVToRGB(HSV{H: cyanH, S: math.Max(hsv.S*0.75, 0.65), V: math.Min(hsv.V*1.25, 1.0)}))
@@ -430,9 +558,7 @@ func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 		palette = append(palette, ensureContrastAuto(brightGreen, bgColor, secondaryTarget, opts))
 		brightYellow := RGBToHex(HSVToRGB(HSV{H: yellowH, S: math.Min(0.30*satBoost, 1.0), V: 0.91}))
 		palette = append(palette, ensureContrastAuto(brightYellow, bgColor, secondaryTarget, opts))
-		// Make it way brighter for type names in dark mode
-		brightBlue := retoneToL(primaryColor, 85.0)
-		palette = append(palette, brightBlue)
+		palette = append(palette, brightAccent)
 		brightMag := RGBToHex(HSVToRGB(HSV{H: magH, S: math.Max(hsv.S*0.7, 0.6), V: math.Min(hsv.V*1.3, 0.9)}))
 		palette = append(palette, ensureContrastAuto(brightMag, bgColor, secondaryTarget, opts))
 		brightCyanH := hsv.H + 0.02
@@ -443,6 +569,13 @@ func GeneratePalette(primaryColor string, opts PaletteOptions) []string {
 		palette = append(palette, ensureContrastAuto(brightCyan, bgColor, secondaryTarget, opts))
 	}
 
+	if honoredBrightMag != "" {
+		palette[13] = honoredBrightMag
+	}
+	if honoredBrightCyan != "" {
+		palette[14] = honoredBrightCyan
+	}
+
 	if opts.IsLight {
 		palette = append(palette, "#1a1a1a")
 	} else {
@@ -3,6 +3,7 @@ package dank16
 import (
 	"encoding/json"
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -389,7 +390,7 @@ func TestEnrichVSCodeTheme(t *testing.T) {
 		t.Fatalf("Failed to marshal base theme: %v", err)
 	}
 
-	result, err := EnrichVSCodeTheme(themeJSON, colors)
+	result, err := EnrichVSCodeTheme(themeJSON, colors, VSCodeMinContrast)
 	if err != nil {
 		t.Fatalf("EnrichVSCodeTheme failed: %v", err)
 	}
@@ -436,11 +437,88 @@ func TestEnrichVSCodeTheme(t *testing.T) {
 	}
 }
 
+func TestGenerateVSCodeTheme(t *testing.T) {
+	for _, isLight := range []bool{false, true} {
+		colors := GeneratePalette("#625690", PaletteOptions{IsLight: isLight, UseDPS: true})
+
+		var theme VSCodeTheme
+		if err := json.Unmarshal([]byte(GenerateVSCodeTheme(colors, isLight)), &theme); err != nil {
+			t.Fatalf("theme is not JSON: %v", err)
+		}
+
+		wantType := "dark"
+		if isLight {
+			wantType = "light"
+		}
+		if theme.Type != wantType {
+			t.Errorf("type = %q, want %q", theme.Type, wantType)
+		}
+		if theme.Colors["editor.background"] != colors[0] || theme.Colors["terminal.ansiRed"] != colors[1] {
+			t.Error("editor and terminal colors should come from the palette")
+		}
+		if len(theme.TokenColors) < len(textMateColors(colors)) || len(theme.SemanticTokenColors) == 0 {
+			t.Errorf("expected token colors for every scope, got %d", len(theme.TokenColors))
+		}
+		for _, tc := range theme.TokenColors {
+			if lc := DeltaPhiStarContrast(tc.Settings.Foreground, colors[0], isLight); lc < VSCodeMinContrast-0.5 {
+				t.Errorf("%v: %s has Lc %.1f against the editor", tc.Scope, tc.Settings.Foreground, lc)
+			}
+		}
+	}
+}
+
+func TestEnrichVSCodeThemeContrast(t *testing.T) {
+	// A dark palette enriching a theme whose editor stays light
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	themeJSON := []byte(`{
+		"colors": {"editor.background": "#fafafa"},
+		"tokenColors": [{"scope": ["comment"], "settings": {}}, {"scope": ["keyword"], "settings": {}}],
+		"semanticTokenColors": {"function": {"foreground": "#000000"}}
+	}`)
+
+	result, err := EnrichVSCodeTheme(themeJSON, colors, VSCodeMinContrast)
+	if err != nil {
+		t.Fatalf("EnrichVSCodeTheme failed: %v", err)
+	}
+
+	var enriched VSCodeTheme
+	if err := json.Unmarshal(result, &enriched); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+
+	var foregrounds []string
+	for _, tc := range enriched.TokenColors {
+		foregrounds = append(foregrounds, tc.Settings.Foreground)
+	}
+	for _, setting := range enriched.SemanticTokenColors {
+		foregrounds = append(foregrounds, setting.Foreground)
+	}
+	for _, fg := range foregrounds {
+		if lc := DeltaPhiStarContrast(fg, "#fafafa", true); lc < VSCodeMinContrast-0.5 {
+			t.Errorf("%s on #fafafa has Lc %.1f, want at least %g", fg, lc, VSCodeMinContrast)
+		}
+	}
+	if enriched.Colors["terminal.ansiWhite"] != colors[7] {
+		t.Error("terminal colors should not be adjusted")
+	}
+
+	unchecked, err := EnrichVSCodeTheme(themeJSON, colors, 0)
+	if err != nil {
+		t.Fatalf("EnrichVSCodeTheme failed: %v", err)
+	}
+	if err := json.Unmarshal(unchecked, &enriched); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if got := enriched.SemanticTokenColors["function"].Foreground; got != semanticColors(colors)["function"] {
+		t.Errorf("with no minimum, function = %s, want the palette color", got)
+	}
+}
+
 func TestEnrichVSCodeThemeInvalidJSON(t *testing.T) {
 	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
 	invalidJSON := []byte("{invalid json")
 
-	_, err := EnrichVSCodeTheme(invalidJSON, colors)
+	_, err := EnrichVSCodeTheme(invalidJSON, colors, VSCodeMinContrast)
 	if err == nil {
 		t.Error("Expected error for invalid JSON, got nil")
 	}
@@ -725,3 +803,168 @@ func TestContrastAlgorithmComparison(t *testing.T) {
 
 	t.Logf("WCAG and DPS palettes differ in %d/16 colors", differentCount)
 }
+
+// TestGeneratePalettePinned catches unintended palette changes. When a
+// change is intended, update these and bump paletteCacheVersion.
+func TestGeneratePalettePinned(t *testing.T) {
+	tests := []struct {
+		seed string
+		opts PaletteOptions
+		want []string
+	}{
+		{"#42a5f5", PaletteOptions{}, []string{"#1a1a1a", "#f43d40", "#72d66e", "#dbd07b", "#2a86d2", "#4c87b7", "#42a5f5", "#abb2bf", "#5c6370", "#e05f61", "#89e086", "#e8de97", "#9ad9ff", "#2c7692", "#4069a0", "#ffffff"}},
+		{"#42a5f5", PaletteOptions{IsLight: true}, []string{"#f8f8f8", "#8c0b0d", "#0d7209", "#7f7211", "#34779e", "#3376ac", "#2e74ad", "#1a1a1a", "#2e2e2e", "#a52022", "#178c13", "#998a21", "#2c92e5", "#2f9ac1", "#5964ff", "#1a1a1a"}},
+		{"#42a5f5", PaletteOptions{UseDPS: true}, []string{"#1a1a1a", "#e84e4b", "#72d66e", "#dbd07b", "#4488cc", "#4f8aba", "#42a5f5", "#abb2bf", "#5c6370", "#e05f61", "#89e086", "#e8de97", "#9ad9ff", "#3d84a1", "#5d7dae", "#ffffff"}},
+		{"#42a5f5", PaletteOptions{IsLight: true, UseDPS: true}, []string{"#f8f8f8", "#8c0b0d", "#0d7209", "#7c700d", "#0075af", "#0874b3", "#0073be", "#1a1a1a", "#2e2e2e", "#a52022", "#178c13", "#998a21", "#0395ef", "#009bcd", "#5964ff", "#1a1a1a"}},
+		{"#42a5f5", PaletteOptions{UseAPCA: true}, []string{"#1a1a1a", "#ff968a", "#72d66e", "#dbd07b", "#7ab7fe", "#82b9ec", "#5fbbff", "#abb2bf", "#5c6370", "#f16e6f", "#89e086", "#e8de97", "#9ad9ff", "#5da1be", "#7a99cc", "#ffffff"}},
+		{"#42a5f5", PaletteOptions{IsLight: true, UseAPCA: true}, []string{"#f8f8f8", "#8c0b0d", "#0d7209", "#7f7211", "#008cc7", "#378aca", "#008ad7", "#1a1a1a", "#2e2e2e", "#a52022", "#178c13", "#998a21", "#32a3ff", "#00aee1", "#5964ff", "#1a1a1a"}},
+		{"#625690", PaletteOptions{}, []string{"#1a1a1a", "#ea473a", "#6ed674", "#dbd87b", "#372a69", "#887cb8", "#8877c8", "#abb2bf", "#5c6370", "#e0685f", "#86e08a", "#e8e597", "#dccaff", "#5755d4", "#7356af", "#ffffff"}},
+		{"#625690", PaletteOptions{IsLight: true}, []string{"#f8f8f8", "#8c140b", "#09720e", "#74720f", "#7551f9", "#36247a", "#625690", "#1a1a1a", "#2e2e2e", "#a52920", "#138c19", "#93901f", "#7060ac", "#413fff", "#c759ff", "#1a1a1a"}},
+		{"#625690", PaletteOptions{UseDPS: true}, []string{"#1a1a1a", "#e45344", "#6ed674", "#dbd87b", "#8d79c2", "#877fa5", "#897bb8", "#abb2bf", "#5c6370", "#e0685f", "#86e08a", "#e8e597", "#dccaff", "#7c74b1", "#8473a1", "#ffffff"}},
+		{"#625690", PaletteOptions{IsLight: true, UseDPS: true}, []string{"#f8f8f8", "#8c140b", "#09720e", "#747200", "#724ef9", "#36247a", "#625690", "#1a1a1a", "#2e2e2e", "#a52920", "#138c19", "#96921e", "#7060ac", "#413fff", "#c759ff", "#1a1a1a"}},
+		{"#625690", PaletteOptions{UseAPCA: true}, []string{"#1a1a1a", "#ff9781", "#6ed674", "#dbd87b", "#bfa8f5", "#b7aed6", "#baaaeb", "#abb2bf", "#5c6370", "#ec7369", "#86e08a", "#e8e597", "#dccaff", "#9a90cf", "#a290c0", "#ffffff"}},
+		{"#625690", PaletteOptions{IsLight: true, UseAPCA: true}, []string{"#f8f8f8", "#8c140b", "#09720e", "#7f7c11", "#7853ff", "#36247a", "#625690", "#1a1a1a", "#2e2e2e", "#a52920", "#138c19", "#999521", "#7060ac", "#413fff", "#c759ff", "#1a1a1a"}},
+	}
+
+	for _, tt := range tests {
+		got := GeneratePalette(tt.seed, tt.opts)
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s %+v: color%d = %s, want %s", tt.seed, tt.opts, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestGeneratePaletteLightDPSReadable(t *testing.T) {
+	for _, seed := range []string{"#42a5f5", "#625690", "#2e7de9", "#ffb300", "#00bcd4", "#4caf50"} {
+		palette := GeneratePalette(seed, PaletteOptions{IsLight: true, UseDPS: true})
+		for i := 1; i <= 6; i++ {
+			if ratio := ContrastRatio(palette[i], palette[0]); ratio < 4.5 {
+				t.Errorf("%s: color%d = %s has WCAG %.2f against %s", seed, i, palette[i], ratio, palette[0])
+			}
+		}
+		for i := 9; i <= 14; i++ {
+			if ratio := ContrastRatio(palette[i], palette[0]); ratio < 3 {
+				t.Errorf("%s: color%d = %s has WCAG %.2f against %s", seed, i, palette[i], ratio, palette[0])
+			}
+		}
+	}
+}
+
+func TestGeneratePaletteHonoredAccents(t *testing.T) {
+	base := "#625690"
+	opts := PaletteOptions{
+		IsLight:        true,
+		UseDPS:         true,
+		HonorPrimary:   "#1a5fb4",
+		HonorSecondary: "#c2185b",
+		HonorTertiary:  "#00695c",
+	}
+
+	plain := GeneratePalette(base, PaletteOptions{IsLight: true, UseDPS: true})
+	result := GeneratePalette(base, opts)
+	// Slots nudged for contrast follow the tinted background, so compare
+	// them against a plain palette on that same background
+	plainOnTint := GeneratePalette(base, PaletteOptions{IsLight: true, UseDPS: true, Background: result[0]})
+
+	// These accents already contrast with a light background, so they are
+	// used unchanged
+	for slot, want := range map[int]string{4: "#1a5fb4", 5: "#c2185b", 6: "#00695c"} {
+		if result[slot] != want {
+			t.Errorf("slot %d = %s, expected %s", slot, result[slot], want)
+		}
+	}
+
+	for _, slot := range []int{1, 2, 3, 7, 9, 10, 11, 15} {
+		if result[slot] != plainOnTint[slot] {
+			t.Errorf("slot %d changed from %s to %s", slot, plainOnTint[slot], result[slot])
+		}
+	}
+
+	if result[0] == plain[0] {
+		t.Errorf("background = %s, expected it to follow the accents", result[0])
+	}
+	for _, slot := range []int{8, 12, 13, 14} {
+		if result[slot] == plainOnTint[slot] {
+			t.Errorf("slot %d = %s, expected it to follow the accents", slot, result[slot])
+		}
+	}
+
+	// The tint keeps the background's lightness
+	if math.Abs(getLstar(result[0])-getLstar(plain[0])) > 1 {
+		t.Errorf("tinted background %s drifted in lightness from %s", result[0], plain[0])
+	}
+}
+
+func TestGeneratePaletteHonoredAccentContrast(t *testing.T) {
+	opts := PaletteOptions{IsLight: false, HonorPrimary: "#101030", HonorSecondary: "#200010"}
+	result := GeneratePalette("#625690", opts)
+
+	for _, slot := range []int{4, 5} {
+		if ratio := ContrastRatio(result[slot], result[0]); ratio < 4.5 {
+			t.Errorf("slot %d = %s has contrast %.2f against %s", slot, result[slot], ratio, result[0])
+		}
+	}
+}
+
+func TestGeneratePaletteHonoredAccentsKeepBackground(t *testing.T) {
+	opts := PaletteOptions{Background: "#0a0a0a", HonorSecondary: "#c2185b", HonorTertiary: "#00897b"}
+	result := GeneratePalette("#625690", opts)
+
+	if result[0] != "#0a0a0a" {
+		t.Errorf("background = %s, expected the explicit #0a0a0a", result[0])
+	}
+	if result[8] != "#5c6370" {
+		t.Errorf("bright black = %s, expected it untinted", result[8])
+	}
+}
+
+func TestAccentConsistentAcrossOutputs(t *testing.T) {
+	for _, opts := range []PaletteOptions{
+		{},
+		{IsLight: true},
+		{HonorPrimary: "#d0bcff"},
+		{IsLight: true, HonorPrimary: "#6750a4", UseDPS: true},
+	} {
+		colors := GeneratePalette("#625690", opts)
+		accent := AccentColor(colors)
+		if opts.HonorPrimary != "" && !opts.IsLight && accent != opts.HonorPrimary {
+			t.Errorf("%+v: accent = %s, expected the honored %s", opts, accent, opts.HonorPrimary)
+		}
+
+		if got := NamePalette(colors, opts.IsLight).Accent.Hex; got != accent {
+			t.Errorf("%+v: JSON accent = %s, want %s", opts, got, accent)
+		}
+		if gtk := GenerateGTKTheme(colors, opts.IsLight); !strings.Contains(gtk, "@define-color accent_bg_color "+accent+";") {
+			t.Errorf("%+v: GTK accent_bg_color is not %s", opts, accent)
+		}
+		if qt := GenerateQtTheme(colors, opts.IsLight); !strings.Contains(qt.ColorScheme, "#ff"+accent[1:]) {
+			t.Errorf("%+v: Qt highlight is not %s", opts, accent)
+		}
+
+		enriched, err := EnrichVSCodeTheme([]byte(`{"colors":{}}`), colors, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var theme struct {
+			Colors map[string]string `json:"colors"`
+		}
+		if err := json.Unmarshal(enriched, &theme); err != nil {
+			t.Fatal(err)
+		}
+		if got := theme.Colors["focusBorder"]; got != accent {
+			t.Errorf("%+v: VSCode focusBorder = %v, want %s", opts, got, accent)
+		}
+
+		for _, format := range []string{"niri", "hyprland"} {
+			out, err := RenderFormat(format, colors, opts.IsLight)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, accent[1:]) {
+				t.Errorf("%+v: %s output lacks the accent %s", opts, format, accent)
+			}
+		}
+	}
+}
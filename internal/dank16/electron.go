@@ -0,0 +1,130 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateDiscordTheme emits a Vencord/Vesktop theme. Save it as
+// dank16.theme.css in the themes folder (Vesktop: ~/.config/vesktop/themes/)
+// and enable it under Settings → Themes. Discord's own variables are
+// overridden for both its dark and light themes, so the client theme setting
+// does not matter.
+func GenerateDiscordTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.35)
+	sidebar := Mix(u.bg, "#000000", 0.1)
+	if isLight {
+		sidebar = Mix(u.bg, u.fg, 0.04)
+	}
+
+	var b strings.Builder
+	b.WriteString(`/**
+ * @name Dank16
+ * @author dank16
+ * @description Generated by dank16 from the DankMaterialShell palette
+ */
+`)
+	b.WriteString(":root,\n.theme-dark,\n.theme-light,\n.visual-refresh {\n")
+	writeCSSVars(&b, "  ", []cssVar{
+		{"--background-primary", u.bg},
+		{"--background-secondary", sidebar},
+		{"--background-secondary-alt", u.raised},
+		{"--background-tertiary", Mix(sidebar, "#000000", 0.1)},
+		{"--background-accent", u.accent},
+		{"--background-floating", u.raised},
+		{"--background-nested-floating", u.raised},
+		{"--background-mobile-primary", u.bg},
+		{"--background-mobile-secondary", sidebar},
+		{"--background-base-lower", sidebar},
+		{"--background-base-low", u.bg},
+		{"--background-surface-high", u.raised},
+		{"--background-modifier-hover", WithAlpha(u.fg, 0.06)},
+		{"--background-modifier-active", WithAlpha(u.fg, 0.1)},
+		{"--background-modifier-selected", WithAlpha(u.fg, 0.14)},
+		{"--background-modifier-accent", WithAlpha(u.border, 0.6)},
+		{"--channeltextarea-background", u.raised},
+		{"--input-background", u.view},
+		{"--modal-background", u.raised},
+		{"--modal-footer-background", sidebar},
+		{"--deprecated-card-bg", u.raised},
+		{"--deprecated-card-editable-bg", u.raised},
+		{"--text-normal", u.fg},
+		{"--text-default", u.fg},
+		{"--text-muted", muted},
+		{"--text-link", u.accentText},
+		{"--text-positive", colors[2]},
+		{"--text-warning", colors[3]},
+		{"--text-danger", colors[1]},
+		{"--header-primary", u.fg},
+		{"--header-secondary", muted},
+		{"--channels-default", muted},
+		{"--interactive-normal", muted},
+		{"--interactive-hover", u.fg},
+		{"--interactive-active", u.fg},
+		{"--interactive-muted", u.disabledFg},
+		{"--brand-experiment", u.accent},
+		{"--brand-500", u.accent},
+		{"--brand-560", Mix(u.accent, "#000000", 0.1)},
+		{"--mention-foreground", u.accentText},
+		{"--mention-background", WithAlpha(u.accent, 0.2)},
+		{"--status-positive", colors[2]},
+		{"--status-warning", colors[3]},
+		{"--status-danger", colors[1]},
+		{"--status-online", colors[2]},
+		{"--status-idle", colors[3]},
+		{"--status-dnd", colors[1]},
+		{"--status-offline", u.disabledFg},
+		{"--scrollbar-thin-thumb", u.border},
+		{"--scrollbar-thin-track", "transparent"},
+		{"--scrollbar-auto-thumb", u.border},
+		{"--scrollbar-auto-track", sidebar},
+		{"--border-subtle", u.border},
+		{"--border-faint", WithAlpha(u.border, 0.5)},
+	})
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// spicetifyColor is the bare RRGGBB form color.ini expects
+func spicetifyColor(hex string) string {
+	return strings.TrimPrefix(hex, "#")
+}
+
+// GenerateSpicetifyTheme emits a Spicetify color.ini with one Dank16 scheme.
+// Save it as ~/.config/spicetify/Themes/Dank16/color.ini and run
+// spicetify config current_theme Dank16 color_scheme Dank16.
+func GenerateSpicetifyTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	sidebar := Mix(u.bg, "#000000", 0.1)
+	if isLight {
+		sidebar = Mix(u.bg, u.fg, 0.04)
+	}
+
+	var b strings.Builder
+	b.WriteString("; Generated by dank16\n")
+	b.WriteString("[Dank16]\n")
+	for _, c := range []struct{ name, hex string }{
+		{"text", u.fg},
+		{"subtext", Mix(u.fg, u.bg, 0.35)},
+		{"main", u.bg},
+		{"main-elevated", u.raised},
+		{"highlight", Mix(u.raised, u.fg, 0.06)},
+		{"highlight-elevated", Mix(u.raised, u.fg, 0.1)},
+		{"sidebar", sidebar},
+		{"player", sidebar},
+		{"card", u.raised},
+		{"shadow", "#000000"},
+		{"selected-row", u.fg},
+		{"button", u.accent},
+		{"button-active", u.accentText},
+		{"button-disabled", u.disabledFg},
+		{"tab-active", u.raised},
+		{"notification", u.accent},
+		{"notification-error", colors[1]},
+		{"misc", u.border},
+	} {
+		fmt.Fprintf(&b, "%-18s = %s\n", c.name, spicetifyColor(c.hex))
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package dank16
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDiscordTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	css := GenerateDiscordTheme(colors, false)
+
+	for _, want := range []string{
+		" * @name Dank16\n",
+		"--background-primary: " + colors[0] + " !important;\n",
+		"--text-normal: " + colors[7] + " !important;\n",
+		"--brand-500: " + colors[4] + " !important;\n",
+		"--status-danger: " + colors[1] + " !important;\n",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Count(css, "{") != strings.Count(css, "}") {
+		t.Error("unbalanced braces")
+	}
+
+	value := regexp.MustCompile(`: (\S+) !important;`)
+	for _, m := range value.FindAllStringSubmatch(css, -1) {
+		if m[1] != "transparent" && !regexp.MustCompile(`^#[0-9a-f]{6}([0-9a-f]{2})?$`).MatchString(m[1]) {
+			t.Errorf("invalid color %q", m[1])
+		}
+	}
+}
+
+func TestGenerateSpicetifyTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: true})
+	ini := GenerateSpicetifyTheme(colors, true)
+
+	if !strings.Contains(ini, "[Dank16]\n") {
+		t.Error("missing [Dank16] section")
+	}
+
+	keys := make(map[string]bool)
+	line := regexp.MustCompile(`^([a-z-]+)\s+= ([0-9a-f]{6})$`)
+	for _, l := range strings.Split(strings.TrimSpace(ini), "\n") {
+		if strings.HasPrefix(l, ";") || strings.HasPrefix(l, "[") {
+			continue
+		}
+		m := line.FindStringSubmatch(l)
+		if m == nil {
+			t.Errorf("malformed line %q", l)
+			continue
+		}
+		keys[m[1]] = true
+	}
+	for _, key := range []string{"text", "subtext", "main", "sidebar", "player", "card", "button", "notification-error"} {
+		if !keys[key] {
+			t.Errorf("missing %s", key)
+		}
+	}
+	if !strings.Contains(ini, "main               = "+strings.TrimPrefix(colors[0], "#")+"\n") {
+		t.Error("main is not the background")
+	}
+}
@@ -0,0 +1,163 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EmacsThemeName is the doom-themes theme name; the output belongs in
+// ~/.config/doom/themes/doom-dank16-theme.el, or any directory on
+// custom-theme-load-path when doom-themes is installed
+const EmacsThemeName = "doom-dank16"
+
+// emacsCategories maps the face categories every doom theme defines, from
+// which doom-themes derives font-lock, tree-sitter and LSP faces
+var emacsCategories = []semanticScope{
+	{"builtin", "function", ""},
+	{"comments", "comment", ""},
+	{"doc-comments", "comment", ""},
+	{"constants", "variable.readonly", ""},
+	{"functions", "function", ""},
+	{"keywords", "keyword", ""},
+	{"methods", "method", ""},
+	{"operators", "operator", ""},
+	{"type", "type", ""},
+	{"strings", "string", ""},
+	{"variables", "variable", ""},
+	{"numbers", "number", ""},
+}
+
+var ansiNames = []string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"brightblack", "brightred", "brightgreen", "brightyellow", "brightblue", "brightmagenta", "brightcyan", "brightwhite",
+}
+
+// GenerateEmacsTheme emits a doom-themes theme. Doom palettes give each
+// color for graphical frames, 256-color and 16-color terminals; the
+// terminal columns use the palette slot the color came from, or the
+// closest ANSI color for mixed ones.
+func GenerateEmacsTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	semantic := semanticColors(colors)
+
+	spec := func(hex string) string {
+		return fmt.Sprintf("'(%q %q %q)", hex, hex, emacsANSIName(colors, hex))
+	}
+
+	type def struct {
+		name  string
+		value string
+	}
+	palette := []def{
+		{"bg", u.bg},
+		{"fg", u.fg},
+		{"bg-alt", u.raised},
+		{"fg-alt", Mix(u.fg, u.bg, 0.35)},
+	}
+	for i, t := range []float64{0, 0.04, 0.08, 0.15, 0.25, 0.4, 0.55, 0.7, 0.85} {
+		palette = append(palette, def{fmt.Sprintf("base%d", i), Mix(u.bg, u.fg, t)})
+	}
+	palette = append(palette,
+		def{"grey", Mix(u.bg, u.fg, 0.4)},
+		def{"red", colors[1]},
+		def{"orange", Mix(colors[1], colors[3], 0.5)},
+		def{"green", colors[2]},
+		def{"teal", colors[10]},
+		def{"yellow", colors[3]},
+		def{"blue", colors[4]},
+		def{"dark-blue", Mix(colors[4], u.bg, 0.3)},
+		def{"magenta", colors[5]},
+		def{"violet", colors[13]},
+		def{"cyan", colors[6]},
+		def{"dark-cyan", Mix(colors[6], u.bg, 0.3)},
+	)
+
+	// Categories that are not code refer to the palette by name
+	categories := []def{
+		{"highlight", spec(u.accentText)},
+		{"vertical-bar", "base2"},
+		{"selection", "dark-blue"},
+		{"region", spec(Mix(u.bg, u.accent, 0.3))},
+		{"error", "red"},
+		{"warning", "yellow"},
+		{"success", "green"},
+		{"vc-modified", "blue"},
+		{"vc-added", "green"},
+		{"vc-deleted", "red"},
+	}
+
+	background := "dark"
+	if isLight {
+		background = "light"
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, ";;; %s-theme.el --- Generated by dank16 -*- lexical-binding: t; no-byte-compile: t; -*-\n\n", EmacsThemeName)
+	result.WriteString("(require 'doom-themes)\n\n")
+	fmt.Fprintf(&result, "(def-doom-theme %s\n", EmacsThemeName)
+	fmt.Fprintf(&result, "  \"A %s theme generated by dank16.\"\n\n", background)
+
+	result.WriteString("  ;; name        gui       256       16\n")
+	for i, d := range palette {
+		open := "   "
+		if i == 0 {
+			open = "  ("
+		}
+		fmt.Fprintf(&result, "%s(%-12s %s)\n", open, d.name, spec(d.value))
+	}
+	result.WriteString("\n   ;; face categories\n")
+	for _, c := range categories {
+		fmt.Fprintf(&result, "   (%-12s %s)\n", c.name, c.value)
+	}
+	for i, s := range emacsCategories {
+		closing := ""
+		if i == len(emacsCategories)-1 {
+			closing = ")"
+		}
+		fmt.Fprintf(&result, "   (%-12s %s)%s\n", s.name, spec(semantic[s.semantic]), closing)
+	}
+
+	result.WriteString("\n  ;; face overrides\n")
+	faces := []string{
+		"(font-lock-comment-face :foreground comments :slant 'italic)",
+		"(font-lock-doc-face :foreground doc-comments :slant 'italic)",
+		"(line-number :foreground base5)",
+		"(line-number-current-line :foreground highlight :weight 'bold)",
+		"(hl-line :background bg-alt)",
+		"(mode-line :background bg-alt :foreground fg)",
+		"(mode-line-inactive :background bg :foreground fg-alt)",
+		"(cursor :background highlight)",
+	}
+	for i, face := range faces {
+		open := "   "
+		if i == 0 {
+			open = "  ("
+		}
+		closing := ""
+		if i == len(faces)-1 {
+			closing = ")"
+		}
+		fmt.Fprintf(&result, "%s%s%s\n", open, face, closing)
+	}
+
+	result.WriteString("\n  ;; variable overrides\n")
+	result.WriteString("  ())\n\n")
+	fmt.Fprintf(&result, ";;; %s-theme.el ends here\n", EmacsThemeName)
+	return result.String()
+}
+
+// emacsANSIName is the name of the palette slot hex came from, or of the
+// closest of the first sixteen slots for colors mixed from several
+func emacsANSIName(colors []string, hex string) string {
+	target := hexToColorful(hex)
+	best, bestDistance := 0, -1.0
+	for i, c := range colors[:16] {
+		if strings.EqualFold(c, hex) {
+			return ansiNames[i]
+		}
+		if d := hexToColorful(c).DistanceCIEDE2000(target); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return ansiNames[best]
+}
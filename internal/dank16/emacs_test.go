@@ -0,0 +1,68 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateEmacsTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	el := GenerateEmacsTheme(colors, false)
+	semantic := semanticColors(colors)
+
+	for _, want := range []string{
+		"(def-doom-theme doom-dank16\n",
+		"\"A dark theme generated by dank16.\"",
+		"((bg           '(\"" + colors[0] + "\" \"" + colors[0] + "\" \"black\"))\n",
+		"(red          '(\"" + colors[1] + "\" \"" + colors[1] + "\" \"red\"))\n",
+		"(strings      '(\"" + semantic["string"] + "\"",
+		"(keywords     '(\"" + semantic["keyword"] + "\"",
+		"(vc-added     green)\n",
+	} {
+		if !strings.Contains(el, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+
+	// def-doom-theme takes the name, docstring and three lists; unbalanced
+	// parentheses would make Emacs fail to load the file
+	depth := 0
+	for _, line := range strings.Split(el, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), ";") {
+			continue
+		}
+		inString := false
+		for _, r := range line {
+			switch {
+			case r == '"':
+				inString = !inString
+			case inString:
+			case r == '(':
+				depth++
+			case r == ')':
+				depth--
+			}
+		}
+		if depth < 0 {
+			t.Fatalf("unbalanced parentheses at %q", line)
+		}
+	}
+	if depth != 0 {
+		t.Errorf("%d unclosed parentheses", depth)
+	}
+
+	light := GenerateEmacsTheme(GeneratePalette("#625690", PaletteOptions{IsLight: true}), true)
+	if !strings.Contains(light, "\"A light theme generated by dank16.\"") {
+		t.Error("light variant should say so")
+	}
+}
+
+func TestEmacsANSIName(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{})
+	if got := emacsANSIName(colors, colors[9]); got != "brightred" {
+		t.Errorf("slot 9 = %s, want brightred", got)
+	}
+	if got := emacsANSIName(colors, Mix(colors[2], colors[0], 0.05)); got != "green" {
+		t.Errorf("near green = %s, want green", got)
+	}
+}
@@ -0,0 +1,241 @@
+package dank16
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Firefox loads these from the profile's chrome/ directory once they are
+// imported from userChrome.css and userContent.css
+const (
+	FirefoxChromeFile  = "dank16-chrome.css"
+	FirefoxContentFile = "dank16-content.css"
+)
+
+// FirefoxTheme holds the stylesheets that skin Firefox and Zen: the browser
+// chrome, the about: pages, and a static theme manifest for users who would
+// rather not enable legacy stylesheets
+type FirefoxTheme struct {
+	UserChrome  string
+	UserContent string
+	Manifest    string
+}
+
+// GenerateFirefoxTheme builds the Firefox and Zen theme from the palette,
+// using the same surfaces as the GTK theme. The stylesheets only set the
+// browser's own custom properties, so they compose with other userChrome
+// tweaks and survive most Firefox updates.
+func GenerateFirefoxTheme(colors []string, isLight bool) FirefoxTheme {
+	u := deriveUIColors(colors, isLight)
+	return FirefoxTheme{
+		UserChrome:  firefoxUserChrome(u, colors, isLight),
+		UserContent: firefoxUserContent(u, colors, isLight),
+		Manifest:    firefoxManifest(u, isLight),
+	}
+}
+
+type cssVar struct{ name, value string }
+
+func firefoxPalette(u uiColors, colors []string) []cssVar {
+	return []cssVar{
+		{"--dank-bg", u.bg},
+		{"--dank-surface", u.raised},
+		{"--dank-field", u.view},
+		{"--dank-fg", u.fg},
+		{"--dank-muted", Mix(u.fg, u.bg, 0.35)},
+		{"--dank-border", u.border},
+		{"--dank-accent", u.accent},
+		{"--dank-accent-text", u.accentText},
+		{"--dank-on-accent", u.onAccent},
+		{"--dank-red", colors[1]},
+		{"--dank-green", colors[2]},
+		{"--dank-yellow", colors[3]},
+	}
+}
+
+func writeCSSVars(b *strings.Builder, indent string, vars []cssVar) {
+	for _, v := range vars {
+		fmt.Fprintf(b, "%s%s: %s !important;\n", indent, v.name, v.value)
+	}
+}
+
+func colorScheme(isLight bool) string {
+	if isLight {
+		return "light"
+	}
+	return "dark"
+}
+
+func firefoxUserChrome(u uiColors, colors []string, isLight bool) string {
+	var b strings.Builder
+	b.WriteString("/* Generated by dank16 */\n")
+	b.WriteString(":root {\n")
+	fmt.Fprintf(&b, "  color-scheme: %s !important;\n", colorScheme(isLight))
+	writeCSSVars(&b, "  ", firefoxPalette(u, colors))
+	b.WriteString("\n  /* Firefox */\n")
+	writeCSSVars(&b, "  ", []cssVar{
+		{"--lwt-accent-color", "var(--dank-bg)"},
+		{"--lwt-accent-color-inactive", "var(--dank-bg)"},
+		{"--lwt-text-color", "var(--dank-fg)"},
+		{"--lwt-tab-text", "var(--dank-fg)"},
+		{"--lwt-tab-line-color", "var(--dank-accent)"},
+		{"--lwt-selected-tab-background-color", "var(--dank-surface)"},
+		{"--tab-selected-bgcolor", "var(--dank-surface)"},
+		{"--tab-selected-textcolor", "var(--dank-fg)"},
+		{"--toolbar-bgcolor", "var(--dank-surface)"},
+		{"--toolbar-color", "var(--dank-fg)"},
+		{"--toolbarbutton-icon-fill", "var(--dank-fg)"},
+		{"--toolbarbutton-icon-fill-attention", "var(--dank-accent)"},
+		{"--toolbar-field-background-color", "var(--dank-field)"},
+		{"--toolbar-field-color", "var(--dank-fg)"},
+		{"--toolbar-field-border-color", "var(--dank-border)"},
+		{"--toolbar-field-focus-background-color", "var(--dank-field)"},
+		{"--toolbar-field-focus-color", "var(--dank-fg)"},
+		{"--toolbar-field-focus-border-color", "var(--dank-accent)"},
+		{"--urlbar-box-bgcolor", "var(--dank-field)"},
+		{"--urlbar-box-text-color", "var(--dank-fg)"},
+		{"--arrowpanel-background", "var(--dank-surface)"},
+		{"--arrowpanel-color", "var(--dank-fg)"},
+		{"--arrowpanel-border-color", "var(--dank-border)"},
+		{"--panel-separator-color", "var(--dank-border)"},
+		{"--chrome-content-separator-color", "var(--dank-border)"},
+		{"--focus-outline-color", "var(--dank-accent)"},
+		{"--button-primary-bgcolor", "var(--dank-accent)"},
+		{"--button-primary-color", "var(--dank-on-accent)"},
+		{"--sidebar-background-color", "var(--dank-bg)"},
+		{"--sidebar-text-color", "var(--dank-fg)"},
+		{"--sidebar-border-color", "var(--dank-border)"},
+	})
+	b.WriteString("\n  /* Zen */\n")
+	writeCSSVars(&b, "  ", []cssVar{
+		{"--zen-primary-color", "var(--dank-accent)"},
+		{"--zen-colors-primary", "var(--dank-surface)"},
+		{"--zen-colors-secondary", "var(--dank-field)"},
+		{"--zen-colors-tertiary", "var(--dank-bg)"},
+		{"--zen-colors-border", "var(--dank-border)"},
+		{"--zen-main-browser-background", "var(--dank-bg)"},
+		{"--zen-themed-toolbar-bg", "var(--dank-bg)"},
+		{"--zen-dialog-background", "var(--dank-surface)"},
+	})
+	b.WriteString("}\n")
+	b.WriteString(`
+#urlbar[focused] > #urlbar-background {
+  outline-color: var(--dank-accent) !important;
+}
+
+.tab-background[selected] {
+  background-color: var(--dank-surface) !important;
+}
+
+.urlbarView-row[selected] {
+  background-color: var(--dank-accent) !important;
+  color: var(--dank-on-accent) !important;
+}
+`)
+	return b.String()
+}
+
+// firefoxUserContent themes Firefox's own about: pages. Web pages are left
+// alone; they pick up the color scheme from the chrome.
+func firefoxUserContent(u uiColors, colors []string, isLight bool) string {
+	var b strings.Builder
+	b.WriteString("/* Generated by dank16 */\n")
+	b.WriteString("@-moz-document url-prefix(\"about:\") {\n")
+	b.WriteString("  :root {\n")
+	fmt.Fprintf(&b, "    color-scheme: %s !important;\n", colorScheme(isLight))
+	writeCSSVars(&b, "    ", firefoxPalette(u, colors))
+	writeCSSVars(&b, "    ", []cssVar{
+		{"--in-content-page-background", "var(--dank-bg)"},
+		{"--in-content-page-color", "var(--dank-fg)"},
+		{"--in-content-text-color", "var(--dank-fg)"},
+		{"--in-content-deemphasized-text", "var(--dank-muted)"},
+		{"--in-content-box-background", "var(--dank-surface)"},
+		{"--in-content-box-border-color", "var(--dank-border)"},
+		{"--in-content-border-color", "var(--dank-border)"},
+		{"--in-content-item-hover", "var(--dank-field)"},
+		{"--in-content-item-selected", "var(--dank-accent)"},
+		{"--in-content-item-selected-text", "var(--dank-on-accent)"},
+		{"--in-content-primary-button-background", "var(--dank-accent)"},
+		{"--in-content-primary-button-text-color", "var(--dank-on-accent)"},
+		{"--in-content-focus-outline-color", "var(--dank-accent)"},
+		{"--in-content-link-color", "var(--dank-accent-text)"},
+		{"--in-content-error-text-color", "var(--dank-red)"},
+		{"--in-content-success-text-color", "var(--dank-green)"},
+		{"--in-content-warning-text-color", "var(--dank-yellow)"},
+		{"--newtab-background-color", "var(--dank-bg)"},
+		{"--newtab-background-color-secondary", "var(--dank-surface)"},
+		{"--newtab-text-primary-color", "var(--dank-fg)"},
+		{"--newtab-text-secondary-color", "var(--dank-muted)"},
+		{"--newtab-primary-action-background", "var(--dank-accent)"},
+	})
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
+
+// firefoxManifest is a static theme add-on. Zipped on its own, it installs
+// from about:addons without touching the profile's stylesheets.
+func firefoxManifest(u uiColors, isLight bool) string {
+	manifest := map[string]any{
+		"manifest_version": 2,
+		"name":             "Dank16",
+		"version":          "1.0",
+		"theme": map[string]any{
+			"colors": map[string]string{
+				"frame":                        u.bg,
+				"frame_inactive":               u.bg,
+				"tab_background_text":          u.fg,
+				"tab_selected":                 u.raised,
+				"tab_text":                     u.fg,
+				"tab_line":                     u.accent,
+				"toolbar":                      u.raised,
+				"toolbar_text":                 u.fg,
+				"toolbar_field":                u.view,
+				"toolbar_field_text":           u.fg,
+				"toolbar_field_border":         u.border,
+				"toolbar_field_focus":          u.view,
+				"toolbar_field_text_focus":     u.fg,
+				"toolbar_top_separator":        u.border,
+				"toolbar_bottom_separator":     u.border,
+				"icons":                        u.fg,
+				"icons_attention":              u.accent,
+				"popup":                        u.raised,
+				"popup_text":                   u.fg,
+				"popup_border":                 u.border,
+				"popup_highlight":              u.accent,
+				"popup_highlight_text":         u.onAccent,
+				"sidebar":                      u.bg,
+				"sidebar_text":                 u.fg,
+				"sidebar_border":               u.border,
+				"ntp_background":               u.bg,
+				"ntp_text":                     u.fg,
+				"button_background_hover":      Mix(u.raised, u.fg, 0.08),
+				"button_background_active":     Mix(u.raised, u.fg, 0.15),
+				"toolbar_field_highlight":      u.accent,
+				"toolbar_field_highlight_text": u.onAccent,
+			},
+			"properties": map[string]string{
+				"color_scheme":         colorScheme(isLight),
+				"content_color_scheme": colorScheme(isLight),
+			},
+		},
+	}
+	marshalled, _ := json.MarshalIndent(manifest, "", "  ")
+	return string(marshalled) + "\n"
+}
+
+// AddFirefoxImport returns a userChrome.css or userContent.css that imports
+// file, leaving the user's own rules in place. Firefox ignores @import after
+// any other rule, so the import goes first.
+func AddFirefoxImport(stylesheet, file string) string {
+	line := fmt.Sprintf("@import %q;", file)
+	for _, l := range strings.Split(stylesheet, "\n") {
+		if strings.TrimSpace(l) == line {
+			return stylesheet
+		}
+	}
+	if stylesheet == "" {
+		return line + "\n"
+	}
+	return line + "\n" + stylesheet
+}
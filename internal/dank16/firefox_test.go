@@ -0,0 +1,81 @@
+package dank16
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFirefoxTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	theme := GenerateFirefoxTheme(colors, false)
+
+	for _, want := range []string{
+		"color-scheme: dark !important;\n",
+		"--dank-bg: " + colors[0] + " !important;\n",
+		"--dank-accent: " + colors[4] + " !important;\n",
+		"--toolbar-bgcolor: var(--dank-surface) !important;\n",
+		"--zen-primary-color: var(--dank-accent) !important;\n",
+	} {
+		if !strings.Contains(theme.UserChrome, want) {
+			t.Errorf("userChrome missing %q", want)
+		}
+	}
+	if !strings.Contains(theme.UserContent, `@-moz-document url-prefix("about:")`) {
+		t.Error("userContent is not scoped to about: pages")
+	}
+
+	for name, css := range map[string]string{"userChrome": theme.UserChrome, "userContent": theme.UserContent} {
+		if strings.Count(css, "{") != strings.Count(css, "}") {
+			t.Errorf("%s: unbalanced braces", name)
+		}
+		// Every variable referenced must be defined
+		for _, part := range strings.Split(css, "var(")[1:] {
+			name := part[:strings.Index(part, ")")]
+			if !strings.Contains(css, name+": ") {
+				t.Errorf("%s is used but not defined", name)
+			}
+		}
+	}
+
+	var manifest struct {
+		Theme struct {
+			Colors     map[string]string `json:"colors"`
+			Properties map[string]string `json:"properties"`
+		} `json:"theme"`
+	}
+	if err := json.Unmarshal([]byte(theme.Manifest), &manifest); err != nil {
+		t.Fatalf("manifest is not JSON: %v", err)
+	}
+	if got := manifest.Theme.Colors["frame"]; got != colors[0] {
+		t.Errorf("frame = %s, want %s", got, colors[0])
+	}
+	for key, hex := range manifest.Theme.Colors {
+		if !paletteHexPattern.MatchString(hex) {
+			t.Errorf("%s: invalid color %q", key, hex)
+		}
+	}
+	if manifest.Theme.Properties["color_scheme"] != "dark" {
+		t.Errorf("color_scheme = %q, want dark", manifest.Theme.Properties["color_scheme"])
+	}
+
+	light := GenerateFirefoxTheme(GeneratePalette("#625690", PaletteOptions{IsLight: true}), true)
+	if !strings.Contains(light.UserChrome, "color-scheme: light !important;") {
+		t.Error("light theme does not set a light color scheme")
+	}
+}
+
+func TestAddFirefoxImport(t *testing.T) {
+	if got := AddFirefoxImport("", FirefoxChromeFile); got != "@import \"dank16-chrome.css\";\n" {
+		t.Errorf("empty stylesheet: got %q", got)
+	}
+
+	existing := "#nav-bar { height: 30px; }\n"
+	got := AddFirefoxImport(existing, FirefoxChromeFile)
+	if !strings.HasPrefix(got, "@import \"dank16-chrome.css\";\n") || !strings.HasSuffix(got, existing) {
+		t.Errorf("import not prepended: %q", got)
+	}
+	if again := AddFirefoxImport(got, FirefoxChromeFile); again != got {
+		t.Errorf("import added twice: %q", again)
+	}
+}
@@ -0,0 +1,180 @@
+package dank16
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Wide-gamut panels show plain hex values stretched to their own primaries
+// unless the color is tagged. The helpers below express palette colors in
+// Display P3 so color-managed renderers (browsers, GTK 4.16+) draw them as
+// intended, while the sRGB hex stays the fallback everywhere else.
+
+// P3 is a gamma-encoded Display P3 color with channels in 0-1
+type P3 struct {
+	R, G, B float64
+}
+
+// CSS formats the color for color(display-p3 ...) declarations
+func (c P3) CSS() string {
+	return fmt.Sprintf("color(display-p3 %s %s %s)", p3Channel(c.R), p3Channel(c.G), p3Channel(c.B))
+}
+
+func p3Channel(c float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.4f", c), "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || s == "-" {
+		return "0"
+	}
+	return s
+}
+
+// oklch is OKLab in polar form: lightness, chroma and hue in radians
+type oklch struct {
+	L, C, H float64
+}
+
+func linearSRGBToOklch(rgb RGB) oklch {
+	l := math.Cbrt(0.4122214708*rgb.R + 0.5363325363*rgb.G + 0.0514459929*rgb.B)
+	m := math.Cbrt(0.2119034982*rgb.R + 0.6806995451*rgb.G + 0.1073969566*rgb.B)
+	s := math.Cbrt(0.0883024619*rgb.R + 0.2817188376*rgb.G + 0.6299787005*rgb.B)
+
+	L := 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	a := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	b := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+	return oklch{L: L, C: math.Hypot(a, b), H: math.Atan2(b, a)}
+}
+
+// linearSRGB may return channels outside 0-1 for colors beyond sRGB
+func (c oklch) linearSRGB() RGB {
+	a, b := c.C*math.Cos(c.H), c.C*math.Sin(c.H)
+	l := math.Pow(c.L+0.3963377774*a+0.2158037573*b, 3)
+	m := math.Pow(c.L-0.1055613458*a-0.0638541728*b, 3)
+	s := math.Pow(c.L-0.0894841775*a-1.2914855480*b, 3)
+	return RGB{
+		R: 4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		G: -1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		B: -0.0041960863*l - 0.7034186147*m + 1.7076147010*s,
+	}
+}
+
+func linearSRGBToLinearP3(rgb RGB) RGB {
+	return RGB{
+		R: 0.8224621*rgb.R + 0.1775380*rgb.G,
+		G: 0.0331941*rgb.R + 0.9668058*rgb.G,
+		B: 0.0170827*rgb.R + 0.0723974*rgb.G + 0.9105199*rgb.B,
+	}
+}
+
+// inGamut allows a little slack so colors that round-trip from hex count as
+// inside
+func inGamut(rgb RGB) bool {
+	const eps = 1e-4
+	for _, c := range []float64{rgb.R, rgb.G, rgb.B} {
+		if c < -eps || c > 1+eps {
+			return false
+		}
+	}
+	return true
+}
+
+// gamutMap lowers chroma at constant lightness and hue until space accepts
+// the color. Clipping each channel instead would shift the hue and flatten
+// gradients, which is what makes naive wide-gamut themes look garish.
+func gamutMap(c oklch, space func(oklch) RGB) RGB {
+	if c.L >= 1 {
+		return space(oklch{L: 1})
+	}
+	if c.L <= 0 {
+		return space(oklch{})
+	}
+	if rgb := space(c); inGamut(rgb) {
+		return rgb
+	}
+
+	lo, hi := 0.0, c.C
+	for hi-lo > 1e-5 {
+		mid := (lo + hi) / 2
+		if inGamut(space(oklch{L: c.L, C: mid, H: c.H})) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return space(oklch{L: c.L, C: lo, H: c.H})
+}
+
+func clampRGB(rgb RGB) RGB {
+	clamp := func(c float64) float64 { return math.Max(0, math.Min(1, c)) }
+	return RGB{R: clamp(rgb.R), G: clamp(rgb.G), B: clamp(rgb.B)}
+}
+
+// DisplayP3 converts an sRGB hex color to Display P3. sRGB lies inside P3,
+// so the color looks the same; only its coordinates change.
+func DisplayP3(hex string) P3 {
+	p3 := clampRGB(linearSRGBToLinearP3(toLinear(hex)))
+	// Display P3 shares the sRGB transfer function
+	return P3{R: linearToSRGB(p3.R), G: linearToSRGB(p3.G), B: linearToSRGB(p3.B)}
+}
+
+// WideGamut scales the chroma of hex by boost in OKLCH and maps the result
+// into Display P3. A boost of 1 keeps the color as is; values above 1 use
+// the extra saturation wide-gamut panels can show. Neutrals stay neutral.
+func WideGamut(hex string, boost float64) P3 {
+	if boost <= 1 {
+		return DisplayP3(hex)
+	}
+
+	c := linearSRGBToOklch(toLinear(hex))
+	c.C *= boost
+
+	p3 := clampRGB(gamutMap(c, func(c oklch) RGB { return linearSRGBToLinearP3(c.linearSRGB()) }))
+	return P3{R: linearToSRGB(p3.R), G: linearToSRGB(p3.G), B: linearToSRGB(p3.B)}
+}
+
+// GenerateP3CSS emits the palette as CSS custom properties: the unchanged
+// sRGB hex in :root and Display P3 overrides for screens that report the P3
+// gamut. boost is passed to WideGamut for the chromatic slots.
+func GenerateP3CSS(colors []string, isLight bool, boost float64) string {
+	u := deriveUIColors(colors, isLight)
+
+	type entry struct {
+		name string
+		hex  string
+		// neutral colors are never boosted, so grey stays grey
+		neutral bool
+	}
+	entries := []entry{
+		{"--dank-bg", u.bg, true},
+		{"--dank-surface", u.raised, true},
+		{"--dank-fg", u.fg, true},
+		{"--dank-border", u.border, true},
+		{"--dank-accent", u.accent, false},
+		{"--dank-on-accent", u.onAccent, true},
+	}
+	for i, c := range colors {
+		neutral := i == 0 || i == 7 || i == 8 || i == 15
+		entries = append(entries, entry{fmt.Sprintf("--dank-color%d", i), c, neutral})
+	}
+
+	var srgb, p3 strings.Builder
+	for _, e := range entries {
+		b := boost
+		if e.neutral {
+			b = 1
+		}
+		fmt.Fprintf(&srgb, "  %s: %s;\n", e.name, e.hex)
+		fmt.Fprintf(&p3, "    %s: %s;\n", e.name, WideGamut(e.hex, b).CSS())
+	}
+
+	var b strings.Builder
+	b.WriteString("/* Generated by dank16 */\n")
+	b.WriteString(":root {\n")
+	b.WriteString(srgb.String())
+	b.WriteString("}\n\n")
+	b.WriteString("@media (color-gamut: p3) {\n  :root {\n")
+	b.WriteString(p3.String())
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
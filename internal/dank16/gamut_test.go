@@ -0,0 +1,104 @@
+package dank16
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDisplayP3(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want P3
+	}{
+		{"#ffffff", P3{1, 1, 1}},
+		{"#000000", P3{0, 0, 0}},
+		// Reference values from CSS Color 4's conversion code
+		{"#ff0000", P3{0.9175, 0.2003, 0.1386}},
+		{"#00ff00", P3{0.4584, 0.9853, 0.2983}},
+	}
+
+	for _, tt := range tests {
+		got := DisplayP3(tt.hex)
+		if math.Abs(got.R-tt.want.R) > 0.001 || math.Abs(got.G-tt.want.G) > 0.001 || math.Abs(got.B-tt.want.B) > 0.001 {
+			t.Errorf("DisplayP3(%s) = %+v, want %+v", tt.hex, got, tt.want)
+		}
+	}
+}
+
+func TestP3CSS(t *testing.T) {
+	if got := (P3{1, 0.5, 0}).CSS(); got != "color(display-p3 1 0.5 0)" {
+		t.Errorf("CSS() = %q", got)
+	}
+	if got := (P3{0.91751, 0.2, 0.13862}).CSS(); got != "color(display-p3 0.9175 0.2 0.1386)" {
+		t.Errorf("CSS() = %q", got)
+	}
+}
+
+func TestOklchRoundTrip(t *testing.T) {
+	for _, hex := range []string{"#625690", "#ff0000", "#1a1b26", "#e0e0e0"} {
+		c := linearSRGBToOklch(toLinear(hex))
+		if got := fromLinear(c.linearSRGB()); got != hex {
+			t.Errorf("round trip of %s gave %s", hex, got)
+		}
+	}
+}
+
+func TestGamutMap(t *testing.T) {
+	// Doubling the chroma of a red leaves sRGB; mapping it back must hold
+	// lightness and hue and only give up chroma
+	orig := linearSRGBToOklch(toLinear("#d04040"))
+	boosted := orig
+	boosted.C *= 2
+
+	mapped := linearSRGBToOklch(gamutMap(boosted, oklch.linearSRGB))
+	if math.Abs(mapped.L-orig.L) > 0.001 || math.Abs(mapped.H-orig.H) > 0.001 {
+		t.Errorf("mapping drifted: %+v -> %+v", boosted, mapped)
+	}
+	if mapped.C <= orig.C || mapped.C >= boosted.C {
+		t.Errorf("chroma %.3f should lie between %.3f and %.3f", mapped.C, orig.C, boosted.C)
+	}
+}
+
+func TestWideGamut(t *testing.T) {
+	if got := WideGamut("#625690", 1); got != DisplayP3("#625690") {
+		t.Errorf("boost 1 changed the color: %+v", got)
+	}
+
+	p3 := WideGamut("#d04040", 2)
+	for _, c := range []float64{p3.R, p3.G, p3.B} {
+		if c < 0 || c > 1 {
+			t.Fatalf("boosted color out of range: %+v", p3)
+		}
+	}
+	if p3 == DisplayP3("#d04040") {
+		t.Error("boosted color should use chroma sRGB cannot show")
+	}
+	if WideGamut("#808080", 2).CSS() != DisplayP3("#808080").CSS() {
+		t.Error("grey should stay grey")
+	}
+}
+
+func TestGenerateP3CSS(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	css := GenerateP3CSS(colors, false, 1.2)
+
+	for _, want := range []string{
+		"  --dank-bg: " + colors[0] + ";\n",
+		"  --dank-color0: " + colors[0] + ";\n",
+		"@media (color-gamut: p3) {\n  :root {\n",
+		"    --dank-bg: " + DisplayP3(colors[0]).CSS() + ";\n",
+		"    --dank-color15: " + DisplayP3(colors[15]).CSS() + ";\n",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("missing %q in:\n%s", want, css)
+		}
+	}
+
+	if strings.Count(css, "{") != strings.Count(css, "}") {
+		t.Error("unbalanced braces")
+	}
+	if strings.Count(css, "color(display-p3 ") != 22 {
+		t.Errorf("expected 22 P3 declarations, got %d", strings.Count(css, "color(display-p3 "))
+	}
+}
@@ -0,0 +1,118 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateGTKTheme emits a gtk.css for ~/.config/gtk-3.0 and gtk-4.0. It
+// defines both the libadwaita named colors (GTK4, adw-gtk3) and the legacy
+// theme_* names older GTK3 themes read, then skins headerbars, views and
+// selections directly for themes that ignore named colors.
+func GenerateGTKTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	named := []struct {
+		name  string
+		value string
+	}{
+		{"accent_color", u.accentText},
+		{"accent_bg_color", u.accent},
+		{"accent_fg_color", u.onAccent},
+		{"destructive_color", colors[1]},
+		{"destructive_bg_color", colors[1]},
+		{"destructive_fg_color", onColor(colors[1])},
+		{"success_color", colors[2]},
+		{"success_bg_color", colors[2]},
+		{"success_fg_color", onColor(colors[2])},
+		{"warning_color", colors[3]},
+		{"warning_bg_color", colors[3]},
+		{"warning_fg_color", onColor(colors[3])},
+		{"error_color", colors[1]},
+		{"error_bg_color", colors[1]},
+		{"error_fg_color", onColor(colors[1])},
+		{"window_bg_color", u.bg},
+		{"window_fg_color", u.fg},
+		{"view_bg_color", u.view},
+		{"view_fg_color", u.fg},
+		{"headerbar_bg_color", u.raised},
+		{"headerbar_fg_color", u.fg},
+		{"headerbar_backdrop_color", u.bg},
+		{"sidebar_bg_color", u.raised},
+		{"sidebar_fg_color", u.fg},
+		{"card_bg_color", u.card},
+		{"card_fg_color", u.fg},
+		{"dialog_bg_color", u.raised},
+		{"dialog_fg_color", u.fg},
+		{"popover_bg_color", u.raised},
+		{"popover_fg_color", u.fg},
+
+		{"theme_bg_color", u.bg},
+		{"theme_fg_color", u.fg},
+		{"theme_base_color", u.view},
+		{"theme_text_color", u.fg},
+		{"theme_selected_bg_color", u.accent},
+		{"theme_selected_fg_color", u.onAccent},
+		{"insensitive_fg_color", u.disabledFg},
+		{"borders", u.border},
+	}
+
+	var result strings.Builder
+	result.WriteString("/* Generated by dms dank16 */\n\n")
+	for _, c := range named {
+		fmt.Fprintf(&result, "@define-color %s %s;\n", c.name, c.value)
+	}
+
+	result.WriteString(`
+window, .background {
+  background-color: @window_bg_color;
+  color: @window_fg_color;
+}
+
+headerbar, .titlebar {
+  background-color: @headerbar_bg_color;
+  color: @headerbar_fg_color;
+  border-color: @borders;
+}
+
+headerbar:backdrop, .titlebar:backdrop {
+  background-color: @headerbar_backdrop_color;
+}
+
+.view, textview text, treeview, iconview {
+  background-color: @view_bg_color;
+  color: @view_fg_color;
+}
+
+selection, *:selected, .view:selected, row:selected {
+  background-color: @accent_bg_color;
+  color: @accent_fg_color;
+}
+
+button.suggested-action {
+  background-color: @accent_bg_color;
+  color: @accent_fg_color;
+}
+
+button.destructive-action {
+  background-color: @destructive_bg_color;
+  color: @destructive_fg_color;
+}
+
+link, *:link {
+  color: @accent_color;
+}
+`)
+
+	// GTK CSS has no eight-digit hex, so translucent colors are rgba()
+	fmt.Fprintf(&result, `
+scrollbar slider {
+  background-color: %s;
+}
+
+scrollbar slider:hover {
+  background-color: %s;
+}
+`, NewRGBA(u.fg, 0.35).Encode(AlphaCSS, ""), NewRGBA(u.fg, 0.55).Encode(AlphaCSS, ""))
+	return result.String()
+}
@@ -0,0 +1,43 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateGTKTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	css := GenerateGTKTheme(colors, false)
+
+	for _, want := range []string{
+		"@define-color window_bg_color " + colors[0] + ";\n",
+		"@define-color window_fg_color " + colors[7] + ";\n",
+		"@define-color accent_bg_color " + colors[4] + ";\n",
+		"@define-color accent_color " + colors[12] + ";\n",
+		"@define-color theme_selected_bg_color " + colors[4] + ";\n",
+		"headerbar, .titlebar {",
+		"selection, *:selected",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("missing %q in:\n%s", want, css)
+		}
+	}
+
+	if strings.Count(css, "{") != strings.Count(css, "}") {
+		t.Error("unbalanced braces")
+	}
+
+	light := GenerateGTKTheme(GeneratePalette("#625690", PaletteOptions{IsLight: true}), true)
+	if !strings.Contains(light, "@define-color window_bg_color #f8f8f8;\n") {
+		t.Errorf("unexpected light window background:\n%s", light)
+	}
+}
+
+func TestOnColor(t *testing.T) {
+	if got := onColor("#ffff00"); got != "#000000" {
+		t.Errorf("onColor(yellow) = %s, want black", got)
+	}
+	if got := onColor("#36247a"); got != "#ffffff" {
+		t.Errorf("onColor(dark purple) = %s, want white", got)
+	}
+}
@@ -0,0 +1,257 @@
+package dank16
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+const (
+	// maxSamples bounds how many pixels are clustered; wallpapers are
+	// sampled on an even grid down to roughly this many
+	maxSamples = 128 * 128
+	clusters   = 8
+	kmeansRuns = 20
+	// minAccentChroma (go-colorful Lab units) is the least colorful a
+	// cluster can be and still count as an accent
+	minAccentChroma = 0.08
+)
+
+// Swatch is one color cluster of an image. Weight is the share of sampled
+// pixels it covers.
+type Swatch struct {
+	Color  string  `json:"color"`
+	Weight float64 `json:"weight"`
+	Chroma float64 `json:"chroma"`
+}
+
+// ImagePalette is what ExtractPaletteFromImage found: the clusters, heaviest
+// first, and the one picked as the accent
+type ImagePalette struct {
+	Accent   string   `json:"accent"`
+	Swatches []Swatch `json:"swatches"`
+}
+
+// Palette generates the 16-color palette seeded with the image's accent
+func (p ImagePalette) Palette(opts PaletteOptions) []string {
+	return GeneratePalette(p.Accent, opts)
+}
+
+// ExtractPaletteFromImage clusters the colors of a PNG, JPEG, GIF or WebP
+// image with k-means in Lab space and picks a dominant accent. WebP is
+// converted with dwebp or ImageMagick, as the standard library cannot read
+// it.
+func ExtractPaletteFromImage(path string) (ImagePalette, error) {
+	img, err := decodeImage(path)
+	if err != nil {
+		return ImagePalette{}, err
+	}
+
+	palette, err := PaletteFromImage(img)
+	if err != nil {
+		return ImagePalette{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return palette, nil
+}
+
+// PaletteFromImage is ExtractPaletteFromImage for an image already in
+// memory, such as a screen capture
+func PaletteFromImage(img image.Image) (ImagePalette, error) {
+	pixels := samplePixels(img)
+	if len(pixels) == 0 {
+		return ImagePalette{}, fmt.Errorf("image has no opaque pixels")
+	}
+
+	swatches := kmeans(pixels, clusters)
+	return ImagePalette{Accent: pickAccent(swatches), Swatches: swatches}, nil
+}
+
+func decodeImage(path string) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if isWebP(data) {
+		if data, err = convertWebP(path); err != nil {
+			return nil, err
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// convertWebP returns the image re-encoded as PNG
+func convertWebP(path string) ([]byte, error) {
+	converters := [][]string{
+		{"dwebp", "-quiet", path, "-o", "-"},
+		{"magick", path, "png:-"},
+		{"convert", path, "png:-"},
+	}
+	for _, c := range converters {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(c[0], c[1:]...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s failed to convert %s: %w", c[0], path, err)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("reading WebP needs dwebp (libwebp) or ImageMagick")
+}
+
+// samplePixels returns the mostly opaque pixels on an even grid, as Lab
+func samplePixels(img image.Image) []colorful.Color {
+	bounds := img.Bounds()
+	step := int(math.Max(1, math.Ceil(math.Sqrt(float64(bounds.Dx()*bounds.Dy())/maxSamples))))
+
+	var pixels []colorful.Color
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			// RGBA is premultiplied
+			c := colorful.Color{R: float64(r) / float64(a), G: float64(g) / float64(a), B: float64(b) / float64(a)}
+			l, aa, bb := c.Lab()
+			pixels = append(pixels, colorful.Color{R: l, G: aa, B: bb})
+		}
+	}
+	return pixels
+}
+
+func labDistance(p, q colorful.Color) float64 {
+	dl, da, db := p.R-q.R, p.G-q.G, p.B-q.B
+	return dl*dl + da*da + db*db
+}
+
+// kmeans clusters Lab pixels (stored in R, G, B) and returns the clusters
+// as swatches, heaviest first. Centers are seeded with k-means++ from a
+// fixed seed so the same image always gives the same palette.
+func kmeans(pixels []colorful.Color, k int) []Swatch {
+	rng := rand.New(rand.NewSource(1))
+	k = min(k, len(pixels))
+
+	centers := []colorful.Color{pixels[rng.Intn(len(pixels))]}
+	dist := make([]float64, len(pixels))
+	for len(centers) < k {
+		total := 0.0
+		for i, p := range pixels {
+			dist[i] = nearestDistance(p, centers)
+			total += dist[i]
+		}
+		if total == 0 {
+			break
+		}
+		target := rng.Float64() * total
+		next := len(pixels) - 1
+		for i, d := range dist {
+			if target -= d; target <= 0 {
+				next = i
+				break
+			}
+		}
+		centers = append(centers, pixels[next])
+	}
+
+	assign := make([]int, len(pixels))
+	counts := make([]int, len(centers))
+	for run := 0; run < kmeansRuns; run++ {
+		changed := false
+		for i, p := range pixels {
+			best := nearest(p, centers)
+			if run == 0 || best != assign[i] {
+				changed = true
+			}
+			assign[i] = best
+		}
+
+		sums := make([]colorful.Color, len(centers))
+		counts = make([]int, len(centers))
+		for i, p := range pixels {
+			c := assign[i]
+			sums[c].R += p.R
+			sums[c].G += p.G
+			sums[c].B += p.B
+			counts[c]++
+		}
+		for c := range centers {
+			if counts[c] > 0 {
+				n := float64(counts[c])
+				centers[c] = colorful.Color{R: sums[c].R / n, G: sums[c].G / n, B: sums[c].B / n}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	var swatches []Swatch
+	for c, center := range centers {
+		if counts[c] == 0 {
+			continue
+		}
+		swatches = append(swatches, Swatch{
+			Color:  labToHex(center.R*100, center.G, center.B),
+			Weight: float64(counts[c]) / float64(len(pixels)),
+			Chroma: math.Hypot(center.G, center.B),
+		})
+	}
+	sort.SliceStable(swatches, func(i, j int) bool { return swatches[i].Weight > swatches[j].Weight })
+	return swatches
+}
+
+func nearest(p colorful.Color, centers []colorful.Color) int {
+	best, bestDist := 0, math.Inf(1)
+	for i, c := range centers {
+		if d := labDistance(p, c); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+func nearestDistance(p colorful.Color, centers []colorful.Color) float64 {
+	return labDistance(p, centers[nearest(p, centers)])
+}
+
+// pickAccent favors clusters that are both colorful and large, so a small
+// vivid detail can win over a big dull area but not over a big colorful one.
+// Near-black and near-white clusters are skipped. Without any colorful
+// cluster the heaviest one is used.
+func pickAccent(swatches []Swatch) string {
+	best, bestScore := "", 0.0
+	for _, s := range swatches {
+		L := getLstar(s.Color)
+		if s.Chroma < minAccentChroma || L < 15 || L > 95 {
+			continue
+		}
+		if score := s.Chroma * math.Sqrt(s.Weight); score > bestScore {
+			best, bestScore = s.Color, score
+		}
+	}
+	if best == "" {
+		return swatches[0].Color
+	}
+	return best
+}
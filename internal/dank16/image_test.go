@@ -0,0 +1,93 @@
+package dank16
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestImage saves a 100x100 image whose left part, split at x, is one
+// color and the rest another
+func writeTestImage(t *testing.T, name string, left, right color.Color, split int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			if x < split {
+				img.Set(x, y, left)
+			} else {
+				img.Set(x, y, right)
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if filepath.Ext(name) == ".jpg" {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 95})
+	} else {
+		err = png.Encode(f, img)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractPaletteFromImage(t *testing.T) {
+	dark := color.RGBA{0x20, 0x22, 0x24, 0xff}
+	orange := color.RGBA{0xe0, 0x70, 0x20, 0xff}
+
+	for _, name := range []string{"wall.png", "wall.jpg"} {
+		path := writeTestImage(t, name, dark, orange, 75)
+		p, err := ExtractPaletteFromImage(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(p.Swatches) == 0 || getLstar(p.Swatches[0].Color) > 20 {
+			t.Errorf("%s: heaviest swatch should be the dark area, got %+v", name, p.Swatches)
+		}
+		if rgb := HexToRGB(p.Accent); rgb.R < 0.8 || rgb.R <= rgb.G || rgb.G <= rgb.B {
+			t.Errorf("%s: accent %s is not the orange area", name, p.Accent)
+		}
+
+		colors := p.Palette(PaletteOptions{UseDPS: true})
+		if len(colors) != 16 {
+			t.Errorf("%s: palette has %d colors", name, len(colors))
+		}
+	}
+}
+
+func TestExtractPaletteFromImage_Grey(t *testing.T) {
+	path := writeTestImage(t, "grey.png", color.Gray{0x40}, color.Gray{0xa0}, 60)
+	p, err := ExtractPaletteFromImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Accent != p.Swatches[0].Color {
+		t.Errorf("grey image should fall back to the heaviest swatch, got %s", p.Accent)
+	}
+}
+
+func TestExtractPaletteFromImage_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExtractPaletteFromImage(path); err == nil {
+		t.Error("expected an error for a text file")
+	}
+	if _, err := ExtractPaletteFromImage(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,73 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// honorPrimaryGain is how much closer, in PaletteDistance, honoring the
+// palette's blue must bring the regenerated palette before InferOptions
+// reports it. Honoring it always reproduces the blue slots, so without a
+// margin it would win on palettes that never had a honored primary.
+const honorPrimaryGain = 0.5
+
+// InferredOptions are the seed and options InferOptions estimated, with
+// the hue of the seed in degrees and the PaletteDistance between the
+// palette they generate and the one they were inferred from
+type InferredOptions struct {
+	Seed     string         `json:"seed"`
+	Hue      float64        `json:"hue"`
+	Options  PaletteOptions `json:"options"`
+	Distance float64        `json:"distance"`
+}
+
+// InferOptions estimates what an existing 16-color palette was generated
+// from, so a palette saved before an output format existed can be run
+// through GeneratePalette again and exported with it. The mode comes from
+// the background's lightness and the seed from the cyan slot, which holds
+// the seed adjusted for contrast. The contrast algorithm, and whether the
+// blue slot was a honored primary, are whichever combination regenerates
+// the closest palette. Background is only set when slot 0 is not the
+// default for the mode. Honored secondary and tertiary accents are not
+// inferred.
+func InferOptions(colors []string) (InferredOptions, error) {
+	if len(colors) != 16 {
+		return InferredOptions{}, fmt.Errorf("palette has %d colors, want 16", len(colors))
+	}
+	palette := make([]string, 16)
+	for i, c := range colors {
+		hex, err := NormalizeHex(c)
+		if err != nil {
+			return InferredOptions{}, fmt.Errorf("slot %d: %w", i, err)
+		}
+		palette[i] = hex
+	}
+
+	base := PaletteOptions{IsLight: getLstar(palette[0]) > 50}
+	defaultBg := "#1a1a1a"
+	if base.IsLight {
+		defaultBg = "#f8f8f8"
+	}
+	if !strings.EqualFold(palette[0], defaultBg) {
+		base.Background = palette[0]
+	}
+
+	seed := palette[6]
+	best := InferredOptions{Seed: seed, Hue: RGBToHSV(HexToRGB(seed)).H * 360, Distance: -1}
+	for _, algo := range []struct{ dps, apca bool }{{true, false}, {false, true}, {false, false}} {
+		opts := base
+		opts.UseDPS, opts.UseAPCA = algo.dps, algo.apca
+
+		distance := PaletteDistance(palette, GeneratePalette(seed, opts))
+		honored := opts
+		honored.HonorPrimary = palette[AccentSlot]
+		if d := PaletteDistance(palette, GeneratePalette(seed, honored)); d < distance-honorPrimaryGain {
+			opts, distance = honored, d
+		}
+
+		if best.Distance < 0 || distance < best.Distance {
+			best.Options, best.Distance = opts, distance
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,92 @@
+package dank16
+
+import "testing"
+
+func TestInferOptionsRecoversGeneratedPalette(t *testing.T) {
+	cases := []struct {
+		seed string
+		opts PaletteOptions
+	}{
+		{"#625690", PaletteOptions{UseDPS: true}},
+		{"#625690", PaletteOptions{IsLight: true, UseDPS: true}},
+		{"#2e7de9", PaletteOptions{UseAPCA: true}},
+		{"#e06c75", PaletteOptions{}},
+		{"#98c379", PaletteOptions{UseDPS: true, HonorPrimary: "#e5c07b"}},
+		{"#625690", PaletteOptions{UseDPS: true, Background: "#101020"}},
+	}
+
+	for _, c := range cases {
+		palette := GeneratePalette(c.seed, c.opts)
+		inferred, err := InferOptions(palette)
+		if err != nil {
+			t.Fatalf("%s %+v: %v", c.seed, c.opts, err)
+		}
+
+		got := inferred.Options
+		if got.IsLight != c.opts.IsLight {
+			t.Errorf("%s %+v: IsLight = %v", c.seed, c.opts, got.IsLight)
+		}
+		if got.UseDPS != c.opts.UseDPS || got.UseAPCA != c.opts.UseAPCA {
+			t.Errorf("%s %+v: UseDPS = %v, UseAPCA = %v", c.seed, c.opts, got.UseDPS, got.UseAPCA)
+		}
+		if got.Background != c.opts.Background {
+			t.Errorf("%s %+v: Background = %q", c.seed, c.opts, got.Background)
+		}
+		if (got.HonorPrimary != "") != (c.opts.HonorPrimary != "") {
+			t.Errorf("%s %+v: HonorPrimary = %q", c.seed, c.opts, got.HonorPrimary)
+		}
+		if inferred.Distance > 1.5 {
+			t.Errorf("%s %+v: distance %.2f, want <= 1.5", c.seed, c.opts, inferred.Distance)
+		}
+		if d := PaletteDistance(palette, GeneratePalette(inferred.Seed, got)); d != inferred.Distance {
+			t.Errorf("%s %+v: regenerated distance %.2f, reported %.2f", c.seed, c.opts, d, inferred.Distance)
+		}
+	}
+}
+
+func TestInferOptionsHue(t *testing.T) {
+	inferred, err := InferOptions(GeneratePalette("#2e7de9", PaletteOptions{UseDPS: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inferred.Hue < 205 || inferred.Hue > 225 {
+		t.Errorf("hue = %.1f, want about 215", inferred.Hue)
+	}
+}
+
+func TestInferOptionsForeignPalette(t *testing.T) {
+	for _, name := range []string{"Tokyo Night", "Tokyo Night Day"} {
+		var scheme Scheme
+		for _, s := range Schemes {
+			if s.Name == name {
+				scheme = s
+			}
+		}
+		if scheme.Name == "" {
+			t.Fatalf("scheme %q not bundled", name)
+		}
+
+		inferred, err := InferOptions(scheme.Colors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inferred.Options.IsLight != scheme.IsLight {
+			t.Errorf("%s: IsLight = %v", name, inferred.Options.IsLight)
+		}
+		if inferred.Options.Background != scheme.Colors[0] {
+			t.Errorf("%s: Background = %q, want %q", name, inferred.Options.Background, scheme.Colors[0])
+		}
+	}
+}
+
+func TestInferOptionsRejectsBadPalettes(t *testing.T) {
+	if _, err := InferOptions(make([]string, 15)); err == nil {
+		t.Error("expected an error for 15 colors")
+	}
+
+	palette := GeneratePalette("#625690", PaletteOptions{UseDPS: true})
+	palette[3] = "not a color"
+	if _, err := InferOptions(palette); err == nil {
+		t.Error("expected an error for an invalid color")
+	}
+}
@@ -0,0 +1,194 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JetBrainsSchemeName is the editor color scheme name; import the output
+// through Settings > Editor > Color Scheme > Import Scheme
+const JetBrainsSchemeName = "Dank16"
+
+// jetbrainsAttributes maps the IDEs' language-independent text attribute
+// keys, which every language's highlighter inherits from
+var jetbrainsAttributes = []semanticScope{
+	{"DEFAULT_KEYWORD", "keyword", ""},
+	{"DEFAULT_LABEL", "keyword", ""},
+	{"DEFAULT_STRING", "string", ""},
+	{"DEFAULT_NUMBER", "number", ""},
+	{"DEFAULT_CONSTANT", "variable.readonly", ""},
+	{"DEFAULT_PREDEFINED_SYMBOL", "variable.readonly", ""},
+	{"DEFAULT_LINE_COMMENT", "comment", "italic"},
+	{"DEFAULT_BLOCK_COMMENT", "comment", "italic"},
+	{"DEFAULT_DOC_COMMENT", "comment", "italic"},
+	{"DEFAULT_IDENTIFIER", "variable", ""},
+	{"DEFAULT_LOCAL_VARIABLE", "variable", ""},
+	{"DEFAULT_GLOBAL_VARIABLE", "variable", ""},
+	{"DEFAULT_PARAMETER", "parameter", ""},
+	{"DEFAULT_INSTANCE_FIELD", "property", ""},
+	{"DEFAULT_STATIC_FIELD", "property", ""},
+	{"DEFAULT_METADATA", "property", ""},
+	{"DEFAULT_FUNCTION_DECLARATION", "function", ""},
+	{"DEFAULT_FUNCTION_CALL", "function", ""},
+	{"DEFAULT_INSTANCE_METHOD", "method", ""},
+	{"DEFAULT_STATIC_METHOD", "method", ""},
+	{"DEFAULT_CLASS_NAME", "class", ""},
+	{"DEFAULT_CLASS_REFERENCE", "class", ""},
+	{"DEFAULT_INTERFACE_NAME", "type", ""},
+	{"ENUM_CONST", "enumMember", ""},
+	{"TYPE_PARAMETER_NAME_ATTRIBUTES", "typeParameter", ""},
+	{"DEFAULT_OPERATION_SIGN", "operator", ""},
+}
+
+// jetbrainsAttribute is one text attribute. Effect is drawn as EffectType:
+// 1 underlines, 2 underlines with a wave and 3 strikes through.
+type jetbrainsAttribute struct {
+	name       string
+	fg         string
+	bg         string
+	style      string
+	effect     string
+	effectType int
+}
+
+// GenerateJetBrainsScheme emits an .icls editor color scheme for IntelliJ
+// IDEA, PyCharm, GoLand and the other JetBrains IDEs. It inherits from
+// Darcula or Default, so attributes it does not set keep a sensible value.
+func GenerateJetBrainsScheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	semantic := semanticColors(colors)
+
+	name, parent := JetBrainsSchemeName+" Dark", "Darcula"
+	if isLight {
+		name, parent = JetBrainsSchemeName+" Light", "Default"
+	}
+
+	gutter := Mix(u.fg, u.bg, 0.55)
+	muted := Mix(u.fg, u.bg, 0.35)
+	selection := Mix(u.bg, u.accent, 0.3)
+
+	editorColors := []struct{ key, value string }{
+		{"CARET_COLOR", u.accentText},
+		{"CARET_ROW_COLOR", Mix(u.bg, u.fg, 0.06)},
+		{"SELECTION_BACKGROUND", selection},
+		{"SELECTION_FOREGROUND", ""},
+		{"GUTTER_BACKGROUND", u.bg},
+		{"LINE_NUMBERS_COLOR", gutter},
+		{"LINE_NUMBER_ON_CARET_ROW_COLOR", u.accentText},
+		{"INDENT_GUIDE", u.border},
+		{"SELECTED_INDENT_GUIDE", muted},
+		{"WHITESPACES", u.border},
+		{"RIGHT_MARGIN_COLOR", u.border},
+		{"TEARLINE_COLOR", u.border},
+		{"SELECTED_TEARLINE_COLOR", muted},
+		{"METHOD_SEPARATORS_COLOR", u.border},
+		{"CONSOLE_BACKGROUND_KEY", u.bg},
+		{"DOCUMENTATION_COLOR", u.raised},
+		{"LOOKUP_COLOR", u.raised},
+		{"NOTIFICATION_BACKGROUND", u.raised},
+		{"ADDED_LINES_COLOR", colors[2]},
+		{"MODIFIED_LINES_COLOR", colors[12]},
+		{"DELETED_LINES_COLOR", colors[1]},
+		{"WHITESPACES_MODIFIED_LINES_COLOR", Mix(colors[12], u.bg, 0.4)},
+		{"FILESTATUS_ADDED", colors[2]},
+		{"FILESTATUS_MODIFIED", colors[12]},
+		{"FILESTATUS_DELETED", colors[1]},
+	}
+
+	attributes := []jetbrainsAttribute{
+		{name: "TEXT", fg: u.fg, bg: u.bg},
+		{name: "FOLDED_TEXT_ATTRIBUTES", fg: muted, bg: u.raised},
+		{name: "IDENTIFIER_UNDER_CARET_ATTRIBUTES", bg: Mix(u.bg, u.accent, 0.15)},
+		{name: "WRITE_IDENTIFIER_UNDER_CARET_ATTRIBUTES", bg: Mix(u.bg, u.accent, 0.25)},
+		{name: "SEARCH_RESULT_ATTRIBUTES", bg: Mix(u.bg, colors[3], 0.3)},
+		{name: "TEXT_SEARCH_RESULT_ATTRIBUTES", bg: Mix(u.bg, colors[3], 0.3)},
+		{name: "MATCHED_BRACE_ATTRIBUTES", fg: u.accentText, style: "bold"},
+		{name: "TODO_DEFAULT_ATTRIBUTES", fg: colors[12], style: "bold"},
+		{name: "HYPERLINK_ATTRIBUTES", fg: colors[4], effect: colors[4], effectType: 1},
+		{name: "ERRORS_ATTRIBUTES", effect: colors[1], effectType: 2},
+		{name: "WARNING_ATTRIBUTES", effect: colors[3], effectType: 2},
+		{name: "INFO_ATTRIBUTES", effect: colors[6], effectType: 2},
+		{name: "WRONG_REFERENCES_ATTRIBUTES", fg: colors[1]},
+		{name: "NOT_USED_ELEMENT_ATTRIBUTES", fg: gutter},
+		{name: "DEPRECATED_ATTRIBUTES", effect: gutter, effectType: 3},
+		{name: "INLAY_DEFAULT", fg: colors[8], bg: u.raised},
+		{name: "DEFAULT_BRACES", fg: muted},
+		{name: "DEFAULT_BRACKETS", fg: muted},
+		{name: "DEFAULT_PARENTHS", fg: muted},
+		{name: "DEFAULT_COMMA", fg: muted},
+		{name: "DEFAULT_DOT", fg: muted},
+		{name: "DEFAULT_SEMICOLON", fg: muted},
+		{name: "DEFAULT_VALID_STRING_ESCAPE", fg: colors[6]},
+		{name: "DEFAULT_INVALID_STRING_ESCAPE", fg: colors[1], effect: colors[1], effectType: 2},
+		{name: "DEFAULT_TAG", fg: semantic["class"]},
+		{name: "DEFAULT_ATTRIBUTE", fg: semantic["property"]},
+		{name: "DEFAULT_ENTITY", fg: colors[6]},
+		{name: "DIFF_INSERTED", bg: Mix(u.bg, colors[2], 0.2)},
+		{name: "DIFF_MODIFIED", bg: Mix(u.bg, colors[4], 0.2)},
+		{name: "DIFF_DELETED", bg: Mix(u.bg, colors[1], 0.2)},
+		{name: "DIFF_CONFLICT", bg: Mix(u.bg, colors[3], 0.2)},
+	}
+	for _, s := range jetbrainsAttributes {
+		attributes = append(attributes, jetbrainsAttribute{name: s.name, fg: semantic[s.semantic], style: s.style})
+	}
+
+	ansi := []string{"BLACK", "RED", "GREEN", "YELLOW", "BLUE", "MAGENTA", "CYAN", "WHITE"}
+	for i, color := range ansi {
+		attributes = append(attributes,
+			jetbrainsAttribute{name: "CONSOLE_" + color + "_OUTPUT", fg: colors[i]},
+			jetbrainsAttribute{name: "CONSOLE_" + color + "_BRIGHT_OUTPUT", fg: colors[i+8]},
+		)
+	}
+	attributes = append(attributes,
+		jetbrainsAttribute{name: "CONSOLE_NORMAL_OUTPUT", fg: u.fg},
+		jetbrainsAttribute{name: "CONSOLE_ERROR_OUTPUT", fg: colors[1]},
+		jetbrainsAttribute{name: "CONSOLE_SYSTEM_OUTPUT", fg: muted},
+		jetbrainsAttribute{name: "CONSOLE_USER_INPUT", fg: colors[2], style: "italic"},
+	)
+
+	var result strings.Builder
+	result.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	result.WriteString("<!-- Generated by dank16 -->\n")
+	fmt.Fprintf(&result, "<scheme name=%q version=\"142\" parent_scheme=%q>\n", name, parent)
+	result.WriteString("  <colors>\n")
+	for _, c := range editorColors {
+		fmt.Fprintf(&result, "    <option name=%q value=%q />\n", c.key, jetbrainsColor(c.value))
+	}
+	result.WriteString("  </colors>\n")
+	result.WriteString("  <attributes>\n")
+	for _, a := range attributes {
+		fmt.Fprintf(&result, "    <option name=%q>\n      <value>\n", a.name)
+		if a.fg != "" {
+			fmt.Fprintf(&result, "        <option name=\"FOREGROUND\" value=%q />\n", jetbrainsColor(a.fg))
+		}
+		if a.bg != "" {
+			fmt.Fprintf(&result, "        <option name=\"BACKGROUND\" value=%q />\n", jetbrainsColor(a.bg))
+		}
+		if fontType := jetbrainsFontType(a.style); fontType != 0 {
+			fmt.Fprintf(&result, "        <option name=\"FONT_TYPE\" value=\"%d\" />\n", fontType)
+		}
+		if a.effect != "" {
+			fmt.Fprintf(&result, "        <option name=\"EFFECT_COLOR\" value=%q />\n", jetbrainsColor(a.effect))
+			fmt.Fprintf(&result, "        <option name=\"EFFECT_TYPE\" value=\"%d\" />\n", a.effectType)
+		}
+		result.WriteString("      </value>\n    </option>\n")
+	}
+	result.WriteString("  </attributes>\n")
+	result.WriteString("</scheme>\n")
+	return result.String()
+}
+
+// jetbrainsColor drops the # JetBrains schemes do without
+func jetbrainsColor(hex string) string {
+	return strings.TrimPrefix(hex, "#")
+}
+
+func jetbrainsFontType(style string) int {
+	switch style {
+	case "bold":
+		return 1
+	case "italic":
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package dank16
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJetBrainsScheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	icls := GenerateJetBrainsScheme(colors, false)
+
+	var scheme struct {
+		Name   string `xml:"name,attr"`
+		Parent string `xml:"parent_scheme,attr"`
+		Colors []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:"value,attr"`
+		} `xml:"colors>option"`
+		Attributes []struct {
+			Name    string `xml:"name,attr"`
+			Options []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:"value,attr"`
+			} `xml:"value>option"`
+		} `xml:"attributes>option"`
+	}
+	if err := xml.Unmarshal([]byte(icls), &scheme); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if scheme.Name != "Dank16 Dark" || scheme.Parent != "Darcula" {
+		t.Errorf("scheme %q based on %q", scheme.Name, scheme.Parent)
+	}
+
+	attrs := make(map[string]map[string]string)
+	for _, a := range scheme.Attributes {
+		if attrs[a.Name] != nil {
+			t.Errorf("%s is set twice", a.Name)
+		}
+		attrs[a.Name] = make(map[string]string)
+		for _, o := range a.Options {
+			attrs[a.Name][o.Name] = o.Value
+		}
+	}
+
+	semantic := semanticColors(colors)
+	for _, s := range jetbrainsAttributes {
+		if got, want := attrs[s.name]["FOREGROUND"], strings.TrimPrefix(semantic[s.semantic], "#"); got != want {
+			t.Errorf("%s = %s, want %s like VSCode's %s", s.name, got, want, s.semantic)
+		}
+	}
+	if attrs["DEFAULT_LINE_COMMENT"]["FONT_TYPE"] != "2" {
+		t.Error("comments should be italic")
+	}
+	if attrs["TEXT"]["BACKGROUND"] != strings.TrimPrefix(colors[0], "#") {
+		t.Errorf("text background = %s", attrs["TEXT"]["BACKGROUND"])
+	}
+	if attrs["CONSOLE_RED_BRIGHT_OUTPUT"]["FOREGROUND"] != strings.TrimPrefix(colors[9], "#") {
+		t.Error("bright red console output should use slot 9")
+	}
+	for _, c := range scheme.Colors {
+		if strings.HasPrefix(c.Value, "#") || (c.Value != "" && len(c.Value) != 6) {
+			t.Errorf("%s = %q, want rrggbb", c.Name, c.Value)
+		}
+	}
+
+	light := GenerateJetBrainsScheme(GeneratePalette("#625690", PaletteOptions{IsLight: true}), true)
+	if !strings.Contains(light, `parent_scheme="Default"`) {
+		t.Error("light variant should inherit from Default")
+	}
+}
@@ -0,0 +1,218 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateRofiTheme emits a rofi .rasi theme. Save it under
+// ~/.config/rofi/themes/ and select it with @theme "dank16" in config.rasi.
+func GenerateRofiTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.35)
+
+	var b strings.Builder
+	b.WriteString("/* Generated by dank16 */\n")
+	b.WriteString("* {\n")
+	for _, v := range []struct{ name, value string }{
+		{"bg", u.bg},
+		{"bg-alt", u.raised},
+		{"fg", u.fg},
+		{"fg-muted", muted},
+		{"border-color", u.border},
+		{"accent", u.accent},
+		{"on-accent", u.onAccent},
+		{"urgent", colors[1]},
+		{"active", colors[2]},
+		{"background-color", "transparent"},
+		{"text-color", "@fg"},
+	} {
+		value := v.value
+		if strings.HasPrefix(value, "#") {
+			value = strings.ToUpper(value)
+		}
+		fmt.Fprintf(&b, "    %s: %s;\n", v.name, value)
+	}
+	b.WriteString("}\n")
+
+	b.WriteString(`
+window {
+    background-color: @bg;
+    border: 2px;
+    border-color: @border-color;
+    border-radius: 12px;
+    padding: 12px;
+}
+
+inputbar {
+    background-color: @bg-alt;
+    border-radius: 8px;
+    padding: 8px 12px;
+    spacing: 8px;
+    children: [ prompt, entry ];
+}
+
+prompt {
+    text-color: @accent;
+}
+
+entry {
+    placeholder-color: @fg-muted;
+}
+
+listview {
+    margin: 8px 0 0;
+    spacing: 4px;
+}
+
+element {
+    border-radius: 8px;
+    padding: 6px 12px;
+}
+
+element normal.urgent, element alternate.urgent {
+    text-color: @urgent;
+}
+
+element normal.active, element alternate.active {
+    text-color: @active;
+}
+
+element selected.normal, element selected.active {
+    background-color: @accent;
+    text-color: @on-accent;
+}
+
+element selected.urgent {
+    background-color: @urgent;
+    text-color: @bg;
+}
+
+element-text, element-icon {
+    text-color: inherit;
+}
+
+message {
+    background-color: @bg-alt;
+    border-radius: 8px;
+    padding: 8px;
+}
+`)
+	return b.String()
+}
+
+// fuzzelColor is the RRGGBBAA form fuzzel.ini expects
+func fuzzelColor(hex string) string {
+	return NewRGBA(hex, 1).Encode(AlphaBareHex, "")
+}
+
+// GenerateFuzzelTheme emits the [colors] section of fuzzel.ini
+func GenerateFuzzelTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	var b strings.Builder
+	b.WriteString("# Generated by dank16\n")
+	b.WriteString("[colors]\n")
+	for _, c := range []struct{ name, hex string }{
+		{"background", u.bg},
+		{"text", u.fg},
+		{"prompt", u.accentText},
+		{"placeholder", Mix(u.fg, u.bg, 0.35)},
+		{"input", u.fg},
+		{"match", u.accentText},
+		{"selection", u.accent},
+		{"selection-text", u.onAccent},
+		{"selection-match", u.onAccent},
+		{"counter", u.disabledFg},
+		{"border", u.border},
+	} {
+		fmt.Fprintf(&b, "%s=%s\n", c.name, fuzzelColor(c.hex))
+	}
+	return b.String()
+}
+
+// GenerateFzfColors emits the --color option for FZF_DEFAULT_OPTS, e.g.
+// export FZF_DEFAULT_OPTS="$FZF_DEFAULT_OPTS $(dms dank16 <color> --fzf)"
+func GenerateFzfColors(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.35)
+
+	var pairs []string
+	for _, c := range []struct{ name, hex string }{
+		{"fg", u.fg},
+		{"bg", u.bg},
+		{"hl", u.accentText},
+		{"fg+", u.fg},
+		{"bg+", u.raised},
+		{"hl+", u.accentText},
+		{"gutter", u.bg},
+		{"query", u.fg},
+		{"info", muted},
+		{"border", u.border},
+		{"separator", u.border},
+		{"scrollbar", u.border},
+		{"label", u.accentText},
+		{"prompt", u.accentText},
+		{"pointer", colors[5]},
+		{"marker", colors[2]},
+		{"spinner", colors[6]},
+		{"header", colors[3]},
+	} {
+		pairs = append(pairs, c.name+":"+c.hex)
+	}
+	return "--color=" + strings.Join(pairs, ",") + "\n"
+}
+
+// GenerateWofiStyle emits a wofi style.css (~/.config/wofi/style.css)
+func GenerateWofiStyle(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	var b strings.Builder
+	b.WriteString("/* Generated by dank16 */\n")
+	fmt.Fprintf(&b, `window {
+    background-color: %s;
+    color: %s;
+    border: 2px solid %s;
+    border-radius: 12px;
+}
+
+#outer-box {
+    padding: 12px;
+}
+
+#input {
+    background-color: %s;
+    color: %s;
+    border: none;
+    border-radius: 8px;
+    padding: 6px 10px;
+    margin-bottom: 8px;
+}
+
+#input:focus {
+    box-shadow: inset 0 0 0 1px %s;
+}
+
+#scroll, #inner-box {
+    background-color: transparent;
+}
+
+#entry {
+    border-radius: 8px;
+    padding: 6px 10px;
+}
+
+#entry:selected {
+    background-color: %s;
+}
+
+#entry:selected #text {
+    color: %s;
+}
+
+#text {
+    color: %s;
+}
+`, u.bg, u.fg, u.border, u.raised, u.fg, u.accent, u.accent, u.onAccent, u.fg)
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateRofiTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	theme := GenerateRofiTheme(colors, false)
+
+	for _, want := range []string{
+		"    bg: " + strings.ToUpper(colors[0]) + ";\n",
+		"    accent: " + strings.ToUpper(colors[4]) + ";\n",
+		"    urgent: " + strings.ToUpper(colors[1]) + ";\n",
+		"element selected.normal, element selected.active {",
+		"    text-color: @on-accent;",
+	} {
+		if !strings.Contains(theme, want) {
+			t.Errorf("missing %q in:\n%s", want, theme)
+		}
+	}
+
+	if strings.Count(theme, "{") != strings.Count(theme, "}") {
+		t.Error("unbalanced braces")
+	}
+}
+
+func TestGenerateFuzzelTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: true})
+	ini := GenerateFuzzelTheme(colors, true)
+
+	if !strings.Contains(ini, "[colors]\n") {
+		t.Fatalf("missing [colors] section:\n%s", ini)
+	}
+	if want := "background=" + colors[0][1:] + "ff\n"; !strings.Contains(ini, want) {
+		t.Errorf("missing %q in:\n%s", want, ini)
+	}
+	if want := "selection=" + colors[4][1:] + "ff\n"; !strings.Contains(ini, want) {
+		t.Errorf("missing %q in:\n%s", want, ini)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(ini), "\n")[2:] {
+		_, value, ok := strings.Cut(line, "=")
+		if !ok || len(value) != 8 || strings.HasPrefix(value, "#") {
+			t.Errorf("bad color line %q", line)
+		}
+	}
+}
+
+func TestGenerateWofiStyle(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	css := GenerateWofiStyle(colors, false)
+
+	for _, want := range []string{
+		"window {\n    background-color: " + colors[0] + ";",
+		"#entry:selected {\n    background-color: " + colors[4] + ";",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("missing %q in:\n%s", want, css)
+		}
+	}
+	if strings.Contains(css, "%!") {
+		t.Errorf("format error in:\n%s", css)
+	}
+}
+
+func TestGenerateFzfColors(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	opt := GenerateFzfColors(colors, false)
+
+	if !strings.HasPrefix(opt, "--color=") || strings.ContainsAny(strings.TrimSpace(opt), " '\"") {
+		t.Fatalf("expected one unquoted --color option, got %q", opt)
+	}
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(strings.TrimSpace(strings.TrimPrefix(opt, "--color=")), ",") {
+		name, hex, ok := strings.Cut(pair, ":")
+		if !ok || !paletteHexPattern.MatchString(hex) {
+			t.Errorf("malformed pair %q", pair)
+		}
+		if seen[name] {
+			t.Errorf("duplicate %s", name)
+		}
+		seen[name] = true
+	}
+	for _, name := range []string{"fg", "bg", "hl", "fg+", "bg+", "hl+", "pointer", "prompt"} {
+		if !seen[name] {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if !strings.Contains(opt, "bg:"+colors[0]+",") {
+		t.Errorf("bg should be the palette background: %s", opt)
+	}
+}
@@ -0,0 +1,218 @@
+package dank16
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic kinds reported by ValidatePalette
+const (
+	DiagnosticInvalid    = "invalid"
+	DiagnosticContrast   = "contrast"
+	DiagnosticHue        = "hue-collision"
+	DiagnosticSaturation = "saturation-outlier"
+)
+
+// Diagnostic is one problem found in a palette. Value is the measured
+// contrast, hue distance in degrees or chroma, and Limit the threshold it
+// was held to.
+type Diagnostic struct {
+	Kind     string   `json:"kind"`
+	Severity Severity `json:"severity"`
+	Slots    []int    `json:"slots"`
+	Value    float64  `json:"value"`
+	Limit    float64  `json:"limit"`
+	Message  string   `json:"message"`
+}
+
+// SlotNames are the ANSI names of the 16 palette slots
+var SlotNames = [16]string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright black", "bright red", "bright green", "bright yellow",
+	"bright blue", "bright magenta", "bright cyan", "bright white",
+}
+
+const (
+	// minHueDistance is how far apart, in OKLCH degrees, two colors must be
+	// to read as different hues
+	minHueDistance = 15.0
+	// minHueChroma is the OKLCH chroma below which a hue is not perceived
+	minHueChroma = 0.04
+	// Chroma outside these multiples of its row's median stands out
+	chromaOutlierLow  = 0.4
+	chromaOutlierHigh = 2.5
+)
+
+// ValidatePalette checks a 16-color palette against the contrast targets
+// GeneratePalette aims for, and looks for colors that are hard to tell
+// apart or out of step with their row. Bright black is a deliberately dim
+// comment color and is not held to a contrast target. Blue, magenta and
+// cyan all derive from the accent, so only collisions involving red, green
+// or yellow are reported. Errors fail a lint; warnings are advisory.
+func ValidatePalette(colors []string, opts PaletteOptions) []Diagnostic {
+	if len(colors) != 16 {
+		return []Diagnostic{{
+			Kind:     DiagnosticInvalid,
+			Severity: SeverityError,
+			Value:    float64(len(colors)),
+			Limit:    16,
+			Message:  fmt.Sprintf("palette has %d colors, want 16", len(colors)),
+		}}
+	}
+
+	var diags []Diagnostic
+	for i, c := range colors {
+		if !paletteHexPattern.MatchString(c) {
+			diags = append(diags, Diagnostic{
+				Kind:     DiagnosticInvalid,
+				Severity: SeverityError,
+				Slots:    []int{i},
+				Message:  fmt.Sprintf("%s: invalid color %q", SlotNames[i], c),
+			})
+		}
+	}
+	if len(diags) > 0 {
+		return diags
+	}
+
+	diags = append(diags, lintContrast(colors, opts)...)
+	diags = append(diags, lintHues(colors)...)
+	diags = append(diags, lintChroma(colors)...)
+	return diags
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func contrastUnit(opts PaletteOptions) string {
+	switch {
+	case opts.UseAPCA:
+		return "APCA Lc"
+	case opts.UseDPS:
+		return "DPS Lc"
+	default:
+		return "WCAG ratio"
+	}
+}
+
+func lintContrast(colors []string, opts PaletteOptions) []Diagnostic {
+	normal, bright := contrastTargets(opts)
+	bg := colors[0]
+
+	var diags []Diagnostic
+	for i := 1; i < 16; i++ {
+		target := normal
+		switch {
+		case i == 8:
+			continue
+		case i > 8 && i < 15:
+			target = bright
+		}
+
+		value := measureContrast(colors[i], bg, opts)
+		if value >= target {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Kind:     DiagnosticContrast,
+			Severity: SeverityError,
+			Slots:    []int{i},
+			Value:    math.Round(value*10) / 10,
+			Limit:    target,
+			Message:  fmt.Sprintf("%s %s: %s %.1f against the background, needs %.1f", SlotNames[i], colors[i], contrastUnit(opts), value, target),
+		})
+	}
+	return diags
+}
+
+// fixedHueSlot is true for red, green and yellow, whose hue carries meaning
+func fixedHueSlot(i int) bool {
+	return (i >= 1 && i <= 3) || (i >= 9 && i <= 11)
+}
+
+func lintHues(colors []string) []Diagnostic {
+	var diags []Diagnostic
+	for _, row := range [][]int{{1, 2, 3, 4, 5, 6}, {9, 10, 11, 12, 13, 14}} {
+		for a := 0; a < len(row); a++ {
+			for b := a + 1; b < len(row); b++ {
+				i, j := row[a], row[b]
+				if !fixedHueSlot(i) && !fixedHueSlot(j) {
+					continue
+				}
+				_, ci, hi := hexToColorful(colors[i]).OkLch()
+				_, cj, hj := hexToColorful(colors[j]).OkLch()
+				if ci < minHueChroma || cj < minHueChroma {
+					continue
+				}
+
+				d := hueDistance(hi, hj)
+				if d >= minHueDistance {
+					continue
+				}
+				diags = append(diags, Diagnostic{
+					Kind:     DiagnosticHue,
+					Severity: SeverityError,
+					Slots:    []int{i, j},
+					Value:    math.Round(d*10) / 10,
+					Limit:    minHueDistance,
+					Message:  fmt.Sprintf("%s %s and %s %s are %.1f° apart in hue, need %.0f°", SlotNames[i], colors[i], SlotNames[j], colors[j], d, minHueDistance),
+				})
+			}
+		}
+	}
+	return diags
+}
+
+func lintChroma(colors []string) []Diagnostic {
+	var diags []Diagnostic
+	for _, row := range [][]int{{1, 2, 3, 4, 5, 6}, {9, 10, 11, 12, 13, 14}} {
+		chroma := make([]float64, len(row))
+		for k, i := range row {
+			_, chroma[k], _ = hexToColorful(colors[i]).OkLch()
+		}
+		sorted := append([]float64(nil), chroma...)
+		sort.Float64s(sorted)
+		median := (sorted[2] + sorted[3]) / 2
+		if median == 0 {
+			continue
+		}
+
+		for k, i := range row {
+			ratio := chroma[k] / median
+			var problem string
+			var limit float64
+			switch {
+			case ratio < chromaOutlierLow:
+				problem, limit = "washed out", chromaOutlierLow
+			case ratio > chromaOutlierHigh:
+				problem, limit = "oversaturated", chromaOutlierHigh
+			default:
+				continue
+			}
+			diags = append(diags, Diagnostic{
+				Kind:     DiagnosticSaturation,
+				Severity: SeverityWarning,
+				Slots:    []int{i},
+				Value:    math.Round(ratio*100) / 100,
+				Limit:    limit,
+				Message:  fmt.Sprintf("%s %s is %s: chroma %.2f× its row's median", SlotNames[i], colors[i], problem, ratio),
+			})
+		}
+	}
+	return diags
+}
@@ -0,0 +1,105 @@
+package dank16
+
+import "testing"
+
+func hasDiagnostic(diags []Diagnostic, kind string, slots ...int) bool {
+	for _, d := range diags {
+		if d.Kind != kind || len(d.Slots) != len(slots) {
+			continue
+		}
+		match := true
+		for i := range slots {
+			if d.Slots[i] != slots[i] {
+				match = false
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidatePaletteGeneratedPasses(t *testing.T) {
+	opts := PaletteOptions{IsLight: false, UseDPS: true}
+	diags := ValidatePalette(GeneratePalette("#625690", opts), opts)
+	if HasErrors(diags) {
+		t.Errorf("generated palette has errors: %+v", diags)
+	}
+}
+
+func TestValidatePaletteContrast(t *testing.T) {
+	opts := PaletteOptions{IsLight: false, UseDPS: true}
+	palette := GeneratePalette("#625690", opts)
+	palette[4] = palette[0]
+	palette[8] = palette[0]
+
+	diags := ValidatePalette(palette, opts)
+	if !hasDiagnostic(diags, DiagnosticContrast, 4) {
+		t.Errorf("expected contrast failure for slot 4, got %+v", diags)
+	}
+	if hasDiagnostic(diags, DiagnosticContrast, 8) {
+		t.Error("bright black should not be held to a contrast target")
+	}
+	if !HasErrors(diags) {
+		t.Error("HasErrors() = false, want true")
+	}
+}
+
+func TestValidatePaletteHueCollision(t *testing.T) {
+	opts := PaletteOptions{IsLight: false, UseDPS: true}
+	palette := GeneratePalette("#625690", opts)
+	palette[5] = "#e06c75"
+	palette[1] = "#e06c70"
+
+	diags := ValidatePalette(palette, opts)
+	if !hasDiagnostic(diags, DiagnosticHue, 1, 5) {
+		t.Errorf("expected hue collision between red and magenta, got %+v", diags)
+	}
+}
+
+func TestValidatePaletteSaturationOutlier(t *testing.T) {
+	opts := PaletteOptions{IsLight: false, UseDPS: true}
+	palette := GeneratePalette("#625690", opts)
+	palette[2] = "#a0a8a0"
+
+	diags := ValidatePalette(palette, opts)
+	found := false
+	for _, d := range diags {
+		if d.Kind == DiagnosticSaturation && d.Slots[0] == 2 {
+			found = true
+			if d.Severity != SeverityWarning {
+				t.Errorf("saturation outlier severity = %s, want warning", d.Severity)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected washed out green, got %+v", diags)
+	}
+}
+
+func TestValidatePaletteInvalid(t *testing.T) {
+	opts := PaletteOptions{}
+
+	diags := ValidatePalette([]string{"#000000"}, opts)
+	if len(diags) != 1 || diags[0].Kind != DiagnosticInvalid {
+		t.Errorf("short palette: got %+v", diags)
+	}
+
+	palette := GeneratePalette("#625690", opts)
+	palette[7] = "white"
+	diags = ValidatePalette(palette, opts)
+	if !hasDiagnostic(diags, DiagnosticInvalid, 7) {
+		t.Errorf("expected invalid color in slot 7, got %+v", diags)
+	}
+}
+
+func TestHasErrorsIgnoresWarnings(t *testing.T) {
+	diags := []Diagnostic{{Kind: DiagnosticSaturation, Severity: SeverityWarning}}
+	if HasErrors(diags) {
+		t.Error("HasErrors() = true for warnings only")
+	}
+	if HasErrors(nil) {
+		t.Error("HasErrors(nil) = true")
+	}
+}
@@ -0,0 +1,258 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sgrColor is the truecolor SGR foreground sequence for a hex color, as
+// used by dircolors and LS_COLORS
+func sgrColor(hex string) string {
+	return "38;2;" + sgrRGB(hex)
+}
+
+// sgrOn draws fg on a filled bg
+func sgrOn(fg, bg string) string {
+	return sgrColor(fg) + ";48;2;" + sgrRGB(bg)
+}
+
+func sgrRGB(hex string) string {
+	rgb := HexToRGB(hex)
+	return fmt.Sprintf("%d;%d;%d", int(rgb.R*255+0.5), int(rgb.G*255+0.5), int(rgb.B*255+0.5))
+}
+
+// fileCategories groups extensions that ls and eza color alike
+var fileCategories = []struct {
+	name       string
+	slot       int
+	extensions []string
+}{
+	{"archives", 1, []string{"7z", "bz2", "deb", "gz", "lz4", "rar", "rpm", "tar", "tgz", "xz", "zip", "zst"}},
+	{"images", 5, []string{"avif", "bmp", "gif", "jpeg", "jpg", "png", "svg", "tiff", "webp"}},
+	{"audio and video", 6, []string{"flac", "m4a", "mkv", "mov", "mp3", "mp4", "ogg", "opus", "wav", "webm"}},
+	{"documents", 3, []string{"doc", "docx", "epub", "md", "odt", "pdf", "txt"}},
+	{"source", 12, []string{"c", "cpp", "go", "h", "js", "lua", "py", "rs", "sh", "ts"}},
+	{"temporary", 8, []string{"bak", "log", "swp", "tmp"}},
+}
+
+// lsKind is a file type with its dircolors keyword and LS_COLORS code
+type lsKind struct {
+	name  string
+	code  string
+	value string
+}
+
+func lsKinds(colors []string, u uiColors) []lsKind {
+	return []lsKind{
+		{"NORMAL", "no", "00"},
+		{"FILE", "fi", "00"},
+		{"RESET", "rs", "0"},
+		{"DIR", "di", "01;" + sgrColor(u.accentText)},
+		{"LINK", "ln", sgrColor(colors[6])},
+		{"MULTIHARDLINK", "mh", "00"},
+		{"FIFO", "pi", sgrColor(colors[3])},
+		{"SOCK", "so", sgrColor(colors[5])},
+		{"DOOR", "do", sgrColor(colors[5])},
+		{"BLK", "bd", "01;" + sgrColor(colors[3])},
+		{"CHR", "cd", "01;" + sgrColor(colors[3])},
+		{"ORPHAN", "or", "01;" + sgrColor(colors[1])},
+		{"MISSING", "mi", "01;" + sgrColor(colors[1])},
+		{"SETUID", "su", sgrOn(onColor(colors[1]), colors[1])},
+		{"SETGID", "sg", sgrOn(onColor(colors[3]), colors[3])},
+		{"CAPABILITY", "ca", "00"},
+		{"STICKY_OTHER_WRITABLE", "tw", sgrOn(onColor(colors[2]), colors[2])},
+		{"OTHER_WRITABLE", "ow", sgrOn(u.accentText, u.raised)},
+		{"STICKY", "st", sgrOn(u.accentText, u.raised)},
+		{"EXEC", "ex", "01;" + sgrColor(colors[2])},
+	}
+}
+
+// GenerateDircolors emits a dircolors database in truecolor. Load it with
+// `eval "$(dircolors ~/.config/dircolors)"` to set LS_COLORS.
+func GenerateDircolors(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	var result strings.Builder
+	result.WriteString("# Generated by dank16\n")
+	result.WriteString("TERM *\n")
+	result.WriteString("COLORTERM ?*\n\n")
+
+	for _, k := range lsKinds(colors, u) {
+		fmt.Fprintf(&result, "%s %s\n", k.name, k.value)
+	}
+
+	for _, category := range fileCategories {
+		fmt.Fprintf(&result, "\n# %s\n", category.name)
+		for _, ext := range category.extensions {
+			fmt.Fprintf(&result, ".%s %s\n", ext, sgrColor(colors[category.slot]))
+		}
+	}
+	fmt.Fprintf(&result, "*~ %s\n", sgrColor(colors[8]))
+
+	return result.String()
+}
+
+// GenerateLSColors is the value dircolors would put in LS_COLORS for the
+// database GenerateDircolors emits, for shells set up without dircolors
+func GenerateLSColors(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	var entries []string
+	for _, k := range lsKinds(colors, u) {
+		entries = append(entries, k.code+"="+k.value)
+	}
+	for _, category := range fileCategories {
+		for _, ext := range category.extensions {
+			entries = append(entries, "*."+ext+"="+sgrColor(colors[category.slot]))
+		}
+	}
+	entries = append(entries, "*~="+sgrColor(colors[8]))
+	return strings.Join(entries, ":")
+}
+
+// ezaStyle is one entry of an eza theme; unset flags are left out
+type ezaStyle struct {
+	fg     string
+	bold   bool
+	dimmed bool
+}
+
+type ezaEntry struct {
+	key   string
+	style ezaStyle
+}
+
+func (s ezaStyle) yaml() string {
+	parts := []string{fmt.Sprintf("foreground: %q", s.fg)}
+	if s.bold {
+		parts = append(parts, "is_bold: true")
+	}
+	if s.dimmed {
+		parts = append(parts, "is_dimmed: true")
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// GenerateEzaTheme emits an eza theme.yml (eza 0.20 or newer reads it from
+// ~/.config/eza/theme.yml). File types use the same colors as the dircolors
+// output so ls and eza agree.
+func GenerateEzaTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.35)
+	subtle := Mix(u.fg, u.bg, 0.55)
+
+	fileTypes := []ezaEntry{}
+	for _, t := range []struct {
+		key  string
+		slot int
+	}{
+		{"image", 5}, {"video", 6}, {"music", 6}, {"lossless", 14}, {"crypto", 2},
+		{"document", 3}, {"compressed", 1}, {"temp", 8}, {"compiled", 13},
+		{"build", 11}, {"source", 12},
+	} {
+		fileTypes = append(fileTypes, ezaEntry{t.key, ezaStyle{fg: colors[t.slot]}})
+	}
+
+	sections := []struct {
+		name    string
+		entries []ezaEntry
+	}{
+		{"filekinds", []ezaEntry{
+			{"normal", ezaStyle{fg: u.fg}},
+			{"directory", ezaStyle{fg: u.accentText, bold: true}},
+			{"symlink", ezaStyle{fg: colors[6]}},
+			{"pipe", ezaStyle{fg: colors[3]}},
+			{"block_device", ezaStyle{fg: colors[3], bold: true}},
+			{"char_device", ezaStyle{fg: colors[3], bold: true}},
+			{"socket", ezaStyle{fg: colors[5]}},
+			{"special", ezaStyle{fg: colors[5]}},
+			{"executable", ezaStyle{fg: colors[2], bold: true}},
+			{"mount_point", ezaStyle{fg: colors[14], bold: true}},
+		}},
+		{"perms", []ezaEntry{
+			{"user_read", ezaStyle{fg: colors[3]}},
+			{"user_write", ezaStyle{fg: colors[1]}},
+			{"user_execute_file", ezaStyle{fg: colors[2], bold: true}},
+			{"user_execute_other", ezaStyle{fg: colors[2]}},
+			{"group_read", ezaStyle{fg: colors[3], dimmed: true}},
+			{"group_write", ezaStyle{fg: colors[1], dimmed: true}},
+			{"group_execute", ezaStyle{fg: colors[2], dimmed: true}},
+			{"other_read", ezaStyle{fg: muted}},
+			{"other_write", ezaStyle{fg: muted}},
+			{"other_execute", ezaStyle{fg: muted}},
+			{"special_user_file", ezaStyle{fg: colors[5]}},
+			{"special_other", ezaStyle{fg: colors[5]}},
+			{"attribute", ezaStyle{fg: subtle}},
+		}},
+		{"size", []ezaEntry{
+			{"major", ezaStyle{fg: colors[2]}},
+			{"minor", ezaStyle{fg: colors[2]}},
+			{"number_byte", ezaStyle{fg: u.fg}},
+			{"number_kilo", ezaStyle{fg: colors[2]}},
+			{"number_mega", ezaStyle{fg: colors[3]}},
+			{"number_giga", ezaStyle{fg: colors[11], bold: true}},
+			{"number_huge", ezaStyle{fg: colors[1], bold: true}},
+			{"unit_byte", ezaStyle{fg: muted}},
+			{"unit_kilo", ezaStyle{fg: muted}},
+			{"unit_mega", ezaStyle{fg: muted}},
+			{"unit_giga", ezaStyle{fg: muted}},
+			{"unit_huge", ezaStyle{fg: muted}},
+		}},
+		{"users", []ezaEntry{
+			{"user_you", ezaStyle{fg: colors[3], bold: true}},
+			{"user_root", ezaStyle{fg: colors[1]}},
+			{"user_other", ezaStyle{fg: muted}},
+			{"group_yours", ezaStyle{fg: colors[3]}},
+			{"group_other", ezaStyle{fg: muted}},
+			{"group_root", ezaStyle{fg: colors[1]}},
+		}},
+		{"links", []ezaEntry{
+			{"normal", ezaStyle{fg: colors[6]}},
+			{"multi_link_file", ezaStyle{fg: colors[6], bold: true}},
+		}},
+		{"git", []ezaEntry{
+			{"new", ezaStyle{fg: colors[2]}},
+			{"modified", ezaStyle{fg: colors[3]}},
+			{"deleted", ezaStyle{fg: colors[1]}},
+			{"renamed", ezaStyle{fg: colors[4]}},
+			{"typechange", ezaStyle{fg: colors[5]}},
+			{"ignored", ezaStyle{fg: subtle}},
+			{"conflicted", ezaStyle{fg: colors[9], bold: true}},
+		}},
+		{"git_repo", []ezaEntry{
+			{"branch_main", ezaStyle{fg: colors[2]}},
+			{"branch_other", ezaStyle{fg: colors[5]}},
+			{"git_clean", ezaStyle{fg: colors[2]}},
+			{"git_dirty", ezaStyle{fg: colors[3]}},
+		}},
+		{"file_type", fileTypes},
+	}
+
+	var result strings.Builder
+	result.WriteString("# Generated by dank16\n")
+	result.WriteString("colourful: true\n")
+	for _, s := range sections {
+		fmt.Fprintf(&result, "\n%s:\n", s.name)
+		for _, e := range s.entries {
+			fmt.Fprintf(&result, "  %s: %s\n", e.key, e.style.yaml())
+		}
+	}
+
+	result.WriteString("\n")
+	for _, top := range []ezaEntry{
+		{"punctuation", ezaStyle{fg: subtle}},
+		{"date", ezaStyle{fg: colors[4]}},
+		{"inode", ezaStyle{fg: muted}},
+		{"blocks", ezaStyle{fg: muted}},
+		{"header", ezaStyle{fg: u.fg, bold: true}},
+		{"octal", ezaStyle{fg: colors[5]}},
+		{"flags", ezaStyle{fg: colors[5]}},
+		{"symlink_path", ezaStyle{fg: colors[6]}},
+		{"control_char", ezaStyle{fg: colors[1]}},
+		{"broken_symlink", ezaStyle{fg: colors[1]}},
+		{"broken_path_overlay", ezaStyle{fg: colors[1], bold: true}},
+	} {
+		fmt.Fprintf(&result, "%s: %s\n", top.key, top.style.yaml())
+	}
+	return result.String()
+}
@@ -0,0 +1,59 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSGRColor(t *testing.T) {
+	if got := sgrColor("#ff8000"); got != "38;2;255;128;0" {
+		t.Errorf("sgrColor = %q", got)
+	}
+	if got := sgrOn("#000000", "#ffffff"); got != "38;2;0;0;0;48;2;255;255;255" {
+		t.Errorf("sgrOn = %q", got)
+	}
+}
+
+func TestGenerateDircolors(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	db := GenerateDircolors(colors, false)
+
+	for _, want := range []string{
+		"TERM *\n",
+		"DIR 01;" + sgrColor(colors[12]) + "\n",
+		"EXEC 01;" + sgrColor(colors[2]) + "\n",
+		".tar " + sgrColor(colors[1]) + "\n",
+		".png " + sgrColor(colors[5]) + "\n",
+	} {
+		if !strings.Contains(db, want) {
+			t.Errorf("missing %q in:\n%s", want, db)
+		}
+	}
+
+	for _, line := range strings.Split(db, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(strings.Fields(line)) != 2 {
+			t.Errorf("malformed dircolors line %q", line)
+		}
+	}
+}
+
+func TestGenerateEzaTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: true})
+	theme := GenerateEzaTheme(colors, true)
+
+	for _, want := range []string{
+		"colourful: true\n",
+		"\nfilekinds:\n",
+		"  directory: {foreground: \"" + colors[4] + "\", is_bold: true}\n",
+		"  executable: {foreground: \"" + colors[2] + "\", is_bold: true}\n",
+		"  compressed: {foreground: \"" + colors[1] + "\"}\n",
+		"\ndate: {foreground: \"" + colors[4] + "\"}\n",
+	} {
+		if !strings.Contains(theme, want) {
+			t.Errorf("missing %q in:\n%s", want, theme)
+		}
+	}
+}
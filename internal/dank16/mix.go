@@ -0,0 +1,128 @@
+package dank16
+
+import (
+	"fmt"
+	"math"
+)
+
+// Mixing sRGB hex values directly darkens midpoints and shifts hues, so the
+// helpers below work on linear light and only encode back to sRGB at the end.
+
+// HSL is hue, saturation and lightness computed from linear RGB, all in 0-1
+type HSL struct {
+	H, S, L float64
+}
+
+func linearToSRGB(c float64) float64 {
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+// toLinear decodes a hex color to linear RGB
+func toLinear(hex string) RGB {
+	rgb := HexToRGB(hex)
+	return RGB{R: sRGBToLinear(rgb.R), G: sRGBToLinear(rgb.G), B: sRGBToLinear(rgb.B)}
+}
+
+// fromLinear encodes linear RGB as hex, rounding so that round trips are
+// stable
+func fromLinear(rgb RGB) string {
+	channel := func(c float64) int {
+		c = math.Max(0, math.Min(1, c))
+		return int(math.Round(linearToSRGB(c) * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", channel(rgb.R), channel(rgb.G), channel(rgb.B))
+}
+
+// Mix blends a towards b by t (0 returns a, 1 returns b)
+func Mix(hexA, hexB string, t float64) string {
+	t = math.Max(0, math.Min(1, t))
+	a := toLinear(hexA)
+	b := toLinear(hexB)
+	return fromLinear(RGB{
+		R: a.R + (b.R-a.R)*t,
+		G: a.G + (b.G-a.G)*t,
+		B: a.B + (b.B-a.B)*t,
+	})
+}
+
+// Lighten mixes towards white by amount (0-1)
+func Lighten(hex string, amount float64) string {
+	return Mix(hex, "#ffffff", amount)
+}
+
+// Darken mixes towards black by amount (0-1)
+func Darken(hex string, amount float64) string {
+	return Mix(hex, "#000000", amount)
+}
+
+// WithAlpha returns hex as #rrggbbaa, replacing any existing alpha. Use
+// NewRGBA and Encode for formats that write alpha another way.
+func WithAlpha(hex string, alpha float64) string {
+	return NewRGBA(hex, alpha).Hex()
+}
+
+// ToHSL converts a hex color to HSL over linear RGB
+func ToHSL(hex string) HSL {
+	rgb := toLinear(hex)
+	max := math.Max(math.Max(rgb.R, rgb.G), rgb.B)
+	min := math.Min(math.Min(rgb.R, rgb.G), rgb.B)
+	l := (max + min) / 2
+	delta := max - min
+
+	if delta == 0 {
+		return HSL{H: 0, S: 0, L: l}
+	}
+
+	s := delta / (1 - math.Abs(2*l-1))
+
+	var h float64
+	switch max {
+	case rgb.R:
+		h = math.Mod((rgb.G-rgb.B)/delta, 6.0) / 6.0
+	case rgb.G:
+		h = ((rgb.B-rgb.R)/delta + 2.0) / 6.0
+	default:
+		h = ((rgb.R-rgb.G)/delta + 4.0) / 6.0
+	}
+	if h < 0 {
+		h += 1.0
+	}
+
+	return HSL{H: h, S: s, L: l}
+}
+
+// FromHSL converts HSL over linear RGB back to a hex color
+func FromHSL(hsl HSL) string {
+	h := math.Mod(hsl.H, 1.0)
+	if h < 0 {
+		h += 1.0
+	}
+	s := math.Max(0, math.Min(1, hsl.S))
+	l := math.Max(0, math.Min(1, hsl.L))
+
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := h * 6.0
+	x := c * (1 - math.Abs(math.Mod(hp, 2.0)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch int(hp) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return fromLinear(RGB{R: r + m, G: g + m, B: b + m})
+}
@@ -0,0 +1,91 @@
+package dank16
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMix(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		t        float64
+		expected string
+	}{
+		{"start", "#ff0000", "#0000ff", 0, "#ff0000"},
+		{"end", "#ff0000", "#0000ff", 1, "#0000ff"},
+		// Naive sRGB averaging would give #800080
+		{"red_blue_midpoint", "#ff0000", "#0000ff", 0.5, "#bc00bc"},
+		{"black_white_midpoint", "#000000", "#ffffff", 0.5, "#bcbcbc"},
+		{"same_color", "#625690", "#625690", 0.3, "#625690"},
+		{"clamps_t", "#000000", "#ffffff", 2, "#ffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mix(tt.a, tt.b, tt.t); got != tt.expected {
+				t.Errorf("Mix(%s, %s, %v) = %s, expected %s", tt.a, tt.b, tt.t, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLightenDarken(t *testing.T) {
+	base := "#625690"
+
+	lighter := Lighten(base, 0.3)
+	if Luminance(lighter) <= Luminance(base) {
+		t.Errorf("Lighten(%s) = %s, expected higher luminance", base, lighter)
+	}
+	darker := Darken(base, 0.3)
+	if Luminance(darker) >= Luminance(base) {
+		t.Errorf("Darken(%s) = %s, expected lower luminance", base, darker)
+	}
+
+	// Equal steps in linear light change luminance linearly
+	expected := Luminance(base) * 0.5
+	if got := Luminance(Darken(base, 0.5)); math.Abs(got-expected) > 0.005 {
+		t.Errorf("Darken(%s, 0.5) luminance = %v, expected %v", base, got, expected)
+	}
+}
+
+func TestWithAlpha(t *testing.T) {
+	tests := []struct {
+		hex      string
+		alpha    float64
+		expected string
+	}{
+		{"#625690", 1, "#625690ff"},
+		{"625690", 0.5, "#62569080"},
+		{"#FF0000cc", 0, "#ff000000"},
+		{"#ffffff", 1.5, "#ffffffff"},
+	}
+
+	for _, tt := range tests {
+		if got := WithAlpha(tt.hex, tt.alpha); got != tt.expected {
+			t.Errorf("WithAlpha(%s, %v) = %s, expected %s", tt.hex, tt.alpha, got, tt.expected)
+		}
+	}
+}
+
+func TestHSLRoundTrip(t *testing.T) {
+	colors := []string{"#000000", "#ffffff", "#808080", "#ff0000", "#00ff00", "#0000ff", "#625690", "#d0bcff", "#1a1a1a"}
+	for _, hex := range colors {
+		if got := FromHSL(ToHSL(hex)); got != hex {
+			t.Errorf("FromHSL(ToHSL(%s)) = %s", hex, got)
+		}
+	}
+}
+
+func TestToHSL(t *testing.T) {
+	hsl := ToHSL("#0000ff")
+	if !floatEqual(hsl.H, 2.0/3.0) || !floatEqual(hsl.S, 1) || !floatEqual(hsl.L, 0.5) {
+		t.Errorf("ToHSL(#0000ff) = %+v", hsl)
+	}
+
+	// Lightness is linear, so sRGB mid gray sits well below 0.5
+	gray := ToHSL("#808080")
+	if gray.S != 0 || math.Abs(gray.L-0.2158) > 0.001 {
+		t.Errorf("ToHSL(#808080) = %+v", gray)
+	}
+}
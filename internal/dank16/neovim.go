@@ -0,0 +1,302 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NeovimThemeName is the colorscheme name; the output belongs in
+// ~/.config/nvim/colors/dank16.lua
+const NeovimThemeName = "dank16"
+
+// nvimHighlight is one nvim_set_hl call. Attrs is a comma separated list of
+// boolean attributes such as "bold,italic"; Link replaces everything else.
+type nvimHighlight struct {
+	group string
+	fg    string
+	bg    string
+	sp    string
+	attrs string
+	link  string
+}
+
+// GenerateNeovimTheme emits a Lua colorscheme covering the editor UI, the
+// legacy syntax groups, treesitter captures, LSP semantic tokens and
+// diagnostics. Token roles follow the VSCode mapping so both editors agree.
+func GenerateNeovimTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	var (
+		red, green, yellow, blue = colors[1], colors[2], colors[3], colors[4]
+		magenta, cyan            = colors[5], colors[6]
+		comment                  = colors[8]
+		constant                 = colors[12]
+		storage                  = colors[13]
+		identifier               = colors[15]
+	)
+	cursorLine := Mix(u.bg, u.fg, 0.06)
+	selection := Mix(u.bg, u.accent, 0.3)
+	gutter := Mix(u.fg, u.bg, 0.55)
+	if isLight {
+		identifier = u.fg
+	}
+
+	groups := []nvimHighlight{
+		// Editor
+		{group: "Normal", fg: u.fg, bg: u.bg},
+		{group: "NormalNC", link: "Normal"},
+		{group: "NormalFloat", fg: u.fg, bg: u.raised},
+		{group: "FloatBorder", fg: u.border, bg: u.raised},
+		{group: "FloatTitle", fg: u.accentText, bg: u.raised, attrs: "bold"},
+		{group: "ColorColumn", bg: cursorLine},
+		{group: "Conceal", fg: comment},
+		{group: "Cursor", fg: u.bg, bg: u.fg},
+		{group: "lCursor", link: "Cursor"},
+		{group: "CursorIM", link: "Cursor"},
+		{group: "CursorLine", bg: cursorLine},
+		{group: "CursorColumn", link: "CursorLine"},
+		{group: "CursorLineNr", fg: u.accentText, attrs: "bold"},
+		{group: "LineNr", fg: gutter},
+		{group: "SignColumn", fg: gutter},
+		{group: "FoldColumn", fg: gutter},
+		{group: "Folded", fg: comment, bg: u.raised},
+		{group: "WinSeparator", fg: u.border},
+		{group: "VertSplit", link: "WinSeparator"},
+		{group: "StatusLine", fg: u.fg, bg: u.raised},
+		{group: "StatusLineNC", fg: gutter, bg: u.raised},
+		{group: "TabLine", fg: gutter, bg: u.raised},
+		{group: "TabLineFill", bg: u.raised},
+		{group: "TabLineSel", fg: u.onAccent, bg: u.accent, attrs: "bold"},
+		{group: "WinBar", fg: u.fg, attrs: "bold"},
+		{group: "WinBarNC", fg: gutter},
+		{group: "Pmenu", fg: u.fg, bg: u.raised},
+		{group: "PmenuSel", fg: u.onAccent, bg: u.accent},
+		{group: "PmenuSbar", bg: u.raised},
+		{group: "PmenuThumb", bg: u.border},
+		{group: "WildMenu", link: "PmenuSel"},
+		{group: "Visual", bg: selection},
+		{group: "VisualNOS", link: "Visual"},
+		{group: "Search", fg: onColor(yellow), bg: yellow},
+		{group: "IncSearch", fg: onColor(colors[11]), bg: colors[11]},
+		{group: "CurSearch", link: "IncSearch"},
+		{group: "Substitute", fg: onColor(red), bg: red},
+		{group: "MatchParen", fg: u.accentText, attrs: "bold,underline"},
+		{group: "NonText", fg: u.border},
+		{group: "Whitespace", fg: u.border},
+		{group: "SpecialKey", fg: u.border},
+		{group: "EndOfBuffer", fg: u.bg},
+		{group: "Directory", fg: blue},
+		{group: "Title", fg: u.accentText, attrs: "bold"},
+		{group: "Question", fg: green},
+		{group: "MoreMsg", fg: green},
+		{group: "ModeMsg", fg: u.fg, attrs: "bold"},
+		{group: "ErrorMsg", fg: red},
+		{group: "WarningMsg", fg: yellow},
+		{group: "QuickFixLine", bg: cursorLine, attrs: "bold"},
+		{group: "DiffAdd", bg: Mix(u.bg, green, 0.2)},
+		{group: "DiffChange", bg: Mix(u.bg, blue, 0.15)},
+		{group: "DiffDelete", fg: red, bg: Mix(u.bg, red, 0.2)},
+		{group: "DiffText", bg: Mix(u.bg, blue, 0.35)},
+		{group: "SpellBad", sp: red, attrs: "undercurl"},
+		{group: "SpellCap", sp: yellow, attrs: "undercurl"},
+		{group: "SpellLocal", sp: cyan, attrs: "undercurl"},
+		{group: "SpellRare", sp: magenta, attrs: "undercurl"},
+
+		// Syntax
+		{group: "Comment", fg: comment, attrs: "italic"},
+		{group: "Constant", fg: constant},
+		{group: "String", fg: yellow},
+		{group: "Character", fg: yellow},
+		{group: "Number", fg: constant},
+		{group: "Boolean", fg: constant},
+		{group: "Float", link: "Number"},
+		{group: "Identifier", fg: identifier},
+		{group: "Function", fg: green},
+		{group: "Statement", fg: magenta},
+		{group: "Conditional", link: "Statement"},
+		{group: "Repeat", link: "Statement"},
+		{group: "Label", link: "Statement"},
+		{group: "Keyword", link: "Statement"},
+		{group: "Exception", link: "Statement"},
+		{group: "Operator", fg: identifier},
+		{group: "PreProc", fg: storage},
+		{group: "Include", fg: magenta},
+		{group: "Define", link: "PreProc"},
+		{group: "Macro", link: "PreProc"},
+		{group: "PreCondit", link: "PreProc"},
+		{group: "Type", fg: constant},
+		{group: "StorageClass", fg: storage},
+		{group: "Structure", fg: storage},
+		{group: "Typedef", fg: storage},
+		{group: "Special", fg: cyan},
+		{group: "SpecialChar", link: "Special"},
+		{group: "Tag", fg: blue},
+		{group: "Delimiter", fg: gutter},
+		{group: "SpecialComment", fg: comment, attrs: "bold"},
+		{group: "Debug", fg: red},
+		{group: "Underlined", attrs: "underline"},
+		{group: "Ignore", fg: u.border},
+		{group: "Error", fg: red},
+		{group: "Todo", fg: u.onAccent, bg: u.accent, attrs: "bold"},
+
+		// Diagnostics
+		{group: "DiagnosticError", fg: red},
+		{group: "DiagnosticWarn", fg: yellow},
+		{group: "DiagnosticInfo", fg: blue},
+		{group: "DiagnosticHint", fg: cyan},
+		{group: "DiagnosticOk", fg: green},
+		{group: "DiagnosticUnderlineError", sp: red, attrs: "undercurl"},
+		{group: "DiagnosticUnderlineWarn", sp: yellow, attrs: "undercurl"},
+		{group: "DiagnosticUnderlineInfo", sp: blue, attrs: "undercurl"},
+		{group: "DiagnosticUnderlineHint", sp: cyan, attrs: "undercurl"},
+		{group: "DiagnosticUnderlineOk", sp: green, attrs: "undercurl"},
+		{group: "DiagnosticVirtualTextError", fg: red, bg: Mix(u.bg, red, 0.1)},
+		{group: "DiagnosticVirtualTextWarn", fg: yellow, bg: Mix(u.bg, yellow, 0.1)},
+		{group: "DiagnosticVirtualTextInfo", fg: blue, bg: Mix(u.bg, blue, 0.1)},
+		{group: "DiagnosticVirtualTextHint", fg: cyan, bg: Mix(u.bg, cyan, 0.1)},
+		{group: "DiagnosticUnnecessary", fg: comment},
+		{group: "DiagnosticDeprecated", sp: comment, attrs: "strikethrough"},
+
+		// LSP
+		{group: "LspReferenceText", bg: selection},
+		{group: "LspReferenceRead", link: "LspReferenceText"},
+		{group: "LspReferenceWrite", bg: selection, attrs: "underline"},
+		{group: "LspInlayHint", fg: comment, bg: Mix(u.bg, u.fg, 0.04)},
+		{group: "LspSignatureActiveParameter", fg: u.accentText, attrs: "bold"},
+		{group: "LspCodeLens", fg: comment},
+
+		// Treesitter
+		{group: "@variable", fg: identifier},
+		{group: "@variable.builtin", fg: constant},
+		{group: "@variable.parameter", fg: u.fg},
+		{group: "@variable.member", fg: blue},
+		{group: "@constant", link: "Constant"},
+		{group: "@constant.builtin", fg: constant},
+		{group: "@constant.macro", link: "Macro"},
+		{group: "@module", fg: identifier},
+		{group: "@label", link: "Label"},
+		{group: "@string", link: "String"},
+		{group: "@string.escape", fg: cyan},
+		{group: "@string.regexp", fg: cyan},
+		{group: "@string.special", link: "Special"},
+		{group: "@string.special.url", fg: blue, attrs: "underline"},
+		{group: "@character", link: "Character"},
+		{group: "@number", link: "Number"},
+		{group: "@number.float", link: "Number"},
+		{group: "@boolean", link: "Boolean"},
+		{group: "@function", link: "Function"},
+		{group: "@function.builtin", fg: green, attrs: "italic"},
+		{group: "@function.call", link: "Function"},
+		{group: "@function.macro", link: "Macro"},
+		{group: "@function.method", link: "Function"},
+		{group: "@function.method.call", link: "Function"},
+		{group: "@constructor", fg: constant},
+		{group: "@operator", link: "Operator"},
+		{group: "@keyword", link: "Keyword"},
+		{group: "@keyword.function", fg: magenta},
+		{group: "@keyword.return", fg: magenta, attrs: "bold"},
+		{group: "@keyword.import", link: "Include"},
+		{group: "@keyword.conditional", link: "Conditional"},
+		{group: "@keyword.repeat", link: "Repeat"},
+		{group: "@keyword.exception", link: "Exception"},
+		{group: "@keyword.modifier", fg: magenta},
+		{group: "@type", link: "Type"},
+		{group: "@type.builtin", fg: storage},
+		{group: "@type.definition", link: "Typedef"},
+		{group: "@attribute", fg: cyan},
+		{group: "@property", fg: blue},
+		{group: "@punctuation.delimiter", link: "Delimiter"},
+		{group: "@punctuation.bracket", fg: gutter},
+		{group: "@punctuation.special", fg: cyan},
+		{group: "@comment", link: "Comment"},
+		{group: "@comment.todo", link: "Todo"},
+		{group: "@comment.error", fg: onColor(red), bg: red, attrs: "bold"},
+		{group: "@comment.warning", fg: onColor(yellow), bg: yellow, attrs: "bold"},
+		{group: "@comment.note", fg: onColor(blue), bg: blue, attrs: "bold"},
+		{group: "@markup.heading", link: "Title"},
+		{group: "@markup.strong", attrs: "bold"},
+		{group: "@markup.italic", attrs: "italic"},
+		{group: "@markup.strikethrough", attrs: "strikethrough"},
+		{group: "@markup.underline", attrs: "underline"},
+		{group: "@markup.link", fg: blue},
+		{group: "@markup.link.url", fg: blue, attrs: "underline"},
+		{group: "@markup.raw", fg: cyan},
+		{group: "@markup.list", fg: magenta},
+		{group: "@markup.quote", fg: comment, attrs: "italic"},
+		{group: "@diff.plus", fg: green},
+		{group: "@diff.minus", fg: red},
+		{group: "@diff.delta", fg: blue},
+		{group: "@tag", link: "Tag"},
+		{group: "@tag.attribute", fg: cyan},
+		{group: "@tag.delimiter", link: "Delimiter"},
+
+		// LSP semantic tokens
+		{group: "@lsp.type.class", link: "@type"},
+		{group: "@lsp.type.decorator", link: "@attribute"},
+		{group: "@lsp.type.enum", link: "@type"},
+		{group: "@lsp.type.enumMember", link: "@constant"},
+		{group: "@lsp.type.function", link: "@function"},
+		{group: "@lsp.type.interface", link: "@type"},
+		{group: "@lsp.type.keyword", link: "@keyword"},
+		{group: "@lsp.type.macro", link: "@constant.macro"},
+		{group: "@lsp.type.method", link: "@function.method"},
+		{group: "@lsp.type.namespace", link: "@module"},
+		{group: "@lsp.type.parameter", link: "@variable.parameter"},
+		{group: "@lsp.type.property", link: "@property"},
+		{group: "@lsp.type.struct", link: "@type"},
+		{group: "@lsp.type.type", link: "@type"},
+		{group: "@lsp.type.typeParameter", fg: storage},
+		{group: "@lsp.type.variable", link: "@variable"},
+		{group: "@lsp.mod.deprecated", attrs: "strikethrough"},
+		{group: "@lsp.typemod.function.defaultLibrary", link: "@function.builtin"},
+		{group: "@lsp.typemod.variable.defaultLibrary", link: "@variable.builtin"},
+		{group: "@lsp.typemod.variable.readonly", fg: constant},
+
+		// gitsigns.nvim
+		{group: "GitSignsAdd", fg: green},
+		{group: "GitSignsChange", fg: blue},
+		{group: "GitSignsDelete", fg: red},
+	}
+
+	background := "dark"
+	if isLight {
+		background = "light"
+	}
+
+	var result strings.Builder
+	result.WriteString("-- Generated by dank16\n")
+	result.WriteString("vim.cmd(\"highlight clear\")\n")
+	result.WriteString("if vim.fn.exists(\"syntax_on\") == 1 then\n  vim.cmd(\"syntax reset\")\nend\n")
+	fmt.Fprintf(&result, "vim.o.background = %q\n", background)
+	fmt.Fprintf(&result, "vim.g.colors_name = %q\n\n", NeovimThemeName)
+	result.WriteString("local hl = function(group, spec) vim.api.nvim_set_hl(0, group, spec) end\n\n")
+
+	for _, h := range groups {
+		fmt.Fprintf(&result, "hl(%q, { %s })\n", h.group, h.spec())
+	}
+
+	result.WriteString("\n")
+	for i, c := range colors {
+		fmt.Fprintf(&result, "vim.g.terminal_color_%d = %q\n", i, c)
+	}
+	return result.String()
+}
+
+func (h nvimHighlight) spec() string {
+	if h.link != "" {
+		return fmt.Sprintf("link = %q", h.link)
+	}
+
+	var fields []string
+	for _, f := range []struct{ key, value string }{{"fg", h.fg}, {"bg", h.bg}, {"sp", h.sp}} {
+		if f.value != "" {
+			fields = append(fields, fmt.Sprintf("%s = %q", f.key, f.value))
+		}
+	}
+	if h.attrs != "" {
+		for _, attr := range strings.Split(h.attrs, ",") {
+			fields = append(fields, attr+" = true")
+		}
+	}
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,51 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateNeovimTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	lua := GenerateNeovimTheme(colors, false)
+
+	for _, want := range []string{
+		"vim.o.background = \"dark\"\n",
+		"vim.g.colors_name = \"dank16\"\n",
+		"hl(\"Normal\", { fg = \"" + colors[7] + "\", bg = \"" + colors[0] + "\" })\n",
+		"hl(\"Comment\", { fg = \"" + colors[8] + "\", italic = true })\n",
+		"hl(\"String\", { fg = \"" + colors[3] + "\" })\n",
+		"hl(\"Function\", { fg = \"" + colors[2] + "\" })\n",
+		"hl(\"@property\", { fg = \"" + colors[4] + "\" })\n",
+		"hl(\"@lsp.type.method\", { link = \"@function.method\" })\n",
+		"hl(\"DiagnosticUnderlineError\", { sp = \"" + colors[1] + "\", undercurl = true })\n",
+		"vim.g.terminal_color_15 = \"" + colors[15] + "\"\n",
+	} {
+		if !strings.Contains(lua, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+
+	// every linked group must be defined so no link dangles
+	defined := make(map[string]bool)
+	for _, line := range strings.Split(lua, "\n") {
+		if name, _, ok := strings.Cut(strings.TrimPrefix(line, "hl(\""), "\""); ok && strings.HasPrefix(line, "hl(\"") {
+			defined[name] = true
+		}
+	}
+	for _, line := range strings.Split(lua, "\n") {
+		_, target, ok := strings.Cut(line, "link = \"")
+		if !ok {
+			continue
+		}
+		target, _, _ = strings.Cut(target, "\"")
+		if !defined[target] {
+			t.Errorf("%s links to undefined group %s", line, target)
+		}
+	}
+
+	light := GenerateNeovimTheme(GeneratePalette("#625690", PaletteOptions{IsLight: true}), true)
+	if !strings.Contains(light, "vim.o.background = \"light\"\n") {
+		t.Error("light variant should set background=light")
+	}
+}
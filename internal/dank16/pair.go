@@ -0,0 +1,110 @@
+package dank16
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// PalettePair is a dark and a light palette from the same seed whose colors
+// correspond slot for slot
+type PalettePair struct {
+	Dark  []string `json:"dark"`
+	Light []string `json:"light"`
+}
+
+// GeneratePalettePair generates both variants of a palette in one call. The
+// light variant takes each accent slot's OKLCH hue from the dark one and
+// keeps its own lightness and chroma, so switching modes changes how light
+// a color is but not which color it is. opts.IsLight is ignored; a custom
+// Background is used for whichever variant it is light or dark enough for.
+func GeneratePalettePair(base string, opts PaletteOptions) PalettePair {
+	darkOpts, lightOpts := opts, opts
+	darkOpts.IsLight, lightOpts.IsLight = false, true
+	if opts.Background != "" {
+		if Luminance(opts.Background) < 0.5 {
+			lightOpts.Background = ""
+		} else {
+			darkOpts.Background = ""
+		}
+	}
+
+	dark := GeneratePalette(base, darkOpts)
+	light := GeneratePalette(base, lightOpts)
+
+	normal, bright := contrastTargets(lightOpts)
+	for i := range light {
+		target := normal
+		switch {
+		case i == 0 || i == 7 || i == 8 || i == 15:
+			continue
+		case i > 8:
+			target = bright
+		}
+		light[i] = matchHue(light[i], dark[i], light[0], target, lightOpts)
+	}
+
+	return PalettePair{Dark: dark, Light: light}
+}
+
+// matchHue gives hex the OKLCH hue of ref, then darkens or lightens it
+// until it meets target against bg again. Chroma is reduced where the hue
+// cannot be shown at full chroma, since clipping to sRGB would shift the
+// hue. Near-gray references have no hue worth matching.
+func matchHue(hex, ref, bg string, target float64, opts PaletteOptions) string {
+	_, refC, refH := hexToColorful(ref).OkLch()
+	l, c, h := hexToColorful(hex).OkLch()
+	if refC < minHueChroma || c < minHueChroma || hueDistance(h, refH) < 0.5 {
+		return hex
+	}
+
+	step := 0.005
+	if opts.IsLight {
+		step = -step
+	}
+	for L := l; L >= 0 && L <= 1; L += step {
+		if cand := okLchToHex(L, c, refH); measureContrast(cand, bg, opts) >= target {
+			return cand
+		}
+	}
+	return hex
+}
+
+// okLchToHex converts, lowering chroma as far as needed to stay in sRGB
+func okLchToHex(l, c, h float64) string {
+	col := colorful.OkLch(l, c, h)
+	if !col.IsValid() {
+		lo, hi := 0.0, c
+		for i := 0; i < 20; i++ {
+			mid := (lo + hi) / 2
+			if colorful.OkLch(l, mid, h).IsValid() {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		col = colorful.OkLch(l, lo, h)
+	}
+	r, g, b := col.Clamped().RGB255()
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// hueDistance is the angle between two hues in degrees, from 0 to 180
+func hueDistance(a, b float64) float64 {
+	d := math.Abs(a - b)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+// GeneratePairJSON emits both variants as NamedPalettes
+func GeneratePairJSON(pair PalettePair) string {
+	marshalled, _ := json.MarshalIndent(struct {
+		Dark  NamedPalette `json:"dark"`
+		Light NamedPalette `json:"light"`
+	}{NamePalette(pair.Dark, false), NamePalette(pair.Light, true)}, "", "  ")
+	return string(marshalled) + "\n"
+}
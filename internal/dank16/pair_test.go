@@ -0,0 +1,61 @@
+package dank16
+
+import "testing"
+
+func TestGeneratePalettePairSharesHues(t *testing.T) {
+	for _, seed := range []string{"#625690", "#ff9800", "#4caf50", "#2196f3"} {
+		for _, opts := range []PaletteOptions{{UseDPS: true}, {UseAPCA: true}, {}} {
+			pair := GeneratePalettePair(seed, opts)
+			lightOpts := opts
+			lightOpts.IsLight = true
+			normal, bright := contrastTargets(lightOpts)
+
+			for i := 1; i < 15; i++ {
+				if i == 7 || i == 8 {
+					continue
+				}
+				_, cd, hd := hexToColorful(pair.Dark[i]).OkLch()
+				_, cl, hl := hexToColorful(pair.Light[i]).OkLch()
+				if cd >= minHueChroma && cl >= minHueChroma && hueDistance(hd, hl) > 2 {
+					t.Errorf("%s slot %d: dark %s and light %s are %.1f° apart", seed, i, pair.Dark[i], pair.Light[i], hueDistance(hd, hl))
+				}
+
+				target := normal
+				if i > 8 {
+					target = bright
+				}
+				if got := measureContrast(pair.Light[i], pair.Light[0], lightOpts); got < target {
+					t.Errorf("%s slot %d: light %s has contrast %.1f, want >= %.1f", seed, i, pair.Light[i], got, target)
+				}
+			}
+		}
+	}
+}
+
+func TestGeneratePalettePairDarkMatchesGeneratePalette(t *testing.T) {
+	opts := PaletteOptions{IsLight: true, UseDPS: true}
+	pair := GeneratePalettePair("#625690", opts)
+
+	opts.IsLight = false
+	dark := GeneratePalette("#625690", opts)
+	for i := range dark {
+		if pair.Dark[i] != dark[i] {
+			t.Errorf("slot %d: pair has %s, GeneratePalette %s", i, pair.Dark[i], dark[i])
+		}
+	}
+}
+
+func TestGeneratePalettePairBackground(t *testing.T) {
+	pair := GeneratePalettePair("#625690", PaletteOptions{UseDPS: true, Background: "#101020"})
+	if pair.Dark[0] != "#101020" {
+		t.Errorf("dark background = %s, want #101020", pair.Dark[0])
+	}
+	if pair.Light[0] == "#101020" {
+		t.Error("dark background used for the light variant")
+	}
+
+	pair = GeneratePalettePair("#625690", PaletteOptions{UseDPS: true, Background: "#fafaf0"})
+	if pair.Light[0] != "#fafaf0" || pair.Dark[0] == "#fafaf0" {
+		t.Errorf("light background landed in the wrong variant: dark %s, light %s", pair.Dark[0], pair.Light[0])
+	}
+}
@@ -0,0 +1,138 @@
+package dank16
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// paletteColumns is the row width of the exported palettes, so the normal
+// and bright colors line up as they do in a terminal
+const paletteColumns = 8
+
+type paletteEntry struct {
+	Name string
+	Hex  string
+}
+
+// paletteEntries are the colors design tools get: the sixteen slots in
+// order, then the UI roles every other output shares
+func paletteEntries(colors []string, isLight bool) []paletteEntry {
+	u := deriveUIColors(colors, isLight)
+	names := []string{
+		"Background", "Red", "Green", "Yellow", "Blue", "Magenta", "Cyan", "Foreground",
+		"Comment", "Bright Red", "Bright Green", "Bright Yellow", "Bright Blue", "Bright Magenta", "Bright Cyan", "Bright White",
+	}
+	entries := make([]paletteEntry, 0, len(names)+5)
+	for i, name := range names {
+		entries = append(entries, paletteEntry{Name: name, Hex: colors[i]})
+	}
+	return append(entries,
+		paletteEntry{Name: "Accent", Hex: u.accent},
+		paletteEntry{Name: "Accent Text", Hex: u.accentText},
+		paletteEntry{Name: "On Accent", Hex: u.onAccent},
+		paletteEntry{Name: "Surface", Hex: u.raised},
+		paletteEntry{Name: "Border", Hex: u.border},
+	)
+}
+
+func paletteTitle(isLight bool) string {
+	if isLight {
+		return "Dank16 Light"
+	}
+	return "Dank16 Dark"
+}
+
+// GenerateGIMPPalette emits a .gpl palette, which GIMP and Inkscape load
+// from ~/.config/GIMP/<version>/palettes and ~/.config/inkscape/palettes
+func GenerateGIMPPalette(colors []string, isLight bool) string {
+	var b strings.Builder
+	b.WriteString("GIMP Palette\n")
+	fmt.Fprintf(&b, "Name: %s\n", paletteTitle(isLight))
+	fmt.Fprintf(&b, "Columns: %d\n", paletteColumns)
+	b.WriteString("#\n")
+	for _, e := range paletteEntries(colors, isLight) {
+		c := newPaletteColor(e.Hex)
+		fmt.Fprintf(&b, "%3d %3d %3d\t%s\n", c.RGB.R, c.RGB.G, c.RGB.B, e.Name)
+	}
+	return b.String()
+}
+
+type kritaColorSet struct {
+	XMLName  xml.Name          `xml:"ColorSet"`
+	Version  string            `xml:"version,attr"`
+	Name     string            `xml:"name,attr"`
+	Comment  string            `xml:"comment,attr"`
+	Columns  int               `xml:"columns,attr"`
+	Rows     int               `xml:"rows,attr"`
+	ReadOnly bool              `xml:"readonly,attr"`
+	Entries  []kritaColorEntry `xml:"ColorSetEntry"`
+}
+
+type kritaColorEntry struct {
+	Name     string `xml:"name,attr"`
+	ID       string `xml:"id,attr"`
+	Spot     bool   `xml:"spot,attr"`
+	BitDepth string `xml:"bitdepth,attr"`
+	SRGB     struct {
+		R float64 `xml:"r,attr"`
+		G float64 `xml:"g,attr"`
+		B float64 `xml:"b,attr"`
+	} `xml:"sRGB"`
+	Position struct {
+		Row    int `xml:"row,attr"`
+		Column int `xml:"column,attr"`
+	} `xml:"Position"`
+}
+
+// GenerateKritaPalette emits a .kpl palette, a zip holding the color set
+// XML, for ~/.local/share/krita/palettes
+func GenerateKritaPalette(colors []string, isLight bool) ([]byte, error) {
+	entries := paletteEntries(colors, isLight)
+	set := kritaColorSet{
+		Version: "2.0",
+		Name:    paletteTitle(isLight),
+		Comment: "Generated by dms dank16",
+		Columns: paletteColumns,
+		Rows:    (len(entries) + paletteColumns - 1) / paletteColumns,
+	}
+	for i, e := range entries {
+		entry := kritaColorEntry{Name: e.Name, ID: fmt.Sprint(i), BitDepth: "U8"}
+		rgb := HexToRGB(e.Hex)
+		entry.SRGB.R, entry.SRGB.G, entry.SRGB.B = rgb.R, rgb.G, rgb.B
+		entry.Position.Row, entry.Position.Column = i/paletteColumns, i%paletteColumns
+		set.Entries = append(set.Entries, entry)
+	}
+	colorSet, err := xml.MarshalIndent(set, "", " ")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	// Like ODF, the mimetype goes first and uncompressed
+	files := []struct {
+		name   string
+		method uint16
+		data   []byte
+	}{
+		{"mimetype", zip.Store, []byte("krita/x-colorset")},
+		{"colorset.xml", zip.Deflate, append([]byte(xml.Header), colorSet...)},
+		{"profiles.xml", zip.Deflate, []byte(xml.Header + "<Profiles/>\n")},
+	}
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: f.method})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package dank16
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGIMPPalette(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	palette := GenerateGIMPPalette(colors, false)
+
+	lines := strings.Split(strings.TrimSuffix(palette, "\n"), "\n")
+	header := []string{"GIMP Palette", "Name: Dank16 Dark", "Columns: 8", "#"}
+	for i, want := range header {
+		if lines[i] != want {
+			t.Errorf("header line %d: got %q, want %q", i, lines[i], want)
+		}
+	}
+	if got := len(lines) - len(header); got != 21 {
+		t.Fatalf("expected 16 slots and 5 UI roles, got %d colors", got)
+	}
+
+	bg := newPaletteColor(colors[0]).RGB
+	if want := fmt.Sprintf("%3d %3d %3d\tBackground", bg.R, bg.G, bg.B); lines[4] != want {
+		t.Errorf("first color: got %q, want %q", lines[4], want)
+	}
+	if !strings.HasSuffix(lines[4+16], "\tAccent") {
+		t.Errorf("UI roles should follow the slots, got %q", lines[4+16])
+	}
+
+	if !strings.Contains(GenerateGIMPPalette(GeneratePalette("#625690", PaletteOptions{IsLight: true}), true), "Name: Dank16 Light\n") {
+		t.Error("light palette should be named as such")
+	}
+}
+
+func TestGenerateKritaPalette(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	data, err := GenerateKritaPalette(colors, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	if len(zr.File) != 3 || zr.File[0].Name != "mimetype" || zr.File[0].Method != zip.Store {
+		t.Fatalf("mimetype should be the first, stored entry")
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	if string(files["mimetype"]) != "krita/x-colorset" {
+		t.Errorf("unexpected mimetype %q", files["mimetype"])
+	}
+
+	var set kritaColorSet
+	if err := xml.Unmarshal(files["colorset.xml"], &set); err != nil {
+		t.Fatalf("colorset.xml: %v", err)
+	}
+	if set.Name != "Dank16 Dark" || set.Columns != 8 || set.Rows != 3 {
+		t.Errorf("unexpected color set %q with %d columns and %d rows", set.Name, set.Columns, set.Rows)
+	}
+	if len(set.Entries) != 21 {
+		t.Fatalf("expected 21 entries, got %d", len(set.Entries))
+	}
+
+	red := set.Entries[1]
+	if red.Name != "Red" || red.Position.Row != 0 || red.Position.Column != 1 {
+		t.Errorf("unexpected entry %+v", red)
+	}
+	got := RGBToHex(RGB{R: red.SRGB.R, G: red.SRGB.G, B: red.SRGB.B})
+	if got != colors[1] {
+		t.Errorf("red: got %s, want %s", got, colors[1])
+	}
+	brightWhite := set.Entries[15]
+	if brightWhite.Position.Row != 1 || brightWhite.Position.Column != 7 {
+		t.Errorf("bright white should end the second row, got %+v", brightWhite.Position)
+	}
+}
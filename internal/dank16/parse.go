@@ -0,0 +1,208 @@
+package dank16
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseHex reads a color as #RGB, #RRGGBB or #RRGGBBAA (the leading # is
+// optional), as rgb()/rgba() in comma or space syntax, or as a CSS color
+// name. Alpha is accepted but dropped; the palette has no use for it.
+func ParseHex(color string) (RGB, error) {
+	r, g, b, _, err := parseColor(color)
+	if err != nil {
+		return RGB{}, err
+	}
+	return RGB{R: float64(r) / 255.0, G: float64(g) / 255.0, B: float64(b) / 255.0}, nil
+}
+
+// NormalizeHex parses color as ParseHex does and returns it as #rrggbb, the
+// form the rest of the package expects
+func NormalizeHex(color string) (string, error) {
+	r, g, b, _, err := parseColor(color)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b), nil
+}
+
+// Normalize returns opts with Background and the honored accents as
+// #rrggbb, or the first of them that can't be parsed
+func (opts PaletteOptions) Normalize() (PaletteOptions, error) {
+	fields := []struct {
+		name  string
+		color *string
+	}{
+		{"background", &opts.Background},
+		{"honor primary", &opts.HonorPrimary},
+		{"honor secondary", &opts.HonorSecondary},
+		{"honor tertiary", &opts.HonorTertiary},
+	}
+	for _, f := range fields {
+		if *f.color == "" {
+			continue
+		}
+		hex, err := NormalizeHex(*f.color)
+		if err != nil {
+			return opts, fmt.Errorf("%s: %w", f.name, err)
+		}
+		*f.color = hex
+	}
+	return opts, nil
+}
+
+// ParsePalette is GeneratePalette for colors from users: the primary and
+// every color in opts are parsed first, and a bad one is an error instead
+// of a palette built around black
+func ParsePalette(primaryColor string, opts PaletteOptions) ([]string, error) {
+	primary, err := NormalizeHex(primaryColor)
+	if err != nil {
+		return nil, err
+	}
+	opts, err = opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	return GeneratePalette(primary, opts), nil
+}
+
+// parseColor returns the channels of color and its alpha in 0-1
+func parseColor(color string) (r, g, b uint8, a float64, err error) {
+	s := strings.ToLower(strings.TrimSpace(color))
+	switch {
+	case s == "":
+		return 0, 0, 0, 0, fmt.Errorf("empty color")
+	case strings.HasPrefix(s, "rgb(") || strings.HasPrefix(s, "rgba("):
+		return parseRGBFunc(s)
+	}
+	if hex, ok := cssColorNames[s]; ok {
+		s = hex
+	}
+
+	digits := strings.TrimPrefix(s, "#")
+	a = 1
+	switch len(digits) {
+	case 3:
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	case 6:
+	case 8:
+		alpha, err := strconv.ParseUint(digits[6:], 16, 8)
+		if err != nil {
+			return 0, 0, 0, 0, fmt.Errorf("invalid color %q", color)
+		}
+		a = float64(alpha) / 255
+		digits = digits[:6]
+	default:
+		return 0, 0, 0, 0, fmt.Errorf("invalid color %q (expected #rgb, #rrggbb, #rrggbbaa, rgb() or a color name)", color)
+	}
+
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid color %q: not a hex number", color)
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), a, nil
+}
+
+// parseRGBFunc reads rgb(1, 2, 3), rgba(1, 2, 3, 0.5), rgb(1 2 3 / 50%)
+// and their percentage forms
+func parseRGBFunc(s string) (r, g, b uint8, a float64, err error) {
+	open := strings.IndexByte(s, '(')
+	if !strings.HasSuffix(s, ")") {
+		return 0, 0, 0, 0, fmt.Errorf("invalid color %q: missing )", s)
+	}
+	args := strings.FieldsFunc(s[open+1:len(s)-1], func(c rune) bool {
+		return c == ',' || c == '/' || c == ' ' || c == '\t'
+	})
+	if len(args) != 3 && len(args) != 4 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid color %q: expected 3 or 4 components", s)
+	}
+
+	var channels [3]uint8
+	a = 1
+	for i, arg := range args {
+		scale := 255.0
+		if i == 3 {
+			scale = 1
+		}
+		v, ok := parseComponent(arg, scale)
+		if !ok {
+			return 0, 0, 0, 0, fmt.Errorf("invalid color %q: bad component %q", s, arg)
+		}
+		if i < 3 {
+			channels[i] = uint8(math.Round(v))
+		} else {
+			a = v
+		}
+	}
+	return channels[0], channels[1], channels[2], a, nil
+}
+
+// parseComponent reads a number or percentage of scale, clamped to
+// [0, scale] as CSS does
+func parseComponent(arg string, scale float64) (float64, bool) {
+	pct := strings.HasSuffix(arg, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	if pct {
+		v = v / 100 * scale
+	}
+	return math.Max(0, math.Min(scale, v)), true
+}
+
+// cssColorNames are the CSS Color Module Level 4 named colors
+var cssColorNames = map[string]string{
+	"aliceblue": "#f0f8ff", "antiquewhite": "#faebd7", "aqua": "#00ffff",
+	"aquamarine": "#7fffd4", "azure": "#f0ffff", "beige": "#f5f5dc",
+	"bisque": "#ffe4c4", "black": "#000000", "blanchedalmond": "#ffebcd",
+	"blue": "#0000ff", "blueviolet": "#8a2be2", "brown": "#a52a2a",
+	"burlywood": "#deb887", "cadetblue": "#5f9ea0", "chartreuse": "#7fff00",
+	"chocolate": "#d2691e", "coral": "#ff7f50", "cornflowerblue": "#6495ed",
+	"cornsilk": "#fff8dc", "crimson": "#dc143c", "cyan": "#00ffff",
+	"darkblue": "#00008b", "darkcyan": "#008b8b", "darkgoldenrod": "#b8860b",
+	"darkgray": "#a9a9a9", "darkgreen": "#006400", "darkgrey": "#a9a9a9",
+	"darkkhaki": "#bdb76b", "darkmagenta": "#8b008b", "darkolivegreen": "#556b2f",
+	"darkorange": "#ff8c00", "darkorchid": "#9932cc", "darkred": "#8b0000",
+	"darksalmon": "#e9967a", "darkseagreen": "#8fbc8f", "darkslateblue": "#483d8b",
+	"darkslategray": "#2f4f4f", "darkslategrey": "#2f4f4f", "darkturquoise": "#00ced1",
+	"darkviolet": "#9400d3", "deeppink": "#ff1493", "deepskyblue": "#00bfff",
+	"dimgray": "#696969", "dimgrey": "#696969", "dodgerblue": "#1e90ff",
+	"firebrick": "#b22222", "floralwhite": "#fffaf0", "forestgreen": "#228b22",
+	"fuchsia": "#ff00ff", "gainsboro": "#dcdcdc", "ghostwhite": "#f8f8ff",
+	"gold": "#ffd700", "goldenrod": "#daa520", "gray": "#808080",
+	"green": "#008000", "greenyellow": "#adff2f", "grey": "#808080",
+	"honeydew": "#f0fff0", "hotpink": "#ff69b4", "indianred": "#cd5c5c",
+	"indigo": "#4b0082", "ivory": "#fffff0", "khaki": "#f0e68c",
+	"lavender": "#e6e6fa", "lavenderblush": "#fff0f5", "lawngreen": "#7cfc00",
+	"lemonchiffon": "#fffacd", "lightblue": "#add8e6", "lightcoral": "#f08080",
+	"lightcyan": "#e0ffff", "lightgoldenrodyellow": "#fafad2", "lightgray": "#d3d3d3",
+	"lightgreen": "#90ee90", "lightgrey": "#d3d3d3", "lightpink": "#ffb6c1",
+	"lightsalmon": "#ffa07a", "lightseagreen": "#20b2aa", "lightskyblue": "#87cefa",
+	"lightslategray": "#778899", "lightslategrey": "#778899", "lightsteelblue": "#b0c4de",
+	"lightyellow": "#ffffe0", "lime": "#00ff00", "limegreen": "#32cd32",
+	"linen": "#faf0e6", "magenta": "#ff00ff", "maroon": "#800000",
+	"mediumaquamarine": "#66cdaa", "mediumblue": "#0000cd", "mediumorchid": "#ba55d3",
+	"mediumpurple": "#9370db", "mediumseagreen": "#3cb371", "mediumslateblue": "#7b68ee",
+	"mediumspringgreen": "#00fa9a", "mediumturquoise": "#48d1cc", "mediumvioletred": "#c71585",
+	"midnightblue": "#191970", "mintcream": "#f5fffa", "mistyrose": "#ffe4e1",
+	"moccasin": "#ffe4b5", "navajowhite": "#ffdead", "navy": "#000080",
+	"oldlace": "#fdf5e6", "olive": "#808000", "olivedrab": "#6b8e23",
+	"orange": "#ffa500", "orangered": "#ff4500", "orchid": "#da70d6",
+	"palegoldenrod": "#eee8aa", "palegreen": "#98fb98", "paleturquoise": "#afeeee",
+	"palevioletred": "#db7093", "papayawhip": "#ffefd5", "peachpuff": "#ffdab9",
+	"peru": "#cd853f", "pink": "#ffc0cb", "plum": "#dda0dd",
+	"powderblue": "#b0e0e6", "purple": "#800080", "rebeccapurple": "#663399",
+	"red": "#ff0000", "rosybrown": "#bc8f8f", "royalblue": "#4169e1",
+	"saddlebrown": "#8b4513", "salmon": "#fa8072", "sandybrown": "#f4a460",
+	"seagreen": "#2e8b57", "seashell": "#fff5ee", "sienna": "#a0522d",
+	"silver": "#c0c0c0", "skyblue": "#87ceeb", "slateblue": "#6a5acd",
+	"slategray": "#708090", "slategrey": "#708090", "snow": "#fffafa",
+	"springgreen": "#00ff7f", "steelblue": "#4682b4", "tan": "#d2b48c",
+	"teal": "#008080", "thistle": "#d8bfd8", "tomato": "#ff6347",
+	"turquoise": "#40e0d0", "violet": "#ee82ee", "wheat": "#f5deb3",
+	"white": "#ffffff", "whitesmoke": "#f5f5f5", "yellow": "#ffff00",
+	"yellowgreen": "#9acd32",
+}
@@ -0,0 +1,77 @@
+package dank16
+
+import (
+	"testing"
+)
+
+func TestNormalizeHex(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"#625690", "#625690"},
+		{"625690", "#625690"},
+		{"#ABCDEF", "#abcdef"},
+		{"  #abc ", "#aabbcc"},
+		{"#62569080", "#625690"},
+		{"rgb(98, 86, 144)", "#625690"},
+		{"rgba(98,86,144,0.5)", "#625690"},
+		{"rgb(98 86 144 / 50%)", "#625690"},
+		{"rgb(100%, 0%, 50%)", "#ff0080"},
+		{"rgb(300, -4, 0)", "#ff0000"},
+		{"RebeccaPurple", "#663399"},
+		{"white", "#ffffff"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeHex(tt.input)
+		if err != nil {
+			t.Errorf("NormalizeHex(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeHex(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeHex_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"", "   ", "#", "#12", "#12345", "#1234567", "#ggg", "zzzzzz",
+		"#+12345", "notacolor", "#red", "rgb(1, 2)", "rgb(1, 2, 3", "rgb(a, b, c)",
+		"rgba(1, 2, 3, 4, 5)", "#625690zz",
+	} {
+		if got, err := NormalizeHex(input); err == nil {
+			t.Errorf("NormalizeHex(%q) = %s, want an error", input, got)
+		}
+	}
+}
+
+func TestHexToRGB_Invalid(t *testing.T) {
+	// Unparseable input used to panic on an empty string and half-parse
+	// garbage; now it is black
+	for _, input := range []string{"", "#", "garbage"} {
+		if got := HexToRGB(input); got != (RGB{}) {
+			t.Errorf("HexToRGB(%q) = %+v, want black", input, got)
+		}
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	got, err := ParsePalette("rgb(98, 86, 144)", PaletteOptions{HonorPrimary: "DodgerBlue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GeneratePalette("#625690", PaletteOptions{HonorPrimary: "#1e90ff"})
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("slot %d: got %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	if _, err := ParsePalette("", PaletteOptions{}); err == nil {
+		t.Error("empty primary should be an error")
+	}
+	if _, err := ParsePalette("#625690", PaletteOptions{Background: "#12"}); err == nil {
+		t.Error("bad background should be an error")
+	}
+}
@@ -0,0 +1,51 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// presetAliases name a scheme by its family, which picks the dark
+// variant, or by its family and polarity
+var presetAliases = map[string]string{
+	"catppuccin":        "catppuccin-mocha",
+	"catppuccin-dark":   "catppuccin-mocha",
+	"catppuccin-light":  "catppuccin-latte",
+	"gruvbox":           "gruvbox-dark",
+	"nord-dark":         "nord",
+	"solarized":         "solarized-dark",
+	"tokyo-night-dark":  "tokyo-night",
+	"tokyo-night-light": "tokyo-night-day",
+}
+
+// PresetName is how a bundled scheme is chosen with --preset: its name in
+// lower case with dashes, such as gruvbox-dark
+func PresetName(s Scheme) string {
+	return strings.ReplaceAll(strings.ToLower(s.Name), " ", "-")
+}
+
+// PresetNames lists the bundled schemes by PresetName
+func PresetNames() []string {
+	names := make([]string, 0, len(Schemes))
+	for _, s := range Schemes {
+		names = append(names, PresetName(s))
+	}
+	return names
+}
+
+// LoadPreset returns a bundled scheme to use in place of a generated
+// palette, so it can go through every output format. name is a PresetName
+// or an alias such as gruvbox or catppuccin-light.
+func LoadPreset(name string) (Scheme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := presetAliases[name]; ok {
+		name = alias
+	}
+	for _, s := range Schemes {
+		if PresetName(s) == name {
+			s.Colors = append([]string{}, s.Colors...)
+			return s, nil
+		}
+	}
+	return Scheme{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(PresetNames(), ", "))
+}
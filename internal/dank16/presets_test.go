@@ -0,0 +1,60 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadPreset(t *testing.T) {
+	for _, name := range PresetNames() {
+		s, err := LoadPreset(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if PresetName(s) != name {
+			t.Errorf("%s loaded %s", name, s.Name)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"nord":             "Nord",
+		"Gruvbox":          "Gruvbox Dark",
+		"catppuccin-light": "Catppuccin Latte",
+		" solarized ":      "Solarized Dark",
+		"solarized-light":  "Solarized Light",
+	} {
+		s, err := LoadPreset(name)
+		if err != nil {
+			t.Errorf("%q: %v", name, err)
+			continue
+		}
+		if s.Name != want {
+			t.Errorf("%q loaded %s, expected %s", name, s.Name, want)
+		}
+	}
+
+	if _, err := LoadPreset("nord-light"); err == nil {
+		t.Error("expected an error for a missing variant")
+	}
+
+	// The caller gets its own copy of the colors
+	s, _ := LoadPreset("nord")
+	s.Colors[0] = "#000000"
+	if again, _ := LoadPreset("nord"); again.Colors[0] != "#2e3440" {
+		t.Errorf("LoadPreset returned the bundled slice, now %s", again.Colors[0])
+	}
+}
+
+func TestPresetAliasesResolve(t *testing.T) {
+	for alias, name := range presetAliases {
+		s, err := LoadPreset(name)
+		if err != nil {
+			t.Errorf("alias %s points at %s: %v", alias, name, err)
+			continue
+		}
+		if strings.HasSuffix(alias, "-light") && !s.IsLight {
+			t.Errorf("alias %s is a dark scheme", alias)
+		}
+	}
+}
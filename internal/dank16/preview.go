@@ -0,0 +1,74 @@
+package dank16
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const ansiReset = "\x1b[0m"
+
+func ansiBackground(hex string) string {
+	c := newPaletteColor(hex).RGB
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+func ansiForeground(hex string) string {
+	c := newPaletteColor(hex).RGB
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+// previewLc is the Lc column: the selected algorithm's when it measures
+// Lc, APCA's when contrast is held to WCAG ratios
+func previewLc(fg, bg string, opts PaletteOptions) (float64, string) {
+	if opts.UseAPCA || opts.UseDPS {
+		return measureContrast(fg, bg, opts), contrastUnit(opts)
+	}
+	return APCAContrastForMode(fg, bg, opts.IsLight), "APCA Lc"
+}
+
+// RenderPreview draws the palette with 24-bit ANSI colors: the two rows of
+// swatches a terminal shows, then every slot as text on the background with
+// its Lc and WCAG ratio against it. Slots below the contrast target the
+// palette was generated for are marked, as dms dank16 --lint reports them.
+func RenderPreview(colors []string, opts PaletteOptions) string {
+	bg := colors[0]
+	failing := make(map[int]Diagnostic)
+	for _, d := range lintContrast(colors, opts) {
+		failing[d.Slots[0]] = d
+	}
+
+	var b strings.Builder
+	mode := "dark"
+	if opts.IsLight {
+		mode = "light"
+	}
+	fmt.Fprintf(&b, "dank16 %s palette, accent %s\n\n", mode, AccentColor(colors))
+
+	for row := 0; row < 2; row++ {
+		b.WriteString("  ")
+		for i := row * 8; i < row*8+8; i++ {
+			b.WriteString(ansiBackground(colors[i]) + "      " + ansiReset)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	_, unit := previewLc(colors[1], bg, opts)
+	fmt.Fprintf(&b, "  %-16s %-8s %-15s %8s %8s\n", "slot", "color", "sample", unit, "WCAG")
+	for i, c := range colors {
+		sample := ansiBackground(bg) + ansiForeground(c) + " The quick fox " + ansiReset
+		if i == 0 {
+			fmt.Fprintf(&b, "  %-16s %-8s %s %8s %8s\n", SlotNames[i], c, ansiBackground(c)+strings.Repeat(" ", 15)+ansiReset, "", "")
+			continue
+		}
+
+		lc, _ := previewLc(c, bg, opts)
+		fmt.Fprintf(&b, "  %-16s %-8s %s %8.1f %7.2f:1", SlotNames[i], c, sample, math.Round(lc*10)/10, ContrastRatio(c, bg))
+		if d, ok := failing[i]; ok {
+			fmt.Fprintf(&b, "  ✗ needs %s %.1f", contrastUnit(opts), d.Limit)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package dank16
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestRenderPreview(t *testing.T) {
+	opts := PaletteOptions{UseDPS: true}
+	colors := GeneratePalette("#625690", opts)
+	preview := RenderPreview(colors, opts)
+
+	if !strings.Contains(preview, "\x1b[48;2;") || !strings.Contains(preview, "\x1b[38;2;") {
+		t.Fatal("preview should use 24-bit background and foreground colors")
+	}
+	for _, line := range strings.Split(preview, "\n") {
+		escapes := ansiEscape.FindAllString(line, -1)
+		if len(escapes) > 0 && escapes[len(escapes)-1] != ansiReset {
+			t.Errorf("colors should be reset before the line ends: %q", line)
+		}
+	}
+
+	plain := ansiEscape.ReplaceAllString(preview, "")
+	lines := strings.Split(strings.TrimSuffix(plain, "\n"), "\n")
+	var slots []string
+	for _, line := range lines {
+		for _, name := range SlotNames {
+			if strings.HasPrefix(strings.TrimSpace(line), name+" ") && strings.Contains(line, "#") {
+				slots = append(slots, line)
+				break
+			}
+		}
+	}
+	if len(slots) != 16 {
+		t.Fatalf("expected a line per slot, got %d:\n%s", len(slots), plain)
+	}
+	if !strings.Contains(plain, "DPS Lc") || !strings.Contains(slots[1], ":1") {
+		t.Errorf("slots should be annotated with Lc and WCAG ratio:\n%s", plain)
+	}
+	if strings.Contains(plain, "✗") {
+		t.Errorf("a generated palette meets its targets:\n%s", plain)
+	}
+}
+
+func TestRenderPreviewMarksLowContrast(t *testing.T) {
+	opts := PaletteOptions{UseDPS: true}
+	colors := GeneratePalette("#625690", opts)
+	colors[1] = "#2a1a1a"
+
+	plain := ansiEscape.ReplaceAllString(RenderPreview(colors, opts), "")
+	for _, line := range strings.Split(plain, "\n") {
+		if strings.Contains(line, "#2a1a1a") {
+			if !strings.Contains(line, "✗ needs DPS Lc 40.0") {
+				t.Errorf("red should be marked as failing: %q", line)
+			}
+			return
+		}
+	}
+	t.Fatalf("red missing from preview:\n%s", plain)
+}
+
+func TestRenderPreviewWCAGShowsAPCALc(t *testing.T) {
+	opts := PaletteOptions{}
+	plain := ansiEscape.ReplaceAllString(RenderPreview(GeneratePalette("#625690", opts), opts), "")
+	if !strings.Contains(plain, "APCA Lc") {
+		t.Errorf("WCAG palettes should still show an Lc:\n%s", plain)
+	}
+}
@@ -0,0 +1,286 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QtThemeName names the Kvantum theme and the qt5ct/qt6ct color scheme
+const QtThemeName = "Dank16"
+
+// QtTheme holds the files that skin Qt apps: a qt5ct/qt6ct color scheme
+// (colors/Dank16.conf) and a flat Kvantum theme (Kvantum/Dank16/)
+type QtTheme struct {
+	ColorScheme string
+	KvConfig    string
+	KvSVG       string
+}
+
+// GenerateQtTheme builds the Qt color scheme and Kvantum theme from the
+// palette, using the same surfaces as the GTK theme
+func GenerateQtTheme(colors []string, isLight bool) QtTheme {
+	u := deriveUIColors(colors, isLight)
+	return QtTheme{
+		ColorScheme: qtColorScheme(u, colors),
+		KvConfig:    kvantumConfig(u, colors, isLight),
+		KvSVG:       kvantumSVG(u),
+	}
+}
+
+// qtColorScheme writes the QPalette roles in the order qt5ct and qt6ct
+// expect, as #aarrggbb
+func qtColorScheme(u uiColors, colors []string) string {
+	roles := func(text, highlight string) []string {
+		return []string{
+			text,                      // WindowText
+			u.raised,                  // Button
+			Mix(u.raised, u.fg, 0.15), // Light
+			Mix(u.raised, u.fg, 0.08), // Midlight
+			Mix(u.bg, "#000000", 0.4), // Dark
+			Mix(u.bg, u.fg, 0.1),      // Mid
+			text,                      // Text
+			colors[15],                // BrightText
+			text,                      // ButtonText
+			u.view,                    // Base
+			u.bg,                      // Window
+			"#000000",                 // Shadow
+			highlight,                 // Highlight
+			u.onAccent,                // HighlightedText
+			u.accentText,              // Link
+			colors[5],                 // LinkVisited
+			Mix(u.view, u.fg, 0.03),   // AlternateBase
+			u.bg,                      // NoRole
+			u.raised,                  // ToolTipBase
+			u.fg,                      // ToolTipText
+			u.disabledFg,              // PlaceholderText
+		}
+	}
+
+	line := func(values []string) string {
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = "#ff" + strings.TrimPrefix(v, "#")
+		}
+		return strings.Join(out, ", ")
+	}
+
+	var result strings.Builder
+	result.WriteString("[ColorScheme]\n")
+	fmt.Fprintf(&result, "active_colors=%s\n", line(roles(u.fg, u.accent)))
+	fmt.Fprintf(&result, "disabled_colors=%s\n", line(roles(u.disabledFg, Mix(u.accent, u.bg, 0.5))))
+	fmt.Fprintf(&result, "inactive_colors=%s\n", line(roles(u.fg, Mix(u.accent, u.bg, 0.3))))
+	return result.String()
+}
+
+// kvElement is a flat SVG element with its state variants. States left
+// empty are not drawn, which Kvantum treats as transparent.
+type kvElement struct {
+	name                                        string
+	normal, focused, pressed, toggled, disabled string
+}
+
+func kvElements(u uiColors) []kvElement {
+	hover := func(c string) string { return Mix(c, u.fg, 0.08) }
+	return []kvElement{
+		{"button", u.raised, hover(u.raised), Mix(u.raised, u.accent, 0.35), Mix(u.raised, u.accent, 0.5), Mix(u.raised, u.bg, 0.5)},
+		{"toolbutton", "", hover(u.bg), Mix(u.bg, u.accent, 0.35), Mix(u.bg, u.accent, 0.5), ""},
+		{"lineedit", u.view, u.view, u.view, u.view, u.bg},
+		{"tab", u.bg, hover(u.bg), u.raised, u.raised, u.bg},
+		{"tabframe", u.raised, "", "", "", ""},
+		{"menu", u.raised, "", "", "", ""},
+		{"menuitem", "", u.accent, u.accent, "", ""},
+		{"menubaritem", "", hover(u.bg), u.accent, u.accent, ""},
+		{"itemview", "", hover(u.view), u.accent, u.accent, ""},
+		{"scrollbarslider", Mix(u.bg, u.fg, 0.25), Mix(u.bg, u.fg, 0.4), u.accent, "", Mix(u.bg, u.fg, 0.1)},
+		{"progress-groove", Mix(u.bg, u.fg, 0.1), "", "", "", ""},
+		{"progress", u.accent, "", "", "", Mix(u.accent, u.bg, 0.5)},
+		{"slider", Mix(u.bg, u.fg, 0.15), "", "", "", ""},
+		{"slidercursor", u.accent, u.accentText, u.accentText, "", u.disabledFg},
+		{"tooltip", u.raised, "", "", "", ""},
+	}
+}
+
+func kvantumSVG(u uiColors) string {
+	const size = 20
+
+	var body strings.Builder
+	y := 0
+	for _, e := range kvElements(u) {
+		states := []struct{ name, fill string }{
+			{"normal", e.normal},
+			{"focused", e.focused},
+			{"pressed", e.pressed},
+			{"toggled", e.toggled},
+			{"disabled", e.disabled},
+		}
+		x := 0
+		for _, s := range states {
+			if s.fill != "" {
+				fmt.Fprintf(&body, "  <rect id=\"%s-%s\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+					e.name, s.name, x, y, size, size, s.fill)
+			}
+			x += size + 4
+		}
+		y += size + 4
+	}
+
+	// Indicators keep their size, so unlike the stretched interiors they
+	// can have rounded corners
+	for _, kind := range []string{"checkbox", "radio"} {
+		radius := 3
+		if kind == "radio" {
+			radius = 8
+		}
+		x := 0
+		for _, state := range []string{"normal", "focused", "pressed", "disabled"} {
+			box := u.view
+			border := u.border
+			if state == "focused" || state == "pressed" {
+				border = u.accent
+			}
+			check := u.accent
+			mark := u.onAccent
+			if state == "disabled" {
+				box = u.bg
+				check = Mix(u.accent, u.bg, 0.5)
+			}
+
+			fmt.Fprintf(&body, "  <g id=\"%s-%s\">\n", kind, state)
+			fmt.Fprintf(&body, "    <rect x=\"%d.5\" y=\"%d.5\" width=\"15\" height=\"15\" rx=\"%d\" fill=\"%s\" stroke=\"%s\"/>\n", x, y, radius, box, border)
+			body.WriteString("  </g>\n")
+
+			fmt.Fprintf(&body, "  <g id=\"%s-checked-%s\">\n", kind, state)
+			fmt.Fprintf(&body, "    <rect x=\"%d\" y=\"%d\" width=\"16\" height=\"16\" rx=\"%d\" fill=\"%s\"/>\n", x, y+size, radius, check)
+			if kind == "radio" {
+				fmt.Fprintf(&body, "    <circle cx=\"%d\" cy=\"%d\" r=\"3\" fill=\"%s\"/>\n", x+8, y+size+8, mark)
+			} else {
+				fmt.Fprintf(&body, "    <path d=\"M %d %d l 3 3 l 6 -7\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\"/>\n", x+4, y+size+8, mark)
+			}
+			body.WriteString("  </g>\n")
+
+			if kind == "checkbox" {
+				fmt.Fprintf(&body, "  <g id=\"checkbox-tristate-%s\">\n", state)
+				fmt.Fprintf(&body, "    <rect x=\"%d\" y=\"%d\" width=\"16\" height=\"16\" rx=\"%d\" fill=\"%s\"/>\n", x, y+2*size, radius, check)
+				fmt.Fprintf(&body, "    <rect x=\"%d\" y=\"%d\" width=\"8\" height=\"2\" fill=\"%s\"/>\n", x+4, y+2*size+7, mark)
+				body.WriteString("  </g>\n")
+			}
+			x += size + 4
+		}
+		y += 3*size + 4
+	}
+
+	return fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n%s</svg>\n", 5*(size+4), y, body.String())
+}
+
+func kvantumConfig(u uiColors, colors []string, isLight bool) string {
+	var result strings.Builder
+
+	fmt.Fprintf(&result, `[%%General]
+author=dms dank16
+comment=Flat theme generated from a dank16 palette
+x11drag=menubar_and_primary_toolbar
+alt_mnemonic=true
+composite=true
+animate_states=false
+attach_active_tab=true
+spread_progressbar=true
+menu_shadow_depth=0
+tooltip_shadow_depth=0
+scroll_width=8
+scroll_min_extent=36
+slider_width=4
+slider_handle_width=16
+slider_handle_length=16
+tree_branch_line=false
+button_contents_shift=false
+transient_scrollbar=false
+translucent_windows=false
+respect_DE=true
+dark_titlebar=%t
+
+[GeneralColors]
+window.color=%s
+base.color=%s
+alt.base.color=%s
+button.color=%s
+light.color=%s
+mid.light.color=%s
+dark.color=%s
+mid.color=%s
+highlight.color=%s
+inactive.highlight.color=%s
+text.color=%s
+window.text.color=%s
+button.text.color=%s
+disabled.text.color=%s
+tooltip.text.color=%s
+highlight.text.color=%s
+link.color=%s
+link.visited.color=%s
+progress.indicator.text.color=%s
+
+[Hacks]
+respect_darkness=true
+normal_default_pushbutton=true
+transparent_dolphin_view=false
+blur_translucent=false
+`,
+		!isLight,
+		u.bg, u.view, Mix(u.view, u.fg, 0.03), u.raised,
+		Mix(u.raised, u.fg, 0.15), Mix(u.raised, u.fg, 0.08), Mix(u.bg, "#000000", 0.4), Mix(u.bg, u.fg, 0.1),
+		u.accent, Mix(u.accent, u.bg, 0.3),
+		u.fg, u.fg, u.fg, u.disabledFg, u.fg, u.onAccent,
+		u.accentText, colors[5], u.onAccent)
+
+	sections := []struct {
+		name  string
+		lines []string
+	}{
+		{"PanelButtonCommand", []string{
+			"frame=false", "interior=true", "interior.element=button",
+			"text.margin.left=8", "text.margin.right=8", "text.margin.top=4", "text.margin.bottom=4",
+			"text.normal.color=" + u.fg, "text.focus.color=" + u.fg,
+			"text.press.color=" + u.fg, "text.toggle.color=" + u.fg,
+		}},
+		{"PanelButtonTool", []string{"inherits=PanelButtonCommand"}},
+		{"ComboBox", []string{"inherits=PanelButtonCommand"}},
+		{"ToolbarButton", []string{"inherits=PanelButtonCommand", "interior.element=toolbutton"}},
+		{"LineEdit", []string{"frame=false", "interior=true", "interior.element=lineedit",
+			"text.margin.left=4", "text.margin.right=4"}},
+		{"TabFrame", []string{"frame=false", "interior=true", "interior.element=tabframe"}},
+		{"Tab", []string{"frame=false", "interior=true", "interior.element=tab",
+			"text.margin.left=8", "text.margin.right=8", "text.margin.top=4", "text.margin.bottom=4",
+			"text.normal.color=" + u.disabledFg, "text.focus.color=" + u.fg, "text.toggle.color=" + u.fg}},
+		{"Menu", []string{"frame=false", "interior=true", "interior.element=menu"}},
+		{"MenuItem", []string{"frame=false", "interior=true", "interior.element=menuitem",
+			"text.margin.left=6", "text.margin.right=6", "text.margin.top=3", "text.margin.bottom=3",
+			"text.normal.color=" + u.fg, "text.focus.color=" + u.onAccent}},
+		{"MenuBarItem", []string{"frame=false", "interior=true", "interior.element=menubaritem",
+			"text.margin.left=6", "text.margin.right=6",
+			"text.normal.color=" + u.fg, "text.focus.color=" + u.fg,
+			"text.press.color=" + u.onAccent, "text.toggle.color=" + u.onAccent}},
+		{"ItemView", []string{"frame=false", "interior=true", "interior.element=itemview",
+			"text.normal.color=" + u.fg, "text.focus.color=" + u.fg,
+			"text.press.color=" + u.onAccent, "text.toggle.color=" + u.onAccent}},
+		{"ScrollbarGroove", []string{"frame=false", "interior=false"}},
+		{"ScrollbarSlider", []string{"frame=false", "interior=true", "interior.element=scrollbarslider"}},
+		{"Progressbar", []string{"frame=false", "interior=true", "interior.element=progress-groove"}},
+		{"ProgressbarContents", []string{"frame=false", "interior=true", "interior.element=progress"}},
+		{"Slider", []string{"frame=false", "interior=true", "interior.element=slider"}},
+		{"SliderCursor", []string{"frame=false", "interior=true", "interior.element=slidercursor"}},
+		{"ToolTip", []string{"frame=false", "interior=true", "interior.element=tooltip",
+			"text.normal.color=" + u.fg}},
+		{"CheckBox", []string{"frame=false", "interior=false", "indicator.element=checkbox", "indicator.size=16",
+			"text.normal.color=" + u.fg, "text.focus.color=" + u.fg}},
+		{"RadioButton", []string{"inherits=CheckBox", "indicator.element=radio"}},
+	}
+
+	for _, section := range sections {
+		fmt.Fprintf(&result, "\n[%s]\n", section.name)
+		for _, line := range section.lines {
+			result.WriteString(line)
+			result.WriteString("\n")
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,52 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateQtTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	theme := GenerateQtTheme(colors, false)
+
+	lines := strings.Split(strings.TrimSpace(theme.ColorScheme), "\n")
+	if len(lines) != 4 || lines[0] != "[ColorScheme]" {
+		t.Fatalf("unexpected color scheme:\n%s", theme.ColorScheme)
+	}
+	for _, line := range lines[1:] {
+		_, roles, _ := strings.Cut(line, "=")
+		entries := strings.Split(roles, ", ")
+		if len(entries) != 21 {
+			t.Errorf("%d roles in %q, want 21", len(entries), line)
+		}
+		for _, e := range entries {
+			if len(e) != 9 || !strings.HasPrefix(e, "#ff") {
+				t.Errorf("malformed color %q", e)
+			}
+		}
+	}
+	if !strings.HasPrefix(lines[1], "active_colors=#ff"+strings.TrimPrefix(colors[7], "#")) {
+		t.Errorf("WindowText should be the foreground: %s", lines[1])
+	}
+
+	for _, want := range []string{
+		"[%General]\n",
+		"[GeneralColors]\nwindow.color=" + colors[0] + "\n",
+		"highlight.color=" + colors[4] + "\n",
+		"[PanelButtonCommand]\n",
+		"[CheckBox]\n",
+	} {
+		if !strings.Contains(theme.KvConfig, want) {
+			t.Errorf("missing %q in kvconfig", want)
+		}
+	}
+
+	for _, id := range []string{"button-normal", "button-pressed", "checkbox-checked-normal", "radio-checked-disabled"} {
+		if !strings.Contains(theme.KvSVG, `id="`+id+`"`) {
+			t.Errorf("missing element %s in svg", id)
+		}
+	}
+	if strings.Contains(theme.KvSVG, `id="toolbutton-normal"`) {
+		t.Error("toolbuttons should stay flat when idle")
+	}
+}
@@ -0,0 +1,67 @@
+package dank16
+
+import (
+	"math/rand"
+)
+
+// Bounds of the random seed color, in OKLCH. The lightness and chroma keep
+// it a clear mid-tone accent; the excluded hues are the yellows and olives
+// that turn muddy once GeneratePalette darkens them into containers.
+const (
+	randomMinLightness = 0.58
+	randomMaxLightness = 0.72
+	randomMinChroma    = 0.09
+	randomMaxChroma    = 0.16
+	muddyHueStart      = 75.0
+	muddyHueEnd        = 120.0
+)
+
+// randomAttempts bounds the candidates RandomPrimary draws before settling
+// for the last one
+const randomAttempts = 64
+
+// RandomPrimary picks a seed color from seed: an OKLCH hue outside the
+// muddy yellows, with lightness and chroma in the ranges that give a
+// coherent palette. Candidates whose dark or light palette fails the lint,
+// which happens when the accent lands on the hue of red, green or yellow,
+// are drawn again. The same seed always gives the same color.
+func RandomPrimary(seed int64) string {
+	r := rand.New(rand.NewSource(seed))
+
+	var hex string
+	for i := 0; i < randomAttempts; i++ {
+		hex = randomCandidate(r)
+		if randomCandidateLints(hex) {
+			break
+		}
+	}
+	return hex
+}
+
+func randomCandidate(r *rand.Rand) string {
+	// Draw from the allowed arc directly, so every hue there is equally
+	// likely
+	hue := r.Float64() * (360 - (muddyHueEnd - muddyHueStart))
+	if hue >= muddyHueStart {
+		hue += muddyHueEnd - muddyHueStart
+	}
+	l := randomMinLightness + r.Float64()*(randomMaxLightness-randomMinLightness)
+	c := randomMinChroma + r.Float64()*(randomMaxChroma-randomMinChroma)
+	return okLchToHex(l, c, hue)
+}
+
+func randomCandidateLints(hex string) bool {
+	for _, isLight := range []bool{false, true} {
+		opts := PaletteOptions{IsLight: isLight, UseDPS: true}
+		if HasErrors(ValidatePalette(GeneratePalette(hex, opts), opts)) {
+			return false
+		}
+	}
+	return true
+}
+
+// GenerateRandomPalette is GeneratePalette seeded with RandomPrimary(seed),
+// for a fresh theme that can be reproduced from the seed alone
+func GenerateRandomPalette(seed int64, opts PaletteOptions) []string {
+	return GeneratePalette(RandomPrimary(seed), opts)
+}
@@ -0,0 +1,45 @@
+package dank16
+
+import "testing"
+
+func TestRandomPrimary(t *testing.T) {
+	if RandomPrimary(42) != RandomPrimary(42) {
+		t.Error("the same seed should give the same color")
+	}
+
+	distinct := make(map[string]bool)
+	for seed := int64(0); seed < 200; seed++ {
+		hex := RandomPrimary(seed)
+		distinct[hex] = true
+
+		l, c, h := hexToColorful(hex).OkLch()
+		if h >= muddyHueStart+1 && h < muddyHueEnd-1 {
+			t.Errorf("seed %d: %s has the muddy hue %.1f", seed, hex, h)
+		}
+		// Chroma may only drop, where the hue cannot reach it in sRGB
+		if c > randomMaxChroma+0.01 {
+			t.Errorf("seed %d: %s has chroma %.3f", seed, hex, c)
+		}
+		if l < randomMinLightness-0.01 || l > randomMaxLightness+0.01 {
+			t.Errorf("seed %d: %s has lightness %.3f", seed, hex, l)
+		}
+	}
+	if len(distinct) < 190 {
+		t.Errorf("only %d distinct colors from 200 seeds", len(distinct))
+	}
+}
+
+func TestGenerateRandomPaletteLints(t *testing.T) {
+	for _, isLight := range []bool{false, true} {
+		opts := PaletteOptions{IsLight: isLight, UseDPS: true}
+		for seed := int64(1); seed <= 50; seed++ {
+			colors := GenerateRandomPalette(seed, opts)
+			if len(colors) != 16 {
+				t.Fatalf("seed %d: %d colors", seed, len(colors))
+			}
+			if diags := ValidatePalette(colors, opts); HasErrors(diags) {
+				t.Errorf("light=%v seed %d (%s): %+v", isLight, seed, RandomPrimary(seed), diags)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package dank16
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var paletteHexPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// RegenerateSlot returns a copy of palette with only slot index replaced by
+// the color GeneratePalette would put there, so manual tweaks to the other
+// 15 slots survive fixing one. The new color is fitted against the locked
+// background (slot 0). Regenerating the background itself uses
+// opts.Background, or the default, and leaves the other slots untouched.
+//
+// The seed is recovered from the palette: slot 6 holds the seed adjusted
+// for contrast unless HonorTertiary replaced it, in which case HonorPrimary
+// or the blue slot stands in.
+func RegenerateSlot(palette []string, index int, opts PaletteOptions) ([]string, error) {
+	if len(palette) != 16 {
+		return nil, fmt.Errorf("palette has %d colors, want 16", len(palette))
+	}
+	if index < 0 || index > 15 {
+		return nil, fmt.Errorf("slot %d out of range 0-15", index)
+	}
+	for i, c := range palette {
+		if !paletteHexPattern.MatchString(c) {
+			return nil, fmt.Errorf("slot %d: invalid color %q", i, c)
+		}
+	}
+
+	if index != 0 {
+		opts.Background = palette[0]
+	}
+	fresh := GeneratePalette(paletteSeed(palette, opts), opts)
+
+	result := make([]string, 16)
+	copy(result, palette)
+	result[index] = fresh[index]
+	return result, nil
+}
+
+func paletteSeed(palette []string, opts PaletteOptions) string {
+	switch {
+	case opts.HonorTertiary == "":
+		return strings.ToLower(palette[6])
+	case opts.HonorPrimary != "":
+		return opts.HonorPrimary
+	default:
+		return strings.ToLower(palette[4])
+	}
+}
@@ -0,0 +1,79 @@
+package dank16
+
+import "testing"
+
+func TestRegenerateSlotKeepsLockedSlots(t *testing.T) {
+	opts := PaletteOptions{IsLight: false, UseDPS: true}
+	palette := GeneratePalette("#625690", opts)
+
+	tweaked := make([]string, 16)
+	copy(tweaked, palette)
+	tweaked[1] = "#ff0000"
+	tweaked[3] = "#808000" // the ugly yellow
+
+	result, err := RegenerateSlot(tweaked, 3, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range result {
+		switch i {
+		case 3:
+			if result[i] == "#808000" {
+				t.Error("slot 3 was not regenerated")
+			}
+			if got := DeltaPhiStarContrast(result[i], result[0], false); got < 40 {
+				t.Errorf("regenerated yellow has contrast %.1f, want >= 40", got)
+			}
+		default:
+			if result[i] != tweaked[i] {
+				t.Errorf("slot %d changed from %s to %s", i, tweaked[i], result[i])
+			}
+		}
+	}
+
+	if tweaked[3] != "#808000" {
+		t.Error("input palette was modified")
+	}
+}
+
+func TestRegenerateSlotRestoresGeneratedColor(t *testing.T) {
+	for _, isLight := range []bool{false, true} {
+		opts := PaletteOptions{IsLight: isLight, UseDPS: true}
+		palette := GeneratePalette("#625690", opts)
+
+		for _, index := range []int{1, 2, 3, 7, 8, 9, 10, 11, 15} {
+			tweaked := make([]string, 16)
+			copy(tweaked, palette)
+			tweaked[index] = "#000001"
+
+			result, err := RegenerateSlot(tweaked, index, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The seed is recovered from a contrast-adjusted slot, so allow
+			// a rounding step of difference
+			if d := hexToColorful(result[index]).DistanceCIEDE2000(hexToColorful(palette[index])); d > 0.01 {
+				t.Errorf("light=%v slot %d: got %s, want %s (ΔE %.3f)", isLight, index, result[index], palette[index], d)
+			}
+		}
+	}
+}
+
+func TestRegenerateSlotErrors(t *testing.T) {
+	palette := GeneratePalette("#625690", PaletteOptions{})
+
+	if _, err := RegenerateSlot(palette[:15], 3, PaletteOptions{}); err == nil {
+		t.Error("expected error for short palette")
+	}
+	if _, err := RegenerateSlot(palette, 16, PaletteOptions{}); err == nil {
+		t.Error("expected error for slot 16")
+	}
+
+	bad := make([]string, 16)
+	copy(bad, palette)
+	bad[5] = "purple"
+	if _, err := RegenerateSlot(bad, 3, PaletteOptions{}); err == nil {
+		t.Error("expected error for invalid color")
+	}
+}
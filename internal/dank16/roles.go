@@ -0,0 +1,122 @@
+package dank16
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// PaletteColor is one palette color in hex and 8-bit RGB
+type PaletteColor struct {
+	Hex string `json:"hex"`
+	RGB RGB8   `json:"rgb"`
+}
+
+type RGB8 struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+}
+
+func newPaletteColor(hex string) PaletteColor {
+	rgb := HexToRGB(hex)
+	to8 := func(c float64) uint8 { return uint8(math.Round(c * 255)) }
+	return PaletteColor{Hex: hex, RGB: RGB8{R: to8(rgb.R), G: to8(rgb.G), B: to8(rgb.B)}}
+}
+
+// NamedPalette names every palette slot by its role, so consumers do not
+// depend on the slot order
+type NamedPalette struct {
+	Mode string `json:"mode"`
+
+	Background PaletteColor `json:"background"`
+	Foreground PaletteColor `json:"foreground"`
+	Comment    PaletteColor `json:"comment"`
+
+	Red     PaletteColor `json:"red"`
+	Green   PaletteColor `json:"green"`
+	Yellow  PaletteColor `json:"yellow"`
+	Blue    PaletteColor `json:"blue"`
+	Magenta PaletteColor `json:"magenta"`
+	Cyan    PaletteColor `json:"cyan"`
+
+	BrightRed     PaletteColor `json:"brightRed"`
+	BrightGreen   PaletteColor `json:"brightGreen"`
+	BrightYellow  PaletteColor `json:"brightYellow"`
+	BrightBlue    PaletteColor `json:"brightBlue"`
+	BrightMagenta PaletteColor `json:"brightMagenta"`
+	BrightCyan    PaletteColor `json:"brightCyan"`
+	BrightWhite   PaletteColor `json:"brightWhite"`
+
+	// Accent is AccentColor, the accent every output shares
+	Accent     PaletteColor `json:"accent"`
+	AccentText PaletteColor `json:"accentText"`
+	OnAccent   PaletteColor `json:"onAccent"`
+	Surface    PaletteColor `json:"surface"`
+	Border     PaletteColor `json:"border"`
+
+	// Surfaces are the Material 3 surface roles around Background, so the
+	// shell needs no tone math of its own
+	Surfaces SurfaceRamp `json:"surfaces"`
+}
+
+// NamePalette assigns roles to a generated palette
+func NamePalette(colors []string, isLight bool) NamedPalette {
+	u := deriveUIColors(colors, isLight)
+
+	mode := "dark"
+	if isLight {
+		mode = "light"
+	}
+
+	return NamedPalette{
+		Mode: mode,
+
+		Background: newPaletteColor(colors[0]),
+		Foreground: newPaletteColor(colors[7]),
+		Comment:    newPaletteColor(colors[8]),
+
+		Red:     newPaletteColor(colors[1]),
+		Green:   newPaletteColor(colors[2]),
+		Yellow:  newPaletteColor(colors[3]),
+		Blue:    newPaletteColor(colors[4]),
+		Magenta: newPaletteColor(colors[5]),
+		Cyan:    newPaletteColor(colors[6]),
+
+		BrightRed:     newPaletteColor(colors[9]),
+		BrightGreen:   newPaletteColor(colors[10]),
+		BrightYellow:  newPaletteColor(colors[11]),
+		BrightBlue:    newPaletteColor(colors[12]),
+		BrightMagenta: newPaletteColor(colors[13]),
+		BrightCyan:    newPaletteColor(colors[14]),
+		BrightWhite:   newPaletteColor(colors[15]),
+
+		Accent:     newPaletteColor(u.accent),
+		AccentText: newPaletteColor(u.accentText),
+		OnAccent:   newPaletteColor(u.onAccent),
+		Surface:    newPaletteColor(u.raised),
+		Border:     newPaletteColor(u.border),
+
+		Surfaces: GenerateSurfaceRamp(colors[0], isLight, DefaultSurfaceSteps),
+	}
+}
+
+// GenerateJSON emits the palette as a color0..color15 map
+func GenerateJSON(colors []string) string {
+	colorMap := make(map[string]string)
+
+	for i, color := range colors {
+		colorMap[fmt.Sprintf("color%d", i)] = color
+	}
+
+	marshalled, _ := json.Marshal(colorMap)
+
+	return string(marshalled)
+}
+
+// GenerateRolesJSON emits the palette as a NamedPalette. It is separate from
+// GenerateJSON so readers of the color0..color15 map keep working.
+func GenerateRolesJSON(colors []string, isLight bool) string {
+	marshalled, _ := json.MarshalIndent(NamePalette(colors, isLight), "", "  ")
+	return string(marshalled) + "\n"
+}
@@ -0,0 +1,67 @@
+package dank16
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewPaletteColor(t *testing.T) {
+	c := newPaletteColor("#ff8001")
+	if c.Hex != "#ff8001" || c.RGB != (RGB8{R: 255, G: 128, B: 1}) {
+		t.Errorf("newPaletteColor = %+v", c)
+	}
+}
+
+func TestGenerateRolesJSON(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+
+	var named NamedPalette
+	if err := json.Unmarshal([]byte(GenerateRolesJSON(colors, false)), &named); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	for _, tt := range []struct {
+		role string
+		got  PaletteColor
+		slot int
+	}{
+		{"background", named.Background, 0},
+		{"red", named.Red, 1},
+		{"foreground", named.Foreground, 7},
+		{"comment", named.Comment, 8},
+		{"brightRed", named.BrightRed, 9},
+		{"brightBlue", named.BrightBlue, 12},
+		{"brightWhite", named.BrightWhite, 15},
+		{"accent", named.Accent, 4},
+	} {
+		if tt.got != newPaletteColor(colors[tt.slot]) {
+			t.Errorf("%s = %+v, want color%d %s", tt.role, tt.got, tt.slot, colors[tt.slot])
+		}
+	}
+	if named.Mode != "dark" {
+		t.Errorf("mode = %q, want dark", named.Mode)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(GenerateRolesJSON(colors, false)), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw["brightRed"].(map[string]interface{})["rgb"]; !ok {
+		t.Errorf("brightRed has no rgb field: %v", raw["brightRed"])
+	}
+}
+
+func TestGenerateJSONKeepsSlotMap(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+
+	var slots map[string]string
+	if err := json.Unmarshal([]byte(GenerateJSON(colors)), &slots); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(slots) != 16 {
+		t.Fatalf("got %d keys, want 16", len(slots))
+	}
+	if slots["color0"] != colors[0] || slots["color15"] != colors[15] {
+		t.Errorf("color0 = %s, color15 = %s, want %s and %s", slots["color0"], slots["color15"], colors[0], colors[15])
+	}
+}
@@ -0,0 +1,130 @@
+package dank16
+
+import (
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Scheme is a well-known terminal color scheme. Colors follow the dank16
+// layout where color0 is the background, so palettes can be compared and
+// swapped directly.
+type Scheme struct {
+	Name    string
+	IsLight bool
+	Colors  []string
+}
+
+type SchemeMatch struct {
+	Scheme   Scheme
+	Distance float64
+}
+
+var Schemes = []Scheme{
+	{
+		Name: "Catppuccin Mocha",
+		Colors: []string{
+			"#1e1e2e", "#f38ba8", "#a6e3a1", "#f9e2af", "#89b4fa", "#f5c2e7", "#94e2d5", "#bac2de",
+			"#585b70", "#f38ba8", "#a6e3a1", "#f9e2af", "#89b4fa", "#f5c2e7", "#94e2d5", "#a6adc8",
+		},
+	},
+	{
+		Name:    "Catppuccin Latte",
+		IsLight: true,
+		Colors: []string{
+			"#eff1f5", "#d20f39", "#40a02b", "#df8e1d", "#1e66f5", "#ea76cb", "#179299", "#acb0be",
+			"#6c6f85", "#d20f39", "#40a02b", "#df8e1d", "#1e66f5", "#ea76cb", "#179299", "#bcc0cc",
+		},
+	},
+	{
+		Name: "Gruvbox Dark",
+		Colors: []string{
+			"#282828", "#cc241d", "#98971a", "#d79921", "#458588", "#b16286", "#689d6a", "#a89984",
+			"#928374", "#fb4934", "#b8bb26", "#fabd2f", "#83a598", "#d3869b", "#8ec07c", "#ebdbb2",
+		},
+	},
+	{
+		Name:    "Gruvbox Light",
+		IsLight: true,
+		Colors: []string{
+			"#fbf1c7", "#cc241d", "#98971a", "#d79921", "#458588", "#b16286", "#689d6a", "#7c6f64",
+			"#928374", "#9d0006", "#79740e", "#b57614", "#076678", "#8f3f71", "#427b58", "#3c3836",
+		},
+	},
+	{
+		Name: "Nord",
+		Colors: []string{
+			"#2e3440", "#bf616a", "#a3be8c", "#ebcb8b", "#81a1c1", "#b48ead", "#88c0d0", "#e5e9f0",
+			"#4c566a", "#bf616a", "#a3be8c", "#ebcb8b", "#81a1c1", "#b48ead", "#8fbcbb", "#eceff4",
+		},
+	},
+	{
+		Name: "Solarized Dark",
+		Colors: []string{
+			"#002b36", "#dc322f", "#859900", "#b58900", "#268bd2", "#d33682", "#2aa198", "#93a1a1",
+			"#586e75", "#cb4b16", "#859900", "#b58900", "#268bd2", "#6c71c4", "#2aa198", "#eee8d5",
+		},
+	},
+	{
+		Name:    "Solarized Light",
+		IsLight: true,
+		Colors: []string{
+			"#fdf6e3", "#dc322f", "#859900", "#b58900", "#268bd2", "#d33682", "#2aa198", "#657b83",
+			"#93a1a1", "#cb4b16", "#859900", "#b58900", "#268bd2", "#6c71c4", "#2aa198", "#073642",
+		},
+	},
+	{
+		Name: "Tokyo Night",
+		Colors: []string{
+			"#1a1b26", "#f7768e", "#9ece6a", "#e0af68", "#7aa2f7", "#bb9af7", "#7dcfff", "#a9b1d6",
+			"#414868", "#f7768e", "#9ece6a", "#e0af68", "#7aa2f7", "#bb9af7", "#7dcfff", "#c0caf5",
+		},
+	},
+	{
+		Name:    "Tokyo Night Day",
+		IsLight: true,
+		Colors: []string{
+			"#e1e2e7", "#f52a65", "#587539", "#8c6c3e", "#2e7de9", "#9854f1", "#007197", "#6172b0",
+			"#a1a6c5", "#f52a65", "#587539", "#8c6c3e", "#2e7de9", "#9854f1", "#007197", "#3760bf",
+		},
+	},
+}
+
+func hexToColorful(hex string) colorful.Color {
+	rgb := HexToRGB(hex)
+	return colorful.Color{R: rgb.R, G: rgb.G, B: rgb.B}
+}
+
+// PaletteDistance is the mean CIEDE2000 ΔE between matching palette slots,
+// scaled to the usual 0-100 range.
+func PaletteDistance(a, b []string) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if n == 0 {
+		return 0
+	}
+
+	var total float64
+	for i := 0; i < n; i++ {
+		total += hexToColorful(a[i]).DistanceCIEDE2000(hexToColorful(b[i])) * 100
+	}
+	return total / float64(n)
+}
+
+// NearestSchemes ranks bundled schemes of the same polarity by distance to palette
+func NearestSchemes(palette []string, isLight bool) []SchemeMatch {
+	var matches []SchemeMatch
+	for _, s := range Schemes {
+		if s.IsLight != isLight {
+			continue
+		}
+		matches = append(matches, SchemeMatch{Scheme: s, Distance: PaletteDistance(palette, s.Colors)})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Distance < matches[j].Distance
+	})
+	return matches
+}
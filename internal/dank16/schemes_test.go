@@ -0,0 +1,63 @@
+package dank16
+
+import (
+	"testing"
+)
+
+func TestSchemesWellFormed(t *testing.T) {
+	for _, s := range Schemes {
+		if len(s.Colors) != 16 {
+			t.Errorf("%s has %d colors, expected 16", s.Name, len(s.Colors))
+		}
+		for _, c := range s.Colors {
+			if len(c) != 7 || c[0] != '#' {
+				t.Errorf("%s has malformed color %q", s.Name, c)
+			}
+		}
+	}
+}
+
+func TestPaletteDistance(t *testing.T) {
+	a := []string{"#000000", "#ff0000"}
+
+	if d := PaletteDistance(a, a); d != 0 {
+		t.Errorf("identical palettes should have zero distance, got %f", d)
+	}
+
+	near := PaletteDistance(a, []string{"#050505", "#f00505"})
+	far := PaletteDistance(a, []string{"#ffffff", "#00ff00"})
+	if near >= far {
+		t.Errorf("expected near (%f) < far (%f)", near, far)
+	}
+
+	if d := PaletteDistance(nil, a); d != 0 {
+		t.Errorf("empty palette should have zero distance, got %f", d)
+	}
+}
+
+func TestNearestSchemes(t *testing.T) {
+	for _, s := range Schemes {
+		t.Run(s.Name, func(t *testing.T) {
+			matches := NearestSchemes(s.Colors, s.IsLight)
+			if len(matches) == 0 {
+				t.Fatal("expected matches")
+			}
+			if matches[0].Scheme.Name != s.Name {
+				t.Errorf("expected %s to match itself, got %s", s.Name, matches[0].Scheme.Name)
+			}
+			for _, m := range matches {
+				if m.Scheme.IsLight != s.IsLight {
+					t.Errorf("match %s has wrong polarity", m.Scheme.Name)
+				}
+			}
+		})
+	}
+
+	palette := GeneratePalette("#7aa2f7", PaletteOptions{IsLight: false, UseDPS: true})
+	matches := NearestSchemes(palette, false)
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Distance < matches[i-1].Distance {
+			t.Errorf("matches not sorted by distance")
+		}
+	}
+}
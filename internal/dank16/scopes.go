@@ -0,0 +1,72 @@
+package dank16
+
+// Code is colored through two tables shared by every editor exporter:
+// textMateColors for tools that highlight with TextMate grammars, and
+// semanticColors for semantic token types. An editor that names its
+// highlights differently only needs a []semanticScope mapping its names
+// onto semanticColors; the exporter then looks the colors up there.
+
+// semanticScope maps one of an editor's highlight names to a semantic token
+// type. Style is "", "italic" or "bold".
+type semanticScope struct {
+	name     string
+	semantic string
+	style    string
+}
+
+// textMateColors maps TextMate scopes to palette slots. Themes for tools that
+// highlight with TextMate grammars, such as bat, share it with VSCode.
+func textMateColors(colors []string) map[string]string {
+	return map[string]string{
+		"comment":                        colors[8],
+		"punctuation.definition.comment": colors[8],
+		"keyword":                        colors[5],
+		"storage.type":                   colors[13],
+		"storage.modifier":               colors[5],
+		"variable":                       colors[15],
+		"variable.parameter":             colors[7],
+		"meta.object-literal.key":        colors[4],
+		"meta.property.object":           colors[4],
+		"variable.other.property":        colors[4],
+		"constant.other.symbol":          colors[12],
+		"constant.numeric":               colors[12],
+		"constant.language":              colors[12],
+		"constant.character":             colors[3],
+		"entity.name.type":               colors[12],
+		"support.type":                   colors[13],
+		"entity.name.class":              colors[12],
+		"entity.name.function":           colors[2],
+		"support.function":               colors[2],
+		"support.class":                  colors[15],
+		"support.variable":               colors[15],
+		"variable.language":              colors[12],
+		"entity.name.tag.yaml":           colors[12],
+		"string.unquoted.plain.out.yaml": colors[15],
+		"string.unquoted.yaml":           colors[15],
+		"string":                         colors[3],
+	}
+}
+
+// semanticColors maps semantic token types to palette slots. Editors other
+// than VSCode read the same table through their semanticScope mappings, so
+// code is colored alike everywhere.
+func semanticColors(colors []string) map[string]string {
+	return map[string]string{
+		"variable":          colors[7],
+		"variable.readonly": colors[12],
+		"property":          colors[4],
+		"function":          colors[2],
+		"method":            colors[2],
+		"type":              colors[12],
+		"class":             colors[12],
+		"typeParameter":     colors[13],
+		"enumMember":        colors[12],
+		"string":            colors[3],
+		"number":            colors[12],
+		"comment":           colors[8],
+		"keyword":           colors[5],
+		"operator":          colors[15],
+		"parameter":         colors[7],
+		"namespace":         colors[15],
+	}
+}
@@ -0,0 +1,27 @@
+package dank16
+
+import "testing"
+
+func TestSemanticScopeMappings(t *testing.T) {
+	semantic := semanticColors(GeneratePalette("#625690", PaletteOptions{}))
+
+	for editor, mapping := range map[string][]semanticScope{
+		"zed":       zedSyntax,
+		"emacs":     emacsCategories,
+		"jetbrains": jetbrainsAttributes,
+	} {
+		seen := make(map[string]bool)
+		for _, s := range mapping {
+			if _, ok := semantic[s.semantic]; !ok {
+				t.Errorf("%s: %s maps to unknown token type %q", editor, s.name, s.semantic)
+			}
+			if s.style != "" && s.style != "italic" && s.style != "bold" {
+				t.Errorf("%s: %s has unsupported style %q", editor, s.name, s.style)
+			}
+			if seen[s.name] {
+				t.Errorf("%s: %s is mapped twice", editor, s.name)
+			}
+			seen[s.name] = true
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LSColors uses ANSI indices only, so it follows whatever palette the
+// terminal has loaded.
+const LSColors = "di=1;34:ln=36:so=35:pi=33:ex=1;32:bd=1;33:cd=1;33:su=30;41:sg=30;43:tw=30;42:ow=34;42:or=1;31:mi=1;31"
+
+func GenerateShellExports(colors []string, shell string) (string, error) {
+	var format string
+	switch shell {
+	case "bash", "zsh":
+		format = "export %s='%s'\n"
+	case "fish":
+		format = "set -gx %s '%s'\n"
+	default:
+		return "", fmt.Errorf("unsupported shell: %s", shell)
+	}
+
+	var result strings.Builder
+	for i, color := range colors {
+		fmt.Fprintf(&result, format, fmt.Sprintf("DANK16_COLOR%d", i), color)
+	}
+	fmt.Fprintf(&result, format, "DANK16_LS_COLORS", LSColors)
+	return result.String(), nil
+}
+
+// ShellThemeShells are the shells GenerateShellTheme supports
+var ShellThemeShells = []string{"bash", "fish", "zsh"}
+
+// GenerateShellTheme emits a file to source from the shell's rc file. It sets
+// LS_COLORS to the truecolor palette and colors the shell itself: fish's
+// syntax highlighting, pager and default prompt, zsh completion menus,
+// zsh-syntax-highlighting and zsh-autosuggestions, and a bash prompt.
+func GenerateShellTheme(colors []string, isLight bool, shell string) (string, error) {
+	u := deriveUIColors(colors, isLight)
+	lsColors := GenerateLSColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.45)
+
+	var b strings.Builder
+	b.WriteString("# Generated by dank16\n")
+	switch shell {
+	case "fish":
+		fmt.Fprintf(&b, "set -gx LS_COLORS '%s'\n\n", lsColors)
+		for _, c := range []struct{ name, value string }{
+			{"fish_color_normal", fishColor(u.fg)},
+			{"fish_color_command", fishColor(u.accentText)},
+			{"fish_color_keyword", fishColor(colors[5])},
+			{"fish_color_quote", fishColor(colors[2])},
+			{"fish_color_redirection", fishColor(colors[6])},
+			{"fish_color_end", fishColor(colors[3])},
+			{"fish_color_error", fishColor(colors[1])},
+			{"fish_color_param", fishColor(u.fg)},
+			{"fish_color_valid_path", "--underline"},
+			{"fish_color_option", fishColor(colors[14])},
+			{"fish_color_comment", fishColor(muted)},
+			{"fish_color_operator", fishColor(colors[6])},
+			{"fish_color_escape", fishColor(colors[13])},
+			{"fish_color_autosuggestion", fishColor(muted)},
+			{"fish_color_cancel", fishColor(colors[1])},
+			{"fish_color_selection", "--background=" + fishColor(u.raised)},
+			{"fish_color_search_match", "--background=" + fishColor(u.raised)},
+			{"fish_color_history_current", "--bold"},
+			{"fish_color_user", fishColor(colors[2])},
+			{"fish_color_host", fishColor(colors[4])},
+			{"fish_color_host_remote", fishColor(colors[3])},
+			{"fish_color_cwd", fishColor(u.accentText)},
+			{"fish_color_cwd_root", fishColor(colors[1])},
+			{"fish_color_status", fishColor(colors[1])},
+			{"fish_pager_color_progress", fishColor(muted)},
+			{"fish_pager_color_prefix", fishColor(u.accentText) + " --bold"},
+			{"fish_pager_color_completion", fishColor(u.fg)},
+			{"fish_pager_color_description", fishColor(muted)},
+			{"fish_pager_color_selected_background", "--background=" + fishColor(u.raised)},
+		} {
+			fmt.Fprintf(&b, "set -g %s %s\n", c.name, c.value)
+		}
+	case "zsh":
+		fmt.Fprintf(&b, "export LS_COLORS='%s'\n\n", lsColors)
+		b.WriteString("zstyle ':completion:*' list-colors ${(s.:.)LS_COLORS}\n")
+		fmt.Fprintf(&b, "zstyle ':completion:*:descriptions' format '%%F{%s}%%B%%d%%b%%f'\n", u.accentText)
+		fmt.Fprintf(&b, "zstyle ':completion:*:messages' format '%%F{%s}%%d%%f'\n", muted)
+		fmt.Fprintf(&b, "zstyle ':completion:*:warnings' format '%%F{%s}no matches for: %%d%%f'\n", colors[1])
+		fmt.Fprintf(&b, "zstyle ':completion:*:corrections' format '%%F{%s}%%d (errors: %%e)%%f'\n\n", colors[3])
+
+		b.WriteString("typeset -gA ZSH_HIGHLIGHT_STYLES\n")
+		for _, s := range []struct{ name, value string }{
+			{"default", "fg=" + u.fg},
+			{"unknown-token", "fg=" + colors[1]},
+			{"reserved-word", "fg=" + colors[5]},
+			{"alias", "fg=" + u.accentText},
+			{"builtin", "fg=" + u.accentText},
+			{"function", "fg=" + u.accentText},
+			{"command", "fg=" + u.accentText},
+			{"precommand", "fg=" + u.accentText + ",underline"},
+			{"commandseparator", "fg=" + colors[3]},
+			{"path", "fg=" + u.fg + ",underline"},
+			{"globbing", "fg=" + colors[13]},
+			{"single-hyphen-option", "fg=" + colors[14]},
+			{"double-hyphen-option", "fg=" + colors[14]},
+			{"single-quoted-argument", "fg=" + colors[2]},
+			{"double-quoted-argument", "fg=" + colors[2]},
+			{"dollar-quoted-argument", "fg=" + colors[2]},
+			{"back-quoted-argument", "fg=" + colors[6]},
+			{"redirection", "fg=" + colors[6]},
+			{"comment", "fg=" + muted},
+		} {
+			fmt.Fprintf(&b, "ZSH_HIGHLIGHT_STYLES[%s]='%s'\n", s.name, s.value)
+		}
+		fmt.Fprintf(&b, "ZSH_AUTOSUGGEST_HIGHLIGHT_STYLE='fg=%s'\n\n", muted)
+
+		fmt.Fprintf(&b, "PROMPT='%%F{%s}%%n%%f@%%F{%s}%%m%%f %%F{%s}%%~%%f %%(?..%%F{%s}%%? %%f)%%# '\n",
+			colors[2], colors[4], u.accentText, colors[1])
+	case "bash":
+		fmt.Fprintf(&b, "export LS_COLORS='%s'\n\n", lsColors)
+		fmt.Fprintf(&b, "PS1='\\[\\e[%sm\\]\\u\\[\\e[0m\\]@\\[\\e[%sm\\]\\h\\[\\e[0m\\] \\[\\e[%sm\\]\\w\\[\\e[0m\\] \\$ '\n",
+			sgrColor(colors[2]), sgrColor(colors[4]), sgrColor(u.accentText))
+	default:
+		return "", fmt.Errorf("unsupported shell: %s (expected %s)", shell, strings.Join(ShellThemeShells, ", "))
+	}
+	return b.String(), nil
+}
+
+// fishColor is the bare RRGGBB form fish's set_color takes
+func fishColor(hex string) string {
+	return strings.TrimPrefix(hex, "#")
+}
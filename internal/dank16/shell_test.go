@@ -0,0 +1,110 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateShellExports(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+
+	bash, err := GenerateShellExports(colors, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bash, "export DANK16_COLOR0='"+colors[0]+"'\n") {
+		t.Errorf("missing color0 export:\n%s", bash)
+	}
+	if !strings.Contains(bash, "export DANK16_COLOR15=") {
+		t.Errorf("missing color15 export:\n%s", bash)
+	}
+
+	fish, err := GenerateShellExports(colors, "fish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(fish, "set -gx DANK16_COLOR0 '") {
+		t.Errorf("unexpected fish output:\n%s", fish)
+	}
+
+	if _, err := GenerateShellExports(colors, "tcsh"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
+
+func TestGenerateLSColors(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	ls := GenerateLSColors(colors, false)
+
+	entries := make(map[string]string)
+	for _, entry := range strings.Split(ls, ":") {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			t.Fatalf("malformed entry %q", entry)
+		}
+		if _, dup := entries[key]; dup {
+			t.Errorf("duplicate key %q", key)
+		}
+		entries[key] = value
+	}
+
+	if entries["ex"] != "01;"+sgrColor(colors[2]) {
+		t.Errorf("ex = %q, want bold green", entries["ex"])
+	}
+	if entries["*.zip"] != sgrColor(colors[1]) {
+		t.Errorf("*.zip = %q, want red", entries["*.zip"])
+	}
+	for _, key := range []string{"di", "ln", "or", "*~"} {
+		if entries[key] == "" {
+			t.Errorf("missing %s", key)
+		}
+	}
+}
+
+func TestGenerateShellTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	ls := GenerateLSColors(colors, false)
+
+	fish, err := GenerateShellTheme(colors, false, "fish")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(fish, "set -gx LS_COLORS '"+ls+"'\n") {
+		t.Error("fish theme does not set LS_COLORS")
+	}
+	if !strings.Contains(fish, "set -g fish_color_error "+strings.TrimPrefix(colors[1], "#")+"\n") {
+		t.Errorf("fish_color_error should be the bare red hex:\n%s", fish)
+	}
+	for _, line := range strings.Split(fish, "\n") {
+		if strings.HasPrefix(line, "set -g fish_") && strings.Contains(line, "#") {
+			t.Errorf("fish colors take no #: %s", line)
+		}
+	}
+
+	zsh, err := GenerateShellTheme(colors, false, "zsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"export LS_COLORS='" + ls + "'\n",
+		"zstyle ':completion:*' list-colors ${(s.:.)LS_COLORS}\n",
+		"ZSH_HIGHLIGHT_STYLES[unknown-token]='fg=" + colors[1] + "'\n",
+		"PROMPT='",
+	} {
+		if !strings.Contains(zsh, want) {
+			t.Errorf("zsh theme missing %q", want)
+		}
+	}
+
+	bash, err := GenerateShellTheme(colors, false, "bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bash, `\[\e[`+sgrColor(colors[2])+`m\]\u`) {
+		t.Errorf("bash prompt should color the user green:\n%s", bash)
+	}
+
+	if _, err := GenerateShellTheme(colors, false, "tcsh"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
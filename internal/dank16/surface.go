@@ -0,0 +1,78 @@
+package dank16
+
+import (
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// DefaultSurfaceSteps is the length of SurfaceRamp.Elevation when no step
+// count is given, one tone for each Material 3 elevation level 0-5
+const DefaultSurfaceSteps = 6
+
+// SurfaceRamp is the Material 3 surface role set built around a background.
+// Each role keeps the background's hue and chroma and only moves its tone
+// (L*), by the distance M3 puts between that role and surface.
+type SurfaceRamp struct {
+	SurfaceDim              string `json:"surfaceDim"`
+	Surface                 string `json:"surface"`
+	SurfaceBright           string `json:"surfaceBright"`
+	SurfaceContainerLowest  string `json:"surfaceContainerLowest"`
+	SurfaceContainerLow     string `json:"surfaceContainerLow"`
+	SurfaceContainer        string `json:"surfaceContainer"`
+	SurfaceContainerHigh    string `json:"surfaceContainerHigh"`
+	SurfaceContainerHighest string `json:"surfaceContainerHighest"`
+	// Elevation steps evenly in tone from Surface to
+	// SurfaceContainerHighest, for shells that stack more levels than the
+	// named roles cover
+	Elevation []string `json:"elevation"`
+}
+
+// surfaceToneOffsets are the M3 tones of each role minus the tone of
+// surface: 6 in the dark scheme, 98 in the light one
+var surfaceToneOffsets = map[bool]struct {
+	dim, bright, lowest, low, container, high, highest float64
+}{
+	false: {dim: 0, bright: 18, lowest: -2, low: 4, container: 6, high: 11, highest: 16},
+	true:  {dim: -11, bright: 0, lowest: 2, low: -2, container: -4, high: -6, highest: -8},
+}
+
+// GenerateSurfaceRamp derives the surface roles from background, which
+// becomes surface itself. steps is the length of Elevation; 0 or less uses
+// DefaultSurfaceSteps.
+func GenerateSurfaceRamp(background string, isLight bool, steps int) SurfaceRamp {
+	if steps <= 0 {
+		steps = DefaultSurfaceSteps
+	}
+
+	rgb := HexToRGB(background)
+	L, a, b := colorful.Color{R: rgb.R, G: rgb.G, B: rgb.B}.Lab()
+	base := L * 100.0
+	tone := func(offset float64) string {
+		if offset == 0 {
+			return background
+		}
+		return labToHex(math.Max(0, math.Min(100, base+offset)), a, b)
+	}
+
+	o := surfaceToneOffsets[isLight]
+	ramp := SurfaceRamp{
+		SurfaceDim:              tone(o.dim),
+		Surface:                 background,
+		SurfaceBright:           tone(o.bright),
+		SurfaceContainerLowest:  tone(o.lowest),
+		SurfaceContainerLow:     tone(o.low),
+		SurfaceContainer:        tone(o.container),
+		SurfaceContainerHigh:    tone(o.high),
+		SurfaceContainerHighest: tone(o.highest),
+		Elevation:               make([]string, steps),
+	}
+	for i := range ramp.Elevation {
+		if steps == 1 {
+			ramp.Elevation[i] = background
+			break
+		}
+		ramp.Elevation[i] = tone(o.highest * float64(i) / float64(steps-1))
+	}
+	return ramp
+}
@@ -0,0 +1,72 @@
+package dank16
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGenerateSurfaceRampDark(t *testing.T) {
+	r := GenerateSurfaceRamp("#1a1a1a", false, 0)
+
+	if r.Surface != "#1a1a1a" || r.SurfaceDim != "#1a1a1a" {
+		t.Errorf("surface and surfaceDim should be the background, got %s and %s", r.Surface, r.SurfaceDim)
+	}
+	order := []string{r.SurfaceContainerLowest, r.Surface, r.SurfaceContainerLow, r.SurfaceContainer,
+		r.SurfaceContainerHigh, r.SurfaceContainerHighest, r.SurfaceBright}
+	for i := 1; i < len(order); i++ {
+		if getLstar(order[i]) <= getLstar(order[i-1]) {
+			t.Errorf("dark ramp should get lighter: %v", order)
+		}
+	}
+
+	if len(r.Elevation) != DefaultSurfaceSteps {
+		t.Fatalf("expected %d elevation steps, got %d", DefaultSurfaceSteps, len(r.Elevation))
+	}
+	if r.Elevation[0] != r.Surface || r.Elevation[len(r.Elevation)-1] != r.SurfaceContainerHighest {
+		t.Errorf("elevation should run from surface to surfaceContainerHighest: %v", r.Elevation)
+	}
+}
+
+func TestGenerateSurfaceRampLight(t *testing.T) {
+	r := GenerateSurfaceRamp("#f8f8f8", true, 3)
+
+	if r.SurfaceBright != "#f8f8f8" {
+		t.Errorf("light surfaceBright should be the background, got %s", r.SurfaceBright)
+	}
+	order := []string{r.SurfaceContainerHighest, r.SurfaceContainerHigh, r.SurfaceContainer,
+		r.SurfaceContainerLow, r.Surface, r.SurfaceContainerLowest}
+	for i := 1; i < len(order); i++ {
+		if getLstar(order[i]) <= getLstar(order[i-1]) {
+			t.Errorf("light containers should get darker with elevation: %v", order)
+		}
+	}
+	if getLstar(r.SurfaceDim) >= getLstar(r.SurfaceContainerHighest) {
+		t.Errorf("surfaceDim %s should be darker than every container", r.SurfaceDim)
+	}
+	if len(r.Elevation) != 3 {
+		t.Errorf("expected 3 elevation steps, got %v", r.Elevation)
+	}
+}
+
+func TestGenerateSurfaceRampKeepsHue(t *testing.T) {
+	bg := "#1c1a26"
+	_, _, h := hexToColorful(bg).Hcl()
+	for _, c := range GenerateSurfaceRamp(bg, false, 0).Elevation[1:] {
+		_, _, ch := hexToColorful(c).Hcl()
+		if d := hueDistance(h, ch); d > 10 {
+			t.Errorf("%s drifted %.1f° from the background hue", c, d)
+		}
+	}
+}
+
+func TestGenerateRolesJSONSurfaces(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{})
+
+	var named NamedPalette
+	if err := json.Unmarshal([]byte(GenerateRolesJSON(colors, false)), &named); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if named.Surfaces.Surface != colors[0] || named.Surfaces.SurfaceContainerHigh == "" {
+		t.Errorf("surfaces missing from JSON: %+v", named.Surfaces)
+	}
+}
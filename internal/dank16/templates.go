@@ -0,0 +1,180 @@
+package dank16
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Output formats that are plain text with colors filled in are
+// text/templates, so new ones need no code: RegisterFormat adds one, and
+// LoadTemplates reads a directory of them such as TemplateDir.
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// TemplateExt is the extension LoadTemplates looks for; the rest of the
+// file name is the format name
+const TemplateExt = ".tmpl"
+
+// TemplateData is what a format template is executed with. The named roles
+// are promoted, so templates can write {{.Red.Hex}} as well as
+// {{index .Colors 1}}.
+type TemplateData struct {
+	NamedPalette
+	Colors  []string
+	IsLight bool
+}
+
+// templateFuncs are the helpers available to every format
+var templateFuncs = template.FuncMap{
+	// bare drops the #
+	"bare": func(hex string) string { return strings.TrimPrefix(hex, "#") },
+	// alpha appends an alpha byte, as #rrggbbaa
+	"alpha": func(hex string, alpha float64) string { return NewRGBA(hex, alpha).Hex() },
+	// flatten blends a translucent color onto bg, for formats without alpha
+	"flatten": func(hex string, alpha float64, bg string) string { return NewRGBA(hex, alpha).Over(bg) },
+	"mix":     Mix,
+	"upper":   strings.ToUpper,
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]*template.Template)
+	// builtinFormats are the embedded templates, kept when a user template
+	// of the same name replaces one in formats
+	builtinFormats = make(map[string]*template.Template)
+)
+
+func init() {
+	entries, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := builtinTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		name := strings.TrimSuffix(entry.Name(), TemplateExt)
+		tmpl, err := parseFormat(name, string(data))
+		if err != nil {
+			panic(err)
+		}
+		builtinFormats[name] = tmpl
+		formats[name] = tmpl
+	}
+}
+
+func parseFormat(name, text string) (*template.Template, error) {
+	if name == "" || strings.ContainsAny(name, "/ \t") {
+		return nil, fmt.Errorf("invalid format name %q", name)
+	}
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// RegisterFormat parses text as a text/template executed with TemplateData
+// and makes it available as name, replacing any format of that name
+func RegisterFormat(name, text string) error {
+	tmpl, err := parseFormat(name, text)
+	if err != nil {
+		return err
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[name] = tmpl
+	return nil
+}
+
+// Formats lists the registered format names, sorted
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteFormat executes the named format for a palette, writing as it goes
+func WriteFormat(w io.Writer, name string, colors []string, isLight bool) error {
+	formatsMu.RLock()
+	tmpl, ok := formats[name]
+	formatsMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(Formats(), ", "))
+	}
+
+	return executeFormat(w, tmpl, colors, isLight)
+}
+
+func executeFormat(w io.Writer, tmpl *template.Template, colors []string, isLight bool) error {
+	return tmpl.Execute(w, TemplateData{
+		NamedPalette: NamePalette(colors, isLight),
+		Colors:       colors,
+		IsLight:      isLight,
+	})
+}
+
+// RenderFormat is WriteFormat into a string
+func RenderFormat(name string, colors []string, isLight bool) (string, error) {
+	var b strings.Builder
+	if err := WriteFormat(&b, name, colors, isLight); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// TemplateDir is where users keep their own format templates
+func TemplateDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "dms", "templates")
+}
+
+// LoadTemplates registers every template in dir, returning the format
+// names. A missing directory is not an error; a template that fails to
+// parse is, though the others are still registered.
+func LoadTemplates(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != TemplateExt {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), TemplateExt)
+		if err := RegisterFormat(name, string(data)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, errors.Join(errs...)
+}
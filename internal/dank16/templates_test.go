@@ -0,0 +1,119 @@
+package dank16
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinFormats(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{})
+
+	for _, name := range []string{"alacritty", "foot", "ghostty", "hyprland", "kitty", "niri", "wezterm"} {
+		if _, ok := builtinFormats[name]; !ok {
+			t.Errorf("%s is not built in", name)
+		}
+	}
+
+	foot, err := RenderFormat("foot", colors, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(foot, "regular0="+colors[0][1:]+"\n") || !strings.HasSuffix(foot, "bright7="+colors[15][1:]+"\n") {
+		t.Errorf("unexpected foot output:\n%s", foot)
+	}
+	if foot != GenerateFootTheme(colors) {
+		t.Error("GenerateFootTheme should match the foot format")
+	}
+
+	alacritty := GenerateAlacrittyTheme(colors)
+	for _, want := range []string{
+		"[colors.normal]\nblack   = '" + colors[0] + "'\n",
+		"magenta = '" + colors[13] + "'\ncyan    = '" + colors[14] + "'\nwhite   = '" + colors[15] + "'\n\n[colors.selection]\n",
+		"background = '" + NewRGBA(colors[4], 0.35).Over(colors[0]) + "'\n",
+	} {
+		if !strings.Contains(alacritty, want) {
+			t.Errorf("alacritty output missing %q", want)
+		}
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: true})
+
+	if err := RegisterFormat("test-roles", "{{.Red.Hex}} {{bare .Background.Hex}} {{alpha .Blue.Hex 0.5}} {{if .IsLight}}light{{end}}"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := RenderFormat("test-roles", colors, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := colors[1] + " " + colors[0][1:] + " " + colors[4] + "80 light"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+
+	if err := RegisterFormat("bad name", "x"); err == nil {
+		t.Error("expected an error for a name with a space")
+	}
+	if err := RegisterFormat("test-unclosed", "{{.Red"); err == nil {
+		t.Error("expected a parse error")
+	}
+	if err := RegisterFormat("test-missing", "{{.Nope}}"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenderFormat("test-missing", colors, true); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if _, err := RenderFormat("test-none", colors, true); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{})
+
+	names, err := LoadTemplates(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || names != nil {
+		t.Errorf("missing directory: %v %v", names, err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"test-wezterm.tmpl": "[colors]\nbackground = '{{.Background.Hex}}'\n",
+		"kitty.tmpl":        "custom {{index .Colors 0}}\n",
+		"test-broken.tmpl":  "{{end}}",
+		"notes.txt":         "not a template",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		formatsMu.Lock()
+		formats["kitty"] = builtinFormats["kitty"]
+		formatsMu.Unlock()
+	})
+
+	names, err = LoadTemplates(dir)
+	if err == nil || !strings.Contains(err.Error(), "test-broken.tmpl") {
+		t.Errorf("expected the broken template to be reported, got %v", err)
+	}
+	if strings.Join(names, ",") != "kitty,test-wezterm" {
+		t.Errorf("loaded %v", names)
+	}
+
+	out, err := RenderFormat("test-wezterm", colors, false)
+	if err != nil || out != "[colors]\nbackground = '"+colors[0]+"'\n" {
+		t.Errorf("test-wezterm = %q, %v", out, err)
+	}
+
+	// A user template replaces the format but not the library's generator
+	if out, _ := RenderFormat("kitty", colors, false); out != "custom "+colors[0]+"\n" {
+		t.Errorf("kitty format not replaced: %q", out)
+	}
+	if !strings.HasPrefix(GenerateKittyTheme(colors), "color0   "+colors[0]+"\n") {
+		t.Error("GenerateKittyTheme should keep the built-in template")
+	}
+}
@@ -1,126 +1,101 @@
 package dank16
 
 import (
-	"encoding/json"
-	"fmt"
 	"strings"
 )
 
-func GenerateJSON(colors []string) string {
-	colorMap := make(map[string]string)
+// The terminal formats are the built-in templates under templates/
 
-	for i, color := range colors {
-		colorMap[fmt.Sprintf("color%d", i)] = color
-	}
+func GenerateKittyTheme(colors []string) string {
+	return renderTerminalFormat("kitty", colors)
+}
 
-	marshalled, _ := json.Marshal(colorMap)
+func GenerateFootTheme(colors []string) string {
+	return renderTerminalFormat("foot", colors)
+}
 
-	return string(marshalled)
+func GenerateAlacrittyTheme(colors []string) string {
+	return renderTerminalFormat("alacritty", colors)
 }
 
-func GenerateKittyTheme(colors []string) string {
-	kittyColors := []struct {
-		name  string
-		index int
-	}{
-		{"color0", 0},
-		{"color1", 1},
-		{"color2", 2},
-		{"color3", 3},
-		{"color4", 4},
-		{"color5", 5},
-		{"color6", 6},
-		{"color7", 7},
-		{"color8", 8},
-		{"color9", 9},
-		{"color10", 10},
-		{"color11", 11},
-		{"color12", 12},
-		{"color13", 13},
-		{"color14", 14},
-		{"color15", 15},
-	}
+func GenerateGhosttyTheme(colors []string) string {
+	return renderTerminalFormat("ghostty", colors)
+}
 
-	var result strings.Builder
-	for _, kc := range kittyColors {
-		fmt.Fprintf(&result, "%s   %s\n", kc.name, colors[kc.index])
-	}
-	return result.String()
+// GenerateWeztermTheme emits a Lua module returning a WezTerm color scheme,
+// to be registered under config.color_schemes
+func GenerateWeztermTheme(colors []string) string {
+	return renderTerminalFormat("wezterm", colors)
 }
 
-func GenerateFootTheme(colors []string) string {
-	footColors := []struct {
-		name  string
-		index int
-	}{
-		{"regular0", 0},
-		{"regular1", 1},
-		{"regular2", 2},
-		{"regular3", 3},
-		{"regular4", 4},
-		{"regular5", 5},
-		{"regular6", 6},
-		{"regular7", 7},
-		{"bright0", 8},
-		{"bright1", 9},
-		{"bright2", 10},
-		{"bright3", 11},
-		{"bright4", 12},
-		{"bright5", 13},
-		{"bright6", 14},
-		{"bright7", 15},
+// renderTerminalFormat executes the built-in template even when a user
+// template replaced the format in the registry. They only read the palette
+// slots, which cannot fail on a full palette.
+func renderTerminalFormat(name string, colors []string) string {
+	var b strings.Builder
+	if err := executeFormat(&b, builtinFormats[name], colors, false); err != nil {
+		panic(err)
 	}
+	return b.String()
+}
 
-	var result strings.Builder
-	for _, fc := range footColors {
-		fmt.Fprintf(&result, "%s=%s\n", fc.name, strings.TrimPrefix(colors[fc.index], "#"))
-	}
-	return result.String()
+// Some terminals nudge text colors that fall below their own contrast
+// threshold, so a palette can render differently from its preview. There are
+// two ways to keep them in step: turn the adjustment off, or pre-compensate
+// the palette so nothing falls below the threshold the terminal enforces.
+
+// minContrastOff is the setting that turns off each terminal's adjustment
+var minContrastOff = map[string]string{
+	"kitty":   "text_fg_override_threshold 0",
+	"ghostty": "minimum-contrast = 1",
 }
 
-func GenerateAlacrittyTheme(colors []string) string {
-	alacrittyColors := []struct {
-		section string
-		name    string
-		index   int
-	}{
-		{"normal", "black", 0},
-		{"normal", "red", 1},
-		{"normal", "green", 2},
-		{"normal", "yellow", 3},
-		{"normal", "blue", 4},
-		{"normal", "magenta", 5},
-		{"normal", "cyan", 6},
-		{"normal", "white", 7},
-		{"bright", "black", 8},
-		{"bright", "red", 9},
-		{"bright", "green", 10},
-		{"bright", "yellow", 11},
-		{"bright", "blue", 12},
-		{"bright", "magenta", 13},
-		{"bright", "cyan", 14},
-		{"bright", "white", 15},
+// DisableTerminalMinContrast appends the setting that stops terminal
+// adjusting theme colors. Terminals without such an adjustment get theme
+// back unchanged.
+func DisableTerminalMinContrast(terminal, theme string) string {
+	setting, ok := minContrastOff[terminal]
+	if !ok {
+		return theme
 	}
+	if theme != "" && !strings.HasSuffix(theme, "\n") {
+		theme += "\n"
+	}
+	return theme + setting + "\n"
+}
 
-	var result strings.Builder
-	currentSection := ""
-	for _, ac := range alacrittyColors {
-		if ac.section != currentSection {
-			if currentSection != "" {
-				result.WriteString("\n")
-			}
-			fmt.Fprintf(&result, "[colors.%s]\n", ac.section)
-			currentSection = ac.section
-		}
-		fmt.Fprintf(&result, "%-7s = '%s'\n", ac.name, colors[ac.index])
+// CompensateMinContrast lifts every text slot to at least ratio (WCAG, as
+// kitty's text_fg_override_threshold and Ghostty's minimum-contrast measure
+// it) against the background, so a terminal enforcing that threshold leaves
+// the palette alone. The background slot itself is never changed.
+func CompensateMinContrast(colors []string, ratio float64, isLight bool) []string {
+	out := append([]string(nil), colors...)
+	if ratio <= 1 || len(out) == 0 {
+		return out
 	}
-	return result.String()
+	for i := 1; i < len(out); i++ {
+		out[i] = liftToRatio(out[i], out[0], ratio, isLight)
+	}
+	return out
 }
 
-func GenerateGhosttyTheme(colors []string) string {
-	var result strings.Builder
-	for i, color := range colors {
-		fmt.Fprintf(&result, "palette = %d=%s\n", i, color)
+// liftToRatio tries EnsureContrast first, which keeps the HSV hue and
+// saturation. Saturated blues run out of HSV value before reaching high
+// ratios on dark backgrounds, so those are moved in OKLCH lightness instead.
+func liftToRatio(hex, bg string, ratio float64, isLight bool) string {
+	if lifted := EnsureContrast(hex, bg, ratio, isLight); ContrastRatio(lifted, bg) >= ratio {
+		return lifted
+	}
+
+	l, c, h := hexToColorful(hex).OkLch()
+	step := 0.01
+	if isLight {
+		step = -step
+	}
+	for L := l; L >= 0 && L <= 1; L += step {
+		if cand := okLchToHex(L, c, h); ContrastRatio(cand, bg) >= ratio {
+			return cand
+		}
 	}
-	return result.String()
+	return hex
 }
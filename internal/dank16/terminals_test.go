@@ -0,0 +1,81 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisableTerminalMinContrast(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{})
+
+	kitty := DisableTerminalMinContrast("kitty", GenerateKittyTheme(colors))
+	if !strings.HasSuffix(kitty, "color15   "+colors[15]+"\ntext_fg_override_threshold 0\n") {
+		t.Errorf("kitty setting missing:\n%s", kitty)
+	}
+
+	ghostty := DisableTerminalMinContrast("ghostty", GenerateGhosttyTheme(colors))
+	if !strings.HasSuffix(ghostty, "\nminimum-contrast = 1\n") {
+		t.Errorf("ghostty setting missing:\n%s", ghostty)
+	}
+
+	foot := GenerateFootTheme(colors)
+	if DisableTerminalMinContrast("foot", foot) != foot {
+		t.Error("foot has no adjustment to turn off")
+	}
+}
+
+func TestCompensateMinContrast(t *testing.T) {
+	for _, isLight := range []bool{false, true} {
+		colors := GeneratePalette("#625690", PaletteOptions{IsLight: isLight})
+		out := CompensateMinContrast(colors, 4.5, isLight)
+
+		if out[0] != colors[0] {
+			t.Errorf("background changed: %s -> %s", colors[0], out[0])
+		}
+		for i := 1; i < 16; i++ {
+			if r := ContrastRatio(out[i], out[0]); r < 4.5 {
+				t.Errorf("light=%v slot %d %s has ratio %.2f", isLight, i, out[i], r)
+			}
+			if ContrastRatio(colors[i], colors[0]) >= 4.5 && out[i] != colors[i] {
+				t.Errorf("light=%v slot %d already passed but changed", isLight, i)
+			}
+		}
+	}
+
+	colors := GeneratePalette("#625690", PaletteOptions{})
+	out := CompensateMinContrast(colors, 1, false)
+	out[1] = "#000000"
+	if colors[1] == "#000000" {
+		t.Error("input palette was modified")
+	}
+}
+
+func TestGenerateWeztermTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{})
+	out := GenerateWeztermTheme(colors)
+
+	quoted := func(slots []string) string {
+		q := make([]string, len(slots))
+		for i, c := range slots {
+			q[i] = `"` + c + `"`
+		}
+		return strings.Join(q, ", ")
+	}
+	for _, want := range []string{
+		"\nreturn {\n",
+		`  background = "` + colors[0] + `",`,
+		`  foreground = "` + colors[7] + `",`,
+		"  ansi = { " + quoted(colors[:8]) + " },\n",
+		"  brights = { " + quoted(colors[8:16]) + " },\n}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("wezterm output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "{") != strings.Count(out, "}") {
+		t.Errorf("unbalanced braces:\n%s", out)
+	}
+	if DisableTerminalMinContrast("wezterm", out) != out {
+		t.Error("wezterm has no adjustment to turn off")
+	}
+}
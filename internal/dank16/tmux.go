@@ -0,0 +1,46 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateTmuxTheme emits a tmux.conf fragment styling the status bar, window
+// list, pane borders, messages and copy mode. Source it from tmux.conf with
+// source-file; it only sets styles, so layout and formats stay the user's.
+func GenerateTmuxTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	muted := Mix(u.fg, u.bg, 0.35)
+
+	options := []struct {
+		name  string
+		value string
+	}{
+		{"status-style", fmt.Sprintf("bg=%s,fg=%s", u.raised, u.fg)},
+		{"status-left-style", fmt.Sprintf("bg=%s,fg=%s,bold", u.accent, u.onAccent)},
+		{"status-right-style", fmt.Sprintf("bg=%s,fg=%s", u.raised, muted)},
+		{"window-status-style", fmt.Sprintf("bg=%s,fg=%s", u.raised, muted)},
+		{"window-status-current-style", fmt.Sprintf("bg=%s,fg=%s,bold", u.bg, u.accentText)},
+		{"window-status-activity-style", fmt.Sprintf("bg=%s,fg=%s", u.raised, colors[3])},
+		{"window-status-bell-style", fmt.Sprintf("bg=%s,fg=%s,bold", u.raised, colors[1])},
+		{"pane-border-style", "fg=" + u.border},
+		{"pane-active-border-style", "fg=" + u.accent},
+		{"display-panes-colour", u.border},
+		{"display-panes-active-colour", u.accent},
+		{"message-style", fmt.Sprintf("bg=%s,fg=%s", u.raised, u.fg)},
+		{"message-command-style", fmt.Sprintf("bg=%s,fg=%s", u.raised, u.accentText)},
+		{"mode-style", fmt.Sprintf("bg=%s,fg=%s", u.accent, u.onAccent)},
+		{"copy-mode-match-style", fmt.Sprintf("bg=%s,fg=%s", colors[3], onColor(colors[3]))},
+		{"copy-mode-current-match-style", fmt.Sprintf("bg=%s,fg=%s", colors[11], onColor(colors[11]))},
+		{"clock-mode-colour", u.accent},
+	}
+
+	var result strings.Builder
+	result.WriteString("# Generated by dank16\n")
+	for _, o := range options {
+		// -q keeps tmux older than 3.2 quiet about the copy-mode match
+		// styles it does not know
+		fmt.Fprintf(&result, "set -gq %s \"%s\"\n", o.name, o.value)
+	}
+	return result.String()
+}
@@ -0,0 +1,34 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateTmuxTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	conf := GenerateTmuxTheme(colors, false)
+
+	for _, want := range []string{
+		"set -gq pane-active-border-style \"fg=" + colors[4] + "\"\n",
+		"set -gq window-status-current-style \"bg=" + colors[0] + ",fg=" + colors[12] + ",bold\"\n",
+		"set -gq status-style \"bg=",
+		"set -gq message-style \"bg=",
+		"set -gq mode-style \"bg=" + colors[4] + ",fg=",
+	} {
+		if !strings.Contains(conf, want) {
+			t.Errorf("missing %q in:\n%s", want, conf)
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(conf), "\n") {
+		if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "set -gq ") {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+
+	light := GenerateTmuxTheme(GeneratePalette("#625690", PaletteOptions{IsLight: true}), true)
+	if !strings.Contains(light, "window-status-current-style \"bg=#f8f8f8,") {
+		t.Errorf("unexpected light current window style:\n%s", light)
+	}
+}
@@ -0,0 +1,50 @@
+package dank16
+
+// uiColors are the surfaces and accents GUI toolkit themes are built from,
+// derived the same way for GTK, Qt, VSCode and the rest so all look alike.
+// The accent is always the one GeneratePalette resolved, AccentColor.
+type uiColors struct {
+	bg         string
+	fg         string
+	view       string
+	raised     string
+	card       string
+	border     string
+	disabledFg string
+	accent     string
+	// accentText is the accent when used as text on the window; the bright
+	// variant reads better in dark mode, the normal one in light mode
+	accentText string
+	onAccent   string
+}
+
+func deriveUIColors(colors []string, isLight bool) uiColors {
+	u := uiColors{
+		bg:         colors[0],
+		fg:         colors[7],
+		accent:     AccentColor(colors),
+		accentText: colors[AccentSlot+8],
+	}
+	u.view = Mix(u.bg, "#000000", 0.15)
+	u.raised = Mix(u.bg, u.fg, 0.03)
+	u.card = Mix(u.bg, u.fg, 0.02)
+	if isLight {
+		u.accentText = u.accent
+		u.view = Mix(u.bg, "#ffffff", 0.6)
+		u.raised = Mix(u.bg, u.fg, 0.06)
+		u.card = u.view
+	}
+	u.border = Mix(u.bg, u.fg, 0.15)
+	u.disabledFg = Mix(u.fg, u.bg, 0.5)
+	u.onAccent = onColor(u.accent)
+	return u
+}
+
+// onColor picks black or white text for a filled background, whichever
+// contrasts more
+func onColor(bg string) string {
+	if ContrastRatio("#ffffff", bg) >= ContrastRatio("#000000", bg) {
+		return "#ffffff"
+	}
+	return "#000000"
+}
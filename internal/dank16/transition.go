@@ -0,0 +1,74 @@
+package dank16
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// SlotDiff is how far one palette slot moved between two palettes, in
+// CIEDE2000 ΔE on the 0-100 scale PaletteDistance uses
+type SlotDiff struct {
+	Slot   int     `json:"slot"`
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	DeltaE float64 `json:"deltaE"`
+}
+
+// DiffPalettes compares matching slots of a and b. Slots beyond the shorter
+// palette are ignored.
+func DiffPalettes(a, b []string) []SlotDiff {
+	n := min(len(a), len(b))
+	diffs := make([]SlotDiff, n)
+	for i := 0; i < n; i++ {
+		diffs[i] = SlotDiff{
+			Slot:   i,
+			From:   a[i],
+			To:     b[i],
+			DeltaE: hexToColorful(a[i]).DistanceCIEDE2000(hexToColorful(b[i])) * 100,
+		}
+	}
+	return diffs
+}
+
+// InterpolatePalettes blends each slot of a towards b by t (0 returns a,
+// 1 returns b). Blending in OKLab keeps lightness changing evenly, so a
+// transition neither flashes bright nor dips through grey halfway.
+func InterpolatePalettes(a, b []string, t float64) []string {
+	t = math.Max(0, math.Min(1, t))
+	n := min(len(a), len(b))
+	out := make([]string, n)
+	for i := 0; i < n; i++ {
+		switch t {
+		case 0:
+			out[i] = a[i]
+		case 1:
+			out[i] = b[i]
+		default:
+			out[i] = hexToColorful(a[i]).BlendOkLab(hexToColorful(b[i]), t).Clamped().Hex()
+		}
+	}
+	return out
+}
+
+// PaletteTransition is what the shell needs to animate a theme change: how
+// much each slot moves and the palettes to show along the way
+type PaletteTransition struct {
+	Diff   []SlotDiff `json:"diff"`
+	Frames [][]string `json:"frames"`
+}
+
+// GenerateTransition samples steps palettes from a to b, both included
+func GenerateTransition(a, b []string, steps int) PaletteTransition {
+	steps = max(steps, 2)
+	frames := make([][]string, steps)
+	for i := range frames {
+		frames[i] = InterpolatePalettes(a, b, float64(i)/float64(steps-1))
+	}
+	return PaletteTransition{Diff: DiffPalettes(a, b), Frames: frames}
+}
+
+// GenerateTransitionJSON emits a PaletteTransition
+func GenerateTransitionJSON(a, b []string, steps int) string {
+	marshalled, _ := json.MarshalIndent(GenerateTransition(a, b, steps), "", "  ")
+	return string(marshalled) + "\n"
+}
@@ -0,0 +1,84 @@
+package dank16
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffPalettes(t *testing.T) {
+	a := GeneratePalette("#625690", PaletteOptions{})
+	b := GeneratePalette("#2e7de9", PaletteOptions{})
+
+	diffs := DiffPalettes(a, a)
+	if len(diffs) != 16 {
+		t.Fatalf("expected 16 slots, got %d", len(diffs))
+	}
+	for _, d := range diffs {
+		if d.DeltaE != 0 {
+			t.Errorf("slot %d of identical palettes differs by %.2f", d.Slot, d.DeltaE)
+		}
+	}
+
+	diffs = DiffPalettes(a, b)
+	if diffs[4].From != a[4] || diffs[4].To != b[4] || diffs[4].DeltaE < 5 {
+		t.Errorf("accent slot should move visibly: %+v", diffs[4])
+	}
+
+	if got := DiffPalettes(a, b[:3]); len(got) != 3 {
+		t.Errorf("expected the shorter length, got %d", len(got))
+	}
+}
+
+func TestInterpolatePalettes(t *testing.T) {
+	a := []string{"#000000", "#ff0000", "#625690"}
+	b := []string{"#ffffff", "#0000ff", "#625690"}
+
+	if got := InterpolatePalettes(a, b, 0); got[1] != "#ff0000" {
+		t.Errorf("t=0 should return a, got %v", got)
+	}
+	if got := InterpolatePalettes(a, b, 1.5); got[1] != "#0000ff" {
+		t.Errorf("t>1 should clamp to b, got %v", got)
+	}
+
+	mid := InterpolatePalettes(a, b, 0.5)
+	if mid[2] != "#625690" {
+		t.Errorf("unchanged slot drifted to %s", mid[2])
+	}
+
+	// Halfway in OKLab sits at about half the perceived lightness, which is
+	// well above the linear-RGB midpoint's darker grey
+	l, _, _ := hexToColorful(mid[0]).OkLab()
+	if l < 0.45 || l > 0.55 {
+		t.Errorf("black to white midpoint %s has OKLab L %.3f", mid[0], l)
+	}
+
+	// Each step moves a slot less than the whole change
+	whole := DiffPalettes(a, b)[1].DeltaE
+	if step := DiffPalettes(a, mid)[1].DeltaE; step <= 0 || step >= whole {
+		t.Errorf("half step ΔE %.1f should be between 0 and %.1f", step, whole)
+	}
+}
+
+func TestGenerateTransition(t *testing.T) {
+	a := GeneratePalette("#625690", PaletteOptions{})
+	b := GeneratePalette("#2e7de9", PaletteOptions{})
+
+	tr := GenerateTransition(a, b, 5)
+	if len(tr.Frames) != 5 || len(tr.Diff) != 16 {
+		t.Fatalf("got %d frames and %d diffs", len(tr.Frames), len(tr.Diff))
+	}
+	if tr.Frames[0][4] != a[4] || tr.Frames[4][4] != b[4] {
+		t.Error("frames should start at a and end at b")
+	}
+	if got := GenerateTransition(a, b, 0); len(got.Frames) != 2 {
+		t.Errorf("fewer than two steps should still give both ends, got %d", len(got.Frames))
+	}
+
+	var decoded PaletteTransition
+	if err := json.Unmarshal([]byte(GenerateTransitionJSON(a, b, 3)), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Frames) != 3 || decoded.Diff[0].To != b[0] {
+		t.Errorf("unexpected JSON: %+v", decoded)
+	}
+}
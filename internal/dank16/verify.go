@@ -0,0 +1,290 @@
+package dank16
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Swatches are drawn as a square of the color inset in a cell filled with
+// the background, the way a block glyph sits in a terminal cell
+const (
+	swatchCell  = 24
+	swatchInset = 4
+	swatchCols  = 8
+)
+
+// VerifyTarget renders one generator's output into a reference image. The
+// config text is parsed back rather than the palette drawn directly, so a
+// generator that formats a color wrongly shows up in the image.
+type VerifyTarget struct {
+	Name   string
+	Render func(colors []string, isLight bool) (image.Image, error)
+}
+
+// VerifyTargets are the generators dms dank16 verify checks against goldens
+var VerifyTargets = []VerifyTarget{
+	{Name: "kitty", Render: func(colors []string, _ bool) (image.Image, error) {
+		return renderTerminal(GenerateKittyTheme(colors), parseKittyColors)
+	}},
+	{Name: "foot", Render: func(colors []string, _ bool) (image.Image, error) {
+		return renderTerminal(GenerateFootTheme(colors), parseFootColors)
+	}},
+	{Name: "alacritty", Render: func(colors []string, _ bool) (image.Image, error) {
+		return renderTerminal(GenerateAlacrittyTheme(colors), parseAlacrittyColors)
+	}},
+	{Name: "ghostty", Render: func(colors []string, _ bool) (image.Image, error) {
+		return renderTerminal(GenerateGhosttyTheme(colors), parseGhosttyColors)
+	}},
+	{Name: "vscode", Render: renderVSCode},
+}
+
+// VerifyResult compares a rendered image with its golden. Pixels further
+// than the threshold apart in CIEDE2000 ΔE count as failed.
+type VerifyResult struct {
+	Target       string  `json:"target"`
+	MaxDeltaE    float64 `json:"maxDeltaE"`
+	FailedPixels int     `json:"failedPixels"`
+	TotalPixels  int     `json:"totalPixels"`
+}
+
+func (r VerifyResult) Passed() bool {
+	return r.FailedPixels == 0
+}
+
+// CompareImages measures how far got drifts from want. Images of different
+// sizes cannot be compared and return an error.
+func CompareImages(got, want image.Image, threshold float64) (VerifyResult, error) {
+	var result VerifyResult
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return result, fmt.Errorf("image is %dx%d, golden is %dx%d", gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			a, _ := colorful.MakeColor(got.At(gb.Min.X+x, gb.Min.Y+y))
+			b, _ := colorful.MakeColor(want.At(wb.Min.X+x, wb.Min.Y+y))
+			d := a.DistanceCIEDE2000(b) * 100
+			if d > result.MaxDeltaE {
+				result.MaxDeltaE = d
+			}
+			if d > threshold {
+				result.FailedPixels++
+			}
+			result.TotalPixels++
+		}
+	}
+	return result, nil
+}
+
+// renderSwatches lays the colors out in rows of eight on the background
+func renderSwatches(bg string, colors []string) image.Image {
+	rows := (len(colors) + swatchCols - 1) / swatchCols
+	img := image.NewRGBA(image.Rect(0, 0, swatchCols*swatchCell, rows*swatchCell))
+
+	fill := func(r image.Rectangle, hex string) {
+		rgb := HexToRGB(hex)
+		c := color.RGBA{R: uint8(math.Round(rgb.R * 255)), G: uint8(math.Round(rgb.G * 255)), B: uint8(math.Round(rgb.B * 255)), A: 255}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+
+	fill(img.Bounds(), bg)
+	for i, hex := range colors {
+		x, y := (i%swatchCols)*swatchCell, (i/swatchCols)*swatchCell
+		fill(image.Rect(x+swatchInset, y+swatchInset, x+swatchCell-swatchInset, y+swatchCell-swatchInset), hex)
+	}
+	return img
+}
+
+// renderTerminal draws the 16 ANSI colors parsed from a terminal config as
+// two rows, normal above bright, on color 0
+func renderTerminal(config string, parse func(string) (map[int]string, error)) (image.Image, error) {
+	parsed, err := parse(config)
+	if err != nil {
+		return nil, err
+	}
+	colors := make([]string, 16)
+	for i := range colors {
+		hex, ok := parsed[i]
+		if !ok {
+			return nil, fmt.Errorf("color %d missing", i)
+		}
+		if !paletteHexPattern.MatchString(hex) {
+			return nil, fmt.Errorf("color %d: invalid color %q", i, hex)
+		}
+		colors[i] = hex
+	}
+	return renderSwatches(colors[0], colors), nil
+}
+
+func parseKittyColors(config string) (map[int]string, error) {
+	colors := make(map[int]string)
+	for _, line := range strings.Split(config, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !strings.HasPrefix(fields[0], "color") {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(fields[0], "color"))
+		if err != nil {
+			return nil, fmt.Errorf("bad kitty key %q", fields[0])
+		}
+		colors[i] = fields[1]
+	}
+	return colors, nil
+}
+
+func parseFootColors(config string) (map[int]string, error) {
+	colors := make(map[int]string)
+	for _, line := range strings.Split(config, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		offset := 0
+		switch {
+		case strings.HasPrefix(key, "regular"):
+			key = strings.TrimPrefix(key, "regular")
+		case strings.HasPrefix(key, "bright"):
+			key, offset = strings.TrimPrefix(key, "bright"), 8
+		default:
+			continue
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("bad foot key in %q", line)
+		}
+		colors[i+offset] = "#" + value
+	}
+	return colors, nil
+}
+
+func parseAlacrittyColors(config string) (map[int]string, error) {
+	tables, err := tomlite.Parse(config)
+	if err != nil {
+		return nil, err
+	}
+	colors := make(map[int]string)
+	for _, t := range tables {
+		offset := 0
+		switch t.Name {
+		case "colors.normal":
+		case "colors.bright":
+			offset = 8
+		default:
+			continue
+		}
+		for i, name := range SlotNames[:8] {
+			if hex, ok := t.Values[name].(string); ok {
+				colors[i+offset] = hex
+			}
+		}
+	}
+	return colors, nil
+}
+
+func parseGhosttyColors(config string) (map[int]string, error) {
+	colors := make(map[int]string)
+	for _, line := range strings.Split(config, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != "palette" {
+			continue
+		}
+		index, hex, ok := strings.Cut(strings.TrimSpace(value), "=")
+		if !ok {
+			return nil, fmt.Errorf("bad ghostty palette line %q", line)
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			return nil, fmt.Errorf("bad ghostty palette index in %q", line)
+		}
+		colors[i] = hex
+	}
+	return colors, nil
+}
+
+var vscodeANSINames = []string{
+	"Black", "Red", "Green", "Yellow", "Blue", "Magenta", "Cyan", "White",
+	"BrightBlack", "BrightRed", "BrightGreen", "BrightYellow",
+	"BrightBlue", "BrightMagenta", "BrightCyan", "BrightWhite",
+}
+
+// renderVSCode enriches a theme holding one rule per TextMate scope, then
+// draws the terminal colors followed by the token and semantic colors in
+// scope order
+func renderVSCode(colors []string, _ bool) (image.Image, error) {
+	scopes := make([]string, 0)
+	for scope := range textMateColors(colors) {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	base := map[string]any{"colors": map[string]any{}}
+	rules := make([]any, 0, len(scopes))
+	for _, scope := range scopes {
+		rules = append(rules, map[string]any{"scope": []any{scope}, "settings": map[string]any{}})
+	}
+	base["tokenColors"] = rules
+
+	data, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	enriched, err := EnrichVSCodeTheme(data, colors, VSCodeMinContrast)
+	if err != nil {
+		return nil, err
+	}
+
+	var theme struct {
+		Colors      map[string]string `json:"colors"`
+		TokenColors []struct {
+			Settings VSCodeTokenSetting `json:"settings"`
+		} `json:"tokenColors"`
+		SemanticTokenColors map[string]VSCodeTokenSetting `json:"semanticTokenColors"`
+	}
+	if err := json.Unmarshal(enriched, &theme); err != nil {
+		return nil, fmt.Errorf("failed to parse enriched theme: %w", err)
+	}
+
+	var swatches []string
+	for _, name := range vscodeANSINames {
+		hex, ok := theme.Colors["terminal.ansi"+name]
+		if !ok {
+			return nil, fmt.Errorf("terminal.ansi%s missing", name)
+		}
+		swatches = append(swatches, hex)
+	}
+	for i, tc := range theme.TokenColors {
+		if tc.Settings.Foreground == "" {
+			return nil, fmt.Errorf("token color %d has no foreground", i)
+		}
+		swatches = append(swatches, tc.Settings.Foreground)
+	}
+	semantic := make([]string, 0, len(theme.SemanticTokenColors))
+	for key := range theme.SemanticTokenColors {
+		semantic = append(semantic, key)
+	}
+	sort.Strings(semantic)
+	for _, key := range semantic {
+		swatches = append(swatches, theme.SemanticTokenColors[key].Foreground)
+	}
+
+	for _, hex := range swatches {
+		if !paletteHexPattern.MatchString(hex) {
+			return nil, fmt.Errorf("invalid color %q in theme", hex)
+		}
+	}
+	return renderSwatches(swatches[0], swatches), nil
+}
@@ -0,0 +1,72 @@
+package dank16
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestVerifyTargetsRoundTrip(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{UseDPS: true})
+	want := renderSwatches(colors[0], colors)
+
+	for _, target := range VerifyTargets {
+		img, err := target.Render(colors, false)
+		if err != nil {
+			t.Errorf("%s: %v", target.Name, err)
+			continue
+		}
+		if target.Name == "vscode" {
+			// The terminal colors come first, so the top two rows match
+			img = img.(*image.RGBA).SubImage(want.Bounds())
+		}
+		result, err := CompareImages(img, want, 0.5)
+		if err != nil {
+			t.Errorf("%s: %v", target.Name, err)
+			continue
+		}
+		if !result.Passed() {
+			t.Errorf("%s: %d pixels differ, max ΔE %.2f", target.Name, result.FailedPixels, result.MaxDeltaE)
+		}
+	}
+}
+
+func TestCompareImagesDetectsDrift(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{UseDPS: true})
+	drifted := append([]string(nil), colors...)
+	drifted[1] = Lighten(drifted[1], 0.1)
+
+	result, err := CompareImages(renderSwatches(drifted[0], drifted), renderSwatches(colors[0], colors), 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed() {
+		t.Error("drifted red was not detected")
+	}
+	want := (swatchCell - 2*swatchInset) * (swatchCell - 2*swatchInset)
+	if result.FailedPixels != want {
+		t.Errorf("FailedPixels = %d, want %d", result.FailedPixels, want)
+	}
+}
+
+func TestCompareImagesSizeMismatch(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	b := image.NewRGBA(image.Rect(0, 0, 8, 16))
+	if _, err := CompareImages(a, b, 1.0); err == nil {
+		t.Error("expected an error for differently sized images")
+	}
+}
+
+func TestRenderTerminalRejectsBrokenConfig(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{UseDPS: true})
+
+	missing := strings.Replace(GenerateKittyTheme(colors), "color5 ", "colour5 ", 1)
+	if _, err := renderTerminal(missing, parseKittyColors); err == nil {
+		t.Error("expected an error for a missing color")
+	}
+
+	malformed := strings.Replace(GenerateGhosttyTheme(colors), "3=#", "3=", 1)
+	if _, err := renderTerminal(malformed, parseGhosttyColors); err == nil {
+		t.Error("expected an error for a color without #")
+	}
+}
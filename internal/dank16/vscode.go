@@ -2,7 +2,7 @@ package dank16
 
 import (
 	"encoding/json"
-	"fmt"
+	"sort"
 )
 
 type VSCodeTheme struct {
@@ -90,7 +90,39 @@ func hasScopeContaining(scopes []interface{}, substring string) bool {
 	return false
 }
 
-func EnrichVSCodeTheme(themeData []byte, colors []string) ([]byte, error) {
+// vscodeAccentKeys are the workbench colors filled with the accent, and
+// vscodeOnAccentKeys the text drawn on them
+var (
+	vscodeAccentKeys = []string{
+		"focusBorder",
+		"button.background",
+		"badge.background",
+		"activityBarBadge.background",
+		"activityBar.activeBorder",
+		"panelTitle.activeBorder",
+		"tab.activeBorderTop",
+		"progressBar.background",
+		"statusBarItem.remoteBackground",
+	}
+	vscodeOnAccentKeys = []string{
+		"button.foreground",
+		"badge.foreground",
+		"activityBarBadge.foreground",
+		"statusBarItem.remoteForeground",
+	}
+)
+
+// VSCodeMinContrast is the Delta Phi Star Lc EnrichVSCodeTheme's callers
+// ask of token colors by default, the palette's own target for bright slots
+const VSCodeMinContrast = 35.0
+
+// EnrichVSCodeTheme writes the palette into an existing theme's terminal,
+// token and semantic token colors, and AccentColor into the workbench
+// colors that show the accent. Every token color it injects is then
+// lifted in L* until it reaches minLc against the theme's own
+// editor.background, which need not be the palette's; minLc <= 0 leaves
+// them as they are.
+func EnrichVSCodeTheme(themeData []byte, colors []string, minLc float64) ([]byte, error) {
 	var theme map[string]interface{}
 	if err := json.Unmarshal(themeData, &theme); err != nil {
 		return nil, err
@@ -102,16 +134,7 @@ func EnrichVSCodeTheme(themeData []byte, colors []string) ([]byte, error) {
 		theme["colors"] = colorsMap
 	}
 
-	bg := colors[0]
-	isLight := false
-	if len(bg) == 7 && bg[0] == '#' {
-		r, g, b := 0, 0, 0
-		fmt.Sscanf(bg[1:], "%02x%02x%02x", &r, &g, &b)
-		luminance := (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 255.0
-		isLight = luminance > 0.5
-	}
-
-	if isLight {
+	if isLightBackground(colors[0]) {
 		theme["type"] = "light"
 	} else {
 		theme["type"] = "dark"
@@ -134,35 +157,30 @@ func EnrichVSCodeTheme(themeData []byte, colors []string) ([]byte, error) {
 	colorsMap["terminal.ansiBrightCyan"] = colors[14]
 	colorsMap["terminal.ansiBrightWhite"] = colors[15]
 
+	// The workbench accent is the one GTK and Qt get, not the theme's own
+	u := deriveUIColors(colors, isLightBackground(colors[0]))
+	for _, key := range vscodeAccentKeys {
+		colorsMap[key] = u.accent
+	}
+	for _, key := range vscodeOnAccentKeys {
+		colorsMap[key] = u.onAccent
+	}
+	colorsMap["textLink.foreground"] = u.accentText
+	colorsMap["textLink.activeForeground"] = u.accentText
+
+	editorBg := vscodeEditorBackground(colorsMap, colors[0])
+	ensure := func(color string) string {
+		if minLc <= 0 {
+			return color
+		}
+		return EnsureContrastDPSLstar(color, editorBg, minLc, isLightBackground(editorBg))
+	}
+
 	tokenColors, ok := theme["tokenColors"].([]interface{})
 	if ok {
-		scopeToColor := map[string]string{
-			"comment":                        colors[8],
-			"punctuation.definition.comment": colors[8],
-			"keyword":                        colors[5],
-			"storage.type":                   colors[13],
-			"storage.modifier":               colors[5],
-			"variable":                       colors[15],
-			"variable.parameter":             colors[7],
-			"meta.object-literal.key":        colors[4],
-			"meta.property.object":           colors[4],
-			"variable.other.property":        colors[4],
-			"constant.other.symbol":          colors[12],
-			"constant.numeric":               colors[12],
-			"constant.language":              colors[12],
-			"constant.character":             colors[3],
-			"entity.name.type":               colors[12],
-			"support.type":                   colors[13],
-			"entity.name.class":              colors[12],
-			"entity.name.function":           colors[2],
-			"support.function":               colors[2],
-			"support.class":                  colors[15],
-			"support.variable":               colors[15],
-			"variable.language":              colors[12],
-			"entity.name.tag.yaml":           colors[12],
-			"string.unquoted.plain.out.yaml": colors[15],
-			"string.unquoted.yaml":           colors[15],
-			"string":                         colors[3],
+		scopeToColor := textMateColors(colors)
+		for scope, color := range scopeToColor {
+			scopeToColor[scope] = ensure(color)
 		}
 
 		for i, tc := range tokenColors {
@@ -173,11 +191,11 @@ func EnrichVSCodeTheme(themeData []byte, colors []string) ([]byte, error) {
 		yamlRules := []VSCodeTokenColor{
 			{
 				Scope:    "entity.name.tag.yaml",
-				Settings: VSCodeTokenSetting{Foreground: colors[12]},
+				Settings: VSCodeTokenSetting{Foreground: ensure(colors[12])},
 			},
 			{
 				Scope:    []string{"string.unquoted.plain.out.yaml", "string.unquoted.yaml"},
-				Settings: VSCodeTokenSetting{Foreground: colors[15]},
+				Settings: VSCodeTokenSetting{Foreground: ensure(colors[15])},
 			},
 		}
 
@@ -189,58 +207,25 @@ func EnrichVSCodeTheme(themeData []byte, colors []string) ([]byte, error) {
 	}
 
 	if semanticTokenColors, ok := theme["semanticTokenColors"].(map[string]interface{}); ok {
-		updates := map[string]string{
-			"variable":          colors[15],
-			"variable.readonly": colors[12],
-			"property":          colors[4],
-			"function":          colors[2],
-			"method":            colors[2],
-			"type":              colors[12],
-			"class":             colors[12],
-			"typeParameter":     colors[13],
-			"enumMember":        colors[12],
-			"string":            colors[3],
-			"number":            colors[12],
-			"comment":           colors[8],
-			"keyword":           colors[5],
-			"operator":          colors[15],
-			"parameter":         colors[7],
-			"namespace":         colors[15],
-		}
+		updates := semanticColors(colors)
+		updates["variable"] = colors[15]
 
 		for key, color := range updates {
 			if existing, ok := semanticTokenColors[key].(map[string]interface{}); ok {
-				existing["foreground"] = color
+				existing["foreground"] = ensure(color)
 			} else {
 				semanticTokenColors[key] = map[string]interface{}{
-					"foreground": color,
+					"foreground": ensure(color),
 				}
 			}
 		}
 	} else {
 		semanticTokenColors := make(map[string]interface{})
-		updates := map[string]string{
-			"variable":          colors[7],
-			"variable.readonly": colors[12],
-			"property":          colors[4],
-			"function":          colors[2],
-			"method":            colors[2],
-			"type":              colors[12],
-			"class":             colors[12],
-			"typeParameter":     colors[13],
-			"enumMember":        colors[12],
-			"string":            colors[3],
-			"number":            colors[12],
-			"comment":           colors[8],
-			"keyword":           colors[5],
-			"operator":          colors[15],
-			"parameter":         colors[7],
-			"namespace":         colors[15],
-		}
+		updates := semanticColors(colors)
 
 		for key, color := range updates {
 			semanticTokenColors[key] = map[string]interface{}{
-				"foreground": color,
+				"foreground": ensure(color),
 			}
 		}
 		theme["semanticTokenColors"] = semanticTokenColors
@@ -248,3 +233,91 @@ func EnrichVSCodeTheme(themeData []byte, colors []string) ([]byte, error) {
 
 	return json.MarshalIndent(theme, "", "  ")
 }
+
+// vscodeEditorBackground is the color token colors are drawn on: the theme's
+// editor.background, flattened onto the palette background if translucent,
+// or the palette background when the theme sets none
+func vscodeEditorBackground(colorsMap map[string]interface{}, paletteBg string) string {
+	value, ok := colorsMap["editor.background"].(string)
+	if !ok {
+		return paletteBg
+	}
+	bg, err := ParseRGBA(value)
+	if err != nil {
+		return paletteBg
+	}
+	return bg.Over(paletteBg)
+}
+
+// isLightBackground tells light themes from dark by perceived brightness
+func isLightBackground(hex string) bool {
+	rgb := HexToRGB(hex)
+	return 0.299*rgb.R+0.587*rgb.G+0.114*rgb.B > 0.5
+}
+
+// GenerateVSCodeTheme emits a complete VSCode color theme: the editor and
+// side bar surfaces from the shared UI colors, and token colors for every
+// scope EnrichVSCodeTheme knows, lifted to VSCodeMinContrast
+func GenerateVSCodeTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	scopes := textMateColors(colors)
+	names := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		names = append(names, scope)
+	}
+	sort.Strings(names)
+	tokenColors := make([]VSCodeTokenColor, 0, len(names))
+	for _, scope := range names {
+		tokenColors = append(tokenColors, VSCodeTokenColor{
+			Scope:    []string{scope},
+			Settings: VSCodeTokenSetting{Foreground: scopes[scope]},
+		})
+	}
+
+	base := VSCodeTheme{
+		Schema: "vscode://schemas/color-theme",
+		Name:   "Dank16",
+		Colors: map[string]string{
+			"editor.background":                u.bg,
+			"editor.foreground":                u.fg,
+			"editor.lineHighlightBackground":   Mix(u.bg, u.fg, 0.06),
+			"editor.selectionBackground":       Mix(u.bg, u.accent, 0.3),
+			"editorLineNumber.foreground":      Mix(u.fg, u.bg, 0.55),
+			"editorCursor.foreground":          u.fg,
+			"sideBar.background":               u.raised,
+			"sideBar.foreground":               u.fg,
+			"activityBar.background":           u.raised,
+			"activityBar.foreground":           u.fg,
+			"panel.background":                 u.bg,
+			"panel.border":                     u.border,
+			"titleBar.activeBackground":        u.raised,
+			"titleBar.activeForeground":        u.fg,
+			"statusBar.background":             u.raised,
+			"statusBar.foreground":             u.fg,
+			"tab.activeBackground":             u.bg,
+			"tab.inactiveBackground":           u.raised,
+			"editorGroupHeader.tabsBackground": u.raised,
+			"input.background":                 u.view,
+			"input.foreground":                 u.fg,
+			"dropdown.background":              u.view,
+			"widget.border":                    u.border,
+			"disabledForeground":               u.disabledFg,
+			"foreground":                       u.fg,
+			"terminal.background":              u.bg,
+			"terminal.foreground":              u.fg,
+		},
+		TokenColors:          tokenColors,
+		SemanticHighlighting: true,
+	}
+	data, err := json.Marshal(base)
+	if err != nil {
+		panic(err)
+	}
+	// The skeleton always parses, so enriching cannot fail
+	theme, err := EnrichVSCodeTheme(data, colors, VSCodeMinContrast)
+	if err != nil {
+		panic(err)
+	}
+	return string(theme) + "\n"
+}
@@ -0,0 +1,148 @@
+package dank16
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateWaybarCSS emits a waybar style.css fragment. Colors are declared
+// with @define-color so the rest of a hand-written style.css can use them;
+// @import it at the top or paste it in. Layout (fonts, spacing) is left to
+// the user.
+func GenerateWaybarCSS(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+
+	var b strings.Builder
+	b.WriteString("/* Generated by dank16 */\n")
+	for _, c := range []struct{ name, hex string }{
+		{"dank_bg", u.bg},
+		{"dank_surface", u.raised},
+		{"dank_fg", u.fg},
+		{"dank_muted", Mix(u.fg, u.bg, 0.35)},
+		{"dank_border", u.border},
+		{"dank_accent", u.accent},
+		{"dank_on_accent", u.onAccent},
+		{"dank_red", colors[1]},
+		{"dank_green", colors[2]},
+		{"dank_yellow", colors[3]},
+		{"dank_blue", colors[4]},
+		{"dank_magenta", colors[5]},
+		{"dank_cyan", colors[6]},
+		{"dank_on_red", onColor(colors[1])},
+	} {
+		fmt.Fprintf(&b, "@define-color %s %s;\n", c.name, c.hex)
+	}
+
+	b.WriteString(`
+window#waybar {
+    background-color: @dank_bg;
+    color: @dank_fg;
+    border-bottom: 1px solid @dank_border;
+}
+
+window#waybar.hidden {
+    opacity: 0.2;
+}
+
+tooltip {
+    background-color: @dank_surface;
+    border: 1px solid @dank_border;
+}
+
+tooltip label {
+    color: @dank_fg;
+}
+
+#workspaces button {
+    color: @dank_muted;
+    background-color: transparent;
+}
+
+#workspaces button:hover {
+    background-color: @dank_surface;
+    color: @dank_fg;
+}
+
+#workspaces button.active,
+#workspaces button.focused {
+    background-color: @dank_accent;
+    color: @dank_on_accent;
+}
+
+#workspaces button.visible {
+    color: @dank_fg;
+}
+
+#workspaces button.urgent {
+    background-color: @dank_red;
+    color: @dank_on_red;
+}
+
+#workspaces button.empty {
+    color: @dank_border;
+}
+
+#clock {
+    color: @dank_fg;
+}
+
+#cpu,
+#memory,
+#temperature,
+#disk {
+    color: @dank_cyan;
+}
+
+#network {
+    color: @dank_blue;
+}
+
+#network.disconnected {
+    color: @dank_muted;
+}
+
+#pulseaudio,
+#wireplumber {
+    color: @dank_magenta;
+}
+
+#pulseaudio.muted,
+#wireplumber.muted {
+    color: @dank_muted;
+}
+
+#backlight {
+    color: @dank_yellow;
+}
+
+#bluetooth {
+    color: @dank_blue;
+}
+
+#tray > .needs-attention {
+    background-color: @dank_red;
+}
+
+#battery {
+    color: @dank_green;
+}
+
+#battery.warning:not(.charging) {
+    color: @dank_yellow;
+}
+
+#battery.critical:not(.charging) {
+    background-color: @dank_red;
+    color: @dank_on_red;
+}
+
+#temperature.critical {
+    color: @dank_red;
+}
+
+#idle_inhibitor.activated {
+    color: @dank_accent;
+}
+`)
+	return b.String()
+}
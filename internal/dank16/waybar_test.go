@@ -0,0 +1,38 @@
+package dank16
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateWaybarCSS(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+	css := GenerateWaybarCSS(colors, false)
+
+	for _, want := range []string{
+		"@define-color dank_bg " + colors[0] + ";\n",
+		"@define-color dank_accent " + colors[4] + ";\n",
+		"@define-color dank_red " + colors[1] + ";\n",
+		"#workspaces button.active,\n#workspaces button.focused {\n    background-color: @dank_accent;",
+		"#battery.warning:not(.charging) {\n    color: @dank_yellow;",
+		"#battery.critical:not(.charging) {\n    background-color: @dank_red;",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("missing %q in:\n%s", want, css)
+		}
+	}
+
+	if strings.Count(css, "{") != strings.Count(css, "}") {
+		t.Error("unbalanced braces")
+	}
+
+	// Every color referenced must be defined
+	for _, line := range strings.Split(css, "\n") {
+		if i := strings.Index(line, "@dank_"); i >= 0 && !strings.HasPrefix(line, "@define-color") {
+			name := strings.TrimRight(line[i+1:], ";")
+			if !strings.Contains(css, "@define-color "+name+" ") {
+				t.Errorf("%s is used but not defined", name)
+			}
+		}
+	}
+}
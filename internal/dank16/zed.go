@@ -0,0 +1,219 @@
+package dank16
+
+import "encoding/json"
+
+const zedSchema = "https://zed.dev/schema/themes/v0.2.0.json"
+
+// ZedThemeFamily is a Zed theme file; it goes in ~/.config/zed/themes
+type ZedThemeFamily struct {
+	Schema string     `json:"$schema"`
+	Name   string     `json:"name"`
+	Author string     `json:"author"`
+	Themes []ZedTheme `json:"themes"`
+}
+
+type ZedTheme struct {
+	Name       string                 `json:"name"`
+	Appearance string                 `json:"appearance"`
+	Style      map[string]interface{} `json:"style"`
+}
+
+type ZedHighlight struct {
+	Color      string `json:"color"`
+	FontStyle  string `json:"font_style,omitempty"`
+	FontWeight int    `json:"font_weight,omitempty"`
+}
+
+type ZedPlayer struct {
+	Cursor     string `json:"cursor"`
+	Background string `json:"background"`
+	Selection  string `json:"selection"`
+}
+
+// zedSyntax maps Zed's highlight names to semantic token types
+var zedSyntax = []semanticScope{
+	{"attribute", "property", ""},
+	{"boolean", "number", ""},
+	{"comment", "comment", "italic"},
+	{"comment.doc", "comment", "italic"},
+	{"constant", "variable.readonly", ""},
+	{"constructor", "class", ""},
+	{"enum", "type", ""},
+	{"function", "function", ""},
+	{"keyword", "keyword", ""},
+	{"label", "keyword", ""},
+	{"namespace", "namespace", ""},
+	{"number", "number", ""},
+	{"operator", "operator", ""},
+	{"preproc", "keyword", ""},
+	{"property", "property", ""},
+	{"string", "string", ""},
+	{"string.special", "string", ""},
+	{"string.special.symbol", "enumMember", ""},
+	{"tag", "class", ""},
+	{"type", "type", ""},
+	{"type.builtin", "typeParameter", ""},
+	{"variable", "variable", ""},
+	{"variable.parameter", "parameter", ""},
+	{"variant", "enumMember", ""},
+}
+
+// GenerateZedTheme emits a Zed theme family with one theme for the palette,
+// covering the UI, terminal and syntax colors
+func GenerateZedTheme(colors []string, isLight bool) string {
+	u := deriveUIColors(colors, isLight)
+	semantic := semanticColors(colors)
+
+	appearance, variant := "dark", "Dark"
+	if isLight {
+		appearance, variant = "light", "Light"
+	}
+
+	muted := Mix(u.fg, u.bg, 0.35)
+	gutter := Mix(u.fg, u.bg, 0.55)
+	hover := Mix(u.raised, u.fg, 0.06)
+	active := Mix(u.raised, u.fg, 0.1)
+
+	style := map[string]interface{}{
+		"background":                                 u.bg,
+		"border":                                     u.border,
+		"border.variant":                             Mix(u.bg, u.fg, 0.08),
+		"border.focused":                             u.accent,
+		"border.selected":                            u.accent,
+		"border.transparent":                         "#00000000",
+		"border.disabled":                            Mix(u.bg, u.fg, 0.08),
+		"elevated_surface.background":                u.raised,
+		"surface.background":                         u.raised,
+		"element.background":                         u.raised,
+		"element.hover":                              hover,
+		"element.active":                             active,
+		"element.selected":                           active,
+		"element.disabled":                           u.raised,
+		"drop_target.background":                     WithAlpha(u.accent, 0.2),
+		"ghost_element.background":                   "#00000000",
+		"ghost_element.hover":                        hover,
+		"ghost_element.active":                       active,
+		"ghost_element.selected":                     active,
+		"ghost_element.disabled":                     "#00000000",
+		"text":                                       u.fg,
+		"text.muted":                                 muted,
+		"text.placeholder":                           gutter,
+		"text.disabled":                              u.disabledFg,
+		"text.accent":                                u.accentText,
+		"icon":                                       u.fg,
+		"icon.muted":                                 muted,
+		"icon.disabled":                              u.disabledFg,
+		"icon.placeholder":                           gutter,
+		"icon.accent":                                u.accentText,
+		"status_bar.background":                      u.raised,
+		"title_bar.background":                       u.raised,
+		"title_bar.inactive_background":              u.bg,
+		"toolbar.background":                         u.bg,
+		"tab_bar.background":                         u.raised,
+		"tab.inactive_background":                    u.raised,
+		"tab.active_background":                      u.bg,
+		"search.match_background":                    WithAlpha(colors[3], 0.3),
+		"panel.background":                           u.raised,
+		"panel.focused_border":                       u.accent,
+		"pane.focused_border":                        u.accent,
+		"scrollbar.thumb.background":                 WithAlpha(u.fg, 0.2),
+		"scrollbar.thumb.hover_background":           WithAlpha(u.fg, 0.35),
+		"scrollbar.thumb.border":                     "#00000000",
+		"scrollbar.track.background":                 "#00000000",
+		"scrollbar.track.border":                     "#00000000",
+		"editor.foreground":                          u.fg,
+		"editor.background":                          u.bg,
+		"editor.gutter.background":                   u.bg,
+		"editor.subheader.background":                u.raised,
+		"editor.active_line.background":              WithAlpha(u.fg, 0.05),
+		"editor.highlighted_line.background":         WithAlpha(u.fg, 0.08),
+		"editor.line_number":                         gutter,
+		"editor.active_line_number":                  u.accentText,
+		"editor.invisible":                           u.border,
+		"editor.wrap_guide":                          Mix(u.bg, u.fg, 0.08),
+		"editor.active_wrap_guide":                   u.border,
+		"editor.document_highlight.read_background":  WithAlpha(u.accent, 0.15),
+		"editor.document_highlight.write_background": WithAlpha(u.accent, 0.25),
+		"link_text.hover":                            colors[12],
+		"terminal.background":                        u.bg,
+		"terminal.foreground":                        u.fg,
+		"terminal.bright_foreground":                 colors[15],
+		"terminal.dim_foreground":                    muted,
+	}
+
+	status := []struct {
+		name  string
+		color string
+	}{
+		{"conflict", colors[3]},
+		{"created", colors[2]},
+		{"deleted", colors[1]},
+		{"error", colors[1]},
+		{"hidden", gutter},
+		{"hint", colors[6]},
+		{"ignored", gutter},
+		{"info", colors[4]},
+		{"modified", colors[3]},
+		{"predictive", gutter},
+		{"renamed", colors[4]},
+		{"success", colors[2]},
+		{"unreachable", muted},
+		{"warning", colors[3]},
+	}
+	for _, s := range status {
+		style[s.name] = s.color
+		style[s.name+".background"] = WithAlpha(s.color, 0.15)
+		style[s.name+".border"] = s.color
+	}
+
+	ansi := []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+	for i, name := range ansi {
+		style["terminal.ansi."+name] = colors[i]
+		style["terminal.ansi.bright_"+name] = colors[i+8]
+		style["terminal.ansi.dim_"+name] = Mix(colors[i], u.bg, 0.3)
+	}
+
+	style["players"] = []ZedPlayer{
+		{Cursor: u.accent, Background: u.accent, Selection: WithAlpha(u.accent, 0.3)},
+		{Cursor: colors[5], Background: colors[5], Selection: WithAlpha(colors[5], 0.3)},
+		{Cursor: colors[2], Background: colors[2], Selection: WithAlpha(colors[2], 0.3)},
+		{Cursor: colors[3], Background: colors[3], Selection: WithAlpha(colors[3], 0.3)},
+	}
+
+	syntax := map[string]ZedHighlight{
+		"emphasis":                {Color: u.accentText, FontStyle: "italic"},
+		"emphasis.strong":         {Color: u.accentText, FontWeight: 700},
+		"link_text":               {Color: colors[4]},
+		"link_uri":                {Color: colors[6], FontStyle: "italic"},
+		"punctuation":             {Color: muted},
+		"punctuation.bracket":     {Color: muted},
+		"punctuation.delimiter":   {Color: muted},
+		"punctuation.list_marker": {Color: colors[5]},
+		"punctuation.special":     {Color: colors[6]},
+		"string.escape":           {Color: colors[6]},
+		"string.regex":            {Color: colors[6]},
+		"text.literal":            {Color: colors[3]},
+		"title":                   {Color: u.accentText, FontWeight: 700},
+		"variable.special":        {Color: colors[12]},
+		"hint":                    {Color: gutter},
+		"predictive":              {Color: gutter, FontStyle: "italic"},
+	}
+	for _, s := range zedSyntax {
+		syntax[s.name] = ZedHighlight{Color: semantic[s.semantic], FontStyle: s.style}
+	}
+	style["syntax"] = syntax
+
+	family := ZedThemeFamily{
+		Schema: zedSchema,
+		Name:   "Dank16",
+		Author: "dank16",
+		Themes: []ZedTheme{{
+			Name:       "Dank16 " + variant,
+			Appearance: appearance,
+			Style:      style,
+		}},
+	}
+
+	marshalled, _ := json.MarshalIndent(family, "", "  ")
+	return string(marshalled) + "\n"
+}
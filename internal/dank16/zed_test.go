@@ -0,0 +1,71 @@
+package dank16
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGenerateZedTheme(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: false})
+
+	var family struct {
+		Schema string `json:"$schema"`
+		Themes []struct {
+			Appearance string `json:"appearance"`
+			Style      struct {
+				Background string                  `json:"background"`
+				Syntax     map[string]ZedHighlight `json:"syntax"`
+				Players    []ZedPlayer             `json:"players"`
+			} `json:"style"`
+		} `json:"themes"`
+	}
+	if err := json.Unmarshal([]byte(GenerateZedTheme(colors, false)), &family); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if family.Schema != zedSchema || len(family.Themes) != 1 {
+		t.Fatalf("unexpected family %+v", family)
+	}
+
+	theme := family.Themes[0]
+	if theme.Appearance != "dark" {
+		t.Errorf("appearance = %q, want dark", theme.Appearance)
+	}
+	if theme.Style.Background != colors[0] {
+		t.Errorf("background = %s, want %s", theme.Style.Background, colors[0])
+	}
+	if len(theme.Style.Players) == 0 {
+		t.Error("no player colors")
+	}
+
+	semantic := semanticColors(colors)
+	for _, s := range zedSyntax {
+		if got := theme.Style.Syntax[s.name].Color; got != semantic[s.semantic] {
+			t.Errorf("syntax %s = %s, want %s like VSCode's %s", s.name, got, semantic[s.semantic], s.semantic)
+		}
+	}
+}
+
+func TestGenerateZedThemeTerminal(t *testing.T) {
+	colors := GeneratePalette("#625690", PaletteOptions{IsLight: true})
+
+	var family ZedThemeFamily
+	if err := json.Unmarshal([]byte(GenerateZedTheme(colors, true)), &family); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	style := family.Themes[0].Style
+	if family.Themes[0].Appearance != "light" {
+		t.Errorf("appearance = %q, want light", family.Themes[0].Appearance)
+	}
+
+	for key, want := range map[string]string{
+		"terminal.ansi.black":        colors[0],
+		"terminal.ansi.red":          colors[1],
+		"terminal.ansi.white":        colors[7],
+		"terminal.ansi.bright_red":   colors[9],
+		"terminal.ansi.bright_white": colors[15],
+	} {
+		if style[key] != want {
+			t.Errorf("%s = %v, want %s", key, style[key], want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package deps
+
+// InstallProfile pre-selects which optional dependencies get installed.
+// Every choice stays editable in the dependency review afterwards.
+type InstallProfile int
+
+const (
+	// ProfileMinimal installs the window manager and the shell
+	ProfileMinimal InstallProfile = iota
+	// ProfileStandard adds clipboard history and screenshot tools
+	ProfileStandard
+	// ProfileFull adds every optional tool and sets up the greeter
+	ProfileFull
+)
+
+var InstallProfiles = []InstallProfile{ProfileMinimal, ProfileStandard, ProfileFull}
+
+func (p InstallProfile) String() string {
+	switch p {
+	case ProfileMinimal:
+		return "Minimal"
+	case ProfileStandard:
+		return "Standard"
+	case ProfileFull:
+		return "Full"
+	default:
+		return "Unknown"
+	}
+}
+
+func (p InstallProfile) Description() string {
+	switch p {
+	case ProfileMinimal:
+		return "Window manager, shell and what they need to run."
+	case ProfileStandard:
+		return "Minimal plus clipboard history and screenshot tools."
+	case ProfileFull:
+		return "Everything, including the color picker and greeter session setup."
+	default:
+		return ""
+	}
+}
+
+// optionalDeps names the dependencies a profile may leave out and the
+// smallest profile that includes them. Anything not listed is needed by
+// every profile.
+var optionalDeps = map[string]InstallProfile{
+	"cliphist":     ProfileStandard,
+	"wl-clipboard": ProfileStandard,
+	"grim":         ProfileStandard,
+	"slurp":        ProfileStandard,
+	"grimblast":    ProfileStandard,
+	"jq":           ProfileStandard,
+	"hyprpicker":   ProfileFull,
+}
+
+// IsOptional reports whether the named dependency can be left out
+func IsOptional(name string) bool {
+	_, ok := optionalDeps[name]
+	return ok
+}
+
+// Includes reports whether the profile installs the named dependency
+func (p InstallProfile) Includes(name string) bool {
+	minimum, ok := optionalDeps[name]
+	return !ok || p >= minimum
+}
+
+// SetsUpGreeter reports whether the profile preselects the compositor in
+// the greeter during session setup
+func (p InstallProfile) SetsUpGreeter() bool {
+	return p >= ProfileFull
+}
@@ -0,0 +1,42 @@
+package deps
+
+import "testing"
+
+func TestInstallProfileIncludes(t *testing.T) {
+	tests := []struct {
+		name     string
+		minimal  bool
+		standard bool
+		full     bool
+	}{
+		{"niri", true, true, true},
+		{"dms (DankMaterialShell)", true, true, true},
+		{"quickshell", true, true, true},
+		{"cliphist", false, true, true},
+		{"grim", false, true, true},
+		{"hyprpicker", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProfileMinimal.Includes(tt.name); got != tt.minimal {
+				t.Errorf("minimal includes %s = %v, want %v", tt.name, got, tt.minimal)
+			}
+			if got := ProfileStandard.Includes(tt.name); got != tt.standard {
+				t.Errorf("standard includes %s = %v, want %v", tt.name, got, tt.standard)
+			}
+			if got := ProfileFull.Includes(tt.name); got != tt.full {
+				t.Errorf("full includes %s = %v, want %v", tt.name, got, tt.full)
+			}
+		})
+	}
+}
+
+func TestInstallProfileGreeter(t *testing.T) {
+	if ProfileStandard.SetsUpGreeter() {
+		t.Error("standard profile should leave the greeter alone")
+	}
+	if !ProfileFull.SetsUpGreeter() {
+		t.Error("full profile should set up the greeter")
+	}
+}
@@ -291,7 +291,7 @@ func (a *ArchDistribution) InstallPrerequisites(ctx context.Context, sudoPasswor
 		LogOutput:   "Installing base-devel development tools",
 	}
 
-	cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S pacman -S --needed --noconfirm base-devel", sudoPassword))
+	cmd := exec.CommandContext(ctx, "bash", "-c", sudoCommand(sudoPassword, "pacman -S --needed --noconfirm base-devel"))
 	if err := a.runWithProgress(cmd, progressChan, PhasePrerequisites, 0.08, 0.10); err != nil {
 		return fmt.Errorf("failed to install base-devel: %w", err)
 	}
@@ -375,6 +375,8 @@ func (a *ArchDistribution) InstallPackages(ctx context.Context, dependencies []d
 		LogOutput:  "Starting post-installation configuration...",
 	}
 
+	a.ConfigureSystemPrerequisites(ctx, sudoPassword, progressChan)
+
 	// Phase 7: Complete
 	progressChan <- InstallProgressMsg{
 		Phase:      PhaseComplete,
@@ -444,7 +446,7 @@ func (a *ArchDistribution) installSystemPackages(ctx context.Context, packages [
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := sudoCommand(sudoPassword, strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return a.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.40, 0.60)
 }
@@ -563,7 +565,7 @@ func (a *ArchDistribution) reorderAURPackages(packages []string) []string {
 }
 
 func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sudoPassword string, progressChan chan<- InstallProgressMsg, startProgress, endProgress float64) error {
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := a.buildHome()
 	if err != nil {
 		return fmt.Errorf("failed to get user home directory: %w", err)
 	}
@@ -578,6 +580,9 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 	if err := os.MkdirAll(buildDir, 0755); err != nil {
 		return fmt.Errorf("failed to create build directory: %w", err)
 	}
+	if err := a.chownToTargetUser(ctx, filepath.Join(homeDir, ".cache", "dankinstall")); err != nil {
+		return fmt.Errorf("failed to hand build directory to %s: %w", a.targetUser, err)
+	}
 	defer func() {
 		if removeErr := os.RemoveAll(buildDir); removeErr != nil {
 			a.log(fmt.Sprintf("Warning: failed to cleanup build directory %s: %v", buildDir, removeErr))
@@ -593,7 +598,7 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 		CommandInfo: fmt.Sprintf("git clone https://aur.archlinux.org/%s.git", pkg),
 	}
 
-	cloneCmd := exec.CommandContext(ctx, "git", "clone", fmt.Sprintf("https://aur.archlinux.org/%s.git", pkg), filepath.Join(buildDir, pkg))
+	cloneCmd := a.asTargetUser(ctx, "git", "clone", fmt.Sprintf("https://aur.archlinux.org/%s.git", pkg), filepath.Join(buildDir, pkg))
 	if err := a.runWithProgress(cloneCmd, progressChan, PhaseAURPackages, startProgress+0.1*(endProgress-startProgress), startProgress+0.2*(endProgress-startProgress)); err != nil {
 		return fmt.Errorf("failed to clone %s: %w", pkg, err)
 	}
@@ -658,9 +663,9 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 					deps=$(echo "$deps" | sed 's/google-breakpad//g' | sed 's/  / /g' | sed 's/^ *//g' | sed 's/ *$//g')
 				fi
 				if [ ! -z "$deps" ] && [ "$deps" != " " ]; then
-					echo '%s' | sudo -S pacman -S --needed --noconfirm $deps
+					%s
 				fi
-			`, srcinfoPath, pkg, sudoPassword))
+			`, srcinfoPath, pkg, sudoCommand(sudoPassword, "pacman -S --needed --noconfirm $deps")))
 
 		if err := a.runWithProgress(depsCmd, progressChan, PhaseAURPackages, startProgress+0.3*(endProgress-startProgress), startProgress+0.35*(endProgress-startProgress)); err != nil {
 			return fmt.Errorf("FAILED to install runtime dependencies for %s: %w", pkg, err)
@@ -670,9 +675,9 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 			fmt.Sprintf(`
 				makedeps=$(grep -E "^[[:space:]]*makedepends = " "%s" | sed 's/^[[:space:]]*makedepends = //' | tr '\n' ' ')
 				if [ ! -z "$makedeps" ]; then
-					echo '%s' | sudo -S pacman -S --needed --noconfirm $makedeps
+					%s
 				fi
-			`, srcinfoPath, sudoPassword))
+			`, srcinfoPath, sudoCommand(sudoPassword, "pacman -S --needed --noconfirm $makedeps")))
 
 		if err := a.runWithProgress(makedepsCmd, progressChan, PhaseAURPackages, startProgress+0.35*(endProgress-startProgress), startProgress+0.4*(endProgress-startProgress)); err != nil {
 			return fmt.Errorf("FAILED to install make dependencies for %s: %w", pkg, err)
@@ -695,7 +700,7 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 		CommandInfo: "makepkg --noconfirm",
 	}
 
-	buildCmd := exec.CommandContext(ctx, "makepkg", "--noconfirm")
+	buildCmd := a.asTargetUser(ctx, "makepkg", "--noconfirm")
 	buildCmd.Dir = packageDir
 	buildCmd.Env = append(os.Environ(), "PKGEXT=.pkg.tar") // Disable compression for speed
 
@@ -756,7 +761,7 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 	installArgs := []string{"pacman", "-U", "--noconfirm"}
 	installArgs = append(installArgs, files...)
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(installArgs, " "))
+	cmdStr := sudoCommand(sudoPassword, strings.Join(installArgs, " "))
 	installCmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 
 	fileNames := make([]string, len(files))
@@ -0,0 +1,280 @@
+package distros
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+const (
+	bootstrapBinaryPath = "/usr/local/bin/dankinstall"
+	firstLoginUnitName  = "dms-first-login.service"
+	firstLoginUnitDir   = "/etc/systemd/user"
+	firstLoginStateFile = "first-login.json"
+)
+
+// BootstrapOptions configures an install from inside a fresh arch-chroot
+type BootstrapOptions struct {
+	User          string
+	WindowManager deps.WindowManager
+	Terminal      deps.Terminal
+}
+
+// FirstLoginState is what bootstrap leaves behind for the session-dependent
+// steps that run on the user's first graphical login.
+type FirstLoginState struct {
+	User          string `json:"user"`
+	WindowManager string `json:"windowManager"`
+	Terminal      string `json:"terminal"`
+}
+
+var windowManagerNames = map[deps.WindowManager]string{
+	deps.WindowManagerHyprland: "hyprland",
+	deps.WindowManagerNiri:     "niri",
+}
+
+var terminalNames = map[deps.Terminal]string{
+	deps.TerminalGhostty:   "ghostty",
+	deps.TerminalKitty:     "kitty",
+	deps.TerminalAlacritty: "alacritty",
+}
+
+// ParseWindowManager maps a compositor name to its deps value
+func ParseWindowManager(name string) (deps.WindowManager, error) {
+	for wm, n := range windowManagerNames {
+		if n == name {
+			return wm, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown window manager: %s", name)
+}
+
+// ParseTerminal maps a terminal name to its deps value
+func ParseTerminal(name string) (deps.Terminal, error) {
+	for t, n := range terminalNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown terminal: %s", name)
+}
+
+// IsChroot reports whether the process root differs from init's, as it does
+// under arch-chroot from the live ISO.
+func IsChroot() bool {
+	var root, initRoot syscall.Stat_t
+	if err := syscall.Stat("/", &root); err != nil {
+		return false
+	}
+	if err := syscall.Stat("/proc/1/root", &initRoot); err != nil {
+		return false
+	}
+	return root.Dev != initRoot.Dev || root.Ino != initRoot.Ino
+}
+
+// Bootstrap installs packages for opts.User without a Wayland session and
+// stages a systemd user unit that deploys configs on first login.
+func (a *ArchDistribution) Bootstrap(ctx context.Context, opts BootstrapOptions, progressChan chan<- InstallProgressMsg) error {
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("bootstrap must run as root")
+	}
+	if _, err := user.Lookup(opts.User); err != nil {
+		return fmt.Errorf("failed to find user %s: %w", opts.User, err)
+	}
+
+	a.targetUser = opts.User
+	defer func() { a.targetUser = "" }()
+
+	dependencies, err := a.DetectDependenciesWithTerminal(ctx, opts.WindowManager, opts.Terminal)
+	if err != nil {
+		return fmt.Errorf("failed to detect dependencies: %w", err)
+	}
+
+	if err := a.InstallPackages(ctx, dependencies, opts.WindowManager, "", nil, progressChan); err != nil {
+		return err
+	}
+
+	a.log(fmt.Sprintf("Staging first-login setup for %s", opts.User))
+	return a.stageFirstLogin(ctx, opts)
+}
+
+func (a *ArchDistribution) stageFirstLogin(ctx context.Context, opts BootstrapOptions) error {
+	u, err := user.Lookup(opts.User)
+	if err != nil {
+		return fmt.Errorf("failed to find user %s: %w", opts.User, err)
+	}
+
+	if err := installBootstrapBinary(bootstrapBinaryPath); err != nil {
+		return fmt.Errorf("failed to install %s: %w", bootstrapBinaryPath, err)
+	}
+
+	unitPath := filepath.Join(firstLoginUnitDir, firstLoginUnitName)
+	if err := os.MkdirAll(firstLoginUnitDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", firstLoginUnitDir, err)
+	}
+	if err := os.WriteFile(unitPath, []byte(firstLoginUnit()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", unitPath, err)
+	}
+
+	configDir := filepath.Join(u.HomeDir, ".config")
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return fmt.Errorf("unexpected uid %q for %s: %w", u.Uid, opts.User, err)
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return fmt.Errorf("unexpected gid %q for %s: %w", u.Gid, opts.User, err)
+	}
+	if err := ensureUserConfigDir(configDir, uid, gid); err != nil {
+		return err
+	}
+	if err := writeFirstLoginState(FirstLoginStatePath(u.HomeDir), FirstLoginState{
+		User:          opts.User,
+		WindowManager: windowManagerNames[opts.WindowManager],
+		Terminal:      terminalNames[opts.Terminal],
+	}); err != nil {
+		return err
+	}
+
+	// Equivalent to `systemctl --user enable`, which needs a user manager
+	// that does not exist inside the chroot.
+	wantsDir := filepath.Join(configDir, "systemd", "user", "default.target.wants")
+	if err := os.MkdirAll(wantsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", wantsDir, err)
+	}
+	link := filepath.Join(wantsDir, firstLoginUnitName)
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %w", link, err)
+	}
+	if err := os.Symlink(unitPath, link); err != nil {
+		return fmt.Errorf("failed to enable %s: %w", firstLoginUnitName, err)
+	}
+
+	for _, dir := range []string{filepath.Join(configDir, "dankinstall"), filepath.Join(configDir, "systemd")} {
+		if err := a.chownToTargetUser(ctx, dir); err != nil {
+			return fmt.Errorf("failed to hand %s to %s: %w", dir, opts.User, err)
+		}
+	}
+	return nil
+}
+
+func firstLoginUnit() string {
+	return fmt.Sprintf(`[Unit]
+Description=DankMaterialShell first login setup
+ConditionPathExists=%%h/.config/dankinstall/%s
+
+[Service]
+Type=oneshot
+ExecStart=%s --first-login
+
+[Install]
+WantedBy=default.target
+`, firstLoginStateFile, bootstrapBinaryPath)
+}
+
+// installBootstrapBinary copies the running installer into the new system so
+// the first-login unit can call it after the live ISO is gone.
+func installBootstrapBinary(dest string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if self == dest {
+		return nil
+	}
+
+	src, err := os.Open(self)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp := dest + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// ensureUserConfigDir creates ~/.config for the target user when the home
+// came from a skel without one. An existing directory is left alone.
+// Bootstrap runs as root, so otherwise creating the dankinstall and systemd
+// directories would leave it root-owned and first-login setup could not
+// write any configs.
+func ensureUserConfigDir(dir string, uid, gid int) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to check %s: %w", dir, err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.Chown(dir, uid, gid); err != nil {
+		return fmt.Errorf("failed to hand %s to uid %d: %w", dir, uid, err)
+	}
+	return nil
+}
+
+// FirstLoginStatePath is the marker the first-login unit is conditioned on
+func FirstLoginStatePath(homeDir string) string {
+	return filepath.Join(homeDir, ".config", "dankinstall", firstLoginStateFile)
+}
+
+func writeFirstLoginState(path string, state FirstLoginState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFirstLoginState loads the choices made during bootstrap
+func ReadFirstLoginState(path string) (deps.WindowManager, deps.Terminal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var state FirstLoginState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	wm, err := ParseWindowManager(state.WindowManager)
+	if err != nil {
+		return 0, 0, err
+	}
+	terminal, err := ParseTerminal(state.Terminal)
+	if err != nil {
+		return 0, 0, err
+	}
+	return wm, terminal, nil
+}
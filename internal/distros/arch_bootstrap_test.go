@@ -0,0 +1,75 @@
+package distros
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstLoginState_RoundTrip(t *testing.T) {
+	path := FirstLoginStatePath(t.TempDir())
+
+	require.NoError(t, writeFirstLoginState(path, FirstLoginState{
+		User:          "dank",
+		WindowManager: windowManagerNames[deps.WindowManagerNiri],
+		Terminal:      terminalNames[deps.TerminalKitty],
+	}))
+
+	wm, terminal, err := ReadFirstLoginState(path)
+	require.NoError(t, err)
+	assert.Equal(t, deps.WindowManagerNiri, wm)
+	assert.Equal(t, deps.TerminalKitty, terminal)
+}
+
+func TestParseWindowManagerAndTerminal(t *testing.T) {
+	wm, err := ParseWindowManager("hyprland")
+	require.NoError(t, err)
+	assert.Equal(t, deps.WindowManagerHyprland, wm)
+
+	_, err = ParseWindowManager("sway")
+	assert.Error(t, err)
+
+	terminal, err := ParseTerminal("alacritty")
+	require.NoError(t, err)
+	assert.Equal(t, deps.TerminalAlacritty, terminal)
+
+	_, err = ParseTerminal("xterm")
+	assert.Error(t, err)
+}
+
+func TestFirstLoginUnit(t *testing.T) {
+	unit := firstLoginUnit()
+	assert.Contains(t, unit, "ConditionPathExists=%h/.config/dankinstall/first-login.json")
+	assert.Contains(t, unit, "ExecStart=/usr/local/bin/dankinstall --first-login")
+	assert.Contains(t, unit, "WantedBy=default.target")
+}
+
+func TestEnsureUserConfigDir(t *testing.T) {
+	t.Run("creates a missing .config owned by the user", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), ".config")
+		require.NoError(t, ensureUserConfigDir(dir, os.Getuid(), os.Getgid()))
+
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+		stat := info.Sys().(*syscall.Stat_t)
+		assert.Equal(t, uint32(os.Getuid()), stat.Uid)
+		assert.Equal(t, uint32(os.Getgid()), stat.Gid)
+	})
+
+	t.Run("leaves an existing .config alone", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), ".config")
+		require.NoError(t, os.Mkdir(dir, 0700))
+		require.NoError(t, ensureUserConfigDir(dir, os.Getuid(), os.Getgid()))
+
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -23,6 +24,10 @@ const forceDMSGit = false
 // BaseDistribution provides common functionality for all distributions
 type BaseDistribution struct {
 	logChan chan<- string
+
+	// targetUser is the account DMS is installed for when running as root
+	// (bootstrap from a chroot). Empty means the invoking user.
+	targetUser string
 }
 
 // NewBaseDistribution creates a new base distribution
@@ -42,6 +47,64 @@ func (b *BaseDistribution) CommandExists(cmd string) bool {
 	return b.commandExists(cmd)
 }
 
+// sudoCommand feeds the password to sudo for a shell command, or runs it
+// directly when already root (e.g. inside arch-chroot).
+func sudoCommand(sudoPassword, command string) string {
+	if os.Geteuid() == 0 {
+		return command
+	}
+	return fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, command)
+}
+
+// sudoExec runs a command as root without a shell, feeding the password to
+// sudo on stdin, or runs it directly when already root. Paths and names read
+// back from the install manifest go through here so they stay arguments.
+func sudoExec(ctx context.Context, sudoPassword, name string, args ...string) *exec.Cmd {
+	if os.Geteuid() == 0 {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	cmd := exec.CommandContext(ctx, "sudo", append([]string{"-S", "-p", "", "--", name}, args...)...)
+	cmd.Stdin = strings.NewReader(sudoPassword + "\n")
+	return cmd
+}
+
+// lookupTargetUser returns the account DMS is being installed for
+func (b *BaseDistribution) lookupTargetUser() (*user.User, error) {
+	if b.targetUser != "" {
+		return user.Lookup(b.targetUser)
+	}
+	return user.Current()
+}
+
+// buildHome is where per-user build caches live, which must belong to the
+// target user since makepkg refuses to run as root.
+func (b *BaseDistribution) buildHome() (string, error) {
+	if b.targetUser == "" {
+		return os.UserHomeDir()
+	}
+	u, err := user.Lookup(b.targetUser)
+	if err != nil {
+		return "", err
+	}
+	return u.HomeDir, nil
+}
+
+// asTargetUser runs an unprivileged build step, dropping root when installing
+// on behalf of another user.
+func (b *BaseDistribution) asTargetUser(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if b.targetUser == "" || os.Geteuid() != 0 {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	return exec.CommandContext(ctx, "runuser", append([]string{"-u", b.targetUser, "--", name}, args...)...)
+}
+
+func (b *BaseDistribution) chownToTargetUser(ctx context.Context, path string) error {
+	if b.targetUser == "" || os.Geteuid() != 0 {
+		return nil
+	}
+	return exec.CommandContext(ctx, "chown", "-R", b.targetUser+":", path).Run()
+}
+
 func (b *BaseDistribution) log(message string) {
 	if b.logChan != nil {
 		b.logChan <- message
@@ -590,8 +653,7 @@ func (b *BaseDistribution) installDMSBinary(ctx context.Context, sudoPassword st
 	downloadURL := fmt.Sprintf("https://github.com/AvengeMedia/danklinux/releases/download/%s/dms-%s.gz", version, arch)
 	gzPath := filepath.Join(tmpDir, "dms.gz")
 
-	downloadCmd := exec.CommandContext(ctx, "curl", "-L", downloadURL, "-o", gzPath)
-	if err := downloadCmd.Run(); err != nil {
+	if err := b.downloadFile(ctx, downloadURL, gzPath); err != nil {
 		return fmt.Errorf("failed to download DMS binary: %w", err)
 	}
 
@@ -627,8 +689,7 @@ func (b *BaseDistribution) installDMSBinary(ctx context.Context, sudoPassword st
 	}
 
 	// Install to /usr/local/bin
-	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S cp %s /usr/local/bin/dms", sudoPassword, binaryPath))
+	installCmd := sudoExec(ctx, sudoPassword, "cp", binaryPath, "/usr/local/bin/dms")
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install DMS binary: %w", err)
 	}
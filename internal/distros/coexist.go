@@ -0,0 +1,264 @@
+package distros
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StepCoexist prefixes the manifest IDs of conflicting services kept out of
+// DMS sessions; the unit or autostart entry name follows after a colon
+const StepCoexist = "coexist"
+
+// coexistDropIn is the drop-in that keeps a systemd user unit out of DMS
+// sessions
+const coexistDropIn = "dms-coexist.conf"
+
+var (
+	systemUserUnitDirs = []string{"/etc/systemd/user", "/usr/lib/systemd/user", "/usr/local/lib/systemd/user"}
+	xsessionsDir       = "/usr/share/xsessions"
+	xdgAutostartDir    = "/etc/xdg/autostart"
+)
+
+// dmsDesktopNames are the XDG_CURRENT_DESKTOP values of the sessions DMS
+// runs in
+var dmsDesktopNames = []string{"niri", "Hyprland"}
+
+// DesktopConflict is a service, usually from a full desktop installed
+// alongside, that fights DMS when it runs in the same session
+type DesktopConflict struct {
+	// Desktop is the desktop it belongs to; empty for standalone agents
+	Desktop string
+	// Unit is a systemd user unit, Autostart the path of an XDG autostart
+	// entry; exactly one is set
+	Unit      string
+	Autostart string
+	Reason    string
+}
+
+// Name is the unit or the autostart entry's file name
+func (c DesktopConflict) Name() string {
+	if c.Unit != "" {
+		return c.Unit
+	}
+	return filepath.Base(c.Autostart)
+}
+
+// CoexistReport lists the other desktops installed and the services of
+// theirs that would also start in a DMS session
+type CoexistReport struct {
+	Desktops  []string
+	Conflicts []DesktopConflict
+}
+
+type conflictSpec struct {
+	desktop   string
+	unit      string
+	autostart string
+	reason    string
+}
+
+// desktopMarkers are the session entries that tell a desktop is installed
+var desktopMarkers = []struct {
+	desktop string
+	prefix  string
+}{
+	{"GNOME", "gnome"},
+	{"KDE Plasma", "plasma"},
+}
+
+var knownConflicts = []conflictSpec{
+	{desktop: "GNOME", unit: "org.gnome.SettingsDaemon.Power.service", reason: "dims and suspends on its own timers and fights DMS over screen brightness"},
+	{desktop: "GNOME", unit: "org.gnome.SettingsDaemon.Color.service", reason: "applies its own night light on top of DMS's"},
+	{desktop: "GNOME", unit: "org.gnome.SettingsDaemon.MediaKeys.service", reason: "shows a second OSD for volume and brightness keys"},
+	{desktop: "GNOME", autostart: "polkit-gnome-authentication-agent-1.desktop", reason: "registers a second polkit agent"},
+	{desktop: "KDE Plasma", unit: "plasma-powerdevil.service", reason: "dims and suspends on its own timers, fights DMS over screen brightness and shows a second OSD"},
+	{desktop: "KDE Plasma", unit: "plasma-polkit-agent.service", reason: "registers a second polkit agent"},
+	{desktop: "KDE Plasma", autostart: "polkit-kde-authentication-agent-1.desktop", reason: "registers a second polkit agent"},
+	{desktop: "KDE Plasma", unit: "xdg-desktop-portal-kde.service", reason: "answers portal requests with Plasma dialogs"},
+	{unit: "hyprpolkitagent.service", reason: "registers a second polkit agent"},
+	{autostart: "lxpolkit.desktop", reason: "registers a second polkit agent"},
+	{autostart: "polkit-mate-authentication-agent-1.desktop", reason: "registers a second polkit agent; DMS starts its own"},
+	{unit: "xdg-desktop-portal-lxqt.service", reason: "answers portal requests with LXQt dialogs"},
+}
+
+// DetectDesktopConflicts looks for GNOME and Plasma installs and for the
+// services, of theirs or standalone, that would also run in a DMS session
+func DetectDesktopConflicts(homeDir string) CoexistReport {
+	return detectDesktopConflicts(
+		[]string{waylandSessionsDir, xsessionsDir},
+		append([]string{filepath.Join(homeDir, ".config", "systemd", "user")}, systemUserUnitDirs...),
+		[]string{filepath.Join(homeDir, ".config", "autostart"), xdgAutostartDir},
+	)
+}
+
+func detectDesktopConflicts(sessionDirs, unitDirs, autostartDirs []string) CoexistReport {
+	var report CoexistReport
+
+	installed := make(map[string]bool)
+	for _, marker := range desktopMarkers {
+		for _, dir := range sessionDirs {
+			matches, _ := filepath.Glob(filepath.Join(dir, marker.prefix+"*.desktop"))
+			if len(matches) > 0 {
+				installed[marker.desktop] = true
+			}
+		}
+		if installed[marker.desktop] {
+			report.Desktops = append(report.Desktops, marker.desktop)
+		}
+	}
+
+	for _, spec := range knownConflicts {
+		if spec.desktop != "" && !installed[spec.desktop] {
+			continue
+		}
+		conflict := DesktopConflict{Desktop: spec.desktop, Unit: spec.unit, Reason: spec.reason}
+		if spec.unit != "" {
+			if !unitInstalled(unitDirs, spec.unit) || unitInstalled(unitDirs, filepath.Join(spec.unit+".d", coexistDropIn)) {
+				continue
+			}
+		} else {
+			path := findAutostart(autostartDirs, spec.autostart)
+			if path == "" {
+				continue
+			}
+			conflict.Autostart = path
+		}
+		report.Conflicts = append(report.Conflicts, conflict)
+	}
+	return report
+}
+
+// unitInstalled also finds drop-ins, given as unit.d/name
+func unitInstalled(dirs []string, unit string) bool {
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, unit)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// findAutostart returns the autostart entry that takes effect, the first
+// in dirs, as long as it would start in a DMS session
+func findAutostart(dirs []string, name string) string {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if autostartsInDMS(string(data)) {
+			return path
+		}
+		return ""
+	}
+	return ""
+}
+
+// autostartsInDMS applies an autostart entry's Hidden, OnlyShowIn and
+// NotShowIn keys to the DMS desktops
+func autostartsInDMS(entry string) bool {
+	if iniValue(entry, "Desktop Entry", "Hidden") == "true" {
+		return false
+	}
+	only := desktopList(iniValue(entry, "Desktop Entry", "OnlyShowIn"))
+	not := desktopList(iniValue(entry, "Desktop Entry", "NotShowIn"))
+	for _, name := range dmsDesktopNames {
+		if (len(only) == 0 || only[strings.ToLower(name)]) && !not[strings.ToLower(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+func desktopList(value string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(value, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[strings.ToLower(name)] = true
+		}
+	}
+	return names
+}
+
+// coexistUnitDropIn keeps a unit from starting while the user manager's
+// environment names a DMS desktop, which the compositor imports at login.
+// Other sessions still start it.
+func coexistUnitDropIn(unit string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Written by dankinstall: keep %s out of DMS sessions\n", unit)
+	b.WriteString("[Unit]\n")
+	for _, name := range dmsDesktopNames {
+		fmt.Fprintf(&b, "ConditionEnvironment=!XDG_CURRENT_DESKTOP=%s\n", name)
+	}
+	return b.String()
+}
+
+// coexistAutostart adds the DMS desktops to an autostart entry's NotShowIn
+func coexistAutostart(entry string) string {
+	value := iniValue(entry, "Desktop Entry", "NotShowIn")
+	present := desktopList(value)
+	for _, name := range dmsDesktopNames {
+		if present[strings.ToLower(name)] {
+			continue
+		}
+		if value != "" && !strings.HasSuffix(value, ";") {
+			value += ";"
+		}
+		value += name + ";"
+	}
+	return setIniKey(entry, "Desktop Entry", "NotShowIn", value)
+}
+
+// maskDesktopConflicts keeps the conflicting services out of DMS sessions
+// only: units get a drop-in conditioned on XDG_CURRENT_DESKTOP and autostart
+// entries a user override with NotShowIn. Both are user files recorded in
+// the manifest, so the revert removes them again.
+func (b *BaseDistribution) maskDesktopConflicts(ctx context.Context, homeDir string, manifest *InstallManifest, report *SessionReport) {
+	conflicts := DetectDesktopConflicts(homeDir).Conflicts
+	if len(conflicts) == 0 {
+		report.Skipped = append(report.Skipped, "No conflicting desktop services found")
+		return
+	}
+
+	reload := false
+	for _, c := range conflicts {
+		var path, content string
+		if c.Unit != "" {
+			path = filepath.Join(homeDir, ".config", "systemd", "user", c.Unit+".d", coexistDropIn)
+			content = coexistUnitDropIn(c.Unit)
+		} else {
+			data, err := os.ReadFile(c.Autostart)
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("failed to read %s: %v", c.Autostart, err))
+				continue
+			}
+			path = filepath.Join(homeDir, ".config", "autostart", filepath.Base(c.Autostart))
+			content = coexistAutostart(string(data))
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to create %s: %v", filepath.Dir(path), err))
+			continue
+		}
+		recordUserFile(manifest, StepCoexist+":"+c.Name(), "kept "+c.Name()+" out of DMS sessions", path)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to write %s: %v", path, err))
+			continue
+		}
+		if err := b.chownToTargetUser(ctx, filepath.Dir(path)); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to chown %s: %v", path, err))
+		}
+		reload = reload || c.Unit != ""
+		report.Applied = append(report.Applied, fmt.Sprintf("Kept %s out of DMS sessions; it %s", c.Name(), c.Reason))
+	}
+
+	if reload {
+		if out, err := b.asTargetUser(ctx, "systemctl", "--user", "daemon-reload").CombinedOutput(); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("systemctl --user daemon-reload: %v: %s", err, strings.TrimSpace(string(out))))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package distros
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDesktopConflicts(t *testing.T) {
+	root := t.TempDir()
+	sessions := filepath.Join(root, "wayland-sessions")
+	userUnits := filepath.Join(root, "home", "systemd")
+	units := filepath.Join(root, "systemd")
+	userAutostart := filepath.Join(root, "home", "autostart")
+	autostart := filepath.Join(root, "autostart")
+	detect := func() CoexistReport {
+		return detectDesktopConflicts([]string{sessions}, []string{userUnits, units}, []string{userAutostart, autostart})
+	}
+
+	// Plasma's units are ignored without Plasma, standalone agents are not
+	writeTestFile(t, filepath.Join(units, "plasma-powerdevil.service"), "")
+	writeTestFile(t, filepath.Join(units, "hyprpolkitagent.service"), "")
+	report := detect()
+	assert.Empty(t, report.Desktops)
+	require.Len(t, report.Conflicts, 1)
+	assert.Equal(t, "hyprpolkitagent.service", report.Conflicts[0].Name())
+
+	writeTestFile(t, filepath.Join(sessions, "gnome-wayland.desktop"), "")
+	writeTestFile(t, filepath.Join(sessions, "plasma.desktop"), "")
+	writeTestFile(t, filepath.Join(units, "org.gnome.SettingsDaemon.Power.service"), "")
+	// GNOME's own agent only autostarts in GNOME; KDE's everywhere
+	writeTestFile(t, filepath.Join(autostart, "polkit-gnome-authentication-agent-1.desktop"), "[Desktop Entry]\nOnlyShowIn=GNOME;XFCE;\n")
+	writeTestFile(t, filepath.Join(autostart, "polkit-kde-authentication-agent-1.desktop"), "[Desktop Entry]\nExec=polkit-kde\n")
+
+	report = detect()
+	assert.Equal(t, []string{"GNOME", "KDE Plasma"}, report.Desktops)
+	var names []string
+	for _, c := range report.Conflicts {
+		names = append(names, c.Name())
+	}
+	assert.Equal(t, []string{
+		"org.gnome.SettingsDaemon.Power.service",
+		"plasma-powerdevil.service",
+		"polkit-kde-authentication-agent-1.desktop",
+		"hyprpolkitagent.service",
+	}, names)
+	assert.Equal(t, filepath.Join(autostart, "polkit-kde-authentication-agent-1.desktop"), report.Conflicts[2].Autostart)
+
+	// Once kept out of DMS sessions they are no longer reported
+	writeTestFile(t, filepath.Join(userUnits, "plasma-powerdevil.service.d", coexistDropIn), coexistUnitDropIn("plasma-powerdevil.service"))
+	writeTestFile(t, filepath.Join(userAutostart, "polkit-kde-authentication-agent-1.desktop"),
+		coexistAutostart("[Desktop Entry]\nExec=polkit-kde\n"))
+	report = detect()
+	assert.Len(t, report.Conflicts, 2)
+	for _, c := range report.Conflicts {
+		assert.NotEqual(t, "plasma-powerdevil.service", c.Name())
+		assert.NotEqual(t, "polkit-kde-authentication-agent-1.desktop", c.Name())
+	}
+}
+
+func TestAutostartsInDMS(t *testing.T) {
+	assert.True(t, autostartsInDMS("[Desktop Entry]\nExec=agent\n"))
+	assert.True(t, autostartsInDMS("[Desktop Entry]\nOnlyShowIn=Hyprland;\n"))
+	assert.True(t, autostartsInDMS("[Desktop Entry]\nNotShowIn=niri;\n"), "still starts in Hyprland")
+	assert.False(t, autostartsInDMS("[Desktop Entry]\nOnlyShowIn=KDE;\n"))
+	assert.False(t, autostartsInDMS("[Desktop Entry]\nNotShowIn=niri;Hyprland;\n"))
+	assert.False(t, autostartsInDMS("[Desktop Entry]\nHidden=true\n"))
+}
+
+func TestCoexistAutostart(t *testing.T) {
+	assert.Equal(t, "[Desktop Entry]\nExec=agent\nNotShowIn=niri;Hyprland;\n", coexistAutostart("[Desktop Entry]\nExec=agent\n"))
+	assert.Equal(t, "[Desktop Entry]\nNotShowIn=KDE;niri;Hyprland;\n", coexistAutostart("[Desktop Entry]\nNotShowIn=KDE\n"))
+	assert.Equal(t, "[Desktop Entry]\nNotShowIn=niri;Hyprland;\n", coexistAutostart("[Desktop Entry]\nNotShowIn=niri;\n"))
+}
+
+func TestCoexistUnitDropIn(t *testing.T) {
+	dropIn := coexistUnitDropIn("plasma-powerdevil.service")
+	assert.Contains(t, dropIn, "[Unit]\n")
+	assert.Contains(t, dropIn, "ConditionEnvironment=!XDG_CURRENT_DESKTOP=niri\n")
+	assert.Contains(t, dropIn, "ConditionEnvironment=!XDG_CURRENT_DESKTOP=Hyprland\n")
+}
@@ -0,0 +1,219 @@
+package distros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source tarballs and release binaries are large and the connections they
+// come over are not always good, so an interrupted transfer is resumed
+// rather than restarted
+const (
+	downloadAttempts = 5
+	downloadBackoff  = 2 * time.Second
+)
+
+// permanentError stops the retry loop; retrying a 404 only wastes time
+type permanentError struct{ error }
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// downloader fetches files through the proxy named by http_proxy,
+// https_proxy and no_proxy, resuming partial files with Range requests
+type downloader struct {
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+	log      func(string)
+}
+
+func newDownloader(log func(string)) *downloader {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	return &downloader{
+		client:   &http.Client{Transport: transport},
+		attempts: downloadAttempts,
+		backoff:  downloadBackoff,
+		log:      log,
+	}
+}
+
+// downloadFile saves url to dest. Bytes land in dest.part first, so a retry,
+// or the next installer run, continues where the last attempt stopped. A
+// later run only resumes when the server gave a validator (ETag or
+// Last-Modified), saved in dest.part.validator and sent as If-Range, so a
+// file that changed upstream is fetched again rather than spliced.
+func (b *BaseDistribution) downloadFile(ctx context.Context, url, dest string) error {
+	return newDownloader(b.log).fetch(ctx, url, dest)
+}
+
+func (d *downloader) fetch(ctx context.Context, url, dest string) error {
+	part := dest + ".part"
+	validatorPath := part + ".validator"
+
+	validator := ""
+	if data, err := os.ReadFile(validatorPath); err == nil {
+		validator = strings.TrimSpace(string(data))
+	}
+	if validator == "" {
+		// Left by an earlier run with nothing to tell whether it still
+		// matches what the server has now
+		os.Remove(part)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= d.attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d.backoff << (attempt - 2)):
+			}
+		}
+
+		next, err := d.attempt(ctx, url, part, validator)
+		if next != validator {
+			validator = next
+			if validator == "" {
+				os.Remove(validatorPath)
+			} else {
+				os.WriteFile(validatorPath, []byte(validator+"\n"), 0644)
+			}
+		}
+		if err == nil {
+			os.Remove(validatorPath)
+			return os.Rename(part, dest)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			os.Remove(part)
+			os.Remove(validatorPath)
+			return permanent.error
+		}
+
+		lastErr = err
+		d.log(fmt.Sprintf("Download of %s failed (attempt %d/%d): %v", url, attempt, d.attempts, err))
+	}
+	return fmt.Errorf("download of %s failed after %d attempts: %w", url, d.attempts, lastErr)
+}
+
+// attempt appends the rest of url to part, resuming only while the server
+// still matches validator. It returns the validator of the bytes now in
+// part, and nil only once the file holds every byte the server announced.
+func (d *downloader) attempt(ctx context.Context, url, part, validator string) (string, error) {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return validator, permanentError{err}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return validator, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, or the file changed, so start over
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		offset = 0
+		validator = responseValidator(resp)
+	case resp.StatusCode == http.StatusPartialContent:
+		start, _, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			os.Remove(part)
+			return "", fmt.Errorf("server resumed at the wrong offset (%q), restarting", resp.Header.Get("Content-Range"))
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The previous run may have got everything but the rename, which
+		// only counts if the server still has the same file
+		_, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err == nil && total == offset && validator != "" && responseValidator(resp) == validator {
+			return validator, nil
+		}
+		os.Remove(part)
+		return "", fmt.Errorf("partial download no longer matches the server, restarting")
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return validator, fmt.Errorf("server returned %s", resp.Status)
+	default:
+		return validator, permanentError{fmt.Errorf("server returned %s", resp.Status)}
+	}
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return validator, permanentError{err}
+	}
+	n, copyErr := io.Copy(f, resp.Body)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return validator, fmt.Errorf("transfer interrupted after %d bytes: %w", offset+n, copyErr)
+	}
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return validator, fmt.Errorf("short transfer: got %d of %d bytes", n, resp.ContentLength)
+	}
+	return validator, nil
+}
+
+// responseValidator is what If-Range can be given to resume this response:
+// a strong ETag, or else Last-Modified. Weak ETags are not allowed there.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// parseContentRange reads "bytes start-end/total" and "bytes */total". An
+// unknown total ("*") is returned as -1.
+func parseContentRange(header string) (start, total int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, fmt.Errorf("bad Content-Range %q", header)
+	}
+	rng, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("bad Content-Range %q", header)
+	}
+
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("bad Content-Range %q", header)
+		}
+	}
+	if rng == "*" {
+		return 0, total, nil
+	}
+	first, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("bad Content-Range %q", header)
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("bad Content-Range %q", header)
+	}
+	return start, total, nil
+}
@@ -0,0 +1,289 @@
+package distros
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testDownloader() *downloader {
+	d := newDownloader(func(string) {})
+	d.backoff = time.Millisecond
+	return d
+}
+
+// serveRange answers like a static file server, honoring Range
+func serveRange(w http.ResponseWriter, r *http.Request, body string) {
+	start := 0
+	if rng := r.Header.Get("Range"); rng != "" {
+		fmt.Sscanf(rng, "bytes=%d-", &start)
+		if start >= len(body) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(body)))
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(body)-1, len(body)))
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)-start))
+		w.WriteHeader(http.StatusPartialContent)
+	}
+	w.Write([]byte(body[start:]))
+}
+
+// serveVersion is serveRange for a file whose current version is etag,
+// falling back to the whole file when If-Range names another version
+func serveVersion(w http.ResponseWriter, r *http.Request, body, etag string) {
+	w.Header().Set("ETag", etag)
+	if ifRange := r.Header.Get("If-Range"); ifRange != "" && ifRange != etag {
+		r.Header.Del("Range")
+	}
+	serveRange(w, r, body)
+}
+
+func TestDownloader_ResumesInterruptedTransfer(t *testing.T) {
+	body := strings.Repeat("0123456789", 1000)
+	var requests atomic.Int32
+	var ranges []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if requests.Add(1) == 1 {
+			// Announce the whole file, send a third of it and drop the connection
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.Write([]byte(body[:len(body)/3]))
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		serveRange(w, r, body)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "src.tar.gz")
+	if err := testDownloader().fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != body {
+		t.Errorf("downloaded %d bytes, want %d", len(got), len(body))
+	}
+	if len(ranges) != 2 || ranges[1] != fmt.Sprintf("bytes=%d-", len(body)/3) {
+		t.Errorf("expected a resume from byte %d, got ranges %q", len(body)/3, ranges)
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Error("partial file left behind")
+	}
+}
+
+func TestDownloader_RestartsWhenRangeIgnored(t *testing.T) {
+	body := "complete file contents"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(dest+".part", []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDownloader().fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("got %q, want %q", got, body)
+	}
+}
+
+func TestDownloader_CompletePartialFile(t *testing.T) {
+	body := "already here"
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		serveVersion(w, r, body, `"v1"`)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(dest+".part", []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest+".part.validator", []byte(`"v1"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDownloader().fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("got %q, want %q", got, body)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected the 416 to finish the download, got %d requests", requests.Load())
+	}
+	if _, err := os.Stat(dest + ".part.validator"); !os.IsNotExist(err) {
+		t.Error("validator left behind")
+	}
+}
+
+func TestDownloader_RestartsWhenFileChanged(t *testing.T) {
+	body := "#!/bin/sh\necho new upstream version\n"
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		serveVersion(w, r, body, `"v2"`)
+	}))
+	defer srv.Close()
+
+	// An earlier run stopped partway through the previous version
+	dest := filepath.Join(t.TempDir(), "grimblast")
+	if err := os.WriteFile(dest+".part", []byte("#!/bin/sh\necho old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest+".part.validator", []byte(`"v1"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDownloader().fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("got %q, want %q", got, body)
+	}
+	if len(ranges) != 1 {
+		t.Errorf("expected one request, got ranges %q", ranges)
+	}
+}
+
+func TestDownloader_DiscardsUnvalidatedPartialFile(t *testing.T) {
+	body := "current contents"
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		serveRange(w, r, body)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(dest+".part", []byte("stale and longer than the file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDownloader().fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("got %q, want %q", got, body)
+	}
+	if len(ranges) != 1 || ranges[0] != "" {
+		t.Errorf("expected a fresh download, got ranges %q", ranges)
+	}
+}
+
+func TestDownloader_RangeNotSatisfiableForChangedFile(t *testing.T) {
+	body := "new"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		serveRange(w, r, body)
+	}))
+	defer srv.Close()
+
+	// The old version was as long as the new one, so the 416's total
+	// matches but the bytes do not
+	dest := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(dest+".part", []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest+".part.validator", []byte(`"v1"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := testDownloader().fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != body {
+		t.Errorf("got %q, want %q", got, body)
+	}
+}
+
+func TestDownloader_RetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "file")
+	if err := testDownloader().fetch(context.Background(), srv.URL, dest); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+}
+
+func TestDownloader_GivesUp(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if strings.HasSuffix(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	d := testDownloader()
+	dest := filepath.Join(t.TempDir(), "file")
+
+	if err := d.fetch(context.Background(), srv.URL+"/missing", dest); err == nil {
+		t.Error("expected an error for 404")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("404 was retried: %d requests", requests.Load())
+	}
+
+	requests.Store(0)
+	if err := d.fetch(context.Background(), srv.URL+"/down", dest); err == nil {
+		t.Error("expected an error for a server that stays down")
+	}
+	if int(requests.Load()) != downloadAttempts {
+		t.Errorf("expected %d attempts, got %d", downloadAttempts, requests.Load())
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header       string
+		start, total int64
+		wantErr      bool
+	}{
+		{"bytes 100-199/1000", 100, 1000, false},
+		{"bytes 0-0/*", 0, -1, false},
+		{"bytes */1000", 0, 1000, false},
+		{"items 0-1/2", 0, 0, true},
+		{"bytes 10/20", 0, 0, true},
+		{"", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		start, total, err := parseContentRange(tt.header)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.header, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (start != tt.start || total != tt.total) {
+			t.Errorf("%q: got (%d, %d), want (%d, %d)", tt.header, start, total, tt.start, tt.total)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package distros
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StepExtra prefixes the manifest IDs of the optional extras; the extra's
+// ID and what was done follow after colons
+const StepExtra = "extra"
+
+const (
+	ExtraZram     = "zram"
+	ExtraEarlyOOM = "earlyoom"
+	ExtraPSD      = "profile-sync-daemon"
+)
+
+const (
+	zramGeneratorConfig = "/etc/systemd/zram-generator.conf"
+	procSwaps           = "/proc/swaps"
+)
+
+// Extra is an optional system tweak offered after install that users
+// otherwise set up by hand. Its package comes from the distro's own
+// repositories; Unit is enabled afterwards, as a user unit when UserUnit
+// is set, and Config is written to ConfigPath unless a file is there.
+type Extra struct {
+	ID          string
+	Name        string
+	Description string
+	Packages    map[DistroFamily]string
+	Unit        string
+	UserUnit    bool
+	// StartOnly units come from a systemd generator, so they are started
+	// rather than enabled
+	StartOnly  bool
+	ConfigPath string
+	Config     string
+}
+
+var Extras = []Extra{
+	{
+		ID:          ExtraZram,
+		Name:        "zram swap",
+		Description: "Compressed swap in RAM, half the memory up to 8 GiB",
+		Packages: map[DistroFamily]string{
+			FamilyArch:   "zram-generator",
+			FamilyFedora: "zram-generator",
+			FamilySUSE:   "zram-generator",
+			FamilyUbuntu: "systemd-zram-generator",
+			FamilyDebian: "systemd-zram-generator",
+			FamilyGentoo: "sys-apps/zram-generator",
+		},
+		Unit:       "systemd-zram-setup@zram0.service",
+		StartOnly:  true,
+		ConfigPath: zramGeneratorConfig,
+		Config: `# Written by dankinstall: compressed swap in RAM
+[zram0]
+zram-size = min(ram / 2, 8192)
+compression-algorithm = zstd
+`,
+	},
+	{
+		ID:          ExtraEarlyOOM,
+		Name:        "earlyoom",
+		Description: "Stop the biggest process before memory runs out, instead of the desktop freezing",
+		Packages: map[DistroFamily]string{
+			FamilyArch:   "earlyoom",
+			FamilyFedora: "earlyoom",
+			FamilySUSE:   "earlyoom",
+			FamilyUbuntu: "earlyoom",
+			FamilyDebian: "earlyoom",
+			FamilyGentoo: "sys-apps/earlyoom",
+		},
+		Unit: "earlyoom.service",
+	},
+	{
+		ID:          ExtraPSD,
+		Name:        "profile-sync-daemon",
+		Description: "Keep browser profiles in tmpfs, synced back to disk",
+		Packages: map[DistroFamily]string{
+			FamilyArch:   "profile-sync-daemon",
+			FamilyFedora: "profile-sync-daemon",
+			FamilySUSE:   "profile-sync-daemon",
+			FamilyUbuntu: "profile-sync-daemon",
+			FamilyDebian: "profile-sync-daemon",
+			FamilyGentoo: "www-misc/profile-sync-daemon",
+		},
+		Unit:     "psd.service",
+		UserUnit: true,
+	},
+}
+
+// ExtraNamed looks up an extra by ID
+func ExtraNamed(id string) (Extra, bool) {
+	for _, e := range Extras {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Extra{}, false
+}
+
+// familyPackageManagers is the package manager of each family the extras
+// can be installed on; NixOS configures them declaratively
+var familyPackageManagers = map[DistroFamily]PackageManagerType{
+	FamilyArch:   PackageManagerPacman,
+	FamilyFedora: PackageManagerDNF,
+	FamilySUSE:   PackageManagerZypper,
+	FamilyUbuntu: PackageManagerAPT,
+	FamilyDebian: PackageManagerAPT,
+	FamilyGentoo: PackageManagerPortage,
+}
+
+// packageCommands are argument lists the package name is appended to
+type packageCommands struct {
+	query   []string
+	install []string
+	remove  []string
+}
+
+var packageManagerCommands = map[PackageManagerType]packageCommands{
+	PackageManagerPacman: {
+		[]string{"pacman", "-Q"},
+		[]string{"pacman", "-S", "--needed", "--noconfirm"},
+		[]string{"pacman", "-Rns", "--noconfirm"},
+	},
+	PackageManagerDNF: {
+		[]string{"rpm", "-q"},
+		[]string{"dnf", "install", "-y"},
+		[]string{"dnf", "remove", "-y"},
+	},
+	PackageManagerZypper: {
+		[]string{"rpm", "-q"},
+		[]string{"zypper", "--non-interactive", "install"},
+		[]string{"zypper", "--non-interactive", "remove"},
+	},
+	PackageManagerAPT: {
+		[]string{"dpkg", "-s"},
+		[]string{"env", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y"},
+		[]string{"apt-get", "remove", "-y"},
+	},
+	PackageManagerPortage: {
+		[]string{"qlist", "-I"},
+		[]string{"emerge", "--noreplace"},
+		[]string{"emerge", "--unmerge"},
+	},
+}
+
+// isExtraPackage reports whether pkg is what an extra installs with
+// manager, so a revert only ever removes packages the installer offers
+func isExtraPackage(manager PackageManagerType, pkg string) bool {
+	for _, e := range Extras {
+		for family, p := range e.Packages {
+			if p == pkg && familyPackageManagers[family] == manager {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isExtraUnit reports whether unit is an extra's, as a user unit or not
+func isExtraUnit(unit string, user bool) bool {
+	for _, e := range Extras {
+		if e.Unit == unit && e.UserUnit == user && !e.StartOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// isExtraConfig reports whether path is the config file of an extra
+func isExtraConfig(path string) bool {
+	for _, e := range Extras {
+		if e.ConfigPath != "" && e.ConfigPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+// hostFamily is the family of the running distro
+func hostFamily() (DistroFamily, error) {
+	osInfo, err := GetOSInfo()
+	if err != nil {
+		return "", err
+	}
+	config, ok := Registry[osInfo.Distribution.ID]
+	if !ok {
+		return "", fmt.Errorf("unsupported distribution %s", osInfo.Distribution.ID)
+	}
+	return config.Family, nil
+}
+
+// ExtraStatus reports whether the extra, or something doing its job, is
+// already running, and what
+func ExtraStatus(ctx context.Context, e Extra) (bool, string) {
+	switch e.ID {
+	case ExtraZram:
+		if data, err := os.ReadFile(procSwaps); err == nil && zramSwapActive(string(data)) {
+			return true, "zram swap is already active"
+		}
+		return false, ""
+	case ExtraEarlyOOM:
+		if systemctlIs(ctx, "is-active", false, "systemd-oomd.service") {
+			return true, "systemd-oomd already handles low memory"
+		}
+	}
+	if systemctlIs(ctx, "is-enabled", e.UserUnit, e.Unit) {
+		return true, e.Unit + " is already enabled"
+	}
+	return false, ""
+}
+
+func systemctlIs(ctx context.Context, check string, user bool, unit string) bool {
+	args := []string{check, "--quiet", unit}
+	if user {
+		args = append([]string{"--user"}, args...)
+	}
+	return exec.CommandContext(ctx, "systemctl", args...).Run() == nil
+}
+
+// zramSwapActive reads /proc/swaps for a zram device
+func zramSwapActive(swaps string) bool {
+	for _, line := range strings.Split(swaps, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "/dev/zram") {
+			return true
+		}
+	}
+	return false
+}
+
+// installExtras installs and enables the selected extras, recording each
+// package, config file and unit it adds so the revert takes them away
+// again. Extras already in effect are skipped.
+func (b *BaseDistribution) installExtras(ctx context.Context, ids []string, sudoPassword string, manifest *InstallManifest, report *SessionReport) {
+	family, err := hostFamily()
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("extras: %v", err))
+		return
+	}
+	manager, ok := familyPackageManagers[family]
+	if !ok {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("Extras are not installed on %s; enable them in your system configuration", family))
+		return
+	}
+	commands := packageManagerCommands[manager]
+
+	for _, id := range ids {
+		e, ok := ExtraNamed(id)
+		if !ok {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("unknown extra %q", id))
+			continue
+		}
+		if active, reason := ExtraStatus(ctx, e); active {
+			report.Skipped = append(report.Skipped, reason)
+			continue
+		}
+		pkg, ok := e.Packages[family]
+		if !ok {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s is not packaged for %s", e.Name, family))
+			continue
+		}
+
+		query := append(slices.Clone(commands.query), pkg)
+		if exec.CommandContext(ctx, query[0], query[1:]...).Run() != nil {
+			b.log(fmt.Sprintf("Installing %s", pkg))
+			args := append(slices.Clone(commands.install), pkg)
+			install := sudoExec(ctx, sudoPassword, args[0], args[1:]...)
+			if out, err := install.CombinedOutput(); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("failed to install %s: %v: %s", pkg, err, lastLine(string(out))))
+				continue
+			}
+			manifest.Record(ManifestStep{
+				ID:          StepExtra + ":" + e.ID + ":package",
+				Kind:        StepPackage,
+				Description: "package " + pkg,
+				Package:     pkg,
+				Manager:     manager,
+				AppliedAt:   time.Now(),
+			})
+		}
+
+		if e.ConfigPath != "" {
+			if _, exists, err := readRootFile(ctx, sudoPassword, e.ConfigPath); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("failed to read %s: %v", e.ConfigPath, err))
+				continue
+			} else if !exists {
+				if err := writeRootFile(ctx, sudoPassword, e.ConfigPath, e.Config, 0644); err != nil {
+					report.Warnings = append(report.Warnings, fmt.Sprintf("failed to write %s: %v", e.ConfigPath, err))
+					continue
+				}
+				manifest.Record(ManifestStep{
+					ID:          StepExtra + ":" + e.ID + ":config",
+					Kind:        StepFile,
+					Description: e.Name + " config " + e.ConfigPath,
+					Path:        e.ConfigPath,
+					Mode:        0644,
+					Created:     true,
+					AppliedAt:   time.Now(),
+				})
+			}
+		}
+
+		if err := b.startExtraUnit(ctx, e, sudoPassword); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to start %s: %v", e.Unit, err))
+			continue
+		}
+		if !e.StartOnly {
+			kind := StepEnabledUnit
+			if e.UserUnit {
+				kind = StepEnabledUserUnit
+			}
+			manifest.Record(ManifestStep{
+				ID:          StepExtra + ":" + e.ID + ":unit",
+				Kind:        kind,
+				Description: "enabled " + e.Unit,
+				Unit:        e.Unit,
+				AppliedAt:   time.Now(),
+			})
+		}
+		report.Applied = append(report.Applied, fmt.Sprintf("Set up %s: %s", e.Name, e.Description))
+	}
+}
+
+func (b *BaseDistribution) startExtraUnit(ctx context.Context, e Extra, sudoPassword string) error {
+	var cmd *exec.Cmd
+	switch {
+	case e.UserUnit:
+		cmd = b.asTargetUser(ctx, "systemctl", "--user", "enable", "--now", e.Unit)
+	case e.StartOnly:
+		if out, err := sudoExec(ctx, sudoPassword, "systemctl", "daemon-reload").CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, lastLine(string(out)))
+		}
+		cmd = sudoExec(ctx, sudoPassword, "systemctl", "start", e.Unit)
+	default:
+		cmd = sudoExec(ctx, sudoPassword, "systemctl", "enable", "--now", e.Unit)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, lastLine(string(out)))
+	}
+	return nil
+}
+
+// removePackage undoes a StepPackage
+func removePackage(ctx context.Context, step ManifestStep, sudoPassword string) error {
+	commands, ok := packageManagerCommands[step.Manager]
+	if !ok {
+		return fmt.Errorf("unknown package manager %q", step.Manager)
+	}
+	if step.Package == "" {
+		return errors.New("no package recorded")
+	}
+	if !isExtraPackage(step.Manager, step.Package) {
+		return fmt.Errorf("refusing to remove %q: not a package the installer offers", step.Package)
+	}
+	remove := append(slices.Clone(commands.remove), step.Package)
+	cmd := sudoExec(ctx, sudoPassword, remove[0], remove[1:]...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, lastLine(string(out)))
+	}
+	return nil
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
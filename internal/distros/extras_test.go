@@ -0,0 +1,84 @@
+package distros
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZramSwapActive(t *testing.T) {
+	header := "Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n"
+	assert.False(t, zramSwapActive(header))
+	assert.False(t, zramSwapActive(header+"/swapfile                               file\t\t8388604\t\t0\t\t-2\n"))
+	assert.True(t, zramSwapActive(header+"/dev/zram0                              partition\t8388604\t\t0\t\t100\n"))
+}
+
+func TestExtrasArePackagedForEveryFamily(t *testing.T) {
+	for _, e := range Extras {
+		for family, manager := range familyPackageManagers {
+			assert.NotEmpty(t, e.Packages[family], "%s has no %s package", e.ID, family)
+			_, ok := packageManagerCommands[manager]
+			assert.True(t, ok, "no commands for %s", manager)
+		}
+		assert.NotEmpty(t, e.Unit, e.ID)
+		if e.ConfigPath != "" {
+			assert.NotEmpty(t, e.Config, e.ID)
+		}
+	}
+
+	_, ok := familyPackageManagers[FamilyNix]
+	assert.False(t, ok, "NixOS configures extras declaratively")
+}
+
+func TestExtraNamed(t *testing.T) {
+	e, ok := ExtraNamed(ExtraEarlyOOM)
+	require.True(t, ok)
+	assert.Equal(t, "earlyoom.service", e.Unit)
+
+	_, ok = ExtraNamed("ly")
+	assert.False(t, ok)
+}
+
+func TestManifestKeepsExtraSteps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	m := &InstallManifest{}
+	m.Record(ManifestStep{ID: StepExtra + ":earlyoom:package", Kind: StepPackage, Package: "earlyoom", Manager: PackageManagerPacman})
+	m.Record(ManifestStep{ID: StepExtra + ":earlyoom:unit", Kind: StepEnabledUnit, Unit: "earlyoom.service"})
+	require.NoError(t, m.Save(path))
+
+	loaded, err := LoadInstallManifest(path)
+	require.NoError(t, err)
+	pkg, ok := loaded.Step(StepExtra + ":earlyoom:package")
+	require.True(t, ok)
+	assert.Equal(t, "earlyoom", pkg.Package)
+	assert.Equal(t, PackageManagerPacman, pkg.Manager)
+	unit, ok := loaded.Step(StepExtra + ":earlyoom:unit")
+	require.True(t, ok)
+	assert.Equal(t, StepEnabledUnit, unit.Kind)
+	assert.Equal(t, "earlyoom.service", unit.Unit)
+}
+
+func TestRemovePackageNeedsAKnownManager(t *testing.T) {
+	assert.Error(t, removePackage(t.Context(), ManifestStep{Kind: StepPackage, Package: "earlyoom", Manager: "flatpak"}, ""))
+	assert.Error(t, removePackage(t.Context(), ManifestStep{Kind: StepPackage, Manager: PackageManagerPacman}, ""))
+}
+
+func TestRevertRefusesUnknownPackagesAndUnits(t *testing.T) {
+	assert.True(t, isExtraPackage(PackageManagerAPT, "systemd-zram-generator"))
+	assert.False(t, isExtraPackage(PackageManagerPacman, "systemd-zram-generator"))
+
+	err := removePackage(t.Context(), ManifestStep{Kind: StepPackage, Package: "glibc; rm -rf /", Manager: PackageManagerPacman}, "")
+	assert.ErrorContains(t, err, "refusing")
+	err = removePackage(t.Context(), ManifestStep{Kind: StepPackage, Package: "sudo", Manager: PackageManagerPacman}, "")
+	assert.ErrorContains(t, err, "refusing")
+
+	assert.True(t, isExtraUnit("earlyoom.service", false))
+	assert.True(t, isExtraUnit("psd.service", true))
+	assert.False(t, isExtraUnit("psd.service", false))
+	assert.ErrorContains(t, revertStep(t.Context(), ManifestStep{Kind: StepEnabledUnit, Unit: "sshd.service"}, ""), "refusing")
+	assert.ErrorContains(t, revertStep(t.Context(), ManifestStep{Kind: StepEnabledUserUnit, Unit: "x; id"}, ""), "refusing")
+
+	assert.NoError(t, checkRevertPath(zramGeneratorConfig))
+}
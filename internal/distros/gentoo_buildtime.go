@@ -0,0 +1,233 @@
+package distros
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+// referenceCores is the machine size the heavy package timings were taken on
+const referenceCores = 4
+
+// gentooSerialFraction is the share of a build that does not scale with
+// cores (configure, linking, single-threaded crates)
+const gentooSerialFraction = 0.15
+
+type gentooHeavyPackage struct {
+	atom string
+	// minutes on a referenceCores machine
+	minutes     float64
+	alternative string
+	// Flathub app ID that replaces the dependency outright
+	flatpak string
+}
+
+const binhostSuggestion = "Enable the Gentoo binary package host (emerge getuto, add FEATURES=\"getbinpkg\") to download Qt instead of compiling it"
+
+// gentooHeavyPackages lists what each dependency pulls in that takes long
+// enough to build to be worth warning about. Times are deliberately rough.
+var gentooHeavyPackages = map[string][]gentooHeavyPackage{
+	"quickshell": {
+		{atom: "dev-qt/qtbase", minutes: 50, alternative: binhostSuggestion},
+		{atom: "dev-qt/qtdeclarative", minutes: 45, alternative: binhostSuggestion},
+		{atom: "dev-qt/qtwayland", minutes: 12, alternative: binhostSuggestion},
+		{atom: "dev-qt/qtsvg", minutes: 4, alternative: binhostSuggestion},
+		{atom: "gui-apps/quickshell", minutes: 15},
+	},
+	"niri": {
+		{atom: "dev-lang/rust", minutes: 90, alternative: "Install dev-lang/rust-bin first to skip compiling the Rust toolchain"},
+		{atom: "gui-wm/niri", minutes: 25},
+	},
+	"hyprland": {
+		{atom: "gui-wm/hyprland", minutes: 20},
+	},
+	"matugen": {
+		{atom: "dev-lang/rust", minutes: 90, alternative: "Install dev-lang/rust-bin first to skip compiling the Rust toolchain"},
+		{atom: "x11-misc/matugen", minutes: 6},
+	},
+	"alacritty": {
+		{atom: "dev-lang/rust", minutes: 90, alternative: "Install dev-lang/rust-bin first to skip compiling the Rust toolchain"},
+		{atom: "x11-terms/alacritty", minutes: 8},
+	},
+	"xwayland-satellite": {
+		{atom: "dev-lang/rust", minutes: 90, alternative: "Install dev-lang/rust-bin first to skip compiling the Rust toolchain"},
+		{atom: "gui-apps/xwayland-satellite", minutes: 5},
+	},
+	"ghostty": {
+		{atom: "dev-lang/zig", minutes: 35, alternative: "Install dev-lang/zig-bin first to skip compiling the Zig toolchain"},
+		{atom: "x11-terms/ghostty", minutes: 10, flatpak: "com.mitchellh.ghostty"},
+	},
+}
+
+// GentooBuildItem is one package that would be compiled for a dependency
+type GentooBuildItem struct {
+	Atom        string
+	Duration    time.Duration
+	Alternative string
+	Flatpak     string
+}
+
+// GentooBuildEstimate holds rough compile times for the heavy packages each
+// dependency would build on this machine
+type GentooBuildEstimate struct {
+	Cores   int
+	Binpkgs bool
+	Deps    map[string][]GentooBuildItem
+}
+
+// EstimateBuildTimes scales the reference timings to the configured job
+// count. Packages already in the vdb are left out, except the dependency's
+// own package since that is what gets rebuilt on reinstall.
+func (g *GentooDistribution) EstimateBuildTimes(dependencies []deps.Dependency) GentooBuildEstimate {
+	return estimateGentooBuilds(dependencies, portageJobs(), portageHasFeature("getbinpkg"), g.packageInstalled)
+}
+
+func estimateGentooBuilds(dependencies []deps.Dependency, cores int, binpkgs bool, installed func(string) bool) GentooBuildEstimate {
+	if cores < 1 {
+		cores = 1
+	}
+	est := GentooBuildEstimate{
+		Cores:   cores,
+		Binpkgs: binpkgs,
+		Deps:    make(map[string][]GentooBuildItem),
+	}
+
+	scale := gentooSerialFraction + (1-gentooSerialFraction)*float64(referenceCores)/float64(cores)
+	for _, dep := range dependencies {
+		heavy, ok := gentooHeavyPackages[dep.Name]
+		if !ok {
+			continue
+		}
+		own := heavy[len(heavy)-1].atom
+		for _, pkg := range heavy {
+			if pkg.atom != own && installed(pkg.atom) {
+				continue
+			}
+			est.Deps[dep.Name] = append(est.Deps[dep.Name], GentooBuildItem{
+				Atom:        pkg.atom,
+				Duration:    time.Duration(pkg.minutes * scale * float64(time.Minute)).Round(time.Minute),
+				Alternative: pkg.alternative,
+				Flatpak:     pkg.flatpak,
+			})
+		}
+	}
+	return est
+}
+
+// For returns the estimate for one dependency on its own
+func (e GentooBuildEstimate) For(name string) time.Duration {
+	var total time.Duration
+	for _, item := range e.Deps[name] {
+		total += item.Duration
+	}
+	return total
+}
+
+// Total sums the dependencies being built, counting shared packages such as
+// the Rust toolchain once
+func (e GentooBuildEstimate) Total(names []string) time.Duration {
+	var total time.Duration
+	seen := make(map[string]bool)
+	for _, name := range names {
+		for _, item := range e.Deps[name] {
+			if seen[item.Atom] {
+				continue
+			}
+			seen[item.Atom] = true
+			total += item.Duration
+		}
+	}
+	return total
+}
+
+// Suggestions lists the distinct alternatives for the dependencies being
+// built, including Flathub builds that replace a dependency entirely. The
+// binhost hint is dropped when getbinpkg is already enabled.
+func (e GentooBuildEstimate) Suggestions(names []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(alt string) {
+		if alt == "" || seen[alt] || (e.Binpkgs && alt == binhostSuggestion) {
+			return
+		}
+		seen[alt] = true
+		out = append(out, alt)
+	}
+	for _, name := range names {
+		for _, item := range e.Deps[name] {
+			add(item.Alternative)
+			if item.Flatpak != "" {
+				add(fmt.Sprintf("Install %s from Flathub instead of compiling it (flatpak install flathub %s)", name, item.Flatpak))
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// FormatBuildDuration renders a rough duration like "~1h 20m"
+func FormatBuildDuration(d time.Duration) string {
+	d = d.Round(5 * time.Minute)
+	if d < 5*time.Minute {
+		return "<5m"
+	}
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("~%dm", m)
+	case m == 0:
+		return fmt.Sprintf("~%dh", h)
+	}
+	return fmt.Sprintf("~%dh %dm", h, m)
+}
+
+// portageJobs reads -j from MAKEOPTS, falling back to the CPU count
+func portageJobs() int {
+	if out, err := exec.Command("portageq", "envvar", "MAKEOPTS").Output(); err == nil {
+		if jobs := parseMakeoptsJobs(string(out)); jobs > 0 {
+			return jobs
+		}
+	}
+	return runtime.NumCPU()
+}
+
+func parseMakeoptsJobs(makeopts string) int {
+	fields := strings.Fields(makeopts)
+	for i, f := range fields {
+		var value string
+		switch {
+		case f == "-j" || f == "--jobs":
+			if i+1 < len(fields) {
+				value = fields[i+1]
+			}
+		case strings.HasPrefix(f, "--jobs="):
+			value = strings.TrimPrefix(f, "--jobs=")
+		case strings.HasPrefix(f, "-j"):
+			value = strings.TrimPrefix(f, "-j")
+		}
+		if jobs, err := strconv.Atoi(value); err == nil && jobs > 0 {
+			return jobs
+		}
+	}
+	return 0
+}
+
+func portageHasFeature(feature string) bool {
+	out, err := exec.Command("portageq", "envvar", "FEATURES").Output()
+	if err != nil {
+		return false
+	}
+	for _, f := range strings.Fields(string(out)) {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package distros
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateGentooBuilds(t *testing.T) {
+	dependencies := []deps.Dependency{
+		{Name: "quickshell", Status: deps.StatusMissing},
+		{Name: "niri", Status: deps.StatusMissing},
+		{Name: "matugen", Status: deps.StatusMissing},
+		{Name: "git", Status: deps.StatusInstalled},
+	}
+	installed := func(atom string) bool { return atom == "dev-qt/qtbase" }
+
+	est := estimateGentooBuilds(dependencies, referenceCores, false, installed)
+
+	assert.NotContains(t, est.Deps, "git")
+	for _, item := range est.Deps["quickshell"] {
+		assert.NotEqual(t, "dev-qt/qtbase", item.Atom)
+	}
+	assert.Equal(t, 76*time.Minute, est.For("quickshell"))
+
+	// Rust is shared by niri and matugen and only counted once
+	assert.Equal(t, est.For("niri")+6*time.Minute, est.Total([]string{"niri", "matugen"}))
+
+	suggestions := est.Suggestions([]string{"quickshell", "niri", "matugen"})
+	assert.Len(t, suggestions, 2)
+
+	est.Binpkgs = true
+	assert.Len(t, est.Suggestions([]string{"quickshell", "niri"}), 1)
+}
+
+func TestEstimateGentooBuilds_FlatpakSuggestion(t *testing.T) {
+	dependencies := []deps.Dependency{
+		{Name: "ghostty", Status: deps.StatusMissing},
+		{Name: "xwayland-satellite", Status: deps.StatusMissing},
+	}
+	none := func(string) bool { return false }
+
+	est := estimateGentooBuilds(dependencies, referenceCores, true, none)
+
+	items := est.Deps["xwayland-satellite"]
+	assert.Equal(t, "gui-apps/xwayland-satellite", items[len(items)-1].Atom)
+	assert.Contains(t, est.Suggestions([]string{"ghostty"}), "Install ghostty from Flathub instead of compiling it (flatpak install flathub com.mitchellh.ghostty)")
+}
+
+func TestEstimateGentooBuilds_ScalesWithCores(t *testing.T) {
+	dependencies := []deps.Dependency{{Name: "hyprland", Status: deps.StatusMissing}}
+	none := func(string) bool { return false }
+
+	slow := estimateGentooBuilds(dependencies, 2, false, none).For("hyprland")
+	ref := estimateGentooBuilds(dependencies, referenceCores, false, none).For("hyprland")
+	fast := estimateGentooBuilds(dependencies, 32, false, none).For("hyprland")
+
+	assert.Equal(t, 20*time.Minute, ref)
+	assert.Greater(t, slow, ref)
+	assert.Less(t, fast, ref)
+	// The serial part keeps many cores from scaling linearly
+	assert.Greater(t, fast, ref/8)
+}
+
+func TestParseMakeoptsJobs(t *testing.T) {
+	assert.Equal(t, 12, parseMakeoptsJobs("-j12 -l12"))
+	assert.Equal(t, 8, parseMakeoptsJobs("--jobs=8"))
+	assert.Equal(t, 6, parseMakeoptsJobs("-j 6"))
+	assert.Equal(t, 0, parseMakeoptsJobs("-l4"))
+}
+
+func TestFormatBuildDuration(t *testing.T) {
+	assert.Equal(t, "<5m", FormatBuildDuration(2*time.Minute))
+	assert.Equal(t, "~45m", FormatBuildDuration(44*time.Minute))
+	assert.Equal(t, "~2h", FormatBuildDuration(121*time.Minute))
+	assert.Equal(t, "~1h 20m", FormatBuildDuration(81*time.Minute))
+}
@@ -0,0 +1,132 @@
+package distros
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const installManifestFile = "manifest.json"
+
+// StepKind tells how a recorded step is undone
+type StepKind string
+
+const (
+	// StepFile replaced or created a file; it is undone by restoring the
+	// previous content or removing the file
+	StepFile StepKind = "file"
+	// StepShell changed a user's login shell
+	StepShell StepKind = "shell"
+	// StepUserFile is a StepFile in the user's home, restored without sudo
+	// so it keeps its owner
+	StepUserFile StepKind = "user-file"
+	// StepUserUnit disabled the systemd user unit named in Previous
+	StepUserUnit StepKind = "user-unit"
+	// StepPackage installed Package with Manager; it is undone by removing
+	// the package
+	StepPackage StepKind = "package"
+	// StepEnabledUnit and StepEnabledUserUnit enabled and started the
+	// system or user unit in Unit
+	StepEnabledUnit     StepKind = "enabled-unit"
+	StepEnabledUserUnit StepKind = "enabled-user-unit"
+)
+
+// ManifestStep is one optional system change made by the installer, with
+// what is needed to undo it
+type ManifestStep struct {
+	ID          string             `json:"id"`
+	Kind        StepKind           `json:"kind"`
+	Description string             `json:"description"`
+	Path        string             `json:"path,omitempty"`
+	Mode        uint32             `json:"mode,omitempty"`
+	User        string             `json:"user,omitempty"`
+	Unit        string             `json:"unit,omitempty"`
+	Package     string             `json:"package,omitempty"`
+	Manager     PackageManagerType `json:"manager,omitempty"`
+	Previous    string             `json:"previous"`
+	Created     bool               `json:"created,omitempty"`
+	AppliedAt   time.Time          `json:"appliedAt"`
+}
+
+// InstallManifest records the reversible steps of an install, and the
+// install statistics when the user chose to keep them
+type InstallManifest struct {
+	Steps []ManifestStep `json:"steps"`
+	Stats []InstallStats `json:"stats,omitempty"`
+}
+
+// InstallManifestPath sits next to the first-login state
+func InstallManifestPath(homeDir string) string {
+	return filepath.Join(homeDir, ".config", "dankinstall", installManifestFile)
+}
+
+// LoadInstallManifest reads the manifest, returning an empty one if nothing
+// was recorded yet
+func LoadInstallManifest(path string) (*InstallManifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &InstallManifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m InstallManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+func (m *InstallManifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Step returns the recorded step with the given ID
+func (m *InstallManifest) Step(id string) (ManifestStep, bool) {
+	for _, s := range m.Steps {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return ManifestStep{}, false
+}
+
+// Record adds a step. Re-applying a step that is already recorded keeps the
+// original previous state, so reverting always returns to how the system
+// was before the first install.
+func (m *InstallManifest) Record(step ManifestStep) {
+	for i, s := range m.Steps {
+		if s.ID == step.ID {
+			step.Previous = s.Previous
+			step.Created = s.Created
+			step.Mode = s.Mode
+			m.Steps[i] = step
+			return
+		}
+	}
+	m.Steps = append(m.Steps, step)
+}
+
+// Remove drops a step after it was reverted
+func (m *InstallManifest) Remove(id string) {
+	for i, s := range m.Steps {
+		if s.ID == id {
+			m.Steps = append(m.Steps[:i], m.Steps[i+1:]...)
+			return
+		}
+	}
+}
@@ -42,6 +42,18 @@ func (m *ManualPackageInstaller) getLatestQuickshellTag(ctx context.Context) str
 	return m.parseLatestTagFromGitOutput(string(tagOutput))
 }
 
+// installBinary copies a built binary into a system directory and makes it
+// executable, through sudoExec so it also works as root in a chroot
+func installBinary(ctx context.Context, sudoPassword, source, target string) error {
+	if err := sudoExec(ctx, sudoPassword, "cp", source, target).Run(); err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", source, target, err)
+	}
+	if err := sudoExec(ctx, sudoPassword, "chmod", "+x", target).Run(); err != nil {
+		return fmt.Errorf("failed to make %s executable: %w", target, err)
+	}
+	return nil
+}
+
 // InstallManualPackages handles packages that need manual building
 func (m *ManualPackageInstaller) InstallManualPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
@@ -153,7 +165,7 @@ func (m *ManualPackageInstaller) installDgop(ctx context.Context, sudoPassword s
 		CommandInfo: "sudo make install",
 	}
 
-	installCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S make install", sudoPassword))
+	installCmd := sudoExec(ctx, sudoPassword, "make", "install")
 	installCmd.Dir = tmpDir
 	if err := installCmd.Run(); err != nil {
 		m.logError("failed to install dgop", err)
@@ -172,14 +184,13 @@ func (m *ManualPackageInstaller) installGrimblast(ctx context.Context, sudoPassw
 		Progress:    0.1,
 		Step:        "Downloading grimblast script...",
 		IsComplete:  false,
-		CommandInfo: "curl grimblast script",
+		CommandInfo: "download grimblast script",
 	}
 
 	grimblastURL := "https://raw.githubusercontent.com/hyprwm/contrib/refs/heads/main/grimblast/grimblast"
 	tmpPath := filepath.Join(os.TempDir(), "grimblast")
 
-	downloadCmd := exec.CommandContext(ctx, "curl", "-L", "-o", tmpPath, grimblastURL)
-	if err := downloadCmd.Run(); err != nil {
+	if err := m.downloadFile(ctx, grimblastURL, tmpPath); err != nil {
 		m.logError("failed to download grimblast", err)
 		return fmt.Errorf("failed to download grimblast: %w", err)
 	}
@@ -207,8 +218,7 @@ func (m *ManualPackageInstaller) installGrimblast(ctx context.Context, sudoPassw
 		CommandInfo: "sudo cp grimblast /usr/local/bin/",
 	}
 
-	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S cp %s /usr/local/bin/grimblast", sudoPassword, tmpPath))
+	installCmd := sudoExec(ctx, sudoPassword, "cp", tmpPath, "/usr/local/bin/grimblast")
 	if err := installCmd.Run(); err != nil {
 		m.logError("failed to install grimblast", err)
 		return fmt.Errorf("failed to install grimblast: %w", err)
@@ -279,8 +289,11 @@ func (m *ManualPackageInstaller) installNiri(ctx context.Context, sudoPassword s
 		CommandInfo: "dpkg -i niri.deb",
 	}
 
-	installDebCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S dpkg -i %s/target/debian/niri_*.deb", sudoPassword, buildDir))
+	debs, err := filepath.Glob(filepath.Join(buildDir, "target", "debian", "niri_*.deb"))
+	if err != nil || len(debs) == 0 {
+		return fmt.Errorf("no niri deb package found in %s", filepath.Join(buildDir, "target", "debian"))
+	}
+	installDebCmd := sudoExec(ctx, sudoPassword, "dpkg", append([]string{"-i"}, debs...)...)
 
 	output, err := installDebCmd.CombinedOutput()
 	if err != nil {
@@ -391,8 +404,8 @@ func (m *ManualPackageInstaller) installQuickshell(ctx context.Context, sudoPass
 		CommandInfo: "sudo cmake --install build",
 	}
 
-	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("cd %s && echo '%s' | sudo -S cmake --install build", tmpDir, sudoPassword))
+	installCmd := sudoExec(ctx, sudoPassword, "cmake", "--install", "build")
+	installCmd.Dir = tmpDir
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install quickshell: %w", err)
 	}
@@ -454,8 +467,8 @@ func (m *ManualPackageInstaller) installHyprland(ctx context.Context, sudoPasswo
 		CommandInfo: "sudo make install",
 	}
 
-	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("cd %s && echo '%s' | sudo -S make install", tmpDir, sudoPassword))
+	installCmd := sudoExec(ctx, sudoPassword, "make", "install")
+	installCmd.Dir = tmpDir
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install Hyprland: %w", err)
 	}
@@ -520,8 +533,8 @@ func (m *ManualPackageInstaller) installHyprpicker(ctx context.Context, sudoPass
 		CommandInfo: "sudo make install",
 	}
 
-	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("cd %s && echo '%s' | sudo -S make install", tmpDir, sudoPassword))
+	installCmd := sudoExec(ctx, sudoPassword, "make", "install")
+	installCmd.Dir = tmpDir
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install hyprpicker: %w", err)
 	}
@@ -586,8 +599,7 @@ func (m *ManualPackageInstaller) installGhostty(ctx context.Context, sudoPasswor
 		CommandInfo: "sudo cp zig-out/bin/ghostty /usr/local/bin/",
 	}
 
-	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S cp %s/zig-out/bin/ghostty /usr/local/bin/", sudoPassword, tmpDir))
+	installCmd := sudoExec(ctx, sudoPassword, "cp", filepath.Join(tmpDir, "zig-out", "bin", "ghostty"), "/usr/local/bin/")
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install Ghostty: %w", err)
 	}
@@ -625,17 +637,8 @@ func (m *ManualPackageInstaller) installMatugen(ctx context.Context, sudoPasswor
 		CommandInfo: fmt.Sprintf("sudo cp %s %s", sourcePath, targetPath),
 	}
 
-	copyCmd := exec.CommandContext(ctx, "sudo", "-S", "cp", sourcePath, targetPath)
-	copyCmd.Stdin = strings.NewReader(sudoPassword + "\n")
-	if err := copyCmd.Run(); err != nil {
-		return fmt.Errorf("failed to copy matugen to /usr/local/bin: %w", err)
-	}
-
-	// Make it executable
-	chmodCmd := exec.CommandContext(ctx, "sudo", "-S", "chmod", "+x", targetPath)
-	chmodCmd.Stdin = strings.NewReader(sudoPassword + "\n")
-	if err := chmodCmd.Run(); err != nil {
-		return fmt.Errorf("failed to make matugen executable: %w", err)
+	if err := installBinary(ctx, sudoPassword, sourcePath, targetPath); err != nil {
+		return fmt.Errorf("failed to install matugen: %w", err)
 	}
 
 	m.log("matugen installed successfully from source")
@@ -739,17 +742,8 @@ func (m *ManualPackageInstaller) installCliphist(ctx context.Context, sudoPasswo
 		CommandInfo: fmt.Sprintf("sudo cp %s %s", sourcePath, targetPath),
 	}
 
-	copyCmd := exec.CommandContext(ctx, "sudo", "-S", "cp", sourcePath, targetPath)
-	copyCmd.Stdin = strings.NewReader(sudoPassword + "\n")
-	if err := copyCmd.Run(); err != nil {
-		return fmt.Errorf("failed to copy cliphist to /usr/local/bin: %w", err)
-	}
-
-	// Make it executable
-	chmodCmd := exec.CommandContext(ctx, "sudo", "-S", "chmod", "+x", targetPath)
-	chmodCmd.Stdin = strings.NewReader(sudoPassword + "\n")
-	if err := chmodCmd.Run(); err != nil {
-		return fmt.Errorf("failed to make cliphist executable: %w", err)
+	if err := installBinary(ctx, sudoPassword, sourcePath, targetPath); err != nil {
+		return fmt.Errorf("failed to install cliphist: %w", err)
 	}
 
 	m.log("cliphist installed successfully from source")
@@ -785,16 +779,8 @@ func (m *ManualPackageInstaller) installXwaylandSatellite(ctx context.Context, s
 		CommandInfo: fmt.Sprintf("sudo cp %s %s", sourcePath, targetPath),
 	}
 
-	copyCmd := exec.CommandContext(ctx, "sudo", "-S", "cp", sourcePath, targetPath)
-	copyCmd.Stdin = strings.NewReader(sudoPassword + "\n")
-	if err := copyCmd.Run(); err != nil {
-		return fmt.Errorf("failed to copy xwayland-satellite to /usr/local/bin: %w", err)
-	}
-
-	chmodCmd := exec.CommandContext(ctx, "sudo", "-S", "chmod", "+x", targetPath)
-	chmodCmd.Stdin = strings.NewReader(sudoPassword + "\n")
-	if err := chmodCmd.Run(); err != nil {
-		return fmt.Errorf("failed to make xwayland-satellite executable: %w", err)
+	if err := installBinary(ctx, sudoPassword, sourcePath, targetPath); err != nil {
+		return fmt.Errorf("failed to install xwayland-satellite: %w", err)
 	}
 
 	m.log("xwayland-satellite installed successfully from source")
@@ -1,6 +1,9 @@
 package distros
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -120,3 +123,38 @@ def456	refs/tags/v0.9.0`
 		t.Errorf("Expected v1.0.0, got %s", result)
 	}
 }
+
+// Bootstrap runs as root inside a fresh arch-chroot, where pacstrap's base
+// has no sudo. A sudo on PATH that always fails stands in for that.
+func TestInstallBinary_AsRootWithoutSudo(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("bootstrap runs as root")
+	}
+
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "sudo"), []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+":"+os.Getenv("PATH"))
+
+	if cmd := sudoExec(context.Background(), "", "true"); filepath.Base(cmd.Path) == "sudo" {
+		t.Errorf("sudoExec went through sudo as root: %v", cmd.Args)
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "grimblast")
+	target := filepath.Join(dir, "installed")
+	if err := os.WriteFile(source, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := installBinary(context.Background(), "", source, target); err != nil {
+		t.Fatalf("installBinary as root: %v", err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&0111 == 0 {
+		t.Errorf("installed binary mode %v is not executable", info.Mode())
+	}
+}
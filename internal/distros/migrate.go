@@ -0,0 +1,672 @@
+package distros
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+// IDs of the migration steps in the install manifest. Autostart steps are
+// suffixed with the file or unit they disabled.
+const (
+	StepMigrateFont      = "migrate-font"
+	StepMigrateWallpaper = "migrate-wallpaper"
+	StepMigrateKeybinds  = "migrate-keybinds"
+	StepMigrateAutostart = "migrate-autostart"
+)
+
+// riceComponents are the pieces of a hand-built desktop DMS replaces, and
+// the config they leave behind
+var riceComponents = []struct {
+	name  string
+	paths []string
+}{
+	{"waybar", []string{".config/waybar"}},
+	{"rofi", []string{".config/rofi"}},
+	{"wofi", []string{".config/wofi"}},
+	{"dunst", []string{".config/dunst/dunstrc"}},
+	{"mako", []string{".config/mako/config"}},
+	{"swaync", []string{".config/swaync"}},
+	{"swaylock", []string{".config/swaylock/config"}},
+	{"hyprpaper", []string{".config/hypr/hyprpaper.conf"}},
+}
+
+// conflictingPrograms start a bar, notification daemon or wallpaper of their
+// own, which would run alongside the shell's
+var conflictingPrograms = map[string]bool{
+	"waybar": true, "polybar": true, "dunst": true, "mako": true, "swaync": true,
+	"fnott": true, "swaybg": true, "hyprpaper": true, "swww-daemon": true,
+}
+
+// RiceBind is a keybinding from the old setup and the DMS action it maps to.
+// Keys are in niri's form, such as Mod+Shift+D.
+type RiceBind struct {
+	Keys   string
+	Action []string
+	Source string
+}
+
+// RiceAutostart is something started at login that conflicts with DMS:
+// an XDG autostart entry (Path) or an enabled systemd user unit (Unit)
+type RiceAutostart struct {
+	Program string
+	Path    string
+	Unit    string
+}
+
+// RiceSetup is what DetectRice found of an existing desktop setup
+type RiceSetup struct {
+	Components      []string
+	Font            string
+	FontSource      string
+	Wallpaper       string
+	WallpaperSource string
+	Keybinds        []RiceBind
+	Autostarts      []RiceAutostart
+}
+
+// Empty reports whether there is nothing to offer
+func (r RiceSetup) Empty() bool {
+	return len(r.Components) == 0 && r.Font == "" && r.Wallpaper == "" && len(r.Keybinds) == 0 && len(r.Autostarts) == 0
+}
+
+// MigrateOptions selects what MigrateRice imports or disables
+type MigrateOptions struct {
+	WindowManager deps.WindowManager
+	// CompositorConfig is the DMS compositor config keybinds are added to
+	CompositorConfig  string
+	ImportFont        bool
+	ImportWallpaper   bool
+	ImportKeybinds    bool
+	DisableAutostarts bool
+}
+
+// DetectRice looks through homeDir for waybar, rofi, dunst, swaylock and
+// similar configs. oldConfigs are compositor configs replaced during
+// deployment; they and sway's config are searched for launcher, lock and
+// notification keybindings.
+func DetectRice(homeDir string, oldConfigs []string) RiceSetup {
+	var r RiceSetup
+	for _, c := range riceComponents {
+		for _, p := range c.paths {
+			if _, err := os.Stat(filepath.Join(homeDir, p)); err == nil {
+				r.Components = append(r.Components, c.name)
+				break
+			}
+		}
+	}
+
+	r.Font, r.FontSource = detectFont(homeDir)
+
+	configs := append([]string{filepath.Join(homeDir, ".config", "sway", "config")}, oldConfigs...)
+	r.Wallpaper, r.WallpaperSource = detectWallpaper(homeDir, configs)
+
+	seen := make(map[string]bool)
+	for _, path := range configs {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, bind := range parseRiceBinds(string(data)) {
+			key := strings.ToLower(bind.Keys)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			bind.Source = path
+			r.Keybinds = append(r.Keybinds, bind)
+		}
+	}
+
+	r.Autostarts = detectAutostarts(homeDir)
+	return r
+}
+
+var (
+	cssFontPattern    = regexp.MustCompile(`font-family:\s*([^;}]+)`)
+	rasiFontPattern   = regexp.MustCompile(`(?m)^\s*font:\s*"([^"]+)"`)
+	dunstFontPattern  = regexp.MustCompile(`(?m)^\s*font\s*=\s*"?([^"\n]+)"?`)
+	fontSizePattern   = regexp.MustCompile(`\s+\d+(\.\d+)?$`)
+	genericFontFamily = map[string]bool{"monospace": true, "sans-serif": true, "serif": true, "sans": true, "system-ui": true}
+)
+
+// detectFont takes the first named font from waybar, rofi or dunst, in
+// that order, without its size
+func detectFont(homeDir string) (string, string) {
+	sources := []struct {
+		path    string
+		pattern *regexp.Regexp
+	}{
+		{".config/waybar/style.css", cssFontPattern},
+		{".config/rofi/config.rasi", rasiFontPattern},
+		{".config/dunst/dunstrc", dunstFontPattern},
+	}
+	for _, s := range sources {
+		path := filepath.Join(homeDir, s.path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		m := s.pattern.FindStringSubmatch(string(data))
+		if m == nil {
+			continue
+		}
+		for _, family := range strings.Split(m[1], ",") {
+			family = strings.Trim(strings.TrimSpace(family), `"'`)
+			family = fontSizePattern.ReplaceAllString(family, "")
+			if family != "" && !genericFontFamily[strings.ToLower(family)] {
+				return family, path
+			}
+		}
+	}
+	return "", ""
+}
+
+var wallpaperPatterns = []*regexp.Regexp{
+	// hyprpaper: wallpaper = DP-1,~/walls/a.png
+	regexp.MustCompile(`(?m)^\s*wallpaper\s*=\s*[^,\n]*,\s*(\S+)`),
+	// sway: output * bg ~/walls/a.png fill
+	regexp.MustCompile(`(?m)^\s*output\s+\S+\s+(?:bg|background)\s+(\S+)`),
+	// swaybg started from any compositor
+	regexp.MustCompile(`swaybg\b[^\n]*?(?:-i|--image)"?\s+"?([^"\s]+)`),
+	// swaylock: image=~/walls/a.png or image=eDP-1:~/walls/a.png
+	regexp.MustCompile(`(?m)^\s*image\s*=\s*(?:[^:/~\n]+:)?(\S+)`),
+}
+
+// detectWallpaper finds an image the old setup drew as the wallpaper.
+// Paths that no longer exist are skipped.
+func detectWallpaper(homeDir string, configs []string) (string, string) {
+	sources := append([]string{
+		filepath.Join(homeDir, ".config", "hypr", "hyprpaper.conf"),
+	}, configs...)
+	sources = append(sources, filepath.Join(homeDir, ".config", "swaylock", "config"))
+
+	for _, path := range sources {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, pattern := range wallpaperPatterns {
+			for _, m := range pattern.FindAllStringSubmatch(string(data), -1) {
+				image := expandHome(strings.Trim(m[1], `"'`), homeDir)
+				if info, err := os.Stat(image); err == nil && !info.IsDir() {
+					return image, path
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+func expandHome(path, homeDir string) string {
+	switch {
+	case path == "~":
+		return homeDir
+	case strings.HasPrefix(path, "~/"):
+		return filepath.Join(homeDir, path[2:])
+	case strings.HasPrefix(path, "$HOME/"):
+		return filepath.Join(homeDir, path[6:])
+	}
+	return path
+}
+
+// riceBindAction maps the command a keybinding ran to the DMS IPC call
+// that replaces it, or nil when DMS has no equivalent
+func riceBindAction(command string) []string {
+	switch {
+	case strings.Contains(command, "cliphist") || strings.Contains(command, "clipman"):
+		return []string{"clipboard", "toggle"}
+	case strings.Contains(command, "dunstctl") || strings.Contains(command, "makoctl") || strings.Contains(command, "swaync-client"):
+		return []string{"notifications", "toggle"}
+	}
+
+	fields := strings.Fields(strings.Trim(command, `"`))
+	if len(fields) == 0 {
+		return nil
+	}
+	switch filepath.Base(strings.Trim(fields[0], `"`)) {
+	case "swaylock", "hyprlock", "gtklock", "waylock":
+		return []string{"lock", "lock"}
+	case "rofi", "wofi", "fuzzel", "tofi", "tofi-drun", "bemenu-run", "dmenu_run", "anyrun", "walker":
+		return []string{"spotlight", "toggle"}
+	}
+	return nil
+}
+
+var (
+	swaySetPattern  = regexp.MustCompile(`^set\s+(\$\S+)\s+(.+)$`)
+	swayBindPattern = regexp.MustCompile(`^bindsym\s+(?:--\S+\s+)*(\S+)\s+exec\s+(?:--no-startup-id\s+)?(.+)$`)
+	hyprVarPattern  = regexp.MustCompile(`^(\$\w+)\s*=\s*(.+)$`)
+	hyprBindPattern = regexp.MustCompile(`^bind[a-z]*\s*=\s*([^,]*),\s*([^,]+),\s*exec\s*,\s*(.+)$`)
+	niriBindPattern = regexp.MustCompile(`^([A-Za-z0-9_+]+)\s[^{]*\{\s*spawn\s+(.+?);?\s*\}|^([A-Za-z0-9_+]+)\s*\{\s*spawn\s+(.+?);?\s*\}`)
+)
+
+// parseRiceBinds reads sway, Hyprland and niri keybindings that run a
+// launcher, locker, clipboard or notification tool
+func parseRiceBinds(config string) []RiceBind {
+	vars := make(map[string]string)
+	expand := func(s string) string {
+		// Longest names first so $mod does not eat $modkey
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+		for _, name := range names {
+			s = strings.ReplaceAll(s, name, vars[name])
+		}
+		return s
+	}
+
+	var binds []RiceBind
+	add := func(mods []string, key, command string) {
+		action := riceBindAction(command)
+		keys := normalizeBindKeys(mods, key)
+		if action != nil && keys != "" {
+			binds = append(binds, RiceBind{Keys: keys, Action: action})
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(config))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		if m := swaySetPattern.FindStringSubmatch(line); m != nil {
+			vars[m[1]] = expand(m[2])
+			continue
+		}
+		if m := hyprVarPattern.FindStringSubmatch(line); m != nil {
+			vars[m[1]] = expand(m[2])
+			continue
+		}
+		if m := swayBindPattern.FindStringSubmatch(line); m != nil {
+			parts := strings.Split(expand(m[1]), "+")
+			add(parts[:len(parts)-1], parts[len(parts)-1], expand(m[2]))
+			continue
+		}
+		if m := hyprBindPattern.FindStringSubmatch(line); m != nil {
+			mods := strings.FieldsFunc(expand(m[1]), func(r rune) bool { return r == ' ' || r == '_' })
+			add(mods, strings.TrimSpace(m[2]), expand(m[3]))
+			continue
+		}
+		if m := niriBindPattern.FindStringSubmatch(line); m != nil {
+			keys, spawn := m[1], m[2]
+			if keys == "" {
+				keys, spawn = m[3], m[4]
+			}
+			parts := strings.Split(keys, "+")
+			add(parts[:len(parts)-1], parts[len(parts)-1], strings.ReplaceAll(spawn, `"`, ""))
+		}
+	}
+	return binds
+}
+
+var bindModifiers = map[string]string{
+	"mod": "Mod", "mod4": "Mod", "super": "Mod", "win": "Mod", "logo": "Mod",
+	"mod1": "Alt", "alt": "Alt",
+	"ctrl": "Ctrl", "control": "Ctrl", "shift": "Shift",
+}
+
+var bindModifierOrder = []string{"Mod", "Ctrl", "Alt", "Shift"}
+
+var bindKeyNames = map[string]string{
+	"return": "Return", "enter": "Return", "space": "Space", "tab": "Tab",
+	"escape": "Escape", "esc": "Escape", "backspace": "BackSpace", "delete": "Delete",
+}
+
+// normalizeBindKeys writes a binding as niri does, Mod+Shift+D, or returns
+// "" for modifiers it doesn't know
+func normalizeBindKeys(mods []string, key string) string {
+	have := make(map[string]bool)
+	for _, mod := range mods {
+		mod = strings.TrimSpace(mod)
+		if mod == "" {
+			continue
+		}
+		name, ok := bindModifiers[strings.ToLower(mod)]
+		if !ok {
+			return ""
+		}
+		have[name] = true
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return ""
+	}
+	if name, ok := bindKeyNames[strings.ToLower(key)]; ok {
+		key = name
+	} else if len(key) == 1 {
+		key = strings.ToUpper(key)
+	}
+
+	var parts []string
+	for _, mod := range bindModifierOrder {
+		if have[mod] {
+			parts = append(parts, mod)
+		}
+	}
+	return strings.Join(append(parts, key), "+")
+}
+
+// detectAutostarts lists XDG autostart entries and enabled systemd user
+// units that start a conflicting bar, notification daemon or wallpaper
+func detectAutostarts(homeDir string) []RiceAutostart {
+	var found []RiceAutostart
+
+	entries, _ := filepath.Glob(filepath.Join(homeDir, ".config", "autostart", "*.desktop"))
+	sort.Strings(entries)
+	for _, path := range entries {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		content := string(data)
+		if iniValue(content, "Desktop Entry", "Hidden") == "true" {
+			continue
+		}
+		fields := strings.Fields(iniValue(content, "Desktop Entry", "Exec"))
+		if len(fields) == 0 {
+			continue
+		}
+		if program := filepath.Base(fields[0]); conflictingPrograms[program] {
+			found = append(found, RiceAutostart{Program: program, Path: path})
+		}
+	}
+
+	links, _ := filepath.Glob(filepath.Join(homeDir, ".config", "systemd", "user", "*.wants", "*.service"))
+	sort.Strings(links)
+	seen := make(map[string]bool)
+	for _, link := range links {
+		unit := filepath.Base(link)
+		program := strings.TrimSuffix(unit, ".service")
+		if conflictingPrograms[program] && !seen[unit] {
+			seen[unit] = true
+			found = append(found, RiceAutostart{Program: program, Unit: unit})
+		}
+	}
+	return found
+}
+
+// iniValue returns key from section of a desktop entry style file
+func iniValue(content, section, key string) string {
+	inSection := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = line == "["+section+"]"
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// MigrateRice imports the selected settings into DMS and disables the
+// conflicting autostarts. Every change is recorded in the install manifest
+// so --revert-session undoes it. A failing step is reported as a warning
+// and does not stop the others.
+func (b *BaseDistribution) MigrateRice(ctx context.Context, setup RiceSetup, opts MigrateOptions) (SessionReport, error) {
+	var report SessionReport
+
+	u, err := b.lookupTargetUser()
+	if err != nil {
+		return report, fmt.Errorf("failed to determine target user: %w", err)
+	}
+
+	manifestPath := InstallManifestPath(u.HomeDir)
+	manifest, err := LoadInstallManifest(manifestPath)
+	if err != nil {
+		return report, err
+	}
+
+	if opts.ImportFont && setup.Font != "" {
+		path := filepath.Join(u.HomeDir, ".config", "DankMaterialShell", "settings.json")
+		if err := updateUserJSON(manifest, StepMigrateFont, "DMS font from "+setup.FontSource, path, "fontFamily", setup.Font); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("font: %v", err))
+		} else {
+			report.Applied = append(report.Applied, fmt.Sprintf("Set the shell font to %s (from %s)", setup.Font, setup.FontSource))
+		}
+	}
+
+	if opts.ImportWallpaper && setup.Wallpaper != "" {
+		path := filepath.Join(u.HomeDir, ".local", "state", "DankMaterialShell", "session.json")
+		if err := updateUserJSON(manifest, StepMigrateWallpaper, "DMS wallpaper from "+setup.WallpaperSource, path, "wallpaperPath", setup.Wallpaper); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("wallpaper: %v", err))
+		} else {
+			report.Applied = append(report.Applied, fmt.Sprintf("Set the wallpaper to %s", setup.Wallpaper))
+		}
+	}
+
+	if opts.ImportKeybinds && len(setup.Keybinds) > 0 {
+		b.migrateKeybinds(setup.Keybinds, opts, manifest, &report)
+	}
+
+	if opts.DisableAutostarts {
+		for _, a := range setup.Autostarts {
+			b.disableAutostart(ctx, a, manifest, &report)
+		}
+	}
+
+	if err := manifest.Save(manifestPath); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to record migration steps: %v", err))
+	}
+	return report, nil
+}
+
+// recordUserFile saves the current content of a file in the user's home
+// before it is changed
+func recordUserFile(manifest *InstallManifest, id, description, path string) {
+	step := ManifestStep{
+		ID:          id,
+		Kind:        StepUserFile,
+		Description: description,
+		Path:        path,
+		AppliedAt:   time.Now(),
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		step.Previous = string(data)
+		if info, err := os.Stat(path); err == nil {
+			step.Mode = uint32(info.Mode().Perm())
+		}
+	} else {
+		step.Created = true
+	}
+	manifest.Record(step)
+}
+
+// updateUserJSON sets one top level key of a JSON object file, keeping the
+// rest of it
+func updateUserJSON(manifest *InstallManifest, id, description, path, key string, value any) error {
+	settings := make(map[string]any)
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	settings[key] = value
+
+	out, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	recordUserFile(manifest, id, description, path)
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
+
+var (
+	niriBoundPattern = regexp.MustCompile(`^\s*([A-Za-z0-9_+]+)(?:\s[^{]*)?\{`)
+	hyprBoundPattern = regexp.MustCompile(`^\s*bind[a-z]*\s*=\s*([^,]*),\s*([^,]+),`)
+)
+
+// boundKeys lists the keys already bound in a niri or Hyprland config, in
+// normalizeBindKeys form and lower case
+func boundKeys(config string, wm deps.WindowManager) map[string]bool {
+	bound := make(map[string]bool)
+	vars := make(map[string]string)
+	inBinds := false
+	for _, line := range strings.Split(config, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch wm {
+		case deps.WindowManagerNiri:
+			if trimmed == "binds {" {
+				inBinds = true
+				continue
+			}
+			if !inBinds {
+				continue
+			}
+			if m := niriBoundPattern.FindStringSubmatch(line); m != nil {
+				parts := strings.Split(m[1], "+")
+				bound[strings.ToLower(normalizeBindKeys(parts[:len(parts)-1], parts[len(parts)-1]))] = true
+			}
+		case deps.WindowManagerHyprland:
+			if m := hyprVarPattern.FindStringSubmatch(trimmed); m != nil {
+				vars[m[1]] = m[2]
+				continue
+			}
+			if m := hyprBoundPattern.FindStringSubmatch(line); m != nil {
+				mods := m[1]
+				for name, value := range vars {
+					mods = strings.ReplaceAll(mods, name, value)
+				}
+				fields := strings.FieldsFunc(mods, func(r rune) bool { return r == ' ' || r == '_' })
+				bound[strings.ToLower(normalizeBindKeys(fields, m[2]))] = true
+			}
+		}
+	}
+	return bound
+}
+
+// addRiceBinds adds binds to a DMS compositor config, skipping keys it
+// already uses. It returns the new config and the binds added.
+func addRiceBinds(config string, wm deps.WindowManager, binds []RiceBind) (string, []RiceBind) {
+	bound := boundKeys(config, wm)
+	var added []RiceBind
+	var lines []string
+	for _, bind := range binds {
+		if bound[strings.ToLower(bind.Keys)] {
+			continue
+		}
+		bound[strings.ToLower(bind.Keys)] = true
+		added = append(added, bind)
+
+		switch wm {
+		case deps.WindowManagerNiri:
+			args := []string{`"dms"`, `"ipc"`, `"call"`}
+			for _, a := range bind.Action {
+				args = append(args, `"`+a+`"`)
+			}
+			lines = append(lines, fmt.Sprintf("    %s { spawn %s; }", bind.Keys, strings.Join(args, " ")))
+		case deps.WindowManagerHyprland:
+			parts := strings.Split(bind.Keys, "+")
+			mods := make([]string, 0, len(parts)-1)
+			for _, mod := range parts[:len(parts)-1] {
+				if mod == "Mod" {
+					mod = "SUPER"
+				}
+				mods = append(mods, strings.ToUpper(mod))
+			}
+			lines = append(lines, fmt.Sprintf("bind = %s, %s, exec, dms ipc call %s", strings.Join(mods, " "), parts[len(parts)-1], strings.Join(bind.Action, " ")))
+		}
+	}
+	if len(added) == 0 {
+		return config, nil
+	}
+
+	switch wm {
+	case deps.WindowManagerNiri:
+		idx := strings.Index(config, "binds {\n")
+		if idx < 0 {
+			return config + "\nbinds {\n    // Migrated keybindings\n" + strings.Join(lines, "\n") + "\n}\n", added
+		}
+		at := idx + len("binds {\n")
+		return config[:at] + "    // Migrated keybindings\n" + strings.Join(lines, "\n") + "\n" + config[at:], added
+	default:
+		return strings.TrimRight(config, "\n") + "\n\n# Migrated keybindings\n" + strings.Join(lines, "\n") + "\n", added
+	}
+}
+
+func (b *BaseDistribution) migrateKeybinds(binds []RiceBind, opts MigrateOptions, manifest *InstallManifest, report *SessionReport) {
+	if opts.CompositorConfig == "" {
+		report.Skipped = append(report.Skipped, "Keybindings: no DMS compositor config was deployed")
+		return
+	}
+	data, err := os.ReadFile(opts.CompositorConfig)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("keybindings: %v", err))
+		return
+	}
+
+	updated, added := addRiceBinds(string(data), opts.WindowManager, binds)
+	if len(added) < len(binds) {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("%d keybindings already used by DMS", len(binds)-len(added)))
+	}
+	if len(added) == 0 {
+		return
+	}
+
+	recordUserFile(manifest, StepMigrateKeybinds, "migrated keybindings in "+opts.CompositorConfig, opts.CompositorConfig)
+	if err := os.WriteFile(opts.CompositorConfig, []byte(updated), 0644); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("keybindings: %v", err))
+		return
+	}
+	for _, bind := range added {
+		report.Applied = append(report.Applied, fmt.Sprintf("Bound %s to %s (was in %s)", bind.Keys, strings.Join(bind.Action, " "), bind.Source))
+	}
+}
+
+func (b *BaseDistribution) disableAutostart(ctx context.Context, a RiceAutostart, manifest *InstallManifest, report *SessionReport) {
+	if a.Unit != "" {
+		if out, err := b.asTargetUser(ctx, "systemctl", "--user", "disable", a.Unit).CombinedOutput(); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to disable %s: %v: %s", a.Unit, err, strings.TrimSpace(string(out))))
+			return
+		}
+		manifest.Record(ManifestStep{
+			ID:          StepMigrateAutostart + ":" + a.Unit,
+			Kind:        StepUserUnit,
+			Description: "disabled " + a.Unit,
+			Previous:    a.Unit,
+			AppliedAt:   time.Now(),
+		})
+		report.Applied = append(report.Applied, "Disabled the "+a.Unit+" user service")
+		return
+	}
+
+	data, err := os.ReadFile(a.Path)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to disable %s: %v", a.Path, err))
+		return
+	}
+	recordUserFile(manifest, StepMigrateAutostart+":"+a.Path, "disabled autostart "+filepath.Base(a.Path), a.Path)
+	if err := os.WriteFile(a.Path, []byte(setIniKey(string(data), "Desktop Entry", "Hidden", "true")), 0644); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to disable %s: %v", a.Path, err))
+		return
+	}
+	report.Applied = append(report.Applied, fmt.Sprintf("Disabled the %s autostart entry %s", a.Program, a.Path))
+}
@@ -0,0 +1,163 @@
+package distros
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestNormalizeBindKeys(t *testing.T) {
+	assert.Equal(t, "Mod+Shift+D", normalizeBindKeys([]string{"Shift", "Mod4"}, "d"))
+	assert.Equal(t, "Mod+Ctrl+Alt+Return", normalizeBindKeys([]string{"SUPER", "ALT", "CTRL"}, "RETURN"))
+	assert.Equal(t, "Mod+Space", normalizeBindKeys([]string{"super"}, "space"))
+	assert.Equal(t, "", normalizeBindKeys([]string{"Hyper"}, "d"))
+	assert.Equal(t, "", normalizeBindKeys([]string{"Mod"}, ""))
+}
+
+func TestParseRiceBinds(t *testing.T) {
+	t.Run("sway", func(t *testing.T) {
+		config := `set $mod Mod4
+set $menu rofi -show drun
+bindsym $mod+d exec $menu
+bindsym --release $mod+Shift+x exec --no-startup-id swaylock -f
+bindsym $mod+Return exec foot
+# bindsym $mod+v exec cliphist list
+`
+		assert.Equal(t, []RiceBind{
+			{Keys: "Mod+D", Action: []string{"spotlight", "toggle"}},
+			{Keys: "Mod+Shift+X", Action: []string{"lock", "lock"}},
+		}, parseRiceBinds(config))
+	})
+
+	t.Run("hyprland", func(t *testing.T) {
+		config := `$mainMod = SUPER
+$menu = wofi --show drun
+bind = $mainMod, R, exec, $menu
+bind = $mainMod SHIFT, V, exec, cliphist list | wofi --dmenu | cliphist decode | wl-copy
+bind = $mainMod, N, exec, swaync-client -t
+bind = $mainMod, Q, killactive,
+`
+		assert.Equal(t, []RiceBind{
+			{Keys: "Mod+R", Action: []string{"spotlight", "toggle"}},
+			{Keys: "Mod+Shift+V", Action: []string{"clipboard", "toggle"}},
+			{Keys: "Mod+N", Action: []string{"notifications", "toggle"}},
+		}, parseRiceBinds(config))
+	})
+
+	t.Run("niri", func(t *testing.T) {
+		config := `binds {
+    Mod+D hotkey-overlay-title="Launcher" { spawn "fuzzel"; }
+    Super+Alt+L { spawn "swaylock"; }
+    Mod+T { spawn "alacritty"; }
+}
+`
+		assert.Equal(t, []RiceBind{
+			{Keys: "Mod+D", Action: []string{"spotlight", "toggle"}},
+			{Keys: "Mod+Alt+L", Action: []string{"lock", "lock"}},
+		}, parseRiceBinds(config))
+	})
+}
+
+func TestAddRiceBinds(t *testing.T) {
+	binds := []RiceBind{
+		{Keys: "Mod+Space", Action: []string{"spotlight", "toggle"}},
+		{Keys: "Mod+Shift+X", Action: []string{"lock", "lock"}},
+	}
+
+	t.Run("niri", func(t *testing.T) {
+		config := "input {\n}\n\nbinds {\n    Mod+Space { spawn \"dms\" \"ipc\" \"call\" \"spotlight\" \"toggle\"; }\n}\n"
+		updated, added := addRiceBinds(config, deps.WindowManagerNiri, binds)
+		assert.Equal(t, binds[1:], added)
+		assert.Equal(t, "input {\n}\n\nbinds {\n    // Migrated keybindings\n"+
+			"    Mod+Shift+X { spawn \"dms\" \"ipc\" \"call\" \"lock\" \"lock\"; }\n"+
+			"    Mod+Space { spawn \"dms\" \"ipc\" \"call\" \"spotlight\" \"toggle\"; }\n}\n", updated)
+	})
+
+	t.Run("hyprland", func(t *testing.T) {
+		config := "$mod = SUPER\nbind = $mod SHIFT, X, exec, dms ipc call lock lock\n"
+		updated, added := addRiceBinds(config, deps.WindowManagerHyprland, binds)
+		assert.Equal(t, binds[:1], added)
+		assert.Equal(t, config+"\n# Migrated keybindings\nbind = SUPER, Space, exec, dms ipc call spotlight toggle\n", updated)
+	})
+
+	t.Run("nothing new", func(t *testing.T) {
+		config := "binds {\n    Mod+Space { spawn \"fuzzel\"; }\n    Mod+Shift+X { spawn \"swaylock\"; }\n}\n"
+		updated, added := addRiceBinds(config, deps.WindowManagerNiri, binds)
+		assert.Empty(t, added)
+		assert.Equal(t, config, updated)
+	})
+}
+
+func TestDetectRice(t *testing.T) {
+	home := t.TempDir()
+	wallpaper := filepath.Join(home, "Pictures", "wall.png")
+	writeTestFile(t, wallpaper, "png")
+	writeTestFile(t, filepath.Join(home, ".config", "waybar", "style.css"),
+		"* {\n  font-family: \"JetBrainsMono Nerd Font\", monospace;\n}\n")
+	writeTestFile(t, filepath.Join(home, ".config", "dunst", "dunstrc"), "[global]\nfont = Inter 11\n")
+	writeTestFile(t, filepath.Join(home, ".config", "hypr", "hyprpaper.conf"),
+		"preload = ~/Pictures/wall.png\nwallpaper = ,~/Pictures/missing.png\nwallpaper = DP-1,~/Pictures/wall.png\n")
+	writeTestFile(t, filepath.Join(home, ".config", "autostart", "waybar.desktop"),
+		"[Desktop Entry]\nType=Application\nExec=/usr/bin/waybar -c style\n")
+	writeTestFile(t, filepath.Join(home, ".config", "autostart", "nm-applet.desktop"),
+		"[Desktop Entry]\nExec=nm-applet\n")
+	writeTestFile(t, filepath.Join(home, ".config", "autostart", "dunst.desktop"),
+		"[Desktop Entry]\nExec=dunst\nHidden=true\n")
+	writeTestFile(t, filepath.Join(home, ".config", "systemd", "user", "graphical-session.target.wants", "mako.service"), "")
+
+	oldConfig := filepath.Join(home, ".config", "hypr", "hyprland.conf.backup")
+	writeTestFile(t, oldConfig, "bind = SUPER, D, exec, rofi -show drun\n")
+
+	r := DetectRice(home, []string{oldConfig})
+	assert.Equal(t, []string{"waybar", "dunst", "hyprpaper"}, r.Components)
+	assert.Equal(t, "JetBrainsMono Nerd Font", r.Font)
+	assert.Equal(t, filepath.Join(home, ".config", "waybar", "style.css"), r.FontSource)
+	assert.Equal(t, wallpaper, r.Wallpaper)
+	assert.Equal(t, []RiceBind{
+		{Keys: "Mod+D", Action: []string{"spotlight", "toggle"}, Source: oldConfig},
+	}, r.Keybinds)
+	assert.Equal(t, []RiceAutostart{
+		{Program: "waybar", Path: filepath.Join(home, ".config", "autostart", "waybar.desktop")},
+		{Program: "mako", Unit: "mako.service"},
+	}, r.Autostarts)
+	assert.False(t, r.Empty())
+
+	assert.True(t, DetectRice(t.TempDir(), nil).Empty())
+}
+
+func TestDetectFontSkipsGenericAndSize(t *testing.T) {
+	home := t.TempDir()
+	writeTestFile(t, filepath.Join(home, ".config", "rofi", "config.rasi"),
+		"configuration {\n  font: \"Iosevka 12\";\n}\n")
+
+	font, _ := detectFont(home)
+	assert.Equal(t, "Iosevka", font)
+}
+
+func TestUpdateUserJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	writeTestFile(t, path, "{\n  \"fontFamily\": \"Inter\",\n  \"use24HourClock\": true\n}\n")
+
+	manifest := &InstallManifest{}
+	require.NoError(t, updateUserJSON(manifest, StepMigrateFont, "font", path, "fontFamily", "Iosevka"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"fontFamily": "Iosevka", "use24HourClock": true}`, string(data))
+
+	step, ok := manifest.Step(StepMigrateFont)
+	require.True(t, ok)
+	assert.Equal(t, StepUserFile, step.Kind)
+	assert.Contains(t, step.Previous, "\"Inter\"")
+	assert.False(t, step.Created)
+}
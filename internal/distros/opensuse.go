@@ -335,6 +335,8 @@ func (o *OpenSUSEDistribution) InstallPackages(ctx context.Context, dependencies
 		LogOutput:  "Starting post-installation configuration...",
 	}
 
+	o.ConfigureSystemPrerequisites(ctx, sudoPassword, progressChan)
+
 	// Phase 5: Complete
 	progressChan <- InstallProgressMsg{
 		Phase:      PhaseComplete,
@@ -0,0 +1,647 @@
+package distros
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+const (
+	waylandSessionsDir      = "/usr/share/wayland-sessions"
+	accountsServiceUsersDir = "/var/lib/AccountsService/users"
+	greetdConfigPath        = "/etc/greetd/config.toml"
+	shellsFile              = "/etc/shells"
+	passwdFile              = "/etc/passwd"
+)
+
+// IDs of the session steps in the install manifest
+const (
+	StepSessionEntry           = "session-entry"
+	StepDefaultSessionAccounts = "default-session-accountsservice"
+	StepDefaultSessionGreetd   = "default-session-greetd"
+	StepLoginShell             = "login-shell"
+)
+
+// errRootFileMissing is the exit status readRootFile uses for a missing file
+const errRootFileMissing = 3
+
+type sessionInfo struct {
+	name    string
+	title   string
+	exec    string
+	comment string
+}
+
+var sessionInfos = map[deps.WindowManager]sessionInfo{
+	deps.WindowManagerNiri:     {"niri", "Niri", "niri-session", "A scrollable-tiling Wayland compositor"},
+	deps.WindowManagerHyprland: {"hyprland", "Hyprland", "Hyprland", "An intelligent dynamic tiling Wayland compositor"},
+}
+
+// SessionOptions selects the optional login steps offered after install.
+// Every step is recorded in the install manifest and can be reverted.
+type SessionOptions struct {
+	WindowManager deps.WindowManager
+	// InstallSessionEntry adds a Wayland session entry for the compositor
+	// when its package did not ship one
+	InstallSessionEntry bool
+	// SetDefaultSession preselects the compositor in AccountsService and
+	// tuigreet
+	SetDefaultSession bool
+	// Shell is the new login shell; empty keeps the current one
+	Shell string
+	// MaskConflicts keeps the services of other desktops that fight DMS,
+	// as found by DetectDesktopConflicts, out of DMS sessions
+	MaskConflicts bool
+	// Extras are the IDs of the Extras to install and enable
+	Extras []string
+}
+
+// SessionReport lists what ConfigureSession did
+type SessionReport struct {
+	Applied  []string
+	Skipped  []string
+	Warnings []string
+}
+
+// ConfigureSession applies the selected login session and shell steps for
+// the target user and records them in the install manifest. A failing step
+// is reported as a warning and does not stop the others.
+func (b *BaseDistribution) ConfigureSession(ctx context.Context, opts SessionOptions, sudoPassword string) (SessionReport, error) {
+	var report SessionReport
+
+	info, ok := sessionInfos[opts.WindowManager]
+	if !ok {
+		return report, fmt.Errorf("no session known for window manager %d", opts.WindowManager)
+	}
+
+	u, err := b.lookupTargetUser()
+	if err != nil {
+		return report, fmt.Errorf("failed to determine target user: %w", err)
+	}
+
+	manifestPath := InstallManifestPath(u.HomeDir)
+	manifest, err := LoadInstallManifest(manifestPath)
+	if err != nil {
+		return report, err
+	}
+
+	session := info.name
+	if existing := findSessionEntry(waylandSessionsDir, info); existing != "" {
+		session = strings.TrimSuffix(filepath.Base(existing), ".desktop")
+		if opts.InstallSessionEntry {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("Session entry already provided by %s", existing))
+		}
+	} else if opts.InstallSessionEntry {
+		path := filepath.Join(waylandSessionsDir, info.name+".desktop")
+		if err := writeRootFile(ctx, sudoPassword, path, sessionDesktopEntry(info), 0644); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to write %s: %v", path, err))
+		} else {
+			manifest.Record(ManifestStep{
+				ID:          StepSessionEntry,
+				Kind:        StepFile,
+				Description: "Wayland session entry for " + info.title,
+				Path:        path,
+				Mode:        0644,
+				Created:     true,
+				AppliedAt:   time.Now(),
+			})
+			report.Applied = append(report.Applied, "Added session entry "+path)
+		}
+	}
+
+	if opts.SetDefaultSession {
+		b.setAccountsServiceSession(ctx, u.Username, session, sudoPassword, manifest, &report)
+		b.setGreetdSession(ctx, info, sudoPassword, manifest, &report)
+	}
+
+	if opts.Shell != "" {
+		b.changeLoginShell(ctx, u, opts.Shell, sudoPassword, manifest, &report)
+	}
+
+	if opts.MaskConflicts {
+		b.maskDesktopConflicts(ctx, u.HomeDir, manifest, &report)
+	}
+
+	if len(opts.Extras) > 0 {
+		b.installExtras(ctx, opts.Extras, sudoPassword, manifest, &report)
+	}
+
+	if err := manifest.Save(manifestPath); err != nil {
+		return report, err
+	}
+	if err := b.chownToTargetUser(ctx, filepath.Dir(manifestPath)); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to chown %s: %v", manifestPath, err))
+	}
+
+	for _, w := range report.Warnings {
+		b.log("WARNING: " + w)
+	}
+	for _, a := range report.Applied {
+		b.log(a)
+	}
+	return report, nil
+}
+
+// setAccountsServiceSession preselects the session in display managers that
+// read AccountsService (GDM, SDDM, LightDM). The daemon picks it up when it
+// next starts.
+func (b *BaseDistribution) setAccountsServiceSession(ctx context.Context, username, session, sudoPassword string, manifest *InstallManifest, report *SessionReport) {
+	if _, err := os.Stat(accountsServiceUsersDir); err != nil {
+		report.Skipped = append(report.Skipped, "AccountsService is not installed")
+		return
+	}
+
+	path := filepath.Join(accountsServiceUsersDir, username)
+	previous, exists, err := readRootFile(ctx, sudoPassword, path)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to read %s: %v", path, err))
+		return
+	}
+
+	updated := setIniKey(previous, "User", "Session", session)
+	if updated == previous {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("AccountsService already defaults to %s", session))
+		return
+	}
+	if err := writeRootFile(ctx, sudoPassword, path, updated, 0600); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to write %s: %v", path, err))
+		return
+	}
+
+	manifest.Record(ManifestStep{
+		ID:          StepDefaultSessionAccounts,
+		Kind:        StepFile,
+		Description: "AccountsService default session " + session,
+		Path:        path,
+		Mode:        0600,
+		Previous:    previous,
+		Created:     !exists,
+		AppliedAt:   time.Now(),
+	})
+	report.Applied = append(report.Applied, fmt.Sprintf("Set default session to %s in AccountsService", session))
+}
+
+// setGreetdSession points tuigreet at the compositor. The DMS greeter and
+// other greetd greeters remember the last session on their own.
+func (b *BaseDistribution) setGreetdSession(ctx context.Context, info sessionInfo, sudoPassword string, manifest *InstallManifest, report *SessionReport) {
+	data, err := os.ReadFile(greetdConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to read %s: %v", greetdConfigPath, err))
+		return
+	}
+
+	previous := string(data)
+	updated, ok := setTuigreetCommand(previous, info.exec)
+	if !ok {
+		report.Skipped = append(report.Skipped, "greetd does not use tuigreet; the greeter remembers the last session")
+		return
+	}
+	if updated == previous {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("tuigreet already starts %s", info.exec))
+		return
+	}
+	if err := writeRootFile(ctx, sudoPassword, greetdConfigPath, updated, 0644); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to write %s: %v", greetdConfigPath, err))
+		return
+	}
+
+	manifest.Record(ManifestStep{
+		ID:          StepDefaultSessionGreetd,
+		Kind:        StepFile,
+		Description: "tuigreet default session " + info.exec,
+		Path:        greetdConfigPath,
+		Mode:        0644,
+		Previous:    previous,
+		AppliedAt:   time.Now(),
+	})
+	report.Applied = append(report.Applied, fmt.Sprintf("Set tuigreet to start %s", info.exec))
+}
+
+func (b *BaseDistribution) changeLoginShell(ctx context.Context, u *user.User, shell, sudoPassword string, manifest *InstallManifest, report *SessionReport) {
+	shells, err := readShells(shellsFile)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to read %s: %v", shellsFile, err))
+		return
+	}
+	if !shells[shell] {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s is not listed in %s", shell, shellsFile))
+		return
+	}
+
+	current, err := readLoginShell(passwdFile, u.Username)
+	if err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+		return
+	}
+	if current == shell {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("Login shell is already %s", shell))
+		return
+	}
+
+	if err := setLoginShell(ctx, u, shell, sudoPassword); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to change login shell: %v", err))
+		return
+	}
+
+	manifest.Record(ManifestStep{
+		ID:          StepLoginShell,
+		Kind:        StepShell,
+		Description: "Login shell " + shell,
+		User:        u.Username,
+		Previous:    current,
+		AppliedAt:   time.Now(),
+	})
+	report.Applied = append(report.Applied, fmt.Sprintf("Changed login shell of %s from %s to %s", u.Username, current, shell))
+}
+
+// setLoginShell runs chsh as root so it does not stop at its own password
+// prompt. Without working sudo it falls back to AccountsService, which asks
+// polkit (org.freedesktop.accounts.user-administration) instead.
+func setLoginShell(ctx context.Context, u *user.User, shell, sudoPassword string) error {
+	chsh := sudoExec(ctx, sudoPassword, "chsh", "-s", shell, u.Username)
+	chshErr := chsh.Run()
+	if chshErr == nil {
+		return nil
+	}
+
+	if _, err := exec.LookPath("busctl"); err != nil {
+		return chshErr
+	}
+	objectPath := "/org/freedesktop/Accounts/User" + u.Uid
+	busctl := exec.CommandContext(ctx, "busctl", "call", "--system", "org.freedesktop.Accounts",
+		objectPath, "org.freedesktop.Accounts.User", "SetShell", "s", shell)
+	if out, err := busctl.CombinedOutput(); err != nil {
+		return fmt.Errorf("chsh: %v, AccountsService: %s", chshErr, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RevertInstallSteps undoes the steps recorded in the target user's install
+// manifest, newest first. Steps that were undone are removed from the
+// manifest; the ones that failed stay so the revert can be retried.
+func (b *BaseDistribution) RevertInstallSteps(ctx context.Context, sudoPassword string) (SessionReport, error) {
+	var report SessionReport
+
+	u, err := b.lookupTargetUser()
+	if err != nil {
+		return report, fmt.Errorf("failed to determine target user: %w", err)
+	}
+
+	manifestPath := InstallManifestPath(u.HomeDir)
+	manifest, err := LoadInstallManifest(manifestPath)
+	if err != nil {
+		return report, err
+	}
+
+	for i := len(manifest.Steps) - 1; i >= 0; i-- {
+		step := manifest.Steps[i]
+		if err := revertStep(ctx, step, sudoPassword); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to revert %s: %v", step.Description, err))
+			continue
+		}
+		manifest.Remove(step.ID)
+		report.Applied = append(report.Applied, "Reverted "+step.Description)
+	}
+
+	if len(manifest.Steps) == 0 {
+		if err := os.Remove(manifestPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return report, err
+		}
+		return report, nil
+	}
+	return report, manifest.Save(manifestPath)
+}
+
+func revertStep(ctx context.Context, step ManifestStep, sudoPassword string) error {
+	switch step.Kind {
+	case StepFile:
+		if err := checkRevertPath(step.Path); err != nil {
+			return err
+		}
+		if step.Created {
+			return sudoExec(ctx, sudoPassword, "rm", "-f", "--", step.Path).Run()
+		}
+		return writeRootFile(ctx, sudoPassword, step.Path, step.Previous, os.FileMode(step.Mode))
+	case StepShell:
+		shells, err := readShells(shellsFile)
+		if err != nil {
+			return err
+		}
+		if !shells[step.Previous] {
+			return fmt.Errorf("%s is not listed in %s", step.Previous, shellsFile)
+		}
+		u, err := user.Lookup(step.User)
+		if err != nil {
+			return err
+		}
+		return setLoginShell(ctx, u, step.Previous, sudoPassword)
+	case StepUserFile:
+		if step.Created {
+			if err := os.Remove(step.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			return nil
+		}
+		return os.WriteFile(step.Path, []byte(step.Previous), os.FileMode(step.Mode))
+	case StepUserUnit:
+		return exec.CommandContext(ctx, "systemctl", "--user", "enable", step.Previous).Run()
+	case StepEnabledUnit:
+		if !isExtraUnit(step.Unit, false) {
+			return fmt.Errorf("refusing to disable %q: not a unit the installer enables", step.Unit)
+		}
+		return sudoExec(ctx, sudoPassword, "systemctl", "disable", "--now", step.Unit).Run()
+	case StepEnabledUserUnit:
+		if !isExtraUnit(step.Unit, true) {
+			return fmt.Errorf("refusing to disable %q: not a unit the installer enables", step.Unit)
+		}
+		return exec.CommandContext(ctx, "systemctl", "--user", "disable", "--now", step.Unit).Run()
+	case StepPackage:
+		return removePackage(ctx, step, sudoPassword)
+	default:
+		return fmt.Errorf("unknown step kind %q", step.Kind)
+	}
+}
+
+// checkRevertPath refuses root file steps outside the files the session
+// steps write. The manifest lives in the user's home, so a revert must not
+// let an edited one remove or overwrite anything else as root.
+func checkRevertPath(path string) error {
+	if !filepath.IsAbs(path) || filepath.Clean(path) != path {
+		return fmt.Errorf("refusing to revert %q: not a clean absolute path", path)
+	}
+	switch dir := filepath.Dir(path); {
+	case path == greetdConfigPath:
+	case dir == waylandSessionsDir && strings.HasSuffix(path, ".desktop"):
+	case dir == accountsServiceUsersDir:
+	case isExtraConfig(path):
+	default:
+		return fmt.Errorf("refusing to revert %s: not a file the installer manages", path)
+	}
+	return nil
+}
+
+// AvailableShells lists the login shells from /etc/shells that are
+// installed, one path per shell name
+func AvailableShells() []string {
+	f, err := os.Open(shellsFile)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var shells []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := filepath.Base(line)
+		if seen[name] || name == "nologin" || name == "false" {
+			continue
+		}
+		if _, err := os.Stat(line); err != nil {
+			continue
+		}
+		seen[name] = true
+		shells = append(shells, line)
+	}
+	return shells
+}
+
+// CurrentLoginShell returns the login shell of the invoking user
+func CurrentLoginShell() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	shell, _ := readLoginShell(passwdFile, u.Username)
+	return shell
+}
+
+// findSessionEntry returns an installed session entry that starts the
+// compositor, whatever the package named it
+func findSessionEntry(dir string, info sessionInfo) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".desktop") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			value, ok := strings.CutPrefix(strings.TrimSpace(line), "Exec=")
+			if !ok {
+				continue
+			}
+			fields := strings.Fields(value)
+			if len(fields) > 0 && strings.EqualFold(filepath.Base(fields[0]), info.exec) {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+func sessionDesktopEntry(info sessionInfo) string {
+	return fmt.Sprintf(`[Desktop Entry]
+Name=%s
+Comment=%s
+Exec=%s
+Type=Application
+DesktopNames=%s
+`, info.title, info.comment, info.exec, info.title)
+}
+
+// setIniKey sets key in section, adding the section if needed and leaving
+// every other line alone
+func setIniKey(content, section, key, value string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	header := "[" + section + "]"
+	inSection := false
+	sectionEnd := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			if inSection {
+				break
+			}
+			inSection = trimmed == header
+			if inSection {
+				sectionEnd = i + 1
+			}
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if trimmed != "" {
+			sectionEnd = i + 1
+		}
+		if k, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(k) == key {
+			lines[i] = key + "=" + value
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+
+	entry := key + "=" + value
+	if sectionEnd < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, header, entry)
+	} else {
+		lines = append(lines[:sectionEnd], append([]string{entry}, lines[sectionEnd:]...)...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// setTuigreetCommand sets the session tuigreet starts by default in the
+// greetd [default_session] command. It reports false when the greeter is
+// not tuigreet.
+func setTuigreetCommand(config, sessionCmd string) (string, bool) {
+	lines := strings.Split(config, "\n")
+	inDefault := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inDefault = trimmed == "[default_session]"
+			continue
+		}
+		if !inDefault {
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok || strings.TrimSpace(key) != "command" {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		fields := strings.Fields(value)
+		if len(fields) == 0 || filepath.Base(fields[0]) != "tuigreet" {
+			return config, false
+		}
+
+		replaced := false
+		for j := 1; j < len(fields); j++ {
+			switch {
+			case (fields[j] == "--cmd" || fields[j] == "-c") && j+1 < len(fields):
+				fields[j+1] = sessionCmd
+				replaced = true
+			case strings.HasPrefix(fields[j], "--cmd="):
+				fields[j] = "--cmd=" + sessionCmd
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields = append(fields, "--cmd", sessionCmd)
+		}
+		lines[i] = fmt.Sprintf(`command = "%s"`, strings.Join(fields, " "))
+		return strings.Join(lines, "\n"), true
+	}
+	return config, false
+}
+
+func readShells(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	shells := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			shells[line] = true
+		}
+	}
+	return shells, nil
+}
+
+func readLoginShell(path, username string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) == 7 && fields[0] == username {
+			return fields[6], nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in %s", username, path)
+}
+
+// readRootFile reads a file that may only be readable by root. It reports
+// whether the file exists so a revert knows to remove it again.
+func readRootFile(ctx context.Context, sudoPassword, path string) (string, bool, error) {
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		return string(data), true, nil
+	case errors.Is(err, os.ErrNotExist):
+		return "", false, nil
+	case !errors.Is(err, os.ErrPermission):
+		return "", false, err
+	}
+
+	// The path is an argument to the script, never part of it
+	var out bytes.Buffer
+	cmd := sudoExec(ctx, sudoPassword, "sh", "-c",
+		fmt.Sprintf(`test -e "$1" || exit %d; cat -- "$1"`, errRootFileMissing), "sh", path)
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == errRootFileMissing {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return out.String(), true, nil
+}
+
+// writeRootFile stages content in a temp file and installs it with sudo
+func writeRootFile(ctx context.Context, sudoPassword, path, content string, mode os.FileMode) error {
+	tmp, err := os.CreateTemp("", "dankinstall-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	cmd := sudoExec(ctx, sudoPassword, "install", "-D", "-m", fmt.Sprintf("%o", mode), "--", tmp.Name(), path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package distros
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetIniKey(t *testing.T) {
+	t.Run("empty file", func(t *testing.T) {
+		assert.Equal(t, "[User]\nSession=niri\n", setIniKey("", "User", "Session", "niri"))
+	})
+
+	t.Run("replaces existing key", func(t *testing.T) {
+		content := "[User]\nSession=gnome\nIcon=/home/dank/.face\nSystemAccount=false\n"
+		assert.Equal(t, "[User]\nSession=niri\nIcon=/home/dank/.face\nSystemAccount=false\n",
+			setIniKey(content, "User", "Session", "niri"))
+	})
+
+	t.Run("adds key to section", func(t *testing.T) {
+		content := "[User]\nIcon=/home/dank/.face\n\n[InputSource0]\nxkb=us\n"
+		assert.Equal(t, "[User]\nIcon=/home/dank/.face\nSession=hyprland\n\n[InputSource0]\nxkb=us\n",
+			setIniKey(content, "User", "Session", "hyprland"))
+	})
+
+	t.Run("adds missing section", func(t *testing.T) {
+		content := "[InputSource0]\nxkb=us\n"
+		assert.Equal(t, "[InputSource0]\nxkb=us\n\n[User]\nSession=niri\n",
+			setIniKey(content, "User", "Session", "niri"))
+	})
+
+	t.Run("ignores key in other section", func(t *testing.T) {
+		content := "[Other]\nSession=x\n[User]\n"
+		assert.Equal(t, "[Other]\nSession=x\n[User]\nSession=niri\n",
+			setIniKey(content, "User", "Session", "niri"))
+	})
+}
+
+func TestSetTuigreetCommand(t *testing.T) {
+	t.Run("appends cmd", func(t *testing.T) {
+		config := "[terminal]\nvt = 1\n\n[default_session]\ncommand = \"tuigreet --time\"\nuser = \"greeter\"\n"
+		updated, ok := setTuigreetCommand(config, "niri-session")
+		require.True(t, ok)
+		assert.Contains(t, updated, `command = "tuigreet --time --cmd niri-session"`)
+		assert.Contains(t, updated, `user = "greeter"`)
+	})
+
+	t.Run("replaces cmd", func(t *testing.T) {
+		config := "[default_session]\ncommand = \"/usr/bin/tuigreet --cmd sway --remember\"\n"
+		updated, ok := setTuigreetCommand(config, "Hyprland")
+		require.True(t, ok)
+		assert.Contains(t, updated, `command = "/usr/bin/tuigreet --cmd Hyprland --remember"`)
+	})
+
+	t.Run("replaces cmd with equals", func(t *testing.T) {
+		config := "[default_session]\ncommand = \"tuigreet --cmd=sway\"\n"
+		updated, ok := setTuigreetCommand(config, "niri-session")
+		require.True(t, ok)
+		assert.Contains(t, updated, `command = "tuigreet --cmd=niri-session"`)
+	})
+
+	t.Run("other greeter", func(t *testing.T) {
+		config := "[default_session]\ncommand = \"dms-greeter --command niri\"\n"
+		updated, ok := setTuigreetCommand(config, "niri-session")
+		assert.False(t, ok)
+		assert.Equal(t, config, updated)
+	})
+
+	t.Run("initial session untouched", func(t *testing.T) {
+		config := "[initial_session]\ncommand = \"tuigreet\"\n"
+		_, ok := setTuigreetCommand(config, "niri-session")
+		assert.False(t, ok)
+	})
+}
+
+func TestFindSessionEntry(t *testing.T) {
+	dir := t.TempDir()
+	info := sessionInfos[deps.WindowManagerHyprland]
+
+	assert.Empty(t, findSessionEntry(dir, info))
+	assert.Empty(t, findSessionEntry(filepath.Join(dir, "missing"), info))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sway.desktop"), []byte("[Desktop Entry]\nExec=sway\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hyprland-uwsm.desktop"), []byte("[Desktop Entry]\nExec=/usr/bin/Hyprland --config x\n"), 0644))
+
+	assert.Equal(t, filepath.Join(dir, "hyprland-uwsm.desktop"), findSessionEntry(dir, info))
+	assert.Empty(t, findSessionEntry(dir, sessionInfos[deps.WindowManagerNiri]))
+}
+
+func TestSessionDesktopEntry(t *testing.T) {
+	entry := sessionDesktopEntry(sessionInfos[deps.WindowManagerNiri])
+	assert.Contains(t, entry, "Exec=niri-session\n")
+	assert.Contains(t, entry, "DesktopNames=Niri\n")
+}
+
+func TestReadLoginShell(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwd")
+	require.NoError(t, os.WriteFile(path, []byte("root:x:0:0::/root:/bin/bash\ndank:x:1000:1000:Dank:/home/dank:/usr/bin/zsh\n"), 0644))
+
+	shell, err := readLoginShell(path, "dank")
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/bin/zsh", shell)
+
+	_, err = readLoginShell(path, "nobody")
+	assert.Error(t, err)
+}
+
+func TestInstallManifest(t *testing.T) {
+	path := InstallManifestPath(t.TempDir())
+
+	m, err := LoadInstallManifest(path)
+	require.NoError(t, err)
+	assert.Empty(t, m.Steps)
+
+	m.Record(ManifestStep{ID: StepLoginShell, Kind: StepShell, User: "dank", Previous: "/bin/bash"})
+	// Applying again must not lose the original shell
+	m.Record(ManifestStep{ID: StepLoginShell, Kind: StepShell, User: "dank", Previous: "/usr/bin/zsh", Description: "Login shell /usr/bin/fish"})
+	m.Record(ManifestStep{ID: StepSessionEntry, Kind: StepFile, Path: "/usr/share/wayland-sessions/niri.desktop", Created: true})
+	require.NoError(t, m.Save(path))
+
+	loaded, err := LoadInstallManifest(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Steps, 2)
+
+	step, ok := loaded.Step(StepLoginShell)
+	require.True(t, ok)
+	assert.Equal(t, "/bin/bash", step.Previous)
+	assert.Equal(t, "Login shell /usr/bin/fish", step.Description)
+
+	loaded.Remove(StepLoginShell)
+	_, ok = loaded.Step(StepLoginShell)
+	assert.False(t, ok)
+	assert.Len(t, loaded.Steps, 1)
+}
+
+func TestCheckRevertPath(t *testing.T) {
+	for _, path := range []string{
+		"/etc/greetd/config.toml",
+		"/usr/share/wayland-sessions/niri.desktop",
+		"/var/lib/AccountsService/users/dank",
+	} {
+		assert.NoError(t, checkRevertPath(path), path)
+	}
+
+	for _, path := range []string{
+		"/etc/passwd",
+		"/etc/sudoers.d/dank",
+		"/usr/share/wayland-sessions/../../../etc/shadow",
+		"/usr/share/wayland-sessions/niri.sh",
+		"/var/lib/AccountsService/users/nested/dank",
+		"/tmp/x; rm -rf /",
+		"relative/config.toml",
+		"",
+	} {
+		assert.Error(t, checkRevertPath(path), path)
+	}
+}
+
+func TestRevertStepRefusesUnmanagedPaths(t *testing.T) {
+	err := revertStep(t.Context(), ManifestStep{Kind: StepFile, Path: "/etc/shadow", Created: true}, "")
+	assert.ErrorContains(t, err, "refusing")
+}
@@ -0,0 +1,59 @@
+package distros
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInstallStats(t *testing.T) {
+	ok := NewInstallStats(FamilyArch, deps.WindowManagerNiri, "")
+	assert.Equal(t, `{"family":"arch","wm":"niri","outcome":"success"}`, ok.JSON())
+
+	failed := NewInstallStats(FamilyFedora, deps.WindowManagerHyprland, PhaseSystemPackages.String())
+	assert.Equal(t, `{"family":"fedora","wm":"hyprland","outcome":"failure","phase":"system-packages"}`, failed.JSON())
+}
+
+func TestRecordInstallStats(t *testing.T) {
+	home := t.TempDir()
+	manifest := &InstallManifest{}
+	manifest.Record(ManifestStep{ID: "shell", Kind: StepShell})
+	require.NoError(t, manifest.Save(InstallManifestPath(home)))
+
+	stats := NewInstallStats(FamilyDebian, deps.WindowManagerNiri, "")
+	require.NoError(t, RecordInstallStats(home, stats))
+
+	loaded, err := LoadInstallManifest(InstallManifestPath(home))
+	require.NoError(t, err)
+	assert.Len(t, loaded.Steps, 1, "recorded steps are kept")
+	assert.Equal(t, []InstallStats{stats}, loaded.Stats)
+}
+
+func TestShareInstallStats(t *testing.T) {
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	stats := NewInstallStats(FamilyArch, deps.WindowManagerHyprland, "configuration")
+	require.NoError(t, ShareInstallStats(context.Background(), srv.URL, stats))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, map[string]any{"family": "arch", "wm": "hyprland", "outcome": "failure", "phase": "configuration"}, got)
+	assert.Equal(t, "dankinstall", header.Get("User-Agent"))
+	assert.Empty(t, header.Get("Cookie"))
+
+	assert.Error(t, ShareInstallStats(context.Background(), "", stats))
+}
@@ -0,0 +1,229 @@
+package distros
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// SystemPrereqReport summarizes the kernel module and group checks run after
+// package installation. Group changes only apply to new login sessions.
+type SystemPrereqReport struct {
+	LoadedModules    []string
+	PersistedModules []string
+	AddedGroups      []string
+	Warnings         []string
+}
+
+// NeedsRelogin reports whether the user must log out for changes to apply
+func (r SystemPrereqReport) NeedsRelogin() bool {
+	return len(r.AddedGroups) > 0
+}
+
+// requiredModules are needed for DDC/CI brightness control of external monitors
+var requiredModules = []string{"i2c-dev"}
+
+// requiredGroups grant access to backlight, input and i2c devices. Groups that
+// do not exist on the system are skipped since some distros use udev uaccess instead.
+var requiredGroups = []string{"video", "input", "i2c"}
+
+const (
+	sysModuleDir     = "/sys/module"
+	modulesLoadDir   = "/etc/modules-load.d"
+	groupFile        = "/etc/group"
+	modulesLoadEntry = "dms.conf"
+)
+
+// ConfigureSystemPrerequisites loads required kernel modules, persists them in
+// modules-load.d and adds the target user to device access groups. Failures
+// are collected as warnings since none of these block the rest of the install.
+func (b *BaseDistribution) ConfigureSystemPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) SystemPrereqReport {
+	var report SystemPrereqReport
+
+	progressChan <- InstallProgressMsg{
+		Phase:      PhaseConfiguration,
+		Progress:   0.91,
+		Step:       "Checking kernel modules and groups...",
+		IsComplete: false,
+		LogOutput:  "Verifying i2c-dev module and video/input/i2c group membership",
+	}
+
+	// Inside a chroot the running kernel belongs to the live system, so only
+	// persist modules for the next boot.
+	inChroot := IsChroot()
+
+	var unpersisted []string
+	for _, module := range requiredModules {
+		if !inChroot && !moduleLoaded(sysModuleDir, module) {
+			if err := sudoExec(ctx, sudoPassword, "modprobe", "--", module).Run(); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("failed to load %s: %v", module, err))
+			} else {
+				report.LoadedModules = append(report.LoadedModules, module)
+				b.log(fmt.Sprintf("Loaded kernel module %s", module))
+			}
+		}
+
+		if !moduleConfiguredAtBoot(modulesLoadDir, module) {
+			unpersisted = append(unpersisted, module)
+		}
+	}
+
+	if len(unpersisted) > 0 {
+		target := filepath.Join(modulesLoadDir, modulesLoadEntry)
+		existing, _ := os.ReadFile(target)
+		content := modulesLoadContent(string(existing), unpersisted)
+		if err := writeRootFile(ctx, sudoPassword, target, content, 0644); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to persist %s in %s: %v", strings.Join(unpersisted, ", "), target, err))
+		} else {
+			report.PersistedModules = append(report.PersistedModules, unpersisted...)
+			b.log(fmt.Sprintf("Added %s to %s", strings.Join(unpersisted, ", "), target))
+		}
+	}
+
+	currentUser, err := b.lookupTargetUser()
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to determine current user: %v", err))
+		b.logPrereqReport(report, progressChan)
+		return report
+	}
+
+	memberOf := make(map[string]bool)
+	if gids, err := currentUser.GroupIds(); err == nil {
+		for _, gid := range gids {
+			if g, err := user.LookupGroupId(gid); err == nil {
+				memberOf[g.Name] = true
+			}
+		}
+	}
+
+	existing, err := readGroupNames(groupFile)
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to read %s: %v", groupFile, err))
+	}
+
+	for _, group := range missingGroups(requiredGroups, existing, memberOf) {
+		if err := sudoExec(ctx, sudoPassword, "usermod", "-aG", group, "--", currentUser.Username).Run(); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to add %s to group %s: %v", currentUser.Username, group, err))
+			continue
+		}
+		report.AddedGroups = append(report.AddedGroups, group)
+		b.log(fmt.Sprintf("Added %s to group %s", currentUser.Username, group))
+	}
+
+	b.logPrereqReport(report, progressChan)
+	return report
+}
+
+func (b *BaseDistribution) logPrereqReport(report SystemPrereqReport, progressChan chan<- InstallProgressMsg) {
+	for _, w := range report.Warnings {
+		b.log("WARNING: " + w)
+	}
+
+	step := "Kernel modules and groups OK"
+	logOutput := "All device access prerequisites satisfied"
+	if report.NeedsRelogin() {
+		step = "Log out and back in to apply group changes"
+		logOutput = fmt.Sprintf("Added to groups: %s (re-login required)", strings.Join(report.AddedGroups, ", "))
+	}
+
+	progressChan <- InstallProgressMsg{
+		Phase:      PhaseConfiguration,
+		Progress:   0.93,
+		Step:       step,
+		IsComplete: false,
+		LogOutput:  logOutput,
+	}
+}
+
+// moduleLoaded checks sysfs for a loaded module. Module names use underscores
+// in /sys/module regardless of how they are spelled for modprobe.
+func moduleLoaded(sysDir, module string) bool {
+	_, err := os.Stat(filepath.Join(sysDir, strings.ReplaceAll(module, "-", "_")))
+	return err == nil
+}
+
+func moduleConfiguredAtBoot(dir, module string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	normalized := strings.ReplaceAll(module, "-", "_")
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".conf") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			if strings.ReplaceAll(line, "-", "_") == normalized {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// modulesLoadContent is the dms modules-load.d file with modules added. The
+// whole file is rewritten, so modules already listed are not repeated.
+func modulesLoadContent(existing string, modules []string) string {
+	var lines []string
+	listed := make(map[string]bool)
+	for _, line := range strings.Split(existing, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		listed[strings.ReplaceAll(line, "-", "_")] = true
+	}
+	for _, module := range modules {
+		if !listed[strings.ReplaceAll(module, "-", "_")] {
+			lines = append(lines, module)
+			listed[strings.ReplaceAll(module, "-", "_")] = true
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func readGroupNames(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	groups := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, _, ok := strings.Cut(scanner.Text(), ":")
+		if ok && name != "" {
+			groups[name] = true
+		}
+	}
+
+	return groups, scanner.Err()
+}
+
+func missingGroups(wanted []string, existing, memberOf map[string]bool) []string {
+	var missing []string
+	for _, group := range wanted {
+		if existing[group] && !memberOf[group] {
+			missing = append(missing, group)
+		}
+	}
+	return missing
+}
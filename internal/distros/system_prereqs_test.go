@@ -0,0 +1,64 @@
+package distros
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleLoaded(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "i2c_dev"), 0755))
+
+	assert.True(t, moduleLoaded(dir, "i2c-dev"))
+	assert.False(t, moduleLoaded(dir, "uinput"))
+}
+
+func TestModuleConfiguredAtBoot(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.False(t, moduleConfiguredAtBoot(dir, "i2c-dev"))
+	assert.False(t, moduleConfiguredAtBoot(filepath.Join(dir, "missing"), "i2c-dev"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.conf"), []byte("# i2c-dev\nuinput\n"), 0644))
+	assert.False(t, moduleConfiguredAtBoot(dir, "i2c-dev"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ddc.txt"), []byte("i2c-dev\n"), 0644))
+	assert.False(t, moduleConfiguredAtBoot(dir, "i2c-dev"), "non .conf files are ignored")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "i2c.conf"), []byte("i2c_dev\n"), 0644))
+	assert.True(t, moduleConfiguredAtBoot(dir, "i2c-dev"))
+}
+
+func TestReadGroupNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "group")
+	require.NoError(t, os.WriteFile(path, []byte("root:x:0:\nvideo:x:44:alice\ninput:x:97:\n"), 0644))
+
+	groups, err := readGroupNames(path)
+	require.NoError(t, err)
+	assert.True(t, groups["video"])
+	assert.True(t, groups["input"])
+	assert.False(t, groups["i2c"])
+}
+
+func TestMissingGroups(t *testing.T) {
+	existing := map[string]bool{"video": true, "input": true}
+	memberOf := map[string]bool{"video": true}
+
+	assert.Equal(t, []string{"input"}, missingGroups([]string{"video", "input", "i2c"}, existing, memberOf))
+	assert.Empty(t, missingGroups([]string{"video"}, existing, memberOf))
+}
+
+func TestSystemPrereqReport_NeedsRelogin(t *testing.T) {
+	assert.False(t, SystemPrereqReport{LoadedModules: []string{"i2c-dev"}}.NeedsRelogin())
+	assert.True(t, SystemPrereqReport{AddedGroups: []string{"i2c"}}.NeedsRelogin())
+}
+
+func TestModulesLoadContent(t *testing.T) {
+	assert.Equal(t, "i2c-dev\n", modulesLoadContent("", []string{"i2c-dev"}))
+	assert.Equal(t, "# dms\nuinput\ni2c-dev\n", modulesLoadContent("# dms\nuinput\n", []string{"i2c-dev"}))
+	assert.Equal(t, "i2c_dev\n", modulesLoadContent("i2c_dev\n", []string{"i2c-dev"}), "already listed modules are not repeated")
+}
@@ -343,6 +343,8 @@ func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies [
 		LogOutput:  "Starting post-installation configuration...",
 	}
 
+	u.ConfigureSystemPrerequisites(ctx, sudoPassword, progressChan)
+
 	// Phase 7: Complete
 	progressChan <- InstallProgressMsg{
 		Phase:      PhaseComplete,
@@ -643,8 +645,14 @@ func (u *UbuntuDistribution) installZig(ctx context.Context, sudoPassword string
 	zigUrl := "https://ziglang.org/download/0.11.0/zig-linux-x86_64-0.11.0.tar.xz"
 	zigTmp := filepath.Join(cacheDir, "zig.tar.xz")
 
-	downloadCmd := exec.CommandContext(ctx, "curl", "-L", zigUrl, "-o", zigTmp)
-	if err := u.runWithProgress(downloadCmd, progressChan, PhaseSystemPackages, 0.84, 0.85); err != nil {
+	progressChan <- InstallProgressMsg{
+		Phase:       PhaseSystemPackages,
+		Progress:    0.84,
+		Step:        "Downloading Zig...",
+		IsComplete:  false,
+		CommandInfo: "download " + zigUrl,
+	}
+	if err := u.downloadFile(ctx, zigUrl, zigTmp); err != nil {
 		return fmt.Errorf("failed to download Zig: %w", err)
 	}
 
@@ -414,15 +414,8 @@ user = "greeter"
 		}
 	}
 
-	// Determine wrapper command path
-	wrapperCmd := "dms-greeter"
-	if !commandExists("dms-greeter") {
-		wrapperCmd = "/usr/local/bin/dms-greeter"
-	}
-
-	// Build command based on compositor and dms path
-	compositorLower := strings.ToLower(compositor)
-	command := fmt.Sprintf(`command = "%s --command %s -p %s"`, wrapperCmd, compositorLower, dmsPath)
+	greeterCommand := GreetdCommand(dmsPath, compositor)
+	command := fmt.Sprintf(`command = "%s"`, greeterCommand)
 
 	var finalLines []string
 	inDefaultSession := false
@@ -448,18 +441,40 @@ user = "greeter"
 		finalLines = append(finalLines, command)
 	}
 
-	newConfig := strings.Join(finalLines, "\n")
+	if err := WriteGreetdConfig(configPath, strings.Join(finalLines, "\n"), sudoPassword); err != nil {
+		return err
+	}
 
-	tmpFile := "/tmp/greetd-config.toml"
-	if err := os.WriteFile(tmpFile, []byte(newConfig), 0644); err != nil {
-		return fmt.Errorf("failed to write temp config: %w", err)
+	logFunc(fmt.Sprintf("✓ Updated greetd configuration (user: greeter, command: %s)", greeterCommand))
+	return nil
+}
+
+// WriteGreetdConfig checks content with ValidateGreetdConfig and moves it
+// into place at configPath through sudo
+func WriteGreetdConfig(configPath, content, sudoPassword string) error {
+	if err := ValidateGreetdConfig(content); err != nil {
+		return fmt.Errorf("generated greetd config is invalid, leaving %s untouched: %w", configPath, err)
 	}
 
-	if err := runSudoCmd(sudoPassword, "mv", tmpFile, configPath); err != nil {
-		return fmt.Errorf("failed to move config to /etc/greetd: %w", err)
+	tmp, err := os.CreateTemp("", "greetd-config-*.toml")
+	if err != nil {
+		return fmt.Errorf("failed to write temp config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temp config: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write temp config: %w", err)
 	}
 
-	logFunc(fmt.Sprintf("✓ Updated greetd configuration (user: greeter, command: %s --command %s -p %s)", wrapperCmd, compositorLower, dmsPath))
+	if err := runSudoCmd(sudoPassword, "mv", tmp.Name(), configPath); err != nil {
+		return fmt.Errorf("failed to move config to %s: %w", filepath.Dir(configPath), err)
+	}
 	return nil
 }
 
@@ -0,0 +1,371 @@
+package greeter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/distros"
+)
+
+// GreetdPAMPath is the PAM service greetd authenticates the user with
+const GreetdPAMPath = "/etc/pam.d/greetd"
+
+// Keyring is a secret store that PAM can unlock with the login password,
+// so Wi-Fi secrets and apps don't ask for it again after login
+type Keyring struct {
+	Name string
+	// Module is the PAM module that unlocks it
+	Module string
+	// Daemons are the binaries whose presence means the keyring is in use
+	Daemons []string
+	// Packages that ship Module, by distro family
+	Packages map[distros.DistroFamily]string
+}
+
+var Keyrings = []Keyring{
+	{
+		Name:    "gnome-keyring",
+		Module:  "pam_gnome_keyring.so",
+		Daemons: []string{"gnome-keyring-daemon"},
+		Packages: map[distros.DistroFamily]string{
+			distros.FamilyArch:   "gnome-keyring",
+			distros.FamilyFedora: "gnome-keyring-pam",
+			distros.FamilySUSE:   "gnome-keyring-pam",
+			distros.FamilyUbuntu: "libpam-gnome-keyring",
+			distros.FamilyDebian: "libpam-gnome-keyring",
+			distros.FamilyGentoo: "gnome-base/gnome-keyring",
+		},
+	},
+	{
+		// kwallet-pam kept the module name for KWallet 6
+		Name:    "kwallet",
+		Module:  "pam_kwallet5.so",
+		Daemons: []string{"kwalletd6", "kwalletd5"},
+		Packages: map[distros.DistroFamily]string{
+			distros.FamilyArch:   "kwallet-pam",
+			distros.FamilyFedora: "pam-kwallet",
+			distros.FamilySUSE:   "pam_kwallet",
+			distros.FamilyUbuntu: "libpam-kwallet5",
+			distros.FamilyDebian: "libpam-kwallet5",
+			distros.FamilyGentoo: "kde-plasma/kwallet-pam",
+		},
+	},
+}
+
+// pamModuleDirs are where distros install PAM modules
+var pamModuleDirs = []string{
+	"/usr/lib/security",
+	"/usr/lib64/security",
+	"/lib/security",
+	"/lib64/security",
+	"/usr/lib/x86_64-linux-gnu/security",
+	"/lib/x86_64-linux-gnu/security",
+	"/usr/lib/aarch64-linux-gnu/security",
+	"/lib/aarch64-linux-gnu/security",
+}
+
+// AuthLine and SessionLine are the PAM entries that unlock the keyring. The
+// auth entry captures the password; the session entry starts the daemon
+// with it once the session opens.
+func (k Keyring) AuthLine() string {
+	return "auth       optional     " + k.Module
+}
+
+func (k Keyring) SessionLine() string {
+	return "session    optional     " + k.Module + " auto_start"
+}
+
+// Installed reports whether one of the keyring's daemons is on PATH
+func (k Keyring) Installed() bool {
+	for _, d := range k.Daemons {
+		if commandExists(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// ModuleInstalled reports whether the PAM module is present
+func (k Keyring) ModuleInstalled() bool {
+	for _, dir := range pamModuleDirs {
+		if _, err := os.Stat(filepath.Join(dir, k.Module)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// pamEntry splits a PAM line into its type and module, skipping comments.
+// Debian's @include lines count as the type they include.
+func pamEntry(line string) (kind, module string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+		return "", ""
+	}
+	if fields[0] == "@include" && len(fields) > 1 {
+		switch {
+		case strings.HasSuffix(fields[1], "-auth"):
+			return "auth", ""
+		case strings.HasSuffix(fields[1], "-session"):
+			return "session", ""
+		}
+		return "", ""
+	}
+	kind = strings.TrimPrefix(fields[0], "-")
+	if len(fields) > 2 {
+		// The control may be a [value=action ...] list with spaces
+		rest := fields[1:]
+		if strings.HasPrefix(rest[0], "[") {
+			for len(rest) > 0 && !strings.HasSuffix(rest[0], "]") {
+				rest = rest[1:]
+			}
+		}
+		if len(rest) > 1 {
+			module = filepath.Base(rest[1])
+		}
+	}
+	return kind, module
+}
+
+// PAMHasKeyring reports whether the PAM config unlocks the keyring, which
+// takes both its auth and session entries
+func PAMHasKeyring(pam string, k Keyring) bool {
+	var auth, session bool
+	for _, line := range strings.Split(pam, "\n") {
+		kind, module := pamEntry(line)
+		if module != k.Module {
+			continue
+		}
+		switch kind {
+		case "auth":
+			auth = true
+		case "session":
+			session = true
+		}
+	}
+	return auth && session
+}
+
+// AddKeyringToPAM returns the PAM config with the keyring's auth entry after
+// the last auth entry and its session entry after the last session entry,
+// so it sees the password the login stack accepted and starts once the
+// session is set up. Entries already there are left alone.
+func AddKeyringToPAM(pam string, k Keyring) string {
+	lines := strings.Split(strings.TrimRight(pam, "\n"), "\n")
+	if pam == "" {
+		lines = nil
+	}
+
+	var hasAuth, hasSession bool
+	lastAuth, lastSession := -1, -1
+	for i, line := range lines {
+		kind, module := pamEntry(line)
+		switch kind {
+		case "auth":
+			lastAuth = i
+			hasAuth = hasAuth || module == k.Module
+		case "session":
+			lastSession = i
+			hasSession = hasSession || module == k.Module
+		}
+	}
+
+	// Without entries of a type to follow, the keyring's goes at the end
+	if lastAuth < 0 {
+		lastAuth = len(lines) - 1
+	}
+	if lastSession < 0 {
+		lastSession = len(lines) - 1
+	}
+	insert := func(at int, line string) {
+		lines = append(lines[:at+1], append([]string{line}, lines[at+1:]...)...)
+	}
+	// The lower entry goes in first so it doesn't shift the other's place;
+	// on a tie the session entry does, leaving auth above it
+	if lastAuth > lastSession {
+		if !hasAuth {
+			insert(lastAuth, k.AuthLine())
+		}
+		if !hasSession {
+			insert(lastSession, k.SessionLine())
+		}
+	} else {
+		if !hasSession {
+			insert(lastSession, k.SessionLine())
+		}
+		if !hasAuth {
+			insert(lastAuth, k.AuthLine())
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// KeyringPackage is the package that ships the keyring's PAM module on
+// this distro, or "" when it isn't known
+func KeyringPackage(k Keyring) string {
+	osInfo, err := distros.GetOSInfo()
+	if err != nil {
+		return ""
+	}
+	config, ok := distros.Registry[osInfo.Distribution.ID]
+	if !ok {
+		return ""
+	}
+	return k.Packages[config.Family]
+}
+
+// ConfigureKeyringPAM adds the unlock entries of every keyring in use whose
+// PAM module is installed to greetd's PAM service
+func ConfigureKeyringPAM(logFunc func(string), sudoPassword string) error {
+	data, err := os.ReadFile(GreetdPAMPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", GreetdPAMPath, err)
+	}
+
+	pam := string(data)
+	updated := pam
+	for _, k := range Keyrings {
+		if !k.Installed() {
+			continue
+		}
+		if !k.ModuleInstalled() {
+			if pkg := KeyringPackage(k); pkg != "" {
+				logFunc(fmt.Sprintf("⚠ %s is installed but %s is not; install %s to unlock it at login", k.Name, k.Module, pkg))
+			} else {
+				logFunc(fmt.Sprintf("⚠ %s is installed but %s is not; install its PAM module to unlock it at login", k.Name, k.Module))
+			}
+			continue
+		}
+		if PAMHasKeyring(updated, k) {
+			logFunc(fmt.Sprintf("✓ %s is already unlocked at login", k.Name))
+			continue
+		}
+		updated = AddKeyringToPAM(updated, k)
+		logFunc(fmt.Sprintf("✓ %s will be unlocked at login", k.Name))
+	}
+	if updated == pam {
+		return nil
+	}
+
+	backupPath := GreetdPAMPath + ".backup"
+	if err := runSudoCmd(sudoPassword, "cp", GreetdPAMPath, backupPath); err != nil {
+		return fmt.Errorf("failed to backup PAM config: %w", err)
+	}
+	logFunc(fmt.Sprintf("✓ Backed up existing PAM config to %s", backupPath))
+
+	// A fresh 0600 file, so no other user can plant or swap the PAM stack
+	// between writing it and installing it
+	tmp, err := os.CreateTemp("", "greetd-pam-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp PAM config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(updated); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp PAM config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temp PAM config: %w", err)
+	}
+	if err := runSudoCmd(sudoPassword, "install", "-m", "0644", tmp.Name(), GreetdPAMPath); err != nil {
+		return fmt.Errorf("failed to write %s: %w", GreetdPAMPath, err)
+	}
+	return nil
+}
+
+// kwalletInitPaths are where kwallet-pam installs the helper that hands the
+// unlocked wallet to kwalletd
+var kwalletInitPaths = []string{
+	"/usr/lib/pam_kwallet_init",
+	"/usr/libexec/pam_kwallet_init",
+	"/usr/lib/x86_64-linux-gnu/libexec/pam_kwallet_init",
+	"/usr/lib/aarch64-linux-gnu/libexec/pam_kwallet_init",
+}
+
+// StartSessionKeyring connects the session to a keyring PAM unlocked at
+// login: gnome-keyring-daemon is asked to export its secrets component and
+// the variables it prints are set in this process, so the daemon and the
+// shell it starts inherit them; KWallet gets its pam_kwallet_init helper
+// run. Keyrings PAM did not start are left alone, since starting one here
+// would only bring up a locked keyring that prompts. It returns the names
+// of the keyrings it connected.
+func StartSessionKeyring() ([]string, error) {
+	var started []string
+	var errs []string
+
+	if gnomeKeyringUnlockedByPAM() {
+		out, err := exec.Command("gnome-keyring-daemon", "--start", "--components=secrets").Output()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("gnome-keyring: %v", err))
+		} else {
+			env := parseKeyringEnv(out)
+			names := make([]string, 0, len(env))
+			for _, kv := range env {
+				os.Setenv(kv[0], kv[1])
+				names = append(names, kv[0]+"="+kv[1])
+			}
+			// Apps activated over D-Bus or by systemd need them too
+			if len(names) > 0 && commandExists("dbus-update-activation-environment") {
+				exec.Command("dbus-update-activation-environment", append([]string{"--systemd"}, names...)...).Run()
+			}
+			started = append(started, "gnome-keyring")
+		}
+	}
+
+	if os.Getenv("PAM_KWALLET5_LOGIN") != "" || os.Getenv("PAM_KWALLET_LOGIN") != "" {
+		for _, path := range kwalletInitPaths {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if err := exec.Command(path).Run(); err != nil {
+				errs = append(errs, fmt.Sprintf("kwallet: %v", err))
+			} else {
+				started = append(started, "kwallet")
+			}
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return started, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return started, nil
+}
+
+// gnomeKeyringUnlockedByPAM reports whether pam_gnome_keyring started the
+// daemon for this login, which leaves its control socket behind
+func gnomeKeyringUnlockedByPAM() bool {
+	if !commandExists("gnome-keyring-daemon") {
+		return false
+	}
+	control := os.Getenv("GNOME_KEYRING_CONTROL")
+	if control == "" {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			return false
+		}
+		control = filepath.Join(runtimeDir, "keyring")
+	}
+	_, err := os.Stat(filepath.Join(control, "control"))
+	return err == nil
+}
+
+// parseKeyringEnv reads the NAME=value lines gnome-keyring-daemon --start
+// prints
+func parseKeyringEnv(out []byte) [][2]string {
+	var env [][2]string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		env = append(env, [2]string{name, value})
+	}
+	return env
+}
@@ -0,0 +1,78 @@
+package greeter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddKeyringToPAM(t *testing.T) {
+	gnome := Keyrings[0]
+
+	arch := `#%PAM-1.0
+
+auth       required     pam_securetty.so
+auth       requisite    pam_nologin.so
+auth       include      system-local-login
+account    include      system-local-login
+session    include      system-local-login
+password   include      system-local-login
+`
+	assert.Equal(t, `#%PAM-1.0
+
+auth       required     pam_securetty.so
+auth       requisite    pam_nologin.so
+auth       include      system-local-login
+auth       optional     pam_gnome_keyring.so
+account    include      system-local-login
+session    include      system-local-login
+session    optional     pam_gnome_keyring.so auto_start
+password   include      system-local-login
+`, AddKeyringToPAM(arch, gnome))
+
+	debian := `auth    requisite       pam_nologin.so
+@include common-auth
+@include common-account
+@include common-session
+@include common-password
+`
+	assert.Equal(t, `auth    requisite       pam_nologin.so
+@include common-auth
+auth       optional     pam_gnome_keyring.so
+@include common-account
+@include common-session
+session    optional     pam_gnome_keyring.so auto_start
+@include common-password
+`, AddKeyringToPAM(debian, gnome))
+
+	// Session entries above the auth ones still each get theirs
+	reversed := "session include login\nauth include login\n"
+	assert.Equal(t, "session include login\nsession    optional     pam_gnome_keyring.so auto_start\nauth include login\nauth       optional     pam_gnome_keyring.so\n",
+		AddKeyringToPAM(reversed, gnome))
+
+	assert.Equal(t, "auth       optional     pam_gnome_keyring.so\nsession    optional     pam_gnome_keyring.so auto_start\n",
+		AddKeyringToPAM("", gnome))
+}
+
+func TestAddKeyringToPAMKeepsExistingEntries(t *testing.T) {
+	kwallet := Keyrings[1]
+
+	configured := "auth include login\n-auth optional /usr/lib/security/pam_kwallet5.so\nsession include login\nsession [success=ok default=ignore] pam_kwallet5.so auto_start\n"
+	assert.True(t, PAMHasKeyring(configured, kwallet))
+	assert.Equal(t, configured, AddKeyringToPAM(configured, kwallet))
+
+	// A commented entry doesn't count
+	half := "auth include login\nauth optional pam_kwallet5.so\nsession include login\n#session optional pam_kwallet5.so auto_start\n"
+	assert.False(t, PAMHasKeyring(half, kwallet))
+	assert.Equal(t, "auth include login\nauth optional pam_kwallet5.so\nsession include login\nsession    optional     pam_kwallet5.so auto_start\n#session optional pam_kwallet5.so auto_start\n",
+		AddKeyringToPAM(half, kwallet))
+	assert.False(t, PAMHasKeyring(half, Keyrings[0]))
+}
+
+func TestParseKeyringEnv(t *testing.T) {
+	out := []byte("GNOME_KEYRING_CONTROL=/run/user/1000/keyring\nSSH_AUTH_SOCK=/run/user/1000/keyring/ssh\n\nwarning: something\n")
+	assert.Equal(t, [][2]string{
+		{"GNOME_KEYRING_CONTROL", "/run/user/1000/keyring"},
+		{"SSH_AUTH_SOCK", "/run/user/1000/keyring/ssh"},
+	}, parseKeyringEnv(out))
+}
@@ -0,0 +1,186 @@
+package greeter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultTestRunTimeout is how long the greeter must stay alive in the nested
+// session before it is considered to have started successfully
+const DefaultTestRunTimeout = 8 * time.Second
+
+// GreeterCommand returns the wrapper command line greetd will run for the
+// given compositor; an empty dmsPath leaves the shell to the wrapper's default
+func GreeterCommand(dmsPath, compositor string) []string {
+	wrapperCmd := "dms-greeter"
+	if !commandExists("dms-greeter") {
+		wrapperCmd = "/usr/local/bin/dms-greeter"
+	}
+
+	args := []string{wrapperCmd, "--command", strings.ToLower(compositor)}
+	if dmsPath != "" {
+		args = append(args, "-p", dmsPath)
+	}
+	return args
+}
+
+// GreetdCommand is GreeterCommand as the command value in greetd's config
+func GreetdCommand(dmsPath, compositor string) string {
+	return strings.Join(GreeterCommand(dmsPath, compositor), " ")
+}
+
+// CanTestRun reports whether there is a Wayland session for TestRunGreeter
+// to nest the greeter in, which there is not when installing from a TTY
+func CanTestRun() bool {
+	return os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// ValidateGreetdConfig performs static checks on a greetd config before it is written
+func ValidateGreetdConfig(content string) error {
+	inDefaultSession := false
+	var command, user string
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			inDefaultSession = trimmed == "[default_session]"
+			continue
+		}
+
+		if !inDefaultSession {
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			return fmt.Errorf("malformed line in [default_session]: %q", trimmed)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch strings.TrimSpace(key) {
+		case "command":
+			if command != "" {
+				return fmt.Errorf("duplicate command in [default_session]")
+			}
+			command = value
+		case "user":
+			user = value
+		}
+	}
+
+	switch {
+	case command == "":
+		return fmt.Errorf("no command set in [default_session]")
+	case user == "":
+		return fmt.Errorf("no user set in [default_session]")
+	}
+
+	binary := strings.Fields(command)[0]
+	if _, err := exec.LookPath(binary); err != nil {
+		return fmt.Errorf("greeter command %s not found: %w", binary, err)
+	}
+
+	return nil
+}
+
+// CheckGreeter runs the checks that need no Wayland session: the wrapper and
+// compositor binaries, the DMS shell and the greeter user. Passing checks are
+// logged, checks that cannot run are logged as skipped and failures are
+// returned together.
+func CheckGreeter(dmsPath, compositor string, logFunc func(string)) error {
+	var errs []error
+
+	wrapper := GreeterCommand(dmsPath, compositor)[0]
+	if _, err := exec.LookPath(wrapper); err != nil {
+		errs = append(errs, fmt.Errorf("greeter wrapper %s not found: %w", wrapper, err))
+	} else {
+		logFunc(fmt.Sprintf("✓ Greeter wrapper: %s", wrapper))
+	}
+
+	if _, err := exec.LookPath(compositor); err != nil {
+		errs = append(errs, fmt.Errorf("compositor %s not found: %w", compositor, err))
+	} else {
+		logFunc(fmt.Sprintf("✓ Compositor: %s", compositor))
+	}
+
+	if dmsPath == "" {
+		logFunc("- Skipped DMS shell check: the wrapper picks its default path at login")
+	} else if _, err := os.Stat(filepath.Join(dmsPath, "shell.qml")); err != nil {
+		errs = append(errs, fmt.Errorf("DMS shell not found in %s: %w", dmsPath, err))
+	} else {
+		logFunc(fmt.Sprintf("✓ DMS shell: %s", dmsPath))
+	}
+
+	if _, err := user.Lookup("greeter"); err != nil {
+		errs = append(errs, fmt.Errorf("greeter user missing: %w", err))
+	} else {
+		logFunc("✓ Greeter user exists")
+	}
+
+	return errors.Join(errs...)
+}
+
+// TestRunGreeter launches the greeter nested inside the current Wayland session and
+// verifies it stays up for the given duration. A greeter that exits early (crash,
+// missing QML, compositor failure) would leave greetd unable to show a login screen.
+func TestRunGreeter(command []string, timeout time.Duration, logFunc func(string)) error {
+	if len(command) == 0 {
+		return fmt.Errorf("empty greeter command")
+	}
+
+	if !CanTestRun() {
+		return fmt.Errorf("greeter test-run requires a running Wayland session (WAYLAND_DISPLAY not set)")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = 2 * time.Second
+
+	logFunc(fmt.Sprintf("Test-running greeter in a nested session for %s...", timeout))
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start greeter: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return fmt.Errorf("greeter exited during test-run (%v): %s", err, lastLines(output.String(), 10))
+	case <-time.After(timeout):
+		cancel()
+		<-done
+		logFunc("✓ Greeter started successfully in nested session")
+		return nil
+	}
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
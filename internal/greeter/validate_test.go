@@ -0,0 +1,132 @@
+package greeter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateGreetdConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "valid config",
+			config: `[terminal]
+vt = 1
+
+[default_session]
+user = "greeter"
+command = "sh --command niri"
+`,
+		},
+		{
+			name: "missing command",
+			config: `[default_session]
+user = "greeter"
+`,
+			wantErr: "no command",
+		},
+		{
+			name: "missing user",
+			config: `[default_session]
+command = "sh"
+`,
+			wantErr: "no user",
+		},
+		{
+			name: "duplicate command",
+			config: `[default_session]
+user = "greeter"
+command = "sh"
+command = "sh"
+`,
+			wantErr: "duplicate command",
+		},
+		{
+			name: "command outside default_session is ignored",
+			config: `[initial_session]
+command = "sh"
+user = "me"
+`,
+			wantErr: "no command",
+		},
+		{
+			name: "command binary not found",
+			config: `[default_session]
+user = "greeter"
+command = "/nonexistent/dms-greeter --command niri"
+`,
+			wantErr: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGreetdConfig(tt.config)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestTestRunGreeter(t *testing.T) {
+	logFunc := func(string) {}
+
+	t.Run("requires wayland session", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "")
+		err := TestRunGreeter([]string{"sleep", "5"}, 100*time.Millisecond, logFunc)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WAYLAND_DISPLAY")
+	})
+
+	t.Run("greeter that stays up passes", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "wayland-test")
+		err := TestRunGreeter([]string{"sleep", "5"}, 100*time.Millisecond, logFunc)
+		assert.NoError(t, err)
+	})
+
+	t.Run("greeter that exits early fails", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "wayland-test")
+		err := TestRunGreeter([]string{"sh", "-c", "echo boom >&2; exit 1"}, 2*time.Second, logFunc)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "boom")
+	})
+}
+
+func TestGreetdCommand(t *testing.T) {
+	assert.True(t, strings.HasSuffix(GreetdCommand("/usr/share/quickshell/dms", "Niri"), "dms-greeter --command niri -p /usr/share/quickshell/dms"))
+	assert.True(t, strings.HasSuffix(GreetdCommand("", "Hyprland"), "dms-greeter --command hyprland"))
+}
+
+func TestCheckGreeter(t *testing.T) {
+	var logged []string
+	logFunc := func(msg string) { logged = append(logged, msg) }
+
+	t.Run("reports missing pieces", func(t *testing.T) {
+		logged = nil
+		err := CheckGreeter(t.TempDir(), "no-such-compositor", logFunc)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "compositor no-such-compositor not found")
+		assert.Contains(t, err.Error(), "DMS shell not found")
+	})
+
+	t.Run("skips shell check without a dms path", func(t *testing.T) {
+		logged = nil
+		err := CheckGreeter("", "sh", logFunc)
+		if err != nil {
+			assert.NotContains(t, err.Error(), "compositor")
+			assert.NotContains(t, err.Error(), "DMS shell")
+		}
+		assert.Contains(t, strings.Join(logged, "\n"), "Skipped DMS shell check")
+	})
+}
@@ -116,6 +116,122 @@ func (_c *MockCUPSClientInterface_CancelJob_Call) RunAndReturn(run func(int, boo
 	return _c
 }
 
+// GetDevices provides a mock function with no fields
+func (_m *MockCUPSClientInterface) GetDevices() (map[string]ipp.Attributes, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDevices")
+	}
+
+	var r0 map[string]ipp.Attributes
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (map[string]ipp.Attributes, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() map[string]ipp.Attributes); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]ipp.Attributes)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCUPSClientInterface_GetDevices_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDevices'
+type MockCUPSClientInterface_GetDevices_Call struct {
+	*mock.Call
+}
+
+// GetDevices is a helper method to define mock.On call
+func (_e *MockCUPSClientInterface_Expecter) GetDevices() *MockCUPSClientInterface_GetDevices_Call {
+	return &MockCUPSClientInterface_GetDevices_Call{Call: _e.mock.On("GetDevices")}
+}
+
+func (_c *MockCUPSClientInterface_GetDevices_Call) Run(run func()) *MockCUPSClientInterface_GetDevices_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_GetDevices_Call) Return(_a0 map[string]ipp.Attributes, _a1 error) *MockCUPSClientInterface_GetDevices_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_GetDevices_Call) RunAndReturn(run func() (map[string]ipp.Attributes, error)) *MockCUPSClientInterface_GetDevices_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetJobAttributes provides a mock function with given fields: jobID, attributes
+func (_m *MockCUPSClientInterface) GetJobAttributes(jobID int, attributes []string) (ipp.Attributes, error) {
+	ret := _m.Called(jobID, attributes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobAttributes")
+	}
+
+	var r0 ipp.Attributes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, []string) (ipp.Attributes, error)); ok {
+		return rf(jobID, attributes)
+	}
+	if rf, ok := ret.Get(0).(func(int, []string) ipp.Attributes); ok {
+		r0 = rf(jobID, attributes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ipp.Attributes)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, []string) error); ok {
+		r1 = rf(jobID, attributes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCUPSClientInterface_GetJobAttributes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetJobAttributes'
+type MockCUPSClientInterface_GetJobAttributes_Call struct {
+	*mock.Call
+}
+
+// GetJobAttributes is a helper method to define mock.On call
+//   - jobID int
+//   - attributes []string
+func (_e *MockCUPSClientInterface_Expecter) GetJobAttributes(jobID interface{}, attributes interface{}) *MockCUPSClientInterface_GetJobAttributes_Call {
+	return &MockCUPSClientInterface_GetJobAttributes_Call{Call: _e.mock.On("GetJobAttributes", jobID, attributes)}
+}
+
+func (_c *MockCUPSClientInterface_GetJobAttributes_Call) Run(run func(jobID int, attributes []string)) *MockCUPSClientInterface_GetJobAttributes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_GetJobAttributes_Call) Return(_a0 ipp.Attributes, _a1 error) *MockCUPSClientInterface_GetJobAttributes_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_GetJobAttributes_Call) RunAndReturn(run func(int, []string) (ipp.Attributes, error)) *MockCUPSClientInterface_GetJobAttributes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetJobs provides a mock function with given fields: printer, class, whichJobs, myJobs, firstJobId, limit, attributes
 func (_m *MockCUPSClientInterface) GetJobs(printer string, class string, whichJobs string, myJobs bool, firstJobId int, limit int, attributes []string) (map[int]ipp.Attributes, error) {
 	ret := _m.Called(printer, class, whichJobs, myJobs, firstJobId, limit, attributes)
@@ -180,6 +296,65 @@ func (_c *MockCUPSClientInterface_GetJobs_Call) RunAndReturn(run func(string, st
 	return _c
 }
 
+// GetPrinterAttributes provides a mock function with given fields: printer, attributes
+func (_m *MockCUPSClientInterface) GetPrinterAttributes(printer string, attributes []string) (ipp.Attributes, error) {
+	ret := _m.Called(printer, attributes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPrinterAttributes")
+	}
+
+	var r0 ipp.Attributes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) (ipp.Attributes, error)); ok {
+		return rf(printer, attributes)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) ipp.Attributes); ok {
+		r0 = rf(printer, attributes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ipp.Attributes)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(printer, attributes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCUPSClientInterface_GetPrinterAttributes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPrinterAttributes'
+type MockCUPSClientInterface_GetPrinterAttributes_Call struct {
+	*mock.Call
+}
+
+// GetPrinterAttributes is a helper method to define mock.On call
+//   - printer string
+//   - attributes []string
+func (_e *MockCUPSClientInterface_Expecter) GetPrinterAttributes(printer interface{}, attributes interface{}) *MockCUPSClientInterface_GetPrinterAttributes_Call {
+	return &MockCUPSClientInterface_GetPrinterAttributes_Call{Call: _e.mock.On("GetPrinterAttributes", printer, attributes)}
+}
+
+func (_c *MockCUPSClientInterface_GetPrinterAttributes_Call) Run(run func(printer string, attributes []string)) *MockCUPSClientInterface_GetPrinterAttributes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_GetPrinterAttributes_Call) Return(_a0 ipp.Attributes, _a1 error) *MockCUPSClientInterface_GetPrinterAttributes_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_GetPrinterAttributes_Call) RunAndReturn(run func(string, []string) (ipp.Attributes, error)) *MockCUPSClientInterface_GetPrinterAttributes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetPrinters provides a mock function with given fields: attributes
 func (_m *MockCUPSClientInterface) GetPrinters(attributes []string) (map[string]ipp.Attributes, error) {
 	ret := _m.Called(attributes)
@@ -284,6 +459,110 @@ func (_c *MockCUPSClientInterface_PausePrinter_Call) RunAndReturn(run func(strin
 	return _c
 }
 
+// PrintJob provides a mock function with given fields: doc, printer, jobAttributes
+func (_m *MockCUPSClientInterface) PrintJob(doc ipp.Document, printer string, jobAttributes map[string]interface{}) (int, error) {
+	ret := _m.Called(doc, printer, jobAttributes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PrintJob")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(ipp.Document, string, map[string]interface{}) (int, error)); ok {
+		return rf(doc, printer, jobAttributes)
+	}
+	if rf, ok := ret.Get(0).(func(ipp.Document, string, map[string]interface{}) int); ok {
+		r0 = rf(doc, printer, jobAttributes)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(ipp.Document, string, map[string]interface{}) error); ok {
+		r1 = rf(doc, printer, jobAttributes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockCUPSClientInterface_PrintJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PrintJob'
+type MockCUPSClientInterface_PrintJob_Call struct {
+	*mock.Call
+}
+
+// PrintJob is a helper method to define mock.On call
+//   - doc ipp.Document
+//   - printer string
+//   - jobAttributes map[string]interface{}
+func (_e *MockCUPSClientInterface_Expecter) PrintJob(doc interface{}, printer interface{}, jobAttributes interface{}) *MockCUPSClientInterface_PrintJob_Call {
+	return &MockCUPSClientInterface_PrintJob_Call{Call: _e.mock.On("PrintJob", doc, printer, jobAttributes)}
+}
+
+func (_c *MockCUPSClientInterface_PrintJob_Call) Run(run func(doc ipp.Document, printer string, jobAttributes map[string]interface{})) *MockCUPSClientInterface_PrintJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(ipp.Document), args[1].(string), args[2].(map[string]interface{}))
+	})
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_PrintJob_Call) Return(_a0 int, _a1 error) *MockCUPSClientInterface_PrintJob_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_PrintJob_Call) RunAndReturn(run func(ipp.Document, string, map[string]interface{}) (int, error)) *MockCUPSClientInterface_PrintJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestartJob provides a mock function with given fields: jobID
+func (_m *MockCUPSClientInterface) RestartJob(jobID int) error {
+	ret := _m.Called(jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestartJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(jobID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockCUPSClientInterface_RestartJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestartJob'
+type MockCUPSClientInterface_RestartJob_Call struct {
+	*mock.Call
+}
+
+// RestartJob is a helper method to define mock.On call
+//   - jobID int
+func (_e *MockCUPSClientInterface_Expecter) RestartJob(jobID interface{}) *MockCUPSClientInterface_RestartJob_Call {
+	return &MockCUPSClientInterface_RestartJob_Call{Call: _e.mock.On("RestartJob", jobID)}
+}
+
+func (_c *MockCUPSClientInterface_RestartJob_Call) Run(run func(jobID int)) *MockCUPSClientInterface_RestartJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_RestartJob_Call) Return(_a0 error) *MockCUPSClientInterface_RestartJob_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCUPSClientInterface_RestartJob_Call) RunAndReturn(run func(int) error) *MockCUPSClientInterface_RestartJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ResumePrinter provides a mock function with given fields: printer
 func (_m *MockCUPSClientInterface) ResumePrinter(printer string) error {
 	ret := _m.Called(printer)
@@ -772,6 +772,64 @@ func (_c *MockBackend_GetWiFiNetworkDetails_Call) RunAndReturn(run func(string)
 	return _c
 }
 
+// GetWiFiRoaming provides a mock function with given fields: ssid
+func (_m *MockBackend) GetWiFiRoaming(ssid string) (*network.WiFiRoaming, error) {
+	ret := _m.Called(ssid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWiFiRoaming")
+	}
+
+	var r0 *network.WiFiRoaming
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*network.WiFiRoaming, error)); ok {
+		return rf(ssid)
+	}
+	if rf, ok := ret.Get(0).(func(string) *network.WiFiRoaming); ok {
+		r0 = rf(ssid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.WiFiRoaming)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ssid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetWiFiRoaming_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWiFiRoaming'
+type MockBackend_GetWiFiRoaming_Call struct {
+	*mock.Call
+}
+
+// GetWiFiRoaming is a helper method to define mock.On call
+//   - ssid string
+func (_e *MockBackend_Expecter) GetWiFiRoaming(ssid interface{}) *MockBackend_GetWiFiRoaming_Call {
+	return &MockBackend_GetWiFiRoaming_Call{Call: _e.mock.On("GetWiFiRoaming", ssid)}
+}
+
+func (_c *MockBackend_GetWiFiRoaming_Call) Run(run func(ssid string)) *MockBackend_GetWiFiRoaming_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetWiFiRoaming_Call) Return(_a0 *network.WiFiRoaming, _a1 error) *MockBackend_GetWiFiRoaming_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetWiFiRoaming_Call) RunAndReturn(run func(string) (*network.WiFiRoaming, error)) *MockBackend_GetWiFiRoaming_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetWiredConnections provides a mock function with no fields
 func (_m *MockBackend) GetWiredConnections() ([]network.WiredConnection, error) {
 	ret := _m.Called()
@@ -1230,6 +1288,52 @@ func (_c *MockBackend_SetWiFiEnabled_Call) RunAndReturn(run func(bool) error) *M
 	return _c
 }
 
+// SetWiFiRoaming provides a mock function with given fields: prefs
+func (_m *MockBackend) SetWiFiRoaming(prefs network.WiFiRoaming) error {
+	ret := _m.Called(prefs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetWiFiRoaming")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(network.WiFiRoaming) error); ok {
+		r0 = rf(prefs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetWiFiRoaming_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetWiFiRoaming'
+type MockBackend_SetWiFiRoaming_Call struct {
+	*mock.Call
+}
+
+// SetWiFiRoaming is a helper method to define mock.On call
+//   - prefs network.WiFiRoaming
+func (_e *MockBackend_Expecter) SetWiFiRoaming(prefs interface{}) *MockBackend_SetWiFiRoaming_Call {
+	return &MockBackend_SetWiFiRoaming_Call{Call: _e.mock.On("SetWiFiRoaming", prefs)}
+}
+
+func (_c *MockBackend_SetWiFiRoaming_Call) Run(run func(prefs network.WiFiRoaming)) *MockBackend_SetWiFiRoaming_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(network.WiFiRoaming))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetWiFiRoaming_Call) Return(_a0 error) *MockBackend_SetWiFiRoaming_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetWiFiRoaming_Call) RunAndReturn(run func(network.WiFiRoaming) error) *MockBackend_SetWiFiRoaming_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StartMonitoring provides a mock function with given fields: onStateChange
 func (_m *MockBackend) StartMonitoring(onStateChange func()) error {
 	ret := _m.Called(onStateChange)
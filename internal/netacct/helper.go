@@ -0,0 +1,274 @@
+package netacct
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// unitState accumulates one unit's traffic. Counters of rules that were
+// deleted, because the unit went away or its cgroup was recreated, are
+// folded into the base so totals never go backwards.
+type unitState struct {
+	unit   Unit
+	rules  []nftRule
+	baseRx uint64
+	baseTx uint64
+	liveRx uint64
+	liveTx uint64
+	lastRx uint64
+	lastTx uint64
+	rxRate float64
+	txRate float64
+	lastAt time.Time
+	goneAt time.Time
+}
+
+func (s *unitState) rx() uint64 { return s.baseRx + s.liveRx }
+func (s *unitState) tx() uint64 { return s.baseTx + s.liveTx }
+
+// retire folds the live counters into the base once the rules are deleted
+func (s *unitState) retire() {
+	s.baseRx += s.liveRx
+	s.baseTx += s.liveTx
+	s.liveRx, s.liveTx = 0, 0
+	s.rules = nil
+}
+
+// Accountant is the root side of per-application accounting
+type Accountant struct {
+	cgroupRoot string
+	dir        string
+	nft        func(script string) error
+	list       func() ([]byte, error)
+	chown      func(path string, uid, gid int) error
+
+	since time.Time
+	users map[int]bool
+	units map[string]*unitState
+}
+
+func NewAccountant() *Accountant {
+	return newAccountant(CgroupRoot, RuntimeDir, runNft, listTable, os.Chown)
+}
+
+func newAccountant(cgroupRoot, dir string, nft func(string) error, list func() ([]byte, error), chown func(string, int, int) error) *Accountant {
+	return &Accountant{
+		cgroupRoot: cgroupRoot,
+		dir:        dir,
+		nft:        nft,
+		list:       list,
+		chown:      chown,
+		users:      make(map[int]bool),
+		units:      make(map[string]*unitState),
+	}
+}
+
+// Run installs the table and updates the snapshots every Interval until
+// stop is closed, then removes the table again
+func (a *Accountant) Run(stop <-chan struct{}) error {
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return err
+	}
+	if err := a.nft(tableScript()); err != nil {
+		return fmt.Errorf("failed to create nftables table: %w", err)
+	}
+	defer func() {
+		if err := a.nft(deleteTableScript()); err != nil {
+			log.Warnf("Failed to remove nftables table: %v", err)
+		}
+	}()
+
+	a.since = time.Now()
+	if err := a.step(a.since); err != nil {
+		log.Warnf("Accounting update failed: %v", err)
+	}
+
+	ticker := time.NewTicker(Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case now := <-ticker.C:
+			if err := a.step(now); err != nil {
+				log.Warnf("Accounting update failed: %v", err)
+			}
+		}
+	}
+}
+
+// step reads the counters, brings the rules in line with the units that
+// exist now and writes every user's snapshot
+func (a *Accountant) step(now time.Time) error {
+	if err := a.readCounters(now); err != nil {
+		return err
+	}
+
+	units, err := discoverUnits(a.cgroupRoot)
+	if err != nil {
+		return err
+	}
+	if err := a.sync(units, now); err != nil {
+		// A unit that vanished between discovery and nft fails the whole
+		// transaction; the next step discovers again without it
+		log.Debugf("Rule update failed: %v", err)
+	}
+
+	for uid := range a.users {
+		if err := writeSnapshot(a.dir, uid, a.snapshot(uid, now), a.chown); err != nil {
+			log.Warnf("Failed to write snapshot for uid %d: %v", uid, err)
+		}
+	}
+	return nil
+}
+
+func (a *Accountant) readCounters(now time.Time) error {
+	data, err := a.list()
+	if err != nil {
+		return err
+	}
+	rules, err := parseRules(data)
+	if err != nil {
+		return err
+	}
+
+	byComment := make(map[string][]nftRule)
+	for _, r := range rules {
+		byComment[r.Comment] = append(byComment[r.Comment], r)
+	}
+
+	for comment, state := range a.units {
+		if state.goneAt.IsZero() {
+			state.rules = byComment[comment]
+			state.liveRx, state.liveTx = 0, 0
+			for _, r := range state.rules {
+				if r.inbound() {
+					state.liveRx += r.Bytes
+				} else {
+					state.liveTx += r.Bytes
+				}
+			}
+		}
+
+		if !state.lastAt.IsZero() {
+			if elapsed := now.Sub(state.lastAt).Seconds(); elapsed > 0 {
+				state.rxRate = float64(state.rx()-state.lastRx) / elapsed
+				state.txRate = float64(state.tx()-state.lastTx) / elapsed
+			}
+		}
+		state.lastRx, state.lastTx = state.rx(), state.tx()
+		state.lastAt = now
+	}
+	return nil
+}
+
+// sync adds rules for new units and users, and deletes the rules of units
+// that went away or whose cgroup was recreated. State only changes once
+// nft accepted the whole script.
+func (a *Accountant) sync(units []Unit, now time.Time) error {
+	var script string
+	newUsers := make(map[int]bool)
+	current := make(map[string]Unit, len(units))
+
+	for _, u := range units {
+		current[ruleComment(u.Path)] = u
+		if !a.users[u.UID] && !newUsers[u.UID] {
+			newUsers[u.UID] = true
+			script += userScript(u.UID)
+		}
+	}
+
+	var added []Unit
+	var retired []string
+	for comment, u := range current {
+		state, known := a.units[comment]
+		switch {
+		case !known:
+			added = append(added, u)
+		case !state.goneAt.IsZero():
+			// A unit that came back, e.g. a restarted service
+			added = append(added, u)
+		case state.unit.Inode != u.Inode:
+			for _, r := range state.rules {
+				script += deleteRuleScript(r)
+			}
+			retired = append(retired, comment)
+			added = append(added, u)
+		}
+	}
+	var gone []string
+	for comment, state := range a.units {
+		if _, ok := current[comment]; ok || !state.goneAt.IsZero() {
+			continue
+		}
+		for _, r := range state.rules {
+			script += deleteRuleScript(r)
+		}
+		gone = append(gone, comment)
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Path < added[j].Path })
+	for _, u := range added {
+		script += unitScript(u)
+	}
+
+	if script != "" {
+		if err := a.nft(script); err != nil {
+			return err
+		}
+	}
+
+	for uid := range newUsers {
+		a.users[uid] = true
+	}
+	for _, comment := range retired {
+		a.units[comment].retire()
+	}
+	for _, comment := range gone {
+		a.units[comment].retire()
+		a.units[comment].goneAt = now
+	}
+	for _, u := range added {
+		comment := ruleComment(u.Path)
+		state, ok := a.units[comment]
+		if !ok {
+			// New rules start at zero, so rates are known from the next read
+			state = &unitState{lastAt: now}
+			a.units[comment] = state
+		}
+		state.unit = u
+		state.goneAt = time.Time{}
+	}
+	for comment, state := range a.units {
+		if !state.goneAt.IsZero() && now.Sub(state.goneAt) > Retention {
+			delete(a.units, comment)
+		}
+	}
+	return nil
+}
+
+func (a *Accountant) snapshot(uid int, now time.Time) Snapshot {
+	snap := Snapshot{Since: a.since, UpdatedAt: now, Units: []UnitUsage{}}
+	for _, state := range a.units {
+		if state.unit.UID != uid {
+			continue
+		}
+		usage := UnitUsage{
+			Unit:    state.unit.Name(),
+			Path:    state.unit.Path,
+			Active:  state.goneAt.IsZero(),
+			RxBytes: state.rx(),
+			TxBytes: state.tx(),
+		}
+		if usage.Active {
+			usage.RxRate = state.rxRate
+			usage.TxRate = state.txRate
+		}
+		snap.Units = append(snap.Units, usage)
+	}
+	sort.Slice(snap.Units, func(i, j int) bool { return snap.Units[i].Path < snap.Units[j].Path })
+	return snap
+}
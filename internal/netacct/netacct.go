@@ -0,0 +1,103 @@
+// Package netacct counts network traffic per systemd unit. A root helper
+// (`dms netacct run`, normally started by dms-netacct.service) keeps one
+// nftables rule per unit under user.slice, matched with `socket cgroupv2`,
+// and writes the counters for each user to /run/dms-netacct/<uid>.json.
+// The daemon runs as the user and cannot read nftables counters, so it
+// serves network.appUsage from that file.
+package netacct
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// TableName is the nftables table the helper owns, in the inet family
+	TableName = "dms_appusage"
+	// RuntimeDir holds one snapshot per user
+	RuntimeDir = "/run/dms-netacct"
+	// CgroupRoot is where cgroup v2 is mounted
+	CgroupRoot = "/sys/fs/cgroup"
+
+	Interval = 2 * time.Second
+	// Retention is how long a unit that went away stays in the snapshot
+	Retention = 10 * time.Minute
+	// StaleAfter is how old a snapshot may get before readers assume the
+	// helper stopped
+	StaleAfter = 5 * Interval
+)
+
+// Snapshot is the traffic of one user's units since the helper started
+type Snapshot struct {
+	Since     time.Time   `json:"since"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+	Units     []UnitUsage `json:"units"`
+}
+
+// UnitUsage is the traffic of one unit. Active is false once the unit has
+// gone away; it is kept for Retention so short-lived scopes still show up.
+type UnitUsage struct {
+	Unit    string  `json:"unit"`
+	Path    string  `json:"path"`
+	Active  bool    `json:"active"`
+	RxBytes uint64  `json:"rxBytes"`
+	TxBytes uint64  `json:"txBytes"`
+	RxRate  float64 `json:"rxRate"`
+	TxRate  float64 `json:"txRate"`
+}
+
+// SnapshotPath is where the helper writes the snapshot for uid
+func SnapshotPath(dir string, uid int) string {
+	return filepath.Join(dir, strconv.Itoa(uid)+".json")
+}
+
+// ReadSnapshot loads the snapshot for uid. It fails with an error wrapping
+// os.ErrNotExist when the helper is not running or has not written one.
+func ReadSnapshot(dir string, uid int, now time.Time) (Snapshot, error) {
+	var snap Snapshot
+	data, err := os.ReadFile(SnapshotPath(dir, uid))
+	if err != nil {
+		return snap, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, fmt.Errorf("failed to parse %s: %w", SnapshotPath(dir, uid), err)
+	}
+	if now.Sub(snap.UpdatedAt) > StaleAfter {
+		return snap, fmt.Errorf("snapshot last updated %s: %w", snap.UpdatedAt.Format(time.RFC3339), os.ErrNotExist)
+	}
+	return snap, nil
+}
+
+// writeSnapshot replaces the snapshot for uid atomically. The file is only
+// readable by that user.
+func writeSnapshot(dir string, uid int, snap Snapshot, chown func(path string, uid, gid int) error) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	if err := chown(tmp.Name(), uid, -1); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), SnapshotPath(dir, uid))
+}
@@ -0,0 +1,273 @@
+package netacct
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	firefoxPath = "user.slice/user-1000.slice/user@1000.service/app.slice/app-niri-firefox-42.scope"
+	sessionPath = "user.slice/user-1000.slice/session-2.scope"
+)
+
+func makeCgroups(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	}
+}
+
+func TestDiscoverUnits(t *testing.T) {
+	root := t.TempDir()
+	makeCgroups(t, root,
+		firefoxPath,
+		sessionPath,
+		"user.slice/user-1000.slice/user@1000.service/init.scope",
+		"user.slice/user-1000.slice/user@1000.service/session.slice/dms.service",
+		"user.slice/user-1001.slice/session-5.scope",
+		"system.slice/sshd.service",
+	)
+
+	units, err := discoverUnits(root)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, u := range units {
+		paths = append(paths, u.Path)
+		assert.NotZero(t, u.Inode, u.Path)
+	}
+	assert.Equal(t, []string{
+		sessionPath,
+		firefoxPath,
+		"user.slice/user-1000.slice/user@1000.service/init.scope",
+		"user.slice/user-1000.slice/user@1000.service/session.slice/dms.service",
+		"user.slice/user-1001.slice/session-5.scope",
+	}, paths)
+	assert.Equal(t, 1000, units[0].UID)
+	assert.Equal(t, 1001, units[4].UID)
+	assert.Equal(t, "app-niri-firefox-42.scope", units[1].Name())
+	assert.Equal(t, 5, units[1].level())
+}
+
+func TestUnitScript(t *testing.T) {
+	script := unitScript(Unit{UID: 1000, Path: firefoxPath})
+	comment := ruleComment(firefoxPath)
+	assert.Equal(t,
+		`add rule inet dms_appusage in_1000 socket cgroupv2 level 5 "`+firefoxPath+`" counter return comment "`+comment+`"`+"\n"+
+			`add rule inet dms_appusage out_1000 socket cgroupv2 level 5 "`+firefoxPath+`" counter return comment "`+comment+`"`+"\n",
+		script)
+	assert.LessOrEqual(t, len(comment), 128)
+	assert.Contains(t, userScript(1000), `add rule inet dms_appusage input socket cgroupv2 level 2 "user.slice/user-1000.slice" jump in_1000`)
+}
+
+func nftListing(rules ...nftRule) []byte {
+	var items []string
+	items = append(items, `{"metainfo":{"version":"1.0.9","json_schema_version":1}}`)
+	items = append(items, `{"table":{"family":"inet","name":"dms_appusage","handle":3}}`)
+	items = append(items, `{"rule":{"family":"inet","table":"dms_appusage","chain":"input","handle":4,"expr":[{"match":{"op":"==","left":{"socket":{"key":"cgroupv2","level":2}},"right":"user.slice/user-1000.slice"}},{"jump":{"target":"in_1000"}}]}}`)
+	for _, r := range rules {
+		items = append(items, fmt.Sprintf(`{"rule":{"family":"inet","table":"dms_appusage","chain":%q,"handle":%d,"comment":%q,"expr":[{"match":{"op":"==","left":{"socket":{"key":"cgroupv2","level":5}},"right":"x"}},{"counter":{"packets":%d,"bytes":%d}},{"return":null}]}}`,
+			r.Chain, r.Handle, r.Comment, r.Packets, r.Bytes))
+	}
+	return []byte(`{"nftables":[` + strings.Join(items, ",") + `]}`)
+}
+
+func TestParseRules(t *testing.T) {
+	want := []nftRule{
+		{Chain: "in_1000", Handle: 7, Comment: "uabc", Packets: 12, Bytes: 9000},
+		{Chain: "out_1000", Handle: 8, Comment: "uabc", Packets: 3, Bytes: 400},
+	}
+	rules, err := parseRules(nftListing(want...))
+	require.NoError(t, err)
+	assert.Equal(t, want, rules)
+	assert.True(t, rules[0].inbound())
+	assert.False(t, rules[1].inbound())
+
+	_, err = parseRules([]byte("not json"))
+	assert.Error(t, err)
+}
+
+// fakeNft applies the rule adds and deletes of a script to an in-memory
+// table, with counters the test sets per comment and chain
+type fakeNft struct {
+	rules   []nftRule
+	handle  int
+	bytes   map[string]uint64
+	scripts []string
+	fail    bool
+}
+
+func (f *fakeNft) run(script string) error {
+	f.scripts = append(f.scripts, script)
+	if f.fail {
+		return errors.New("no such file or directory")
+	}
+	for _, line := range strings.Split(strings.TrimSpace(script), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "add rule") && strings.Contains(line, " comment "):
+			f.handle++
+			comment := strings.Trim(fields[len(fields)-1], `"`)
+			f.rules = append(f.rules, nftRule{Chain: fields[4], Handle: f.handle, Comment: comment})
+		case strings.HasPrefix(line, "delete rule"):
+			var handle int
+			fmt.Sscanf(fields[len(fields)-1], "%d", &handle)
+			for i, r := range f.rules {
+				if r.Handle == handle {
+					f.rules = append(f.rules[:i], f.rules[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeNft) list() ([]byte, error) {
+	rules := make([]nftRule, len(f.rules))
+	for i, r := range f.rules {
+		r.Bytes = f.bytes[fmt.Sprintf("%s/%d", r.Chain, r.Handle)]
+		rules[i] = r
+	}
+	return nftListing(rules...), nil
+}
+
+// count adds traffic to the live rules of a unit
+func (f *fakeNft) count(unitPath string, rx, tx uint64) {
+	for _, r := range f.rules {
+		if r.Comment != ruleComment(unitPath) {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", r.Chain, r.Handle)
+		if r.inbound() {
+			f.bytes[key] += rx
+		} else {
+			f.bytes[key] += tx
+		}
+	}
+}
+
+func noChown(string, int, int) error { return nil }
+
+func TestAccountant(t *testing.T) {
+	root := t.TempDir()
+	dir := t.TempDir()
+	makeCgroups(t, root, firefoxPath, sessionPath)
+
+	nft := &fakeNft{bytes: make(map[string]uint64)}
+	a := newAccountant(root, dir, nft.run, nft.list, noChown)
+	start := time.Now()
+	a.since = start
+
+	require.NoError(t, a.step(start))
+	require.Len(t, nft.rules, 4)
+	assert.Contains(t, nft.scripts[0], "jump in_1000")
+
+	nft.count(firefoxPath, 8000, 600)
+	nft.count(sessionPath, 100, 0)
+	require.NoError(t, a.step(start.Add(2*time.Second)))
+
+	snap, err := ReadSnapshot(dir, 1000, start.Add(2*time.Second))
+	require.NoError(t, err)
+	require.Len(t, snap.Units, 2)
+	assert.Equal(t, start.Unix(), snap.Since.Unix())
+
+	session, firefox := snap.Units[0], snap.Units[1]
+	assert.Equal(t, "session-2.scope", session.Unit)
+	assert.Equal(t, "app-niri-firefox-42.scope", firefox.Unit)
+	assert.True(t, firefox.Active)
+	assert.Equal(t, uint64(8000), firefox.RxBytes)
+	assert.Equal(t, uint64(600), firefox.TxBytes)
+	assert.InDelta(t, 4000.0, firefox.RxRate, 0.01)
+
+	// The scope exits: its rules go, its totals stay
+	require.NoError(t, os.Remove(filepath.Join(root, firefoxPath)))
+	require.NoError(t, a.step(start.Add(4*time.Second)))
+	assert.Len(t, nft.rules, 2)
+
+	snap, err = ReadSnapshot(dir, 1000, start.Add(4*time.Second))
+	require.NoError(t, err)
+	require.Len(t, snap.Units, 2)
+	assert.False(t, snap.Units[1].Active)
+	assert.Equal(t, uint64(8000), snap.Units[1].RxBytes)
+	assert.Zero(t, snap.Units[1].RxRate)
+
+	require.NoError(t, a.step(start.Add(6*time.Second+Retention)))
+	snap, err = ReadSnapshot(dir, 1000, start.Add(6*time.Second+Retention))
+	require.NoError(t, err)
+	require.Len(t, snap.Units, 1)
+	assert.Equal(t, "session-2.scope", snap.Units[0].Unit)
+}
+
+func TestAccountantRecreatedCgroup(t *testing.T) {
+	root := t.TempDir()
+	dir := t.TempDir()
+	servicePath := "user.slice/user-1000.slice/user@1000.service/session.slice/dms.service"
+	makeCgroups(t, root, servicePath)
+
+	nft := &fakeNft{bytes: make(map[string]uint64)}
+	a := newAccountant(root, dir, nft.run, nft.list, noChown)
+	start := time.Now()
+	require.NoError(t, a.step(start))
+	nft.count(servicePath, 500, 50)
+	require.NoError(t, a.step(start.Add(2*time.Second)))
+
+	// A restart between two steps leaves the path but not the cgroup. A
+	// placeholder keeps the old inode from being handed out again.
+	require.NoError(t, os.Rename(filepath.Join(root, servicePath), filepath.Join(root, "old")))
+	makeCgroups(t, root, servicePath)
+	require.NoError(t, a.step(start.Add(4*time.Second)))
+	require.Len(t, nft.rules, 2)
+
+	nft.count(servicePath, 100, 10)
+	require.NoError(t, a.step(start.Add(6*time.Second)))
+
+	snap, err := ReadSnapshot(dir, 1000, start.Add(6*time.Second))
+	require.NoError(t, err)
+	require.Len(t, snap.Units, 1)
+	assert.Equal(t, uint64(600), snap.Units[0].RxBytes)
+	assert.Equal(t, uint64(60), snap.Units[0].TxBytes)
+}
+
+func TestAccountantRetriesFailedUpdate(t *testing.T) {
+	root := t.TempDir()
+	makeCgroups(t, root, sessionPath)
+
+	nft := &fakeNft{bytes: make(map[string]uint64), fail: true}
+	a := newAccountant(root, t.TempDir(), nft.run, nft.list, noChown)
+	require.NoError(t, a.step(time.Now()))
+	assert.Empty(t, a.units)
+
+	nft.fail = false
+	require.NoError(t, a.step(time.Now()))
+	assert.Len(t, a.units, 1)
+	assert.Len(t, nft.rules, 2)
+}
+
+func TestReadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	_, err := ReadSnapshot(dir, 1000, now)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, writeSnapshot(dir, 1000, Snapshot{UpdatedAt: now, Units: []UnitUsage{{Unit: "a.scope"}}}, noChown))
+	info, err := os.Stat(SnapshotPath(dir, 1000))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	snap, err := ReadSnapshot(dir, 1000, now)
+	require.NoError(t, err)
+	assert.Equal(t, "a.scope", snap.Units[0].Unit)
+
+	_, err = ReadSnapshot(dir, 1000, now.Add(StaleAfter+time.Second))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,151 @@
+package netacct
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os/exec"
+	"strings"
+)
+
+// Rules live in per-user chains so a packet from another user's socket, or
+// from a system service, only pays for one jump test. Each unit has a rule
+// in the in_<uid> and out_<uid> chains carrying the same comment.
+//
+//	table inet dms_appusage {
+//		chain input  { ... socket cgroupv2 level 2 "user.slice/user-1000.slice" jump in_1000 }
+//		chain in_1000 { socket cgroupv2 level 5 "user.slice/.../app-niri-foot-42.scope" counter return comment "u1a2b..." }
+//	}
+//
+// Incoming packets are matched through the kernel's socket lookup, which
+// covers TCP and UDP, so QUIC is counted. Matching cgroups on input needs
+// Linux 5.13 or newer.
+
+// ruleComment keys a unit's rules. nftables limits comments to 128 bytes,
+// which unit paths can exceed, so the path is hashed.
+func ruleComment(unitPath string) string {
+	h := fnv.New64a()
+	h.Write([]byte(unitPath))
+	return fmt.Sprintf("u%016x", h.Sum64())
+}
+
+func inChain(uid int) string  { return fmt.Sprintf("in_%d", uid) }
+func outChain(uid int) string { return fmt.Sprintf("out_%d", uid) }
+
+// tableScript replaces the table with empty base chains. Adding the table
+// first makes the delete succeed when it does not exist yet.
+func tableScript() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "add table inet %s\n", TableName)
+	fmt.Fprintf(&b, "delete table inet %s\n", TableName)
+	fmt.Fprintf(&b, "add table inet %s\n", TableName)
+	fmt.Fprintf(&b, "add chain inet %s input { type filter hook input priority -150; policy accept; }\n", TableName)
+	fmt.Fprintf(&b, "add chain inet %s output { type filter hook output priority -150; policy accept; }\n", TableName)
+	return b.String()
+}
+
+func deleteTableScript() string {
+	return fmt.Sprintf("add table inet %s\ndelete table inet %s\n", TableName, TableName)
+}
+
+func userScript(uid int) string {
+	slice := fmt.Sprintf("user.slice/user-%d.slice", uid)
+	var b strings.Builder
+	fmt.Fprintf(&b, "add chain inet %s %s\n", TableName, inChain(uid))
+	fmt.Fprintf(&b, "add chain inet %s %s\n", TableName, outChain(uid))
+	fmt.Fprintf(&b, "add rule inet %s input socket cgroupv2 level 2 %q jump %s\n", TableName, slice, inChain(uid))
+	fmt.Fprintf(&b, "add rule inet %s output socket cgroupv2 level 2 %q jump %s\n", TableName, slice, outChain(uid))
+	return b.String()
+}
+
+// unitScript counts a unit's traffic. nftables strings have no escapes, so
+// systemd's \x2d in unit names passes through as it is on disk.
+func unitScript(u Unit) string {
+	var b strings.Builder
+	for _, chain := range []string{inChain(u.UID), outChain(u.UID)} {
+		fmt.Fprintf(&b, "add rule inet %s %s socket cgroupv2 level %d \"%s\" counter return comment %q\n",
+			TableName, chain, u.level(), u.Path, ruleComment(u.Path))
+	}
+	return b.String()
+}
+
+func deleteRuleScript(r nftRule) string {
+	return fmt.Sprintf("delete rule inet %s %s handle %d\n", TableName, r.Chain, r.Handle)
+}
+
+// nftRule is a counting rule as `nft -j list table` reports it
+type nftRule struct {
+	Chain   string
+	Handle  int
+	Comment string
+	Packets uint64
+	Bytes   uint64
+}
+
+// inbound reports whether the rule counts received traffic
+func (r nftRule) inbound() bool {
+	return strings.HasPrefix(r.Chain, "in_")
+}
+
+// parseRules picks the counting rules out of `nft -j list table` output.
+// Jump rules carry no comment and are skipped.
+func parseRules(data []byte) ([]nftRule, error) {
+	var doc struct {
+		Nftables []struct {
+			Rule *struct {
+				Chain   string `json:"chain"`
+				Handle  int    `json:"handle"`
+				Comment string `json:"comment"`
+				Expr    []struct {
+					Counter *struct {
+						Packets uint64 `json:"packets"`
+						Bytes   uint64 `json:"bytes"`
+					} `json:"counter"`
+				} `json:"expr"`
+			} `json:"rule"`
+		} `json:"nftables"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse nft output: %w", err)
+	}
+
+	var rules []nftRule
+	for _, item := range doc.Nftables {
+		if item.Rule == nil || item.Rule.Comment == "" {
+			continue
+		}
+		rule := nftRule{Chain: item.Rule.Chain, Handle: item.Rule.Handle, Comment: item.Rule.Comment}
+		for _, expr := range item.Rule.Expr {
+			if expr.Counter != nil {
+				rule.Packets = expr.Counter.Packets
+				rule.Bytes = expr.Counter.Bytes
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// runNft feeds a script to `nft -f -`, which applies it as one transaction
+func runNft(script string) error {
+	cmd := exec.Command("nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("nft: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func listTable() ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("nft", "-j", "list", "table", "inet", TableName)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("nft list table: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package netacct
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	ServiceName = "dms-netacct.service"
+	ServicePath = "/etc/systemd/system/" + ServiceName
+)
+
+// serviceUnit runs the helper from the dms binary at exe
+func serviceUnit(exe string) string {
+	return fmt.Sprintf(`[Unit]
+Description=DankMaterialShell per-application network accounting
+Documentation=https://github.com/AvengeMedia/danklinux
+
+[Service]
+ExecStart=%s netacct run
+Restart=on-failure
+RuntimeDirectory=dms-netacct
+RuntimeDirectoryMode=0755
+CapabilityBoundingSet=CAP_NET_ADMIN CAP_CHOWN
+ProtectSystem=strict
+ProtectHome=read-only
+PrivateTmp=yes
+
+[Install]
+WantedBy=multi-user.target
+`, exe)
+}
+
+// Install writes the service for the dms binary at exe and starts it. The
+// caller must be root.
+func Install(exe string) error {
+	if err := os.WriteFile(ServicePath, []byte(serviceUnit(exe)), 0644); err != nil {
+		return err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", ServiceName)
+}
+
+// Uninstall stops the service and removes it. The caller must be root.
+func Uninstall() error {
+	if err := systemctl("disable", "--now", ServiceName); err != nil {
+		return err
+	}
+	if err := os.Remove(ServicePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+// ServiceActive reports whether systemd runs the helper
+func ServiceActive() bool {
+	return exec.Command("systemctl", "is-active", "--quiet", ServiceName).Run() == nil
+}
+
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package netacct
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Unit is a scope or service under a user's slice. Path is relative to the
+// cgroup root, e.g.
+// user.slice/user-1000.slice/user@1000.service/app.slice/app-niri-foot-42.scope
+type Unit struct {
+	UID  int
+	Path string
+	// Inode identifies this incarnation of the cgroup. A service that
+	// restarts gets a new cgroup under the same path, and nftables
+	// resolves the path to the cgroup when the rule is added.
+	Inode uint64
+}
+
+// Name is the systemd unit name, the last path component
+func (u Unit) Name() string {
+	return path.Base(u.Path)
+}
+
+// level is the cgroup depth `socket cgroupv2 level` compares at
+func (u Unit) level() int {
+	return strings.Count(u.Path, "/") + 1
+}
+
+var userSlicePattern = regexp.MustCompile(`^user-(\d+)\.slice$`)
+
+// discoverUnits lists every scope and service below user.slice. Slices and
+// the user manager are descended into; any other unit is a leaf, even when
+// it delegates cgroups of its own.
+func discoverUnits(root string) ([]Unit, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "user.slice"))
+	if err != nil {
+		return nil, err
+	}
+
+	var units []Unit
+	for _, e := range entries {
+		m := userSlicePattern.FindStringSubmatch(e.Name())
+		if m == nil || !e.IsDir() {
+			continue
+		}
+		uid, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		walkUnits(root, path.Join("user.slice", e.Name()), uid, &units)
+	}
+
+	sort.Slice(units, func(i, j int) bool { return units[i].Path < units[j].Path })
+	return units, nil
+}
+
+func walkUnits(root, rel string, uid int, units *[]Unit) {
+	entries, err := os.ReadDir(filepath.Join(root, rel))
+	if err != nil {
+		// The slice went away while walking
+		return
+	}
+
+	manager := "user@" + strconv.Itoa(uid) + ".service"
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		child := path.Join(rel, name)
+		switch {
+		case strings.HasSuffix(name, ".slice") || name == manager:
+			walkUnits(root, child, uid, units)
+		case strings.HasSuffix(name, ".scope") || strings.HasSuffix(name, ".service"):
+			info, err := os.Stat(filepath.Join(root, child))
+			if err != nil {
+				continue
+			}
+			var inode uint64
+			if st, ok := info.Sys().(*syscall.Stat_t); ok {
+				inode = st.Ino
+			}
+			*units = append(*units, Unit{UID: uid, Path: child, Inode: inode})
+		}
+	}
+}
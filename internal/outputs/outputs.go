@@ -0,0 +1,338 @@
+// Package outputs reads the geometry and scale of Wayland outputs. It only
+// needs a connection to the compositor, so the daemon and the config
+// wizard share it.
+package outputs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	wlclient "github.com/yaslama/go-wayland/wayland/client"
+	"github.com/yaslama/go-wayland/wayland/unstable/xdg-output-v1"
+
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// referenceDPI is the density at which a scale of 1 looks right
+const referenceDPI = 96.0
+
+// Info combines wl_output and xdg-output data for one output. Scale is the
+// fractional scale derived from the pixel and logical sizes.
+type Info struct {
+	Name             string  `json:"name"`
+	Description      string  `json:"description,omitempty"`
+	Make             string  `json:"make,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	PhysicalWidthMM  int32   `json:"physicalWidthMm"`
+	PhysicalHeightMM int32   `json:"physicalHeightMm"`
+	PixelWidth       int32   `json:"pixelWidth"`
+	PixelHeight      int32   `json:"pixelHeight"`
+	RefreshMHz       int32   `json:"refreshMhz"`
+	Transform        int32   `json:"transform"`
+	IntegerScale     int32   `json:"integerScale"`
+	LogicalX         int32   `json:"logicalX"`
+	LogicalY         int32   `json:"logicalY"`
+	LogicalWidth     int32   `json:"logicalWidth"`
+	LogicalHeight    int32   `json:"logicalHeight"`
+	Scale            float64 `json:"scale"`
+	DPI              float64 `json:"dpi"`
+	EffectiveDPI     float64 `json:"effectiveDpi"`
+	SuggestedScale   float64 `json:"suggestedScale"`
+}
+
+// computeDerived fills Scale, DPI, EffectiveDPI and SuggestedScale
+func (o *Info) computeDerived() {
+	o.Scale = float64(o.IntegerScale)
+	if o.Scale <= 0 {
+		o.Scale = 1
+	}
+
+	// Modes are reported before the transform, logical sizes after it
+	pixelWidth := o.PixelWidth
+	if o.Transform%2 == 1 {
+		pixelWidth = o.PixelHeight
+	}
+	if o.LogicalWidth > 0 && pixelWidth > 0 {
+		// Compositors snap fractional scales to 1/120 steps
+		o.Scale = math.Round(float64(pixelWidth)/float64(o.LogicalWidth)*120) / 120
+	}
+
+	o.DPI = 0
+	if o.PhysicalWidthMM > 0 && o.PixelWidth > 0 {
+		o.DPI = math.Round(float64(o.PixelWidth)/(float64(o.PhysicalWidthMM)/25.4)*10) / 10
+	}
+	o.EffectiveDPI = math.Round(o.DPI/o.Scale*10) / 10
+	o.SuggestedScale = SuggestScale(o.DPI)
+}
+
+// SuggestScale proposes a scale in 0.25 steps that brings dpi close to 96.
+// Unknown sizes (projectors, virtual outputs) get 1.
+func SuggestScale(dpi float64) float64 {
+	if dpi <= 0 {
+		return 1
+	}
+	scale := math.Round(dpi/referenceDPI*4) / 4
+	return math.Max(1, math.Min(3, scale))
+}
+
+type trackedOutput struct {
+	registryName uint32
+	output       *wlclient.Output
+	xdgOutput    *xdg_output.Output
+	pending      Info
+	info         Info
+	ready        bool
+}
+
+// Tracker follows geometry events for every bound wl_output. Name events
+// are forwarded by the owner with SetName since a wl_output has a single
+// handler.
+type Tracker struct {
+	mu         sync.RWMutex
+	outputs    map[uint32]*trackedOutput
+	xdgManager *xdg_output.OutputManager
+	onChange   func()
+}
+
+// NewTracker calls onChange, if set, whenever an output is committed with
+// new values or removed
+func NewTracker(onChange func()) *Tracker {
+	return &Tracker{
+		outputs:  make(map[uint32]*trackedOutput),
+		onChange: onChange,
+	}
+}
+
+// BindManager binds zxdg_output_manager_v1 and attaches xdg-outputs to the
+// outputs already tracked
+func (t *Tracker) BindManager(registry *wlclient.Registry, name, version uint32) {
+	manager := xdg_output.NewOutputManager(registry.Context())
+	if version > 3 {
+		version = 3
+	}
+	if err := registry.Bind(name, xdg_output.OutputManagerInterfaceName, version, manager); err != nil {
+		log.Warnf("Failed to bind %s: %v", xdg_output.OutputManagerInterfaceName, err)
+		return
+	}
+
+	t.mu.Lock()
+	t.xdgManager = manager
+	tracked := make([]*trackedOutput, 0, len(t.outputs))
+	for _, out := range t.outputs {
+		tracked = append(tracked, out)
+	}
+	t.mu.Unlock()
+
+	for _, out := range tracked {
+		t.attachXdgOutput(out)
+	}
+}
+
+// AddOutput starts tracking a bound wl_output
+func (t *Tracker) AddOutput(output *wlclient.Output, registryName uint32) {
+	out := &trackedOutput{registryName: registryName, output: output}
+	id := output.ID()
+
+	output.SetGeometryHandler(func(e wlclient.OutputGeometryEvent) {
+		t.update(id, func(info *Info) {
+			info.Make = e.Make
+			info.Model = e.Model
+			info.PhysicalWidthMM = e.PhysicalWidth
+			info.PhysicalHeightMM = e.PhysicalHeight
+			info.Transform = e.Transform
+		})
+	})
+	output.SetModeHandler(func(e wlclient.OutputModeEvent) {
+		if e.Flags&uint32(wlclient.OutputModeCurrent) == 0 {
+			return
+		}
+		t.update(id, func(info *Info) {
+			info.PixelWidth = e.Width
+			info.PixelHeight = e.Height
+			info.RefreshMHz = e.Refresh
+		})
+	})
+	output.SetScaleHandler(func(e wlclient.OutputScaleEvent) {
+		t.update(id, func(info *Info) { info.IntegerScale = e.Factor })
+	})
+	output.SetDescriptionHandler(func(e wlclient.OutputDescriptionEvent) {
+		t.update(id, func(info *Info) { info.Description = e.Description })
+	})
+	output.SetDoneHandler(func(wlclient.OutputDoneEvent) {
+		t.commit(id)
+	})
+
+	t.mu.Lock()
+	t.outputs[id] = out
+	hasManager := t.xdgManager != nil
+	t.mu.Unlock()
+
+	if hasManager {
+		t.attachXdgOutput(out)
+	}
+}
+
+func (t *Tracker) attachXdgOutput(out *trackedOutput) {
+	t.mu.Lock()
+	if out.xdgOutput != nil || t.xdgManager == nil {
+		t.mu.Unlock()
+		return
+	}
+	manager := t.xdgManager
+	t.mu.Unlock()
+
+	xdgOut, err := manager.GetXdgOutput(out.output)
+	if err != nil {
+		log.Warnf("Failed to get xdg-output for output %d: %v", out.output.ID(), err)
+		return
+	}
+
+	id := out.output.ID()
+	xdgOut.SetLogicalPositionHandler(func(e xdg_output.OutputLogicalPositionEvent) {
+		t.update(id, func(info *Info) {
+			info.LogicalX = e.X
+			info.LogicalY = e.Y
+		})
+	})
+	xdgOut.SetLogicalSizeHandler(func(e xdg_output.OutputLogicalSizeEvent) {
+		t.update(id, func(info *Info) {
+			info.LogicalWidth = e.Width
+			info.LogicalHeight = e.Height
+		})
+	})
+	xdgOut.SetNameHandler(func(e xdg_output.OutputNameEvent) {
+		t.SetName(id, e.Name)
+	})
+	// Version 3 defers to wl_output.done, older versions send their own
+	xdgOut.SetDoneHandler(func(xdg_output.OutputDoneEvent) {
+		t.commit(id)
+	})
+
+	t.mu.Lock()
+	out.xdgOutput = xdgOut
+	t.mu.Unlock()
+}
+
+// SetName records the name the owner's wl_output or xdg-output name
+// handler received
+func (t *Tracker) SetName(id uint32, name string) {
+	t.update(id, func(info *Info) { info.Name = name })
+	t.mu.Lock()
+	if out, ok := t.outputs[id]; ok && out.ready {
+		out.info.Name = name
+	}
+	t.mu.Unlock()
+}
+
+func (t *Tracker) update(id uint32, fn func(*Info)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if out, ok := t.outputs[id]; ok {
+		fn(&out.pending)
+	}
+}
+
+func (t *Tracker) commit(id uint32) {
+	t.mu.Lock()
+	out, ok := t.outputs[id]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	info := out.pending
+	info.computeDerived()
+	changed := !out.ready || info != out.info
+	out.info = info
+	out.ready = true
+	t.mu.Unlock()
+
+	if changed && t.onChange != nil {
+		t.onChange()
+	}
+}
+
+// Remove stops tracking the output bound from registry global registryName
+func (t *Tracker) Remove(registryName uint32) {
+	t.mu.Lock()
+	var removed bool
+	for id, out := range t.outputs {
+		if out.registryName != registryName {
+			continue
+		}
+		if out.xdgOutput != nil {
+			out.xdgOutput.Destroy()
+		}
+		delete(t.outputs, id)
+		removed = removed || out.ready
+	}
+	t.mu.Unlock()
+
+	if removed && t.onChange != nil {
+		t.onChange()
+	}
+}
+
+// Snapshot lists the outputs that sent their first done event, by name
+func (t *Tracker) Snapshot() []Info {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	infos := make([]Info, 0, len(t.outputs))
+	for _, out := range t.outputs {
+		if out.ready {
+			infos = append(infos, out.info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Query opens a short-lived connection to the compositor and reports all
+// outputs. Used outside the server, e.g. by the config wizard.
+func Query() ([]Info, error) {
+	display, err := wlclient.Connect("")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errdefs.ErrNoWaylandDisplay, err)
+	}
+	defer display.Context().Close()
+
+	registry, err := display.GetRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registry: %w", err)
+	}
+
+	tracker := NewTracker(nil)
+	registry.SetGlobalHandler(func(e wlclient.RegistryGlobalEvent) {
+		switch e.Interface {
+		case xdg_output.OutputManagerInterfaceName:
+			tracker.BindManager(registry, e.Name, e.Version)
+		case "wl_output":
+			output := wlclient.NewOutput(display.Context())
+			version := e.Version
+			if version > 4 {
+				version = 4
+			}
+			if err := registry.Bind(e.Name, e.Interface, version, output); err != nil {
+				log.Warnf("Failed to bind wl_output: %v", err)
+				return
+			}
+			id := output.ID()
+			output.SetNameHandler(func(ev wlclient.OutputNameEvent) {
+				tracker.SetName(id, ev.Name)
+			})
+			tracker.AddOutput(output, e.Name)
+		}
+	})
+
+	// Globals, then output and xdg-output events
+	for i := 0; i < 3; i++ {
+		if err := display.Roundtrip(); err != nil {
+			return nil, fmt.Errorf("roundtrip failed: %w", err)
+		}
+	}
+
+	return tracker.Snapshot(), nil
+}
@@ -0,0 +1,91 @@
+package outputs
+
+import "testing"
+
+func TestInfoComputeDerived(t *testing.T) {
+	tests := []struct {
+		name          string
+		info          Info
+		wantScale     float64
+		wantDPI       float64
+		wantEffective float64
+		wantSuggested float64
+	}{
+		{
+			name: "27in_4k_fractional",
+			info: Info{
+				PhysicalWidthMM: 597, PhysicalHeightMM: 336,
+				PixelWidth: 3840, PixelHeight: 2160,
+				IntegerScale: 2,
+				LogicalWidth: 2560, LogicalHeight: 1440,
+			},
+			wantScale:     1.5,
+			wantDPI:       163.4,
+			wantEffective: 108.9,
+			wantSuggested: 1.75,
+		},
+		{
+			name: "rotated_portrait",
+			info: Info{
+				PhysicalWidthMM: 527, PhysicalHeightMM: 296,
+				PixelWidth: 1920, PixelHeight: 1080,
+				Transform:    1,
+				IntegerScale: 1,
+				LogicalWidth: 1080, LogicalHeight: 1920,
+			},
+			wantScale:     1,
+			wantDPI:       92.5,
+			wantEffective: 92.5,
+			wantSuggested: 1,
+		},
+		{
+			name: "no_xdg_output_or_physical_size",
+			info: Info{
+				PixelWidth: 1920, PixelHeight: 1080,
+				IntegerScale: 2,
+			},
+			wantScale:     2,
+			wantDPI:       0,
+			wantEffective: 0,
+			wantSuggested: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			info.computeDerived()
+			if info.Scale != tt.wantScale {
+				t.Errorf("Scale = %v, want %v", info.Scale, tt.wantScale)
+			}
+			if info.DPI != tt.wantDPI {
+				t.Errorf("DPI = %v, want %v", info.DPI, tt.wantDPI)
+			}
+			if info.EffectiveDPI != tt.wantEffective {
+				t.Errorf("EffectiveDPI = %v, want %v", info.EffectiveDPI, tt.wantEffective)
+			}
+			if info.SuggestedScale != tt.wantSuggested {
+				t.Errorf("SuggestedScale = %v, want %v", info.SuggestedScale, tt.wantSuggested)
+			}
+		})
+	}
+}
+
+func TestSuggestScale(t *testing.T) {
+	tests := []struct {
+		dpi  float64
+		want float64
+	}{
+		{0, 1},
+		{92, 1},
+		{141, 1.5},
+		{220, 2.25},
+		{400, 3},
+	}
+
+	for _, tt := range tests {
+		if got := SuggestScale(tt.dpi); got != tt.want {
+			t.Errorf("SuggestScale(%v) = %v, want %v", tt.dpi, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package a11y
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "a11y.get":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "a11y.set":
+		handleSet(conn, req, manager)
+	case "a11y.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSet(conn net.Conn, req Request, manager *Manager) {
+	var update Update
+
+	if raw, ok := req.Params["cursorSize"]; ok {
+		value, ok := raw.(float64)
+		if !ok || value != float64(int(value)) {
+			models.RespondError(conn, req.ID, "invalid 'cursorSize' parameter")
+			return
+		}
+		size := int(value)
+		update.CursorSize = &size
+	}
+	if raw, ok := req.Params["textScale"]; ok {
+		value, ok := raw.(float64)
+		if !ok {
+			models.RespondError(conn, req.ID, "invalid 'textScale' parameter")
+			return
+		}
+		update.TextScale = &value
+	}
+	if raw, ok := req.Params["reduceMotion"]; ok {
+		value, ok := raw.(bool)
+		if !ok {
+			models.RespondError(conn, req.ID, "invalid 'reduceMotion' parameter")
+			return
+		}
+		update.ReduceMotion = &value
+	}
+
+	if update.CursorSize == nil && update.TextScale == nil && update.ReduceMotion == nil {
+		models.RespondError(conn, req.ID, "nothing to set: pass cursorSize, textScale or reduceMotion")
+		return
+	}
+
+	state, err := manager.Set(update)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package a11y
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const gnomeInterface = "org.gnome.desktop.interface"
+
+func NewManager() (*Manager, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+
+	m := newManager(configHome, os.Getenv, runCommand, outputCommand)
+	m.load()
+	return m, nil
+}
+
+func newManager(configHome string, getenv func(string) string, run func(string, ...string) error, output func(string, ...string) ([]byte, error)) *Manager {
+	return &Manager{
+		configHome:  configHome,
+		getenv:      getenv,
+		run:         run,
+		output:      output,
+		current:     Settings{CursorSize: DefaultCursorSize, TextScale: 1},
+		subscribers: make(map[string]chan State),
+	}
+}
+
+func runCommand(name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return err
+}
+
+func outputCommand(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// load reads the current values from GSettings, which GTK, the portal and
+// most toolkits already follow. Without it the defaults are assumed.
+func (m *Manager) load() {
+	get := func(key string) (string, bool) {
+		out, err := m.output("gsettings", "get", gnomeInterface, key)
+		if err != nil {
+			return "", false
+		}
+		// Integers print with a type prefix when the schema is missing
+		fields := strings.Fields(strings.TrimSpace(string(out)))
+		if len(fields) == 0 {
+			return "", false
+		}
+		return fields[len(fields)-1], true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if v, ok := get("cursor-size"); ok {
+		if size, err := strconv.Atoi(v); err == nil && size > 0 {
+			m.current.CursorSize = size
+		}
+	}
+	if v, ok := get("text-scaling-factor"); ok {
+		if scale, err := strconv.ParseFloat(v, 64); err == nil && scale > 0 {
+			m.current.TextScale = scale
+		}
+	}
+	if v, ok := get("enable-animations"); ok {
+		m.current.ReduceMotion = v == "false"
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stateLocked()
+}
+
+func (m *Manager) stateLocked() State {
+	return State{
+		Settings: m.current,
+		Applied:  append([]string{}, m.applied...),
+		Failed:   append([]string(nil), m.failed...),
+	}
+}
+
+// Set validates the update, writes the result everywhere it is read from
+// and notifies subscribers. It only fails when nothing could be written.
+func (m *Manager) Set(u Update) (State, error) {
+	m.mu.Lock()
+	prev := m.current
+	m.mu.Unlock()
+	next := prev
+
+	if u.CursorSize != nil {
+		next.CursorSize = *u.CursorSize
+	}
+	if u.TextScale != nil {
+		next.TextScale = *u.TextScale
+	}
+	if u.ReduceMotion != nil {
+		next.ReduceMotion = *u.ReduceMotion
+	}
+
+	if next.CursorSize < MinCursorSize || next.CursorSize > MaxCursorSize {
+		return State{}, fmt.Errorf("cursor size must be between %d and %d", MinCursorSize, MaxCursorSize)
+	}
+	if next.TextScale < MinTextScale || next.TextScale > MaxTextScale {
+		return State{}, fmt.Errorf("text scale must be between %.1f and %.1f", MinTextScale, MaxTextScale)
+	}
+
+	applied, failed := m.apply(change{from: prev, to: next, update: u})
+
+	m.mu.Lock()
+	if len(applied) > 0 {
+		m.current = next
+	}
+	m.applied = applied
+	m.failed = failed
+	state := m.stateLocked()
+	m.mu.Unlock()
+
+	if len(applied) == 0 {
+		return state, fmt.Errorf("no accessibility settings could be written: %s", strings.Join(failed, "; "))
+	}
+
+	log.Infof("Accessibility settings applied to %s", strings.Join(applied, ", "))
+	m.broadcast(state)
+	return state, nil
+}
+
+// change is one Set call: the settings before and after, and which of them
+// the caller actually passed
+type change struct {
+	from, to Settings
+	update   Update
+}
+
+type applyTarget struct {
+	name string
+	fn   func(change) error
+}
+
+// apply writes the change to every place toolkits read it from. GSettings
+// also feeds the org.gnome.desktop.interface keys of the settings portal,
+// which is how Flatpak apps see the change.
+func (m *Manager) apply(c change) (applied, failed []string) {
+	targets := []applyTarget{
+		{"gsettings", func(c change) error { return m.applyGSettings(c.to) }},
+		{"gtk-3.0", func(c change) error { return m.applyGTK("gtk-3.0", c) }},
+		{"gtk-4.0", func(c change) error { return m.applyGTK("gtk-4.0", c) }},
+		{"kdeglobals", m.applyKDE},
+		{"session environment", func(c change) error { return m.applyEnvironment(c.to) }},
+	}
+	if compositor.Detect(m.getenv) == compositor.Hyprland {
+		targets = append(targets, applyTarget{"hyprland", func(c change) error { return m.applyHyprland(c.to) }})
+	}
+
+	for _, t := range targets {
+		if err := t.fn(c); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", t.name, err))
+			continue
+		}
+		applied = append(applied, t.name)
+	}
+	return applied, failed
+}
+
+func (m *Manager) applyGSettings(s Settings) error {
+	values := [][2]string{
+		{"cursor-size", strconv.Itoa(s.CursorSize)},
+		{"text-scaling-factor", fmt.Sprintf("%.2f", s.TextScale)},
+		{"enable-animations", strconv.FormatBool(!s.ReduceMotion)},
+	}
+
+	if err := m.run("gsettings", "writable", gnomeInterface, "cursor-size"); err == nil {
+		for _, kv := range values {
+			if err := m.run("gsettings", "set", gnomeInterface, kv[0], kv[1]); err != nil {
+				return fmt.Errorf("gsettings set %s failed: %w", kv[0], err)
+			}
+		}
+		return nil
+	}
+
+	// Without the schemas installed dconf still stores the keys for
+	// anything that reads them directly
+	for _, kv := range values {
+		if err := m.run("dconf", "write", "/org/gnome/desktop/interface/"+kv[0], kv[1]); err != nil {
+			return fmt.Errorf("both gsettings and dconf unavailable or failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyGTK covers GTK apps outside GNOME's settings daemon. Fonts follow
+// the text scale through gtk-xft-dpi, which is in 1024ths of a DPI.
+func (m *Manager) applyGTK(dir string, c change) error {
+	values := make(map[string]string)
+	if c.update.CursorSize != nil {
+		values["gtk-cursor-theme-size"] = strconv.Itoa(c.to.CursorSize)
+	}
+	if c.update.ReduceMotion != nil {
+		values["gtk-enable-animations"] = strconv.FormatBool(!c.to.ReduceMotion)
+	}
+	if c.update.TextScale != nil {
+		if c.to.TextScale != 1 {
+			values["gtk-xft-dpi"] = strconv.Itoa(int(96 * 1024 * c.to.TextScale))
+		} else if c.from.TextScale != 1 {
+			values["gtk-xft-dpi"] = ""
+		}
+	}
+	return updateINI(filepath.Join(m.configHome, dir, "settings.ini"), "Settings", values)
+}
+
+// applyKDE turns off animations for Qt apps using the KDE platform theme
+func (m *Manager) applyKDE(c change) error {
+	values := make(map[string]string)
+	if c.update.ReduceMotion != nil {
+		if c.to.ReduceMotion {
+			values["AnimationDurationFactor"] = "0"
+		} else if c.from.ReduceMotion {
+			values["AnimationDurationFactor"] = ""
+		}
+	}
+	return updateINI(filepath.Join(m.configHome, "kdeglobals"), "KDE", values)
+}
+
+// applyEnvironment reaches apps started after the change: XCURSOR_SIZE is
+// read by Qt, Electron and Xwayland clients and QT_FONT_DPI scales Qt text
+func (m *Manager) applyEnvironment(s Settings) error {
+	return m.run("dbus-update-activation-environment", "--systemd",
+		"XCURSOR_SIZE="+strconv.Itoa(s.CursorSize),
+		"QT_FONT_DPI="+strconv.Itoa(int(96*s.TextScale)))
+}
+
+// applyHyprland resizes the cursor of running clients right away
+func (m *Manager) applyHyprland(s Settings) error {
+	theme := m.getenv("XCURSOR_THEME")
+	if out, err := m.output("gsettings", "get", gnomeInterface, "cursor-theme"); err == nil {
+		if t := strings.Trim(strings.TrimSpace(string(out)), "'"); t != "" {
+			theme = t
+		}
+	}
+	if theme == "" {
+		theme = "default"
+	}
+	return m.run("hyprctl", "setcursor", theme, strconv.Itoa(s.CursorSize))
+}
+
+// updateINI sets keys in one section of an INI file, keeping everything
+// else. An empty value removes the key, which is only asked for when going
+// back to the toolkit default from a value written here; keys left out of
+// values are never touched.
+func updateINI(path, section string, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	pending := make(map[string]string, len(values))
+	for k, v := range values {
+		if v != "" {
+			pending[k] = v
+		}
+	}
+	keys := make([]string, 0, len(pending))
+	for k := range pending {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	header := "[" + section + "]"
+	var out []string
+	flush := func() {
+		// Keep the section's trailing blank lines after the new keys
+		at := len(out)
+		for at > 0 && strings.TrimSpace(out[at-1]) == "" {
+			at--
+		}
+		var add []string
+		for _, k := range keys {
+			if v, ok := pending[k]; ok {
+				add = append(add, k+"="+v)
+				delete(pending, k)
+			}
+		}
+		out = append(out[:at], append(add, out[at:]...)...)
+	}
+
+	inSection, found := false, false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			if inSection {
+				flush()
+			}
+			inSection = trimmed == header
+			found = found || inSection
+			out = append(out, line)
+			continue
+		}
+		if inSection {
+			if key, _, ok := strings.Cut(trimmed, "="); ok {
+				key = strings.TrimSpace(key)
+				if _, managed := values[key]; managed {
+					if v, ok := pending[key]; ok {
+						out = append(out, key+"="+v)
+						delete(pending, key)
+					}
+					continue
+				}
+			}
+		}
+		out = append(out, line)
+	}
+
+	if inSection {
+		flush()
+	} else if !found && len(pending) > 0 {
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, header)
+		flush()
+	}
+
+	if len(out) == 0 && len(data) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(out, "\n")+"\n"), 0644)
+}
+
+func (m *Manager) broadcast(state State) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) Close() {
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
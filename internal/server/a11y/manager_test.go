@@ -0,0 +1,165 @@
+package a11y
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCommands struct {
+	ran     []string
+	outputs map[string]string
+	failing map[string]bool
+}
+
+func (f *fakeCommands) run(name string, args ...string) error {
+	line := strings.Join(append([]string{name}, args...), " ")
+	f.ran = append(f.ran, line)
+	if f.failing[name] {
+		return errors.New("not found")
+	}
+	return nil
+}
+
+func (f *fakeCommands) output(name string, args ...string) ([]byte, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	if out, ok := f.outputs[line]; ok {
+		return []byte(out), nil
+	}
+	return nil, errors.New("not found")
+}
+
+func newTestManager(t *testing.T, env map[string]string) (*Manager, *fakeCommands, string) {
+	cmds := &fakeCommands{outputs: map[string]string{}, failing: map[string]bool{}}
+	configHome := t.TempDir()
+	m := newManager(configHome, func(key string) string { return env[key] }, cmds.run, cmds.output)
+	return m, cmds, configHome
+}
+
+func intPtr(v int) *int           { return &v }
+func floatPtr(v float64) *float64 { return &v }
+func boolPtr(v bool) *bool        { return &v }
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestLoad(t *testing.T) {
+	m, cmds, _ := newTestManager(t, nil)
+	cmds.outputs["gsettings get org.gnome.desktop.interface cursor-size"] = "int32 48\n"
+	cmds.outputs["gsettings get org.gnome.desktop.interface text-scaling-factor"] = "1.25\n"
+	cmds.outputs["gsettings get org.gnome.desktop.interface enable-animations"] = "false\n"
+	m.load()
+
+	assert.Equal(t, Settings{CursorSize: 48, TextScale: 1.25, ReduceMotion: true}, m.GetState().Settings)
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	m, _, _ := newTestManager(t, nil)
+	m.load()
+	assert.Equal(t, Settings{CursorSize: DefaultCursorSize, TextScale: 1}, m.GetState().Settings)
+}
+
+func TestSet(t *testing.T) {
+	m, cmds, configHome := newTestManager(t, map[string]string{"HYPRLAND_INSTANCE_SIGNATURE": "abc"})
+	cmds.outputs["gsettings get org.gnome.desktop.interface cursor-theme"] = "'Bibata-Modern-Ice'\n"
+
+	gtk3 := filepath.Join(configHome, "gtk-3.0", "settings.ini")
+	require.NoError(t, os.MkdirAll(filepath.Dir(gtk3), 0755))
+	require.NoError(t, os.WriteFile(gtk3, []byte("[Settings]\ngtk-icon-theme-name=Papirus\ngtk-cursor-theme-size=24\n\n[Other]\nkey=value\n"), 0644))
+
+	sub := m.Subscribe("test")
+	state, err := m.Set(Update{CursorSize: intPtr(48), TextScale: floatPtr(1.5), ReduceMotion: boolPtr(true)})
+	require.NoError(t, err)
+
+	assert.Equal(t, Settings{CursorSize: 48, TextScale: 1.5, ReduceMotion: true}, state.Settings)
+	assert.Equal(t, []string{"gsettings", "gtk-3.0", "gtk-4.0", "kdeglobals", "session environment", "hyprland"}, state.Applied)
+	assert.Empty(t, state.Failed)
+	assert.Equal(t, state, <-sub)
+
+	assert.Equal(t, []string{
+		"gsettings writable org.gnome.desktop.interface cursor-size",
+		"gsettings set org.gnome.desktop.interface cursor-size 48",
+		"gsettings set org.gnome.desktop.interface text-scaling-factor 1.50",
+		"gsettings set org.gnome.desktop.interface enable-animations false",
+		"dbus-update-activation-environment --systemd XCURSOR_SIZE=48 QT_FONT_DPI=144",
+		"hyprctl setcursor Bibata-Modern-Ice 48",
+	}, cmds.ran)
+
+	assert.Equal(t, "[Settings]\ngtk-icon-theme-name=Papirus\ngtk-cursor-theme-size=48\ngtk-enable-animations=false\ngtk-xft-dpi=147456\n\n[Other]\nkey=value\n", readFile(t, gtk3))
+	assert.Equal(t, "[Settings]\ngtk-cursor-theme-size=48\ngtk-enable-animations=false\ngtk-xft-dpi=147456\n", readFile(t, filepath.Join(configHome, "gtk-4.0", "settings.ini")))
+	assert.Equal(t, "[KDE]\nAnimationDurationFactor=0\n", readFile(t, filepath.Join(configHome, "kdeglobals")))
+
+	// Only the given settings change, and defaults remove what was added
+	state, err = m.Set(Update{TextScale: floatPtr(1), ReduceMotion: boolPtr(false)})
+	require.NoError(t, err)
+	assert.Equal(t, Settings{CursorSize: 48, TextScale: 1}, state.Settings)
+	assert.Equal(t, "[Settings]\ngtk-cursor-theme-size=48\ngtk-enable-animations=true\n", readFile(t, filepath.Join(configHome, "gtk-4.0", "settings.ini")))
+	assert.Equal(t, "[KDE]\n", readFile(t, filepath.Join(configHome, "kdeglobals")))
+}
+
+func TestSet_KeepsUserKeys(t *testing.T) {
+	m, _, configHome := newTestManager(t, nil)
+
+	gtk3 := filepath.Join(configHome, "gtk-3.0", "settings.ini")
+	require.NoError(t, os.MkdirAll(filepath.Dir(gtk3), 0755))
+	require.NoError(t, os.WriteFile(gtk3, []byte("[Settings]\ngtk-xft-dpi=110592\ngtk-enable-animations=false\n"), 0644))
+	kde := filepath.Join(configHome, "kdeglobals")
+	require.NoError(t, os.WriteFile(kde, []byte("[KDE]\nAnimationDurationFactor=0.5\n"), 0644))
+
+	// Only the cursor changes, everything else is the user's
+	_, err := m.Set(Update{CursorSize: intPtr(32)})
+	require.NoError(t, err)
+	assert.Equal(t, "[Settings]\ngtk-xft-dpi=110592\ngtk-enable-animations=false\ngtk-cursor-theme-size=32\n", readFile(t, gtk3))
+	assert.Equal(t, "[KDE]\nAnimationDurationFactor=0.5\n", readFile(t, kde))
+
+	// Setting what is already the toolkit default removes nothing
+	_, err = m.Set(Update{TextScale: floatPtr(1), ReduceMotion: boolPtr(false)})
+	require.NoError(t, err)
+	assert.Equal(t, "[Settings]\ngtk-xft-dpi=110592\ngtk-enable-animations=true\ngtk-cursor-theme-size=32\n", readFile(t, gtk3))
+	assert.Equal(t, "[KDE]\nAnimationDurationFactor=0.5\n", readFile(t, kde))
+}
+
+func TestSet_DconfFallbackAndFailures(t *testing.T) {
+	m, cmds, configHome := newTestManager(t, nil)
+	cmds.failing["gsettings"] = true
+	cmds.failing["dbus-update-activation-environment"] = true
+
+	state, err := m.Set(Update{CursorSize: intPtr(32)})
+	require.NoError(t, err)
+	assert.Contains(t, cmds.ran, "dconf write /org/gnome/desktop/interface/cursor-size 32")
+	assert.Equal(t, []string{"gsettings", "gtk-3.0", "gtk-4.0", "kdeglobals"}, state.Applied)
+	require.Len(t, state.Failed, 1)
+	assert.True(t, strings.HasPrefix(state.Failed[0], "session environment: "))
+	assert.NoFileExists(t, filepath.Join(configHome, "kdeglobals"), "nothing to write means no file")
+}
+
+func TestSet_Validation(t *testing.T) {
+	m, cmds, _ := newTestManager(t, nil)
+
+	_, err := m.Set(Update{CursorSize: intPtr(4)})
+	assert.Error(t, err)
+	_, err = m.Set(Update{TextScale: floatPtr(5)})
+	assert.Error(t, err)
+	assert.Empty(t, cmds.ran)
+	assert.Equal(t, DefaultCursorSize, m.GetState().CursorSize)
+}
+
+func TestUpdateINI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.ini")
+	require.NoError(t, os.WriteFile(path, []byte("# comment\n[Settings]\ngtk-xft-dpi=98304\ngtk-theme-name=adw-gtk3\ngtk-xft-dpi=1\n"), 0644))
+
+	require.NoError(t, updateINI(path, "Settings", map[string]string{"gtk-xft-dpi": "", "gtk-cursor-theme-size": "32"}))
+	assert.Equal(t, "# comment\n[Settings]\ngtk-theme-name=adw-gtk3\ngtk-cursor-theme-size=32\n", readFile(t, path))
+
+	require.NoError(t, updateINI(path, "Extra", map[string]string{"a": "1"}))
+	assert.Equal(t, "# comment\n[Settings]\ngtk-theme-name=adw-gtk3\ngtk-cursor-theme-size=32\n\n[Extra]\na=1\n", readFile(t, path))
+}
@@ -0,0 +1,55 @@
+package a11y
+
+import "sync"
+
+const (
+	DefaultCursorSize = 24
+	MinCursorSize     = 12
+	MaxCursorSize     = 256
+	MinTextScale      = 0.5
+	MaxTextScale      = 3.0
+)
+
+// Settings are the accessibility options the shell's panel controls
+type Settings struct {
+	CursorSize   int     `json:"cursorSize"`
+	TextScale    float64 `json:"textScale"`
+	ReduceMotion bool    `json:"reduceMotion"`
+}
+
+// Update changes only the settings that are set
+type Update struct {
+	CursorSize   *int
+	TextScale    *float64
+	ReduceMotion *bool
+}
+
+// State is the current settings and where the last change was written.
+// Failed names the places that could not be updated and why.
+type State struct {
+	Settings
+	Applied []string `json:"applied"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type Manager struct {
+	configHome string
+	getenv     func(string) string
+	// run executes a command, output also returns what it printed
+	run    func(name string, args ...string) error
+	output func(name string, args ...string) ([]byte, error)
+
+	mu      sync.Mutex
+	current Settings
+	applied []string
+	failed  []string
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+}
@@ -0,0 +1,35 @@
+package appcolor
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"strings"
+)
+
+// captureScale shrinks the capture to a thumbnail; clustering samples a
+// grid of pixels anyway, so full resolution only costs time
+const captureScale = "0.25"
+
+// captureWindow grabs the window's area of the screen with grim
+func captureWindow(w Window) (image.Image, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("grim", "-g", grimGeometry(w), "-s", captureScale, "-t", "png", "-")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("grim: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	img, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode capture: %w", err)
+	}
+	return img, nil
+}
+
+// grimGeometry is the "X,Y WxH" region grim -g takes
+func grimGeometry(w Window) string {
+	return fmt.Sprintf("%d,%d %dx%d", w.X, w.Y, w.Width, w.Height)
+}
@@ -0,0 +1,67 @@
+package appcolor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// hyprlandBackend reads the focused window from hyprctl and focus changes
+// from socket2
+type hyprlandBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newHyprlandBackend() *hyprlandBackend {
+	return &hyprlandBackend{run: compositor.Run}
+}
+
+func (h *hyprlandBackend) name() string { return "hyprland" }
+
+type hyprlandWindow struct {
+	Address string `json:"address"`
+	Class   string `json:"class"`
+	Title   string `json:"title"`
+	At      []int  `json:"at"`
+	Size    []int  `json:"size"`
+	Hidden  bool   `json:"hidden"`
+}
+
+func (h *hyprlandBackend) focused() (Window, error) {
+	out, err := h.run("hyprctl", "-j", "activewindow")
+	if err != nil {
+		return Window{}, err
+	}
+	return parseHyprlandActiveWindow(out)
+}
+
+// parseHyprlandActiveWindow reads hyprctl -j activewindow, which is {}
+// when no window has focus
+func parseHyprlandActiveWindow(data []byte) (Window, error) {
+	var hw hyprlandWindow
+	if err := json.Unmarshal(data, &hw); err != nil {
+		return Window{}, fmt.Errorf("failed to parse hyprctl output: %w", err)
+	}
+	if hw.Address == "" || hw.Hidden || len(hw.At) != 2 || len(hw.Size) != 2 {
+		return Window{}, fmt.Errorf("no focused window")
+	}
+	return Window{
+		ID:     hw.Address,
+		AppID:  hw.Class,
+		Title:  hw.Title,
+		X:      hw.At[0],
+		Y:      hw.At[1],
+		Width:  hw.Size[0],
+		Height: hw.Size[1],
+	}, nil
+}
+
+func (h *hyprlandBackend) watch(stop <-chan struct{}, changed func()) error {
+	return compositor.WatchHyprland(stop, func(event string) {
+		if strings.HasPrefix(event, "activewindowv2>>") {
+			changed()
+		}
+	})
+}
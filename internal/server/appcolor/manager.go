@@ -0,0 +1,232 @@
+package appcolor
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/dank16"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	defaultSettle   = 300 * time.Millisecond
+	watchRetryDelay = 2 * time.Second
+)
+
+// NewManager picks the compositor backend from the environment. Windows
+// are captured with grim, which uses wlr-screencopy.
+func NewManager() (*Manager, error) {
+	backend, err := detectBackend()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath("grim"); err != nil {
+		return nil, fmt.Errorf("window color sampling needs grim")
+	}
+	return newManager(backend, captureWindow), nil
+}
+
+func newManager(backend windowBackend, capture func(Window) (image.Image, error)) *Manager {
+	return &Manager{
+		backend:     backend,
+		capture:     capture,
+		settle:      defaultSettle,
+		subscribers: make(map[string]chan State),
+	}
+}
+
+func detectBackend() (windowBackend, error) {
+	switch compositor.Detect(os.Getenv) {
+	case compositor.Niri:
+		return newNiriBackend(), nil
+	case compositor.Hyprland:
+		return newHyprlandBackend(), nil
+	}
+	return nil, fmt.Errorf("window color sampling needs niri or Hyprland")
+}
+
+func (m *Manager) GetState() State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stateLocked()
+}
+
+func (m *Manager) stateLocked() State {
+	state := State{
+		Backend:   m.backend.name(),
+		Following: m.stopChan != nil,
+		Error:     m.lastError,
+	}
+	if m.current != nil {
+		sample := *m.current
+		state.Current = &sample
+	}
+	return state
+}
+
+// Sample captures the focused window and finds its dominant color
+func (m *Manager) Sample() (Sample, error) {
+	sample, err := m.sample()
+
+	m.mutex.Lock()
+	if err != nil {
+		m.lastError = err.Error()
+	} else {
+		m.current = &sample
+		m.lastError = ""
+	}
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	return sample, err
+}
+
+func (m *Manager) sample() (Sample, error) {
+	w, err := m.backend.focused()
+	if err != nil {
+		return Sample{}, err
+	}
+	if w.Width <= 0 || w.Height <= 0 {
+		return Sample{}, fmt.Errorf("no focused window")
+	}
+
+	img, err := m.capture(w)
+	if err != nil {
+		return Sample{}, err
+	}
+	palette, err := dank16.PaletteFromImage(img)
+	if err != nil {
+		return Sample{}, err
+	}
+	return Sample{
+		AppID:     w.AppID,
+		Title:     w.Title,
+		Color:     palette.Accent,
+		Swatches:  palette.Swatches,
+		SampledAt: time.Now(),
+	}, nil
+}
+
+// Follow turns sampling on focus changes on or off
+func (m *Manager) Follow(enabled bool) State {
+	m.mutex.Lock()
+	switch {
+	case enabled && m.stopChan == nil:
+		m.stopChan = make(chan struct{})
+		go m.watchLoop(m.stopChan)
+	case !enabled && m.stopChan != nil:
+		m.stopFollowLocked()
+	}
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	if enabled {
+		go m.focusChanged()
+	}
+	return state
+}
+
+// stopFollowLocked ends the watch and drops a sample that is still waiting
+// for the window to settle. Callers hold mutex.
+func (m *Manager) stopFollowLocked() {
+	close(m.stopChan)
+	m.stopChan = nil
+	if m.pending != nil {
+		m.pending.Stop()
+		m.pending = nil
+	}
+}
+
+func (m *Manager) watchLoop(stop chan struct{}) {
+	defer crash.Capture("appcolor watch", nil)
+
+	for {
+		err := m.backend.watch(stop, m.focusChanged)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err != nil {
+			log.Debugf("Window color focus stream ended: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+// focusChanged samples once the window has settled. Focus moving again
+// before then restarts the wait, so flicking through windows only samples
+// the last one.
+func (m *Manager) focusChanged() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.stopChan == nil {
+		return
+	}
+	if m.pending != nil {
+		m.pending.Stop()
+	}
+	m.pending = time.AfterFunc(m.settle, func() {
+		defer crash.Capture("appcolor sample", nil)
+		if _, err := m.Sample(); err != nil {
+			log.Debugf("Window color sample failed: %v", err)
+		}
+	})
+}
+
+func (m *Manager) broadcast(state State) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) Close() {
+	m.mutex.Lock()
+	if m.stopChan != nil {
+		m.stopFollowLocked()
+	}
+	m.mutex.Unlock()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,242 @@
+package appcolor
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	mu      sync.Mutex
+	window  Window
+	err     error
+	changed func()
+}
+
+func (f *fakeBackend) name() string { return "fake" }
+
+func (f *fakeBackend) focused() (Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.window, f.err
+}
+
+func (f *fakeBackend) watch(stop <-chan struct{}, changed func()) error {
+	f.mu.Lock()
+	f.changed = changed
+	f.mu.Unlock()
+	<-stop
+	return nil
+}
+
+func (f *fakeBackend) focus(w Window) {
+	f.mu.Lock()
+	f.window = w
+	changed := f.changed
+	f.mu.Unlock()
+	if changed != nil {
+		changed()
+	}
+}
+
+// solidCapture fills every window with the color its app id maps to and
+// counts the captures
+type solidCapture struct {
+	mu     sync.Mutex
+	colors map[string]color.RGBA
+	count  int
+}
+
+func (s *solidCapture) capture(w Window) (image.Image, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	c, ok := s.colors[w.AppID]
+	if !ok {
+		return nil, fmt.Errorf("capture failed")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img, nil
+}
+
+func (s *solidCapture) captures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+func newTestManager() (*Manager, *fakeBackend, *solidCapture) {
+	backend := &fakeBackend{}
+	capture := &solidCapture{colors: map[string]color.RGBA{
+		"firefox": {R: 0xe6, G: 0x60, B: 0x00, A: 0xff},
+		"code":    {R: 0x00, G: 0x7a, B: 0xcc, A: 0xff},
+	}}
+	m := newManager(backend, capture.capture)
+	m.settle = 20 * time.Millisecond
+	return m, backend, capture
+}
+
+func TestManager_Sample(t *testing.T) {
+	m, backend, _ := newTestManager()
+	defer m.Close()
+
+	backend.window = Window{ID: "1", AppID: "firefox", Title: "Mozilla Firefox", Width: 800, Height: 600}
+	sample, err := m.Sample()
+	require.NoError(t, err)
+	assert.Equal(t, "firefox", sample.AppID)
+	assert.Equal(t, "Mozilla Firefox", sample.Title)
+	assert.NotEmpty(t, sample.Color)
+	assert.NotEmpty(t, sample.Swatches)
+
+	state := m.GetState()
+	assert.Equal(t, "fake", state.Backend)
+	assert.False(t, state.Following)
+	require.NotNil(t, state.Current)
+	assert.Equal(t, sample.Color, state.Current.Color)
+	assert.Empty(t, state.Error)
+}
+
+func TestManager_SampleErrorKeepsLastSample(t *testing.T) {
+	m, backend, _ := newTestManager()
+	defer m.Close()
+
+	backend.window = Window{ID: "1", AppID: "firefox", Width: 800, Height: 600}
+	first, err := m.Sample()
+	require.NoError(t, err)
+
+	backend.window = Window{ID: "2", AppID: "unknown", Width: 800, Height: 600}
+	_, err = m.Sample()
+	require.Error(t, err)
+
+	state := m.GetState()
+	assert.Equal(t, "capture failed", state.Error)
+	require.NotNil(t, state.Current)
+	assert.Equal(t, first.Color, state.Current.Color)
+
+	backend.window = Window{}
+	_, err = m.Sample()
+	assert.Error(t, err, "a window without a size cannot be captured")
+}
+
+func TestManager_FollowSamplesOnFocusChange(t *testing.T) {
+	m, backend, capture := newTestManager()
+	defer m.Close()
+
+	backend.window = Window{ID: "1", AppID: "firefox", Width: 800, Height: 600}
+	ch := m.Subscribe("test")
+
+	state := m.Follow(true)
+	assert.True(t, state.Following)
+	assert.True(t, (<-ch).Following)
+
+	// Following takes a sample of the window focused right away
+	first := waitForSample(t, ch)
+	assert.Equal(t, "firefox", first.AppID)
+
+	require.Eventually(t, func() bool {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+		return backend.changed != nil
+	}, time.Second, 5*time.Millisecond)
+
+	// Flicking through windows only samples the one focus settles on
+	before := capture.captures()
+	backend.focus(Window{ID: "2", AppID: "firefox", Width: 800, Height: 600})
+	backend.focus(Window{ID: "3", AppID: "firefox", Width: 800, Height: 600})
+	backend.focus(Window{ID: "4", AppID: "code", Width: 800, Height: 600})
+	second := waitForSample(t, ch)
+	assert.Equal(t, "code", second.AppID)
+	assert.NotEqual(t, first.Color, second.Color)
+	assert.Equal(t, before+1, capture.captures())
+
+	state = m.Follow(false)
+	assert.False(t, state.Following)
+	backend.focus(Window{ID: "5", AppID: "firefox", Width: 800, Height: 600})
+	time.Sleep(3 * m.settle)
+	assert.Equal(t, "code", m.GetState().Current.AppID, "focus changes are ignored once following stops")
+}
+
+func waitForSample(t *testing.T, ch chan State) Sample {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case state := <-ch:
+			if state.Current != nil && state.Error == "" {
+				return *state.Current
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for a sample")
+		}
+	}
+}
+
+func TestManager_CloseEndsSubscriptions(t *testing.T) {
+	m, _, _ := newTestManager()
+	ch := m.Subscribe("test")
+	m.Follow(true)
+	m.Close()
+
+	assert.False(t, m.GetState().Following)
+	for range ch {
+	}
+}
+
+func TestParseHyprlandActiveWindow(t *testing.T) {
+	w, err := parseHyprlandActiveWindow([]byte(`{
+		"address": "0x55d0c4b6f0a0",
+		"at": [1930, 42],
+		"size": [1260, 1006],
+		"class": "org.gnome.Nautilus",
+		"title": "Home",
+		"hidden": false
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, Window{ID: "0x55d0c4b6f0a0", AppID: "org.gnome.Nautilus", Title: "Home", X: 1930, Y: 42, Width: 1260, Height: 1006}, w)
+
+	_, err = parseHyprlandActiveWindow([]byte(`{}`))
+	assert.Error(t, err, "hyprctl prints {} without a focused window")
+}
+
+func TestParseNiriFocusedWindow(t *testing.T) {
+	window := []byte(`{
+		"id": 12,
+		"title": "Terminal",
+		"app_id": "kitty",
+		"workspace_id": 3,
+		"layout": {
+			"window_size": [958, 1048],
+			"tile_pos_in_workspace_view": [16.0, 16.0],
+			"window_offset_in_tile": [2.0, 2.0]
+		}
+	}`)
+	workspaces := []byte(`[
+		{"id": 1, "output": "eDP-1"},
+		{"id": 3, "output": "DP-1"}
+	]`)
+	outputs := []byte(`{
+		"eDP-1": {"logical": {"x": 0, "y": 0}},
+		"DP-1": {"logical": {"x": 1920, "y": 0}}
+	}`)
+
+	w, err := parseNiriFocusedWindow(window, workspaces, outputs)
+	require.NoError(t, err)
+	assert.Equal(t, Window{ID: "12", AppID: "kitty", Title: "Terminal", X: 1938, Y: 18, Width: 958, Height: 1048}, w)
+
+	_, err = parseNiriFocusedWindow([]byte(`null`), workspaces, outputs)
+	assert.Error(t, err)
+
+	_, err = parseNiriFocusedWindow([]byte(`{"id": 12, "app_id": "kitty", "workspace_id": 3}`), workspaces, outputs)
+	assert.ErrorContains(t, err, "25.08", "older niri has no layout")
+}
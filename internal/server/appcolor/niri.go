@@ -0,0 +1,137 @@
+package appcolor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// niriBackend works out the focused window's place on screen from its
+// layout, which niri reports since 25.08, and follows focus changes on
+// the event stream
+type niriBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newNiriBackend() *niriBackend {
+	return &niriBackend{run: compositor.Run}
+}
+
+func (n *niriBackend) name() string { return "niri" }
+
+type niriWindow struct {
+	ID          uint64 `json:"id"`
+	Title       string `json:"title"`
+	AppID       string `json:"app_id"`
+	WorkspaceID uint64 `json:"workspace_id"`
+	Layout      *struct {
+		WindowSize         []float64 `json:"window_size"`
+		TilePosInWorkspace []float64 `json:"tile_pos_in_workspace_view"`
+		WindowOffsetInTile []float64 `json:"window_offset_in_tile"`
+	} `json:"layout"`
+}
+
+type niriWorkspace struct {
+	ID     uint64 `json:"id"`
+	Output string `json:"output"`
+}
+
+type niriOutput struct {
+	Logical *struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"logical"`
+}
+
+func (n *niriBackend) focused() (Window, error) {
+	window, err := n.run("niri", "msg", "--json", "focused-window")
+	if err != nil {
+		return Window{}, err
+	}
+	workspaces, err := n.run("niri", "msg", "--json", "workspaces")
+	if err != nil {
+		return Window{}, err
+	}
+	outputs, err := n.run("niri", "msg", "--json", "outputs")
+	if err != nil {
+		return Window{}, err
+	}
+	return parseNiriFocusedWindow(window, workspaces, outputs)
+}
+
+// parseNiriFocusedWindow places the window in the global layout: its
+// output's position, plus the tile's position in the workspace view, plus
+// the window's offset in the tile
+func parseNiriFocusedWindow(windowData, workspacesData, outputsData []byte) (Window, error) {
+	var nw *niriWindow
+	if err := json.Unmarshal(windowData, &nw); err != nil {
+		return Window{}, fmt.Errorf("failed to parse niri window: %w", err)
+	}
+	if nw == nil {
+		return Window{}, fmt.Errorf("no focused window")
+	}
+	if nw.Layout == nil || len(nw.Layout.WindowSize) != 2 {
+		return Window{}, fmt.Errorf("niri does not report window positions; window color sampling needs niri 25.08 or newer")
+	}
+	if len(nw.Layout.TilePosInWorkspace) != 2 {
+		return Window{}, fmt.Errorf("focused window is not on screen")
+	}
+
+	var workspaces []niriWorkspace
+	if err := json.Unmarshal(workspacesData, &workspaces); err != nil {
+		return Window{}, fmt.Errorf("failed to parse niri workspaces: %w", err)
+	}
+	var outputs map[string]niriOutput
+	if err := json.Unmarshal(outputsData, &outputs); err != nil {
+		return Window{}, fmt.Errorf("failed to parse niri outputs: %w", err)
+	}
+
+	var originX, originY int
+	for _, ws := range workspaces {
+		if ws.ID != nw.WorkspaceID {
+			continue
+		}
+		out, ok := outputs[ws.Output]
+		if !ok || out.Logical == nil {
+			return Window{}, fmt.Errorf("output %s of the focused window is not enabled", ws.Output)
+		}
+		originX, originY = out.Logical.X, out.Logical.Y
+		break
+	}
+
+	x := nw.Layout.TilePosInWorkspace[0]
+	y := nw.Layout.TilePosInWorkspace[1]
+	if len(nw.Layout.WindowOffsetInTile) == 2 {
+		x += nw.Layout.WindowOffsetInTile[0]
+		y += nw.Layout.WindowOffsetInTile[1]
+	}
+	return Window{
+		ID:     strconv.FormatUint(nw.ID, 10),
+		AppID:  nw.AppID,
+		Title:  nw.Title,
+		X:      originX + int(x),
+		Y:      originY + int(y),
+		Width:  int(nw.Layout.WindowSize[0]),
+		Height: int(nw.Layout.WindowSize[1]),
+	}, nil
+}
+
+type niriEvent struct {
+	WindowFocusChanged *struct {
+		ID *uint64 `json:"id"`
+	} `json:"WindowFocusChanged"`
+}
+
+func (n *niriBackend) watch(stop <-chan struct{}, changed func()) error {
+	return compositor.WatchNiri(stop, func(line []byte) {
+		var event niriEvent
+		if json.Unmarshal(line, &event) != nil {
+			return
+		}
+		if event.WindowFocusChanged != nil && event.WindowFocusChanged.ID != nil {
+			changed()
+		}
+	})
+}
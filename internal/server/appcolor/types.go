@@ -0,0 +1,71 @@
+package appcolor
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/dank16"
+)
+
+// Window is the focused toplevel and where it sits in the compositor's
+// logical layout
+type Window struct {
+	ID     string `json:"id"`
+	AppID  string `json:"appId"`
+	Title  string `json:"title"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Sample is the dominant color of a window's pixels. Color is the accent
+// dank16 picks from them, ready to seed a palette with.
+type Sample struct {
+	AppID     string          `json:"appId"`
+	Title     string          `json:"title"`
+	Color     string          `json:"color"`
+	Swatches  []dank16.Swatch `json:"swatches"`
+	SampledAt time.Time       `json:"sampledAt"`
+}
+
+// State is streamed to subscribers on every sample. While Following, a new
+// sample is taken whenever focus moves to another window.
+type State struct {
+	Backend   string  `json:"backend"`
+	Following bool    `json:"following"`
+	Current   *Sample `json:"current,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// windowBackend is the compositor side: it finds the focused window and
+// reports focus changes
+type windowBackend interface {
+	name() string
+	focused() (Window, error)
+	watch(stop <-chan struct{}, changed func()) error
+}
+
+type Manager struct {
+	backend windowBackend
+	capture func(w Window) (image.Image, error)
+	// settle is how long after a focus change the window is captured, so
+	// open and focus animations have finished
+	settle time.Duration
+
+	mutex     sync.Mutex
+	current   *Sample
+	lastError string
+	stopChan  chan struct{}
+	pending   *time.Timer
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+}
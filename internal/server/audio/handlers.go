@@ -0,0 +1,134 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "audio.streams.list":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "audio.streams.setVolume":
+		handleSetVolume(conn, req, manager)
+	case "audio.streams.setMute":
+		handleSetMute(conn, req, manager)
+	case "audio.streams.setSink":
+		handleSetSink(conn, req, manager)
+	case "audio.streams.forget":
+		handleForget(conn, req, manager)
+	case "audio.streams.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func streamID(req Request) (uint32, bool) {
+	value, ok := req.Params["id"].(float64)
+	if !ok || value < 0 || value != float64(uint32(value)) {
+		return 0, false
+	}
+	return uint32(value), true
+}
+
+func handleSetVolume(conn net.Conn, req Request, manager *Manager) {
+	id, ok := streamID(req)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+	volume, ok := req.Params["volume"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'volume' parameter")
+		return
+	}
+
+	state, err := manager.SetVolume(id, volume)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSetMute(conn net.Conn, req Request, manager *Manager) {
+	id, ok := streamID(req)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+	muted, ok := req.Params["muted"].(bool)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'muted' parameter")
+		return
+	}
+
+	state, err := manager.SetMute(id, muted)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSetSink(conn net.Conn, req Request, manager *Manager) {
+	id, ok := streamID(req)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+	sink, ok := req.Params["sink"].(string)
+	if !ok || sink == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'sink' parameter")
+		return
+	}
+
+	state, err := manager.SetSink(id, sink)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleForget(conn net.Conn, req Request, manager *Manager) {
+	app, ok := req.Params["app"].(string)
+	if !ok || app == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'app' parameter")
+		return
+	}
+
+	state, err := manager.Forget(app)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
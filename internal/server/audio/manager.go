@@ -0,0 +1,382 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const watchRetryDelay = 2 * time.Second
+
+// NewManager lists the current streams and follows the sound server for
+// new ones. Streams playing when the daemon starts are left alone; routes
+// are restored on streams that appear afterwards.
+func NewManager() (*Manager, error) {
+	backend, err := newPactlBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+
+	m := newManager(backend, filepath.Join(stateHome, "DankMaterialShell", "audio-routes.json"))
+	m.load()
+	if err := m.refresh(false); err != nil {
+		return nil, err
+	}
+
+	go m.refreshLoop()
+	go m.watchLoop()
+
+	return m, nil
+}
+
+func newManager(backend streamBackend, routePath string) *Manager {
+	return &Manager{
+		backend:     backend,
+		routePath:   routePath,
+		routes:      make(map[string]Route),
+		known:       make(map[uint32]bool),
+		subscribers: make(map[string]chan State),
+		refreshChan: make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+func (m *Manager) load() {
+	data, err := os.ReadFile(m.routePath)
+	if err != nil {
+		return
+	}
+
+	var routes map[string]Route
+	if err := json.Unmarshal(data, &routes); err != nil {
+		log.Warnf("Ignoring corrupt audio routes file %s: %v", m.routePath, err)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for app, r := range routes {
+		m.routes[app] = r
+	}
+}
+
+// save must be called with the mutex held
+func (m *Manager) save() {
+	data, err := json.MarshalIndent(m.routes, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.routePath), 0755); err != nil {
+		log.Warnf("Failed to create %s: %v", filepath.Dir(m.routePath), err)
+		return
+	}
+	tmp := m.routePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Warnf("Failed to save audio routes: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, m.routePath); err != nil {
+		log.Warnf("Failed to save audio routes: %v", err)
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stateLocked()
+}
+
+func (m *Manager) stateLocked() State {
+	routes := make(map[string]Route, len(m.routes))
+	for app, r := range m.routes {
+		routes[app] = r
+	}
+	return State{
+		Streams: append([]Stream{}, m.streams...),
+		Sinks:   append([]Sink{}, m.sinks...),
+		Routes:  routes,
+	}
+}
+
+// requestRefresh coalesces bursts of events, such as a volume slider being
+// dragged, into one listing
+func (m *Manager) requestRefresh() {
+	select {
+	case m.refreshChan <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) refreshLoop() {
+	defer crash.Capture("audio refresh", nil)
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.refreshChan:
+			if err := m.refresh(true); err != nil {
+				log.Debugf("Failed to list audio streams: %v", err)
+			}
+		}
+	}
+}
+
+func (m *Manager) watchLoop() {
+	defer crash.Capture("audio watch", nil)
+
+	for {
+		err := m.backend.watch(m.stopChan, m.requestRefresh)
+		select {
+		case <-m.stopChan:
+			return
+		default:
+		}
+		if err != nil {
+			log.Debugf("Audio event stream ended: %v", err)
+		}
+
+		select {
+		case <-m.stopChan:
+			return
+		case <-time.After(watchRetryDelay):
+		}
+		// The sound server may have restarted with everything renumbered
+		m.requestRefresh()
+	}
+}
+
+// refresh re-lists streams and sinks, restoring the route of every stream
+// not seen before when restore is set, and notifies subscribers of changes
+func (m *Manager) refresh(restore bool) error {
+	state, changed, err := m.update(restore)
+	if err != nil {
+		return err
+	}
+	if changed {
+		m.broadcast(state)
+	}
+	return nil
+}
+
+func (m *Manager) update(restore bool) (State, bool, error) {
+	streams, sinks, err := m.backend.list()
+	if err != nil {
+		return State{}, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	present := make(map[uint32]bool, len(streams))
+	for i := range streams {
+		s := &streams[i]
+		present[s.ID] = true
+		if m.known[s.ID] {
+			continue
+		}
+		m.known[s.ID] = true
+		if route, ok := m.routes[s.App]; ok && restore {
+			m.restore(s, route, sinks)
+		}
+	}
+	for id := range m.known {
+		if !present[id] {
+			delete(m.known, id)
+		}
+	}
+
+	changed := !reflect.DeepEqual(streams, m.streams) || !reflect.DeepEqual(sinks, m.sinks)
+	m.streams = streams
+	m.sinks = sinks
+	return m.stateLocked(), changed, nil
+}
+
+// restore applies route to a new stream and updates s to match. A sink
+// that isn't connected right now is skipped so the stream still plays.
+func (m *Manager) restore(s *Stream, route Route, sinks []Sink) {
+	if route.Sink != "" && route.Sink != s.Sink && hasSink(sinks, route.Sink) {
+		if err := m.backend.move(s.ID, route.Sink); err != nil {
+			log.Warnf("Failed to move %s to %s: %v", s.App, route.Sink, err)
+		} else {
+			s.Sink = route.Sink
+		}
+	}
+	if route.Volume != nil && *route.Volume != s.Volume {
+		if err := m.backend.setVolume(s.ID, *route.Volume); err != nil {
+			log.Warnf("Failed to restore volume of %s: %v", s.App, err)
+		} else {
+			s.Volume = *route.Volume
+		}
+	}
+	if route.Muted != nil && *route.Muted != s.Muted {
+		if err := m.backend.setMute(s.ID, *route.Muted); err != nil {
+			log.Warnf("Failed to restore mute of %s: %v", s.App, err)
+		} else {
+			s.Muted = *route.Muted
+		}
+	}
+}
+
+func hasSink(sinks []Sink, name string) bool {
+	for _, s := range sinks {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// stream finds a current stream by ID
+func (m *Manager) stream(id uint32) (Stream, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.streams {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return Stream{}, fmt.Errorf("no audio stream %d", id)
+}
+
+// remember records a change for app and persists it
+func (m *Manager) remember(app string, update func(*Route)) {
+	m.mu.Lock()
+	route := m.routes[app]
+	update(&route)
+	m.routes[app] = route
+	m.save()
+	m.mu.Unlock()
+}
+
+// SetVolume changes a stream's volume and remembers it for the application
+func (m *Manager) SetVolume(id uint32, volume float64) (State, error) {
+	if volume < 0 || volume > MaxVolume {
+		return State{}, fmt.Errorf("volume must be between 0 and %.1f", MaxVolume)
+	}
+	s, err := m.stream(id)
+	if err != nil {
+		return State{}, err
+	}
+	if err := m.backend.setVolume(id, volume); err != nil {
+		return State{}, err
+	}
+	m.remember(s.App, func(r *Route) { r.Volume = &volume })
+	return m.afterChange()
+}
+
+// SetMute mutes or unmutes a stream and remembers it for the application
+func (m *Manager) SetMute(id uint32, muted bool) (State, error) {
+	s, err := m.stream(id)
+	if err != nil {
+		return State{}, err
+	}
+	if err := m.backend.setMute(id, muted); err != nil {
+		return State{}, err
+	}
+	m.remember(s.App, func(r *Route) { r.Muted = &muted })
+	return m.afterChange()
+}
+
+// SetSink moves a stream to another output and remembers it for the
+// application
+func (m *Manager) SetSink(id uint32, sink string) (State, error) {
+	s, err := m.stream(id)
+	if err != nil {
+		return State{}, err
+	}
+	m.mu.Lock()
+	known := hasSink(m.sinks, sink)
+	m.mu.Unlock()
+	if !known {
+		return State{}, fmt.Errorf("no output device %q", sink)
+	}
+	if err := m.backend.move(id, sink); err != nil {
+		return State{}, err
+	}
+	m.remember(s.App, func(r *Route) { r.Sink = sink })
+	return m.afterChange()
+}
+
+// Forget drops what was remembered for app. Its streams stay as they are.
+func (m *Manager) Forget(app string) (State, error) {
+	m.mu.Lock()
+	if _, ok := m.routes[app]; !ok {
+		m.mu.Unlock()
+		return State{}, fmt.Errorf("nothing remembered for %q", app)
+	}
+	delete(m.routes, app)
+	m.save()
+	state := m.stateLocked()
+	m.mu.Unlock()
+
+	m.broadcast(state)
+	return state, nil
+}
+
+// afterChange re-lists so the result shows what the sound server applied.
+// Subscribers are always told, as the remembered routes changed.
+func (m *Manager) afterChange() (State, error) {
+	state, _, err := m.update(true)
+	if err != nil {
+		log.Debugf("Failed to list audio streams: %v", err)
+		state = m.GetState()
+	}
+	m.broadcast(state)
+	return state, nil
+}
+
+func (m *Manager) broadcast(state State) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) Close() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
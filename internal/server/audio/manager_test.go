@@ -0,0 +1,205 @@
+package audio
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	mu      sync.Mutex
+	streams []Stream
+	sinks   []Sink
+	calls   []string
+}
+
+func (f *fakeBackend) list() ([]Stream, []Sink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Stream(nil), f.streams...), append([]Sink(nil), f.sinks...), nil
+}
+
+func (f *fakeBackend) find(id uint32) *Stream {
+	for i := range f.streams {
+		if f.streams[i].ID == id {
+			return &f.streams[i]
+		}
+	}
+	return nil
+}
+
+func (f *fakeBackend) setVolume(id uint32, volume float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fmt.Sprintf("volume %d %.2f", id, volume))
+	f.find(id).Volume = volume
+	return nil
+}
+
+func (f *fakeBackend) setMute(id uint32, muted bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fmt.Sprintf("mute %d %v", id, muted))
+	f.find(id).Muted = muted
+	return nil
+}
+
+func (f *fakeBackend) move(id uint32, sink string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fmt.Sprintf("move %d %s", id, sink))
+	f.find(id).Sink = sink
+	return nil
+}
+
+func (f *fakeBackend) watch(stop <-chan struct{}, changed func()) error {
+	<-stop
+	return nil
+}
+
+func (f *fakeBackend) add(s Stream) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams = append(f.streams, s)
+}
+
+func newFake() *fakeBackend {
+	return &fakeBackend{
+		sinks: []Sink{
+			{Name: "speakers", Default: true},
+			{Name: "headphones"},
+		},
+	}
+}
+
+func TestManager_RememberAndRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audio-routes.json")
+	backend := newFake()
+	backend.add(Stream{ID: 1, App: "spotify", Sink: "speakers", Volume: 1})
+
+	m := newManager(backend, path)
+	require.NoError(t, m.refresh(false))
+
+	_, err := m.SetSink(1, "headphones")
+	require.NoError(t, err)
+	state, err := m.SetVolume(1, 0.4)
+	require.NoError(t, err)
+	assert.Equal(t, "headphones", state.Streams[0].Sink)
+	assert.Equal(t, "headphones", state.Routes["spotify"].Sink)
+	assert.Equal(t, 0.4, *state.Routes["spotify"].Volume)
+	assert.Nil(t, state.Routes["spotify"].Muted, "mute was never changed")
+
+	// A fresh daemon reads the routes back and applies them to the next
+	// stream Spotify opens
+	backend = newFake()
+	m = newManager(backend, path)
+	m.load()
+	require.NoError(t, m.refresh(false))
+
+	backend.add(Stream{ID: 7, App: "spotify", Sink: "speakers", Volume: 1})
+	backend.add(Stream{ID: 8, App: "firefox", Sink: "speakers", Volume: 1})
+	require.NoError(t, m.refresh(true))
+
+	assert.Equal(t, []string{"move 7 headphones", "volume 7 0.40"}, backend.calls)
+	state = m.GetState()
+	require.Len(t, state.Streams, 2)
+	assert.Equal(t, "headphones", state.Streams[0].Sink)
+	assert.Equal(t, 0.4, state.Streams[0].Volume)
+
+	// Restored once, not on every refresh
+	require.NoError(t, m.refresh(true))
+	assert.Len(t, backend.calls, 2)
+}
+
+func TestManager_StartupStreamsLeftAlone(t *testing.T) {
+	backend := newFake()
+	backend.add(Stream{ID: 3, App: "mpv", Sink: "speakers", Volume: 1})
+
+	m := newManager(backend, filepath.Join(t.TempDir(), "audio-routes.json"))
+	muted := true
+	m.routes["mpv"] = Route{Muted: &muted}
+
+	require.NoError(t, m.refresh(false))
+	require.NoError(t, m.refresh(true))
+	assert.Empty(t, backend.calls)
+}
+
+func TestManager_MissingSinkSkipped(t *testing.T) {
+	backend := newFake()
+	m := newManager(backend, filepath.Join(t.TempDir(), "audio-routes.json"))
+	m.routes["spotify"] = Route{Sink: "bluetooth-buds"}
+
+	backend.add(Stream{ID: 4, App: "spotify", Sink: "speakers", Volume: 1})
+	require.NoError(t, m.refresh(true))
+	assert.Empty(t, backend.calls, "unplugged device keeps the stream where it is")
+	assert.Equal(t, "bluetooth-buds", m.GetState().Routes["spotify"].Sink, "route kept for when it returns")
+}
+
+func TestManager_Validation(t *testing.T) {
+	backend := newFake()
+	backend.add(Stream{ID: 1, App: "spotify", Sink: "speakers", Volume: 1})
+	m := newManager(backend, filepath.Join(t.TempDir(), "audio-routes.json"))
+	require.NoError(t, m.refresh(false))
+
+	_, err := m.SetVolume(1, 2)
+	assert.Error(t, err)
+	_, err = m.SetVolume(99, 0.5)
+	assert.Error(t, err)
+	_, err = m.SetSink(1, "nowhere")
+	assert.Error(t, err)
+	_, err = m.Forget("spotify")
+	assert.Error(t, err, "nothing remembered yet")
+
+	_, err = m.SetMute(1, true)
+	require.NoError(t, err)
+	state, err := m.Forget("spotify")
+	require.NoError(t, err)
+	assert.Empty(t, state.Routes)
+	assert.True(t, state.Streams[0].Muted, "forgetting leaves the stream as it is")
+	assert.Empty(t, backend.calls[1:])
+}
+
+func TestParsePactl(t *testing.T) {
+	info := []byte(`{"default_sink_name":"alsa_output.pci"}`)
+	sinks := []byte(`[
+		{"index":56,"name":"alsa_output.pci","description":"Speakers"},
+		{"index":71,"name":"bluez_output.buds","description":"Buds"}
+	]`)
+	inputs := []byte(`[
+		{"index":42,"sink":71,"mute":false,
+		 "volume":{"front-left":{"value":32768},"front-right":{"value":32768}},
+		 "properties":{"application.name":"Spotify","application.process.binary":"spotify","media.name":"Song","application.icon_name":"spotify-client"}},
+		{"index":43,"sink":56,"mute":true,"volume":{"mono":{"value":65536}},
+		 "properties":{"application.name":"Chromium"}},
+		{"index":44,"sink":56,"mute":false,"volume":{},
+		 "properties":{"application.name":"bell","media.role":"event"}}
+	]`)
+
+	streams, parsedSinks, err := parsePactl(info, sinks, inputs)
+	require.NoError(t, err)
+
+	require.Len(t, parsedSinks, 2)
+	assert.True(t, parsedSinks[0].Default)
+	assert.False(t, parsedSinks[1].Default)
+
+	require.Len(t, streams, 2, "event sounds are skipped")
+	assert.Equal(t, Stream{ID: 42, App: "spotify", Name: "Spotify", Media: "Song", Icon: "spotify-client", Sink: "bluez_output.buds", Volume: 0.5}, streams[0])
+	assert.Equal(t, "chromium", streams[1].App, "falls back to the application name")
+	assert.Equal(t, 1.0, streams[1].Volume)
+	assert.True(t, streams[1].Muted)
+
+	_, _, err = parsePactl([]byte("not json"), sinks, inputs)
+	assert.Error(t, err)
+}
+
+func TestIsStreamEvent(t *testing.T) {
+	assert.True(t, isStreamEvent("Event 'new' on sink-input #42"))
+	assert.True(t, isStreamEvent("Event 'change' on sink #56"))
+	assert.True(t, isStreamEvent("Event 'change' on server #4294967295"))
+	assert.False(t, isStreamEvent("Event 'change' on source-output #3"))
+	assert.False(t, isStreamEvent("Event 'new' on client #80"))
+}
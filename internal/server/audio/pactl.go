@@ -0,0 +1,200 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// pactlBackend drives PulseAudio, or PipeWire through pipewire-pulse, with
+// pactl. JSON output needs pactl 16 or newer.
+type pactlBackend struct {
+	run func(args ...string) ([]byte, error)
+}
+
+func newPactlBackend() (*pactlBackend, error) {
+	if _, err := exec.LookPath("pactl"); err != nil {
+		return nil, fmt.Errorf("per-application audio needs pactl (PulseAudio or pipewire-pulse)")
+	}
+	return &pactlBackend{run: runPactl}, nil
+}
+
+func runPactl(args ...string) ([]byte, error) {
+	out, err := exec.Command("pactl", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return out, fmt.Errorf("pactl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return out, fmt.Errorf("pactl %s: %w", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+type pactlVolume map[string]struct {
+	Value int `json:"value"`
+}
+
+type pactlSink struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type pactlSinkInput struct {
+	Index      uint32            `json:"index"`
+	Sink       int               `json:"sink"`
+	Mute       bool              `json:"mute"`
+	Volume     pactlVolume       `json:"volume"`
+	Properties map[string]string `json:"properties"`
+}
+
+func (p *pactlBackend) list() ([]Stream, []Sink, error) {
+	info, err := p.run("-f", "json", "info")
+	if err != nil {
+		return nil, nil, err
+	}
+	sinkData, err := p.run("-f", "json", "list", "sinks")
+	if err != nil {
+		return nil, nil, err
+	}
+	inputData, err := p.run("-f", "json", "list", "sink-inputs")
+	if err != nil {
+		return nil, nil, err
+	}
+	return parsePactl(info, sinkData, inputData)
+}
+
+// parsePactl turns pactl's info, sink and sink-input listings into streams
+// and sinks. Sink inputs refer to sinks by index, which is resolved to the
+// name since indexes change when a device comes back.
+func parsePactl(info, sinkData, inputData []byte) ([]Stream, []Sink, error) {
+	var server struct {
+		DefaultSink string `json:"default_sink_name"`
+	}
+	if err := json.Unmarshal(info, &server); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse pactl info: %w", err)
+	}
+
+	var rawSinks []pactlSink
+	if err := json.Unmarshal(sinkData, &rawSinks); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse pactl sinks: %w", err)
+	}
+	sinks := make([]Sink, 0, len(rawSinks))
+	sinkNames := make(map[int]string, len(rawSinks))
+	for _, s := range rawSinks {
+		sinkNames[s.Index] = s.Name
+		sinks = append(sinks, Sink{Name: s.Name, Description: s.Description, Default: s.Name == server.DefaultSink})
+	}
+
+	var inputs []pactlSinkInput
+	if err := json.Unmarshal(inputData, &inputs); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse pactl sink inputs: %w", err)
+	}
+	streams := make([]Stream, 0, len(inputs))
+	for _, in := range inputs {
+		props := in.Properties
+		// Event sounds come and go too quickly to route
+		if props["media.role"] == "event" {
+			continue
+		}
+		name := props["application.name"]
+		app := props["application.process.binary"]
+		if app == "" {
+			app = strings.ToLower(name)
+		}
+		if app == "" {
+			continue
+		}
+
+		streams = append(streams, Stream{
+			ID:     in.Index,
+			App:    app,
+			Name:   name,
+			Media:  props["media.name"],
+			Icon:   props["application.icon_name"],
+			Sink:   sinkNames[in.Sink],
+			Volume: in.Volume.average(),
+			Muted:  in.Mute,
+		})
+	}
+	return streams, sinks, nil
+}
+
+// average is the mean of the channels, 1 being 100%
+func (v pactlVolume) average() float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	total := 0
+	for _, ch := range v {
+		total += ch.Value
+	}
+	return math.Round(float64(total)/float64(len(v))/65536*100) / 100
+}
+
+func (p *pactlBackend) setVolume(id uint32, volume float64) error {
+	_, err := p.run("set-sink-input-volume", strconv.FormatUint(uint64(id), 10), fmt.Sprintf("%d%%", int(math.Round(volume*100))))
+	return err
+}
+
+func (p *pactlBackend) setMute(id uint32, muted bool) error {
+	value := "0"
+	if muted {
+		value = "1"
+	}
+	_, err := p.run("set-sink-input-mute", strconv.FormatUint(uint64(id), 10), value)
+	return err
+}
+
+func (p *pactlBackend) move(id uint32, sink string) error {
+	_, err := p.run("move-sink-input", strconv.FormatUint(uint64(id), 10), sink)
+	return err
+}
+
+// watch follows `pactl subscribe`, which prints a line per event such as
+// "Event 'new' on sink-input #42"
+func (p *pactlBackend) watch(stop <-chan struct{}, changed func()) error {
+	cmd := exec.Command("pactl", "subscribe")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start pactl subscribe: %w", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			cmd.Process.Kill()
+		case <-done:
+		}
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if isStreamEvent(scanner.Text()) {
+			changed()
+		}
+	}
+
+	cmd.Process.Kill()
+	cmd.Wait()
+	return scanner.Err()
+}
+
+// isStreamEvent matches sink-input and sink events, and server changes
+// which is how a new default sink shows up
+func isStreamEvent(line string) bool {
+	_, target, ok := strings.Cut(line, " on ")
+	if !ok {
+		return false
+	}
+	return strings.HasPrefix(target, "sink-input ") || strings.HasPrefix(target, "sink ") || strings.HasPrefix(target, "server")
+}
@@ -0,0 +1,78 @@
+package audio
+
+import "sync"
+
+// MaxVolume is the loudest a stream may be set, 1 being 100%. PulseAudio
+// allows more but past 150% everything clips.
+const MaxVolume = 1.5
+
+// Stream is one application playing audio, a PulseAudio sink input. App is
+// the name its settings are remembered under: the process binary, or the
+// application name when the client doesn't report one.
+type Stream struct {
+	ID     uint32  `json:"id"`
+	App    string  `json:"app"`
+	Name   string  `json:"name"`
+	Media  string  `json:"media,omitempty"`
+	Icon   string  `json:"icon,omitempty"`
+	Sink   string  `json:"sink"`
+	Volume float64 `json:"volume"`
+	Muted  bool    `json:"muted"`
+}
+
+// Sink is an output device streams can be routed to
+type Sink struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// Route is what was last chosen for an application, restored whenever one
+// of its streams appears. Unset fields are left to the sound server.
+type Route struct {
+	Volume *float64 `json:"volume,omitempty"`
+	Muted  *bool    `json:"muted,omitempty"`
+	Sink   string   `json:"sink,omitempty"`
+}
+
+type State struct {
+	Streams []Stream         `json:"streams"`
+	Sinks   []Sink           `json:"sinks"`
+	Routes  map[string]Route `json:"routes"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// streamBackend talks to the sound server
+type streamBackend interface {
+	list() ([]Stream, []Sink, error)
+	setVolume(id uint32, volume float64) error
+	setMute(id uint32, muted bool) error
+	move(id uint32, sink string) error
+	// watch calls changed whenever streams or sinks may have changed,
+	// until stop is closed or the event source goes away
+	watch(stop <-chan struct{}, changed func()) error
+}
+
+type Manager struct {
+	backend   streamBackend
+	routePath string
+
+	mu      sync.Mutex
+	streams []Stream
+	sinks   []Sink
+	routes  map[string]Route
+	// known holds the streams already restored, or present at startup
+	known map[uint32]bool
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+
+	refreshChan chan struct{}
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
@@ -2,8 +2,12 @@ package bluez
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
 )
 
 func TestSubscriptionBrokerAskWait(t *testing.T) {
@@ -218,3 +222,92 @@ func TestSubscriptionBrokerMultipleRequests(t *testing.T) {
 		t.Errorf("expected passkey=567890, got %s", reply2.Secrets["passkey"])
 	}
 }
+
+func newRelayedBroker(t *testing.T) (*SubscriptionBroker, *prompts.Manager) {
+	t.Helper()
+	relay, err := prompts.NewManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker := NewSubscriptionBroker(nil).(*SubscriptionBroker)
+	broker.SetRelay(relay)
+	return broker, relay
+}
+
+func TestSubscriptionBrokerRelayRespond(t *testing.T) {
+	broker, relay := newRelayedBroker(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	token, err := broker.Ask(ctx, PromptRequest{
+		DeviceName:  "Headphones",
+		DeviceAddr:  "AA:BB:CC:DD:EE:FF",
+		RequestType: "pin",
+		Fields:      []string{"pin"},
+	})
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+
+	pending := relay.Pending()
+	if len(pending) != 1 || pending[0].Token != token {
+		t.Fatalf("expected the prompt to be filed with the relay, got %+v", pending)
+	}
+	if pending[0].Source != "bluetooth" || pending[0].Kind != prompts.KindPairing {
+		t.Errorf("unexpected source/kind %s/%s", pending[0].Source, pending[0].Kind)
+	}
+	if pending[0].Details["deviceAddr"] != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("expected device address in details, got %v", pending[0].Details)
+	}
+
+	go relay.Respond(token, prompts.Response{Action: prompts.ActionAllow, Secrets: map[string]string{"pin": "0000"}})
+
+	reply, err := broker.Wait(ctx, token)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if !reply.Accept || reply.Secrets["pin"] != "0000" {
+		t.Errorf("unexpected reply %+v", reply)
+	}
+}
+
+func TestSubscriptionBrokerRelayLegacyResolve(t *testing.T) {
+	broker, relay := newRelayedBroker(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	token, err := broker.Ask(ctx, PromptRequest{DeviceName: "Keyboard", RequestType: "pin", Fields: []string{"pin"}})
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+
+	go broker.Resolve(token, PromptReply{Cancel: true})
+
+	if _, err := broker.Wait(ctx, token); !errors.Is(err, errdefs.ErrSecretPromptCancelled) {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+	if len(relay.Pending()) != 0 {
+		t.Error("expected the relayed prompt to be resolved")
+	}
+}
+
+func TestSubscriptionBrokerRelayAllowIsDecision(t *testing.T) {
+	broker, relay := newRelayedBroker(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	token, err := broker.Ask(ctx, PromptRequest{DeviceName: "Phone", RequestType: "authorize", Fields: []string{"decision"}})
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+
+	go relay.Respond(token, prompts.Response{Action: prompts.ActionAllow})
+
+	reply, err := broker.Wait(ctx, token)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if reply.Secrets["decision"] != "yes" {
+		t.Errorf("expected allow to answer the decision, got %v", reply.Secrets)
+	}
+}
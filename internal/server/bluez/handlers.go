@@ -252,6 +252,7 @@ func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
 			Data: state,
 		}
 		if err := json.NewEncoder(conn).Encode(models.Response[BluetoothEvent]{
+			ID:     req.ID,
 			Result: &event,
 		}); err != nil {
 			return
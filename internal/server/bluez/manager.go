@@ -6,7 +6,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
+	"github.com/AvengeMedia/danklinux/internal/utils"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -69,10 +72,10 @@ func NewManager() (*Manager, error) {
 	}
 
 	m.notifierWg.Add(1)
-	go m.notifier()
+	crash.Go("bluez.notifier", m.notifier)
 
 	m.eventWg.Add(1)
-	go m.eventWorker()
+	crash.Go("bluez.eventWorker", m.eventWorker)
 
 	return m, nil
 }
@@ -273,7 +276,7 @@ func (m *Manager) startSignalPump() error {
 	}
 
 	m.sigWG.Add(1)
-	go func() {
+	crash.Go("bluez.signals", func() {
 		defer m.sigWG.Done()
 		for {
 			select {
@@ -289,7 +292,7 @@ func (m *Manager) startSignalPump() error {
 				m.handleSignal(sig)
 			}
 		}
-	}()
+	})
 
 	return nil
 }
@@ -528,6 +531,13 @@ func (m *Manager) broadcastPairingPrompt(prompt PairingPrompt) {
 	}
 }
 
+// SetPromptRelay files pairing prompts with the daemon's prompts module too
+func (m *Manager) SetPromptRelay(relay *prompts.Manager) {
+	if broker, ok := m.promptBroker.(*SubscriptionBroker); ok {
+		broker.SetRelay(relay)
+	}
+}
+
 func (m *Manager) SubmitPairing(token string, secrets map[string]string, accept bool) error {
 	if m.promptBroker == nil {
 		return fmt.Errorf("prompt broker not initialized")
@@ -666,3 +676,8 @@ func stateChanged(old, new *BluetoothState) bool {
 	}
 	return false
 }
+
+// HealthCheck reports whether bluetoothd still answers on our connection
+func (m *Manager) HealthCheck() error {
+	return utils.PingDBus(m.dbusConn, bluezService)
+}
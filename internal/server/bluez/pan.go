@@ -0,0 +1,124 @@
+package bluez
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	network1Iface = "org.bluez.Network1"
+	// napUUID is the profile a phone offers while Bluetooth tethering is on
+	napUUID = "00001116-0000-1000-8000-00805f9b34fb"
+)
+
+// PANDevice is a paired device that can share its connection over
+// Bluetooth PAN. Interface is the bnep interface while connected.
+type PANDevice struct {
+	Path      string `json:"path"`
+	Address   string `json:"address"`
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// panDevicesFromObjects picks the paired PAN-capable devices of the
+// adapter at adapterPath out of GetManagedObjects
+func panDevicesFromObjects(adapterPath dbus.ObjectPath, objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant) []PANDevice {
+	devices := []PANDevice{}
+	for path, interfaces := range objects {
+		if !strings.HasPrefix(string(path), string(adapterPath)+"/") {
+			continue
+		}
+		devProps, ok := interfaces[device1Iface]
+		if !ok {
+			continue
+		}
+		netProps, ok := interfaces[network1Iface]
+		if !ok {
+			continue
+		}
+		if paired, _ := devProps["Paired"].Value().(bool); !paired {
+			continue
+		}
+		uuids, _ := devProps["UUIDs"].Value().([]string)
+		offersNAP := false
+		for _, uuid := range uuids {
+			if strings.EqualFold(uuid, napUUID) {
+				offersNAP = true
+				break
+			}
+		}
+		if !offersNAP {
+			continue
+		}
+
+		d := PANDevice{Path: string(path)}
+		d.Address, _ = devProps["Address"].Value().(string)
+		d.Name, _ = devProps["Alias"].Value().(string)
+		if d.Name == "" {
+			d.Name, _ = devProps["Name"].Value().(string)
+		}
+		if d.Name == "" {
+			d.Name = d.Address
+		}
+		d.Connected, _ = netProps["Connected"].Value().(bool)
+		if d.Connected {
+			d.Interface, _ = netProps["Interface"].Value().(string)
+		}
+		devices = append(devices, d)
+	}
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Address < devices[j].Address })
+	return devices
+}
+
+// PANDevices lists paired phones offering Bluetooth tethering
+func (m *Manager) PANDevices() ([]PANDevice, error) {
+	obj := m.dbusConn.Object(bluezService, dbus.ObjectPath("/"))
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	if err := obj.Call(objectMgrIface+".GetManagedObjects", 0).Store(&objects); err != nil {
+		return nil, err
+	}
+	return panDevicesFromObjects(m.adapterPath, objects), nil
+}
+
+func (m *Manager) findPANDevice(address string) (PANDevice, error) {
+	devices, err := m.PANDevices()
+	if err != nil {
+		return PANDevice{}, err
+	}
+	for _, d := range devices {
+		if strings.EqualFold(d.Address, address) {
+			return d, nil
+		}
+	}
+	return PANDevice{}, fmt.Errorf("%s is not a paired device offering tethering", address)
+}
+
+// ConnectPAN joins the network of the phone at address and returns the
+// interface bluez created for it
+func (m *Manager) ConnectPAN(address string) (string, error) {
+	d, err := m.findPANDevice(address)
+	if err != nil {
+		return "", err
+	}
+
+	var iface string
+	obj := m.dbusConn.Object(bluezService, dbus.ObjectPath(d.Path))
+	if err := obj.Call(network1Iface+".Connect", 0, "nap").Store(&iface); err != nil {
+		return "", err
+	}
+	return iface, nil
+}
+
+func (m *Manager) DisconnectPAN(address string) error {
+	d, err := m.findPANDevice(address)
+	if err != nil {
+		return err
+	}
+	obj := m.dbusConn.Object(bluezService, dbus.ObjectPath(d.Path))
+	return obj.Call(network1Iface+".Disconnect", 0).Err
+}
@@ -0,0 +1,63 @@
+package bluez
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestPANDevicesFromObjects(t *testing.T) {
+	device := func(address, alias string, paired bool, uuids []string) map[string]dbus.Variant {
+		return map[string]dbus.Variant{
+			"Address": dbus.MakeVariant(address),
+			"Alias":   dbus.MakeVariant(alias),
+			"Paired":  dbus.MakeVariant(paired),
+			"UUIDs":   dbus.MakeVariant(uuids),
+		}
+	}
+	network := func(connected bool, iface string) map[string]dbus.Variant {
+		return map[string]dbus.Variant{
+			"Connected": dbus.MakeVariant(connected),
+			"Interface": dbus.MakeVariant(iface),
+		}
+	}
+	nap := []string{"0000110a-0000-1000-8000-00805f9b34fb", "00001116-0000-1000-8000-00805F9B34FB"}
+
+	objects := map[dbus.ObjectPath]map[string]map[string]dbus.Variant{
+		"/org/bluez/hci0": {adapter1Iface: {}},
+		"/org/bluez/hci0/dev_22": {
+			device1Iface:  device("22:22:22:22:22:22", "Pixel", true, nap),
+			network1Iface: network(true, "bnep0"),
+		},
+		"/org/bluez/hci0/dev_11": {
+			device1Iface:  device("11:11:11:11:11:11", "", true, nap),
+			network1Iface: network(false, ""),
+		},
+		// Not paired
+		"/org/bluez/hci0/dev_33": {
+			device1Iface:  device("33:33:33:33:33:33", "Stranger", false, nap),
+			network1Iface: network(false, ""),
+		},
+		// Headphones offer no access point
+		"/org/bluez/hci0/dev_44": {
+			device1Iface: device("44:44:44:44:44:44", "Headphones", true, []string{"0000110b-0000-1000-8000-00805f9b34fb"}),
+		},
+		// Another adapter
+		"/org/bluez/hci1/dev_55": {
+			device1Iface:  device("55:55:55:55:55:55", "Other", true, nap),
+			network1Iface: network(false, ""),
+		},
+	}
+
+	devices := panDevicesFromObjects("/org/bluez/hci0", objects)
+	if len(devices) != 2 {
+		t.Fatalf("expected 2 devices, got %+v", devices)
+	}
+
+	if d := devices[0]; d.Address != "11:11:11:11:11:11" || d.Name != d.Address || d.Connected || d.Interface != "" {
+		t.Errorf("unexpected first device %+v", d)
+	}
+	if d := devices[1]; d.Name != "Pixel" || !d.Connected || d.Interface != "bnep0" || d.Path != "/org/bluez/hci0/dev_22" {
+		t.Errorf("unexpected second device %+v", d)
+	}
+}
@@ -3,9 +3,13 @@ package bluez
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
 )
 
 type SubscriptionBroker struct {
@@ -13,6 +17,10 @@ type SubscriptionBroker struct {
 	pending         map[string]chan PromptReply
 	requests        map[string]PromptRequest
 	broadcastPrompt func(PairingPrompt)
+	// relay is the daemon's prompts module; once set, pairing prompts are
+	// filed there and can be answered with prompts.respond as well as
+	// bluetooth.pairing.submit
+	relay *prompts.Manager
 }
 
 func NewSubscriptionBroker(broadcastPrompt func(PairingPrompt)) PromptBroker {
@@ -23,8 +31,27 @@ func NewSubscriptionBroker(broadcastPrompt func(PairingPrompt)) PromptBroker {
 	}
 }
 
+// SetRelay files pairing prompts with the prompts module from now on
+func (b *SubscriptionBroker) SetRelay(relay *prompts.Manager) {
+	b.mu.Lock()
+	b.relay = relay
+	b.mu.Unlock()
+}
+
 func (b *SubscriptionBroker) Ask(ctx context.Context, req PromptRequest) (string, error) {
-	token, err := generateToken()
+	b.mu.RLock()
+	relay := b.relay
+	b.mu.RUnlock()
+
+	var token string
+	var err error
+	if relay != nil {
+		var p prompts.Prompt
+		p, err = relay.Open(relayRequest(ctx, req))
+		token = p.Token
+	} else {
+		token, err = generateToken()
+	}
 	if err != nil {
 		return "", err
 	}
@@ -55,12 +82,35 @@ func (b *SubscriptionBroker) Ask(ctx context.Context, req PromptRequest) (string
 func (b *SubscriptionBroker) Wait(ctx context.Context, token string) (PromptReply, error) {
 	b.mu.RLock()
 	replyChan, exists := b.pending[token]
+	req := b.requests[token]
+	relay := b.relay
 	b.mu.RUnlock()
 
 	if !exists {
 		return PromptReply{}, fmt.Errorf("unknown token: %s", token)
 	}
 
+	if relay != nil {
+		defer b.cleanup(token)
+		resp, err := relay.Wait(ctx, token)
+		if err != nil || resp.TimedOut {
+			return PromptReply{}, errdefs.ErrSecretPromptTimeout
+		}
+		if resp.Action != prompts.ActionAllow {
+			return PromptReply{Cancel: true}, errdefs.ErrSecretPromptCancelled
+		}
+		secrets := resp.Secrets
+		// Allowing a yes/no request is the decision
+		if slices.Contains(req.Fields, "decision") && secrets["decision"] == "" {
+			secrets = maps.Clone(secrets)
+			if secrets == nil {
+				secrets = map[string]string{}
+			}
+			secrets["decision"] = "yes"
+		}
+		return PromptReply{Secrets: secrets, Accept: true}, nil
+	}
+
 	select {
 	case <-ctx.Done():
 		b.cleanup(token)
@@ -77,12 +127,21 @@ func (b *SubscriptionBroker) Wait(ctx context.Context, token string) (PromptRepl
 func (b *SubscriptionBroker) Resolve(token string, reply PromptReply) error {
 	b.mu.RLock()
 	replyChan, exists := b.pending[token]
+	relay := b.relay
 	b.mu.RUnlock()
 
 	if !exists {
 		return fmt.Errorf("unknown or expired token: %s", token)
 	}
 
+	if relay != nil {
+		resp := prompts.Response{Action: prompts.ActionDeny}
+		if reply.Accept && !reply.Cancel {
+			resp = prompts.Response{Action: prompts.ActionAllow, Secrets: reply.Secrets}
+		}
+		return relay.Respond(token, resp)
+	}
+
 	select {
 	case replyChan <- reply:
 		return nil
@@ -97,3 +156,47 @@ func (b *SubscriptionBroker) cleanup(token string) {
 	delete(b.requests, token)
 	b.mu.Unlock()
 }
+
+// relayRequest describes a pairing request for the prompts module. It
+// expires with ctx, which bounds how long the agent waits anyway.
+func relayRequest(ctx context.Context, req PromptRequest) prompts.PromptRequest {
+	details := map[string]string{
+		"devicePath":  req.DevicePath,
+		"deviceName":  req.DeviceName,
+		"deviceAddr":  req.DeviceAddr,
+		"requestType": req.RequestType,
+	}
+	if req.Passkey != nil {
+		details["passkey"] = fmt.Sprintf("%06d", *req.Passkey)
+	}
+	if len(req.Hints) > 0 {
+		details["hint"] = req.Hints[0]
+	}
+
+	var message string
+	switch req.RequestType {
+	case "pin":
+		message = "Enter the PIN shown on the device"
+	case "passkey":
+		message = "Enter the passkey shown on the device"
+	case "confirm":
+		message = "Confirm the passkey matches the one on the device"
+	case "display-pin", "display-passkey":
+		message = "Enter the code on the device"
+	default:
+		message = "Allow the device to connect"
+	}
+
+	r := prompts.PromptRequest{
+		Source:  "bluetooth",
+		Kind:    prompts.KindPairing,
+		Title:   "Pair with " + req.DeviceName,
+		Message: message,
+		Fields:  req.Fields,
+		Details: details,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		r.Timeout = time.Until(deadline)
+	}
+	return r
+}
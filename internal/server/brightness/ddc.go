@@ -39,6 +39,27 @@ func NewDDCBackend() (*DDCBackend, error) {
 	return b, nil
 }
 
+// errSessionInactive is returned for DDC writes from a session that is not in
+// the foreground; the active session on the seat owns the monitors
+var errSessionInactive = fmt.Errorf("session is not active on its seat")
+
+// inForeground reports whether this session may talk to the monitors. A
+// background session leaves the bus to the foreground one instead of polling
+// and writing alongside it.
+func (b *DDCBackend) inForeground() bool {
+	return b.sessionActive == nil || b.sessionActive()
+}
+
+// lockBus takes an exclusive flock on the i2c device node. Daemons of other
+// sessions open the same node, and interleaved DDC/CI transactions from two
+// processes corrupt each other's replies.
+func lockBus(fd int) error {
+	if err := unix.Flock(fd, unix.LOCK_EX); err != nil {
+		return fmt.Errorf("lock i2c device: %w", err)
+	}
+	return nil
+}
+
 func (b *DDCBackend) scanI2CDevices() error {
 	b.scanMutex.Lock()
 	lastScan := b.lastScan
@@ -79,6 +100,7 @@ func (b *DDCBackend) scanI2CDevices() error {
 
 		id := fmt.Sprintf("ddc:i2c-%d", i)
 		dev.id = id
+		dev.edidKey = edidKeyForBus(i)
 		b.devices[id] = dev
 		log.Debugf("found DDC device on i2c-%d", i)
 	}
@@ -97,6 +119,10 @@ func (b *DDCBackend) probeDDCDevice(bus int) (*ddcDevice, error) {
 	}
 	defer syscall.Close(fd)
 
+	if err := lockBus(fd); err != nil {
+		return nil, err
+	}
+
 	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), I2C_SLAVE, uintptr(DDCCI_ADDR)); errno != 0 {
 		return nil, errno
 	}
@@ -161,6 +187,101 @@ func (b *DDCBackend) readInitialBrightness(fd int, dev *ddcDevice) {
 	log.Debugf("initialized %s with brightness %d/%d", dev.name, cap.current, cap.max)
 }
 
+// refreshBrightness re-reads VCP brightness from every monitor so changes made
+// with the OSD buttons or ddcutil are picked up. Devices for which skip returns
+// true, or with a pending debounced write, are left alone.
+func (b *DDCBackend) refreshBrightness(skip func(id string) bool) bool {
+	if !b.inForeground() {
+		return false
+	}
+
+	b.devicesMutex.RLock()
+	devices := make(map[string]*ddcDevice, len(b.devices))
+	for id, dev := range b.devices {
+		devices[id] = dev
+	}
+	b.devicesMutex.RUnlock()
+
+	changed := false
+	for id, dev := range devices {
+		if skip(id) || b.hasPendingSet(id) {
+			continue
+		}
+
+		current, err := b.readBrightness(dev)
+		if err != nil {
+			log.Debugf("failed to poll brightness for %s: %v", id, err)
+			continue
+		}
+
+		b.devicesMutex.Lock()
+		if dev.lastBrightness != current {
+			dev.lastBrightness = current
+			changed = true
+		}
+		b.devicesMutex.Unlock()
+	}
+
+	return changed
+}
+
+func (b *DDCBackend) hasPendingSet(id string) bool {
+	b.debounceMutex.Lock()
+	defer b.debounceMutex.Unlock()
+	_, pending := b.debouncePending[id]
+	return pending
+}
+
+func (b *DDCBackend) readBrightness(dev *ddcDevice) (int, error) {
+	b.ioMutex.Lock()
+	defer b.ioMutex.Unlock()
+
+	fd, err := b.openDevice(dev)
+	if err != nil {
+		return 0, err
+	}
+	defer syscall.Close(fd)
+
+	cap, err := b.getVCPFeature(fd, VCP_BRIGHTNESS)
+	if err != nil {
+		return 0, err
+	}
+
+	return cap.current, nil
+}
+
+// healthCheck reads brightness from each monitor until one answers. A monitor
+// with a write in flight counts as alive, since it was just talked to.
+func (b *DDCBackend) healthCheck() error {
+	if !b.inForeground() {
+		return nil
+	}
+
+	b.devicesMutex.RLock()
+	devices := make([]*ddcDevice, 0, len(b.devices))
+	for _, dev := range b.devices {
+		devices = append(devices, dev)
+	}
+	b.devicesMutex.RUnlock()
+
+	if len(devices) == 0 {
+		return nil
+	}
+
+	var lastErr error
+	for _, dev := range devices {
+		if b.hasPendingSet(dev.id) {
+			return nil
+		}
+		if _, err := b.readBrightness(dev); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("no DDC monitor responding: %w", lastErr)
+}
+
 func (b *DDCBackend) GetDevices() ([]Device, error) {
 	if err := b.scanI2CDevices(); err != nil {
 		log.Debugf("DDC scan error: %v", err)
@@ -203,6 +324,10 @@ func (b *DDCBackend) SetBrightnessWithExponent(id string, value int, exponential
 		return fmt.Errorf("value out of range: %d", value)
 	}
 
+	if !b.inForeground() {
+		return errSessionInactive
+	}
+
 	b.debounceMutex.Lock()
 	defer b.debounceMutex.Unlock()
 
@@ -253,18 +378,15 @@ func (b *DDCBackend) setBrightnessImmediateWithExponent(id string, value int, ex
 		return fmt.Errorf("device not found: %s", id)
 	}
 
-	busPath := fmt.Sprintf("/dev/i2c-%d", dev.bus)
+	b.ioMutex.Lock()
+	defer b.ioMutex.Unlock()
 
-	fd, err := syscall.Open(busPath, syscall.O_RDWR, 0)
+	fd, err := b.openDevice(dev)
 	if err != nil {
-		return fmt.Errorf("open i2c device: %w", err)
+		return err
 	}
 	defer syscall.Close(fd)
 
-	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), I2C_SLAVE, uintptr(dev.addr)); errno != 0 {
-		return fmt.Errorf("set i2c slave addr: %w", errno)
-	}
-
 	max := dev.max
 	if max == 0 {
 		cap, err := b.getVCPFeature(fd, VCP_BRIGHTNESS)
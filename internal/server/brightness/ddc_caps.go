@@ -0,0 +1,621 @@
+package brightness
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	DDCCI_CAPS_REQUEST = 0xF3
+	DDCCI_CAPS_REPLY   = 0xE3
+	VCP_CONTRAST       = 0x12
+	VCP_INPUT_SOURCE   = 0x60
+
+	// maxCapsLength guards against monitors that never send the empty
+	// terminating fragment
+	maxCapsLength = 4096
+)
+
+// DDCCapabilities is a parsed MCCS capabilities string
+type DDCCapabilities struct {
+	Raw         string       `json:"raw"`
+	Type        string       `json:"type,omitempty"`
+	Model       string       `json:"model,omitempty"`
+	MCCSVersion string       `json:"mccsVersion,omitempty"`
+	Commands    []int        `json:"commands,omitempty"`
+	VCP         []VCPFeature `json:"vcp"`
+	Features    DDCFeatures  `json:"features"`
+
+	vcp map[byte][]byte
+}
+
+// VCPFeature is one supported VCP code and, for non-continuous features,
+// its allowed values
+type VCPFeature struct {
+	Code   int    `json:"code"`
+	Name   string `json:"name,omitempty"`
+	Values []int  `json:"values,omitempty"`
+}
+
+// DDCFeatures summarises the VCP codes the API acts on
+type DDCFeatures struct {
+	Brightness  bool       `json:"brightness"`
+	Contrast    bool       `json:"contrast"`
+	InputSelect bool       `json:"inputSelect"`
+	Inputs      []DDCInput `json:"inputs,omitempty"`
+}
+
+type DDCInput struct {
+	Value int    `json:"value"`
+	Name  string `json:"name"`
+}
+
+var vcpNames = map[byte]string{
+	0x02: "new control value",
+	0x04: "restore factory defaults",
+	0x05: "restore factory brightness/contrast",
+	0x08: "restore factory color",
+	0x10: "brightness",
+	0x12: "contrast",
+	0x14: "color preset",
+	0x16: "red gain",
+	0x18: "green gain",
+	0x1A: "blue gain",
+	0x52: "active control",
+	0x60: "input source",
+	0x62: "audio volume",
+	0x6C: "red black level",
+	0x6E: "green black level",
+	0x70: "blue black level",
+	0x8D: "audio mute",
+	0xAC: "horizontal frequency",
+	0xAE: "vertical frequency",
+	0xB2: "subpixel layout",
+	0xB6: "display technology",
+	0xC6: "application enable key",
+	0xC8: "controller type",
+	0xC9: "firmware level",
+	0xCA: "osd",
+	0xCC: "osd language",
+	0xD6: "power mode",
+	0xDF: "vcp version",
+}
+
+// inputSourceNames are the MCCS 2.2 values for VCP 0x60
+var inputSourceNames = map[int]string{
+	0x01: "vga-1",
+	0x02: "vga-2",
+	0x03: "dvi-1",
+	0x04: "dvi-2",
+	0x05: "composite-1",
+	0x06: "composite-2",
+	0x07: "s-video-1",
+	0x08: "s-video-2",
+	0x09: "tuner-1",
+	0x0A: "tuner-2",
+	0x0B: "tuner-3",
+	0x0C: "component-1",
+	0x0D: "component-2",
+	0x0E: "component-3",
+	0x0F: "displayport-1",
+	0x10: "displayport-2",
+	0x11: "hdmi-1",
+	0x12: "hdmi-2",
+	0x1B: "usb-c",
+}
+
+// ParseInputSource accepts a VCP 0x60 value or one of the MCCS names
+func ParseInputSource(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for value, name := range inputSourceNames {
+		if name == s {
+			return value, nil
+		}
+	}
+	if v, err := strconv.ParseInt(s, 0, 16); err == nil && v > 0 {
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("unknown input source: %s", s)
+}
+
+func inputSourceName(value int) string {
+	if name, ok := inputSourceNames[value]; ok {
+		return name
+	}
+	return fmt.Sprintf("input-0x%02x", value)
+}
+
+// Supports reports whether the monitor listed code in its vcp() section
+func (c *DDCCapabilities) Supports(code byte) bool {
+	_, ok := c.vcp[code]
+	return ok
+}
+
+// Values returns the allowed values listed for code, if any
+func (c *DDCCapabilities) Values(code byte) []byte {
+	return c.vcp[code]
+}
+
+// parseCapabilities reads strings like
+// (prot(monitor)type(lcd)model(U2720Q)cmds(01 02 03 0C E3 F3)vcp(02 04 10 12 60(0F 11 12))mccs_ver(2.1))
+func parseCapabilities(raw string) (*DDCCapabilities, error) {
+	caps := &DDCCapabilities{Raw: raw, vcp: make(map[byte][]byte)}
+
+	s := strings.TrimSpace(strings.TrimRight(raw, "\x00"))
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = s[1 : len(s)-1]
+	}
+
+	sections := 0
+	for s != "" {
+		open := strings.IndexByte(s, '(')
+		if open < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:open]))
+		end := matchingParen(s, open)
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced parentheses in capabilities after %q", key)
+		}
+		value := s[open+1 : end]
+		s = s[end+1:]
+		sections++
+
+		switch key {
+		case "type":
+			caps.Type = strings.TrimSpace(value)
+		case "model":
+			caps.Model = strings.TrimSpace(value)
+		case "mccs_ver":
+			caps.MCCSVersion = strings.TrimSpace(value)
+		case "cmds":
+			codes, _ := parseHexList(value)
+			for _, c := range codes {
+				caps.Commands = append(caps.Commands, int(c))
+			}
+		case "vcp":
+			if err := parseVCPSection(value, caps.vcp); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if sections == 0 {
+		return nil, fmt.Errorf("no sections in capabilities string")
+	}
+
+	codes := make([]int, 0, len(caps.vcp))
+	for code := range caps.vcp {
+		codes = append(codes, int(code))
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		feature := VCPFeature{Code: code, Name: vcpNames[byte(code)]}
+		for _, v := range caps.vcp[byte(code)] {
+			feature.Values = append(feature.Values, int(v))
+		}
+		caps.VCP = append(caps.VCP, feature)
+	}
+
+	caps.Features = DDCFeatures{
+		Brightness:  caps.Supports(VCP_BRIGHTNESS),
+		Contrast:    caps.Supports(VCP_CONTRAST),
+		InputSelect: caps.Supports(VCP_INPUT_SOURCE),
+	}
+	for _, v := range caps.Values(VCP_INPUT_SOURCE) {
+		caps.Features.Inputs = append(caps.Features.Inputs, DDCInput{Value: int(v), Name: inputSourceName(int(v))})
+	}
+	return caps, nil
+}
+
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseVCPSection reads codes with optional value lists. Some monitors omit
+// the spaces between codes, so digits are taken in pairs.
+func parseVCPSection(s string, out map[byte][]byte) error {
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '(':
+			return fmt.Errorf("value list without a vcp code at offset %d", i)
+		}
+
+		if i+2 > len(s) {
+			return fmt.Errorf("truncated vcp code at offset %d", i)
+		}
+		code, err := strconv.ParseUint(s[i:i+2], 16, 8)
+		if err != nil {
+			return fmt.Errorf("invalid vcp code %q", s[i:i+2])
+		}
+		i += 2
+		out[byte(code)] = nil
+
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i < len(s) && s[i] == '(' {
+			end := matchingParen(s, i)
+			if end < 0 {
+				return fmt.Errorf("unbalanced value list for vcp 0x%02x", code)
+			}
+			values, err := parseHexList(s[i+1 : end])
+			if err != nil {
+				return fmt.Errorf("vcp 0x%02x: %w", code, err)
+			}
+			out[byte(code)] = values
+			i = end + 1
+		}
+	}
+	return nil
+}
+
+func parseHexList(s string) ([]byte, error) {
+	compact := strings.Join(strings.Fields(s), "")
+	if len(compact)%2 != 0 {
+		return nil, fmt.Errorf("odd number of hex digits in %q", s)
+	}
+	out := make([]byte, 0, len(compact)/2)
+	for i := 0; i < len(compact); i += 2 {
+		v, err := strconv.ParseUint(compact[i:i+2], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex value %q", compact[i:i+2])
+		}
+		out = append(out, byte(v))
+	}
+	return out, nil
+}
+
+// Capabilities returns the parsed capabilities of a DDC monitor. They are
+// read once per EDID and cached on disk, since the transfer takes a second
+// or more on most monitors.
+func (b *DDCBackend) Capabilities(id string) (*DDCCapabilities, error) {
+	b.devicesMutex.RLock()
+	dev, ok := b.devices[id]
+	b.devicesMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("device not found: %s", id)
+	}
+
+	key := dev.edidKey
+	if key == "" {
+		key = id
+	}
+
+	b.capsMutex.Lock()
+	if caps, ok := b.capsCache[key]; ok {
+		b.capsMutex.Unlock()
+		return caps, nil
+	}
+	b.capsMutex.Unlock()
+
+	if dev.edidKey != "" {
+		if raw, ok := loadCachedCapabilities(dev.edidKey); ok {
+			if caps, err := parseCapabilities(raw); err == nil {
+				b.storeCapabilities(key, caps)
+				return caps, nil
+			}
+		}
+	}
+
+	raw, err := b.readCapabilities(dev)
+	if err != nil {
+		return nil, err
+	}
+	caps, err := parseCapabilities(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse capabilities of %s: %w", id, err)
+	}
+
+	b.storeCapabilities(key, caps)
+	if dev.edidKey != "" {
+		saveCachedCapabilities(dev.edidKey, raw)
+	}
+	return caps, nil
+}
+
+func (b *DDCBackend) storeCapabilities(key string, caps *DDCCapabilities) {
+	b.capsMutex.Lock()
+	if b.capsCache == nil {
+		b.capsCache = make(map[string]*DDCCapabilities)
+	}
+	b.capsCache[key] = caps
+	b.capsMutex.Unlock()
+}
+
+// supportsVCP checks the capabilities string, falling back to a VCP read for
+// monitors whose capabilities cannot be fetched or parsed
+func (b *DDCBackend) supportsVCP(id string, code byte) (bool, error) {
+	caps, err := b.Capabilities(id)
+	if err == nil {
+		return caps.Supports(code), nil
+	}
+	log.Debugf("capabilities unavailable for %s, probing vcp 0x%02x: %v", id, code, err)
+
+	_, probeErr := b.readVCP(id, code)
+	return probeErr == nil, nil
+}
+
+func (b *DDCBackend) readCapabilities(dev *ddcDevice) (string, error) {
+	b.ioMutex.Lock()
+	defer b.ioMutex.Unlock()
+
+	fd, err := b.openDevice(dev)
+	if err != nil {
+		return "", err
+	}
+	defer syscall.Close(fd)
+
+	var buf []byte
+	for len(buf) < maxCapsLength {
+		var fragment []byte
+		var err error
+		for attempt := 0; attempt < 3; attempt++ {
+			if fragment, err = b.readCapabilityFragment(fd, len(buf)); err == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if err != nil {
+			return "", fmt.Errorf("read capabilities at offset %d: %w", len(buf), err)
+		}
+		if len(fragment) == 0 {
+			break
+		}
+		buf = append(buf, fragment...)
+	}
+	return strings.TrimRight(string(buf), "\x00"), nil
+}
+
+func (b *DDCBackend) readCapabilityFragment(fd int, offset int) ([]byte, error) {
+	dummy := make([]byte, 32)
+	syscall.Read(fd, dummy)
+
+	data := []byte{DDCCI_CAPS_REQUEST, byte(offset >> 8), byte(offset & 0xFF)}
+	payload := []byte{DDC_SOURCE_ADDR, byte(len(data)) | 0x80}
+	payload = append(payload, data...)
+	payload = append(payload, ddcciChecksum(payload))
+
+	n, err := syscall.Write(fd, payload)
+	if err != nil || n != len(payload) {
+		return nil, fmt.Errorf("write i2c: %w", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	pollFds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	if ready, err := unix.Poll(pollFds, 200); err != nil || ready == 0 {
+		return nil, fmt.Errorf("poll i2c: no reply")
+	}
+
+	response := make([]byte, 40)
+	n, err = syscall.Read(fd, response)
+	if err != nil {
+		return nil, fmt.Errorf("read i2c: %w", err)
+	}
+	return parseCapabilityFragment(response[:n], offset)
+}
+
+// parseCapabilityFragment validates a capabilities reply:
+// 0x6E, 0x80|length, 0xE3, offset hi, offset lo, data..., checksum
+func parseCapabilityFragment(response []byte, offset int) ([]byte, error) {
+	if len(response) < 6 || response[0] != 0x6E || response[2] != DDCCI_CAPS_REPLY {
+		return nil, fmt.Errorf("invalid capabilities reply")
+	}
+	length := int(response[1] &^ 0x80)
+	if length < 3 || len(response) < 2+length {
+		return nil, fmt.Errorf("truncated capabilities reply")
+	}
+	if got := int(response[3])<<8 | int(response[4]); got != offset {
+		return nil, fmt.Errorf("capabilities offset mismatch: wanted %d, got %d", offset, got)
+	}
+	return append([]byte(nil), response[5:2+length]...), nil
+}
+
+func (b *DDCBackend) openDevice(dev *ddcDevice) (int, error) {
+	fd, err := syscall.Open(fmt.Sprintf("/dev/i2c-%d", dev.bus), syscall.O_RDWR, 0)
+	if err != nil {
+		return -1, fmt.Errorf("open i2c device: %w", err)
+	}
+	if err := lockBus(fd); err != nil {
+		syscall.Close(fd)
+		return -1, err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), I2C_SLAVE, uintptr(dev.addr)); errno != 0 {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("set i2c slave addr: %w", errno)
+	}
+	return fd, nil
+}
+
+func (b *DDCBackend) readVCP(id string, code byte) (*ddcCapability, error) {
+	b.devicesMutex.RLock()
+	dev, ok := b.devices[id]
+	b.devicesMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("device not found: %s", id)
+	}
+
+	b.ioMutex.Lock()
+	defer b.ioMutex.Unlock()
+
+	fd, err := b.openDevice(dev)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+	return b.getVCPFeature(fd, code)
+}
+
+func (b *DDCBackend) writeVCP(id string, code byte, value int) error {
+	if !b.inForeground() {
+		return errSessionInactive
+	}
+
+	b.devicesMutex.RLock()
+	dev, ok := b.devices[id]
+	b.devicesMutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("device not found: %s", id)
+	}
+
+	b.ioMutex.Lock()
+	defer b.ioMutex.Unlock()
+
+	fd, err := b.openDevice(dev)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+	return b.setVCPFeature(fd, code, value)
+}
+
+// SetContrast sets VCP 0x12 as a percentage of the monitor's maximum
+func (b *DDCBackend) SetContrast(id string, percent int) error {
+	supported, err := b.supportsVCP(id, VCP_CONTRAST)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("%s does not support contrast control", id)
+	}
+
+	current, err := b.readVCP(id, VCP_CONTRAST)
+	if err != nil {
+		return fmt.Errorf("read contrast: %w", err)
+	}
+	max := current.max
+	if max <= 0 {
+		max = 100
+	}
+	return b.writeVCP(id, VCP_CONTRAST, percent*max/100)
+}
+
+// SetInput switches the monitor to another input. Values the monitor lists
+// for VCP 0x60 are enforced when it lists any.
+func (b *DDCBackend) SetInput(id string, input int) error {
+	caps, err := b.Capabilities(id)
+	if err != nil {
+		return fmt.Errorf("input select needs the monitor's capabilities: %w", err)
+	}
+	if !caps.Supports(VCP_INPUT_SOURCE) {
+		return fmt.Errorf("%s does not support input select", id)
+	}
+	if allowed := caps.Values(VCP_INPUT_SOURCE); len(allowed) > 0 {
+		found := false
+		for _, v := range allowed {
+			if int(v) == input {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s has no input %s", id, inputSourceName(input))
+		}
+	}
+	return b.writeVCP(id, VCP_INPUT_SOURCE, input)
+}
+
+var drmSysfsRoot = "/sys/class/drm"
+
+// edidKeyForBus hashes the EDID of the DRM connector whose DDC channel is
+// i2c-<bus>, so cached capabilities follow the monitor across ports
+func edidKeyForBus(bus int) string {
+	busName := fmt.Sprintf("i2c-%d", bus)
+	connectors, _ := filepath.Glob(filepath.Join(drmSysfsRoot, "card*-*"))
+	for _, conn := range connectors {
+		match := false
+		if target, err := os.Readlink(filepath.Join(conn, "ddc")); err == nil && filepath.Base(target) == busName {
+			match = true
+		} else if _, err := os.Stat(filepath.Join(conn, busName)); err == nil {
+			match = true
+		}
+		if !match {
+			continue
+		}
+
+		edid, err := os.ReadFile(filepath.Join(conn, "edid"))
+		if err != nil || len(edid) < 128 {
+			return ""
+		}
+		sum := sha256.Sum256(edid)
+		return hex.EncodeToString(sum[:8])
+	}
+	return ""
+}
+
+func capabilitiesCachePath() string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		cacheHome = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(cacheHome, "DankMaterialShell", "ddc-capabilities.json")
+}
+
+func loadCapabilitiesCache() map[string]string {
+	cache := make(map[string]string)
+	path := capabilitiesCachePath()
+	if path == "" {
+		return cache
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+func loadCachedCapabilities(key string) (string, bool) {
+	raw, ok := loadCapabilitiesCache()[key]
+	return raw, ok
+}
+
+func saveCachedCapabilities(key, raw string) {
+	path := capabilitiesCachePath()
+	if path == "" {
+		return
+	}
+	cache := loadCapabilitiesCache()
+	cache[key] = raw
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Debugf("failed to create ddc cache dir: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Debugf("failed to write ddc capabilities cache: %v", err)
+	}
+}
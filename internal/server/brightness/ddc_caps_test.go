@@ -0,0 +1,156 @@
+package brightness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	raw := "(prot(monitor)type(LCD)model(U2720Q)cmds(01 02 03 07 0C E3 F3)vcp(02 04 05 08 10 12 14(01 04 05 06 08 0B) 16 18 1A 60(0F 11 12 1B) AC AE B2 B6 C6 C8 C9 D6(01 04 05) DF)mccs_ver(2.1))"
+
+	caps, err := parseCapabilities(raw)
+	if err != nil {
+		t.Fatalf("parseCapabilities: %v", err)
+	}
+
+	if caps.Model != "U2720Q" || caps.Type != "LCD" || caps.MCCSVersion != "2.1" {
+		t.Errorf("metadata = %q %q %q", caps.Model, caps.Type, caps.MCCSVersion)
+	}
+	if len(caps.Commands) != 7 || caps.Commands[6] != 0xF3 {
+		t.Errorf("commands = %v", caps.Commands)
+	}
+	if !caps.Features.Brightness || !caps.Features.Contrast || !caps.Features.InputSelect {
+		t.Errorf("features = %+v", caps.Features)
+	}
+
+	wantInputs := []string{"displayport-1", "hdmi-1", "hdmi-2", "usb-c"}
+	if len(caps.Features.Inputs) != len(wantInputs) {
+		t.Fatalf("inputs = %+v", caps.Features.Inputs)
+	}
+	for i, name := range wantInputs {
+		if caps.Features.Inputs[i].Name != name {
+			t.Errorf("input %d = %q, want %q", i, caps.Features.Inputs[i].Name, name)
+		}
+	}
+
+	if got := caps.Values(0xD6); len(got) != 3 || got[2] != 0x05 {
+		t.Errorf("power mode values = %v", got)
+	}
+	if caps.Supports(0x62) {
+		t.Error("audio volume should not be supported")
+	}
+}
+
+func TestParseCapabilities_Unspaced(t *testing.T) {
+	caps, err := parseCapabilities("(type(lcd)vcp(021012(0A)60(0304))mccs_ver(2.2))")
+	if err != nil {
+		t.Fatalf("parseCapabilities: %v", err)
+	}
+	if !caps.Supports(0x02) || !caps.Supports(0x10) || !caps.Supports(0x12) {
+		t.Errorf("vcp = %+v", caps.VCP)
+	}
+	if got := caps.Values(VCP_INPUT_SOURCE); len(got) != 2 || got[0] != 0x03 || got[1] != 0x04 {
+		t.Errorf("input values = %v", got)
+	}
+}
+
+func TestParseCapabilities_NoContrast(t *testing.T) {
+	caps, err := parseCapabilities("(prot(monitor)vcp(10 D6))\x00")
+	if err != nil {
+		t.Fatalf("parseCapabilities: %v", err)
+	}
+	if caps.Features.Contrast || caps.Features.InputSelect {
+		t.Errorf("features = %+v", caps.Features)
+	}
+}
+
+func TestParseCapabilities_Invalid(t *testing.T) {
+	for _, raw := range []string{"", "garbage", "(vcp(10 12)", "(vcp(1G))", "(vcp((01)))"} {
+		if _, err := parseCapabilities(raw); err == nil {
+			t.Errorf("parseCapabilities(%q) should fail", raw)
+		}
+	}
+}
+
+func TestParseCapabilityFragment(t *testing.T) {
+	reply := []byte{0x6E, 0x80 | 7, DDCCI_CAPS_REPLY, 0x00, 0x20, 'v', 'c', 'p', '(', 0x00}
+
+	data, err := parseCapabilityFragment(reply, 0x20)
+	if err != nil {
+		t.Fatalf("parseCapabilityFragment: %v", err)
+	}
+	if string(data) != "vcp(" {
+		t.Errorf("data = %q", data)
+	}
+
+	if _, err := parseCapabilityFragment(reply, 0); err == nil {
+		t.Error("offset mismatch should fail")
+	}
+
+	empty := []byte{0x6E, 0x80 | 3, DDCCI_CAPS_REPLY, 0x00, 0x40, 0x00}
+	if data, err := parseCapabilityFragment(empty, 0x40); err != nil || len(data) != 0 {
+		t.Errorf("empty fragment = %q, %v", data, err)
+	}
+}
+
+func TestParseInputSource(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"hdmi-1", 0x11},
+		{"DisplayPort-2", 0x10},
+		{"0x0f", 0x0F},
+		{"17", 17},
+	}
+	for _, tt := range tests {
+		got, err := ParseInputSource(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseInputSource(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseInputSource("scart"); err == nil {
+		t.Error("unknown input should fail")
+	}
+}
+
+func TestEdidKeyForBus(t *testing.T) {
+	root := t.TempDir()
+	orig := drmSysfsRoot
+	drmSysfsRoot = root
+	defer func() { drmSysfsRoot = orig }()
+
+	conn := filepath.Join(root, "card1-DP-1")
+	if err := os.MkdirAll(filepath.Join(conn, "i2c-7"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	edid := make([]byte, 128)
+	edid[8] = 0x10
+	if err := os.WriteFile(filepath.Join(conn, "edid"), edid, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key := edidKeyForBus(7)
+	if len(key) != 16 {
+		t.Errorf("key = %q", key)
+	}
+	if edidKeyForBus(8) != "" {
+		t.Error("unmatched bus should have no key")
+	}
+}
+
+func TestCapabilitiesDiskCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if _, ok := loadCachedCapabilities("abc"); ok {
+		t.Fatal("cache should start empty")
+	}
+	saveCachedCapabilities("abc", "(vcp(10 12))")
+	saveCachedCapabilities("def", "(vcp(10))")
+
+	raw, ok := loadCachedCapabilities("abc")
+	if !ok || raw != "(vcp(10 12))" {
+		t.Errorf("cached = %q, %v", raw, ok)
+	}
+}
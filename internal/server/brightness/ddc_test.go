@@ -2,6 +2,7 @@ package brightness
 
 import (
 	"testing"
+	"time"
 )
 
 func TestDDCBackend_PercentConversions(t *testing.T) {
@@ -133,3 +134,36 @@ func TestDDCBackend_RoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestDDCBackend_InactiveSession(t *testing.T) {
+	active := false
+	b := &DDCBackend{
+		devices: map[string]*ddcDevice{
+			"ddc:i2c-99": {id: "ddc:i2c-99", bus: 99, addr: DDCCI_ADDR},
+		},
+		debounceTimers:  make(map[string]*time.Timer),
+		debouncePending: make(map[string]ddcPendingSet),
+		sessionActive:   func() bool { return active },
+	}
+
+	if err := b.SetBrightness("ddc:i2c-99", 50, false, nil); err != errSessionInactive {
+		t.Errorf("SetBrightness() error = %v, want %v", err, errSessionInactive)
+	}
+	if err := b.writeVCP("ddc:i2c-99", VCP_BRIGHTNESS, 50); err != errSessionInactive {
+		t.Errorf("writeVCP() error = %v, want %v", err, errSessionInactive)
+	}
+	if b.refreshBrightness(func(string) bool { t.Error("inactive session polled the bus"); return true }) {
+		t.Error("refreshBrightness() reported a change while inactive")
+	}
+	if err := b.healthCheck(); err != nil {
+		t.Errorf("healthCheck() error = %v, want nil while inactive", err)
+	}
+
+	active = true
+	if err := b.SetBrightness("ddc:i2c-99", 50, false, nil); err != nil {
+		t.Errorf("SetBrightness() error = %v once active", err)
+	}
+	b.debounceMutex.Lock()
+	b.debounceTimers["ddc:i2c-99"].Stop()
+	b.debounceMutex.Unlock()
+}
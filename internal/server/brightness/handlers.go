@@ -2,6 +2,7 @@ package brightness
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 
 	"github.com/AvengeMedia/danklinux/internal/server/models"
@@ -19,6 +20,12 @@ func HandleRequest(conn net.Conn, req Request, m *Manager) {
 		handleDecrement(conn, req, m)
 	case "brightness.rescan":
 		handleRescan(conn, req, m)
+	case "brightness.ddc.capabilities":
+		handleDDCCapabilities(conn, req, m)
+	case "brightness.ddc.setContrast":
+		handleDDCSetContrast(conn, req, m)
+	case "brightness.ddc.setInput":
+		handleDDCSetInput(conn, req, m)
 	case "brightness.subscribe":
 		handleSubscribe(conn, req, m)
 	default:
@@ -89,7 +96,13 @@ func handleIncrement(conn net.Conn, req Request, m *Manager) {
 		exponent = exponentFloat
 	}
 
-	if err := m.IncrementBrightnessWithExponent(device, step, exponential, exponent); err != nil {
+	var err error
+	if source, ok := req.Params["source"].(string); ok && source != "" {
+		err = m.StepBrightness(device, InputSource(source), true, exponential, exponent)
+	} else {
+		err = m.IncrementBrightnessWithExponent(device, step, exponential, exponent)
+	}
+	if err != nil {
 		models.RespondError(conn, req.ID.(int), err.Error())
 		return
 	}
@@ -120,7 +133,13 @@ func handleDecrement(conn net.Conn, req Request, m *Manager) {
 		exponent = exponentFloat
 	}
 
-	if err := m.IncrementBrightnessWithExponent(device, -step, exponential, exponent); err != nil {
+	var err error
+	if source, ok := req.Params["source"].(string); ok && source != "" {
+		err = m.StepBrightness(device, InputSource(source), false, exponential, exponent)
+	} else {
+		err = m.IncrementBrightnessWithExponent(device, -step, exponential, exponent)
+	}
+	if err != nil {
 		models.RespondError(conn, req.ID.(int), err.Error())
 		return
 	}
@@ -135,6 +154,71 @@ func handleRescan(conn net.Conn, req Request, m *Manager) {
 	models.Respond(conn, req.ID.(int), state)
 }
 
+func handleDDCCapabilities(conn net.Conn, req Request, m *Manager) {
+	device, ok := req.Params["device"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID.(int), "missing or invalid device parameter")
+		return
+	}
+
+	caps, err := m.GetDDCCapabilities(device)
+	if err != nil {
+		models.RespondError(conn, req.ID.(int), err.Error())
+		return
+	}
+	models.Respond(conn, req.ID.(int), caps)
+}
+
+func handleDDCSetContrast(conn net.Conn, req Request, m *Manager) {
+	device, ok := req.Params["device"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID.(int), "missing or invalid device parameter")
+		return
+	}
+
+	percentFloat, ok := req.Params["percent"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID.(int), "missing or invalid percent parameter")
+		return
+	}
+
+	if err := m.SetDDCContrast(device, int(percentFloat)); err != nil {
+		models.RespondError(conn, req.ID.(int), err.Error())
+		return
+	}
+	models.Respond(conn, req.ID.(int), SuccessResult{Success: true, Message: "contrast set"})
+}
+
+func handleDDCSetInput(conn net.Conn, req Request, m *Manager) {
+	device, ok := req.Params["device"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID.(int), "missing or invalid device parameter")
+		return
+	}
+
+	var input int
+	switch v := req.Params["input"].(type) {
+	case float64:
+		input = int(v)
+	case string:
+		parsed, err := ParseInputSource(v)
+		if err != nil {
+			models.RespondError(conn, req.ID.(int), err.Error())
+			return
+		}
+		input = parsed
+	default:
+		models.RespondError(conn, req.ID.(int), "missing or invalid input parameter")
+		return
+	}
+
+	if err := m.SetDDCInput(device, input); err != nil {
+		models.RespondError(conn, req.ID.(int), err.Error())
+		return
+	}
+	models.Respond(conn, req.ID.(int), SuccessResult{Success: true, Message: fmt.Sprintf("switched to %s", inputSourceName(input))})
+}
+
 func handleSubscribe(conn net.Conn, req Request, m *Manager) {
 	clientID := "brightness-subscriber"
 	if idStr, ok := req.ID.(string); ok && idStr != "" {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/session"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -12,9 +13,13 @@ type DBusConn interface {
 	Close() error
 }
 
+const autoSessionPath = dbus.ObjectPath("/org/freedesktop/login1/session/auto")
+
 type LogindBackend struct {
 	conn     DBusConn
 	connOnce bool
+	// sessionPath is the session SetBrightness is called on
+	sessionPath dbus.ObjectPath
 }
 
 func NewLogindBackend() (*LogindBackend, error) {
@@ -23,7 +28,8 @@ func NewLogindBackend() (*LogindBackend, error) {
 		return nil, fmt.Errorf("connect to system bus: %w", err)
 	}
 
-	obj := conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto")
+	path := sessionObjectPath()
+	obj := conn.Object("org.freedesktop.login1", path)
 	call := obj.Call("org.freedesktop.DBus.Peer.Ping", 0)
 	if call.Err != nil {
 		conn.Close()
@@ -32,12 +38,13 @@ func NewLogindBackend() (*LogindBackend, error) {
 
 	conn.Close()
 
-	return &LogindBackend{}, nil
+	return &LogindBackend{sessionPath: path}, nil
 }
 
 func NewLogindBackendWithConn(conn DBusConn) *LogindBackend {
 	return &LogindBackend{
-		conn: conn,
+		conn:        conn,
+		sessionPath: autoSessionPath,
 	}
 }
 
@@ -50,7 +57,7 @@ func (b *LogindBackend) SetBrightness(subsystem, name string, brightness uint32)
 		b.conn = conn
 	}
 
-	obj := b.conn.Object("org.freedesktop.login1", "/org/freedesktop/login1/session/auto")
+	obj := b.conn.Object("org.freedesktop.login1", b.sessionPath)
 	call := obj.Call("org.freedesktop.login1.Session.SetBrightness", 0, subsystem, name, brightness)
 	if call.Err != nil {
 		return fmt.Errorf("dbus call failed: %w", call.Err)
@@ -60,6 +67,16 @@ func (b *LogindBackend) SetBrightness(subsystem, name string, brightness uint32)
 	return nil
 }
 
+// sessionObjectPath is the daemon's own session. session/auto only works for
+// callers inside a session scope and a daemon started by systemd --user is
+// not, so it is the fallback when the session could not be resolved.
+func sessionObjectPath() dbus.ObjectPath {
+	if path := session.Current().Path; path != "" {
+		return path
+	}
+	return autoSessionPath
+}
+
 func (b *LogindBackend) Close() {
 	if b.conn != nil {
 		b.conn.Close()
@@ -6,7 +6,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/session"
 )
 
 func NewManager() (*Manager, error) {
@@ -17,13 +19,18 @@ func NewManagerWithOptions(exponential bool) (*Manager, error) {
 	m := &Manager{
 		subscribers:       make(map[string]chan State),
 		updateSubscribers: make(map[string]chan DeviceUpdate),
+		localWrites:       make(map[string]time.Time),
 		stopChan:          make(chan struct{}),
 		exponential:       exponential,
+		stepCurves:        make(map[InputSource]StepCurve),
+	}
+	for source, curve := range defaultStepCurves {
+		m.stepCurves[source] = curve
 	}
 
-	go m.initLogind()
-	go m.initSysfs()
-	go m.initDDC()
+	crash.Go("brightness.initLogind", m.initLogind)
+	crash.Go("brightness.initSysfs", m.initSysfs)
+	crash.Go("brightness.initDDC", m.initDDC)
 
 	return m, nil
 }
@@ -67,6 +74,8 @@ func (m *Manager) initSysfs() {
 	m.sysfsBackend = sysfs
 	m.sysfsReady = true
 	m.updateState()
+
+	crash.Go("brightness.watchSysfs", m.watchSysfs)
 }
 
 func (m *Manager) initDDC() {
@@ -76,16 +85,32 @@ func (m *Manager) initDDC() {
 		return
 	}
 
+	ddc.sessionActive = session.Current().IsActive
 	m.ddcBackend = ddc
 	m.ddcReady = true
 	log.Info("DDC backend initialized")
 
 	m.updateState()
+
+	crash.Go("brightness.pollDDC", m.pollDDC)
 }
 
+// Rescan looks for devices plugged in or removed since startup and updates
+// the sysfs watch list to match, so new devices report external changes too
 func (m *Manager) Rescan() {
 	log.Debug("Rescanning brightness devices...")
+	if m.sysfsReady && m.sysfsBackend != nil {
+		if err := m.sysfsBackend.scanDevices(); err != nil {
+			log.Debugf("Failed to rescan sysfs devices: %v", err)
+		}
+	}
+	if m.ddcReady && m.ddcBackend != nil {
+		m.ddcBackend.scanMutex.Lock()
+		m.ddcBackend.lastScan = time.Time{}
+		m.ddcBackend.scanMutex.Unlock()
+	}
 	m.updateState()
+	m.refreshSysfsWatches()
 }
 
 func sortDevices(devices []Device) {
@@ -130,7 +155,25 @@ func stateChanged(old, new State) bool {
 	return false
 }
 
-func (m *Manager) updateState() {
+func changedDeviceIDs(old, new State) []string {
+	oldMap := make(map[string]Device)
+	for _, d := range old.Devices {
+		oldMap[d.ID] = d
+	}
+
+	var changed []string
+	for _, newDev := range new.Devices {
+		oldDev, exists := oldMap[newDev.ID]
+		if !exists || oldDev.Current != newDev.Current || oldDev.Max != newDev.Max {
+			changed = append(changed, newDev.ID)
+		}
+	}
+	return changed
+}
+
+// updateState re-reads all backends and returns the IDs of devices whose
+// values changed since the last snapshot.
+func (m *Manager) updateState() []string {
 	allDevices := make([]Device, 0)
 
 	if m.sysfsReady && m.sysfsBackend != nil {
@@ -161,13 +204,15 @@ func (m *Manager) updateState() {
 
 	if !stateChanged(oldState, newState) {
 		m.stateMutex.Unlock()
-		return
+		return nil
 	}
 
 	m.state = newState
 	m.stateMutex.Unlock()
 	log.Debugf("State changed, notifying subscribers")
 	m.NotifySubscribers()
+
+	return changedDeviceIDs(oldState, newState)
 }
 
 func (m *Manager) SetBrightness(deviceID string, percent int) error {
@@ -214,6 +259,8 @@ func (m *Manager) SetBrightnessWithExponent(deviceID string, percent int, expone
 	m.state = State{Devices: newDevices}
 	m.stateMutex.Unlock()
 
+	m.markLocalWrite(deviceID)
+
 	var err error
 	if deviceClass == ClassDDC {
 		log.Debugf("Calling DDC backend for %s", deviceID)
@@ -241,6 +288,62 @@ func (m *Manager) SetBrightnessWithExponent(deviceID string, percent int, expone
 	return nil
 }
 
+func (m *Manager) ddcFor(deviceID string) (*DDCBackend, error) {
+	if !m.ddcReady || m.ddcBackend == nil {
+		return nil, fmt.Errorf("DDC is not available")
+	}
+	if !strings.HasPrefix(deviceID, "ddc:") {
+		return nil, fmt.Errorf("not a DDC device: %s", deviceID)
+	}
+	return m.ddcBackend, nil
+}
+
+// SetDDCScanInterval sets the minimum time between DDC/I2C bus scans
+func (m *Manager) SetDDCScanInterval(interval time.Duration) {
+	if !m.ddcReady || m.ddcBackend == nil {
+		return
+	}
+	m.ddcBackend.scanMutex.Lock()
+	m.ddcBackend.scanInterval = interval
+	m.ddcBackend.scanMutex.Unlock()
+}
+
+// HealthCheck fails when DDC monitors were found but none of them answer on
+// their i2c bus any more
+func (m *Manager) HealthCheck() error {
+	if !m.ddcReady || m.ddcBackend == nil {
+		return nil
+	}
+	return m.ddcBackend.healthCheck()
+}
+
+func (m *Manager) GetDDCCapabilities(deviceID string) (*DDCCapabilities, error) {
+	ddc, err := m.ddcFor(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return ddc.Capabilities(deviceID)
+}
+
+func (m *Manager) SetDDCContrast(deviceID string, percent int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("percent out of range: %d", percent)
+	}
+	ddc, err := m.ddcFor(deviceID)
+	if err != nil {
+		return err
+	}
+	return ddc.SetContrast(deviceID, percent)
+}
+
+func (m *Manager) SetDDCInput(deviceID string, input int) error {
+	ddc, err := m.ddcFor(deviceID)
+	if err != nil {
+		return err
+	}
+	return ddc.SetInput(deviceID, input)
+}
+
 func (m *Manager) IncrementBrightness(deviceID string, step int) error {
 	return m.IncrementBrightnessWithMode(deviceID, step, m.exponential)
 }
@@ -249,24 +352,22 @@ func (m *Manager) IncrementBrightnessWithMode(deviceID string, step int, exponen
 	return m.IncrementBrightnessWithExponent(deviceID, step, exponential, 1.2)
 }
 
-func (m *Manager) IncrementBrightnessWithExponent(deviceID string, step int, exponential bool, exponent float64) error {
+func (m *Manager) currentPercent(deviceID string) (int, error) {
 	m.stateMutex.RLock()
-	currentState := m.state
-	m.stateMutex.RUnlock()
-
-	var currentPercent int
-	var found bool
+	defer m.stateMutex.RUnlock()
 
-	for _, dev := range currentState.Devices {
+	for _, dev := range m.state.Devices {
 		if dev.ID == deviceID {
-			currentPercent = dev.CurrentPercent
-			found = true
-			break
+			return dev.CurrentPercent, nil
 		}
 	}
+	return 0, fmt.Errorf("device not found: %s", deviceID)
+}
 
-	if !found {
-		return fmt.Errorf("device not found: %s", deviceID)
+func (m *Manager) IncrementBrightnessWithExponent(deviceID string, step int, exponential bool, exponent float64) error {
+	currentPercent, err := m.currentPercent(deviceID)
+	if err != nil {
+		return err
 	}
 
 	newPercent := currentPercent + step
@@ -280,6 +381,46 @@ func (m *Manager) IncrementBrightnessWithExponent(deviceID string, step int, exp
 	return m.SetBrightnessWithExponent(deviceID, newPercent, exponential, exponent)
 }
 
+// StepBrightness moves one step up or down along the source's step curve
+func (m *Manager) StepBrightness(deviceID string, source InputSource, up bool, exponential bool, exponent float64) error {
+	curve, err := m.StepCurve(source)
+	if err != nil {
+		return err
+	}
+	currentPercent, err := m.currentPercent(deviceID)
+	if err != nil {
+		return err
+	}
+	return m.SetBrightnessWithExponent(deviceID, curve.Next(currentPercent, up), exponential, exponent)
+}
+
+func (m *Manager) StepCurve(source InputSource) (StepCurve, error) {
+	m.stepMutex.RLock()
+	defer m.stepMutex.RUnlock()
+
+	curve, ok := m.stepCurves[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown input source: %s", source)
+	}
+	return curve, nil
+}
+
+// SetStepCurve replaces a source's curve; an empty curve restores the default
+func (m *Manager) SetStepCurve(source InputSource, curve StepCurve) error {
+	def, ok := defaultStepCurves[source]
+	if !ok {
+		return fmt.Errorf("unknown input source: %s", source)
+	}
+	if len(curve) == 0 {
+		curve = def
+	}
+
+	m.stepMutex.Lock()
+	m.stepCurves[source] = curve
+	m.stepMutex.Unlock()
+	return nil
+}
+
 func (m *Manager) DecrementBrightness(deviceID string, step int) error {
 	return m.IncrementBrightness(deviceID, -step)
 }
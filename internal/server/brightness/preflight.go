@@ -0,0 +1,109 @@
+package brightness
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// defaultBrightnessGroup is the group distributions grant backlight access
+// to, and the one the suggested udev rule assigns
+const defaultBrightnessGroup = "video"
+
+// PermissionError means neither logind nor a direct sysfs write could change
+// a device. It carries what the user needs to fix it.
+type PermissionError struct {
+	Device string
+	Path   string
+	// Group owns the brightness file, or is the group the udev rule would
+	// give it when root owns it
+	Group string
+	Err   error
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("cannot set brightness of %s: logind could not change it and %s is not writable. %s", e.Device, e.Path, e.Fix())
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// Fix names the group to join and the udev rule that opens the file to it
+func (e *PermissionError) Fix() string {
+	return fmt.Sprintf("Join the %s group (sudo usermod -aG %s $USER, then log in again) or add this rule to /etc/udev/rules.d/90-dms-brightness.rules: %s",
+		e.Group, e.Group, e.UdevRule())
+}
+
+// UdevRule makes the brightness files of the device's class group-writable
+func (e *PermissionError) UdevRule() string {
+	class, _, _ := strings.Cut(e.Device, ":")
+	path := "/sys/class/" + class + "/%k/brightness"
+	return fmt.Sprintf(`ACTION=="add", SUBSYSTEM=="%s", RUN+="/bin/chgrp %s %s", RUN+="/bin/chmod g+w %s"`,
+		class, e.Group, path, path)
+}
+
+func newPermissionError(deviceID, path string, err error) *PermissionError {
+	return &PermissionError{Device: deviceID, Path: path, Group: owningGroup(path), Err: err}
+}
+
+func owningGroup(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return defaultBrightnessGroup
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Gid == 0 {
+		return defaultBrightnessGroup
+	}
+	group, err := user.LookupGroupId(strconv.FormatUint(uint64(stat.Gid), 10))
+	if err != nil {
+		return defaultBrightnessGroup
+	}
+	return group.Name
+}
+
+func isPermissionError(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, unix.EROFS)
+}
+
+// unwritable lists the devices whose brightness file the current user
+// cannot write
+func (b *SysfsBackend) unwritable() []*PermissionError {
+	b.deviceCacheMutex.RLock()
+	defer b.deviceCacheMutex.RUnlock()
+
+	var problems []*PermissionError
+	for id, dev := range b.deviceCache {
+		path := filepath.Join(b.basePath, string(dev.class), dev.name, "brightness")
+		if err := unix.Access(path, unix.W_OK); err != nil {
+			problems = append(problems, newPermissionError(id, path, err))
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Device < problems[j].Device })
+	return problems
+}
+
+// CheckAccess is the preflight for brightness control. When logind answers
+// it can set every device, so only without it are the sysfs files checked.
+func CheckAccess() (logind bool, problems []*PermissionError, err error) {
+	if backend, err := NewLogindBackend(); err == nil {
+		backend.Close()
+		return true, nil, nil
+	}
+
+	sysfs, err := NewSysfsBackend()
+	if err != nil {
+		return false, nil, err
+	}
+	return false, sysfs.unwritable(), nil
+}
@@ -0,0 +1,57 @@
+package brightness
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionError(t *testing.T) {
+	err := &PermissionError{
+		Device: "backlight:intel_backlight",
+		Path:   "/sys/class/backlight/intel_backlight/brightness",
+		Group:  "video",
+		Err:    os.ErrPermission,
+	}
+
+	assert.Equal(t, `ACTION=="add", SUBSYSTEM=="backlight", RUN+="/bin/chgrp video /sys/class/backlight/%k/brightness", RUN+="/bin/chmod g+w /sys/class/backlight/%k/brightness"`, err.UdevRule())
+	assert.Contains(t, err.Error(), "/sys/class/backlight/intel_backlight/brightness is not writable")
+	assert.Contains(t, err.Error(), "sudo usermod -aG video $USER")
+	assert.Contains(t, err.Error(), err.UdevRule())
+	assert.ErrorIs(t, err, os.ErrPermission)
+
+	var permErr *PermissionError
+	wrapped := fmt.Errorf("failed to set brightness: %w", err)
+	require.True(t, errors.As(wrapped, &permErr))
+	assert.Equal(t, "backlight:intel_backlight", permErr.Device)
+}
+
+func TestSysfsBackend_PermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write read-only files")
+	}
+
+	tmpDir := t.TempDir()
+	devDir := filepath.Join(tmpDir, "leds", "kbd_backlight")
+	require.NoError(t, os.MkdirAll(devDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(devDir, "max_brightness"), []byte("3\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(devDir, "brightness"), []byte("1\n"), 0444))
+
+	b := &SysfsBackend{basePath: tmpDir, classes: []string{"leds"}, deviceCache: make(map[string]*sysfsDevice)}
+	require.NoError(t, b.scanDevices())
+
+	problems := b.unwritable()
+	require.Len(t, problems, 1)
+	assert.Equal(t, "leds:kbd_backlight", problems[0].Device)
+
+	err := b.SetBrightness("leds:kbd_backlight", 100, false)
+	var permErr *PermissionError
+	require.ErrorAs(t, err, &permErr)
+	assert.Equal(t, filepath.Join(devDir, "brightness"), permErr.Path)
+	assert.Contains(t, permErr.UdevRule(), `SUBSYSTEM=="leds"`)
+}
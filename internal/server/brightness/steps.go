@@ -0,0 +1,98 @@
+package brightness
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InputSource is where a brightness step came from. Keys and slider drags
+// can step differently, e.g. finely near the bottom of the range where a
+// backlight changes most visibly.
+type InputSource string
+
+const (
+	SourceKeys   InputSource = "keys"
+	SourceSlider InputSource = "slider"
+)
+
+// StepSegment applies Step to brightness up to and including UpTo percent
+type StepSegment struct {
+	UpTo int `json:"upTo"`
+	Step int `json:"step"`
+}
+
+// StepCurve is a piecewise step size over 0–100%, segments in ascending
+// order with the last one ending at 100
+type StepCurve []StepSegment
+
+var defaultStepCurves = map[InputSource]StepCurve{
+	SourceKeys:   {{UpTo: 10, Step: 1}, {UpTo: 30, Step: 5}, {UpTo: 100, Step: 10}},
+	SourceSlider: {{UpTo: 10, Step: 1}, {UpTo: 100, Step: 5}},
+}
+
+// ParseStepCurve reads a curve written as "upto:step" pairs, e.g.
+// "10:1,30:5,100:10" for 1% steps up to 10%, 5% up to 30% and 10% above
+func ParseStepCurve(s string) (StepCurve, error) {
+	var curve StepCurve
+	prev := 0
+	for _, pair := range strings.Split(s, ",") {
+		upTo, step, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("step curve segment %q: expected upto:step", pair)
+		}
+		seg := StepSegment{}
+		var err error
+		if seg.UpTo, err = strconv.Atoi(strings.TrimSpace(upTo)); err != nil {
+			return nil, fmt.Errorf("step curve segment %q: invalid percentage", pair)
+		}
+		if seg.Step, err = strconv.Atoi(strings.TrimSpace(step)); err != nil {
+			return nil, fmt.Errorf("step curve segment %q: invalid step", pair)
+		}
+		if seg.UpTo <= prev || seg.UpTo > 100 {
+			return nil, fmt.Errorf("step curve segment %q: percentages must rise from 1 to 100", pair)
+		}
+		if seg.Step < 1 || seg.Step > 100 {
+			return nil, fmt.Errorf("step curve segment %q: step must be 1-100", pair)
+		}
+		prev = seg.UpTo
+		curve = append(curve, seg)
+	}
+	if prev != 100 {
+		return nil, fmt.Errorf("step curve must end at 100")
+	}
+	return curve, nil
+}
+
+func (c StepCurve) String() string {
+	parts := make([]string, len(c))
+	for i, seg := range c {
+		parts[i] = fmt.Sprintf("%d:%d", seg.UpTo, seg.Step)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Next is the brightness one step up or down from current. A step never
+// crosses a segment boundary, so stepping down and back up returns to the
+// same values.
+func (c StepCurve) Next(current int, up bool) int {
+	lower := 0
+	for _, seg := range c {
+		inSegment := current < seg.UpTo
+		if !up {
+			inSegment = current <= seg.UpTo
+		}
+		if !inSegment {
+			lower = seg.UpTo
+			continue
+		}
+		if up {
+			return min(current+seg.Step, seg.UpTo)
+		}
+		return max(current-seg.Step, lower)
+	}
+	if up {
+		return 100
+	}
+	return max(current-1, 0)
+}
@@ -0,0 +1,68 @@
+package brightness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStepCurve(t *testing.T) {
+	curve, err := ParseStepCurve("10:1, 30:5,100:10")
+	require.NoError(t, err)
+	assert.Equal(t, StepCurve{{UpTo: 10, Step: 1}, {UpTo: 30, Step: 5}, {UpTo: 100, Step: 10}}, curve)
+	assert.Equal(t, "10:1,30:5,100:10", curve.String())
+
+	for _, bad := range []string{"", "10:1", "30:5,10:1,100:10", "10:0,100:10", "10-1,100:10", "50:5,120:10"} {
+		_, err := ParseStepCurve(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestStepCurveNext(t *testing.T) {
+	curve := defaultStepCurves[SourceKeys]
+
+	tests := []struct {
+		current int
+		up      bool
+		want    int
+	}{
+		{0, true, 1},
+		{9, true, 10},
+		{10, true, 15},
+		{28, true, 30},
+		{30, true, 40},
+		{95, true, 100},
+		{100, true, 100},
+		{100, false, 90},
+		{33, false, 30},
+		{30, false, 25},
+		{12, false, 10},
+		{10, false, 9},
+		{0, false, 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, curve.Next(tt.current, tt.up), "current=%d up=%v", tt.current, tt.up)
+	}
+}
+
+func TestManagerStepCurves(t *testing.T) {
+	m := &Manager{stepCurves: map[InputSource]StepCurve{}}
+	require.NoError(t, m.SetStepCurve(SourceSlider, nil))
+
+	curve, err := m.StepCurve(SourceSlider)
+	require.NoError(t, err)
+	assert.Equal(t, defaultStepCurves[SourceSlider], curve, "an empty curve restores the default")
+
+	custom := StepCurve{{UpTo: 100, Step: 2}}
+	require.NoError(t, m.SetStepCurve(SourceSlider, custom))
+	curve, _ = m.StepCurve(SourceSlider)
+	assert.Equal(t, custom, curve)
+
+	assert.Error(t, m.SetStepCurve("mouse", custom))
+	_, err = m.StepCurve("mouse")
+	assert.Error(t, err)
+
+	m.state = State{Devices: []Device{{ID: "backlight:intel", CurrentPercent: 5}}}
+	assert.ErrorContains(t, m.StepBrightness("backlight:acpi", SourceSlider, true, false, 1.2), "device not found")
+}
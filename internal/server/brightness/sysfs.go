@@ -25,10 +25,13 @@ func NewSysfsBackend() (*SysfsBackend, error) {
 	return b, nil
 }
 
+// scanDevices rebuilds the device cache, dropping devices that have been
+// unplugged since the last scan
 func (b *SysfsBackend) scanDevices() error {
 	b.deviceCacheMutex.Lock()
 	defer b.deviceCacheMutex.Unlock()
 
+	cache := make(map[string]*sysfsDevice)
 	for _, class := range b.classes {
 		classPath := filepath.Join(b.basePath, class)
 		entries, err := os.ReadDir(classPath)
@@ -68,7 +71,7 @@ func (b *SysfsBackend) scanDevices() error {
 			}
 
 			deviceID := fmt.Sprintf("%s:%s", class, entry.Name())
-			b.deviceCache[deviceID] = &sysfsDevice{
+			cache[deviceID] = &sysfsDevice{
 				class:         deviceClass,
 				id:            deviceID,
 				name:          entry.Name(),
@@ -80,6 +83,7 @@ func (b *SysfsBackend) scanDevices() error {
 		}
 	}
 
+	b.deviceCache = cache
 	return nil
 }
 
@@ -155,6 +159,36 @@ func (b *SysfsBackend) GetDevices() ([]Device, error) {
 	return devices, nil
 }
 
+// watchPaths returns the attribute files that change when brightness is
+// adjusted, either by a write to brightness or by firmware updating
+// actual_brightness.
+func (b *SysfsBackend) watchPaths() []string {
+	b.deviceCacheMutex.RLock()
+	defer b.deviceCacheMutex.RUnlock()
+
+	var paths []string
+	for _, dev := range b.deviceCache {
+		if shouldSuppressDevice(dev.name) {
+			continue
+		}
+
+		parts := strings.SplitN(dev.id, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		devicePath := filepath.Join(b.basePath, parts[0], parts[1])
+		for _, attr := range []string{"brightness", "actual_brightness"} {
+			path := filepath.Join(devicePath, attr)
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	return paths
+}
+
 func (b *SysfsBackend) GetDevice(id string) (*sysfsDevice, error) {
 	b.deviceCacheMutex.RLock()
 	defer b.deviceCacheMutex.RUnlock()
@@ -196,6 +230,9 @@ func (b *SysfsBackend) SetBrightnessWithExponent(id string, percent int, exponen
 
 	data := []byte(fmt.Sprintf("%d", value))
 	if err := os.WriteFile(brightnessPath, data, 0644); err != nil {
+		if isPermissionError(err) {
+			return newPermissionError(id, brightnessPath, err)
+		}
 		return fmt.Errorf("write brightness: %w", err)
 	}
 
@@ -48,6 +48,9 @@ type Manager struct {
 
 	exponential bool
 
+	stepMutex  sync.RWMutex
+	stepCurves map[InputSource]StepCurve
+
 	stateMutex sync.RWMutex
 	state      State
 
@@ -60,6 +63,15 @@ type Manager struct {
 	broadcastPending bool
 	pendingDeviceID  string
 
+	localWriteMutex sync.Mutex
+	localWrites     map[string]time.Time
+
+	// watchFd is the inotify instance of watchSysfs and watches its watch
+	// descriptors by path; watches is nil while the watcher is not running
+	watchMutex sync.Mutex
+	watchFd    int
+	watches    map[string]int
+
 	stopChan chan struct{}
 }
 
@@ -90,6 +102,15 @@ type DDCBackend struct {
 	debounceMutex   sync.Mutex
 	debounceTimers  map[string]*time.Timer
 	debouncePending map[string]ddcPendingSet
+
+	ioMutex sync.Mutex
+
+	capsMutex sync.Mutex
+	capsCache map[string]*DDCCapabilities
+
+	// sessionActive gates bus access on the session being in the foreground;
+	// nil allows it always
+	sessionActive func() bool
 }
 
 type ddcPendingSet struct {
@@ -104,6 +125,7 @@ type ddcDevice struct {
 	name           string
 	max            int
 	lastBrightness int
+	edidKey        string
 }
 
 type ddcCapability struct {
@@ -119,6 +141,11 @@ type SetBrightnessParams struct {
 	Exponent    float64 `json:"exponent,omitempty"`
 }
 
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
 func (m *Manager) Subscribe(id string) chan State {
 	ch := make(chan State, 16)
 	m.subMutex.Lock()
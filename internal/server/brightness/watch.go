@@ -0,0 +1,133 @@
+package brightness
+
+import (
+	"os"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	externalChangeDebounce = 100 * time.Millisecond
+	localWriteGrace        = time.Second
+	ddcPollInterval        = 15 * time.Second
+)
+
+func (m *Manager) markLocalWrite(deviceID string) {
+	m.localWriteMutex.Lock()
+	defer m.localWriteMutex.Unlock()
+
+	if m.localWrites == nil {
+		m.localWrites = make(map[string]time.Time)
+	}
+	m.localWrites[deviceID] = time.Now()
+}
+
+func (m *Manager) recentlyWritten(deviceID string) bool {
+	m.localWriteMutex.Lock()
+	defer m.localWriteMutex.Unlock()
+
+	t, ok := m.localWrites[deviceID]
+	return ok && time.Since(t) < localWriteGrace
+}
+
+// watchSysfs picks up brightness changes made outside of dms (brightnessctl,
+// firmware hotkeys). Writes to brightness and kernel sysfs_notify on
+// actual_brightness both surface as IN_MODIFY.
+func (m *Manager) watchSysfs() {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		log.Debugf("inotify unavailable, external brightness changes won't be tracked: %v", err)
+		return
+	}
+
+	m.watchMutex.Lock()
+	m.watchFd = fd
+	m.watches = make(map[string]int)
+	m.watchMutex.Unlock()
+	m.refreshSysfsWatches()
+
+	f := os.NewFile(uintptr(fd), "inotify")
+	crash.Go("brightness.inotifyClose", func() {
+		<-m.stopChan
+		m.watchMutex.Lock()
+		m.watches = nil
+		m.watchMutex.Unlock()
+		f.Close()
+	})
+
+	var debounce *time.Timer
+	buf := make([]byte, 4096)
+	for {
+		if _, err := f.Read(buf); err != nil {
+			return
+		}
+
+		if debounce == nil {
+			debounce = time.AfterFunc(externalChangeDebounce, m.syncExternalChanges)
+		} else {
+			debounce.Reset(externalChangeDebounce)
+		}
+	}
+}
+
+// refreshSysfsWatches watches the attributes of every current sysfs device
+// and drops the watches of removed ones. Adding a path that is already
+// watched only returns its existing descriptor.
+func (m *Manager) refreshSysfsWatches() {
+	m.watchMutex.Lock()
+	defer m.watchMutex.Unlock()
+
+	if m.watches == nil {
+		return
+	}
+
+	current := make(map[string]int)
+	for _, path := range m.sysfsBackend.watchPaths() {
+		wd, err := unix.InotifyAddWatch(m.watchFd, path, unix.IN_MODIFY|unix.IN_CLOSE_WRITE)
+		if err != nil {
+			log.Debugf("failed to watch %s: %v", path, err)
+			continue
+		}
+		current[path] = wd
+	}
+	for path, wd := range m.watches {
+		if _, ok := current[path]; !ok {
+			// the kernel has already dropped the watch if the file is gone
+			unix.InotifyRmWatch(m.watchFd, uint32(wd))
+		}
+	}
+	m.watches = current
+
+	log.Debugf("Watching %d sysfs brightness attributes", len(current))
+}
+
+// pollDDC periodically re-reads monitor brightness, since DDC/CI has no
+// change notifications.
+func (m *Manager) pollDDC() {
+	ticker := time.NewTicker(ddcPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			if m.ddcBackend.refreshBrightness(m.recentlyWritten) {
+				m.syncExternalChanges()
+			}
+		}
+	}
+}
+
+func (m *Manager) syncExternalChanges() {
+	for _, id := range m.updateState() {
+		if m.recentlyWritten(id) {
+			continue
+		}
+		log.Debugf("Brightness of %s changed externally", id)
+		m.broadcastDeviceUpdate(id)
+	}
+}
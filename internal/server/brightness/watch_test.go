@@ -0,0 +1,137 @@
+package brightness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newWatchTestManager(t *testing.T) (*Manager, string) {
+	tmpDir := t.TempDir()
+
+	backlightDir := filepath.Join(tmpDir, "backlight", "test_backlight")
+	if err := os.MkdirAll(backlightDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(backlightDir, "max_brightness"), []byte("100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(backlightDir, "brightness"), []byte("50\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sysfs := &SysfsBackend{
+		basePath:    tmpDir,
+		classes:     []string{"backlight"},
+		deviceCache: make(map[string]*sysfsDevice),
+	}
+	if err := sysfs.scanDevices(); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		sysfsBackend:      sysfs,
+		sysfsReady:        true,
+		subscribers:       make(map[string]chan State),
+		updateSubscribers: make(map[string]chan DeviceUpdate),
+		stopChan:          make(chan struct{}),
+	}
+	m.updateState()
+
+	return m, filepath.Join(backlightDir, "brightness")
+}
+
+func TestManager_WatchSysfs_ExternalChange(t *testing.T) {
+	m, brightnessPath := newWatchTestManager(t)
+	defer close(m.stopChan)
+
+	updates := m.SubscribeUpdates("test")
+	go m.watchSysfs()
+
+	// give the watcher time to register before the external write
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(brightnessPath, []byte("80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case update := <-updates:
+		if update.Device.ID != "backlight:test_backlight" {
+			t.Errorf("unexpected device %s", update.Device.ID)
+		}
+		if update.Device.Current != 80 {
+			t.Errorf("expected current 80, got %d", update.Device.Current)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("external change was not detected")
+	}
+}
+
+func TestManager_Rescan_WatchesHotpluggedDevice(t *testing.T) {
+	m, brightnessPath := newWatchTestManager(t)
+	defer close(m.stopChan)
+
+	updates := m.SubscribeUpdates("test")
+	go m.watchSysfs()
+	time.Sleep(50 * time.Millisecond)
+
+	plugged := filepath.Join(filepath.Dir(filepath.Dir(brightnessPath)), "plugged_backlight")
+	if err := os.MkdirAll(plugged, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(plugged, "max_brightness"), []byte("100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(plugged, "brightness"), []byte("20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.Rescan()
+
+	if err := os.WriteFile(filepath.Join(plugged, "brightness"), []byte("60\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case update := <-updates:
+			if update.Device.ID == "backlight:plugged_backlight" && update.Device.Current == 60 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("change on the hot-plugged device was not detected")
+		}
+	}
+}
+
+func TestManager_SyncExternalChanges_IgnoresLocalWrites(t *testing.T) {
+	m, brightnessPath := newWatchTestManager(t)
+	updates := m.SubscribeUpdates("test")
+
+	m.markLocalWrite("backlight:test_backlight")
+	if err := os.WriteFile(brightnessPath, []byte("30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.syncExternalChanges()
+
+	select {
+	case update := <-updates:
+		t.Errorf("unexpected update for local write: %+v", update)
+	default:
+	}
+
+	if got := m.GetState().Devices[0].Current; got != 30 {
+		t.Errorf("state not refreshed, current = %d", got)
+	}
+}
+
+func TestChangedDeviceIDs(t *testing.T) {
+	old := State{Devices: []Device{{ID: "a", Current: 1, Max: 10}, {ID: "b", Current: 5, Max: 10}}}
+	new := State{Devices: []Device{{ID: "a", Current: 1, Max: 10}, {ID: "b", Current: 6, Max: 10}, {ID: "c", Current: 1, Max: 10}}}
+
+	changed := changedDeviceIDs(old, new)
+	if len(changed) != 2 || changed[0] != "b" || changed[1] != "c" {
+		t.Errorf("expected [b c], got %v", changed)
+	}
+}
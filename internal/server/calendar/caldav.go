@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	fetchTimeout = 30 * time.Second
+	// maxResponseSize caps how much of a remote calendar is read
+	maxResponseSize = 16 << 20
+)
+
+var httpClient = &http.Client{Timeout: fetchTimeout}
+
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// fetchRemote reads a remote source. ICS feeds are fetched whole; CalDAV
+// collections are asked for the events in the window only.
+func fetchRemote(src Source, from, to time.Time) ([]string, error) {
+	url := src.URL
+	if strings.HasPrefix(url, "webcal://") {
+		url = "https://" + strings.TrimPrefix(url, "webcal://")
+	}
+
+	var req *http.Request
+	var err error
+	if src.Kind == KindCalDAV {
+		req, err = http.NewRequest("REPORT", url, bytes.NewBufferString(calendarQuery(from, to)))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+			req.Header.Set("Depth", "1")
+		}
+	} else {
+		req, err = http.NewRequest(http.MethodGet, url, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Username != "" {
+		password, err := sourcePassword(src)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(src.Username, password)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("%s returned %s", src.URL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Kind != KindCalDAV {
+		return []string{string(body)}, nil
+	}
+	return parseMultistatus(body)
+}
+
+func calendarQuery(from, to time.Time) string {
+	const layout = "20060102T150405Z"
+	return fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%s" end="%s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`, from.UTC().Format(layout), to.UTC().Format(layout))
+}
+
+func parseMultistatus(body []byte) ([]string, error) {
+	var ms multistatus
+	if err := xml.Unmarshal(body, &ms); err != nil {
+		return nil, fmt.Errorf("invalid CalDAV response: %w", err)
+	}
+
+	var objects []string
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstat {
+			if ps.Prop.CalendarData != "" && (ps.Status == "" || strings.Contains(ps.Status, " 200 ")) {
+				objects = append(objects, ps.Prop.CalendarData)
+			}
+		}
+	}
+	return objects, nil
+}
+
+func sourcePassword(src Source) (string, error) {
+	if src.PasswordCommand == "" {
+		return src.Password, nil
+	}
+	out, err := exec.Command("sh", "-c", src.PasswordCommand).Output()
+	if err != nil {
+		return "", fmt.Errorf("password-command for %s failed: %w", src.Name, err)
+	}
+	password, _, _ := strings.Cut(string(out), "\n")
+	return password, nil
+}
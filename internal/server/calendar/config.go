@@ -0,0 +1,172 @@
+package calendar
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+)
+
+// ConfigPath is where users list their calendars
+func ConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "calendars.toml")
+}
+
+// parseSources reads calendars.toml, which holds [[calendar]] blocks such as
+//
+//	[[calendar]]
+//	name = "Work"
+//	url = "https://dav.example.com/calendars/me/work/"
+//	username = "me"
+//	password-command = "pass show dav"
+//
+// Invalid blocks are skipped and reported; a file that does not parse
+// yields no sources at all.
+func parseSources(data string) ([]Source, []string, error) {
+	tables, err := tomlite.Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sources []Source
+	var errs []string
+	seen := make(map[string]bool)
+	for i, t := range tables {
+		if t.Name != "calendar" || !t.Array {
+			return nil, nil, fmt.Errorf("line %d: unsupported table %q, only [[calendar]] is allowed", t.Line, t.Name)
+		}
+		src, err := compileSource(i+1, t)
+		if err == nil && seen[src.Name] {
+			err = fmt.Errorf("calendar %q (line %d): duplicate name", src.Name, t.Line)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		seen[src.Name] = true
+		sources = append(sources, src)
+	}
+	return sources, errs, nil
+}
+
+func compileSource(index int, t tomlite.Table) (Source, error) {
+	src := Source{Name: fmt.Sprintf("calendar %d", index)}
+	if name, ok := t.Values["name"].(string); ok && name != "" {
+		src.Name = name
+	}
+	fail := func(format string, args ...any) (Source, error) {
+		return Source{}, fmt.Errorf("calendar %q (line %d): %s", src.Name, t.Line, fmt.Sprintf(format, args...))
+	}
+
+	keys := make([]string, 0, len(t.Values))
+	for key := range t.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	kind := ""
+	for _, key := range keys {
+		value, ok := t.Values[key].(string)
+		if !ok {
+			return fail("%s must be a string", key)
+		}
+		switch key {
+		case "name":
+		case "path":
+			src.Path = expandHome(value)
+		case "url":
+			src.URL = value
+		case "kind":
+			kind = value
+		case "color":
+			src.Color = value
+		case "username":
+			src.Username = value
+		case "password":
+			src.Password = value
+		case "password-command":
+			src.PasswordCommand = value
+		default:
+			return fail("unknown key %s", key)
+		}
+	}
+
+	switch {
+	case src.Path != "" && src.URL != "":
+		return fail("set either path or url, not both")
+	case src.Path != "":
+		if kind != "" && kind != string(KindFile) {
+			return fail("kind %s needs a url", kind)
+		}
+		src.Kind = KindFile
+	case src.URL != "":
+		if !strings.HasPrefix(src.URL, "https://") && !strings.HasPrefix(src.URL, "http://") && !strings.HasPrefix(src.URL, "webcal://") {
+			return fail("url must be http(s) or webcal")
+		}
+		switch SourceKind(kind) {
+		case KindICS, KindCalDAV:
+			src.Kind = SourceKind(kind)
+		case "":
+			// Feeds are usually published as .ics files; anything else is
+			// taken to be a CalDAV collection
+			src.Kind = KindCalDAV
+			if strings.HasPrefix(src.URL, "webcal://") || strings.HasSuffix(strings.ToLower(src.URL), ".ics") {
+				src.Kind = KindICS
+			}
+		default:
+			return fail("kind must be ics or caldav for a url")
+		}
+	default:
+		return fail("needs a path or url")
+	}
+	return src, nil
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// readFileSource returns the contents of an .ics file, or of every .ics
+// file under a directory
+func readFileSource(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(data)}, nil
+	}
+
+	var objects []string
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".ics") {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, string(data))
+		return nil
+	})
+	return objects, err
+}
@@ -0,0 +1,67 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "calendar.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "calendar.upcoming":
+		handleUpcoming(conn, req, manager)
+	case "calendar.refresh":
+		manager.Refresh()
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "refresh started"})
+	case "calendar.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleUpcoming(conn net.Conn, req Request, manager *Manager) {
+	days := DefaultDays
+	if v, ok := req.Params["days"].(float64); ok {
+		days = int(v)
+	}
+	limit := 0
+	if v, ok := req.Params["limit"].(float64); ok {
+		limit = int(v)
+	}
+	calendar, _ := req.Params["calendar"].(string)
+
+	events, err := manager.Upcoming(days, limit, calendar)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, events)
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,567 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds recurrence expansion so a rule without an end cannot
+// spin forever
+const maxPeriods = 5000
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      *time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+}
+
+type vevent struct {
+	uid          string
+	summary      string
+	location     string
+	description  string
+	status       string
+	start        time.Time
+	end          *time.Time
+	duration     time.Duration
+	allDay       bool
+	rule         *rrule
+	exdates      []time.Time
+	recurrenceID *time.Time
+}
+
+// ParseICS reads the VEVENTs of an iCalendar object and returns the
+// occurrences that overlap [from, to). TZIDs are resolved against the
+// system zoneinfo; unknown ones, and floating times, use local time.
+func ParseICS(data string, src Source, from, to time.Time) ([]Event, error) {
+	vevents, err := parseVEvents(data)
+	if err != nil {
+		return nil, err
+	}
+
+	overridden := make(map[string]bool)
+	for _, ev := range vevents {
+		if ev.recurrenceID != nil {
+			overridden[occurrenceKey(ev.uid, *ev.recurrenceID)] = true
+		}
+	}
+
+	var events []Event
+	for _, ev := range vevents {
+		if ev.status == "CANCELLED" {
+			continue
+		}
+		for _, start := range ev.occurrences(from, to) {
+			if ev.recurrenceID == nil && ev.rule != nil && overridden[occurrenceKey(ev.uid, start)] {
+				continue
+			}
+			events = append(events, ev.event(src, start))
+		}
+	}
+
+	sortEvents(events)
+	return events, nil
+}
+
+func occurrenceKey(uid string, start time.Time) string {
+	return fmt.Sprintf("%s@%d", uid, start.Unix())
+}
+
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Start.Equal(events[j].Start) {
+			return events[i].Start.Before(events[j].Start)
+		}
+		return events[i].Summary < events[j].Summary
+	})
+}
+
+func parseVEvents(data string) ([]vevent, error) {
+	var (
+		events []vevent
+		cur    *vevent
+		// depth counts components nested in the current VEVENT, e.g. VALARM
+		depth int
+	)
+
+	for i, line := range unfold(data) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && cur == nil:
+			cur = &vevent{}
+			continue
+		case prop.name == "BEGIN" && cur != nil:
+			depth++
+			continue
+		case prop.name == "END" && cur != nil && depth > 0:
+			depth--
+			continue
+		case prop.name == "END" && cur != nil && strings.EqualFold(prop.value, "VEVENT"):
+			if cur.start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", i+1, cur.summary)
+			}
+			if cur.uid == "" {
+				cur.uid = fmt.Sprintf("%s-%d", cur.summary, cur.start.Unix())
+			}
+			events = append(events, *cur)
+			cur = nil
+			continue
+		}
+		if cur == nil || depth > 0 {
+			continue
+		}
+
+		if err := cur.set(prop); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", i+1, prop.name, err)
+		}
+	}
+
+	if cur != nil {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines, which start with a space or tab
+func unfold(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return lines
+}
+
+func parseProperty(line string) (property, error) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+func (ev *vevent) set(prop property) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		ev.uid = prop.value
+	case "SUMMARY":
+		ev.summary = unescapeText(prop.value)
+	case "LOCATION":
+		ev.location = unescapeText(prop.value)
+	case "DESCRIPTION":
+		ev.description = unescapeText(prop.value)
+	case "STATUS":
+		ev.status = strings.ToUpper(prop.value)
+	case "DTSTART":
+		ev.start, ev.allDay, err = parseDateTime(prop)
+	case "DTEND":
+		var end time.Time
+		end, _, err = parseDateTime(prop)
+		ev.end = &end
+	case "DURATION":
+		ev.duration, err = parseDuration(prop.value)
+	case "RRULE":
+		ev.rule, err = parseRRule(prop.value)
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exdate, _, perr := parseDateTime(property{params: prop.params, value: value})
+			if perr != nil {
+				return perr
+			}
+			ev.exdates = append(ev.exdates, exdate)
+		}
+	case "RECURRENCE-ID":
+		var id time.Time
+		id, _, err = parseDateTime(prop)
+		ev.recurrenceID = &id
+	}
+	return err
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+func parseDateTime(prop property) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+	if prop.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+
+	loc := time.Local
+	if strings.HasSuffix(value, "Z") {
+		loc = time.UTC
+		value = strings.TrimSuffix(value, "Z")
+	} else if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// parseDuration reads an RFC 5545 duration such as PT1H30M or P1D
+func parseDuration(s string) (time.Duration, error) {
+	orig := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+
+	var total time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r == 'T':
+			inTime = true
+		case r >= '0' && r <= '9':
+			num += string(r)
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			num = ""
+			unit := map[rune]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}[r]
+			if inTime {
+				unit = map[rune]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}[r]
+			}
+			if unit == 0 {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			total += time.Duration(n) * unit
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	return sign * total, nil
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRRule(s string) (*rrule, error) {
+	r := &rrule{interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+			r.count = n
+		case "UNTIL":
+			until, _, err := parseDateTime(property{value: value})
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", value)
+			}
+			r.until = &until
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				d = strings.ToUpper(d)
+				if len(d) < 2 {
+					return nil, fmt.Errorf("invalid BYDAY %q", value)
+				}
+				day, ok := weekdays[d[len(d)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", value)
+				}
+				n := 0
+				if prefix := d[:len(d)-2]; prefix != "" {
+					var err error
+					if n, err = strconv.Atoi(prefix); err != nil {
+						return nil, fmt.Errorf("invalid BYDAY %q", value)
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n, day})
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", value)
+				}
+				r.byMonthDay = append(r.byMonthDay, n)
+			}
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+		return r, nil
+	}
+	return nil, fmt.Errorf("unsupported FREQ %q", r.freq)
+}
+
+// length is how long each occurrence lasts. All-day events without an end
+// last one day.
+func (ev *vevent) length() time.Duration {
+	switch {
+	case ev.end != nil:
+		return ev.end.Sub(ev.start)
+	case ev.duration != 0:
+		return ev.duration
+	case ev.allDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+func (ev *vevent) endOf(start time.Time) time.Time {
+	if ev.allDay {
+		days := int((ev.length() + 12*time.Hour) / (24 * time.Hour))
+		return start.AddDate(0, 0, max(days, 1))
+	}
+	return start.Add(ev.length())
+}
+
+func (ev *vevent) event(src Source, start time.Time) Event {
+	return Event{
+		ID:          occurrenceKey(ev.uid, start),
+		UID:         ev.uid,
+		Calendar:    src.Name,
+		Color:       src.Color,
+		Summary:     ev.summary,
+		Location:    ev.location,
+		Description: ev.description,
+		Start:       start,
+		End:         ev.endOf(start),
+		AllDay:      ev.allDay,
+		Recurring:   ev.rule != nil || ev.recurrenceID != nil,
+	}
+}
+
+func (ev *vevent) overlaps(start, from, to time.Time) bool {
+	end := ev.endOf(start)
+	if end.Equal(start) {
+		return !start.Before(from) && start.Before(to)
+	}
+	return start.Before(to) && end.After(from)
+}
+
+func (ev *vevent) excluded(start time.Time) bool {
+	for _, ex := range ev.exdates {
+		if ex.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// occurrences lists the start times of the event that overlap [from, to)
+func (ev *vevent) occurrences(from, to time.Time) []time.Time {
+	if ev.rule == nil || ev.recurrenceID != nil {
+		if ev.overlaps(ev.start, from, to) {
+			return []time.Time{ev.start}
+		}
+		return nil
+	}
+
+	r := ev.rule
+	var starts []time.Time
+	count := 0
+	first := 0
+	if r.count == 0 {
+		// Without COUNT nothing before the window matters, so old rules
+		// start expanding just ahead of it
+		first = r.periodsBefore(ev.start, from)
+	}
+	for k := first; k < first+maxPeriods; k++ {
+		candidates, periodStart := r.period(ev.start, k)
+		if !periodStart.Before(to) {
+			break
+		}
+		for _, c := range candidates {
+			if c.Before(ev.start) {
+				continue
+			}
+			if r.until != nil && c.After(*r.until) {
+				return starts
+			}
+			count++
+			if r.count > 0 && count > r.count {
+				return starts
+			}
+			if !ev.excluded(c) && ev.overlaps(c, from, to) {
+				starts = append(starts, c)
+			}
+		}
+	}
+	return starts
+}
+
+// periodsBefore estimates how many whole intervals lie between start and
+// from, erring on the low side
+func (r *rrule) periodsBefore(start, from time.Time) int {
+	if !from.After(start) {
+		return 0
+	}
+	var n int
+	switch r.freq {
+	case "DAILY":
+		n = int(from.Sub(start).Hours() / 24)
+	case "WEEKLY":
+		n = int(from.Sub(start).Hours() / (24 * 7))
+	case "MONTHLY":
+		n = (from.Year()-start.Year())*12 + int(from.Month()-start.Month())
+	default:
+		n = from.Year() - start.Year()
+	}
+	return max(n/r.interval-1, 0)
+}
+
+// period returns the candidate starts in the k-th interval of the rule,
+// in order, and the time that interval begins
+func (r *rrule) period(start time.Time, k int) ([]time.Time, time.Time) {
+	h, m, s := start.Clock()
+	loc := start.Location()
+	step := k * r.interval
+
+	switch r.freq {
+	case "DAILY":
+		c := start.AddDate(0, 0, step)
+		return []time.Time{c}, c
+	case "WEEKLY":
+		if len(r.byDay) == 0 {
+			c := start.AddDate(0, 0, 7*step)
+			return []time.Time{c}, c
+		}
+		sinceMonday := (int(start.Weekday()) + 6) % 7
+		week := start.AddDate(0, 0, 7*step-sinceMonday)
+		var out []time.Time
+		for _, d := range r.byDay {
+			out = append(out, week.AddDate(0, 0, (int(d.day)+6)%7))
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+		return out, week
+	case "MONTHLY":
+		first := time.Date(start.Year(), start.Month()+time.Month(step), 1, h, m, s, 0, loc)
+		days := daysIn(first)
+		var out []time.Time
+		switch {
+		case len(r.byDay) > 0:
+			for _, d := range r.byDay {
+				for _, day := range weekdayDays(first, days, d) {
+					out = append(out, first.AddDate(0, 0, day-1))
+				}
+			}
+		case len(r.byMonthDay) > 0:
+			for _, day := range r.byMonthDay {
+				if day < 0 {
+					day = days + day + 1
+				}
+				if day >= 1 && day <= days {
+					out = append(out, first.AddDate(0, 0, day-1))
+				}
+			}
+		default:
+			if start.Day() <= days {
+				out = append(out, first.AddDate(0, 0, start.Day()-1))
+			}
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+		return out, first
+	default:
+		c := time.Date(start.Year()+step, start.Month(), start.Day(), h, m, s, 0, loc)
+		periodStart := time.Date(start.Year()+step, 1, 1, 0, 0, 0, 0, loc)
+		if c.Month() != start.Month() {
+			// Feb 29 in a year without one
+			return nil, periodStart
+		}
+		return []time.Time{c}, periodStart
+	}
+}
+
+func daysIn(first time.Time) int {
+	return first.AddDate(0, 1, -1).Day()
+}
+
+// weekdayDays lists the days of the month matching a BYDAY entry: every
+// such weekday, or only the n-th (counted from the end when negative)
+func weekdayDays(first time.Time, days int, d weekdayNum) []int {
+	var matches []int
+	for day := 1 + (int(d.day)-int(first.Weekday())+7)%7; day <= days; day += 7 {
+		matches = append(matches, day)
+	}
+	switch {
+	case d.n == 0:
+		return matches
+	case d.n > 0 && d.n <= len(matches):
+		return []int{matches[d.n-1]}
+	case d.n < 0 && -d.n <= len(matches):
+		return []int{matches[len(matches)+d.n]}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ics(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+func window() (time.Time, time.Time) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 1, 0)
+}
+
+func starts(events []Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.Start.UTC().Format("01-02 15:04")
+	}
+	return out
+}
+
+func TestParseICS_Single(t *testing.T) {
+	from, to := window()
+	data := ics("BEGIN:VEVENT\r\n" +
+		"UID:a1\r\n" +
+		"SUMMARY:Standup\\, daily\r\n" +
+		"DESCRIPTION:Line one\\nline two that is folded\r\n" +
+		"  across lines\r\n" +
+		"LOCATION:Room 4\r\n" +
+		"DTSTART:20250303T090000Z\r\n" +
+		"DTEND:20250303T091500Z\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nSUMMARY:ignored\r\nEND:VALARM\r\n" +
+		"END:VEVENT\r\n")
+
+	events, err := ParseICS(data, Source{Name: "Work", Color: "#ff0000"}, from, to)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	e := events[0]
+	assert.Equal(t, "Standup, daily", e.Summary)
+	assert.Equal(t, "Line one\nline two that is folded across lines", e.Description)
+	assert.Equal(t, "Room 4", e.Location)
+	assert.Equal(t, "Work", e.Calendar)
+	assert.Equal(t, "#ff0000", e.Color)
+	assert.Equal(t, 15*time.Minute, e.End.Sub(e.Start))
+	assert.False(t, e.AllDay)
+	assert.False(t, e.Recurring)
+}
+
+func TestParseICS_AllDayAndTimezones(t *testing.T) {
+	from, to := window()
+	data := ics(
+		"BEGIN:VEVENT\r\nUID:d\r\nSUMMARY:Holiday\r\nDTSTART;VALUE=DATE:20250310\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nUID:tz\r\nSUMMARY:Call\r\nDTSTART;TZID=Europe/Berlin:20250311T100000\r\nDURATION:PT1H30M\r\nEND:VEVENT\r\n",
+	)
+
+	events, err := ParseICS(data, Source{}, from, to)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.True(t, events[0].AllDay)
+	assert.Equal(t, events[0].Start.AddDate(0, 0, 1), events[0].End)
+
+	assert.Equal(t, time.Date(2025, 3, 11, 9, 0, 0, 0, time.UTC), events[1].Start.UTC())
+	assert.Equal(t, 90*time.Minute, events[1].End.Sub(events[1].Start))
+}
+
+func TestParseICS_Recurrence(t *testing.T) {
+	from, to := window()
+
+	tests := []struct {
+		name  string
+		rule  string
+		extra string
+		want  []string
+	}{
+		{
+			name: "weekly by day with count",
+			rule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			want: []string{"03-03 09:00", "03-05 09:00", "03-10 09:00", "03-12 09:00"},
+		},
+		{
+			name: "daily until",
+			rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20250308T090000Z",
+			want: []string{"03-03 09:00", "03-05 09:00", "03-07 09:00"},
+		},
+		{
+			name:  "exdate",
+			rule:  "FREQ=WEEKLY;COUNT=3",
+			extra: "EXDATE:20250310T090000Z\r\n",
+			want:  []string{"03-03 09:00", "03-17 09:00"},
+		},
+		{
+			name: "second tuesday",
+			rule: "FREQ=MONTHLY;BYDAY=2TU",
+			want: []string{"03-11 09:00"},
+		},
+		{
+			name: "last friday",
+			rule: "FREQ=MONTHLY;BYDAY=-1FR",
+			want: []string{"03-28 09:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := ics("BEGIN:VEVENT\r\nUID:r\r\nSUMMARY:R\r\nDTSTART:20250303T090000Z\r\nDTEND:20250303T100000Z\r\n" +
+				"RRULE:" + tt.rule + "\r\n" + tt.extra + "END:VEVENT\r\n")
+			events, err := ParseICS(data, Source{}, from, to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, starts(events))
+			for _, e := range events {
+				assert.True(t, e.Recurring)
+			}
+		})
+	}
+}
+
+func TestParseICS_OldRuleStillExpands(t *testing.T) {
+	from, to := window()
+	data := ics("BEGIN:VEVENT\r\nUID:old\r\nSUMMARY:Old\r\nDTSTART:19900101T080000Z\r\nRRULE:FREQ=DAILY\r\nEND:VEVENT\r\n")
+
+	events, err := ParseICS(data, Source{}, from, to)
+	require.NoError(t, err)
+	assert.Len(t, events, 31)
+}
+
+func TestParseICS_OverriddenOccurrence(t *testing.T) {
+	from, to := window()
+	data := ics(
+		"BEGIN:VEVENT\r\nUID:s\r\nSUMMARY:Sync\r\nDTSTART:20250303T090000Z\r\nRRULE:FREQ=WEEKLY;COUNT=3\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nUID:s\r\nSUMMARY:Sync (moved)\r\nRECURRENCE-ID:20250310T090000Z\r\nDTSTART:20250311T140000Z\r\nEND:VEVENT\r\n",
+		"BEGIN:VEVENT\r\nUID:c\r\nSUMMARY:Cancelled\r\nSTATUS:CANCELLED\r\nDTSTART:20250304T090000Z\r\nEND:VEVENT\r\n",
+	)
+
+	events, err := ParseICS(data, Source{}, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"03-03 09:00", "03-11 14:00", "03-17 09:00"}, starts(events))
+	assert.Equal(t, "Sync (moved)", events[1].Summary)
+}
+
+func TestParseICS_Errors(t *testing.T) {
+	from, to := window()
+
+	_, err := ParseICS(ics("BEGIN:VEVENT\r\nUID:x\r\nSUMMARY:No start\r\nEND:VEVENT\r\n"), Source{}, from, to)
+	assert.Error(t, err)
+
+	_, err = ParseICS(ics("BEGIN:VEVENT\r\nDTSTART:20250303T090000Z\r\nRRULE:FREQ=HOURLY\r\nEND:VEVENT\r\n"), Source{}, from, to)
+	assert.Error(t, err)
+
+	_, err = ParseICS("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20250303T090000Z\r\n", Source{}, from, to)
+	assert.Error(t, err)
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := parseDuration("P1DT2H")
+	require.NoError(t, err)
+	assert.Equal(t, 26*time.Hour, d)
+
+	d, err = parseDuration("-PT15M")
+	require.NoError(t, err)
+	assert.Equal(t, -15*time.Minute, d)
+
+	_, err = parseDuration("1H")
+	assert.Error(t, err)
+}
@@ -0,0 +1,388 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	// Horizon is how far ahead events are expanded and cached
+	Horizon = 90 * 24 * time.Hour
+	// DefaultDays is the window pushed to subscribers
+	DefaultDays = 14
+
+	DefaultRefreshInterval = 15 * time.Minute
+	// tick is how often local files are re-read and ended events dropped
+	tick = time.Minute
+	// retryDelay is how soon a failed remote source is tried again
+	retryDelay = 5 * time.Minute
+)
+
+func NewManager() (*Manager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+
+	m := newManager(ConfigPath(), filepath.Join(stateHome, "DankMaterialShell", "calendar.json"), time.Now, fetchRemote)
+	m.loadCache()
+
+	go m.loop()
+
+	return m, nil
+}
+
+func newManager(configPath, cachePath string, now func() time.Time, fetch Fetcher) *Manager {
+	return &Manager{
+		now:          now,
+		fetch:        fetch,
+		configPath:   configPath,
+		cachePath:    cachePath,
+		cache:        make(map[string]*sourceCache),
+		subscribers:  make(map[string]chan State),
+		intervalChan: make(chan time.Duration, 1),
+		wakeChan:     make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// loadCache restores events from the last run so remote calendars show up
+// before the first sync, or while offline
+func (m *Manager) loadCache() {
+	data, err := os.ReadFile(m.cachePath)
+	if err != nil {
+		return
+	}
+
+	var cache map[string]*sourceCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		log.Warnf("Ignoring corrupt calendar cache %s: %v", m.cachePath, err)
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for name, c := range cache {
+		if c != nil {
+			m.cache[name] = c
+		}
+	}
+}
+
+// saveCache must be called with the mutex held
+func (m *Manager) saveCache() {
+	data, err := json.Marshal(m.cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.cachePath), 0755); err != nil {
+		log.Warnf("Failed to create %s: %v", filepath.Dir(m.cachePath), err)
+		return
+	}
+	tmp := m.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Warnf("Failed to save calendar cache: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, m.cachePath); err != nil {
+		log.Warnf("Failed to save calendar cache: %v", err)
+	}
+}
+
+// SetRefreshInterval changes how often remote calendars are synced
+func (m *Manager) SetRefreshInterval(interval time.Duration) error {
+	if interval < time.Minute {
+		return fmt.Errorf("refresh interval too short: %s", interval)
+	}
+	select {
+	case <-m.intervalChan:
+	default:
+	}
+	select {
+	case m.intervalChan <- interval:
+	default:
+	}
+	return nil
+}
+
+// Refresh re-reads calendars.toml and syncs every source now
+func (m *Manager) Refresh() {
+	select {
+	case m.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) loop() {
+	defer crash.Capture("calendar.loop", nil)
+
+	interval := DefaultRefreshInterval
+	m.refresh(interval, true)
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case interval = <-m.intervalChan:
+		case <-m.wakeChan:
+			m.refresh(interval, true)
+		case <-ticker.C:
+			m.refresh(interval, false)
+		}
+	}
+}
+
+// refresh reloads the config, re-reads local files and syncs remote
+// sources that are due. Network requests run without the mutex held.
+func (m *Manager) refresh(interval time.Duration, force bool) {
+	sources, errs := m.readConfig()
+	now := m.now()
+	from, to := startOfDay(now), now.Add(Horizon)
+
+	m.mutex.Lock()
+	before := m.fingerprint(now)
+	var due []Source
+	for _, src := range sources {
+		c := m.cache[src.Name]
+		if src.Kind == KindFile || force || c == nil || c.LastSync == nil ||
+			now.Sub(*c.LastSync) >= interval || (c.err != "" && now.Sub(*c.LastSync) >= retryDelay) {
+			due = append(due, src)
+		}
+	}
+	m.mutex.Unlock()
+
+	results := make(map[string]*sourceCache)
+	for _, src := range due {
+		events, err := m.load(src, from, to)
+		synced := now
+		result := &sourceCache{Events: events, LastSync: &synced}
+		if err != nil {
+			log.Warnf("Calendar %s: %v", src.Name, err)
+			result.err = err.Error()
+		}
+		results[src.Name] = result
+	}
+
+	m.mutex.Lock()
+	m.sources = sources
+	m.errors = errs
+	kept := make(map[string]*sourceCache)
+	for _, src := range sources {
+		c, ok := results[src.Name]
+		switch {
+		case ok && c.err != "" && m.cache[src.Name] != nil:
+			// Keep showing the last good events while a server is down
+			prev := *m.cache[src.Name]
+			prev.err = c.err
+			if src.Kind != KindFile {
+				prev.LastSync = c.LastSync
+			}
+			kept[src.Name] = &prev
+		case ok:
+			kept[src.Name] = c
+		case m.cache[src.Name] != nil:
+			kept[src.Name] = m.cache[src.Name]
+		}
+	}
+	m.cache = kept
+	m.updatedAt = now
+	if len(results) > 0 {
+		m.saveCache()
+	}
+
+	changed := m.fingerprint(now) != before
+	m.mutex.Unlock()
+
+	if changed {
+		m.notifySubscribers()
+	}
+}
+
+func (m *Manager) readConfig() ([]Source, []string) {
+	data, err := os.ReadFile(m.configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []string{fmt.Sprintf("failed to read %s: %v", m.configPath, err)}
+	}
+
+	sources, errs, err := parseSources(string(data))
+	if err != nil {
+		return nil, []string{fmt.Sprintf("failed to parse %s: %v", m.configPath, err)}
+	}
+	return sources, errs
+}
+
+func (m *Manager) load(src Source, from, to time.Time) ([]Event, error) {
+	var objects []string
+	var err error
+	if src.Kind == KindFile {
+		objects, err = readFileSource(src.Path)
+	} else {
+		objects, err = m.fetch(src, from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var problems []string
+	for _, obj := range objects {
+		parsed, err := ParseICS(obj, src, from, to)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		events = append(events, parsed...)
+	}
+	sortEvents(events)
+
+	if len(problems) > 0 && len(events) == 0 {
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return events, nil
+}
+
+// fingerprint summarizes what subscribers see, to skip updates when a
+// refresh changed nothing. Must be called with the mutex held.
+func (m *Manager) fingerprint(now time.Time) string {
+	var b strings.Builder
+	for _, e := range m.upcoming(now, DefaultDays, 0, "") {
+		fmt.Fprintf(&b, "%s|%s|%s|%d\n", e.ID, e.Summary, e.Location, e.End.Unix())
+	}
+	for _, src := range m.sources {
+		if c := m.cache[src.Name]; c != nil {
+			fmt.Fprintf(&b, "%s:%s\n", src.Name, c.err)
+		}
+	}
+	for _, e := range m.errors {
+		b.WriteString(e)
+	}
+	return b.String()
+}
+
+// upcoming must be called with the mutex held
+func (m *Manager) upcoming(now time.Time, days, limit int, calendar string) []Event {
+	to := now.AddDate(0, 0, days)
+	events := []Event{}
+	for name, c := range m.cache {
+		if calendar != "" && name != calendar {
+			continue
+		}
+		for _, e := range c.Events {
+			if e.End.After(now) && e.Start.Before(to) {
+				events = append(events, e)
+			}
+		}
+	}
+	sortEvents(events)
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events
+}
+
+// Upcoming returns events that have not ended yet and start within the
+// given number of days, soonest first
+func (m *Manager) Upcoming(days, limit int, calendar string) ([]Event, error) {
+	if days < 1 || days > int(Horizon/(24*time.Hour)) {
+		return nil, fmt.Errorf("days must be between 1 and %d", int(Horizon/(24*time.Hour)))
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if calendar != "" {
+		if _, ok := m.cache[calendar]; !ok {
+			return nil, fmt.Errorf("unknown calendar: %s", calendar)
+		}
+	}
+	return m.upcoming(m.now(), days, limit, calendar), nil
+}
+
+func (m *Manager) GetState() State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := State{
+		Path:      m.configPath,
+		Sources:   []SourceStatus{},
+		Events:    m.upcoming(m.now(), DefaultDays, 0, ""),
+		Errors:    append([]string(nil), m.errors...),
+		UpdatedAt: m.updatedAt,
+	}
+	for _, src := range m.sources {
+		status := SourceStatus{Source: src}
+		if c := m.cache[src.Name]; c != nil {
+			status.Events = len(c.Events)
+			status.LastSync = c.LastSync
+			status.Error = c.err
+		}
+		state.Sources = append(state.Sources, status)
+	}
+	return state
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	state := m.GetState()
+
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,179 @@
+package calendar
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func event(uid, summary, start string) string {
+	return ics("BEGIN:VEVENT\r\nUID:" + uid + "\r\nSUMMARY:" + summary + "\r\nDTSTART:" + start + "\r\nDURATION:PT1H\r\nEND:VEVENT\r\n")
+}
+
+func TestParseSources(t *testing.T) {
+	sources, errs, err := parseSources(`
+[[calendar]]
+name = "Personal"
+path = "/home/me/.calendars/personal"
+
+[[calendar]]
+name = "Holidays"
+url = "webcal://example.com/holidays.ics"
+
+[[calendar]]
+name = "Work"
+url = "https://dav.example.com/me/work/"
+username = "me"
+password-command = "pass dav"
+
+[[calendar]]
+name = "Broken"
+
+[[calendar]]
+name = "Work"
+path = "/tmp/dup.ics"
+`)
+	require.NoError(t, err)
+	require.Len(t, sources, 3)
+	assert.Equal(t, KindFile, sources[0].Kind)
+	assert.Equal(t, KindICS, sources[1].Kind)
+	assert.Equal(t, KindCalDAV, sources[2].Kind)
+	assert.Equal(t, "pass dav", sources[2].PasswordCommand)
+	assert.Len(t, errs, 2)
+
+	_, _, err = parseSources("[settings]\nfoo = 1\n")
+	assert.Error(t, err)
+}
+
+func TestManager_FileSources(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)}
+
+	writeFile(t, filepath.Join(dir, "cal", "a.ics"), event("a", "Past", "20250303T080000Z"))
+	writeFile(t, filepath.Join(dir, "cal", "b.ics"), event("b", "Lunch", "20250303T113000Z"))
+	writeFile(t, filepath.Join(dir, "cal", "sub", "c.ics"), event("c", "Review", "20250305T150000Z"))
+	writeFile(t, filepath.Join(dir, "cal", "notes.txt"), "not a calendar")
+	writeFile(t, filepath.Join(dir, "calendars.toml"), "[[calendar]]\nname = \"Home\"\npath = \""+filepath.Join(dir, "cal")+"\"\n")
+
+	m := newManager(filepath.Join(dir, "calendars.toml"), filepath.Join(dir, "cache.json"), clock.now, nil)
+	updates := m.Subscribe("test")
+	m.refresh(DefaultRefreshInterval, true)
+
+	state := <-updates
+	require.Len(t, state.Sources, 1)
+	assert.Equal(t, 3, state.Sources[0].Events)
+	require.Len(t, state.Events, 2, "events that already ended are left out")
+	assert.Equal(t, "Lunch", state.Events[0].Summary)
+	assert.Equal(t, "Review", state.Events[1].Summary)
+
+	events, err := m.Upcoming(1, 0, "")
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	_, err = m.Upcoming(1, 0, "Nope")
+	assert.Error(t, err)
+	_, err = m.Upcoming(0, 0, "")
+	assert.Error(t, err)
+
+	m.refresh(DefaultRefreshInterval, false)
+	select {
+	case <-updates:
+		t.Fatal("unchanged refresh should not notify")
+	default:
+	}
+}
+
+func TestManager_CalDAV(t *testing.T) {
+	var method, depth, user string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, depth = r.Method, r.Header.Get("Depth")
+		user, _, _ = r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `<c:time-range start="20250303T000000Z"`)
+
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:response>
+    <d:href>/me/work/1.ics</d:href>
+    <d:propstat>
+      <d:prop><c:calendar-data>`+event("w1", "Planning", "20250304T100000Z")+`</c:calendar-data></d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "calendars.toml"), "[[calendar]]\nname = \"Work\"\nurl = \""+server.URL+"/me/work/\"\nusername = \"me\"\npassword-command = \"echo secret\"\n")
+	clock := &fakeClock{t: time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)}
+
+	m := newManager(filepath.Join(dir, "calendars.toml"), filepath.Join(dir, "cache.json"), clock.now, fetchRemote)
+	m.refresh(DefaultRefreshInterval, true)
+
+	assert.Equal(t, "REPORT", method)
+	assert.Equal(t, "1", depth)
+	assert.Equal(t, "me", user)
+
+	state := m.GetState()
+	require.Len(t, state.Events, 1)
+	assert.Equal(t, "Planning", state.Events[0].Summary)
+	assert.Equal(t, "Work", state.Events[0].Calendar)
+}
+
+func TestManager_KeepsCacheWhenOffline(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "calendars.toml"), "[[calendar]]\nname = \"Feed\"\nurl = \"https://example.com/feed.ics\"\n")
+	clock := &fakeClock{t: time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)}
+
+	calls := 0
+	online := true
+	fetch := func(src Source, from, to time.Time) ([]string, error) {
+		calls++
+		if !online {
+			return nil, errors.New("network unreachable")
+		}
+		return []string{event("f1", "Concert", "20250306T190000Z")}, nil
+	}
+
+	m := newManager(filepath.Join(dir, "calendars.toml"), filepath.Join(dir, "cache.json"), clock.now, fetch)
+	m.refresh(DefaultRefreshInterval, true)
+	require.Len(t, m.GetState().Events, 1)
+
+	// Not due yet
+	clock.t = clock.t.Add(time.Minute)
+	m.refresh(DefaultRefreshInterval, false)
+	assert.Equal(t, 1, calls)
+
+	online = false
+	clock.t = clock.t.Add(DefaultRefreshInterval)
+	m.refresh(DefaultRefreshInterval, false)
+	state := m.GetState()
+	require.Len(t, state.Events, 1)
+	assert.Equal(t, "network unreachable", state.Sources[0].Error)
+
+	// A restarted daemon shows the cached events before the first sync
+	restored := newManager(m.configPath, m.cachePath, clock.now, fetch)
+	restored.loadCache()
+	restored.sources = m.sources
+	assert.Len(t, restored.GetState().Events, 1)
+}
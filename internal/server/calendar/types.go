@@ -0,0 +1,106 @@
+package calendar
+
+import (
+	"sync"
+	"time"
+)
+
+type SourceKind string
+
+const (
+	// KindFile is a local .ics file or a directory of them, such as a
+	// vdirsyncer collection
+	KindFile SourceKind = "file"
+	// KindICS is a remote .ics feed fetched with a plain GET
+	KindICS SourceKind = "ics"
+	// KindCalDAV is a CalDAV calendar collection queried with REPORT
+	KindCalDAV SourceKind = "caldav"
+)
+
+// Source is one [[calendar]] block in calendars.toml. The daemon only ever
+// reads from a source.
+type Source struct {
+	Name     string     `json:"name"`
+	Kind     SourceKind `json:"kind"`
+	Path     string     `json:"path,omitempty"`
+	URL      string     `json:"url,omitempty"`
+	Color    string     `json:"color,omitempty"`
+	Username string     `json:"-"`
+	// Password is used as is; PasswordCommand is run through sh and its
+	// first line of output is used instead, to keep secrets out of the file
+	Password        string `json:"-"`
+	PasswordCommand string `json:"-"`
+}
+
+// Event is a single occurrence. Recurring events are expanded, so each
+// occurrence has its own entry sharing the UID.
+type Event struct {
+	ID          string    `json:"id"`
+	UID         string    `json:"uid"`
+	Calendar    string    `json:"calendar"`
+	Color       string    `json:"color,omitempty"`
+	Summary     string    `json:"summary"`
+	Location    string    `json:"location,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	AllDay      bool      `json:"allDay"`
+	Recurring   bool      `json:"recurring,omitempty"`
+}
+
+type SourceStatus struct {
+	Source
+	Events   int        `json:"events"`
+	LastSync *time.Time `json:"lastSync,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+type State struct {
+	Path      string         `json:"path"`
+	Sources   []SourceStatus `json:"sources"`
+	Events    []Event        `json:"events"`
+	Errors    []string       `json:"errors,omitempty"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// Fetcher reads the raw calendar objects of a remote source between two
+// times. It is swapped out in tests.
+type Fetcher func(src Source, from, to time.Time) ([]string, error)
+
+type sourceCache struct {
+	Events   []Event    `json:"events"`
+	LastSync *time.Time `json:"lastSync,omitempty"`
+	err      string
+}
+
+type Manager struct {
+	now        func() time.Time
+	fetch      Fetcher
+	configPath string
+	cachePath  string
+
+	mutex     sync.Mutex
+	sources   []Source
+	errors    []string
+	cache     map[string]*sourceCache
+	updatedAt time.Time
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+
+	intervalChan chan time.Duration
+	wakeChan     chan struct{}
+	stopChan     chan struct{}
+	stopOnce     sync.Once
+}
@@ -1,6 +1,7 @@
 package cups
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -63,12 +64,15 @@ func (m *Manager) GetJobs(printerName string, whichJobs string) ([]Job, error) {
 
 	jobs := make([]Job, 0, len(jobAttrs))
 	for _, attrs := range jobAttrs {
+		state, ippState := parseJobState(attrs)
 		job := Job{
-			ID:    getIntAttr(attrs, ipp.AttributeJobID),
-			Name:  getStringAttr(attrs, ipp.AttributeJobName),
-			State: parseJobState(attrs),
-			User:  getStringAttr(attrs, ipp.AttributeJobOriginatingUserName),
-			Size:  getIntAttr(attrs, ipp.AttributeJobKilobyteOctets) * 1024,
+			ID:        getIntAttr(attrs, ipp.AttributeJobID),
+			Name:      getStringAttr(attrs, ipp.AttributeJobName),
+			State:     state,
+			StateInfo: state.Info(),
+			IPPState:  ippState,
+			User:      getStringAttr(attrs, ipp.AttributeJobOriginatingUserName),
+			Size:      getIntAttr(attrs, ipp.AttributeJobKilobyteOctets) * 1024,
 		}
 
 		if uri := getStringAttr(attrs, ipp.AttributeJobPrinterURI); uri != "" {
@@ -102,6 +106,21 @@ func (m *Manager) ResumePrinter(printerName string) error {
 	return m.client.ResumePrinter(printerName)
 }
 
+// DeletePrinter removes a queue and any jobs still in it
+func (m *Manager) DeletePrinter(printerName string) error {
+	if m.config == nil {
+		return fmt.Errorf("printer administration is not available")
+	}
+	if _, err := m.config.lpadmin("-x", printerName); err != nil {
+		return fmt.Errorf("failed to delete printer %s: %w", printerName, err)
+	}
+
+	if err := m.updateState(); err == nil {
+		m.notifySubscribers()
+	}
+	return nil
+}
+
 func (m *Manager) PurgeJobs(printerName string) error {
 	return m.client.CancelAllJob(printerName, true)
 }
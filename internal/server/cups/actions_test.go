@@ -125,7 +125,9 @@ func TestManager_GetJobs(t *testing.T) {
 				if len(got) > 0 {
 					assert.Equal(t, 1, got[0].ID)
 					assert.Equal(t, "test-job", got[0].Name)
-					assert.Equal(t, "processing", got[0].State)
+					assert.Equal(t, JobProcessing, got[0].State)
+					assert.Equal(t, "Printing", got[0].StateInfo.Label)
+					assert.Equal(t, 5, got[0].IPPState)
 					assert.Equal(t, "testuser", got[0].User)
 					assert.Equal(t, "printer1", got[0].Printer)
 					assert.Equal(t, 10240, got[0].Size)
@@ -0,0 +1,206 @@
+package cups
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+)
+
+// Device is a printer the scheduler's backends discovered. Driverless devices
+// speak IPP Everywhere and can be added without a vendor driver.
+type Device struct {
+	URI        string `json:"uri"`
+	MakeModel  string `json:"makeModel"`
+	Info       string `json:"info"`
+	Location   string `json:"location"`
+	Class      string `json:"class"`
+	Driverless bool   `json:"driverless"`
+	Configured string `json:"configured,omitempty"`
+}
+
+// AutoAddResult describes the queue cups.autoAdd created
+type AutoAddResult struct {
+	Success   bool   `json:"success"`
+	Name      string `json:"name"`
+	URI       string `json:"uri"`
+	MakeModel string `json:"makeModel"`
+}
+
+var (
+	queueNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+	// probeAttributes are what a usable IPP Everywhere queue must report
+	probeAttributes = []string{
+		ipp.AttributePrinterName,
+		ipp.AttributePrinterMakeAndModel,
+		ipp.AttributePrinterStateReasons,
+		"document-format-supported",
+	}
+)
+
+// GetDevices lists discovered printers, driverless ones first. Devices that
+// already back a queue carry its name in Configured.
+func (m *Manager) GetDevices() ([]Device, error) {
+	deviceAttrs, err := m.client.GetDevices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover printers: %w", err)
+	}
+
+	configured := make(map[string]string)
+	if printers, err := m.client.GetPrinters([]string{ipp.AttributePrinterName, ipp.AttributeDeviceURI}); err == nil {
+		for _, attrs := range printers {
+			configured[getStringAttr(attrs, ipp.AttributeDeviceURI)] = getStringAttr(attrs, ipp.AttributePrinterName)
+		}
+	}
+
+	devices := make([]Device, 0, len(deviceAttrs))
+	for uri, attrs := range deviceAttrs {
+		class := getStringAttr(attrs, "device-class")
+		if class != "network" {
+			continue
+		}
+		devices = append(devices, Device{
+			URI:        uri,
+			MakeModel:  getStringAttr(attrs, "device-make-and-model"),
+			Info:       getStringAttr(attrs, "device-info"),
+			Location:   getStringAttr(attrs, "device-location"),
+			Class:      class,
+			Driverless: isDriverlessURI(uri),
+			Configured: configured[uri],
+		})
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Driverless != devices[j].Driverless {
+			return devices[i].Driverless
+		}
+		return devices[i].URI < devices[j].URI
+	})
+	return devices, nil
+}
+
+// AutoAdd creates an IPP Everywhere queue for a discovered printer and probes
+// it with Get-Printer-Attributes. A queue that fails the probe is deleted
+// again so a half-working printer is never left behind.
+func (m *Manager) AutoAdd(deviceURI, name string) (AutoAddResult, error) {
+	if m.config == nil {
+		return AutoAddResult{}, fmt.Errorf("printer administration is not available")
+	}
+	if !isDriverlessURI(deviceURI) {
+		return AutoAddResult{}, fmt.Errorf("%s is not a driverless IPP printer", deviceURI)
+	}
+
+	if name == "" {
+		name = queueNameFromURI(deviceURI)
+	} else if queueNameInvalid.MatchString(name) {
+		return AutoAddResult{}, fmt.Errorf("invalid queue name %q: use letters, digits, '-' and '_'", name)
+	}
+	if name == "" {
+		return AutoAddResult{}, fmt.Errorf("cannot derive a queue name from %s", deviceURI)
+	}
+
+	printers, err := m.client.GetPrinters([]string{ipp.AttributePrinterName})
+	if err != nil {
+		return AutoAddResult{}, err
+	}
+	for _, attrs := range printers {
+		if strings.EqualFold(getStringAttr(attrs, ipp.AttributePrinterName), name) {
+			return AutoAddResult{}, fmt.Errorf("a printer named %s already exists", name)
+		}
+	}
+
+	if _, err := m.config.lpadmin("-p", name, "-E", "-v", deviceURI, "-m", "everywhere"); err != nil {
+		return AutoAddResult{}, fmt.Errorf("failed to create queue %s: %w", name, err)
+	}
+
+	makeModel, err := m.probeQueue(name)
+	if err != nil {
+		if _, delErr := m.config.lpadmin("-x", name); delErr != nil {
+			log.Warnf("[CUPS] Failed to roll back queue %s: %v", name, delErr)
+		}
+		return AutoAddResult{}, fmt.Errorf("queue %s failed verification and was removed: %w", name, err)
+	}
+
+	if err := m.updateState(); err == nil {
+		m.notifySubscribers()
+	}
+	return AutoAddResult{Success: true, Name: name, URI: deviceURI, MakeModel: makeModel}, nil
+}
+
+// probeQueue checks that the new queue answers Get-Printer-Attributes with a
+// generated model and the formats it accepts
+func (m *Manager) probeQueue(name string) (string, error) {
+	attrs, err := m.client.GetPrinterAttributes(name, probeAttributes)
+	if err != nil {
+		return "", err
+	}
+
+	makeModel := getStringAttr(attrs, ipp.AttributePrinterMakeAndModel)
+	if makeModel == "" {
+		return "", fmt.Errorf("printer reported no make and model")
+	}
+	if len(attrs["document-format-supported"]) == 0 {
+		return "", fmt.Errorf("printer reported no supported document formats")
+	}
+	for _, reason := range attrs[ipp.AttributePrinterStateReasons] {
+		if r, _ := reason.Value.(string); strings.HasPrefix(r, "cups-missing-filter") || strings.HasPrefix(r, "cups-insecure-filter") {
+			return "", fmt.Errorf("queue cannot print: %s", r)
+		}
+	}
+	return makeModel, nil
+}
+
+// isDriverlessURI reports whether lpadmin's everywhere model can handle the
+// URI, which needs the printer reachable over IPP
+func isDriverlessURI(uri string) bool {
+	scheme, host := splitDeviceURI(uri)
+	switch scheme {
+	case "ipp", "ipps":
+		return host != ""
+	case "dnssd":
+		// dnssd://Name._ipp._tcp.local/ or _ipps; the _pdl-datastream and
+		// _printer services are not IPP
+		return strings.Contains(host, "._ipp._tcp") || strings.Contains(host, "._ipps._tcp")
+	}
+	return false
+}
+
+// splitDeviceURI returns the scheme and host of a device URI. dnssd hosts
+// hold escaped service names that net/url rejects, so it is split by hand.
+func splitDeviceURI(uri string) (scheme, host string) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return "", ""
+	}
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		rest = rest[:i]
+	}
+	if _, after, ok := strings.Cut(rest, "@"); ok {
+		rest = after
+	}
+	return strings.ToLower(scheme), rest
+}
+
+// queueNameFromURI turns the service name or host into a queue name the way
+// the CUPS web interface suggests one
+func queueNameFromURI(uri string) string {
+	scheme, name := splitDeviceURI(uri)
+	if scheme == "dnssd" {
+		name, _, _ = strings.Cut(name, "._")
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+	} else if host, _, err := net.SplitHostPort(name); err == nil {
+		name = host
+	}
+	name = strings.Trim(queueNameInvalid.ReplaceAllString(name, "_"), "_")
+	if len(name) > 127 {
+		name = name[:127]
+	}
+	return name
+}
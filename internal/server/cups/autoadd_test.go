@@ -0,0 +1,152 @@
+package cups
+
+import (
+	"errors"
+	"testing"
+
+	mocks_cups "github.com/AvengeMedia/danklinux/internal/mocks/cups"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsDriverlessURI(t *testing.T) {
+	assert.True(t, isDriverlessURI("ipp://192.168.1.20/ipp/print"))
+	assert.True(t, isDriverlessURI("ipps://printer.local:631/ipp/print"))
+	assert.True(t, isDriverlessURI("dnssd://HP%20LaserJet%20MFP%20(12ab)._ipp._tcp.local/?uuid=abc"))
+	assert.True(t, isDriverlessURI("dnssd://Brother._ipps._tcp.local/"))
+	assert.False(t, isDriverlessURI("dnssd://Brother._pdl-datastream._tcp.local/"))
+	assert.False(t, isDriverlessURI("socket://192.168.1.20"))
+	assert.False(t, isDriverlessURI("usb://HP/LaserJet"))
+	assert.False(t, isDriverlessURI("ipp://"))
+}
+
+func TestQueueNameFromURI(t *testing.T) {
+	assert.Equal(t, "HP_LaserJet_MFP_12ab", queueNameFromURI("dnssd://HP%20LaserJet%20MFP%20(12ab)._ipp._tcp.local/?uuid=abc"))
+	assert.Equal(t, "printer_local", queueNameFromURI("ipps://printer.local:631/ipp/print"))
+	assert.Equal(t, "192_168_1_20", queueNameFromURI("ipp://192.168.1.20/ipp/print"))
+}
+
+func TestManager_GetDevices(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetDevices().Return(map[string]ipp.Attributes{
+		"socket://10.0.0.5": {
+			"device-class":          []ipp.Attribute{{Value: "network"}},
+			"device-make-and-model": []ipp.Attribute{{Value: "Unknown"}},
+		},
+		"dnssd://Office._ipp._tcp.local/": {
+			"device-class":          []ipp.Attribute{{Value: "network"}},
+			"device-make-and-model": []ipp.Attribute{{Value: "Brother HL-L2350DW"}},
+			"device-info":           []ipp.Attribute{{Value: "Office"}},
+		},
+		"usb://HP/LaserJet": {
+			"device-class": []ipp.Attribute{{Value: "direct"}},
+		},
+	}, nil)
+	mockClient.EXPECT().GetPrinters(mock.Anything).Return(map[string]ipp.Attributes{
+		"Office": {
+			ipp.AttributePrinterName: []ipp.Attribute{{Value: "Office"}},
+			ipp.AttributeDeviceURI:   []ipp.Attribute{{Value: "dnssd://Office._ipp._tcp.local/"}},
+		},
+	}, nil)
+
+	m := &Manager{client: mockClient}
+	devices, err := m.GetDevices()
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+
+	assert.Equal(t, "dnssd://Office._ipp._tcp.local/", devices[0].URI)
+	assert.True(t, devices[0].Driverless)
+	assert.Equal(t, "Office", devices[0].Configured)
+	assert.Equal(t, "Brother HL-L2350DW", devices[0].MakeModel)
+	assert.False(t, devices[1].Driverless)
+}
+
+func newAutoAddManager(t *testing.T, cfg *fakeConfig) (*Manager, *mocks_cups.MockCUPSClientInterface) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	m := &Manager{
+		client:      mockClient,
+		config:      cfg,
+		state:       &CUPSState{Printers: make(map[string]*Printer)},
+		dirty:       make(chan struct{}, 1),
+		subscribers: make(map[string]chan CUPSState),
+	}
+	return m, mockClient
+}
+
+func TestManager_AutoAdd(t *testing.T) {
+	const uri = "dnssd://Office%20Laser._ipp._tcp.local/"
+
+	t.Run("creates and verifies", func(t *testing.T) {
+		cfg := &fakeConfig{}
+		m, mockClient := newAutoAddManager(t, cfg)
+		mockClient.EXPECT().GetPrinters(mock.Anything).Return(map[string]ipp.Attributes{}, nil)
+		mockClient.EXPECT().GetPrinterAttributes("Office_Laser", mock.Anything).Return(ipp.Attributes{
+			ipp.AttributePrinterMakeAndModel: []ipp.Attribute{{Value: "Brother HL-L2350DW"}},
+			ipp.AttributePrinterStateReasons: []ipp.Attribute{{Value: "none"}},
+			"document-format-supported":      []ipp.Attribute{{Value: "application/pdf"}, {Value: "image/pwg-raster"}},
+		}, nil)
+		mockClient.EXPECT().GetJobs(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(map[int]ipp.Attributes{}, nil).Maybe()
+
+		result, err := m.AutoAdd(uri, "")
+		require.NoError(t, err)
+		assert.Equal(t, AutoAddResult{Success: true, Name: "Office_Laser", URI: uri, MakeModel: "Brother HL-L2350DW"}, result)
+		assert.Equal(t, [][]string{{"-p", "Office_Laser", "-E", "-v", uri, "-m", "everywhere"}}, cfg.lpadmins)
+	})
+
+	t.Run("rolls back when the probe fails", func(t *testing.T) {
+		cfg := &fakeConfig{}
+		m, mockClient := newAutoAddManager(t, cfg)
+		mockClient.EXPECT().GetPrinters(mock.Anything).Return(map[string]ipp.Attributes{}, nil)
+		mockClient.EXPECT().GetPrinterAttributes("desk", mock.Anything).Return(ipp.Attributes{
+			ipp.AttributePrinterMakeAndModel: []ipp.Attribute{{Value: "Brother HL-L2350DW"}},
+			ipp.AttributePrinterStateReasons: []ipp.Attribute{{Value: "cups-missing-filter-warning"}},
+			"document-format-supported":      []ipp.Attribute{{Value: "application/pdf"}},
+		}, nil)
+
+		_, err := m.AutoAdd(uri, "desk")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "was removed")
+		require.Len(t, cfg.lpadmins, 2)
+		assert.Equal(t, []string{"-x", "desk"}, cfg.lpadmins[1])
+	})
+
+	t.Run("rolls back when the queue does not answer", func(t *testing.T) {
+		cfg := &fakeConfig{}
+		m, mockClient := newAutoAddManager(t, cfg)
+		mockClient.EXPECT().GetPrinters(mock.Anything).Return(map[string]ipp.Attributes{}, nil)
+		mockClient.EXPECT().GetPrinterAttributes("desk", mock.Anything).Return(nil, errors.New("not found"))
+
+		_, err := m.AutoAdd(uri, "desk")
+		require.Error(t, err)
+		assert.Equal(t, []string{"-x", "desk"}, cfg.lpadmins[len(cfg.lpadmins)-1])
+	})
+
+	t.Run("lpadmin failure leaves nothing to roll back", func(t *testing.T) {
+		cfg := &fakeConfig{lpadminErr: errors.New("unable to connect")}
+		m, mockClient := newAutoAddManager(t, cfg)
+		mockClient.EXPECT().GetPrinters(mock.Anything).Return(map[string]ipp.Attributes{}, nil)
+
+		_, err := m.AutoAdd(uri, "desk")
+		require.Error(t, err)
+		assert.Len(t, cfg.lpadmins, 1)
+	})
+
+	t.Run("rejects existing names and non-IPP devices", func(t *testing.T) {
+		cfg := &fakeConfig{}
+		m, mockClient := newAutoAddManager(t, cfg)
+		mockClient.EXPECT().GetPrinters(mock.Anything).Return(map[string]ipp.Attributes{
+			"desk": {ipp.AttributePrinterName: []ipp.Attribute{{Value: "desk"}}},
+		}, nil)
+
+		_, err := m.AutoAdd(uri, "Desk")
+		assert.Error(t, err)
+		_, err = m.AutoAdd("socket://10.0.0.5", "")
+		assert.Error(t, err)
+		_, err = m.AutoAdd(uri, "bad name")
+		assert.Error(t, err)
+		assert.Empty(t, cfg.lpadmins)
+	})
+}
@@ -0,0 +1,179 @@
+package cups
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+)
+
+// JobFailure is published when a job is aborted or stops on a printer
+// error. Actions are the IPC calls a notification offers as buttons.
+type JobFailure struct {
+	JobID        int          `json:"jobId"`
+	JobName      string       `json:"jobName"`
+	Printer      string       `json:"printer"`
+	State        JobState     `json:"state"`
+	StateInfo    JobStateInfo `json:"stateInfo"`
+	StateReasons []string     `json:"stateReasons,omitempty"`
+	Message      string       `json:"message"`
+	Time         time.Time    `json:"time"`
+	Actions      []JobAction  `json:"actions"`
+}
+
+type JobAction struct {
+	ID     string                 `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+var jobFailureAttributes = []string{
+	ipp.AttributeJobID,
+	ipp.AttributeJobName,
+	ipp.AttributeJobState,
+	ipp.AttributeJobStateReasons,
+	ipp.AttributeJobPrinterURI,
+	ipp.AttributeJobPrinterStateMessage,
+}
+
+func isFailedJobState(state JobState) bool {
+	return state == JobAborted || state == JobStopped
+}
+
+// checkJobFailure looks up a job named in a subscription event and publishes
+// a JobFailure the first time it is seen aborted or stopped
+func (m *Manager) checkJobFailure(jobID int) {
+	attrs, err := m.client.GetJobAttributes(jobID, jobFailureAttributes)
+	if err != nil {
+		log.Debugf("[CUPS] Failed to get attributes of job %d: %v", jobID, err)
+		return
+	}
+
+	state, _ := parseJobState(attrs)
+	m.failureMutex.Lock()
+	if !isFailedJobState(state) {
+		delete(m.reportedFailures, jobID)
+		m.failureMutex.Unlock()
+		return
+	}
+	if m.reportedFailures == nil {
+		m.reportedFailures = make(map[int]JobState)
+	}
+	if m.reportedFailures[jobID] == state {
+		m.failureMutex.Unlock()
+		return
+	}
+	m.reportedFailures[jobID] = state
+	m.failureMutex.Unlock()
+
+	failure := m.jobFailure(jobID, state, attrs)
+	log.Infof("[CUPS] Job %d on %s %s: %s", jobID, failure.Printer, state, failure.Message)
+	m.broadcastFailure(failure)
+}
+
+func (m *Manager) jobFailure(jobID int, state JobState, attrs ipp.Attributes) JobFailure {
+	failure := JobFailure{
+		JobID:     jobID,
+		JobName:   getStringAttr(attrs, ipp.AttributeJobName),
+		Printer:   printerFromURI(getStringAttr(attrs, ipp.AttributeJobPrinterURI)),
+		State:     state,
+		StateInfo: state.Info(),
+		Message:   getStringAttr(attrs, ipp.AttributeJobPrinterStateMessage),
+		Time:      time.Now(),
+		Actions: []JobAction{
+			{ID: "retry", Method: "cups.retryJob", Params: map[string]interface{}{"jobID": jobID}},
+			{ID: "cancel", Method: "cups.cancelJob", Params: map[string]interface{}{"jobID": jobID}},
+		},
+	}
+	for _, a := range attrs[ipp.AttributeJobStateReasons] {
+		if reason, ok := a.Value.(string); ok && reason != "none" {
+			failure.StateReasons = append(failure.StateReasons, reason)
+		}
+	}
+
+	// Older schedulers don't copy the message onto the job
+	if failure.Message == "" && failure.Printer != "" {
+		if pattrs, err := m.client.GetPrinterAttributes(failure.Printer, []string{ipp.AttributePrinterStateMessage}); err == nil {
+			failure.Message = getStringAttr(pattrs, ipp.AttributePrinterStateMessage)
+		}
+	}
+	return failure
+}
+
+func printerFromURI(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	parts := strings.Split(uri, "/")
+	return parts[len(parts)-1]
+}
+
+// RetryJob resumes the job's printer when it stopped and restarts the job
+// when it was aborted, which is what recovering from a paper jam takes
+func (m *Manager) RetryJob(jobID int) error {
+	attrs, err := m.client.GetJobAttributes(jobID, jobFailureAttributes)
+	if err != nil {
+		return err
+	}
+	state, _ := parseJobState(attrs)
+	printer := printerFromURI(getStringAttr(attrs, ipp.AttributeJobPrinterURI))
+
+	if printer != "" {
+		pattrs, err := m.client.GetPrinterAttributes(printer, []string{ipp.AttributePrinterState})
+		if err == nil && parsePrinterState(pattrs) == "stopped" {
+			if err := m.client.ResumePrinter(printer); err != nil {
+				return fmt.Errorf("failed to resume %s: %w", printer, err)
+			}
+		}
+	}
+
+	switch state {
+	case JobAborted, JobCanceled:
+		if err := m.client.RestartJob(jobID); err != nil {
+			return err
+		}
+	case JobStopped, JobPending:
+		// Resuming the printer picks the job up again
+	default:
+		return fmt.Errorf("job %d is %s and cannot be retried", jobID, state)
+	}
+
+	m.failureMutex.Lock()
+	delete(m.reportedFailures, jobID)
+	m.failureMutex.Unlock()
+	return nil
+}
+
+func (m *Manager) SubscribeFailures(id string) chan JobFailure {
+	ch := make(chan JobFailure, 16)
+	m.failureMutex.Lock()
+	if m.failureSubscribers == nil {
+		m.failureSubscribers = make(map[string]chan JobFailure)
+	}
+	m.failureSubscribers[id] = ch
+	m.failureMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) UnsubscribeFailures(id string) {
+	m.failureMutex.Lock()
+	if ch, ok := m.failureSubscribers[id]; ok {
+		close(ch)
+		delete(m.failureSubscribers, id)
+	}
+	m.failureMutex.Unlock()
+}
+
+func (m *Manager) broadcastFailure(failure JobFailure) {
+	m.failureMutex.RLock()
+	defer m.failureMutex.RUnlock()
+
+	for _, ch := range m.failureSubscribers {
+		select {
+		case ch <- failure:
+		default:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cups
+
+import (
+	"testing"
+
+	mocks_cups "github.com/AvengeMedia/danklinux/internal/mocks/cups"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func jobAttrs(state int, message string) ipp.Attributes {
+	attrs := ipp.Attributes{
+		ipp.AttributeJobID:           []ipp.Attribute{{Value: 7}},
+		ipp.AttributeJobName:         []ipp.Attribute{{Value: "report.pdf"}},
+		ipp.AttributeJobState:        []ipp.Attribute{{Value: state}},
+		ipp.AttributeJobStateReasons: []ipp.Attribute{{Value: "job-stopped"}, {Value: "printer-stopped"}},
+		ipp.AttributeJobPrinterURI:   []ipp.Attribute{{Value: "ipp://localhost/printers/office"}},
+	}
+	if message != "" {
+		attrs[ipp.AttributeJobPrinterStateMessage] = []ipp.Attribute{{Value: message}}
+	}
+	return attrs
+}
+
+func TestCheckJobFailurePublishesOnce(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobAttributes(7, mock.Anything).Return(jobAttrs(6, "Paper jam"), nil)
+
+	m := &Manager{client: mockClient}
+	ch := m.SubscribeFailures("test")
+	defer m.UnsubscribeFailures("test")
+
+	m.checkJobFailure(7)
+	m.checkJobFailure(7)
+
+	require.Len(t, ch, 1)
+	failure := <-ch
+	assert.Equal(t, 7, failure.JobID)
+	assert.Equal(t, "report.pdf", failure.JobName)
+	assert.Equal(t, "office", failure.Printer)
+	assert.Equal(t, JobStopped, failure.State)
+	assert.Equal(t, JobSeverityError, failure.StateInfo.Severity)
+	assert.Equal(t, []string{"job-stopped", "printer-stopped"}, failure.StateReasons)
+	assert.Equal(t, "Paper jam", failure.Message)
+	require.Len(t, failure.Actions, 2)
+	assert.Equal(t, "cups.retryJob", failure.Actions[0].Method)
+	assert.Equal(t, 7, failure.Actions[0].Params["jobID"])
+}
+
+func TestCheckJobFailureFallsBackToPrinterMessage(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobAttributes(7, mock.Anything).Return(jobAttrs(8, ""), nil)
+	mockClient.EXPECT().GetPrinterAttributes("office", []string{ipp.AttributePrinterStateMessage}).
+		Return(ipp.Attributes{ipp.AttributePrinterStateMessage: []ipp.Attribute{{Value: "Out of toner"}}}, nil)
+
+	m := &Manager{client: mockClient}
+	ch := m.SubscribeFailures("test")
+	defer m.UnsubscribeFailures("test")
+
+	m.checkJobFailure(7)
+
+	require.Len(t, ch, 1)
+	failure := <-ch
+	assert.Equal(t, JobAborted, failure.State)
+	assert.Equal(t, "Out of toner", failure.Message)
+}
+
+func TestCheckJobFailureIgnoresHealthyJobs(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobAttributes(7, mock.Anything).Return(jobAttrs(5, ""), nil)
+
+	m := &Manager{client: mockClient, reportedFailures: map[int]JobState{7: JobStopped}}
+	ch := m.SubscribeFailures("test")
+	defer m.UnsubscribeFailures("test")
+
+	m.checkJobFailure(7)
+
+	assert.Len(t, ch, 0)
+	assert.NotContains(t, m.reportedFailures, 7, "a recovered job can fail again")
+}
+
+func TestRetryJobRestartsAbortedJob(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobAttributes(7, mock.Anything).Return(jobAttrs(8, ""), nil)
+	mockClient.EXPECT().GetPrinterAttributes("office", []string{ipp.AttributePrinterState}).
+		Return(ipp.Attributes{ipp.AttributePrinterState: []ipp.Attribute{{Value: 5}}}, nil)
+	mockClient.EXPECT().ResumePrinter("office").Return(nil)
+	mockClient.EXPECT().RestartJob(7).Return(nil)
+
+	m := &Manager{client: mockClient, reportedFailures: map[int]JobState{7: JobAborted}}
+	require.NoError(t, m.RetryJob(7))
+	assert.NotContains(t, m.reportedFailures, 7)
+}
+
+func TestRetryJobResumesStoppedJob(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobAttributes(7, mock.Anything).Return(jobAttrs(6, ""), nil)
+	mockClient.EXPECT().GetPrinterAttributes("office", []string{ipp.AttributePrinterState}).
+		Return(ipp.Attributes{ipp.AttributePrinterState: []ipp.Attribute{{Value: 5}}}, nil)
+	mockClient.EXPECT().ResumePrinter("office").Return(nil)
+
+	m := &Manager{client: mockClient}
+	assert.NoError(t, m.RetryJob(7))
+}
+
+func TestRetryJobRejectsCompletedJob(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobAttributes(7, mock.Anything).Return(jobAttrs(9, ""), nil)
+	mockClient.EXPECT().GetPrinterAttributes("office", []string{ipp.AttributePrinterState}).
+		Return(ipp.Attributes{ipp.AttributePrinterState: []ipp.Attribute{{Value: 3}}}, nil)
+
+	m := &Manager{client: mockClient}
+	assert.ErrorContains(t, m.RetryJob(7), "cannot be retried")
+}
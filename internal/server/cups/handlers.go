@@ -1,10 +1,16 @@
 package cups
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"os/user"
+	"strconv"
 
+	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/internal/server/models"
 )
 
@@ -19,9 +25,16 @@ type SuccessResult struct {
 	Message string `json:"message"`
 }
 
+type PrintResult struct {
+	Success bool `json:"success"`
+	JobID   int  `json:"jobId"`
+}
+
+// CUPSEvent is "state_changed", or "job_failed" with Failure set
 type CUPSEvent struct {
-	Type string    `json:"type"`
-	Data CUPSState `json:"data"`
+	Type    string      `json:"type"`
+	Data    CUPSState   `json:"data"`
+	Failure *JobFailure `json:"failure,omitempty"`
 }
 
 func HandleRequest(conn net.Conn, req Request, manager *Manager) {
@@ -38,8 +51,22 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleResumePrinter(conn, req, manager)
 	case "cups.cancelJob":
 		handleCancelJob(conn, req, manager)
+	case "cups.retryJob":
+		handleRetryJob(conn, req, manager)
 	case "cups.purgeJobs":
 		handlePurgeJobs(conn, req, manager)
+	case "cups.deletePrinter":
+		handleDeletePrinter(conn, req, manager)
+	case "cups.print":
+		handlePrint(conn, req, manager)
+	case "cups.getServerSettings":
+		handleGetServerSettings(conn, req, manager)
+	case "cups.setServerSettings":
+		handleSetServerSettings(conn, req, manager)
+	case "cups.getDevices":
+		handleGetDevices(conn, req, manager)
+	case "cups.autoAdd":
+		handleAutoAdd(conn, req, manager)
 	default:
 		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
 	}
@@ -107,6 +134,11 @@ func handleCancelJob(conn net.Conn, req Request, manager *Manager) {
 	}
 	jobID := int(jobIDFloat)
 
+	if err := manager.checkCancelJob(callerFromConn(conn), jobID); err != nil {
+		respondError(conn, req.ID, err)
+		return
+	}
+
 	if err := manager.CancelJob(jobID); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
 		return
@@ -114,6 +146,20 @@ func handleCancelJob(conn net.Conn, req Request, manager *Manager) {
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "job canceled"})
 }
 
+func handleRetryJob(conn net.Conn, req Request, manager *Manager) {
+	jobIDFloat, ok := req.Params["jobID"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'jobID' parameter")
+		return
+	}
+
+	if err := manager.RetryJob(int(jobIDFloat)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "job restarted"})
+}
+
 func handlePurgeJobs(conn net.Conn, req Request, manager *Manager) {
 	printerName, ok := req.Params["printerName"].(string)
 	if !ok {
@@ -121,6 +167,11 @@ func handlePurgeJobs(conn net.Conn, req Request, manager *Manager) {
 		return
 	}
 
+	if err := manager.checkPurgeJobs(callerFromConn(conn), printerName); err != nil {
+		respondError(conn, req.ID, err)
+		return
+	}
+
 	if err := manager.PurgeJobs(printerName); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
 		return
@@ -128,10 +179,159 @@ func handlePurgeJobs(conn net.Conn, req Request, manager *Manager) {
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "jobs canceled"})
 }
 
+func handleDeletePrinter(conn net.Conn, req Request, manager *Manager) {
+	printerName, ok := req.Params["printerName"].(string)
+	if !ok || printerName == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'printerName' parameter")
+		return
+	}
+
+	if err := manager.checkDeletePrinter(callerFromConn(conn)); err != nil {
+		respondError(conn, req.ID, err)
+		return
+	}
+
+	if err := manager.DeletePrinter(printerName); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "printer deleted"})
+}
+
+// callerFromConn identifies the client process, or returns nil when the
+// connection carries no peer credentials
+func callerFromConn(conn net.Conn) *Caller {
+	cred, err := models.PeerCredentials(conn)
+	if err != nil {
+		log.Debugf("[CUPS] No peer credentials: %v", err)
+		return nil
+	}
+
+	caller := &Caller{PID: cred.PID, UID: cred.UID}
+	if u, err := user.LookupId(strconv.FormatUint(uint64(cred.UID), 10)); err == nil {
+		caller.Username = u.Username
+	}
+	return caller
+}
+
+// respondError tags polkit denials so the shell can offer to authenticate
+func respondError(conn net.Conn, id int, err error) {
+	var permErr *PermissionError
+	if errors.As(err, &permErr) {
+		models.RespondErrorCode(conn, id, models.CodePermissionDenied, err.Error())
+		return
+	}
+	models.RespondError(conn, id, err.Error())
+}
+
+func handlePrint(conn net.Conn, req Request, manager *Manager) {
+	printerName, ok := req.Params["printerName"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'printerName' parameter")
+		return
+	}
+
+	title, _ := req.Params["title"].(string)
+
+	var jobID int
+	var err error
+	if path, ok := req.Params["path"].(string); ok {
+		jobID, err = manager.PrintFile(printerName, path, title)
+	} else if rawURL, ok := req.Params["url"].(string); ok {
+		jobID, err = manager.PrintURL(printerName, rawURL, title)
+	} else if data, ok := req.Params["data"].(string); ok {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(data)
+		if decodeErr != nil {
+			models.RespondError(conn, req.ID, "invalid 'data' parameter: expected base64")
+			return
+		}
+		jobID, err = manager.PrintReader(printerName, bytes.NewReader(decoded), title)
+	} else {
+		models.RespondError(conn, req.ID, "one of 'path', 'url' or 'data' is required")
+		return
+	}
+
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, PrintResult{Success: true, JobID: jobID})
+}
+
+func handleGetServerSettings(conn net.Conn, req Request, manager *Manager) {
+	settings, err := manager.GetServerSettings()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, settings)
+}
+
+func handleSetServerSettings(conn net.Conn, req Request, manager *Manager) {
+	var update ServerSettingsUpdate
+	fields := []struct {
+		name  string
+		value **bool
+	}{
+		{"sharePrinters", &update.SharePrinters},
+		{"remoteAny", &update.RemoteAny},
+		{"remoteAdmin", &update.RemoteAdmin},
+		{"userCancelAny", &update.UserCancelAny},
+		{"debugLogging", &update.DebugLogging},
+		{"browseRemote", &update.BrowseRemote},
+	}
+	for _, f := range fields {
+		raw, present := req.Params[f.name]
+		if !present {
+			continue
+		}
+		value, ok := raw.(bool)
+		if !ok {
+			models.RespondError(conn, req.ID, fmt.Sprintf("missing or invalid '%s' parameter", f.name))
+			return
+		}
+		*f.value = &value
+	}
+
+	settings, err := manager.SetServerSettings(update)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, settings)
+}
+
+func handleGetDevices(conn net.Conn, req Request, manager *Manager) {
+	devices, err := manager.GetDevices()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, devices)
+}
+
+func handleAutoAdd(conn net.Conn, req Request, manager *Manager) {
+	uri, ok := req.Params["uri"].(string)
+	if !ok || uri == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'uri' parameter")
+		return
+	}
+	name, _ := req.Params["name"].(string)
+
+	result, err := manager.AutoAdd(uri, name)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
 func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
 	clientID := fmt.Sprintf("client-%p", conn)
 	stateChan := manager.Subscribe(clientID)
 	defer manager.Unsubscribe(clientID)
+	failureChan := manager.SubscribeFailures(clientID)
+	defer manager.UnsubscribeFailures(clientID)
 
 	initialState := manager.GetState()
 	event := CUPSEvent{
@@ -146,12 +346,29 @@ func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
 		return
 	}
 
-	for state := range stateChan {
-		event := CUPSEvent{
-			Type: "state_changed",
-			Data: state,
+	for {
+		var event CUPSEvent
+		select {
+		case state, ok := <-stateChan:
+			if !ok {
+				return
+			}
+			event = CUPSEvent{
+				Type: "state_changed",
+				Data: state,
+			}
+		case failure, ok := <-failureChan:
+			if !ok {
+				return
+			}
+			event = CUPSEvent{
+				Type:    "job_failed",
+				Data:    manager.GetState(),
+				Failure: &failure,
+			}
 		}
 		if err := json.NewEncoder(conn).Encode(models.Response[CUPSEvent]{
+			ID:     req.ID,
 			Result: &event,
 		}); err != nil {
 			return
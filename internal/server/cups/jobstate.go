@@ -0,0 +1,86 @@
+package cups
+
+import (
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+)
+
+// JobState is a job's state as clients see it. The values are the IPP
+// job-state keywords, plus JobUnknown for anything else the server reports.
+type JobState string
+
+const (
+	JobPending    JobState = "pending"
+	JobHeld       JobState = "pending-held"
+	JobProcessing JobState = "processing"
+	JobStopped    JobState = "processing-stopped"
+	JobCanceled   JobState = "canceled"
+	JobAborted    JobState = "aborted"
+	JobCompleted  JobState = "completed"
+	JobUnknown    JobState = "unknown"
+)
+
+// JobSeverity says how much a job's state needs the user's attention
+type JobSeverity string
+
+const (
+	JobSeverityInfo    JobSeverity = "info"
+	JobSeveritySuccess JobSeverity = "success"
+	JobSeverityWarning JobSeverity = "warning"
+	JobSeverityError   JobSeverity = "error"
+)
+
+// JobStateInfo is how a state is shown. Label is the English text, which
+// translations look up by the state rather than by the label; Icon is a
+// freedesktop icon name hint.
+type JobStateInfo struct {
+	Label    string      `json:"label"`
+	Icon     string      `json:"icon"`
+	Severity JobSeverity `json:"severity"`
+}
+
+var jobStateInfo = map[JobState]JobStateInfo{
+	JobPending:    {Label: "Queued", Icon: "document-print", Severity: JobSeverityInfo},
+	JobHeld:       {Label: "Held", Icon: "media-playback-pause", Severity: JobSeverityWarning},
+	JobProcessing: {Label: "Printing", Icon: "printer-printing", Severity: JobSeverityInfo},
+	JobStopped:    {Label: "Stopped", Icon: "printer-error", Severity: JobSeverityError},
+	JobCanceled:   {Label: "Canceled", Icon: "process-stop", Severity: JobSeverityWarning},
+	JobAborted:    {Label: "Failed", Icon: "dialog-error", Severity: JobSeverityError},
+	JobCompleted:  {Label: "Completed", Icon: "emblem-ok", Severity: JobSeveritySuccess},
+	JobUnknown:    {Label: "Unknown", Icon: "dialog-question", Severity: JobSeverityWarning},
+}
+
+// ipp job-state enum values, RFC 8011 section 5.3.7
+var jobStatesByValue = map[int]JobState{
+	3: JobPending,
+	4: JobHeld,
+	5: JobProcessing,
+	6: JobStopped,
+	7: JobCanceled,
+	8: JobAborted,
+	9: JobCompleted,
+}
+
+// Info is the display metadata of the state
+func (s JobState) Info() JobStateInfo {
+	if info, ok := jobStateInfo[s]; ok {
+		return info
+	}
+	return jobStateInfo[JobUnknown]
+}
+
+// parseJobState maps the job-state attribute to a JobState and returns the
+// raw IPP value with it, 0 when the attribute is missing
+func parseJobState(attrs ipp.Attributes) (JobState, int) {
+	stateAttr, ok := attrs[ipp.AttributeJobState]
+	if !ok || len(stateAttr) == 0 {
+		return JobUnknown, 0
+	}
+	value, ok := stateAttr[0].Value.(int)
+	if !ok {
+		return JobUnknown, 0
+	}
+	if state, ok := jobStatesByValue[value]; ok {
+		return state, value
+	}
+	return JobUnknown, value
+}
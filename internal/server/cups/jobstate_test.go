@@ -0,0 +1,59 @@
+package cups
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJobStateIPPValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    ipp.Attributes
+		want     JobState
+		wantIPP  int
+		severity JobSeverity
+	}{
+		{"pending", ipp.Attributes{ipp.AttributeJobState: []ipp.Attribute{{Value: 3}}}, JobPending, 3, JobSeverityInfo},
+		{"held", ipp.Attributes{ipp.AttributeJobState: []ipp.Attribute{{Value: 4}}}, JobHeld, 4, JobSeverityWarning},
+		{"stopped", ipp.Attributes{ipp.AttributeJobState: []ipp.Attribute{{Value: 6}}}, JobStopped, 6, JobSeverityError},
+		{"completed", ipp.Attributes{ipp.AttributeJobState: []ipp.Attribute{{Value: 9}}}, JobCompleted, 9, JobSeveritySuccess},
+		{"out of range keeps the raw value", ipp.Attributes{ipp.AttributeJobState: []ipp.Attribute{{Value: 12}}}, JobUnknown, 12, JobSeverityWarning},
+		{"missing", ipp.Attributes{}, JobUnknown, 0, JobSeverityWarning},
+		{"not an enum", ipp.Attributes{ipp.AttributeJobState: []ipp.Attribute{{Value: "pending"}}}, JobUnknown, 0, JobSeverityWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, ippState := parseJobState(tt.attrs)
+			assert.Equal(t, tt.want, state)
+			assert.Equal(t, tt.wantIPP, ippState)
+			assert.Equal(t, tt.severity, state.Info().Severity)
+		})
+	}
+}
+
+func TestJobStateInfoCoversEveryState(t *testing.T) {
+	for _, state := range jobStatesByValue {
+		info, ok := jobStateInfo[state]
+		require.True(t, ok, "%s has no display info", state)
+		assert.NotEmpty(t, info.Label)
+		assert.NotEmpty(t, info.Icon)
+	}
+	assert.Equal(t, jobStateInfo[JobUnknown], JobState("bogus").Info())
+}
+
+func TestJobJSON(t *testing.T) {
+	job := Job{ID: 1, State: JobHeld, StateInfo: JobHeld.Info(), IPPState: 4}
+	data, err := json.Marshal(job)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "pending-held", decoded["state"])
+	assert.Equal(t, float64(4), decoded["ippState"])
+	assert.Equal(t, map[string]any{"label": "Held", "icon": "media-playback-pause", "severity": "warning"}, decoded["stateInfo"])
+}
@@ -2,31 +2,21 @@ package cups
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/pkg/ipp"
 )
 
 func NewManager() (*Manager, error) {
-	host := os.Getenv("DMS_IPP_HOST")
-	if host == "" {
-		host = "localhost"
-	}
-
-	portStr := os.Getenv("DMS_IPP_PORT")
-	port := 631
-	if portStr != "" {
-		if p, err := strconv.Atoi(portStr); err == nil {
-			port = p
-		}
-	}
-
-	username := os.Getenv("DMS_IPP_USERNAME")
-	password := os.Getenv("DMS_IPP_PASSWORD")
+	host, port, username, password := ippConfigFromEnv()
 
 	client := ipp.NewCUPSClient(host, port, username, password, false)
 	baseURL := fmt.Sprintf("http://%s:%d", host, port)
@@ -37,6 +27,7 @@ func NewManager() (*Manager, error) {
 		},
 		client:      client,
 		baseURL:     baseURL,
+		config:      newSystemConfig(host, port, username),
 		stateMutex:  sync.RWMutex{},
 		stopChan:    make(chan struct{}),
 		dirty:       make(chan struct{}, 1),
@@ -57,11 +48,29 @@ func NewManager() (*Manager, error) {
 	}
 
 	m.notifierWg.Add(1)
-	go m.notifier()
+	crash.Go("cups.notifier", m.notifier)
 
 	return m, nil
 }
 
+func ippConfigFromEnv() (host string, port int, username, password string) {
+	host = os.Getenv("DMS_IPP_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	port = 631
+	if portStr := os.Getenv("DMS_IPP_PORT"); portStr != "" {
+		if p, err := strconv.Atoi(portStr); err == nil {
+			port = p
+		}
+	}
+
+	username = os.Getenv("DMS_IPP_USERNAME")
+	password = os.Getenv("DMS_IPP_PASSWORD")
+	return host, port, username, password
+}
+
 func isLocalCUPS(host string) bool {
 	switch host {
 	case "localhost", "127.0.0.1", "::1", "":
@@ -88,6 +97,10 @@ func (m *Manager) eventHandler() {
 			log.Debugf("[CUPS] Received event: %s (printer: %s, job: %d)",
 				event.EventName, event.PrinterName, event.JobID)
 
+			if event.JobID != 0 && strings.HasPrefix(event.EventName, "job-") {
+				m.checkJobFailure(event.JobID)
+			}
+
 			if err := m.updateState(); err != nil {
 				log.Warnf("[CUPS] Failed to update state after event: %v", err)
 			} else {
@@ -209,7 +222,7 @@ func (m *Manager) Subscribe(id string) chan CUPSState {
 			log.Warnf("[CUPS] Failed to start subscription manager: %v", err)
 		} else {
 			m.eventWG.Add(1)
-			go m.eventHandler()
+			crash.Go("cups.eventHandler", m.eventHandler)
 		}
 	}
 
@@ -247,6 +260,29 @@ func (m *Manager) Close() {
 	}
 	m.subscribers = make(map[string]chan CUPSState)
 	m.subMutex.Unlock()
+
+	m.failureMutex.Lock()
+	for _, ch := range m.failureSubscribers {
+		close(ch)
+	}
+	m.failureSubscribers = nil
+	m.failureMutex.Unlock()
+}
+
+// HealthCheck reports whether the CUPS server still accepts connections. It
+// only dials, since an IPP request can wait minutes on a hung cupsd.
+func (m *Manager) HealthCheck() error {
+	u, err := url.Parse(m.baseURL)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", u.Host, 3*time.Second)
+	if err != nil {
+		return fmt.Errorf("CUPS not reachable: %w", err)
+	}
+	conn.Close()
+	return nil
 }
 
 func stateChanged(old, new *CUPSState) bool {
@@ -285,32 +321,6 @@ func parsePrinterState(attrs ipp.Attributes) string {
 	return "unknown"
 }
 
-func parseJobState(attrs ipp.Attributes) string {
-	if stateAttr, ok := attrs[ipp.AttributeJobState]; ok && len(stateAttr) > 0 {
-		if state, ok := stateAttr[0].Value.(int); ok {
-			switch state {
-			case 3:
-				return "pending"
-			case 4:
-				return "pending-held"
-			case 5:
-				return "processing"
-			case 6:
-				return "processing-stopped"
-			case 7:
-				return "canceled"
-			case 8:
-				return "aborted"
-			case 9:
-				return "completed"
-			default:
-				return fmt.Sprintf("%d", state)
-			}
-		}
-	}
-	return "unknown"
-}
-
 func getStringAttr(attrs ipp.Attributes, key string) string {
 	if attr, ok := attrs[key]; ok && len(attr) > 0 {
 		if val, ok := attr[0].Value.(string); ok {
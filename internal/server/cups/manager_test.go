@@ -211,7 +211,7 @@ func TestParseJobState(t *testing.T) {
 	tests := []struct {
 		name  string
 		attrs ipp.Attributes
-		want  string
+		want  JobState
 	}{
 		{
 			name: "pending",
@@ -243,7 +243,7 @@ func TestParseJobState(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := parseJobState(tt.attrs)
+			got, _ := parseJobState(tt.attrs)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -0,0 +1,188 @@
+package cups
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+	"github.com/godbus/dbus/v5"
+)
+
+// The actions cups-pk-helper registers, so existing polkit rules for
+// printer administration also cover requests made through the daemon
+const (
+	ActionDeletePrinter    = "org.opensuse.cupspkhelper.mechanism.printeraddremove"
+	ActionEditOthersJobs   = "org.opensuse.cupspkhelper.mechanism.job-not-owned-edit"
+	polkitAllowInteraction = 1
+	// polkitTimeout covers the user typing a password into the agent
+	polkitTimeout = 2 * time.Minute
+)
+
+// Caller is the process behind a request, from the socket's peer credentials
+type Caller struct {
+	PID      int32
+	UID      uint32
+	Username string
+}
+
+// PermissionError means polkit did not authorize the caller for Action
+type PermissionError struct {
+	Action string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("not authorized: %s", e.Action)
+}
+
+type authorizer interface {
+	CheckAuthorization(caller Caller, action string) (bool, error)
+}
+
+// polkitAuthorizer asks polkitd on the system bus, letting the session's
+// authentication agent prompt when the policy wants a password
+type polkitAuthorizer struct{}
+
+func (polkitAuthorizer) CheckAuthorization(caller Caller, action string) (bool, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+
+	startTime, err := processStartTime(caller.PID)
+	if err != nil {
+		return false, err
+	}
+
+	subject := struct {
+		Kind    string
+		Details map[string]dbus.Variant
+	}{
+		Kind: "unix-process",
+		Details: map[string]dbus.Variant{
+			"pid":        dbus.MakeVariant(uint32(caller.PID)),
+			"start-time": dbus.MakeVariant(startTime),
+			"uid":        dbus.MakeVariant(int32(caller.UID)),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), polkitTimeout)
+	defer cancel()
+
+	var result struct {
+		IsAuthorized bool
+		IsChallenge  bool
+		Details      map[string]string
+	}
+	obj := conn.Object("org.freedesktop.PolicyKit1", "/org/freedesktop/PolicyKit1/Authority")
+	call := obj.CallWithContext(ctx, "org.freedesktop.PolicyKit1.Authority.CheckAuthorization", 0,
+		subject, action, map[string]string{}, uint32(polkitAllowInteraction), "")
+	if call.Err != nil {
+		return false, fmt.Errorf("polkit check for %s failed: %w", action, call.Err)
+	}
+	if err := call.Store(&result); err != nil {
+		return false, fmt.Errorf("unexpected polkit reply: %w", err)
+	}
+	return result.IsAuthorized, nil
+}
+
+// processStartTime reads the start time polkit pairs with the PID so a
+// recycled PID can't borrow another process's authorization
+func processStartTime(pid int32) (uint64, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read caller process: %w", err)
+	}
+	return parseStartTime(string(data))
+}
+
+func parseStartTime(stat string) (uint64, error) {
+	// The command name may contain spaces, so count fields after its ')'
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("malformed /proc stat")
+	}
+	fields := strings.Fields(stat[end+1:])
+	// starttime is field 22, and fields[0] here is field 3
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("malformed /proc stat")
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
+// SetPolkitChecks turns the per-action authorization on or off. Off, any
+// client able to reach the socket may use every cups method.
+func (m *Manager) SetPolkitChecks(enabled bool) {
+	m.polkitChecks.Store(enabled)
+}
+
+// authorize fails closed: an unknown caller or an unreachable polkitd is
+// refused just like an explicit denial
+func (m *Manager) authorize(caller *Caller, action string) error {
+	if !m.polkitChecks.Load() {
+		return nil
+	}
+	if caller == nil {
+		return &PermissionError{Action: action}
+	}
+	if caller.UID == 0 {
+		return nil
+	}
+
+	auth := m.authorizer
+	if auth == nil {
+		auth = polkitAuthorizer{}
+	}
+	ok, err := auth.CheckAuthorization(*caller, action)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &PermissionError{Action: action}
+	}
+	return nil
+}
+
+func (m *Manager) checkDeletePrinter(caller *Caller) error {
+	return m.authorize(caller, ActionDeletePrinter)
+}
+
+// checkCancelJob lets callers cancel their own jobs freely and asks
+// polkit only for someone else's
+func (m *Manager) checkCancelJob(caller *Caller, jobID int) error {
+	if !m.polkitChecks.Load() {
+		return nil
+	}
+
+	attrs, err := m.client.GetJobAttributes(jobID, []string{ipp.AttributeJobOriginatingUserName})
+	if err != nil {
+		return err
+	}
+	if caller != nil && getStringAttr(attrs, ipp.AttributeJobOriginatingUserName) == caller.Username {
+		return nil
+	}
+	return m.authorize(caller, ActionEditOthersJobs)
+}
+
+// checkPurgeJobs asks polkit when the queue holds any job the caller does
+// not own
+func (m *Manager) checkPurgeJobs(caller *Caller, printerName string) error {
+	if !m.polkitChecks.Load() {
+		return nil
+	}
+
+	jobs, err := m.client.GetJobs(printerName, "", "not-completed", false, 0, 0,
+		[]string{ipp.AttributeJobID, ipp.AttributeJobOriginatingUserName})
+	if err != nil {
+		return err
+	}
+	for _, attrs := range jobs {
+		if caller == nil || getStringAttr(attrs, ipp.AttributeJobOriginatingUserName) != caller.Username {
+			return m.authorize(caller, ActionEditOthersJobs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package cups
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	mocks_cups "github.com/AvengeMedia/danklinux/internal/mocks/cups"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuthorizer struct {
+	allow   bool
+	err     error
+	actions []string
+}
+
+func (f *fakeAuthorizer) CheckAuthorization(caller Caller, action string) (bool, error) {
+	f.actions = append(f.actions, action)
+	return f.allow, f.err
+}
+
+func ownerAttrs(user string) ipp.Attributes {
+	return ipp.Attributes{ipp.AttributeJobOriginatingUserName: []ipp.Attribute{{Value: user}}}
+}
+
+func TestParseStartTime(t *testing.T) {
+	stat := "4242 (qs (main) x) S 1 4242 4242 0 -1 4194560 2000 0 0 0 12 3 0 0 20 0 8 0 987654 123456 789"
+	start, err := parseStartTime(stat)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(987654), start)
+
+	_, err = parseStartTime("4242 (broken")
+	assert.Error(t, err)
+}
+
+func TestAuthorize(t *testing.T) {
+	alice := &Caller{PID: 10, UID: 1000, Username: "alice"}
+
+	m := &Manager{authorizer: &fakeAuthorizer{}}
+	assert.NoError(t, m.authorize(nil, ActionDeletePrinter), "checks are off by default")
+
+	m.SetPolkitChecks(true)
+	var permErr *PermissionError
+	assert.ErrorAs(t, m.authorize(nil, ActionDeletePrinter), &permErr, "unknown callers are refused")
+	assert.NoError(t, m.authorize(&Caller{PID: 1, UID: 0, Username: "root"}, ActionDeletePrinter))
+	assert.ErrorAs(t, m.authorize(alice, ActionDeletePrinter), &permErr)
+	assert.Equal(t, ActionDeletePrinter, permErr.Action)
+
+	m.authorizer = &fakeAuthorizer{allow: true}
+	assert.NoError(t, m.authorize(alice, ActionDeletePrinter))
+
+	m.authorizer = &fakeAuthorizer{err: errors.New("polkitd not running")}
+	err := m.authorize(alice, ActionDeletePrinter)
+	assert.EqualError(t, err, "polkitd not running")
+	assert.False(t, errors.As(err, &permErr))
+}
+
+func TestCheckCancelJob(t *testing.T) {
+	alice := &Caller{PID: 10, UID: 1000, Username: "alice"}
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobAttributes(1, mock.Anything).Return(ownerAttrs("alice"), nil)
+	mockClient.EXPECT().GetJobAttributes(2, mock.Anything).Return(ownerAttrs("bob"), nil)
+
+	auth := &fakeAuthorizer{}
+	m := &Manager{client: mockClient, authorizer: auth}
+	m.SetPolkitChecks(true)
+
+	assert.NoError(t, m.checkCancelJob(alice, 1))
+	assert.Empty(t, auth.actions, "own jobs need no authorization")
+
+	var permErr *PermissionError
+	assert.ErrorAs(t, m.checkCancelJob(alice, 2), &permErr)
+	assert.Equal(t, []string{ActionEditOthersJobs}, auth.actions)
+}
+
+func TestCheckPurgeJobs(t *testing.T) {
+	alice := &Caller{PID: 10, UID: 1000, Username: "alice"}
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetJobs("mine", "", "not-completed", false, 0, 0, mock.Anything).Return(map[int]ipp.Attributes{
+		1: ownerAttrs("alice"),
+	}, nil)
+	mockClient.EXPECT().GetJobs("shared", "", "not-completed", false, 0, 0, mock.Anything).Return(map[int]ipp.Attributes{
+		1: ownerAttrs("alice"),
+		2: ownerAttrs("bob"),
+	}, nil)
+
+	auth := &fakeAuthorizer{allow: true}
+	m := &Manager{client: mockClient, authorizer: auth}
+	m.SetPolkitChecks(true)
+
+	assert.NoError(t, m.checkPurgeJobs(alice, "mine"))
+	assert.Empty(t, auth.actions)
+	assert.NoError(t, m.checkPurgeJobs(alice, "shared"))
+	assert.Equal(t, []string{ActionEditOthersJobs}, auth.actions)
+}
+
+func TestHandleDeletePrinter(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	mockClient.EXPECT().GetPrinters(mock.Anything).Return(map[string]ipp.Attributes{}, nil)
+
+	config := &fakeConfig{}
+	m := &Manager{client: mockClient, config: config, state: &CUPSState{Printers: map[string]*Printer{}}}
+
+	buf := &bytes.Buffer{}
+	handleDeletePrinter(&mockConn{Buffer: buf}, Request{ID: 1, Method: "cups.deletePrinter", Params: map[string]interface{}{"printerName": "Office"}}, m)
+
+	var resp models.Response[SuccessResult]
+	require.NoError(t, json.NewDecoder(buf).Decode(&resp))
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, [][]string{{"-x", "Office"}}, config.lpadmins)
+}
+
+func TestHandleDeletePrinter_PermissionDenied(t *testing.T) {
+	config := &fakeConfig{}
+	m := &Manager{config: config, authorizer: &fakeAuthorizer{allow: true}}
+	m.SetPolkitChecks(true)
+
+	// The test connection has no peer credentials, so the caller is unknown
+	buf := &bytes.Buffer{}
+	handleDeletePrinter(&mockConn{Buffer: buf}, Request{ID: 1, Method: "cups.deletePrinter", Params: map[string]interface{}{"printerName": "Office"}}, m)
+
+	var resp models.Response[SuccessResult]
+	require.NoError(t, json.NewDecoder(buf).Decode(&resp))
+	assert.Equal(t, models.CodePermissionDenied, resp.Code)
+	assert.Contains(t, resp.Error, ActionDeletePrinter)
+	assert.Empty(t, config.lpadmins)
+}
@@ -0,0 +1,124 @@
+package cups
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+)
+
+const (
+	// MaxPrintSize caps documents read from stdin or downloaded from a URL.
+	MaxPrintSize = 64 << 20
+
+	downloadTimeout = 60 * time.Second
+)
+
+var downloadClient = &http.Client{Timeout: downloadTimeout}
+
+func (m *Manager) PrintFile(printerName, path, title string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() {
+		return 0, fmt.Errorf("%s is a directory", path)
+	}
+
+	if title == "" {
+		title = filepath.Base(path)
+	}
+
+	return m.submit(printerName, ipp.Document{
+		Document: f,
+		Size:     int(info.Size()),
+		Name:     title,
+		MimeType: ipp.MimeTypeOctetStream,
+	})
+}
+
+// PrintReader buffers r (up to MaxPrintSize) and submits it as a single job,
+// so callers can pipe data from stdin without writing a temp file.
+func (m *Manager) PrintReader(printerName string, r io.Reader, title string) (int, error) {
+	data, err := readCapped(r, MaxPrintSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no data to print")
+	}
+
+	if title == "" {
+		title = "stdin"
+	}
+
+	return m.submit(printerName, ipp.Document{
+		Document: bytes.NewReader(data),
+		Size:     len(data),
+		Name:     title,
+		MimeType: ipp.MimeTypeOctetStream,
+	})
+}
+
+// PrintURL downloads an http(s) document server-side and submits it.
+func (m *Manager) PrintURL(printerName, rawURL, title string) (int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return 0, fmt.Errorf("unsupported url scheme: %s", u.Scheme)
+	}
+
+	resp, err := downloadClient.Get(u.String())
+	if err != nil {
+		return 0, fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download failed: %s", resp.Status)
+	}
+	if resp.ContentLength > MaxPrintSize {
+		return 0, fmt.Errorf("document too large: %d bytes (max %d)", resp.ContentLength, MaxPrintSize)
+	}
+
+	if title == "" {
+		title = filepath.Base(u.Path)
+		if title == "/" || title == "." {
+			title = u.Host
+		}
+	}
+
+	return m.PrintReader(printerName, resp.Body, title)
+}
+
+func (m *Manager) submit(printerName string, doc ipp.Document) (int, error) {
+	if printerName == "" {
+		return 0, fmt.Errorf("no printer specified")
+	}
+	return m.client.PrintJob(doc, printerName, nil)
+}
+
+func readCapped(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("document too large (max %d bytes)", limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,95 @@
+package cups
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mocks_cups "github.com/AvengeMedia/danklinux/internal/mocks/cups"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func expectDocument(t *testing.T, mockClient *mocks_cups.MockCUPSClientInterface, name, content string) {
+	mockClient.EXPECT().PrintJob(mock.Anything, "printer1", mock.Anything).
+		RunAndReturn(func(doc ipp.Document, printer string, attrs map[string]interface{}) (int, error) {
+			data, err := io.ReadAll(doc.Document)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+			assert.Equal(t, len(content), doc.Size)
+			assert.Equal(t, name, doc.Name)
+			return 42, nil
+		})
+}
+
+func TestManager_PrintFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0644))
+
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	expectDocument(t, mockClient, "report.txt", "hello")
+
+	m := &Manager{client: mockClient}
+	jobID, err := m.PrintFile("printer1", path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, jobID)
+
+	_, err = m.PrintFile("printer1", filepath.Join(t.TempDir(), "missing"), "")
+	assert.Error(t, err)
+}
+
+func TestManager_PrintReader(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	expectDocument(t, mockClient, "piped", "from stdin")
+
+	m := &Manager{client: mockClient}
+	jobID, err := m.PrintReader("printer1", strings.NewReader("from stdin"), "piped")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, jobID)
+
+	_, err = m.PrintReader("printer1", strings.NewReader(""), "")
+	assert.Error(t, err)
+
+	_, err = m.PrintReader("", strings.NewReader("x"), "")
+	assert.Error(t, err)
+}
+
+func TestReadCapped(t *testing.T) {
+	data, err := readCapped(strings.NewReader("12345"), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345", string(data))
+
+	_, err = readCapped(strings.NewReader("123456"), 5)
+	assert.Error(t, err)
+}
+
+func TestManager_PrintURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.pdf" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("%PDF-1.4"))
+	}))
+	defer srv.Close()
+
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	expectDocument(t, mockClient, "doc.pdf", "%PDF-1.4")
+
+	m := &Manager{client: mockClient}
+	jobID, err := m.PrintURL("printer1", srv.URL+"/files/doc.pdf", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, jobID)
+
+	_, err = m.PrintURL("printer1", srv.URL+"/missing.pdf", "")
+	assert.Error(t, err)
+
+	_, err = m.PrintURL("printer1", "file:///etc/passwd", "")
+	assert.Error(t, err)
+}
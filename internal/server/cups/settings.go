@@ -0,0 +1,370 @@
+package cups
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// ServerSettings mirrors the toggles cupsctl exposes, plus whether
+// cups-browsed adds queues shared by other machines
+type ServerSettings struct {
+	SharePrinters   bool `json:"sharePrinters"`
+	RemoteAny       bool `json:"remoteAny"`
+	RemoteAdmin     bool `json:"remoteAdmin"`
+	UserCancelAny   bool `json:"userCancelAny"`
+	DebugLogging    bool `json:"debugLogging"`
+	BrowseRemote    bool `json:"browseRemote"`
+	BrowseAvailable bool `json:"browseAvailable"`
+}
+
+// ServerSettingsUpdate changes only the fields that are set
+type ServerSettingsUpdate struct {
+	SharePrinters *bool
+	RemoteAny     *bool
+	RemoteAdmin   *bool
+	UserCancelAny *bool
+	DebugLogging  *bool
+	BrowseRemote  *bool
+}
+
+func (u ServerSettingsUpdate) empty() bool {
+	return u.SharePrinters == nil && u.RemoteAny == nil && u.RemoteAdmin == nil &&
+		u.UserCancelAny == nil && u.DebugLogging == nil && u.BrowseRemote == nil
+}
+
+// serverConfig reads and writes the scheduler and cups-browsed configuration
+type serverConfig interface {
+	// cupsctl runs cupsctl with args, escalating through polkit if the
+	// scheduler refuses the unprivileged request
+	cupsctl(args ...string) (string, error)
+	// lpadmin runs lpadmin with args, escalating the same way
+	lpadmin(args ...string) (string, error)
+	readCupsdConf() (string, error)
+	readBrowsedConf() (string, error)
+	writeBrowsedConf(content string) error
+}
+
+func (m *Manager) GetServerSettings() (ServerSettings, error) {
+	if m.config == nil {
+		return ServerSettings{}, fmt.Errorf("server settings are not available")
+	}
+
+	var settings ServerSettings
+	if out, err := m.config.cupsctl(); err == nil {
+		settings = parseCupsctlOutput(out)
+	} else {
+		conf, confErr := m.config.readCupsdConf()
+		if confErr != nil {
+			return ServerSettings{}, fmt.Errorf("failed to read CUPS server settings: %w", err)
+		}
+		settings = parseCupsdConf(conf)
+	}
+
+	if conf, err := m.config.readBrowsedConf(); err == nil {
+		settings.BrowseAvailable = true
+		settings.BrowseRemote = parseBrowseRemote(conf)
+	}
+	return settings, nil
+}
+
+func (m *Manager) SetServerSettings(update ServerSettingsUpdate) (ServerSettings, error) {
+	if m.config == nil {
+		return ServerSettings{}, fmt.Errorf("server settings are not available")
+	}
+	if update.empty() {
+		return ServerSettings{}, fmt.Errorf("no settings to change")
+	}
+
+	if args := cupsctlArgs(update); len(args) > 0 {
+		if _, err := m.config.cupsctl(args...); err != nil {
+			return ServerSettings{}, fmt.Errorf("failed to update CUPS server settings: %w", err)
+		}
+	}
+
+	if update.BrowseRemote != nil {
+		conf, err := m.config.readBrowsedConf()
+		if err != nil {
+			return ServerSettings{}, fmt.Errorf("cups-browsed is not installed: %w", err)
+		}
+		if err := m.config.writeBrowsedConf(setBrowseRemote(conf, *update.BrowseRemote)); err != nil {
+			return ServerSettings{}, fmt.Errorf("failed to update cups-browsed settings: %w", err)
+		}
+	}
+
+	return m.GetServerSettings()
+}
+
+func cupsctlArgs(update ServerSettingsUpdate) []string {
+	var args []string
+	flag := func(value *bool, name string) {
+		if value == nil {
+			return
+		}
+		if *value {
+			args = append(args, "--"+name)
+		} else {
+			args = append(args, "--no-"+name)
+		}
+	}
+	flag(update.SharePrinters, "share-printers")
+	flag(update.RemoteAny, "remote-any")
+	flag(update.RemoteAdmin, "remote-admin")
+	flag(update.UserCancelAny, "user-cancel-any")
+	flag(update.DebugLogging, "debug-logging")
+	return args
+}
+
+// parseCupsctlOutput reads the name=value listing cupsctl prints without
+// arguments
+func parseCupsctlOutput(out string) ServerSettings {
+	var s ServerSettings
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		on := value == "1"
+		switch name {
+		case "_share_printers":
+			s.SharePrinters = on
+		case "_remote_any":
+			s.RemoteAny = on
+		case "_remote_admin":
+			s.RemoteAdmin = on
+		case "_user_cancel_any":
+			s.UserCancelAny = on
+		case "_debug_logging":
+			s.DebugLogging = on
+		}
+	}
+	return s
+}
+
+// parseCupsdConf approximates libcups' cupsAdminGetServerSettings for when
+// cupsctl cannot query the scheduler
+func parseCupsdConf(conf string) ServerSettings {
+	var s ServerSettings
+	var browsing, remoteAccess bool
+	var location string
+	inCancelLimit := false
+	cancelLimitSeen := false
+	cancelRequiresOwner := false
+
+	scanner := bufio.NewScanner(strings.NewReader(conf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(name) {
+		case "port":
+			remoteAccess = true
+		case "listen":
+			if !isLocalListen(value) {
+				remoteAccess = true
+			}
+		case "browsing":
+			browsing = confBool(value)
+		case "loglevel":
+			s.DebugLogging = strings.HasPrefix(strings.ToLower(value), "debug")
+		case "<location":
+			location = strings.TrimSuffix(value, ">")
+		case "</location>":
+			location = ""
+		case "<limit":
+			for _, op := range strings.Fields(strings.TrimSuffix(value, ">")) {
+				if op == "Cancel-Job" {
+					inCancelLimit = true
+					cancelLimitSeen = true
+				}
+			}
+		case "</limit>":
+			inCancelLimit = false
+		case "require":
+			if inCancelLimit && strings.Contains(value, "@OWNER") {
+				cancelRequiresOwner = true
+			}
+		case "allow":
+			from := strings.ToLower(strings.TrimPrefix(value, "from "))
+			switch location {
+			case "/":
+				if from == "all" {
+					s.RemoteAny = true
+				}
+				if from != "localhost" && from != "127.0.0.1" {
+					s.SharePrinters = true
+				}
+			case "/admin":
+				if from != "localhost" && from != "127.0.0.1" {
+					s.RemoteAdmin = true
+				}
+			}
+		}
+	}
+
+	s.SharePrinters = s.SharePrinters && browsing && remoteAccess
+	s.RemoteAny = s.RemoteAny && remoteAccess
+	s.RemoteAdmin = s.RemoteAdmin && remoteAccess
+	s.UserCancelAny = cancelLimitSeen && !cancelRequiresOwner
+	return s
+}
+
+func isLocalListen(value string) bool {
+	if strings.HasPrefix(value, "/") {
+		return true
+	}
+	host, _, err := net.SplitHostPort(value)
+	if err != nil {
+		host = value
+	}
+	switch strings.Trim(host, "[]") {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+func confBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "yes", "on", "true", "1":
+		return true
+	}
+	return false
+}
+
+// parseBrowseRemote reports whether cups-browsed creates queues for remote
+// printers. It defaults to dnssd when BrowseRemoteProtocols is unset.
+func parseBrowseRemote(conf string) bool {
+	enabled := true
+	scanner := bufio.NewScanner(strings.NewReader(conf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "BrowseRemoteProtocols" {
+			continue
+		}
+		enabled = false
+		for _, proto := range fields[1:] {
+			if !strings.EqualFold(proto, "none") {
+				enabled = true
+			}
+		}
+	}
+	return enabled
+}
+
+// setBrowseRemote rewrites the BrowseRemoteProtocols directive, keeping
+// everything else in the file as is
+func setBrowseRemote(conf string, enabled bool) string {
+	directive := "BrowseRemoteProtocols none"
+	if enabled {
+		directive = "BrowseRemoteProtocols dnssd"
+	}
+
+	lines := strings.Split(strings.TrimRight(conf, "\n"), "\n")
+	replaced := false
+	out := make([]string, 0, len(lines)+1)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == "BrowseRemoteProtocols" {
+			if replaced {
+				continue
+			}
+			line = directive
+			replaced = true
+		}
+		out = append(out, line)
+	}
+	if !replaced {
+		out = append(out, directive)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// systemConfig drives the local cupsctl and config files
+type systemConfig struct {
+	host       string
+	port       int
+	username   string
+	serverRoot string
+}
+
+func newSystemConfig(host string, port int, username string) *systemConfig {
+	serverRoot := os.Getenv("CUPS_SERVERROOT")
+	if serverRoot == "" {
+		serverRoot = "/etc/cups"
+	}
+	return &systemConfig{host: host, port: port, username: username, serverRoot: serverRoot}
+}
+
+func (c *systemConfig) cupsctl(args ...string) (string, error) {
+	return c.admin("cupsctl", args...)
+}
+
+func (c *systemConfig) lpadmin(args ...string) (string, error) {
+	return c.admin("lpadmin", args...)
+}
+
+// admin runs a CUPS administration tool against the configured server,
+// retrying through pkexec for a local scheduler that refuses the user
+func (c *systemConfig) admin(tool string, args ...string) (string, error) {
+	full := []string{"-h", net.JoinHostPort(c.host, strconv.Itoa(c.port))}
+	if c.username != "" {
+		full = append(full, "-U", c.username)
+	}
+	full = append(full, args...)
+
+	out, err := exec.Command(tool, full...).CombinedOutput()
+	if err == nil {
+		return string(out), nil
+	}
+	if !isLocalCUPS(c.host) || os.Geteuid() == 0 {
+		return "", fmt.Errorf("%s: %w: %s", tool, err, strings.TrimSpace(string(out)))
+	}
+
+	out, err = exec.Command("pkexec", append([]string{tool}, full...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("pkexec %s: %w: %s", tool, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func (c *systemConfig) readCupsdConf() (string, error) {
+	if !isLocalCUPS(c.host) {
+		return "", fmt.Errorf("cupsd.conf is not readable on remote server %s", c.host)
+	}
+	data, err := os.ReadFile(filepath.Join(c.serverRoot, "cupsd.conf"))
+	return string(data), err
+}
+
+func (c *systemConfig) readBrowsedConf() (string, error) {
+	if !isLocalCUPS(c.host) {
+		return "", fmt.Errorf("cups-browsed.conf is not readable on remote server %s", c.host)
+	}
+	data, err := os.ReadFile(filepath.Join(c.serverRoot, "cups-browsed.conf"))
+	return string(data), err
+}
+
+func (c *systemConfig) writeBrowsedConf(content string) error {
+	path := filepath.Join(c.serverRoot, "cups-browsed.conf")
+	if err := utils.PrivilegedWrite(path, content); err != nil {
+		return err
+	}
+
+	// cups-browsed only reads its config at startup
+	if out, err := exec.Command("systemctl", "try-restart", "cups-browsed.service").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restart cups-browsed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package cups
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConfig struct {
+	cupsctlOut  string
+	cupsctlErr  error
+	cupsdConf   string
+	browsedConf string
+	browsedErr  error
+	calls       [][]string
+	lpadmins    [][]string
+	lpadminErr  error
+	written     string
+}
+
+func (f *fakeConfig) cupsctl(args ...string) (string, error) {
+	f.calls = append(f.calls, args)
+	if len(args) > 0 {
+		return "", nil
+	}
+	return f.cupsctlOut, f.cupsctlErr
+}
+
+func (f *fakeConfig) lpadmin(args ...string) (string, error) {
+	f.lpadmins = append(f.lpadmins, args)
+	if len(args) > 0 && args[0] == "-x" {
+		return "", nil
+	}
+	return "", f.lpadminErr
+}
+
+func (f *fakeConfig) readCupsdConf() (string, error) {
+	return f.cupsdConf, nil
+}
+
+func (f *fakeConfig) readBrowsedConf() (string, error) {
+	return f.browsedConf, f.browsedErr
+}
+
+func (f *fakeConfig) writeBrowsedConf(content string) error {
+	f.written = content
+	f.browsedConf = content
+	return nil
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestParseCupsctlOutput(t *testing.T) {
+	out := "_debug_logging=0\n_remote_admin=1\n_remote_any=0\n_share_printers=1\n_user_cancel_any=1\nBrowseLocalProtocols=dnssd\n"
+	assert.Equal(t, ServerSettings{
+		SharePrinters: true,
+		RemoteAdmin:   true,
+		UserCancelAny: true,
+	}, parseCupsctlOutput(out))
+}
+
+func TestParseCupsdConf(t *testing.T) {
+	t.Run("default local only", func(t *testing.T) {
+		conf := `LogLevel warn
+Listen localhost:631
+Listen /run/cups/cups.sock
+Browsing No
+<Location />
+  Order allow,deny
+</Location>
+<Policy default>
+  <Limit Cancel-Job CUPS-Authenticate-Job>
+    Require user @OWNER @SYSTEM
+    Order deny,allow
+  </Limit>
+</Policy>
+`
+		assert.Equal(t, ServerSettings{}, parseCupsdConf(conf))
+	})
+
+	t.Run("shared with remote admin", func(t *testing.T) {
+		conf := `LogLevel debug
+Port 631
+Browsing On
+<Location />
+  Order allow,deny
+  Allow @LOCAL
+</Location>
+<Location /admin>
+  Order allow,deny
+  Allow @LOCAL
+</Location>
+<Policy default>
+  <Limit Cancel-Job>
+    Order deny,allow
+  </Limit>
+</Policy>
+`
+		assert.Equal(t, ServerSettings{
+			SharePrinters: true,
+			RemoteAdmin:   true,
+			UserCancelAny: true,
+			DebugLogging:  true,
+		}, parseCupsdConf(conf))
+	})
+
+	t.Run("allow all", func(t *testing.T) {
+		conf := "Listen 0.0.0.0:631\nBrowsing Yes\n<Location />\n  Allow from all\n</Location>\n"
+		s := parseCupsdConf(conf)
+		assert.True(t, s.SharePrinters)
+		assert.True(t, s.RemoteAny)
+	})
+}
+
+func TestBrowseRemote(t *testing.T) {
+	assert.True(t, parseBrowseRemote("# defaults\n"))
+	assert.True(t, parseBrowseRemote("BrowseRemoteProtocols dnssd cups\n"))
+	assert.False(t, parseBrowseRemote("BrowseRemoteProtocols none\n"))
+
+	conf := "# comment\nBrowseRemoteProtocols dnssd\nBrowseRemoteProtocols cups\nCreateIPPPrinterQueues All\n"
+	assert.Equal(t, "# comment\nBrowseRemoteProtocols none\nCreateIPPPrinterQueues All\n", setBrowseRemote(conf, false))
+	assert.Equal(t, "# comment\nBrowseRemoteProtocols dnssd\n", setBrowseRemote("# comment\n", true))
+}
+
+func TestGetServerSettings(t *testing.T) {
+	t.Run("cupsctl", func(t *testing.T) {
+		cfg := &fakeConfig{cupsctlOut: "_share_printers=1\n", browsedConf: "BrowseRemoteProtocols none\n"}
+		m := &Manager{config: cfg}
+
+		s, err := m.GetServerSettings()
+		require.NoError(t, err)
+		assert.True(t, s.SharePrinters)
+		assert.True(t, s.BrowseAvailable)
+		assert.False(t, s.BrowseRemote)
+	})
+
+	t.Run("falls back to cupsd.conf", func(t *testing.T) {
+		cfg := &fakeConfig{
+			cupsctlErr: errors.New("Forbidden"),
+			cupsdConf:  "LogLevel debug2\n",
+			browsedErr: errors.New("not found"),
+		}
+		m := &Manager{config: cfg}
+
+		s, err := m.GetServerSettings()
+		require.NoError(t, err)
+		assert.True(t, s.DebugLogging)
+		assert.False(t, s.BrowseAvailable)
+	})
+}
+
+func TestSetServerSettings(t *testing.T) {
+	cfg := &fakeConfig{browsedConf: "BrowseRemoteProtocols dnssd\n"}
+	m := &Manager{config: cfg}
+
+	_, err := m.SetServerSettings(ServerSettingsUpdate{})
+	assert.Error(t, err)
+
+	_, err = m.SetServerSettings(ServerSettingsUpdate{
+		SharePrinters: boolPtr(true),
+		DebugLogging:  boolPtr(false),
+		BrowseRemote:  boolPtr(false),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--share-printers", "--no-debug-logging"}, cfg.calls[0])
+	assert.Equal(t, "BrowseRemoteProtocols none\n", cfg.written)
+
+	cfg.browsedErr = errors.New("not found")
+	_, err = m.SetServerSettings(ServerSettingsUpdate{BrowseRemote: boolPtr(true)})
+	assert.Error(t, err)
+}
+
+func TestHandleSetServerSettings(t *testing.T) {
+	cfg := &fakeConfig{cupsctlOut: "_remote_any=1\n", browsedErr: errors.New("not found")}
+	m := &Manager{config: cfg}
+
+	buf := &bytes.Buffer{}
+	handleSetServerSettings(&mockConn{Buffer: buf}, Request{
+		ID:     1,
+		Method: "cups.setServerSettings",
+		Params: map[string]interface{}{"remoteAny": true},
+	}, m)
+
+	var resp models.Response[ServerSettings]
+	require.NoError(t, json.NewDecoder(buf).Decode(&resp))
+	require.NotNil(t, resp.Result)
+	assert.True(t, resp.Result.RemoteAny)
+	assert.Equal(t, []string{"--remote-any"}, cfg.calls[0])
+
+	buf.Reset()
+	handleSetServerSettings(&mockConn{Buffer: buf}, Request{
+		ID:     2,
+		Method: "cups.setServerSettings",
+		Params: map[string]interface{}{"remoteAny": "yes"},
+	}, m)
+	require.NoError(t, json.NewDecoder(buf).Decode(&resp))
+	assert.Equal(t, "missing or invalid 'remoteAny' parameter", resp.Error)
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/pkg/ipp"
 )
@@ -39,6 +40,8 @@ func (sm *SubscriptionManager) Start() error {
 	sm.running = true
 	sm.mu.Unlock()
 
+	cancelStaleSubscriptions(sm.client, sm.baseURL, subscriptionOwner(), subscriptionTag())
+
 	subID, err := sm.createSubscription()
 	if err != nil {
 		sm.mu.Lock()
@@ -51,7 +54,7 @@ func (sm *SubscriptionManager) Start() error {
 	log.Infof("[CUPS] Created IPP subscription with ID %d", subID)
 
 	sm.wg.Add(1)
-	go sm.notificationLoop()
+	crash.Go("cups.notificationLoop", sm.notificationLoop)
 
 	return nil
 }
@@ -59,7 +62,6 @@ func (sm *SubscriptionManager) Start() error {
 func (sm *SubscriptionManager) createSubscription() (int, error) {
 	req := ipp.NewRequest(ipp.OperationCreatePrinterSubscriptions, 1)
 	req.OperationAttributes[ipp.AttributePrinterURI] = fmt.Sprintf("%s/", sm.baseURL)
-	req.OperationAttributes[ipp.AttributeRequestingUserName] = "dms"
 
 	// Subscription attributes go in SubscriptionAttributes (subscription-attributes-tag in IPP)
 	req.SubscriptionAttributes = map[string]interface{}{
@@ -74,6 +76,7 @@ func (sm *SubscriptionManager) createSubscription() (int, error) {
 		"notify-pull-method":    "ippget",
 		"notify-lease-duration": 0,
 	}
+	applySubscriptionIdentity(req)
 
 	// Send to root IPP endpoint
 	resp, err := sm.client.SendRequest(fmt.Sprintf("%s/", sm.baseURL), req, nil)
@@ -146,7 +149,7 @@ func (sm *SubscriptionManager) notificationLoop() {
 func (sm *SubscriptionManager) fetchNotificationsWithWait() (bool, error) {
 	req := ipp.NewRequest(ipp.OperationGetNotifications, 1)
 	req.OperationAttributes[ipp.AttributePrinterURI] = fmt.Sprintf("%s/", sm.baseURL)
-	req.OperationAttributes[ipp.AttributeRequestingUserName] = "dms"
+	req.OperationAttributes[ipp.AttributeRequestingUserName] = subscriptionOwner()
 	req.OperationAttributes["notify-subscription-ids"] = sm.subscriptionID
 	if sm.sequenceNumber > 0 {
 		req.OperationAttributes["notify-sequence-numbers"] = sm.sequenceNumber
@@ -233,7 +236,7 @@ func (sm *SubscriptionManager) Stop() {
 func (sm *SubscriptionManager) cancelSubscription() {
 	req := ipp.NewRequest(ipp.OperationCancelSubscription, 1)
 	req.OperationAttributes[ipp.AttributePrinterURI] = fmt.Sprintf("%s/", sm.baseURL)
-	req.OperationAttributes[ipp.AttributeRequestingUserName] = "dms"
+	req.OperationAttributes[ipp.AttributeRequestingUserName] = subscriptionOwner()
 	req.OperationAttributes["notify-subscription-id"] = sm.subscriptionID
 
 	_, err := sm.client.SendRequest(fmt.Sprintf("%s/", sm.baseURL), req, nil)
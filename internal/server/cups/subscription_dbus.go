@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/pkg/ipp"
 	"github.com/godbus/dbus/v5"
@@ -49,6 +50,8 @@ func (sm *DBusSubscriptionManager) Start() error {
 	}
 	sm.conn = conn
 
+	cancelStaleSubscriptions(sm.client, sm.baseURL, subscriptionOwner(), subscriptionTag())
+
 	subID, err := sm.createDBusSubscription()
 	if err != nil {
 		sm.conn.Close()
@@ -73,7 +76,7 @@ func (sm *DBusSubscriptionManager) Start() error {
 	}
 
 	sm.wg.Add(1)
-	go sm.dbusListenerLoop()
+	crash.Go("cups.dbusListener", sm.dbusListenerLoop)
 
 	return nil
 }
@@ -81,7 +84,6 @@ func (sm *DBusSubscriptionManager) Start() error {
 func (sm *DBusSubscriptionManager) createDBusSubscription() (int, error) {
 	req := ipp.NewRequest(ipp.OperationCreatePrinterSubscriptions, 2)
 	req.OperationAttributes[ipp.AttributePrinterURI] = fmt.Sprintf("%s/", sm.baseURL)
-	req.OperationAttributes[ipp.AttributeRequestingUserName] = "dms"
 
 	req.SubscriptionAttributes = map[string]interface{}{
 		"notify-events": []string{
@@ -95,6 +97,7 @@ func (sm *DBusSubscriptionManager) createDBusSubscription() (int, error) {
 		"notify-recipient-uri":  "dbus:/",
 		"notify-lease-duration": 86400,
 	}
+	applySubscriptionIdentity(req)
 
 	resp, err := sm.client.SendRequest(fmt.Sprintf("%s/", sm.baseURL), req, nil)
 	if err != nil {
@@ -283,7 +286,7 @@ func (sm *DBusSubscriptionManager) Stop() {
 func (sm *DBusSubscriptionManager) cancelSubscription() {
 	req := ipp.NewRequest(ipp.OperationCancelSubscription, 1)
 	req.OperationAttributes[ipp.AttributePrinterURI] = fmt.Sprintf("%s/", sm.baseURL)
-	req.OperationAttributes[ipp.AttributeRequestingUserName] = "dms"
+	req.OperationAttributes[ipp.AttributeRequestingUserName] = subscriptionOwner()
 	req.OperationAttributes["notify-subscription-id"] = sm.subscriptionID
 
 	_, err := sm.client.SendRequest(fmt.Sprintf("%s/", sm.baseURL), req, nil)
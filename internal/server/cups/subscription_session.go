@@ -0,0 +1,96 @@
+package cups
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/session"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+)
+
+// notify-user-data is limited to 63 octets
+const maxUserData = 63
+
+// subscriptionOwner is the user subscriptions are created as. Naming the real
+// user rather than a shared one keeps the daemons of different users from
+// reading or cancelling each other's subscriptions.
+func subscriptionOwner() string {
+	return session.Current().User
+}
+
+// subscriptionTag marks this session's subscriptions in notify-user-data. It
+// is empty without a logind session, as a tag shared by every session of the
+// user would make them sweep each other's subscriptions.
+func subscriptionTag() string {
+	id := session.Current().ID
+	if id == "" {
+		return ""
+	}
+	tag := "dms:" + id
+	if len(tag) > maxUserData {
+		tag = tag[:maxUserData]
+	}
+	return tag
+}
+
+// applySubscriptionIdentity sets the owner and session tag on a
+// Create-Printer-Subscriptions request
+func applySubscriptionIdentity(req *ipp.Request) {
+	req.OperationAttributes[ipp.AttributeRequestingUserName] = subscriptionOwner()
+	if tag := subscriptionTag(); tag != "" {
+		req.SubscriptionAttributes["notify-user-data"] = tag
+	}
+}
+
+// cancelStaleSubscriptions removes subscriptions an earlier run of this
+// session left behind, for instance after a crash, which would otherwise
+// deliver every event twice. Subscriptions of other sessions are left alone.
+func cancelStaleSubscriptions(client CUPSClientInterface, baseURL, owner, tag string) {
+	if tag == "" {
+		return
+	}
+
+	req := ipp.NewRequest(ipp.OperationGetSubscriptions, 1)
+	req.OperationAttributes[ipp.AttributePrinterURI] = fmt.Sprintf("%s/", baseURL)
+	req.OperationAttributes[ipp.AttributeRequestingUserName] = owner
+	req.OperationAttributes["my-subscriptions"] = true
+	req.OperationAttributes[ipp.AttributeRequestedAttributes] = []string{"notify-subscription-id", "notify-user-data"}
+
+	resp, err := client.SendRequest(fmt.Sprintf("%s/", baseURL), req, nil)
+	if err != nil {
+		log.Debugf("[CUPS] Failed to list subscriptions: %v", err)
+		return
+	}
+	if err := resp.CheckForErrors(); err != nil {
+		// client-error-not-found when there are none
+		return
+	}
+
+	for _, attrs := range resp.SubscriptionAttributes {
+		if getStringAttr(attrs, "notify-user-data") != tag {
+			continue
+		}
+		id := getIntAttr(attrs, "notify-subscription-id")
+		if id == 0 {
+			continue
+		}
+		if err := cancelSubscriptionID(client, baseURL, owner, id); err != nil {
+			log.Warnf("[CUPS] Failed to cancel stale subscription %d: %v", id, err)
+			continue
+		}
+		log.Infof("[CUPS] Cancelled stale subscription %d", id)
+	}
+}
+
+func cancelSubscriptionID(client CUPSClientInterface, baseURL, owner string, id int) error {
+	req := ipp.NewRequest(ipp.OperationCancelSubscription, 1)
+	req.OperationAttributes[ipp.AttributePrinterURI] = fmt.Sprintf("%s/", baseURL)
+	req.OperationAttributes[ipp.AttributeRequestingUserName] = owner
+	req.OperationAttributes["notify-subscription-id"] = id
+
+	resp, err := client.SendRequest(fmt.Sprintf("%s/", baseURL), req, nil)
+	if err != nil {
+		return err
+	}
+	return resp.CheckForErrors()
+}
@@ -0,0 +1,52 @@
+package cups
+
+import (
+	"io"
+	"testing"
+
+	mocks_cups "github.com/AvengeMedia/danklinux/internal/mocks/cups"
+	"github.com/AvengeMedia/danklinux/pkg/ipp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCancelStaleSubscriptions(t *testing.T) {
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+
+	mockClient.EXPECT().SendRequest(mock.Anything, mock.MatchedBy(func(req *ipp.Request) bool {
+		return req.Operation == ipp.OperationGetSubscriptions
+	}), mock.Anything).Return(&ipp.Response{
+		StatusCode: ipp.StatusOk,
+		SubscriptionAttributes: []ipp.Attributes{
+			{
+				"notify-subscription-id": []ipp.Attribute{{Value: 11}},
+				"notify-user-data":       []ipp.Attribute{{Value: "dms:2"}},
+			},
+			{
+				"notify-subscription-id": []ipp.Attribute{{Value: 12}},
+				"notify-user-data":       []ipp.Attribute{{Value: "dms:7"}},
+			},
+			{
+				"notify-subscription-id": []ipp.Attribute{{Value: 13}},
+			},
+		},
+	}, nil).Once()
+
+	var cancelled []int
+	mockClient.EXPECT().SendRequest(mock.Anything, mock.MatchedBy(func(req *ipp.Request) bool {
+		return req.Operation == ipp.OperationCancelSubscription
+	}), mock.Anything).RunAndReturn(func(_ string, req *ipp.Request, _ io.Writer) (*ipp.Response, error) {
+		assert.Equal(t, "alice", req.OperationAttributes[ipp.AttributeRequestingUserName])
+		cancelled = append(cancelled, req.OperationAttributes["notify-subscription-id"].(int))
+		return &ipp.Response{StatusCode: ipp.StatusOk}, nil
+	})
+
+	cancelStaleSubscriptions(mockClient, "http://localhost:631", "alice", "dms:2")
+	assert.Equal(t, []int{11}, cancelled)
+}
+
+func TestCancelStaleSubscriptions_NoTag(t *testing.T) {
+	// without a session tag nothing is listed or cancelled
+	mockClient := mocks_cups.NewMockCUPSClientInterface(t)
+	cancelStaleSubscriptions(mockClient, "http://localhost:631", "alice", "")
+}
@@ -3,6 +3,7 @@ package cups
 import (
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/AvengeMedia/danklinux/pkg/ipp"
@@ -24,14 +25,18 @@ type Printer struct {
 	Jobs        []Job  `json:"jobs"`
 }
 
+// Job is a print job. State is what clients should switch on and StateInfo
+// how to show it; IPPState is the job-state value CUPS reported.
 type Job struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	State       string    `json:"state"`
-	Printer     string    `json:"printer"`
-	User        string    `json:"user"`
-	Size        int       `json:"size"`
-	TimeCreated time.Time `json:"timeCreated"`
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	State       JobState     `json:"state"`
+	StateInfo   JobStateInfo `json:"stateInfo"`
+	IPPState    int          `json:"ippState,omitempty"`
+	Printer     string       `json:"printer"`
+	User        string       `json:"user"`
+	Size        int          `json:"size"`
+	TimeCreated time.Time    `json:"timeCreated"`
 }
 
 type Manager struct {
@@ -47,6 +52,17 @@ type Manager struct {
 	notifierWg        sync.WaitGroup
 	lastNotifiedState *CUPSState
 	baseURL           string
+	config            serverConfig
+
+	failureMutex       sync.RWMutex
+	failureSubscribers map[string]chan JobFailure
+	// reportedFailures remembers the failed state already published per job
+	// so repeated events don't raise the same notification twice
+	reportedFailures map[int]JobState
+
+	// authorizer checks polkit when polkitChecks is on; nil uses polkitd
+	authorizer   authorizer
+	polkitChecks atomic.Bool
 }
 
 type SubscriptionManagerInterface interface {
@@ -62,7 +78,12 @@ type CUPSClientInterface interface {
 	PausePrinter(printer string) error
 	ResumePrinter(printer string) error
 	CancelAllJob(printer string, purge bool) error
+	PrintJob(doc ipp.Document, printer string, jobAttributes map[string]interface{}) (int, error)
 	SendRequest(url string, req *ipp.Request, additionalResponseData io.Writer) (*ipp.Response, error)
+	GetDevices() (map[string]ipp.Attributes, error)
+	GetPrinterAttributes(printer string, attributes []string) (ipp.Attributes, error)
+	GetJobAttributes(jobID int, attributes []string) (ipp.Attributes, error)
+	RestartJob(jobID int) error
 }
 
 type SubscriptionEvent struct {
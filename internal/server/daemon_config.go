@@ -0,0 +1,369 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/daemonconfig"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/brightness"
+	"github.com/AvengeMedia/danklinux/internal/server/notifications"
+	"github.com/AvengeMedia/danklinux/internal/server/power"
+	"github.com/AvengeMedia/danklinux/internal/server/wayland"
+)
+
+var errModuleDisabled = errors.New("disabled in daemon.toml")
+
+var (
+	daemonConfig      *daemonconfig.Config
+	daemonConfigMutex sync.Mutex
+)
+
+// ConfigReloadResult reports what config.reload changed
+type ConfigReloadResult struct {
+	Path            string   `json:"path"`
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restartRequired"`
+	Problems        []string `json:"problems,omitempty"`
+}
+
+// getDaemonConfig loads daemon.toml on first use. GetSocketPath needs it
+// before Start runs, so it cannot wait for server startup.
+func getDaemonConfig() *daemonconfig.Config {
+	daemonConfigMutex.Lock()
+	defer daemonConfigMutex.Unlock()
+
+	if daemonConfig == nil {
+		cfg, err := daemonconfig.Load()
+		if err != nil {
+			log.Warnf("Using default daemon config: %v", err)
+			cfg = daemonconfig.Default()
+		}
+		for _, problem := range cfg.Problems {
+			log.Warnf("daemon.toml: %s", problem)
+		}
+		daemonConfig = cfg
+	}
+	return daemonConfig
+}
+
+func checkModuleEnabled(module string) error {
+	if !getDaemonConfig().ModuleEnabled(module) {
+		return fmt.Errorf("%s %w", module, errModuleDisabled)
+	}
+	return nil
+}
+
+// reloadDaemonConfig re-reads daemon.toml and applies the options that can
+// change at runtime. A file that fails to parse leaves the running config
+// alone.
+func reloadDaemonConfig() (ConfigReloadResult, error) {
+	old := getDaemonConfig()
+	cfg, err := daemonconfig.LoadFile(old.Path)
+	if err != nil {
+		return ConfigReloadResult{}, err
+	}
+
+	result := ConfigReloadResult{
+		Path:            cfg.Path,
+		Applied:         []string{},
+		RestartRequired: []string{},
+		Problems:        cfg.Problems,
+	}
+
+	changed := make(map[string]bool)
+	for _, opt := range daemonconfig.Options {
+		if opt.Format(old.Get(opt.Key)) == opt.Format(cfg.Get(opt.Key)) {
+			continue
+		}
+		changed[opt.Key] = true
+		if opt.HotReload {
+			result.Applied = append(result.Applied, opt.Key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, opt.Key)
+		}
+	}
+
+	daemonConfigMutex.Lock()
+	daemonConfig = cfg
+	daemonConfigMutex.Unlock()
+
+	if changed["brightness.ddc-scan-interval"] || changed["brightness.key-steps"] || changed["brightness.slider-steps"] {
+		result.Problems = append(result.Problems, applyBrightnessConfig(cfg)...)
+	}
+	if changed["sensors.poll-interval"] {
+		applySensorsConfig(cfg)
+	}
+	if changed["network.speedtest-url"] || changed["network.speedtest-upload-url"] {
+		applyNetworkConfig(cfg)
+	}
+	if changed["calendar.refresh-interval"] {
+		applyCalendarConfig(cfg)
+	}
+	if changed["termcolors.terminals"] {
+		applyTermcolorsConfig(cfg)
+	}
+	if cm := cupsManager.Load(); changed["cups.polkit"] && cm != nil {
+		cm.SetPolkitChecks(cfg.Bool("cups.polkit"))
+	}
+	if changed["health.check-interval"] && healthManager != nil {
+		if err := healthManager.SetInterval(cfg.Duration("health.check-interval")); err != nil {
+			result.Problems = append(result.Problems, err.Error())
+		}
+	}
+	if err := applyNightLightConfig(cfg, changed); err != nil {
+		result.Problems = append(result.Problems, err.Error())
+	}
+	if notificationsManager != nil {
+		applyNotificationsConfig(cfg, changed)
+	}
+	if pm := powerManager.Load(); pm != nil && changedPowerPolicy(changed) {
+		if err := pm.SetPolicy(powerPolicyConfig(cfg)); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("power policy: %v", err))
+		}
+	}
+
+	log.Infof("Reloaded %s: %d applied, %d need a restart", cfg.Path, len(result.Applied), len(result.RestartRequired))
+	return result, nil
+}
+
+// applyBrightnessConfig returns the step curves it could not use
+func applyBrightnessConfig(cfg *daemonconfig.Config) []string {
+	brm := brightnessManager.Load()
+	if brm == nil {
+		return nil
+	}
+	brm.SetDDCScanInterval(cfg.Duration("brightness.ddc-scan-interval"))
+
+	var problems []string
+	for key, source := range map[string]brightness.InputSource{
+		"brightness.key-steps":    brightness.SourceKeys,
+		"brightness.slider-steps": brightness.SourceSlider,
+	} {
+		var curve brightness.StepCurve
+		if value := cfg.String(key); value != "" {
+			var err error
+			if curve, err = brightness.ParseStepCurve(value); err != nil {
+				log.Warnf("Ignoring %s: %v", key, err)
+				problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+				continue
+			}
+		}
+		brm.SetStepCurve(source, curve)
+	}
+	return problems
+}
+
+func applySensorsConfig(cfg *daemonconfig.Config) {
+	if sensorsManager != nil {
+		if err := sensorsManager.SetPollInterval(cfg.Duration("sensors.poll-interval")); err != nil {
+			log.Warnf("Failed to set sensor poll interval: %v", err)
+		}
+	}
+}
+
+func applyNetworkConfig(cfg *daemonconfig.Config) {
+	if nm := networkManager.Load(); nm != nil {
+		nm.SetSpeedTestEndpoints(cfg.String("network.speedtest-url"), cfg.String("network.speedtest-upload-url"))
+	}
+}
+
+func applyCalendarConfig(cfg *daemonconfig.Config) {
+	if calendarManager != nil {
+		if err := calendarManager.SetRefreshInterval(cfg.Duration("calendar.refresh-interval")); err != nil {
+			log.Warnf("Failed to set calendar refresh interval: %v", err)
+		}
+	}
+}
+
+func applyTermcolorsConfig(cfg *daemonconfig.Config) {
+	if termcolorsManager != nil {
+		termcolorsManager.SetTerminals(cfg.String("termcolors.terminals"))
+	}
+}
+
+// nightLightConfig overlays the [nightlight] options set in daemon.toml on
+// the gamma defaults. Options left out keep whatever the shell sets over
+// IPC.
+func nightLightConfig(cfg *daemonconfig.Config, base wayland.Config) wayland.Config {
+	if cfg.IsSet("nightlight.low-temp") {
+		base.LowTemp = cfg.Int("nightlight.low-temp")
+	}
+	if cfg.IsSet("nightlight.high-temp") {
+		base.HighTemp = cfg.Int("nightlight.high-temp")
+	}
+	if cfg.IsSet("nightlight.enabled") {
+		base.Enabled = cfg.Bool("nightlight.enabled")
+	}
+	sunrise, sunset, ok := manualTimes(cfg)
+	if ok {
+		base.ManualSunrise = &sunrise
+		base.ManualSunset = &sunset
+	}
+	return base
+}
+
+func manualTimes(cfg *daemonconfig.Config) (time.Time, time.Time, bool) {
+	sunriseStr := cfg.String("nightlight.sunrise")
+	sunsetStr := cfg.String("nightlight.sunset")
+	if sunriseStr == "" || sunsetStr == "" {
+		return time.Time{}, time.Time{}, false
+	}
+	sunrise, err1 := time.Parse("15:04", sunriseStr)
+	sunset, err2 := time.Parse("15:04", sunsetStr)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return sunrise, sunset, true
+}
+
+// applyNightLightConfig pushes only the [nightlight] options that changed,
+// so a reload does not undo what the shell set for the others
+func applyNightLightConfig(cfg *daemonconfig.Config, changed map[string]bool) error {
+	if waylandManager == nil {
+		return nil
+	}
+
+	if changed["nightlight.low-temp"] || changed["nightlight.high-temp"] {
+		if err := waylandManager.SetTemperature(cfg.Int("nightlight.low-temp"), cfg.Int("nightlight.high-temp")); err != nil {
+			return fmt.Errorf("night light temperature: %w", err)
+		}
+	}
+	if changed["nightlight.sunrise"] || changed["nightlight.sunset"] {
+		if sunrise, sunset, ok := manualTimes(cfg); ok {
+			if err := waylandManager.SetManualTimes(sunrise, sunset); err != nil {
+				return fmt.Errorf("night light schedule: %w", err)
+			}
+		} else {
+			waylandManager.ClearManualTimes()
+		}
+	}
+	if changed["nightlight.enabled"] {
+		waylandManager.SetEnabled(cfg.Bool("nightlight.enabled"))
+	}
+	return nil
+}
+
+// powerPolicyKeys map each [power] option to its policy field's value
+var powerPolicyKeys = []struct {
+	key   string
+	value func(power.Policy) string
+}{
+	{"power.policy", func(p power.Policy) string { return strconv.FormatBool(p.Enabled) }},
+	{"power.ac-profile", func(p power.Policy) string { return p.ACProfile }},
+	{"power.battery-profile", func(p power.Policy) string { return p.BatteryProfile }},
+	{"power.battery-brightness-cap", func(p power.Policy) string { return strconv.Itoa(p.BatteryBrightnessCap) }},
+	{"power.battery-refresh-rate", func(p power.Policy) string { return strconv.Itoa(int(math.Round(p.BatteryRefreshRate))) }},
+}
+
+func changedPowerPolicy(changed map[string]bool) bool {
+	for _, k := range powerPolicyKeys {
+		if changed[k.key] {
+			return true
+		}
+	}
+	return false
+}
+
+// powerPolicyConfig reads the [power] policy. DMS_DISABLE_POWER_POLICY
+// still turns it off whatever the file says.
+func powerPolicyConfig(cfg *daemonconfig.Config) power.Policy {
+	policy := power.DefaultPolicy()
+	policy.Enabled = policy.Enabled && cfg.Bool("power.policy")
+	policy.ACProfile = cfg.String("power.ac-profile")
+	policy.BatteryProfile = cfg.String("power.battery-profile")
+	policy.BatteryBrightnessCap = cfg.Int("power.battery-brightness-cap")
+	policy.BatteryRefreshRate = float64(cfg.Int("power.battery-refresh-rate"))
+	return policy
+}
+
+// powerPolicyStore writes policy changes made over IPC to daemon.toml
+type powerPolicyStore struct{}
+
+func (powerPolicyStore) SavePolicy(policy power.Policy) error {
+	var values []daemonOptionValue
+	for _, k := range powerPolicyKeys {
+		// DMS_DISABLE_POWER_POLICY is not a setting to write back
+		if k.key == "power.policy" && !policy.Enabled && !power.DefaultPolicy().Enabled {
+			continue
+		}
+		values = append(values, daemonOptionValue{k.key, k.value(policy)})
+	}
+	return saveDaemonOptions(values)
+}
+
+func notificationKey(urgency notifications.Urgency, field string) string {
+	return fmt.Sprintf("notifications.%s-%s", urgency, field)
+}
+
+// notificationsConfig reads the forwarding targets under [notifications]
+func notificationsConfig(cfg *daemonconfig.Config) notifications.ForwardConfig {
+	config := notifications.ForwardConfig{Targets: make(map[notifications.Urgency]notifications.ForwardTarget)}
+	for _, u := range daemonconfig.NotificationUrgencies {
+		urgency := notifications.Urgency(u)
+		config.Targets[urgency] = notifications.ForwardTarget{
+			File:     cfg.String(notificationKey(urgency, "file")),
+			Terminal: cfg.Bool(notificationKey(urgency, "terminal")),
+			Bell:     cfg.Bool(notificationKey(urgency, "bell")),
+		}
+	}
+	return config
+}
+
+// applyNotificationsConfig replaces the targets of the urgencies whose
+// options changed
+func applyNotificationsConfig(cfg *daemonconfig.Config, changed map[string]bool) {
+	for urgency, target := range notificationsConfig(cfg).Targets {
+		if changed[notificationKey(urgency, "file")] || changed[notificationKey(urgency, "terminal")] || changed[notificationKey(urgency, "bell")] {
+			notificationsManager.SetTarget(urgency, target)
+		}
+	}
+}
+
+// notificationsStore writes forwarding changes made over IPC to daemon.toml
+type notificationsStore struct{}
+
+func (notificationsStore) SaveTarget(urgency notifications.Urgency, target notifications.ForwardTarget) error {
+	return saveDaemonOptions([]daemonOptionValue{
+		{notificationKey(urgency, "file"), target.File},
+		{notificationKey(urgency, "terminal"), strconv.FormatBool(target.Terminal)},
+		{notificationKey(urgency, "bell"), strconv.FormatBool(target.Bell)},
+	})
+}
+
+type daemonOptionValue struct {
+	key, value string
+}
+
+// saveDaemonOptions writes values changed over IPC to daemon.toml, only
+// touching the options that differ, and keeps the running config in step
+// so a later config.reload does not see them as edits
+func saveDaemonOptions(values []daemonOptionValue) error {
+	daemonConfigMutex.Lock()
+	defer daemonConfigMutex.Unlock()
+
+	cfg := daemonConfig
+	if cfg == nil {
+		return fmt.Errorf("daemon config not loaded")
+	}
+	for _, v := range values {
+		opt, ok := daemonconfig.Lookup(v.key)
+		if !ok {
+			return fmt.Errorf("unknown option %s", v.key)
+		}
+		if opt.Format(cfg.Get(v.key)) == v.value {
+			continue
+		}
+		parsed, err := daemonconfig.Set(cfg.Path, v.key, v.value)
+		if err != nil {
+			return err
+		}
+		cfg = cfg.With(v.key, parsed)
+	}
+	daemonConfig = cfg
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/AvengeMedia/danklinux/internal/utils"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -249,3 +250,24 @@ func (m *Manager) Close() {
 		m.sessionConn.Close()
 	}
 }
+
+// HealthCheck reports whether the bus connections are still usable and,
+// when accounts were found at startup, whether AccountsService still answers
+func (m *Manager) HealthCheck() error {
+	if err := utils.PingDBus(m.systemConn, "org.freedesktop.DBus"); err != nil {
+		return fmt.Errorf("system bus: %w", err)
+	}
+	if m.sessionConn != nil {
+		if err := utils.PingDBus(m.sessionConn, "org.freedesktop.DBus"); err != nil {
+			return fmt.Errorf("session bus: %w", err)
+		}
+	}
+
+	m.stateMutex.RLock()
+	accounts := m.state.Accounts.Available
+	m.stateMutex.RUnlock()
+	if accounts {
+		return utils.PingDBus(m.systemConn, dbusAccountsDest)
+	}
+	return nil
+}
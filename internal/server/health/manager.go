@@ -0,0 +1,311 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	defaultInterval   = 30 * time.Second
+	defaultMinBackoff = 2 * time.Second
+	defaultMaxBackoff = 5 * time.Minute
+)
+
+// NewManager starts the watchdog. onRecover is called after a backend was
+// restarted successfully.
+func NewManager(onRecover func(name string)) *Manager {
+	m := newManager(defaultInterval, time.Now)
+	m.onRecover = onRecover
+
+	go m.loop()
+
+	return m
+}
+
+func newManager(interval time.Duration, now func() time.Time) *Manager {
+	return &Manager{
+		interval:    interval,
+		minBackoff:  defaultMinBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		now:         now,
+		subscribers: make(map[string]chan State),
+		wakeChan:    make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Register adds a backend. Its first check runs right away.
+func (m *Manager) Register(b Backend) {
+	m.mutex.Lock()
+	m.backends = append(m.backends, &watched{
+		backend:   b,
+		health:    BackendHealth{Name: b.Name, Status: StatusStopped},
+		nextCheck: m.now(),
+	})
+	m.mutex.Unlock()
+
+	m.wake()
+}
+
+// SetInterval changes how often healthy backends are checked
+func (m *Manager) SetInterval(interval time.Duration) error {
+	if interval < time.Second {
+		return fmt.Errorf("check interval too short: %s", interval)
+	}
+
+	m.mutex.Lock()
+	m.interval = interval
+	now := m.now()
+	for _, w := range m.backends {
+		if w.nextCheck.Sub(now) > interval {
+			w.nextCheck = now.Add(interval)
+		}
+	}
+	m.mutex.Unlock()
+
+	m.wake()
+	return nil
+}
+
+func (m *Manager) wake() {
+	select {
+	case m.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) loop() {
+	defer crash.Capture("health.loop", nil)
+
+	for {
+		m.runDue(false)
+
+		timer := time.NewTimer(m.untilNext())
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.wakeChan:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// CheckNow checks every backend immediately, retrying degraded ones without
+// waiting for their backoff, and returns the result
+func (m *Manager) CheckNow() State {
+	m.runDue(true)
+	return m.GetState()
+}
+
+// runDue checks or restarts every backend that is due. Checks can block on
+// D-Bus or the network, so they run without holding the mutex; runMutex
+// keeps the loop and CheckNow from running them at the same time.
+func (m *Manager) runDue(force bool) {
+	m.runMutex.Lock()
+	defer m.runMutex.Unlock()
+
+	m.mutex.Lock()
+	backends := append([]*watched(nil), m.backends...)
+	m.mutex.Unlock()
+
+	for _, w := range backends {
+		m.mutex.Lock()
+		now := m.now()
+		degraded := w.health.Status == StatusDegraded
+		due := force || (degraded && !now.Before(w.nextRetry)) || (!degraded && !now.Before(w.nextCheck))
+		m.mutex.Unlock()
+
+		if !due {
+			continue
+		}
+
+		if degraded {
+			m.restart(w, call(w.backend.Name, w.backend.Restart))
+		} else {
+			m.check(w, call(w.backend.Name, w.backend.Check))
+		}
+	}
+}
+
+// call runs a check or restart, turning a panic into a failure so one
+// broken backend cannot stop the watchdog
+func call(name string, fn func() error) (err error) {
+	defer crash.Capture("health."+name, func(v any) {
+		err = fmt.Errorf("panic: %v", v)
+	})
+	return fn()
+}
+
+func (m *Manager) check(w *watched, err error) {
+	m.mutex.Lock()
+	now := m.now()
+	previous := w.health.Status
+	first := w.health.LastCheck.IsZero()
+
+	w.health.LastCheck = now
+	w.nextCheck = now.Add(m.interval)
+
+	switch {
+	case err == nil:
+		w.health.Status = StatusOK
+		w.health.Error = ""
+		w.health.Failures = 0
+	case errors.Is(err, ErrNotRunning):
+		w.health.Status = StatusStopped
+		w.health.Error = ""
+		w.health.Failures = 0
+	default:
+		w.health.Status = StatusDegraded
+		w.health.Error = err.Error()
+		w.health.Failures = 1
+		w.nextRetry = now.Add(m.backoff(1))
+		log.Warnf("Health check failed for %s, reinitializing: %v", w.backend.Name, err)
+	}
+	degraded := w.health.Status == StatusDegraded
+	m.mutex.Unlock()
+
+	// The first check only establishes the starting state
+	if !first || degraded {
+		m.changed(w, previous)
+	}
+}
+
+func (m *Manager) restart(w *watched, err error) {
+	m.mutex.Lock()
+	now := m.now()
+	previous := w.health.Status
+
+	w.health.LastCheck = now
+	w.nextCheck = now.Add(m.interval)
+
+	switch {
+	case err == nil:
+		w.health.Status = StatusOK
+		w.health.Error = ""
+		w.health.Failures = 0
+		log.Infof("%s recovered", w.backend.Name)
+	case errors.Is(err, ErrNotRunning):
+		w.health.Status = StatusStopped
+		w.health.Error = ""
+		w.health.Failures = 0
+	default:
+		w.health.Failures++
+		w.health.Error = err.Error()
+		w.nextRetry = now.Add(m.backoff(w.health.Failures))
+		log.Debugf("Reinitializing %s failed (attempt %d, next in %s): %v", w.backend.Name, w.health.Failures, w.nextRetry.Sub(now), err)
+	}
+	m.mutex.Unlock()
+
+	if err == nil && m.onRecover != nil {
+		m.onRecover(w.backend.Name)
+	}
+	m.changed(w, previous)
+}
+
+// backoff doubles the retry delay after every failed attempt
+func (m *Manager) backoff(failures int) time.Duration {
+	delay := m.minBackoff
+	for i := 1; i < failures && delay < m.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, m.maxBackoff)
+}
+
+func (m *Manager) untilNext() time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	wait := m.interval
+	for _, w := range m.backends {
+		next := w.nextCheck
+		if w.health.Status == StatusDegraded {
+			next = w.nextRetry
+		}
+		wait = min(wait, next.Sub(now))
+	}
+	return max(wait, 0)
+}
+
+func (m *Manager) snapshot(w *watched) BackendHealth {
+	h := w.health
+	if h.Status == StatusDegraded {
+		retry := w.nextRetry
+		h.NextRetry = &retry
+	}
+	return h
+}
+
+func (m *Manager) GetState() State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := State{Backends: make([]BackendHealth, 0, len(m.backends))}
+	for _, w := range m.backends {
+		h := m.snapshot(w)
+		if h.Status == StatusDegraded {
+			state.Degraded = true
+		}
+		state.Backends = append(state.Backends, h)
+	}
+	return state
+}
+
+// changed notifies subscribers of a status change. Repeated failures of a
+// degraded backend are only reported once.
+func (m *Manager) changed(w *watched, previous Status) {
+	m.mutex.Lock()
+	current := m.snapshot(w)
+	m.mutex.Unlock()
+
+	if current.Status == previous {
+		return
+	}
+
+	state := m.GetState()
+	state.Changed = current.Name
+
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) Close() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,159 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+type fakeBackend struct {
+	checkErr   error
+	restartErr error
+	checks     int
+	restarts   int
+}
+
+func (f *fakeBackend) backend(name string) Backend {
+	return Backend{
+		Name: name,
+		Check: func() error {
+			f.checks++
+			return f.checkErr
+		},
+		Restart: func() error {
+			f.restarts++
+			return f.restartErr
+		},
+	}
+}
+
+func newTestManager() (*Manager, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	return newManager(30*time.Second, clock.now), clock
+}
+
+func TestManager_HealthyBackend(t *testing.T) {
+	m, clock := newTestManager()
+	fake := &fakeBackend{}
+	m.Register(fake.backend("cups"))
+
+	m.runDue(false)
+	assert.Equal(t, 1, fake.checks)
+	assert.Equal(t, StatusOK, m.GetState().Backends[0].Status)
+
+	clock.advance(10 * time.Second)
+	m.runDue(false)
+	assert.Equal(t, 1, fake.checks, "not due yet")
+
+	clock.advance(20 * time.Second)
+	m.runDue(false)
+	assert.Equal(t, 2, fake.checks)
+	assert.Equal(t, 0, fake.restarts)
+}
+
+func TestManager_RestartWithBackoff(t *testing.T) {
+	m, clock := newTestManager()
+	fake := &fakeBackend{}
+	m.Register(fake.backend("bluetooth"))
+	m.runDue(false)
+
+	states := m.Subscribe("test")
+
+	fake.checkErr = errors.New("org.bluez not responding")
+	fake.restartErr = errors.New("bluez manager: no adapter")
+	clock.advance(30 * time.Second)
+	m.runDue(false)
+
+	state := <-states
+	require.True(t, state.Degraded)
+	assert.Equal(t, "bluetooth", state.Changed)
+	health := state.Backends[0]
+	assert.Equal(t, StatusDegraded, health.Status)
+	assert.Equal(t, "org.bluez not responding", health.Error)
+	require.NotNil(t, health.NextRetry)
+	assert.Equal(t, clock.t.Add(2*time.Second), *health.NextRetry)
+
+	// Failed restarts double the delay and do not spam subscribers
+	for _, wait := range []time.Duration{2, 4, 8} {
+		clock.advance(wait * time.Second)
+		m.runDue(false)
+	}
+	assert.Equal(t, 3, fake.restarts)
+	assert.Equal(t, 4, m.GetState().Backends[0].Failures)
+	assert.Equal(t, clock.t.Add(16*time.Second), *m.GetState().Backends[0].NextRetry)
+	assert.Len(t, states, 0)
+
+	fake.restartErr = nil
+	clock.advance(16 * time.Second)
+	m.runDue(false)
+
+	state = <-states
+	assert.False(t, state.Degraded)
+	assert.Equal(t, StatusOK, state.Backends[0].Status)
+	assert.Zero(t, state.Backends[0].Failures)
+	assert.Nil(t, state.Backends[0].NextRetry)
+}
+
+func TestManager_OnRecover(t *testing.T) {
+	m, _ := newTestManager()
+	var recovered []string
+	m.onRecover = func(name string) { recovered = append(recovered, name) }
+
+	fake := &fakeBackend{checkErr: errors.New("gone")}
+	m.Register(fake.backend("power"))
+	m.runDue(false)
+	assert.True(t, m.GetState().Degraded)
+
+	state := m.CheckNow()
+	assert.False(t, state.Degraded)
+	assert.Equal(t, []string{"power"}, recovered)
+}
+
+func TestManager_NotRunning(t *testing.T) {
+	m, _ := newTestManager()
+	fake := &fakeBackend{checkErr: ErrNotRunning}
+	m.Register(fake.backend("cups"))
+
+	m.runDue(true)
+	assert.Equal(t, StatusStopped, m.GetState().Backends[0].Status)
+	assert.False(t, m.GetState().Degraded)
+	assert.Zero(t, fake.restarts)
+}
+
+func TestManager_PanickingCheck(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	m, _ := newTestManager()
+	m.Register(Backend{
+		Name:    "brightness",
+		Check:   func() error { panic("i2c exploded") },
+		Restart: func() error { return nil },
+	})
+
+	m.runDue(false)
+	health := m.GetState().Backends[0]
+	assert.Equal(t, StatusDegraded, health.Status)
+	assert.Contains(t, health.Error, "i2c exploded")
+}
+
+func TestManager_Backoff(t *testing.T) {
+	m, _ := newTestManager()
+	assert.Equal(t, 2*time.Second, m.backoff(1))
+	assert.Equal(t, 4*time.Second, m.backoff(2))
+	assert.Equal(t, 5*time.Minute, m.backoff(20))
+}
+
+func TestManager_SetInterval(t *testing.T) {
+	m, _ := newTestManager()
+	assert.Error(t, m.SetInterval(time.Millisecond))
+	require.NoError(t, m.SetInterval(5*time.Second))
+	assert.Equal(t, 5*time.Second, m.untilNext())
+}
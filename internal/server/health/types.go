@@ -0,0 +1,83 @@
+package health
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusOK Status = "ok"
+	// StatusDegraded means the last check failed and the backend is being
+	// reinitialized with backoff. Its last known state is still served.
+	StatusDegraded Status = "degraded"
+	// StatusStopped means the backend is not running, e.g. CUPS without
+	// subscribers, so nothing is checked.
+	StatusStopped Status = "stopped"
+)
+
+// ErrNotRunning is returned by a check or restart when the backend is not
+// supposed to be running right now
+var ErrNotRunning = errors.New("not running")
+
+// Backend is a service the watchdog looks after
+type Backend struct {
+	Name string
+	// Check returns an error when the backend no longer works
+	Check func() error
+	// Restart replaces the backend with a fresh instance
+	Restart func() error
+}
+
+type BackendHealth struct {
+	Name      string     `json:"name"`
+	Status    Status     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	Failures  int        `json:"failures"`
+	LastCheck time.Time  `json:"lastCheck"`
+	NextRetry *time.Time `json:"nextRetry,omitempty"`
+}
+
+type State struct {
+	Degraded bool            `json:"degraded"`
+	Backends []BackendHealth `json:"backends"`
+	// Changed names the backend whose status change caused this update; it
+	// is empty in snapshots
+	Changed string `json:"changed,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type watched struct {
+	backend   Backend
+	health    BackendHealth
+	nextCheck time.Time
+	nextRetry time.Time
+}
+
+type Manager struct {
+	interval   time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+
+	mutex    sync.Mutex
+	backends []*watched
+	runMutex sync.Mutex
+
+	// onRecover runs after a successful restart
+	onRecover func(name string)
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+
+	wakeChan chan struct{}
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/jobs"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/AvengeMedia/danklinux/internal/server/termcolors"
+)
+
+// backgroundMethods are the methods that run as a job when called with
+// "async": true. Each reads its params up front, so a bad request
+// fails at once instead of as a failed job.
+var backgroundMethods = map[string]func(params map[string]interface{}) (jobs.Func, error){
+	"network.speedTest": func(map[string]interface{}) (jobs.Func, error) {
+		nm := networkManager.Load()
+		if nm == nil {
+			return nil, fmt.Errorf("network manager not initialized")
+		}
+		return func(ctx context.Context, report jobs.Report) (any, error) {
+			return nm.RunSpeedTest(ctx, report)
+		}, nil
+	},
+	"network.tether.connect": func(params map[string]interface{}) (jobs.Func, error) {
+		nm := networkManager.Load()
+		if nm == nil {
+			return nil, fmt.Errorf("network manager not initialized")
+		}
+		id, ok := params["id"].(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("missing or invalid 'id' parameter")
+		}
+		return func(ctx context.Context, report jobs.Report) (any, error) {
+			report(0, "connecting "+id)
+			if err := nm.ConnectTether(id); err != nil {
+				return nil, err
+			}
+			return network.SuccessResult{Success: true, Message: "connected"}, nil
+		}, nil
+	},
+	// The CUPS calls cannot be interrupted; a cancelled job drops their
+	// result once they return
+	"cups.getDevices": func(map[string]interface{}) (jobs.Func, error) {
+		cm := cupsManager.Load()
+		if cm == nil {
+			return nil, fmt.Errorf("CUPS manager not initialized")
+		}
+		return func(ctx context.Context, report jobs.Report) (any, error) {
+			report(0, "discovering printers")
+			return cm.GetDevices()
+		}, nil
+	},
+	"cups.autoAdd": func(params map[string]interface{}) (jobs.Func, error) {
+		cm := cupsManager.Load()
+		if cm == nil {
+			return nil, fmt.Errorf("CUPS manager not initialized")
+		}
+		uri, ok := params["uri"].(string)
+		if !ok || uri == "" {
+			return nil, fmt.Errorf("missing or invalid 'uri' parameter")
+		}
+		name, _ := params["name"].(string)
+		return func(ctx context.Context, report jobs.Report) (any, error) {
+			report(0, "adding "+uri)
+			return cm.AutoAdd(uri, name)
+		}, nil
+	},
+	"termcolors.apply": func(params map[string]interface{}) (jobs.Func, error) {
+		if termcolorsManager == nil {
+			return nil, fmt.Errorf("termcolors manager not initialized")
+		}
+		palette, err := termcolors.PaletteFromParams(params)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, report jobs.Report) (any, error) {
+			return termcolorsManager.Broadcast(palette)
+		}, nil
+	},
+}
+
+// startBackgroundJob answers a request made with "async": true with
+// the job running it. Progress and the result follow on jobs.subscribe.
+func startBackgroundJob(conn net.Conn, req models.Request) {
+	if jobsManager == nil {
+		models.RespondError(conn, req.ID, "jobs manager not initialized")
+		return
+	}
+	build, ok := backgroundMethods[req.Method]
+	if !ok {
+		models.RespondError(conn, req.ID, fmt.Sprintf("%s cannot run in the background", req.Method))
+		return
+	}
+	fn, err := build(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, jobsManager.Start(req.Method, fn))
+}
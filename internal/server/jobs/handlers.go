@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "jobs.list":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "jobs.get":
+		handleGet(conn, req, manager)
+	case "jobs.cancel":
+		handleCancel(conn, req, manager)
+	case "jobs.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGet(conn net.Conn, req Request, manager *Manager) {
+	id, ok := req.Params["id"].(string)
+	if !ok || id == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+
+	job, ok := manager.Get(id)
+	if !ok {
+		models.RespondError(conn, req.ID, fmt.Sprintf("no job %s", id))
+		return
+	}
+	models.Respond(conn, req.ID, job)
+}
+
+func handleCancel(conn net.Conn, req Request, manager *Manager) {
+	id, ok := req.Params["id"].(string)
+	if !ok || id == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+
+	job, err := manager.Cancel(id)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, job)
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
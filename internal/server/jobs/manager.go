@@ -0,0 +1,260 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+)
+
+func NewManager() *Manager {
+	return newManager(maxRunning)
+}
+
+func newManager(maxRunning int) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		ctx:         ctx,
+		cancel:      cancel,
+		maxRunning:  maxRunning,
+		jobs:        make(map[string]*entry),
+		subscribers: make(map[string]chan State),
+	}
+}
+
+// stateLocked must be called with the mutex held
+func (m *Manager) stateLocked() State {
+	state := State{Jobs: make([]Job, 0, len(m.order))}
+	for _, id := range m.order {
+		state.Jobs = append(state.Jobs, m.jobs[id].job)
+	}
+	return state
+}
+
+func (m *Manager) GetState() State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stateLocked()
+}
+
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// Start queues fn as a job for method and returns it without waiting.
+// Jobs start in the order they were queued.
+func (m *Manager) Start(method string, fn Func) Job {
+	ctx, cancel := context.WithCancel(m.ctx)
+
+	m.mutex.Lock()
+	m.nextID++
+	e := &entry{
+		job: Job{
+			ID:        fmt.Sprintf("job-%d", m.nextID),
+			Method:    method,
+			Status:    StatusQueued,
+			CreatedAt: time.Now(),
+		},
+		fn:     fn,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	m.jobs[e.job.ID] = e
+	m.order = append(m.order, e.job.ID)
+	m.queue = append(m.queue, e.job.ID)
+	m.startQueuedLocked()
+	job := e.job
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	return job
+}
+
+// startQueuedLocked starts queued jobs while there are free slots. It must
+// be called with the mutex held.
+func (m *Manager) startQueuedLocked() {
+	for m.running < m.maxRunning && len(m.queue) > 0 {
+		e := m.jobs[m.queue[0]]
+		m.queue = m.queue[1:]
+
+		now := time.Now()
+		e.job.Status = StatusRunning
+		e.job.StartedAt = &now
+		m.running++
+		go m.run(e)
+	}
+}
+
+func (m *Manager) run(e *entry) {
+	id := e.job.ID
+
+	var result any
+	var err error
+	func() {
+		defer crash.Capture("job "+e.job.Method, func(any) {
+			err = fmt.Errorf("internal error")
+		})
+		result, err = e.fn(e.ctx, func(progress float64, message string) {
+			progress = min(max(progress, 0), 1)
+			m.update(id, func(job *Job) {
+				if job.Status == StatusRunning {
+					job.Progress = progress
+					job.Message = message
+				}
+			})
+		})
+	}()
+
+	switch {
+	case e.ctx.Err() != nil:
+		m.finish(id, StatusCancelled, nil, "")
+	case err != nil:
+		m.finish(id, StatusFailed, nil, err.Error())
+	default:
+		m.finish(id, StatusSucceeded, result, "")
+	}
+}
+
+func (m *Manager) update(id string, change func(job *Job)) {
+	m.mutex.Lock()
+	e, ok := m.jobs[id]
+	if !ok {
+		m.mutex.Unlock()
+		return
+	}
+	change(&e.job)
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+}
+
+// finish ends a running job and starts the next queued one
+func (m *Manager) finish(id, status string, result any, errMsg string) {
+	m.mutex.Lock()
+	m.running--
+	e := m.jobs[id]
+	m.finishLocked(e, status, result, errMsg)
+	m.startQueuedLocked()
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+}
+
+// finishLocked must be called with the mutex held
+func (m *Manager) finishLocked(e *entry, status string, result any, errMsg string) {
+	now := time.Now()
+	e.job.Status = status
+	e.job.Result = result
+	e.job.Error = errMsg
+	e.job.FinishedAt = &now
+	if status == StatusSucceeded {
+		e.job.Progress = 1
+	}
+	e.cancel()
+	m.pruneLocked()
+}
+
+// pruneLocked drops the oldest finished jobs past keepFinished. It must be
+// called with the mutex held.
+func (m *Manager) pruneLocked() {
+	finished := 0
+	for _, id := range m.order {
+		if m.jobs[id].job.Done() {
+			finished++
+		}
+	}
+
+	kept := m.order[:0]
+	for _, id := range m.order {
+		if finished > keepFinished && m.jobs[id].job.Done() {
+			delete(m.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
+
+// Cancel drops a queued job, or asks a running one to stop. A running job
+// is marked cancelled once its work returns.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mutex.Lock()
+	e, ok := m.jobs[id]
+	if !ok {
+		m.mutex.Unlock()
+		return Job{}, fmt.Errorf("no job %s", id)
+	}
+	if e.job.Done() {
+		m.mutex.Unlock()
+		return Job{}, fmt.Errorf("job %s already %s", id, e.job.Status)
+	}
+
+	e.cancel()
+	if e.job.Status == StatusQueued {
+		for i, queued := range m.queue {
+			if queued == id {
+				m.queue = append(m.queue[:i], m.queue[i+1:]...)
+				break
+			}
+		}
+		m.finishLocked(e, StatusCancelled, nil, "")
+	}
+	job := e.job
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	return job, nil
+}
+
+func (m *Manager) broadcast(state State) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+// Close cancels every job and ends the subscriptions
+func (m *Manager) Close() {
+	m.cancel()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
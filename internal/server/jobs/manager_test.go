@@ -0,0 +1,169 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitFor waits until the job reaches status
+func waitFor(t *testing.T, m *Manager, id, status string) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		job, _ = m.Get(id)
+		return job.Status == status
+	}, time.Second, 5*time.Millisecond, "job %s never became %s", id, status)
+	return job
+}
+
+func TestJobSucceeds(t *testing.T) {
+	m := newManager(1)
+	defer m.Close()
+
+	release := make(chan struct{})
+	job := m.Start("network.speedTest", func(ctx context.Context, report Report) (any, error) {
+		report(0.5, "download")
+		<-release
+		return 42, nil
+	})
+	assert.Equal(t, "job-1", job.ID)
+	assert.Equal(t, "network.speedTest", job.Method)
+
+	require.Eventually(t, func() bool {
+		job, _ := m.Get("job-1")
+		return job.Progress == 0.5
+	}, time.Second, 5*time.Millisecond)
+	running, _ := m.Get("job-1")
+	assert.Equal(t, StatusRunning, running.Status)
+	assert.Equal(t, "download", running.Message)
+	assert.NotNil(t, running.StartedAt)
+
+	close(release)
+	done := waitFor(t, m, "job-1", StatusSucceeded)
+	assert.Equal(t, 42, done.Result)
+	assert.Equal(t, 1.0, done.Progress)
+	assert.NotNil(t, done.FinishedAt)
+}
+
+func TestJobFails(t *testing.T) {
+	m := newManager(1)
+	defer m.Close()
+
+	job := m.Start("cups.getDevices", func(context.Context, Report) (any, error) {
+		return nil, errors.New("no cupsd")
+	})
+	done := waitFor(t, m, job.ID, StatusFailed)
+	assert.Equal(t, "no cupsd", done.Error)
+	assert.Nil(t, done.Result)
+}
+
+func TestJobsQueueBeyondMaxRunning(t *testing.T) {
+	m := newManager(1)
+	defer m.Close()
+
+	release := make(chan struct{})
+	block := func(ctx context.Context, report Report) (any, error) {
+		<-release
+		return nil, nil
+	}
+	first := m.Start("a", block)
+	second := m.Start("b", block)
+	assert.Equal(t, StatusRunning, first.Status)
+	assert.Equal(t, StatusQueued, second.Status)
+
+	close(release)
+	waitFor(t, m, first.ID, StatusSucceeded)
+	waitFor(t, m, second.ID, StatusSucceeded)
+}
+
+func TestCancel(t *testing.T) {
+	m := newManager(1)
+	defer m.Close()
+
+	running := m.Start("a", func(ctx context.Context, report Report) (any, error) {
+		<-ctx.Done()
+		return "late", nil
+	})
+	queued := m.Start("b", func(context.Context, Report) (any, error) {
+		t.Error("a cancelled queued job must not run")
+		return nil, nil
+	})
+	waitFor(t, m, running.ID, StatusRunning)
+
+	// Queued jobs are dropped at once
+	job, err := m.Cancel(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, job.Status)
+
+	_, err = m.Cancel(running.ID)
+	require.NoError(t, err)
+	done := waitFor(t, m, running.ID, StatusCancelled)
+	assert.Nil(t, done.Result)
+
+	_, err = m.Cancel(running.ID)
+	assert.Error(t, err)
+	_, err = m.Cancel("job-99")
+	assert.Error(t, err)
+}
+
+func TestFinishedJobsArePruned(t *testing.T) {
+	m := newManager(4)
+	defer m.Close()
+
+	release := make(chan struct{})
+	pending := m.Start("slow", func(context.Context, Report) (any, error) {
+		<-release
+		return nil, nil
+	})
+	var last Job
+	for i := 0; i < keepFinished+5; i++ {
+		last = m.Start("quick", func(context.Context, Report) (any, error) { return i, nil })
+		waitFor(t, m, last.ID, StatusSucceeded)
+	}
+
+	state := m.GetState()
+	assert.Len(t, state.Jobs, keepFinished+1)
+	// The oldest job is still running, so it is kept
+	assert.Equal(t, pending.ID, state.Jobs[0].ID)
+	assert.Equal(t, last.ID, state.Jobs[len(state.Jobs)-1].ID)
+	_, ok := m.Get("job-2")
+	assert.False(t, ok)
+
+	close(release)
+}
+
+func TestSubscribeGetsProgress(t *testing.T) {
+	m := newManager(1)
+	ch := m.Subscribe("test")
+
+	m.Start("a", func(ctx context.Context, report Report) (any, error) {
+		report(2, "clamped")
+		return nil, nil
+	})
+
+	var statuses []string
+	for state := range ch {
+		require.Len(t, state.Jobs, 1)
+		job := state.Jobs[0]
+		if job.Message == "clamped" && job.Status == StatusRunning {
+			assert.Equal(t, 1.0, job.Progress)
+		}
+		if len(statuses) == 0 || statuses[len(statuses)-1] != job.Status {
+			statuses = append(statuses, job.Status)
+		}
+		if job.Done() {
+			break
+		}
+	}
+	// A free slot starts the job as it is queued
+	assert.Equal(t, []string{StatusRunning, StatusSucceeded}, statuses)
+
+	m.Close()
+	_, open := <-ch
+	assert.False(t, open)
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Statuses of a job. Queued jobs wait for a free slot; the last three are
+// final.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// maxRunning is how many jobs run at once; the rest wait queued
+const maxRunning = 2
+
+// keepFinished is how many finished jobs are kept for clients that ask
+// for the result after the job is done
+const keepFinished = 32
+
+// Job is one background run of an IPC method. Progress goes from 0 to 1
+// and stays at 0 for work that cannot tell how far along it is; Message
+// names the current step. Result is the method's usual response once the
+// job succeeded.
+type Job struct {
+	ID         string     `json:"id"`
+	Method     string     `json:"method"`
+	Status     string     `json:"status"`
+	Progress   float64    `json:"progress"`
+	Message    string     `json:"message,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Done tells whether the job reached a final status
+func (j Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+// State lists the running and recent jobs, oldest first
+type State struct {
+	Jobs []Job `json:"jobs"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// Report sets a running job's progress, from 0 to 1, and current step
+type Report func(progress float64, message string)
+
+// Func is the work of a job. It should return soon after ctx is cancelled;
+// whatever it returns then is dropped.
+type Func func(ctx context.Context, report Report) (any, error)
+
+type entry struct {
+	job    Job
+	fn     Func
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type Manager struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	maxRunning int
+
+	mutex   sync.Mutex
+	jobs    map[string]*entry
+	order   []string
+	queue   []string
+	running int
+	nextID  uint64
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+}
@@ -0,0 +1,252 @@
+package launcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// desktopEntry holds the [Desktop Entry] group with locale suffixes kept in
+// the keys, e.g. "Name[de]".
+type desktopEntry map[string]string
+
+func parseDesktopEntry(r io.Reader) (desktopEntry, error) {
+	entry := make(desktopEntry)
+	inGroup := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == "[Desktop Entry]"
+			continue
+		}
+		if !inGroup {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		entry[strings.TrimSpace(key)] = unescapeValue(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func unescapeValue(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	r := strings.NewReplacer(`\s`, " ", `\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`)
+	return r.Replace(v)
+}
+
+// localized picks key[lang_COUNTRY], key[lang] or key, following LC_MESSAGES
+// and LANG.
+func (e desktopEntry) localized(key string) string {
+	for _, locale := range localeCandidates() {
+		if v, ok := e[key+"["+locale+"]"]; ok && v != "" {
+			return v
+		}
+	}
+	return e[key]
+}
+
+func localeCandidates() []string {
+	locale := os.Getenv("LC_MESSAGES")
+	if locale == "" {
+		locale = os.Getenv("LANG")
+	}
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+
+	candidates := []string{locale}
+	if lang, _, ok := strings.Cut(locale, "_"); ok {
+		candidates = append(candidates, lang)
+	}
+	return candidates
+}
+
+func (e desktopEntry) bool(key string) bool {
+	return e[key] == "true"
+}
+
+func (e desktopEntry) list(key string) []string {
+	return splitList(e[key])
+}
+
+// visible applies Type, NoDisplay, Hidden, OnlyShowIn/NotShowIn and TryExec
+func (e desktopEntry) visible(desktops []string) bool {
+	if e["Type"] != "Application" || e.bool("NoDisplay") || e.bool("Hidden") || e["Exec"] == "" {
+		return false
+	}
+
+	if only := e.list("OnlyShowIn"); len(only) > 0 && !intersects(only, desktops) {
+		return false
+	}
+	if intersects(e.list("NotShowIn"), desktops) {
+		return false
+	}
+
+	if tryExec := e["TryExec"]; tryExec != "" {
+		if _, err := exec.LookPath(tryExec); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func currentDesktops() []string {
+	return strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":")
+}
+
+func loadApp(path, id string, desktops []string) (App, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return App{}, false
+	}
+	defer f.Close()
+
+	entry, err := parseDesktopEntry(f)
+	if err != nil || !entry.visible(desktops) {
+		return App{}, false
+	}
+
+	return App{
+		ID:          id,
+		Name:        entry.localized("Name"),
+		GenericName: entry.localized("GenericName"),
+		Comment:     entry.localized("Comment"),
+		Icon:        entry["Icon"],
+		Exec:        entry["Exec"],
+		Path:        entry["Path"],
+		Terminal:    entry.bool("Terminal"),
+		Keywords:    splitList(entry.localized("Keywords")),
+		Categories:  entry.list("Categories"),
+		File:        path,
+	}, true
+}
+
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// desktopFileID turns applications/kde/foo.desktop into kde-foo.desktop
+func desktopFileID(appDir, path string) string {
+	rel, err := filepath.Rel(appDir, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+}
+
+// buildCommand splits Exec into argv and expands field codes. File and URL
+// codes are dropped since the launcher never passes documents.
+func buildCommand(app App) ([]string, error) {
+	args, err := splitExec(app.Exec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Exec in %s: %w", app.ID, err)
+	}
+
+	var argv []string
+	for _, arg := range args {
+		switch arg {
+		case "%f", "%F", "%u", "%U", "%d", "%D", "%n", "%N", "%v", "%m":
+			continue
+		case "%i":
+			if app.Icon != "" {
+				argv = append(argv, "--icon", app.Icon)
+			}
+			continue
+		}
+
+		var b strings.Builder
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '%' || i+1 >= len(arg) {
+				b.WriteByte(arg[i])
+				continue
+			}
+			i++
+			switch arg[i] {
+			case '%':
+				b.WriteByte('%')
+			case 'c':
+				b.WriteString(app.Name)
+			case 'k':
+				b.WriteString(app.File)
+			}
+		}
+		argv = append(argv, b.String())
+	}
+
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("empty Exec in %s", app.ID)
+	}
+	return argv, nil
+}
+
+// splitExec tokenizes an Exec value. Arguments may be double-quoted, in which
+// case \", \`, \$ and \\ are escapes.
+func splitExec(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inQuotes, hasToken := false, false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case !inQuotes && (c == ' ' || c == '\t'):
+			if hasToken {
+				args = append(args, cur.String())
+				cur.Reset()
+				hasToken = false
+			}
+		default:
+			cur.WriteByte(c)
+			hasToken = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if hasToken {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
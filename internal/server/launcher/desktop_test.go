@@ -0,0 +1,66 @@
+package launcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDesktopEntry(t *testing.T) {
+	t.Setenv("LANG", "de_DE.UTF-8")
+	t.Setenv("LC_MESSAGES", "")
+
+	entry, err := parseDesktopEntry(strings.NewReader(`# comment
+[Desktop Entry]
+Type=Application
+Name=Files
+Name[de]=Dateien
+Keywords=folder;manager;
+Exec=nautilus --new-window %U
+
+[Desktop Action new-window]
+Name=New Window
+Exec=nautilus --other
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Dateien", entry.localized("Name"))
+	assert.Equal(t, "nautilus --new-window %U", entry["Exec"], "action groups must not override the main entry")
+	assert.Equal(t, []string{"folder", "manager"}, entry.list("Keywords"))
+	assert.True(t, entry.visible(nil))
+}
+
+func TestDesktopEntryVisible(t *testing.T) {
+	base := desktopEntry{"Type": "Application", "Exec": "foo"}
+
+	hidden := desktopEntry{"Type": "Application", "Exec": "foo", "NoDisplay": "true"}
+	assert.False(t, hidden.visible(nil))
+
+	onlyKDE := desktopEntry{"Type": "Application", "Exec": "foo", "OnlyShowIn": "KDE;"}
+	assert.False(t, onlyKDE.visible([]string{"niri"}))
+	assert.True(t, onlyKDE.visible([]string{"KDE"}))
+
+	notNiri := desktopEntry{"Type": "Application", "Exec": "foo", "NotShowIn": "niri;"}
+	assert.False(t, notNiri.visible([]string{"niri"}))
+
+	link := desktopEntry{"Type": "Link", "URL": "https://example.com"}
+	assert.False(t, link.visible(nil))
+
+	assert.True(t, base.visible(nil))
+}
+
+func TestBuildCommand(t *testing.T) {
+	argv, err := buildCommand(App{
+		ID:   "foo.desktop",
+		Name: "Foo",
+		Icon: "foo",
+		Exec: `sh -c "echo \"100%%\"" %U %i --class=%c`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", `echo "100%"`, "--icon", "foo", "--class=Foo"}, argv)
+
+	_, err = buildCommand(App{ID: "bad.desktop", Exec: `foo "unterminated`})
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package launcher
+
+import "strings"
+
+// Field weights applied to the best match in each field
+const (
+	weightName     = 1.0
+	weightKeywords = 0.6
+	weightGeneric  = 0.5
+	weightExec     = 0.4
+	weightComment  = 0.2
+)
+
+// matchScore rates how well query matches text, from 0 (no match) to 100.
+// Exact and prefix matches beat word-prefix matches, which beat substrings,
+// which beat scattered subsequences.
+func matchScore(query, text string) float64 {
+	if query == "" || text == "" {
+		return 0
+	}
+	q := strings.ToLower(query)
+	t := strings.ToLower(text)
+
+	switch {
+	case t == q:
+		return 100
+	case strings.HasPrefix(t, q):
+		return 90 - lengthPenalty(t, q)
+	case wordPrefix(t, q):
+		return 80 - lengthPenalty(t, q)
+	case strings.Contains(t, q):
+		return 60 - lengthPenalty(t, q)
+	}
+	return subsequenceScore(q, t)
+}
+
+// lengthPenalty favours shorter texts for the same kind of match
+func lengthPenalty(text, query string) float64 {
+	extra := len(text) - len(query)
+	if extra > 20 {
+		extra = 20
+	}
+	return float64(extra) / 2
+}
+
+func wordPrefix(text, query string) bool {
+	for i := 1; i < len(text); i++ {
+		if isBoundary(rune(text[i-1])) && strings.HasPrefix(text[i:], query) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBoundary(r rune) bool {
+	return r == ' ' || r == '-' || r == '_' || r == '.' || r == '/'
+}
+
+// subsequenceScore matches query characters in order, rewarding runs and
+// word starts. Capped below substring matches.
+func subsequenceScore(query, text string) float64 {
+	qr := []rune(query)
+	tr := []rune(text)
+
+	score := 0.0
+	run := 0
+	qi := 0
+	for ti := 0; ti < len(tr) && qi < len(qr); ti++ {
+		if tr[ti] != qr[qi] {
+			run = 0
+			continue
+		}
+		points := 1.0
+		if ti == 0 || isBoundary(tr[ti-1]) {
+			points += 2
+		}
+		if run > 0 {
+			points += float64(run)
+		}
+		score += points
+		run++
+		qi++
+	}
+	if qi < len(qr) {
+		return 0
+	}
+
+	// Normalise by the best possible score for this query length
+	best := 0.0
+	for i := range qr {
+		best += 3 + float64(i)
+	}
+	return 40 * score / best
+}
+
+// scoreApp combines field matches into a single relevance score
+func scoreApp(query string, app App) float64 {
+	best := matchScore(query, app.Name) * weightName
+	for _, kw := range app.Keywords {
+		best = max(best, matchScore(query, kw)*weightKeywords)
+	}
+	best = max(best, matchScore(query, app.GenericName)*weightGeneric)
+	if argv, err := splitExec(app.Exec); err == nil && len(argv) > 0 {
+		exe := argv[0]
+		if i := strings.LastIndexByte(exe, '/'); i >= 0 {
+			exe = exe[i+1:]
+		}
+		best = max(best, matchScore(query, exe)*weightExec)
+	}
+	best = max(best, matchScore(query, app.Comment)*weightComment)
+	return best
+}
@@ -0,0 +1,58 @@
+package launcher
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "launcher.search":
+		handleSearch(conn, req, manager)
+	case "launcher.launch":
+		handleLaunch(conn, req, manager)
+	case "launcher.frecency":
+		handleFrecency(conn, req, manager)
+	case "launcher.refresh":
+		handleRefresh(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func limitParam(req Request) int {
+	if limit, ok := req.Params["limit"].(float64); ok {
+		return int(limit)
+	}
+	return DefaultLimit
+}
+
+func handleSearch(conn net.Conn, req Request, manager *Manager) {
+	query, _ := req.Params["query"].(string)
+	models.Respond(conn, req.ID, manager.Search(query, limitParam(req)))
+}
+
+func handleLaunch(conn net.Conn, req Request, manager *Manager) {
+	id, ok := req.Params["id"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+
+	if err := manager.Launch(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "launched"})
+}
+
+func handleFrecency(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.Frecency(limitParam(req)))
+}
+
+func handleRefresh(conn net.Conn, req Request, manager *Manager) {
+	manager.refresh(true)
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "index refreshed"})
+}
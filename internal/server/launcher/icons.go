@@ -0,0 +1,192 @@
+package launcher
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// iconSizeDirs is the lookup order within a theme, largest raster first
+var iconSizeDirs = []string{"scalable", "512x512", "256x256", "128x128", "96x96", "64x64", "48x48", "32x32"}
+
+var iconExtensions = []string{".svg", ".png", ".xpm"}
+
+// iconResolver maps Icon= names to files using a simplified version of the
+// icon theme spec: the user's theme and its parents, then hicolor, then
+// pixmaps. Results are cached until reset.
+type iconResolver struct {
+	baseDirs []string
+	theme    string
+
+	mu     sync.Mutex
+	cache  map[string]string
+	themes []string
+}
+
+func newIconResolver(dataDirs []string, theme string) *iconResolver {
+	var baseDirs []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		baseDirs = append(baseDirs, filepath.Join(homeDir, ".icons"))
+	}
+	for _, dir := range dataDirs {
+		baseDirs = append(baseDirs, filepath.Join(dir, "icons"))
+	}
+
+	return &iconResolver{
+		baseDirs: baseDirs,
+		theme:    theme,
+		cache:    make(map[string]string),
+	}
+}
+
+func (r *iconResolver) reset() {
+	r.mu.Lock()
+	r.cache = make(map[string]string)
+	r.themes = nil
+	r.mu.Unlock()
+}
+
+func (r *iconResolver) resolve(icon string) string {
+	if icon == "" {
+		return ""
+	}
+	if filepath.IsAbs(icon) {
+		if _, err := os.Stat(icon); err == nil {
+			return icon
+		}
+		return ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if path, ok := r.cache[icon]; ok {
+		return path
+	}
+	path := r.lookup(icon)
+	r.cache[icon] = path
+	return path
+}
+
+func (r *iconResolver) lookup(icon string) string {
+	if r.themes == nil {
+		r.themes = r.themeChain()
+	}
+
+	for _, theme := range r.themes {
+		for _, base := range r.baseDirs {
+			themeDir := filepath.Join(base, theme)
+			for _, size := range iconSizeDirs {
+				if path := findIconFile(filepath.Join(themeDir, size, "apps"), icon); path != "" {
+					return path
+				}
+			}
+		}
+	}
+
+	for _, base := range r.baseDirs {
+		pixmaps := filepath.Join(filepath.Dir(base), "pixmaps")
+		if path := findIconFile(pixmaps, icon); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+func findIconFile(dir, icon string) string {
+	for _, ext := range iconExtensions {
+		path := filepath.Join(dir, icon+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// themeChain follows Inherits= from the configured theme, ending in hicolor
+func (r *iconResolver) themeChain() []string {
+	var chain []string
+	seen := map[string]bool{}
+
+	queue := []string{r.theme}
+	for len(queue) > 0 {
+		theme := queue[0]
+		queue = queue[1:]
+		if theme == "" || seen[theme] {
+			continue
+		}
+		seen[theme] = true
+		chain = append(chain, theme)
+		queue = append(queue, r.themeParents(theme)...)
+	}
+
+	if !seen["hicolor"] {
+		chain = append(chain, "hicolor")
+	}
+	return chain
+}
+
+func (r *iconResolver) themeParents(theme string) []string {
+	for _, base := range r.baseDirs {
+		f, err := os.Open(filepath.Join(base, theme, "index.theme"))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Inherits="); ok {
+				return splitCommaList(value)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func splitCommaList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// currentIconTheme reads gtk-icon-theme-name from the GTK settings DMS keeps
+// in sync with its icon theme option.
+func currentIconTheme() string {
+	if theme := os.Getenv("DMS_ICON_THEME"); theme != "" {
+		return theme
+	}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+
+	for _, dir := range []string{"gtk-4.0", "gtk-3.0"} {
+		f, err := os.Open(filepath.Join(configHome, dir, "settings.ini"))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), "=")
+			if ok && strings.TrimSpace(key) == "gtk-icon-theme-name" {
+				f.Close()
+				return strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+		f.Close()
+	}
+	return ""
+}
@@ -0,0 +1,349 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	// rescanInterval bounds how often directory mtimes are checked on search
+	rescanInterval = 2 * time.Second
+	DefaultLimit   = 20
+)
+
+func NewManager() (*Manager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	dataDirs := []string{dataHome}
+	xdgDataDirs := os.Getenv("XDG_DATA_DIRS")
+	if xdgDataDirs == "" {
+		xdgDataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range strings.Split(xdgDataDirs, ":") {
+		if dir != "" {
+			dataDirs = append(dataDirs, dir)
+		}
+	}
+
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+
+	m := newManager(dataDirs, filepath.Join(stateHome, "DankMaterialShell", "launcher-usage.json"), currentIconTheme())
+	m.loadUsage()
+	m.refresh(true)
+
+	log.Infof("Launcher indexed %d applications", len(m.apps))
+	return m, nil
+}
+
+func newManager(dataDirs []string, usagePath, iconTheme string) *Manager {
+	appDirs := make([]string, 0, len(dataDirs))
+	for _, dir := range dataDirs {
+		appDirs = append(appDirs, filepath.Join(dir, "applications"))
+	}
+
+	return &Manager{
+		appDirs:   appDirs,
+		icons:     newIconResolver(dataDirs, iconTheme),
+		apps:      make(map[string]App),
+		dirStamps: make(map[string]time.Time),
+		usage:     make(map[string]usageRecord),
+		usagePath: usagePath,
+		launch:    startDetached,
+	}
+}
+
+// refresh rebuilds the index when any application directory changed. Package
+// managers add and remove files directly in these directories, so their
+// mtimes are enough to notice installs.
+func (m *Manager) refresh(force bool) {
+	m.mu.RLock()
+	recent := time.Since(m.lastCheck) < rescanInterval
+	m.mu.RUnlock()
+	if !force && recent {
+		return
+	}
+
+	stamps := make(map[string]time.Time)
+	for _, dir := range m.appDirs {
+		filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				stamps[path] = info.ModTime()
+			}
+			return nil
+		})
+	}
+
+	m.mu.Lock()
+	m.lastCheck = time.Now()
+	unchanged := !force && stampsEqual(m.dirStamps, stamps)
+	m.mu.Unlock()
+	if unchanged {
+		return
+	}
+
+	apps := m.scan()
+	m.icons.reset()
+	for id, app := range apps {
+		app.IconPath = m.icons.resolve(app.Icon)
+		apps[id] = app
+	}
+
+	m.mu.Lock()
+	m.apps = apps
+	m.dirStamps = stamps
+	m.mu.Unlock()
+}
+
+func stampsEqual(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !b[k].Equal(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// scan reads all application directories. Earlier directories take
+// precedence, so a hidden entry in ~/.local/share masks the system one.
+func (m *Manager) scan() map[string]App {
+	apps := make(map[string]App)
+	seen := make(map[string]bool)
+	desktops := currentDesktops()
+
+	for _, dir := range m.appDirs {
+		filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+			id := desktopFileID(dir, path)
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+
+			if app, ok := loadApp(path, id, desktops); ok {
+				apps[id] = app
+			}
+			return nil
+		})
+	}
+	return apps
+}
+
+// Search ranks applications by match quality with a frecency boost. An empty
+// query lists everything, most used first.
+func (m *Manager) Search(query string, limit int) []SearchResult {
+	m.refresh(false)
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	query = strings.TrimSpace(query)
+	now := time.Now()
+
+	m.mu.RLock()
+	m.usageMutex.Lock()
+	results := make([]SearchResult, 0, len(m.apps))
+	for id, app := range m.apps {
+		boost := frecencyScore(m.usage[id], now)
+
+		score := boost
+		if query != "" {
+			relevance := scoreApp(query, app)
+			if relevance <= 0 {
+				continue
+			}
+			score = relevance + math.Min(20, boost)
+		}
+		results = append(results, SearchResult{App: app, Score: math.Round(score*100) / 100})
+	}
+	m.usageMutex.Unlock()
+	m.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].App.Name) < strings.ToLower(results[j].App.Name)
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Launch starts an application by desktop file ID and records the use
+func (m *Manager) Launch(id string) error {
+	m.refresh(false)
+
+	m.mu.RLock()
+	app, ok := m.apps[id]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("application not found: %s", id)
+	}
+
+	argv, err := buildCommand(app)
+	if err != nil {
+		return err
+	}
+	if app.Terminal {
+		argv = wrapInTerminal(argv)
+	}
+
+	if err := m.launch(argv, app.Path); err != nil {
+		return fmt.Errorf("failed to launch %s: %w", app.Name, err)
+	}
+
+	m.recordLaunch(id)
+	return nil
+}
+
+func wrapInTerminal(argv []string) []string {
+	terminal := os.Getenv("TERMINAL")
+	if terminal == "" {
+		if _, err := exec.LookPath("xdg-terminal-exec"); err == nil {
+			return append([]string{"xdg-terminal-exec"}, argv...)
+		}
+		terminal = "xterm"
+	}
+	return append([]string{terminal, "-e"}, argv...)
+}
+
+func startDetached(argv []string, dir string) error {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// Frecency lists the most used applications
+func (m *Manager) Frecency(limit int) []FrecencyEntry {
+	m.refresh(false)
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	now := time.Now()
+
+	m.mu.RLock()
+	m.usageMutex.Lock()
+	entries := make([]FrecencyEntry, 0, len(m.usage))
+	for id, rec := range m.usage {
+		app, ok := m.apps[id]
+		if !ok {
+			continue
+		}
+		entries = append(entries, FrecencyEntry{
+			App:      app,
+			Count:    rec.Count,
+			LastUsed: rec.LastUsed,
+			Score:    math.Round(frecencyScore(rec, now)*100) / 100,
+		})
+	}
+	m.usageMutex.Unlock()
+	m.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// frecencyScore weights the launch count by how recently the app was used,
+// in the style of Firefox's frecency buckets.
+func frecencyScore(rec usageRecord, now time.Time) float64 {
+	if rec.Count == 0 {
+		return 0
+	}
+
+	age := now.Sub(rec.LastUsed)
+	var weight float64
+	switch {
+	case age < 4*time.Hour:
+		weight = 1.0
+	case age < 24*time.Hour:
+		weight = 0.7
+	case age < 7*24*time.Hour:
+		weight = 0.5
+	case age < 30*24*time.Hour:
+		weight = 0.3
+	default:
+		weight = 0.1
+	}
+	return float64(rec.Count) * weight
+}
+
+func (m *Manager) recordLaunch(id string) {
+	m.usageMutex.Lock()
+	rec := m.usage[id]
+	rec.Count++
+	rec.LastUsed = time.Now()
+	m.usage[id] = rec
+	data, err := json.Marshal(m.usage)
+	m.usageMutex.Unlock()
+
+	if err != nil || m.usagePath == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.usagePath), 0755); err != nil {
+		log.Warnf("Failed to save launcher usage: %v", err)
+		return
+	}
+	if err := os.WriteFile(m.usagePath, data, 0644); err != nil {
+		log.Warnf("Failed to save launcher usage: %v", err)
+	}
+}
+
+func (m *Manager) loadUsage() {
+	data, err := os.ReadFile(m.usagePath)
+	if err != nil {
+		return
+	}
+
+	usage := make(map[string]usageRecord)
+	if err := json.Unmarshal(data, &usage); err != nil {
+		log.Warnf("Ignoring corrupt launcher usage file %s: %v", m.usagePath, err)
+		return
+	}
+
+	m.usageMutex.Lock()
+	m.usage = usage
+	m.usageMutex.Unlock()
+}
@@ -0,0 +1,125 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDesktopFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, "applications", name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func newTestManager(t *testing.T) (*Manager, string, string) {
+	t.Helper()
+	t.Setenv("LANG", "C")
+	userDir := t.TempDir()
+	systemDir := t.TempDir()
+
+	writeDesktopFile(t, systemDir, "firefox.desktop", "[Desktop Entry]\nType=Application\nName=Firefox\nGenericName=Web Browser\nExec=firefox %u\nIcon=firefox\n")
+	writeDesktopFile(t, systemDir, "org.gnome.Nautilus.desktop", "[Desktop Entry]\nType=Application\nName=Files\nKeywords=folder;explorer;\nExec=nautilus\n")
+	writeDesktopFile(t, systemDir, "kde/konsole.desktop", "[Desktop Entry]\nType=Application\nName=Konsole\nExec=konsole\n")
+	writeDesktopFile(t, systemDir, "htop.desktop", "[Desktop Entry]\nType=Application\nName=Htop\nExec=htop\nTerminal=true\n")
+	// User override hides the system entry
+	writeDesktopFile(t, userDir, "htop.desktop", "[Desktop Entry]\nType=Application\nName=Htop\nExec=htop\nHidden=true\n")
+
+	iconPath := filepath.Join(systemDir, "icons", "hicolor", "scalable", "apps", "firefox.svg")
+	require.NoError(t, os.MkdirAll(filepath.Dir(iconPath), 0755))
+	require.NoError(t, os.WriteFile(iconPath, []byte("<svg/>"), 0644))
+
+	m := newManager([]string{userDir, systemDir}, filepath.Join(t.TempDir(), "usage.json"), "")
+	m.refresh(true)
+	return m, userDir, iconPath
+}
+
+func TestManager_Index(t *testing.T) {
+	m, _, iconPath := newTestManager(t)
+
+	assert.Len(t, m.apps, 3)
+	assert.Contains(t, m.apps, "kde-konsole.desktop")
+	assert.NotContains(t, m.apps, "htop.desktop")
+	assert.Equal(t, iconPath, m.apps["firefox.desktop"].IconPath)
+}
+
+func TestManager_Search(t *testing.T) {
+	m, _, _ := newTestManager(t)
+
+	results := m.Search("fire", 10)
+	require.NotEmpty(t, results)
+	assert.Equal(t, "firefox.desktop", results[0].App.ID)
+
+	results = m.Search("explorer", 10)
+	require.Len(t, results, 1)
+	assert.Equal(t, "org.gnome.Nautilus.desktop", results[0].App.ID, "keywords are searched")
+
+	results = m.Search("browser", 10)
+	require.Len(t, results, 1, "generic name is searched")
+
+	results = m.Search("ksl", 10)
+	require.Len(t, results, 1)
+	assert.Equal(t, "kde-konsole.desktop", results[0].App.ID, "fuzzy subsequence match")
+
+	assert.Empty(t, m.Search("zzz", 10))
+	assert.Len(t, m.Search("", 2), 2)
+}
+
+func TestManager_LaunchRecordsFrecency(t *testing.T) {
+	m, _, _ := newTestManager(t)
+
+	var launched []string
+	m.launch = func(argv []string, dir string) error {
+		launched = argv
+		return nil
+	}
+
+	require.NoError(t, m.Launch("org.gnome.Nautilus.desktop"))
+	require.NoError(t, m.Launch("org.gnome.Nautilus.desktop"))
+	require.NoError(t, m.Launch("firefox.desktop"))
+	assert.Equal(t, []string{"firefox"}, launched)
+	assert.Error(t, m.Launch("missing.desktop"))
+
+	entries := m.Frecency(10)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "org.gnome.Nautilus.desktop", entries[0].App.ID)
+	assert.Equal(t, 2, entries[0].Count)
+
+	// Frecency breaks ties between equally good matches
+	results := m.Search("", 10)
+	assert.Equal(t, "org.gnome.Nautilus.desktop", results[0].App.ID)
+
+	reloaded := newManager(nil, m.usagePath, "")
+	reloaded.loadUsage()
+	assert.Equal(t, 2, reloaded.usage["org.gnome.Nautilus.desktop"].Count)
+}
+
+func TestManager_RefreshPicksUpNewFiles(t *testing.T) {
+	m, userDir, _ := newTestManager(t)
+
+	writeDesktopFile(t, userDir, "new.desktop", "[Desktop Entry]\nType=Application\nName=Newcomer\nExec=newcomer\n")
+	// Directory mtimes have one-second granularity on some filesystems
+	past := time.Now().Add(-time.Minute)
+	m.mu.Lock()
+	m.lastCheck = past
+	for dir := range m.dirStamps {
+		m.dirStamps[dir] = past
+	}
+	m.mu.Unlock()
+
+	results := m.Search("newcomer", 10)
+	require.Len(t, results, 1)
+}
+
+func TestFrecencyScore(t *testing.T) {
+	now := time.Now()
+	assert.Zero(t, frecencyScore(usageRecord{}, now))
+	recent := frecencyScore(usageRecord{Count: 3, LastUsed: now.Add(-time.Hour)}, now)
+	old := frecencyScore(usageRecord{Count: 3, LastUsed: now.Add(-60 * 24 * time.Hour)}, now)
+	assert.Greater(t, recent, old)
+}
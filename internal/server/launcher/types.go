@@ -0,0 +1,68 @@
+package launcher
+
+import (
+	"sync"
+	"time"
+)
+
+// App is one launchable .desktop entry. ID is the desktop file ID as defined
+// by the desktop entry spec, e.g. "org.gnome.Nautilus.desktop".
+type App struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	GenericName string   `json:"genericName,omitempty"`
+	Comment     string   `json:"comment,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	IconPath    string   `json:"iconPath,omitempty"`
+	Exec        string   `json:"exec"`
+	Path        string   `json:"path,omitempty"`
+	Terminal    bool     `json:"terminal"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	File        string   `json:"file"`
+}
+
+type SearchResult struct {
+	App   App     `json:"app"`
+	Score float64 `json:"score"`
+}
+
+type FrecencyEntry struct {
+	App      App       `json:"app"`
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"lastUsed"`
+	Score    float64   `json:"score"`
+}
+
+type usageRecord struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type Manager struct {
+	appDirs []string
+	icons   *iconResolver
+
+	mu        sync.RWMutex
+	apps      map[string]App
+	dirStamps map[string]time.Time
+	lastCheck time.Time
+
+	usageMutex sync.Mutex
+	usage      map[string]usageRecord
+	usagePath  string
+
+	// launch starts the prepared command; replaced in tests
+	launch func(argv []string, dir string) error
+}
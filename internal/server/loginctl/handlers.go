@@ -159,6 +159,7 @@ func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
 			Data: state,
 		}
 		if err := json.NewEncoder(conn).Encode(models.Response[SessionEvent]{
+			ID:     req.ID,
 			Result: &event,
 		}); err != nil {
 			return
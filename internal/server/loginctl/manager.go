@@ -7,6 +7,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/session"
+	"github.com/AvengeMedia/danklinux/internal/utils"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -16,7 +19,7 @@ func NewManager() (*Manager, error) {
 		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
 	}
 
-	sessionID := os.Getenv("XDG_SESSION_ID")
+	sessionID := session.Current().ID
 	if sessionID == "" {
 		sessionID = "self"
 	}
@@ -45,7 +48,7 @@ func NewManager() (*Manager, error) {
 	}
 
 	m.notifierWg.Add(1)
-	go m.notifier()
+	crash.Go("loginctl.notifier", m.notifier)
 
 	if err := m.startSignalPump(); err != nil {
 		m.Close()
@@ -520,7 +523,7 @@ func (m *Manager) startSignalPump() error {
 	}
 
 	m.sigWG.Add(1)
-	go func() {
+	crash.Go("loginctl.signals", func() {
 		defer m.sigWG.Done()
 		for {
 			select {
@@ -536,7 +539,7 @@ func (m *Manager) startSignalPump() error {
 				m.handleDBusSignal(sig)
 			}
 		}
-	}()
+	})
 	return nil
 }
 
@@ -595,3 +598,8 @@ func (m *Manager) Close() {
 		m.conn.Close()
 	}
 }
+
+// HealthCheck reports whether logind still answers on our connection
+func (m *Manager) HealthCheck() error {
+	return utils.PingDBus(m.conn, dbusDest)
+}
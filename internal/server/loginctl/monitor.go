@@ -3,6 +3,7 @@ package loginctl
 import (
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -60,12 +61,12 @@ func (m *Manager) handleDBusSignal(sig *dbus.Signal) {
 			}
 
 			readyCh := m.newLockerReadyCh()
-			go func(id uint64, ch <-chan struct{}) {
-				<-ch
-				if m.inSleepCycle.Load() && m.sleepCycleID.Load() == id {
+			crash.Go("loginctl.lockerReady", func() {
+				<-readyCh
+				if m.inSleepCycle.Load() && m.sleepCycleID.Load() == cycleID {
 					m.releaseSleepInhibitor()
 				}
-			}(cycleID, readyCh)
+			})
 		} else {
 			m.inSleepCycle.Store(false)
 			m.signalLockerReady()
@@ -0,0 +1,647 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/AvengeMedia/danklinux/internal/backup"
+	"github.com/AvengeMedia/danklinux/internal/server/a11y"
+	"github.com/AvengeMedia/danklinux/internal/server/appcolor"
+	"github.com/AvengeMedia/danklinux/internal/server/audio"
+	"github.com/AvengeMedia/danklinux/internal/server/bluez"
+	"github.com/AvengeMedia/danklinux/internal/server/brightness"
+	"github.com/AvengeMedia/danklinux/internal/server/calendar"
+	"github.com/AvengeMedia/danklinux/internal/server/cups"
+	"github.com/AvengeMedia/danklinux/internal/server/dwl"
+	"github.com/AvengeMedia/danklinux/internal/server/freedesktop"
+	"github.com/AvengeMedia/danklinux/internal/server/health"
+	"github.com/AvengeMedia/danklinux/internal/server/jobs"
+	"github.com/AvengeMedia/danklinux/internal/server/launcher"
+	"github.com/AvengeMedia/danklinux/internal/server/loginctl"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/AvengeMedia/danklinux/internal/server/notifications"
+	"github.com/AvengeMedia/danklinux/internal/server/plugins"
+	"github.com/AvengeMedia/danklinux/internal/server/power"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
+	"github.com/AvengeMedia/danklinux/internal/server/remap"
+	"github.com/AvengeMedia/danklinux/internal/server/rules"
+	"github.com/AvengeMedia/danklinux/internal/server/scratchpad"
+	"github.com/AvengeMedia/danklinux/internal/server/sensors"
+	"github.com/AvengeMedia/danklinux/internal/server/settings"
+	"github.com/AvengeMedia/danklinux/internal/server/sounds"
+	"github.com/AvengeMedia/danklinux/internal/server/termcolors"
+	"github.com/AvengeMedia/danklinux/internal/server/thermal"
+	"github.com/AvengeMedia/danklinux/internal/server/timers"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
+	"github.com/AvengeMedia/danklinux/internal/server/wayland"
+)
+
+// MethodInfo describes an IPC method for validation and editor completion.
+// Streaming methods answer once with Result and again on every change.
+type MethodInfo struct {
+	Method               string         `json:"method"`
+	Description          string         `json:"description"`
+	Streaming            bool           `json:"streaming,omitempty"`
+	RequiresConfirmation bool           `json:"requiresConfirmation,omitempty"`
+	Params               *models.Schema `json:"params"`
+	Result               *models.Schema `json:"result"`
+}
+
+type methodSpec struct {
+	method      string
+	description string
+	params      any
+	result      any
+	streaming   bool
+}
+
+type noParams struct{}
+
+type deviceParams struct {
+	Device string `json:"device" desc:"Device path or address"`
+}
+
+type tokenParams struct {
+	Token string `json:"token" desc:"Token from the request event"`
+}
+
+type printerParams struct {
+	PrinterName string `json:"printerName"`
+}
+
+type jobParams struct {
+	JobID int `json:"jobID"`
+}
+
+type ssidParams struct {
+	SSID string `json:"ssid"`
+}
+
+type vpnParams struct {
+	UUIDOrName string `json:"uuidOrName,omitempty" desc:"Connection UUID or name; uuid or name may be given instead"`
+	UUID       string `json:"uuid,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+type limitParams struct {
+	Limit int `json:"limit,omitempty" desc:"Maximum number of entries, 0 for all"`
+}
+
+type pluginParams struct {
+	Name string `json:"name" desc:"Plugin ID, or name"`
+}
+
+type timerParams struct {
+	ID string `json:"id"`
+}
+
+type stepParams struct {
+	Device      string  `json:"device"`
+	Step        float64 `json:"step,omitempty" desc:"Percentage points"`
+	Exponential bool    `json:"exponential,omitempty"`
+	Exponent    float64 `json:"exponent,omitempty"`
+	Source      string  `json:"source,omitempty" desc:"What caused the change, shown in the OSD"`
+}
+
+type streamParams struct {
+	ID uint32 `json:"id" desc:"Stream ID from audio.streams.list"`
+}
+
+// asyncParams are the params of a method in backgroundMethods that takes
+// no others
+type asyncParams struct {
+	Async bool `json:"async,omitempty" desc:"Answer at once with a job from jobs.get instead of the result"`
+}
+
+type idParams struct {
+	ID string `json:"id"`
+}
+
+type wifiEnabled struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ipcMethods is every method the router serves. methods_test.go checks it
+// against the handlers, so a new method must be added here as well.
+var ipcMethods = []methodSpec{
+	{"ping", "Check that the server answers", noParams{}, "", false},
+	{"getServerInfo", "API version, capabilities and session of the server", noParams{}, ServerInfo{}, false},
+	{"introspect", "Schemas of one method, or of every method", struct {
+		Method string `json:"method,omitempty" desc:"Method to describe, all of them when empty"`
+	}{}, []MethodInfo{}, false},
+	{"subscribe", "Events of the given services, or of all of them", struct {
+		Services []string `json:"services,omitempty" desc:"Services to follow, such as network or bluetooth"`
+	}{}, ServiceEvent{}, true},
+	{"config.reload", "Re-read daemon.toml", noParams{}, ConfigReloadResult{}, false},
+	{"debug.record.start", "Record IPC traffic to a file in the crash directory", struct {
+		Name     string `json:"name,omitempty" desc:"File name ending in .jsonl, ipc-recording.jsonl when empty"`
+		MaxBytes int64  `json:"maxBytes,omitempty" desc:"Size after which the file is rotated"`
+	}{}, RecordingStatus{}, false},
+	{"debug.record.stop", "Stop recording IPC traffic", noParams{}, RecordingStatus{}, false},
+	{"debug.record.status", "Whether IPC traffic is being recorded", noParams{}, RecordingStatus{}, false},
+
+	{"a11y.get", "Accessibility settings", noParams{}, a11y.State{}, false},
+	{"a11y.set", "Change accessibility settings; absent fields are kept", struct {
+		CursorSize   int     `json:"cursorSize,omitempty"`
+		TextScale    float64 `json:"textScale,omitempty"`
+		ReduceMotion bool    `json:"reduceMotion,omitempty"`
+	}{}, a11y.State{}, false},
+	{"a11y.subscribe", "Accessibility settings on every change", noParams{}, a11y.State{}, true},
+
+	{"appcolor.getState", "Last sampled color of the focused window (experimental)", noParams{}, appcolor.State{}, false},
+	{"appcolor.sample", "Capture the focused window and return its dominant color (experimental)", noParams{}, appcolor.Sample{}, false},
+	{"appcolor.follow", "Sample again on every focus change (experimental)", struct {
+		Enabled bool `json:"enabled"`
+	}{}, appcolor.State{}, false},
+	{"appcolor.subscribe", "Sampled colors as focus changes (experimental)", noParams{}, appcolor.State{}, true},
+
+	{"audio.streams.list", "Playback streams and remembered routes", noParams{}, audio.State{}, false},
+	{"audio.streams.setVolume", "Set a stream's volume", struct {
+		streamParams
+		Volume float64 `json:"volume" desc:"0 to 1.5"`
+	}{}, audio.State{}, false},
+	{"audio.streams.setMute", "Mute or unmute a stream", struct {
+		streamParams
+		Muted bool `json:"muted"`
+	}{}, audio.State{}, false},
+	{"audio.streams.setSink", "Move a stream to another output and remember it for the application", struct {
+		streamParams
+		Sink string `json:"sink" desc:"Sink name"`
+	}{}, audio.State{}, false},
+	{"audio.streams.forget", "Forget an application's remembered route", struct {
+		App string `json:"app"`
+	}{}, audio.State{}, false},
+	{"audio.streams.subscribe", "Playback streams on every change", noParams{}, audio.State{}, true},
+
+	{"bluetooth.getState", "Adapter and devices", noParams{}, bluez.BluetoothState{}, false},
+	{"bluetooth.startDiscovery", "Start scanning for devices", noParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.stopDiscovery", "Stop scanning for devices", noParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.setPowered", "Power the adapter on or off", struct {
+		Powered bool `json:"powered"`
+	}{}, bluez.SuccessResult{}, false},
+	{"bluetooth.pair", "Pair with a device", deviceParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.connect", "Connect a device", deviceParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.disconnect", "Disconnect a device", deviceParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.remove", "Remove a paired device", deviceParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.trust", "Trust a device", deviceParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.untrust", "Stop trusting a device", deviceParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.pairing.submit", "Answer a pairing request", struct {
+		Token   string            `json:"token"`
+		Accept  bool              `json:"accept,omitempty"`
+		Secrets map[string]string `json:"secrets,omitempty" desc:"PIN or passkey, by field name"`
+	}{}, bluez.SuccessResult{}, false},
+	{"bluetooth.pairing.cancel", "Reject a pairing request", tokenParams{}, bluez.SuccessResult{}, false},
+	{"bluetooth.subscribe", "Bluetooth state and pairing requests", noParams{}, bluez.BluetoothEvent{}, true},
+
+	{"brightness.getState", "Backlights, LEDs and DDC monitors", noParams{}, brightness.State{}, false},
+	{"brightness.setBrightness", "Set a device's brightness", struct {
+		Device      string  `json:"device"`
+		Percent     float64 `json:"percent"`
+		Exponential bool    `json:"exponential,omitempty"`
+		Exponent    float64 `json:"exponent,omitempty"`
+	}{}, brightness.State{}, false},
+	{"brightness.increment", "Raise a device's brightness", stepParams{}, brightness.State{}, false},
+	{"brightness.decrement", "Lower a device's brightness", stepParams{}, brightness.State{}, false},
+	{"brightness.rescan", "Look for new devices", noParams{}, brightness.State{}, false},
+	{"brightness.ddc.capabilities", "What a DDC monitor supports", struct {
+		Device string `json:"device"`
+	}{}, brightness.DDCCapabilities{}, false},
+	{"brightness.ddc.setContrast", "Set a DDC monitor's contrast", struct {
+		Device  string  `json:"device"`
+		Percent float64 `json:"percent"`
+	}{}, brightness.SuccessResult{}, false},
+	{"brightness.ddc.setInput", "Switch a DDC monitor's input", struct {
+		Device string `json:"device"`
+		Input  any    `json:"input" desc:"Input name such as hdmi1, or its VCP value"`
+	}{}, brightness.SuccessResult{}, false},
+	{"brightness.subscribe", "Brightness on every change", noParams{}, brightness.State{}, true},
+
+	{"calendar.getState", "Calendars and upcoming events", noParams{}, calendar.State{}, false},
+	{"calendar.upcoming", "Events in the coming days", struct {
+		Days     int    `json:"days,omitempty"`
+		Limit    int    `json:"limit,omitempty"`
+		Calendar string `json:"calendar,omitempty" desc:"Only events of this calendar"`
+	}{}, []calendar.Event{}, false},
+	{"calendar.refresh", "Re-read the calendars", noParams{}, calendar.SuccessResult{}, false},
+	{"calendar.subscribe", "Calendar state on every change", noParams{}, calendar.State{}, true},
+
+	{"cups.getPrinters", "Printers", noParams{}, []cups.Printer{}, false},
+	{"cups.getJobs", "A printer's jobs", printerParams{}, []cups.Job{}, false},
+	{"cups.pausePrinter", "Pause a printer", printerParams{}, cups.SuccessResult{}, false},
+	{"cups.resumePrinter", "Resume a printer", printerParams{}, cups.SuccessResult{}, false},
+	{"cups.cancelJob", "Cancel a job", jobParams{}, cups.SuccessResult{}, false},
+	{"cups.retryJob", "Restart a failed job", jobParams{}, cups.SuccessResult{}, false},
+	{"cups.purgeJobs", "Cancel all of a printer's jobs", printerParams{}, cups.SuccessResult{}, false},
+	{"cups.deletePrinter", "Delete a printer", printerParams{}, cups.SuccessResult{}, false},
+	{"cups.print", "Print a file, URL or base64 data", struct {
+		PrinterName string `json:"printerName"`
+		Path        string `json:"path,omitempty"`
+		URL         string `json:"url,omitempty"`
+		Data        string `json:"data,omitempty" desc:"Base64 document"`
+		Title       string `json:"title,omitempty"`
+	}{}, cups.PrintResult{}, false},
+	{"cups.getServerSettings", "CUPS server settings", noParams{}, cups.ServerSettings{}, false},
+	{"cups.setServerSettings", "Change CUPS server settings; absent fields are kept", struct {
+		SharePrinters bool `json:"sharePrinters,omitempty"`
+		RemoteAny     bool `json:"remoteAny,omitempty"`
+		RemoteAdmin   bool `json:"remoteAdmin,omitempty"`
+		UserCancelAny bool `json:"userCancelAny,omitempty"`
+		DebugLogging  bool `json:"debugLogging,omitempty"`
+		BrowseRemote  bool `json:"browseRemote,omitempty"`
+	}{}, cups.ServerSettings{}, false},
+	{"cups.getDevices", "Printers that can be added", asyncParams{}, []cups.Device{}, false},
+	{"cups.autoAdd", "Add a discovered printer with a matching driver", struct {
+		URI   string `json:"uri"`
+		Name  string `json:"name,omitempty"`
+		Async bool   `json:"async,omitempty" desc:"Answer at once with a job from jobs.get instead of the result"`
+	}{}, cups.AutoAddResult{}, false},
+	{"cups.subscribe", "Printer and job events", noParams{}, cups.CUPSEvent{}, true},
+
+	{"dwl.getState", "Outputs, tags and layouts", noParams{}, dwl.State{}, false},
+	{"dwl.setTags", "Show tags on an output", struct {
+		Output       string `json:"output"`
+		Tagmask      uint32 `json:"tagmask"`
+		ToggleTagset uint32 `json:"toggleTagset"`
+	}{}, dwl.SuccessResult{}, false},
+	{"dwl.setClientTags", "Change the focused client's tags", struct {
+		Output  string `json:"output"`
+		AndTags uint32 `json:"andTags"`
+		XorTags uint32 `json:"xorTags"`
+	}{}, dwl.SuccessResult{}, false},
+	{"dwl.setLayout", "Set an output's layout", struct {
+		Output string `json:"output"`
+		Index  uint32 `json:"index"`
+	}{}, dwl.SuccessResult{}, false},
+	{"dwl.subscribe", "dwl state on every change", noParams{}, dwl.State{}, true},
+
+	{"freedesktop.getState", "Account and desktop settings", noParams{}, freedesktop.FreedeskState{}, false},
+	{"freedesktop.accounts.setIconFile", "Set the user's picture", struct {
+		Path string `json:"path"`
+	}{}, freedesktop.SuccessResult{}, false},
+	{"freedesktop.accounts.setRealName", "Set the user's full name", struct {
+		Name string `json:"name"`
+	}{}, freedesktop.SuccessResult{}, false},
+	{"freedesktop.accounts.setEmail", "Set the user's email address", struct {
+		Email string `json:"email"`
+	}{}, freedesktop.SuccessResult{}, false},
+	{"freedesktop.accounts.setLanguage", "Set the user's language", struct {
+		Language string `json:"language"`
+	}{}, freedesktop.SuccessResult{}, false},
+	{"freedesktop.accounts.setLocation", "Set the user's location", struct {
+		Location string `json:"location"`
+	}{}, freedesktop.SuccessResult{}, false},
+	{"freedesktop.accounts.getUserIconFile", "Another user's picture, in value", struct {
+		Username string `json:"username"`
+	}{}, freedesktop.SuccessResult{}, false},
+	{"freedesktop.settings.getColorScheme", "The portal color scheme: 0 none, 1 dark, 2 light", noParams{}, struct {
+		ColorScheme uint32 `json:"colorScheme"`
+	}{}, false},
+	{"freedesktop.settings.setIconTheme", "Set the icon theme", struct {
+		IconTheme string `json:"iconTheme"`
+	}{}, freedesktop.SuccessResult{}, false},
+
+	{"health.getState", "Backend health", noParams{}, health.State{}, false},
+	{"health.check", "Check every backend now", noParams{}, health.State{}, false},
+	{"health.subscribe", "Backend health on every change", noParams{}, health.State{}, true},
+
+	{"launcher.search", "Search applications", struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit,omitempty"`
+	}{}, []launcher.SearchResult{}, false},
+	{"launcher.launch", "Launch an application and record it", struct {
+		ID string `json:"id" desc:"Desktop entry ID"`
+	}{}, launcher.SuccessResult{}, false},
+	{"launcher.frecency", "Most used applications", struct {
+		Limit int `json:"limit,omitempty"`
+	}{}, []launcher.FrecencyEntry{}, false},
+	{"launcher.refresh", "Re-read desktop entries", noParams{}, launcher.SuccessResult{}, false},
+
+	{"loginctl.getState", "Session state", noParams{}, loginctl.SessionState{}, false},
+	{"loginctl.lock", "Lock the session", noParams{}, loginctl.SuccessResult{}, false},
+	{"loginctl.unlock", "Unlock the session", noParams{}, loginctl.SuccessResult{}, false},
+	{"loginctl.activate", "Activate the session", noParams{}, loginctl.SuccessResult{}, false},
+	{"loginctl.setIdleHint", "Set the session's idle hint", struct {
+		Idle bool `json:"idle"`
+	}{}, loginctl.SuccessResult{}, false},
+	{"loginctl.setLockBeforeSuspend", "Lock the session before suspending", struct {
+		Enabled bool `json:"enabled"`
+	}{}, loginctl.SuccessResult{}, false},
+	{"loginctl.setSleepInhibitorEnabled", "Delay sleep until the lock screen is up", struct {
+		Enabled bool `json:"enabled"`
+	}{}, loginctl.SuccessResult{}, false},
+	{"loginctl.lockerReady", "Report that the lock screen is up", noParams{}, loginctl.SuccessResult{}, false},
+	{"loginctl.terminate", "End the session", noParams{}, loginctl.SuccessResult{}, false},
+	{"loginctl.subscribe", "Session state and lock requests", noParams{}, loginctl.SessionEvent{}, true},
+
+	{"network.getState", "Network state", noParams{}, network.NetworkState{}, false},
+	{"network.wifi.scan", "Scan for WiFi networks", noParams{}, network.SuccessResult{}, false},
+	{"network.wifi.networks", "Visible WiFi networks", noParams{}, []network.WiFiNetwork{}, false},
+	{"network.wifi.connect", "Connect to a WiFi network", struct {
+		SSID              string `json:"ssid"`
+		Password          string `json:"password,omitempty"`
+		Username          string `json:"username,omitempty" desc:"Enterprise networks only"`
+		AnonymousIdentity string `json:"anonymousIdentity,omitempty"`
+		DomainSuffixMatch string `json:"domainSuffixMatch,omitempty"`
+		Interactive       bool   `json:"interactive,omitempty" desc:"Ask for missing secrets through network.subscribe"`
+	}{}, network.SuccessResult{}, false},
+	{"network.wifi.disconnect", "Disconnect from WiFi", noParams{}, network.SuccessResult{}, false},
+	{"network.wifi.forget", "Forget a WiFi network", ssidParams{}, network.SuccessResult{}, false},
+	{"network.wifi.toggle", "Turn WiFi on or off", noParams{}, wifiEnabled{}, false},
+	{"network.wifi.enable", "Turn WiFi on", noParams{}, wifiEnabled{}, false},
+	{"network.wifi.disable", "Turn WiFi off", noParams{}, wifiEnabled{}, false},
+	{"network.wifi.setAutoconnect", "Set whether a WiFi network connects on its own", struct {
+		SSID        string `json:"ssid"`
+		Autoconnect bool   `json:"autoconnect"`
+	}{}, network.SuccessResult{}, false},
+	{"network.wifi.getRoaming", "A WiFi network's band and roaming settings", ssidParams{}, network.WiFiRoaming{}, false},
+	{"network.wifi.setRoaming", "Change a WiFi network's band and roaming settings", struct {
+		SSID    string `json:"ssid"`
+		BSSID   string `json:"bssid,omitempty" desc:"Access point to stay on, empty for any"`
+		Band    string `json:"band,omitempty" enum:"auto|2.4|5|6"`
+		Roaming string `json:"roaming,omitempty" enum:"default|moderate|aggressive"`
+	}{}, network.SuccessResult{}, false},
+	{"network.ethernet.connect", "Connect the wired interface", noParams{}, network.SuccessResult{}, false},
+	{"network.ethernet.connect.config", "Connect a wired profile", struct {
+		UUID string `json:"uuid"`
+	}{}, network.SuccessResult{}, false},
+	{"network.ethernet.disconnect", "Disconnect the wired interface", noParams{}, network.SuccessResult{}, false},
+	{"network.ethernet.info", "Details of a wired profile", struct {
+		UUID string `json:"uuid"`
+	}{}, network.WiredNetworkInfoResponse{}, false},
+	{"network.preference.set", "Prefer WiFi or ethernet", struct {
+		Preference string `json:"preference" enum:"auto|wifi|ethernet"`
+	}{}, struct {
+		Preference string `json:"preference"`
+	}{}, false},
+	{"network.info", "Details of a WiFi network", ssidParams{}, network.NetworkInfoResponse{}, false},
+	{"network.credentials.submit", "Answer a request for network secrets", struct {
+		Token   string            `json:"token"`
+		Secrets map[string]string `json:"secrets"`
+		Save    bool              `json:"save,omitempty"`
+	}{}, network.SuccessResult{}, false},
+	{"network.credentials.cancel", "Reject a request for network secrets", tokenParams{}, network.SuccessResult{}, false},
+	{"network.vpn.profiles", "VPN profiles", noParams{}, []network.VPNProfile{}, false},
+	{"network.vpn.active", "Active VPN connections", noParams{}, []network.VPNActive{}, false},
+	{"network.vpn.connect", "Connect a VPN", struct {
+		vpnParams
+		SingleActive *bool `json:"singleActive,omitempty" desc:"Disconnect other VPNs first (default true)"`
+	}{}, network.SuccessResult{}, false},
+	{"network.vpn.disconnect", "Disconnect a VPN", vpnParams{}, network.SuccessResult{}, false},
+	{"network.vpn.disconnectAll", "Disconnect every VPN", noParams{}, network.SuccessResult{}, false},
+	{"network.vpn.clearCredentials", "Forget a VPN's saved secrets", vpnParams{}, network.SuccessResult{}, false},
+	{"network.appUsage", "Traffic per application", limitParams{}, network.AppUsageReport{}, false},
+	{"network.speedTest", "Measure the connection's speed", asyncParams{}, network.SpeedTestResult{}, false},
+	{"network.tether.list", "USB and Bluetooth phones that can share their connection", noParams{}, []network.TetherDevice{}, false},
+	{"network.tether.connect", "Connect through a tethering phone", struct {
+		idParams
+		asyncParams
+	}{}, network.SuccessResult{}, false},
+	{"network.tether.disconnect", "Disconnect a Bluetooth tethering phone", idParams{}, network.SuccessResult{}, false},
+	{"network.linkHistory", "Recent signal and speed samples", limitParams{}, network.LinkHistory{}, false},
+	{"network.subscribe", "Network state and secret requests", noParams{}, network.NetworkEvent{}, true},
+
+	{"notifications.getForwarding", "Where notifications are forwarded", noParams{}, notifications.ForwardConfig{}, false},
+	{"notifications.setForwarding", "Change where notifications of one urgency are forwarded, saved to daemon.toml", struct {
+		Urgency  string `json:"urgency" enum:"low|normal|critical" desc:"Lowest urgency that is forwarded"`
+		File     string `json:"file,omitempty"`
+		Terminal bool   `json:"terminal,omitempty"`
+		Bell     bool   `json:"bell,omitempty"`
+	}{}, notifications.SuccessResult{}, false},
+	{"notifications.forward", "Forward a notification", struct {
+		Summary string `json:"summary"`
+		Body    string `json:"body,omitempty"`
+		AppName string `json:"appName,omitempty"`
+		Urgency string `json:"urgency,omitempty" enum:"low|normal|critical"`
+	}{}, notifications.ForwardResult{}, false},
+
+	{"plugins.list", "Plugins in the registry", noParams{}, []plugins.PluginInfo{}, false},
+	{"plugins.listInstalled", "Installed plugins", noParams{}, []plugins.PluginInfo{}, false},
+	{"plugins.install", "Install a plugin", pluginParams{}, plugins.SuccessResult{}, false},
+	{"plugins.uninstall", "Uninstall a plugin", pluginParams{}, plugins.SuccessResult{}, false},
+	{"plugins.update", "Update a plugin", pluginParams{}, plugins.SuccessResult{}, false},
+	{"plugins.search", "Search the registry", struct {
+		Query      string `json:"query,omitempty"`
+		Category   string `json:"category,omitempty"`
+		Compositor string `json:"compositor,omitempty"`
+		Capability string `json:"capability,omitempty"`
+	}{}, []plugins.PluginInfo{}, false},
+
+	{"power.getState", "Power profile and battery", noParams{}, power.State{}, false},
+	{"power.setProfile", "Set the power profile", struct {
+		Profile string `json:"profile"`
+	}{}, power.SuccessResult{}, false},
+	{"power.policy.get", "Profiles used on AC and battery", noParams{}, power.Policy{}, false},
+	{"power.policy.set", "Change the AC and battery policy, saved to daemon.toml; absent fields are kept", struct {
+		Enabled        bool    `json:"enabled,omitempty"`
+		ACProfile      string  `json:"acProfile,omitempty"`
+		BatteryProfile string  `json:"batteryProfile,omitempty"`
+		BrightnessCap  int     `json:"brightnessCap,omitempty" desc:"Highest brightness on battery, in percent"`
+		RefreshRate    float64 `json:"refreshRate,omitempty" desc:"Refresh rate on battery, in Hz"`
+	}{}, power.Policy{}, false},
+	{"power.subscribe", "Power state on every change", noParams{}, power.State{}, true},
+
+	{"prompts.list", "Pending prompts", noParams{}, []prompts.Prompt{}, false},
+	{"prompts.respond", "Answer a prompt", struct {
+		Token    string            `json:"token"`
+		Action   string            `json:"action"`
+		Value    string            `json:"value,omitempty"`
+		Secrets  map[string]string `json:"secrets,omitempty" desc:"Values for the prompt's fields"`
+		Remember bool              `json:"remember,omitempty" desc:"Keep the secrets, e.g. save them to the network connection"`
+	}{}, prompts.SuccessResult{}, false},
+	{"prompts.subscribe", "Prompts as they are raised and answered", noParams{}, prompts.Event{}, true},
+
+	{"remap.getState", "Key remaps", noParams{}, remap.State{}, false},
+	{"remap.reload", "Re-read the remaps", noParams{}, remap.State{}, false},
+	{"remap.set", "Remap a key", struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}{}, remap.State{}, false},
+	{"remap.remove", "Remove a remap", struct {
+		From string `json:"from"`
+	}{}, remap.State{}, false},
+	{"remap.clear", "Remove every remap", noParams{}, remap.State{}, false},
+
+	{"rules.getState", "Window rules", noParams{}, rules.State{}, false},
+	{"rules.reload", "Re-read the window rules", noParams{}, rules.State{}, false},
+	{"rules.match", "Rules that apply to a window", struct {
+		AppID string `json:"appId"`
+		Title string `json:"title,omitempty"`
+	}{}, rules.MatchResult{}, false},
+
+	{"scratchpad.getState", "Scratchpads", noParams{}, scratchpad.State{}, false},
+	{"scratchpad.reload", "Re-read the scratchpads", noParams{}, scratchpad.State{}, false},
+	{"scratchpad.toggle", "Show or hide a scratchpad, starting it if needed", struct {
+		Name    string `json:"name"`
+		Command string `json:"command,omitempty" desc:"Define the scratchpad on the fly"`
+		AppID   string `json:"appId,omitempty"`
+	}{}, scratchpad.ToggleResult{}, false},
+
+	{"sensors.list", "Temperature sensors", noParams{}, sensors.State{}, false},
+	{"sensors.setThreshold", "Set a sensor's warning threshold", struct {
+		Sensor string  `json:"sensor"`
+		Value  float64 `json:"value" desc:"Degrees Celsius"`
+	}{}, sensors.SuccessResult{}, false},
+	{"sensors.setDefaultThreshold", "Set the warning threshold of sensors without their own", struct {
+		Value float64 `json:"value" desc:"Degrees Celsius"`
+	}{}, sensors.SuccessResult{}, false},
+	{"sensors.subscribe", "Sensors on every change", noParams{}, sensors.State{}, true},
+
+	{"settings.export", "Export the shell settings to an archive", struct {
+		Path string `json:"path,omitempty"`
+	}{}, settings.ExportResult{}, false},
+	{"settings.import", "Restore the shell settings from an archive", struct {
+		Path string `json:"path" desc:"Absolute path"`
+	}{}, backup.RestoreResult{}, false},
+
+	{"sounds.getState", "Per-event sound settings and what playback can do", noParams{}, sounds.State{}, false},
+	{"sounds.play", "Play an event's sound, then speak text when the event has speak set; returns without waiting", struct {
+		Event  string  `json:"event"`
+		Text   string  `json:"text,omitempty"`
+		Volume float64 `json:"volume,omitempty" desc:"0 to 1, overriding the event's volume"`
+	}{}, sounds.PlayResult{}, false},
+	{"sounds.setEvent", "Configure an event, adding it when new; absent fields are kept", struct {
+		Event   string  `json:"event"`
+		Enabled bool    `json:"enabled,omitempty"`
+		Sound   string  `json:"sound,omitempty" desc:"Sound theme name or absolute path"`
+		Volume  float64 `json:"volume,omitempty" desc:"0 to 1"`
+		Duck    bool    `json:"duck,omitempty"`
+		Speak   bool    `json:"speak,omitempty"`
+	}{}, sounds.State{}, false},
+	{"sounds.setConfig", "Set the settings shared by all events; absent fields are kept", struct {
+		Theme         string   `json:"theme,omitempty"`
+		Volume        float64  `json:"volume,omitempty" desc:"0 to 1"`
+		DuckLevel     float64  `json:"duckLevel,omitempty" desc:"0 to 1, what other playback is lowered to"`
+		SpeechCommand []string `json:"speechCommand,omitempty" desc:"Program and arguments; the text is appended"`
+	}{}, sounds.State{}, false},
+	{"sounds.subscribe", "Sound settings and playback on every change", noParams{}, sounds.State{}, true},
+
+	{"jobs.list", "Running, queued and recent background jobs", noParams{}, jobs.State{}, false},
+	{"jobs.get", "One job, with its result once it succeeded", idParams{}, jobs.Job{}, false},
+	{"jobs.cancel", "Stop a queued or running job; it turns cancelled once its work returns", idParams{}, jobs.Job{}, false},
+	{"jobs.subscribe", "Jobs on every status or progress change", noParams{}, jobs.State{}, true},
+
+	{"termcolors.getState", "Palette last sent to terminals", noParams{}, termcolors.State{}, false},
+	{"termcolors.apply", "Send a palette to every open terminal", struct {
+		Colors     []string `json:"colors" desc:"The 16 ANSI colors as hex"`
+		Foreground string   `json:"foreground,omitempty"`
+		Background string   `json:"background,omitempty"`
+		Cursor     string   `json:"cursor,omitempty"`
+		Async      bool     `json:"async,omitempty" desc:"Answer at once with a job from jobs.get instead of the result"`
+	}{}, termcolors.BroadcastResult{}, false},
+
+	{"thermal.getState", "Thermal profiles", noParams{}, thermal.State{}, false},
+	{"thermal.listProfiles", "Thermal profiles", noParams{}, thermal.State{}, false},
+	{"thermal.setProfile", "Set the thermal profile", struct {
+		Profile string `json:"profile"`
+	}{}, thermal.State{}, false},
+
+	{"timers.list", "Timers", noParams{}, timers.State{}, false},
+	{"timers.create", "Start a countdown", struct {
+		Seconds int    `json:"seconds"`
+		Label   string `json:"label,omitempty"`
+	}{}, timers.Timer{}, false},
+	{"timers.alarm", "Set an alarm", struct {
+		At    string `json:"at" desc:"HH:MM, or an RFC 3339 time"`
+		Label string `json:"label,omitempty"`
+	}{}, timers.Timer{}, false},
+	{"timers.pomodoro", "Start a pomodoro cycle; durations are in minutes", struct {
+		Work       int    `json:"work,omitempty"`
+		ShortBreak int    `json:"shortBreak,omitempty"`
+		LongBreak  int    `json:"longBreak,omitempty"`
+		Rounds     int    `json:"rounds,omitempty"`
+		Label      string `json:"label,omitempty"`
+	}{}, timers.Timer{}, false},
+	{"timers.pause", "Pause a timer", timerParams{}, timers.Timer{}, false},
+	{"timers.resume", "Resume a timer", timerParams{}, timers.Timer{}, false},
+	{"timers.skip", "Skip to a pomodoro's next phase", timerParams{}, timers.Timer{}, false},
+	{"timers.cancel", "Cancel a timer", timerParams{}, timers.SuccessResult{}, false},
+	{"timers.subscribe", "Timers on every change", noParams{}, timers.State{}, true},
+
+	{"wallpaper.get", "Wallpapers and where each output draws them", noParams{}, wallpaper.State{}, false},
+	{"wallpaper.set", "Set the wallpaper of one output, every output, or spanned across them", struct {
+		Path   string `json:"path"`
+		Output string `json:"output,omitempty"`
+		Mode   string `json:"mode,omitempty" enum:"fill|fit|center"`
+		Span   bool   `json:"span,omitempty"`
+	}{}, wallpaper.State{}, false},
+	{"wallpaper.clear", "Remove an output's wallpaper, or every wallpaper", struct {
+		Output string `json:"output,omitempty"`
+	}{}, wallpaper.State{}, false},
+	{"wallpaper.subscribe", "Wallpapers on every change", noParams{}, wallpaper.State{}, true},
+
+	{"wayland.gamma.getState", "Night light state", noParams{}, wayland.State{}, false},
+	{"wayland.gamma.setTemperature", "Set the night and day temperatures", struct {
+		Temp int `json:"temp,omitempty" desc:"Both temperatures, in Kelvin"`
+		Low  int `json:"low,omitempty"`
+		High int `json:"high,omitempty"`
+	}{}, wayland.SuccessResult{}, false},
+	{"wayland.gamma.setLocation", "Set the location used for sunrise and sunset", struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}{}, wayland.SuccessResult{}, false},
+	{"wayland.gamma.setManualTimes", "Set sunrise and sunset, or clear them when absent", struct {
+		Sunrise string `json:"sunrise,omitempty" desc:"HH:MM"`
+		Sunset  string `json:"sunset,omitempty" desc:"HH:MM"`
+	}{}, wayland.SuccessResult{}, false},
+	{"wayland.gamma.setUseIPLocation", "Locate by IP address", struct {
+		Use bool `json:"use"`
+	}{}, wayland.SuccessResult{}, false},
+	{"wayland.gamma.setGamma", "Set the gamma", struct {
+		Gamma float64 `json:"gamma"`
+	}{}, wayland.SuccessResult{}, false},
+	{"wayland.gamma.setEnabled", "Turn the night light on or off", struct {
+		Enabled bool `json:"enabled"`
+	}{}, wayland.SuccessResult{}, false},
+	{"wayland.gamma.subscribe", "Night light state on every change", noParams{}, wayland.State{}, true},
+}
+
+func (s methodSpec) info() MethodInfo {
+	return MethodInfo{
+		Method:               s.method,
+		Description:          s.description,
+		Streaming:            s.streaming,
+		RequiresConfirmation: destructiveMethods[s.method],
+		Params:               models.SchemaFor(s.params),
+		Result:               models.SchemaFor(s.result),
+	}
+}
+
+// Methods describes every IPC method, sorted by name
+func Methods() []MethodInfo {
+	infos := make([]MethodInfo, 0, len(ipcMethods))
+	for _, spec := range ipcMethods {
+		infos = append(infos, spec.info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Method < infos[j].Method })
+	return infos
+}
+
+// LookupMethod describes one method. Deprecated names resolve to the
+// method that replaced them.
+func LookupMethod(method string) (MethodInfo, bool) {
+	if rename, ok := renamedMethods[method]; ok {
+		method = rename.replacement
+	}
+	for _, spec := range ipcMethods {
+		if spec.method == method {
+			return spec.info(), true
+		}
+	}
+	return MethodInfo{}, false
+}
+
+func handleIntrospect(conn net.Conn, req models.Request) {
+	method, _ := req.Params["method"].(string)
+	if method == "" {
+		models.Respond(conn, req.ID, Methods())
+		return
+	}
+	info, ok := LookupMethod(method)
+	if !ok {
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", method))
+		return
+	}
+	models.Respond(conn, req.ID, info)
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	caseLine   = regexp.MustCompile(`(?m)^\tcase "[^\n]*:$`)
+	caseMethod = regexp.MustCompile(`"([a-zA-Z][a-zA-Z0-9]*(?:\.[a-zA-Z0-9]+)*)"`)
+)
+
+// handledMethods collects the method names of the top-level cases in the
+// router and the module handlers. Nested switches, such as the one over
+// network.wifi.setRoaming's keys, are indented further.
+func handledMethods(t *testing.T) []string {
+	files, err := filepath.Glob("*/handlers.go")
+	require.NoError(t, err)
+	files = append(files, "router.go")
+
+	var methods []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, line := range caseLine.FindAllString(string(data), -1) {
+			for _, m := range caseMethod.FindAllStringSubmatch(line, -1) {
+				methods = append(methods, m[1])
+			}
+		}
+	}
+	return methods
+}
+
+func TestMethodsCoverHandlers(t *testing.T) {
+	handled := handledMethods(t)
+	require.Greater(t, len(handled), 100)
+
+	for _, method := range handled {
+		if _, renamed := renamedMethods[method]; renamed {
+			continue
+		}
+		_, ok := LookupMethod(method)
+		assert.True(t, ok, "%s has no schema in ipcMethods", method)
+	}
+
+	isHandled := make(map[string]bool)
+	for _, method := range handled {
+		isHandled[method] = true
+	}
+	seen := make(map[string]bool)
+	for _, spec := range ipcMethods {
+		assert.False(t, seen[spec.method], "%s is listed twice", spec.method)
+		seen[spec.method] = true
+		assert.True(t, isHandled[spec.method], "%s is not handled", spec.method)
+	}
+}
+
+func TestBackgroundMethodsTakeAsync(t *testing.T) {
+	for method := range backgroundMethods {
+		info, ok := LookupMethod(method)
+		require.True(t, ok, method)
+		assert.Contains(t, info.Params.Properties, "async", method)
+	}
+}
+
+func TestLookupMethod(t *testing.T) {
+	info, ok := LookupMethod("wallpaper.set")
+	require.True(t, ok)
+	assert.Equal(t, models.SchemaDialect, info.Params.Dialect)
+	assert.Equal(t, []string{"path"}, info.Params.Required)
+	assert.Equal(t, []string{"fill", "fit", "center"}, info.Params.Properties["mode"].Enum)
+	assert.Equal(t, "#/$defs/wallpaper.Config", info.Result.Properties["config"].Ref)
+	assert.Contains(t, info.Result.Defs, "wallpaper.Placement")
+
+	info, ok = LookupMethod("sensors.list")
+	require.True(t, ok)
+	assert.Equal(t, "sensors.list", info.Method)
+
+	info, _ = LookupMethod("cups.purgeJobs")
+	assert.True(t, info.RequiresConfirmation)
+
+	_, ok = LookupMethod("nope.nothing")
+	assert.False(t, ok)
+
+	// Every schema must survive a round trip through JSON
+	for _, info := range Methods() {
+		_, err := json.Marshal(info)
+		require.NoError(t, err, info.Method)
+	}
+}
+
+type schemaNode struct {
+	Name     string        `json:"name" desc:"Node name"`
+	Children []*schemaNode `json:"children,omitempty"`
+}
+
+type schemaEmbedded struct {
+	ID uint16 `json:"id"`
+}
+
+type schemaSample struct {
+	schemaEmbedded
+	Root    schemaNode         `json:"root"`
+	Kind    string             `json:"kind" enum:"a|b"`
+	When    time.Time          `json:"when"`
+	Raw     []byte             `json:"raw,omitempty"`
+	Labels  map[string]float64 `json:"labels,omitempty"`
+	Any     interface{}        `json:"any,omitempty"`
+	Skipped string             `json:"-"`
+	hidden  string
+}
+
+func TestSchemaFor(t *testing.T) {
+	s := models.SchemaFor(schemaSample{hidden: "x"})
+
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"id", "root", "kind", "when"}, s.Required)
+	assert.NotContains(t, s.Properties, "Skipped")
+	assert.NotContains(t, s.Properties, "hidden")
+
+	assert.Equal(t, "integer", s.Properties["id"].Type)
+	assert.Equal(t, 0.0, *s.Properties["id"].Minimum)
+	assert.Equal(t, []string{"a", "b"}, s.Properties["kind"].Enum)
+	assert.Equal(t, "date-time", s.Properties["when"].Format)
+	assert.Equal(t, "byte", s.Properties["raw"].Format)
+	assert.Equal(t, "number", s.Properties["labels"].AdditionalProperties.Type)
+	assert.Empty(t, s.Properties["any"].Type)
+
+	// Recursive types are referenced, not expanded
+	assert.Equal(t, "#/$defs/server.schemaNode", s.Properties["root"].Ref)
+	node := s.Defs["server.schemaNode"]
+	require.NotNil(t, node)
+	assert.Equal(t, "Node name", node.Properties["name"].Description)
+	assert.Equal(t, "#/$defs/server.schemaNode", node.Properties["children"].Items.Ref)
+
+	assert.Equal(t, "null", models.SchemaFor(nil).Type)
+	assert.Equal(t, "array", models.SchemaFor([]string{}).Type)
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// PeerCred identifies the process on the other end of a client connection
+type PeerCred struct {
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// BaseConn returns the socket under conn, looking through any wrappers
+// that expose Unwrap
+func BaseConn(conn net.Conn) net.Conn {
+	for {
+		w, ok := conn.(interface{ Unwrap() net.Conn })
+		if !ok {
+			return conn
+		}
+		conn = w.Unwrap()
+	}
+}
+
+// PeerCredentials reads SO_PEERCRED from the unix socket under conn
+func PeerCredentials(conn net.Conn) (PeerCred, error) {
+	conn = BaseConn(conn)
+
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return PeerCred{}, fmt.Errorf("peer credentials need a unix socket, got %T", conn)
+	}
+
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return PeerCred{}, err
+	}
+
+	var cred *unix.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return PeerCred{}, err
+	}
+	if credErr != nil {
+		return PeerCred{}, fmt.Errorf("SO_PEERCRED: %w", credErr)
+	}
+
+	return PeerCred{PID: cred.Pid, UID: cred.Uid, GID: cred.Gid}, nil
+}
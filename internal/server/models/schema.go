@@ -0,0 +1,188 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaDialect is the JSON Schema draft generated schemas declare
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Named struct types are emitted once under Defs
+// and referenced, which also keeps recursive types finite.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	emptyStruct   = reflect.TypeOf(struct{}{})
+	zero          = 0.0
+	unsignedFloor = &zero
+)
+
+// SchemaFor describes the JSON encoding/json produces for v. Fields
+// without omitempty are required. Struct fields may carry a desc tag,
+// copied into the description, and an enum tag listing the allowed values
+// separated by |.
+func SchemaFor(v any) *Schema {
+	if v == nil {
+		return &Schema{Dialect: SchemaDialect, Type: "null"}
+	}
+	g := &schemaGen{defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// The described type itself is inlined rather than referenced
+	var s *Schema
+	if t.Kind() == reflect.Struct && t != timeType {
+		s = g.object(t)
+	} else {
+		s = g.schema(t)
+	}
+	s.Dialect = SchemaDialect
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+type schemaGen struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func (g *schemaGen) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Minimum: unsignedFloor}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" || t == emptyStruct {
+			return g.object(t)
+		}
+		return g.ref(t)
+	}
+	// interface{} and anything encoding/json cannot describe statically
+	return &Schema{}
+}
+
+// ref returns a reference to the definition of a named struct, adding it
+// on first use
+func (g *schemaGen) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = defName(t)
+		for g.defs[name] != nil {
+			name += "_"
+		}
+		g.names[t] = name
+		g.defs[name] = &Schema{}
+		*g.defs[name] = *g.object(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// defName is the type's package and name, such as timers.Timer
+func defName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name := t.Name()
+	// Generic instances are named like Response[github.com/x/pkg.T]
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+func (g *schemaGen) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.fields(t, s)
+	return s
+}
+
+// fields adds the fields of struct t to s, flattening embedded structs as
+// encoding/json does
+func (g *schemaGen) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, s)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.schema(f.Type)
+		fs.Description = f.Tag.Get("desc")
+		if enum := f.Tag.Get("enum"); enum != "" {
+			fs.Enum = strings.Split(enum, "|")
+		}
+		s.Properties[name] = fs
+
+		if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
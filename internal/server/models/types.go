@@ -11,21 +11,68 @@ type Request struct {
 	ID     int                    `json:"id,omitempty"`
 	Method string                 `json:"method"`
 	Params map[string]interface{} `json:"params,omitempty"`
+	// APIVersion is the server API version the client was written against.
+	// Clients that predate versioning leave it out.
+	APIVersion int `json:"apiVersion,omitempty"`
 }
 
 type Response[T any] struct {
 	ID     int    `json:"id,omitempty"`
 	Result *T     `json:"result,omitempty"`
 	Error  string `json:"error,omitempty"`
+	// Code classifies errors a client can act on, such as prompting for
+	// authentication. Most errors leave it out.
+	Code string        `json:"code,omitempty"`
+	Meta *ResponseMeta `json:"meta,omitempty"`
+}
+
+// CodePermissionDenied means polkit refused the caller. The shell can offer
+// to retry, which brings up the authentication prompt again.
+const CodePermissionDenied = "PERMISSION_DENIED"
+
+// ResponseMeta is sent to clients that declare an API version, and to any
+// client calling a deprecated method
+type ResponseMeta struct {
+	APIVersion   int           `json:"apiVersion"`
+	Deprecations []Deprecation `json:"deprecations,omitempty"`
+}
+
+// Deprecation tells a client to migrate off a method before it is removed
+type Deprecation struct {
+	Method      string `json:"method"`
+	Replacement string `json:"replacement,omitempty"`
+	Since       int    `json:"since"`
+	Message     string `json:"message"`
+}
+
+// MetaConn attaches Meta to every response written with Respond or
+// RespondError for one request
+type MetaConn struct {
+	net.Conn
+	Meta ResponseMeta
+}
+
+func (c *MetaConn) Unwrap() net.Conn { return c.Conn }
+
+func metaFor(conn net.Conn) *ResponseMeta {
+	if mc, ok := conn.(*MetaConn); ok {
+		meta := mc.Meta
+		return &meta
+	}
+	return nil
 }
 
 func RespondError(conn net.Conn, id int, errMsg string) {
+	RespondErrorCode(conn, id, "", errMsg)
+}
+
+func RespondErrorCode(conn net.Conn, id int, code, errMsg string) {
 	log.Errorf("DMS API Error: id=%d error=%s", id, errMsg)
-	resp := Response[any]{ID: id, Error: errMsg}
+	resp := Response[any]{ID: id, Error: errMsg, Code: code, Meta: metaFor(conn)}
 	json.NewEncoder(conn).Encode(resp)
 }
 
 func Respond[T any](conn net.Conn, id int, result T) {
-	resp := Response[T]{ID: id, Result: &result}
+	resp := Response[T]{ID: id, Result: &result, Meta: metaFor(conn)}
 	json.NewEncoder(conn).Encode(resp)
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/godbus/dbus/v5"
@@ -291,7 +292,7 @@ func (a *SecretAgent) GetSecrets(
 	// If save=true, persist secrets in background after returning to NetworkManager
 	// This MUST happen after we return secrets, in a goroutine
 	if reply.Save {
-		go func() {
+		crash.Go("network.saveSecrets", func() {
 			log.Infof("[SecretAgent] Persisting secrets with Update2: path=%s, setting=%s", path, settingName)
 
 			// Get existing connection settings
@@ -391,7 +392,7 @@ func (a *SecretAgent) GetSecrets(
 			} else {
 				log.Infof("[SecretAgent] Successfully persisted secrets to disk for %s", settingName)
 			}
-		}()
+		})
 	}
 
 	return out, nil
@@ -0,0 +1,169 @@
+package network
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/netacct"
+)
+
+// AppUsageReport is the network.appUsage response
+type AppUsageReport struct {
+	Since time.Time  `json:"since"`
+	Apps  []AppUsage `json:"apps"`
+}
+
+// AppUsage is the traffic of one application since accounting started.
+// Applications are grouped by their systemd scope, which is how launchers
+// following the XDG app naming scheme run them; any other unit, such as the
+// session scope, is reported on its own.
+type AppUsage struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Units   []string `json:"units"`
+	PIDs    []int    `json:"pids"`
+	Active  bool     `json:"active"`
+	RxBytes uint64   `json:"rxBytes"`
+	TxBytes uint64   `json:"txBytes"`
+	RxRate  float64  `json:"rxRate"`
+	TxRate  float64  `json:"txRate"`
+}
+
+var cgroupRoot = netacct.CgroupRoot
+
+// errAppUsageUnavailable is returned while the root helper is not running.
+// nftables counters need CAP_NET_ADMIN, which the daemon does not have.
+var errAppUsageUnavailable = errors.New("per-application accounting is not running, enable it with 'dms netacct enable'")
+
+// GetAppUsage returns per-application traffic counted by the accounting
+// helper, busiest first
+func (m *Manager) GetAppUsage(limit int) (AppUsageReport, error) {
+	return readAppUsage(netacct.RuntimeDir, os.Getuid(), time.Now(), limit)
+}
+
+func readAppUsage(dir string, uid int, now time.Time, limit int) (AppUsageReport, error) {
+	snap, err := netacct.ReadSnapshot(dir, uid, now)
+	if errors.Is(err, os.ErrNotExist) {
+		return AppUsageReport{}, errAppUsageUnavailable
+	}
+	if err != nil {
+		return AppUsageReport{}, err
+	}
+	return AppUsageReport{Since: snap.Since, Apps: groupAppUsage(snap.Units, limit)}, nil
+}
+
+// groupAppUsage folds units into applications, so an app started twice
+// shows up once
+func groupAppUsage(units []netacct.UnitUsage, limit int) []AppUsage {
+	apps := make(map[string]*AppUsage)
+	for _, u := range units {
+		if u.RxBytes == 0 && u.TxBytes == 0 {
+			continue
+		}
+
+		id := appIDFromUnit(u.Unit)
+		name := id
+		if id == "" {
+			id = u.Unit
+			name = strings.TrimSuffix(strings.TrimSuffix(u.Unit, ".scope"), ".service")
+		}
+
+		app, ok := apps[id]
+		if !ok {
+			app = &AppUsage{ID: id, Name: name, Units: []string{}, PIDs: []int{}}
+			apps[id] = app
+		}
+		app.Units = append(app.Units, u.Unit)
+		app.RxBytes += u.RxBytes
+		app.TxBytes += u.TxBytes
+		app.RxRate += u.RxRate
+		app.TxRate += u.TxRate
+		if u.Active {
+			app.Active = true
+			app.PIDs = append(app.PIDs, cgroupPIDs(u.Path)...)
+		}
+	}
+
+	result := make([]AppUsage, 0, len(apps))
+	for _, app := range apps {
+		sort.Strings(app.Units)
+		sort.Ints(app.PIDs)
+		result = append(result, *app)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ri := result[i].RxRate + result[i].TxRate
+		rj := result[j].RxRate + result[j].TxRate
+		if ri != rj {
+			return ri > rj
+		}
+		ti := result[i].RxBytes + result[i].TxBytes
+		tj := result[j].RxBytes + result[j].TxBytes
+		if ti != tj {
+			return ti > tj
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// cgroupPIDs lists the processes in a unit's cgroup
+func cgroupPIDs(unitPath string) []int {
+	data, err := os.ReadFile(filepath.Join(cgroupRoot, unitPath, "cgroup.procs"))
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, line := range strings.Fields(string(data)) {
+		if pid, err := strconv.Atoi(line); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// appLaunchers are the launcher names systemd-style app scopes may carry
+// between "app-" and the application ID
+var appLaunchers = []string{"flatpak", "niri", "hyprland", "Hyprland", "uwsm", "gnome", "kde", "dms"}
+
+var scopeRandomSuffix = regexp.MustCompile(`-[0-9a-f]+$`)
+
+// appIDFromUnit extracts the application ID from units named like
+// app-niri-firefox-1234.scope or app-org.gnome.Nautilus@ab12.service
+func appIDFromUnit(unit string) string {
+	name, ok := strings.CutPrefix(unit, "app-")
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".scope"):
+		name = strings.TrimSuffix(name, ".scope")
+		name = scopeRandomSuffix.ReplaceAllString(name, "")
+	case strings.HasSuffix(name, ".service"):
+		name = strings.TrimSuffix(name, ".service")
+		if before, _, found := strings.Cut(name, "@"); found {
+			name = before
+		}
+	default:
+		return ""
+	}
+
+	for _, launcher := range appLaunchers {
+		if rest, ok := strings.CutPrefix(name, launcher+"-"); ok && rest != "" {
+			name = rest
+			break
+		}
+	}
+	return strings.ReplaceAll(name, `\x2d`, "-")
+}
@@ -0,0 +1,91 @@
+package network
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/netacct"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppIDFromUnit(t *testing.T) {
+	tests := map[string]string{
+		"app-niri-firefox-4123.scope":                          "firefox",
+		"app-flatpak-org.mozilla.firefox-889.scope":            "org.mozilla.firefox",
+		"app-org.gnome.Nautilus@ab12cd.service":                "org.gnome.Nautilus",
+		"app-Hyprland-kitty-12.scope":                          "kitty",
+		"app-dbus\\x2d:1.2\\x2dorg.freedesktop.portal.service": "dbus-:1.2-org.freedesktop.portal",
+		"session-2.scope":                                      "",
+		"dms.service":                                          "",
+		"":                                                     "",
+	}
+	for unit, want := range tests {
+		assert.Equal(t, want, appIDFromUnit(unit), unit)
+	}
+}
+
+func TestGroupAppUsage(t *testing.T) {
+	root := t.TempDir()
+	orig := cgroupRoot
+	cgroupRoot = root
+	defer func() { cgroupRoot = orig }()
+
+	firefox := "user.slice/user-1000.slice/user@1000.service/app.slice/app-niri-firefox-42.scope"
+	require.NoError(t, os.MkdirAll(filepath.Join(root, firefox), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, firefox, "cgroup.procs"), []byte("42\n57\n"), 0644))
+
+	units := []netacct.UnitUsage{
+		{Unit: "app-niri-firefox-42.scope", Path: firefox, Active: true, RxBytes: 8000, TxBytes: 600, RxRate: 4000},
+		{Unit: "app-niri-firefox-9.scope", Path: "gone", RxBytes: 1000},
+		{Unit: "session-2.scope", Path: "user.slice/user-1000.slice/session-2.scope", Active: true, RxBytes: 50000},
+		{Unit: "app-niri-foot-3.scope", Path: "idle", Active: true},
+	}
+
+	usage := groupAppUsage(units, 0)
+	require.Len(t, usage, 2)
+
+	assert.Equal(t, "firefox", usage[0].ID)
+	assert.Equal(t, []string{"app-niri-firefox-42.scope", "app-niri-firefox-9.scope"}, usage[0].Units)
+	assert.Equal(t, []int{42, 57}, usage[0].PIDs)
+	assert.True(t, usage[0].Active)
+	assert.Equal(t, uint64(9000), usage[0].RxBytes)
+	assert.InDelta(t, 4000.0, usage[0].RxRate, 0.01)
+
+	// Units outside an app scope are reported as themselves
+	assert.Equal(t, "session-2.scope", usage[1].ID)
+	assert.Equal(t, "session-2", usage[1].Name)
+	assert.Empty(t, usage[1].PIDs)
+
+	assert.Len(t, groupAppUsage(units, 1), 1)
+}
+
+func TestReadAppUsage(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	_, err := readAppUsage(dir, 1000, now, 0)
+	assert.ErrorIs(t, err, errAppUsageUnavailable)
+
+	snap := netacct.Snapshot{
+		Since:     now.Add(-time.Hour),
+		UpdatedAt: now,
+		Units:     []netacct.UnitUsage{{Unit: "session-2.scope", RxBytes: 10}},
+	}
+	data, err := json.Marshal(snap)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(netacct.SnapshotPath(dir, 1000), data, 0600))
+
+	report, err := readAppUsage(dir, 1000, now, 0)
+	require.NoError(t, err)
+	assert.Equal(t, snap.Since.Unix(), report.Since.Unix())
+	require.Len(t, report.Apps, 1)
+	assert.Equal(t, "session-2.scope", report.Apps[0].ID)
+
+	// A helper that stopped leaves its last snapshot behind
+	_, err = readAppUsage(dir, 1000, now.Add(time.Minute), 0)
+	assert.ErrorIs(t, err, errAppUsageUnavailable)
+}
@@ -14,6 +14,8 @@ type Backend interface {
 	DisconnectWiFi() error
 	ForgetWiFiNetwork(ssid string) error
 	SetWiFiAutoconnect(ssid string, autoconnect bool) error
+	GetWiFiRoaming(ssid string) (*WiFiRoaming, error)
+	SetWiFiRoaming(prefs WiFiRoaming) error
 
 	GetWiredConnections() ([]WiredConnection, error)
 	GetWiredNetworkDetails(uuid string) (*WiredNetworkInfoResponse, error)
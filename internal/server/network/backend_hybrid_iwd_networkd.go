@@ -196,3 +196,15 @@ func (b *HybridIwdNetworkdBackend) CancelCredentials(token string) error {
 func (b *HybridIwdNetworkdBackend) SetWiFiAutoconnect(ssid string, autoconnect bool) error {
 	return b.wifi.SetWiFiAutoconnect(ssid, autoconnect)
 }
+
+func (b *HybridIwdNetworkdBackend) GetWiFiRoaming(ssid string) (*WiFiRoaming, error) {
+	return b.wifi.GetWiFiRoaming(ssid)
+}
+
+func (b *HybridIwdNetworkdBackend) SetWiFiRoaming(prefs WiFiRoaming) error {
+	return b.wifi.SetWiFiRoaming(prefs)
+}
+
+func (b *HybridIwdNetworkdBackend) ActivateInterface(iface string) error {
+	return b.l3.ActivateInterface(iface)
+}
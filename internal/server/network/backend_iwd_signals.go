@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/godbus/dbus/v5"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
 )
 
 func (b *IWDBackend) StartMonitoring(onStateChange func()) error {
@@ -46,7 +48,7 @@ func (b *IWDBackend) StartMonitoring(onStateChange func()) error {
 	}
 
 	b.sigWG.Add(1)
-	go b.signalHandler(sigChan)
+	crash.Go("network.iwdSignals", func() { b.signalHandler(sigChan) })
 
 	return nil
 }
@@ -239,7 +241,8 @@ func (b *IWDBackend) signalHandler(sigChan chan *dbus.Signal) {
 								stateChanged = true
 
 								if att != nil && isTarget {
-									go func(attLocal *connectAttempt, tgt dbus.ObjectPath) {
+									attLocal, tgt := att, targetPath
+									crash.Go("network.iwdConnectCheck", func() {
 										time.Sleep(3 * time.Second)
 										station := b.conn.Object(iwdBusName, b.stationPath)
 										var nowState string
@@ -259,7 +262,7 @@ func (b *IWDBackend) signalHandler(sigChan chan *dbus.Signal) {
 											}
 											b.attemptMutex.Unlock()
 										}
-									}(att, targetPath)
+									})
 								}
 
 							case "disconnecting", "disconnected":
@@ -45,3 +45,13 @@ func (b *IWDBackend) DisconnectAllVPN() error {
 func (b *IWDBackend) ClearVPNCredentials(uuidOrName string) error {
 	return fmt.Errorf("VPN not supported by iwd backend")
 }
+
+// iwd keeps roaming thresholds global in main.conf and has no per-network
+// BSSID or band settings
+func (b *IWDBackend) GetWiFiRoaming(ssid string) (*WiFiRoaming, error) {
+	return nil, fmt.Errorf("per-network roaming not supported by iwd backend")
+}
+
+func (b *IWDBackend) SetWiFiRoaming(prefs WiFiRoaming) error {
+	return fmt.Errorf("per-network roaming not supported by iwd backend")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/godbus/dbus/v5"
 )
@@ -399,7 +400,7 @@ func (b *IWDBackend) finalizeAttempt(att *connectAttempt, code string) {
 
 func (b *IWDBackend) startAttemptWatchdog(att *connectAttempt) {
 	b.sigWG.Add(1)
-	go func() {
+	crash.Go("network.iwdAttemptWatchdog", func() {
 		defer b.sigWG.Done()
 
 		ticker := time.NewTicker(250 * time.Millisecond)
@@ -448,7 +449,7 @@ func (b *IWDBackend) startAttemptWatchdog(att *connectAttempt) {
 				return
 			}
 		}
-	}()
+	})
 }
 
 func (b *IWDBackend) mapIwdDBusError(name string) string {
@@ -510,7 +511,7 @@ func (b *IWDBackend) ConnectWiFi(req ConnectionRequest) error {
 	}
 
 	netObj := b.conn.Object(iwdBusName, networkPath)
-	go func() {
+	crash.Go("network.iwdConnect", func() {
 		call := netObj.Call(iwdNetworkInterface+".Connect", 0)
 		if call.Err != nil {
 			var code string
@@ -533,7 +534,7 @@ func (b *IWDBackend) ConnectWiFi(req ConnectionRequest) error {
 		}
 
 		b.startAttemptWatchdog(att)
-	}()
+	})
 
 	return nil
 }
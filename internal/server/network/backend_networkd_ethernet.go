@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net"
 	"strings"
+
+	"github.com/godbus/dbus/v5"
 )
 
 func (b *SystemdNetworkdBackend) GetWiredConnections() ([]WiredConnection, error) {
@@ -108,3 +110,18 @@ func (b *SystemdNetworkdBackend) ActivateWiredConnection(id string) error {
 	linkObj := b.conn.Object(networkdBusName, link.path)
 	return linkObj.Call(networkdLinkIface+".Reconfigure", 0).Err
 }
+
+// ActivateInterface reconfigures iface, which starts DHCP when a .network
+// file matches it. The link is looked up by name since a phone's interface
+// may be newer than the links networkd last reported.
+func (b *SystemdNetworkdBackend) ActivateInterface(iface string) error {
+	var index int32
+	var path dbus.ObjectPath
+	managerObj := b.conn.Object(networkdBusName, networkdManagerPath)
+	if err := managerObj.Call(networkdManagerIface+".GetLinkByName", 0, iface).Store(&index, &path); err != nil {
+		return fmt.Errorf("networkd has no link %s: %w", iface, err)
+	}
+
+	linkObj := b.conn.Object(networkdBusName, path)
+	return linkObj.Call(networkdLinkIface+".Reconfigure", 0).Err
+}
@@ -3,6 +3,7 @@ package network
 import (
 	"fmt"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/godbus/dbus/v5"
 )
@@ -25,7 +26,7 @@ func (b *SystemdNetworkdBackend) StartMonitoring(onStateChange func()) error {
 	}
 
 	b.sigWG.Add(1)
-	go b.signalLoop()
+	crash.Go("network.networkdSignals", b.signalLoop)
 
 	return nil
 }
@@ -57,3 +57,11 @@ func (b *SystemdNetworkdBackend) ClearVPNCredentials(uuidOrName string) error {
 func (b *SystemdNetworkdBackend) SetWiFiAutoconnect(ssid string, autoconnect bool) error {
 	return fmt.Errorf("WiFi autoconnect not supported by networkd backend")
 }
+
+func (b *SystemdNetworkdBackend) GetWiFiRoaming(ssid string) (*WiFiRoaming, error) {
+	return nil, fmt.Errorf("WiFi roaming not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) SetWiFiRoaming(prefs WiFiRoaming) error {
+	return fmt.Errorf("WiFi roaming not supported by networkd backend")
+}
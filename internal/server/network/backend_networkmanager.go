@@ -3,6 +3,7 @@ package network
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/Wifx/gonetworkmanager/v2"
@@ -52,6 +53,10 @@ type NetworkManagerBackend struct {
 	lastFailedTime int64
 	failedMutex    sync.RWMutex
 
+	lastRoamCheck time.Time
+	lastRoam      time.Time
+	roamMutex     sync.Mutex
+
 	onStateChange func()
 }
 
@@ -0,0 +1,235 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// NetworkManager has no band preference or roaming threshold of its own,
+// so both are kept in the connection's user data and enforced by the server
+const (
+	nmUserDataBand    = "dms.wifi-band"
+	nmUserDataRoaming = "dms.wifi-roaming"
+)
+
+func (b *NetworkManagerBackend) GetWiFiRoaming(ssid string) (*WiFiRoaming, error) {
+	conn, err := b.findConnection(ssid)
+	if err != nil {
+		return nil, fmt.Errorf("connection not found: %w", err)
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	prefs := roamingFromSettings(ssid, settings)
+	return &prefs, nil
+}
+
+func (b *NetworkManagerBackend) SetWiFiRoaming(prefs WiFiRoaming) error {
+	conn, err := b.findConnection(prefs.SSID)
+	if err != nil {
+		return fmt.Errorf("connection not found: %w", err)
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	if err := applyRoamingSettings(settings, prefs); err != nil {
+		return err
+	}
+
+	if ipv4, ok := settings["ipv4"]; ok {
+		delete(ipv4, "addresses")
+		delete(ipv4, "routes")
+		delete(ipv4, "dns")
+	}
+
+	if ipv6, ok := settings["ipv6"]; ok {
+		delete(ipv6, "addresses")
+		delete(ipv6, "routes")
+		delete(ipv6, "dns")
+	}
+
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+
+	// Settings only apply on the next activation, so move a live
+	// connection now rather than waiting for it to drop
+	b.checkRoaming(true)
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func roamingFromSettings(ssid string, settings gonetworkmanager.ConnectionSettings) WiFiRoaming {
+	prefs := WiFiRoaming{SSID: ssid, Band: BandAuto, Roaming: RoamingDefault}
+
+	if wifi, ok := settings["802-11-wireless"]; ok {
+		if bssid, ok := wifi["bssid"].([]byte); ok && len(bssid) == 6 {
+			prefs.BSSID = strings.ToUpper(net.HardwareAddr(bssid).String())
+		}
+	}
+
+	if user, ok := settings["user"]; ok {
+		if data, ok := user["data"].(map[string]string); ok {
+			if band := WiFiBand(data[nmUserDataBand]); band != "" {
+				prefs.Band = band
+			}
+			if roaming := RoamingMode(data[nmUserDataRoaming]); roaming != "" {
+				prefs.Roaming = roaming
+			}
+		}
+	}
+
+	return prefs
+}
+
+func applyRoamingSettings(settings gonetworkmanager.ConnectionSettings, prefs WiFiRoaming) error {
+	wifi, ok := settings["802-11-wireless"]
+	if !ok {
+		return fmt.Errorf("wireless settings not found")
+	}
+
+	if prefs.BSSID != "" {
+		mac, err := net.ParseMAC(prefs.BSSID)
+		if err != nil {
+			return fmt.Errorf("invalid bssid: %s", prefs.BSSID)
+		}
+		wifi["bssid"] = []byte(mac)
+	} else {
+		delete(wifi, "bssid")
+	}
+
+	data := map[string]string{}
+	if user, ok := settings["user"]; ok {
+		if existing, ok := user["data"].(map[string]string); ok {
+			for k, v := range existing {
+				data[k] = v
+			}
+		}
+	}
+	delete(data, nmUserDataBand)
+	delete(data, nmUserDataRoaming)
+	if prefs.Band != "" && prefs.Band != BandAuto {
+		data[nmUserDataBand] = string(prefs.Band)
+	}
+	if prefs.Roaming != "" && prefs.Roaming != RoamingDefault {
+		data[nmUserDataRoaming] = string(prefs.Roaming)
+	}
+
+	if len(data) == 0 {
+		delete(settings, "user")
+	} else {
+		settings["user"] = map[string]interface{}{"data": data}
+	}
+
+	return nil
+}
+
+// checkRoaming moves the active connection to a better AP of the same
+// network when its preferences ask for it. Signal changes arrive often, so
+// unless forced it runs at most every roamCheckInterval and never within
+// roamCooldown of the last move.
+func (b *NetworkManagerBackend) checkRoaming(force bool) {
+	now := time.Now()
+
+	b.roamMutex.Lock()
+	if !force && (now.Sub(b.lastRoamCheck) < roamCheckInterval || now.Sub(b.lastRoam) < roamCooldown) {
+		b.roamMutex.Unlock()
+		return
+	}
+	b.lastRoamCheck = now
+	b.roamMutex.Unlock()
+
+	b.stateMutex.RLock()
+	connected := b.state.WiFiConnected
+	ssid := b.state.WiFiSSID
+	current := WiFiNetwork{SSID: ssid, BSSID: b.state.WiFiBSSID, Signal: b.state.WiFiSignal}
+	b.stateMutex.RUnlock()
+
+	if !connected || ssid == "" || b.wifiDevice == nil {
+		return
+	}
+
+	conn, err := b.findConnection(ssid)
+	if err != nil {
+		return
+	}
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return
+	}
+	prefs := roamingFromSettings(ssid, settings)
+
+	details, err := b.GetWiFiNetworkDetails(ssid)
+	if err != nil {
+		return
+	}
+	for _, ap := range details.Bands {
+		if strings.EqualFold(ap.BSSID, current.BSSID) {
+			current.Frequency = ap.Frequency
+		}
+	}
+
+	var target WiFiNetwork
+	switch {
+	case prefs.BSSID != "":
+		if strings.EqualFold(prefs.BSSID, current.BSSID) {
+			return
+		}
+		target = WiFiNetwork{BSSID: prefs.BSSID}
+	default:
+		var ok bool
+		if target, ok = pickRoamTarget(current, details.Bands, prefs); !ok {
+			return
+		}
+	}
+
+	if err := b.activateOnAccessPoint(conn, target.BSSID); err != nil {
+		log.Warnf("[Roaming] Failed to move %s to %s: %v", ssid, target.BSSID, err)
+		return
+	}
+
+	log.Infof("[Roaming] Moved %s from %s to %s", ssid, current.BSSID, target.BSSID)
+	b.roamMutex.Lock()
+	b.lastRoam = now
+	b.roamMutex.Unlock()
+}
+
+func (b *NetworkManagerBackend) activateOnAccessPoint(conn gonetworkmanager.Connection, bssid string) error {
+	if err := b.ensureWiFiDevice(); err != nil {
+		return err
+	}
+
+	w := b.wifiDev.(gonetworkmanager.DeviceWireless)
+	aps, err := w.GetAccessPoints()
+	if err != nil {
+		return fmt.Errorf("failed to get access points: %w", err)
+	}
+
+	for _, ap := range aps {
+		hw, err := ap.GetPropertyHWAddress()
+		if err != nil || !strings.EqualFold(hw, bssid) {
+			continue
+		}
+		nm := b.nmConn.(gonetworkmanager.NetworkManager)
+		dev := b.wifiDevice.(gonetworkmanager.Device)
+		_, err = nm.ActivateWirelessConnection(conn, dev, ap)
+		return err
+	}
+
+	return fmt.Errorf("access point %s is not in range", bssid)
+}
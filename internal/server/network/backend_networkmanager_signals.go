@@ -3,6 +3,8 @@ package network
 import (
 	"github.com/Wifx/gonetworkmanager/v2"
 	"github.com/godbus/dbus/v5"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
 )
 
 func (b *NetworkManagerBackend) startSignalPump() error {
@@ -106,7 +108,7 @@ func (b *NetworkManagerBackend) startSignalPump() error {
 	}
 
 	b.sigWG.Add(1)
-	go func() {
+	crash.Go("network.nmSignals", func() {
 		defer b.sigWG.Done()
 		for {
 			select {
@@ -122,7 +124,7 @@ func (b *NetworkManagerBackend) startSignalPump() error {
 				b.handleDBusSignal(sig)
 			}
 		}
-	}()
+	})
 	return nil
 }
 
@@ -318,4 +320,6 @@ func (b *NetworkManagerBackend) handleAccessPointChange(changes map[string]dbus.
 			b.onStateChange()
 		}
 	}
+
+	b.checkRoaming(false)
 }
@@ -0,0 +1,127 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// ActivateInterface activates the profile bound to iface, creating one the
+// first time so the phone reconnects the same way when plugged in again
+func (b *NetworkManagerBackend) ActivateInterface(iface string) error {
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+
+	dev, err := nm.GetDeviceByIpIface(iface)
+	if err != nil {
+		return fmt.Errorf("NetworkManager has no device %s: %w", iface, err)
+	}
+
+	settingsMgr, err := gonetworkmanager.NewSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get settings: %w", err)
+	}
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	for _, conn := range connections {
+		connSettings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		if name, _ := connSettings["connection"]["interface-name"].(string); name == iface {
+			if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+				return fmt.Errorf("failed to activate %s: %w", iface, err)
+			}
+			return nil
+		}
+	}
+
+	settings := map[string]map[string]interface{}{
+		"connection": {
+			"id":             "USB tethering (" + iface + ")",
+			"type":           "802-3-ethernet",
+			"interface-name": iface,
+		},
+	}
+	if _, err := nm.AddAndActivateConnection(settings, dev); err != nil {
+		return fmt.Errorf("failed to create tethering connection for %s: %w", iface, err)
+	}
+	return nil
+}
+
+// ActivateBluetoothPAN activates the PAN profile of the phone at address,
+// creating it the first time. NetworkManager connects the phone through
+// bluez itself.
+func (b *NetworkManagerBackend) ActivateBluetoothPAN(address, name string) error {
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+
+	hw, err := net.ParseMAC(address)
+	if err != nil {
+		return fmt.Errorf("invalid Bluetooth address %s: %w", address, err)
+	}
+
+	devices, err := nm.GetDevices()
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+	var btDevice gonetworkmanager.Device
+	for _, dev := range devices {
+		devType, err := dev.GetPropertyDeviceType()
+		if err != nil || devType != gonetworkmanager.NmDeviceTypeBt {
+			continue
+		}
+		// A Bluetooth device's interface is the phone's address
+		if iface, err := dev.GetPropertyInterface(); err == nil && strings.EqualFold(iface, address) {
+			btDevice = dev
+			break
+		}
+	}
+	if btDevice == nil {
+		return fmt.Errorf("NetworkManager does not offer %s for tethering; pair it and turn on Bluetooth tethering on the phone", name)
+	}
+
+	settingsMgr, err := gonetworkmanager.NewSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get settings: %w", err)
+	}
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	for _, conn := range connections {
+		connSettings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		if connType, _ := connSettings["connection"]["type"].(string); connType != "bluetooth" {
+			continue
+		}
+		if bdaddr, _ := connSettings["bluetooth"]["bdaddr"].([]byte); bytes.Equal(bdaddr, hw) {
+			if _, err := nm.ActivateConnection(conn, btDevice, nil); err != nil {
+				return fmt.Errorf("failed to connect to %s: %w", name, err)
+			}
+			return nil
+		}
+	}
+
+	settings := map[string]map[string]interface{}{
+		"connection": {
+			"id":   name + " Network",
+			"type": "bluetooth",
+		},
+		"bluetooth": {
+			"bdaddr": []byte(hw),
+			"type":   "panu",
+		},
+	}
+	if _, err := nm.AddAndActivateConnection(settings, btDevice); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", name, err)
+	}
+	return nil
+}
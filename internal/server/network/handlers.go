@@ -1,6 +1,7 @@
 package network
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -72,6 +73,22 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleClearVPNCredentials(conn, req, manager)
 	case "network.wifi.setAutoconnect":
 		handleSetWiFiAutoconnect(conn, req, manager)
+	case "network.wifi.getRoaming":
+		handleGetWiFiRoaming(conn, req, manager)
+	case "network.wifi.setRoaming":
+		handleSetWiFiRoaming(conn, req, manager)
+	case "network.appUsage":
+		handleAppUsage(conn, req, manager)
+	case "network.speedTest":
+		handleSpeedTest(conn, req, manager)
+	case "network.linkHistory":
+		handleLinkHistory(conn, req, manager)
+	case "network.tether.list":
+		handleListTether(conn, req, manager)
+	case "network.tether.connect":
+		handleTether(conn, req, manager.ConnectTether, "connected")
+	case "network.tether.disconnect":
+		handleTether(conn, req, manager.DisconnectTether, "disconnected")
 	default:
 		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
 	}
@@ -347,6 +364,7 @@ func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
 			Data: state,
 		}
 		if err := json.NewEncoder(conn).Encode(models.Response[NetworkEvent]{
+			ID:     req.ID,
 			Result: &event,
 		}); err != nil {
 			return
@@ -485,3 +503,128 @@ func handleSetWiFiAutoconnect(conn net.Conn, req Request, manager *Manager) {
 
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "autoconnect updated"})
 }
+
+func handleGetWiFiRoaming(conn net.Conn, req Request, manager *Manager) {
+	ssid, ok := req.Params["ssid"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'ssid' parameter")
+		return
+	}
+
+	prefs, err := manager.GetWiFiRoaming(ssid)
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to get roaming settings: %v", err))
+		return
+	}
+
+	models.Respond(conn, req.ID, prefs)
+}
+
+// handleSetWiFiRoaming only changes the settings present in params, so
+// pinning a BSSID keeps the band and roaming choices
+func handleSetWiFiRoaming(conn net.Conn, req Request, manager *Manager) {
+	ssid, ok := req.Params["ssid"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'ssid' parameter")
+		return
+	}
+
+	current, err := manager.GetWiFiRoaming(ssid)
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to get roaming settings: %v", err))
+		return
+	}
+	prefs := *current
+
+	for _, key := range []string{"bssid", "band", "roaming"} {
+		raw, present := req.Params[key]
+		if !present {
+			continue
+		}
+		value, ok := raw.(string)
+		if !ok {
+			models.RespondError(conn, req.ID, fmt.Sprintf("invalid '%s' parameter", key))
+			return
+		}
+		switch key {
+		case "bssid":
+			prefs.BSSID = value
+		case "band":
+			prefs.Band = WiFiBand(value)
+		case "roaming":
+			prefs.Roaming = RoamingMode(value)
+		}
+	}
+
+	if err := manager.SetWiFiRoaming(prefs); err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to set roaming settings: %v", err))
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "roaming settings updated"})
+}
+
+func handleAppUsage(conn net.Conn, req Request, manager *Manager) {
+	limit := 0
+	if raw, present := req.Params["limit"]; present {
+		value, ok := raw.(float64)
+		if !ok || value < 0 {
+			models.RespondError(conn, req.ID, "missing or invalid 'limit' parameter")
+			return
+		}
+		limit = int(value)
+	}
+
+	report, err := manager.GetAppUsage(limit)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, report)
+}
+
+func handleSpeedTest(conn net.Conn, req Request, manager *Manager) {
+	result, err := manager.RunSpeedTest(context.Background(), nil)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
+func handleLinkHistory(conn net.Conn, req Request, manager *Manager) {
+	limit := 0
+	if raw, present := req.Params["limit"]; present {
+		value, ok := raw.(float64)
+		if !ok || value < 0 {
+			models.RespondError(conn, req.ID, "missing or invalid 'limit' parameter")
+			return
+		}
+		limit = int(value)
+	}
+
+	models.Respond(conn, req.ID, manager.GetLinkHistory(limit))
+}
+
+func handleListTether(conn net.Conn, req Request, manager *Manager) {
+	devices, err := manager.GetTetherDevices()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, devices)
+}
+
+func handleTether(conn net.Conn, req Request, action func(id string) error, message string) {
+	id, ok := req.Params["id"].(string)
+	if !ok || id == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+
+	if err := action(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: message})
+}
@@ -0,0 +1,54 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// healthChecker is implemented by backends that can tell whether their
+// daemon and D-Bus connection still respond
+type healthChecker interface {
+	HealthCheck() error
+}
+
+// HealthCheck reports whether the network backend still responds
+func (m *Manager) HealthCheck() error {
+	if checker, ok := m.backend.(healthChecker); ok {
+		return checker.HealthCheck()
+	}
+	return nil
+}
+
+func (b *NetworkManagerBackend) HealthCheck() error {
+	if b.dbusConn != nil {
+		if err := utils.PingDBus(b.dbusConn, "org.freedesktop.NetworkManager"); err != nil {
+			return err
+		}
+	}
+
+	nm, ok := b.nmConn.(gonetworkmanager.NetworkManager)
+	if !ok {
+		return nil
+	}
+	if _, err := nm.GetPropertyState(); err != nil {
+		return fmt.Errorf("NetworkManager not responding: %w", err)
+	}
+	return nil
+}
+
+func (b *IWDBackend) HealthCheck() error {
+	return utils.PingDBus(b.conn, iwdBusName)
+}
+
+func (b *SystemdNetworkdBackend) HealthCheck() error {
+	return utils.PingDBus(b.conn, networkdBusName)
+}
+
+func (b *HybridIwdNetworkdBackend) HealthCheck() error {
+	if err := b.wifi.HealthCheck(); err != nil {
+		return err
+	}
+	return b.l3.HealthCheck()
+}
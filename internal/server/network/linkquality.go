@@ -0,0 +1,167 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	linkSampleInterval = 2 * time.Second
+	// linkHistorySize keeps the last half hour of samples
+	linkHistorySize = 900
+)
+
+// LinkSample is one reading of the Wi-Fi link. Retries and failures are
+// counted since the previous sample.
+type LinkSample struct {
+	Time      time.Time `json:"time"`
+	Interface string    `json:"interface"`
+	BSSID     string    `json:"bssid"`
+	Signal    int       `json:"signal"`
+	SignalAvg int       `json:"signalAvg,omitempty"`
+	TxBitrate float64   `json:"txBitrate"`
+	RxBitrate float64   `json:"rxBitrate"`
+	TxRetries uint32    `json:"txRetries"`
+	TxFailed  uint32    `json:"txFailed"`
+}
+
+// LinkHistory is returned by network.linkHistory, oldest sample first
+type LinkHistory struct {
+	IntervalMs int64            `json:"intervalMs"`
+	Samples    []LinkSample     `json:"samples"`
+	SpeedTest  *SpeedTestResult `json:"speedTest,omitempty"`
+}
+
+// linkQualitySampler reads the station info of the connected Wi-Fi
+// interface on a fixed interval into a ring buffer. Nothing is recorded
+// while Wi-Fi is disconnected, so gaps in the graph are real gaps.
+type linkQualitySampler struct {
+	mu      sync.Mutex
+	read    func(iface string) (stationInfo, error)
+	samples []LinkSample
+	next    int
+	full    bool
+	last    *stationInfo
+	lastErr string
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+func newLinkQualitySampler(read func(iface string) (stationInfo, error), size int) *linkQualitySampler {
+	return &linkQualitySampler{
+		read:     read,
+		samples:  make([]LinkSample, size),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// run samples until stopped. link reports the Wi-Fi interface and whether
+// it is connected.
+func (s *linkQualitySampler) run(link func() (string, bool)) {
+	ticker := time.NewTicker(linkSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case now := <-ticker.C:
+			iface, connected := link()
+			if !connected || iface == "" {
+				s.mu.Lock()
+				s.last = nil
+				s.mu.Unlock()
+				continue
+			}
+			s.sample(iface, now)
+		}
+	}
+}
+
+func (s *linkQualitySampler) sample(iface string, now time.Time) {
+	info, err := s.read(iface)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		if err.Error() != s.lastErr {
+			log.Debugf("Link quality sample failed: %v", err)
+			s.lastErr = err.Error()
+		}
+		s.last = nil
+		return
+	}
+	s.lastErr = ""
+
+	sample := LinkSample{
+		Time:      now,
+		Interface: iface,
+		BSSID:     info.BSSID,
+		Signal:    info.Signal,
+		SignalAvg: info.SignalAvg,
+		TxBitrate: info.TxBitrate,
+		RxBitrate: info.RxBitrate,
+	}
+	// Counters restart on reassociation, including roaming to another AP
+	if s.last != nil && s.last.BSSID == info.BSSID && info.TxRetries >= s.last.TxRetries && info.TxFailed >= s.last.TxFailed {
+		sample.TxRetries = info.TxRetries - s.last.TxRetries
+		sample.TxFailed = info.TxFailed - s.last.TxFailed
+	}
+	s.last = &info
+
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % len(s.samples)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// history returns up to limit of the newest samples, oldest first. A limit
+// of zero returns everything kept.
+func (s *linkQualitySampler) history(limit int) []LinkSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ordered := make([]LinkSample, 0, len(s.samples))
+	if s.full {
+		ordered = append(ordered, s.samples[s.next:]...)
+	}
+	ordered = append(ordered, s.samples[:s.next]...)
+
+	if limit > 0 && len(ordered) > limit {
+		ordered = ordered[len(ordered)-limit:]
+	}
+	return ordered
+}
+
+func (s *linkQualitySampler) stop() {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+}
+
+func (m *Manager) wifiLink() (string, bool) {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state.WiFiDevice, m.state.WiFiConnected
+}
+
+// GetLinkHistory returns the recent Wi-Fi link samples and the last speed
+// test result
+func (m *Manager) GetLinkHistory(limit int) LinkHistory {
+	history := LinkHistory{
+		IntervalMs: linkSampleInterval.Milliseconds(),
+		Samples:    []LinkSample{},
+	}
+	if m.linkQuality != nil {
+		history.Samples = m.linkQuality.history(limit)
+	}
+
+	m.speedTestMutex.Lock()
+	history.SpeedTest = m.lastSpeedTest
+	m.speedTestMutex.Unlock()
+
+	return history
+}
@@ -0,0 +1,124 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func u32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func TestParseStation(t *testing.T) {
+	txRate := netlinkAttr(unix.NL80211_RATE_INFO_BITRATE32, u32(8667))
+	rxRate := netlinkAttr(unix.NL80211_RATE_INFO_BITRATE32, u32(7800))
+	var sta []byte
+	sta = append(sta, netlinkAttr(unix.NL80211_STA_INFO_SIGNAL, []byte{byte(0xca)})...) // -54
+	sta = append(sta, netlinkAttr(unix.NL80211_STA_INFO_SIGNAL_AVG, []byte{byte(0xc9)})...)
+	sta = append(sta, netlinkAttr(unix.NL80211_STA_INFO_TX_BITRATE|unix.NLA_F_NESTED, txRate)...)
+	sta = append(sta, netlinkAttr(unix.NL80211_STA_INFO_RX_BITRATE|unix.NLA_F_NESTED, rxRate)...)
+	sta = append(sta, netlinkAttr(unix.NL80211_STA_INFO_TX_RETRIES, u32(12))...)
+	sta = append(sta, netlinkAttr(unix.NL80211_STA_INFO_TX_FAILED, u32(1))...)
+
+	var msg []byte
+	msg = append(msg, netlinkAttr(unix.NL80211_ATTR_MAC, []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff})...)
+	msg = append(msg, netlinkAttr(unix.NL80211_ATTR_STA_INFO|unix.NLA_F_NESTED, sta)...)
+
+	info, ok := parseStation(parseNetlinkAttrs(msg))
+	require.True(t, ok)
+	assert.Equal(t, stationInfo{
+		BSSID:     "aa:bb:cc:dd:ee:ff",
+		Signal:    -54,
+		SignalAvg: -55,
+		TxBitrate: 866.7,
+		RxBitrate: 780,
+		TxRetries: 12,
+		TxFailed:  1,
+	}, info)
+
+	_, ok = parseStation(parseNetlinkAttrs(netlinkAttr(unix.NL80211_ATTR_IFINDEX, u32(3))))
+	assert.False(t, ok)
+}
+
+func TestLinkQualitySampler(t *testing.T) {
+	readings := []stationInfo{
+		{BSSID: "ap1", Signal: -50, TxRetries: 10},
+		{BSSID: "ap1", Signal: -52, TxRetries: 14, TxFailed: 1},
+		{BSSID: "ap2", Signal: -60, TxRetries: 2},
+		{BSSID: "ap2", Signal: -61, TxRetries: 5},
+	}
+	i := 0
+	s := newLinkQualitySampler(func(iface string) (stationInfo, error) {
+		if i >= len(readings) {
+			return stationInfo{}, errors.New("not associated")
+		}
+		i++
+		return readings[i-1], nil
+	}, 3)
+
+	start := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	for n := 0; n < 5; n++ {
+		s.sample("wlan0", start.Add(time.Duration(n)*linkSampleInterval))
+	}
+
+	samples := s.history(0)
+	require.Len(t, samples, 3, "ring buffer keeps the newest")
+	assert.Equal(t, -52, samples[0].Signal)
+	assert.Equal(t, uint32(4), samples[0].TxRetries)
+	assert.Equal(t, uint32(1), samples[0].TxFailed)
+	assert.Equal(t, uint32(0), samples[1].TxRetries, "counters restart after roaming")
+	assert.Equal(t, uint32(3), samples[2].TxRetries)
+	assert.Equal(t, "wlan0", samples[2].Interface)
+
+	assert.Equal(t, []LinkSample{samples[2]}, s.history(1))
+	assert.NotNil(t, newLinkQualitySampler(nil, 3).history(0))
+}
+
+func TestRunSpeedTest(t *testing.T) {
+	var uploaded int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead:
+		case r.Method == http.MethodGet && r.URL.Path == "/down":
+			io.WriteString(w, strings.Repeat("x", 1<<20))
+		case r.Method == http.MethodPost && r.URL.Path == "/up":
+			uploaded, _ = io.Copy(io.Discard, r.Body)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var phases []string
+	progress := func(done float64, phase string) { phases = append(phases, phase) }
+	result, err := runSpeedTest(context.Background(), server.Client(), server.URL+"/down", server.URL+"/up", progress)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"latency", "download", "upload"}, phases)
+	assert.Empty(t, result.Warnings)
+	assert.Equal(t, int64(1<<20), result.DownloadBytes)
+	assert.Greater(t, result.DownloadMbps, 0.0)
+	assert.Equal(t, int64(speedTestUploadSize), uploaded)
+	assert.Greater(t, result.UploadMbps, 0.0)
+	assert.Greater(t, result.LatencyMs, 0.0)
+
+	result, err = runSpeedTest(context.Background(), server.Client(), server.URL+"/down", server.URL+"/missing", nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Warnings, 1)
+	assert.Zero(t, result.UploadMbps)
+
+	_, err = runSpeedTest(context.Background(), server.Client(), server.URL+"/missing", "", nil)
+	assert.Error(t, err)
+}
@@ -5,7 +5,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
 )
 
 func NewManager() (*Manager, error) {
@@ -89,7 +91,10 @@ func NewManager() (*Manager, error) {
 	}
 
 	m.notifierWg.Add(1)
-	go m.notifier()
+	crash.Go("network.notifier", m.notifier)
+
+	m.linkQuality = newLinkQualitySampler(readStationInfo, linkHistorySize)
+	crash.Go("network.linkQuality", func() { m.linkQuality.run(m.wifiLink) })
 
 	if err := backend.StartMonitoring(m.onBackendStateChange); err != nil {
 		m.Close()
@@ -372,6 +377,13 @@ func (m *Manager) notifySubscribers() {
 	}
 }
 
+// SetPromptRelay files secret prompts with the daemon's prompts module too
+func (m *Manager) SetPromptRelay(relay *prompts.Manager) {
+	if broker, ok := m.backend.GetPromptBroker().(*SubscriptionBroker); ok {
+		broker.SetRelay(relay)
+	}
+}
+
 func (m *Manager) SetPromptBroker(broker PromptBroker) error {
 	return m.backend.SetPromptBroker(broker)
 }
@@ -396,6 +408,10 @@ func (m *Manager) Close() {
 		m.backend.Close()
 	}
 
+	if m.linkQuality != nil {
+		m.linkQuality.stop()
+	}
+
 	m.subMutex.Lock()
 	for _, ch := range m.subscribers {
 		close(ch)
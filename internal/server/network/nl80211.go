@@ -0,0 +1,200 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// stationInfo is what nl80211 reports about the access point a managed
+// interface is associated with
+type stationInfo struct {
+	BSSID     string
+	Signal    int
+	SignalAvg int
+	// TxBitrate and RxBitrate are in Mbit/s
+	TxBitrate float64
+	RxBitrate float64
+	// TxRetries and TxFailed count since association
+	TxRetries uint32
+	TxFailed  uint32
+}
+
+const genlHeaderLen = 4
+
+// readStationInfo asks nl80211 for the station entry of a Wi-Fi interface.
+// This is the same data `iw dev <iface> station dump` shows and needs no
+// privileges.
+func readStationInfo(iface string) (stationInfo, error) {
+	link, err := net.InterfaceByName(iface)
+	if err != nil {
+		return stationInfo{}, err
+	}
+
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_GENERIC)
+	if err != nil {
+		return stationInfo{}, fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return stationInfo{}, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+	tv := unix.Timeval{Sec: 2}
+	_ = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv)
+
+	family, err := genlFamilyID(fd, "nl80211")
+	if err != nil {
+		return stationInfo{}, err
+	}
+
+	ifindex := make([]byte, 4)
+	binary.LittleEndian.PutUint32(ifindex, uint32(link.Index))
+	msgs, err := genlRequest(fd, family, unix.NLM_F_DUMP, unix.NL80211_CMD_GET_STATION,
+		netlinkAttr(unix.NL80211_ATTR_IFINDEX, ifindex))
+	if err != nil {
+		return stationInfo{}, fmt.Errorf("nl80211 station dump failed: %w", err)
+	}
+
+	for _, msg := range msgs {
+		if info, ok := parseStation(parseNetlinkAttrs(msg)); ok {
+			return info, nil
+		}
+	}
+	return stationInfo{}, fmt.Errorf("%s is not associated", iface)
+}
+
+func genlFamilyID(fd int, name string) (uint16, error) {
+	msgs, err := genlRequest(fd, unix.GENL_ID_CTRL, 0, unix.CTRL_CMD_GETFAMILY,
+		netlinkAttr(unix.CTRL_ATTR_FAMILY_NAME, append([]byte(name), 0)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	for _, msg := range msgs {
+		if id := parseNetlinkAttrs(msg)[unix.CTRL_ATTR_FAMILY_ID]; len(id) >= 2 {
+			return binary.LittleEndian.Uint16(id), nil
+		}
+	}
+	return 0, fmt.Errorf("generic netlink family %s not found", name)
+}
+
+// genlRequest sends one generic netlink command and collects the attribute
+// payloads of the replies
+func genlRequest(fd int, family uint16, flags uint16, cmd uint8, attrs []byte) ([][]byte, error) {
+	const seq = 1
+	msg := make([]byte, unix.SizeofNlMsghdr+genlHeaderLen, unix.SizeofNlMsghdr+genlHeaderLen+len(attrs))
+	msg = append(msg, attrs...)
+	binary.LittleEndian.PutUint32(msg[0:4], uint32(len(msg)))
+	binary.LittleEndian.PutUint16(msg[4:6], family)
+	binary.LittleEndian.PutUint16(msg[6:8], unix.NLM_F_REQUEST|unix.NLM_F_ACK|flags)
+	binary.LittleEndian.PutUint32(msg[8:12], seq)
+	msg[unix.SizeofNlMsghdr] = cmd
+	msg[unix.SizeofNlMsghdr+1] = 1
+
+	if err := unix.Sendto(fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	var payloads [][]byte
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		replies, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, reply := range replies {
+			switch reply.Header.Type {
+			case unix.NLMSG_DONE:
+				return payloads, nil
+			case unix.NLMSG_ERROR:
+				if len(reply.Data) >= 4 {
+					if errno := int32(binary.LittleEndian.Uint32(reply.Data[:4])); errno != 0 {
+						return nil, unix.Errno(-errno)
+					}
+				}
+				// An ACK ends requests that are not dumps
+				if flags&unix.NLM_F_DUMP == 0 {
+					return payloads, nil
+				}
+			default:
+				if len(reply.Data) > genlHeaderLen {
+					payloads = append(payloads, reply.Data[genlHeaderLen:])
+				}
+			}
+		}
+	}
+}
+
+func netlinkAttr(typ uint16, data []byte) []byte {
+	length := unix.NLA_HDRLEN + len(data)
+	attr := make([]byte, (length+unix.NLA_ALIGNTO-1) & ^(unix.NLA_ALIGNTO-1))
+	binary.LittleEndian.PutUint16(attr[0:2], uint16(length))
+	binary.LittleEndian.PutUint16(attr[2:4], typ)
+	copy(attr[unix.NLA_HDRLEN:], data)
+	return attr
+}
+
+func parseNetlinkAttrs(b []byte) map[uint16][]byte {
+	attrs := make(map[uint16][]byte)
+	for len(b) >= unix.NLA_HDRLEN {
+		length := int(binary.LittleEndian.Uint16(b[0:2]))
+		typ := binary.LittleEndian.Uint16(b[2:4]) &^ (unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER)
+		if length < unix.NLA_HDRLEN || length > len(b) {
+			break
+		}
+		attrs[typ] = b[unix.NLA_HDRLEN:length]
+		aligned := (length + unix.NLA_ALIGNTO - 1) & ^(unix.NLA_ALIGNTO - 1)
+		if aligned > len(b) {
+			break
+		}
+		b = b[aligned:]
+	}
+	return attrs
+}
+
+func parseStation(attrs map[uint16][]byte) (stationInfo, bool) {
+	raw, ok := attrs[unix.NL80211_ATTR_STA_INFO]
+	if !ok {
+		return stationInfo{}, false
+	}
+	sta := parseNetlinkAttrs(raw)
+
+	var info stationInfo
+	if mac := attrs[unix.NL80211_ATTR_MAC]; len(mac) == 6 {
+		info.BSSID = net.HardwareAddr(mac).String()
+	}
+	if v := sta[unix.NL80211_STA_INFO_SIGNAL]; len(v) >= 1 {
+		info.Signal = int(int8(v[0]))
+	}
+	if v := sta[unix.NL80211_STA_INFO_SIGNAL_AVG]; len(v) >= 1 {
+		info.SignalAvg = int(int8(v[0]))
+	}
+	info.TxBitrate = parseBitrate(sta[unix.NL80211_STA_INFO_TX_BITRATE])
+	info.RxBitrate = parseBitrate(sta[unix.NL80211_STA_INFO_RX_BITRATE])
+	if v := sta[unix.NL80211_STA_INFO_TX_RETRIES]; len(v) >= 4 {
+		info.TxRetries = binary.LittleEndian.Uint32(v)
+	}
+	if v := sta[unix.NL80211_STA_INFO_TX_FAILED]; len(v) >= 4 {
+		info.TxFailed = binary.LittleEndian.Uint32(v)
+	}
+	return info, true
+}
+
+// parseBitrate reads a nested rate info, given in units of 100 kbit/s
+func parseBitrate(raw []byte) float64 {
+	rate := parseNetlinkAttrs(raw)
+	if v := rate[unix.NL80211_RATE_INFO_BITRATE32]; len(v) >= 4 {
+		return float64(binary.LittleEndian.Uint32(v)) / 10
+	}
+	if v := rate[unix.NL80211_RATE_INFO_BITRATE]; len(v) >= 2 {
+		return float64(binary.LittleEndian.Uint16(v)) / 10
+	}
+	return 0
+}
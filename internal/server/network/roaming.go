@@ -0,0 +1,143 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// roamCheckInterval limits how often signal changes are evaluated
+	roamCheckInterval = 10 * time.Second
+	// roamCooldown keeps a fresh association from being moved again while
+	// the signal readings settle
+	roamCooldown = time.Minute
+	// bandMinSignal is the weakest AP in the preferred band worth moving to
+	bandMinSignal = 40
+)
+
+// roamThreshold moves the connection once the signal drops below below and
+// another AP of the network is at least margin points stronger
+type roamThreshold struct {
+	below  uint8
+	margin uint8
+}
+
+// RoamingDefault leaves the decision to the supplicant, so it has no entry
+var roamThresholds = map[RoamingMode]roamThreshold{
+	RoamingModerate:   {below: 45, margin: 20},
+	RoamingAggressive: {below: 70, margin: 10},
+}
+
+// normalize validates the preferences and fills in defaults. BSSIDs are
+// upper-cased to match how NetworkManager reports hardware addresses.
+func (r *WiFiRoaming) normalize() error {
+	if r.SSID == "" {
+		return fmt.Errorf("missing ssid")
+	}
+
+	if r.BSSID != "" {
+		mac, err := net.ParseMAC(r.BSSID)
+		if err != nil || len(mac) != 6 {
+			return fmt.Errorf("invalid bssid: %s", r.BSSID)
+		}
+		r.BSSID = strings.ToUpper(mac.String())
+	}
+
+	switch r.Band {
+	case "":
+		r.Band = BandAuto
+	case BandAuto, Band2GHz, Band5GHz, Band6GHz:
+	default:
+		return fmt.Errorf("invalid band: %s (use auto, 2.4, 5 or 6)", r.Band)
+	}
+
+	switch r.Roaming {
+	case "":
+		r.Roaming = RoamingDefault
+	case RoamingDefault, RoamingModerate, RoamingAggressive:
+	default:
+		return fmt.Errorf("invalid roaming mode: %s (use default, moderate or aggressive)", r.Roaming)
+	}
+
+	return nil
+}
+
+// steers reports whether the server ever moves this network between APs
+func (r WiFiRoaming) steers() bool {
+	if r.BSSID != "" {
+		return false
+	}
+	return (r.Band != "" && r.Band != BandAuto) || roamThresholds[r.Roaming] != roamThreshold{}
+}
+
+func frequencyBand(freq uint32) WiFiBand {
+	switch {
+	case freq >= 5925:
+		return Band6GHz
+	case freq >= 4900:
+		return Band5GHz
+	case freq > 0:
+		return Band2GHz
+	}
+	return BandAuto
+}
+
+func inBand(band WiFiBand, ap WiFiNetwork) bool {
+	return band == "" || band == BandAuto || frequencyBand(ap.Frequency) == band
+}
+
+func strongestAP(aps []WiFiNetwork, exclude string, match func(WiFiNetwork) bool) *WiFiNetwork {
+	var best *WiFiNetwork
+	for i := range aps {
+		ap := &aps[i]
+		if strings.EqualFold(ap.BSSID, exclude) || !match(*ap) {
+			continue
+		}
+		if best == nil || ap.Signal > best.Signal {
+			best = ap
+		}
+	}
+	return best
+}
+
+// pickRoamTarget chooses the AP of the current network to move to, if any.
+// Leaving the preferred band is only considered when the connection is
+// already outside it.
+func pickRoamTarget(current WiFiNetwork, aps []WiFiNetwork, prefs WiFiRoaming) (WiFiNetwork, bool) {
+	if !prefs.steers() {
+		return WiFiNetwork{}, false
+	}
+
+	currentInBand := inBand(prefs.Band, current)
+	preferred := strongestAP(aps, current.BSSID, func(ap WiFiNetwork) bool { return inBand(prefs.Band, ap) })
+	if !currentInBand && preferred != nil && preferred.Signal >= bandMinSignal {
+		return *preferred, true
+	}
+
+	threshold, ok := roamThresholds[prefs.Roaming]
+	if !ok || current.Signal >= threshold.below {
+		return WiFiNetwork{}, false
+	}
+
+	target := preferred
+	if !currentInBand {
+		target = strongestAP(aps, current.BSSID, func(WiFiNetwork) bool { return true })
+	}
+	if target == nil || int(target.Signal) < int(current.Signal)+int(threshold.margin) {
+		return WiFiNetwork{}, false
+	}
+	return *target, true
+}
+
+func (m *Manager) GetWiFiRoaming(ssid string) (*WiFiRoaming, error) {
+	return m.backend.GetWiFiRoaming(ssid)
+}
+
+func (m *Manager) SetWiFiRoaming(prefs WiFiRoaming) error {
+	if err := prefs.normalize(); err != nil {
+		return err
+	}
+	return m.backend.SetWiFiRoaming(prefs)
+}
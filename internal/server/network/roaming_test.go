@@ -0,0 +1,135 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWiFiRoaming_Normalize(t *testing.T) {
+	prefs := WiFiRoaming{SSID: "HomeNet", BSSID: "aa-bb-cc-dd-ee-ff"}
+	require.NoError(t, prefs.normalize())
+	assert.Equal(t, WiFiRoaming{SSID: "HomeNet", BSSID: "AA:BB:CC:DD:EE:FF", Band: BandAuto, Roaming: RoamingDefault}, prefs)
+
+	for _, bad := range []WiFiRoaming{
+		{},
+		{SSID: "HomeNet", BSSID: "not-a-mac"},
+		{SSID: "HomeNet", BSSID: "00:00:00:00:fe:80:00:00"},
+		{SSID: "HomeNet", Band: "60"},
+		{SSID: "HomeNet", Roaming: "sticky"},
+	} {
+		assert.Error(t, bad.normalize(), "%+v", bad)
+	}
+}
+
+func TestFrequencyBand(t *testing.T) {
+	assert.Equal(t, Band2GHz, frequencyBand(2437))
+	assert.Equal(t, Band5GHz, frequencyBand(5180))
+	assert.Equal(t, Band5GHz, frequencyBand(5865))
+	assert.Equal(t, Band6GHz, frequencyBand(5955))
+	assert.Equal(t, BandAuto, frequencyBand(0))
+}
+
+func TestPickRoamTarget(t *testing.T) {
+	aps := []WiFiNetwork{
+		{BSSID: "AA:00:00:00:00:01", Signal: 35, Frequency: 2412},
+		{BSSID: "AA:00:00:00:00:02", Signal: 50, Frequency: 2437},
+		{BSSID: "AA:00:00:00:00:03", Signal: 62, Frequency: 5180},
+		{BSSID: "AA:00:00:00:00:04", Signal: 30, Frequency: 5955},
+	}
+	weak24 := WiFiNetwork{BSSID: "AA:00:00:00:00:01", Signal: 35, Frequency: 2412}
+	fair5 := WiFiNetwork{BSSID: "AA:00:00:00:00:03", Signal: 62, Frequency: 5180}
+
+	tests := []struct {
+		name     string
+		current  WiFiNetwork
+		prefs    WiFiRoaming
+		expected string
+	}{
+		{name: "default leaves it alone", current: weak24, prefs: WiFiRoaming{Band: BandAuto, Roaming: RoamingDefault}},
+		{name: "pinned leaves it alone", current: weak24, prefs: WiFiRoaming{BSSID: "AA:00:00:00:00:03", Roaming: RoamingAggressive}},
+		{name: "moderate moves to a much stronger AP", current: weak24, prefs: WiFiRoaming{Roaming: RoamingModerate}, expected: "AA:00:00:00:00:03"},
+		{name: "moderate keeps a fair signal", current: fair5, prefs: WiFiRoaming{Roaming: RoamingModerate}},
+		{name: "aggressive needs a real improvement", current: fair5, prefs: WiFiRoaming{Roaming: RoamingAggressive}},
+		{name: "band preference moves into the band", current: weak24, prefs: WiFiRoaming{Band: Band5GHz}, expected: "AA:00:00:00:00:03"},
+		{name: "too weak in the preferred band", current: weak24, prefs: WiFiRoaming{Band: Band6GHz}},
+		{name: "outside the band roams anywhere", current: weak24, prefs: WiFiRoaming{Band: Band6GHz, Roaming: RoamingModerate}, expected: "AA:00:00:00:00:03"},
+		{name: "inside the band stays there", current: WiFiNetwork{BSSID: "AA:00:00:00:00:04", Signal: 30, Frequency: 5955}, prefs: WiFiRoaming{Band: Band6GHz, Roaming: RoamingAggressive}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, ok := pickRoamTarget(tt.current, aps, tt.prefs)
+			assert.Equal(t, tt.expected != "", ok)
+			assert.Equal(t, tt.expected, target.BSSID)
+		})
+	}
+}
+
+func TestRoamingSettingsRoundTrip(t *testing.T) {
+	settings := gonetworkmanager.ConnectionSettings{
+		"connection":      {"type": "802-11-wireless"},
+		"802-11-wireless": {"ssid": []byte("HomeNet")},
+		"user":            {"data": map[string]string{"other.key": "kept"}},
+	}
+	assert.Equal(t, WiFiRoaming{SSID: "HomeNet", Band: BandAuto, Roaming: RoamingDefault}, roamingFromSettings("HomeNet", settings))
+
+	prefs := WiFiRoaming{SSID: "HomeNet", BSSID: "AA:BB:CC:DD:EE:FF", Band: Band6GHz, Roaming: RoamingAggressive}
+	require.NoError(t, applyRoamingSettings(settings, prefs))
+	assert.Equal(t, []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, settings["802-11-wireless"]["bssid"])
+	assert.Equal(t, map[string]string{"other.key": "kept", nmUserDataBand: "6", nmUserDataRoaming: "aggressive"}, settings["user"]["data"])
+	assert.Equal(t, prefs, roamingFromSettings("HomeNet", settings))
+
+	require.NoError(t, applyRoamingSettings(settings, WiFiRoaming{SSID: "HomeNet", Band: BandAuto, Roaming: RoamingDefault}))
+	assert.NotContains(t, settings["802-11-wireless"], "bssid")
+	assert.Equal(t, map[string]string{"other.key": "kept"}, settings["user"]["data"])
+
+	delete(settings, "user")
+	require.NoError(t, applyRoamingSettings(settings, WiFiRoaming{SSID: "HomeNet"}))
+	assert.NotContains(t, settings, "user")
+}
+
+type roamingBackend struct {
+	Backend
+	prefs WiFiRoaming
+}
+
+func (b *roamingBackend) GetWiFiRoaming(ssid string) (*WiFiRoaming, error) {
+	prefs := b.prefs
+	prefs.SSID = ssid
+	return &prefs, nil
+}
+
+func (b *roamingBackend) SetWiFiRoaming(prefs WiFiRoaming) error {
+	b.prefs = prefs
+	return nil
+}
+
+func TestHandleSetWiFiRoaming(t *testing.T) {
+	backend := &roamingBackend{prefs: WiFiRoaming{Band: Band5GHz, Roaming: RoamingAggressive}}
+	manager := NewTestManager(backend, nil)
+
+	conn := newMockNetConn()
+	handleSetWiFiRoaming(conn, Request{ID: 1, Method: "network.wifi.setRoaming", Params: map[string]interface{}{
+		"ssid":  "HomeNet",
+		"bssid": "aa:bb:cc:dd:ee:ff",
+	}}, manager)
+
+	var resp models.Response[SuccessResult]
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, WiFiRoaming{SSID: "HomeNet", BSSID: "AA:BB:CC:DD:EE:FF", Band: Band5GHz, Roaming: RoamingAggressive}, backend.prefs)
+
+	conn = newMockNetConn()
+	handleSetWiFiRoaming(conn, Request{ID: 2, Method: "network.wifi.setRoaming", Params: map[string]interface{}{
+		"ssid": "HomeNet",
+		"band": "60",
+	}}, manager)
+	require.NoError(t, json.NewDecoder(conn.writeBuf).Decode(&resp))
+	assert.Contains(t, resp.Error, "invalid band")
+	assert.Equal(t, Band5GHz, backend.prefs.Band)
+}
@@ -0,0 +1,205 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultSpeedTestURL       = "https://speed.cloudflare.com/__down?bytes=25000000"
+	DefaultSpeedTestUploadURL = "https://speed.cloudflare.com/__up"
+
+	speedTestPhaseTimeout = 15 * time.Second
+	speedTestUploadSize   = 10 << 20
+	latencyProbes         = 3
+)
+
+// SpeedTestResult is one run of network.speedTest. Rates are in Mbit/s and
+// a phase that was skipped or failed is left at zero.
+type SpeedTestResult struct {
+	Endpoint       string    `json:"endpoint"`
+	UploadEndpoint string    `json:"uploadEndpoint,omitempty"`
+	StartedAt      time.Time `json:"startedAt"`
+	LatencyMs      float64   `json:"latencyMs"`
+	DownloadMbps   float64   `json:"downloadMbps"`
+	UploadMbps     float64   `json:"uploadMbps"`
+	DownloadBytes  int64     `json:"downloadBytes"`
+	UploadBytes    int64     `json:"uploadBytes"`
+	Warnings       []string  `json:"warnings,omitempty"`
+}
+
+// SetSpeedTestEndpoints sets where speed tests download from and upload
+// to. An empty download URL uses the default endpoints; an empty upload URL
+// with a custom download URL skips the upload phase.
+func (m *Manager) SetSpeedTestEndpoints(download, upload string) {
+	if download == "" {
+		download = DefaultSpeedTestURL
+		if upload == "" {
+			upload = DefaultSpeedTestUploadURL
+		}
+	}
+
+	m.speedTestMutex.Lock()
+	m.speedTestURL = download
+	m.speedTestUploadURL = upload
+	m.speedTestMutex.Unlock()
+}
+
+// RunSpeedTest measures latency, download and upload speed against the
+// configured endpoints. Only one test runs at a time. progress, when set,
+// is called as each phase starts with the share of the test done so far.
+func (m *Manager) RunSpeedTest(ctx context.Context, progress func(done float64, phase string)) (SpeedTestResult, error) {
+	m.speedTestMutex.Lock()
+	if m.speedTestRunning {
+		m.speedTestMutex.Unlock()
+		return SpeedTestResult{}, fmt.Errorf("a speed test is already running")
+	}
+	m.speedTestRunning = true
+	download, upload := m.speedTestURL, m.speedTestUploadURL
+	m.speedTestMutex.Unlock()
+
+	if download == "" {
+		download, upload = DefaultSpeedTestURL, DefaultSpeedTestUploadURL
+	}
+
+	result, err := runSpeedTest(ctx, http.DefaultClient, download, upload, progress)
+
+	m.speedTestMutex.Lock()
+	m.speedTestRunning = false
+	if err == nil {
+		m.lastSpeedTest = &result
+	}
+	m.speedTestMutex.Unlock()
+
+	return result, err
+}
+
+func runSpeedTest(ctx context.Context, client *http.Client, download, upload string, progress func(float64, string)) (SpeedTestResult, error) {
+	result := SpeedTestResult{Endpoint: download, UploadEndpoint: upload, StartedAt: time.Now()}
+	if progress == nil {
+		progress = func(float64, string) {}
+	}
+
+	// The latency probes are quick; the transfers each take up to
+	// speedTestPhaseTimeout
+	progress(0, "latency")
+	latency, err := measureLatency(ctx, client, download)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("latency: %v", err))
+	}
+	result.LatencyMs = float64(latency.Microseconds()) / 1000
+
+	progress(0.05, "download")
+	result.DownloadBytes, result.DownloadMbps, err = measureDownload(ctx, client, download)
+	if err != nil {
+		return result, fmt.Errorf("download failed: %w", err)
+	}
+
+	if upload != "" {
+		progress(0.5, "upload")
+		result.UploadBytes, result.UploadMbps, err = measureUpload(ctx, client, upload)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("upload: %v", err))
+		}
+	}
+	return result, nil
+}
+
+// measureLatency returns the fastest of a few HEAD round trips, which
+// leaves out connection setup after the first
+func measureLatency(ctx context.Context, client *http.Client, url string) (time.Duration, error) {
+	best := time.Duration(0)
+	for i := 0; i < latencyProbes; i++ {
+		probeCtx, cancel := context.WithTimeout(ctx, speedTestPhaseTimeout)
+		req, err := http.NewRequestWithContext(probeCtx, http.MethodHead, url, nil)
+		if err != nil {
+			cancel()
+			return 0, err
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		elapsed := time.Since(start)
+		cancel()
+		if err != nil {
+			return best, err
+		}
+		resp.Body.Close()
+		if best == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+	return best, nil
+}
+
+// measureDownload reads the body for at most the phase timeout and rates
+// the bytes received after the headers arrived
+func measureDownload(ctx context.Context, client *http.Client, url string) (int64, float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, speedTestPhaseTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, resp.Body)
+	elapsed := time.Since(start)
+	// Hitting the time limit still gives a valid measurement
+	if err != nil && ctx.Err() == nil {
+		return n, 0, err
+	}
+	if n == 0 {
+		return 0, 0, fmt.Errorf("%s returned no data", url)
+	}
+	return n, mbps(n, elapsed), nil
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func measureUpload(ctx context.Context, client *http.Client, url string) (int64, float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, speedTestPhaseTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, io.LimitReader(zeroReader{}, speedTestUploadSize))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.ContentLength = speedTestUploadSize
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp.Body.Close()
+	elapsed := time.Since(start)
+	if resp.StatusCode >= 300 {
+		return 0, 0, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return speedTestUploadSize, mbps(speedTestUploadSize, elapsed), nil
+}
+
+func mbps(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) * 8 / elapsed.Seconds() / 1e6
+}
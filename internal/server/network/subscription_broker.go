@@ -3,10 +3,13 @@ package network
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
 )
 
 type SubscriptionBroker struct {
@@ -15,6 +18,10 @@ type SubscriptionBroker struct {
 	requests           map[string]PromptRequest
 	pathSettingToToken map[string]string
 	broadcastPrompt    func(CredentialPrompt)
+	// relay is the daemon's prompts module; once set, secret prompts are
+	// filed there and can be answered with prompts.respond as well as
+	// network.credentials.submit
+	relay *prompts.Manager
 }
 
 func NewSubscriptionBroker(broadcastPrompt func(CredentialPrompt)) PromptBroker {
@@ -26,6 +33,13 @@ func NewSubscriptionBroker(broadcastPrompt func(CredentialPrompt)) PromptBroker
 	}
 }
 
+// SetRelay files secret prompts with the prompts module from now on
+func (b *SubscriptionBroker) SetRelay(relay *prompts.Manager) {
+	b.mu.Lock()
+	b.relay = relay
+	b.mu.Unlock()
+}
+
 func (b *SubscriptionBroker) Ask(ctx context.Context, req PromptRequest) (string, error) {
 	pathSettingKey := fmt.Sprintf("%s:%s", req.ConnectionPath, req.SettingName)
 
@@ -38,7 +52,19 @@ func (b *SubscriptionBroker) Ask(ctx context.Context, req PromptRequest) (string
 		return existingToken, nil
 	}
 
-	token, err := generateToken()
+	b.mu.RLock()
+	relay := b.relay
+	b.mu.RUnlock()
+
+	var token string
+	var err error
+	if relay != nil {
+		var p prompts.Prompt
+		p, err = relay.Open(relayRequest(ctx, req))
+		token = p.Token
+	} else {
+		token, err = generateToken()
+	}
 	if err != nil {
 		return "", err
 	}
@@ -73,12 +99,25 @@ func (b *SubscriptionBroker) Ask(ctx context.Context, req PromptRequest) (string
 func (b *SubscriptionBroker) Wait(ctx context.Context, token string) (PromptReply, error) {
 	b.mu.RLock()
 	replyChan, exists := b.pending[token]
+	relay := b.relay
 	b.mu.RUnlock()
 
 	if !exists {
 		return PromptReply{}, fmt.Errorf("unknown token: %s", token)
 	}
 
+	if relay != nil {
+		defer b.cleanup(token)
+		resp, err := relay.Wait(ctx, token)
+		if err != nil || resp.TimedOut {
+			return PromptReply{}, errdefs.ErrSecretPromptTimeout
+		}
+		if resp.Action != prompts.ActionAllow {
+			return PromptReply{Cancel: true}, errdefs.ErrSecretPromptCancelled
+		}
+		return PromptReply{Secrets: resp.Secrets, Save: resp.Remember}, nil
+	}
+
 	select {
 	case <-ctx.Done():
 		b.cleanup(token)
@@ -95,6 +134,7 @@ func (b *SubscriptionBroker) Wait(ctx context.Context, token string) (PromptRepl
 func (b *SubscriptionBroker) Resolve(token string, reply PromptReply) error {
 	b.mu.RLock()
 	replyChan, exists := b.pending[token]
+	relay := b.relay
 	b.mu.RUnlock()
 
 	if !exists {
@@ -102,6 +142,14 @@ func (b *SubscriptionBroker) Resolve(token string, reply PromptReply) error {
 		return fmt.Errorf("unknown or expired token: %s", token)
 	}
 
+	if relay != nil {
+		resp := prompts.Response{Action: prompts.ActionDeny}
+		if !reply.Cancel {
+			resp = prompts.Response{Action: prompts.ActionAllow, Secrets: reply.Secrets, Remember: reply.Save}
+		}
+		return relay.Respond(token, resp)
+	}
+
 	select {
 	case replyChan <- reply:
 		return nil
@@ -144,3 +192,42 @@ func (b *SubscriptionBroker) Cancel(path string, setting string) error {
 
 	return b.Resolve(token, reply)
 }
+
+// relayRequest describes a secrets request for the prompts module. It
+// expires with ctx, which bounds how long the agent waits anyway.
+func relayRequest(ctx context.Context, req PromptRequest) prompts.PromptRequest {
+	name := req.Name
+	if name == "" {
+		name = req.SSID
+	}
+	details := map[string]string{
+		"name":           req.Name,
+		"ssid":           req.SSID,
+		"connType":       req.ConnType,
+		"vpnService":     req.VpnService,
+		"setting":        req.SettingName,
+		"connectionId":   req.ConnectionId,
+		"connectionUuid": req.ConnectionUuid,
+	}
+	for k, v := range details {
+		if v == "" {
+			delete(details, k)
+		}
+	}
+	if len(req.Hints) > 0 {
+		details["hints"] = strings.Join(req.Hints, ",")
+	}
+
+	r := prompts.PromptRequest{
+		Source:  "network",
+		Kind:    prompts.KindSecret,
+		Title:   "Authentication required for " + name,
+		Message: req.Reason,
+		Fields:  req.Fields,
+		Details: details,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		r.Timeout = time.Until(deadline)
+	}
+	return r
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRelayedBroker(t *testing.T) (*SubscriptionBroker, *prompts.Manager) {
+	t.Helper()
+	relay, err := prompts.NewManager()
+	require.NoError(t, err)
+	broker := NewSubscriptionBroker(nil).(*SubscriptionBroker)
+	broker.SetRelay(relay)
+	return broker, relay
+}
+
+func TestSubscriptionBroker_RelayRespond(t *testing.T) {
+	broker, relay := newRelayedBroker(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	token, err := broker.Ask(ctx, PromptRequest{
+		SSID:           "Home",
+		SettingName:    "802-11-wireless-security",
+		Fields:         []string{"psk"},
+		ConnectionPath: "/org/freedesktop/NetworkManager/Settings/1",
+	})
+	require.NoError(t, err)
+
+	pending := relay.Pending()
+	require.Len(t, pending, 1)
+	assert.Equal(t, token, pending[0].Token)
+	assert.Equal(t, "network", pending[0].Source)
+	assert.Equal(t, prompts.KindSecret, pending[0].Kind)
+	assert.Equal(t, []string{"psk"}, pending[0].Fields)
+	assert.Equal(t, "Home", pending[0].Details["ssid"])
+
+	go relay.Respond(token, prompts.Response{
+		Action:   prompts.ActionAllow,
+		Secrets:  map[string]string{"psk": "hunter22"},
+		Remember: true,
+	})
+
+	reply, err := broker.Wait(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter22", reply.Secrets["psk"])
+	assert.True(t, reply.Save)
+}
+
+func TestSubscriptionBroker_RelayCancel(t *testing.T) {
+	broker, relay := newRelayedBroker(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	path := "/org/freedesktop/NetworkManager/Settings/2"
+	token, err := broker.Ask(ctx, PromptRequest{SSID: "Cafe", SettingName: "802-11-wireless-security", ConnectionPath: path})
+	require.NoError(t, err)
+
+	go broker.Cancel(path, "802-11-wireless-security")
+
+	_, err = broker.Wait(ctx, token)
+	assert.ErrorIs(t, err, errdefs.ErrSecretPromptCancelled)
+	assert.Empty(t, relay.Pending())
+}
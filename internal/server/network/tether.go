@@ -0,0 +1,246 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	TetherUSB       = "usb"
+	TetherBluetooth = "bluetooth"
+)
+
+const netClassDir = "/sys/class/net"
+
+// usbTetherDrivers are the kernel drivers of the network interface a phone
+// brings up when USB tethering is turned on
+var usbTetherDrivers = map[string]bool{
+	"rndis_host": true, // Android
+	"cdc_ncm":    true, // Android 11 and later
+	"cdc_ether":  true,
+	"ipheth":     true, // iPhone
+}
+
+// TetherDevice is a phone that can share its connection. ID is
+// usb:<interface> or bluetooth:<address>; Interface is empty for a
+// Bluetooth phone until it is connected.
+type TetherDevice struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Interface string `json:"interface,omitempty"`
+	Address   string `json:"address,omitempty"`
+	Connected bool   `json:"connected"`
+}
+
+// PANDevice is a paired Bluetooth device offering the network access point
+// profile
+type PANDevice struct {
+	Address   string
+	Name      string
+	Connected bool
+	Interface string
+}
+
+// PANProvider is the Bluetooth side of tethering. The server sets it from
+// the bluez manager when both run.
+type PANProvider interface {
+	PANDevices() ([]PANDevice, error)
+	ConnectPAN(address string) (iface string, err error)
+	DisconnectPAN(address string) error
+}
+
+// interfaceActivator is implemented by backends that can bring up an
+// interface the kernel just created, such as usb0
+type interfaceActivator interface {
+	ActivateInterface(iface string) error
+}
+
+// panActivator is implemented by backends that drive Bluetooth PAN
+// themselves rather than configuring the interface bluez creates
+type panActivator interface {
+	ActivateBluetoothPAN(address, name string) error
+}
+
+// scanUSBTether lists the interfaces in dir, a /sys/class/net, that belong
+// to a USB tethering driver
+func scanUSBTether(dir string, hasAddress func(iface string) bool) []TetherDevice {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var devices []TetherDevice
+	for _, entry := range entries {
+		iface := entry.Name()
+		driver, err := os.Readlink(filepath.Join(dir, iface, "device", "driver"))
+		if err != nil || !usbTetherDrivers[filepath.Base(driver)] {
+			continue
+		}
+
+		// device is the USB interface; the phone's own attributes are on
+		// its parent, which has to be found through the link rather than
+		// by cleaning the path
+		var name string
+		if usbIface, err := filepath.EvalSymlinks(filepath.Join(dir, iface, "device")); err == nil {
+			name = readSysfsString(filepath.Join(filepath.Dir(usbIface), "product"))
+			if name == "" {
+				name = readSysfsString(filepath.Join(filepath.Dir(usbIface), "manufacturer"))
+			}
+		}
+		if name == "" {
+			name = iface
+		}
+
+		devices = append(devices, TetherDevice{
+			ID:        TetherUSB + ":" + iface,
+			Type:      TetherUSB,
+			Name:      name,
+			Interface: iface,
+			Connected: hasAddress(iface),
+		})
+	}
+	return devices
+}
+
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// interfaceHasAddress tells whether iface got a routable address, which
+// is when tethering is usable
+func interfaceHasAddress(iface string) bool {
+	i, err := net.InterfaceByName(iface)
+	if err != nil {
+		return false
+	}
+	addrs, err := i.Addrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsGlobalUnicast() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPANProvider lets Bluetooth phones be listed and connected. nil
+// removes them.
+func (m *Manager) SetPANProvider(p PANProvider) {
+	m.tetherMutex.Lock()
+	m.panProvider = p
+	m.tetherMutex.Unlock()
+}
+
+// GetTetherDevices lists phones plugged in with USB tethering on, then
+// paired Bluetooth phones offering PAN
+func (m *Manager) GetTetherDevices() ([]TetherDevice, error) {
+	devices := scanUSBTether(netClassDir, interfaceHasAddress)
+
+	m.tetherMutex.RLock()
+	provider := m.panProvider
+	m.tetherMutex.RUnlock()
+	if provider == nil {
+		return devices, nil
+	}
+
+	pan, err := provider.PANDevices()
+	if err != nil {
+		return devices, fmt.Errorf("failed to list Bluetooth phones: %w", err)
+	}
+	sort.Slice(pan, func(i, j int) bool { return pan[i].Name < pan[j].Name })
+	for _, d := range pan {
+		devices = append(devices, TetherDevice{
+			ID:        TetherBluetooth + ":" + d.Address,
+			Type:      TetherBluetooth,
+			Name:      d.Name,
+			Interface: d.Interface,
+			Address:   d.Address,
+			Connected: d.Connected,
+		})
+	}
+	return devices, nil
+}
+
+func (m *Manager) findTetherDevice(id string) (TetherDevice, error) {
+	devices, err := m.GetTetherDevices()
+	for _, d := range devices {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	if err != nil {
+		return TetherDevice{}, err
+	}
+	return TetherDevice{}, fmt.Errorf("no tethering device %s", id)
+}
+
+// ConnectTether brings up a phone's shared connection. USB interfaces are
+// handed to the backend; Bluetooth phones are connected through the
+// backend when it manages PAN, otherwise through bluez with the interface
+// it creates handed to the backend.
+func (m *Manager) ConnectTether(id string) error {
+	device, err := m.findTetherDevice(id)
+	if err != nil {
+		return err
+	}
+
+	activator, canActivate := m.backend.(interfaceActivator)
+
+	if device.Type == TetherUSB {
+		if !canActivate {
+			return fmt.Errorf("this network backend cannot bring up %s; configure it for DHCP", device.Interface)
+		}
+		return activator.ActivateInterface(device.Interface)
+	}
+
+	if pan, ok := m.backend.(panActivator); ok {
+		return pan.ActivateBluetoothPAN(device.Address, device.Name)
+	}
+
+	m.tetherMutex.RLock()
+	provider := m.panProvider
+	m.tetherMutex.RUnlock()
+	if provider == nil {
+		return fmt.Errorf("bluetooth is not available")
+	}
+
+	iface, err := provider.ConnectPAN(device.Address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", device.Name, err)
+	}
+	if canActivate {
+		return activator.ActivateInterface(iface)
+	}
+	return nil
+}
+
+// DisconnectTether ends a Bluetooth phone's connection. USB tethering ends
+// by unplugging the phone or turning it off there.
+func (m *Manager) DisconnectTether(id string) error {
+	device, err := m.findTetherDevice(id)
+	if err != nil {
+		return err
+	}
+	if device.Type != TetherBluetooth {
+		return fmt.Errorf("turn off USB tethering on the phone or unplug it to disconnect")
+	}
+
+	m.tetherMutex.RLock()
+	provider := m.panProvider
+	m.tetherMutex.RUnlock()
+	if provider == nil {
+		return fmt.Errorf("bluetooth is not available")
+	}
+	return provider.DisconnectPAN(device.Address)
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNetDev lays out /sys/class/net/<iface> with its USB device and driver
+func fakeNetDev(t *testing.T, dir, iface, driver, product string) {
+	t.Helper()
+	usbDev := filepath.Join(dir, "devices", iface)
+	usbIface := filepath.Join(usbDev, "1-2:1.0")
+	require.NoError(t, os.MkdirAll(usbIface, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "drivers", driver), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "drivers", driver), filepath.Join(usbIface, "driver")))
+	if product != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(usbDev, "product"), []byte(product+"\n"), 0644))
+	}
+
+	netDir := filepath.Join(dir, "net", iface)
+	require.NoError(t, os.MkdirAll(netDir, 0755))
+	require.NoError(t, os.Symlink(usbIface, filepath.Join(netDir, "device")))
+}
+
+func TestScanUSBTether(t *testing.T) {
+	dir := t.TempDir()
+	fakeNetDev(t, dir, "usb0", "rndis_host", "Pixel 8")
+	fakeNetDev(t, dir, "enx0", "ipheth", "")
+	fakeNetDev(t, dir, "enp3s0", "r8169", "")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "net", "lo"), 0755))
+
+	devices := scanUSBTether(filepath.Join(dir, "net"), func(iface string) bool { return iface == "usb0" })
+	assert.Equal(t, []TetherDevice{
+		{ID: "usb:enx0", Type: TetherUSB, Name: "enx0", Interface: "enx0"},
+		{ID: "usb:usb0", Type: TetherUSB, Name: "Pixel 8", Interface: "usb0", Connected: true},
+	}, devices)
+
+	assert.Nil(t, scanUSBTether(filepath.Join(dir, "missing"), nil))
+}
+
+type fakePAN struct {
+	devices      []PANDevice
+	connected    []string
+	disconnected []string
+	err          error
+}
+
+func (p *fakePAN) PANDevices() ([]PANDevice, error) { return p.devices, nil }
+
+func (p *fakePAN) ConnectPAN(address string) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	p.connected = append(p.connected, address)
+	return "bnep0", nil
+}
+
+func (p *fakePAN) DisconnectPAN(address string) error {
+	p.disconnected = append(p.disconnected, address)
+	return nil
+}
+
+// tetherBackend brings up interfaces like networkd, leaving PAN to bluez
+type tetherBackend struct {
+	Backend
+	activated []string
+}
+
+func (b *tetherBackend) ActivateInterface(iface string) error {
+	b.activated = append(b.activated, iface)
+	return nil
+}
+
+// panBackend drives PAN itself like NetworkManager
+type panBackend struct {
+	tetherBackend
+	pan []string
+}
+
+func (b *panBackend) ActivateBluetoothPAN(address, name string) error {
+	b.pan = append(b.pan, address+" "+name)
+	return nil
+}
+
+func TestGetTetherDevicesListsBluetoothPhones(t *testing.T) {
+	m := NewTestManager(&tetherBackend{}, nil)
+	m.SetPANProvider(&fakePAN{devices: []PANDevice{
+		{Address: "22:22:22:22:22:22", Name: "Zed's phone"},
+		{Address: "11:11:11:11:11:11", Name: "Pixel", Connected: true, Interface: "bnep0"},
+	}})
+
+	devices, err := m.GetTetherDevices()
+	require.NoError(t, err)
+	var bluetooth []TetherDevice
+	for _, d := range devices {
+		if d.Type == TetherBluetooth {
+			bluetooth = append(bluetooth, d)
+		}
+	}
+	assert.Equal(t, []TetherDevice{
+		{ID: "bluetooth:11:11:11:11:11:11", Type: TetherBluetooth, Name: "Pixel", Interface: "bnep0", Address: "11:11:11:11:11:11", Connected: true},
+		{ID: "bluetooth:22:22:22:22:22:22", Type: TetherBluetooth, Name: "Zed's phone", Address: "22:22:22:22:22:22"},
+	}, bluetooth)
+}
+
+func TestConnectTetherBluetooth(t *testing.T) {
+	pan := &fakePAN{devices: []PANDevice{{Address: "11:11:11:11:11:11", Name: "Pixel"}}}
+
+	// Without PAN support in the backend, bluez connects and the backend
+	// configures the interface
+	backend := &tetherBackend{}
+	m := NewTestManager(backend, nil)
+	m.SetPANProvider(pan)
+	require.NoError(t, m.ConnectTether("bluetooth:11:11:11:11:11:11"))
+	assert.Equal(t, []string{"11:11:11:11:11:11"}, pan.connected)
+	assert.Equal(t, []string{"bnep0"}, backend.activated)
+
+	require.NoError(t, m.DisconnectTether("bluetooth:11:11:11:11:11:11"))
+	assert.Equal(t, []string{"11:11:11:11:11:11"}, pan.disconnected)
+
+	// A backend that drives PAN gets the phone instead
+	nm := &panBackend{}
+	m = NewTestManager(nm, nil)
+	m.SetPANProvider(pan)
+	require.NoError(t, m.ConnectTether("bluetooth:11:11:11:11:11:11"))
+	assert.Equal(t, []string{"11:11:11:11:11:11 Pixel"}, nm.pan)
+	assert.Len(t, pan.connected, 1)
+
+	pan.err = errors.New("refused")
+	m = NewTestManager(&tetherBackend{}, nil)
+	m.SetPANProvider(pan)
+	assert.ErrorContains(t, m.ConnectTether("bluetooth:11:11:11:11:11:11"), "refused")
+
+	assert.Error(t, m.ConnectTether("bluetooth:99:99:99:99:99:99"))
+	m.SetPANProvider(nil)
+	assert.Error(t, m.ConnectTether("bluetooth:11:11:11:11:11:11"))
+}
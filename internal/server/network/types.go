@@ -104,6 +104,33 @@ type PriorityUpdate struct {
 	Preference ConnectionPreference `json:"preference"`
 }
 
+type WiFiBand string
+
+const (
+	BandAuto WiFiBand = "auto"
+	Band2GHz WiFiBand = "2.4"
+	Band5GHz WiFiBand = "5"
+	Band6GHz WiFiBand = "6"
+)
+
+type RoamingMode string
+
+const (
+	RoamingDefault    RoamingMode = "default"
+	RoamingModerate   RoamingMode = "moderate"
+	RoamingAggressive RoamingMode = "aggressive"
+)
+
+// WiFiRoaming steers a saved network between its access points. A pinned
+// BSSID locks the connection to one AP; otherwise the band preference and
+// roaming mode decide when the server moves it to a better one.
+type WiFiRoaming struct {
+	SSID    string      `json:"ssid"`
+	BSSID   string      `json:"bssid"`
+	Band    WiFiBand    `json:"band"`
+	Roaming RoamingMode `json:"roaming"`
+}
+
 type Manager struct {
 	backend               Backend
 	state                 *NetworkState
@@ -116,6 +143,14 @@ type Manager struct {
 	lastNotifiedState     *NetworkState
 	credentialSubscribers map[string]chan CredentialPrompt
 	credSubMutex          sync.RWMutex
+	linkQuality           *linkQualitySampler
+	speedTestMutex        sync.Mutex
+	speedTestRunning      bool
+	speedTestURL          string
+	speedTestUploadURL    string
+	lastSpeedTest         *SpeedTestResult
+	panProvider           PANProvider
+	tetherMutex           sync.RWMutex
 }
 
 type EventType string
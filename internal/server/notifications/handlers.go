@@ -0,0 +1,88 @@
+package notifications
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "notifications.getForwarding":
+		handleGetForwarding(conn, req, manager)
+	case "notifications.setForwarding":
+		handleSetForwarding(conn, req, manager)
+	case "notifications.forward":
+		handleForward(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetForwarding(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetConfig())
+}
+
+func handleSetForwarding(conn net.Conn, req Request, manager *Manager) {
+	urgencyStr, ok := req.Params["urgency"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'urgency' parameter")
+		return
+	}
+
+	urgency, err := ParseUrgency(urgencyStr)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	var target ForwardTarget
+	if file, ok := req.Params["file"].(string); ok {
+		if file != "" && !filepath.IsAbs(file) {
+			models.RespondError(conn, req.ID, "'file' must be an absolute path")
+			return
+		}
+		target.File = file
+	}
+	if terminal, ok := req.Params["terminal"].(bool); ok {
+		target.Terminal = terminal
+	}
+	if bell, ok := req.Params["bell"].(bool); ok {
+		target.Bell = bell
+	}
+
+	manager.SetTarget(urgency, target)
+	if err := manager.SaveTarget(urgency); err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("forwarding changed but not saved: %v", err))
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "forwarding updated"})
+}
+
+func handleForward(conn net.Conn, req Request, manager *Manager) {
+	summary, ok := req.Params["summary"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'summary' parameter")
+		return
+	}
+
+	n := Notification{Summary: summary}
+	if body, ok := req.Params["body"].(string); ok {
+		n.Body = body
+	}
+	if appName, ok := req.Params["appName"].(string); ok {
+		n.AppName = appName
+	}
+
+	urgencyStr, _ := req.Params["urgency"].(string)
+	urgency, err := ParseUrgency(urgencyStr)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	n.Urgency = urgency
+
+	models.Respond(conn, req.ID, manager.Forward(n))
+}
@@ -0,0 +1,195 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// NewManager starts with the given forwarding config, writing later
+// changes to store
+func NewManager(config ForwardConfig, store ConfigStore) (*Manager, error) {
+	m := newManager("/dev/pts", os.Getuid())
+	for urgency, target := range config.Targets {
+		m.SetTarget(urgency, target)
+	}
+	m.store = store
+	return m, nil
+}
+
+func newManager(ptsDir string, uid int) *Manager {
+	return &Manager{
+		config: ForwardConfig{Targets: make(map[Urgency]ForwardTarget)},
+		ptsDir: ptsDir,
+		uid:    uid,
+	}
+}
+
+func ParseUrgency(s string) (Urgency, error) {
+	switch Urgency(strings.ToLower(s)) {
+	case UrgencyLow:
+		return UrgencyLow, nil
+	case UrgencyNormal, "":
+		return UrgencyNormal, nil
+	case UrgencyCritical:
+		return UrgencyCritical, nil
+	default:
+		return "", fmt.Errorf("invalid urgency: %s", s)
+	}
+}
+
+func (m *Manager) SetTarget(urgency Urgency, target ForwardTarget) {
+	m.configMutex.Lock()
+	defer m.configMutex.Unlock()
+
+	if target.File == "" && !target.Terminal && !target.Bell {
+		delete(m.config.Targets, urgency)
+		return
+	}
+	m.config.Targets[urgency] = target
+}
+
+// SaveTarget writes the target of one urgency to the store, if one is set
+func (m *Manager) SaveTarget(urgency Urgency) error {
+	m.configMutex.RLock()
+	target := m.config.Targets[urgency]
+	store := m.store
+	m.configMutex.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+	return store.SaveTarget(urgency, target)
+}
+
+// Forward mirrors a notification to the sinks configured for its urgency.
+// Missing FIFO readers and unwritable terminals are skipped, not fatal.
+func (m *Manager) Forward(n Notification) ForwardResult {
+	var result ForwardResult
+
+	m.configMutex.RLock()
+	target, ok := m.config.Targets[n.Urgency]
+	m.configMutex.RUnlock()
+	if !ok {
+		return result
+	}
+
+	if target.File != "" {
+		if err := writeFileTarget(target.File, n); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		} else {
+			result.Files++
+		}
+	}
+
+	if target.Terminal || target.Bell {
+		seq := terminalSequence(n, target)
+		for _, tty := range m.userTerminals() {
+			if err := writeNonBlocking(tty, []byte(seq)); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", tty, err))
+				continue
+			}
+			result.Terminals++
+		}
+	}
+
+	return result
+}
+
+// terminalSequence builds an OSC 777 desktop notification, which terminals such
+// as foot, kitty, wezterm and urxvt surface natively, optionally followed by BEL.
+func terminalSequence(n Notification, target ForwardTarget) string {
+	var b strings.Builder
+	if target.Terminal {
+		title := sanitizeOSC(n.Summary)
+		if n.AppName != "" {
+			title = sanitizeOSC(n.AppName) + ": " + title
+		}
+		fmt.Fprintf(&b, "\x1b]777;notify;%s;%s\x07", title, sanitizeOSC(n.Body))
+	}
+	if target.Bell {
+		b.WriteString("\a")
+	}
+	return b.String()
+}
+
+// sanitizeOSC strips C0 and C1 control characters and the ';' separator so
+// notification text cannot terminate or inject escape sequences. C1 matters
+// because many terminals treat U+009B and U+009D like ESC [ and ESC ].
+func sanitizeOSC(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) || r == ';' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func (m *Manager) userTerminals() []string {
+	entries, err := os.ReadDir(m.ptsDir)
+	if err != nil {
+		return nil
+	}
+
+	var ttys []string
+	for _, entry := range entries {
+		if entry.Name() == "ptmx" {
+			continue
+		}
+
+		path := filepath.Join(m.ptsDir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok || int(stat.Uid) != m.uid {
+			continue
+		}
+		ttys = append(ttys, path)
+	}
+	return ttys
+}
+
+func writeFileTarget(path string, n Notification) error {
+	line, err := json.Marshal(struct {
+		Notification
+		Time time.Time `json:"time"`
+	}{n, time.Now()})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	info, err := os.Stat(path)
+	if err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+		return writeNonBlocking(path, line)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(line)
+	return err
+}
+
+// writeNonBlocking avoids hanging the daemon on a FIFO with no reader or a
+// stalled terminal.
+func writeNonBlocking(path string, data []byte) error {
+	fd, err := syscall.Open(path, syscall.O_WRONLY|syscall.O_NONBLOCK|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer syscall.Close(fd)
+
+	_, err = syscall.Write(fd, data)
+	return err
+}
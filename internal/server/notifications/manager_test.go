@@ -0,0 +1,125 @@
+package notifications
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUrgency(t *testing.T) {
+	u, err := ParseUrgency("CRITICAL")
+	require.NoError(t, err)
+	assert.Equal(t, UrgencyCritical, u)
+
+	u, err = ParseUrgency("")
+	require.NoError(t, err)
+	assert.Equal(t, UrgencyNormal, u)
+
+	_, err = ParseUrgency("urgent")
+	assert.Error(t, err)
+}
+
+func TestTerminalSequence(t *testing.T) {
+	n := Notification{AppName: "mail", Summary: "New; message", Body: "hi\x1b]0;pwned\x07"}
+
+	seq := terminalSequence(n, ForwardTarget{Terminal: true, Bell: true})
+	assert.True(t, strings.HasPrefix(seq, "\x1b]777;notify;mail: New  message;"))
+	assert.True(t, strings.HasSuffix(seq, "\x07\a"))
+	assert.Equal(t, 1, strings.Count(seq, "\x1b"), "body escape sequences must be stripped")
+
+	assert.Equal(t, "\a", terminalSequence(n, ForwardTarget{Bell: true}))
+}
+
+func TestSanitizeOSC_C1(t *testing.T) {
+	assert.Equal(t, "a 2J b 0  c", sanitizeOSC("a\u009b2J b\u009d0\u009c c"))
+	assert.Equal(t, "caf\u00e9", sanitizeOSC("caf\u00e9"), "printable Latin-1 is kept")
+}
+
+func TestForward_File(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notifications.log")
+
+	m := newManager(filepath.Join(dir, "pts"), os.Getuid())
+	m.SetTarget(UrgencyCritical, ForwardTarget{File: path})
+
+	res := m.Forward(Notification{Summary: "low battery", Urgency: UrgencyNormal})
+	assert.Equal(t, 0, res.Files, "unconfigured urgency is ignored")
+
+	res = m.Forward(Notification{Summary: "low battery", Urgency: UrgencyCritical})
+	assert.Equal(t, 1, res.Files)
+	assert.Empty(t, res.Errors)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var got Notification
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(string(data))), &got))
+	assert.Equal(t, "low battery", got.Summary)
+	assert.Equal(t, UrgencyCritical, got.Urgency)
+}
+
+func TestForward_FIFOWithoutReader(t *testing.T) {
+	dir := t.TempDir()
+	fifo := filepath.Join(dir, "fifo")
+	require.NoError(t, syscall.Mkfifo(fifo, 0600))
+
+	m := newManager(filepath.Join(dir, "pts"), os.Getuid())
+	m.SetTarget(UrgencyCritical, ForwardTarget{File: fifo})
+
+	res := m.Forward(Notification{Summary: "x", Urgency: UrgencyCritical})
+	assert.Equal(t, 0, res.Files)
+	assert.Len(t, res.Errors, 1)
+}
+
+func TestSetTarget_EmptyRemoves(t *testing.T) {
+	m := newManager(t.TempDir(), os.Getuid())
+	m.SetTarget(UrgencyLow, ForwardTarget{Bell: true})
+	assert.Contains(t, m.GetConfig().Targets, UrgencyLow)
+
+	m.SetTarget(UrgencyLow, ForwardTarget{})
+	assert.NotContains(t, m.GetConfig().Targets, UrgencyLow)
+}
+
+type fakeStore map[Urgency]ForwardTarget
+
+func (f fakeStore) SaveTarget(urgency Urgency, target ForwardTarget) error {
+	f[urgency] = target
+	return nil
+}
+
+func TestNewManager_LoadsAndSavesConfig(t *testing.T) {
+	store := fakeStore{}
+	m, err := NewManager(ForwardConfig{Targets: map[Urgency]ForwardTarget{
+		UrgencyCritical: {Bell: true},
+		UrgencyLow:      {},
+	}}, store)
+	require.NoError(t, err)
+	assert.Equal(t, map[Urgency]ForwardTarget{UrgencyCritical: {Bell: true}}, m.GetConfig().Targets)
+
+	m.SetTarget(UrgencyNormal, ForwardTarget{File: "/tmp/notes.jsonl"})
+	require.NoError(t, m.SaveTarget(UrgencyNormal))
+	m.SetTarget(UrgencyCritical, ForwardTarget{})
+	require.NoError(t, m.SaveTarget(UrgencyCritical))
+	assert.Equal(t, fakeStore{
+		UrgencyNormal:   {File: "/tmp/notes.jsonl"},
+		UrgencyCritical: {},
+	}, store)
+}
+
+func TestUserTerminals(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ptmx"), nil, 0600))
+
+	m := newManager(dir, os.Getuid())
+	assert.Equal(t, []string{filepath.Join(dir, "0")}, m.userTerminals())
+
+	other := newManager(dir, os.Getuid()+1)
+	assert.Empty(t, other.userTerminals())
+}
@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"sync"
+)
+
+type Urgency string
+
+const (
+	UrgencyLow      Urgency = "low"
+	UrgencyNormal   Urgency = "normal"
+	UrgencyCritical Urgency = "critical"
+)
+
+type Notification struct {
+	AppName string  `json:"appName"`
+	Summary string  `json:"summary"`
+	Body    string  `json:"body"`
+	Urgency Urgency `json:"urgency"`
+}
+
+// ForwardTarget configures where notifications of one urgency are mirrored.
+// File may be a regular file (appended as JSON lines) or a FIFO.
+type ForwardTarget struct {
+	File     string `json:"file,omitempty"`
+	Terminal bool   `json:"terminal"`
+	Bell     bool   `json:"bell"`
+}
+
+type ForwardConfig struct {
+	Targets map[Urgency]ForwardTarget `json:"targets"`
+}
+
+type ForwardResult struct {
+	Files     int      `json:"files"`
+	Terminals int      `json:"terminals"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// ConfigStore keeps the forwarding config across daemon restarts
+type ConfigStore interface {
+	SaveTarget(urgency Urgency, target ForwardTarget) error
+}
+
+type Manager struct {
+	configMutex sync.RWMutex
+	config      ForwardConfig
+	store       ConfigStore
+
+	ptsDir string
+	uid    int
+}
+
+func (m *Manager) GetConfig() ForwardConfig {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+
+	targets := make(map[Urgency]ForwardTarget, len(m.config.Targets))
+	for k, v := range m.config.Targets {
+		targets[k] = v
+	}
+	return ForwardConfig{Targets: targets}
+}
@@ -0,0 +1,18 @@
+package power
+
+const (
+	upowerDest      = "org.freedesktop.UPower"
+	upowerPath      = "/org/freedesktop/UPower"
+	upowerInterface = "org.freedesktop.UPower"
+
+	// power-profiles-daemon moved its bus name in 0.20 and still owns the
+	// old one for compatibility, but older releases only have the latter
+	ppdDest            = "org.freedesktop.UPower.PowerProfiles"
+	ppdPath            = "/org/freedesktop/UPower/PowerProfiles"
+	ppdInterface       = "org.freedesktop.UPower.PowerProfiles"
+	ppdLegacyDest      = "net.hadess.PowerProfiles"
+	ppdLegacyPath      = "/net/hadess/PowerProfiles"
+	ppdLegacyInterface = "net.hadess.PowerProfiles"
+
+	dbusPropsInterface = "org.freedesktop.DBus.Properties"
+)
@@ -0,0 +1,127 @@
+package power
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "power.getState":
+		handleGetState(conn, req, manager)
+	case "power.setProfile":
+		handleSetProfile(conn, req, manager)
+	case "power.policy.get":
+		handleGetPolicy(conn, req, manager)
+	case "power.policy.set":
+		handleSetPolicy(conn, req, manager)
+	case "power.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSetProfile(conn net.Conn, req Request, manager *Manager) {
+	profile, ok := req.Params["profile"].(string)
+	if !ok || profile == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'profile' parameter")
+		return
+	}
+
+	if err := manager.SetProfile(profile); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "profile set"})
+}
+
+func handleGetPolicy(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetPolicy())
+}
+
+// handleSetPolicy updates only the fields present in params
+func handleSetPolicy(conn net.Conn, req Request, manager *Manager) {
+	policy := manager.GetPolicy()
+
+	if v, ok := req.Params["enabled"]; ok {
+		enabled, ok := v.(bool)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'enabled' parameter")
+			return
+		}
+		policy.Enabled = enabled
+	}
+	if v, ok := req.Params["acProfile"]; ok {
+		profile, ok := v.(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'acProfile' parameter")
+			return
+		}
+		policy.ACProfile = profile
+	}
+	if v, ok := req.Params["batteryProfile"]; ok {
+		profile, ok := v.(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'batteryProfile' parameter")
+			return
+		}
+		policy.BatteryProfile = profile
+	}
+	if v, ok := req.Params["brightnessCap"]; ok {
+		percent, ok := v.(float64)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'brightnessCap' parameter")
+			return
+		}
+		policy.BatteryBrightnessCap = int(percent)
+	}
+	if v, ok := req.Params["refreshRate"]; ok {
+		rate, ok := v.(float64)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'refreshRate' parameter")
+			return
+		}
+		policy.BatteryRefreshRate = rate
+	}
+
+	if err := manager.SetPolicy(policy); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if err := manager.SavePolicy(); err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("policy changed but not saved: %v", err))
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetPolicy())
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
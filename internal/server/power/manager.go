@@ -0,0 +1,250 @@
+package power
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Enabled:        os.Getenv("DMS_DISABLE_POWER_POLICY") == "",
+		ACProfile:      "balanced",
+		BatteryProfile: "power-saver",
+	}
+}
+
+// NewManager starts watching UPower with the given policy. The current AC
+// state is only recorded; the policy runs on the first plug event.
+func NewManager(policy Policy) (*Manager, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+
+	upower := conn.Object(upowerDest, dbus.ObjectPath(upowerPath))
+	var onBattery bool
+	if err := upower.StoreProperty(upowerInterface+".OnBattery", &onBattery); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("UPower not available: %w", err)
+	}
+
+	var profiles profileBackend
+	var ppdPath dbus.ObjectPath
+	if backend, path, err := findProfileDaemon(conn); err != nil {
+		log.Warnf("power-profiles-daemon not available, profile switching disabled: %v", err)
+	} else {
+		profiles = backend
+		ppdPath = path
+	}
+
+	m := newManager(profiles, policy, onBattery)
+	m.conn = conn
+	m.upower = upower
+	m.ppdPath = ppdPath
+
+	hasBattery, _ := m.displayDevice()
+	m.stateMutex.Lock()
+	m.state.HasBattery = hasBattery
+	m.stateMutex.Unlock()
+
+	m.refreshProfiles()
+
+	if err := m.startSignalPump(); err != nil {
+		m.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func newManager(profiles profileBackend, policy Policy, onBattery bool) *Manager {
+	return &Manager{
+		profiles:      profiles,
+		policy:        policy,
+		lastOnBattery: onBattery,
+		state:         State{OnBattery: onBattery, Policy: policy},
+		subscribers:   make(map[string]chan State),
+		stopChan:      make(chan struct{}),
+	}
+}
+
+// displayDevice reports whether UPower's composite device is a battery
+func (m *Manager) displayDevice() (bool, error) {
+	var path dbus.ObjectPath
+	if err := m.upower.Call(upowerInterface+".GetDisplayDevice", 0).Store(&path); err != nil {
+		return false, err
+	}
+	var present bool
+	device := m.conn.Object(upowerDest, path)
+	if err := device.StoreProperty(upowerInterface+".Device.IsPresent", &present); err != nil {
+		return false, err
+	}
+	return present, nil
+}
+
+// SetHooks installs the optional brightness and refresh rate limiters used
+// by the battery side of the policy, and the store SavePolicy writes to
+func (m *Manager) SetHooks(brightness BrightnessLimiter, refresh RefreshRateLimiter, store PolicyStore) {
+	m.hookMutex.Lock()
+	m.brightness = brightness
+	m.refresh = refresh
+	m.store = store
+	m.hookMutex.Unlock()
+}
+
+func (m *Manager) startSignalPump() error {
+	m.signals = make(chan *dbus.Signal, 64)
+	m.conn.Signal(m.signals)
+
+	if err := m.conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(dbus.ObjectPath(upowerPath)),
+		dbus.WithMatchInterface(dbusPropsInterface),
+		dbus.WithMatchMember("PropertiesChanged"),
+	); err != nil {
+		m.conn.RemoveSignal(m.signals)
+		return fmt.Errorf("failed to watch UPower: %w", err)
+	}
+
+	if m.ppdPath != "" {
+		if err := m.conn.AddMatchSignal(
+			dbus.WithMatchObjectPath(m.ppdPath),
+			dbus.WithMatchInterface(dbusPropsInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		); err != nil {
+			log.Warnf("Failed to watch power-profiles-daemon: %v", err)
+		}
+	}
+
+	m.sigWG.Add(1)
+	go func() {
+		defer m.sigWG.Done()
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case sig, ok := <-m.signals:
+				if !ok {
+					return
+				}
+				m.handleSignal(sig)
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *Manager) handleSignal(sig *dbus.Signal) {
+	if sig == nil || sig.Name != dbusPropsInterface+".PropertiesChanged" || len(sig.Body) < 2 {
+		return
+	}
+	changed, ok := sig.Body[1].(map[string]dbus.Variant)
+	if !ok {
+		return
+	}
+
+	switch sig.Path {
+	case dbus.ObjectPath(upowerPath):
+		if v, ok := changed["OnBattery"]; ok {
+			if onBattery, ok := v.Value().(bool); ok {
+				m.setOnBattery(onBattery)
+			}
+		}
+	case m.ppdPath:
+		_, active := changed["ActiveProfile"]
+		_, profiles := changed["Profiles"]
+		if active || profiles {
+			m.refreshProfiles()
+			m.NotifySubscribers()
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	select {
+	case <-m.stopChan:
+		return
+	default:
+		close(m.stopChan)
+	}
+
+	if m.conn != nil {
+		if m.signals != nil {
+			m.conn.RemoveSignal(m.signals)
+		}
+		m.sigWG.Wait()
+		m.conn.Close()
+	}
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
+
+// ppdBackend reads and writes power-profiles-daemon properties on whichever
+// bus name is available
+type ppdBackend struct {
+	obj   dbus.BusObject
+	iface string
+}
+
+func findProfileDaemon(conn *dbus.Conn) (*ppdBackend, dbus.ObjectPath, error) {
+	candidates := []struct {
+		dest, path, iface string
+	}{
+		{ppdDest, ppdPath, ppdInterface},
+		{ppdLegacyDest, ppdLegacyPath, ppdLegacyInterface},
+	}
+
+	var lastErr error
+	for _, c := range candidates {
+		backend := &ppdBackend{obj: conn.Object(c.dest, dbus.ObjectPath(c.path)), iface: c.iface}
+		if _, err := backend.ActiveProfile(); err != nil {
+			lastErr = err
+			continue
+		}
+		return backend, dbus.ObjectPath(c.path), nil
+	}
+	return nil, "", lastErr
+}
+
+func (b *ppdBackend) Profiles() ([]string, error) {
+	var raw []map[string]dbus.Variant
+	if err := b.obj.StoreProperty(b.iface+".Profiles", &raw); err != nil {
+		return nil, err
+	}
+
+	profiles := make([]string, 0, len(raw))
+	for _, entry := range raw {
+		if v, ok := entry["Profile"]; ok {
+			if name, ok := v.Value().(string); ok {
+				profiles = append(profiles, name)
+			}
+		}
+	}
+	return profiles, nil
+}
+
+func (b *ppdBackend) ActiveProfile() (string, error) {
+	var profile string
+	if err := b.obj.StoreProperty(b.iface+".ActiveProfile", &profile); err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+func (b *ppdBackend) SetActiveProfile(profile string) error {
+	return b.obj.SetProperty(b.iface+".ActiveProfile", dbus.MakeVariant(profile))
+}
+
+// HealthCheck reports whether UPower still answers on our connection
+func (m *Manager) HealthCheck() error {
+	return utils.PingDBus(m.conn, upowerDest)
+}
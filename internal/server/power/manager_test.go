@@ -0,0 +1,208 @@
+package power
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProfiles struct {
+	profiles []string
+	active   string
+	sets     []string
+}
+
+func (f *fakeProfiles) Profiles() ([]string, error)    { return f.profiles, nil }
+func (f *fakeProfiles) ActiveProfile() (string, error) { return f.active, nil }
+func (f *fakeProfiles) SetActiveProfile(profile string) error {
+	f.active = profile
+	f.sets = append(f.sets, profile)
+	return nil
+}
+
+type fakeLimiter struct {
+	caps               []int
+	limits             []float64
+	restores           int
+	brightnessRestores int
+}
+
+func (f *fakeLimiter) CapBrightness(percent int) error {
+	f.caps = append(f.caps, percent)
+	return nil
+}
+
+func (f *fakeLimiter) RestoreBrightness() error {
+	f.brightnessRestores++
+	return nil
+}
+
+func (f *fakeLimiter) LimitRefreshRate(hz float64) error {
+	f.limits = append(f.limits, hz)
+	return nil
+}
+
+func (f *fakeLimiter) RestoreRefreshRate() error {
+	f.restores++
+	return nil
+}
+
+type fakeStore struct {
+	saved []Policy
+}
+
+func (f *fakeStore) SavePolicy(policy Policy) error {
+	f.saved = append(f.saved, policy)
+	return nil
+}
+
+func newTestManager(policy Policy) (*Manager, *fakeProfiles, *fakeLimiter) {
+	profiles := &fakeProfiles{
+		profiles: []string{"power-saver", "balanced", "performance"},
+		active:   "performance",
+	}
+	limiter := &fakeLimiter{}
+	m := newManager(profiles, policy, false)
+	m.refreshProfiles()
+	m.SetHooks(limiter, limiter, nil)
+	return m, profiles, limiter
+}
+
+func TestManager_SwitchesProfileOnTransitions(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Enabled = true
+	m, profiles, _ := newTestManager(policy)
+
+	m.setOnBattery(true)
+	assert.Equal(t, "power-saver", profiles.active)
+	assert.True(t, m.GetState().OnBattery)
+
+	// A manual change sticks while the AC state stays the same
+	require.NoError(t, m.SetProfile("performance"))
+	m.setOnBattery(true)
+	assert.Equal(t, "performance", profiles.active)
+
+	m.setOnBattery(false)
+	assert.Equal(t, "balanced", profiles.active)
+	assert.Equal(t, "balanced", m.GetState().ActiveProfile)
+}
+
+func TestManager_BatteryHooks(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Enabled = true
+	policy.BatteryBrightnessCap = 60
+	policy.BatteryRefreshRate = 60
+	m, _, limiter := newTestManager(policy)
+
+	m.setOnBattery(true)
+	assert.Equal(t, []int{60}, limiter.caps)
+	assert.Equal(t, []float64{60}, limiter.limits)
+
+	m.setOnBattery(false)
+	assert.Equal(t, 1, limiter.restores)
+	assert.Equal(t, 1, limiter.brightnessRestores)
+}
+
+func TestManager_StartupKeepsManualProfile(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Enabled = true
+	policy.BatteryBrightnessCap = 50
+	profiles := &fakeProfiles{profiles: []string{"power-saver", "balanced", "performance"}, active: "performance"}
+	limiter := &fakeLimiter{}
+
+	for _, onBattery := range []bool{false, true} {
+		m := newManager(profiles, policy, onBattery)
+		m.refreshProfiles()
+		m.SetHooks(limiter, limiter, nil)
+		m.setOnBattery(onBattery)
+
+		assert.Equal(t, "performance", profiles.active)
+		assert.Equal(t, onBattery, m.GetState().OnBattery)
+	}
+	assert.Empty(t, profiles.sets)
+	assert.Empty(t, limiter.caps)
+	assert.Zero(t, limiter.restores)
+}
+
+func TestManager_DisabledPolicyDoesNothing(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Enabled = false
+	policy.BatteryBrightnessCap = 50
+	m, profiles, limiter := newTestManager(policy)
+
+	m.setOnBattery(true)
+	assert.Equal(t, "performance", profiles.active)
+	assert.Empty(t, profiles.sets)
+	assert.Empty(t, limiter.caps)
+}
+
+func TestManager_SetPolicy(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.Enabled = true
+	m, profiles, limiter := newTestManager(policy)
+	m.setOnBattery(true)
+	require.NoError(t, m.SetProfile("performance"))
+
+	// A new policy waits for the next plug event
+	updated := m.GetPolicy()
+	updated.BatteryProfile = "balanced"
+	updated.BatteryRefreshRate = 48
+	require.NoError(t, m.SetPolicy(updated))
+	assert.Equal(t, "performance", profiles.active)
+	assert.Empty(t, limiter.limits)
+	assert.Equal(t, updated, m.GetState().Policy)
+
+	m.setOnBattery(false)
+	m.setOnBattery(true)
+	assert.Equal(t, "balanced", profiles.active)
+	assert.Equal(t, []float64{48}, limiter.limits)
+
+	updated.Enabled = false
+	restores := limiter.restores
+	require.NoError(t, m.SetPolicy(updated))
+	assert.Equal(t, restores+1, limiter.restores)
+	assert.Equal(t, 2, limiter.brightnessRestores)
+
+	bad := updated
+	bad.BatteryProfile = "turbo"
+	assert.Error(t, m.SetPolicy(bad))
+	bad = updated
+	bad.BatteryBrightnessCap = 150
+	assert.Error(t, m.SetPolicy(bad))
+	assert.Equal(t, updated, m.GetPolicy())
+}
+
+func TestManager_SetProfileWithoutDaemon(t *testing.T) {
+	m := newManager(nil, DefaultPolicy(), false)
+	assert.Error(t, m.SetProfile("balanced"))
+	assert.False(t, m.GetState().ProfilesAvailable)
+
+	// The policy still runs its hooks without profiles
+	policy := DefaultPolicy()
+	policy.Enabled = true
+	policy.BatteryBrightnessCap = 40
+	limiter := &fakeLimiter{}
+	m.SetHooks(limiter, nil, nil)
+	require.NoError(t, m.SetPolicy(policy))
+	m.setOnBattery(true)
+	assert.Equal(t, []int{40}, limiter.caps)
+}
+
+func TestManager_UnknownProfile(t *testing.T) {
+	m, _, _ := newTestManager(DefaultPolicy())
+	assert.Error(t, m.SetProfile("turbo"))
+}
+
+func TestManager_SavePolicy(t *testing.T) {
+	m, _, _ := newTestManager(DefaultPolicy())
+	require.NoError(t, m.SavePolicy(), "no store is not an error")
+
+	store := &fakeStore{}
+	m.SetHooks(nil, nil, store)
+	policy := m.GetPolicy()
+	policy.BatteryBrightnessCap = 30
+	require.NoError(t, m.SetPolicy(policy))
+	require.NoError(t, m.SavePolicy())
+	assert.Equal(t, []Policy{policy}, store.saved)
+}
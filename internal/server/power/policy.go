@@ -0,0 +1,192 @@
+package power
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// setOnBattery records the AC state and runs the matching side of the
+// policy when it flipped
+func (m *Manager) setOnBattery(onBattery bool) {
+	m.stateMutex.Lock()
+	m.state.OnBattery = onBattery
+	m.stateMutex.Unlock()
+
+	m.policyMutex.Lock()
+	flipped := m.lastOnBattery != onBattery
+	m.lastOnBattery = onBattery
+	policy := m.policy
+	m.policyMutex.Unlock()
+
+	if flipped && policy.Enabled {
+		m.applyPolicy(policy, onBattery)
+	}
+	m.NotifySubscribers()
+}
+
+// applyPolicy switches the profile and, on battery, caps brightness and
+// refresh rate; on AC both are given back. It only runs on plug events, so
+// a profile the user picks by hand sticks until the next one and starting
+// the daemon leaves everything as it is.
+func (m *Manager) applyPolicy(policy Policy, onBattery bool) {
+	profile := policy.ACProfile
+	if onBattery {
+		profile = policy.BatteryProfile
+	}
+	if profile != "" && m.profiles != nil {
+		if err := m.setProfile(profile); err != nil {
+			log.Warnf("Power policy: %v", err)
+		}
+	}
+
+	if !onBattery {
+		m.restoreLimits()
+		return
+	}
+
+	m.hookMutex.RLock()
+	brightness, refresh := m.brightness, m.refresh
+	m.hookMutex.RUnlock()
+
+	if policy.BatteryBrightnessCap > 0 && brightness != nil {
+		if err := brightness.CapBrightness(policy.BatteryBrightnessCap); err != nil {
+			log.Warnf("Power policy: failed to cap brightness: %v", err)
+		}
+	}
+	if policy.BatteryRefreshRate > 0 && refresh != nil {
+		if err := refresh.LimitRefreshRate(policy.BatteryRefreshRate); err != nil {
+			log.Warnf("Power policy: %v", err)
+		}
+	}
+}
+
+// restoreLimits undoes the battery brightness cap and refresh rate limit
+func (m *Manager) restoreLimits() {
+	m.hookMutex.RLock()
+	brightness, refresh := m.brightness, m.refresh
+	m.hookMutex.RUnlock()
+
+	if brightness != nil {
+		if err := brightness.RestoreBrightness(); err != nil {
+			log.Warnf("Power policy: failed to restore brightness: %v", err)
+		}
+	}
+	if refresh != nil {
+		if err := refresh.RestoreRefreshRate(); err != nil {
+			log.Warnf("Power policy: %v", err)
+		}
+	}
+}
+
+func (m *Manager) refreshProfiles() {
+	if m.profiles == nil {
+		return
+	}
+
+	profiles, err := m.profiles.Profiles()
+	if err != nil {
+		log.Warnf("Failed to read power profiles: %v", err)
+		return
+	}
+	active, err := m.profiles.ActiveProfile()
+	if err != nil {
+		log.Warnf("Failed to read active power profile: %v", err)
+		return
+	}
+
+	m.stateMutex.Lock()
+	m.state.Profiles = profiles
+	m.state.ActiveProfile = active
+	m.state.ProfilesAvailable = true
+	m.stateMutex.Unlock()
+}
+
+func (m *Manager) setProfile(profile string) error {
+	m.stateMutex.RLock()
+	known := slices.Contains(m.state.Profiles, profile)
+	m.stateMutex.RUnlock()
+	if !known {
+		return fmt.Errorf("unknown power profile: %s", profile)
+	}
+
+	if err := m.profiles.SetActiveProfile(profile); err != nil {
+		return fmt.Errorf("failed to set power profile %s: %w", profile, err)
+	}
+
+	m.stateMutex.Lock()
+	m.state.ActiveProfile = profile
+	m.stateMutex.Unlock()
+	return nil
+}
+
+// SetProfile switches the power profile by hand
+func (m *Manager) SetProfile(profile string) error {
+	if m.profiles == nil {
+		return fmt.Errorf("power-profiles-daemon not available")
+	}
+	if err := m.setProfile(profile); err != nil {
+		return err
+	}
+	m.NotifySubscribers()
+	return nil
+}
+
+func (m *Manager) GetPolicy() Policy {
+	m.policyMutex.Lock()
+	defer m.policyMutex.Unlock()
+	return m.policy
+}
+
+// SetPolicy validates and stores a policy. It takes effect on the next plug
+// event, except that turning it off while unplugged gives back brightness
+// and refresh rate.
+func (m *Manager) SetPolicy(policy Policy) error {
+	if policy.BatteryBrightnessCap < 0 || policy.BatteryBrightnessCap > 100 {
+		return fmt.Errorf("brightness cap must be between 0 and 100")
+	}
+	if policy.BatteryRefreshRate < 0 {
+		return fmt.Errorf("refresh rate must not be negative")
+	}
+
+	m.stateMutex.RLock()
+	available := m.state.ProfilesAvailable
+	profiles := m.state.Profiles
+	m.stateMutex.RUnlock()
+	if available {
+		for _, p := range []string{policy.ACProfile, policy.BatteryProfile} {
+			if p != "" && !slices.Contains(profiles, p) {
+				return fmt.Errorf("unknown power profile: %s", p)
+			}
+		}
+	}
+
+	m.policyMutex.Lock()
+	wasEnabled := m.policy.Enabled
+	m.policy = policy
+	onBattery := m.lastOnBattery
+	m.policyMutex.Unlock()
+
+	m.stateMutex.Lock()
+	m.state.Policy = policy
+	m.stateMutex.Unlock()
+
+	if wasEnabled && !policy.Enabled && onBattery {
+		m.restoreLimits()
+	}
+
+	m.NotifySubscribers()
+	return nil
+}
+
+// SavePolicy writes the current policy to the store, if one is set
+func (m *Manager) SavePolicy() error {
+	m.hookMutex.RLock()
+	store := m.store
+	m.hookMutex.RUnlock()
+	if store == nil {
+		return nil
+	}
+	return store.SavePolicy(m.GetPolicy())
+}
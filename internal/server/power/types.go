@@ -0,0 +1,129 @@
+package power
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Policy decides what happens when the machine is unplugged. Zero values for
+// the brightness cap and refresh rate leave them alone.
+type Policy struct {
+	Enabled              bool    `json:"enabled"`
+	ACProfile            string  `json:"acProfile"`
+	BatteryProfile       string  `json:"batteryProfile"`
+	BatteryBrightnessCap int     `json:"batteryBrightnessCap"`
+	BatteryRefreshRate   float64 `json:"batteryRefreshRate"`
+}
+
+type State struct {
+	OnBattery         bool     `json:"onBattery"`
+	HasBattery        bool     `json:"hasBattery"`
+	ActiveProfile     string   `json:"activeProfile"`
+	Profiles          []string `json:"profiles"`
+	ProfilesAvailable bool     `json:"profilesAvailable"`
+	Policy            Policy   `json:"policy"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// BrightnessLimiter lowers internal displays to at most percent and puts
+// back what they were at on restore
+type BrightnessLimiter interface {
+	CapBrightness(percent int) error
+	RestoreBrightness() error
+}
+
+// RefreshRateLimiter caps output refresh rates and undoes it on restore
+type RefreshRateLimiter interface {
+	LimitRefreshRate(hz float64) error
+	RestoreRefreshRate() error
+}
+
+// PolicyStore keeps the policy across daemon restarts
+type PolicyStore interface {
+	SavePolicy(policy Policy) error
+}
+
+// profileBackend talks to power-profiles-daemon
+type profileBackend interface {
+	Profiles() ([]string, error)
+	ActiveProfile() (string, error)
+	SetActiveProfile(profile string) error
+}
+
+type Manager struct {
+	conn     *dbus.Conn
+	upower   dbus.BusObject
+	profiles profileBackend
+	ppdPath  dbus.ObjectPath
+	signals  chan *dbus.Signal
+	sigWG    sync.WaitGroup
+
+	policyMutex sync.Mutex
+	policy      Policy
+	// lastOnBattery is the AC state last seen, so only a flip runs the
+	// policy and repeated property signals don't fight manual changes
+	lastOnBattery bool
+
+	hookMutex  sync.RWMutex
+	brightness BrightnessLimiter
+	refresh    RefreshRateLimiter
+	store      PolicyStore
+
+	stateMutex sync.RWMutex
+	state      State
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+
+	stopChan chan struct{}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) NotifySubscribers() {
+	state := m.GetState()
+
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	state := m.state
+	state.Profiles = append([]string(nil), m.state.Profiles...)
+	return state
+}
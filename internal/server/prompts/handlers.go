@@ -0,0 +1,86 @@
+package prompts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "prompts.list":
+		handleList(conn, req, manager)
+	case "prompts.respond":
+		handleRespond(conn, req, manager)
+	case "prompts.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleList(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.Pending())
+}
+
+func handleRespond(conn net.Conn, req Request, manager *Manager) {
+	token, ok := req.Params["token"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'token' parameter")
+		return
+	}
+
+	action, ok := req.Params["action"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'action' parameter")
+		return
+	}
+
+	resp := Response{Action: action}
+	if value, ok := req.Params["value"].(string); ok {
+		resp.Value = value
+	}
+	if secrets, ok := req.Params["secrets"].(map[string]interface{}); ok {
+		resp.Secrets = make(map[string]string, len(secrets))
+		for k, v := range secrets {
+			if s, ok := v.(string); ok {
+				resp.Secrets[k] = s
+			}
+		}
+	}
+	if remember, ok := req.Params["remember"].(bool); ok {
+		resp.Remember = remember
+	}
+
+	if err := manager.Respond(token, resp); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "response delivered"})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	eventChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	for _, p := range manager.Pending() {
+		if err := json.NewEncoder(conn).Encode(models.Response[Event]{
+			ID:     req.ID,
+			Result: &Event{Type: "prompt", Prompt: p},
+		}); err != nil {
+			return
+		}
+	}
+
+	for event := range eventChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[Event]{
+			ID:     req.ID,
+			Result: &event,
+		}); err != nil {
+			return
+		}
+	}
+}
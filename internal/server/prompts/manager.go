@@ -0,0 +1,211 @@
+package prompts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const DefaultTimeout = 60 * time.Second
+
+func NewManager() (*Manager, error) {
+	timeout := DefaultTimeout
+	if s := os.Getenv("DMS_PROMPT_TIMEOUT"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil && secs > 0 {
+			timeout = time.Duration(secs) * time.Second
+		} else {
+			log.Warnf("Ignoring invalid DMS_PROMPT_TIMEOUT: %s", s)
+		}
+	}
+
+	return newManager(timeout), nil
+}
+
+func newManager(timeout time.Duration) *Manager {
+	return &Manager{
+		defaultTimeout: timeout,
+		pending:        make(map[string]*pendingPrompt),
+		subscribers:    make(map[string]chan Event),
+	}
+}
+
+// Ask publishes a prompt and blocks until the shell answers via Respond, the
+// prompt times out (yielding its Default action) or ctx is cancelled.
+func (m *Manager) Ask(ctx context.Context, req PromptRequest) (Response, error) {
+	p, err := m.Open(req)
+	if err != nil {
+		return Response{}, err
+	}
+	return m.Wait(ctx, p.Token)
+}
+
+// Open publishes a prompt without waiting for it, for modules whose own
+// protocol hands out the token before the answer arrives. Every opened
+// prompt must be collected with Wait.
+func (m *Manager) Open(req PromptRequest) (Prompt, error) {
+	p, err := m.newPrompt(req)
+	if err != nil {
+		return Prompt{}, err
+	}
+
+	m.mu.Lock()
+	m.pending[p.Token] = &pendingPrompt{prompt: p, reply: make(chan Response, 1)}
+	m.mu.Unlock()
+
+	m.notify(Event{Type: "prompt", Prompt: p})
+	return p, nil
+}
+
+// Wait blocks until the prompt behind token is answered, times out or ctx
+// is cancelled, as Ask does
+func (m *Manager) Wait(ctx context.Context, token string) (Response, error) {
+	m.mu.RLock()
+	pending, ok := m.pending[token]
+	m.mu.RUnlock()
+	if !ok {
+		return Response{}, fmt.Errorf("unknown or expired prompt: %s", token)
+	}
+	p := pending.prompt
+
+	timer := time.NewTimer(time.Until(p.ExpiresAt))
+	defer timer.Stop()
+
+	var resp Response
+	select {
+	case resp = <-pending.reply:
+	case <-timer.C:
+		resp = Response{Action: p.Default, TimedOut: true}
+	case <-ctx.Done():
+		m.resolve(p, Response{Action: p.Default})
+		return Response{}, ctx.Err()
+	}
+
+	m.resolve(p, resp)
+	return resp, nil
+}
+
+// Confirm is a convenience wrapper for yes/no consent.
+func (m *Manager) Confirm(ctx context.Context, source, title, message string) (bool, error) {
+	resp, err := m.Ask(ctx, PromptRequest{
+		Source:  source,
+		Kind:    KindConfirm,
+		Title:   title,
+		Message: message,
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Action == ActionAllow, nil
+}
+
+func (m *Manager) Respond(token string, resp Response) error {
+	m.mu.RLock()
+	pending, ok := m.pending[token]
+	m.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("unknown or expired prompt: %s", token)
+	}
+
+	if !slices.Contains(pending.prompt.Actions, resp.Action) {
+		return fmt.Errorf("invalid action %q (expected one of %v)", resp.Action, pending.prompt.Actions)
+	}
+
+	select {
+	case pending.reply <- resp:
+		return nil
+	default:
+		return fmt.Errorf("prompt already answered: %s", token)
+	}
+}
+
+// Pending lists open prompts, oldest first, so a shell that connects late can
+// still show them.
+func (m *Manager) Pending() []Prompt {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prompts := make([]Prompt, 0, len(m.pending))
+	for _, p := range m.pending {
+		prompts = append(prompts, p.prompt)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].ExpiresAt.Before(prompts[j].ExpiresAt)
+	})
+	return prompts
+}
+
+func (m *Manager) newPrompt(req PromptRequest) (Prompt, error) {
+	if req.Source == "" || req.Title == "" {
+		return Prompt{}, fmt.Errorf("prompt requires source and title")
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return Prompt{}, err
+	}
+
+	actions := req.Actions
+	if len(actions) == 0 {
+		actions = []string{ActionAllow, ActionDeny}
+	}
+
+	def := req.Default
+	if def == "" {
+		def = ActionDeny
+	}
+	if !slices.Contains(actions, def) {
+		return Prompt{}, fmt.Errorf("default action %q is not one of %v", def, actions)
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = KindConfirm
+	}
+
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = m.defaultTimeout
+	}
+
+	return Prompt{
+		Token:     token,
+		Source:    req.Source,
+		Kind:      kind,
+		Title:     req.Title,
+		Message:   req.Message,
+		Fields:    req.Fields,
+		Details:   req.Details,
+		Actions:   actions,
+		Default:   def,
+		ExpiresAt: time.Now().Add(timeout),
+	}, nil
+}
+
+// resolve retires the prompt and tells subscribers how it ended. Secrets
+// and typed values only go to the waiting caller, never to subscribers.
+func (m *Manager) resolve(p Prompt, resp Response) {
+	m.mu.Lock()
+	delete(m.pending, p.Token)
+	m.mu.Unlock()
+
+	resp.Value = ""
+	resp.Secrets = nil
+	m.notify(Event{Type: "resolved", Prompt: p, Response: &resp})
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,170 @@
+package prompts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForPrompt(t *testing.T, events chan Event) Prompt {
+	t.Helper()
+	select {
+	case event := <-events:
+		require.Equal(t, "prompt", event.Type)
+		return event.Prompt
+	case <-time.After(time.Second):
+		t.Fatal("prompt was not published")
+		return Prompt{}
+	}
+}
+
+func TestAsk_Respond(t *testing.T) {
+	m := newManager(time.Minute)
+	events := m.Subscribe("test")
+
+	done := make(chan Response, 1)
+	go func() {
+		resp, err := m.Ask(context.Background(), PromptRequest{
+			Source:  "bluez",
+			Kind:    KindPairing,
+			Title:   "Pair with headphones?",
+			Actions: []string{"pair", "cancel"},
+			Default: "cancel",
+		})
+		assert.NoError(t, err)
+		done <- resp
+	}()
+
+	p := waitForPrompt(t, events)
+	assert.Equal(t, KindPairing, p.Kind)
+	assert.Len(t, m.Pending(), 1)
+
+	assert.Error(t, m.Respond(p.Token, Response{Action: ActionAllow}), "action must be one of the prompt's actions")
+	require.NoError(t, m.Respond(p.Token, Response{Action: "pair"}))
+
+	resp := <-done
+	assert.Equal(t, "pair", resp.Action)
+	assert.False(t, resp.TimedOut)
+	assert.Empty(t, m.Pending())
+
+	resolved := <-events
+	assert.Equal(t, "resolved", resolved.Type)
+	require.NotNil(t, resolved.Response)
+	assert.Equal(t, "pair", resolved.Response.Action)
+
+	assert.Error(t, m.Respond(p.Token, Response{Action: "pair"}), "resolved prompts reject late answers")
+}
+
+func TestResolvedEventOmitsSecrets(t *testing.T) {
+	m := newManager(time.Minute)
+	events := m.Subscribe("test")
+
+	done := make(chan Response, 1)
+	go func() {
+		resp, err := m.Ask(context.Background(), PromptRequest{
+			Source: "network",
+			Kind:   KindSecret,
+			Title:  "Password for Home",
+			Fields: []string{"psk"},
+		})
+		assert.NoError(t, err)
+		done <- resp
+	}()
+
+	p := waitForPrompt(t, events)
+	require.NoError(t, m.Respond(p.Token, Response{
+		Action:  ActionAllow,
+		Value:   "123456",
+		Secrets: map[string]string{"psk": "hunter22"},
+	}))
+
+	resp := <-done
+	assert.Equal(t, "hunter22", resp.Secrets["psk"])
+	assert.Equal(t, "123456", resp.Value)
+
+	resolved := <-events
+	require.NotNil(t, resolved.Response)
+	assert.Equal(t, ActionAllow, resolved.Response.Action)
+	assert.Nil(t, resolved.Response.Secrets)
+	assert.Empty(t, resolved.Response.Value)
+}
+
+func TestAsk_TimeoutAppliesDefault(t *testing.T) {
+	m := newManager(time.Minute)
+
+	resp, err := m.Ask(context.Background(), PromptRequest{
+		Source:  "plugins",
+		Kind:    KindPermission,
+		Title:   "Allow network access?",
+		Timeout: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ActionDeny, resp.Action)
+	assert.True(t, resp.TimedOut)
+	assert.Empty(t, m.Pending())
+}
+
+func TestAsk_ContextCancelled(t *testing.T) {
+	m := newManager(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := m.Ask(ctx, PromptRequest{Source: "system", Title: "Reboot?"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, m.Pending())
+}
+
+func TestAsk_InvalidRequest(t *testing.T) {
+	m := newManager(time.Minute)
+
+	_, err := m.Ask(context.Background(), PromptRequest{Title: "no source"})
+	assert.Error(t, err)
+
+	_, err = m.Ask(context.Background(), PromptRequest{Source: "x", Title: "y", Actions: []string{"a"}, Default: "b"})
+	assert.Error(t, err)
+}
+
+func TestConfirm(t *testing.T) {
+	m := newManager(time.Minute)
+	events := m.Subscribe("test")
+
+	go func() {
+		p := waitForPrompt(t, events)
+		assert.Equal(t, []string{ActionAllow, ActionDeny}, p.Actions)
+		assert.Equal(t, ActionDeny, p.Default)
+		assert.NoError(t, m.Respond(p.Token, Response{Action: ActionAllow}))
+	}()
+
+	ok, err := m.Confirm(context.Background(), "system", "Suspend now?", "")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestOpen_WaitCarriesSecrets(t *testing.T) {
+	m := newManager(time.Minute)
+
+	p, err := m.Open(PromptRequest{
+		Source:  "network",
+		Kind:    KindSecret,
+		Title:   "Authentication required for Home",
+		Fields:  []string{"psk"},
+		Details: map[string]string{"ssid": "Home"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"psk"}, p.Fields)
+	require.Len(t, m.Pending(), 1)
+
+	require.NoError(t, m.Respond(p.Token, Response{Action: ActionAllow, Secrets: map[string]string{"psk": "hunter22"}, Remember: true}))
+
+	resp, err := m.Wait(context.Background(), p.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter22", resp.Secrets["psk"])
+	assert.True(t, resp.Remember)
+	assert.Empty(t, m.Pending())
+
+	_, err = m.Wait(context.Background(), p.Token)
+	assert.Error(t, err, "a collected prompt can't be waited on again")
+}
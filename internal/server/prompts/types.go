@@ -0,0 +1,131 @@
+package prompts
+
+import (
+	"sync"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindConfirm    Kind = "confirm"
+	KindPairing    Kind = "pairing"
+	KindPermission Kind = "permission"
+	KindSecret     Kind = "secret"
+)
+
+const (
+	ActionAllow = "allow"
+	ActionDeny  = "deny"
+)
+
+// PromptRequest is filed by a module that needs user consent. Actions default
+// to allow/deny, Default (taken on timeout) to deny and Timeout to the
+// manager's default. Fields names the secrets the answer should carry, such
+// as a PIN or passphrase; Details is whatever else the shell needs to show,
+// keyed by the filing module.
+type PromptRequest struct {
+	Source  string
+	Kind    Kind
+	Title   string
+	Message string
+	Fields  []string
+	Details map[string]string
+	Actions []string
+	Default string
+	Timeout time.Duration
+}
+
+type Prompt struct {
+	Token     string            `json:"token"`
+	Source    string            `json:"source"`
+	Kind      Kind              `json:"kind"`
+	Title     string            `json:"title"`
+	Message   string            `json:"message,omitempty"`
+	Fields    []string          `json:"fields,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	Actions   []string          `json:"actions"`
+	Default   string            `json:"default"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// Response is the shell's answer. Secrets fill the prompt's Fields;
+// Remember asks the module to keep them, as network secrets are saved to
+// the connection.
+type Response struct {
+	Action   string            `json:"action"`
+	Value    string            `json:"value,omitempty"`
+	Secrets  map[string]string `json:"secrets,omitempty"`
+	Remember bool              `json:"remember,omitempty"`
+	TimedOut bool              `json:"timedOut,omitempty"`
+}
+
+type Event struct {
+	Type     string    `json:"type"`
+	Prompt   Prompt    `json:"prompt"`
+	Response *Response `json:"response,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type pendingPrompt struct {
+	prompt Prompt
+	reply  chan Response
+}
+
+type Manager struct {
+	defaultTimeout time.Duration
+
+	mu      sync.RWMutex
+	pending map[string]*pendingPrompt
+
+	subscribers map[string]chan Event
+	subMutex    sync.RWMutex
+}
+
+func (m *Manager) Subscribe(id string) chan Event {
+	ch := make(chan Event, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notify(event Event) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan Event)
+	m.subMutex.Unlock()
+}
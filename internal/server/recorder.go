@@ -0,0 +1,321 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+// defaultRecordingMaxBytes caps a recording. Once reached the file is moved
+// to <path>.1 and a new one started, so at most twice this is kept on disk.
+const defaultRecordingMaxBytes = 8 << 20
+
+// RecordingStatus describes the IPC traffic recording
+type RecordingStatus struct {
+	Active   bool   `json:"active"`
+	Path     string `json:"path,omitempty"`
+	MaxBytes int64  `json:"maxBytes,omitempty"`
+	Written  int64  `json:"written"`
+	Entries  int    `json:"entries"`
+	Rotated  bool   `json:"rotated"`
+}
+
+// recordEntry is one line of a recording. Message is the sanitized JSON the
+// client sent or received.
+type recordEntry struct {
+	Time      time.Time       `json:"time"`
+	Conn      int64           `json:"conn"`
+	Direction string          `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// trafficRecorder logs request and response lines to a capped file while a
+// user reproduces a bug. It is off until debug.record.start is called.
+type trafficRecorder struct {
+	mu       sync.Mutex
+	active   atomic.Bool
+	file     *os.File
+	path     string
+	maxBytes int64
+	written  int64
+	entries  int
+	rotated  bool
+	redactor *crash.Redactor
+	now      func() time.Time
+	nextConn atomic.Int64
+	// dir overrides crash.Dir for tests
+	dir string
+}
+
+var ipcRecorder = &trafficRecorder{now: time.Now}
+
+// defaultRecordingName is the recording file used when the client names
+// none
+const defaultRecordingName = "ipc-recording.jsonl"
+
+// recordingPath resolves a client-supplied file name inside the crash
+// directory, where dms crash-report users already look. Any client on the
+// socket can start a recording, so it never gets to pick a directory or a
+// file that is not a recording.
+func (r *trafficRecorder) recordingPath(name string) (string, error) {
+	if name == "" {
+		name = defaultRecordingName
+	}
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".jsonl") {
+		return "", fmt.Errorf("invalid recording name %q: expected a file name ending in .jsonl", name)
+	}
+	dir := r.dir
+	if dir == "" {
+		dir = crash.Dir()
+	}
+	return filepath.Join(dir, name), nil
+}
+
+func (r *trafficRecorder) start(name string, maxBytes int64) (RecordingStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		return r.statusLocked(), fmt.Errorf("already recording to %s", r.path)
+	}
+	path, err := r.recordingPath(name)
+	if err != nil {
+		return RecordingStatus{}, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultRecordingMaxBytes
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return RecordingStatus{}, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|syscall.O_NOFOLLOW, 0600)
+	if err != nil {
+		return RecordingStatus{}, fmt.Errorf("failed to open recording: %w", err)
+	}
+
+	r.file = file
+	r.path = path
+	r.maxBytes = maxBytes
+	r.written = 0
+	r.entries = 0
+	r.rotated = false
+	if r.redactor == nil {
+		r.redactor = crash.NewRedactor()
+	}
+	r.active.Store(true)
+	return r.statusLocked(), nil
+}
+
+func (r *trafficRecorder) stop() (RecordingStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return r.statusLocked(), fmt.Errorf("not recording")
+	}
+	r.active.Store(false)
+	status := r.statusLocked()
+	status.Active = false
+	err := r.file.Close()
+	r.file = nil
+	return status, err
+}
+
+func (r *trafficRecorder) status() RecordingStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.statusLocked()
+}
+
+func (r *trafficRecorder) statusLocked() RecordingStatus {
+	return RecordingStatus{
+		Active:   r.file != nil,
+		Path:     r.path,
+		MaxBytes: r.maxBytes,
+		Written:  r.written,
+		Entries:  r.entries,
+		Rotated:  r.rotated,
+	}
+}
+
+// record appends one message. Errors stop the recording rather than the
+// request, since a full disk must not break the shell.
+func (r *trafficRecorder) record(conn int64, direction string, message []byte) {
+	if !r.active.Load() {
+		return
+	}
+	message = []byte(strings.TrimSpace(string(message)))
+	if len(message) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+
+	line, err := json.Marshal(recordEntry{
+		Time:      r.now(),
+		Conn:      conn,
+		Direction: direction,
+		Message:   sanitizeMessage(message, r.redactor),
+	})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	if r.written+int64(len(line)) > r.maxBytes && r.written > 0 {
+		if err := r.rotateLocked(); err != nil {
+			r.active.Store(false)
+			r.file.Close()
+			r.file = nil
+			return
+		}
+	}
+
+	n, err := r.file.Write(line)
+	r.written += int64(n)
+	if err != nil {
+		r.active.Store(false)
+		r.file.Close()
+		r.file = nil
+		return
+	}
+	r.entries++
+}
+
+func (r *trafficRecorder) rotateLocked() error {
+	r.file.Close()
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.written = 0
+	r.rotated = true
+	return nil
+}
+
+// wrap gives conn a recording identity. Every connection is wrapped so a
+// recording started later still sees it; writes cost one atomic load while
+// nothing is recorded.
+func (r *trafficRecorder) wrap(conn net.Conn) *recordingConn {
+	return &recordingConn{Conn: conn, id: r.nextConn.Add(1), recorder: r}
+}
+
+type recordingConn struct {
+	net.Conn
+	id       int64
+	recorder *trafficRecorder
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.recorder.record(c.id, "response", b[:n])
+	return n, err
+}
+
+func (c *recordingConn) Unwrap() net.Conn { return c.Conn }
+
+func (c *recordingConn) recordRequest(line []byte) {
+	c.recorder.record(c.id, "request", line)
+}
+
+// secretKeys name params and results whose values never go into a recording
+var secretKeys = []string{"password", "passphrase", "secret", "token", "psk", "passkey", "pin", "credential", "cookie"}
+
+// identifyingKeys are redacted like crash reports redact them in free text
+var identifyingKeys = map[string]string{
+	"ssid":  "<ssid>",
+	"bssid": "<mac>",
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if key == s || (len(s) > 3 && strings.Contains(key, s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeMessage redacts secret values by key, then home, user and host
+// names, addresses and SSIDs anywhere in the text. Lines that are not JSON
+// are kept as a redacted string.
+func sanitizeMessage(message []byte, redactor *crash.Redactor) json.RawMessage {
+	var v any
+	if err := json.Unmarshal(message, &v); err != nil {
+		quoted, _ := json.Marshal(redactor.Redact(string(message)))
+		return quoted
+	}
+	redacted, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return json.RawMessage(`null`)
+	}
+	return json.RawMessage(redactor.Redact(string(redacted)))
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch {
+			case isSecretKey(key):
+				if value != nil && value != "" {
+					v[key] = "<redacted>"
+				}
+			case identifyingKeys[strings.ToLower(key)] != "":
+				if s, ok := value.(string); ok && s != "" {
+					v[key] = identifyingKeys[strings.ToLower(key)]
+				}
+			default:
+				v[key] = redactValue(value)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return v
+}
+
+func handleRecordStart(conn net.Conn, req models.Request) {
+	name, _ := req.Params["name"].(string)
+	var maxBytes int64
+	if value, ok := req.Params["maxBytes"].(float64); ok {
+		maxBytes = int64(value)
+	}
+	status, err := ipcRecorder.start(name, maxBytes)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, status)
+}
+
+func handleRecordStop(conn net.Conn, req models.Request) {
+	status, err := ipcRecorder.stop()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, status)
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRecorder(t *testing.T) *trafficRecorder {
+	return &trafficRecorder{
+		redactor: crash.NewRedactor(),
+		now:      func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) },
+		dir:      t.TempDir(),
+	}
+}
+
+func readRecording(t *testing.T, path string) []recordEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var entries []recordEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e recordEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestTrafficRecorder_RecordsRequestsAndResponses(t *testing.T) {
+	r := newTestRecorder(t)
+	path := filepath.Join(r.dir, "rec.jsonl")
+
+	conn := r.wrap(&mockConn{})
+	conn.recordRequest([]byte(`{"id":1,"method":"ping"}`))
+	models.Respond(conn, 1, "pong")
+	assert.NoFileExists(t, path, "nothing is written before start")
+
+	status, err := r.start("rec.jsonl", 0)
+	require.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.Equal(t, int64(defaultRecordingMaxBytes), status.MaxBytes)
+
+	conn.recordRequest([]byte(`{"id":2,"method":"ping"}`))
+	models.Respond(conn, 2, "pong")
+
+	status, err = r.stop()
+	require.NoError(t, err)
+	assert.False(t, status.Active)
+	assert.Equal(t, 2, status.Entries)
+
+	entries := readRecording(t, path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "request", entries[0].Direction)
+	assert.JSONEq(t, `{"id":2,"method":"ping"}`, string(entries[0].Message))
+	assert.Equal(t, "response", entries[1].Direction)
+	assert.JSONEq(t, `{"id":2,"result":"pong"}`, string(entries[1].Message))
+	assert.Equal(t, conn.id, entries[0].Conn)
+
+	models.Respond(conn, 3, "pong")
+	assert.Len(t, readRecording(t, path), 2, "nothing is written after stop")
+}
+
+func TestTrafficRecorder_StartStopErrors(t *testing.T) {
+	r := newTestRecorder(t)
+
+	_, err := r.stop()
+	assert.Error(t, err)
+
+	_, err = r.start("rec.jsonl", 0)
+	require.NoError(t, err)
+	_, err = r.start("rec.jsonl", 0)
+	assert.Error(t, err)
+	_, err = r.stop()
+	assert.NoError(t, err)
+}
+
+func TestTrafficRecorder_Rotates(t *testing.T) {
+	r := newTestRecorder(t)
+	path := filepath.Join(r.dir, "rec.jsonl")
+	_, err := r.start("rec.jsonl", 300)
+	require.NoError(t, err)
+
+	conn := r.wrap(&mockConn{})
+	for i := 0; i < 10; i++ {
+		conn.recordRequest([]byte(`{"id":1,"method":"ping"}`))
+	}
+	status, err := r.stop()
+	require.NoError(t, err)
+
+	assert.True(t, status.Rotated)
+	assert.FileExists(t, path+".1")
+	for _, p := range []string{path, path + ".1"} {
+		info, err := os.Stat(p)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(300))
+	}
+}
+
+func TestTrafficRecorder_StaysInCrashDir(t *testing.T) {
+	r := newTestRecorder(t)
+	outside := filepath.Join(t.TempDir(), "victim.jsonl")
+	require.NoError(t, os.WriteFile(outside, []byte("keep"), 0600))
+
+	for _, name := range []string{outside, "../victim.jsonl", "sub/rec.jsonl", ".hidden.jsonl", "notes.txt", ".."} {
+		_, err := r.start(name, 0)
+		assert.Error(t, err, name)
+	}
+	data, err := os.ReadFile(outside)
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(data))
+
+	require.NoError(t, os.Symlink(outside, filepath.Join(r.dir, "link.jsonl")))
+	_, err = r.start("link.jsonl", 0)
+	assert.Error(t, err, "symlinks are not followed")
+
+	status, err := r.start("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(r.dir, defaultRecordingName), status.Path)
+	_, err = r.stop()
+	require.NoError(t, err)
+}
+
+func TestSanitizeMessage(t *testing.T) {
+	redactor := crash.NewRedactor()
+
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "secrets by key",
+			message:  `{"id":1,"method":"network.wifi.connect","params":{"ssid":"HomeNet","password":"hunter2","confirmToken":"abc"}}`,
+			expected: `{"id":1,"method":"network.wifi.connect","params":{"ssid":"<ssid>","password":"<redacted>","confirmToken":"<redacted>"}}`,
+		},
+		{
+			name:     "nested in results",
+			message:  `{"id":2,"result":{"networks":[{"ssid":"Cafe","bssid":"aa:bb:cc:dd:ee:ff","secured":true}]}}`,
+			expected: `{"id":2,"result":{"networks":[{"ssid":"<ssid>","bssid":"<mac>","secured":true}]}}`,
+		},
+		{
+			name:     "addresses in free text",
+			message:  `{"id":3,"result":{"ip":"192.168.1.20","mail":"me@example.com"}}`,
+			expected: `{"id":3,"result":{"ip":"<ip>","mail":"<email>"}}`,
+		},
+		{
+			name:     "empty secrets stay empty",
+			message:  `{"id":4,"params":{"pin":""}}`,
+			expected: `{"id":4,"params":{"pin":""}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.expected, string(sanitizeMessage([]byte(tt.message), redactor)))
+		})
+	}
+
+	t.Run("non-JSON is kept as a string", func(t *testing.T) {
+		got := sanitizeMessage([]byte(`not json from 10.0.0.1`), redactor)
+		var s string
+		require.NoError(t, json.Unmarshal(got, &s))
+		assert.True(t, strings.HasSuffix(s, "<ip>"))
+	})
+}
+
+func TestIsSecretKey(t *testing.T) {
+	for _, key := range []string{"password", "Passphrase", "confirmToken", "psk", "pin", "clientSecret"} {
+		assert.True(t, isSecretKey(key), key)
+	}
+	for _, key := range []string{"ssid", "pinned", "method", "name"} {
+		assert.False(t, isSecretKey(key), key)
+	}
+}
@@ -0,0 +1,92 @@
+package remap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeKeyd(t *testing.T) (*keydBackend, *[]string) {
+	var calls []string
+	k := &keydBackend{
+		path: filepath.Join(t.TempDir(), "dms.conf"),
+		run: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, name+" "+strings.Join(args, " "))
+			return nil, nil
+		},
+		write: func(path, content string) error {
+			return os.WriteFile(path, []byte(content), 0644)
+		},
+		remove: os.Remove,
+	}
+	return k, &calls
+}
+
+func TestKeydConfig(t *testing.T) {
+	got := keydConfig([]Remap{{From: "capslock", To: "esc"}, {From: "rightalt", To: "compose"}})
+	assert.Contains(t, got, "[ids]\n*\n\n[main]\ncapslock = esc\nrightalt = compose\n")
+}
+
+func TestKeydApplySkipsUnchangedConfig(t *testing.T) {
+	k, calls := fakeKeyd(t)
+	remaps := []Remap{{From: "capslock", To: "esc"}}
+
+	require.NoError(t, k.apply(remaps))
+	require.Len(t, *calls, 1)
+	assert.Contains(t, (*calls)[0], "keyd reload")
+
+	require.NoError(t, k.apply(remaps))
+	assert.Len(t, *calls, 1, "no reload when the file already matches")
+}
+
+func TestKeydRevertRemovesFile(t *testing.T) {
+	k, calls := fakeKeyd(t)
+	require.NoError(t, k.apply([]Remap{{From: "capslock", To: "esc"}}))
+
+	require.NoError(t, k.apply(nil))
+	_, err := os.Stat(k.path)
+	assert.True(t, os.IsNotExist(err))
+	assert.Len(t, *calls, 2)
+
+	require.NoError(t, k.revert())
+	assert.Len(t, *calls, 2, "nothing to revert")
+}
+
+func TestHyprlandApplyKeepsUserOptions(t *testing.T) {
+	var keywords []string
+	h := &hyprlandBackend{run: func(name string, args ...string) ([]byte, error) {
+		if args[0] == "getoption" {
+			return []byte(`{"option":"input:kb_options","str":"grp:alt_shift_toggle","set":true}`), nil
+		}
+		keywords = append(keywords, args[len(args)-1])
+		return nil, nil
+	}}
+
+	require.NoError(t, h.apply([]Remap{{From: "capslock", To: "esc"}, {From: "rightalt", To: "compose"}}))
+	require.NoError(t, h.revert())
+	assert.Equal(t, []string{"grp:alt_shift_toggle,caps:escape,compose:ralt", "grp:alt_shift_toggle"}, keywords)
+}
+
+func TestHyprlandEmptyOptions(t *testing.T) {
+	var keywords []string
+	h := &hyprlandBackend{run: func(name string, args ...string) ([]byte, error) {
+		if args[0] == "getoption" {
+			return []byte(`{"option":"input:kb_options","str":"[[EMPTY]]","set":false}`), nil
+		}
+		keywords = append(keywords, args[len(args)-1])
+		return nil, nil
+	}}
+
+	require.NoError(t, h.apply([]Remap{{From: "capslock", To: "leftcontrol"}}))
+	assert.Equal(t, []string{"ctrl:nocaps"}, keywords)
+}
+
+func TestHyprlandSupports(t *testing.T) {
+	h := newHyprlandBackend()
+	assert.NoError(t, h.supports(Remap{From: "capslock", To: "esc"}))
+	assert.ErrorContains(t, h.supports(Remap{From: "a", To: "b"}), "needs keyd")
+}
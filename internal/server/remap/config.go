@@ -0,0 +1,80 @@
+package remap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+)
+
+const configTable = "remap"
+
+var keyNamePattern = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// ConfigPath is where remaps are declared. remap.set and remap.remove edit
+// it in place, keeping comments.
+func ConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "remaps.toml")
+}
+
+// parseRemaps reads remaps.toml, a single table of key = "target":
+//
+//	[remap]
+//	capslock = "esc"
+//	rightalt = "compose"
+//
+// Invalid entries are reported and skipped.
+func parseRemaps(data string) ([]Remap, []string, error) {
+	tables, err := tomlite.Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var remaps []Remap
+	var errs []string
+	for _, t := range tables {
+		if t.Name != configTable || t.Array {
+			return nil, nil, fmt.Errorf("line %d: only a [%s] table is allowed", t.Line, configTable)
+		}
+		for from, v := range t.Values {
+			r := Remap{From: from, Line: t.Lines[from]}
+			to, ok := v.(string)
+			if !ok {
+				errs = append(errs, fmt.Sprintf("line %d: %s must be a key name in quotes", r.Line, from))
+				continue
+			}
+			r.To = to
+			if err := validateRemap(r); err != nil {
+				errs = append(errs, fmt.Sprintf("line %d: %v", r.Line, err))
+				continue
+			}
+			remaps = append(remaps, r)
+		}
+	}
+	sortRemaps(remaps)
+	return remaps, errs, nil
+}
+
+func validateRemap(r Remap) error {
+	if !keyNamePattern.MatchString(r.From) {
+		return fmt.Errorf("invalid key name %q", r.From)
+	}
+	if !keyNamePattern.MatchString(r.To) {
+		return fmt.Errorf("invalid key name %q", r.To)
+	}
+	if r.From == r.To {
+		return fmt.Errorf("%s is remapped to itself", r.From)
+	}
+	return nil
+}
+
+func sortRemaps(remaps []Remap) {
+	sort.Slice(remaps, func(i, j int) bool { return remaps[i].From < remaps[j].From })
+}
@@ -0,0 +1,39 @@
+package remap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemaps(t *testing.T) {
+	remaps, errs, err := parseRemaps(`# keyboard tweaks
+[remap]
+rightalt = "compose"
+capslock = "esc" # the classic
+menu = 3
+tab = "tab"
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []Remap{
+		{From: "capslock", To: "esc", Line: 4},
+		{From: "rightalt", To: "compose", Line: 3},
+	}, remaps)
+	assert.Len(t, errs, 2)
+}
+
+func TestParseRemapsRejectsOtherTables(t *testing.T) {
+	_, _, err := parseRemaps("[keys]\ncapslock = \"esc\"\n")
+	assert.Error(t, err)
+
+	_, _, err = parseRemaps("capslock = \"esc\"\n")
+	assert.Error(t, err)
+}
+
+func TestParseRemapsEmpty(t *testing.T) {
+	remaps, errs, err := parseRemaps("")
+	require.NoError(t, err)
+	assert.Empty(t, remaps)
+	assert.Empty(t, errs)
+}
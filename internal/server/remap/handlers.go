@@ -0,0 +1,65 @@
+package remap
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "remap.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "remap.reload":
+		handleReload(conn, req, manager)
+	case "remap.set":
+		handleSet(conn, req, manager)
+	case "remap.remove":
+		handleRemove(conn, req, manager)
+	case "remap.clear":
+		respond(conn, req, manager.Clear)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func respond(conn net.Conn, req Request, action func() (State, error)) {
+	state, err := action()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleReload(conn net.Conn, req Request, manager *Manager) {
+	if err := manager.Reload(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSet(conn net.Conn, req Request, manager *Manager) {
+	from, ok := req.Params["from"].(string)
+	if !ok || from == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'from' parameter")
+		return
+	}
+	to, ok := req.Params["to"].(string)
+	if !ok || to == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'to' parameter")
+		return
+	}
+	respond(conn, req, func() (State, error) { return manager.Set(from, to) })
+}
+
+func handleRemove(conn net.Conn, req Request, manager *Manager) {
+	from, ok := req.Params["from"].(string)
+	if !ok || from == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'from' parameter")
+		return
+	}
+	respond(conn, req, func() (State, error) { return manager.Remove(from) })
+}
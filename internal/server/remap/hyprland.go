@@ -0,0 +1,106 @@
+package remap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// xkbOptions are the remaps XKB has an option for, keyed by from and to
+var xkbOptions = map[string]map[string]string{
+	"capslock": {
+		"esc":         "caps:escape",
+		"leftcontrol": "ctrl:nocaps",
+		"backspace":   "caps:backspace",
+		"leftmeta":    "caps:super",
+		"compose":     "compose:caps",
+		"noop":        "caps:none",
+	},
+	"rightalt":     {"compose": "compose:ralt"},
+	"rightcontrol": {"compose": "compose:rctrl"},
+	"menu":         {"compose": "compose:menu", "rightmeta": "altwin:menu_win"},
+}
+
+// hyprlandBackend adds XKB options to input:kb_options at runtime. Only the
+// remaps XKB knows are possible, and a config reload drops them until the
+// next apply.
+type hyprlandBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+	// original is the user's kb_options, kept in front of ours
+	original *string
+}
+
+func newHyprlandBackend() *hyprlandBackend {
+	return &hyprlandBackend{run: compositor.Run}
+}
+
+func (h *hyprlandBackend) name() string { return "hyprland" }
+
+func (h *hyprlandBackend) supports(r Remap) error {
+	if _, ok := xkbOptions[r.From][r.To]; ok {
+		return nil
+	}
+
+	var pairs []string
+	for from, targets := range xkbOptions {
+		for to := range targets {
+			pairs = append(pairs, from+"→"+to)
+		}
+	}
+	sort.Strings(pairs)
+	return fmt.Errorf("%s→%s needs keyd; Hyprland supports %s", r.From, r.To, strings.Join(pairs, ", "))
+}
+
+func (h *hyprlandBackend) userOptions() (string, error) {
+	if h.original != nil {
+		return *h.original, nil
+	}
+
+	out, err := h.run("hyprctl", "getoption", "input:kb_options", "-j")
+	if err != nil {
+		return "", err
+	}
+	var opt struct {
+		Str string `json:"str"`
+	}
+	if err := json.Unmarshal(out, &opt); err != nil {
+		return "", fmt.Errorf("failed to parse kb_options: %w", err)
+	}
+	// Hyprland reports unset strings as a placeholder
+	if opt.Str == "[[EMPTY]]" {
+		opt.Str = ""
+	}
+	h.original = &opt.Str
+	return opt.Str, nil
+}
+
+func (h *hyprlandBackend) apply(remaps []Remap) error {
+	user, err := h.userOptions()
+	if err != nil {
+		return err
+	}
+
+	var options []string
+	if user != "" {
+		options = append(options, user)
+	}
+	for _, r := range remaps {
+		if err := h.supports(r); err != nil {
+			return err
+		}
+		options = append(options, xkbOptions[r.From][r.To])
+	}
+	_, err = h.run("hyprctl", "keyword", "input:kb_options", strings.Join(options, ","))
+	return err
+}
+
+func (h *hyprlandBackend) revert() error {
+	if h.original == nil {
+		return nil
+	}
+	_, err := h.run("hyprctl", "keyword", "input:kb_options", *h.original)
+	return err
+}
@@ -0,0 +1,94 @@
+package remap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const keydConfigPath = "/etc/keyd/dms.conf"
+
+// keydBackend owns one file in /etc/keyd and reloads keyd after changing
+// it. Keyboards listed by ID in another keyd config keep that config.
+type keydBackend struct {
+	path string
+	run  func(name string, args ...string) ([]byte, error)
+	// write and remove change files under /etc, through pkexec unless the
+	// daemon runs as root
+	write  func(path, content string) error
+	remove func(path string) error
+}
+
+func newKeydBackend() *keydBackend {
+	return &keydBackend{path: keydConfigPath, run: runCommand, write: utils.PrivilegedWrite, remove: privilegedRemove}
+}
+
+func (k *keydBackend) name() string { return "keyd" }
+
+// keyd takes any key name it knows, so only the syntax is checked
+func (k *keydBackend) supports(Remap) error { return nil }
+
+func keydConfig(remaps []Remap) string {
+	var b strings.Builder
+	b.WriteString("# Generated by DMS from remaps.toml; edits are overwritten\n")
+	b.WriteString("[ids]\n*\n\n[main]\n")
+	for _, r := range remaps {
+		fmt.Fprintf(&b, "%s = %s\n", r.From, r.To)
+	}
+	return b.String()
+}
+
+func (k *keydBackend) apply(remaps []Remap) error {
+	if len(remaps) == 0 {
+		return k.revert()
+	}
+
+	content := keydConfig(remaps)
+	// Skip the authentication prompt when nothing changed, as on every login
+	if current, err := os.ReadFile(k.path); err == nil && string(current) == content {
+		return nil
+	}
+	if err := k.write(k.path, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", k.path, err)
+	}
+	return k.reload()
+}
+
+func (k *keydBackend) revert() error {
+	if _, err := os.Stat(k.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := k.remove(k.path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", k.path, err)
+	}
+	return k.reload()
+}
+
+func (k *keydBackend) reload() error {
+	if os.Geteuid() == 0 {
+		_, err := k.run("keyd", "reload")
+		return err
+	}
+	_, err := k.run("pkexec", "keyd", "reload")
+	return err
+}
+
+func privilegedRemove(path string) error {
+	if os.Geteuid() == 0 {
+		return os.Remove(path)
+	}
+	_, err := runCommand("pkexec", "rm", "-f", path)
+	return err
+}
+
+func runCommand(name string, args ...string) ([]byte, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
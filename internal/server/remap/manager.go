@@ -0,0 +1,186 @@
+package remap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+)
+
+// NewManager picks keyd when it is installed, else Hyprland's XKB options,
+// and applies remaps.toml
+func NewManager() (*Manager, error) {
+	b, err := detectBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	m := newManager(b, ConfigPath())
+	if err := m.Reload(); err != nil {
+		log.Warnf("Key remaps not applied: %v", err)
+	}
+	return m, nil
+}
+
+func newManager(b backend, path string) *Manager {
+	return &Manager{backend: b, path: path}
+}
+
+func detectBackend() (backend, error) {
+	if _, err := exec.LookPath("keyd"); err == nil {
+		if info, err := os.Stat("/etc/keyd"); err == nil && info.IsDir() {
+			return newKeydBackend(), nil
+		}
+	}
+	if compositor.Detect(os.Getenv) == compositor.Hyprland {
+		return newHyprlandBackend(), nil
+	}
+	return nil, fmt.Errorf("key remaps need keyd or Hyprland")
+}
+
+func (m *Manager) GetState() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return State{
+		Path:    m.path,
+		Backend: m.backend.name(),
+		Remaps:  append([]Remap(nil), m.remaps...),
+		Applied: m.applied,
+		Errors:  append([]string(nil), m.errors...),
+	}
+}
+
+// Reload re-reads remaps.toml and applies it. A parse error keeps the
+// remaps in effect.
+func (m *Manager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reload()
+}
+
+// reload must be called with the mutex held
+func (m *Manager) reload() error {
+	data, err := m.read()
+	if err != nil {
+		return err
+	}
+
+	remaps, errs, err := parseRemaps(data)
+	if err != nil {
+		m.errors = []string{err.Error()}
+		return fmt.Errorf("failed to parse %s: %w", m.path, err)
+	}
+
+	supported := remaps[:0]
+	for _, r := range remaps {
+		if err := m.backend.supports(r); err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %v", r.Line, err))
+			continue
+		}
+		supported = append(supported, r)
+	}
+	for _, e := range errs {
+		log.Warnf("Key remaps: %s", e)
+	}
+	m.errors = errs
+
+	if err := m.backend.apply(supported); err != nil {
+		m.applied = false
+		return fmt.Errorf("failed to apply remaps: %w", err)
+	}
+	m.remaps = supported
+	m.applied = len(supported) > 0
+	log.Infof("Applied %d key remaps through %s", len(supported), m.backend.name())
+	return nil
+}
+
+// Set adds or replaces a remap in remaps.toml and applies it
+func (m *Manager) Set(from, to string) (State, error) {
+	r := Remap{From: from, To: to}
+	if err := validateRemap(r); err != nil {
+		return m.GetState(), err
+	}
+	if err := m.backend.supports(r); err != nil {
+		return m.GetState(), err
+	}
+
+	err := m.edit(func(data string) (string, error) {
+		return tomlite.Set(data, configTable, from, tomlite.FormatValue(to)), nil
+	})
+	return m.GetState(), err
+}
+
+// Remove drops a remap from remaps.toml and applies the rest
+func (m *Manager) Remove(from string) (State, error) {
+	err := m.edit(func(data string) (string, error) {
+		out, ok := tomlite.Unset(data, configTable, from)
+		if !ok {
+			return "", fmt.Errorf("%s is not remapped", from)
+		}
+		return out, nil
+	})
+	return m.GetState(), err
+}
+
+// Clear removes every remap from remaps.toml, which restores the keyboard
+func (m *Manager) Clear() (State, error) {
+	err := m.edit(func(data string) (string, error) {
+		tables, err := tomlite.Parse(data)
+		if err != nil {
+			return "", err
+		}
+		for _, t := range tables {
+			for key := range t.Values {
+				data, _ = tomlite.Unset(data, t.Name, key)
+			}
+		}
+		return data, nil
+	})
+	return m.GetState(), err
+}
+
+func (m *Manager) edit(change func(data string) (string, error)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := m.read()
+	if err != nil {
+		return err
+	}
+	data, err = change(data)
+	if err != nil {
+		return err
+	}
+	if err := m.write(data); err != nil {
+		return err
+	}
+	return m.reload()
+}
+
+func (m *Manager) read() (string, error) {
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", m.path, err)
+	}
+	return string(data), nil
+}
+
+func (m *Manager) write(data string) error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
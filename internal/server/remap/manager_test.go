@@ -0,0 +1,111 @@
+package remap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	applied [][]Remap
+}
+
+func (f *fakeBackend) name() string { return "fake" }
+
+func (f *fakeBackend) supports(r Remap) error {
+	if r.From == "fn" {
+		return fmt.Errorf("fn cannot be remapped")
+	}
+	return nil
+}
+
+func (f *fakeBackend) apply(remaps []Remap) error {
+	f.applied = append(f.applied, append([]Remap(nil), remaps...))
+	return nil
+}
+
+func (f *fakeBackend) revert() error { return nil }
+
+func (f *fakeBackend) last() []Remap {
+	return f.applied[len(f.applied)-1]
+}
+
+func TestSetAndRemoveEditConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "DankMaterialShell", "remaps.toml")
+	b := &fakeBackend{}
+	m := newManager(b, path)
+
+	state, err := m.Set("capslock", "esc")
+	require.NoError(t, err)
+	assert.True(t, state.Applied)
+	assert.Equal(t, []Remap{{From: "capslock", To: "esc", Line: 2}}, b.last())
+
+	_, err = m.Set("capslock", "leftcontrol")
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[remap]\ncapslock = \"leftcontrol\"\n", string(data))
+
+	state, err = m.Remove("capslock")
+	require.NoError(t, err)
+	assert.False(t, state.Applied)
+	assert.Empty(t, b.last())
+
+	_, err = m.Remove("capslock")
+	assert.ErrorContains(t, err, "not remapped")
+}
+
+func TestSetValidates(t *testing.T) {
+	m := newManager(&fakeBackend{}, filepath.Join(t.TempDir(), "remaps.toml"))
+
+	_, err := m.Set("Caps Lock", "esc")
+	assert.Error(t, err)
+	_, err = m.Set("fn", "esc")
+	assert.ErrorContains(t, err, "cannot be remapped")
+}
+
+func TestReloadSkipsUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remaps.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[remap]\nfn = \"esc\"\ncapslock = \"esc\"\n"), 0644))
+	b := &fakeBackend{}
+	m := newManager(b, path)
+
+	require.NoError(t, m.Reload())
+	state := m.GetState()
+	assert.Equal(t, []Remap{{From: "capslock", To: "esc", Line: 3}}, state.Remaps)
+	assert.Len(t, state.Errors, 1)
+}
+
+func TestReloadParseErrorKeepsRemaps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remaps.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[remap]\ncapslock = \"esc\"\n"), 0644))
+	b := &fakeBackend{}
+	m := newManager(b, path)
+	require.NoError(t, m.Reload())
+
+	require.NoError(t, os.WriteFile(path, []byte("[remap\n"), 0644))
+	assert.Error(t, m.Reload())
+	assert.Len(t, b.applied, 1)
+	assert.Len(t, m.GetState().Remaps, 1)
+}
+
+func TestClearKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remaps.toml")
+	require.NoError(t, os.WriteFile(path, []byte("# mine\n[remap]\ncapslock = \"esc\"\nfn = \"esc\"\n"), 0644))
+	b := &fakeBackend{}
+	m := newManager(b, path)
+	require.NoError(t, m.Reload())
+
+	state, err := m.Clear()
+	require.NoError(t, err)
+	assert.False(t, state.Applied)
+	assert.Empty(t, b.last())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\n[remap]\n", string(data))
+}
@@ -0,0 +1,44 @@
+package remap
+
+import "sync"
+
+// Remap sends one key as another. Names are keyd's (capslock, esc,
+// leftcontrol, rightalt, compose, ...).
+type Remap struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Line int    `json:"line,omitempty"`
+}
+
+type State struct {
+	Path    string   `json:"path"`
+	Backend string   `json:"backend"`
+	Remaps  []Remap  `json:"remaps"`
+	Applied bool     `json:"applied"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// backend makes the remaps take effect. apply replaces whatever it applied
+// before; revert leaves the keyboard as it was before DMS touched it.
+type backend interface {
+	name() string
+	supports(r Remap) error
+	apply(remaps []Remap) error
+	revert() error
+}
+
+type Manager struct {
+	backend backend
+	path    string
+
+	mu      sync.Mutex
+	remaps  []Remap
+	errors  []string
+	applied bool
+}
@@ -5,21 +5,53 @@ import (
 	"net"
 	"strings"
 
+	"github.com/AvengeMedia/danklinux/internal/server/a11y"
+	"github.com/AvengeMedia/danklinux/internal/server/appcolor"
+	"github.com/AvengeMedia/danklinux/internal/server/audio"
 	"github.com/AvengeMedia/danklinux/internal/server/bluez"
 	"github.com/AvengeMedia/danklinux/internal/server/brightness"
+	"github.com/AvengeMedia/danklinux/internal/server/calendar"
 	"github.com/AvengeMedia/danklinux/internal/server/cups"
 	"github.com/AvengeMedia/danklinux/internal/server/dwl"
 	"github.com/AvengeMedia/danklinux/internal/server/freedesktop"
+	"github.com/AvengeMedia/danklinux/internal/server/health"
+	"github.com/AvengeMedia/danklinux/internal/server/jobs"
+	"github.com/AvengeMedia/danklinux/internal/server/launcher"
 	"github.com/AvengeMedia/danklinux/internal/server/loginctl"
 	"github.com/AvengeMedia/danklinux/internal/server/models"
 	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/AvengeMedia/danklinux/internal/server/notifications"
 	serverPlugins "github.com/AvengeMedia/danklinux/internal/server/plugins"
+	"github.com/AvengeMedia/danklinux/internal/server/power"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
+	"github.com/AvengeMedia/danklinux/internal/server/remap"
+	"github.com/AvengeMedia/danklinux/internal/server/rules"
+	"github.com/AvengeMedia/danklinux/internal/server/scratchpad"
+	"github.com/AvengeMedia/danklinux/internal/server/sensors"
+	"github.com/AvengeMedia/danklinux/internal/server/settings"
+	"github.com/AvengeMedia/danklinux/internal/server/sounds"
+	"github.com/AvengeMedia/danklinux/internal/server/termcolors"
+	"github.com/AvengeMedia/danklinux/internal/server/thermal"
+	"github.com/AvengeMedia/danklinux/internal/server/timers"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
 	"github.com/AvengeMedia/danklinux/internal/server/wayland"
 )
 
 func RouteRequest(conn net.Conn, req models.Request) {
+	conn, req = applyVersioning(conn, req)
+
+	if !checkSafeguard(conn, req) {
+		return
+	}
+
+	if async, _ := req.Params["async"].(bool); async {
+		startBackgroundJob(conn, req)
+		return
+	}
+
 	if strings.HasPrefix(req.Method, "network.") {
-		if networkManager == nil {
+		nm := networkManager.Load()
+		if nm == nil {
 			models.RespondError(conn, req.ID, "network manager not initialized")
 			return
 		}
@@ -28,7 +60,7 @@ func RouteRequest(conn net.Conn, req models.Request) {
 			Method: req.Method,
 			Params: req.Params,
 		}
-		network.HandleRequest(conn, netReq, networkManager)
+		network.HandleRequest(conn, netReq, nm)
 		return
 	}
 
@@ -37,8 +69,14 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		return
 	}
 
+	if strings.HasPrefix(req.Method, "settings.") {
+		settings.HandleRequest(conn, req)
+		return
+	}
+
 	if strings.HasPrefix(req.Method, "loginctl.") {
-		if loginctlManager == nil {
+		lm := loginctlManager.Load()
+		if lm == nil {
 			models.RespondError(conn, req.ID, "loginctl manager not initialized")
 			return
 		}
@@ -47,12 +85,13 @@ func RouteRequest(conn net.Conn, req models.Request) {
 			Method: req.Method,
 			Params: req.Params,
 		}
-		loginctl.HandleRequest(conn, loginReq, loginctlManager)
+		loginctl.HandleRequest(conn, loginReq, lm)
 		return
 	}
 
 	if strings.HasPrefix(req.Method, "freedesktop.") {
-		if freedesktopManager == nil {
+		fdm := freedesktopManager.Load()
+		if fdm == nil {
 			models.RespondError(conn, req.ID, "freedesktop manager not initialized")
 			return
 		}
@@ -61,7 +100,7 @@ func RouteRequest(conn net.Conn, req models.Request) {
 			Method: req.Method,
 			Params: req.Params,
 		}
-		freedesktop.HandleRequest(conn, freedeskReq, freedesktopManager)
+		freedesktop.HandleRequest(conn, freedeskReq, fdm)
 		return
 	}
 
@@ -80,7 +119,8 @@ func RouteRequest(conn net.Conn, req models.Request) {
 	}
 
 	if strings.HasPrefix(req.Method, "bluetooth.") {
-		if bluezManager == nil {
+		bm := bluezManager.Load()
+		if bm == nil {
 			models.RespondError(conn, req.ID, "bluetooth manager not initialized")
 			return
 		}
@@ -89,12 +129,13 @@ func RouteRequest(conn net.Conn, req models.Request) {
 			Method: req.Method,
 			Params: req.Params,
 		}
-		bluez.HandleRequest(conn, bluezReq, bluezManager)
+		bluez.HandleRequest(conn, bluezReq, bm)
 		return
 	}
 
 	if strings.HasPrefix(req.Method, "cups.") {
-		if cupsManager == nil {
+		cm := cupsManager.Load()
+		if cm == nil {
 			models.RespondError(conn, req.ID, "CUPS manager not initialized")
 			return
 		}
@@ -103,7 +144,7 @@ func RouteRequest(conn net.Conn, req models.Request) {
 			Method: req.Method,
 			Params: req.Params,
 		}
-		cups.HandleRequest(conn, cupsReq, cupsManager)
+		cups.HandleRequest(conn, cupsReq, cm)
 		return
 	}
 
@@ -122,7 +163,8 @@ func RouteRequest(conn net.Conn, req models.Request) {
 	}
 
 	if strings.HasPrefix(req.Method, "brightness.") {
-		if brightnessManager == nil {
+		brm := brightnessManager.Load()
+		if brm == nil {
 			models.RespondError(conn, req.ID, "brightness manager not initialized")
 			return
 		}
@@ -131,7 +173,274 @@ func RouteRequest(conn net.Conn, req models.Request) {
 			Method: req.Method,
 			Params: req.Params,
 		}
-		brightness.HandleRequest(conn, brightnessReq, brightnessManager)
+		brightness.HandleRequest(conn, brightnessReq, brm)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "notifications.") {
+		if notificationsManager == nil {
+			models.RespondError(conn, req.ID, "notifications manager not initialized")
+			return
+		}
+		notificationsReq := notifications.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		notifications.HandleRequest(conn, notificationsReq, notificationsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "prompts.") {
+		if promptsManager == nil {
+			models.RespondError(conn, req.ID, "prompts manager not initialized")
+			return
+		}
+		promptsReq := prompts.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		prompts.HandleRequest(conn, promptsReq, promptsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "sensors.") {
+		if sensorsManager == nil {
+			models.RespondError(conn, req.ID, "sensors manager not initialized")
+			return
+		}
+		sensorsReq := sensors.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		sensors.HandleRequest(conn, sensorsReq, sensorsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "launcher.") {
+		if launcherManager == nil {
+			models.RespondError(conn, req.ID, "launcher manager not initialized")
+			return
+		}
+		launcherReq := launcher.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		launcher.HandleRequest(conn, launcherReq, launcherManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "power.") {
+		pm := powerManager.Load()
+		if pm == nil {
+			models.RespondError(conn, req.ID, "power manager not initialized")
+			return
+		}
+		powerReq := power.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		power.HandleRequest(conn, powerReq, pm)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "rules.") {
+		if rulesManager == nil {
+			models.RespondError(conn, req.ID, "rules manager not initialized")
+			return
+		}
+		rulesReq := rules.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		rules.HandleRequest(conn, rulesReq, rulesManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "scratchpad.") {
+		if scratchpadManager == nil {
+			models.RespondError(conn, req.ID, "scratchpad manager not initialized")
+			return
+		}
+		scratchpadReq := scratchpad.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		scratchpad.HandleRequest(conn, scratchpadReq, scratchpadManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "appcolor.") {
+		if appcolorManager == nil {
+			models.RespondError(conn, req.ID, "appcolor manager not initialized")
+			return
+		}
+		appcolorReq := appcolor.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		appcolor.HandleRequest(conn, appcolorReq, appcolorManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "a11y.") {
+		if a11yManager == nil {
+			models.RespondError(conn, req.ID, "a11y manager not initialized")
+			return
+		}
+		a11yReq := a11y.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		a11y.HandleRequest(conn, a11yReq, a11yManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "audio.") {
+		if audioManager == nil {
+			models.RespondError(conn, req.ID, "audio manager not initialized")
+			return
+		}
+		audioReq := audio.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		audio.HandleRequest(conn, audioReq, audioManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "wallpaper.") {
+		if wallpaperManager == nil {
+			models.RespondError(conn, req.ID, "wallpaper manager not initialized")
+			return
+		}
+		wallpaperReq := wallpaper.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		wallpaper.HandleRequest(conn, wallpaperReq, wallpaperManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "sounds.") {
+		if soundsManager == nil {
+			models.RespondError(conn, req.ID, "sounds manager not initialized")
+			return
+		}
+		soundsReq := sounds.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		sounds.HandleRequest(conn, soundsReq, soundsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "jobs.") {
+		if jobsManager == nil {
+			models.RespondError(conn, req.ID, "jobs manager not initialized")
+			return
+		}
+		jobsReq := jobs.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		jobs.HandleRequest(conn, jobsReq, jobsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "termcolors.") {
+		if termcolorsManager == nil {
+			models.RespondError(conn, req.ID, "termcolors manager not initialized")
+			return
+		}
+		termcolorsReq := termcolors.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		termcolors.HandleRequest(conn, termcolorsReq, termcolorsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "thermal.") {
+		if thermalManager == nil {
+			models.RespondError(conn, req.ID, "thermal manager not initialized")
+			return
+		}
+		thermalReq := thermal.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		thermal.HandleRequest(conn, thermalReq, thermalManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "remap.") {
+		if remapManager == nil {
+			models.RespondError(conn, req.ID, "remap manager not initialized")
+			return
+		}
+		remapReq := remap.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		remap.HandleRequest(conn, remapReq, remapManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "timers.") {
+		if timersManager == nil {
+			models.RespondError(conn, req.ID, "timers manager not initialized")
+			return
+		}
+		timersReq := timers.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		timers.HandleRequest(conn, timersReq, timersManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "calendar.") {
+		if calendarManager == nil {
+			models.RespondError(conn, req.ID, "calendar manager not initialized")
+			return
+		}
+		calendarReq := calendar.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		calendar.HandleRequest(conn, calendarReq, calendarManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "health.") {
+		if healthManager == nil {
+			models.RespondError(conn, req.ID, "health manager not initialized")
+			return
+		}
+		healthReq := health.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		health.HandleRequest(conn, healthReq, healthManager)
 		return
 	}
 
@@ -143,6 +452,21 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		models.Respond(conn, req.ID, info)
 	case "subscribe":
 		handleSubscribe(conn, req)
+	case "introspect":
+		handleIntrospect(conn, req)
+	case "debug.record.start":
+		handleRecordStart(conn, req)
+	case "debug.record.stop":
+		handleRecordStop(conn, req)
+	case "debug.record.status":
+		models.Respond(conn, req.ID, ipcRecorder.status())
+	case "config.reload":
+		result, err := reloadDaemonConfig()
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, result)
 	default:
 		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
 	}
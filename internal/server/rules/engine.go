@@ -0,0 +1,267 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+)
+
+var propertyOrder = []string{"workspace", "output", "floating", "fullscreen", "maximized", "opacity"}
+
+// compileRules validates parsed tables. Invalid rules are reported and left
+// out so one typo does not disable every other rule.
+func compileRules(tables []tomlite.Table, unsupported []string) ([]Rule, []string) {
+	var rules []Rule
+	var errs []string
+
+	for i, t := range tables {
+		rule, err := compileRule(i+1, t)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, prop := range unsupported {
+			if _, set := rule.properties()[prop]; set {
+				errs = append(errs, fmt.Sprintf("rule %q: %s is not supported on this compositor and will be ignored", rule.Name, prop))
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, errs
+}
+
+func compileRule(index int, t tomlite.Table) (Rule, error) {
+	rule := Rule{Name: fmt.Sprintf("rule %d", index), Line: t.Line}
+	if name, ok := t.Values["name"].(string); ok && name != "" {
+		rule.Name = name
+	}
+	fail := func(key, format string, args ...any) (Rule, error) {
+		return Rule{}, fmt.Errorf("rule %q (line %d): %s", rule.Name, t.Lines[key], fmt.Sprintf(format, args...))
+	}
+
+	keys := make([]string, 0, len(t.Values))
+	for key := range t.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := t.Values[key]
+		switch key {
+		case "name":
+			if _, ok := value.(string); !ok {
+				return fail(key, "name must be a string")
+			}
+		case "app-id", "app_id":
+			s, ok := value.(string)
+			if !ok {
+				return fail(key, "%s must be a string", key)
+			}
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return fail(key, "invalid %s pattern: %v", key, err)
+			}
+			rule.AppID, rule.appID = s, re
+		case "title":
+			s, ok := value.(string)
+			if !ok {
+				return fail(key, "title must be a string")
+			}
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return fail(key, "invalid title pattern: %v", err)
+			}
+			rule.Title, rule.title = s, re
+		case "workspace":
+			switch v := value.(type) {
+			case int64:
+				if v < 1 {
+					return fail(key, "workspace index must be 1 or more")
+				}
+				rule.Workspace = strconv.FormatInt(v, 10)
+			case string:
+				if v == "" {
+					return fail(key, "workspace must not be empty")
+				}
+				rule.Workspace = v
+			default:
+				return fail(key, "workspace must be a number or a name")
+			}
+		case "output":
+			s, ok := value.(string)
+			if !ok || s == "" {
+				return fail(key, "output must be a connector name like \"DP-1\"")
+			}
+			rule.Output = s
+		case "floating", "fullscreen", "maximized":
+			b, ok := value.(bool)
+			if !ok {
+				return fail(key, "%s must be true or false", key)
+			}
+			switch key {
+			case "floating":
+				rule.Floating = &b
+			case "fullscreen":
+				rule.Fullscreen = &b
+			case "maximized":
+				rule.Maximized = &b
+			}
+		case "opacity":
+			var f float64
+			switch v := value.(type) {
+			case float64:
+				f = v
+			case int64:
+				f = float64(v)
+			default:
+				return fail(key, "opacity must be a number")
+			}
+			if f <= 0 || f > 1 {
+				return fail(key, "opacity must be in (0, 1]")
+			}
+			rule.Opacity = &f
+		default:
+			return fail(key, "unknown key %q", key)
+		}
+	}
+
+	if rule.appID == nil && rule.title == nil {
+		return Rule{}, fmt.Errorf("rule %q (line %d): needs app-id or title to match on", rule.Name, t.Line)
+	}
+	if len(rule.properties()) == 0 {
+		return Rule{}, fmt.Errorf("rule %q (line %d): does nothing, set workspace, output, floating, fullscreen, maximized or opacity", rule.Name, t.Line)
+	}
+	return rule, nil
+}
+
+func (r *Rule) matches(w Window) bool {
+	if r.appID != nil && !r.appID.MatchString(w.AppID) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(w.Title) {
+		return false
+	}
+	return true
+}
+
+// properties renders the set actions as strings for comparison and display
+func (a Actions) properties() map[string]string {
+	props := make(map[string]string)
+	if a.Workspace != "" {
+		props["workspace"] = a.Workspace
+	}
+	if a.Output != "" {
+		props["output"] = a.Output
+	}
+	if a.Floating != nil {
+		props["floating"] = strconv.FormatBool(*a.Floating)
+	}
+	if a.Fullscreen != nil {
+		props["fullscreen"] = strconv.FormatBool(*a.Fullscreen)
+	}
+	if a.Maximized != nil {
+		props["maximized"] = strconv.FormatBool(*a.Maximized)
+	}
+	if a.Opacity != nil {
+		props["opacity"] = strconv.FormatFloat(*a.Opacity, 'g', -1, 64)
+	}
+	return props
+}
+
+// merge overlays the properties set in other
+func (a *Actions) merge(other Actions) {
+	if other.Workspace != "" {
+		a.Workspace = other.Workspace
+	}
+	if other.Output != "" {
+		a.Output = other.Output
+	}
+	if other.Floating != nil {
+		a.Floating = other.Floating
+	}
+	if other.Fullscreen != nil {
+		a.Fullscreen = other.Fullscreen
+	}
+	if other.Maximized != nil {
+		a.Maximized = other.Maximized
+	}
+	if other.Opacity != nil {
+		a.Opacity = other.Opacity
+	}
+}
+
+// combine merges rules in order and reports properties they disagree on
+func combine(rules []*Rule, window string) MatchResult {
+	result := MatchResult{Rules: []string{}}
+	type setter struct{ rule, value string }
+	setters := make(map[string][]setter)
+
+	for _, r := range rules {
+		result.Rules = append(result.Rules, r.Name)
+		result.Actions.merge(r.Actions)
+		for prop, value := range r.properties() {
+			setters[prop] = append(setters[prop], setter{r.Name, value})
+		}
+	}
+
+	for _, prop := range propertyOrder {
+		list := setters[prop]
+		differs := false
+		for _, s := range list[min(1, len(list)):] {
+			if s.value != list[0].value {
+				differs = true
+				break
+			}
+		}
+		if !differs {
+			continue
+		}
+		c := Conflict{Property: prop, Window: window}
+		for _, s := range list {
+			c.Rules = append(c.Rules, s.rule)
+			c.Values = append(c.Values, s.value)
+		}
+		result.Conflicts = append(result.Conflicts, c)
+	}
+	return result
+}
+
+func resolve(rules []Rule, w Window) MatchResult {
+	var matched []*Rule
+	for i := range rules {
+		if rules[i].matches(w) {
+			matched = append(matched, &rules[i])
+		}
+	}
+	return combine(matched, w.AppID)
+}
+
+// staticConflicts finds rules with identical matchers that disagree, which
+// collide on every window they apply to
+func staticConflicts(rules []Rule) []Conflict {
+	groups := make(map[string][]*Rule)
+	var order []string
+	for i := range rules {
+		key := rules[i].AppID + "\x00" + rules[i].Title
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], &rules[i])
+	}
+
+	var conflicts []Conflict
+	for _, key := range order {
+		if group := groups[key]; len(group) > 1 {
+			conflicts = append(conflicts, combine(group, "").Conflicts...)
+		}
+	}
+	return conflicts
+}
+
+func conflictKey(c Conflict) string {
+	return c.Property + "\x00" + strings.Join(c.Rules, "\x00") + "\x00" + strings.Join(c.Values, "\x00")
+}
@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustRules(t *testing.T, data string, unsupported ...string) ([]Rule, []string) {
+	t.Helper()
+	tables, err := parseRulesTOML(data)
+	require.NoError(t, err)
+	return compileRules(tables, unsupported)
+}
+
+func TestCompileRules(t *testing.T) {
+	rules, errs := mustRules(t, `
+[[rule]]
+app-id = "^firefox$"
+workspace = 3
+
+[[rule]]
+name = "bad regex"
+title = "("
+floating = true
+
+[[rule]]
+name = "no matcher"
+floating = true
+
+[[rule]]
+name = "no action"
+app-id = "foot"
+
+[[rule]]
+name = "typo"
+app-id = "foot"
+workspce = 2
+
+[[rule]]
+name = "named workspace"
+app_id = "discord"
+workspace = "chat"
+opacity = 1
+`)
+
+	require.Len(t, rules, 2)
+	assert.Equal(t, "rule 1", rules[0].Name)
+	assert.Equal(t, "3", rules[0].Workspace)
+	assert.Equal(t, "chat", rules[1].Workspace)
+	assert.Equal(t, 1.0, *rules[1].Opacity)
+
+	require.Len(t, errs, 4)
+	assert.Contains(t, errs[0], `rule "bad regex" (line 8): invalid title pattern`)
+	assert.Contains(t, errs[1], "needs app-id or title")
+	assert.Contains(t, errs[2], "does nothing")
+	assert.Contains(t, errs[3], `unknown key "workspce"`)
+}
+
+func TestCompileRules_Unsupported(t *testing.T) {
+	rules, errs := mustRules(t, "[[rule]]\napp-id = \"foot\"\nopacity = 0.8\n", "opacity")
+	require.Len(t, rules, 1)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "opacity is not supported")
+}
+
+func TestResolve(t *testing.T) {
+	rules, errs := mustRules(t, `
+[[rule]]
+name = "browsers"
+app-id = "^(firefox|chromium)$"
+workspace = 2
+
+[[rule]]
+name = "pip"
+title = "^Picture-in-Picture$"
+floating = true
+workspace = 5
+
+[[rule]]
+name = "firefox"
+app-id = "^firefox$"
+output = "DP-1"
+`)
+	require.Empty(t, errs)
+
+	result := resolve(rules, Window{AppID: "firefox", Title: "Mozilla Firefox"})
+	assert.Equal(t, []string{"browsers", "firefox"}, result.Rules)
+	assert.Equal(t, "2", result.Actions.Workspace)
+	assert.Equal(t, "DP-1", result.Actions.Output)
+	assert.Nil(t, result.Actions.Floating)
+	assert.Empty(t, result.Conflicts)
+
+	result = resolve(rules, Window{AppID: "firefox", Title: "Picture-in-Picture"})
+	assert.Equal(t, "5", result.Actions.Workspace, "later rule wins")
+	require.NotNil(t, result.Actions.Floating)
+	assert.True(t, *result.Actions.Floating)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, Conflict{
+		Property: "workspace",
+		Rules:    []string{"browsers", "pip"},
+		Values:   []string{"2", "5"},
+		Window:   "firefox",
+	}, result.Conflicts[0])
+
+	result = resolve(rules, Window{AppID: "foot"})
+	assert.Empty(t, result.Rules)
+}
+
+func TestStaticConflicts(t *testing.T) {
+	rules, _ := mustRules(t, `
+[[rule]]
+name = "a"
+app-id = "steam"
+floating = true
+
+[[rule]]
+name = "b"
+app-id = "steam"
+floating = false
+workspace = 4
+
+[[rule]]
+name = "c"
+app-id = "steam"
+workspace = 4
+`)
+
+	conflicts := staticConflicts(rules)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "floating", conflicts[0].Property)
+	assert.Equal(t, []string{"a", "b"}, conflicts[0].Rules)
+	assert.Empty(t, conflicts[0].Window)
+}
@@ -0,0 +1,44 @@
+package rules
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "rules.getState":
+		handleGetState(conn, req, manager)
+	case "rules.reload":
+		handleReload(conn, req, manager)
+	case "rules.match":
+		handleMatch(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleReload(conn net.Conn, req Request, manager *Manager) {
+	if err := manager.Reload(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleMatch(conn net.Conn, req Request, manager *Manager) {
+	appID, _ := req.Params["appId"].(string)
+	title, _ := req.Params["title"].(string)
+	if appID == "" && title == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'appId' or 'title' parameter")
+		return
+	}
+
+	models.Respond(conn, req.ID, manager.Match(Window{AppID: appID, Title: title}))
+}
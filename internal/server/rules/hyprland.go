@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// hyprlandBackend reads openwindow events from socket2 and applies actions
+// with address-targeted dispatchers. Keyword window rules are avoided since
+// they cannot be removed again on rules.reload without a full config reload.
+type hyprlandBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newHyprlandBackend() *hyprlandBackend {
+	return &hyprlandBackend{run: compositor.Run}
+}
+
+func (h *hyprlandBackend) name() string { return "hyprland" }
+
+func (h *hyprlandBackend) unsupported() []string { return nil }
+
+func (h *hyprlandBackend) watch(stop <-chan struct{}, opened func(Window)) error {
+	return compositor.WatchHyprland(stop, func(event string) {
+		if w, ok := parseHyprlandOpenWindow(event); ok {
+			opened(w)
+		}
+	})
+}
+
+// parseHyprlandOpenWindow reads "openwindow>>ADDRESS,WORKSPACE,CLASS,TITLE".
+// The title is last since it may itself contain commas.
+func parseHyprlandOpenWindow(line string) (Window, bool) {
+	data, ok := strings.CutPrefix(line, "openwindow>>")
+	if !ok {
+		return Window{}, false
+	}
+	parts := strings.SplitN(data, ",", 4)
+	if len(parts) != 4 || parts[0] == "" {
+		return Window{}, false
+	}
+	return Window{ID: "0x" + strings.TrimPrefix(parts[0], "0x"), AppID: parts[2], Title: parts[3]}, true
+}
+
+// hyprlandDispatches builds the batch for a window. Moving to an output and
+// the fullscreen modes only act on the focused window, so those focus it
+// first.
+func hyprlandDispatches(w Window, a Actions) []string {
+	addr := "address:" + w.ID
+	var cmds []string
+
+	if a.Floating != nil {
+		if *a.Floating {
+			cmds = append(cmds, "dispatch setfloating "+addr)
+		} else {
+			cmds = append(cmds, "dispatch settiled "+addr)
+		}
+	}
+	if a.Workspace != "" {
+		cmds = append(cmds, fmt.Sprintf("dispatch movetoworkspacesilent %s,%s", hyprlandWorkspace(a.Workspace), addr))
+	}
+	if a.Opacity != nil {
+		cmds = append(cmds, fmt.Sprintf("dispatch setprop %s alpha %g", addr, *a.Opacity))
+	}
+
+	needsFocus := a.Output != "" || (a.Fullscreen != nil && *a.Fullscreen) || (a.Maximized != nil && *a.Maximized)
+	if needsFocus {
+		cmds = append(cmds, "dispatch focuswindow "+addr)
+	}
+	if a.Output != "" {
+		cmds = append(cmds, "dispatch movewindow mon:"+a.Output)
+	}
+	switch {
+	case a.Fullscreen != nil && *a.Fullscreen:
+		cmds = append(cmds, "dispatch fullscreen 0")
+	case a.Maximized != nil && *a.Maximized:
+		cmds = append(cmds, "dispatch fullscreen 1")
+	}
+	return cmds
+}
+
+// hyprlandWorkspace passes numeric workspaces through and prefixes names
+func hyprlandWorkspace(ws string) string {
+	if strings.Trim(ws, "0123456789") == "" || strings.Contains(ws, ":") {
+		return ws
+	}
+	return "name:" + ws
+}
+
+func (h *hyprlandBackend) apply(w Window, a Actions) error {
+	cmds := hyprlandDispatches(w, a)
+	if len(cmds) == 0 {
+		return nil
+	}
+	_, err := h.run("hyprctl", "--batch", strings.Join(cmds, " ; "))
+	return err
+}
@@ -0,0 +1,196 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	watchRetryDelay = 2 * time.Second
+	// maxRuntimeConflicts bounds the conflicts remembered from live windows
+	maxRuntimeConflicts = 50
+)
+
+// NewManager picks the compositor backend from the environment and loads
+// rules.toml. A missing file is not an error, rules.reload picks it up once
+// it exists.
+func NewManager() (*Manager, error) {
+	backend, err := detectBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	m := newManager(backend, ConfigPath())
+	if err := m.Reload(); err != nil {
+		log.Warnf("Window rules not loaded: %v", err)
+	}
+
+	go m.watchLoop()
+
+	return m, nil
+}
+
+func newManager(backend windowBackend, path string) *Manager {
+	return &Manager{
+		backend:  backend,
+		path:     path,
+		seen:     make(map[string]bool),
+		stopChan: make(chan struct{}),
+	}
+}
+
+func detectBackend() (windowBackend, error) {
+	switch compositor.Detect(os.Getenv) {
+	case compositor.Niri:
+		return newNiriBackend(), nil
+	case compositor.Hyprland:
+		return newHyprlandBackend(), nil
+	}
+	return nil, fmt.Errorf("window rules need niri or Hyprland")
+}
+
+// ConfigPath is where users declare their window rules
+func ConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "rules.toml")
+}
+
+// Reload re-reads the rules file. Parse errors keep the previous rules;
+// invalid individual rules are dropped and listed in the state.
+func (m *Manager) Reload() error {
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = nil, nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", m.path, err)
+	}
+
+	tables, err := parseRulesTOML(string(data))
+	if err != nil {
+		m.stateMutex.Lock()
+		m.errors = []string{err.Error()}
+		m.stateMutex.Unlock()
+		return fmt.Errorf("failed to parse %s: %w", m.path, err)
+	}
+
+	rules, errs := compileRules(tables, m.backend.unsupported())
+	for _, e := range errs {
+		log.Warnf("Window rules: %s", e)
+	}
+
+	m.stateMutex.Lock()
+	m.rules = rules
+	m.errors = errs
+	m.conflicts = staticConflicts(rules)
+	m.seen = make(map[string]bool)
+	for _, c := range m.conflicts {
+		m.seen[conflictKey(c)] = true
+	}
+	m.stateMutex.Unlock()
+
+	log.Infof("Loaded %d window rules from %s", len(rules), m.path)
+	return nil
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+
+	return State{
+		Path:      m.path,
+		Backend:   m.backend.name(),
+		Rules:     append([]Rule{}, m.rules...),
+		Errors:    append([]string(nil), m.errors...),
+		Conflicts: append([]Conflict(nil), m.conflicts...),
+		Applied:   m.applied,
+	}
+}
+
+// Match reports what the current rules would do to a window without
+// touching the compositor
+func (m *Manager) Match(w Window) MatchResult {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return resolve(m.rules, w)
+}
+
+func (m *Manager) watchLoop() {
+	defer crash.Capture("rules watch", nil)
+
+	for {
+		err := m.backend.watch(m.stopChan, m.handleOpened)
+		select {
+		case <-m.stopChan:
+			return
+		default:
+		}
+		if err != nil {
+			log.Debugf("Window rules event stream ended: %v", err)
+		}
+
+		select {
+		case <-m.stopChan:
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+func (m *Manager) handleOpened(w Window) {
+	m.stateMutex.Lock()
+	result := resolve(m.rules, w)
+	fresh := m.recordConflicts(result.Conflicts)
+	if len(result.Rules) > 0 {
+		m.applied++
+	}
+	m.stateMutex.Unlock()
+
+	if len(result.Rules) == 0 {
+		return
+	}
+	for _, c := range fresh {
+		log.Warnf("Window rules conflict on %s for %s: %v set %v", c.Property, w.AppID, c.Rules, c.Values)
+	}
+
+	if err := m.backend.apply(w, result.Actions); err != nil {
+		log.Warnf("Failed to apply window rules to %s: %v", w.AppID, err)
+	}
+}
+
+// recordConflicts keeps each distinct conflict once and returns the ones not
+// seen before. Callers hold stateMutex.
+func (m *Manager) recordConflicts(conflicts []Conflict) []Conflict {
+	var fresh []Conflict
+	for _, c := range conflicts {
+		key := conflictKey(c)
+		if m.seen[key] {
+			continue
+		}
+		m.seen[key] = true
+		fresh = append(fresh, c)
+		if len(m.conflicts) >= maxRuntimeConflicts {
+			continue
+		}
+		m.conflicts = append(m.conflicts, c)
+	}
+	return fresh
+}
+
+func (m *Manager) Close() {
+	select {
+	case <-m.stopChan:
+	default:
+		close(m.stopChan)
+	}
+}
@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	mu      sync.Mutex
+	applied map[string]Actions
+	skip    []string
+}
+
+func (f *fakeBackend) name() string { return "fake" }
+
+func (f *fakeBackend) unsupported() []string { return f.skip }
+
+func (f *fakeBackend) watch(stop <-chan struct{}, opened func(Window)) error {
+	<-stop
+	return nil
+}
+
+func (f *fakeBackend) apply(w Window, a Actions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.applied == nil {
+		f.applied = make(map[string]Actions)
+	}
+	f.applied[w.ID] = a
+	return nil
+}
+
+func writeRules(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+}
+
+func TestManager_ReloadAndApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.toml")
+	backend := &fakeBackend{}
+	m := newManager(backend, path)
+
+	require.NoError(t, m.Reload(), "a missing file is an empty rule set")
+	assert.Empty(t, m.GetState().Rules)
+
+	writeRules(t, path, `
+[[rule]]
+name = "first"
+app-id = "^mpv$"
+workspace = 1
+
+[[rule]]
+name = "second"
+app-id = "^mpv$"
+workspace = 2
+floating = true
+`)
+	require.NoError(t, m.Reload())
+
+	state := m.GetState()
+	assert.Equal(t, "fake", state.Backend)
+	assert.Len(t, state.Rules, 2)
+	require.Len(t, state.Conflicts, 1, "identical matchers conflict up front")
+
+	m.handleOpened(Window{ID: "7", AppID: "mpv"})
+	m.handleOpened(Window{ID: "8", AppID: "foot"})
+
+	assert.Equal(t, "2", backend.applied["7"].Workspace)
+	assert.NotContains(t, backend.applied, "8")
+
+	state = m.GetState()
+	assert.Equal(t, 1, state.Applied)
+	assert.Len(t, state.Conflicts, 1, "runtime repeat of a static conflict is not listed twice")
+}
+
+func TestManager_ReloadKeepsRulesOnParseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.toml")
+	m := newManager(&fakeBackend{}, path)
+
+	writeRules(t, path, "[[rule]]\napp-id = \"foot\"\nfloating = true\n")
+	require.NoError(t, m.Reload())
+
+	writeRules(t, path, "[[rule]]\napp-id = \"foot\n")
+	err := m.Reload()
+	require.Error(t, err)
+
+	state := m.GetState()
+	assert.Len(t, state.Rules, 1)
+	require.Len(t, state.Errors, 1)
+	assert.Contains(t, state.Errors[0], "line 2")
+}
+
+func TestManager_RuntimeConflicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.toml")
+	m := newManager(&fakeBackend{}, path)
+
+	writeRules(t, path, `
+[[rule]]
+name = "games"
+app-id = "^steam_app_"
+workspace = 9
+
+[[rule]]
+name = "fullscreen titles"
+title = "Fullscreen"
+workspace = 1
+`)
+	require.NoError(t, m.Reload())
+	assert.Empty(t, m.GetState().Conflicts)
+
+	m.handleOpened(Window{ID: "1", AppID: "steam_app_42", Title: "Fullscreen game"})
+	m.handleOpened(Window{ID: "2", AppID: "steam_app_42", Title: "Fullscreen game"})
+
+	conflicts := m.GetState().Conflicts
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "steam_app_42", conflicts[0].Window)
+	assert.Equal(t, []string{"9", "1"}, conflicts[0].Values)
+}
+
+func TestNiriTracker(t *testing.T) {
+	tr := newNiriTracker()
+
+	assert.Empty(t, tr.handle([]byte(`{"WindowsChanged":{"windows":[{"id":1,"title":"a","app_id":"foot"}]}}`)))
+	assert.Empty(t, tr.handle([]byte(`{"WindowOpenedOrChanged":{"window":{"id":1,"title":"b","app_id":"foot"}}}`)), "existing window retitled")
+
+	opened := tr.handle([]byte(`{"WindowOpenedOrChanged":{"window":{"id":5,"title":"Mozilla Firefox","app_id":"firefox"}}}`))
+	assert.Equal(t, []Window{{ID: "5", AppID: "firefox", Title: "Mozilla Firefox"}}, opened)
+	assert.Empty(t, tr.handle([]byte(`{"WindowOpenedOrChanged":{"window":{"id":5,"title":"New Tab","app_id":"firefox"}}}`)))
+
+	tr.handle([]byte(`{"WindowClosed":{"id":5}}`))
+	assert.Len(t, tr.handle([]byte(`{"WindowOpenedOrChanged":{"window":{"id":5,"title":"","app_id":"firefox"}}}`)), 1)
+	assert.Empty(t, tr.handle([]byte(`not json`)))
+}
+
+func TestNiriApply(t *testing.T) {
+	var calls [][]string
+	n := &niriBackend{run: func(name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+		return nil, nil
+	}}
+
+	floating := true
+	require.NoError(t, n.apply(Window{ID: "5"}, Actions{Workspace: "3", Floating: &floating}))
+	assert.Equal(t, [][]string{
+		{"niri", "msg", "action", "move-window-to-floating", "--id", "5"},
+		{"niri", "msg", "action", "move-window-to-workspace", "--window-id", "5", "--focus", "false", "3"},
+	}, calls)
+}
+
+func TestHyprland(t *testing.T) {
+	w, ok := parseHyprlandOpenWindow("openwindow>>55d0c8a0e1b0,2,firefox,Title, with commas")
+	require.True(t, ok)
+	assert.Equal(t, Window{ID: "0x55d0c8a0e1b0", AppID: "firefox", Title: "Title, with commas"}, w)
+
+	_, ok = parseHyprlandOpenWindow("closewindow>>55d0c8a0e1b0")
+	assert.False(t, ok)
+
+	yes := true
+	opacity := 0.85
+	cmds := hyprlandDispatches(w, Actions{Workspace: "chat", Output: "DP-2", Fullscreen: &yes, Opacity: &opacity})
+	assert.Equal(t, []string{
+		"dispatch movetoworkspacesilent name:chat,address:0x55d0c8a0e1b0",
+		"dispatch setprop address:0x55d0c8a0e1b0 alpha 0.85",
+		"dispatch focuswindow address:0x55d0c8a0e1b0",
+		"dispatch movewindow mon:DP-2",
+		"dispatch fullscreen 0",
+	}, cmds)
+
+	assert.Equal(t, "3", hyprlandWorkspace("3"))
+	assert.Equal(t, "special:scratch", hyprlandWorkspace("special:scratch"))
+}
@@ -0,0 +1,124 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// niriBackend follows `niri msg --json event-stream`. niri has no runtime
+// window rules, so actions are sent as window-targeted actions right after
+// the window opens.
+type niriBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newNiriBackend() *niriBackend {
+	return &niriBackend{run: compositor.Run}
+}
+
+func (n *niriBackend) name() string { return "niri" }
+
+func (n *niriBackend) unsupported() []string { return []string{"opacity"} }
+
+type niriWindow struct {
+	ID    uint64 `json:"id"`
+	Title string `json:"title"`
+	AppID string `json:"app_id"`
+}
+
+type niriEvent struct {
+	WindowsChanged *struct {
+		Windows []niriWindow `json:"windows"`
+	} `json:"WindowsChanged"`
+	WindowOpenedOrChanged *struct {
+		Window niriWindow `json:"window"`
+	} `json:"WindowOpenedOrChanged"`
+	WindowClosed *struct {
+		ID uint64 `json:"id"`
+	} `json:"WindowClosed"`
+}
+
+func (n *niriBackend) watch(stop <-chan struct{}, opened func(Window)) error {
+	tracker := newNiriTracker()
+	return compositor.WatchNiri(stop, func(event []byte) {
+		for _, w := range tracker.handle(event) {
+			opened(w)
+		}
+	})
+}
+
+// niriTracker turns the event stream into "window opened" notifications.
+// WindowOpenedOrChanged fires on every title change too, so windows already
+// known are skipped, including everything open when the stream started.
+type niriTracker struct {
+	known map[uint64]bool
+}
+
+func newNiriTracker() *niriTracker {
+	return &niriTracker{known: make(map[uint64]bool)}
+}
+
+func (t *niriTracker) handle(line []byte) []Window {
+	var ev niriEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return nil
+	}
+
+	switch {
+	case ev.WindowsChanged != nil:
+		t.known = make(map[uint64]bool)
+		for _, w := range ev.WindowsChanged.Windows {
+			t.known[w.ID] = true
+		}
+	case ev.WindowOpenedOrChanged != nil:
+		w := ev.WindowOpenedOrChanged.Window
+		if t.known[w.ID] {
+			return nil
+		}
+		t.known[w.ID] = true
+		return []Window{{ID: strconv.FormatUint(w.ID, 10), AppID: w.AppID, Title: w.Title}}
+	case ev.WindowClosed != nil:
+		delete(t.known, ev.WindowClosed.ID)
+	}
+	return nil
+}
+
+func (n *niriBackend) apply(w Window, a Actions) error {
+	var errs []string
+	action := func(args ...string) {
+		full := append([]string{"msg", "action"}, args...)
+		if _, err := n.run("niri", full...); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if a.Floating != nil {
+		if *a.Floating {
+			action("move-window-to-floating", "--id", w.ID)
+		} else {
+			action("move-window-to-tiling", "--id", w.ID)
+		}
+	}
+	if a.Output != "" {
+		action("move-window-to-monitor", "--id", w.ID, a.Output)
+	}
+	if a.Workspace != "" {
+		action("move-window-to-workspace", "--window-id", w.ID, "--focus", "false", a.Workspace)
+	}
+	if a.Maximized != nil && *a.Maximized {
+		action("focus-window", "--id", w.ID)
+		action("maximize-column")
+	}
+	if a.Fullscreen != nil && *a.Fullscreen {
+		action("fullscreen-window", "--id", w.ID)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+)
+
+// parseRulesTOML reads rules.toml, which holds nothing but [[rule]] blocks
+func parseRulesTOML(data string) ([]tomlite.Table, error) {
+	tables, err := tomlite.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tables {
+		switch {
+		case t.Name == "":
+			keys := make([]string, 0, len(t.Values))
+			for key := range t.Values {
+				keys = append(keys, key)
+			}
+			sort.Slice(keys, func(i, j int) bool { return t.Lines[keys[i]] < t.Lines[keys[j]] })
+			return nil, fmt.Errorf("line %d: %s is set outside a [[rule]] block", t.Lines[keys[0]], keys[0])
+		case t.Name != "rule" || !t.Array:
+			return nil, fmt.Errorf("line %d: unsupported table %s, only [[rule]] is allowed", t.Line, t.Name)
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRulesTOML(t *testing.T) {
+	data := `# window rules
+[[rule]]
+name = "Firefox on 3" # trailing comment
+app-id = '^firefox$'
+workspace = 3
+
+[ [rule] ]
+title = "Picture-in-Picture"
+floating = true
+opacity = 0.9
+`
+	tables, err := parseRulesTOML(data)
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+
+	assert.Equal(t, 2, tables[0].Line)
+	assert.Equal(t, "Firefox on 3", tables[0].Values["name"])
+	assert.Equal(t, "^firefox$", tables[0].Values["app-id"])
+	assert.Equal(t, int64(3), tables[0].Values["workspace"])
+	assert.Equal(t, 5, tables[0].Lines["workspace"])
+
+	assert.Equal(t, true, tables[1].Values["floating"])
+	assert.Equal(t, 0.9, tables[1].Values["opacity"])
+}
+
+func TestParseRulesTOML_HashInString(t *testing.T) {
+	tables, err := parseRulesTOML("[[rule]]\ntitle = \"Issue #12 \\\"quoted\\\"\" # comment\n")
+	require.NoError(t, err)
+	assert.Equal(t, `Issue #12 "quoted"`, tables[0].Values["title"])
+}
+
+func TestParseRulesTOML_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"key outside table", "workspace = 1\n", "line 1: workspace is set outside a [[rule]] block"},
+		{"other table", "[general]\n", "only [[rule]] is allowed"},
+		{"duplicate key", "[[rule]]\ntitle = \"a\"\ntitle = \"b\"\n", "line 3: title is set twice"},
+		{"unterminated", "[[rule]]\ntitle = \"abc\n", "unterminated string"},
+		{"bare word", "[[rule]]\nfloating = yes\n", "unsupported value yes"},
+		{"no equals", "[[rule]]\nfloating\n", "expected key = value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRulesTOML(tt.data)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
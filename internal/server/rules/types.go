@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Actions are what a rule does to a matching window. Unset fields leave the
+// compositor's own behaviour alone.
+type Actions struct {
+	Workspace  string   `json:"workspace,omitempty"`
+	Output     string   `json:"output,omitempty"`
+	Floating   *bool    `json:"floating,omitempty"`
+	Fullscreen *bool    `json:"fullscreen,omitempty"`
+	Maximized  *bool    `json:"maximized,omitempty"`
+	Opacity    *float64 `json:"opacity,omitempty"`
+}
+
+// Rule matches newly opened windows by app ID and title regexes
+type Rule struct {
+	Name  string `json:"name"`
+	AppID string `json:"appId,omitempty"`
+	Title string `json:"title,omitempty"`
+	Line  int    `json:"line"`
+	Actions
+
+	appID *regexp.Regexp
+	title *regexp.Regexp
+}
+
+// Window is a newly opened toplevel as reported by the compositor
+type Window struct {
+	ID    string `json:"id"`
+	AppID string `json:"appId"`
+	Title string `json:"title"`
+}
+
+// Conflict is two or more rules setting the same property of a window to
+// different values. The last rule in the file wins, as in the compositors'
+// own configs.
+type Conflict struct {
+	Property string   `json:"property"`
+	Rules    []string `json:"rules"`
+	Values   []string `json:"values"`
+	// Window is the app ID that triggered the conflict, empty when the rules
+	// share the same matchers and always collide
+	Window string `json:"window,omitempty"`
+}
+
+// MatchResult is what the rules would do to a window
+type MatchResult struct {
+	Rules     []string   `json:"rules"`
+	Actions   Actions    `json:"actions"`
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+type State struct {
+	Path      string     `json:"path"`
+	Backend   string     `json:"backend"`
+	Rules     []Rule     `json:"rules"`
+	Errors    []string   `json:"errors,omitempty"`
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	Applied   int        `json:"applied"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// windowBackend is the compositor side of the engine: it reports windows as
+// they open and applies actions to them
+type windowBackend interface {
+	name() string
+	watch(stop <-chan struct{}, opened func(Window)) error
+	apply(w Window, a Actions) error
+	// unsupported lists action properties the compositor cannot apply at
+	// runtime
+	unsupported() []string
+}
+
+type Manager struct {
+	backend windowBackend
+	path    string
+
+	stateMutex sync.RWMutex
+	rules      []Rule
+	errors     []string
+	conflicts  []Conflict
+	seen       map[string]bool
+	applied    int
+
+	stopChan chan struct{}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+const safeguardTokenTTL = 30 * time.Second
+
+// destructiveMethods require a confirmation round-trip when server.safeguard
+// is turned on in daemon.toml.
+var destructiveMethods = map[string]bool{
+	"cups.purgeJobs":      true,
+	"cups.deletePrinter":  true,
+	"network.wifi.forget": true,
+	"loginctl.terminate":  true,
+}
+
+type ConfirmationRequired struct {
+	ConfirmationRequired bool   `json:"confirmationRequired"`
+	Method               string `json:"method"`
+	Token                string `json:"token"`
+	ExpiresIn            int    `json:"expiresIn"`
+}
+
+// pendingConfirmation remembers the socket a token was issued to, so another
+// client cannot confirm a call it did not make. The socket rather than conn
+// is kept because each request may arrive wrapped in a fresh MetaConn.
+type pendingConfirmation struct {
+	conn    net.Conn
+	method  string
+	expires time.Time
+}
+
+type safeguard struct {
+	mu      sync.Mutex
+	enabled func() bool
+	pending map[string]pendingConfirmation
+	now     func() time.Time
+}
+
+var requestSafeguard = newSafeguard(func() bool {
+	return getDaemonConfig().Bool("server.safeguard")
+})
+
+func newSafeguard(enabled func() bool) *safeguard {
+	return &safeguard{
+		enabled: enabled,
+		pending: make(map[string]pendingConfirmation),
+		now:     time.Now,
+	}
+}
+
+func (s *safeguard) requiresConfirmation(method string) bool {
+	return destructiveMethods[method] && s.enabled()
+}
+
+func (s *safeguard) issue(conn net.Conn, method string) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for t, p := range s.pending {
+		if now.After(p.expires) {
+			delete(s.pending, t)
+		}
+	}
+	s.pending[token] = pendingConfirmation{conn: models.BaseConn(conn), method: method, expires: now.Add(safeguardTokenTTL)}
+
+	return token, nil
+}
+
+// consume validates a token for method on conn and invalidates it. Tokens
+// are single-use.
+func (s *safeguard) consume(conn net.Conn, method, token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.pending[token]
+	if !ok {
+		return false
+	}
+	delete(s.pending, token)
+
+	return p.conn == models.BaseConn(conn) && p.method == method && !s.now().After(p.expires)
+}
+
+// checkSafeguard returns true when the request may proceed. Otherwise it has
+// already responded, either with a fresh confirmation token or an error.
+func checkSafeguard(conn net.Conn, req models.Request) bool {
+	if !requestSafeguard.requiresConfirmation(req.Method) {
+		return true
+	}
+
+	if token, ok := req.Params["confirmToken"].(string); ok && token != "" {
+		if requestSafeguard.consume(conn, req.Method, token) {
+			return true
+		}
+		models.RespondError(conn, req.ID, "invalid or expired confirmation token")
+		return false
+	}
+
+	token, err := requestSafeguard.issue(conn, req.Method)
+	if err != nil {
+		models.RespondError(conn, req.ID, "failed to issue confirmation token: "+err.Error())
+		return false
+	}
+
+	models.Respond(conn, req.ID, ConfirmationRequired{
+		ConfirmationRequired: true,
+		Method:               req.Method,
+		Token:                token,
+		ExpiresIn:            int(safeguardTokenTTL.Seconds()),
+	})
+	return false
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeguard_RequiresConfirmation(t *testing.T) {
+	enabled := true
+	s := newSafeguard(func() bool { return enabled })
+	assert.True(t, s.requiresConfirmation("cups.purgeJobs"))
+	assert.True(t, s.requiresConfirmation("network.wifi.forget"))
+	assert.False(t, s.requiresConfirmation("cups.getPrinters"))
+
+	enabled = false
+	assert.False(t, s.requiresConfirmation("cups.purgeJobs"))
+}
+
+func TestSafeguard_TokenLifecycle(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := newSafeguard(func() bool { return true })
+	s.now = func() time.Time { return now }
+	conn := &mockConn{}
+
+	t.Run("token is single use", func(t *testing.T) {
+		token, err := s.issue(conn, "cups.purgeJobs")
+		require.NoError(t, err)
+		assert.True(t, s.consume(conn, "cups.purgeJobs", token))
+		assert.False(t, s.consume(conn, "cups.purgeJobs", token))
+	})
+
+	t.Run("token is bound to method", func(t *testing.T) {
+		token, err := s.issue(conn, "cups.purgeJobs")
+		require.NoError(t, err)
+		assert.False(t, s.consume(conn, "loginctl.terminate", token))
+	})
+
+	t.Run("token is bound to connection", func(t *testing.T) {
+		token, err := s.issue(conn, "cups.purgeJobs")
+		require.NoError(t, err)
+		assert.False(t, s.consume(&mockConn{}, "cups.purgeJobs", token))
+		assert.False(t, s.consume(conn, "cups.purgeJobs", token))
+	})
+
+	t.Run("token expires", func(t *testing.T) {
+		token, err := s.issue(conn, "cups.purgeJobs")
+		require.NoError(t, err)
+		now = now.Add(safeguardTokenTTL + time.Second)
+		assert.False(t, s.consume(conn, "cups.purgeJobs", token))
+	})
+}
+
+func TestCheckSafeguard(t *testing.T) {
+	original := requestSafeguard
+	defer func() { requestSafeguard = original }()
+	requestSafeguard = newSafeguard(func() bool { return true })
+
+	t.Run("non-destructive passes through", func(t *testing.T) {
+		conn := &mockConn{}
+		assert.True(t, checkSafeguard(conn, models.Request{ID: 1, Method: "ping"}))
+		assert.Empty(t, conn.written)
+	})
+
+	t.Run("first call returns token, echo proceeds", func(t *testing.T) {
+		conn := &mockConn{}
+		req := models.Request{ID: 2, Method: "cups.purgeJobs", Params: map[string]interface{}{"printerName": "p"}}
+		assert.False(t, checkSafeguard(conn, req))
+
+		var resp models.Response[ConfirmationRequired]
+		require.NoError(t, json.Unmarshal(conn.written, &resp))
+		require.NotNil(t, resp.Result)
+		assert.True(t, resp.Result.ConfirmationRequired)
+		assert.NotEmpty(t, resp.Result.Token)
+
+		req.Params["confirmToken"] = resp.Result.Token
+		assert.True(t, checkSafeguard(conn, req))
+	})
+
+	t.Run("token from another connection is rejected", func(t *testing.T) {
+		conn := &mockConn{}
+		req := models.Request{ID: 4, Method: "cups.purgeJobs", Params: map[string]interface{}{"printerName": "p"}}
+		assert.False(t, checkSafeguard(conn, req))
+
+		var resp models.Response[ConfirmationRequired]
+		require.NoError(t, json.Unmarshal(conn.written, &resp))
+		require.NotNil(t, resp.Result)
+
+		other := &mockConn{}
+		req.Params["confirmToken"] = resp.Result.Token
+		assert.False(t, checkSafeguard(other, req))
+
+		var rejected models.Response[any]
+		require.NoError(t, json.Unmarshal(other.written, &rejected))
+		assert.NotEmpty(t, rejected.Error)
+	})
+
+	t.Run("bad token is rejected", func(t *testing.T) {
+		conn := &mockConn{}
+		req := models.Request{ID: 3, Method: "cups.purgeJobs", Params: map[string]interface{}{"confirmToken": "nope"}}
+		assert.False(t, checkSafeguard(conn, req))
+
+		var resp models.Response[any]
+		require.NoError(t, json.Unmarshal(conn.written, &resp))
+		assert.NotEmpty(t, resp.Error)
+	})
+}
+
+func TestRouteRequest_SafeguardWithAPIVersion(t *testing.T) {
+	original := requestSafeguard
+	defer func() { requestSafeguard = original }()
+	requestSafeguard = newSafeguard(func() bool { return true })
+
+	conn := &mockConn{}
+	req := models.Request{ID: 1, Method: "cups.purgeJobs", APIVersion: APIVersion, Params: map[string]interface{}{"printerName": "p"}}
+	RouteRequest(conn, req)
+
+	var resp models.Response[ConfirmationRequired]
+	require.NoError(t, json.Unmarshal(conn.written, &resp))
+	require.NotNil(t, resp.Result)
+	require.True(t, resp.Result.ConfirmationRequired)
+
+	// Each request is wrapped in its own MetaConn, so the echo only gets
+	// through if the token is bound to the socket underneath
+	conn.written = nil
+	req.Params["confirmToken"] = resp.Result.Token
+	RouteRequest(conn, req)
+
+	var confirmed models.Response[any]
+	require.NoError(t, json.Unmarshal(conn.written, &confirmed))
+	assert.Equal(t, "CUPS manager not initialized", confirmed.Error)
+}
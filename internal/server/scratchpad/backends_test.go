@@ -0,0 +1,104 @@
+package scratchpad
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHyprlandClients(t *testing.T) {
+	windows, err := parseHyprlandClients([]byte(`[
+		{"address": "0xaa", "class": "dms-term", "at": [10, 20], "size": [800, 400], "floating": true,
+		 "workspace": {"id": -98, "name": "special:dms-scratchpad"}, "focusHistoryID": 3},
+		{"address": "0xbb", "class": "firefox", "at": [0, 0], "size": [1920, 1080], "floating": false,
+		 "workspace": {"id": 1, "name": "1"}, "focusHistoryID": 0}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, windows, 2)
+
+	assert.Equal(t, window{ID: "0xaa", AppID: "dms-term", Hidden: true, Geometry: &Geometry{X: 10, Y: 20, Width: 800, Height: 400}}, windows[0])
+	assert.True(t, windows[1].Focused)
+	assert.Nil(t, windows[1].Geometry, "tiled windows have no floating geometry to remember")
+}
+
+func TestHyprlandShowDispatches(t *testing.T) {
+	w := window{ID: "0xaa"}
+	assert.Equal(t, []string{
+		"dispatch movetoworkspacesilent 3,address:0xaa",
+		"dispatch setfloating address:0xaa",
+		"dispatch resizewindowpixel exact 800 400,address:0xaa",
+		"dispatch movewindowpixel exact 10 20,address:0xaa",
+		"dispatch focuswindow address:0xaa",
+	}, hyprlandShowDispatches(w, 3, &Geometry{X: 10, Y: 20, Width: 800, Height: 400}))
+
+	cmds := hyprlandShowDispatches(w, 3, nil)
+	assert.Equal(t, "dispatch centerwindow", cmds[len(cmds)-1])
+}
+
+func TestHyprlandBackend(t *testing.T) {
+	var calls []string
+	h := &hyprlandBackend{run: func(name string, args ...string) ([]byte, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		if len(args) > 0 && args[0] == "activeworkspace" {
+			return []byte(`{"id": 2}`), nil
+		}
+		return nil, nil
+	}}
+
+	require.NoError(t, h.hide(window{ID: "0xaa"}))
+	require.NoError(t, h.show(window{ID: "0xaa"}, nil))
+	assert.Equal(t, "hyprctl dispatch movetoworkspacesilent special:dms-scratchpad,address:0xaa", calls[0])
+	assert.Contains(t, calls[2], "movetoworkspacesilent 2,address:0xaa")
+}
+
+func TestNiriToWindows(t *testing.T) {
+	ws := func(id uint64) *uint64 { return &id }
+	windows := niriToWindows([]niriWindow{
+		{ID: 1, AppID: "dms-term", WorkspaceID: ws(5), IsFloating: true},
+		{ID: 2, AppID: "firefox", WorkspaceID: ws(1), IsFocused: true},
+	}, []niriWorkspace{
+		{ID: 1, Idx: 1, Output: "DP-1", IsActive: true, IsFocused: true},
+		{ID: 5, Idx: 2, Output: "DP-1"},
+	})
+
+	require.Len(t, windows, 2)
+	assert.True(t, windows[0].Hidden, "windows on workspaces no output shows are hidden")
+	assert.False(t, windows[1].Hidden)
+	assert.True(t, windows[1].Focused)
+}
+
+func TestNiriParkingWorkspace(t *testing.T) {
+	ws := func(id uint64) *uint64 { return &id }
+	windows := []niriWindow{{ID: 1, WorkspaceID: ws(1)}}
+	workspaces := []niriWorkspace{
+		{ID: 1, Idx: 1, Output: "DP-1", IsActive: true},
+		{ID: 2, Idx: 2, Output: "DP-1"},
+		{ID: 3, Idx: 1, Output: "HDMI-A-1", IsActive: true},
+	}
+
+	target, err := niriParkingWorkspace("1", windows, workspaces)
+	require.NoError(t, err)
+	assert.Equal(t, "2", target)
+
+	name := niriWorkspaceName
+	workspaces = append(workspaces, niriWorkspace{ID: 4, Idx: 3, Name: &name, Output: "DP-1"})
+	target, err = niriParkingWorkspace("1", windows, workspaces)
+	require.NoError(t, err)
+	assert.Equal(t, niriWorkspaceName, target)
+}
+
+func TestNiriShowActions(t *testing.T) {
+	focused := niriWorkspace{ID: 3, Idx: 1, Output: "HDMI-A-1", IsFocused: true}
+	actions := niriShowActions("1", "DP-1", focused, &Geometry{Width: 800, Height: 400})
+
+	assert.Equal(t, [][]string{
+		{"move-window-to-monitor", "--id", "1", "HDMI-A-1"},
+		{"move-window-to-workspace", "--window-id", "1", "--focus", "false", "1"},
+		{"move-window-to-floating", "--id", "1"},
+		{"set-window-width", "--id", "1", "800"},
+		{"set-window-height", "--id", "1", "400"},
+		{"focus-window", "--id", "1"},
+	}, actions)
+}
@@ -0,0 +1,119 @@
+package scratchpad
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/AvengeMedia/danklinux/internal/tomlite"
+)
+
+var padNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parsePads reads scratchpads.toml, which holds [[scratchpad]] blocks:
+//
+//	[[scratchpad]]
+//	name = "term"
+//	command = "kitty --class dms-term"
+//	app-id = "dms-term"
+//	width = 1200
+//	height = 700
+//
+// Invalid blocks are reported and skipped.
+func parsePads(data string) ([]Pad, []string, error) {
+	tables, err := tomlite.Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pads []Pad
+	var errs []string
+	seen := make(map[string]bool)
+	for _, t := range tables {
+		if t.Name != "scratchpad" || !t.Array {
+			return nil, nil, fmt.Errorf("line %d: only [[scratchpad]] blocks are allowed", tableLine(t))
+		}
+		pad, err := compilePad(t)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if seen[pad.Name] {
+			errs = append(errs, fmt.Sprintf("scratchpad %q (line %d): defined twice", pad.Name, pad.Line))
+			continue
+		}
+		seen[pad.Name] = true
+		pads = append(pads, pad)
+	}
+	return pads, errs, nil
+}
+
+// tableLine is the header line, or the first key of the headerless root
+func tableLine(t tomlite.Table) int {
+	if t.Name != "" {
+		return t.Line
+	}
+	lines := make([]int, 0, len(t.Lines))
+	for _, line := range t.Lines {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines[0]
+}
+
+func compilePad(t tomlite.Table) (Pad, error) {
+	pad := Pad{Line: t.Line}
+	name, _ := t.Values["name"].(string)
+	if !padNamePattern.MatchString(name) {
+		return Pad{}, fmt.Errorf("scratchpad at line %d: name must be letters, digits, '-' or '_'", t.Line)
+	}
+	pad.Name = name
+	fail := func(format string, args ...any) (Pad, error) {
+		return Pad{}, fmt.Errorf("scratchpad %q (line %d): %s", name, t.Line, fmt.Sprintf(format, args...))
+	}
+
+	keys := make([]string, 0, len(t.Values))
+	for key := range t.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := t.Values[key]
+		switch key {
+		case "name":
+		case "command":
+			s, ok := value.(string)
+			if !ok || s == "" {
+				return fail("command must be a non-empty string")
+			}
+			pad.Command = s
+		case "app-id", "app_id":
+			s, ok := value.(string)
+			if !ok || s == "" {
+				return fail("%s must be a non-empty string", key)
+			}
+			pad.AppID = s
+		case "width", "height":
+			n, ok := value.(int64)
+			if !ok || n < 0 {
+				return fail("%s must be a positive number of pixels", key)
+			}
+			if key == "width" {
+				pad.Width = int(n)
+			} else {
+				pad.Height = int(n)
+			}
+		default:
+			return fail("unknown key %s", key)
+		}
+	}
+
+	if pad.Command == "" {
+		return fail("command is required")
+	}
+	if pad.AppID == "" {
+		return fail("app-id is required to find the window")
+	}
+	return pad, nil
+}
@@ -0,0 +1,53 @@
+package scratchpad
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePads(t *testing.T) {
+	pads, errs, err := parsePads(`
+[[scratchpad]]
+name = "term"
+command = "kitty --class dms-term"
+app-id = "dms-term"
+width = 1200
+height = 700
+
+[[scratchpad]]
+name = "bad name"
+command = "pavucontrol"
+app-id = "pavucontrol"
+
+[[scratchpad]]
+name = "mixer"
+command = "pavucontrol"
+
+[[scratchpad]]
+name = "term"
+command = "foot"
+app-id = "foot"
+`)
+	require.NoError(t, err)
+	require.Len(t, pads, 1)
+	assert.Equal(t, Pad{Name: "term", Command: "kitty --class dms-term", AppID: "dms-term", Width: 1200, Height: 700, Line: 2}, pads[0])
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0], "name must be")
+	assert.Contains(t, errs[1], "app-id is required")
+	assert.Contains(t, errs[2], "defined twice")
+}
+
+func TestParsePads_Errors(t *testing.T) {
+	_, _, err := parsePads("name = \"term\"\n")
+	assert.ErrorContains(t, err, "line 1")
+
+	_, _, err = parsePads("[pad]\nname = \"term\"\n")
+	assert.ErrorContains(t, err, "only [[scratchpad]]")
+
+	_, errs, err := parsePads("[[scratchpad]]\nname = \"t\"\ncommand = \"foot\"\napp-id = \"foot\"\nopacity = 0.9\n")
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "unknown key opacity")
+}
@@ -0,0 +1,57 @@
+package scratchpad
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "scratchpad.getState":
+		handleGetState(conn, req, manager)
+	case "scratchpad.reload":
+		handleReload(conn, req, manager)
+	case "scratchpad.toggle":
+		handleToggle(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleReload(conn net.Conn, req Request, manager *Manager) {
+	if err := manager.Reload(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleToggle(conn net.Conn, req Request, manager *Manager) {
+	name, ok := req.Params["name"].(string)
+	if !ok || name == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'name' parameter")
+		return
+	}
+
+	// A command declares the pad on the fly
+	if command, _ := req.Params["command"].(string); command != "" {
+		appID, _ := req.Params["appId"].(string)
+		if err := manager.Define(Pad{Name: name, Command: command, AppID: appID}); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+	}
+
+	result, err := manager.Toggle(name)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
@@ -0,0 +1,108 @@
+package scratchpad
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// hyprlandSpecial is the special workspace hidden pads are parked on. It is
+// never shown by DMS; togglespecialworkspace would reveal every pad at once.
+const hyprlandSpecial = "special:dms-scratchpad"
+
+type hyprlandBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newHyprlandBackend() *hyprlandBackend {
+	return &hyprlandBackend{run: compositor.Run}
+}
+
+func (h *hyprlandBackend) name() string { return "hyprland" }
+
+type hyprlandClient struct {
+	Address   string `json:"address"`
+	Class     string `json:"class"`
+	At        [2]int `json:"at"`
+	Size      [2]int `json:"size"`
+	Floating  bool   `json:"floating"`
+	Workspace struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"workspace"`
+	FocusHistoryID int `json:"focusHistoryID"`
+}
+
+func (h *hyprlandBackend) windows() ([]window, error) {
+	out, err := h.run("hyprctl", "clients", "-j")
+	if err != nil {
+		return nil, err
+	}
+	return parseHyprlandClients(out)
+}
+
+func parseHyprlandClients(data []byte) ([]window, error) {
+	var clients []hyprlandClient
+	if err := json.Unmarshal(data, &clients); err != nil {
+		return nil, fmt.Errorf("failed to parse hyprctl clients: %w", err)
+	}
+
+	windows := make([]window, 0, len(clients))
+	for _, c := range clients {
+		w := window{
+			ID:      c.Address,
+			AppID:   c.Class,
+			Hidden:  c.Workspace.Name == hyprlandSpecial,
+			Focused: c.FocusHistoryID == 0,
+		}
+		if c.Floating {
+			w.Geometry = &Geometry{X: c.At[0], Y: c.At[1], Width: c.Size[0], Height: c.Size[1]}
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func (h *hyprlandBackend) hide(w window) error {
+	_, err := h.run("hyprctl", "dispatch", "movetoworkspacesilent", hyprlandSpecial+",address:"+w.ID)
+	return err
+}
+
+func (h *hyprlandBackend) show(w window, g *Geometry) error {
+	out, err := h.run("hyprctl", "activeworkspace", "-j")
+	if err != nil {
+		return err
+	}
+	var active struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(out, &active); err != nil {
+		return fmt.Errorf("failed to parse active workspace: %w", err)
+	}
+
+	_, err = h.run("hyprctl", "--batch", strings.Join(hyprlandShowDispatches(w, active.ID, g), " ; "))
+	return err
+}
+
+// hyprlandShowDispatches brings the window to the workspace, floating, sized
+// and focused. Without a geometry it is centred at whatever size it has.
+func hyprlandShowDispatches(w window, workspace int, g *Geometry) []string {
+	addr := "address:" + w.ID
+	cmds := []string{
+		fmt.Sprintf("dispatch movetoworkspacesilent %d,%s", workspace, addr),
+		"dispatch setfloating " + addr,
+	}
+	if g != nil {
+		cmds = append(cmds, fmt.Sprintf("dispatch resizewindowpixel exact %d %d,%s", g.Width, g.Height, addr))
+	}
+	if g != nil && (g.X != 0 || g.Y != 0) {
+		cmds = append(cmds, fmt.Sprintf("dispatch movewindowpixel exact %d %d,%s", g.X, g.Y, addr))
+	}
+	cmds = append(cmds, "dispatch focuswindow "+addr)
+	if g == nil || (g.X == 0 && g.Y == 0) {
+		cmds = append(cmds, "dispatch centerwindow")
+	}
+	return cmds
+}
@@ -0,0 +1,368 @@
+package scratchpad
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	defaultMapTimeout = 5 * time.Second
+	mapPollInterval   = 100 * time.Millisecond
+)
+
+// NewManager picks the compositor backend from the environment and loads
+// scratchpads.toml. Pads can also be declared on the fly in
+// scratchpad.toggle, so a missing file is not an error.
+func NewManager() (*Manager, error) {
+	backend, err := detectBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+
+	m := newManager(backend, ConfigPath(), filepath.Join(stateHome, "DankMaterialShell", "scratchpads.json"), spawnDetached)
+	m.loadGeometry()
+	if err := m.Reload(); err != nil {
+		log.Warnf("Scratchpads not loaded: %v", err)
+	}
+	return m, nil
+}
+
+func newManager(backend windowBackend, path, statePath string, spawn func(string) error) *Manager {
+	return &Manager{
+		backend:    backend,
+		path:       path,
+		statePath:  statePath,
+		spawn:      spawn,
+		mapTimeout: defaultMapTimeout,
+		pads:       make(map[string]Pad),
+		geometry:   make(map[string]Geometry),
+		pending:    make(map[string]time.Time),
+		stopChan:   make(chan struct{}),
+	}
+}
+
+func detectBackend() (windowBackend, error) {
+	switch compositor.Detect(os.Getenv) {
+	case compositor.Niri:
+		return newNiriBackend(), nil
+	case compositor.Hyprland:
+		return newHyprlandBackend(), nil
+	}
+	return nil, fmt.Errorf("scratchpads need niri or Hyprland")
+}
+
+// ConfigPath is where users declare their scratchpads
+func ConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "scratchpads.toml")
+}
+
+// Reload re-reads the config. Pads declared through scratchpad.toggle are
+// dropped; a parse error keeps the previous pads.
+func (m *Manager) Reload() error {
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = nil, nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", m.path, err)
+	}
+
+	pads, errs, err := parsePads(string(data))
+	if err != nil {
+		m.mu.Lock()
+		m.errors = []string{err.Error()}
+		m.mu.Unlock()
+		return fmt.Errorf("failed to parse %s: %w", m.path, err)
+	}
+	for _, e := range errs {
+		log.Warnf("Scratchpads: %s", e)
+	}
+
+	m.mu.Lock()
+	m.pads = make(map[string]Pad)
+	m.order = nil
+	for _, pad := range pads {
+		m.pads[pad.Name] = pad
+		m.order = append(m.order, pad.Name)
+	}
+	m.errors = errs
+	m.mu.Unlock()
+
+	log.Infof("Loaded %d scratchpads from %s", len(pads), m.path)
+	return nil
+}
+
+func (m *Manager) GetState() State {
+	m.mu.Lock()
+	pads := make([]Pad, 0, len(m.order))
+	for _, name := range m.order {
+		pads = append(pads, m.pads[name])
+	}
+	state := State{
+		Path:    m.path,
+		Backend: m.backend.name(),
+		Errors:  append([]string(nil), m.errors...),
+	}
+	geometry := make(map[string]Geometry, len(m.geometry))
+	for name, g := range m.geometry {
+		geometry[name] = g
+	}
+	m.mu.Unlock()
+
+	windows, err := m.backend.windows()
+	if err != nil {
+		log.Debugf("Scratchpads: failed to list windows: %v", err)
+	}
+
+	for _, pad := range pads {
+		ps := PadState{Pad: pad}
+		if w := findWindow(windows, pad.AppID); w != nil {
+			ps.Running = true
+			ps.Visible = !w.Hidden
+		}
+		if g, ok := geometry[pad.Name]; ok {
+			ps.Geometry = &g
+		}
+		state.Pads = append(state.Pads, ps)
+	}
+	return state
+}
+
+// Define adds or replaces a pad for this daemon run, which lets a keybinding
+// carry its own command instead of needing scratchpads.toml
+func (m *Manager) Define(pad Pad) error {
+	if !padNamePattern.MatchString(pad.Name) {
+		return fmt.Errorf("invalid scratchpad name %q", pad.Name)
+	}
+	if pad.Command == "" || pad.AppID == "" {
+		return fmt.Errorf("scratchpad %q needs a command and an app ID", pad.Name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.pads[pad.Name]; !exists {
+		m.order = append(m.order, pad.Name)
+	}
+	m.pads[pad.Name] = pad
+	return nil
+}
+
+// Toggle starts the pad when it is not running, shows it when hidden or out
+// of focus and hides it when it is the focused window. The geometry is
+// remembered on hide and restored on the next show.
+func (m *Manager) Toggle(name string) (ToggleResult, error) {
+	result := ToggleResult{Name: name}
+
+	m.mu.Lock()
+	pad, ok := m.pads[name]
+	m.mu.Unlock()
+	if !ok {
+		return result, fmt.Errorf("unknown scratchpad %q", name)
+	}
+
+	windows, err := m.backend.windows()
+	if err != nil {
+		return result, fmt.Errorf("failed to list windows: %w", err)
+	}
+
+	w := findWindow(windows, pad.AppID)
+	switch {
+	case w == nil:
+		return m.start(pad)
+	case w.Hidden || !w.Focused:
+		if err := m.backend.show(*w, m.geometryFor(pad)); err != nil {
+			return result, fmt.Errorf("failed to show %s: %w", name, err)
+		}
+		result.Action = ActionShown
+	default:
+		if w.Geometry != nil {
+			m.rememberGeometry(name, *w.Geometry)
+		}
+		if err := m.backend.hide(*w); err != nil {
+			return result, fmt.Errorf("failed to hide %s: %w", name, err)
+		}
+		result.Action = ActionHidden
+	}
+	return result, nil
+}
+
+// start spawns the pad and shows its window once it maps. Toggling again
+// while it starts does not spawn a second copy.
+func (m *Manager) start(pad Pad) (ToggleResult, error) {
+	result := ToggleResult{Name: pad.Name}
+
+	m.mu.Lock()
+	if since, ok := m.pending[pad.Name]; ok && time.Since(since) < m.mapTimeout {
+		m.mu.Unlock()
+		result.Action = ActionStarting
+		return result, nil
+	}
+	m.pending[pad.Name] = time.Now()
+	m.mu.Unlock()
+
+	if err := m.spawn(pad.Command); err != nil {
+		m.mu.Lock()
+		delete(m.pending, pad.Name)
+		m.mu.Unlock()
+		return result, fmt.Errorf("failed to start %s: %w", pad.Name, err)
+	}
+
+	go m.showWhenMapped(pad)
+
+	result.Action = ActionSpawned
+	return result, nil
+}
+
+func (m *Manager) showWhenMapped(pad Pad) {
+	defer crash.Capture("scratchpad.showWhenMapped", nil)
+	defer func() {
+		m.mu.Lock()
+		delete(m.pending, pad.Name)
+		m.mu.Unlock()
+	}()
+
+	deadline := time.After(m.mapTimeout)
+	ticker := time.NewTicker(mapPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-deadline:
+			log.Warnf("Scratchpad %s: no window with app ID %s appeared", pad.Name, pad.AppID)
+			return
+		case <-ticker.C:
+		}
+
+		windows, err := m.backend.windows()
+		if err != nil {
+			continue
+		}
+		if w := findWindow(windows, pad.AppID); w != nil {
+			if err := m.backend.show(*w, m.geometryFor(pad)); err != nil {
+				log.Warnf("Scratchpad %s: %v", pad.Name, err)
+			}
+			return
+		}
+	}
+}
+
+func findWindow(windows []window, appID string) *window {
+	for i := range windows {
+		if windows[i].AppID == appID {
+			return &windows[i]
+		}
+	}
+	return nil
+}
+
+// geometryFor is the remembered geometry, else the configured size
+func (m *Manager) geometryFor(pad Pad) *Geometry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if g, ok := m.geometry[pad.Name]; ok {
+		return &g
+	}
+	if pad.Width > 0 && pad.Height > 0 {
+		return &Geometry{Width: pad.Width, Height: pad.Height}
+	}
+	return nil
+}
+
+func (m *Manager) rememberGeometry(name string, g Geometry) {
+	if g.Width <= 0 || g.Height <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.geometry[name] == g {
+		return
+	}
+	m.geometry[name] = g
+	m.saveGeometry()
+}
+
+func (m *Manager) loadGeometry() {
+	data, err := os.ReadFile(m.statePath)
+	if err != nil {
+		return
+	}
+
+	var geometry map[string]Geometry
+	if err := json.Unmarshal(data, &geometry); err != nil {
+		log.Warnf("Ignoring corrupt scratchpad state %s: %v", m.statePath, err)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, g := range geometry {
+		m.geometry[name] = g
+	}
+}
+
+// saveGeometry must be called with the mutex held
+func (m *Manager) saveGeometry() {
+	data, err := json.MarshalIndent(m.geometry, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0755); err != nil {
+		log.Warnf("Failed to create %s: %v", filepath.Dir(m.statePath), err)
+		return
+	}
+	tmp := m.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Warnf("Failed to save scratchpad geometry: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, m.statePath); err != nil {
+		log.Warnf("Failed to save scratchpad geometry: %v", err)
+	}
+}
+
+// spawnDetached runs the command through the shell in its own session, so
+// pads outlive a daemon restart
+func spawnDetached(command string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func (m *Manager) Close() {
+	select {
+	case <-m.stopChan:
+	default:
+		close(m.stopChan)
+	}
+}
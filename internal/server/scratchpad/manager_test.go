@@ -0,0 +1,145 @@
+package scratchpad
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCompositor struct {
+	mu      sync.Mutex
+	wins    []window
+	shown   []*Geometry
+	hidden  []string
+	listErr error
+}
+
+func (f *fakeCompositor) name() string { return "fake" }
+
+func (f *fakeCompositor) windows() ([]window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]window(nil), f.wins...), f.listErr
+}
+
+func (f *fakeCompositor) setWindows(wins ...window) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.wins = wins
+}
+
+func (f *fakeCompositor) hide(w window) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hidden = append(f.hidden, w.ID)
+	return nil
+}
+
+func (f *fakeCompositor) show(w window, g *Geometry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.shown = append(f.shown, g)
+	return nil
+}
+
+func (f *fakeCompositor) shownCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.shown)
+}
+
+func newTestManager(t *testing.T, backend *fakeCompositor) (*Manager, *[]string) {
+	t.Helper()
+	dir := t.TempDir()
+	var spawned []string
+	m := newManager(backend, filepath.Join(dir, "scratchpads.toml"), filepath.Join(dir, "state", "scratchpads.json"), func(command string) error {
+		spawned = append(spawned, command)
+		return nil
+	})
+	t.Cleanup(m.Close)
+	require.NoError(t, m.Define(Pad{Name: "term", Command: "foot --app-id dms-term", AppID: "dms-term", Width: 900, Height: 500}))
+	return m, &spawned
+}
+
+func TestManager_ToggleLifecycle(t *testing.T) {
+	backend := &fakeCompositor{}
+	m, spawned := newTestManager(t, backend)
+
+	result, err := m.Toggle("term")
+	require.NoError(t, err)
+	assert.Equal(t, ActionSpawned, result.Action)
+	assert.Equal(t, []string{"foot --app-id dms-term"}, *spawned)
+
+	result, err = m.Toggle("term")
+	require.NoError(t, err)
+	assert.Equal(t, ActionStarting, result.Action, "a second toggle while starting does not spawn again")
+	assert.Len(t, *spawned, 1)
+
+	backend.setWindows(window{ID: "1", AppID: "dms-term", Focused: true})
+	require.Eventually(t, func() bool { return backend.shownCount() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, &Geometry{Width: 900, Height: 500}, backend.shown[0], "first show uses the configured size")
+
+	backend.setWindows(window{ID: "1", AppID: "dms-term", Focused: true, Geometry: &Geometry{X: 40, Y: 60, Width: 1000, Height: 600}})
+	result, err = m.Toggle("term")
+	require.NoError(t, err)
+	assert.Equal(t, ActionHidden, result.Action)
+	assert.Equal(t, []string{"1"}, backend.hidden)
+
+	backend.setWindows(window{ID: "1", AppID: "dms-term", Hidden: true})
+	result, err = m.Toggle("term")
+	require.NoError(t, err)
+	assert.Equal(t, ActionShown, result.Action)
+	assert.Equal(t, &Geometry{X: 40, Y: 60, Width: 1000, Height: 600}, backend.shown[1], "hiding remembers the geometry")
+
+	backend.setWindows(window{ID: "1", AppID: "dms-term"})
+	result, err = m.Toggle("term")
+	require.NoError(t, err)
+	assert.Equal(t, ActionShown, result.Action, "a visible but unfocused pad is focused rather than hidden")
+}
+
+func TestManager_GeometryPersists(t *testing.T) {
+	backend := &fakeCompositor{}
+	m, _ := newTestManager(t, backend)
+
+	backend.setWindows(window{ID: "1", AppID: "dms-term", Focused: true, Geometry: &Geometry{X: 1, Y: 2, Width: 300, Height: 200}})
+	_, err := m.Toggle("term")
+	require.NoError(t, err)
+
+	reloaded := newManager(backend, m.path, m.statePath, nil)
+	reloaded.loadGeometry()
+	assert.Equal(t, Geometry{X: 1, Y: 2, Width: 300, Height: 200}, reloaded.geometry["term"])
+}
+
+func TestManager_ReloadAndState(t *testing.T) {
+	backend := &fakeCompositor{}
+	m, _ := newTestManager(t, backend)
+
+	require.NoError(t, os.WriteFile(m.path, []byte(`
+[[scratchpad]]
+name = "mixer"
+command = "pavucontrol"
+app-id = "org.pulseaudio.pavucontrol"
+`), 0644))
+	require.NoError(t, m.Reload())
+
+	_, err := m.Toggle("term")
+	assert.Error(t, err, "reload drops pads declared on the fly")
+
+	backend.setWindows(window{ID: "9", AppID: "org.pulseaudio.pavucontrol", Hidden: true})
+	state := m.GetState()
+	assert.Equal(t, "fake", state.Backend)
+	require.Len(t, state.Pads, 1)
+	assert.True(t, state.Pads[0].Running)
+	assert.False(t, state.Pads[0].Visible)
+}
+
+func TestManager_Define(t *testing.T) {
+	m, _ := newTestManager(t, &fakeCompositor{})
+	assert.Error(t, m.Define(Pad{Name: "x y", Command: "foot", AppID: "foot"}))
+	assert.Error(t, m.Define(Pad{Name: "x", Command: "foot"}))
+}
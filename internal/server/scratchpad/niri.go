@@ -0,0 +1,221 @@
+package scratchpad
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// niriWorkspaceName is a named workspace users may declare in their niri
+// config to park pads on. Without it pads go to the empty workspace at the
+// bottom of their output.
+const niriWorkspaceName = "scratchpad"
+
+// niriBackend treats a window on a workspace that is not shown on any
+// output as hidden, since niri has no special workspaces
+type niriBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newNiriBackend() *niriBackend {
+	return &niriBackend{run: compositor.Run}
+}
+
+func (n *niriBackend) name() string { return "niri" }
+
+type niriWindow struct {
+	ID          uint64  `json:"id"`
+	AppID       string  `json:"app_id"`
+	WorkspaceID *uint64 `json:"workspace_id"`
+	IsFocused   bool    `json:"is_focused"`
+	IsFloating  bool    `json:"is_floating"`
+	// Layout is only reported by niri 25.05 and newer
+	Layout *struct {
+		WindowSize      [2]float64  `json:"window_size"`
+		TilePosInWindow *[2]float64 `json:"tile_pos_in_workspace_view"`
+	} `json:"layout"`
+}
+
+type niriWorkspace struct {
+	ID        uint64  `json:"id"`
+	Idx       int     `json:"idx"`
+	Name      *string `json:"name"`
+	Output    string  `json:"output"`
+	IsActive  bool    `json:"is_active"`
+	IsFocused bool    `json:"is_focused"`
+}
+
+func (n *niriBackend) query() ([]niriWindow, []niriWorkspace, error) {
+	out, err := n.run("niri", "msg", "--json", "windows")
+	if err != nil {
+		return nil, nil, err
+	}
+	var windows []niriWindow
+	if err := json.Unmarshal(out, &windows); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse niri windows: %w", err)
+	}
+
+	out, err = n.run("niri", "msg", "--json", "workspaces")
+	if err != nil {
+		return nil, nil, err
+	}
+	var workspaces []niriWorkspace
+	if err := json.Unmarshal(out, &workspaces); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse niri workspaces: %w", err)
+	}
+	return windows, workspaces, nil
+}
+
+func (n *niriBackend) windows() ([]window, error) {
+	windows, workspaces, err := n.query()
+	if err != nil {
+		return nil, err
+	}
+	return niriToWindows(windows, workspaces), nil
+}
+
+func niriToWindows(windows []niriWindow, workspaces []niriWorkspace) []window {
+	active := make(map[uint64]bool)
+	for _, ws := range workspaces {
+		active[ws.ID] = ws.IsActive
+	}
+
+	result := make([]window, 0, len(windows))
+	for _, nw := range windows {
+		w := window{
+			ID:      strconv.FormatUint(nw.ID, 10),
+			AppID:   nw.AppID,
+			Hidden:  nw.WorkspaceID == nil || !active[*nw.WorkspaceID],
+			Focused: nw.IsFocused,
+		}
+		if nw.IsFloating && nw.Layout != nil {
+			g := Geometry{
+				Width:  int(math.Round(nw.Layout.WindowSize[0])),
+				Height: int(math.Round(nw.Layout.WindowSize[1])),
+			}
+			if pos := nw.Layout.TilePosInWindow; pos != nil {
+				g.X, g.Y = int(math.Round(pos[0])), int(math.Round(pos[1]))
+			}
+			w.Geometry = &g
+		}
+		result = append(result, w)
+	}
+	return result
+}
+
+func (n *niriBackend) action(args ...string) error {
+	_, err := n.run("niri", append([]string{"msg", "action"}, args...)...)
+	return err
+}
+
+func (n *niriBackend) hide(w window) error {
+	windows, workspaces, err := n.query()
+	if err != nil {
+		return err
+	}
+	target, err := niriParkingWorkspace(w.ID, windows, workspaces)
+	if err != nil {
+		return err
+	}
+	return n.action("move-window-to-workspace", "--window-id", w.ID, "--focus", "false", target)
+}
+
+// niriParkingWorkspace is the scratchpad workspace when declared, else the
+// last workspace of the window's output, which niri always keeps empty
+func niriParkingWorkspace(id string, windows []niriWindow, workspaces []niriWorkspace) (string, error) {
+	for _, ws := range workspaces {
+		if ws.Name != nil && *ws.Name == niriWorkspaceName {
+			return niriWorkspaceName, nil
+		}
+	}
+
+	var output string
+	for _, nw := range windows {
+		if strconv.FormatUint(nw.ID, 10) != id || nw.WorkspaceID == nil {
+			continue
+		}
+		for _, ws := range workspaces {
+			if ws.ID == *nw.WorkspaceID {
+				output = ws.Output
+			}
+		}
+	}
+
+	last := 0
+	for _, ws := range workspaces {
+		if ws.Output == output && ws.Idx > last {
+			last = ws.Idx
+		}
+	}
+	if last == 0 {
+		return "", fmt.Errorf("no workspace to hide window %s on", id)
+	}
+	return strconv.Itoa(last), nil
+}
+
+func (n *niriBackend) show(w window, g *Geometry) error {
+	windows, workspaces, err := n.query()
+	if err != nil {
+		return err
+	}
+
+	var focused *niriWorkspace
+	for i := range workspaces {
+		if workspaces[i].IsFocused {
+			focused = &workspaces[i]
+		}
+	}
+	if focused == nil {
+		return fmt.Errorf("no focused workspace")
+	}
+
+	var errs []string
+	for _, args := range niriShowActions(w.ID, niriWindowOutput(w.ID, windows, workspaces), *focused, g) {
+		if err := n.action(args...); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func niriWindowOutput(id string, windows []niriWindow, workspaces []niriWorkspace) string {
+	for _, nw := range windows {
+		if strconv.FormatUint(nw.ID, 10) != id || nw.WorkspaceID == nil {
+			continue
+		}
+		for _, ws := range workspaces {
+			if ws.ID == *nw.WorkspaceID {
+				return ws.Output
+			}
+		}
+	}
+	return ""
+}
+
+func niriShowActions(id, output string, focused niriWorkspace, g *Geometry) [][]string {
+	var actions [][]string
+	if output != "" && output != focused.Output {
+		actions = append(actions, []string{"move-window-to-monitor", "--id", id, focused.Output})
+	}
+	actions = append(actions,
+		[]string{"move-window-to-workspace", "--window-id", id, "--focus", "false", strconv.Itoa(focused.Idx)},
+		[]string{"move-window-to-floating", "--id", id},
+	)
+	if g != nil {
+		actions = append(actions,
+			[]string{"set-window-width", "--id", id, strconv.Itoa(g.Width)},
+			[]string{"set-window-height", "--id", id, strconv.Itoa(g.Height)},
+		)
+		if g.X != 0 || g.Y != 0 {
+			actions = append(actions, []string{"move-floating-window", "--id", id, "-x", strconv.Itoa(g.X), "-y", strconv.Itoa(g.Y)})
+		}
+	}
+	return append(actions, []string{"focus-window", "--id", id})
+}
@@ -0,0 +1,99 @@
+package scratchpad
+
+import (
+	"sync"
+	"time"
+)
+
+// Pad is a named drop-down window: the command that starts it and the app
+// ID its window is recognised by
+type Pad struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	AppID   string `json:"appId"`
+	// Width and Height size the window the first time it is shown, zero
+	// leaves it to the compositor. Later toggles restore the remembered
+	// geometry instead.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	Line   int `json:"line,omitempty"`
+}
+
+// Geometry is a floating window's position and size in logical pixels
+type Geometry struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type PadState struct {
+	Pad
+	Running  bool      `json:"running"`
+	Visible  bool      `json:"visible"`
+	Geometry *Geometry `json:"geometry,omitempty"`
+}
+
+type State struct {
+	Path    string     `json:"path"`
+	Backend string     `json:"backend"`
+	Pads    []PadState `json:"pads"`
+	Errors  []string   `json:"errors,omitempty"`
+}
+
+type ToggleAction string
+
+const (
+	ActionSpawned  ToggleAction = "spawned"
+	ActionStarting ToggleAction = "starting"
+	ActionShown    ToggleAction = "shown"
+	ActionHidden   ToggleAction = "hidden"
+)
+
+type ToggleResult struct {
+	Name   string       `json:"name"`
+	Action ToggleAction `json:"action"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// window is a toplevel as the compositor reports it. Hidden windows are
+// parked where the user cannot see them.
+type window struct {
+	ID       string
+	AppID    string
+	Hidden   bool
+	Focused  bool
+	Geometry *Geometry
+}
+
+// windowBackend hides and shows windows. show moves the window to the focused
+// workspace, floats and focuses it, applying the geometry when given.
+type windowBackend interface {
+	name() string
+	windows() ([]window, error)
+	hide(w window) error
+	show(w window, g *Geometry) error
+}
+
+type Manager struct {
+	backend   windowBackend
+	path      string
+	statePath string
+	spawn     func(command string) error
+	// mapTimeout is how long a spawned pad may take to open its window
+	mapTimeout time.Duration
+
+	mu       sync.Mutex
+	pads     map[string]Pad
+	order    []string
+	errors   []string
+	geometry map[string]Geometry
+	pending  map[string]time.Time
+
+	stopChan chan struct{}
+}
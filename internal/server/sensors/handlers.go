@@ -0,0 +1,85 @@
+package sensors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "sensors.list":
+		handleGetState(conn, req, manager)
+	case "sensors.setThreshold":
+		handleSetThreshold(conn, req, manager)
+	case "sensors.setDefaultThreshold":
+		handleSetDefaultThreshold(conn, req, manager)
+	case "sensors.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSetThreshold(conn net.Conn, req Request, manager *Manager) {
+	sensorID, ok := req.Params["sensor"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'sensor' parameter")
+		return
+	}
+
+	value, ok := req.Params["value"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'value' parameter")
+		return
+	}
+
+	if err := manager.SetThreshold(sensorID, value); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "threshold set"})
+}
+
+func handleSetDefaultThreshold(conn net.Conn, req Request, manager *Manager) {
+	value, ok := req.Params["value"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'value' parameter")
+		return
+	}
+
+	if err := manager.SetDefaultWarning(value); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "default threshold set"})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
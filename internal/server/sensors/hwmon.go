@@ -0,0 +1,119 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func NewHwmonBackend() (*HwmonBackend, error) {
+	return newHwmonBackend("/sys/class/hwmon")
+}
+
+func newHwmonBackend(basePath string) (*HwmonBackend, error) {
+	if _, err := os.Stat(basePath); err != nil {
+		return nil, fmt.Errorf("hwmon not available: %w", err)
+	}
+	return &HwmonBackend{basePath: basePath}, nil
+}
+
+// ReadSensors reads all temperature and fan inputs exposed by hwmon chips.
+// Temperatures are reported in °C and fans in RPM.
+func (b *HwmonBackend) ReadSensors() ([]Sensor, error) {
+	entries, err := os.ReadDir(b.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", b.basePath, err)
+	}
+
+	var sensors []Sensor
+	for _, entry := range entries {
+		chipPath := filepath.Join(b.basePath, entry.Name())
+		chip := readString(filepath.Join(chipPath, "name"))
+		if chip == "" {
+			chip = entry.Name()
+		}
+
+		files, err := os.ReadDir(chipPath)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range files {
+			name := f.Name()
+			if !strings.HasSuffix(name, "_input") {
+				continue
+			}
+			prefix := strings.TrimSuffix(name, "_input")
+
+			var kind SensorKind
+			var unit string
+			var scale float64
+			switch {
+			case strings.HasPrefix(prefix, "temp"):
+				kind, unit, scale = KindTemperature, "°C", 1000
+			case strings.HasPrefix(prefix, "fan"):
+				kind, unit, scale = KindFan, "RPM", 1
+			default:
+				continue
+			}
+
+			raw, ok := readInt(filepath.Join(chipPath, name))
+			if !ok {
+				continue
+			}
+
+			label := readString(filepath.Join(chipPath, prefix+"_label"))
+			if label == "" {
+				label = prefix
+			}
+
+			s := Sensor{
+				ID:    fmt.Sprintf("%s:%s:%s", entry.Name(), chip, prefix),
+				Chip:  chip,
+				Label: label,
+				Kind:  kind,
+				Value: float64(raw) / scale,
+				Unit:  unit,
+			}
+
+			if kind == KindTemperature {
+				if crit, ok := readInt(filepath.Join(chipPath, prefix+"_crit")); ok && crit > 0 {
+					s.Critical = float64(crit) / scale
+				}
+				if max, ok := readInt(filepath.Join(chipPath, prefix+"_max")); ok && max > 0 {
+					s.Warning = float64(max) / scale
+				}
+			}
+
+			sensors = append(sensors, s)
+		}
+	}
+
+	sort.Slice(sensors, func(i, j int) bool {
+		if sensors[i].Kind != sensors[j].Kind {
+			return sensors[i].Kind > sensors[j].Kind
+		}
+		return sensors[i].ID < sensors[j].ID
+	})
+
+	return sensors, nil
+}
+
+func readString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readInt(path string) (int, bool) {
+	v, err := strconv.Atoi(readString(path))
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
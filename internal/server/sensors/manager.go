@@ -0,0 +1,229 @@
+package sensors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	defaultPollInterval   = 3 * time.Second
+	defaultWarningCelsius = 90.0
+	defaultHysteresis     = 3.0
+)
+
+func NewManager() (*Manager, error) {
+	backend, err := NewHwmonBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	m := newManager(backend, defaultPollInterval)
+	m.poll()
+
+	go m.pollLoop()
+
+	return m, nil
+}
+
+func newManager(backend *HwmonBackend, interval time.Duration) *Manager {
+	return &Manager{
+		backend:          backend,
+		pollInterval:     interval,
+		thresholds:       make(map[string]float64),
+		defaultWarning:   defaultWarningCelsius,
+		alerting:         make(map[string]bool),
+		hysteresisMargin: defaultHysteresis,
+		subscribers:      make(map[string]chan State),
+		alertSubscribers: make(map[string]chan Alert),
+		intervalChan:     make(chan time.Duration, 1),
+		wakeChan:         make(chan struct{}, 1),
+		stopChan:         make(chan struct{}),
+	}
+}
+
+// pollLoop is the only place sensors are polled once the manager runs, so
+// threshold crossings are tracked in order. Other callers wake it instead.
+func (m *Manager) pollLoop() {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case interval := <-m.intervalChan:
+			ticker.Reset(interval)
+		case <-m.wakeChan:
+			m.poll()
+		case <-ticker.C:
+			m.poll()
+		}
+	}
+}
+
+func (m *Manager) wake() {
+	select {
+	case m.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// SetPollInterval changes how often sensors are read, taking effect on the
+// next tick
+func (m *Manager) SetPollInterval(interval time.Duration) error {
+	if interval < time.Second {
+		return fmt.Errorf("poll interval too short: %s", interval)
+	}
+	select {
+	case <-m.intervalChan:
+	default:
+	}
+	select {
+	case m.intervalChan <- interval:
+	default:
+	}
+	return nil
+}
+
+func (m *Manager) poll() {
+	sensors, err := m.backend.ReadSensors()
+	if err != nil {
+		log.Debugf("Failed to read hwmon sensors: %v", err)
+		return
+	}
+
+	alerts := m.applyThresholds(sensors)
+
+	m.stateMutex.Lock()
+	changed := stateChanged(m.state, sensors)
+	m.state = State{Sensors: sensors}
+	m.stateMutex.Unlock()
+
+	for _, alert := range alerts {
+		if alert.Exceeded {
+			log.Warnf("Sensor %s (%s) at %.1f%s exceeded threshold %.1f", alert.Sensor.Label, alert.Sensor.Chip, alert.Sensor.Value, alert.Sensor.Unit, alert.Threshold)
+		}
+		m.notifyAlert(alert)
+	}
+
+	if changed {
+		m.NotifySubscribers()
+	}
+}
+
+// applyThresholds fills in the effective warning level for each temperature
+// sensor and returns alerts for sensors that crossed it since the last poll.
+// A sensor must cool below threshold-hysteresis before it clears.
+func (m *Manager) applyThresholds(sensors []Sensor) []Alert {
+	m.thresholdMutex.Lock()
+	defer m.thresholdMutex.Unlock()
+
+	var alerts []Alert
+	for i := range sensors {
+		s := &sensors[i]
+		if s.Kind != KindTemperature {
+			continue
+		}
+
+		threshold, ok := m.thresholds[s.ID]
+		switch {
+		case ok:
+		case s.Warning > 0:
+			threshold = s.Warning
+		default:
+			threshold = m.defaultWarning
+		}
+		s.Warning = threshold
+
+		wasAlerting := m.alerting[s.ID]
+		switch {
+		case !wasAlerting && s.Value >= threshold:
+			m.alerting[s.ID] = true
+		case wasAlerting && s.Value < threshold-m.hysteresisMargin:
+			m.alerting[s.ID] = false
+		}
+
+		s.Alerting = m.alerting[s.ID]
+		if s.Alerting != wasAlerting {
+			alerts = append(alerts, Alert{Sensor: *s, Threshold: threshold, Exceeded: s.Alerting})
+		}
+	}
+
+	return alerts
+}
+
+// stateChanged ignores sub-degree temperature jitter so subscribers are not
+// flooded on every poll.
+func stateChanged(old State, sensors []Sensor) bool {
+	if len(old.Sensors) != len(sensors) {
+		return true
+	}
+
+	for i, s := range sensors {
+		o := old.Sensors[i]
+		if o.ID != s.ID || o.Alerting != s.Alerting || o.Warning != s.Warning {
+			return true
+		}
+
+		delta := o.Value - s.Value
+		if delta < 0 {
+			delta = -delta
+		}
+
+		minDelta := 1.0
+		if s.Kind == KindFan {
+			minDelta = 50
+		}
+		if delta >= minDelta {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetThreshold overrides the warning level for a sensor. A value <= 0 restores
+// the hwmon-provided or default threshold.
+func (m *Manager) SetThreshold(sensorID string, value float64) error {
+	found := false
+	for _, s := range m.GetState().Sensors {
+		if s.ID == sensorID {
+			if s.Kind != KindTemperature {
+				return fmt.Errorf("thresholds are only supported for temperature sensors")
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("sensor not found: %s", sensorID)
+	}
+
+	m.thresholdMutex.Lock()
+	if value <= 0 {
+		delete(m.thresholds, sensorID)
+	} else {
+		m.thresholds[sensorID] = value
+	}
+	m.thresholdMutex.Unlock()
+
+	m.wake()
+	return nil
+}
+
+// SetDefaultWarning sets the fallback threshold for sensors without a
+// hwmon-provided max or explicit override.
+func (m *Manager) SetDefaultWarning(value float64) error {
+	if value <= 0 {
+		return fmt.Errorf("invalid threshold: %.1f", value)
+	}
+
+	m.thresholdMutex.Lock()
+	m.defaultWarning = value
+	m.thresholdMutex.Unlock()
+
+	m.wake()
+	return nil
+}
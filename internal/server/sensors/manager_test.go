@@ -0,0 +1,148 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHwmonFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0644))
+}
+
+func setupHwmon(t *testing.T) string {
+	base := t.TempDir()
+
+	cpu := filepath.Join(base, "hwmon0")
+	writeHwmonFile(t, cpu, "name", "k10temp")
+	writeHwmonFile(t, cpu, "temp1_input", "45250")
+	writeHwmonFile(t, cpu, "temp1_label", "Tctl")
+	writeHwmonFile(t, cpu, "temp1_crit", "100000")
+
+	gpu := filepath.Join(base, "hwmon1")
+	writeHwmonFile(t, gpu, "name", "amdgpu")
+	writeHwmonFile(t, gpu, "temp1_input", "60000")
+	writeHwmonFile(t, gpu, "temp1_max", "80000")
+	writeHwmonFile(t, gpu, "fan1_input", "1200")
+	writeHwmonFile(t, gpu, "pwm1", "128")
+
+	return base
+}
+
+func TestHwmonBackend_ReadSensors(t *testing.T) {
+	base := setupHwmon(t)
+	b, err := newHwmonBackend(base)
+	require.NoError(t, err)
+
+	sensors, err := b.ReadSensors()
+	require.NoError(t, err)
+	require.Len(t, sensors, 3)
+
+	byID := make(map[string]Sensor)
+	for _, s := range sensors {
+		byID[s.ID] = s
+	}
+
+	cpu := byID["hwmon0:k10temp:temp1"]
+	assert.Equal(t, "Tctl", cpu.Label)
+	assert.Equal(t, KindTemperature, cpu.Kind)
+	assert.InDelta(t, 45.25, cpu.Value, 0.001)
+	assert.InDelta(t, 100.0, cpu.Critical, 0.001)
+
+	gpu := byID["hwmon1:amdgpu:temp1"]
+	assert.Equal(t, "temp1", gpu.Label)
+	assert.InDelta(t, 80.0, gpu.Warning, 0.001)
+
+	fan := byID["hwmon1:amdgpu:fan1"]
+	assert.Equal(t, KindFan, fan.Kind)
+	assert.Equal(t, "RPM", fan.Unit)
+	assert.InDelta(t, 1200.0, fan.Value, 0.001)
+
+	assert.Equal(t, KindFan, sensors[len(sensors)-1].Kind)
+}
+
+func TestNewHwmonBackend_Missing(t *testing.T) {
+	_, err := newHwmonBackend(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestManager_Thresholds(t *testing.T) {
+	base := setupHwmon(t)
+	b, err := newHwmonBackend(base)
+	require.NoError(t, err)
+
+	m := newManager(b, time.Hour)
+	alerts := m.SubscribeAlerts("test")
+	m.poll()
+
+	state := m.GetState()
+	require.Len(t, state.Sensors, 3)
+	for _, s := range state.Sensors {
+		assert.False(t, s.Alerting, s.ID)
+	}
+
+	t.Run("crossing hwmon max emits alert", func(t *testing.T) {
+		writeHwmonFile(t, filepath.Join(base, "hwmon1"), "temp1_input", "82000")
+		m.poll()
+
+		select {
+		case a := <-alerts:
+			assert.True(t, a.Exceeded)
+			assert.Equal(t, "hwmon1:amdgpu:temp1", a.Sensor.ID)
+			assert.InDelta(t, 80.0, a.Threshold, 0.001)
+		default:
+			t.Fatal("expected alert")
+		}
+	})
+
+	t.Run("no repeat alert and hysteresis holds", func(t *testing.T) {
+		writeHwmonFile(t, filepath.Join(base, "hwmon1"), "temp1_input", "78000")
+		m.poll()
+		assert.Empty(t, alerts)
+	})
+
+	t.Run("cooling below hysteresis clears", func(t *testing.T) {
+		writeHwmonFile(t, filepath.Join(base, "hwmon1"), "temp1_input", "70000")
+		m.poll()
+
+		select {
+		case a := <-alerts:
+			assert.False(t, a.Exceeded)
+		default:
+			t.Fatal("expected clear alert")
+		}
+	})
+
+	t.Run("override threshold", func(t *testing.T) {
+		go m.pollLoop()
+		defer m.Close()
+		require.NoError(t, m.SetThreshold("hwmon0:k10temp:temp1", 40))
+
+		select {
+		case a := <-alerts:
+			assert.True(t, a.Exceeded)
+			assert.Equal(t, "hwmon0:k10temp:temp1", a.Sensor.ID)
+		case <-time.After(time.Second):
+			t.Fatal("expected alert")
+		}
+	})
+
+	t.Run("thresholds rejected for fans and unknown sensors", func(t *testing.T) {
+		assert.Error(t, m.SetThreshold("hwmon1:amdgpu:fan1", 10))
+		assert.Error(t, m.SetThreshold("nope", 10))
+	})
+}
+
+func TestStateChanged(t *testing.T) {
+	old := State{Sensors: []Sensor{{ID: "a", Kind: KindTemperature, Value: 50}}}
+
+	assert.False(t, stateChanged(old, []Sensor{{ID: "a", Kind: KindTemperature, Value: 50.4}}))
+	assert.True(t, stateChanged(old, []Sensor{{ID: "a", Kind: KindTemperature, Value: 51.5}}))
+	assert.True(t, stateChanged(old, nil))
+}
@@ -0,0 +1,158 @@
+package sensors
+
+import (
+	"sync"
+	"time"
+)
+
+type SensorKind string
+
+const (
+	KindTemperature SensorKind = "temperature"
+	KindFan         SensorKind = "fan"
+)
+
+type Sensor struct {
+	ID       string     `json:"id"`
+	Chip     string     `json:"chip"`
+	Label    string     `json:"label"`
+	Kind     SensorKind `json:"kind"`
+	Value    float64    `json:"value"`
+	Unit     string     `json:"unit"`
+	Critical float64    `json:"critical,omitempty"`
+	Warning  float64    `json:"warning,omitempty"`
+	Alerting bool       `json:"alerting"`
+}
+
+type State struct {
+	Sensors []Sensor `json:"sensors"`
+}
+
+// Alert is emitted once when a sensor crosses its warning threshold and once
+// when it drops back below it.
+type Alert struct {
+	Sensor    Sensor  `json:"sensor"`
+	Threshold float64 `json:"threshold"`
+	Exceeded  bool    `json:"exceeded"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+type HwmonBackend struct {
+	basePath string
+}
+
+type Manager struct {
+	backend *HwmonBackend
+
+	pollInterval time.Duration
+
+	thresholdMutex   sync.RWMutex
+	thresholds       map[string]float64
+	defaultWarning   float64
+	alerting         map[string]bool
+	hysteresisMargin float64
+
+	stateMutex sync.RWMutex
+	state      State
+
+	subscribers      map[string]chan State
+	alertSubscribers map[string]chan Alert
+	subMutex         sync.RWMutex
+
+	intervalChan chan time.Duration
+	wakeChan     chan struct{}
+	stopChan     chan struct{}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) SubscribeAlerts(id string) chan Alert {
+	ch := make(chan Alert, 16)
+	m.subMutex.Lock()
+	m.alertSubscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) UnsubscribeAlerts(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.alertSubscribers[id]; ok {
+		close(ch)
+		delete(m.alertSubscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) NotifySubscribers() {
+	m.stateMutex.RLock()
+	state := m.state
+	m.stateMutex.RUnlock()
+
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) notifyAlert(alert Alert) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.alertSubscribers {
+		select {
+		case ch <- alert:
+		default:
+		}
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	for _, ch := range m.alertSubscribers {
+		close(ch)
+	}
+	m.alertSubscribers = make(map[string]chan Alert)
+	m.subMutex.Unlock()
+}
@@ -3,38 +3,64 @@ package server
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
-	"strconv"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/crash"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/outputs"
+	"github.com/AvengeMedia/danklinux/internal/server/a11y"
+	"github.com/AvengeMedia/danklinux/internal/server/appcolor"
+	"github.com/AvengeMedia/danklinux/internal/server/audio"
 	"github.com/AvengeMedia/danklinux/internal/server/bluez"
 	"github.com/AvengeMedia/danklinux/internal/server/brightness"
+	"github.com/AvengeMedia/danklinux/internal/server/calendar"
 	"github.com/AvengeMedia/danklinux/internal/server/cups"
 	"github.com/AvengeMedia/danklinux/internal/server/dwl"
 	"github.com/AvengeMedia/danklinux/internal/server/freedesktop"
+	"github.com/AvengeMedia/danklinux/internal/server/jobs"
+	"github.com/AvengeMedia/danklinux/internal/server/launcher"
 	"github.com/AvengeMedia/danklinux/internal/server/loginctl"
 	"github.com/AvengeMedia/danklinux/internal/server/models"
 	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/AvengeMedia/danklinux/internal/server/notifications"
+	"github.com/AvengeMedia/danklinux/internal/server/power"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
+	"github.com/AvengeMedia/danklinux/internal/server/remap"
+	"github.com/AvengeMedia/danklinux/internal/server/rules"
+	"github.com/AvengeMedia/danklinux/internal/server/scratchpad"
+	"github.com/AvengeMedia/danklinux/internal/server/sensors"
+	"github.com/AvengeMedia/danklinux/internal/server/sounds"
+	"github.com/AvengeMedia/danklinux/internal/server/termcolors"
+	"github.com/AvengeMedia/danklinux/internal/server/thermal"
+	"github.com/AvengeMedia/danklinux/internal/server/timers"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
 	"github.com/AvengeMedia/danklinux/internal/server/wayland"
 	"github.com/AvengeMedia/danklinux/internal/server/wlcontext"
+	"github.com/AvengeMedia/danklinux/internal/session"
+	"github.com/AvengeMedia/danklinux/internal/session/socket"
 )
 
-const APIVersion = 15
+const APIVersion = 17
 
 type Capabilities struct {
 	Capabilities []string `json:"capabilities"`
 }
 
 type ServerInfo struct {
-	APIVersion   int      `json:"apiVersion"`
-	Capabilities []string `json:"capabilities"`
+	APIVersion   int                  `json:"apiVersion"`
+	Capabilities []string             `json:"capabilities"`
+	CrashCount   int                  `json:"crashCount"`
+	Session      string               `json:"session,omitempty"`
+	Seat         string               `json:"seat,omitempty"`
+	Deprecations []models.Deprecation `json:"deprecations"`
 }
 
 type ServiceEvent struct {
@@ -42,14 +68,32 @@ type ServiceEvent struct {
 	Data    interface{} `json:"data"`
 }
 
-var networkManager *network.Manager
-var loginctlManager *loginctl.Manager
-var freedesktopManager *freedesktop.Manager
+var networkManager atomic.Pointer[network.Manager]
+var loginctlManager atomic.Pointer[loginctl.Manager]
+var freedesktopManager atomic.Pointer[freedesktop.Manager]
 var waylandManager *wayland.Manager
-var bluezManager *bluez.Manager
-var cupsManager *cups.Manager
+var bluezManager atomic.Pointer[bluez.Manager]
+var cupsManager atomic.Pointer[cups.Manager]
 var dwlManager *dwl.Manager
-var brightnessManager *brightness.Manager
+var brightnessManager atomic.Pointer[brightness.Manager]
+var sensorsManager *sensors.Manager
+var notificationsManager *notifications.Manager
+var promptsManager *prompts.Manager
+var launcherManager *launcher.Manager
+var powerManager atomic.Pointer[power.Manager]
+var rulesManager *rules.Manager
+var timersManager *timers.Manager
+var calendarManager *calendar.Manager
+var scratchpadManager *scratchpad.Manager
+var termcolorsManager *termcolors.Manager
+var thermalManager *thermal.Manager
+var remapManager *remap.Manager
+var a11yManager *a11y.Manager
+var audioManager *audio.Manager
+var wallpaperManager *wallpaper.Manager
+var soundsManager *sounds.Manager
+var jobsManager *jobs.Manager
+var appcolorManager *appcolor.Manager
 var wlContext *wlcontext.SharedContext
 
 var capabilitySubscribers = make(map[string]chan ServerInfo)
@@ -59,6 +103,10 @@ var cupsSubscribers = make(map[string]bool)
 var cupsSubscribersMutex sync.Mutex
 
 func getSocketDir() string {
+	if dir := getDaemonConfig().String("server.socket-dir"); dir != "" {
+		return dir
+	}
+
 	if runtime := os.Getenv("XDG_RUNTIME_DIR"); runtime != "" {
 		return runtime
 	}
@@ -70,11 +118,13 @@ func getSocketDir() string {
 		return "/var/run/dankdots"
 	}
 
-	return os.TempDir()
+	return socket.FallbackDir()
 }
 
+// GetSocketPath names the socket after the logind session as well as the
+// pid, so clients of one session never pick up another session's server
 func GetSocketPath() string {
-	return filepath.Join(getSocketDir(), fmt.Sprintf("danklinux-%d.sock", os.Getpid()))
+	return filepath.Join(getSocketDir(), socket.Name(session.Current().ID, os.Getpid()))
 }
 
 func cleanupStaleSockets() {
@@ -85,27 +135,13 @@ func cleanupStaleSockets() {
 	}
 
 	for _, entry := range entries {
-		if !strings.HasPrefix(entry.Name(), "danklinux-") || !strings.HasSuffix(entry.Name(), ".sock") {
-			continue
-		}
-
-		pidStr := strings.TrimPrefix(entry.Name(), "danklinux-")
-		pidStr = strings.TrimSuffix(pidStr, ".sock")
-		pid, err := strconv.Atoi(pidStr)
-		if err != nil {
-			continue
-		}
-
-		process, err := os.FindProcess(pid)
-		if err != nil {
-			socketPath := filepath.Join(dir, entry.Name())
-			os.Remove(socketPath)
-			log.Debugf("Removed stale socket: %s", socketPath)
+		_, pid, ok := socket.Parse(entry.Name())
+		if !ok {
 			continue
 		}
 
-		err = process.Signal(syscall.Signal(0))
-		if err != nil {
+		// EPERM means the pid belongs to another user's live process
+		if syscall.Kill(pid, 0) == syscall.ESRCH {
 			socketPath := filepath.Join(dir, entry.Name())
 			os.Remove(socketPath)
 			log.Debugf("Removed stale socket: %s", socketPath)
@@ -114,45 +150,66 @@ func cleanupStaleSockets() {
 }
 
 func InitializeNetworkManager() error {
+	if err := checkModuleEnabled("network"); err != nil {
+		return err
+	}
+
 	manager, err := network.NewManager()
 	if err != nil {
 		log.Warnf("Failed to initialize network manager: %v", err)
 		return err
 	}
 
-	networkManager = manager
+	manager.SetPANProvider(bluezPAN{})
+	if promptsManager != nil {
+		manager.SetPromptRelay(promptsManager)
+	}
+	networkManager.Store(manager)
+	applyNetworkConfig(getDaemonConfig())
 
 	log.Info("Network manager initialized")
 	return nil
 }
 
 func InitializeLoginctlManager() error {
+	if err := checkModuleEnabled("loginctl"); err != nil {
+		return err
+	}
+
 	manager, err := loginctl.NewManager()
 	if err != nil {
 		log.Warnf("Failed to initialize loginctl manager: %v", err)
 		return err
 	}
 
-	loginctlManager = manager
+	loginctlManager.Store(manager)
 
 	log.Info("Loginctl manager initialized")
 	return nil
 }
 
 func InitializeFreedeskManager() error {
+	if err := checkModuleEnabled("freedesktop"); err != nil {
+		return err
+	}
+
 	manager, err := freedesktop.NewManager()
 	if err != nil {
 		log.Warnf("Failed to initialize freedesktop manager: %v", err)
 		return err
 	}
 
-	freedesktopManager = manager
+	freedesktopManager.Store(manager)
 
 	log.Info("Freedesktop manager initialized")
 	return nil
 }
 
 func InitializeWaylandManager() error {
+	if err := checkModuleEnabled("wayland"); err != nil {
+		return err
+	}
+
 	log.Info("Attempting to initialize Wayland gamma control...")
 
 	if wlContext == nil {
@@ -164,7 +221,7 @@ func InitializeWaylandManager() error {
 		wlContext = ctx
 	}
 
-	config := wayland.DefaultConfig()
+	config := nightLightConfig(getDaemonConfig(), wayland.DefaultConfig())
 	manager, err := wayland.NewManager(wlContext.Display(), config)
 	if err != nil {
 		log.Errorf("Failed to initialize wayland manager: %v", err)
@@ -178,32 +235,48 @@ func InitializeWaylandManager() error {
 }
 
 func InitializeBluezManager() error {
+	if err := checkModuleEnabled("bluetooth"); err != nil {
+		return err
+	}
+
 	manager, err := bluez.NewManager()
 	if err != nil {
 		log.Warnf("Failed to initialize bluez manager: %v", err)
 		return err
 	}
+	if promptsManager != nil {
+		manager.SetPromptRelay(promptsManager)
+	}
 
-	bluezManager = manager
+	bluezManager.Store(manager)
 
 	log.Info("Bluez manager initialized")
 	return nil
 }
 
 func InitializeCupsManager() error {
+	if err := checkModuleEnabled("cups"); err != nil {
+		return err
+	}
+
 	manager, err := cups.NewManager()
 	if err != nil {
 		log.Warnf("Failed to initialize cups manager: %v", err)
 		return err
 	}
 
-	cupsManager = manager
+	cupsManager.Store(manager)
+	manager.SetPolkitChecks(getDaemonConfig().Bool("cups.polkit"))
 
 	log.Info("CUPS manager initialized")
 	return nil
 }
 
 func InitializeDwlManager() error {
+	if err := checkModuleEnabled("dwl"); err != nil {
+		return err
+	}
+
 	log.Info("Attempting to initialize DWL IPC...")
 
 	if wlContext == nil {
@@ -228,226 +301,1198 @@ func InitializeDwlManager() error {
 }
 
 func InitializeBrightnessManager() error {
+	if err := checkModuleEnabled("brightness"); err != nil {
+		return err
+	}
+
 	manager, err := brightness.NewManager()
 	if err != nil {
 		log.Warnf("Failed to initialize brightness manager: %v", err)
 		return err
 	}
 
-	brightnessManager = manager
+	brightnessManager.Store(manager)
+	if cfg := getDaemonConfig(); cfg.IsSet("brightness.ddc-scan-interval") || cfg.IsSet("brightness.key-steps") || cfg.IsSet("brightness.slider-steps") {
+		applyBrightnessConfig(cfg)
+	}
 
 	log.Info("Brightness manager initialized")
 	return nil
 }
 
-func handleConnection(conn net.Conn) {
-	defer conn.Close()
+func InitializeSensorsManager() error {
+	if err := checkModuleEnabled("sensors"); err != nil {
+		return err
+	}
 
-	caps := getCapabilities()
-	capsData, _ := json.Marshal(caps)
-	conn.Write(capsData)
-	conn.Write([]byte("\n"))
+	manager, err := sensors.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize sensors manager: %v", err)
+		return err
+	}
 
-	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
-		line := scanner.Bytes()
+	sensorsManager = manager
+	if cfg := getDaemonConfig(); cfg.IsSet("sensors.poll-interval") {
+		applySensorsConfig(cfg)
+	}
 
-		var req models.Request
-		if err := json.Unmarshal(line, &req); err != nil {
-			log.Warnf("handleConnection: Failed to unmarshal JSON: %v, line: %s", err, string(line))
-			models.RespondError(conn, 0, "invalid json")
-			continue
-		}
+	log.Info("Sensors manager initialized")
+	return nil
+}
+
+func InitializeNotificationsManager() error {
+	if err := checkModuleEnabled("notifications"); err != nil {
+		return err
+	}
 
-		go RouteRequest(conn, req)
+	manager, err := notifications.NewManager(notificationsConfig(getDaemonConfig()), notificationsStore{})
+	if err != nil {
+		log.Warnf("Failed to initialize notifications manager: %v", err)
+		return err
 	}
+
+	notificationsManager = manager
+
+	log.Info("Notifications manager initialized")
+	return nil
 }
 
-func getCapabilities() Capabilities {
-	caps := []string{"plugins"}
+func InitializePromptsManager() error {
+	if err := checkModuleEnabled("prompts"); err != nil {
+		return err
+	}
 
-	if networkManager != nil {
-		caps = append(caps, "network")
+	manager, err := prompts.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize prompts manager: %v", err)
+		return err
 	}
 
-	if loginctlManager != nil {
-		caps = append(caps, "loginctl")
+	promptsManager = manager
+
+	log.Info("Prompts manager initialized")
+	return nil
+}
+
+func InitializeLauncherManager() error {
+	if err := checkModuleEnabled("launcher"); err != nil {
+		return err
 	}
 
-	if freedesktopManager != nil {
-		caps = append(caps, "freedesktop")
+	manager, err := launcher.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize launcher manager: %v", err)
+		return err
 	}
 
-	if waylandManager != nil {
-		caps = append(caps, "gamma")
+	launcherManager = manager
+
+	log.Info("Launcher manager initialized")
+	return nil
+}
+
+func InitializePowerManager() error {
+	if err := checkModuleEnabled("power"); err != nil {
+		return err
 	}
 
-	if bluezManager != nil {
-		caps = append(caps, "bluetooth")
+	manager, err := power.NewManager(powerPolicyConfig(getDaemonConfig()))
+	if err != nil {
+		log.Warnf("Failed to initialize power manager: %v", err)
+		return err
 	}
 
-	if cupsManager != nil {
-		caps = append(caps, "cups")
+	manager.SetHooks(&backlightLimiter{saved: make(map[string]int)}, wayland.NewRefreshRateController(), powerPolicyStore{})
+	powerManager.Store(manager)
+
+	log.Info("Power manager initialized")
+	return nil
+}
+
+// backlightLimiter caps backlight devices through the brightness manager for
+// the power policy and remembers what they were at so AC can restore it.
+// External monitors are left alone since dimming them saves nothing on
+// battery.
+type backlightLimiter struct {
+	mu    sync.Mutex
+	saved map[string]int
+}
+
+func (l *backlightLimiter) CapBrightness(percent int) error {
+	brm := brightnessManager.Load()
+	if brm == nil {
+		return fmt.Errorf("brightness manager not initialized")
 	}
 
-	if dwlManager != nil {
-		caps = append(caps, "dwl")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, device := range brm.GetState().Devices {
+		if device.Class != brightness.ClassBacklight || device.CurrentPercent <= percent {
+			continue
+		}
+		if err := brm.SetBrightness(device.ID, percent); err != nil {
+			return err
+		}
+		if _, ok := l.saved[device.ID]; !ok {
+			l.saved[device.ID] = device.CurrentPercent
+		}
 	}
+	return nil
+}
 
-	if brightnessManager != nil {
-		caps = append(caps, "brightness")
+// RestoreBrightness puts capped devices back where they were before the
+// cap, and forgets them even when that fails so a stale level is never
+// restored on a later plug event
+func (l *backlightLimiter) RestoreBrightness() error {
+	l.mu.Lock()
+	saved := l.saved
+	l.saved = make(map[string]int)
+	l.mu.Unlock()
+
+	if len(saved) == 0 {
+		return nil
+	}
+	brm := brightnessManager.Load()
+	if brm == nil {
+		return fmt.Errorf("brightness manager not initialized")
 	}
 
-	return Capabilities{Capabilities: caps}
+	var errs []error
+	for id, percent := range saved {
+		if err := brm.SetBrightness(id, percent); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func getServerInfo() ServerInfo {
-	caps := []string{"plugins"}
+func InitializeRulesManager() error {
+	if err := checkModuleEnabled("rules"); err != nil {
+		return err
+	}
 
-	if networkManager != nil {
-		caps = append(caps, "network")
+	manager, err := rules.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize rules manager: %v", err)
+		return err
 	}
 
-	if loginctlManager != nil {
-		caps = append(caps, "loginctl")
+	rulesManager = manager
+
+	log.Info("Rules manager initialized")
+	return nil
+}
+
+func InitializeTimersManager() error {
+	if err := checkModuleEnabled("timers"); err != nil {
+		return err
 	}
 
-	if freedesktopManager != nil {
-		caps = append(caps, "freedesktop")
+	manager, err := timers.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize timers manager: %v", err)
+		return err
 	}
 
-	if waylandManager != nil {
-		caps = append(caps, "gamma")
+	timersManager = manager
+
+	log.Info("Timers manager initialized")
+	return nil
+}
+
+func InitializeCalendarManager() error {
+	if err := checkModuleEnabled("calendar"); err != nil {
+		return err
 	}
 
-	if bluezManager != nil {
-		caps = append(caps, "bluetooth")
+	manager, err := calendar.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize calendar manager: %v", err)
+		return err
 	}
 
-	if cupsManager != nil {
-		caps = append(caps, "cups")
+	calendarManager = manager
+	if cfg := getDaemonConfig(); cfg.IsSet("calendar.refresh-interval") {
+		applyCalendarConfig(cfg)
 	}
 
-	if dwlManager != nil {
-		caps = append(caps, "dwl")
+	log.Info("Calendar manager initialized")
+	return nil
+}
+
+func InitializeScratchpadManager() error {
+	if err := checkModuleEnabled("scratchpad"); err != nil {
+		return err
 	}
 
-	if brightnessManager != nil {
-		caps = append(caps, "brightness")
+	manager, err := scratchpad.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize scratchpad manager: %v", err)
+		return err
 	}
 
-	return ServerInfo{
-		APIVersion:   APIVersion,
-		Capabilities: caps,
+	scratchpadManager = manager
+
+	log.Info("Scratchpad manager initialized")
+	return nil
+}
+
+func InitializeTermcolorsManager() error {
+	if err := checkModuleEnabled("termcolors"); err != nil {
+		return err
 	}
+
+	termcolorsManager = termcolors.NewManager()
+	applyTermcolorsConfig(getDaemonConfig())
+
+	log.Info("Termcolors manager initialized")
+	return nil
 }
 
-func notifyCapabilityChange() {
-	capabilityMutex.RLock()
-	defer capabilityMutex.RUnlock()
+func InitializeThermalManager() error {
+	if err := checkModuleEnabled("thermal"); err != nil {
+		return err
+	}
 
-	info := getServerInfo()
-	for _, ch := range capabilitySubscribers {
-		select {
-		case ch <- info:
-		default:
-		}
+	manager, err := thermal.NewManager()
+	if err != nil {
+		return err
 	}
+
+	thermalManager = manager
+
+	log.Info("Thermal manager initialized")
+	return nil
 }
 
-func handleSubscribe(conn net.Conn, req models.Request) {
-	clientID := fmt.Sprintf("meta-client-%p", conn)
+func InitializeRemapManager() error {
+	if err := checkModuleEnabled("remap"); err != nil {
+		return err
+	}
 
-	var services []string
-	if servicesParam, ok := req.Params["services"].([]interface{}); ok {
-		for _, s := range servicesParam {
-			if str, ok := s.(string); ok {
-				services = append(services, str)
-			}
-		}
+	manager, err := remap.NewManager()
+	if err != nil {
+		return err
 	}
 
-	if len(services) == 0 {
-		services = []string{"all"}
+	remapManager = manager
+
+	log.Info("Remap manager initialized")
+	return nil
+}
+
+func InitializeA11yManager() error {
+	if err := checkModuleEnabled("a11y"); err != nil {
+		return err
 	}
 
-	subscribeAll := false
-	for _, s := range services {
-		if s == "all" {
-			subscribeAll = true
-			break
-		}
+	manager, err := a11y.NewManager()
+	if err != nil {
+		return err
 	}
 
-	var wg sync.WaitGroup
-	eventChan := make(chan ServiceEvent, 256)
-	stopChan := make(chan struct{})
+	a11yManager = manager
 
-	capChan := make(chan ServerInfo, 64)
-	capabilityMutex.Lock()
-	capabilitySubscribers[clientID+"-capabilities"] = capChan
-	capabilityMutex.Unlock()
+	log.Info("Accessibility manager initialized")
+	return nil
+}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		defer func() {
-			capabilityMutex.Lock()
-			delete(capabilitySubscribers, clientID+"-capabilities")
-			capabilityMutex.Unlock()
+func InitializeAudioManager() error {
+	if err := checkModuleEnabled("audio"); err != nil {
+		return err
+	}
+
+	manager, err := audio.NewManager()
+	if err != nil {
+		return err
+	}
+
+	audioManager = manager
+
+	log.Info("Audio stream manager initialized")
+	return nil
+}
+
+func InitializeWallpaperManager() error {
+	if err := checkModuleEnabled("wallpaper"); err != nil {
+		return err
+	}
+
+	manager, err := wallpaper.NewManager()
+	if err != nil {
+		return err
+	}
+
+	if waylandManager != nil {
+		layoutChan := waylandManager.Subscribe("wallpaper-layout")
+		manager.SetOutputs(wallpaperOutputs(waylandManager.GetState().Outputs))
+		go func() {
+			defer crash.Capture("wallpaperLayout", nil)
+			for state := range layoutChan {
+				manager.SetOutputs(wallpaperOutputs(state.Outputs))
+			}
 		}()
+	} else if infos, err := outputs.Query(); err == nil {
+		// Without the wayland manager the layout is read once and
+		// hotplugged outputs are not picked up
+		manager.SetOutputs(wallpaperOutputs(infos))
+	} else {
+		log.Warnf("Wallpaper manager has no output layout: %v", err)
+	}
 
-		for {
-			select {
-			case info, ok := <-capChan:
-				if !ok {
+	wallpaperManager = manager
+
+	log.Info("Wallpaper manager initialized")
+	return nil
+}
+
+func InitializeSoundsManager() error {
+	if err := checkModuleEnabled("sounds"); err != nil {
+		return err
+	}
+
+	manager, err := sounds.NewManager()
+	if err != nil {
+		return err
+	}
+
+	if pm := powerManager.Load(); pm != nil {
+		powerChan := pm.Subscribe("sounds-power")
+		onBattery := pm.GetState().OnBattery
+		go func() {
+			defer crash.Capture("soundsPower", nil)
+			for state := range powerChan {
+				if state.OnBattery == onBattery {
+					continue
+				}
+				onBattery = state.OnBattery
+				event := sounds.EventChargePlug
+				if onBattery {
+					event = sounds.EventChargeUnplug
+				}
+				if _, err := manager.Play(sounds.PlayOptions{Event: event}); err != nil {
+					log.Debugf("Failed to play %s sound: %v", event, err)
+				}
+			}
+		}()
+	}
+
+	soundsManager = manager
+
+	log.Info("Sounds manager initialized")
+	return nil
+}
+
+func InitializeJobsManager() error {
+	if err := checkModuleEnabled("jobs"); err != nil {
+		return err
+	}
+
+	jobsManager = jobs.NewManager()
+
+	log.Info("Jobs manager initialized")
+	return nil
+}
+
+func InitializeAppcolorManager() error {
+	if err := checkModuleEnabled("appcolor"); err != nil {
+		return err
+	}
+
+	manager, err := appcolor.NewManager()
+	if err != nil {
+		return err
+	}
+
+	appcolorManager = manager
+
+	log.Info("Window color manager initialized")
+	return nil
+}
+
+// wallpaperOutputs is the logical layout of the outputs xdg-output has
+// placed
+func wallpaperOutputs(infos []wayland.OutputInfo) []wallpaper.Output {
+	outputs := make([]wallpaper.Output, 0, len(infos))
+	for _, info := range infos {
+		if info.Name == "" || info.LogicalWidth <= 0 || info.LogicalHeight <= 0 {
+			continue
+		}
+		outputs = append(outputs, wallpaper.Output{
+			Name:   info.Name,
+			X:      int(info.LogicalX),
+			Y:      int(info.LogicalY),
+			Width:  int(info.LogicalWidth),
+			Height: int(info.LogicalHeight),
+			Scale:  info.Scale,
+		})
+	}
+	return outputs
+}
+
+// maxRequestSize leaves room for base64-encoded documents passed to cups.print.
+const maxRequestSize = cups.MaxPrintSize*4/3 + 64*1024
+
+func handleConnection(netConn net.Conn) {
+	defer netConn.Close()
+	defer crash.Capture("connection", nil)
+
+	conn := ipcRecorder.wrap(netConn)
+	caps := getCapabilities()
+	capsData, _ := json.Marshal(caps)
+	conn.Write(capsData)
+	conn.Write([]byte("\n"))
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRequestSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		conn.recordRequest(line)
+
+		var req models.Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			log.Warnf("handleConnection: Failed to unmarshal JSON: %v, line: %s", err, string(line))
+			models.RespondError(conn, 0, "invalid json")
+			continue
+		}
+
+		go func() {
+			defer crash.Capture("request "+req.Method, func(any) {
+				models.RespondError(conn, req.ID, "internal error")
+			})
+			RouteRequest(conn, req)
+		}()
+	}
+}
+
+func getCapabilities() Capabilities {
+	caps := []string{"plugins", "settings"}
+
+	if networkManager.Load() != nil {
+		caps = append(caps, "network")
+	}
+
+	if loginctlManager.Load() != nil {
+		caps = append(caps, "loginctl")
+	}
+
+	if freedesktopManager.Load() != nil {
+		caps = append(caps, "freedesktop")
+	}
+
+	if waylandManager != nil {
+		caps = append(caps, "gamma")
+	}
+
+	if bluezManager.Load() != nil {
+		caps = append(caps, "bluetooth")
+	}
+
+	if cupsManager.Load() != nil {
+		caps = append(caps, "cups")
+	}
+
+	if dwlManager != nil {
+		caps = append(caps, "dwl")
+	}
+
+	if brightnessManager.Load() != nil {
+		caps = append(caps, "brightness")
+	}
+
+	if sensorsManager != nil {
+		caps = append(caps, "sensors")
+	}
+
+	if notificationsManager != nil {
+		caps = append(caps, "notifications")
+	}
+
+	if promptsManager != nil {
+		caps = append(caps, "prompts")
+	}
+
+	if launcherManager != nil {
+		caps = append(caps, "launcher")
+	}
+
+	if powerManager.Load() != nil {
+		caps = append(caps, "power")
+	}
+
+	if rulesManager != nil {
+		caps = append(caps, "rules")
+	}
+
+	if timersManager != nil {
+		caps = append(caps, "timers")
+	}
+
+	if calendarManager != nil {
+		caps = append(caps, "calendar")
+	}
+
+	if scratchpadManager != nil {
+		caps = append(caps, "scratchpad")
+	}
+
+	if termcolorsManager != nil {
+		caps = append(caps, "termcolors")
+	}
+
+	if thermalManager != nil {
+		caps = append(caps, "thermal")
+	}
+
+	if remapManager != nil {
+		caps = append(caps, "remap")
+	}
+
+	if a11yManager != nil {
+		caps = append(caps, "a11y")
+	}
+
+	if audioManager != nil {
+		caps = append(caps, "audio")
+	}
+
+	if wallpaperManager != nil {
+		caps = append(caps, "wallpaper")
+	}
+
+	if soundsManager != nil {
+		caps = append(caps, "sounds")
+	}
+
+	if jobsManager != nil {
+		caps = append(caps, "jobs")
+	}
+
+	if appcolorManager != nil {
+		caps = append(caps, "appcolor")
+	}
+
+	if healthManager != nil {
+		caps = append(caps, "health")
+	}
+
+	return Capabilities{Capabilities: caps}
+}
+
+func getServerInfo() ServerInfo {
+	caps := []string{"plugins", "settings"}
+
+	if networkManager.Load() != nil {
+		caps = append(caps, "network")
+	}
+
+	if loginctlManager.Load() != nil {
+		caps = append(caps, "loginctl")
+	}
+
+	if freedesktopManager.Load() != nil {
+		caps = append(caps, "freedesktop")
+	}
+
+	if waylandManager != nil {
+		caps = append(caps, "gamma")
+	}
+
+	if bluezManager.Load() != nil {
+		caps = append(caps, "bluetooth")
+	}
+
+	if cupsManager.Load() != nil {
+		caps = append(caps, "cups")
+	}
+
+	if dwlManager != nil {
+		caps = append(caps, "dwl")
+	}
+
+	if brightnessManager.Load() != nil {
+		caps = append(caps, "brightness")
+	}
+
+	if sensorsManager != nil {
+		caps = append(caps, "sensors")
+	}
+
+	if notificationsManager != nil {
+		caps = append(caps, "notifications")
+	}
+
+	if promptsManager != nil {
+		caps = append(caps, "prompts")
+	}
+
+	if launcherManager != nil {
+		caps = append(caps, "launcher")
+	}
+
+	if powerManager.Load() != nil {
+		caps = append(caps, "power")
+	}
+
+	if rulesManager != nil {
+		caps = append(caps, "rules")
+	}
+
+	if timersManager != nil {
+		caps = append(caps, "timers")
+	}
+
+	if calendarManager != nil {
+		caps = append(caps, "calendar")
+	}
+
+	if scratchpadManager != nil {
+		caps = append(caps, "scratchpad")
+	}
+
+	if termcolorsManager != nil {
+		caps = append(caps, "termcolors")
+	}
+
+	if thermalManager != nil {
+		caps = append(caps, "thermal")
+	}
+
+	if remapManager != nil {
+		caps = append(caps, "remap")
+	}
+
+	if a11yManager != nil {
+		caps = append(caps, "a11y")
+	}
+
+	if audioManager != nil {
+		caps = append(caps, "audio")
+	}
+
+	if wallpaperManager != nil {
+		caps = append(caps, "wallpaper")
+	}
+
+	if soundsManager != nil {
+		caps = append(caps, "sounds")
+	}
+
+	if jobsManager != nil {
+		caps = append(caps, "jobs")
+	}
+
+	if appcolorManager != nil {
+		caps = append(caps, "appcolor")
+	}
+
+	if healthManager != nil {
+		caps = append(caps, "health")
+	}
+
+	sess := session.Current()
+	return ServerInfo{
+		APIVersion:   APIVersion,
+		Capabilities: caps,
+		CrashCount:   crash.Count(),
+		Session:      sess.ID,
+		Seat:         sess.Seat,
+		Deprecations: getDeprecations(),
+	}
+}
+
+func notifyCapabilityChange() {
+	capabilityMutex.RLock()
+	defer capabilityMutex.RUnlock()
+
+	info := getServerInfo()
+	for _, ch := range capabilitySubscribers {
+		select {
+		case ch <- info:
+		default:
+		}
+	}
+}
+
+func handleSubscribe(conn net.Conn, req models.Request) {
+	clientID := fmt.Sprintf("meta-client-%p", conn)
+
+	var services []string
+	if servicesParam, ok := req.Params["services"].([]interface{}); ok {
+		for _, s := range servicesParam {
+			if str, ok := s.(string); ok {
+				services = append(services, str)
+			}
+		}
+	}
+
+	if len(services) == 0 {
+		services = []string{"all"}
+	}
+
+	subscribeAll := false
+	for _, s := range services {
+		if s == "all" {
+			subscribeAll = true
+			break
+		}
+	}
+
+	var wg sync.WaitGroup
+	eventChan := make(chan ServiceEvent, 256)
+	stopChan := make(chan struct{})
+
+	capChan := make(chan ServerInfo, 64)
+	capabilityMutex.Lock()
+	capabilitySubscribers[clientID+"-capabilities"] = capChan
+	capabilityMutex.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer crash.Capture("handleSubscribe", nil)
+		defer wg.Done()
+		defer func() {
+			capabilityMutex.Lock()
+			delete(capabilitySubscribers, clientID+"-capabilities")
+			capabilityMutex.Unlock()
+		}()
+
+		for {
+			select {
+			case info, ok := <-capChan:
+				if !ok {
+					return
+				}
+				select {
+				case eventChan <- ServiceEvent{Service: "server", Data: info}:
+				case <-stopChan:
+					return
+				}
+			case <-stopChan:
+				return
+			}
+		}
+	}()
+
+	shouldSubscribe := func(service string) bool {
+		if subscribeAll {
+			return true
+		}
+		for _, s := range services {
+			if s == service {
+				return true
+			}
+		}
+		return false
+	}
+
+	if nm := networkManager.Load(); shouldSubscribe("network") && nm != nil {
+		wg.Add(1)
+		netChan := nm.Subscribe(clientID + "-network")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer nm.Unsubscribe(clientID + "-network")
+
+			initialState := nm.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "network", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-netChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "network", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if nm := networkManager.Load(); shouldSubscribe("network.credentials") && nm != nil {
+		wg.Add(1)
+		credChan := nm.SubscribeCredentials(clientID + "-credentials")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer nm.UnsubscribeCredentials(clientID + "-credentials")
+
+			for {
+				select {
+				case prompt, ok := <-credChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "network.credentials", Data: prompt}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if lm := loginctlManager.Load(); shouldSubscribe("loginctl") && lm != nil {
+		wg.Add(1)
+		loginChan := lm.Subscribe(clientID + "-loginctl")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer lm.Unsubscribe(clientID + "-loginctl")
+
+			initialState := lm.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "loginctl", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-loginChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "loginctl", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if fdm := freedesktopManager.Load(); shouldSubscribe("freedesktop") && fdm != nil {
+		wg.Add(1)
+		freedesktopChan := fdm.Subscribe(clientID + "-freedesktop")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer fdm.Unsubscribe(clientID + "-freedesktop")
+
+			initialState := fdm.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "freedesktop", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-freedesktopChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "freedesktop", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("gamma") && waylandManager != nil {
+		wg.Add(1)
+		waylandChan := waylandManager.Subscribe(clientID + "-gamma")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer waylandManager.Unsubscribe(clientID + "-gamma")
+
+			initialState := waylandManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "gamma", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-waylandChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "gamma", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if bm := bluezManager.Load(); shouldSubscribe("bluetooth") && bm != nil {
+		wg.Add(1)
+		bluezChan := bm.Subscribe(clientID + "-bluetooth")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer bm.Unsubscribe(clientID + "-bluetooth")
+
+			initialState := bm.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "bluetooth", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-bluezChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "bluetooth", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if bm := bluezManager.Load(); shouldSubscribe("bluetooth.pairing") && bm != nil {
+		wg.Add(1)
+		pairingChan := bm.SubscribePairing(clientID + "-pairing")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer bm.UnsubscribePairing(clientID + "-pairing")
+
+			for {
+				select {
+				case prompt, ok := <-pairingChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "bluetooth.pairing", Data: prompt}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("cups") {
+		cupsSubscribersMutex.Lock()
+		wasEmpty := len(cupsSubscribers) == 0
+		cupsSubscribers[clientID+"-cups"] = true
+		cupsSubscribersMutex.Unlock()
+
+		if wasEmpty {
+			if err := initWatched(InitializeCupsManager); err != nil {
+				log.Warnf("Failed to initialize CUPS manager for subscription: %v", err)
+			} else {
+				notifyCapabilityChange()
+			}
+		}
+
+		if cm := cupsManager.Load(); cm != nil {
+			wg.Add(1)
+			cupsChan := cm.Subscribe(clientID + "-cups")
+			failureChan := cm.SubscribeFailures(clientID + "-cups")
+			go func() {
+				defer crash.Capture("handleSubscribe", nil)
+				defer wg.Done()
+				defer func() {
+					cm.UnsubscribeFailures(clientID + "-cups")
+					cm.Unsubscribe(clientID + "-cups")
+
+					cupsSubscribersMutex.Lock()
+					delete(cupsSubscribers, clientID+"-cups")
+					isEmpty := len(cupsSubscribers) == 0
+					cupsSubscribersMutex.Unlock()
+
+					if isEmpty {
+						log.Info("Last CUPS subscriber disconnected, shutting down CUPS manager")
+						watchedInitMu.Lock()
+						current := cupsManager.Swap(nil)
+						watchedInitMu.Unlock()
+						if current != nil {
+							current.Close()
+							notifyCapabilityChange()
+						}
+					}
+				}()
+
+				initialState := cm.GetState()
+				select {
+				case eventChan <- ServiceEvent{Service: "cups", Data: initialState}:
+				case <-stopChan:
+					return
+				}
+
+				for {
+					select {
+					case state, ok := <-cupsChan:
+						if !ok {
+							return
+						}
+						select {
+						case eventChan <- ServiceEvent{Service: "cups", Data: state}:
+						case <-stopChan:
+							return
+						}
+					case failure, ok := <-failureChan:
+						if !ok {
+							return
+						}
+						select {
+						case eventChan <- ServiceEvent{Service: "cups.jobFailed", Data: failure}:
+						case <-stopChan:
+							return
+						}
+					case <-stopChan:
+						return
+					}
+				}
+			}()
+		}
+	}
+
+	if shouldSubscribe("dwl") && dwlManager != nil {
+		wg.Add(1)
+		dwlChan := dwlManager.Subscribe(clientID + "-dwl")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer dwlManager.Unsubscribe(clientID + "-dwl")
+
+			initialState := dwlManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "dwl", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-dwlChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "dwl", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if brm := brightnessManager.Load(); shouldSubscribe("brightness") && brm != nil {
+		wg.Add(2)
+		brightnessStateChan := brm.Subscribe(clientID + "-brightness-state")
+		brightnessUpdateChan := brm.SubscribeUpdates(clientID + "-brightness-updates")
+
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer brm.Unsubscribe(clientID + "-brightness-state")
+
+			initialState := brm.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "brightness", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-brightnessStateChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "brightness", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
 					return
 				}
+			}
+		}()
+
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer brm.UnsubscribeUpdates(clientID + "-brightness-updates")
+
+			for {
 				select {
-				case eventChan <- ServiceEvent{Service: "server", Data: info}:
+				case update, ok := <-brightnessUpdateChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "brightness.update", Data: update}:
+					case <-stopChan:
+						return
+					}
 				case <-stopChan:
 					return
 				}
-			case <-stopChan:
-				return
-			}
-		}
-	}()
-
-	shouldSubscribe := func(service string) bool {
-		if subscribeAll {
-			return true
-		}
-		for _, s := range services {
-			if s == service {
-				return true
 			}
-		}
-		return false
+		}()
 	}
 
-	if shouldSubscribe("network") && networkManager != nil {
+	if shouldSubscribe("sensors") && sensorsManager != nil {
 		wg.Add(1)
-		netChan := networkManager.Subscribe(clientID + "-network")
+		sensorsChan := sensorsManager.Subscribe(clientID + "-sensors")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer networkManager.Unsubscribe(clientID + "-network")
+			defer sensorsManager.Unsubscribe(clientID + "-sensors")
 
-			initialState := networkManager.GetState()
+			initialState := sensorsManager.GetState()
 			select {
-			case eventChan <- ServiceEvent{Service: "network", Data: initialState}:
+			case eventChan <- ServiceEvent{Service: "sensors", Data: initialState}:
 			case <-stopChan:
 				return
 			}
 
 			for {
 				select {
-				case state, ok := <-netChan:
+				case state, ok := <-sensorsChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "network", Data: state}:
+					case eventChan <- ServiceEvent{Service: "sensors", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -458,21 +1503,22 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("network.credentials") && networkManager != nil {
+	if shouldSubscribe("sensors.alert") && sensorsManager != nil {
 		wg.Add(1)
-		credChan := networkManager.SubscribeCredentials(clientID + "-credentials")
+		alertChan := sensorsManager.SubscribeAlerts(clientID + "-sensors-alert")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer networkManager.UnsubscribeCredentials(clientID + "-credentials")
+			defer sensorsManager.UnsubscribeAlerts(clientID + "-sensors-alert")
 
 			for {
 				select {
-				case prompt, ok := <-credChan:
+				case alert, ok := <-alertChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "network.credentials", Data: prompt}:
+					case eventChan <- ServiceEvent{Service: "sensors.alert", Data: alert}:
 					case <-stopChan:
 						return
 					}
@@ -483,28 +1529,30 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("loginctl") && loginctlManager != nil {
+	if shouldSubscribe("prompts") && promptsManager != nil {
 		wg.Add(1)
-		loginChan := loginctlManager.Subscribe(clientID + "-loginctl")
+		promptChan := promptsManager.Subscribe(clientID + "-prompts")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer loginctlManager.Unsubscribe(clientID + "-loginctl")
+			defer promptsManager.Unsubscribe(clientID + "-prompts")
 
-			initialState := loginctlManager.GetState()
-			select {
-			case eventChan <- ServiceEvent{Service: "loginctl", Data: initialState}:
-			case <-stopChan:
-				return
+			for _, p := range promptsManager.Pending() {
+				select {
+				case eventChan <- ServiceEvent{Service: "prompts", Data: prompts.Event{Type: "prompt", Prompt: p}}:
+				case <-stopChan:
+					return
+				}
 			}
 
 			for {
 				select {
-				case state, ok := <-loginChan:
+				case event, ok := <-promptChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "loginctl", Data: state}:
+					case eventChan <- ServiceEvent{Service: "prompts", Data: event}:
 					case <-stopChan:
 						return
 					}
@@ -515,28 +1563,29 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("freedesktop") && freedesktopManager != nil {
+	if pm := powerManager.Load(); shouldSubscribe("power") && pm != nil {
 		wg.Add(1)
-		freedesktopChan := freedesktopManager.Subscribe(clientID + "-freedesktop")
+		powerChan := pm.Subscribe(clientID + "-power")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer freedesktopManager.Unsubscribe(clientID + "-freedesktop")
+			defer pm.Unsubscribe(clientID + "-power")
 
-			initialState := freedesktopManager.GetState()
+			initialState := pm.GetState()
 			select {
-			case eventChan <- ServiceEvent{Service: "freedesktop", Data: initialState}:
+			case eventChan <- ServiceEvent{Service: "power", Data: initialState}:
 			case <-stopChan:
 				return
 			}
 
 			for {
 				select {
-				case state, ok := <-freedesktopChan:
+				case state, ok := <-powerChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "freedesktop", Data: state}:
+					case eventChan <- ServiceEvent{Service: "power", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -547,28 +1596,29 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("gamma") && waylandManager != nil {
+	if shouldSubscribe("timers") && timersManager != nil {
 		wg.Add(1)
-		waylandChan := waylandManager.Subscribe(clientID + "-gamma")
+		timersChan := timersManager.Subscribe(clientID + "-timers")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer waylandManager.Unsubscribe(clientID + "-gamma")
+			defer timersManager.Unsubscribe(clientID + "-timers")
 
-			initialState := waylandManager.GetState()
+			initialState := timersManager.GetState()
 			select {
-			case eventChan <- ServiceEvent{Service: "gamma", Data: initialState}:
+			case eventChan <- ServiceEvent{Service: "timers", Data: initialState}:
 			case <-stopChan:
 				return
 			}
 
 			for {
 				select {
-				case state, ok := <-waylandChan:
+				case state, ok := <-timersChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "gamma", Data: state}:
+					case eventChan <- ServiceEvent{Service: "timers", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -579,28 +1629,29 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("bluetooth") && bluezManager != nil {
+	if shouldSubscribe("a11y") && a11yManager != nil {
 		wg.Add(1)
-		bluezChan := bluezManager.Subscribe(clientID + "-bluetooth")
+		a11yChan := a11yManager.Subscribe(clientID + "-a11y")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer bluezManager.Unsubscribe(clientID + "-bluetooth")
+			defer a11yManager.Unsubscribe(clientID + "-a11y")
 
-			initialState := bluezManager.GetState()
+			initialState := a11yManager.GetState()
 			select {
-			case eventChan <- ServiceEvent{Service: "bluetooth", Data: initialState}:
+			case eventChan <- ServiceEvent{Service: "a11y", Data: initialState}:
 			case <-stopChan:
 				return
 			}
 
 			for {
 				select {
-				case state, ok := <-bluezChan:
+				case state, ok := <-a11yChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "bluetooth", Data: state}:
+					case eventChan <- ServiceEvent{Service: "a11y", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -611,21 +1662,29 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("bluetooth.pairing") && bluezManager != nil {
+	if shouldSubscribe("audio") && audioManager != nil {
 		wg.Add(1)
-		pairingChan := bluezManager.SubscribePairing(clientID + "-pairing")
+		audioChan := audioManager.Subscribe(clientID + "-audio")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer bluezManager.UnsubscribePairing(clientID + "-pairing")
+			defer audioManager.Unsubscribe(clientID + "-audio")
+
+			initialState := audioManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "audio", Data: initialState}:
+			case <-stopChan:
+				return
+			}
 
 			for {
 				select {
-				case prompt, ok := <-pairingChan:
+				case state, ok := <-audioChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "bluetooth.pairing", Data: prompt}:
+					case eventChan <- ServiceEvent{Service: "audio", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -636,91 +1695,128 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("cups") {
-		cupsSubscribersMutex.Lock()
-		wasEmpty := len(cupsSubscribers) == 0
-		cupsSubscribers[clientID+"-cups"] = true
-		cupsSubscribersMutex.Unlock()
+	if shouldSubscribe("wallpaper") && wallpaperManager != nil {
+		wg.Add(1)
+		wallpaperChan := wallpaperManager.Subscribe(clientID + "-wallpaper")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer wallpaperManager.Unsubscribe(clientID + "-wallpaper")
 
-		if wasEmpty {
-			if err := InitializeCupsManager(); err != nil {
-				log.Warnf("Failed to initialize CUPS manager for subscription: %v", err)
-			} else {
-				notifyCapabilityChange()
+			initialState := wallpaperManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "wallpaper", Data: initialState}:
+			case <-stopChan:
+				return
 			}
-		}
 
-		if cupsManager != nil {
-			wg.Add(1)
-			cupsChan := cupsManager.Subscribe(clientID + "-cups")
-			go func() {
-				defer wg.Done()
-				defer func() {
-					cupsManager.Unsubscribe(clientID + "-cups")
+			for {
+				select {
+				case state, ok := <-wallpaperChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "wallpaper", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
 
-					cupsSubscribersMutex.Lock()
-					delete(cupsSubscribers, clientID+"-cups")
-					isEmpty := len(cupsSubscribers) == 0
-					cupsSubscribersMutex.Unlock()
+	if shouldSubscribe("sounds") && soundsManager != nil {
+		wg.Add(1)
+		soundsChan := soundsManager.Subscribe(clientID + "-sounds")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer soundsManager.Unsubscribe(clientID + "-sounds")
 
-					if isEmpty {
-						log.Info("Last CUPS subscriber disconnected, shutting down CUPS manager")
-						if cupsManager != nil {
-							cupsManager.Close()
-							cupsManager = nil
-							notifyCapabilityChange()
-						}
-					}
-				}()
+			initialState := soundsManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "sounds", Data: initialState}:
+			case <-stopChan:
+				return
+			}
 
-				initialState := cupsManager.GetState()
+			for {
 				select {
-				case eventChan <- ServiceEvent{Service: "cups", Data: initialState}:
+				case state, ok := <-soundsChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "sounds", Data: state}:
+					case <-stopChan:
+						return
+					}
 				case <-stopChan:
 					return
 				}
+			}
+		}()
+	}
 
-				for {
+	if shouldSubscribe("jobs") && jobsManager != nil {
+		wg.Add(1)
+		jobsChan := jobsManager.Subscribe(clientID + "-jobs")
+		go func() {
+			defer crash.Capture("handleSubscribe", nil)
+			defer wg.Done()
+			defer jobsManager.Unsubscribe(clientID + "-jobs")
+
+			initialState := jobsManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "jobs", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-jobsChan:
+					if !ok {
+						return
+					}
 					select {
-					case state, ok := <-cupsChan:
-						if !ok {
-							return
-						}
-						select {
-						case eventChan <- ServiceEvent{Service: "cups", Data: state}:
-						case <-stopChan:
-							return
-						}
+					case eventChan <- ServiceEvent{Service: "jobs", Data: state}:
 					case <-stopChan:
 						return
 					}
+				case <-stopChan:
+					return
 				}
-			}()
-		}
+			}
+		}()
 	}
 
-	if shouldSubscribe("dwl") && dwlManager != nil {
+	if shouldSubscribe("appcolor") && appcolorManager != nil {
 		wg.Add(1)
-		dwlChan := dwlManager.Subscribe(clientID + "-dwl")
+		appcolorChan := appcolorManager.Subscribe(clientID + "-appcolor")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer dwlManager.Unsubscribe(clientID + "-dwl")
+			defer appcolorManager.Unsubscribe(clientID + "-appcolor")
 
-			initialState := dwlManager.GetState()
+			initialState := appcolorManager.GetState()
 			select {
-			case eventChan <- ServiceEvent{Service: "dwl", Data: initialState}:
+			case eventChan <- ServiceEvent{Service: "appcolor", Data: initialState}:
 			case <-stopChan:
 				return
 			}
 
 			for {
 				select {
-				case state, ok := <-dwlChan:
+				case state, ok := <-appcolorChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "dwl", Data: state}:
+					case eventChan <- ServiceEvent{Service: "appcolor", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -731,30 +1827,29 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	if shouldSubscribe("brightness") && brightnessManager != nil {
-		wg.Add(2)
-		brightnessStateChan := brightnessManager.Subscribe(clientID + "-brightness-state")
-		brightnessUpdateChan := brightnessManager.SubscribeUpdates(clientID + "-brightness-updates")
-
+	if shouldSubscribe("calendar") && calendarManager != nil {
+		wg.Add(1)
+		calendarChan := calendarManager.Subscribe(clientID + "-calendar")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer brightnessManager.Unsubscribe(clientID + "-brightness-state")
+			defer calendarManager.Unsubscribe(clientID + "-calendar")
 
-			initialState := brightnessManager.GetState()
+			initialState := calendarManager.GetState()
 			select {
-			case eventChan <- ServiceEvent{Service: "brightness", Data: initialState}:
+			case eventChan <- ServiceEvent{Service: "calendar", Data: initialState}:
 			case <-stopChan:
 				return
 			}
 
 			for {
 				select {
-				case state, ok := <-brightnessStateChan:
+				case state, ok := <-calendarChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "brightness", Data: state}:
+					case eventChan <- ServiceEvent{Service: "calendar", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -763,19 +1858,31 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 				}
 			}
 		}()
+	}
 
+	if shouldSubscribe("health") && healthManager != nil {
+		wg.Add(1)
+		healthChan := healthManager.Subscribe(clientID + "-health")
 		go func() {
+			defer crash.Capture("handleSubscribe", nil)
 			defer wg.Done()
-			defer brightnessManager.UnsubscribeUpdates(clientID + "-brightness-updates")
+			defer healthManager.Unsubscribe(clientID + "-health")
+
+			initialState := healthManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "health", Data: initialState}:
+			case <-stopChan:
+				return
+			}
 
 			for {
 				select {
-				case update, ok := <-brightnessUpdateChan:
+				case state, ok := <-healthChan:
 					if !ok {
 						return
 					}
 					select {
-					case eventChan <- ServiceEvent{Service: "brightness.update", Data: update}:
+					case eventChan <- ServiceEvent{Service: "health", Data: state}:
 					case <-stopChan:
 						return
 					}
@@ -787,6 +1894,7 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 	}
 
 	go func() {
+		defer crash.Capture("handleSubscribe", nil)
 		wg.Wait()
 		close(eventChan)
 	}()
@@ -812,29 +1920,71 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 }
 
 func cleanupManagers() {
-	if networkManager != nil {
-		networkManager.Close()
+	if nm := networkManager.Load(); nm != nil {
+		nm.Close()
 	}
-	if loginctlManager != nil {
-		loginctlManager.Close()
+	if lm := loginctlManager.Load(); lm != nil {
+		lm.Close()
 	}
-	if freedesktopManager != nil {
-		freedesktopManager.Close()
+	if fdm := freedesktopManager.Load(); fdm != nil {
+		fdm.Close()
 	}
 	if waylandManager != nil {
 		waylandManager.Close()
 	}
-	if bluezManager != nil {
-		bluezManager.Close()
+	if bm := bluezManager.Load(); bm != nil {
+		bm.Close()
 	}
-	if cupsManager != nil {
-		cupsManager.Close()
+	if cm := cupsManager.Load(); cm != nil {
+		cm.Close()
 	}
 	if dwlManager != nil {
 		dwlManager.Close()
 	}
-	if brightnessManager != nil {
-		brightnessManager.Close()
+	if brm := brightnessManager.Load(); brm != nil {
+		brm.Close()
+	}
+	if sensorsManager != nil {
+		sensorsManager.Close()
+	}
+	if promptsManager != nil {
+		promptsManager.Close()
+	}
+	if pm := powerManager.Load(); pm != nil {
+		pm.Close()
+	}
+	if rulesManager != nil {
+		rulesManager.Close()
+	}
+	if timersManager != nil {
+		timersManager.Close()
+	}
+	if a11yManager != nil {
+		a11yManager.Close()
+	}
+	if audioManager != nil {
+		audioManager.Close()
+	}
+	if wallpaperManager != nil {
+		wallpaperManager.Close()
+	}
+	if soundsManager != nil {
+		soundsManager.Close()
+	}
+	if jobsManager != nil {
+		jobsManager.Close()
+	}
+	if appcolorManager != nil {
+		appcolorManager.Close()
+	}
+	if calendarManager != nil {
+		calendarManager.Close()
+	}
+	if scratchpadManager != nil {
+		scratchpadManager.Close()
+	}
+	if healthManager != nil {
+		healthManager.Close()
 	}
 	if wlContext != nil {
 		wlContext.Close()
@@ -854,17 +2004,27 @@ func Start(printDocs bool) error {
 	defer listener.Close()
 	defer cleanupManagers()
 
+	if err := crash.CaptureFatal(); err != nil {
+		log.Warnf("Fatal crash capture unavailable: %v", err)
+	}
+
 	log.Infof("DMS API Server listening on: %s", socketPath)
 	log.Infof("API Version: %d", APIVersion)
 	log.Info("Protocol: JSON over Unix socket")
-	log.Info("Request format: {\"id\": <any>, \"method\": \"...\", \"params\": {...}}")
+	log.Info("Request format: {\"id\": <any>, \"method\": \"...\", \"params\": {...}, \"apiVersion\": <int, optional>}")
 	log.Info("Response format: {\"id\": <any>, \"result\": {...}} or {\"id\": <any>, \"error\": \"...\"}")
+	log.Info("Responses carry \"meta\": {apiVersion, deprecations} when the request set apiVersion or called a deprecated method")
 	log.Info("")
 	if printDocs {
 		log.Info("Available methods:")
 		log.Info("  ping          - Test connection")
-		log.Info("  getServerInfo - Get server info (API version and capabilities)")
+		log.Info("  getServerInfo - Get server info (API version, capabilities, recovered panic count and deprecated methods)")
+		log.Info("  config.reload - Re-read daemon.toml and apply runtime options (returns applied and restartRequired keys)")
+		log.Info("  debug.record.start  - Record sanitized IPC traffic to a capped file (params: path?, maxBytes?)")
+		log.Info("  debug.record.stop   - Stop recording and close the file")
+		log.Info("  debug.record.status - Get the recording path, size and entry count")
 		log.Info("  subscribe     - Subscribe to multiple services (params: services [default: all])")
+		log.Info("  introspect    - Get JSON Schemas of method params and results (params: method? [default: all])")
 		log.Info("Plugins:")
 		log.Info(" plugins.list                - List all plugins")
 		log.Info(" plugins.listInstalled       - List installed plugins")
@@ -872,6 +2032,9 @@ func Start(printDocs bool) error {
 		log.Info(" plugins.uninstall           - Uninstall plugin (params: name)")
 		log.Info(" plugins.update              - Update plugin (params: name)")
 		log.Info(" plugins.search              - Search plugins (params: query, category?, compositor?, capability?)")
+		log.Info("Settings:")
+		log.Info(" settings.export             - Archive DMS settings, state and theme (params: path?)")
+		log.Info(" settings.import             - Restore a settings archive (params: path, absolute)")
 		log.Info("Network:")
 		log.Info(" network.getState            - Get current network state")
 		log.Info(" network.wifi.scan           - Scan for WiFi networks")
@@ -883,9 +2046,15 @@ func Start(printDocs bool) error {
 		log.Info(" network.wifi.enable         - Enable WiFi")
 		log.Info(" network.wifi.disable        - Disable WiFi")
 		log.Info(" network.wifi.setAutoconnect - Set network autoconnect (params: ssid, autoconnect)")
+		log.Info(" network.appUsage            - Per-application traffic, busiest first; needs 'dms netacct enable' (params: limit?)")
+		log.Info(" network.speedTest           - Measure latency, download and upload speed (takes up to ~45s)")
+		log.Info(" network.linkHistory         - Recent Wi-Fi signal, bitrate and retry samples (params: limit?)")
 		log.Info(" network.ethernet.connect    - Connect Ethernet")
 		log.Info(" network.ethernet.connect.config - Connect Ethernet to a specific configuration")
 		log.Info(" network.ethernet.disconnect - Disconnect Ethernet")
+		log.Info(" network.tether.list         - List USB and Bluetooth tethering phones")
+		log.Info(" network.tether.connect      - Connect through a tethering phone (params: id)")
+		log.Info(" network.tether.disconnect   - Disconnect a Bluetooth tethering phone (params: id)")
 		log.Info(" network.vpn.profiles        - List VPN profiles")
 		log.Info(" network.vpn.active          - List active VPN connections")
 		log.Info(" network.vpn.connect         - Connect VPN (params: uuidOrName|name|uuid, singleActive?)")
@@ -946,7 +2115,20 @@ func Start(printDocs bool) error {
 		log.Info(" cups.pausePrinter                     - Pause printer (params: printerName)")
 		log.Info(" cups.resumePrinter                    - Resume printer (params: printerName)")
 		log.Info(" cups.cancelJob                        - Cancel job (params: printerName, jobID)")
+		log.Info(" cups.retryJob                         - Resume the stopped printer and restart an aborted job (params: jobID)")
 		log.Info(" cups.purgeJobs                        - Cancel all jobs (params: printerName)")
+		log.Info(" cups.deletePrinter                    - Delete a printer queue (params: printerName)")
+		log.Info(" cups.print                            - Print a document (params: printerName, path|url|data (base64), title?)")
+		log.Info(" cups.getServerSettings                - Get printer sharing and browsing settings")
+		log.Info(" cups.setServerSettings                - Change server settings (params: sharePrinters?, remoteAny?, remoteAdmin?, userCancelAny?, debugLogging?, browseRemote?)")
+		log.Info(" cups.getDevices                       - Discover network printers, driverless first")
+		log.Info(" cups.autoAdd                          - Create and verify an IPP Everywhere queue (params: uri, name?)")
+		log.Info("   Job state is the IPP keyword (unknown for anything else), with stateInfo giving a")
+		log.Info("   label, icon and severity to show it with and ippState the raw value.")
+		log.Info("   Aborted and stopped jobs are published as job_failed events on cups.subscribe")
+		log.Info("   (cups.jobFailed in subscribe) with the printer-state-message and retry/cancel actions.")
+		log.Info("   With cups.polkit set, deleting printers and cancelling other users' jobs needs polkit")
+		log.Info("   authorization for the calling process; refusals carry code PERMISSION_DENIED.")
 		log.Info("DWL:")
 		log.Info(" dwl.getState                          - Get current dwl state (tags, windows, layouts)")
 		log.Info(" dwl.setTags                           - Set active tags (params: output, tagmask, toggleTagset)")
@@ -956,23 +2138,168 @@ func Start(printDocs bool) error {
 		log.Info("Brightness:")
 		log.Info(" brightness.getState                   - Get current brightness state for all devices")
 		log.Info(" brightness.setBrightness              - Set device brightness (params: device, percent)")
-		log.Info(" brightness.increment                  - Increment device brightness (params: device, step?)")
-		log.Info(" brightness.decrement                  - Decrement device brightness (params: device, step?)")
+		log.Info(" brightness.increment                  - Increment device brightness (params: device, step?, source?)")
+		log.Info(" brightness.decrement                  - Decrement device brightness (params: device, step?, source?)")
+		log.Info("   With source (keys or slider) the step follows that source's curve from")
+		log.Info("   brightness.key-steps or brightness.slider-steps instead of step.")
 		log.Info(" brightness.rescan                     - Rescan for brightness devices (e.g., after plugging in monitor)")
+		log.Info(" brightness.ddc.capabilities           - Get supported VCP features of a DDC monitor (params: device)")
+		log.Info(" brightness.ddc.setContrast            - Set DDC monitor contrast (params: device, percent)")
+		log.Info(" brightness.ddc.setInput               - Switch DDC monitor input (params: device, input)")
 		log.Info(" brightness.subscribe                  - Subscribe to brightness state changes (streaming)")
 		log.Info("   Subscription events:")
 		log.Info("     - brightness       : Full device list (on rescan, DDC discovery, device changes)")
 		log.Info("     - brightness.update: Single device update (on brightness change for efficiency)")
+		log.Info("Sensors:")
+		log.Info(" sensors.list                          - Get current temperature and fan readings")
+		log.Info(" sensors.setThreshold                  - Set warning threshold in °C (params: sensor, value; value <= 0 resets)")
+		log.Info(" sensors.setDefaultThreshold           - Set fallback warning threshold in °C (params: value)")
+		log.Info(" sensors.subscribe                     - Subscribe to sensor state changes (streaming)")
+		log.Info("   Subscription events:")
+		log.Info("     - sensors      : Full sensor list (on significant change)")
+		log.Info("     - sensors.alert: Sensor crossed its warning threshold (exceeded true/false)")
+		log.Info("Notifications:")
+		log.Info(" notifications.getForwarding           - Get per-urgency forwarding config")
+		log.Info(" notifications.setForwarding           - Set forwarding for an urgency (params: urgency, file?, terminal?, bell?)")
+		log.Info(" notifications.forward                 - Mirror a notification to configured sinks (params: summary, body?, appName?, urgency?)")
+		log.Info("Prompts:")
+		log.Info(" prompts.list                          - List open consent prompts")
+		log.Info(" prompts.respond                       - Answer a prompt (params: token, action, value?, secrets?, remember?)")
+		log.Info("  Bluetooth pairing and network secret prompts are filed here as well as on their own modules")
+		log.Info(" prompts.subscribe                     - Subscribe to prompt events (streaming)")
+		log.Info("   Subscription events:")
+		log.Info("     - prompt  : A module needs consent (token, source, kind, title, actions, default, expiresAt)")
+		log.Info("     - resolved: Prompt answered or timed out (default action applied)")
+		log.Info("Launcher:")
+		log.Info(" launcher.search                       - Search installed applications (params: query?, limit?)")
+		log.Info(" launcher.launch                       - Launch an application and record the use (params: id)")
+		log.Info(" launcher.frecency                     - List most used applications (params: limit?)")
+		log.Info(" launcher.refresh                      - Rebuild the application index")
+		log.Info("Power:")
+		log.Info(" power.getState                        - Get AC state, active profile and available profiles")
+		log.Info(" power.setProfile                      - Switch power profile by hand (params: profile)")
+		log.Info(" power.policy.get                      - Get the AC/battery policy (off until enabled)")
+		log.Info(" power.policy.set                      - Update the policy (params: enabled?, acProfile?, batteryProfile?, brightnessCap?, refreshRate?)")
+		log.Info(" power.subscribe                       - Subscribe to power state changes (streaming)")
+		log.Info("Rules:")
+		log.Info(" rules.getState                        - Get loaded window rules, errors and conflicts")
+		log.Info(" rules.reload                          - Re-read rules.toml and return the new state")
+		log.Info(" rules.match                           - Show which rules apply to a window (params: appId?, title?)")
+		log.Info("Timers:")
+		log.Info(" timers.list                           - List running and paused timers, soonest first")
+		log.Info(" timers.create                         - Start a countdown (params: seconds, label?)")
+		log.Info(" timers.alarm                          - Set an alarm (params: at (HH:MM or RFC 3339), label?)")
+		log.Info(" timers.pomodoro                       - Start a pomodoro cycle (params: work?, shortBreak?, longBreak?, rounds?, label?)")
+		log.Info(" timers.pause                          - Pause a timer or pomodoro (params: id)")
+		log.Info(" timers.resume                         - Resume a paused timer (params: id)")
+		log.Info(" timers.skip                           - Move a pomodoro to its next phase (params: id)")
+		log.Info(" timers.cancel                         - Remove a timer or alarm (params: id)")
+		log.Info(" timers.subscribe                      - Subscribe to timer changes (streaming, fired is set when one goes off)")
+		log.Info("   Timers survive restarts; ones that ran out while dms was down fire on startup.")
+		log.Info("Calendar:")
+		log.Info(" calendar.getState                     - Get configured calendars, sync status and the next 14 days of events")
+		log.Info(" calendar.upcoming                     - List events that have not ended yet (params: days?, limit?, calendar?)")
+		log.Info(" calendar.refresh                      - Re-read calendars.toml and sync every calendar now")
+		log.Info(" calendar.subscribe                    - Subscribe to calendar changes (streaming)")
+		log.Info("   Calendars are read-only ICS files, ICS feeds or CalDAV collections listed in")
+		log.Info("   ~/.config/DankMaterialShell/calendars.toml; remote events are cached for offline use.")
+		log.Info("Scratchpad:")
+		log.Info(" scratchpad.getState                   - Get declared scratchpads, whether they run and are visible")
+		log.Info(" scratchpad.reload                     - Re-read scratchpads.toml and return the new state")
+		log.Info(" scratchpad.toggle                     - Start, show or hide a scratchpad (params: name, command?, appId?)")
+		log.Info("   Hidden scratchpads are parked off-screen and come back with the size and")
+		log.Info("   position they had; command and appId declare a pad not in scratchpads.toml.")
+		log.Info("Termcolors:")
+		log.Info(" termcolors.getState                   - Get the allowed terminals and the shells that would be recoloured")
+		log.Info(" termcolors.apply                      - Recolour open shells (params: colors (16 hex), foreground?, background?, cursor?)")
+		log.Info("   OSC 4/10/11/12 sequences are written to the ptys of shells started by the")
+		log.Info("   terminals listed in termcolors.terminals; nothing is written while it is empty.")
+		log.Info("Thermal:")
+		log.Info(" thermal.getState                      - Get the fan/thermal profile backend, its profiles and the active one")
+		log.Info(" thermal.listProfiles                  - Alias for thermal.getState")
+		log.Info(" thermal.setProfile                    - Switch the thermal profile (params: profile)")
+		log.Info("   Uses asusctl, then TLP, then /sys/firmware/acpi/platform_profile, whichever")
+		log.Info("   is found first; TLP and platform_profile writes go through pkexec.")
+		log.Info("Remap:")
+		log.Info(" remap.getState                        - Get the key remaps, the backend and whether they are applied")
+		log.Info(" remap.set                             - Remap a key and apply it (params: from, to)")
+		log.Info(" remap.remove                          - Drop a remap and apply the rest (params: from)")
+		log.Info(" remap.clear                           - Drop every remap, restoring the keyboard")
+		log.Info(" remap.reload                          - Re-read remaps.toml and apply it")
+		log.Info("   Keys use keyd names (capslock, esc, leftcontrol, compose). keyd gets")
+		log.Info("   /etc/keyd/dms.conf through pkexec; on Hyprland only XKB-option remaps work.")
+		log.Info("Accessibility:")
+		log.Info(" a11y.get                              - Get cursor size, text scale and reduce motion, and where they were applied")
+		log.Info(" a11y.set                              - Change settings (params: cursorSize?, textScale?, reduceMotion?)")
+		log.Info(" a11y.subscribe                        - Subscribe to accessibility changes (streaming)")
+		log.Info("   Written to GSettings (and so the settings portal), GTK 3/4 settings.ini,")
+		log.Info("   kdeglobals and the session environment; Hyprland cursors change live.")
+		log.Info("Audio Streams:")
+		log.Info(" audio.streams.list                    - List application streams, output devices and remembered routes")
+		log.Info(" audio.streams.setVolume               - Set a stream's volume, 1 being 100% (params: id, volume)")
+		log.Info(" audio.streams.setMute                 - Mute or unmute a stream (params: id, muted)")
+		log.Info(" audio.streams.setSink                 - Move a stream to an output device (params: id, sink)")
+		log.Info(" audio.streams.forget                  - Stop restoring an application's settings (params: app)")
+		log.Info(" audio.streams.subscribe               - Subscribe to stream changes (streaming)")
+		log.Info("   Changes are remembered per application and restored when it plays again.")
+		log.Info("Wallpaper:")
+		log.Info(" wallpaper.get                         - Get the wallpaper choices and what each output draws")
+		log.Info(" wallpaper.set                         - Set a wallpaper (params: path, output?, mode?, span?)")
+		log.Info(" wallpaper.clear                       - Remove an output's wallpaper, or all of them (params: output?)")
+		log.Info(" wallpaper.subscribe                   - Subscribe to wallpaper and layout changes (streaming)")
+		log.Info("   Modes are fill (default), fit and center. With span one image covers the whole")
+		log.Info("   output layout and each output gets its crop of it.")
+		log.Info("Sounds:")
+		log.Info(" sounds.getState                       - Get per-event sound settings and what playback can do")
+		log.Info(" sounds.play                           - Play an event's sound (params: event, text?, volume?)")
+		log.Info(" sounds.setEvent                       - Configure an event (params: event, enabled?, sound?, volume?, duck?, speak?)")
+		log.Info(" sounds.setConfig                      - Set the theme, master volume, duck level or speech command")
+		log.Info(" sounds.subscribe                      - Subscribe to sound settings and playback changes (streaming)")
+		log.Info("   Events are notification, error, charge-plug and charge-unplug, or any name")
+		log.Info("   added with setEvent. Charge sounds play on power changes once enabled.")
+		log.Info("Jobs:")
+		log.Info(" jobs.list                             - List running, queued and recent jobs")
+		log.Info(" jobs.get                              - Get one job, with its result once done (params: id)")
+		log.Info(" jobs.cancel                           - Stop a queued or running job (params: id)")
+		log.Info(" jobs.subscribe                        - Subscribe to job progress and results (streaming)")
+		log.Info("   network.speedTest, network.tether.connect, cups.getDevices, cups.autoAdd and")
+		log.Info("   termcolors.apply called with async: true answer with a job at once instead of")
+		log.Info("   waiting.")
+		log.Info("Window Color (experimental):")
+		log.Info(" appcolor.getState                     - Get the last sampled color of the focused window")
+		log.Info(" appcolor.sample                       - Capture the focused window and return its dominant color")
+		log.Info(" appcolor.follow                       - Sample again whenever focus changes (params: enabled)")
+		log.Info(" appcolor.subscribe                    - Subscribe to sampled colors (streaming)")
+		log.Info("   Needs niri 25.08+ or Hyprland, and grim for the screencopy thumbnail.")
+		log.Info("Health:")
+		log.Info(" health.getState                       - Get the status of watched backends (ok, degraded, stopped)")
+		log.Info(" health.check                          - Check every backend now and retry degraded ones")
+		log.Info(" health.subscribe                      - Subscribe to backend status changes (streaming, changed names the backend)")
+		log.Info("   A degraded backend keeps serving its last state while it is reinitialized")
+		log.Info("   with backoff; on recovery the server capabilities event is resent.")
+		log.Info("Safeguard:")
+		log.Info("  cups.purgeJobs, cups.deletePrinter, network.wifi.forget and loginctl.terminate return a confirmation")
+		log.Info("  token on first call; repeat the call with params.confirmToken on the same connection within 30s")
+		log.Info("  to proceed. Off by default; set server.safeguard = true in daemon.toml to turn it on.")
 		log.Info("")
 	}
 	log.Info("Initializing managers...")
 	log.Info("")
 
+	// Network and bluetooth file their prompts with it, so it comes first
+	if err := InitializePromptsManager(); err != nil {
+		log.Warnf("Prompts manager unavailable: %v", err)
+	}
+
 	go func() {
+		defer crash.Capture("Start", nil)
 		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
 
-		if err := InitializeNetworkManager(); err != nil {
+		if err := initWatched(InitializeNetworkManager); errors.Is(err, errModuleDisabled) {
+			log.Infof("Network manager not started: %v", err)
+			return
+		} else if err != nil {
 			log.Warnf("Network manager unavailable: %v", err)
 		} else {
 			notifyCapabilityChange()
@@ -980,10 +2307,10 @@ func Start(printDocs bool) error {
 		}
 
 		for range ticker.C {
-			if networkManager != nil {
+			if networkManager.Load() != nil {
 				return
 			}
-			if err := InitializeNetworkManager(); err == nil {
+			if err := initWatched(InitializeNetworkManager); err == nil {
 				log.Info("Network manager initialized")
 				notifyCapabilityChange()
 				return
@@ -992,7 +2319,8 @@ func Start(printDocs bool) error {
 	}()
 
 	go func() {
-		if err := InitializeLoginctlManager(); err != nil {
+		defer crash.Capture("Start", nil)
+		if err := initWatched(InitializeLoginctlManager); err != nil {
 			log.Warnf("Loginctl manager unavailable: %v", err)
 		} else {
 			notifyCapabilityChange()
@@ -1000,10 +2328,11 @@ func Start(printDocs bool) error {
 	}()
 
 	go func() {
-		if err := InitializeFreedeskManager(); err != nil {
+		defer crash.Capture("Start", nil)
+		if err := initWatched(InitializeFreedeskManager); err != nil {
 			log.Warnf("Freedesktop manager unavailable: %v", err)
-		} else if freedesktopManager != nil {
-			freedesktopManager.NotifySubscribers()
+		} else if fdm := freedesktopManager.Load(); fdm != nil {
+			fdm.NotifySubscribers()
 			notifyCapabilityChange()
 		}
 	}()
@@ -1013,7 +2342,8 @@ func Start(printDocs bool) error {
 	}
 
 	go func() {
-		if err := InitializeBluezManager(); err != nil {
+		defer crash.Capture("Start", nil)
+		if err := initWatched(InitializeBluezManager); err != nil {
 			log.Warnf("Bluez manager unavailable: %v", err)
 		} else {
 			notifyCapabilityChange()
@@ -1025,13 +2355,101 @@ func Start(printDocs bool) error {
 	}
 
 	go func() {
-		if err := InitializeBrightnessManager(); err != nil {
+		defer crash.Capture("Start", nil)
+		if err := initWatched(InitializeBrightnessManager); err != nil {
 			log.Warnf("Brightness manager unavailable: %v", err)
 		} else {
 			notifyCapabilityChange()
 		}
 	}()
 
+	if err := InitializeNotificationsManager(); err != nil {
+		log.Warnf("Notifications manager unavailable: %v", err)
+	}
+
+	go func() {
+		defer crash.Capture("Start", nil)
+		if err := InitializeSensorsManager(); err != nil {
+			log.Warnf("Sensors manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
+	}()
+
+	go func() {
+		defer crash.Capture("Start", nil)
+		if err := InitializeLauncherManager(); err != nil {
+			log.Warnf("Launcher manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
+	}()
+
+	go func() {
+		defer crash.Capture("Start", nil)
+		if err := initWatched(InitializePowerManager); err != nil {
+			log.Warnf("Power manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
+	}()
+
+	if err := InitializeRulesManager(); err != nil {
+		log.Warnf("Rules manager unavailable: %v", err)
+	}
+
+	if err := InitializeTimersManager(); err != nil {
+		log.Warnf("Timers manager unavailable: %v", err)
+	}
+
+	if err := InitializeCalendarManager(); err != nil {
+		log.Warnf("Calendar manager unavailable: %v", err)
+	}
+
+	if err := InitializeScratchpadManager(); err != nil {
+		log.Warnf("Scratchpad manager unavailable: %v", err)
+	}
+
+	if err := InitializeTermcolorsManager(); err != nil {
+		log.Warnf("Termcolors manager unavailable: %v", err)
+	}
+
+	if err := InitializeThermalManager(); err != nil {
+		log.Warnf("Thermal manager unavailable: %v", err)
+	}
+
+	if err := InitializeRemapManager(); err != nil {
+		log.Warnf("Remap manager unavailable: %v", err)
+	}
+
+	if err := InitializeA11yManager(); err != nil {
+		log.Warnf("Accessibility manager unavailable: %v", err)
+	}
+
+	if err := InitializeAudioManager(); err != nil {
+		log.Warnf("Audio stream manager unavailable: %v", err)
+	}
+
+	if err := InitializeWallpaperManager(); err != nil {
+		log.Warnf("Wallpaper manager unavailable: %v", err)
+	}
+
+	if err := InitializeSoundsManager(); err != nil {
+		log.Warnf("Sounds manager unavailable: %v", err)
+	}
+
+	if err := InitializeJobsManager(); err != nil {
+		log.Warnf("Jobs manager unavailable: %v", err)
+	}
+
+	if err := InitializeAppcolorManager(); err != nil {
+		log.Warnf("Window color manager unavailable: %v", err)
+	}
+
+	if err := InitializeHealthManager(); err != nil {
+		log.Warnf("Health watchdog unavailable: %v", err)
+	}
+
 	if wlContext != nil {
 		wlContext.Start()
 		log.Info("Wayland event dispatcher started")
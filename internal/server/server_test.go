@@ -8,8 +8,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/AvengeMedia/danklinux/internal/daemonconfig"
 	"github.com/AvengeMedia/danklinux/internal/server/models"
 	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/AvengeMedia/danklinux/internal/server/notifications"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -48,18 +50,18 @@ func TestGetSocketPath(t *testing.T) {
 }
 
 func TestGetCapabilities(t *testing.T) {
-	originalNetworkManager := networkManager
-	defer func() { networkManager = originalNetworkManager }()
+	originalNetworkManager := networkManager.Load()
+	defer func() { networkManager.Store(originalNetworkManager) }()
 
 	t.Run("capabilities without network manager", func(t *testing.T) {
-		networkManager = nil
+		networkManager.Store(nil)
 		caps := getCapabilities()
 		assert.Contains(t, caps.Capabilities, "plugins")
 		assert.NotContains(t, caps.Capabilities, "network")
 	})
 
 	t.Run("capabilities with network manager", func(t *testing.T) {
-		networkManager = &network.Manager{}
+		networkManager.Store(&network.Manager{})
 		caps := getCapabilities()
 		assert.Contains(t, caps.Capabilities, "plugins")
 		assert.Contains(t, caps.Capabilities, "network")
@@ -178,3 +180,66 @@ func TestCleanupStaleSockets(t *testing.T) {
 	_, err = os.Stat(activeSocket)
 	assert.NoError(t, err)
 }
+
+// useDaemonConfig points the server at a daemon.toml holding content for
+// the rest of the test
+func useDaemonConfig(t *testing.T, content string) (string, *daemonconfig.Config) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "daemon.toml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	cfg, err := daemonconfig.LoadFile(path)
+	require.NoError(t, err)
+
+	daemonConfigMutex.Lock()
+	previous := daemonConfig
+	daemonConfig = cfg
+	daemonConfigMutex.Unlock()
+	t.Cleanup(func() {
+		daemonConfigMutex.Lock()
+		daemonConfig = previous
+		daemonConfigMutex.Unlock()
+	})
+	return path, cfg
+}
+
+func TestPowerPolicyStore(t *testing.T) {
+	path, cfg := useDaemonConfig(t, "[power]\nac-profile = \"performance\"\n")
+
+	policy := powerPolicyConfig(cfg)
+	assert.False(t, policy.Enabled, "the policy stays off until the user opts in")
+	assert.Equal(t, "performance", policy.ACProfile)
+	assert.Equal(t, "power-saver", policy.BatteryProfile)
+
+	policy.BatteryBrightnessCap = 40
+	policy.BatteryRefreshRate = 59.94
+	require.NoError(t, powerPolicyStore{}.SavePolicy(policy))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "battery-brightness-cap = 40")
+	assert.Contains(t, string(data), "battery-refresh-rate = 60")
+	assert.NotContains(t, string(data), "battery-profile", "unchanged options stay out of the file")
+
+	reloaded, err := daemonconfig.LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 40, reloaded.Int("power.battery-brightness-cap"))
+	assert.Equal(t, 40, getDaemonConfig().Int("power.battery-brightness-cap"))
+}
+
+func TestNotificationsStore(t *testing.T) {
+	path, cfg := useDaemonConfig(t, "[notifications]\ncritical-bell = true\n")
+
+	config := notificationsConfig(cfg)
+	assert.Equal(t, notifications.ForwardTarget{Bell: true}, config.Targets[notifications.UrgencyCritical])
+	assert.Equal(t, notifications.ForwardTarget{}, config.Targets[notifications.UrgencyLow])
+
+	require.NoError(t, notificationsStore{}.SaveTarget(notifications.UrgencyLow, notifications.ForwardTarget{File: "/tmp/low.jsonl", Terminal: true}))
+	require.NoError(t, notificationsStore{}.SaveTarget(notifications.UrgencyCritical, notifications.ForwardTarget{}))
+
+	reloaded, err := daemonconfig.LoadFile(path)
+	require.NoError(t, err)
+	config = notificationsConfig(reloaded)
+	assert.Equal(t, notifications.ForwardTarget{File: "/tmp/low.jsonl", Terminal: true}, config.Targets[notifications.UrgencyLow])
+	assert.Equal(t, notifications.ForwardTarget{}, config.Targets[notifications.UrgencyCritical])
+	assert.Equal(t, "/tmp/low.jsonl", getDaemonConfig().String("notifications.low-file"))
+}
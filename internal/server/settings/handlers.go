@@ -0,0 +1,67 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/backup"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req models.Request) {
+	switch req.Method {
+	case "settings.export":
+		HandleExport(conn, req)
+	case "settings.import":
+		HandleImport(conn, req)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func HandleExport(conn net.Conn, req models.Request) {
+	dest, _ := req.Params["path"].(string)
+	if dest == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("failed to get home directory: %v", err))
+			return
+		}
+		dest = filepath.Join(homeDir, backup.DefaultArchiveName(time.Now()))
+	}
+	if !filepath.IsAbs(dest) {
+		models.RespondError(conn, req.ID, "'path' must be absolute")
+		return
+	}
+
+	manifest, err := backup.NewArchiver().CreateFile(dest)
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to export settings: %v", err))
+		return
+	}
+
+	models.Respond(conn, req.ID, ExportResult{Path: dest, Files: manifest.Files})
+}
+
+func HandleImport(conn net.Conn, req models.Request) {
+	src, ok := req.Params["path"].(string)
+	if !ok || src == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'path' parameter")
+		return
+	}
+	if !filepath.IsAbs(src) {
+		models.RespondError(conn, req.ID, "'path' must be absolute")
+		return
+	}
+
+	result, err := backup.NewArchiver().RestoreFile(src)
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to import settings: %v", err))
+		return
+	}
+
+	models.Respond(conn, req.ID, result)
+}
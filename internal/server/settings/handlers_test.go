@@ -0,0 +1,46 @@
+package settings
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/mocks/net"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func respondedError(t *testing.T, req models.Request) string {
+	conn := net.NewMockConn(t)
+	var written []byte
+	conn.EXPECT().Write(mock.Anything).RunAndReturn(func(b []byte) (int, error) {
+		written = b
+		return len(b), nil
+	}).Maybe()
+
+	HandleRequest(conn, req)
+
+	var resp models.Response[any]
+	assert.NoError(t, json.Unmarshal(written, &resp))
+	return resp.Error
+}
+
+func TestHandleImportMissingPath(t *testing.T) {
+	errMsg := respondedError(t, models.Request{ID: 1, Method: "settings.import", Params: map[string]interface{}{}})
+	assert.Contains(t, errMsg, "missing or invalid 'path' parameter")
+}
+
+func TestHandleExportRelativePath(t *testing.T) {
+	errMsg := respondedError(t, models.Request{ID: 1, Method: "settings.export", Params: map[string]interface{}{"path": "backup.tar.gz"}})
+	assert.Contains(t, errMsg, "must be absolute")
+}
+
+func TestHandleImportRelativePath(t *testing.T) {
+	errMsg := respondedError(t, models.Request{ID: 1, Method: "settings.import", Params: map[string]interface{}{"path": "backup.tar.gz"}})
+	assert.Contains(t, errMsg, "must be absolute")
+}
+
+func TestHandleUnknownMethod(t *testing.T) {
+	errMsg := respondedError(t, models.Request{ID: 1, Method: "settings.nope", Params: map[string]interface{}{}})
+	assert.Contains(t, errMsg, "unknown method")
+}
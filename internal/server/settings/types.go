@@ -0,0 +1,8 @@
+package settings
+
+import "github.com/AvengeMedia/danklinux/internal/backup"
+
+type ExportResult struct {
+	Path  string             `json:"path"`
+	Files []backup.FileEntry `json:"files"`
+}
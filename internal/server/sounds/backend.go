@@ -0,0 +1,134 @@
+package sounds
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// commandPlayer plays sounds through pw-play on PipeWire, or paplay on
+// PulseAudio. Either tags the stream with the event role, which the audio
+// module and the ducker leave alone.
+type commandPlayer struct {
+	binary string
+	args   func(path string, volume float64) []string
+}
+
+func newPlayer() (*commandPlayer, error) {
+	if _, err := exec.LookPath("pw-play"); err == nil {
+		return &commandPlayer{binary: "pw-play", args: func(path string, volume float64) []string {
+			return []string{"--media-role=event", fmt.Sprintf("--volume=%.2f", volume), path}
+		}}, nil
+	}
+	if _, err := exec.LookPath("paplay"); err == nil {
+		return &commandPlayer{binary: "paplay", args: func(path string, volume float64) []string {
+			return []string{"--property=media.role=event", fmt.Sprintf("--volume=%d", int(math.Round(volume*65536))), path}
+		}}, nil
+	}
+	return nil, fmt.Errorf("event sounds need pw-play (PipeWire) or paplay (PulseAudio)")
+}
+
+func (p *commandPlayer) name() string {
+	return p.binary
+}
+
+func (p *commandPlayer) play(path string, volume float64) error {
+	out, err := exec.Command(p.binary, p.args(path, volume)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", p.binary, path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// pactlDucker lowers sink inputs with pactl, which pipewire-pulse provides
+// as well
+type pactlDucker struct {
+	run func(args ...string) ([]byte, error)
+}
+
+func newDucker() *pactlDucker {
+	if _, err := exec.LookPath("pactl"); err != nil {
+		return nil
+	}
+	return &pactlDucker{run: func(args ...string) ([]byte, error) {
+		return exec.Command("pactl", args...).Output()
+	}}
+}
+
+type pactlSinkInput struct {
+	Index  uint32 `json:"index"`
+	Volume map[string]struct {
+		Value int `json:"value"`
+	} `json:"volume"`
+	Properties map[string]string `json:"properties"`
+}
+
+// duckTargets picks the streams to lower from `pactl -f json list
+// sink-inputs`, with their volumes as pactl percentages
+func duckTargets(data []byte) (map[uint32]int, error) {
+	var inputs []pactlSinkInput
+	if err := json.Unmarshal(data, &inputs); err != nil {
+		return nil, fmt.Errorf("failed to parse pactl sink inputs: %w", err)
+	}
+
+	targets := make(map[uint32]int, len(inputs))
+	for _, in := range inputs {
+		role := strings.ToLower(in.Properties["media.role"])
+		if role == "event" || role == "notification" || len(in.Volume) == 0 {
+			continue
+		}
+		total := 0
+		for _, ch := range in.Volume {
+			total += ch.Value
+		}
+		targets[in.Index] = int(math.Round(float64(total) / float64(len(in.Volume)) / 65536 * 100))
+	}
+	return targets, nil
+}
+
+func (d *pactlDucker) duck(level float64) (func(), error) {
+	data, err := d.run("-f", "json", "list", "sink-inputs")
+	if err != nil {
+		return nil, fmt.Errorf("pactl list sink-inputs: %w", err)
+	}
+	targets, err := duckTargets(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for id, percent := range targets {
+		lowered := int(math.Round(float64(percent) * level))
+		if _, err := d.run("set-sink-input-volume", strconv.FormatUint(uint64(id), 10), fmt.Sprintf("%d%%", lowered)); err != nil {
+			// The stream may have ended in between
+			log.Debugf("Failed to duck sink input %d: %v", id, err)
+			delete(targets, id)
+		}
+	}
+
+	return func() {
+		for id, percent := range targets {
+			if _, err := d.run("set-sink-input-volume", strconv.FormatUint(uint64(id), 10), fmt.Sprintf("%d%%", percent)); err != nil {
+				log.Debugf("Failed to restore sink input %d: %v", id, err)
+			}
+		}
+	}, nil
+}
+
+// runSpeech runs command with text as its last argument, never through a
+// shell
+func runSpeech(command []string, text string) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no speech command configured")
+	}
+	args := append(append([]string{}, command[1:]...), text)
+	out, err := exec.Command(command[0], args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", command[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
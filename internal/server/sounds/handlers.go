@@ -0,0 +1,141 @@
+package sounds
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "sounds.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "sounds.play":
+		handlePlay(conn, req, manager)
+	case "sounds.setEvent":
+		handleSetEvent(conn, req, manager)
+	case "sounds.setConfig":
+		handleSetConfig(conn, req, manager)
+	case "sounds.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handlePlay(conn net.Conn, req Request, manager *Manager) {
+	event, ok := req.Params["event"].(string)
+	if !ok || event == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'event' parameter")
+		return
+	}
+	opts := PlayOptions{Event: event}
+	opts.Text, _ = req.Params["text"].(string)
+	if volume, ok := req.Params["volume"].(float64); ok {
+		opts.Volume = &volume
+	}
+
+	result, err := manager.Play(opts)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
+func handleSetEvent(conn net.Conn, req Request, manager *Manager) {
+	name, ok := req.Params["event"].(string)
+	if !ok || name == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'event' parameter")
+		return
+	}
+
+	// Unset parameters keep the event's current settings
+	event, known := manager.GetState().Config.Events[name]
+	if !known {
+		event = EventConfig{Enabled: true, Volume: 1}
+	}
+	if v, ok := req.Params["enabled"].(bool); ok {
+		event.Enabled = v
+	}
+	if v, ok := req.Params["sound"].(string); ok {
+		event.Sound = v
+	}
+	if v, ok := req.Params["volume"].(float64); ok {
+		event.Volume = v
+	}
+	if v, ok := req.Params["duck"].(bool); ok {
+		event.Duck = v
+	}
+	if v, ok := req.Params["speak"].(bool); ok {
+		event.Speak = v
+	}
+
+	state, err := manager.SetEvent(name, event)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSetConfig(conn net.Conn, req Request, manager *Manager) {
+	var update ConfigUpdate
+	if v, ok := req.Params["theme"].(string); ok {
+		update.Theme = &v
+	}
+	if v, ok := req.Params["volume"].(float64); ok {
+		update.Volume = &v
+	}
+	if v, ok := req.Params["duckLevel"].(float64); ok {
+		update.DuckLevel = &v
+	}
+	if raw, ok := req.Params["speechCommand"]; ok {
+		args, ok := raw.([]interface{})
+		if !ok {
+			models.RespondError(conn, req.ID, "'speechCommand' must be a list of strings")
+			return
+		}
+		update.SpeechCommand = make([]string, 0, len(args))
+		for _, a := range args {
+			s, ok := a.(string)
+			if !ok {
+				models.RespondError(conn, req.ID, "'speechCommand' must be a list of strings")
+				return
+			}
+			update.SpeechCommand = append(update.SpeechCommand, s)
+		}
+	}
+
+	state, err := manager.SetConfig(update)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
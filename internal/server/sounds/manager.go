@@ -0,0 +1,337 @@
+package sounds
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// NewManager needs a player; ducking and speech are optional and skipped
+// when pactl or the speech command are missing
+func NewManager() (*Manager, error) {
+	p, err := newPlayer()
+	if err != nil {
+		return nil, err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+
+	var d ducker
+	if pd := newDucker(); pd != nil {
+		d = pd
+	}
+
+	m := newManager(filepath.Join(stateHome, "DankMaterialShell", "sounds.json"), p, d, runSpeech, themeFinder(soundDataDirs()), exec.LookPath)
+	m.load()
+	return m, nil
+}
+
+func newManager(configPath string, p player, d ducker, speak func([]string, string) error, find func(string, string) string, lookPath func(string) (string, error)) *Manager {
+	return &Manager{
+		configPath:  configPath,
+		player:      p,
+		ducker:      d,
+		speak:       speak,
+		findSound:   find,
+		lookPath:    lookPath,
+		config:      DefaultConfig(),
+		subscribers: make(map[string]chan State),
+	}
+}
+
+// load decodes the saved config over the defaults, so settings and events
+// added since it was written keep their default
+func (m *Manager) load() {
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return
+	}
+
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Warnf("Ignoring corrupt sounds file %s: %v", m.configPath, err)
+		return
+	}
+	if config.Theme == "" {
+		config.Theme = DefaultTheme
+	}
+	if config.Events == nil {
+		config.Events = make(map[string]EventConfig)
+	}
+
+	m.mutex.Lock()
+	m.config = config
+	m.mutex.Unlock()
+}
+
+// save must be called with the mutex held
+func (m *Manager) save() {
+	data, err := json.MarshalIndent(m.config, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.configPath), 0755); err != nil {
+		log.Warnf("Failed to create %s: %v", filepath.Dir(m.configPath), err)
+		return
+	}
+	tmp := m.configPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Warnf("Failed to save sounds: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, m.configPath); err != nil {
+		log.Warnf("Failed to save sounds: %v", err)
+	}
+}
+
+// stateLocked must be called with the mutex held
+func (m *Manager) stateLocked() State {
+	config := m.config
+	config.SpeechCommand = append([]string{}, m.config.SpeechCommand...)
+	config.Events = make(map[string]EventConfig, len(m.config.Events))
+	for name, event := range m.config.Events {
+		config.Events[name] = event
+	}
+
+	speech := false
+	if len(config.SpeechCommand) > 0 {
+		_, err := m.lookPath(config.SpeechCommand[0])
+		speech = err == nil
+	}
+
+	return State{
+		Config:  config,
+		Player:  m.player.name(),
+		Ducking: m.ducker != nil,
+		Speech:  speech,
+		Playing: m.playing,
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stateLocked()
+}
+
+func validVolume(name string, volume float64) error {
+	if volume < 0 || volume > 1 {
+		return fmt.Errorf("%s must be between 0 and 1", name)
+	}
+	return nil
+}
+
+// SetEvent replaces how an event sounds, adding it when new
+func (m *Manager) SetEvent(name string, event EventConfig) (State, error) {
+	if name == "" {
+		return State{}, fmt.Errorf("event name is empty")
+	}
+	if err := validVolume("volume", event.Volume); err != nil {
+		return State{}, err
+	}
+	if event.Sound != "" && !filepath.IsAbs(event.Sound) && filepath.Base(event.Sound) != event.Sound {
+		return State{}, fmt.Errorf("sound must be a theme sound name or an absolute path")
+	}
+
+	m.mutex.Lock()
+	m.config.Events[name] = event
+	m.save()
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	return state, nil
+}
+
+func (m *Manager) SetConfig(update ConfigUpdate) (State, error) {
+	if update.Volume != nil {
+		if err := validVolume("volume", *update.Volume); err != nil {
+			return State{}, err
+		}
+	}
+	if update.DuckLevel != nil {
+		if err := validVolume("duckLevel", *update.DuckLevel); err != nil {
+			return State{}, err
+		}
+	}
+	if update.Theme != nil && *update.Theme != "" && filepath.Base(*update.Theme) != *update.Theme {
+		return State{}, fmt.Errorf("theme must be a sound theme name")
+	}
+
+	m.mutex.Lock()
+	if update.Theme != nil {
+		m.config.Theme = *update.Theme
+		if m.config.Theme == "" {
+			m.config.Theme = DefaultTheme
+		}
+	}
+	if update.Volume != nil {
+		m.config.Volume = *update.Volume
+	}
+	if update.DuckLevel != nil {
+		m.config.DuckLevel = *update.DuckLevel
+	}
+	if update.SpeechCommand != nil {
+		m.config.SpeechCommand = append([]string{}, update.SpeechCommand...)
+	}
+	m.save()
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	return state, nil
+}
+
+// Play starts an event's sound, followed by speaking opts.Text when the
+// event has Speak set, and returns without waiting for either. Unknown
+// events are an error; events turned off are not.
+func (m *Manager) Play(opts PlayOptions) (PlayResult, error) {
+	if opts.Volume != nil {
+		if err := validVolume("volume", *opts.Volume); err != nil {
+			return PlayResult{}, err
+		}
+	}
+
+	m.mutex.Lock()
+	event, ok := m.config.Events[opts.Event]
+	config := m.config
+	speechCommand := append([]string{}, m.config.SpeechCommand...)
+	m.mutex.Unlock()
+
+	if !ok {
+		return PlayResult{}, fmt.Errorf("unknown event %q", opts.Event)
+	}
+	result := PlayResult{Event: opts.Event}
+	if !event.Enabled {
+		return result, nil
+	}
+
+	if event.Sound != "" {
+		result.Path = m.findSound(config.Theme, event.Sound)
+	}
+	result.Spoken = event.Speak && opts.Text != "" && len(speechCommand) > 0
+	if result.Path == "" && !result.Spoken {
+		return PlayResult{}, fmt.Errorf("no sound %q in theme %s", event.Sound, config.Theme)
+	}
+	result.Played = true
+
+	volume := event.Volume
+	if opts.Volume != nil {
+		volume = *opts.Volume
+	}
+	volume *= config.Volume
+
+	m.setPlaying(1)
+	go func() {
+		defer crash.Capture("sounds play", nil)
+		defer m.setPlaying(-1)
+
+		if event.Duck && m.ducker != nil {
+			m.duck(config.DuckLevel)
+			defer m.unduck()
+		}
+		if result.Path != "" {
+			if err := m.player.play(result.Path, volume); err != nil {
+				log.Warnf("Failed to play %s sound: %v", opts.Event, err)
+			}
+		}
+		if result.Spoken {
+			if err := m.speak(speechCommand, opts.Text); err != nil {
+				log.Warnf("Failed to speak %s event: %v", opts.Event, err)
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+func (m *Manager) setPlaying(delta int) {
+	m.mutex.Lock()
+	m.playing += delta
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+}
+
+// duck lowers other streams for the first of overlapping events only;
+// the others would otherwise duck already ducked volumes and restore them
+// to the lowered level
+func (m *Manager) duck(level float64) {
+	m.duckMutex.Lock()
+	defer m.duckMutex.Unlock()
+
+	m.duckCount++
+	if m.duckCount > 1 {
+		return
+	}
+	restore, err := m.ducker.duck(level)
+	if err != nil {
+		log.Debugf("Failed to duck playback: %v", err)
+		return
+	}
+	m.duckRestore = restore
+}
+
+// unduck restores the streams once the last overlapping event is done
+func (m *Manager) unduck() {
+	m.duckMutex.Lock()
+	defer m.duckMutex.Unlock()
+
+	m.duckCount--
+	if m.duckCount > 0 || m.duckRestore == nil {
+		return
+	}
+	m.duckRestore()
+	m.duckRestore = nil
+}
+
+func (m *Manager) broadcast(state State) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) Close() {
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,280 @@
+package sounds
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type played struct {
+	path   string
+	volume float64
+}
+
+type fakePlayer struct {
+	mu     sync.Mutex
+	played []played
+	// block holds play until closed
+	block chan struct{}
+}
+
+func (p *fakePlayer) name() string { return "fake" }
+
+func (p *fakePlayer) play(path string, volume float64) error {
+	if p.block != nil {
+		<-p.block
+	}
+	p.mu.Lock()
+	p.played = append(p.played, played{path, volume})
+	p.mu.Unlock()
+	return nil
+}
+
+type fakeDucker struct {
+	mu       sync.Mutex
+	ducks    []float64
+	restores int
+}
+
+func (d *fakeDucker) duck(level float64) (func(), error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ducks = append(d.ducks, level)
+	return func() {
+		d.mu.Lock()
+		d.restores++
+		d.mu.Unlock()
+	}, nil
+}
+
+func noCommand(string) (string, error) { return "", errors.New("not found") }
+
+func newTestManager(t *testing.T, p player, d ducker, speak func([]string, string) error) *Manager {
+	find := func(theme, name string) string { return "/sounds/" + theme + "/" + name + ".oga" }
+	if speak == nil {
+		speak = func([]string, string) error { return nil }
+	}
+	return newManager(filepath.Join(t.TempDir(), "sounds.json"), p, d, speak, find, noCommand)
+}
+
+// waitIdle waits for the playback goroutines to finish
+func waitIdle(t *testing.T, m *Manager) {
+	t.Helper()
+	require.Eventually(t, func() bool { return m.GetState().Playing == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestPlay(t *testing.T) {
+	p := &fakePlayer{}
+	m := newTestManager(t, p, nil, nil)
+
+	result, err := m.Play(PlayOptions{Event: EventNotification})
+	require.NoError(t, err)
+	assert.True(t, result.Played)
+	assert.Equal(t, "/sounds/freedesktop/message-new-instant.oga", result.Path)
+	waitIdle(t, m)
+	assert.Equal(t, []played{{"/sounds/freedesktop/message-new-instant.oga", 1}}, p.played)
+
+	_, err = m.Play(PlayOptions{Event: "nope"})
+	assert.Error(t, err)
+
+	// Charge sounds are off until turned on
+	result, err = m.Play(PlayOptions{Event: EventChargePlug})
+	require.NoError(t, err)
+	assert.False(t, result.Played)
+}
+
+func TestPlayVolume(t *testing.T) {
+	p := &fakePlayer{}
+	m := newTestManager(t, p, nil, nil)
+
+	half := 0.5
+	_, err := m.SetConfig(ConfigUpdate{Volume: &half})
+	require.NoError(t, err)
+	_, err = m.SetEvent(EventError, EventConfig{Enabled: true, Sound: "dialog-error", Volume: 0.8})
+	require.NoError(t, err)
+
+	_, err = m.Play(PlayOptions{Event: EventError})
+	require.NoError(t, err)
+	_, err = m.Play(PlayOptions{Event: EventError, Volume: &half})
+	require.NoError(t, err)
+	waitIdle(t, m)
+
+	require.Len(t, p.played, 2)
+	assert.ElementsMatch(t, []float64{0.4, 0.25}, []float64{p.played[0].volume, p.played[1].volume})
+
+	tooLoud := 2.0
+	_, err = m.Play(PlayOptions{Event: EventError, Volume: &tooLoud})
+	assert.Error(t, err)
+}
+
+func TestPlayDucksOnceForOverlappingEvents(t *testing.T) {
+	p := &fakePlayer{block: make(chan struct{})}
+	d := &fakeDucker{}
+	m := newTestManager(t, p, d, nil)
+
+	_, err := m.SetEvent(EventNotification, EventConfig{Enabled: true, Sound: "message", Volume: 1, Duck: true})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err := m.Play(PlayOptions{Event: EventNotification})
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.ducks) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, m.GetState().Playing)
+
+	close(p.block)
+	waitIdle(t, m)
+	assert.Equal(t, []float64{defaultDuckLevel}, d.ducks)
+	assert.Equal(t, 1, d.restores)
+}
+
+func TestPlaySpeaks(t *testing.T) {
+	var mu sync.Mutex
+	var spoken []string
+	speak := func(command []string, text string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		spoken = append(spoken, command[0]+": "+text)
+		return nil
+	}
+	p := &fakePlayer{}
+	m := newTestManager(t, p, nil, speak)
+
+	// Without speak set the text is ignored
+	result, err := m.Play(PlayOptions{Event: EventError, Text: "Disk full"})
+	require.NoError(t, err)
+	assert.False(t, result.Spoken)
+
+	_, err = m.SetEvent(EventError, EventConfig{Enabled: true, Volume: 1, Speak: true})
+	require.NoError(t, err)
+	result, err = m.Play(PlayOptions{Event: EventError, Text: "Disk full"})
+	require.NoError(t, err)
+	assert.True(t, result.Spoken)
+	assert.Empty(t, result.Path)
+	waitIdle(t, m)
+
+	assert.Equal(t, []string{"spd-say: Disk full"}, spoken)
+
+	// Nothing to play and nothing to say
+	_, err = m.Play(PlayOptions{Event: EventError})
+	assert.Error(t, err)
+}
+
+func TestConfigPersists(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sounds.json")
+	m := newManager(path, &fakePlayer{}, nil, nil, func(string, string) string { return "" }, noCommand)
+
+	theme := "ocean"
+	_, err := m.SetConfig(ConfigUpdate{Theme: &theme, SpeechCommand: []string{"espeak-ng"}})
+	require.NoError(t, err)
+	_, err = m.SetEvent("screenshot", EventConfig{Enabled: true, Sound: "camera-shutter", Volume: 0.7})
+	require.NoError(t, err)
+
+	reloaded := newManager(path, &fakePlayer{}, nil, nil, nil, noCommand)
+	reloaded.load()
+	config := reloaded.GetState().Config
+	assert.Equal(t, "ocean", config.Theme)
+	assert.Equal(t, []string{"espeak-ng"}, config.SpeechCommand)
+	assert.Equal(t, "camera-shutter", config.Events["screenshot"].Sound)
+	assert.Equal(t, "message-new-instant", config.Events[EventNotification].Sound)
+}
+
+func TestLoadKeepsDefaultsForMissingEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sounds.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"theme":"","volume":0.3,"events":{"error":{"enabled":false,"volume":1}}}`), 0644))
+
+	m := newManager(path, &fakePlayer{}, nil, nil, nil, noCommand)
+	m.load()
+	config := m.GetState().Config
+	assert.Equal(t, DefaultTheme, config.Theme)
+	assert.Equal(t, 0.3, config.Volume)
+	assert.Equal(t, defaultDuckLevel, config.DuckLevel)
+	assert.False(t, config.Events[EventError].Enabled)
+	assert.True(t, config.Events[EventNotification].Enabled)
+}
+
+func TestSetValidation(t *testing.T) {
+	m := newTestManager(t, &fakePlayer{}, nil, nil)
+
+	_, err := m.SetEvent("", EventConfig{Volume: 1})
+	assert.Error(t, err)
+	_, err = m.SetEvent("x", EventConfig{Volume: 1.5})
+	assert.Error(t, err)
+	_, err = m.SetEvent("x", EventConfig{Sound: "../etc/passwd", Volume: 1})
+	assert.Error(t, err)
+
+	level := -0.1
+	_, err = m.SetConfig(ConfigUpdate{DuckLevel: &level})
+	assert.Error(t, err)
+	theme := "a/b"
+	_, err = m.SetConfig(ConfigUpdate{Theme: &theme})
+	assert.Error(t, err)
+}
+
+func writeSound(t *testing.T, path string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+}
+
+func TestFindSound(t *testing.T) {
+	user := t.TempDir()
+	system := t.TempDir()
+	dirs := []string{user, system}
+
+	writeSound(t, filepath.Join(system, "freedesktop", "stereo", "message.oga"))
+	writeSound(t, filepath.Join(system, "freedesktop", "stereo", "dialog-error.oga"))
+	writeSound(t, filepath.Join(system, "base", "stereo", "battery-low.wav"))
+	writeSound(t, filepath.Join(user, "ocean", "power-plug.ogg"))
+	writeSound(t, filepath.Join(system, "ocean", "stereo", "dialog-error.ogg"))
+	require.NoError(t, os.WriteFile(filepath.Join(system, "ocean", "index.theme"), []byte("[Sound Theme]\nName=Ocean\nInherits=base\n"), 0644))
+
+	// The theme wins over freedesktop, the user's copy over the system's
+	assert.Equal(t, filepath.Join(system, "ocean", "stereo", "dialog-error.ogg"), findSound(dirs, "ocean", "dialog-error"))
+	assert.Equal(t, filepath.Join(user, "ocean", "power-plug.ogg"), findSound(dirs, "ocean", "power-plug"))
+	// Shorter names are tried once no theme has the full one
+	assert.Equal(t, filepath.Join(system, "freedesktop", "stereo", "message.oga"), findSound(dirs, "ocean", "message-new-instant"))
+	// Inherited themes are searched
+	assert.Equal(t, filepath.Join(system, "base", "stereo", "battery-low.wav"), findSound(dirs, "ocean", "battery-low"))
+	assert.Equal(t, "", findSound(dirs, "", "battery-low"))
+
+	abs := filepath.Join(system, "freedesktop", "stereo", "message.oga")
+	assert.Equal(t, abs, findSound(dirs, "ocean", abs))
+	assert.Equal(t, "", findSound(dirs, "ocean", filepath.Join(system, "missing.oga")))
+}
+
+func TestThemeChainCutsCycles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a", "index.theme"), []byte("Inherits=b, freedesktop\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "b"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b", "index.theme"), []byte("Inherits=a\n"), 0644))
+
+	assert.Equal(t, []string{"a", "b", "freedesktop"}, themeChain([]string{dir}, "a"))
+}
+
+func TestDuckTargets(t *testing.T) {
+	data := []byte(`[
+		{"index":7,"volume":{"front-left":{"value":65536},"front-right":{"value":32768}},"properties":{"application.name":"mpv"}},
+		{"index":9,"volume":{"mono":{"value":65536}},"properties":{"media.role":"event"}},
+		{"index":11,"volume":{"mono":{"value":39322}},"properties":{"media.role":"Notification"}},
+		{"index":12,"volume":{"mono":{"value":19661}},"properties":{}}
+	]`)
+	targets, err := duckTargets(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[uint32]int{7: 75, 12: 30}, targets)
+
+	_, err = duckTargets([]byte("nope"))
+	assert.Error(t, err)
+}
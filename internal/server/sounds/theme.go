@@ -0,0 +1,136 @@
+package sounds
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// soundExtensions are the formats the sound theme spec allows, in its
+// order of preference
+var soundExtensions = []string{".oga", ".ogg", ".wav"}
+
+// soundDataDirs are where sound themes are installed, most specific first
+func soundDataDirs() []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+
+	var dirs []string
+	if dataHome != "" {
+		dirs = append(dirs, filepath.Join(dataHome, "sounds"))
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "sounds"))
+		}
+	}
+	return dirs
+}
+
+// themeFinder looks sounds up in the theme directories under dirs
+func themeFinder(dirs []string) func(theme, name string) string {
+	return func(theme, name string) string {
+		return findSound(dirs, theme, name)
+	}
+}
+
+// findSound resolves a sound name the way the XDG sound theme spec does:
+// through the theme and the themes it inherits, then freedesktop, trying
+// ever shorter names, so message-new-instant falls back to message-new
+// and then to message. Absolute paths are taken as they are.
+func findSound(dirs []string, theme, name string) string {
+	if filepath.IsAbs(name) {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+		return ""
+	}
+	if theme == "" {
+		theme = DefaultTheme
+	}
+
+	chain := themeChain(dirs, theme)
+	for candidate := name; candidate != ""; {
+		for _, t := range chain {
+			if path := findInTheme(dirs, t, candidate); path != "" {
+				return path
+			}
+		}
+		i := strings.LastIndex(candidate, "-")
+		if i < 0 {
+			break
+		}
+		candidate = candidate[:i]
+	}
+	return ""
+}
+
+// themeChain is theme followed by what it inherits, depth first, ending
+// with freedesktop. Cycles are cut.
+func themeChain(dirs []string, theme string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	var walk func(string)
+	walk = func(t string) {
+		if t == "" || seen[t] {
+			return
+		}
+		seen[t] = true
+		chain = append(chain, t)
+		for _, parent := range themeInherits(dirs, t) {
+			walk(parent)
+		}
+	}
+	walk(theme)
+	walk(DefaultTheme)
+	return chain
+}
+
+// themeInherits reads Inherits from the first index.theme of theme found
+func themeInherits(dirs []string, theme string) []string {
+	for _, dir := range dirs {
+		data, err := os.ReadFile(filepath.Join(dir, theme, "index.theme"))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok || strings.TrimSpace(key) != "Inherits" {
+				continue
+			}
+			var parents []string
+			for _, p := range strings.Split(value, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					parents = append(parents, p)
+				}
+			}
+			return parents
+		}
+		return nil
+	}
+	return nil
+}
+
+// findInTheme prefers the stereo variant, which is all most themes ship,
+// and also accepts files at the top of the theme
+func findInTheme(dirs []string, theme, name string) string {
+	for _, dir := range dirs {
+		for _, sub := range []string{"stereo", ""} {
+			for _, ext := range soundExtensions {
+				path := filepath.Join(dir, theme, sub, name+ext)
+				if _, err := os.Stat(path); err == nil {
+					return path
+				}
+			}
+		}
+	}
+	return ""
+}
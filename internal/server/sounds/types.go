@@ -0,0 +1,139 @@
+package sounds
+
+import "sync"
+
+// Events the shell and the daemon play sounds for. Others may be added to
+// the config; they have no default sound.
+const (
+	EventNotification = "notification"
+	EventChargePlug   = "charge-plug"
+	EventChargeUnplug = "charge-unplug"
+	EventError        = "error"
+)
+
+// DefaultTheme is the XDG sound theme every other theme falls back to
+const DefaultTheme = "freedesktop"
+
+// defaultDuckLevel is what other streams are lowered to while an event
+// with Duck set plays, 1 being their own volume
+const defaultDuckLevel = 0.4
+
+// defaultSpeechCommand speaks the text given as its last argument and
+// returns once done, so ducking lasts as long as the speech
+var defaultSpeechCommand = []string{"spd-say", "--wait"}
+
+// EventConfig is how one event sounds. Sound is a name looked up in the
+// sound theme, such as message-new-instant, or an absolute file path.
+type EventConfig struct {
+	Enabled bool    `json:"enabled"`
+	Sound   string  `json:"sound,omitempty"`
+	Volume  float64 `json:"volume"`
+	// Duck lowers other playback while the event plays
+	Duck bool `json:"duck,omitempty"`
+	// Speak reads the text sounds.play was given after the sound
+	Speak bool `json:"speak,omitempty"`
+}
+
+// Config is what the user set. Volume scales every event's own volume.
+type Config struct {
+	Theme         string                 `json:"theme"`
+	Volume        float64                `json:"volume"`
+	DuckLevel     float64                `json:"duckLevel"`
+	SpeechCommand []string               `json:"speechCommand,omitempty"`
+	Events        map[string]EventConfig `json:"events"`
+}
+
+// DefaultConfig plays notifications and errors. The charge events are
+// played by the daemon itself on power changes, so they start off.
+func DefaultConfig() Config {
+	return Config{
+		Theme:         DefaultTheme,
+		Volume:        1,
+		DuckLevel:     defaultDuckLevel,
+		SpeechCommand: append([]string{}, defaultSpeechCommand...),
+		Events: map[string]EventConfig{
+			EventNotification: {Enabled: true, Sound: "message-new-instant", Volume: 1},
+			EventChargePlug:   {Enabled: false, Sound: "power-plug", Volume: 1},
+			EventChargeUnplug: {Enabled: false, Sound: "power-unplug", Volume: 1},
+			EventError:        {Enabled: true, Sound: "dialog-error", Volume: 1},
+		},
+	}
+}
+
+type State struct {
+	Config Config `json:"config"`
+	// Player is the program sounds are played with
+	Player string `json:"player"`
+	// Ducking and Speech tell whether pactl and the speech command were
+	// found; events asking for them play without otherwise
+	Ducking bool `json:"ducking"`
+	Speech  bool `json:"speech"`
+	// Playing counts the events playing right now
+	Playing int `json:"playing"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// PlayOptions are the parameters of sounds.play. Volume overrides the
+// event's own volume when set.
+type PlayOptions struct {
+	Event  string
+	Text   string
+	Volume *float64
+}
+
+// ConfigUpdate changes the settings shared by all events; nil fields are
+// kept
+type ConfigUpdate struct {
+	Theme         *string
+	Volume        *float64
+	DuckLevel     *float64
+	SpeechCommand []string
+}
+
+// PlayResult tells what sounds.play started. Played is false when the
+// event is turned off; Path is empty when no sound was found and only
+// speech runs.
+type PlayResult struct {
+	Event  string `json:"event"`
+	Played bool   `json:"played"`
+	Path   string `json:"path,omitempty"`
+	Spoken bool   `json:"spoken"`
+}
+
+// player plays a sound file at volume, 1 being 100%, and returns once done
+type player interface {
+	name() string
+	play(path string, volume float64) error
+}
+
+// ducker lowers every other playback stream to level times its volume.
+// restore puts them back.
+type ducker interface {
+	duck(level float64) (restore func(), err error)
+}
+
+type Manager struct {
+	configPath string
+	player     player
+	ducker     ducker
+	// speak runs the speech command with text appended
+	speak     func(command []string, text string) error
+	findSound func(theme, name string) string
+	lookPath  func(file string) (string, error)
+
+	mutex   sync.Mutex
+	config  Config
+	playing int
+
+	duckMutex   sync.Mutex
+	duckCount   int
+	duckRestore func()
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+}
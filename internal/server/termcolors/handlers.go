@@ -0,0 +1,55 @@
+package termcolors
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "termcolors.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "termcolors.apply":
+		handleApply(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleApply(conn net.Conn, req Request, manager *Manager) {
+	palette, err := PaletteFromParams(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	result, err := manager.Broadcast(palette)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
+// PaletteFromParams reads the palette of a termcolors.apply request
+func PaletteFromParams(params map[string]interface{}) (Palette, error) {
+	rawColors, ok := params["colors"].([]interface{})
+	if !ok {
+		return Palette{}, fmt.Errorf("missing or invalid 'colors' parameter")
+	}
+
+	var palette Palette
+	for _, c := range rawColors {
+		s, ok := c.(string)
+		if !ok {
+			return Palette{}, fmt.Errorf("'colors' must be a list of hex colors")
+		}
+		palette.Colors = append(palette.Colors, s)
+	}
+	palette.Foreground, _ = params["foreground"].(string)
+	palette.Background, _ = params["background"].(string)
+	palette.Cursor, _ = params["cursor"].(string)
+	return palette, nil
+}
@@ -0,0 +1,114 @@
+package termcolors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// NewManager recolours nothing until SetTerminals allows some terminals
+func NewManager() *Manager {
+	return &Manager{
+		procRoot: "/proc",
+		uid:      os.Getuid(),
+		owner:    ttyOwner,
+		write:    writeTTY,
+	}
+}
+
+// SetTerminals sets the allowlist from a comma-separated list of terminal
+// names such as "foot,alacritty"
+func (m *Manager) SetTerminals(list string) {
+	var terminals []string
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			terminals = append(terminals, t)
+		}
+	}
+
+	m.mu.Lock()
+	m.terminals = terminals
+	m.mu.Unlock()
+}
+
+func (m *Manager) allowed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.terminals...)
+}
+
+func (m *Manager) GetState() State {
+	allowed := m.allowed()
+	state := State{
+		Terminals: allowed,
+		Targets:   m.findTargets(allowed),
+	}
+	if state.Terminals == nil {
+		state.Terminals = []string{}
+	}
+	if state.Targets == nil {
+		state.Targets = []Target{}
+	}
+
+	m.mu.Lock()
+	state.Last = m.last
+	m.mu.Unlock()
+	return state
+}
+
+// Broadcast writes the palette's OSC sequences to every shell running in an
+// allowed terminal. A tty that cannot be written is reported, not fatal.
+func (m *Manager) Broadcast(p Palette) (BroadcastResult, error) {
+	data, err := Sequences(p)
+	if err != nil {
+		return BroadcastResult{}, err
+	}
+
+	allowed := m.allowed()
+	if len(allowed) == 0 {
+		return BroadcastResult{}, fmt.Errorf("no terminals allowed; set termcolors.terminals in daemon.toml")
+	}
+
+	result := BroadcastResult{Written: []Target{}}
+	for _, target := range m.findTargets(allowed) {
+		if err := m.write(target.TTY, []byte(data)); err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", target.TTY, err))
+			continue
+		}
+		result.Written = append(result.Written, target)
+	}
+
+	m.mu.Lock()
+	m.last = &result
+	m.mu.Unlock()
+
+	log.Infof("Recoloured %d terminals (%d failed)", len(result.Written), len(result.Failed))
+	return result, nil
+}
+
+func ttyOwner(tty string) (int, error) {
+	info, err := os.Stat(tty)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("cannot read owner of %s", tty)
+	}
+	return int(st.Uid), nil
+}
+
+// writeTTY never blocks on a terminal that stopped reading and never makes
+// the tty the daemon's controlling terminal
+func writeTTY(tty string, data []byte) error {
+	f, err := os.OpenFile(tty, os.O_WRONLY|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	return err
+}
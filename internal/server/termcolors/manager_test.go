@@ -0,0 +1,109 @@
+package termcolors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProc struct {
+	root string
+	t    *testing.T
+}
+
+func (f fakeProc) add(pid, ppid, uid int, name string, tty string) {
+	dir := filepath.Join(f.root, strconv.Itoa(pid))
+	require.NoError(f.t, os.MkdirAll(filepath.Join(dir, "fd"), 0755))
+	status := fmt.Sprintf("Name:\t%s\nUmask:\t0022\nPPid:\t%d\nUid:\t%d\t%d\t%d\t%d\n", name, ppid, uid, uid, uid, uid)
+	require.NoError(f.t, os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0644))
+	if tty != "" {
+		require.NoError(f.t, os.Symlink(tty, filepath.Join(dir, "fd", "0")))
+	}
+}
+
+func newTestManager(t *testing.T) (*Manager, fakeProc, map[string][]byte) {
+	proc := fakeProc{root: t.TempDir(), t: t}
+	written := make(map[string][]byte)
+	m := &Manager{
+		procRoot: proc.root,
+		uid:      1000,
+		owner: func(tty string) (int, error) {
+			if tty == "/dev/pts/9" {
+				return 0, nil
+			}
+			return 1000, nil
+		},
+		write: func(tty string, data []byte) error {
+			if tty == "/dev/pts/5" {
+				return fmt.Errorf("resource temporarily unavailable")
+			}
+			written[tty] = data
+			return nil
+		},
+	}
+
+	proc.add(100, 1, 1000, "foot", "")
+	proc.add(101, 100, 1000, "zsh", "/dev/pts/1")
+	proc.add(102, 101, 1000, "nvim", "/dev/pts/1")
+	proc.add(103, 100, 1000, "fish", "/dev/pts/2")
+	proc.add(200, 1, 1000, "gnome-terminal-", "")
+	proc.add(201, 200, 1000, "bash", "/dev/pts/3")
+	proc.add(300, 1, 1000, "alacritty", "")
+	proc.add(301, 300, 1000, "bash", "/dev/pts/4")
+	proc.add(302, 300, 1000, "bash", "/dev/pts/5")
+	proc.add(400, 1, 0, "foot", "")
+	proc.add(401, 400, 0, "bash", "/dev/pts/8")
+	proc.add(500, 1, 1000, "foot", "")
+	proc.add(501, 500, 1000, "su", "/dev/pts/9")
+	return m, proc, written
+}
+
+func TestFindTargets(t *testing.T) {
+	m, _, _ := newTestManager(t)
+
+	assert.Empty(t, m.findTargets(nil))
+
+	targets := m.findTargets([]string{"foot", "gnome-terminal"})
+	assert.Equal(t, []Target{
+		{TTY: "/dev/pts/1", Terminal: "foot", PID: 101},
+		{TTY: "/dev/pts/2", Terminal: "foot", PID: 103},
+		{TTY: "/dev/pts/3", Terminal: "gnome-terminal", PID: 201},
+	}, targets, "other users' terminals and ttys are skipped")
+}
+
+func TestBroadcast(t *testing.T) {
+	m, _, written := newTestManager(t)
+
+	_, err := m.Broadcast(testPalette())
+	assert.ErrorContains(t, err, "no terminals allowed")
+
+	m.SetTerminals("alacritty, ")
+	result, err := m.Broadcast(testPalette())
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{TTY: "/dev/pts/4", Terminal: "alacritty", PID: 301}}, result.Written)
+	require.Len(t, result.Failed, 1)
+	assert.Contains(t, result.Failed[0], "/dev/pts/5")
+
+	seq, _ := Sequences(testPalette())
+	assert.Equal(t, []byte(seq), written["/dev/pts/4"])
+
+	state := m.GetState()
+	assert.Equal(t, []string{"alacritty"}, state.Terminals)
+	assert.Len(t, state.Targets, 2)
+	require.NotNil(t, state.Last)
+	assert.Equal(t, result.Written, state.Last.Written)
+}
+
+func TestBroadcast_InvalidPalette(t *testing.T) {
+	m, _, written := newTestManager(t)
+	m.SetTerminals("foot")
+
+	_, err := m.Broadcast(Palette{Colors: []string{"#000000"}})
+	assert.Error(t, err)
+	assert.Empty(t, written)
+}
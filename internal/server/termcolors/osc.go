@@ -0,0 +1,56 @@
+package termcolors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var hexPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func (p Palette) validate() error {
+	if len(p.Colors) != 16 {
+		return fmt.Errorf("expected 16 colors, got %d", len(p.Colors))
+	}
+	for i, c := range p.Colors {
+		if !hexPattern.MatchString(c) {
+			return fmt.Errorf("color %d: invalid hex color %q", i, c)
+		}
+	}
+	for name, c := range map[string]string{"foreground": p.Foreground, "background": p.Background, "cursor": p.Cursor} {
+		if c != "" && !hexPattern.MatchString(c) {
+			return fmt.Errorf("%s: invalid hex color %q", name, c)
+		}
+	}
+	return nil
+}
+
+// xcolor converts #rrggbb to the rgb:rr/gg/bb form every xterm-compatible
+// terminal parses, unlike the #-prefixed form
+func xcolor(hex string) string {
+	h := strings.ToLower(hex[1:])
+	return fmt.Sprintf("rgb:%s/%s/%s", h[0:2], h[2:4], h[4:6])
+}
+
+// Sequences renders the palette as OSC 4 (indexed colors), OSC 10
+// (foreground), OSC 11 (background) and OSC 12 (cursor), each terminated
+// by ST
+func Sequences(p Palette) (string, error) {
+	if err := p.validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, c := range p.Colors {
+		fmt.Fprintf(&b, "\x1b]4;%d;%s\x1b\\", i, xcolor(c))
+	}
+	for _, seq := range []struct {
+		code  int
+		color string
+	}{{10, p.Foreground}, {11, p.Background}, {12, p.Cursor}} {
+		if seq.color != "" {
+			fmt.Fprintf(&b, "\x1b]%d;%s\x1b\\", seq.code, xcolor(seq.color))
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,45 @@
+package termcolors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPalette() Palette {
+	colors := make([]string, 16)
+	for i := range colors {
+		colors[i] = "#1A2b3c"
+	}
+	return Palette{Colors: colors, Foreground: "#ffffff", Background: "#000000"}
+}
+
+func TestSequences(t *testing.T) {
+	seq, err := Sequences(testPalette())
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(seq, "\x1b]4;0;rgb:1a/2b/3c\x1b\\"))
+	assert.Contains(t, seq, "\x1b]4;15;rgb:1a/2b/3c\x1b\\")
+	assert.Contains(t, seq, "\x1b]10;rgb:ff/ff/ff\x1b\\")
+	assert.True(t, strings.HasSuffix(seq, "\x1b]11;rgb:00/00/00\x1b\\"))
+	assert.NotContains(t, seq, "\x1b]12;", "an unset cursor is left alone")
+}
+
+func TestSequences_Invalid(t *testing.T) {
+	p := testPalette()
+	p.Colors = p.Colors[:8]
+	_, err := Sequences(p)
+	assert.ErrorContains(t, err, "expected 16 colors")
+
+	p = testPalette()
+	p.Colors[3] = "red"
+	_, err = Sequences(p)
+	assert.ErrorContains(t, err, "color 3")
+
+	p = testPalette()
+	p.Cursor = "#fff"
+	_, err = Sequences(p)
+	assert.ErrorContains(t, err, "cursor")
+}
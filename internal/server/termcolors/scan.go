@@ -0,0 +1,151 @@
+package termcolors
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// terminalProcesses maps allowlist names to the process names the terminal
+// runs as, where they differ. The kernel cuts names to 15 characters.
+var terminalProcesses = map[string][]string{
+	"gnome-terminal": {"gnome-terminal-"},
+	"wezterm":        {"wezterm-gui"},
+	"kitty":          {"kitty"},
+	"foot":           {"foot"},
+	"ptyxis":         {"ptyxis-agent"},
+	"xfce4-terminal": {"xfce4-terminal"},
+}
+
+type process struct {
+	pid  int
+	ppid int
+	uid  int
+	name string
+}
+
+// readStatus reads the Name, PPid and real Uid lines of /proc/<pid>/status
+func readStatus(path string) (process, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return process{}, false
+	}
+	defer f.Close()
+
+	p := process{uid: -1}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Name":
+			p.name = value
+		case "PPid":
+			p.ppid, _ = strconv.Atoi(value)
+		case "Uid":
+			if fields := strings.Fields(value); len(fields) > 0 {
+				p.uid, _ = strconv.Atoi(fields[0])
+			}
+		}
+	}
+	return p, p.uid >= 0
+}
+
+func listProcesses(procRoot string) []process {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil
+	}
+
+	var procs []process
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		p, ok := readStatus(filepath.Join(procRoot, e.Name(), "status"))
+		if !ok {
+			continue
+		}
+		p.pid = pid
+		procs = append(procs, p)
+	}
+	return procs
+}
+
+// processTerminal returns the allowlist entry a process name belongs to
+func processTerminal(name string, allowed []string) string {
+	for _, terminal := range allowed {
+		if name == terminal {
+			return terminal
+		}
+		for _, alias := range terminalProcesses[terminal] {
+			if name == alias {
+				return terminal
+			}
+		}
+	}
+	return ""
+}
+
+// findTargets lists the pseudo-terminals of the user's shells whose parent
+// is an allowed terminal emulator. Only direct children count: anything
+// deeper shares its shell's tty, or runs in a multiplexer that keeps its
+// own palette.
+func (m *Manager) findTargets(allowed []string) []Target {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	procs := listProcesses(m.procRoot)
+	terminals := make(map[int]string)
+	for _, p := range procs {
+		if p.uid != m.uid {
+			continue
+		}
+		if terminal := processTerminal(p.name, allowed); terminal != "" {
+			terminals[p.pid] = terminal
+		}
+	}
+
+	seen := make(map[string]bool)
+	var targets []Target
+	for _, p := range procs {
+		terminal, ok := terminals[p.ppid]
+		if !ok || p.uid != m.uid {
+			continue
+		}
+		tty := m.processTTY(p.pid)
+		if tty == "" || seen[tty] {
+			continue
+		}
+		if owner, err := m.owner(tty); err != nil || owner != m.uid {
+			continue
+		}
+		seen[tty] = true
+		targets = append(targets, Target{TTY: tty, Terminal: terminal, PID: p.pid})
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].TTY < targets[j].TTY })
+	return targets
+}
+
+// processTTY is the /dev/pts device on one of the standard descriptors
+func (m *Manager) processTTY(pid int) string {
+	for _, fd := range []string{"0", "1", "2"} {
+		link, err := os.Readlink(filepath.Join(m.procRoot, strconv.Itoa(pid), "fd", fd))
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(link, "/dev/pts/") && link != "/dev/pts/ptmx" {
+			return link
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package termcolors
+
+import "sync"
+
+// Palette is what gets sent to terminals: the 16 ANSI colors plus the
+// default foreground, background and cursor as #rrggbb. Empty defaults are
+// left as the terminal has them.
+type Palette struct {
+	Colors     []string `json:"colors"`
+	Foreground string   `json:"foreground,omitempty"`
+	Background string   `json:"background,omitempty"`
+	Cursor     string   `json:"cursor,omitempty"`
+}
+
+// Target is a shell's pseudo-terminal inside an allowed terminal emulator
+type Target struct {
+	TTY      string `json:"tty"`
+	Terminal string `json:"terminal"`
+	PID      int    `json:"pid"`
+}
+
+type BroadcastResult struct {
+	Written []Target `json:"written"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+type State struct {
+	// Terminals is the allowlist from termcolors.terminals in daemon.toml;
+	// nothing is recoloured while it is empty
+	Terminals []string         `json:"terminals"`
+	Targets   []Target         `json:"targets"`
+	Last      *BroadcastResult `json:"last,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type Manager struct {
+	procRoot string
+	uid      int
+	// owner reports the uid owning a tty, write sends it the sequences
+	owner func(tty string) (int, error)
+	write func(tty string, data []byte) error
+
+	mu        sync.Mutex
+	terminals []string
+	last      *BroadcastResult
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/server/network"
+)
+
+// bluezPAN hands the network manager the phones bluez can tether through.
+// bluezManager is looked up on every call since the two managers start
+// independently and either may come up later or not at all.
+type bluezPAN struct{}
+
+func (bluezPAN) PANDevices() ([]network.PANDevice, error) {
+	bm := bluezManager.Load()
+	if bm == nil {
+		return nil, nil
+	}
+	devices, err := bm.PANDevices()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]network.PANDevice, 0, len(devices))
+	for _, d := range devices {
+		result = append(result, network.PANDevice{
+			Address:   d.Address,
+			Name:      d.Name,
+			Connected: d.Connected,
+			Interface: d.Interface,
+		})
+	}
+	return result, nil
+}
+
+func (bluezPAN) ConnectPAN(address string) (string, error) {
+	bm := bluezManager.Load()
+	if bm == nil {
+		return "", fmt.Errorf("bluetooth manager not initialized")
+	}
+	return bm.ConnectPAN(address)
+}
+
+func (bluezPAN) DisconnectPAN(address string) error {
+	bm := bluezManager.Load()
+	if bm == nil {
+		return fmt.Errorf("bluetooth manager not initialized")
+	}
+	return bm.DisconnectPAN(address)
+}
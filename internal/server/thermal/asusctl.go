@@ -0,0 +1,93 @@
+package thermal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// asusProfiles are the names asusd uses, in the order they are shown
+var asusProfiles = []string{"quiet", "balanced", "performance"}
+
+// asusctlBackend talks to asusd through its CLI, which needs no root
+type asusctlBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newAsusctlBackend() *asusctlBackend {
+	return &asusctlBackend{run: runCommand}
+}
+
+func (a *asusctlBackend) name() string { return "asusctl" }
+
+func (a *asusctlBackend) profiles() ([]string, error) {
+	out, err := a.run("asusctl", "profile", "-l")
+	if err != nil {
+		return nil, err
+	}
+	profiles := parseAsusProfiles(string(out))
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("asusctl listed no profiles")
+	}
+	return profiles, nil
+}
+
+// parseAsusProfiles accepts both the one-per-line listing and the older
+// bracketed form, keeping the known names in their usual order
+func parseAsusProfiles(out string) []string {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(out), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	}) {
+		words[w] = true
+	}
+
+	var profiles []string
+	for _, p := range asusProfiles {
+		if words[p] {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
+}
+
+func (a *asusctlBackend) active() (string, error) {
+	out, err := a.run("asusctl", "profile", "-p")
+	if err != nil {
+		return "", err
+	}
+	return parseAsusActive(string(out))
+}
+
+func parseAsusActive(out string) (string, error) {
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.Contains(strings.ToLower(line), "active profile") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			return strings.ToLower(fields[len(fields)-1]), nil
+		}
+	}
+	return "", fmt.Errorf("unexpected asusctl output: %q", strings.TrimSpace(out))
+}
+
+func (a *asusctlBackend) set(profile string) error {
+	_, err := a.run("asusctl", "profile", "-P", titleCase(profile))
+	return err
+}
+
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func runCommand(name string, args ...string) ([]byte, error) {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
@@ -0,0 +1,72 @@
+package thermal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAsusProfiles(t *testing.T) {
+	assert.Equal(t, []string{"quiet", "balanced", "performance"}, parseAsusProfiles("Quiet\nBalanced\nPerformance\n"))
+	assert.Equal(t, []string{"quiet", "balanced", "performance"}, parseAsusProfiles("Available profiles are [Balanced, Performance, Quiet]\n"))
+	assert.Empty(t, parseAsusProfiles("Error: asusd not running\n"))
+}
+
+func TestParseAsusActive(t *testing.T) {
+	active, err := parseAsusActive("Starting version 6.0.12\nActive profile is Performance\n")
+	require.NoError(t, err)
+	assert.Equal(t, "performance", active)
+
+	_, err = parseAsusActive("garbage")
+	assert.Error(t, err)
+}
+
+func TestAsusctlSetUsesAsusdNames(t *testing.T) {
+	var calls []string
+	b := &asusctlBackend{run: func(name string, args ...string) ([]byte, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		return nil, nil
+	}}
+
+	require.NoError(t, b.set("quiet"))
+	assert.Equal(t, []string{"asusctl profile -P Quiet"}, calls)
+}
+
+func TestParseTLPMode(t *testing.T) {
+	mode, err := parseTLPMode("power-saver/BAT\n")
+	require.NoError(t, err)
+	assert.Equal(t, "power-saver", mode)
+
+	_, err = parseTLPMode("AC\n")
+	assert.Error(t, err)
+}
+
+func TestSysfsBackend(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "platform_profile_choices"), []byte("low-power balanced performance\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "platform_profile"), []byte("balanced\n"), 0644))
+
+	b := &sysfsBackend{root: root, privileged: func(string, string) error {
+		return fmt.Errorf("unexpected privileged write")
+	}}
+	assert.True(t, b.available())
+
+	profiles, err := b.profiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"low-power", "balanced", "performance"}, profiles)
+
+	require.NoError(t, b.set("performance"))
+	active, err := b.active()
+	require.NoError(t, err)
+	assert.Equal(t, "performance", active)
+}
+
+func TestSysfsBackendMissing(t *testing.T) {
+	b := &sysfsBackend{root: t.TempDir()}
+	assert.False(t, b.available())
+}
@@ -0,0 +1,43 @@
+package thermal
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "thermal.getState", "thermal.listProfiles":
+		handleGetState(conn, req, manager)
+	case "thermal.setProfile":
+		handleSetProfile(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req Request, manager *Manager) {
+	state, err := manager.GetState()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSetProfile(conn net.Conn, req Request, manager *Manager) {
+	profile, ok := req.Params["profile"].(string)
+	if !ok || profile == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'profile' parameter")
+		return
+	}
+
+	state, err := manager.SetProfile(profile)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
@@ -0,0 +1,94 @@
+package thermal
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// NewManager picks the first backend that answers: asusctl, then TLP, then
+// the kernel's platform_profile
+func NewManager() (*Manager, error) {
+	b, err := detectBackend()
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Thermal profiles through %s", b.name())
+	return newManager(b), nil
+}
+
+func newManager(b backend) *Manager {
+	return &Manager{backend: b}
+}
+
+func detectBackend() (backend, error) {
+	if _, err := exec.LookPath("asusctl"); err == nil {
+		b := newAsusctlBackend()
+		if _, err := b.active(); err == nil {
+			return b, nil
+		}
+	}
+	if _, err := exec.LookPath("tlp"); err == nil {
+		b := newTLPBackend()
+		if _, err := b.active(); err == nil {
+			return b, nil
+		}
+	}
+	if b := newSysfsBackend(); b.available() {
+		return b, nil
+	}
+	return nil, fmt.Errorf("no thermal profile support found (asusctl, tlp or platform_profile)")
+}
+
+func (m *Manager) GetState() (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state()
+}
+
+// state must be called with the mutex held
+func (m *Manager) state() (State, error) {
+	state := State{Backend: m.backend.name()}
+
+	profiles, err := m.backend.profiles()
+	if err != nil {
+		return state, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	for _, p := range profiles {
+		state.Profiles = append(state.Profiles, strings.ToLower(p))
+	}
+
+	active, err := m.backend.active()
+	if err != nil {
+		return state, fmt.Errorf("failed to read active profile: %w", err)
+	}
+	state.Active = strings.ToLower(active)
+	return state, nil
+}
+
+// SetProfile switches to one of the listed profiles, matched without regard
+// to case
+func (m *Manager) SetProfile(profile string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	profile = strings.ToLower(strings.TrimSpace(profile))
+	state, err := m.state()
+	if err != nil {
+		return state, err
+	}
+	if !slices.Contains(state.Profiles, profile) {
+		return state, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(state.Profiles, ", "))
+	}
+	if profile == state.Active {
+		return state, nil
+	}
+
+	if err := m.backend.set(profile); err != nil {
+		return state, fmt.Errorf("failed to set profile %s: %w", profile, err)
+	}
+	return m.state()
+}
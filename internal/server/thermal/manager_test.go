@@ -0,0 +1,69 @@
+package thermal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	choices []string
+	current string
+	sets    int
+	setErr  error
+}
+
+func (f *fakeBackend) name() string                { return "fake" }
+func (f *fakeBackend) profiles() ([]string, error) { return f.choices, nil }
+func (f *fakeBackend) active() (string, error)     { return f.current, nil }
+
+func (f *fakeBackend) set(profile string) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	f.sets++
+	f.current = profile
+	return nil
+}
+
+func TestGetStateLowercases(t *testing.T) {
+	m := newManager(&fakeBackend{choices: []string{"Quiet", "Balanced"}, current: "Balanced"})
+
+	state, err := m.GetState()
+	require.NoError(t, err)
+	assert.Equal(t, State{Backend: "fake", Profiles: []string{"quiet", "balanced"}, Active: "balanced"}, state)
+}
+
+func TestSetProfile(t *testing.T) {
+	b := &fakeBackend{choices: []string{"quiet", "balanced", "performance"}, current: "balanced"}
+	m := newManager(b)
+
+	state, err := m.SetProfile(" Performance ")
+	require.NoError(t, err)
+	assert.Equal(t, "performance", state.Active)
+	assert.Equal(t, 1, b.sets)
+
+	_, err = m.SetProfile("performance")
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.sets, "setting the active profile is a no-op")
+}
+
+func TestSetProfileRejectsUnknown(t *testing.T) {
+	b := &fakeBackend{choices: []string{"quiet", "balanced"}, current: "balanced"}
+	m := newManager(b)
+
+	_, err := m.SetProfile("turbo")
+	assert.ErrorContains(t, err, "unknown profile")
+	assert.Equal(t, 0, b.sets)
+}
+
+func TestSetProfileBackendError(t *testing.T) {
+	b := &fakeBackend{choices: []string{"quiet", "balanced"}, current: "balanced", setErr: fmt.Errorf("denied")}
+	m := newManager(b)
+
+	state, err := m.SetProfile("quiet")
+	assert.ErrorContains(t, err, "denied")
+	assert.Equal(t, "balanced", state.Active)
+}
@@ -0,0 +1,57 @@
+package thermal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const defaultACPIRoot = "/sys/firmware/acpi"
+
+// sysfsBackend drives the ACPI platform_profile interface directly. It is
+// the fallback for laptops without a vendor daemon; power-profiles-daemon
+// also writes this file, so profiles picked here may be overridden by it.
+type sysfsBackend struct {
+	root string
+	// privileged writes the profile when the file is not writable by the user
+	privileged func(path, value string) error
+}
+
+func newSysfsBackend() *sysfsBackend {
+	return &sysfsBackend{root: defaultACPIRoot, privileged: utils.PrivilegedWrite}
+}
+
+func (s *sysfsBackend) name() string { return "platform_profile" }
+
+func (s *sysfsBackend) available() bool {
+	_, err := os.Stat(filepath.Join(s.root, "platform_profile_choices"))
+	return err == nil
+}
+
+func (s *sysfsBackend) profiles() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, "platform_profile_choices"))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+func (s *sysfsBackend) active() (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, "platform_profile"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (s *sysfsBackend) set(profile string) error {
+	path := filepath.Join(s.root, "platform_profile")
+	err := os.WriteFile(path, []byte(profile), 0644)
+	if err == nil || !errors.Is(err, os.ErrPermission) || os.Geteuid() == 0 {
+		return err
+	}
+	return s.privileged(path, profile)
+}
@@ -0,0 +1,54 @@
+package thermal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tlpProfiles are the profiles TLP 1.8 and newer switch between
+var tlpProfiles = []string{"performance", "balanced", "power-saver"}
+
+// tlpBackend switches TLP's profile. tlp needs root, so it goes through
+// pkexec unless the daemon already runs as root.
+type tlpBackend struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func newTLPBackend() *tlpBackend {
+	return &tlpBackend{run: runCommand}
+}
+
+func (t *tlpBackend) name() string { return "tlp" }
+
+func (t *tlpBackend) profiles() ([]string, error) {
+	return append([]string(nil), tlpProfiles...), nil
+}
+
+func (t *tlpBackend) active() (string, error) {
+	out, err := t.run("tlp-stat", "-m")
+	if err != nil {
+		return "", err
+	}
+	return parseTLPMode(string(out))
+}
+
+// parseTLPMode reads "profile/source" as printed by tlp-stat -m. Versions
+// before profiles only print the power source, which is not a profile.
+func parseTLPMode(out string) (string, error) {
+	mode := strings.TrimSpace(out)
+	profile, _, ok := strings.Cut(mode, "/")
+	if !ok {
+		return "", fmt.Errorf("tlp does not report a profile (%q); TLP 1.8 or newer is needed", mode)
+	}
+	return strings.ToLower(profile), nil
+}
+
+func (t *tlpBackend) set(profile string) error {
+	if os.Geteuid() == 0 {
+		_, err := t.run("tlp", profile)
+		return err
+	}
+	_, err := t.run("pkexec", "tlp", profile)
+	return err
+}
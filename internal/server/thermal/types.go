@@ -0,0 +1,30 @@
+package thermal
+
+import "sync"
+
+// State is the active fan/thermal profile and the ones the backend accepts.
+// Profile names are the backend's own, lowercased.
+type State struct {
+	Backend  string   `json:"backend"`
+	Profiles []string `json:"profiles"`
+	Active   string   `json:"active"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// backend switches profiles through one vendor tool or the kernel interface
+type backend interface {
+	name() string
+	profiles() ([]string, error)
+	active() (string, error)
+	set(profile string) error
+}
+
+type Manager struct {
+	backend backend
+	mu      sync.Mutex
+}
@@ -0,0 +1,152 @@
+package timers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "timers.list":
+		handleList(conn, req, manager)
+	case "timers.create":
+		handleCreate(conn, req, manager)
+	case "timers.alarm":
+		handleAlarm(conn, req, manager)
+	case "timers.pomodoro":
+		handlePomodoro(conn, req, manager)
+	case "timers.pause":
+		handleUpdate(conn, req, manager.Pause)
+	case "timers.resume":
+		handleUpdate(conn, req, manager.Resume)
+	case "timers.skip":
+		handleUpdate(conn, req, manager.Skip)
+	case "timers.cancel":
+		handleCancel(conn, req, manager)
+	case "timers.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleList(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleCreate(conn net.Conn, req Request, manager *Manager) {
+	seconds, ok := req.Params["seconds"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'seconds' parameter")
+		return
+	}
+	label, _ := req.Params["label"].(string)
+
+	timer, err := manager.Create(int(seconds), label)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, timer)
+}
+
+func handleAlarm(conn net.Conn, req Request, manager *Manager) {
+	atStr, ok := req.Params["at"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'at' parameter")
+		return
+	}
+	label, _ := req.Params["label"].(string)
+
+	at, err := ParseAlarmTime(atStr, manager.now())
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	timer, err := manager.CreateAlarm(at, label)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, timer)
+}
+
+func handlePomodoro(conn net.Conn, req Request, manager *Manager) {
+	var cfg PomodoroConfig
+	if v, ok := req.Params["work"].(float64); ok {
+		cfg.Work = int(v)
+	}
+	if v, ok := req.Params["shortBreak"].(float64); ok {
+		cfg.ShortBreak = int(v)
+	}
+	if v, ok := req.Params["longBreak"].(float64); ok {
+		cfg.LongBreak = int(v)
+	}
+	if v, ok := req.Params["rounds"].(float64); ok {
+		cfg.Rounds = int(v)
+	}
+	label, _ := req.Params["label"].(string)
+
+	timer, err := manager.StartPomodoro(cfg, label)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, timer)
+}
+
+func handleUpdate(conn net.Conn, req Request, fn func(id string) (Timer, error)) {
+	id, ok := req.Params["id"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+
+	timer, err := fn(id)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, timer)
+}
+
+func handleCancel(conn net.Conn, req Request, manager *Manager) {
+	id, ok := req.Params["id"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+		return
+	}
+
+	if err := manager.Cancel(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "timer cancelled"})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
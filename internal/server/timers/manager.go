@@ -0,0 +1,524 @@
+package timers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crash"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	maxDuration = 7 * 24 * time.Hour
+	// maxWait bounds how long the loop sleeps. Go timers do not advance
+	// during suspend, so alarms re-check the wall clock after resume.
+	maxWait = 30 * time.Second
+)
+
+type persistedState struct {
+	NextID int      `json:"nextId"`
+	Timers []*Timer `json:"timers"`
+}
+
+func NewManager() (*Manager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+
+	m := newManager(filepath.Join(stateHome, "DankMaterialShell", "timers.json"), time.Now, notifyDesktop)
+	m.load()
+
+	go m.loop()
+
+	return m, nil
+}
+
+func newManager(statePath string, now func() time.Time, notify Notifier) *Manager {
+	return &Manager{
+		now:         now,
+		notify:      notify,
+		statePath:   statePath,
+		nextID:      1,
+		subscribers: make(map[string]chan State),
+		wakeChan:    make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// load restores timers from the last run. Ones that ran out while the
+// daemon was down fire on the first loop iteration.
+func (m *Manager) load() {
+	data, err := os.ReadFile(m.statePath)
+	if err != nil {
+		return
+	}
+
+	var state persistedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Warnf("Ignoring corrupt timers file %s: %v", m.statePath, err)
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.timers = nil
+	for _, t := range state.Timers {
+		if t != nil && (t.EndsAt != nil || t.Paused) {
+			m.timers = append(m.timers, t)
+		}
+	}
+	m.nextID = max(state.NextID, 1)
+}
+
+// save must be called with the mutex held
+func (m *Manager) save() {
+	data, err := json.MarshalIndent(persistedState{NextID: m.nextID, Timers: m.timers}, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0755); err != nil {
+		log.Warnf("Failed to create %s: %v", filepath.Dir(m.statePath), err)
+		return
+	}
+	tmp := m.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Warnf("Failed to save timers: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, m.statePath); err != nil {
+		log.Warnf("Failed to save timers: %v", err)
+	}
+}
+
+func (m *Manager) wake() {
+	select {
+	case m.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) loop() {
+	defer crash.Capture("timers.loop", nil)
+
+	for {
+		m.fireDue()
+
+		timer := time.NewTimer(m.untilNext())
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.wakeChan:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (m *Manager) untilNext() time.Duration {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	wait := maxWait
+	for _, t := range m.timers {
+		if t.EndsAt != nil {
+			wait = min(wait, t.EndsAt.Sub(now))
+		}
+	}
+	return max(wait, 0)
+}
+
+// fireDue removes finished timers, moves pomodoros to their next phase and
+// notifies about each
+func (m *Manager) fireDue() {
+	m.mutex.Lock()
+	now := m.now()
+	var fired []Timer
+	kept := m.timers[:0]
+	for _, t := range m.timers {
+		if t.EndsAt == nil || t.EndsAt.After(now) {
+			kept = append(kept, t)
+			continue
+		}
+		fired = append(fired, *t)
+		if t.Kind == KindPomodoro {
+			advancePhase(t, now)
+			kept = append(kept, t)
+		}
+	}
+	m.timers = kept
+	if len(fired) > 0 {
+		m.save()
+	}
+	m.mutex.Unlock()
+
+	for i := range fired {
+		t := fired[i]
+		summary, body, urgent := notification(t)
+		if m.notify != nil {
+			if err := m.notify(summary, body, urgent); err != nil {
+				log.Warnf("Failed to show notification for timer %s: %v", t.ID, err)
+			}
+		}
+		state := m.GetState()
+		state.Fired = &t
+		m.broadcast(state)
+	}
+}
+
+// advancePhase starts the pomodoro phase after the one that just ended
+func advancePhase(t *Timer, now time.Time) {
+	cfg := DefaultPomodoro
+	if t.Pomodoro != nil {
+		cfg = *t.Pomodoro
+	}
+
+	switch t.Phase {
+	case PhaseWork:
+		t.Phase = PhaseShortBreak
+		t.Duration = cfg.ShortBreak
+		if cfg.Rounds > 0 && t.Round%cfg.Rounds == 0 {
+			t.Phase = PhaseLongBreak
+			t.Duration = cfg.LongBreak
+		}
+	default:
+		t.Phase = PhaseWork
+		t.Duration = cfg.Work
+		t.Round++
+	}
+
+	endsAt := now.Add(time.Duration(t.Duration) * time.Second)
+	t.EndsAt = &endsAt
+	t.Remaining = t.Duration
+}
+
+func notification(t Timer) (string, string, bool) {
+	label := t.Label
+	switch t.Kind {
+	case KindAlarm:
+		if label == "" && t.EndsAt != nil {
+			label = t.EndsAt.Local().Format("15:04")
+		}
+		return "Alarm", label, true
+	case KindPomodoro:
+		cfg := DefaultPomodoro
+		if t.Pomodoro != nil {
+			cfg = *t.Pomodoro
+		}
+		if t.Phase == PhaseWork {
+			next := fmt.Sprintf("Short break, %s", formatSeconds(cfg.ShortBreak))
+			if cfg.Rounds > 0 && t.Round%cfg.Rounds == 0 {
+				next = fmt.Sprintf("Long break, %s", formatSeconds(cfg.LongBreak))
+			}
+			return "Pomodoro: time for a break", next, false
+		}
+		return "Pomodoro: back to work", fmt.Sprintf("Round %d, %s", t.Round+1, formatSeconds(cfg.Work)), false
+	default:
+		if label == "" {
+			label = formatSeconds(t.Duration) + " timer"
+		}
+		return "Timer finished", label, false
+	}
+}
+
+func formatSeconds(seconds int) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+func (m *Manager) add(t *Timer) Timer {
+	m.mutex.Lock()
+	t.ID = "t" + strconv.Itoa(m.nextID)
+	m.nextID++
+	t.CreatedAt = m.now()
+	m.timers = append(m.timers, t)
+	m.save()
+	created := *t
+	m.mutex.Unlock()
+
+	m.wake()
+	m.broadcast(m.GetState())
+	return created
+}
+
+// Create starts a countdown
+func (m *Manager) Create(seconds int, label string) (Timer, error) {
+	duration := time.Duration(seconds) * time.Second
+	if seconds <= 0 || duration > maxDuration {
+		return Timer{}, fmt.Errorf("duration must be between 1s and %s", maxDuration)
+	}
+
+	endsAt := m.now().Add(duration)
+	return m.add(&Timer{
+		Kind:      KindTimer,
+		Label:     label,
+		Duration:  seconds,
+		EndsAt:    &endsAt,
+		Remaining: seconds,
+	}), nil
+}
+
+// CreateAlarm rings at a wall clock time
+func (m *Manager) CreateAlarm(at time.Time, label string) (Timer, error) {
+	now := m.now()
+	if !at.After(now) {
+		return Timer{}, fmt.Errorf("alarm time %s is in the past", at.Format(time.RFC3339))
+	}
+	if at.Sub(now) > maxDuration {
+		return Timer{}, fmt.Errorf("alarm must be within %s", maxDuration)
+	}
+
+	seconds := int(at.Sub(now).Round(time.Second) / time.Second)
+	return m.add(&Timer{
+		Kind:      KindAlarm,
+		Label:     label,
+		Duration:  seconds,
+		EndsAt:    &at,
+		Remaining: seconds,
+	}), nil
+}
+
+// StartPomodoro begins a work phase; zero values in cfg use the defaults
+func (m *Manager) StartPomodoro(cfg PomodoroConfig, label string) (Timer, error) {
+	if cfg.Work == 0 {
+		cfg.Work = DefaultPomodoro.Work
+	}
+	if cfg.ShortBreak == 0 {
+		cfg.ShortBreak = DefaultPomodoro.ShortBreak
+	}
+	if cfg.LongBreak == 0 {
+		cfg.LongBreak = DefaultPomodoro.LongBreak
+	}
+	if cfg.Rounds == 0 {
+		cfg.Rounds = DefaultPomodoro.Rounds
+	}
+	for _, v := range []int{cfg.Work, cfg.ShortBreak, cfg.LongBreak} {
+		if v <= 0 || time.Duration(v)*time.Second > maxDuration {
+			return Timer{}, fmt.Errorf("phase lengths must be between 1s and %s", maxDuration)
+		}
+	}
+	if cfg.Rounds < 0 {
+		return Timer{}, fmt.Errorf("rounds must be positive")
+	}
+
+	endsAt := m.now().Add(time.Duration(cfg.Work) * time.Second)
+	return m.add(&Timer{
+		Kind:      KindPomodoro,
+		Label:     label,
+		Duration:  cfg.Work,
+		EndsAt:    &endsAt,
+		Remaining: cfg.Work,
+		Phase:     PhaseWork,
+		Round:     1,
+		Pomodoro:  &cfg,
+	}), nil
+}
+
+// update runs fn on a timer and saves and broadcasts the result
+func (m *Manager) update(id string, fn func(t *Timer, now time.Time) error) (Timer, error) {
+	m.mutex.Lock()
+	var target *Timer
+	for _, t := range m.timers {
+		if t.ID == id {
+			target = t
+			break
+		}
+	}
+	if target == nil {
+		m.mutex.Unlock()
+		return Timer{}, fmt.Errorf("timer not found: %s", id)
+	}
+	if err := fn(target, m.now()); err != nil {
+		m.mutex.Unlock()
+		return Timer{}, err
+	}
+	m.save()
+	updated := *target
+	m.mutex.Unlock()
+
+	m.wake()
+	m.broadcast(m.GetState())
+	return updated, nil
+}
+
+func (m *Manager) Pause(id string) (Timer, error) {
+	return m.update(id, func(t *Timer, now time.Time) error {
+		if t.Kind == KindAlarm {
+			return fmt.Errorf("alarms cannot be paused")
+		}
+		if t.Paused {
+			return nil
+		}
+		t.Remaining = max(int(t.EndsAt.Sub(now).Round(time.Second)/time.Second), 0)
+		t.EndsAt = nil
+		t.Paused = true
+		return nil
+	})
+}
+
+func (m *Manager) Resume(id string) (Timer, error) {
+	return m.update(id, func(t *Timer, now time.Time) error {
+		if !t.Paused {
+			return nil
+		}
+		endsAt := now.Add(time.Duration(t.Remaining) * time.Second)
+		t.EndsAt = &endsAt
+		t.Paused = false
+		return nil
+	})
+}
+
+// Skip ends the current pomodoro phase without a notification
+func (m *Manager) Skip(id string) (Timer, error) {
+	return m.update(id, func(t *Timer, now time.Time) error {
+		if t.Kind != KindPomodoro {
+			return fmt.Errorf("only pomodoros can skip a phase")
+		}
+		advancePhase(t, now)
+		t.Paused = false
+		return nil
+	})
+}
+
+func (m *Manager) Cancel(id string) error {
+	m.mutex.Lock()
+	found := false
+	for i, t := range m.timers {
+		if t.ID == id {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+			found = true
+			break
+		}
+	}
+	if found {
+		m.save()
+	}
+	m.mutex.Unlock()
+
+	if !found {
+		return fmt.Errorf("timer not found: %s", id)
+	}
+	m.wake()
+	m.broadcast(m.GetState())
+	return nil
+}
+
+// GetState lists running timers by when they end, paused ones last
+func (m *Manager) GetState() State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	timers := make([]Timer, 0, len(m.timers))
+	for _, t := range m.timers {
+		c := *t
+		if c.EndsAt != nil {
+			c.Remaining = max(int(c.EndsAt.Sub(now).Round(time.Second)/time.Second), 0)
+		}
+		timers = append(timers, c)
+	}
+	sort.SliceStable(timers, func(i, j int) bool {
+		a, b := timers[i].EndsAt, timers[j].EndsAt
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		default:
+			return a.Before(*b)
+		}
+	})
+	return State{Timers: timers}
+}
+
+func (m *Manager) broadcast(state State) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) Close() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
+
+// notifyDesktop sends the notification through whatever notification daemon
+// owns the session bus name, which is normally the shell itself
+func notifyDesktop(summary, body string, urgent bool) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+
+	urgency := byte(1)
+	if urgent {
+		urgency = 2
+	}
+	hints := map[string]dbus.Variant{
+		"urgency":       dbus.MakeVariant(urgency),
+		"desktop-entry": dbus.MakeVariant("DankMaterialShell"),
+	}
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	return obj.Call("org.freedesktop.Notifications.Notify", 0,
+		"DankMaterialShell", uint32(0), "alarm-symbolic", summary, body, []string{}, hints, int32(-1)).Err
+}
+
+// ParseAlarmTime accepts "HH:MM", meaning the next time the clock shows it,
+// or an RFC 3339 timestamp
+func ParseAlarmTime(s string, now time.Time) (time.Time, error) {
+	if clock, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid alarm time %q (expected HH:MM or RFC 3339)", s)
+	}
+	return at, nil
+}
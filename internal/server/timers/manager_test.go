@@ -0,0 +1,209 @@
+package timers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+type sent struct {
+	summary string
+	body    string
+	urgent  bool
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeClock, *[]sent) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	var notifications []sent
+	m := newManager(filepath.Join(t.TempDir(), "timers.json"), clock.now, func(summary, body string, urgent bool) error {
+		notifications = append(notifications, sent{summary, body, urgent})
+		return nil
+	})
+	return m, clock, &notifications
+}
+
+func TestManager_TimerFires(t *testing.T) {
+	m, clock, notifications := newTestManager(t)
+
+	timer, err := m.Create(90, "Tea")
+	require.NoError(t, err)
+	assert.Equal(t, "t1", timer.ID)
+
+	states := m.Subscribe("test")
+
+	clock.advance(60 * time.Second)
+	m.fireDue()
+	assert.Empty(t, *notifications)
+	assert.Equal(t, 30, m.GetState().Timers[0].Remaining)
+
+	clock.advance(30 * time.Second)
+	m.fireDue()
+	require.Len(t, *notifications, 1)
+	assert.Equal(t, sent{"Timer finished", "Tea", false}, (*notifications)[0])
+	assert.Empty(t, m.GetState().Timers)
+
+	state := <-states
+	require.NotNil(t, state.Fired)
+	assert.Equal(t, "t1", state.Fired.ID)
+}
+
+func TestManager_InvalidDurations(t *testing.T) {
+	m, clock, _ := newTestManager(t)
+
+	_, err := m.Create(0, "")
+	assert.Error(t, err)
+	_, err = m.Create(8*24*3600, "")
+	assert.Error(t, err)
+	_, err = m.CreateAlarm(clock.now().Add(-time.Minute), "")
+	assert.Error(t, err)
+}
+
+func TestManager_PauseResume(t *testing.T) {
+	m, clock, notifications := newTestManager(t)
+
+	timer, err := m.Create(60, "")
+	require.NoError(t, err)
+
+	clock.advance(20 * time.Second)
+	paused, err := m.Pause(timer.ID)
+	require.NoError(t, err)
+	assert.True(t, paused.Paused)
+	assert.Equal(t, 40, paused.Remaining)
+
+	clock.advance(time.Hour)
+	m.fireDue()
+	assert.Empty(t, *notifications)
+
+	resumed, err := m.Resume(timer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, clock.now().Add(40*time.Second), *resumed.EndsAt)
+
+	clock.advance(40 * time.Second)
+	m.fireDue()
+	require.Len(t, *notifications, 1)
+	assert.Equal(t, "1m0s timer", (*notifications)[0].body)
+}
+
+func TestManager_Alarm(t *testing.T) {
+	m, clock, notifications := newTestManager(t)
+
+	at, err := ParseAlarmTime("12:30", clock.now())
+	require.NoError(t, err)
+	alarm, err := m.CreateAlarm(at, "")
+	require.NoError(t, err)
+
+	_, err = m.Pause(alarm.ID)
+	assert.Error(t, err)
+
+	clock.advance(30 * time.Minute)
+	m.fireDue()
+	require.Len(t, *notifications, 1)
+	assert.Equal(t, sent{"Alarm", "12:30", true}, (*notifications)[0])
+}
+
+func TestManager_Pomodoro(t *testing.T) {
+	m, clock, notifications := newTestManager(t)
+
+	timer, err := m.StartPomodoro(PomodoroConfig{Work: 60, ShortBreak: 10, LongBreak: 30, Rounds: 2}, "")
+	require.NoError(t, err)
+	assert.Equal(t, PhaseWork, timer.Phase)
+	assert.Equal(t, 1, timer.Round)
+
+	clock.advance(60 * time.Second)
+	m.fireDue()
+	current := m.GetState().Timers[0]
+	assert.Equal(t, PhaseShortBreak, current.Phase)
+	assert.Equal(t, 10, current.Remaining)
+	assert.Equal(t, "Pomodoro: time for a break", (*notifications)[0].summary)
+
+	clock.advance(10 * time.Second)
+	m.fireDue()
+	current = m.GetState().Timers[0]
+	assert.Equal(t, PhaseWork, current.Phase)
+	assert.Equal(t, 2, current.Round)
+
+	clock.advance(60 * time.Second)
+	m.fireDue()
+	current = m.GetState().Timers[0]
+	assert.Equal(t, PhaseLongBreak, current.Phase)
+	assert.Equal(t, "Long break, 30s", (*notifications)[2].body)
+
+	skipped, err := m.Skip(timer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, PhaseWork, skipped.Phase)
+	assert.Equal(t, 3, skipped.Round)
+	assert.Len(t, *notifications, 3, "skipping does not notify")
+}
+
+func TestManager_Persistence(t *testing.T) {
+	m, clock, _ := newTestManager(t)
+
+	_, err := m.Create(120, "Pasta")
+	require.NoError(t, err)
+	second, err := m.Create(300, "")
+	require.NoError(t, err)
+	require.NoError(t, m.Cancel(second.ID))
+	assert.Error(t, m.Cancel(second.ID))
+
+	var fired []sent
+	restored := newManager(m.statePath, clock.now, func(summary, body string, urgent bool) error {
+		fired = append(fired, sent{summary, body, urgent})
+		return nil
+	})
+	restored.load()
+
+	state := restored.GetState()
+	require.Len(t, state.Timers, 1)
+	assert.Equal(t, "Pasta", state.Timers[0].Label)
+
+	// A timer that ran out while the daemon was down fires on startup
+	clock.advance(time.Hour)
+	restored.fireDue()
+	assert.Len(t, fired, 1)
+
+	next, err := restored.Create(10, "")
+	require.NoError(t, err)
+	assert.Equal(t, "t3", next.ID, "IDs are not reused")
+}
+
+func TestManager_StateOrder(t *testing.T) {
+	m, _, _ := newTestManager(t)
+
+	long, err := m.Create(300, "")
+	require.NoError(t, err)
+	_, err = m.Create(60, "")
+	require.NoError(t, err)
+	paused, err := m.Create(10, "")
+	require.NoError(t, err)
+	_, err = m.Pause(paused.ID)
+	require.NoError(t, err)
+
+	timers := m.GetState().Timers
+	require.Len(t, timers, 3)
+	assert.Equal(t, "t2", timers[0].ID)
+	assert.Equal(t, long.ID, timers[1].ID)
+	assert.Equal(t, paused.ID, timers[2].ID)
+}
+
+func TestParseAlarmTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	at, err := ParseAlarmTime("07:30", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 2, 7, 30, 0, 0, time.UTC), at)
+
+	at, err = ParseAlarmTime("2025-01-01T18:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, 18, at.Hour())
+
+	_, err = ParseAlarmTime("tomorrow", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package timers
+
+import (
+	"sync"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindTimer    Kind = "timer"
+	KindAlarm    Kind = "alarm"
+	KindPomodoro Kind = "pomodoro"
+)
+
+type Phase string
+
+const (
+	PhaseWork       Phase = "work"
+	PhaseShortBreak Phase = "shortBreak"
+	PhaseLongBreak  Phase = "longBreak"
+)
+
+// PomodoroConfig sets the phase lengths in seconds. A long break replaces
+// the short one after every Rounds work phases.
+type PomodoroConfig struct {
+	Work       int `json:"work"`
+	ShortBreak int `json:"shortBreak"`
+	LongBreak  int `json:"longBreak"`
+	Rounds     int `json:"rounds"`
+}
+
+var DefaultPomodoro = PomodoroConfig{
+	Work:       25 * 60,
+	ShortBreak: 5 * 60,
+	LongBreak:  15 * 60,
+	Rounds:     4,
+}
+
+// Timer is a countdown, an alarm at a wall clock time or a pomodoro cycle.
+// A paused timer has no EndsAt and keeps what was left in Remaining.
+type Timer struct {
+	ID        string     `json:"id"`
+	Kind      Kind       `json:"kind"`
+	Label     string     `json:"label,omitempty"`
+	Duration  int        `json:"duration"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+	Paused    bool       `json:"paused"`
+	Remaining int        `json:"remaining"`
+	CreatedAt time.Time  `json:"createdAt"`
+
+	Phase    Phase           `json:"phase,omitempty"`
+	Round    int             `json:"round,omitempty"`
+	Pomodoro *PomodoroConfig `json:"pomodoro,omitempty"`
+}
+
+type State struct {
+	Timers []Timer `json:"timers"`
+	// Fired is the timer that just went off; it is only set in the update
+	// sent when it fires
+	Fired *Timer `json:"fired,omitempty"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// Notifier shows a desktop notification when a timer fires
+type Notifier func(summary, body string, urgent bool) error
+
+type Manager struct {
+	now       func() time.Time
+	notify    Notifier
+	statePath string
+
+	mutex  sync.Mutex
+	timers []*Timer
+	nextID int
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+
+	wakeChan chan struct{}
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type methodRename struct {
+	replacement string
+	since       int
+}
+
+// renamedMethods are old method names still served under their new name.
+// Callers get a deprecation in the response meta and the daemon logs the
+// first use of each. Only names that shipped in a release belong here.
+var renamedMethods = map[string]methodRename{}
+
+var warnedDeprecations sync.Map
+
+func deprecationFor(method string, rename methodRename) models.Deprecation {
+	return models.Deprecation{
+		Method:      method,
+		Replacement: rename.replacement,
+		Since:       rename.since,
+		Message:     fmt.Sprintf("%s is deprecated since API %d, use %s", method, rename.since, rename.replacement),
+	}
+}
+
+// applyVersioning rewrites a renamed method to its replacement and wraps
+// conn so the response carries the API version and any deprecation. Clients
+// that declare no version and call no deprecated method get conn back
+// unchanged, so their responses stay as they were.
+func applyVersioning(conn net.Conn, req models.Request) (net.Conn, models.Request) {
+	var deprecations []models.Deprecation
+	if rename, ok := renamedMethods[req.Method]; ok {
+		d := deprecationFor(req.Method, rename)
+		if _, warned := warnedDeprecations.LoadOrStore(req.Method, true); !warned {
+			log.Warnf("Client called %s", d.Message)
+		}
+		deprecations = append(deprecations, d)
+		req.Method = rename.replacement
+	}
+
+	if req.APIVersion > APIVersion {
+		log.Debugf("Client expects API %d, server provides %d", req.APIVersion, APIVersion)
+	}
+
+	if req.APIVersion == 0 && len(deprecations) == 0 {
+		return conn, req
+	}
+	return &models.MetaConn{
+		Conn: conn,
+		Meta: models.ResponseMeta{APIVersion: APIVersion, Deprecations: deprecations},
+	}, req
+}
+
+// getDeprecations lists every renamed method so clients can migrate ahead of
+// calling them
+func getDeprecations() []models.Deprecation {
+	deprecations := make([]models.Deprecation, 0, len(renamedMethods))
+	for method, rename := range renamedMethods {
+		deprecations = append(deprecations, deprecationFor(method, rename))
+	}
+	sort.Slice(deprecations, func(i, j int) bool { return deprecations[i].Method < deprecations[j].Method })
+	return deprecations
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyVersioning_UnversionedClientUnchanged(t *testing.T) {
+	conn := &mockConn{}
+	gotConn, gotReq := applyVersioning(conn, models.Request{ID: 1, Method: "ping"})
+	assert.Same(t, conn, gotConn)
+	assert.Equal(t, "ping", gotReq.Method)
+
+	models.Respond(gotConn, 1, "pong")
+	var resp models.Response[string]
+	require.NoError(t, json.Unmarshal(conn.written, &resp))
+	assert.Nil(t, resp.Meta)
+}
+
+func TestApplyVersioning_VersionedClientGetsMeta(t *testing.T) {
+	conn := &mockConn{}
+	gotConn, _ := applyVersioning(conn, models.Request{ID: 2, Method: "ping", APIVersion: APIVersion})
+
+	models.Respond(gotConn, 2, "pong")
+	var resp models.Response[string]
+	require.NoError(t, json.Unmarshal(conn.written, &resp))
+	require.NotNil(t, resp.Meta)
+	assert.Equal(t, APIVersion, resp.Meta.APIVersion)
+	assert.Empty(t, resp.Meta.Deprecations)
+}
+
+func TestApplyVersioning_RenamedMethod(t *testing.T) {
+	renamedMethods["sensors.read"] = methodRename{replacement: "sensors.list", since: APIVersion}
+	defer delete(renamedMethods, "sensors.read")
+
+	conn := &mockConn{}
+	gotConn, gotReq := applyVersioning(conn, models.Request{ID: 3, Method: "sensors.read"})
+	assert.Equal(t, "sensors.list", gotReq.Method)
+
+	models.RespondError(gotConn, 3, "sensors manager not initialized")
+	var resp models.Response[any]
+	require.NoError(t, json.Unmarshal(conn.written, &resp))
+	require.NotNil(t, resp.Meta)
+	require.Len(t, resp.Meta.Deprecations, 1)
+	d := resp.Meta.Deprecations[0]
+	assert.Equal(t, "sensors.read", d.Method)
+	assert.Equal(t, "sensors.list", d.Replacement)
+	assert.Equal(t, APIVersion, d.Since)
+}
+
+func TestRenamedMethodsPointAtCurrentMethods(t *testing.T) {
+	for old, rename := range renamedMethods {
+		_, chained := renamedMethods[rename.replacement]
+		assert.False(t, chained, "%s renames to %s, which is itself renamed", old, rename.replacement)
+		assert.LessOrEqual(t, rename.since, APIVersion)
+	}
+	assert.Len(t, getDeprecations(), len(renamedMethods))
+}
@@ -0,0 +1,69 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	switch req.Method {
+	case "wallpaper.get":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "wallpaper.set":
+		handleSet(conn, req, manager)
+	case "wallpaper.clear":
+		output, _ := req.Params["output"].(string)
+		models.Respond(conn, req.ID, manager.Clear(output))
+	case "wallpaper.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSet(conn net.Conn, req Request, manager *Manager) {
+	path, ok := req.Params["path"].(string)
+	if !ok || path == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'path' parameter")
+		return
+	}
+	opts := SetOptions{Path: path}
+	opts.Output, _ = req.Params["output"].(string)
+	opts.Span, _ = req.Params["span"].(bool)
+	if mode, ok := req.Params["mode"].(string); ok {
+		opts.Mode = Mode(mode)
+	}
+
+	state, err := manager.Set(opts)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
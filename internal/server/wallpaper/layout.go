@@ -0,0 +1,192 @@
+package wallpaper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"os"
+)
+
+// frect is Rect before rounding
+type frect struct {
+	x, y, w, h float64
+}
+
+func (r frect) intersect(o frect) frect {
+	x0 := math.Max(r.x, o.x)
+	y0 := math.Max(r.y, o.y)
+	x1 := math.Min(r.x+r.w, o.x+o.w)
+	y1 := math.Min(r.y+r.h, o.y+o.h)
+	if x1 <= x0 || y1 <= y0 {
+		return frect{}
+	}
+	return frect{x0, y0, x1 - x0, y1 - y0}
+}
+
+func (r frect) round() Rect {
+	x0, y0 := math.Round(r.x), math.Round(r.y)
+	return Rect{
+		X:      int(x0),
+		Y:      int(y0),
+		Width:  int(math.Round(r.x+r.w) - x0),
+		Height: int(math.Round(r.y+r.h) - y0),
+	}
+}
+
+// imageRect is where an imgW x imgH image lands in area for mode. scale is
+// the output scale, which sets how big an image pixel is in center mode.
+func imageRect(mode Mode, imgW, imgH int, area frect, scale float64) frect {
+	w, h := float64(imgW), float64(imgH)
+	var k float64
+	switch mode {
+	case ModeFit:
+		k = math.Min(area.w/w, area.h/h)
+	case ModeCenter:
+		k = 1 / math.Max(scale, 1)
+	default:
+		k = math.Max(area.w/w, area.h/h)
+	}
+	return frect{
+		x: area.x + (area.w-w*k)/2,
+		y: area.y + (area.h-h*k)/2,
+		w: w * k,
+		h: h * k,
+	}
+}
+
+// placeOn crops the image drawn at img to what out shows of it
+func placeOn(out Output, img frect, imgW, imgH int) (source, target Rect) {
+	area := frect{float64(out.X), float64(out.Y), float64(out.Width), float64(out.Height)}
+	visible := area.intersect(img)
+	if visible.w == 0 {
+		return Rect{}, Rect{}
+	}
+
+	kx, ky := float64(imgW)/img.w, float64(imgH)/img.h
+	source = frect{
+		x: (visible.x - img.x) * kx,
+		y: (visible.y - img.y) * ky,
+		w: visible.w * kx,
+		h: visible.h * ky,
+	}.round()
+	target = frect{visible.x - area.x, visible.y - area.y, visible.w, visible.h}.round()
+	return source, target
+}
+
+// layoutBounds is the box around every output
+func layoutBounds(outputs []Output) frect {
+	if len(outputs) == 0 {
+		return frect{}
+	}
+	x0, y0 := math.Inf(1), math.Inf(1)
+	x1, y1 := math.Inf(-1), math.Inf(-1)
+	for _, o := range outputs {
+		x0 = math.Min(x0, float64(o.X))
+		y0 = math.Min(y0, float64(o.Y))
+		x1 = math.Max(x1, float64(o.X+o.Width))
+		y1 = math.Max(y1, float64(o.Y+o.Height))
+	}
+	return frect{x0, y0, x1 - x0, y1 - y0}
+}
+
+// placements works out what every output draws for config
+func placements(config Config, outputs []Output, size func(string) imageSize) []Placement {
+	result := make([]Placement, 0, len(outputs))
+
+	if config.Span != nil {
+		a := *config.Span
+		bounds := layoutBounds(outputs)
+		scale := 1.0
+		for _, o := range outputs {
+			scale = math.Max(scale, o.Scale)
+		}
+		for _, o := range outputs {
+			p := Placement{Output: o.Name, Path: a.Path, Mode: a.Mode, Span: true}
+			fillPlacement(&p, size(a.Path), bounds, scale, o)
+			result = append(result, p)
+		}
+		return result
+	}
+
+	for _, o := range outputs {
+		a, ok := config.Outputs[o.Name]
+		if !ok {
+			if config.Default == nil {
+				continue
+			}
+			a = *config.Default
+		}
+		p := Placement{Output: o.Name, Path: a.Path, Mode: a.Mode}
+		area := frect{float64(o.X), float64(o.Y), float64(o.Width), float64(o.Height)}
+		fillPlacement(&p, size(a.Path), area, o.Scale, o)
+		result = append(result, p)
+	}
+	return result
+}
+
+// fillPlacement sets the image size and the rects p needs to show the image
+// in area on out
+func fillPlacement(p *Placement, s imageSize, area frect, scale float64, out Output) {
+	if s.err != nil {
+		p.Error = s.err.Error()
+		return
+	}
+	p.ImageWidth, p.ImageHeight = s.width, s.height
+	img := imageRect(p.Mode, s.width, s.height, area, scale)
+	p.Source, p.Target = placeOn(out, img, s.width, s.height)
+}
+
+// readImageSize reads the dimensions from an image header. WebP is parsed
+// here since the standard library has no decoder for it.
+func readImageSize(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 30)
+	n, _ := io.ReadFull(f, header)
+	if w, h, ok := webpSize(header[:n]); ok {
+		return w, h, nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return 0, 0, fmt.Errorf("%s has no pixels", path)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// webpSize reads the canvas size of a lossy (VP8), lossless (VP8L) or
+// extended (VP8X) WebP
+func webpSize(h []byte) (int, int, bool) {
+	if len(h) < 30 || string(h[0:4]) != "RIFF" || string(h[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	switch string(h[12:16]) {
+	case "VP8 ":
+		w := int(binary.LittleEndian.Uint16(h[26:28]) & 0x3fff)
+		ht := int(binary.LittleEndian.Uint16(h[28:30]) & 0x3fff)
+		return w, ht, w > 0 && ht > 0
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(h[21:25])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, true
+	case "VP8X":
+		w := int(h[24]) | int(h[25])<<8 | int(h[26])<<16
+		ht := int(h[27]) | int(h[28])<<8 | int(h[29])<<16
+		return w + 1, ht + 1, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,221 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+func NewManager() (*Manager, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+
+	m := newManager(filepath.Join(stateHome, "DankMaterialShell", "wallpapers.json"), readImageSize)
+	m.load()
+	return m, nil
+}
+
+func newManager(configPath string, readSize func(string) (int, int, error)) *Manager {
+	return &Manager{
+		configPath:  configPath,
+		readSize:    readSize,
+		sizes:       make(map[string]imageSize),
+		subscribers: make(map[string]chan State),
+	}
+}
+
+func (m *Manager) load() {
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		return
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Warnf("Ignoring corrupt wallpaper file %s: %v", m.configPath, err)
+		return
+	}
+
+	m.mutex.Lock()
+	m.config = config
+	m.mutex.Unlock()
+}
+
+// save must be called with the mutex held
+func (m *Manager) save() {
+	data, err := json.MarshalIndent(m.config, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.configPath), 0755); err != nil {
+		log.Warnf("Failed to create %s: %v", filepath.Dir(m.configPath), err)
+		return
+	}
+	tmp := m.configPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		log.Warnf("Failed to save wallpapers: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, m.configPath); err != nil {
+		log.Warnf("Failed to save wallpapers: %v", err)
+	}
+}
+
+// size must be called with the mutex held. Sizes are cached until the path
+// is set again, so replacing a file in place takes a new wallpaper.set.
+func (m *Manager) size(path string) imageSize {
+	if s, ok := m.sizes[path]; ok {
+		return s
+	}
+	w, h, err := m.readSize(path)
+	s := imageSize{width: w, height: h, err: err}
+	m.sizes[path] = s
+	return s
+}
+
+// stateLocked must be called with the mutex held
+func (m *Manager) stateLocked() State {
+	config := m.config
+	if config.Outputs != nil {
+		outputs := make(map[string]Assignment, len(config.Outputs))
+		for name, a := range config.Outputs {
+			outputs[name] = a
+		}
+		config.Outputs = outputs
+	}
+	return State{
+		Config:     config,
+		Placements: placements(m.config, m.outputs, m.size),
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stateLocked()
+}
+
+// SetOutputs replaces the output layout, normally from the wayland manager
+func (m *Manager) SetOutputs(outputs []Output) {
+	m.mutex.Lock()
+	if slices.Equal(m.outputs, outputs) {
+		m.mutex.Unlock()
+		return
+	}
+	m.outputs = slices.Clone(outputs)
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+}
+
+// Set shows an image on one output, on all of them or spanned across them
+func (m *Manager) Set(opts SetOptions) (State, error) {
+	if opts.Mode == "" {
+		opts.Mode = ModeFill
+	}
+	if !opts.Mode.Valid() {
+		return State{}, fmt.Errorf("invalid mode %q (expected fill, fit or center)", opts.Mode)
+	}
+	if opts.Span && opts.Output != "" {
+		return State{}, fmt.Errorf("span covers every output and cannot be combined with output")
+	}
+
+	path, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return State{}, err
+	}
+	w, h, err := m.readSize(path)
+	if err != nil {
+		return State{}, err
+	}
+
+	m.mutex.Lock()
+	m.sizes[path] = imageSize{width: w, height: h}
+
+	a := Assignment{Path: path, Mode: opts.Mode}
+	switch {
+	case opts.Span:
+		m.config.Span = &a
+	case opts.Output != "":
+		m.config.Span = nil
+		if m.config.Outputs == nil {
+			m.config.Outputs = make(map[string]Assignment)
+		}
+		m.config.Outputs[opts.Output] = a
+	default:
+		m.config = Config{Default: &a}
+	}
+	m.save()
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	return state, nil
+}
+
+// Clear removes an output's own wallpaper so it shows the default again,
+// or with no output, every wallpaper
+func (m *Manager) Clear(output string) State {
+	m.mutex.Lock()
+	if output == "" {
+		m.config = Config{}
+	} else {
+		delete(m.config.Outputs, output)
+	}
+	m.save()
+	state := m.stateLocked()
+	m.mutex.Unlock()
+
+	m.broadcast(state)
+	return state
+}
+
+func (m *Manager) broadcast(state State) {
+	m.subMutex.RLock()
+	defer m.subMutex.RUnlock()
+
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 16)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) Close() {
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
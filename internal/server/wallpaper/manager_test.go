@@ -0,0 +1,209 @@
+package wallpaper
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sideBySide = []Output{
+	{Name: "DP-1", X: 0, Y: 0, Width: 1920, Height: 1080, Scale: 1},
+	{Name: "HDMI-A-1", X: 1920, Y: 0, Width: 1920, Height: 1080, Scale: 1},
+}
+
+func fixedSizes(sizes map[string][2]int) func(string) (int, int, error) {
+	return func(path string) (int, int, error) {
+		s, ok := sizes[filepath.Base(path)]
+		if !ok {
+			return 0, 0, errors.New("no such image")
+		}
+		return s[0], s[1], nil
+	}
+}
+
+func sizeOf(w, h int) func(string) imageSize {
+	return func(string) imageSize { return imageSize{width: w, height: h} }
+}
+
+func TestPlacementsModes(t *testing.T) {
+	out := []Output{{Name: "eDP-1", X: 0, Y: 0, Width: 1920, Height: 1080, Scale: 2}}
+
+	tests := []struct {
+		name   string
+		mode   Mode
+		w, h   int
+		source Rect
+		target Rect
+	}{
+		{"fill crops the overflow", ModeFill, 1000, 1000, Rect{0, 219, 1000, 562}, Rect{0, 0, 1920, 1080}},
+		{"fit leaves borders", ModeFit, 1000, 1000, Rect{0, 0, 1000, 1000}, Rect{420, 0, 1080, 1080}},
+		{"center uses the output scale", ModeCenter, 1000, 1000, Rect{0, 0, 1000, 1000}, Rect{710, 290, 500, 500}},
+		{"center crops a large image", ModeCenter, 8000, 6000, Rect{2080, 1920, 3840, 2160}, Rect{0, 0, 1920, 1080}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Default: &Assignment{Path: "/w.png", Mode: tt.mode}}
+			got := placements(config, out, sizeOf(tt.w, tt.h))
+			require.Len(t, got, 1)
+			assert.Equal(t, tt.source, got[0].Source)
+			assert.Equal(t, tt.target, got[0].Target)
+			assert.Equal(t, tt.w, got[0].ImageWidth)
+		})
+	}
+}
+
+func TestPlacementsSpan(t *testing.T) {
+	t.Run("side by side", func(t *testing.T) {
+		config := Config{Span: &Assignment{Path: "/w.png", Mode: ModeFill}}
+		got := placements(config, sideBySide, sizeOf(3840, 1080))
+		require.Len(t, got, 2)
+		assert.Equal(t, Rect{0, 0, 1920, 1080}, got[0].Source)
+		assert.Equal(t, Rect{1920, 0, 1920, 1080}, got[1].Source)
+		assert.Equal(t, Rect{0, 0, 1920, 1080}, got[1].Target)
+		assert.True(t, got[1].Span)
+	})
+
+	t.Run("fill crops the whole layout", func(t *testing.T) {
+		config := Config{Span: &Assignment{Path: "/w.png", Mode: ModeFill}}
+		got := placements(config, sideBySide, sizeOf(1920, 1080))
+		assert.Equal(t, Rect{0, 270, 960, 540}, got[0].Source)
+		assert.Equal(t, Rect{960, 270, 960, 540}, got[1].Source)
+	})
+
+	t.Run("stacked outputs of different sizes", func(t *testing.T) {
+		outputs := []Output{
+			{Name: "DP-1", X: 0, Y: 0, Width: 1920, Height: 1080, Scale: 1},
+			{Name: "eDP-1", X: 320, Y: 1080, Width: 1280, Height: 800, Scale: 1},
+		}
+		config := Config{Span: &Assignment{Path: "/w.png", Mode: ModeFill}}
+		got := placements(config, outputs, sizeOf(1920, 1880))
+		assert.Equal(t, Rect{0, 0, 1920, 1080}, got[0].Source)
+		assert.Equal(t, Rect{320, 1080, 1280, 800}, got[1].Source)
+	})
+
+	t.Run("fit straddles the seam", func(t *testing.T) {
+		config := Config{Span: &Assignment{Path: "/w.png", Mode: ModeFit}}
+		got := placements(config, sideBySide, sizeOf(1000, 1000))
+		assert.Equal(t, Rect{0, 0, 500, 1000}, got[0].Source)
+		assert.Equal(t, Rect{1380, 0, 540, 1080}, got[0].Target)
+		assert.Equal(t, Rect{500, 0, 500, 1000}, got[1].Source)
+		assert.Equal(t, Rect{0, 0, 540, 1080}, got[1].Target)
+	})
+}
+
+func TestPlacementsPerOutput(t *testing.T) {
+	config := Config{
+		Default: &Assignment{Path: "/default.png", Mode: ModeFill},
+		Outputs: map[string]Assignment{"HDMI-A-1": {Path: "/side.png", Mode: ModeFit}},
+	}
+	got := placements(config, sideBySide, sizeOf(1920, 1080))
+	require.Len(t, got, 2)
+	assert.Equal(t, "/default.png", got[0].Path)
+	assert.Equal(t, "/side.png", got[1].Path)
+	assert.Equal(t, Rect{0, 0, 1920, 1080}, got[1].Target)
+
+	config.Default = nil
+	got = placements(config, sideBySide, sizeOf(1920, 1080))
+	require.Len(t, got, 1)
+	assert.Equal(t, "HDMI-A-1", got[0].Output)
+
+	got = placements(config, sideBySide, func(string) imageSize { return imageSize{err: errors.New("gone")} })
+	assert.Equal(t, "gone", got[0].Error)
+}
+
+func TestManagerSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallpapers.json")
+	m := newManager(path, fixedSizes(map[string][2]int{"a.png": {3840, 1080}, "b.png": {1920, 1080}}))
+	m.SetOutputs(sideBySide)
+
+	ch := m.Subscribe("test")
+	defer m.Unsubscribe("test")
+
+	state, err := m.Set(SetOptions{Path: "/walls/a.png"})
+	require.NoError(t, err)
+	assert.Equal(t, ModeFill, state.Config.Default.Mode)
+	assert.Len(t, state.Placements, 2)
+	<-ch
+
+	state, err = m.Set(SetOptions{Path: "/walls/b.png", Output: "HDMI-A-1", Mode: ModeCenter})
+	require.NoError(t, err)
+	assert.Equal(t, "/walls/a.png", state.Placements[0].Path)
+	assert.Equal(t, "/walls/b.png", state.Placements[1].Path)
+
+	state, err = m.Set(SetOptions{Path: "/walls/a.png", Span: true})
+	require.NoError(t, err)
+	assert.True(t, state.Placements[0].Span)
+	assert.Equal(t, Rect{1920, 0, 1920, 1080}, state.Placements[1].Source)
+
+	// Spanning keeps the per-output choices for when it is replaced
+	state, err = m.Set(SetOptions{Path: "/walls/b.png", Output: "DP-1"})
+	require.NoError(t, err)
+	assert.Nil(t, state.Config.Span)
+	assert.Equal(t, "/walls/b.png", state.Placements[0].Path)
+	assert.Equal(t, ModeCenter, state.Placements[1].Mode)
+
+	reloaded := newManager(path, m.readSize)
+	reloaded.load()
+	reloaded.SetOutputs(sideBySide)
+	assert.Equal(t, state, reloaded.GetState())
+
+	state = m.Clear("DP-1")
+	assert.Equal(t, "/walls/a.png", state.Placements[0].Path)
+	state = m.Clear("")
+	assert.Empty(t, state.Placements)
+}
+
+func TestManagerSetErrors(t *testing.T) {
+	m := newManager(filepath.Join(t.TempDir(), "wallpapers.json"), fixedSizes(map[string][2]int{"a.png": {10, 10}}))
+
+	_, err := m.Set(SetOptions{Path: "/a.png", Mode: "tile"})
+	assert.Error(t, err)
+	_, err = m.Set(SetOptions{Path: "/a.png", Span: true, Output: "DP-1"})
+	assert.Error(t, err)
+	_, err = m.Set(SetOptions{Path: "/missing.png"})
+	assert.Error(t, err)
+	assert.Nil(t, m.GetState().Config.Default)
+}
+
+func TestManagerSetOutputsBroadcastsChanges(t *testing.T) {
+	m := newManager(filepath.Join(t.TempDir(), "wallpapers.json"), fixedSizes(nil))
+	ch := m.Subscribe("test")
+	defer m.Unsubscribe("test")
+
+	m.SetOutputs(sideBySide)
+	m.SetOutputs(append([]Output(nil), sideBySide...))
+	assert.Len(t, ch, 1)
+}
+
+func TestReadImageSize(t *testing.T) {
+	dir := t.TempDir()
+
+	pngPath := filepath.Join(dir, "a.png")
+	f, err := os.Create(pngPath)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, image.NewRGBA(image.Rect(0, 0, 64, 48))))
+	require.NoError(t, f.Close())
+
+	w, h, err := readImageSize(pngPath)
+	require.NoError(t, err)
+	assert.Equal(t, [2]int{64, 48}, [2]int{w, h})
+
+	// VP8X header for a 1921x1081 canvas
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x00\x00\x00\x00\x80\x07\x00\x38\x04\x00")
+	webpPath := filepath.Join(dir, "a.webp")
+	require.NoError(t, os.WriteFile(webpPath, webp, 0644))
+	w, h, err = readImageSize(webpPath)
+	require.NoError(t, err)
+	assert.Equal(t, [2]int{1921, 1081}, [2]int{w, h})
+
+	textPath := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(textPath, []byte("not an image"), 0644))
+	_, _, err = readImageSize(textPath)
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package wallpaper
+
+import "sync"
+
+// Mode is how an image is scaled to the area it covers: an output, or the
+// whole layout when spanning
+type Mode string
+
+const (
+	// ModeFill scales the image to cover the area and crops what overflows
+	ModeFill Mode = "fill"
+	// ModeFit scales the image to fit inside the area, leaving borders
+	ModeFit Mode = "fit"
+	// ModeCenter draws the image at its own pixel size, centered
+	ModeCenter Mode = "center"
+)
+
+func (m Mode) Valid() bool {
+	return m == ModeFill || m == ModeFit || m == ModeCenter
+}
+
+// Rect is an area in pixels
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (r Rect) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Output is a monitor's place in the compositor's logical layout
+type Output struct {
+	Name   string
+	X      int
+	Y      int
+	Width  int
+	Height int
+	Scale  float64
+}
+
+// Assignment is a wallpaper as chosen by the user
+type Assignment struct {
+	Path string `json:"path"`
+	Mode Mode   `json:"mode"`
+}
+
+// Config is what the user set. A span overrides everything else; outputs
+// without their own wallpaper show Default.
+type Config struct {
+	Default *Assignment           `json:"default,omitempty"`
+	Outputs map[string]Assignment `json:"outputs,omitempty"`
+	Span    *Assignment           `json:"span,omitempty"`
+}
+
+// Placement is what one output draws: the Source part of the image, in
+// image pixels, scaled into Target, in the output's logical coordinates.
+// Target is smaller than the output when the mode leaves borders.
+type Placement struct {
+	Output      string `json:"output"`
+	Path        string `json:"path"`
+	Mode        Mode   `json:"mode"`
+	Span        bool   `json:"span"`
+	ImageWidth  int    `json:"imageWidth"`
+	ImageHeight int    `json:"imageHeight"`
+	Source      Rect   `json:"source"`
+	Target      Rect   `json:"target"`
+	// Error is set when the image can no longer be read
+	Error string `json:"error,omitempty"`
+}
+
+type State struct {
+	Config     Config      `json:"config"`
+	Placements []Placement `json:"placements"`
+}
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// SetOptions are the parameters of wallpaper.set. With no Output and no
+// Span the image goes on every output, replacing per-output choices.
+type SetOptions struct {
+	Path   string
+	Output string
+	Mode   Mode
+	Span   bool
+}
+
+type imageSize struct {
+	width  int
+	height int
+	err    error
+}
+
+type Manager struct {
+	configPath string
+	readSize   func(path string) (int, int, error)
+
+	mutex   sync.Mutex
+	config  Config
+	outputs []Output
+	sizes   map[string]imageSize
+
+	subscribers map[string]chan State
+	subMutex    sync.RWMutex
+}
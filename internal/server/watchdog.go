@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/health"
+)
+
+var healthManager *health.Manager
+
+// errNotStarted is what a check reports for an enabled backend whose
+// manager failed to come up, so the watchdog keeps retrying its init
+var errNotStarted = errors.New("not started")
+
+// retiredManagerGrace is how long a replaced manager stays open so requests
+// that loaded it before the swap can finish
+const retiredManagerGrace = 10 * time.Second
+
+// watchedInitMu serializes creating watched managers, so startup and the
+// watchdog never bring up two instances of the same backend
+var watchedInitMu sync.Mutex
+
+func InitializeHealthManager() error {
+	if err := checkModuleEnabled("health"); err != nil {
+		return err
+	}
+
+	manager := health.NewManager(func(name string) {
+		notifyCapabilityChange()
+	})
+	if err := manager.SetInterval(getDaemonConfig().Duration("health.check-interval")); err != nil {
+		manager.Close()
+		return err
+	}
+	for _, backend := range watchedBackends() {
+		manager.Register(backend)
+	}
+
+	healthManager = manager
+
+	log.Info("Health watchdog started")
+	return nil
+}
+
+// initWatched runs the init of a watched manager outside the watchdog
+func initWatched(init func() error) error {
+	watchedInitMu.Lock()
+	defer watchedInitMu.Unlock()
+	return init()
+}
+
+type watchedManager interface {
+	HealthCheck() error
+	Close()
+}
+
+// watchedBackends are the services whose daemons can go away underneath us.
+// A broken manager keeps serving its last state until a replacement comes
+// up; clients see the replacement as a capability change and resubscribe.
+// A manager that never came up is retried the same way while its module is
+// enabled.
+func watchedBackends() []health.Backend {
+	return []health.Backend{
+		watchedBackend("network", &networkManager, InitializeNetworkManager),
+		watchedBackend("loginctl", &loginctlManager, InitializeLoginctlManager),
+		watchedBackend("freedesktop", &freedesktopManager, InitializeFreedeskManager),
+		watchedBackend("bluetooth", &bluezManager, InitializeBluezManager),
+		{
+			Name: "cups",
+			Check: func() error {
+				cm := cupsManager.Load()
+				if cm == nil {
+					return health.ErrNotRunning
+				}
+				return cm.HealthCheck()
+			},
+			Restart: func() error {
+				watchedInitMu.Lock()
+				defer watchedInitMu.Unlock()
+				// CUPS only runs while someone is subscribed
+				if cupsManager.Load() == nil {
+					return health.ErrNotRunning
+				}
+				return replaceManager(&cupsManager, InitializeCupsManager)
+			},
+		},
+		watchedBackend("brightness", &brightnessManager, InitializeBrightnessManager),
+		watchedBackend("power", &powerManager, InitializePowerManager),
+	}
+}
+
+func watchedBackend[T any, PT interface {
+	*T
+	watchedManager
+}](name string, current *atomic.Pointer[T], init func() error) health.Backend {
+	return health.Backend{
+		Name: name,
+		Check: func() error {
+			if m := current.Load(); m != nil {
+				return PT(m).HealthCheck()
+			}
+			if checkModuleEnabled(name) != nil {
+				return health.ErrNotRunning
+			}
+			return errNotStarted
+		},
+		Restart: func() error {
+			watchedInitMu.Lock()
+			defer watchedInitMu.Unlock()
+			if checkModuleEnabled(name) != nil {
+				return health.ErrNotRunning
+			}
+			return replaceManager[T, PT](current, init)
+		},
+	}
+}
+
+// replaceManager starts a fresh manager through init, which only swaps the
+// global on success, and closes the old one once requests still holding it
+// have had time to finish. A manager that is healthy again by now, e.g.
+// because startup brought it up late, is kept. The caller holds
+// watchedInitMu.
+func replaceManager[T any, PT interface {
+	*T
+	watchedManager
+}](current *atomic.Pointer[T], init func() error) error {
+	old := current.Load()
+	if old != nil && PT(old).HealthCheck() == nil {
+		return nil
+	}
+	if err := init(); err != nil {
+		return err
+	}
+	if old != nil {
+		time.AfterFunc(retiredManagerGrace, PT(old).Close)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/server/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWatched struct {
+	healthErr error
+	closed    atomic.Bool
+}
+
+func (f *fakeWatched) HealthCheck() error { return f.healthErr }
+func (f *fakeWatched) Close()             { f.closed.Store(true) }
+
+func TestWatchedBackend_RetriesManagerThatNeverStarted(t *testing.T) {
+	var current atomic.Pointer[fakeWatched]
+	inits := 0
+	backend := watchedBackend("network", &current, func() error {
+		inits++
+		if inits == 1 {
+			return errors.New("NetworkManager not on the bus")
+		}
+		current.Store(&fakeWatched{})
+		return nil
+	})
+
+	err := backend.Check()
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, health.ErrNotRunning)
+
+	assert.Error(t, backend.Restart())
+	assert.Nil(t, current.Load())
+	require.NoError(t, backend.Restart())
+	assert.NotNil(t, current.Load())
+	assert.NoError(t, backend.Check())
+}
+
+func TestReplaceManager_KeepsHealthyManager(t *testing.T) {
+	var current atomic.Pointer[fakeWatched]
+	healthy := &fakeWatched{}
+	current.Store(healthy)
+
+	require.NoError(t, replaceManager(&current, func() error {
+		t.Fatal("init called for a healthy manager")
+		return nil
+	}))
+	assert.Same(t, healthy, current.Load())
+	assert.False(t, healthy.closed.Load())
+}
+
+func TestReplaceManager_SwapsBeforeClosing(t *testing.T) {
+	var current atomic.Pointer[fakeWatched]
+	broken := &fakeWatched{healthErr: errors.New("gone")}
+	current.Store(broken)
+
+	fresh := &fakeWatched{}
+	require.NoError(t, replaceManager(&current, func() error {
+		current.Store(fresh)
+		return nil
+	}))
+	assert.Same(t, fresh, current.Load())
+	assert.False(t, broken.closed.Load(), "old manager closed before in-flight requests finished")
+}
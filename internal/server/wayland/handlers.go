@@ -197,6 +197,7 @@ func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
 
 	for state := range stateChan {
 		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
 			Result: &state,
 		}); err != nil {
 			return
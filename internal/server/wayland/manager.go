@@ -10,10 +10,12 @@ import (
 
 	"github.com/godbus/dbus/v5"
 	wlclient "github.com/yaslama/go-wayland/wayland/client"
+	"github.com/yaslama/go-wayland/wayland/unstable/xdg-output-v1"
 	"golang.org/x/sys/unix"
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/outputs"
 	"github.com/AvengeMedia/danklinux/internal/proto/wlr_gamma_control"
 )
 
@@ -34,6 +36,7 @@ func NewManager(display *wlclient.Display, config Config) (*Manager, error) {
 		dbusSignal:     make(chan *dbus.Signal, 16),
 		transitionChan: make(chan int, 1),
 	}
+	m.outputInfo = outputs.NewTracker(m.outputsChanged)
 
 	if err := m.setupRegistry(); err != nil {
 		return nil, err
@@ -176,6 +179,8 @@ func (m *Manager) setupRegistry() error {
 			} else {
 				log.Errorf("setupRegistry: failed to bind gamma control: %v", err)
 			}
+		case xdg_output.OutputManagerInterfaceName:
+			m.outputInfo.BindManager(registry, e.Name, e.Version)
 		case "wl_output":
 			log.Debugf("Global event: found wl_output (name=%d)", e.Name)
 			output := wlclient.NewOutput(ctx)
@@ -190,12 +195,15 @@ func (m *Manager) setupRegistry() error {
 				output.SetNameHandler(func(ev wlclient.OutputNameEvent) {
 					log.Infof("Output %d name: %s", outputID, ev.Name)
 					outputNames[outputID] = ev.Name
+					m.outputInfo.SetName(outputID, ev.Name)
 					isVirtual := len(ev.Name) >= 9 && ev.Name[:9] == "HEADLESS-"
 					if isVirtual {
 						log.Infof("Output %d identified as virtual", outputID)
 					}
 				})
 
+				m.outputInfo.AddOutput(output, e.Name)
+
 				if gammaMgr != nil {
 					outputs = append(outputs, output)
 					outputRegNames[outputID] = e.Name
@@ -235,6 +243,7 @@ func (m *Manager) setupRegistry() error {
 	})
 
 	registry.SetGlobalRemoveHandler(func(e wlclient.RegistryGlobalRemoveEvent) {
+		m.outputInfo.Remove(e.Name)
 		m.post(func() {
 			m.outputsMutex.Lock()
 			defer m.outputsMutex.Unlock()
@@ -375,6 +384,7 @@ func (m *Manager) addOutputControl(output *wlclient.Output) error {
 	var outputName string
 	output.SetNameHandler(func(ev wlclient.OutputNameEvent) {
 		outputName = ev.Name
+		m.outputInfo.SetName(outputID, ev.Name)
 		m.outputsMutex.Lock()
 		if outState, exists := m.outputs[outputID]; exists {
 			outState.name = ev.Name
@@ -904,6 +914,7 @@ func (m *Manager) updateState() {
 		SunriseTime:    sunrise,
 		SunsetTime:     sunset,
 		IsDay:          isDay,
+		Outputs:        m.outputInfo.Snapshot(),
 	}
 
 	m.stateMutex.Lock()
@@ -913,6 +924,23 @@ func (m *Manager) updateState() {
 	m.notifySubscribers()
 }
 
+// outputsChanged refreshes the output list without recomputing sun times
+func (m *Manager) outputsChanged() {
+	outputs := m.outputInfo.Snapshot()
+
+	m.stateMutex.Lock()
+	if m.state == nil {
+		m.stateMutex.Unlock()
+		return
+	}
+	newState := *m.state
+	newState.Outputs = outputs
+	m.state = &newState
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
 func (m *Manager) notifier() {
 	defer m.notifierWg.Done()
 	const minGap = 100 * time.Millisecond
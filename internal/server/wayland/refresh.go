@@ -0,0 +1,301 @@
+package wayland
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AvengeMedia/danklinux/internal/compositor"
+)
+
+// Mode is a display mode as reported by the compositor
+type Mode struct {
+	Width   int     `json:"width"`
+	Height  int     `json:"height"`
+	Refresh float64 `json:"refresh"`
+}
+
+func (m Mode) String() string {
+	return fmt.Sprintf("%dx%d@%.3f", m.Width, m.Height, m.Refresh)
+}
+
+// compositorOutput is the subset of compositor output state needed to pick
+// and restore modes
+type compositorOutput struct {
+	Name    string
+	Current Mode
+	Modes   []Mode
+	X, Y    int
+	Scale   float64
+}
+
+type modeBackend interface {
+	outputs() ([]compositorOutput, error)
+	setMode(out compositorOutput, mode Mode) error
+}
+
+// RefreshRateController lowers output refresh rates through the compositor's
+// IPC and remembers the previous modes so they can be restored. wlr output
+// management is not used since niri and Hyprland would revert it on their
+// next config reload.
+type RefreshRateController struct {
+	mu      sync.Mutex
+	backend modeBackend
+	saved   map[string]Mode
+}
+
+// NewRefreshRateController detects niri or Hyprland from the environment.
+// Other compositors get a controller that reports an error on use.
+func NewRefreshRateController() *RefreshRateController {
+	var backend modeBackend
+	switch compositor.Detect(os.Getenv) {
+	case compositor.Niri:
+		backend = niriModes{run: compositor.Run}
+	case compositor.Hyprland:
+		backend = hyprlandModes{run: compositor.Run}
+	}
+	return newRefreshRateController(backend)
+}
+
+func newRefreshRateController(backend modeBackend) *RefreshRateController {
+	return &RefreshRateController{
+		backend: backend,
+		saved:   make(map[string]Mode),
+	}
+}
+
+// LimitRefreshRate switches every output running faster than hz to the
+// fastest mode at or below it with the same resolution
+func (c *RefreshRateController) LimitRefreshRate(hz float64) error {
+	if c.backend == nil {
+		return fmt.Errorf("refresh rate control is not supported on this compositor")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	outputs, err := c.backend.outputs()
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, out := range outputs {
+		mode, ok := pickMode(out.Modes, out.Current, hz)
+		if !ok {
+			continue
+		}
+		if err := c.backend.setMode(out, mode); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if _, exists := c.saved[out.Name]; !exists {
+			c.saved[out.Name] = out.Current
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to limit refresh rate: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// RestoreRefreshRate puts back the modes replaced by LimitRefreshRate.
+// Outputs that were unplugged meanwhile are forgotten.
+func (c *RefreshRateController) RestoreRefreshRate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.backend == nil || len(c.saved) == 0 {
+		return nil
+	}
+
+	outputs, err := c.backend.outputs()
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, out := range outputs {
+		mode, ok := c.saved[out.Name]
+		if !ok {
+			continue
+		}
+		if !sameMode(mode, out.Current) {
+			if err := c.backend.setMode(out, mode); err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+		}
+	}
+	c.saved = make(map[string]Mode)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to restore refresh rate: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// pickMode returns the fastest mode at or below hz with the current
+// resolution. Reports false when the current mode already qualifies.
+func pickMode(modes []Mode, current Mode, hz float64) (Mode, bool) {
+	// Allow for 59.94 vs 60 style rounding in the target
+	limit := hz + 0.5
+	if current.Refresh <= limit {
+		return Mode{}, false
+	}
+
+	var best Mode
+	found := false
+	for _, mode := range modes {
+		if mode.Width != current.Width || mode.Height != current.Height {
+			continue
+		}
+		if mode.Refresh > limit {
+			continue
+		}
+		if !found || mode.Refresh > best.Refresh {
+			best = mode
+			found = true
+		}
+	}
+	return best, found
+}
+
+func sameMode(a, b Mode) bool {
+	return a.Width == b.Width && a.Height == b.Height && math.Abs(a.Refresh-b.Refresh) < 0.01
+}
+
+type niriModes struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+type niriOutput struct {
+	Name  string `json:"name"`
+	Modes []struct {
+		Width       int `json:"width"`
+		Height      int `json:"height"`
+		RefreshRate int `json:"refresh_rate"`
+	} `json:"modes"`
+	CurrentMode *int `json:"current_mode"`
+}
+
+func (n niriModes) outputs() ([]compositorOutput, error) {
+	data, err := n.run("niri", "msg", "--json", "outputs")
+	if err != nil {
+		return nil, err
+	}
+	return parseNiriOutputs(data)
+}
+
+func parseNiriOutputs(data []byte) ([]compositorOutput, error) {
+	var raw map[string]niriOutput
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse niri outputs: %w", err)
+	}
+
+	var outputs []compositorOutput
+	for name, o := range raw {
+		if o.CurrentMode == nil || *o.CurrentMode < 0 || *o.CurrentMode >= len(o.Modes) {
+			continue
+		}
+		out := compositorOutput{Name: name}
+		for _, m := range o.Modes {
+			out.Modes = append(out.Modes, Mode{Width: m.Width, Height: m.Height, Refresh: float64(m.RefreshRate) / 1000})
+		}
+		out.Current = out.Modes[*o.CurrentMode]
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+func (n niriModes) setMode(out compositorOutput, mode Mode) error {
+	_, err := n.run("niri", "msg", "output", out.Name, "mode", mode.String())
+	return err
+}
+
+type hyprlandModes struct {
+	run func(name string, args ...string) ([]byte, error)
+}
+
+type hyprlandMonitor struct {
+	Name           string   `json:"name"`
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	RefreshRate    float64  `json:"refreshRate"`
+	X              int      `json:"x"`
+	Y              int      `json:"y"`
+	Scale          float64  `json:"scale"`
+	AvailableModes []string `json:"availableModes"`
+	Disabled       bool     `json:"disabled"`
+}
+
+func (h hyprlandModes) outputs() ([]compositorOutput, error) {
+	data, err := h.run("hyprctl", "-j", "monitors")
+	if err != nil {
+		return nil, err
+	}
+	return parseHyprlandMonitors(data)
+}
+
+func parseHyprlandMonitors(data []byte) ([]compositorOutput, error) {
+	var monitors []hyprlandMonitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		return nil, fmt.Errorf("failed to parse hyprland monitors: %w", err)
+	}
+
+	var outputs []compositorOutput
+	for _, m := range monitors {
+		if m.Disabled {
+			continue
+		}
+		out := compositorOutput{
+			Name:    m.Name,
+			Current: Mode{Width: m.Width, Height: m.Height, Refresh: m.RefreshRate},
+			X:       m.X,
+			Y:       m.Y,
+			Scale:   m.Scale,
+		}
+		for _, s := range m.AvailableModes {
+			if mode, ok := parseHyprlandMode(s); ok {
+				out.Modes = append(out.Modes, mode)
+			}
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+// parseHyprlandMode reads "2560x1440@165.00Hz"
+func parseHyprlandMode(s string) (Mode, bool) {
+	res, rate, ok := strings.Cut(strings.TrimSuffix(s, "Hz"), "@")
+	if !ok {
+		return Mode{}, false
+	}
+	w, h, ok := strings.Cut(res, "x")
+	if !ok {
+		return Mode{}, false
+	}
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	refresh, err3 := strconv.ParseFloat(rate, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return Mode{}, false
+	}
+	return Mode{Width: width, Height: height, Refresh: refresh}, true
+}
+
+func (h hyprlandModes) setMode(out compositorOutput, mode Mode) error {
+	scale := out.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	rule := fmt.Sprintf("%s,%dx%d@%.2f,%dx%d,%s", out.Name, mode.Width, mode.Height, mode.Refresh, out.X, out.Y,
+		strconv.FormatFloat(scale, 'f', -1, 64))
+	_, err := h.run("hyprctl", "keyword", "monitor", rule)
+	return err
+}
@@ -0,0 +1,129 @@
+package wayland
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPickMode(t *testing.T) {
+	modes := []Mode{
+		{2560, 1440, 165},
+		{2560, 1440, 144},
+		{2560, 1440, 59.951},
+		{1920, 1080, 60},
+	}
+
+	tests := []struct {
+		name    string
+		current Mode
+		hz      float64
+		want    Mode
+		wantOK  bool
+	}{
+		{"lowers_to_60", Mode{2560, 1440, 165}, 60, Mode{2560, 1440, 59.951}, true},
+		{"keeps_resolution", Mode{2560, 1440, 165}, 150, Mode{2560, 1440, 144}, true},
+		{"already_below", Mode{2560, 1440, 59.951}, 60, Mode{}, false},
+		{"no_candidate", Mode{2560, 1440, 165}, 30, Mode{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pickMode(modes, tt.current, tt.hz)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("pickMode() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseHyprlandMonitors(t *testing.T) {
+	data := `[{"name":"eDP-1","width":2560,"height":1600,"refreshRate":165.00,"x":0,"y":0,"scale":1.6,
+		"availableModes":["2560x1600@165.00Hz","2560x1600@60.00Hz","bogus"],"disabled":false},
+		{"name":"HDMI-A-1","width":1920,"height":1080,"refreshRate":60,"disabled":true}]`
+
+	outputs, err := parseHyprlandMonitors([]byte(data))
+	if err != nil {
+		t.Fatalf("parseHyprlandMonitors() error = %v", err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("got %d outputs, want 1", len(outputs))
+	}
+	out := outputs[0]
+	if out.Name != "eDP-1" || out.Scale != 1.6 || len(out.Modes) != 2 {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if out.Current != (Mode{2560, 1600, 165}) {
+		t.Errorf("current = %v", out.Current)
+	}
+}
+
+func TestParseNiriOutputs(t *testing.T) {
+	data := `{"eDP-1":{"name":"eDP-1","modes":[{"width":2880,"height":1800,"refresh_rate":120000},
+		{"width":2880,"height":1800,"refresh_rate":60001}],"current_mode":0},
+		"DP-2":{"name":"DP-2","modes":[],"current_mode":null}}`
+
+	outputs, err := parseNiriOutputs([]byte(data))
+	if err != nil {
+		t.Fatalf("parseNiriOutputs() error = %v", err)
+	}
+	if len(outputs) != 1 {
+		t.Fatalf("got %d outputs, want 1", len(outputs))
+	}
+	if outputs[0].Current != (Mode{2880, 1800, 120}) || outputs[0].Modes[1].Refresh != 60.001 {
+		t.Errorf("unexpected output %+v", outputs[0])
+	}
+}
+
+type fakeModeBackend struct {
+	outs  []compositorOutput
+	calls []string
+}
+
+func (f *fakeModeBackend) outputs() ([]compositorOutput, error) {
+	return f.outs, nil
+}
+
+func (f *fakeModeBackend) setMode(out compositorOutput, mode Mode) error {
+	f.calls = append(f.calls, out.Name+" "+mode.String())
+	for i := range f.outs {
+		if f.outs[i].Name == out.Name {
+			f.outs[i].Current = mode
+		}
+	}
+	return nil
+}
+
+func TestRefreshRateControllerRestores(t *testing.T) {
+	backend := &fakeModeBackend{outs: []compositorOutput{{
+		Name:    "eDP-1",
+		Current: Mode{2560, 1600, 165},
+		Modes:   []Mode{{2560, 1600, 165}, {2560, 1600, 60}},
+	}}}
+	c := newRefreshRateController(backend)
+
+	if err := c.LimitRefreshRate(60); err != nil {
+		t.Fatalf("LimitRefreshRate() error = %v", err)
+	}
+	// A second limit must not overwrite the saved original mode
+	if err := c.LimitRefreshRate(60); err != nil {
+		t.Fatalf("LimitRefreshRate() error = %v", err)
+	}
+	if err := c.RestoreRefreshRate(); err != nil {
+		t.Fatalf("RestoreRefreshRate() error = %v", err)
+	}
+
+	want := "eDP-1 2560x1600@60.000\neDP-1 2560x1600@165.000"
+	if got := strings.Join(backend.calls, "\n"); got != want {
+		t.Errorf("calls =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRefreshRateControllerUnsupported(t *testing.T) {
+	c := newRefreshRateController(nil)
+	if err := c.LimitRefreshRate(60); err == nil {
+		t.Error("expected error without a compositor backend")
+	}
+	if err := c.RestoreRefreshRate(); err != nil {
+		t.Errorf("RestoreRefreshRate() error = %v", err)
+	}
+}
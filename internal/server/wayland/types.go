@@ -2,14 +2,19 @@ package wayland
 
 import (
 	"math"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/outputs"
 	"github.com/godbus/dbus/v5"
 	wlclient "github.com/yaslama/go-wayland/wayland/client"
 )
 
+// OutputInfo is the geometry and scale of one output as reported in State
+type OutputInfo = outputs.Info
+
 type Config struct {
 	Outputs        []string
 	LowTemp        int
@@ -25,12 +30,13 @@ type Config struct {
 }
 
 type State struct {
-	Config         Config    `json:"config"`
-	CurrentTemp    int       `json:"currentTemp"`
-	NextTransition time.Time `json:"nextTransition"`
-	SunriseTime    time.Time `json:"sunriseTime"`
-	SunsetTime     time.Time `json:"sunsetTime"`
-	IsDay          bool      `json:"isDay"`
+	Config         Config       `json:"config"`
+	CurrentTemp    int          `json:"currentTemp"`
+	NextTransition time.Time    `json:"nextTransition"`
+	SunriseTime    time.Time    `json:"sunriseTime"`
+	SunsetTime     time.Time    `json:"sunsetTime"`
+	IsDay          bool         `json:"isDay"`
+	Outputs        []OutputInfo `json:"outputs"`
 }
 
 type cmd struct {
@@ -76,6 +82,8 @@ type Manager struct {
 
 	dbusConn   *dbus.Conn
 	dbusSignal chan *dbus.Signal
+
+	outputInfo *outputs.Tracker
 }
 
 type outputState struct {
@@ -190,5 +198,8 @@ func stateChanged(old, new *State) bool {
 	if old.Config.Enabled != new.Config.Enabled {
 		return true
 	}
+	if !slices.Equal(old.Outputs, new.Outputs) {
+		return true
+	}
 	return false
 }
@@ -309,6 +309,20 @@ func TestStateChanged(t *testing.T) {
 			},
 			wantChanged: true,
 		},
+		{
+			name: "outputs_changed",
+			old:  baseState,
+			new: &State{
+				CurrentTemp:    baseState.CurrentTemp,
+				NextTransition: baseState.NextTransition,
+				SunriseTime:    baseState.SunriseTime,
+				SunsetTime:     baseState.SunsetTime,
+				IsDay:          baseState.IsDay,
+				Config:         baseState.Config,
+				Outputs:        []OutputInfo{{Name: "DP-1", Scale: 1.5}},
+			},
+			wantChanged: true,
+		},
 	}
 
 	for _, tt := range tests {
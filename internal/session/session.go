@@ -0,0 +1,138 @@
+// Package session resolves the logind session and seat the daemon belongs
+// to, so several graphical sessions on one machine each get their own socket
+// and only the session in front of the monitors drives shared hardware.
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+
+	"github.com/AvengeMedia/danklinux/internal/session/socket"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	logindDest   = "org.freedesktop.login1"
+	logindPath   = "/org/freedesktop/login1"
+	managerIface = "org.freedesktop.login1.Manager"
+	sessionIface = "org.freedesktop.login1.Session"
+	userIface    = "org.freedesktop.login1.User"
+)
+
+// Info identifies the session. ID and Path are empty when logind is not
+// available, in which case every check below treats the session as active.
+type Info struct {
+	ID   string
+	Path dbus.ObjectPath
+	Seat string
+	User string
+	UID  int
+}
+
+var (
+	current     Info
+	currentOnce sync.Once
+)
+
+// Current resolves the daemon's session once: $XDG_SESSION_ID, else the
+// session the process runs in, else the user's display session, which covers
+// a daemon started from a systemd user unit.
+func Current() Info {
+	currentOnce.Do(func() {
+		current = resolve()
+	})
+	return current
+}
+
+func resolve() Info {
+	info := Info{UID: os.Getuid(), User: strconv.Itoa(os.Getuid())}
+	if u, err := user.Current(); err == nil {
+		info.User = u.Username
+	}
+
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		info.ID = socket.SessionFromEnv()
+		return info
+	}
+
+	path, err := findSession(conn)
+	if err != nil {
+		info.ID = socket.SessionFromEnv()
+		return info
+	}
+	info.Path = path
+
+	obj := conn.Object(logindDest, path)
+	if v, err := obj.GetProperty(sessionIface + ".Id"); err == nil {
+		info.ID, _ = v.Value().(string)
+	}
+	if v, err := obj.GetProperty(sessionIface + ".Seat"); err == nil {
+		info.Seat = seatName(v.Value())
+	}
+	if info.Seat == "" {
+		info.Seat = os.Getenv("XDG_SEAT")
+	}
+	return info
+}
+
+func findSession(conn *dbus.Conn) (dbus.ObjectPath, error) {
+	manager := conn.Object(logindDest, logindPath)
+
+	var path dbus.ObjectPath
+	if id := socket.SessionFromEnv(); id != "" {
+		if err := manager.Call(managerIface+".GetSession", 0, id).Store(&path); err == nil {
+			return path, nil
+		}
+	}
+	if err := manager.Call(managerIface+".GetSessionByPID", 0, uint32(os.Getpid())).Store(&path); err == nil {
+		return path, nil
+	}
+
+	var userPath dbus.ObjectPath
+	if err := manager.Call(managerIface+".GetUser", 0, uint32(os.Getuid())).Store(&userPath); err != nil {
+		return "", fmt.Errorf("no logind user for uid %d: %w", os.Getuid(), err)
+	}
+	v, err := conn.Object(logindDest, userPath).GetProperty(userIface + ".Display")
+	if err != nil {
+		return "", err
+	}
+	display, ok := v.Value().([]interface{})
+	if !ok || len(display) != 2 {
+		return "", fmt.Errorf("unexpected Display property %v", v.Value())
+	}
+	if path, ok = display[1].(dbus.ObjectPath); !ok || path == "/" {
+		return "", fmt.Errorf("user has no display session")
+	}
+	return path, nil
+}
+
+// seatName reads the (so) Seat property
+func seatName(value interface{}) string {
+	if seat, ok := value.([]interface{}); ok && len(seat) == 2 {
+		name, _ := seat[0].(string)
+		return name
+	}
+	return ""
+}
+
+// IsActive reports whether logind has the session in the foreground of its
+// seat. Without logind the answer is always yes.
+func (i Info) IsActive() bool {
+	if i.Path == "" {
+		return true
+	}
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return true
+	}
+	v, err := conn.Object(logindDest, i.Path).GetProperty(sessionIface + ".Active")
+	if err != nil {
+		return true
+	}
+	active, _ := v.Value().(bool)
+	return active
+}
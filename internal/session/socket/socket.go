@@ -0,0 +1,93 @@
+// Package socket names the daemon's IPC sockets. It is kept apart from the
+// session package so clients can find a server without linking D-Bus.
+package socket
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	socketPrefix    = "danklinux-"
+	socketSuffix    = ".sock"
+	maxSessionIDLen = 32
+)
+
+// SessionFromEnv is the session ID the environment names, empty outside a session
+func SessionFromEnv() string {
+	id := os.Getenv("XDG_SESSION_ID")
+	if !validID(id) {
+		return ""
+	}
+	return id
+}
+
+// logind session IDs are short alphanumerics such as "2" or "c1"
+func validID(id string) bool {
+	if id == "" || len(id) > maxSessionIDLen {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Name is the IPC socket file name for the server process pid. The
+// session ID is part of the name so clients can find their own session's
+// server when one user runs several.
+func Name(sessionID string, pid int) string {
+	if !validID(sessionID) {
+		return fmt.Sprintf("%s%d%s", socketPrefix, pid, socketSuffix)
+	}
+	return fmt.Sprintf("%s%s-%d%s", socketPrefix, sessionID, pid, socketSuffix)
+}
+
+// Parse undoes Name. Sockets from before session namespacing
+// parse with an empty session.
+func Parse(name string) (sessionID string, pid int, ok bool) {
+	rest, ok := strings.CutPrefix(name, socketPrefix)
+	if !ok {
+		return "", 0, false
+	}
+	if rest, ok = strings.CutSuffix(rest, socketSuffix); !ok {
+		return "", 0, false
+	}
+
+	pidStr := rest
+	if i := strings.LastIndexByte(rest, '-'); i >= 0 {
+		sessionID, pidStr = rest[:i], rest[i+1:]
+		if !validID(sessionID) {
+			return "", 0, false
+		}
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil || pid <= 0 {
+		return "", 0, false
+	}
+	return sessionID, pid, true
+}
+
+// FallbackDir is a private per-user directory under the temp dir for when
+// $XDG_RUNTIME_DIR is unset, so users never share a socket directory
+func FallbackDir() string {
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("dms-%d", os.Getuid()))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return os.TempDir()
+	}
+	if info, err := os.Lstat(dir); err != nil || !info.IsDir() || !ownedByUs(info) {
+		return os.TempDir()
+	}
+	return dir
+}
+
+func ownedByUs(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid() && info.Mode().Perm()&0077 == 0
+}
@@ -0,0 +1,68 @@
+package socket
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "danklinux-2-1234.sock", Name("2", 1234))
+	assert.Equal(t, "danklinux-c1-99.sock", Name("c1", 99))
+	assert.Equal(t, "danklinux-1234.sock", Name("", 1234))
+	assert.Equal(t, "danklinux-1234.sock", Name("../x", 1234))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		pid     int
+		ok      bool
+	}{
+		{"danklinux-2-1234.sock", "2", 1234, true},
+		{"danklinux-c1-99.sock", "c1", 99, true},
+		{"danklinux-1234.sock", "", 1234, true},
+		{"danklinux-1234.pid", "", 0, false},
+		{"danklinux-fp-12.sh", "", 0, false},
+		{"danklinux-a-b.sock", "", 0, false},
+		{"danklinux-x.y-1.sock", "", 0, false},
+		{"other-1.sock", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, pid, ok := Parse(tt.name)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.session, session)
+			assert.Equal(t, tt.pid, pid)
+		})
+	}
+
+	for _, id := range []string{"", "3", "c12"} {
+		session, pid, ok := Parse(Name(id, 42))
+		assert.True(t, ok)
+		assert.Equal(t, id, session)
+		assert.Equal(t, 42, pid)
+	}
+}
+
+func TestSessionFromEnv(t *testing.T) {
+	t.Setenv("XDG_SESSION_ID", "5")
+	assert.Equal(t, "5", SessionFromEnv())
+	t.Setenv("XDG_SESSION_ID", "5/../..")
+	assert.Equal(t, "", SessionFromEnv())
+}
+
+func TestFallbackDir(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	dir := FallbackDir()
+	info, err := os.Stat(dir)
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	// a directory another user could write to is not used
+	assert.NoError(t, os.Chmod(dir, 0777))
+	assert.Equal(t, os.TempDir(), FallbackDir())
+}
@@ -0,0 +1,261 @@
+// Package tomlite reads and edits the small subset of TOML used by the
+// daemon's own config files: comments, [table] and [[array]] headers and
+// single-line key = value pairs holding strings, numbers and booleans.
+// Edits are made line by line so comments and layout survive.
+package tomlite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Table is one header and the keys under it. Keys before the first header
+// land in a table with an empty name. Values are string, int64, float64 or
+// bool; Lines holds where each key was set for error messages.
+type Table struct {
+	Name   string
+	Array  bool
+	Line   int
+	Values map[string]any
+	Lines  map[string]int
+}
+
+func newTable(name string, array bool, line int) Table {
+	return Table{Name: name, Array: array, Line: line, Values: make(map[string]any), Lines: make(map[string]int)}
+}
+
+// Parse returns the tables in file order. The root table is only included
+// when it holds keys.
+func Parse(data string) ([]Table, error) {
+	tables := []Table{newTable("", false, 0)}
+	defined := make(map[string]bool)
+
+	for i, rawLine := range strings.Split(data, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripComment(rawLine))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name, array, err := parseHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			if !array {
+				if defined[name] {
+					return nil, fmt.Errorf("line %d: table [%s] is defined twice", lineNo, name)
+				}
+				defined[name] = true
+			}
+			tables = append(tables, newTable(name, array, lineNo))
+			continue
+		}
+
+		key, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNo)
+		}
+
+		current := &tables[len(tables)-1]
+		if _, dup := current.Values[key]; dup {
+			return nil, fmt.Errorf("line %d: %s is set twice in the same table", lineNo, key)
+		}
+
+		value, err := ParseValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", lineNo, key, err)
+		}
+		current.Values[key] = value
+		current.Lines[key] = lineNo
+	}
+
+	if len(tables[0].Values) == 0 {
+		tables = tables[1:]
+	}
+	return tables, nil
+}
+
+func parseHeader(line string) (string, bool, error) {
+	header := strings.ReplaceAll(line, " ", "")
+	array := strings.HasPrefix(header, "[[")
+	var name string
+	switch {
+	case array && strings.HasSuffix(header, "]]"):
+		name = header[2 : len(header)-2]
+	case !array && strings.HasSuffix(header, "]"):
+		name = header[1 : len(header)-1]
+	default:
+		return "", false, fmt.Errorf("malformed table header %s", line)
+	}
+	if name == "" {
+		return "", false, fmt.Errorf("empty table name")
+	}
+	return name, array, nil
+}
+
+// stripComment drops a trailing # comment outside of quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// ParseValue reads a single TOML scalar
+func ParseValue(s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("missing value")
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s[0] == '"':
+		if len(s) < 2 || s[len(s)-1] != '"' {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		value, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", s)
+		}
+		return value, nil
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return s[1 : len(s)-1], nil
+	}
+
+	clean := strings.ReplaceAll(s, "_", "")
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", s)
+}
+
+// FormatValue renders a string, integer, float or bool as TOML
+func FormatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return s
+	}
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// locate finds the line range of a plain table and the line holding key in
+// it. Ranges are [start, end) over lines; start is -1 when the table is
+// missing and keyLine is -1 when the key is.
+func locate(lines []string, table, key string) (start, end, keyLine int) {
+	start, end, keyLine = -1, len(lines), -1
+	current := ""
+	inTable := table == ""
+	if inTable {
+		start = 0
+	}
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(stripComment(raw))
+		if strings.HasPrefix(line, "[") {
+			if inTable {
+				end = i
+				break
+			}
+			name, array, err := parseHeader(line)
+			current = name
+			if err == nil && !array && current == table {
+				inTable = true
+				start = i + 1
+			}
+			continue
+		}
+		if !inTable {
+			continue
+		}
+		k, _, ok := strings.Cut(line, "=")
+		if ok && strings.Trim(strings.TrimSpace(k), `"`) == key {
+			keyLine = i
+		}
+	}
+	return start, end, keyLine
+}
+
+// Set writes key = value (an already formatted TOML literal) into the plain
+// table, replacing an existing line in place and keeping its comment, or
+// appending the key and, if needed, the table
+func Set(data, table, key, literal string) string {
+	lines := strings.Split(data, "\n")
+	start, end, keyLine := locate(lines, table, key)
+	entry := key + " = " + literal
+
+	if keyLine >= 0 {
+		raw := lines[keyLine]
+		indent := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		comment := raw[len(stripComment(raw)):]
+		if comment != "" {
+			comment = " " + comment
+		}
+		lines[keyLine] = indent + entry + comment
+		return strings.Join(lines, "\n")
+	}
+
+	if start >= 0 {
+		insert := end
+		for insert > start && strings.TrimSpace(lines[insert-1]) == "" {
+			insert--
+		}
+		lines = append(lines[:insert], append([]string{entry}, lines[insert:]...)...)
+		return strings.Join(lines, "\n")
+	}
+
+	out := strings.TrimRight(data, "\n")
+	if out != "" {
+		out += "\n\n"
+	}
+	return out + "[" + table + "]\n" + entry + "\n"
+}
+
+// Unset removes key from the plain table. Tables left empty are kept so
+// their comments are not lost.
+func Unset(data, table, key string) (string, bool) {
+	lines := strings.Split(data, "\n")
+	_, _, keyLine := locate(lines, table, key)
+	if keyLine < 0 {
+		return data, false
+	}
+	lines = append(lines[:keyLine], lines[keyLine+1:]...)
+	return strings.Join(lines, "\n"), true
+}
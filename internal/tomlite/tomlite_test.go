@@ -0,0 +1,111 @@
+package tomlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tables, err := Parse(`top = 1
+
+# comment
+[server]
+socket-dir = "/run/user/1000" # trailing
+
+[[rule]]
+a = 'x'
+
+[[rule]]
+a = 1_000
+b = -2.5
+`)
+	require.NoError(t, err)
+	require.Len(t, tables, 4)
+
+	assert.Equal(t, "", tables[0].Name)
+	assert.Equal(t, int64(1), tables[0].Values["top"])
+	assert.Equal(t, "server", tables[1].Name)
+	assert.Equal(t, "/run/user/1000", tables[1].Values["socket-dir"])
+	assert.Equal(t, 5, tables[1].Lines["socket-dir"])
+	assert.True(t, tables[2].Array)
+	assert.Equal(t, int64(1000), tables[3].Values["a"])
+	assert.Equal(t, -2.5, tables[3].Values["b"])
+}
+
+func TestParse_Errors(t *testing.T) {
+	for data, want := range map[string]string{
+		"[a]\n[a]\n":       "line 2: table [a] is defined twice",
+		"[a\n":             "line 1: malformed table header",
+		"[]\n":             "empty table name",
+		"a = 1\na = 2\n":   "line 2: a is set twice",
+		"a = [1, 2]\n":     "unsupported value",
+		"a = \"open\n":     "unterminated string",
+		"just a line\n":    "expected key = value",
+		" = 3\n":           "empty key",
+		"a = 'unclosed\n":  "unterminated string",
+		"[x]\nb = nope\n":  "line 2: b: unsupported value nope",
+		"[x]\nb = \"\\q\"": "invalid string",
+	} {
+		_, err := Parse(data)
+		if assert.Error(t, err, data) {
+			assert.Contains(t, err.Error(), want, data)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, `"a \"b\""`, FormatValue(`a "b"`))
+	assert.Equal(t, "true", FormatValue(true))
+	assert.Equal(t, "42", FormatValue(42))
+	assert.Equal(t, "3.0", FormatValue(3.0))
+	assert.Equal(t, "0.25", FormatValue(0.25))
+}
+
+func TestSet(t *testing.T) {
+	data := `# daemon config
+[server]
+socket-dir = "/tmp" # keep me
+
+[sensors]
+poll-interval = "3s"
+`
+
+	out := Set(data, "server", "socket-dir", `"/run/dms"`)
+	assert.Contains(t, out, `socket-dir = "/run/dms" # keep me`)
+
+	out = Set(out, "server", "other", "1")
+	assert.Equal(t, `# daemon config
+[server]
+socket-dir = "/run/dms" # keep me
+other = 1
+
+[sensors]
+poll-interval = "3s"
+`, out)
+
+	out = Set(out, "nightlight", "enabled", "true")
+	assert.Contains(t, out, "poll-interval = \"3s\"\n\n[nightlight]\nenabled = true\n")
+
+	tables, err := Parse(out)
+	require.NoError(t, err)
+	assert.Len(t, tables, 3)
+
+	assert.Equal(t, "[modules]\nnetwork = false\n", Set("", "modules", "network", "false"))
+}
+
+func TestSet_DoesNotTouchOtherTables(t *testing.T) {
+	data := "[a]\nkey = 1\n\n[b]\nkey = 2\n"
+	assert.Equal(t, "[a]\nkey = 1\n\n[b]\nkey = 3\n", Set(data, "b", "key", "3"))
+}
+
+func TestUnset(t *testing.T) {
+	data := "[a]\nkey = 1\nother = 2\n"
+	out, ok := Unset(data, "a", "key")
+	assert.True(t, ok)
+	assert.Equal(t, "[a]\nother = 2\n", out)
+
+	_, ok = Unset(data, "b", "key")
+	assert.False(t, ok)
+}
@@ -14,6 +14,7 @@ type Model struct {
 
 	osInfo       *distros.OSInfo
 	dependencies []deps.Dependency
+	gentooBuilds *distros.GentooBuildEstimate
 	err          error
 
 	spinner       spinner.Model
@@ -33,12 +34,34 @@ type Model struct {
 	selectedWM        int
 	selectedTerminal  int
 	selectedDep       int
+	selectedProfile   deps.InstallProfile
 	selectedConfig    int
 	reinstallItems    map[string]bool
+	skipItems         map[string]bool
 	replaceConfigs    map[string]bool
 	sudoPassword      string
 	existingConfigs   []ExistingConfigInfo
 	fingerprintFailed bool
+
+	riceSetup        distros.RiceSetup
+	migrateChoices   migrateChoices
+	selectedMigrate  int
+	compositorConfig string
+
+	sessionChoices  sessionChoices
+	selectedSession int
+	availableShells []string
+	sessionLog      []string
+	// desktopConflicts are the other desktops' services found when the
+	// session screen opens
+	desktopConflicts distros.CoexistReport
+	// activeExtras says why an extra is not offered, by ID, when it or
+	// something doing its job is already running
+	activeExtras map[string]string
+
+	// failedPhase names the step an install failed in, for the statistics
+	failedPhase string
+	statsStatus string
 }
 
 func NewModel(version string) Model {
@@ -78,8 +101,10 @@ func NewModel(version string) Model {
 		selectedWM:       0,
 		selectedTerminal: 0, // Default to Ghostty
 		selectedDep:      0,
+		selectedProfile:  deps.ProfileStandard,
 		selectedConfig:   0,
 		reinstallItems:   make(map[string]bool),
+		skipItems:        make(map[string]bool),
 		replaceConfigs:   make(map[string]bool),
 		installationLogs: []string{},
 	}
@@ -134,6 +159,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateMissingWMInstructionsState(msg)
 	case StateDetectingDeps:
 		return m.updateDetectingDepsState(msg)
+	case StateSelectProfile:
+		return m.updateSelectProfileState(msg)
 	case StateDependencyReview:
 		return m.updateDependencyReviewState(msg)
 	case StateGentooUseFlags:
@@ -150,6 +177,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateConfigConfirmationState(msg)
 	case StateDeployingConfigs:
 		return m.updateDeployingConfigsState(msg)
+	case StateMigration:
+		return m.updateMigrationState(msg)
+	case StateApplyingMigration:
+		return m.updateApplyingMigrationState(msg)
+	case StateSessionSetup:
+		return m.updateSessionSetupState(msg)
+	case StateApplyingSession:
+		return m.updateApplyingSessionState(msg)
 	case StateInstallComplete:
 		return m.updateInstallCompleteState(msg)
 	case StateError:
@@ -176,6 +211,8 @@ func (m Model) View() string {
 		return m.viewMissingWMInstructions()
 	case StateDetectingDeps:
 		return m.viewDetectingDeps()
+	case StateSelectProfile:
+		return m.viewSelectProfile()
 	case StateDependencyReview:
 		return m.viewDependencyReview()
 	case StateGentooUseFlags:
@@ -192,6 +229,14 @@ func (m Model) View() string {
 		return m.viewConfigConfirmation()
 	case StateDeployingConfigs:
 		return m.viewDeployingConfigs()
+	case StateMigration:
+		return m.viewMigration()
+	case StateApplyingMigration:
+		return m.viewApplyingMigration()
+	case StateSessionSetup:
+		return m.viewSessionSetup()
+	case StateApplyingSession:
+		return m.viewApplyingSession()
 	case StateInstallComplete:
 		return m.viewInstallComplete()
 	case StateError:
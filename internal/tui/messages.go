@@ -15,11 +15,13 @@ type osInfoCompleteMsg struct {
 }
 
 type depsDetectedMsg struct {
-	deps []deps.Dependency
-	err  error
+	deps         []deps.Dependency
+	gentooBuilds *distros.GentooBuildEstimate
+	err          error
 }
 
 type packageInstallProgressMsg struct {
+	phase       distros.InstallPhase
 	progress    float64
 	step        string
 	isComplete  bool
@@ -37,3 +39,8 @@ type passwordValidMsg struct {
 }
 
 type delayCompleteMsg struct{}
+
+type statsResultMsg struct {
+	shared bool
+	err    error
+}
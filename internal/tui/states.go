@@ -8,6 +8,7 @@ const (
 	StateSelectTerminal
 	StateMissingWMInstructions
 	StateDetectingDeps
+	StateSelectProfile
 	StateDependencyReview
 	StateGentooUseFlags
 	StateAuthMethodChoice
@@ -16,6 +17,10 @@ const (
 	StateInstallingPackages
 	StateConfigConfirmation
 	StateDeployingConfigs
+	StateMigration
+	StateApplyingMigration
+	StateSessionSetup
+	StateApplyingSession
 	StateInstallComplete
 	StateFinalComplete
 	StateError
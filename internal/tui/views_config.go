@@ -9,6 +9,7 @@ import (
 
 	"github.com/AvengeMedia/danklinux/internal/config"
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/distros"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -77,11 +78,13 @@ func (m Model) updateDeployingConfigsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if result, ok := msg.(configDeploymentResult); ok {
 		if result.error != nil {
 			m.err = result.error
+			m.failedPhase = "deploy-configs"
 			m.state = StateError
 			m.isLoading = false
 			return m, nil
 		}
 
+		validator := config.NewValidator(os.Getenv("HOME"))
 		for _, deployResult := range result.results {
 			if deployResult.Deployed {
 				logMsg := fmt.Sprintf("✓ %s configuration deployed", deployResult.ConfigType)
@@ -89,17 +92,44 @@ func (m Model) updateDeployingConfigsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 					logMsg += fmt.Sprintf(" (backup: %s)", deployResult.BackupPath)
 				}
 				m.installationLogs = append(m.installationLogs, logMsg)
+				for _, problem := range validator.ValidateFile(deployResult.ConfigType, deployResult.Path) {
+					m.installationLogs = append(m.installationLogs, "⚠ "+problem.String())
+				}
 			}
 		}
 
-		m.state = StateInstallComplete
 		m.isLoading = false
-		return m, nil
+		if m.detectRice(result.results) {
+			m.state = StateMigration
+			return m, nil
+		}
+		return m.enterSessionSetup(), nil
 	}
 
 	return m, m.listenForLogs()
 }
 
+func (m Model) enterSessionSetup() Model {
+	m.state = StateSessionSetup
+	m.isLoading = false
+	m.availableShells = distros.AvailableShells()
+	m.desktopConflicts = distros.DetectDesktopConflicts(os.Getenv("HOME"))
+	m.activeExtras = make(map[string]string)
+	for _, e := range distros.Extras {
+		if active, reason := distros.ExtraStatus(context.Background(), e); active {
+			m.activeExtras[e.ID] = reason
+		}
+	}
+	m.sessionChoices = sessionChoices{
+		sessionEntry:   true,
+		defaultSession: m.selectedProfile.SetsUpGreeter(),
+		maskConflicts:  len(m.desktopConflicts.Conflicts) > 0,
+		extras:         make(map[string]bool),
+	}
+	m.selectedSession = 0
+	return m
+}
+
 func (m Model) deployConfigurations() tea.Cmd {
 	return func() tea.Msg {
 		// Determine the selected window manager
@@ -202,6 +232,7 @@ func (m Model) updateConfigConfirmationState(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if result, ok := msg.(configCheckResult); ok {
 		if result.error != nil {
 			m.err = result.error
+			m.failedPhase = "check-configs"
 			m.state = StateError
 			return m, nil
 		}
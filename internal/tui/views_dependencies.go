@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
 	"github.com/AvengeMedia/danklinux/internal/distros"
@@ -52,6 +53,8 @@ func (m Model) viewDependencyReview() string {
 			if m.reinstallItems[dep.Name] {
 				reinstallMarker = "🔄 "
 				status = m.styles.Warning.Render("Will reinstall")
+			} else if m.skipItems[dep.Name] {
+				status = m.styles.Subtle.Render("– Skipped")
 			} else if isDMS {
 				reinstallMarker = "⚡ "
 				switch dep.Status {
@@ -77,19 +80,26 @@ func (m Model) viewDependencyReview() string {
 				}
 			}
 
+			var buildMarker string
+			if m.gentooBuilds != nil && m.willBuild(dep) {
+				if d := m.gentooBuilds.For(dep.Name); d >= heavyBuildThreshold {
+					buildMarker = fmt.Sprintf(" [build %s]", distros.FormatBuildDuration(d))
+				}
+			}
+
 			var line string
 			if i == m.selectedDep {
 				line = fmt.Sprintf("▶ %s%s%-25s %s", reinstallMarker, variantMarker, dep.Name, status)
 				if dep.Version != "" {
 					line += fmt.Sprintf(" (%s)", dep.Version)
 				}
-				line = m.styles.SelectedOption.Render(line)
+				line = m.styles.SelectedOption.Render(line + buildMarker)
 			} else {
 				line = fmt.Sprintf("  %s%s%-25s %s", reinstallMarker, variantMarker, dep.Name, status)
 				if dep.Version != "" {
 					line += fmt.Sprintf(" (%s)", dep.Version)
 				}
-				line = m.styles.Normal.Render(line)
+				line = m.styles.Normal.Render(line) + m.styles.Warning.Render(buildMarker)
 			}
 
 			b.WriteString(line)
@@ -97,22 +107,70 @@ func (m Model) viewDependencyReview() string {
 		}
 	}
 
+	b.WriteString(m.renderGentooBuildWarning())
+
 	b.WriteString("\n")
-	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle reinstall, G: Toggle stable/git, Enter: Continue")
+	help := m.styles.Subtle.Render(fmt.Sprintf("%s profile. ↑/↓: Navigate, Space: Toggle reinstall/skip, G: Toggle stable/git, Enter: Continue, Esc: Change profile", m.selectedProfile))
 	b.WriteString(help)
 
 	return b.String()
 }
 
+// heavyBuildThreshold is the compile time from which a dependency gets an
+// estimate next to it in the review list
+const heavyBuildThreshold = 10 * time.Minute
+
+func (m Model) willBuild(dep deps.Dependency) bool {
+	if m.skipItems[dep.Name] {
+		return false
+	}
+	return dep.Status != deps.StatusInstalled || m.reinstallItems[dep.Name]
+}
+
+// renderGentooBuildWarning summarises how long the selected packages will
+// take to compile, so a multi-hour emerge is not a surprise
+func (m Model) renderGentooBuildWarning() string {
+	if m.gentooBuilds == nil {
+		return ""
+	}
+
+	var names []string
+	for _, dep := range m.dependencies {
+		if m.willBuild(dep) {
+			names = append(names, dep.Name)
+		}
+	}
+	total := m.gentooBuilds.Total(names)
+	if total < heavyBuildThreshold {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	summary := fmt.Sprintf("Estimated compile time: %s with %d parallel jobs", distros.FormatBuildDuration(total), m.gentooBuilds.Cores)
+	if m.gentooBuilds.Binpkgs {
+		summary += " (less where binary packages are available)"
+	}
+	b.WriteString(m.styles.Warning.Render(summary))
+	b.WriteString("\n")
+	for _, suggestion := range m.gentooBuilds.Suggestions(names) {
+		b.WriteString(m.styles.Subtle.Render("  • " + suggestion))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
 func (m Model) updateDetectingDepsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if depsMsg, ok := msg.(depsDetectedMsg); ok {
 		m.isLoading = false
 		if depsMsg.err != nil {
 			m.err = depsMsg.err
+			m.failedPhase = "detect-deps"
 			m.state = StateError
 		} else {
 			m.dependencies = depsMsg.deps
-			m.state = StateDependencyReview
+			m.gentooBuilds = depsMsg.gentooBuilds
+			m.state = StateSelectProfile
 		}
 		return m, m.listenForLogs()
 	}
@@ -137,6 +195,8 @@ func (m Model) updateDependencyReviewState(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.dependencies[m.selectedDep].Status == deps.StatusInstalled ||
 					m.dependencies[m.selectedDep].Status == deps.StatusNeedsReinstall {
 					m.reinstallItems[depName] = !m.reinstallItems[depName]
+				} else if deps.IsOptional(depName) {
+					m.skipItems[depName] = !m.skipItems[depName]
 				}
 			}
 		case "g", "G":
@@ -166,7 +226,7 @@ func (m Model) updateDependencyReviewState(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 		case "esc":
-			m.state = StateSelectWindowManager
+			m.state = StateSelectProfile
 			return m, nil
 		}
 	}
@@ -204,7 +264,7 @@ func (m Model) installPackages() tea.Cmd {
 
 		go func() {
 			defer close(installerProgressChan)
-			err := installer.InstallPackages(context.Background(), m.dependencies, wm, m.sudoPassword, m.reinstallItems, installerProgressChan)
+			err := installer.InstallPackages(context.Background(), m.selectedDependencies(), wm, m.sudoPassword, m.reinstallItems, installerProgressChan)
 			if err != nil {
 				installerProgressChan <- distros.InstallProgressMsg{
 					Progress:   0.0,
@@ -219,6 +279,7 @@ func (m Model) installPackages() tea.Cmd {
 		go func() {
 			for msg := range installerProgressChan {
 				tuiMsg := packageInstallProgressMsg{
+					phase:       msg.Phase,
 					progress:    msg.Progress,
 					step:        msg.Step,
 					isComplete:  msg.IsComplete,
@@ -145,7 +145,16 @@ func (m Model) viewInstallComplete() string {
 		b.WriteString("\n")
 	}
 
+	if len(m.sessionLog) > 0 {
+		b.WriteString("\n")
+		for _, line := range m.sessionLog {
+			b.WriteString(m.styles.Subtle.Render(line))
+			b.WriteString("\n")
+		}
+	}
+
 	b.WriteString("\n")
+	b.WriteString(m.viewStatsOffer())
 	info := m.styles.Normal.Render("Your system is ready! Log out and log back in to start using\nyour new desktop environment.\nIf you do not have a greeter, login with \"niri-session\" or \"Hyprland\" \n\nPress Enter to exit.")
 	b.WriteString(info)
 
@@ -199,6 +208,8 @@ func (m Model) viewError() string {
 		b.WriteString("\n")
 	}
 
+	b.WriteString(m.viewStatsOffer())
+
 	hint := m.styles.Subtle.Render("Press Ctrl+D for full debug logs")
 	b.WriteString(hint)
 	b.WriteString("\n")
@@ -224,6 +235,7 @@ func (m Model) updateInstallingPackagesState(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if progressMsg.isComplete {
 			if progressMsg.error != nil {
+				m.failedPhase = progressMsg.phase.String()
 				m.state = StateError
 				m.isLoading = false
 			} else {
@@ -239,6 +251,9 @@ func (m Model) updateInstallingPackagesState(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateInstallCompleteState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if next, cmd, handled := m.updateStats(msg); handled {
+		return next, cmd
+	}
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.String() {
 		case "enter":
@@ -249,6 +264,9 @@ func (m Model) updateInstallCompleteState(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateErrorState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if next, cmd, handled := m.updateStats(msg); handled {
+		return next, cmd
+	}
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.String() {
 		case "enter":
@@ -0,0 +1,252 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// migrateChoices are what the migration assistant imports from an existing
+// setup; options with nothing detected stay off
+type migrateChoices struct {
+	font       bool
+	wallpaper  bool
+	keybinds   bool
+	autostarts bool
+}
+
+type migrationResult struct {
+	report distros.SessionReport
+	err    error
+}
+
+const (
+	migrateOptionFont = iota
+	migrateOptionWallpaper
+	migrateOptionKeybinds
+	migrateOptionAutostarts
+	migrateOptionCount
+)
+
+// detectRice looks for an existing setup once the DMS configs are in place.
+// Replaced compositor configs are read from their backups.
+func (m *Model) detectRice(results []config.DeploymentResult) bool {
+	wmType := "Niri"
+	if m.selectedWM == 1 {
+		wmType = "Hyprland"
+	}
+
+	var oldConfigs []string
+	m.compositorConfig = ""
+	for _, r := range results {
+		if r.ConfigType != "Niri" && r.ConfigType != "Hyprland" {
+			continue
+		}
+		if r.BackupPath != "" {
+			oldConfigs = append(oldConfigs, r.BackupPath)
+		}
+		if r.ConfigType == wmType && r.Deployed {
+			m.compositorConfig = r.Path
+		}
+	}
+
+	m.riceSetup = distros.DetectRice(os.Getenv("HOME"), oldConfigs)
+	if m.riceSetup.Empty() {
+		return false
+	}
+	m.migrateChoices = migrateChoices{
+		font:       m.riceSetup.Font != "",
+		wallpaper:  m.riceSetup.Wallpaper != "",
+		keybinds:   len(m.riceSetup.Keybinds) > 0 && m.compositorConfig != "",
+		autostarts: len(m.riceSetup.Autostarts) > 0,
+	}
+	m.selectedMigrate = 0
+	return true
+}
+
+func (m Model) viewMigration() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Existing Setup")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	found := "Found configuration for " + strings.Join(m.riceSetup.Components, ", ") + "."
+	if len(m.riceSetup.Components) == 0 {
+		found = "Found settings from your previous desktop."
+	}
+	info := m.styles.Normal.Render(found + "\nBring these over to DMS? Everything changed is listed at the end and\ncan be undone with \"dankinstall --revert-session\".")
+	b.WriteString(info)
+	b.WriteString("\n\n")
+
+	none := func(s string) string {
+		if s == "" {
+			return "nothing found"
+		}
+		return s
+	}
+
+	var binds []string
+	for _, bind := range m.riceSetup.Keybinds {
+		binds = append(binds, bind.Keys+" → "+strings.Join(bind.Action, " "))
+	}
+	bindDesc := none(strings.Join(binds, ", "))
+	if len(binds) > 0 && m.compositorConfig == "" {
+		bindDesc = "the compositor config was kept, so there is nowhere to add them"
+	}
+
+	var starts []string
+	for _, a := range m.riceSetup.Autostarts {
+		starts = append(starts, a.Program)
+	}
+
+	options := []struct {
+		label       string
+		checked     bool
+		description string
+	}{
+		{"Font", m.migrateChoices.font, none(m.riceSetup.Font)},
+		{"Wallpaper", m.migrateChoices.wallpaper, none(m.riceSetup.Wallpaper)},
+		{"Keybindings", m.migrateChoices.keybinds, bindDesc},
+		{"Autostarts", m.migrateChoices.autostarts, "Disable " + none(strings.Join(starts, ", ")) + " so they don't run next to DMS"},
+	}
+
+	for i, option := range options {
+		line := fmt.Sprintf("%-16s %s", option.label, checkbox(option.checked))
+		if i == m.selectedMigrate {
+			b.WriteString(m.styles.SelectedOption.Render("▶ " + line))
+		} else {
+			b.WriteString(m.styles.Normal.Render("  " + line))
+		}
+		b.WriteString("\n")
+		b.WriteString(m.styles.Subtle.Render("  " + wrapText(option.description, 76)))
+		b.WriteString("\n\n")
+	}
+
+	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle, Enter: Apply, s: Skip")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+// migrateAvailable reports whether an option has anything to import
+func (m Model) migrateAvailable(option int) bool {
+	switch option {
+	case migrateOptionFont:
+		return m.riceSetup.Font != ""
+	case migrateOptionWallpaper:
+		return m.riceSetup.Wallpaper != ""
+	case migrateOptionKeybinds:
+		return len(m.riceSetup.Keybinds) > 0 && m.compositorConfig != ""
+	case migrateOptionAutostarts:
+		return len(m.riceSetup.Autostarts) > 0
+	}
+	return false
+}
+
+func (m Model) updateMigrationState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up":
+			if m.selectedMigrate > 0 {
+				m.selectedMigrate--
+			}
+		case "down":
+			if m.selectedMigrate < migrateOptionCount-1 {
+				m.selectedMigrate++
+			}
+		case " ":
+			if !m.migrateAvailable(m.selectedMigrate) {
+				break
+			}
+			switch m.selectedMigrate {
+			case migrateOptionFont:
+				m.migrateChoices.font = !m.migrateChoices.font
+			case migrateOptionWallpaper:
+				m.migrateChoices.wallpaper = !m.migrateChoices.wallpaper
+			case migrateOptionKeybinds:
+				m.migrateChoices.keybinds = !m.migrateChoices.keybinds
+			case migrateOptionAutostarts:
+				m.migrateChoices.autostarts = !m.migrateChoices.autostarts
+			}
+		case "s":
+			return m.enterSessionSetup(), nil
+		case "enter":
+			c := m.migrateChoices
+			if !c.font && !c.wallpaper && !c.keybinds && !c.autostarts {
+				return m.enterSessionSetup(), nil
+			}
+			m.state = StateApplyingMigration
+			m.isLoading = true
+			return m, tea.Batch(m.spinner.Tick, m.applyMigration())
+		}
+	}
+	return m, m.listenForLogs()
+}
+
+func (m Model) viewApplyingMigration() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Existing Setup")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	spinner := m.spinner.View()
+	status := m.styles.Normal.Render("Importing settings...")
+	b.WriteString(fmt.Sprintf("%s %s", spinner, status))
+
+	return b.String()
+}
+
+func (m Model) updateApplyingMigrationState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if result, ok := msg.(migrationResult); ok {
+		// Like the session steps, migration is optional and only reported
+		if result.err != nil {
+			m.sessionLog = append(m.sessionLog, "⚠ Migration failed: "+result.err.Error())
+		} else {
+			for _, line := range result.report.Applied {
+				m.sessionLog = append(m.sessionLog, "✓ "+line)
+			}
+			for _, line := range result.report.Skipped {
+				m.sessionLog = append(m.sessionLog, "• "+line)
+			}
+			for _, line := range result.report.Warnings {
+				m.sessionLog = append(m.sessionLog, "⚠ "+line)
+			}
+		}
+		return m.enterSessionSetup(), nil
+	}
+	return m, m.listenForLogs()
+}
+
+func (m Model) applyMigration() tea.Cmd {
+	return func() tea.Msg {
+		wm := deps.WindowManagerNiri
+		if m.selectedWM == 1 {
+			wm = deps.WindowManagerHyprland
+		}
+
+		base := distros.NewBaseDistribution(m.logChan)
+		report, err := base.MigrateRice(context.Background(), m.riceSetup, distros.MigrateOptions{
+			WindowManager:     wm,
+			CompositorConfig:  m.compositorConfig,
+			ImportFont:        m.migrateChoices.font,
+			ImportWallpaper:   m.migrateChoices.wallpaper,
+			ImportKeybinds:    m.migrateChoices.keybinds,
+			DisableAutostarts: m.migrateChoices.autostarts,
+		})
+		return migrationResult{report: report, err: err}
+	}
+}
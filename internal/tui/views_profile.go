@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m Model) viewSelectProfile() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Choose Install Profile")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	for i, profile := range deps.InstallProfiles {
+		if profile == m.selectedProfile {
+			b.WriteString(m.styles.SelectedOption.Render("▶ " + profile.String()))
+		} else {
+			b.WriteString(m.styles.Normal.Render("  " + profile.String()))
+		}
+		b.WriteString("\n")
+		b.WriteString(m.styles.Subtle.Render("  " + profile.Description()))
+		b.WriteString("\n")
+		if i < len(deps.InstallProfiles)-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	help := m.styles.Subtle.Render("Use ↑/↓ to navigate, Enter to review packages, Esc to go back")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func (m Model) updateSelectProfileState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up":
+			if m.selectedProfile > deps.ProfileMinimal {
+				m.selectedProfile--
+			}
+		case "down":
+			if m.selectedProfile < deps.ProfileFull {
+				m.selectedProfile++
+			}
+		case "enter":
+			m.applyProfile()
+			m.state = StateDependencyReview
+			return m, m.listenForLogs()
+		case "esc":
+			m.state = StateSelectWindowManager
+			return m, m.listenForLogs()
+		}
+	}
+	return m, m.listenForLogs()
+}
+
+// applyProfile marks the missing dependencies the profile leaves out as
+// skipped. Choosing a profile again starts over from its defaults.
+func (m *Model) applyProfile() {
+	m.skipItems = make(map[string]bool)
+	for _, dep := range m.dependencies {
+		if dep.Status != deps.StatusInstalled && !m.selectedProfile.Includes(dep.Name) {
+			m.skipItems[dep.Name] = true
+		}
+	}
+}
+
+// selectedDependencies is what the installer gets: everything detected
+// except the dependencies skipped in the review
+func (m Model) selectedDependencies() []deps.Dependency {
+	selected := make([]deps.Dependency, 0, len(m.dependencies))
+	for _, dep := range m.dependencies {
+		if !m.skipItems[dep.Name] {
+			selected = append(selected, dep)
+		}
+	}
+	return selected
+}
@@ -239,6 +239,11 @@ func (m Model) detectDependencies() tea.Cmd {
 		}
 
 		dependencies, err := detector.DetectDependenciesWithTerminal(context.Background(), wm, terminal)
-		return depsDetectedMsg{deps: dependencies, err: err}
+		msg := depsDetectedMsg{deps: dependencies, err: err}
+		if gentoo, ok := detector.(*distros.GentooDistribution); ok && err == nil {
+			estimate := gentoo.EstimateBuildTimes(dependencies)
+			msg.gentooBuilds = &estimate
+		}
+		return msg
 	}
 }
@@ -0,0 +1,266 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionChoices are the optional login steps offered after deployment
+type sessionChoices struct {
+	sessionEntry   bool
+	defaultSession bool
+	// shell indexes availableShells, offset by one; 0 keeps the current shell
+	shell         int
+	maskConflicts bool
+	// extras are the distros.Extras to set up, by ID
+	extras map[string]bool
+}
+
+type sessionSetupResult struct {
+	report distros.SessionReport
+	err    error
+}
+
+const (
+	sessionOptionEntry = iota
+	sessionOptionDefault
+	sessionOptionShell
+	sessionOptionConflicts
+	// sessionOptionExtras is the first of the distros.Extras toggles
+	sessionOptionExtras
+)
+
+type sessionOption struct {
+	label       string
+	value       string
+	description string
+}
+
+func sessionOptionCount() int {
+	return sessionOptionExtras + len(distros.Extras)
+}
+
+func (m Model) selectedExtras() []string {
+	var ids []string
+	for _, e := range distros.Extras {
+		if m.sessionChoices.extras[e.ID] {
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids
+}
+
+func (m Model) selectedShell() string {
+	if m.sessionChoices.shell == 0 || m.sessionChoices.shell > len(m.availableShells) {
+		return ""
+	}
+	return m.availableShells[m.sessionChoices.shell-1]
+}
+
+func (m Model) viewSessionSetup() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Login Session")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	info := m.styles.Normal.Render("Optional steps to make logging in easier. Each one is recorded and\ncan be undone with \"dankinstall --revert-session\".")
+	b.WriteString(info)
+	b.WriteString("\n\n")
+
+	wmName := "niri"
+	if m.selectedWM == 1 {
+		wmName = "Hyprland"
+	}
+
+	shellValue := "keep " + distros.CurrentLoginShell()
+	if shell := m.selectedShell(); shell != "" {
+		shellValue = shell
+	}
+
+	options := []sessionOption{
+		{"Session entry", checkbox(m.sessionChoices.sessionEntry), fmt.Sprintf("Add a %s entry to the login screen's session list if missing", wmName)},
+		{"Default session", checkbox(m.sessionChoices.defaultSession), fmt.Sprintf("Preselect %s in AccountsService and tuigreet", wmName)},
+		{"Login shell", shellValue, "Change your shell with chsh (←/→ to choose)"},
+		{"Coexistence", checkbox(m.sessionChoices.maskConflicts), conflictsDescription(m.desktopConflicts)},
+	}
+	for _, e := range distros.Extras {
+		if reason, active := m.activeExtras[e.ID]; active {
+			options = append(options, sessionOption{e.Name, "[-]", reason})
+			continue
+		}
+		options = append(options, sessionOption{e.Name, checkbox(m.sessionChoices.extras[e.ID]), e.Description})
+	}
+
+	for i, option := range options {
+		line := fmt.Sprintf("%-16s %s", option.label, option.value)
+		if i == m.selectedSession {
+			b.WriteString(m.styles.SelectedOption.Render("▶ " + line))
+		} else {
+			b.WriteString(m.styles.Normal.Render("  " + line))
+		}
+		b.WriteString("\n")
+		b.WriteString(m.styles.Subtle.Render("  " + option.description))
+		b.WriteString("\n\n")
+	}
+
+	if len(m.desktopConflicts.Conflicts) > 0 {
+		warning := fmt.Sprintf("⚠ Also installed: %s. These services would also run in your DMS session,\n  causing double OSDs and brightness fights:", strings.Join(m.desktopConflicts.Desktops, " and "))
+		if len(m.desktopConflicts.Desktops) == 0 {
+			warning = "⚠ These services would also run in your DMS session:"
+		}
+		b.WriteString(m.styles.Warning.Render(warning))
+		b.WriteString("\n")
+		for _, c := range m.desktopConflicts.Conflicts {
+			b.WriteString(m.styles.Subtle.Render(fmt.Sprintf("    %s: %s", c.Name(), c.Reason)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle, ←/→: Choose shell, Enter: Apply, s: Skip")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func conflictsDescription(report distros.CoexistReport) string {
+	if len(report.Conflicts) == 0 {
+		return "No services of other desktops found that would fight DMS"
+	}
+	return fmt.Sprintf("Keep %d conflicting service(s) out of DMS sessions; other sessions still start them", len(report.Conflicts))
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func (m Model) updateSessionSetupState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up":
+			if m.selectedSession > 0 {
+				m.selectedSession--
+			}
+		case "down":
+			if m.selectedSession < sessionOptionCount()-1 {
+				m.selectedSession++
+			}
+		case " ":
+			switch m.selectedSession {
+			case sessionOptionEntry:
+				m.sessionChoices.sessionEntry = !m.sessionChoices.sessionEntry
+			case sessionOptionDefault:
+				m.sessionChoices.defaultSession = !m.sessionChoices.defaultSession
+			case sessionOptionConflicts:
+				if len(m.desktopConflicts.Conflicts) > 0 {
+					m.sessionChoices.maskConflicts = !m.sessionChoices.maskConflicts
+				}
+			default:
+				if i := m.selectedSession - sessionOptionExtras; i >= 0 && i < len(distros.Extras) {
+					id := distros.Extras[i].ID
+					if _, active := m.activeExtras[id]; !active {
+						m.sessionChoices.extras[id] = !m.sessionChoices.extras[id]
+					}
+				}
+			}
+		case "left":
+			if m.selectedSession == sessionOptionShell && m.sessionChoices.shell > 0 {
+				m.sessionChoices.shell--
+			}
+		case "right":
+			if m.selectedSession == sessionOptionShell && m.sessionChoices.shell < len(m.availableShells) {
+				m.sessionChoices.shell++
+			}
+		case "s":
+			m.state = StateInstallComplete
+			return m, nil
+		case "enter":
+			if !m.sessionChoices.sessionEntry && !m.sessionChoices.defaultSession && m.selectedShell() == "" && !m.sessionChoices.maskConflicts && len(m.selectedExtras()) == 0 {
+				m.state = StateInstallComplete
+				return m, nil
+			}
+			m.state = StateApplyingSession
+			m.isLoading = true
+			return m, tea.Batch(m.spinner.Tick, m.applySessionSetup())
+		}
+	}
+	return m, m.listenForLogs()
+}
+
+func (m Model) viewApplyingSession() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Login Session")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	spinner := m.spinner.View()
+	status := m.styles.Normal.Render("Configuring login session...")
+	b.WriteString(fmt.Sprintf("%s %s", spinner, status))
+
+	return b.String()
+}
+
+func (m Model) updateApplyingSessionState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if result, ok := msg.(sessionSetupResult); ok {
+		m.isLoading = false
+		m.state = StateInstallComplete
+
+		// Session steps are optional, so a failure only ends up in the log
+		if result.err != nil {
+			m.sessionLog = append(m.sessionLog, "⚠ Session setup failed: "+result.err.Error())
+			return m, nil
+		}
+		for _, line := range result.report.Applied {
+			m.sessionLog = append(m.sessionLog, "✓ "+line)
+		}
+		for _, line := range result.report.Skipped {
+			m.sessionLog = append(m.sessionLog, "• "+line)
+		}
+		for _, line := range result.report.Warnings {
+			m.sessionLog = append(m.sessionLog, "⚠ "+line)
+		}
+		return m, nil
+	}
+	return m, m.listenForLogs()
+}
+
+func (m Model) applySessionSetup() tea.Cmd {
+	return func() tea.Msg {
+		var wm deps.WindowManager
+		switch m.selectedWM {
+		case 0:
+			wm = deps.WindowManagerNiri
+		case 1:
+			wm = deps.WindowManagerHyprland
+		default:
+			wm = deps.WindowManagerNiri
+		}
+
+		base := distros.NewBaseDistribution(m.logChan)
+		report, err := base.ConfigureSession(context.Background(), distros.SessionOptions{
+			WindowManager:       wm,
+			InstallSessionEntry: m.sessionChoices.sessionEntry,
+			SetDefaultSession:   m.sessionChoices.defaultSession,
+			Shell:               m.selectedShell(),
+			MaskConflicts:       m.sessionChoices.maskConflicts,
+			Extras:              m.selectedExtras(),
+		}, m.sudoPassword)
+		return sessionSetupResult{report: report, err: err}
+	}
+}
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// installStats describes this run. Nothing is offered when the distro was
+// not detected, since the report would be empty.
+func (m Model) installStats() (distros.InstallStats, bool) {
+	if m.osInfo == nil {
+		return distros.InstallStats{}, false
+	}
+	config, ok := distros.Registry[m.osInfo.Distribution.ID]
+	if !ok {
+		return distros.InstallStats{}, false
+	}
+
+	wm := deps.WindowManagerNiri
+	if m.selectedWM == 1 {
+		wm = deps.WindowManagerHyprland
+	}
+
+	phase := m.failedPhase
+	if m.state == StateError && phase == "" {
+		phase = "unknown"
+	}
+	return distros.NewInstallStats(config.Family, wm, phase), true
+}
+
+// viewStatsOffer shows the exact report and asks whether to keep it. Nothing
+// is written or sent unless a key below is pressed.
+func (m Model) viewStatsOffer() string {
+	stats, ok := m.installStats()
+	if !ok {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.styles.Normal.Render("Optional install statistics"))
+	b.WriteString("\n")
+	if m.statsStatus != "" {
+		b.WriteString(m.styles.Subtle.Render(m.statsStatus))
+		b.WriteString("\n\n")
+		return b.String()
+	}
+
+	b.WriteString(m.styles.Subtle.Render("Help us see which install paths get used. The report is exactly:"))
+	b.WriteString("\n")
+	b.WriteString(m.styles.Subtle.Render("  " + stats.JSON()))
+	b.WriteString("\n")
+	b.WriteString(m.styles.Subtle.Render("No IDs, names, addresses or versions are included. Nothing is kept unless you choose to:"))
+	b.WriteString("\n")
+	if distros.StatsURL != "" {
+		b.WriteString(m.styles.Subtle.Render("  s - send it anonymously (and keep a copy in the install manifest)"))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.styles.Subtle.Render("  l - only keep it in ~/.config/dankinstall/manifest.json"))
+	b.WriteString("\n\n")
+	return b.String()
+}
+
+// updateStats handles the statistics keys and results on the final screens
+func (m Model) updateStats(msg tea.Msg) (Model, tea.Cmd, bool) {
+	if result, ok := msg.(statsResultMsg); ok {
+		switch {
+		case result.err != nil:
+			m.statsStatus = "⚠ Statistics not saved: " + result.err.Error()
+		case result.shared:
+			m.statsStatus = "✓ Statistics sent, thank you"
+		default:
+			m.statsStatus = "✓ Statistics kept in the install manifest"
+		}
+		return m, m.listenForLogs(), true
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.statsStatus != "" {
+		return m, nil, false
+	}
+	stats, ok := m.installStats()
+	if !ok {
+		return m, nil, false
+	}
+
+	switch keyMsg.String() {
+	case "s":
+		if distros.StatsURL == "" {
+			return m, nil, false
+		}
+		m.statsStatus = "Sending statistics..."
+		return m, saveInstallStats(stats, true), true
+	case "l":
+		m.statsStatus = "Saving statistics..."
+		return m, saveInstallStats(stats, false), true
+	}
+	return m, nil, false
+}
+
+func saveInstallStats(stats distros.InstallStats, share bool) tea.Cmd {
+	return func() tea.Msg {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return statsResultMsg{err: err}
+		}
+		if err := distros.RecordInstallStats(homeDir, stats); err != nil {
+			return statsResultMsg{err: err}
+		}
+		if share {
+			if err := distros.ShareInstallStats(context.Background(), distros.StatsURL, stats); err != nil {
+				return statsResultMsg{err: err}
+			}
+		}
+		return statsResultMsg{shared: share}
+	}
+}
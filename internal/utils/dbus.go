@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const pingTimeout = 3 * time.Second
+
+// PingDBus checks that conn is still open and that dest answers on it
+func PingDBus(conn *dbus.Conn, dest string) error {
+	if conn == nil || !conn.Connected() {
+		return errors.New("D-Bus connection closed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	call := conn.Object(dest, "/").CallWithContext(ctx, "org.freedesktop.DBus.Peer.Ping", 0)
+	if call.Err != nil {
+		return fmt.Errorf("%s not responding: %w", dest, call.Err)
+	}
+	return nil
+}
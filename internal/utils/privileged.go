@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PrivilegedWrite writes content to a root-owned path, directly when already
+// root and through pkexec tee otherwise
+func PrivilegedWrite(path, content string) error {
+	if os.Geteuid() == 0 {
+		return os.WriteFile(path, []byte(content), 0644)
+	}
+
+	cmd := exec.Command("pkexec", "tee", path)
+	cmd.Stdin = strings.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pkexec tee %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
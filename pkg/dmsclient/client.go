@@ -0,0 +1,477 @@
+// Package dmsclient talks to the dms server over its unix socket. Requests
+// are newline-delimited JSON and may be issued concurrently; responses are
+// matched back to callers by request ID.
+package dmsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/session/socket"
+)
+
+// APIVersion is the server API version this client was written against.
+// It is sent with every request so the server can flag deprecated calls.
+const APIVersion = 17
+
+// ErrClosed is returned for calls on a client whose connection is gone
+var ErrClosed = errors.New("dmsclient: connection closed")
+
+// ServerError is an error reported by the server for one request
+type ServerError struct {
+	Method  string
+	Message string
+	// Code is set for errors a client can act on, such as CodePermissionDenied
+	Code string
+}
+
+// CodePermissionDenied marks a request polkit refused; retrying asks the
+// authentication agent again
+const CodePermissionDenied = "PERMISSION_DENIED"
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Method, e.Message)
+}
+
+// IsPermissionDenied reports whether err is a polkit refusal from the server
+func IsPermissionDenied(err error) bool {
+	var serverErr *ServerError
+	return errors.As(err, &serverErr) && serverErr.Code == CodePermissionDenied
+}
+
+type rawResponse struct {
+	ID     int             `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Code   string          `json:"code,omitempty"`
+	Meta   *struct {
+		Deprecations []Deprecation `json:"deprecations"`
+	} `json:"meta,omitempty"`
+}
+
+type request struct {
+	ID         int            `json:"id"`
+	Method     string         `json:"method"`
+	Params     map[string]any `json:"params,omitempty"`
+	APIVersion int            `json:"apiVersion"`
+}
+
+// waiter receives responses for one request ID. Calls take a single
+// response, streams keep receiving until done is closed.
+type waiter struct {
+	ch     chan rawResponse
+	done   chan struct{}
+	stream bool
+}
+
+type Client struct {
+	conn         net.Conn
+	capabilities []string
+
+	writeMu sync.Mutex
+	nextID  atomic.Int64
+
+	mu            sync.Mutex
+	waiters       map[int]*waiter
+	err           error
+	onDeprecation func(Deprecation)
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial connects to the server at socketPath and reads its capability
+// handshake
+func Dial(ctx context.Context, socketPath string) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", socketPath, err)
+	}
+
+	c := &Client{
+		conn:    conn,
+		waiters: make(map[int]*waiter),
+		closed:  make(chan struct{}),
+	}
+
+	dec := json.NewDecoder(conn)
+	handshake := make(chan error, 1)
+	go func() {
+		var caps Capabilities
+		if err := dec.Decode(&caps); err != nil {
+			handshake <- fmt.Errorf("failed to read capabilities: %w", err)
+			return
+		}
+		c.capabilities = caps.Capabilities
+		handshake <- nil
+	}()
+
+	select {
+	case err := <-handshake:
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	case <-ctx.Done():
+		conn.Close()
+		return nil, ctx.Err()
+	}
+
+	go c.readLoop(dec)
+	return c, nil
+}
+
+// DialDefault connects to the socket named by FindSocket
+func DialDefault(ctx context.Context) (*Client, error) {
+	path, err := FindSocket()
+	if err != nil {
+		return nil, err
+	}
+	return Dial(ctx, path)
+}
+
+// FindSocket returns $DMS_SOCKET when set, otherwise the newest live server
+// socket in the runtime directory, preferring the caller's own session
+func FindSocket() (string, error) {
+	if path := os.Getenv("DMS_SOCKET"); path != "" {
+		return path, nil
+	}
+	return FindSocketIn(socketDir())
+}
+
+// FindSocketIn looks for a live server socket in dir, for servers started
+// with a custom server.socket-dir. A server of the $XDG_SESSION_ID session
+// wins over newer ones from other sessions.
+func FindSocketIn(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	type candidate struct {
+		path        string
+		mod         int64
+		sameSession bool
+	}
+	own := socket.SessionFromEnv()
+	var candidates []candidate
+	for _, entry := range entries {
+		name := entry.Name()
+		sessionID, pid, ok := socket.Parse(name)
+		if !ok || syscall.Kill(pid, 0) == syscall.ESRCH {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			path:        filepath.Join(dir, name),
+			mod:         info.ModTime().UnixNano(),
+			sameSession: own != "" && sessionID == own,
+		})
+	}
+
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no dms server socket found in %s", dir)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].sameSession != candidates[j].sameSession {
+			return candidates[i].sameSession
+		}
+		return candidates[i].mod > candidates[j].mod
+	})
+	return candidates[0].path, nil
+}
+
+// socketDir mirrors the server's choice of socket directory
+func socketDir() string {
+	if runtime := os.Getenv("XDG_RUNTIME_DIR"); runtime != "" {
+		return runtime
+	}
+	if os.Getuid() == 0 {
+		if _, err := os.Stat("/run"); err == nil {
+			return "/run/dankdots"
+		}
+		return "/var/run/dankdots"
+	}
+	return socket.FallbackDir()
+}
+
+// Capabilities lists the services the server advertised on connect
+func (c *Client) Capabilities() []string {
+	return append([]string(nil), c.capabilities...)
+}
+
+// HasCapability reports whether the server advertised name on connect
+func (c *Client) HasCapability(name string) bool {
+	for _, have := range c.capabilities {
+		if have == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Close ends the connection and every subscription on it
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// OnDeprecation sets a function called for each deprecated method the
+// server reports in a response
+func (c *Client) OnDeprecation(fn func(Deprecation)) {
+	c.mu.Lock()
+	c.onDeprecation = fn
+	c.mu.Unlock()
+}
+
+func (c *Client) readLoop(dec *json.Decoder) {
+	var loopErr error
+	for {
+		var resp rawResponse
+		if err := dec.Decode(&resp); err != nil {
+			loopErr = err
+			break
+		}
+		if resp.ID == 0 {
+			continue
+		}
+
+		c.mu.Lock()
+		w, ok := c.waiters[resp.ID]
+		if ok && !w.stream {
+			delete(c.waiters, resp.ID)
+		}
+		onDeprecation := c.onDeprecation
+		c.mu.Unlock()
+
+		if resp.Meta != nil && onDeprecation != nil {
+			for _, d := range resp.Meta.Deprecations {
+				onDeprecation(d)
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		select {
+		case w.ch <- resp:
+		case <-w.done:
+		case <-c.closed:
+		}
+	}
+
+	c.mu.Lock()
+	c.err = fmt.Errorf("%w: %v", ErrClosed, loopErr)
+	for id, w := range c.waiters {
+		close(w.ch)
+		delete(c.waiters, id)
+	}
+	c.waiters = nil
+	c.mu.Unlock()
+	c.Close()
+}
+
+func (c *Client) register(stream bool) (int, *waiter, error) {
+	id := int(c.nextID.Add(1))
+	w := &waiter{ch: make(chan rawResponse, 1), done: make(chan struct{}), stream: stream}
+	if stream {
+		w.ch = make(chan rawResponse, 16)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.waiters == nil {
+		return 0, nil, c.err
+	}
+	c.waiters[id] = w
+	return id, w, nil
+}
+
+func (c *Client) unregister(id int, w *waiter) {
+	c.mu.Lock()
+	if c.waiters != nil && c.waiters[id] == w {
+		delete(c.waiters, id)
+	}
+	c.mu.Unlock()
+	close(w.done)
+}
+
+func (c *Client) send(ctx context.Context, req request) error {
+	req.APIVersion = APIVersion
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", req.Method, err)
+	}
+	data = append(data, '\n')
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetWriteDeadline(deadline)
+		defer c.conn.SetWriteDeadline(time.Time{})
+	}
+	if _, err := c.conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send %s: %w", req.Method, err)
+	}
+	return nil
+}
+
+// Call sends method with params and decodes the result into result, which
+// may be nil to discard it
+func (c *Client) Call(ctx context.Context, method string, params map[string]any, result any) error {
+	id, w, err := c.register(false)
+	if err != nil {
+		return err
+	}
+	defer c.unregister(id, w)
+
+	if err := c.send(ctx, request{ID: id, Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case resp, ok := <-w.ch:
+		if !ok {
+			return c.closeErr()
+		}
+		return decodeResponse(method, resp, result)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return ErrClosed
+}
+
+func decodeResponse(method string, resp rawResponse, result any) error {
+	if resp.Error != "" {
+		return &ServerError{Method: method, Message: resp.Error, Code: resp.Code}
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// CallConfirmed performs a call guarded by the server safeguard, answering
+// the confirmation round-trip automatically
+func (c *Client) CallConfirmed(ctx context.Context, method string, params map[string]any, result any) error {
+	var raw json.RawMessage
+	if err := c.Call(ctx, method, params, &raw); err != nil {
+		return err
+	}
+
+	var confirm ConfirmationRequired
+	if json.Unmarshal(raw, &confirm) == nil && confirm.ConfirmationRequired {
+		confirmed := make(map[string]any, len(params)+1)
+		for k, v := range params {
+			confirmed[k] = v
+		}
+		confirmed["confirmToken"] = confirm.Token
+		return c.Call(ctx, method, confirmed, result)
+	}
+
+	if result == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// Ping checks that the server answers
+func (c *Client) Ping(ctx context.Context) error {
+	var pong string
+	if err := c.Call(ctx, "ping", nil, &pong); err != nil {
+		return err
+	}
+	if pong != "pong" {
+		return fmt.Errorf("unexpected ping reply %q", pong)
+	}
+	return nil
+}
+
+// ServerInfo returns the API version and current capabilities
+func (c *Client) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var info ServerInfo
+	err := c.Call(ctx, "getServerInfo", nil, &info)
+	return info, err
+}
+
+// ReloadConfig makes the server re-read daemon.toml
+func (c *Client) ReloadConfig(ctx context.Context) (ConfigReloadResult, error) {
+	var result ConfigReloadResult
+	err := c.Call(ctx, "config.reload", nil, &result)
+	return result, err
+}
+
+// Methods describes every method the server serves, with JSON Schemas of
+// its params and result
+func (c *Client) Methods(ctx context.Context) ([]MethodInfo, error) {
+	var methods []MethodInfo
+	err := c.Call(ctx, "introspect", nil, &methods)
+	return methods, err
+}
+
+// Method describes one method
+func (c *Client) Method(ctx context.Context, method string) (MethodInfo, error) {
+	var info MethodInfo
+	err := c.Call(ctx, "introspect", map[string]any{"method": method}, &info)
+	return info, err
+}
+
+type DebugAPI struct{ c *Client }
+
+func (c *Client) Debug() DebugAPI { return DebugAPI{c} }
+
+// StartRecording makes the server log sanitized requests and responses of
+// every client to the file name in its crash directory, or to
+// ipc-recording.jsonl when name is empty. A zero maxBytes uses the server's
+// cap.
+func (d DebugAPI) StartRecording(ctx context.Context, name string, maxBytes int64) (RecordingStatus, error) {
+	params := map[string]any{}
+	if name != "" {
+		params["name"] = name
+	}
+	if maxBytes > 0 {
+		params["maxBytes"] = maxBytes
+	}
+	return call[RecordingStatus](ctx, d.c, "debug.record.start", params)
+}
+
+func (d DebugAPI) StopRecording(ctx context.Context) (RecordingStatus, error) {
+	return call[RecordingStatus](ctx, d.c, "debug.record.stop", nil)
+}
+
+func (d DebugAPI) RecordingStatus(ctx context.Context) (RecordingStatus, error) {
+	return call[RecordingStatus](ctx, d.c, "debug.record.status", nil)
+}
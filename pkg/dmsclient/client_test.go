@@ -0,0 +1,287 @@
+package dmsclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	ID         int            `json:"id"`
+	Method     string         `json:"method"`
+	Params     map[string]any `json:"params"`
+	APIVersion int            `json:"apiVersion"`
+}
+
+type testResponse struct {
+	ID     int    `json:"id,omitempty"`
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Meta   any    `json:"meta,omitempty"`
+}
+
+// startServer runs a fake dms server that answers each request with
+// handler on its own goroutine, like the real router
+func startServer(t *testing.T, handler func(req testRequest, reply func(testResponse))) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dms.sock")
+	ln, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				enc := json.NewEncoder(conn)
+				var writeMu = make(chan struct{}, 1)
+				reply := func(resp testResponse) {
+					writeMu <- struct{}{}
+					enc.Encode(resp)
+					<-writeMu
+				}
+				enc.Encode(Capabilities{Capabilities: []string{"plugins", "power"}})
+
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var req testRequest
+					if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+						continue
+					}
+					go handler(req, reply)
+				}
+			}()
+		}
+	}()
+	return path
+}
+
+func dial(t *testing.T, path string) *Client {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, path)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_CallAndCapabilities(t *testing.T) {
+	path := startServer(t, func(req testRequest, reply func(testResponse)) {
+		switch req.Method {
+		case "ping":
+			reply(testResponse{ID: req.ID, Result: "pong"})
+		case "power.getState":
+			reply(testResponse{ID: req.ID, Result: PowerState{OnBattery: true, ActiveProfile: "balanced"}})
+		default:
+			reply(testResponse{ID: req.ID, Error: "unknown method: " + req.Method})
+		}
+	})
+	c := dial(t, path)
+	ctx := context.Background()
+
+	assert.Equal(t, []string{"plugins", "power"}, c.Capabilities())
+	assert.True(t, c.HasCapability("power"))
+	assert.False(t, c.HasCapability("network"))
+
+	require.NoError(t, c.Ping(ctx))
+
+	state, err := c.Power().GetState(ctx)
+	require.NoError(t, err)
+	assert.True(t, state.OnBattery)
+	assert.Equal(t, "balanced", state.ActiveProfile)
+
+	err = c.Call(ctx, "bogus.method", nil, nil)
+	var serverErr *ServerError
+	require.ErrorAs(t, err, &serverErr)
+	assert.Equal(t, "unknown method: bogus.method", serverErr.Message)
+}
+
+func TestClient_ConcurrentCallsAreMatchedByID(t *testing.T) {
+	path := startServer(t, func(req testRequest, reply func(testResponse)) {
+		// Answer later requests first
+		n := int(req.Params["n"].(float64))
+		time.Sleep(time.Duration(10-n) * 5 * time.Millisecond)
+		reply(testResponse{ID: req.ID, Result: n})
+	})
+	c := dial(t, path)
+
+	results := make(chan [2]int, 10)
+	for i := 0; i < 10; i++ {
+		go func(n int) {
+			var got int
+			err := c.Call(context.Background(), "echo", map[string]any{"n": n}, &got)
+			assert.NoError(t, err)
+			results <- [2]int{n, got}
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		r := <-results
+		assert.Equal(t, r[0], r[1])
+	}
+}
+
+func TestClient_CallConfirmed(t *testing.T) {
+	path := startServer(t, func(req testRequest, reply func(testResponse)) {
+		if req.Params["confirmToken"] == "tok" {
+			reply(testResponse{ID: req.ID, Result: SuccessResult{Success: true, Message: "jobs canceled"}})
+			return
+		}
+		reply(testResponse{ID: req.ID, Result: ConfirmationRequired{ConfirmationRequired: true, Method: req.Method, Token: "tok", ExpiresIn: 30}})
+	})
+	c := dial(t, path)
+
+	var result SuccessResult
+	require.NoError(t, c.CallConfirmed(context.Background(), "cups.purgeJobs", map[string]any{"printerName": "office"}, &result))
+	assert.Equal(t, "jobs canceled", result.Message)
+}
+
+func TestClient_ReportsDeprecations(t *testing.T) {
+	path := startServer(t, func(req testRequest, reply func(testResponse)) {
+		assert.Equal(t, APIVersion, req.APIVersion)
+		reply(testResponse{ID: req.ID, Result: SensorsState{}, Meta: map[string]any{
+			"apiVersion": APIVersion,
+			"deprecations": []Deprecation{{
+				Method:      req.Method,
+				Replacement: "sensors.list",
+				Since:       17,
+				Message:     "sensors.read is deprecated since API 17, use sensors.list",
+			}},
+		}})
+	})
+	c := dial(t, path)
+
+	var reported []Deprecation
+	c.OnDeprecation(func(d Deprecation) { reported = append(reported, d) })
+
+	require.NoError(t, c.Call(context.Background(), "sensors.read", nil, nil))
+	require.Len(t, reported, 1)
+	assert.Equal(t, "sensors.read", reported[0].Method)
+	assert.Equal(t, "sensors.list", reported[0].Replacement)
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	path := startServer(t, func(req testRequest, reply func(testResponse)) {
+		switch req.Method {
+		case "power.subscribe":
+			for _, profile := range []string{"balanced", "power-saver", "performance"} {
+				reply(testResponse{ID: req.ID, Result: PowerState{ActiveProfile: profile}})
+			}
+		case "sensors.subscribe":
+			reply(testResponse{ID: req.ID, Error: "sensors manager not initialized"})
+		}
+	})
+	c := dial(t, path)
+	ctx := context.Background()
+
+	sub, err := c.Power().Subscribe(ctx)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	var profiles []string
+	for len(profiles) < 3 {
+		select {
+		case state := <-sub.Events():
+			profiles = append(profiles, state.ActiveProfile)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+	assert.Equal(t, []string{"balanced", "power-saver", "performance"}, profiles)
+
+	failed, err := c.Sensors().Subscribe(ctx)
+	require.NoError(t, err)
+	select {
+	case _, ok := <-failed.Events():
+		assert.False(t, ok)
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription did not end")
+	}
+	var serverErr *ServerError
+	assert.ErrorAs(t, failed.Err(), &serverErr)
+}
+
+func TestClient_ContextAndClose(t *testing.T) {
+	path := startServer(t, func(req testRequest, reply func(testResponse)) {
+		// Never answer
+	})
+	c := dial(t, path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.Call(ctx, "slow", nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	done := make(chan error, 1)
+	go func() { done <- c.Call(context.Background(), "slow", nil, nil) }()
+	time.Sleep(20 * time.Millisecond)
+	c.Close()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrClosed)
+	case <-time.After(2 * time.Second):
+		t.Fatal("call did not fail after Close")
+	}
+	assert.ErrorIs(t, c.Call(context.Background(), "ping", nil, nil), ErrClosed)
+}
+
+func TestFindSocket_PrefersEnv(t *testing.T) {
+	t.Setenv("DMS_SOCKET", "/tmp/custom.sock")
+	path, err := FindSocket()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/custom.sock", path)
+}
+
+func TestFindSocket_SkipsDeadProcesses(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DMS_SOCKET", "")
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	// PID 1 always exists, an absurd PID never does
+	live := filepath.Join(dir, "danklinux-1.sock")
+	dead := filepath.Join(dir, "danklinux-999999999.sock")
+	for _, p := range []string{live, dead} {
+		ln, err := net.Listen("unix", p)
+		require.NoError(t, err)
+		defer ln.Close()
+	}
+
+	path, err := FindSocket()
+	require.NoError(t, err)
+	assert.Equal(t, live, path)
+}
+
+func TestFindSocket_PrefersOwnSession(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DMS_SOCKET", "")
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	t.Setenv("XDG_SESSION_ID", "3")
+
+	own := filepath.Join(dir, "danklinux-3-1.sock")
+	other := filepath.Join(dir, "danklinux-7-1.sock")
+	for _, p := range []string{own, other} {
+		ln, err := net.Listen("unix", p)
+		require.NoError(t, err)
+		defer ln.Close()
+	}
+	// the other session's server is newer but must not win
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(other, future, future))
+
+	path, err := FindSocket()
+	require.NoError(t, err)
+	assert.Equal(t, own, path)
+}
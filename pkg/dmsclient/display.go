@@ -0,0 +1,127 @@
+package dmsclient
+
+import "context"
+
+type GammaAPI struct{ c *Client }
+
+// Gamma wraps the wayland.gamma night light methods
+func (c *Client) Gamma() GammaAPI { return GammaAPI{c} }
+
+func (g GammaAPI) GetState(ctx context.Context) (GammaState, error) {
+	return call[GammaState](ctx, g.c, "wayland.gamma.getState", nil)
+}
+
+// SetTemperature sets the night (low) and day (high) temperatures in Kelvin
+func (g GammaAPI) SetTemperature(ctx context.Context, low, high int) error {
+	return g.c.Call(ctx, "wayland.gamma.setTemperature", map[string]any{"low": low, "high": high}, nil)
+}
+
+func (g GammaAPI) SetLocation(ctx context.Context, latitude, longitude float64) error {
+	return g.c.Call(ctx, "wayland.gamma.setLocation", map[string]any{"latitude": latitude, "longitude": longitude}, nil)
+}
+
+// SetManualTimes takes HH:MM times; empty strings clear them
+func (g GammaAPI) SetManualTimes(ctx context.Context, sunrise, sunset string) error {
+	return g.c.Call(ctx, "wayland.gamma.setManualTimes", map[string]any{"sunrise": sunrise, "sunset": sunset}, nil)
+}
+
+func (g GammaAPI) SetUseIPLocation(ctx context.Context, use bool) error {
+	return g.c.Call(ctx, "wayland.gamma.setUseIPLocation", map[string]any{"use": use}, nil)
+}
+
+func (g GammaAPI) SetGamma(ctx context.Context, gamma float64) error {
+	return g.c.Call(ctx, "wayland.gamma.setGamma", map[string]any{"gamma": gamma}, nil)
+}
+
+func (g GammaAPI) SetEnabled(ctx context.Context, enabled bool) error {
+	return g.c.Call(ctx, "wayland.gamma.setEnabled", map[string]any{"enabled": enabled}, nil)
+}
+
+func (g GammaAPI) Subscribe(ctx context.Context) (*Subscription[GammaState], error) {
+	return Subscribe[GammaState](ctx, g.c, "wayland.gamma.subscribe", nil)
+}
+
+type DWLAPI struct{ c *Client }
+
+func (c *Client) DWL() DWLAPI { return DWLAPI{c} }
+
+func (d DWLAPI) GetState(ctx context.Context) (DWLState, error) {
+	return call[DWLState](ctx, d.c, "dwl.getState", nil)
+}
+
+func (d DWLAPI) SetTags(ctx context.Context, output string, tagmask uint32, toggleTagset uint32) error {
+	return d.c.Call(ctx, "dwl.setTags", map[string]any{"output": output, "tagmask": tagmask, "toggleTagset": toggleTagset}, nil)
+}
+
+func (d DWLAPI) SetClientTags(ctx context.Context, output string, andTags, xorTags uint32) error {
+	return d.c.Call(ctx, "dwl.setClientTags", map[string]any{"output": output, "andTags": andTags, "xorTags": xorTags}, nil)
+}
+
+func (d DWLAPI) SetLayout(ctx context.Context, output string, index uint32) error {
+	return d.c.Call(ctx, "dwl.setLayout", map[string]any{"output": output, "index": index}, nil)
+}
+
+func (d DWLAPI) Subscribe(ctx context.Context) (*Subscription[DWLState], error) {
+	return Subscribe[DWLState](ctx, d.c, "dwl.subscribe", nil)
+}
+
+type BrightnessAPI struct{ c *Client }
+
+func (c *Client) Brightness() BrightnessAPI { return BrightnessAPI{c} }
+
+func (b BrightnessAPI) GetState(ctx context.Context) (BrightnessState, error) {
+	return call[BrightnessState](ctx, b.c, "brightness.getState", nil)
+}
+
+func (b BrightnessAPI) SetBrightness(ctx context.Context, device string, percent int) (BrightnessState, error) {
+	return call[BrightnessState](ctx, b.c, "brightness.setBrightness", map[string]any{"device": device, "percent": percent})
+}
+
+// Increment raises brightness by step percent; zero uses the server default
+func (b BrightnessAPI) Increment(ctx context.Context, device string, step int) (BrightnessState, error) {
+	return call[BrightnessState](ctx, b.c, "brightness.increment", stepParams(device, step))
+}
+
+func (b BrightnessAPI) Decrement(ctx context.Context, device string, step int) (BrightnessState, error) {
+	return call[BrightnessState](ctx, b.c, "brightness.decrement", stepParams(device, step))
+}
+
+// Step moves brightness one step up or down along the step curve configured
+// for the input source ("keys" or "slider")
+func (b BrightnessAPI) Step(ctx context.Context, device, source string, up bool) (BrightnessState, error) {
+	method := "brightness.decrement"
+	if up {
+		method = "brightness.increment"
+	}
+	return call[BrightnessState](ctx, b.c, method, map[string]any{"device": device, "source": source})
+}
+
+func stepParams(device string, step int) map[string]any {
+	params := map[string]any{"device": device}
+	if step > 0 {
+		params["step"] = step
+	}
+	return params
+}
+
+func (b BrightnessAPI) Rescan(ctx context.Context) (BrightnessState, error) {
+	return call[BrightnessState](ctx, b.c, "brightness.rescan", nil)
+}
+
+func (b BrightnessAPI) Subscribe(ctx context.Context) (*Subscription[BrightnessState], error) {
+	return Subscribe[BrightnessState](ctx, b.c, "brightness.subscribe", nil)
+}
+
+// DDCCapabilities returns the VCP features a DDC monitor reports
+func (b BrightnessAPI) DDCCapabilities(ctx context.Context, device string) (*DDCCapabilities, error) {
+	return call[*DDCCapabilities](ctx, b.c, "brightness.ddc.capabilities", map[string]any{"device": device})
+}
+
+func (b BrightnessAPI) SetDDCContrast(ctx context.Context, device string, percent int) error {
+	return b.c.Call(ctx, "brightness.ddc.setContrast", map[string]any{"device": device, "percent": percent}, nil)
+}
+
+// SetDDCInput switches input; input is a VCP 0x60 value or a name like "hdmi-1"
+func (b BrightnessAPI) SetDDCInput(ctx context.Context, device string, input any) error {
+	return b.c.Call(ctx, "brightness.ddc.setInput", map[string]any{"device": device, "input": input}, nil)
+}
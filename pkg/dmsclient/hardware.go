@@ -0,0 +1,224 @@
+package dmsclient
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+type BluetoothAPI struct{ c *Client }
+
+func (c *Client) Bluetooth() BluetoothAPI { return BluetoothAPI{c} }
+
+func (b BluetoothAPI) GetState(ctx context.Context) (BluetoothState, error) {
+	return call[BluetoothState](ctx, b.c, "bluetooth.getState", nil)
+}
+
+func (b BluetoothAPI) StartDiscovery(ctx context.Context) error {
+	return b.c.Call(ctx, "bluetooth.startDiscovery", nil, nil)
+}
+
+func (b BluetoothAPI) StopDiscovery(ctx context.Context) error {
+	return b.c.Call(ctx, "bluetooth.stopDiscovery", nil, nil)
+}
+
+func (b BluetoothAPI) SetPowered(ctx context.Context, powered bool) error {
+	return b.c.Call(ctx, "bluetooth.setPowered", map[string]any{"powered": powered}, nil)
+}
+
+// Pair, Connect and the other device methods take the device object path
+func (b BluetoothAPI) Pair(ctx context.Context, device string) error {
+	return b.c.Call(ctx, "bluetooth.pair", map[string]any{"device": device}, nil)
+}
+
+func (b BluetoothAPI) Connect(ctx context.Context, device string) error {
+	return b.c.Call(ctx, "bluetooth.connect", map[string]any{"device": device}, nil)
+}
+
+func (b BluetoothAPI) Disconnect(ctx context.Context, device string) error {
+	return b.c.Call(ctx, "bluetooth.disconnect", map[string]any{"device": device}, nil)
+}
+
+func (b BluetoothAPI) Remove(ctx context.Context, device string) error {
+	return b.c.Call(ctx, "bluetooth.remove", map[string]any{"device": device}, nil)
+}
+
+func (b BluetoothAPI) Trust(ctx context.Context, device string) error {
+	return b.c.Call(ctx, "bluetooth.trust", map[string]any{"device": device}, nil)
+}
+
+func (b BluetoothAPI) Untrust(ctx context.Context, device string) error {
+	return b.c.Call(ctx, "bluetooth.untrust", map[string]any{"device": device}, nil)
+}
+
+func (b BluetoothAPI) SubmitPairing(ctx context.Context, token string, secrets map[string]string, accept bool) error {
+	return b.c.Call(ctx, "bluetooth.pairing.submit", map[string]any{"token": token, "secrets": secrets, "accept": accept}, nil)
+}
+
+func (b BluetoothAPI) CancelPairing(ctx context.Context, token string) error {
+	return b.c.Call(ctx, "bluetooth.pairing.cancel", map[string]any{"token": token}, nil)
+}
+
+func (b BluetoothAPI) Subscribe(ctx context.Context) (*Subscription[BluetoothEvent], error) {
+	return Subscribe[BluetoothEvent](ctx, b.c, "bluetooth.subscribe", nil)
+}
+
+type CUPSAPI struct{ c *Client }
+
+func (c *Client) CUPS() CUPSAPI { return CUPSAPI{c} }
+
+func (p CUPSAPI) Printers(ctx context.Context) ([]Printer, error) {
+	return call[[]Printer](ctx, p.c, "cups.getPrinters", nil)
+}
+
+func (p CUPSAPI) Jobs(ctx context.Context, printerName string) ([]PrintJob, error) {
+	return call[[]PrintJob](ctx, p.c, "cups.getJobs", map[string]any{"printerName": printerName})
+}
+
+func (p CUPSAPI) PausePrinter(ctx context.Context, printerName string) error {
+	return p.c.Call(ctx, "cups.pausePrinter", map[string]any{"printerName": printerName}, nil)
+}
+
+func (p CUPSAPI) ResumePrinter(ctx context.Context, printerName string) error {
+	return p.c.Call(ctx, "cups.resumePrinter", map[string]any{"printerName": printerName}, nil)
+}
+
+func (p CUPSAPI) CancelJob(ctx context.Context, jobID int) error {
+	return p.c.Call(ctx, "cups.cancelJob", map[string]any{"jobID": jobID}, nil)
+}
+
+// RetryJob resumes the job's printer if it stopped and restarts the job if
+// it was aborted
+func (p CUPSAPI) RetryJob(ctx context.Context, jobID int) error {
+	return p.c.Call(ctx, "cups.retryJob", map[string]any{"jobID": jobID}, nil)
+}
+
+// PurgeJobs cancels every job on a printer, confirming the safeguard prompt
+func (p CUPSAPI) PurgeJobs(ctx context.Context, printerName string) error {
+	return p.c.CallConfirmed(ctx, "cups.purgeJobs", map[string]any{"printerName": printerName}, nil)
+}
+
+// DeletePrinter removes a printer queue, confirming the safeguard prompt
+func (p CUPSAPI) DeletePrinter(ctx context.Context, printerName string) error {
+	return p.c.CallConfirmed(ctx, "cups.deletePrinter", map[string]any{"printerName": printerName}, nil)
+}
+
+// PrintFile prints a file readable by the server
+func (p CUPSAPI) PrintFile(ctx context.Context, printerName, path, title string) (int, error) {
+	return p.print(ctx, map[string]any{"printerName": printerName, "path": path, "title": title})
+}
+
+func (p CUPSAPI) PrintURL(ctx context.Context, printerName, url, title string) (int, error) {
+	return p.print(ctx, map[string]any{"printerName": printerName, "url": url, "title": title})
+}
+
+// PrintData sends the document inline, base64-encoded
+func (p CUPSAPI) PrintData(ctx context.Context, printerName string, data []byte, title string) (int, error) {
+	return p.print(ctx, map[string]any{"printerName": printerName, "data": base64.StdEncoding.EncodeToString(data), "title": title})
+}
+
+func (p CUPSAPI) print(ctx context.Context, params map[string]any) (int, error) {
+	result, err := call[PrintResult](ctx, p.c, "cups.print", params)
+	return result.JobID, err
+}
+
+func (p CUPSAPI) ServerSettings(ctx context.Context) (CUPSServerSettings, error) {
+	return call[CUPSServerSettings](ctx, p.c, "cups.getServerSettings", nil)
+}
+
+// CUPSServerSettingsUpdate changes only the fields that are set
+type CUPSServerSettingsUpdate struct {
+	SharePrinters *bool
+	RemoteAny     *bool
+	RemoteAdmin   *bool
+	UserCancelAny *bool
+	DebugLogging  *bool
+	BrowseRemote  *bool
+}
+
+// SetServerSettings may block on a polkit prompt on the server's session
+func (p CUPSAPI) SetServerSettings(ctx context.Context, update CUPSServerSettingsUpdate) (CUPSServerSettings, error) {
+	params := map[string]any{}
+	set := func(name string, value *bool) {
+		if value != nil {
+			params[name] = *value
+		}
+	}
+	set("sharePrinters", update.SharePrinters)
+	set("remoteAny", update.RemoteAny)
+	set("remoteAdmin", update.RemoteAdmin)
+	set("userCancelAny", update.UserCancelAny)
+	set("debugLogging", update.DebugLogging)
+	set("browseRemote", update.BrowseRemote)
+	return call[CUPSServerSettings](ctx, p.c, "cups.setServerSettings", params)
+}
+
+// Devices runs printer discovery, which can take several seconds
+func (p CUPSAPI) Devices(ctx context.Context) ([]PrinterDevice, error) {
+	return call[[]PrinterDevice](ctx, p.c, "cups.getDevices", nil)
+}
+
+// AutoAdd creates an IPP Everywhere queue for a discovered device. An empty
+// name is derived from the device; it may block on a polkit prompt.
+func (p CUPSAPI) AutoAdd(ctx context.Context, uri, name string) (AutoAddResult, error) {
+	params := map[string]any{"uri": uri}
+	if name != "" {
+		params["name"] = name
+	}
+	return call[AutoAddResult](ctx, p.c, "cups.autoAdd", params)
+}
+
+func (p CUPSAPI) Subscribe(ctx context.Context) (*Subscription[CUPSEvent], error) {
+	return Subscribe[CUPSEvent](ctx, p.c, "cups.subscribe", nil)
+}
+
+type SensorsAPI struct{ c *Client }
+
+func (c *Client) Sensors() SensorsAPI { return SensorsAPI{c} }
+
+func (s SensorsAPI) List(ctx context.Context) (SensorsState, error) {
+	return call[SensorsState](ctx, s.c, "sensors.list", nil)
+}
+
+// SetThreshold sets a warning level in °C; zero or below resets it
+func (s SensorsAPI) SetThreshold(ctx context.Context, sensor string, value float64) error {
+	return s.c.Call(ctx, "sensors.setThreshold", map[string]any{"sensor": sensor, "value": value}, nil)
+}
+
+func (s SensorsAPI) SetDefaultThreshold(ctx context.Context, value float64) error {
+	return s.c.Call(ctx, "sensors.setDefaultThreshold", map[string]any{"value": value}, nil)
+}
+
+func (s SensorsAPI) Subscribe(ctx context.Context) (*Subscription[SensorsState], error) {
+	return Subscribe[SensorsState](ctx, s.c, "sensors.subscribe", nil)
+}
+
+type PowerAPI struct{ c *Client }
+
+func (c *Client) Power() PowerAPI { return PowerAPI{c} }
+
+func (p PowerAPI) GetState(ctx context.Context) (PowerState, error) {
+	return call[PowerState](ctx, p.c, "power.getState", nil)
+}
+
+func (p PowerAPI) SetProfile(ctx context.Context, profile string) error {
+	return p.c.Call(ctx, "power.setProfile", map[string]any{"profile": profile}, nil)
+}
+
+func (p PowerAPI) Policy(ctx context.Context) (PowerPolicy, error) {
+	return call[PowerPolicy](ctx, p.c, "power.policy.get", nil)
+}
+
+// SetPolicy replaces the whole policy and returns what the server stored
+func (p PowerAPI) SetPolicy(ctx context.Context, policy PowerPolicy) (PowerPolicy, error) {
+	return call[PowerPolicy](ctx, p.c, "power.policy.set", map[string]any{
+		"enabled":        policy.Enabled,
+		"acProfile":      policy.ACProfile,
+		"batteryProfile": policy.BatteryProfile,
+		"brightnessCap":  policy.BatteryBrightnessCap,
+		"refreshRate":    policy.BatteryRefreshRate,
+	})
+}
+
+func (p PowerAPI) Subscribe(ctx context.Context) (*Subscription[PowerState], error) {
+	return Subscribe[PowerState](ctx, p.c, "power.subscribe", nil)
+}
@@ -0,0 +1,213 @@
+package dmsclient
+
+import "context"
+
+func call[T any](ctx context.Context, c *Client, method string, params map[string]any) (T, error) {
+	var result T
+	err := c.Call(ctx, method, params, &result)
+	return result, err
+}
+
+type NetworkAPI struct{ c *Client }
+
+func (c *Client) Network() NetworkAPI { return NetworkAPI{c} }
+
+// WiFiConnectOptions are the optional parameters of network.wifi.connect.
+// Interactive nil lets the server decide whether to prompt for secrets.
+type WiFiConnectOptions struct {
+	Password          string
+	Username          string
+	AnonymousIdentity string
+	DomainSuffixMatch string
+	Interactive       *bool
+}
+
+func (n NetworkAPI) GetState(ctx context.Context) (NetworkState, error) {
+	return call[NetworkState](ctx, n.c, "network.getState", nil)
+}
+
+func (n NetworkAPI) Scan(ctx context.Context) error {
+	return n.c.Call(ctx, "network.wifi.scan", nil, nil)
+}
+
+func (n NetworkAPI) WiFiNetworks(ctx context.Context) ([]WiFiNetwork, error) {
+	return call[[]WiFiNetwork](ctx, n.c, "network.wifi.networks", nil)
+}
+
+func (n NetworkAPI) ConnectWiFi(ctx context.Context, ssid string, opts WiFiConnectOptions) error {
+	params := map[string]any{"ssid": ssid}
+	if opts.Password != "" {
+		params["password"] = opts.Password
+	}
+	if opts.Username != "" {
+		params["username"] = opts.Username
+	}
+	if opts.AnonymousIdentity != "" {
+		params["anonymousIdentity"] = opts.AnonymousIdentity
+	}
+	if opts.DomainSuffixMatch != "" {
+		params["domainSuffixMatch"] = opts.DomainSuffixMatch
+	}
+	if opts.Interactive != nil {
+		params["interactive"] = *opts.Interactive
+	}
+	return n.c.Call(ctx, "network.wifi.connect", params, nil)
+}
+
+func (n NetworkAPI) DisconnectWiFi(ctx context.Context) error {
+	return n.c.Call(ctx, "network.wifi.disconnect", nil, nil)
+}
+
+// ForgetWiFi deletes a saved network, confirming the safeguard prompt
+func (n NetworkAPI) ForgetWiFi(ctx context.Context, ssid string) error {
+	return n.c.CallConfirmed(ctx, "network.wifi.forget", map[string]any{"ssid": ssid}, nil)
+}
+
+// SetWiFiEnabled turns the radio on or off and returns the new state
+func (n NetworkAPI) SetWiFiEnabled(ctx context.Context, enabled bool) (bool, error) {
+	method := "network.wifi.disable"
+	if enabled {
+		method = "network.wifi.enable"
+	}
+	result, err := call[map[string]bool](ctx, n.c, method, nil)
+	return result["enabled"], err
+}
+
+func (n NetworkAPI) ToggleWiFi(ctx context.Context) (bool, error) {
+	result, err := call[map[string]bool](ctx, n.c, "network.wifi.toggle", nil)
+	return result["enabled"], err
+}
+
+func (n NetworkAPI) SetWiFiAutoconnect(ctx context.Context, ssid string, autoconnect bool) error {
+	return n.c.Call(ctx, "network.wifi.setAutoconnect", map[string]any{"ssid": ssid, "autoconnect": autoconnect}, nil)
+}
+
+// WiFiRoaming returns the BSSID pin, band preference and roaming mode of a
+// saved network
+func (n NetworkAPI) WiFiRoaming(ctx context.Context, ssid string) (WiFiRoaming, error) {
+	return call[WiFiRoaming](ctx, n.c, "network.wifi.getRoaming", map[string]any{"ssid": ssid})
+}
+
+// SetWiFiRoaming replaces all three roaming settings of a saved network. An
+// empty BSSID unpins it.
+func (n NetworkAPI) SetWiFiRoaming(ctx context.Context, prefs WiFiRoaming) error {
+	return n.c.Call(ctx, "network.wifi.setRoaming", map[string]any{
+		"ssid":    prefs.SSID,
+		"bssid":   prefs.BSSID,
+		"band":    prefs.Band,
+		"roaming": prefs.Roaming,
+	}, nil)
+}
+
+func (n NetworkAPI) ConnectEthernet(ctx context.Context) error {
+	return n.c.Call(ctx, "network.ethernet.connect", nil, nil)
+}
+
+// ConnectEthernetConfig activates a specific wired connection profile
+func (n NetworkAPI) ConnectEthernetConfig(ctx context.Context, uuid string) error {
+	return n.c.Call(ctx, "network.ethernet.connect.config", map[string]any{"uuid": uuid}, nil)
+}
+
+func (n NetworkAPI) DisconnectEthernet(ctx context.Context) error {
+	return n.c.Call(ctx, "network.ethernet.disconnect", nil, nil)
+}
+
+// SetPreference chooses between "auto", "wifi" and "ethernet"
+func (n NetworkAPI) SetPreference(ctx context.Context, preference string) error {
+	return n.c.Call(ctx, "network.preference.set", map[string]any{"preference": preference}, nil)
+}
+
+func (n NetworkAPI) Info(ctx context.Context, ssid string) (NetworkInfo, error) {
+	return call[NetworkInfo](ctx, n.c, "network.info", map[string]any{"ssid": ssid})
+}
+
+func (n NetworkAPI) EthernetInfo(ctx context.Context, uuid string) (WiredNetworkInfo, error) {
+	return call[WiredNetworkInfo](ctx, n.c, "network.ethernet.info", map[string]any{"uuid": uuid})
+}
+
+func (n NetworkAPI) SubmitCredentials(ctx context.Context, token string, secrets map[string]string, save bool) error {
+	return n.c.Call(ctx, "network.credentials.submit", map[string]any{"token": token, "secrets": secrets, "save": save}, nil)
+}
+
+func (n NetworkAPI) CancelCredentials(ctx context.Context, token string) error {
+	return n.c.Call(ctx, "network.credentials.cancel", map[string]any{"token": token}, nil)
+}
+
+func (n NetworkAPI) VPNProfiles(ctx context.Context) ([]VPNProfile, error) {
+	return call[[]VPNProfile](ctx, n.c, "network.vpn.profiles", nil)
+}
+
+func (n NetworkAPI) ActiveVPN(ctx context.Context) ([]VPNActive, error) {
+	return call[[]VPNActive](ctx, n.c, "network.vpn.active", nil)
+}
+
+// ConnectVPN activates a VPN by UUID or name. With singleActive, other VPNs
+// are disconnected first.
+func (n NetworkAPI) ConnectVPN(ctx context.Context, uuidOrName string, singleActive bool) error {
+	return n.c.Call(ctx, "network.vpn.connect", map[string]any{"uuidOrName": uuidOrName, "singleActive": singleActive}, nil)
+}
+
+func (n NetworkAPI) DisconnectVPN(ctx context.Context, uuidOrName string) error {
+	return n.c.Call(ctx, "network.vpn.disconnect", map[string]any{"uuidOrName": uuidOrName}, nil)
+}
+
+func (n NetworkAPI) DisconnectAllVPN(ctx context.Context) error {
+	return n.c.Call(ctx, "network.vpn.disconnectAll", nil, nil)
+}
+
+func (n NetworkAPI) ClearVPNCredentials(ctx context.Context, uuidOrName string) error {
+	return n.c.Call(ctx, "network.vpn.clearCredentials", map[string]any{"uuidOrName": uuidOrName}, nil)
+}
+
+// AppUsage returns per-application traffic, busiest first. It fails until
+// the accounting helper is enabled with `dms netacct enable`.
+func (n NetworkAPI) AppUsage(ctx context.Context, limit int) (AppUsageReport, error) {
+	params := map[string]any{}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	return call[AppUsageReport](ctx, n.c, "network.appUsage", params)
+}
+
+// SpeedTest runs a speed test against the endpoints set in daemon.toml. It
+// takes up to 45 seconds, so ctx should allow for that.
+func (n NetworkAPI) SpeedTest(ctx context.Context) (SpeedTestResult, error) {
+	return call[SpeedTestResult](ctx, n.c, "network.speedTest", nil)
+}
+
+// StartSpeedTest runs a speed test as a job and returns without waiting.
+// Its progress and result are followed with Jobs().
+func (n NetworkAPI) StartSpeedTest(ctx context.Context) (Job, error) {
+	return call[Job](ctx, n.c, "network.speedTest", map[string]any{"async": true})
+}
+
+// Tethers lists the USB and Bluetooth phones that can share their
+// connection
+func (n NetworkAPI) Tethers(ctx context.Context) ([]TetherDevice, error) {
+	return call[[]TetherDevice](ctx, n.c, "network.tether.list", nil)
+}
+
+// ConnectTether connects through the phone with the given TetherDevice ID
+func (n NetworkAPI) ConnectTether(ctx context.Context, id string) (SuccessResult, error) {
+	return call[SuccessResult](ctx, n.c, "network.tether.connect", map[string]any{"id": id})
+}
+
+// DisconnectTether disconnects a Bluetooth tethering phone. USB tethering
+// ends when the phone turns it off or is unplugged.
+func (n NetworkAPI) DisconnectTether(ctx context.Context, id string) (SuccessResult, error) {
+	return call[SuccessResult](ctx, n.c, "network.tether.disconnect", map[string]any{"id": id})
+}
+
+// LinkHistory returns up to limit recent Wi-Fi link samples, oldest first,
+// with the last speed test result. A zero limit returns all of them.
+func (n NetworkAPI) LinkHistory(ctx context.Context, limit int) (LinkHistory, error) {
+	params := map[string]any{}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	return call[LinkHistory](ctx, n.c, "network.linkHistory", params)
+}
+
+func (n NetworkAPI) Subscribe(ctx context.Context) (*Subscription[NetworkEvent], error) {
+	return Subscribe[NetworkEvent](ctx, n.c, "network.subscribe", nil)
+}
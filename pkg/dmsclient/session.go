@@ -0,0 +1,93 @@
+package dmsclient
+
+import "context"
+
+type SessionAPI struct{ c *Client }
+
+// Session wraps the loginctl methods
+func (c *Client) Session() SessionAPI { return SessionAPI{c} }
+
+func (s SessionAPI) GetState(ctx context.Context) (SessionState, error) {
+	return call[SessionState](ctx, s.c, "loginctl.getState", nil)
+}
+
+func (s SessionAPI) Lock(ctx context.Context) error {
+	return s.c.Call(ctx, "loginctl.lock", nil, nil)
+}
+
+func (s SessionAPI) Unlock(ctx context.Context) error {
+	return s.c.Call(ctx, "loginctl.unlock", nil, nil)
+}
+
+func (s SessionAPI) Activate(ctx context.Context) error {
+	return s.c.Call(ctx, "loginctl.activate", nil, nil)
+}
+
+func (s SessionAPI) SetIdleHint(ctx context.Context, idle bool) error {
+	return s.c.Call(ctx, "loginctl.setIdleHint", map[string]any{"idle": idle}, nil)
+}
+
+func (s SessionAPI) SetLockBeforeSuspend(ctx context.Context, enabled bool) error {
+	return s.c.Call(ctx, "loginctl.setLockBeforeSuspend", map[string]any{"enabled": enabled}, nil)
+}
+
+func (s SessionAPI) SetSleepInhibitorEnabled(ctx context.Context, enabled bool) error {
+	return s.c.Call(ctx, "loginctl.setSleepInhibitorEnabled", map[string]any{"enabled": enabled}, nil)
+}
+
+// LockerReady tells the server the lock screen is up so suspend may proceed
+func (s SessionAPI) LockerReady(ctx context.Context) error {
+	return s.c.Call(ctx, "loginctl.lockerReady", nil, nil)
+}
+
+// Terminate ends the session, confirming the safeguard prompt
+func (s SessionAPI) Terminate(ctx context.Context) error {
+	return s.c.CallConfirmed(ctx, "loginctl.terminate", nil, nil)
+}
+
+func (s SessionAPI) Subscribe(ctx context.Context) (*Subscription[SessionEvent], error) {
+	return Subscribe[SessionEvent](ctx, s.c, "loginctl.subscribe", nil)
+}
+
+type FreedesktopAPI struct{ c *Client }
+
+func (c *Client) Freedesktop() FreedesktopAPI { return FreedesktopAPI{c} }
+
+func (f FreedesktopAPI) GetState(ctx context.Context) (FreedesktopState, error) {
+	return call[FreedesktopState](ctx, f.c, "freedesktop.getState", nil)
+}
+
+func (f FreedesktopAPI) SetIconFile(ctx context.Context, path string) error {
+	return f.c.Call(ctx, "freedesktop.accounts.setIconFile", map[string]any{"path": path}, nil)
+}
+
+func (f FreedesktopAPI) SetRealName(ctx context.Context, name string) error {
+	return f.c.Call(ctx, "freedesktop.accounts.setRealName", map[string]any{"name": name}, nil)
+}
+
+func (f FreedesktopAPI) SetEmail(ctx context.Context, email string) error {
+	return f.c.Call(ctx, "freedesktop.accounts.setEmail", map[string]any{"email": email}, nil)
+}
+
+func (f FreedesktopAPI) SetLanguage(ctx context.Context, language string) error {
+	return f.c.Call(ctx, "freedesktop.accounts.setLanguage", map[string]any{"language": language}, nil)
+}
+
+func (f FreedesktopAPI) SetLocation(ctx context.Context, location string) error {
+	return f.c.Call(ctx, "freedesktop.accounts.setLocation", map[string]any{"location": location}, nil)
+}
+
+func (f FreedesktopAPI) UserIconFile(ctx context.Context, username string) (string, error) {
+	result, err := call[SuccessResult](ctx, f.c, "freedesktop.accounts.getUserIconFile", map[string]any{"username": username})
+	return result.Value, err
+}
+
+// ColorScheme returns the portal value: 0 no preference, 1 dark, 2 light
+func (f FreedesktopAPI) ColorScheme(ctx context.Context) (uint32, error) {
+	result, err := call[map[string]uint32](ctx, f.c, "freedesktop.settings.getColorScheme", nil)
+	return result["colorScheme"], err
+}
+
+func (f FreedesktopAPI) SetIconTheme(ctx context.Context, iconTheme string) error {
+	return f.c.Call(ctx, "freedesktop.settings.setIconTheme", map[string]any{"iconTheme": iconTheme}, nil)
+}
@@ -0,0 +1,532 @@
+package dmsclient
+
+import "context"
+
+type NotificationsAPI struct{ c *Client }
+
+func (c *Client) Notifications() NotificationsAPI { return NotificationsAPI{c} }
+
+func (n NotificationsAPI) Forwarding(ctx context.Context) (ForwardConfig, error) {
+	return call[ForwardConfig](ctx, n.c, "notifications.getForwarding", nil)
+}
+
+func (n NotificationsAPI) SetForwarding(ctx context.Context, urgency NotificationUrgency, target ForwardTarget) error {
+	return n.c.Call(ctx, "notifications.setForwarding", map[string]any{
+		"urgency":  string(urgency),
+		"file":     target.File,
+		"terminal": target.Terminal,
+		"bell":     target.Bell,
+	}, nil)
+}
+
+func (n NotificationsAPI) Forward(ctx context.Context, notification Notification) (ForwardResult, error) {
+	return call[ForwardResult](ctx, n.c, "notifications.forward", map[string]any{
+		"summary": notification.Summary,
+		"body":    notification.Body,
+		"appName": notification.AppName,
+		"urgency": string(notification.Urgency),
+	})
+}
+
+type PromptsAPI struct{ c *Client }
+
+func (c *Client) Prompts() PromptsAPI { return PromptsAPI{c} }
+
+func (p PromptsAPI) List(ctx context.Context) ([]Prompt, error) {
+	return call[[]Prompt](ctx, p.c, "prompts.list", nil)
+}
+
+func (p PromptsAPI) Respond(ctx context.Context, token, action, value string) error {
+	params := map[string]any{"token": token, "action": action}
+	if value != "" {
+		params["value"] = value
+	}
+	return p.c.Call(ctx, "prompts.respond", params, nil)
+}
+
+// Submit allows a prompt that asked for secrets, such as a pairing PIN or a
+// Wi-Fi passphrase. remember keeps them where the module can.
+func (p PromptsAPI) Submit(ctx context.Context, token string, secrets map[string]string, remember bool) error {
+	params := map[string]any{"token": token, "action": "allow", "secrets": secrets}
+	if remember {
+		params["remember"] = true
+	}
+	return p.c.Call(ctx, "prompts.respond", params, nil)
+}
+
+func (p PromptsAPI) Subscribe(ctx context.Context) (*Subscription[PromptEvent], error) {
+	return Subscribe[PromptEvent](ctx, p.c, "prompts.subscribe", nil)
+}
+
+type LauncherAPI struct{ c *Client }
+
+func (c *Client) Launcher() LauncherAPI { return LauncherAPI{c} }
+
+// Search ranks applications for query; limit zero uses the server default
+func (l LauncherAPI) Search(ctx context.Context, query string, limit int) ([]LauncherResult, error) {
+	params := map[string]any{"query": query}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	return call[[]LauncherResult](ctx, l.c, "launcher.search", params)
+}
+
+func (l LauncherAPI) Launch(ctx context.Context, id string) error {
+	return l.c.Call(ctx, "launcher.launch", map[string]any{"id": id}, nil)
+}
+
+func (l LauncherAPI) Frecency(ctx context.Context, limit int) ([]FrecencyEntry, error) {
+	var params map[string]any
+	if limit > 0 {
+		params = map[string]any{"limit": limit}
+	}
+	return call[[]FrecencyEntry](ctx, l.c, "launcher.frecency", params)
+}
+
+func (l LauncherAPI) Refresh(ctx context.Context) error {
+	return l.c.Call(ctx, "launcher.refresh", nil, nil)
+}
+
+type PluginsAPI struct{ c *Client }
+
+func (c *Client) Plugins() PluginsAPI { return PluginsAPI{c} }
+
+func (p PluginsAPI) List(ctx context.Context) ([]PluginInfo, error) {
+	return call[[]PluginInfo](ctx, p.c, "plugins.list", nil)
+}
+
+func (p PluginsAPI) ListInstalled(ctx context.Context) ([]PluginInfo, error) {
+	return call[[]PluginInfo](ctx, p.c, "plugins.listInstalled", nil)
+}
+
+// PluginSearch narrows plugins.search; empty fields are ignored
+type PluginSearch struct {
+	Query      string
+	Category   string
+	Compositor string
+	Capability string
+}
+
+func (p PluginsAPI) Search(ctx context.Context, search PluginSearch) ([]PluginInfo, error) {
+	params := map[string]any{"query": search.Query}
+	if search.Category != "" {
+		params["category"] = search.Category
+	}
+	if search.Compositor != "" {
+		params["compositor"] = search.Compositor
+	}
+	if search.Capability != "" {
+		params["capability"] = search.Capability
+	}
+	return call[[]PluginInfo](ctx, p.c, "plugins.search", params)
+}
+
+func (p PluginsAPI) Install(ctx context.Context, idOrName string) error {
+	return p.c.Call(ctx, "plugins.install", map[string]any{"name": idOrName}, nil)
+}
+
+func (p PluginsAPI) Uninstall(ctx context.Context, name string) error {
+	return p.c.Call(ctx, "plugins.uninstall", map[string]any{"name": name}, nil)
+}
+
+func (p PluginsAPI) Update(ctx context.Context, name string) error {
+	return p.c.Call(ctx, "plugins.update", map[string]any{"name": name}, nil)
+}
+
+type SettingsAPI struct{ c *Client }
+
+func (c *Client) Settings() SettingsAPI { return SettingsAPI{c} }
+
+// Export writes a settings archive; an empty path uses the server default
+func (s SettingsAPI) Export(ctx context.Context, path string) (SettingsExport, error) {
+	var params map[string]any
+	if path != "" {
+		params = map[string]any{"path": path}
+	}
+	return call[SettingsExport](ctx, s.c, "settings.export", params)
+}
+
+func (s SettingsAPI) Import(ctx context.Context, path string) (SettingsRestore, error) {
+	return call[SettingsRestore](ctx, s.c, "settings.import", map[string]any{"path": path})
+}
+
+type RulesAPI struct{ c *Client }
+
+func (c *Client) Rules() RulesAPI { return RulesAPI{c} }
+
+func (r RulesAPI) GetState(ctx context.Context) (WindowRulesState, error) {
+	return call[WindowRulesState](ctx, r.c, "rules.getState", nil)
+}
+
+func (r RulesAPI) Reload(ctx context.Context) (WindowRulesState, error) {
+	return call[WindowRulesState](ctx, r.c, "rules.reload", nil)
+}
+
+// Match reports which rules would apply to a window, without moving it
+func (r RulesAPI) Match(ctx context.Context, appID, title string) (WindowRulesMatch, error) {
+	return call[WindowRulesMatch](ctx, r.c, "rules.match", map[string]any{"appId": appID, "title": title})
+}
+
+type ScratchpadAPI struct{ c *Client }
+
+func (c *Client) Scratchpad() ScratchpadAPI { return ScratchpadAPI{c} }
+
+func (s ScratchpadAPI) GetState(ctx context.Context) (ScratchpadState, error) {
+	return call[ScratchpadState](ctx, s.c, "scratchpad.getState", nil)
+}
+
+func (s ScratchpadAPI) Reload(ctx context.Context) (ScratchpadState, error) {
+	return call[ScratchpadState](ctx, s.c, "scratchpad.reload", nil)
+}
+
+// Toggle starts, shows or hides a pad. A non-empty command declares the pad
+// for this daemon run, so it does not need to be in scratchpads.toml.
+func (s ScratchpadAPI) Toggle(ctx context.Context, name, command, appID string) (ScratchpadToggleResult, error) {
+	params := map[string]any{"name": name}
+	if command != "" {
+		params["command"] = command
+		params["appId"] = appID
+	}
+	return call[ScratchpadToggleResult](ctx, s.c, "scratchpad.toggle", params)
+}
+
+type TermcolorsAPI struct{ c *Client }
+
+func (c *Client) Termcolors() TermcolorsAPI { return TermcolorsAPI{c} }
+
+func (t TermcolorsAPI) GetState(ctx context.Context) (TermcolorsState, error) {
+	return call[TermcolorsState](ctx, t.c, "termcolors.getState", nil)
+}
+
+// Apply recolours the shells open in the terminals allowed by
+// termcolors.terminals in daemon.toml
+func (t TermcolorsAPI) Apply(ctx context.Context, palette TermcolorsPalette) (TermcolorsResult, error) {
+	params := map[string]any{"colors": palette.Colors}
+	if palette.Foreground != "" {
+		params["foreground"] = palette.Foreground
+	}
+	if palette.Background != "" {
+		params["background"] = palette.Background
+	}
+	if palette.Cursor != "" {
+		params["cursor"] = palette.Cursor
+	}
+	return call[TermcolorsResult](ctx, t.c, "termcolors.apply", params)
+}
+
+type ThermalAPI struct{ c *Client }
+
+func (c *Client) Thermal() ThermalAPI { return ThermalAPI{c} }
+
+func (t ThermalAPI) GetState(ctx context.Context) (ThermalState, error) {
+	return call[ThermalState](ctx, t.c, "thermal.getState", nil)
+}
+
+// SetProfile switches to one of the profiles listed in GetState
+func (t ThermalAPI) SetProfile(ctx context.Context, profile string) (ThermalState, error) {
+	return call[ThermalState](ctx, t.c, "thermal.setProfile", map[string]any{"profile": profile})
+}
+
+type RemapAPI struct{ c *Client }
+
+func (c *Client) Remap() RemapAPI { return RemapAPI{c} }
+
+func (r RemapAPI) GetState(ctx context.Context) (RemapState, error) {
+	return call[RemapState](ctx, r.c, "remap.getState", nil)
+}
+
+// Set remaps from to to, using keyd key names, and saves it in remaps.toml
+func (r RemapAPI) Set(ctx context.Context, from, to string) (RemapState, error) {
+	return call[RemapState](ctx, r.c, "remap.set", map[string]any{"from": from, "to": to})
+}
+
+func (r RemapAPI) Remove(ctx context.Context, from string) (RemapState, error) {
+	return call[RemapState](ctx, r.c, "remap.remove", map[string]any{"from": from})
+}
+
+func (r RemapAPI) Clear(ctx context.Context) (RemapState, error) {
+	return call[RemapState](ctx, r.c, "remap.clear", nil)
+}
+
+type HealthAPI struct{ c *Client }
+
+func (c *Client) Health() HealthAPI { return HealthAPI{c} }
+
+func (h HealthAPI) GetState(ctx context.Context) (HealthState, error) {
+	return call[HealthState](ctx, h.c, "health.getState", nil)
+}
+
+// Check runs every health check now and retries degraded backends
+func (h HealthAPI) Check(ctx context.Context) (HealthState, error) {
+	return call[HealthState](ctx, h.c, "health.check", nil)
+}
+
+func (h HealthAPI) Subscribe(ctx context.Context) (*Subscription[HealthState], error) {
+	return Subscribe[HealthState](ctx, h.c, "health.subscribe", nil)
+}
+
+type TimersAPI struct{ c *Client }
+
+func (c *Client) Timers() TimersAPI { return TimersAPI{c} }
+
+func (t TimersAPI) List(ctx context.Context) (TimersState, error) {
+	return call[TimersState](ctx, t.c, "timers.list", nil)
+}
+
+func (t TimersAPI) Create(ctx context.Context, seconds int, label string) (Timer, error) {
+	return call[Timer](ctx, t.c, "timers.create", map[string]any{"seconds": seconds, "label": label})
+}
+
+// Alarm rings at "HH:MM" (the next time the clock shows it) or an RFC 3339
+// timestamp
+func (t TimersAPI) Alarm(ctx context.Context, at, label string) (Timer, error) {
+	return call[Timer](ctx, t.c, "timers.alarm", map[string]any{"at": at, "label": label})
+}
+
+// Pomodoro starts a cycle; zero fields in cfg use the server defaults
+func (t TimersAPI) Pomodoro(ctx context.Context, cfg PomodoroConfig, label string) (Timer, error) {
+	params := map[string]any{"label": label}
+	if cfg.Work > 0 {
+		params["work"] = cfg.Work
+	}
+	if cfg.ShortBreak > 0 {
+		params["shortBreak"] = cfg.ShortBreak
+	}
+	if cfg.LongBreak > 0 {
+		params["longBreak"] = cfg.LongBreak
+	}
+	if cfg.Rounds > 0 {
+		params["rounds"] = cfg.Rounds
+	}
+	return call[Timer](ctx, t.c, "timers.pomodoro", params)
+}
+
+func (t TimersAPI) Pause(ctx context.Context, id string) (Timer, error) {
+	return call[Timer](ctx, t.c, "timers.pause", map[string]any{"id": id})
+}
+
+func (t TimersAPI) Resume(ctx context.Context, id string) (Timer, error) {
+	return call[Timer](ctx, t.c, "timers.resume", map[string]any{"id": id})
+}
+
+// Skip moves a pomodoro to its next phase
+func (t TimersAPI) Skip(ctx context.Context, id string) (Timer, error) {
+	return call[Timer](ctx, t.c, "timers.skip", map[string]any{"id": id})
+}
+
+func (t TimersAPI) Cancel(ctx context.Context, id string) error {
+	return t.c.Call(ctx, "timers.cancel", map[string]any{"id": id}, nil)
+}
+
+func (t TimersAPI) Subscribe(ctx context.Context) (*Subscription[TimersState], error) {
+	return Subscribe[TimersState](ctx, t.c, "timers.subscribe", nil)
+}
+
+type CalendarAPI struct{ c *Client }
+
+func (c *Client) Calendar() CalendarAPI { return CalendarAPI{c} }
+
+func (a CalendarAPI) State(ctx context.Context) (CalendarState, error) {
+	return call[CalendarState](ctx, a.c, "calendar.getState", nil)
+}
+
+// Upcoming lists events that have not ended and start within days. A zero
+// limit returns them all; an empty calendar includes every calendar.
+func (a CalendarAPI) Upcoming(ctx context.Context, days, limit int, calendar string) ([]CalendarEvent, error) {
+	params := map[string]any{"days": days}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	if calendar != "" {
+		params["calendar"] = calendar
+	}
+	return call[[]CalendarEvent](ctx, a.c, "calendar.upcoming", params)
+}
+
+// Refresh re-reads calendars.toml and syncs every calendar in the
+// background; subscribers see the result
+func (a CalendarAPI) Refresh(ctx context.Context) error {
+	return a.c.Call(ctx, "calendar.refresh", nil, nil)
+}
+
+func (a CalendarAPI) Subscribe(ctx context.Context) (*Subscription[CalendarState], error) {
+	return Subscribe[CalendarState](ctx, a.c, "calendar.subscribe", nil)
+}
+
+type A11yAPI struct{ c *Client }
+
+func (c *Client) A11y() A11yAPI { return A11yAPI{c} }
+
+func (a A11yAPI) Get(ctx context.Context) (A11yState, error) {
+	return call[A11yState](ctx, a.c, "a11y.get", nil)
+}
+
+// Set changes the settings that are non-nil in update and returns where
+// they were written
+func (a A11yAPI) Set(ctx context.Context, update A11yUpdate) (A11yState, error) {
+	params := map[string]any{}
+	if update.CursorSize != nil {
+		params["cursorSize"] = *update.CursorSize
+	}
+	if update.TextScale != nil {
+		params["textScale"] = *update.TextScale
+	}
+	if update.ReduceMotion != nil {
+		params["reduceMotion"] = *update.ReduceMotion
+	}
+	return call[A11yState](ctx, a.c, "a11y.set", params)
+}
+
+func (a A11yAPI) Subscribe(ctx context.Context) (*Subscription[A11yState], error) {
+	return Subscribe[A11yState](ctx, a.c, "a11y.subscribe", nil)
+}
+
+type AudioAPI struct{ c *Client }
+
+func (c *Client) Audio() AudioAPI { return AudioAPI{c} }
+
+func (a AudioAPI) Streams(ctx context.Context) (AudioState, error) {
+	return call[AudioState](ctx, a.c, "audio.streams.list", nil)
+}
+
+// SetVolume sets a stream's volume, 1 being 100%, and remembers it for the
+// application
+func (a AudioAPI) SetVolume(ctx context.Context, id uint32, volume float64) (AudioState, error) {
+	return call[AudioState](ctx, a.c, "audio.streams.setVolume", map[string]any{"id": id, "volume": volume})
+}
+
+func (a AudioAPI) SetMute(ctx context.Context, id uint32, muted bool) (AudioState, error) {
+	return call[AudioState](ctx, a.c, "audio.streams.setMute", map[string]any{"id": id, "muted": muted})
+}
+
+// SetSink moves a stream to the named output and remembers it for the
+// application
+func (a AudioAPI) SetSink(ctx context.Context, id uint32, sink string) (AudioState, error) {
+	return call[AudioState](ctx, a.c, "audio.streams.setSink", map[string]any{"id": id, "sink": sink})
+}
+
+func (a AudioAPI) Forget(ctx context.Context, app string) (AudioState, error) {
+	return call[AudioState](ctx, a.c, "audio.streams.forget", map[string]any{"app": app})
+}
+
+func (a AudioAPI) Subscribe(ctx context.Context) (*Subscription[AudioState], error) {
+	return Subscribe[AudioState](ctx, a.c, "audio.streams.subscribe", nil)
+}
+
+type WallpaperAPI struct{ c *Client }
+
+func (c *Client) Wallpaper() WallpaperAPI { return WallpaperAPI{c} }
+
+func (w WallpaperAPI) Get(ctx context.Context) (WallpaperState, error) {
+	return call[WallpaperState](ctx, w.c, "wallpaper.get", nil)
+}
+
+// Set shows an image on opts.Output, on every output when it is empty, or
+// across all of them with opts.Span
+func (w WallpaperAPI) Set(ctx context.Context, opts WallpaperSetOptions) (WallpaperState, error) {
+	params := map[string]any{"path": opts.Path}
+	if opts.Output != "" {
+		params["output"] = opts.Output
+	}
+	if opts.Mode != "" {
+		params["mode"] = string(opts.Mode)
+	}
+	if opts.Span {
+		params["span"] = true
+	}
+	return call[WallpaperState](ctx, w.c, "wallpaper.set", params)
+}
+
+// Clear removes output's own wallpaper, or every wallpaper when output is
+// empty
+func (w WallpaperAPI) Clear(ctx context.Context, output string) (WallpaperState, error) {
+	var params map[string]any
+	if output != "" {
+		params = map[string]any{"output": output}
+	}
+	return call[WallpaperState](ctx, w.c, "wallpaper.clear", params)
+}
+
+func (w WallpaperAPI) Subscribe(ctx context.Context) (*Subscription[WallpaperState], error) {
+	return Subscribe[WallpaperState](ctx, w.c, "wallpaper.subscribe", nil)
+}
+
+type SoundsAPI struct{ c *Client }
+
+func (c *Client) Sounds() SoundsAPI { return SoundsAPI{c} }
+
+func (s SoundsAPI) Get(ctx context.Context) (SoundsState, error) {
+	return call[SoundsState](ctx, s.c, "sounds.getState", nil)
+}
+
+// Play starts event's sound and returns without waiting for it. text is
+// spoken afterwards when the event has speak set.
+func (s SoundsAPI) Play(ctx context.Context, event, text string) (SoundsPlayResult, error) {
+	params := map[string]any{"event": event}
+	if text != "" {
+		params["text"] = text
+	}
+	return call[SoundsPlayResult](ctx, s.c, "sounds.play", params)
+}
+
+// SetEvent replaces how event sounds, adding it when new
+func (s SoundsAPI) SetEvent(ctx context.Context, event string, config SoundsEventConfig) (SoundsState, error) {
+	return call[SoundsState](ctx, s.c, "sounds.setEvent", map[string]any{
+		"event":   event,
+		"enabled": config.Enabled,
+		"sound":   config.Sound,
+		"volume":  config.Volume,
+		"duck":    config.Duck,
+		"speak":   config.Speak,
+	})
+}
+
+func (s SoundsAPI) Subscribe(ctx context.Context) (*Subscription[SoundsState], error) {
+	return Subscribe[SoundsState](ctx, s.c, "sounds.subscribe", nil)
+}
+
+type JobsAPI struct{ c *Client }
+
+func (c *Client) Jobs() JobsAPI { return JobsAPI{c} }
+
+func (j JobsAPI) List(ctx context.Context) (JobsState, error) {
+	return call[JobsState](ctx, j.c, "jobs.list", nil)
+}
+
+// Get returns one job, with its result once it succeeded
+func (j JobsAPI) Get(ctx context.Context, id string) (Job, error) {
+	return call[Job](ctx, j.c, "jobs.get", map[string]any{"id": id})
+}
+
+// Cancel drops a queued job or stops a running one
+func (j JobsAPI) Cancel(ctx context.Context, id string) (Job, error) {
+	return call[Job](ctx, j.c, "jobs.cancel", map[string]any{"id": id})
+}
+
+func (j JobsAPI) Subscribe(ctx context.Context) (*Subscription[JobsState], error) {
+	return Subscribe[JobsState](ctx, j.c, "jobs.subscribe", nil)
+}
+
+// AppColorAPI samples the focused window's dominant color. It is
+// experimental and may change.
+type AppColorAPI struct{ c *Client }
+
+func (c *Client) AppColor() AppColorAPI { return AppColorAPI{c} }
+
+func (a AppColorAPI) Get(ctx context.Context) (AppColorState, error) {
+	return call[AppColorState](ctx, a.c, "appcolor.getState", nil)
+}
+
+// Sample captures the focused window now
+func (a AppColorAPI) Sample(ctx context.Context) (AppColorSample, error) {
+	return call[AppColorSample](ctx, a.c, "appcolor.sample", nil)
+}
+
+// Follow turns sampling on every focus change on or off
+func (a AppColorAPI) Follow(ctx context.Context, enabled bool) (AppColorState, error) {
+	return call[AppColorState](ctx, a.c, "appcolor.follow", map[string]any{"enabled": enabled})
+}
+
+func (a AppColorAPI) Subscribe(ctx context.Context) (*Subscription[AppColorState], error) {
+	return Subscribe[AppColorState](ctx, a.c, "appcolor.subscribe", nil)
+}
@@ -0,0 +1,126 @@
+package dmsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Subscription delivers the stream of results for one subscribe request.
+// The server has no unsubscribe call, so closing a subscription only stops
+// local delivery; close the Client to end the stream server-side.
+type Subscription[T any] struct {
+	events chan T
+	done   chan struct{}
+	once   sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// Events yields results until the subscription ends, after which Err
+// explains why
+func (s *Subscription[T]) Events() <-chan T {
+	return s.events
+}
+
+// Err reports why the subscription ended, or nil while it is running or
+// after Close
+func (s *Subscription[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription[T]) Close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *Subscription[T]) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+}
+
+// Subscribe starts a streaming method and decodes each result as T. The
+// stream stops when ctx is cancelled, the subscription is closed or the
+// connection drops.
+func Subscribe[T any](ctx context.Context, c *Client, method string, params map[string]any) (*Subscription[T], error) {
+	id, w, err := c.register(true)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.send(ctx, request{ID: id, Method: method, Params: params}); err != nil {
+		c.unregister(id, w)
+		return nil, err
+	}
+
+	sub := &Subscription[T]{
+		events: make(chan T, 16),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(sub.events)
+		defer c.unregister(id, w)
+
+		for {
+			select {
+			case resp, ok := <-w.ch:
+				if !ok {
+					sub.fail(c.closeErr())
+					return
+				}
+				if resp.Error != "" {
+					sub.fail(&ServerError{Method: method, Message: resp.Error})
+					return
+				}
+				var event T
+				if err := json.Unmarshal(resp.Result, &event); err != nil {
+					sub.fail(fmt.Errorf("failed to decode %s event: %w", method, err))
+					return
+				}
+				select {
+				case sub.events <- event:
+				case <-sub.done:
+					return
+				case <-ctx.Done():
+					sub.fail(ctx.Err())
+					return
+				}
+			case <-sub.done:
+				return
+			case <-ctx.Done():
+				sub.fail(ctx.Err())
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// Event is one message from the combined subscribe stream. Data holds the
+// service's own payload, e.g. a NetworkState for "network".
+type Event struct {
+	Service string          `json:"service"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Decode unmarshals Data into v
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// SubscribeAll streams events from the given services, or all of them when
+// none are named. The first event is the "server" ServerInfo.
+func (c *Client) SubscribeAll(ctx context.Context, services ...string) (*Subscription[Event], error) {
+	var params map[string]any
+	if len(services) > 0 {
+		params = map[string]any{"services": services}
+	}
+	return Subscribe[Event](ctx, c, "subscribe", params)
+}
@@ -0,0 +1,1206 @@
+package dmsclient
+
+import (
+	"io/fs"
+	"time"
+)
+
+// Result types mirror the server's wire format. They are declared here
+// rather than aliased so importing the client does not pull in the daemon
+// and its D-Bus and Wayland dependencies; wire_test.go checks that they
+// still match the server's JSON.
+
+type Capabilities struct {
+	Capabilities []string `json:"capabilities"`
+}
+
+type ServerInfo struct {
+	APIVersion   int           `json:"apiVersion"`
+	Capabilities []string      `json:"capabilities"`
+	CrashCount   int           `json:"crashCount"`
+	Session      string        `json:"session,omitempty"`
+	Seat         string        `json:"seat,omitempty"`
+	Deprecations []Deprecation `json:"deprecations"`
+}
+
+// ConfigReloadResult lists the daemon.toml keys a reload applied and the
+// ones that only take effect after a restart
+type ConfigReloadResult struct {
+	Path            string   `json:"path"`
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restartRequired"`
+	Problems        []string `json:"problems,omitempty"`
+}
+
+// MethodInfo describes an IPC method. Streaming methods answer once with
+// Result and again on every change.
+type MethodInfo struct {
+	Method               string  `json:"method"`
+	Description          string  `json:"description"`
+	Streaming            bool    `json:"streaming,omitempty"`
+	RequiresConfirmation bool    `json:"requiresConfirmation,omitempty"`
+	Params               *Schema `json:"params"`
+	Result               *Schema `json:"result"`
+}
+
+// RecordingStatus describes the IPC traffic recording started with
+// Debug().StartRecording
+type RecordingStatus struct {
+	Active   bool   `json:"active"`
+	Path     string `json:"path,omitempty"`
+	MaxBytes int64  `json:"maxBytes,omitempty"`
+	Written  int64  `json:"written"`
+	Entries  int    `json:"entries"`
+	Rotated  bool   `json:"rotated"`
+}
+
+// SuccessResult is the acknowledgement returned by most action methods
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Value   string `json:"value,omitempty"`
+}
+
+// ConfirmationRequired is returned by safeguarded methods until the call is
+// repeated with the token
+type ConfirmationRequired struct {
+	ConfirmationRequired bool   `json:"confirmationRequired"`
+	Method               string `json:"method"`
+	Token                string `json:"token"`
+	ExpiresIn            int    `json:"expiresIn"`
+}
+
+type NetworkState struct {
+	Backend                string               `json:"backend"`
+	NetworkStatus          NetworkStatus        `json:"networkStatus"`
+	Preference             ConnectionPreference `json:"preference"`
+	EthernetIP             string               `json:"ethernetIP"`
+	EthernetDevice         string               `json:"ethernetDevice"`
+	EthernetConnected      bool                 `json:"ethernetConnected"`
+	EthernetConnectionUuid string               `json:"ethernetConnectionUuid"`
+	WiFiIP                 string               `json:"wifiIP"`
+	WiFiDevice             string               `json:"wifiDevice"`
+	WiFiConnected          bool                 `json:"wifiConnected"`
+	WiFiEnabled            bool                 `json:"wifiEnabled"`
+	WiFiSSID               string               `json:"wifiSSID"`
+	WiFiBSSID              string               `json:"wifiBSSID"`
+	WiFiSignal             uint8                `json:"wifiSignal"`
+	WiFiNetworks           []WiFiNetwork        `json:"wifiNetworks"`
+	WiredConnections       []WiredConnection    `json:"wiredConnections"`
+	VPNProfiles            []VPNProfile         `json:"vpnProfiles"`
+	VPNActive              []VPNActive          `json:"vpnActive"`
+	IsConnecting           bool                 `json:"isConnecting"`
+	ConnectingSSID         string               `json:"connectingSSID"`
+	LastError              string               `json:"lastError"`
+}
+
+type NetworkEvent struct {
+	Type NetworkEventType `json:"type"`
+	Data NetworkState     `json:"data"`
+}
+
+type WiFiNetwork struct {
+	SSID        string `json:"ssid"`
+	BSSID       string `json:"bssid"`
+	Signal      uint8  `json:"signal"`
+	Secured     bool   `json:"secured"`
+	Enterprise  bool   `json:"enterprise"`
+	Connected   bool   `json:"connected"`
+	Saved       bool   `json:"saved"`
+	Autoconnect bool   `json:"autoconnect"`
+	Frequency   uint32 `json:"frequency"`
+	Mode        string `json:"mode"`
+	Rate        uint32 `json:"rate"`
+	Channel     uint32 `json:"channel"`
+}
+
+// WiFiRoaming steers a saved network between its access points. A pinned
+// BSSID locks the connection to one AP; otherwise the band preference and
+// roaming mode decide when the server moves it to a better one.
+type WiFiRoaming struct {
+	SSID    string          `json:"ssid"`
+	BSSID   string          `json:"bssid"`
+	Band    WiFiBand        `json:"band"`
+	Roaming WiFiRoamingMode `json:"roaming"`
+}
+
+// AppUsageReport is per-application traffic counted since Since, when the
+// accounting helper started
+type AppUsageReport struct {
+	Since time.Time  `json:"since"`
+	Apps  []AppUsage `json:"apps"`
+}
+
+// AppUsage is the traffic of one application. Units lists the systemd
+// scopes and services it ran in; Active is false once all of them exited.
+type AppUsage struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Units   []string `json:"units"`
+	PIDs    []int    `json:"pids"`
+	Active  bool     `json:"active"`
+	RxBytes uint64   `json:"rxBytes"`
+	TxBytes uint64   `json:"txBytes"`
+	RxRate  float64  `json:"rxRate"`
+	TxRate  float64  `json:"txRate"`
+}
+
+// SpeedTestResult is one run of network.speedTest. Rates are in Mbit/s and
+// a phase that was skipped or failed is left at zero.
+type SpeedTestResult struct {
+	Endpoint       string    `json:"endpoint"`
+	UploadEndpoint string    `json:"uploadEndpoint,omitempty"`
+	StartedAt      time.Time `json:"startedAt"`
+	LatencyMs      float64   `json:"latencyMs"`
+	DownloadMbps   float64   `json:"downloadMbps"`
+	UploadMbps     float64   `json:"uploadMbps"`
+	DownloadBytes  int64     `json:"downloadBytes"`
+	UploadBytes    int64     `json:"uploadBytes"`
+	Warnings       []string  `json:"warnings,omitempty"`
+}
+
+// LinkHistory is returned by network.linkHistory, oldest sample first
+type LinkHistory struct {
+	IntervalMs int64            `json:"intervalMs"`
+	Samples    []LinkSample     `json:"samples"`
+	SpeedTest  *SpeedTestResult `json:"speedTest,omitempty"`
+}
+
+// LinkSample is one reading of the Wi-Fi link. Retries and failures are
+// counted since the previous sample.
+type LinkSample struct {
+	Time      time.Time `json:"time"`
+	Interface string    `json:"interface"`
+	BSSID     string    `json:"bssid"`
+	Signal    int       `json:"signal"`
+	SignalAvg int       `json:"signalAvg,omitempty"`
+	TxBitrate float64   `json:"txBitrate"`
+	RxBitrate float64   `json:"rxBitrate"`
+	TxRetries uint32    `json:"txRetries"`
+	TxFailed  uint32    `json:"txFailed"`
+}
+
+type NetworkInfo struct {
+	SSID  string        `json:"ssid"`
+	Bands []WiFiNetwork `json:"bands"`
+}
+
+type WiredNetworkInfo struct {
+	UUID   string        `json:"uuid"`
+	IFace  string        `json:"iface"`
+	Driver string        `json:"driver"`
+	HwAddr string        `json:"hwAddr"`
+	Speed  string        `json:"speed"`
+	IPv4   WiredIPConfig `json:"IPv4s"`
+	IPv6   WiredIPConfig `json:"IPv6s"`
+}
+
+type VPNProfile struct {
+	Name        string `json:"name"`
+	UUID        string `json:"uuid"`
+	Type        string `json:"type"`
+	ServiceType string `json:"serviceType"`
+}
+
+type VPNActive struct {
+	Name   string `json:"name"`
+	UUID   string `json:"uuid"`
+	Device string `json:"device,omitempty"`
+	State  string `json:"state,omitempty"`
+	Type   string `json:"type"`
+	Plugin string `json:"serviceType"`
+}
+
+// TetherDevice is a phone that can share its connection. ID is
+// usb:<interface> or bluetooth:<address>; Interface is empty for a
+// Bluetooth phone until it is connected.
+type TetherDevice struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Interface string `json:"interface,omitempty"`
+	Address   string `json:"address,omitempty"`
+	Connected bool   `json:"connected"`
+}
+
+type SessionState struct {
+	SessionID         string `json:"sessionId"`
+	SessionPath       string `json:"sessionPath"`
+	Locked            bool   `json:"locked"`
+	Active            bool   `json:"active"`
+	IdleHint          bool   `json:"idleHint"`
+	IdleSinceHint     uint64 `json:"idleSinceHint"`
+	LockedHint        bool   `json:"lockedHint"`
+	SessionType       string `json:"sessionType"`
+	SessionClass      string `json:"sessionClass"`
+	User              uint32 `json:"user"`
+	UserName          string `json:"userName"`
+	RemoteHost        string `json:"remoteHost"`
+	Service           string `json:"service"`
+	TTY               string `json:"tty"`
+	Display           string `json:"display"`
+	Remote            bool   `json:"remote"`
+	Seat              string `json:"seat"`
+	VTNr              uint32 `json:"vtnr"`
+	PreparingForSleep bool   `json:"preparingForSleep"`
+}
+
+type SessionEvent struct {
+	Type SessionEventType `json:"type"`
+	Data SessionState     `json:"data"`
+}
+
+type FreedesktopState struct {
+	Accounts FreedesktopAccounts `json:"accounts"`
+	Settings FreedesktopSettings `json:"settings"`
+}
+
+type GammaState struct {
+	Config         GammaConfig   `json:"config"`
+	CurrentTemp    int           `json:"currentTemp"`
+	NextTransition time.Time     `json:"nextTransition"`
+	SunriseTime    time.Time     `json:"sunriseTime"`
+	SunsetTime     time.Time     `json:"sunsetTime"`
+	IsDay          bool          `json:"isDay"`
+	Outputs        []GammaOutput `json:"outputs"`
+}
+
+type BluetoothState struct {
+	Powered          bool              `json:"powered"`
+	Discovering      bool              `json:"discovering"`
+	Devices          []BluetoothDevice `json:"devices"`
+	PairedDevices    []BluetoothDevice `json:"pairedDevices"`
+	ConnectedDevices []BluetoothDevice `json:"connectedDevices"`
+}
+
+type BluetoothEvent struct {
+	Type string         `json:"type"`
+	Data BluetoothState `json:"data"`
+}
+
+type Printer struct {
+	Name        string     `json:"name"`
+	URI         string     `json:"uri"`
+	State       string     `json:"state"`
+	StateReason string     `json:"stateReason"`
+	Location    string     `json:"location"`
+	Info        string     `json:"info"`
+	MakeModel   string     `json:"makeModel"`
+	Accepting   bool       `json:"accepting"`
+	Jobs        []PrintJob `json:"jobs"`
+}
+
+// PrintJob is a print job. State is what clients should switch on and StateInfo
+// how to show it; IPPState is the job-state value CUPS reported.
+type PrintJob struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	State       PrintJobState     `json:"state"`
+	StateInfo   PrintJobStateInfo `json:"stateInfo"`
+	IPPState    int               `json:"ippState,omitempty"`
+	Printer     string            `json:"printer"`
+	User        string            `json:"user"`
+	Size        int               `json:"size"`
+	TimeCreated time.Time         `json:"timeCreated"`
+}
+
+// PrintJobState is a job's state as clients see it. The values are the IPP
+// job-state keywords, plus "unknown" for anything else the server reports.
+type PrintJobState string
+
+// PrintJobStateInfo is how a state is shown. Label is the English text, which
+// translations look up by the state rather than by the label; Icon is a
+// freedesktop icon name hint.
+type PrintJobStateInfo struct {
+	Label    string           `json:"label"`
+	Icon     string           `json:"icon"`
+	Severity PrintJobSeverity `json:"severity"`
+}
+
+type PrintResult struct {
+	Success bool `json:"success"`
+	JobID   int  `json:"jobId"`
+}
+
+// CUPSServerSettings mirrors the toggles cupsctl exposes, plus whether
+// cups-browsed adds queues shared by other machines
+type CUPSServerSettings struct {
+	SharePrinters   bool `json:"sharePrinters"`
+	RemoteAny       bool `json:"remoteAny"`
+	RemoteAdmin     bool `json:"remoteAdmin"`
+	UserCancelAny   bool `json:"userCancelAny"`
+	DebugLogging    bool `json:"debugLogging"`
+	BrowseRemote    bool `json:"browseRemote"`
+	BrowseAvailable bool `json:"browseAvailable"`
+}
+
+// CUPSEvent is "state_changed", or "job_failed" with Failure set
+type CUPSEvent struct {
+	Type    string           `json:"type"`
+	Data    CUPSState        `json:"data"`
+	Failure *PrintJobFailure `json:"failure,omitempty"`
+}
+
+// PrinterDevice is a printer the scheduler's backends discovered. Driverless
+// devices speak IPP Everywhere and can be added without a vendor driver.
+type PrinterDevice struct {
+	URI        string `json:"uri"`
+	MakeModel  string `json:"makeModel"`
+	Info       string `json:"info"`
+	Location   string `json:"location"`
+	Class      string `json:"class"`
+	Driverless bool   `json:"driverless"`
+	Configured string `json:"configured,omitempty"`
+}
+
+// AutoAddResult describes the queue cups.autoAdd created
+type AutoAddResult struct {
+	Success   bool   `json:"success"`
+	Name      string `json:"name"`
+	URI       string `json:"uri"`
+	MakeModel string `json:"makeModel"`
+}
+
+// PrintJobFailure is published when a job is aborted or stops on a printer
+// error. Actions are the IPC calls a notification offers as buttons.
+type PrintJobFailure struct {
+	JobID        int               `json:"jobId"`
+	JobName      string            `json:"jobName"`
+	Printer      string            `json:"printer"`
+	State        PrintJobState     `json:"state"`
+	StateInfo    PrintJobStateInfo `json:"stateInfo"`
+	StateReasons []string          `json:"stateReasons,omitempty"`
+	Message      string            `json:"message"`
+	Time         time.Time         `json:"time"`
+	Actions      []PrintJobAction  `json:"actions"`
+}
+
+type DWLState struct {
+	Outputs      map[string]*DWLOutput `json:"outputs"`
+	TagCount     uint32                `json:"tagCount"`
+	Layouts      []string              `json:"layouts"`
+	ActiveOutput string                `json:"activeOutput"`
+}
+
+type BrightnessState struct {
+	Devices []BrightnessDevice `json:"devices"`
+}
+
+type BrightnessDevice struct {
+	Class          BrightnessDeviceClass `json:"class"`
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	Current        int                   `json:"current"`
+	Max            int                   `json:"max"`
+	CurrentPercent int                   `json:"currentPercent"`
+	Backend        string                `json:"backend"`
+}
+
+// DDCCapabilities is a parsed MCCS capabilities string
+type DDCCapabilities struct {
+	Raw         string       `json:"raw"`
+	Type        string       `json:"type,omitempty"`
+	Model       string       `json:"model,omitempty"`
+	MCCSVersion string       `json:"mccsVersion,omitempty"`
+	Commands    []int        `json:"commands,omitempty"`
+	VCP         []VCPFeature `json:"vcp"`
+	Features    DDCFeatures  `json:"features"`
+}
+
+type SensorsState struct {
+	Sensors []Sensor `json:"sensors"`
+}
+
+type Notification struct {
+	AppName string              `json:"appName"`
+	Summary string              `json:"summary"`
+	Body    string              `json:"body"`
+	Urgency NotificationUrgency `json:"urgency"`
+}
+
+// ForwardTarget configures where notifications of one urgency are mirrored.
+// File may be a regular file (appended as JSON lines) or a FIFO.
+type ForwardTarget struct {
+	File     string `json:"file,omitempty"`
+	Terminal bool   `json:"terminal"`
+	Bell     bool   `json:"bell"`
+}
+
+type ForwardConfig struct {
+	Targets map[NotificationUrgency]ForwardTarget `json:"targets"`
+}
+
+type ForwardResult struct {
+	Files     int      `json:"files"`
+	Terminals int      `json:"terminals"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+type Prompt struct {
+	Token     string            `json:"token"`
+	Source    string            `json:"source"`
+	Kind      PromptKind        `json:"kind"`
+	Title     string            `json:"title"`
+	Message   string            `json:"message,omitempty"`
+	Fields    []string          `json:"fields,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	Actions   []string          `json:"actions"`
+	Default   string            `json:"default"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+type PromptEvent struct {
+	Type     string          `json:"type"`
+	Prompt   Prompt          `json:"prompt"`
+	Response *PromptResponse `json:"response,omitempty"`
+}
+
+type LauncherResult struct {
+	App   LauncherApp `json:"app"`
+	Score float64     `json:"score"`
+}
+
+type FrecencyEntry struct {
+	App      LauncherApp `json:"app"`
+	Count    int         `json:"count"`
+	LastUsed time.Time   `json:"lastUsed"`
+	Score    float64     `json:"score"`
+}
+
+type PowerState struct {
+	OnBattery         bool        `json:"onBattery"`
+	HasBattery        bool        `json:"hasBattery"`
+	ActiveProfile     string      `json:"activeProfile"`
+	Profiles          []string    `json:"profiles"`
+	ProfilesAvailable bool        `json:"profilesAvailable"`
+	Policy            PowerPolicy `json:"policy"`
+}
+
+// PowerPolicy decides what happens when the machine is unplugged. Zero values
+// for the brightness cap and refresh rate leave them alone.
+type PowerPolicy struct {
+	Enabled              bool    `json:"enabled"`
+	ACProfile            string  `json:"acProfile"`
+	BatteryProfile       string  `json:"batteryProfile"`
+	BatteryBrightnessCap int     `json:"batteryBrightnessCap"`
+	BatteryRefreshRate   float64 `json:"batteryRefreshRate"`
+}
+
+type PluginInfo struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Category     string   `json:"category,omitempty"`
+	Author       string   `json:"author,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Repo         string   `json:"repo,omitempty"`
+	Path         string   `json:"path,omitempty"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	Compositors  []string `json:"compositors,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Installed    bool     `json:"installed,omitempty"`
+	FirstParty   bool     `json:"firstParty,omitempty"`
+	Note         string   `json:"note,omitempty"`
+	HasUpdate    bool     `json:"hasUpdate,omitempty"`
+}
+
+type WindowRulesState struct {
+	Path      string               `json:"path"`
+	Backend   string               `json:"backend"`
+	Rules     []WindowRule         `json:"rules"`
+	Errors    []string             `json:"errors,omitempty"`
+	Conflicts []WindowRuleConflict `json:"conflicts,omitempty"`
+	Applied   int                  `json:"applied"`
+}
+
+// WindowRulesMatch is what the rules would do to a window
+type WindowRulesMatch struct {
+	Rules     []string             `json:"rules"`
+	Actions   WindowRuleActions    `json:"actions"`
+	Conflicts []WindowRuleConflict `json:"conflicts,omitempty"`
+}
+
+type HealthState struct {
+	Degraded bool            `json:"degraded"`
+	Backends []BackendHealth `json:"backends"`
+	// Changed names the backend whose status change caused this update; it
+	// is empty in snapshots
+	Changed string `json:"changed,omitempty"`
+}
+
+type BackendHealth struct {
+	Name      string        `json:"name"`
+	Status    BackendStatus `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Failures  int           `json:"failures"`
+	LastCheck time.Time     `json:"lastCheck"`
+	NextRetry *time.Time    `json:"nextRetry,omitempty"`
+}
+
+type TimersState struct {
+	Timers []Timer `json:"timers"`
+	// Fired is the timer that just went off; it is only set in the update
+	// sent when it fires
+	Fired *Timer `json:"fired,omitempty"`
+}
+
+// Timer is a countdown, an alarm at a wall clock time or a pomodoro cycle.
+// A paused timer has no EndsAt and keeps what was left in Remaining.
+type Timer struct {
+	ID        string     `json:"id"`
+	Kind      TimerKind  `json:"kind"`
+	Label     string     `json:"label,omitempty"`
+	Duration  int        `json:"duration"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+	Paused    bool       `json:"paused"`
+	Remaining int        `json:"remaining"`
+	CreatedAt time.Time  `json:"createdAt"`
+
+	Phase    PomodoroPhase   `json:"phase,omitempty"`
+	Round    int             `json:"round,omitempty"`
+	Pomodoro *PomodoroConfig `json:"pomodoro,omitempty"`
+}
+
+// PomodoroConfig sets the phase lengths in seconds. A long break replaces
+// the short one after every Rounds work phases.
+type PomodoroConfig struct {
+	Work       int `json:"work"`
+	ShortBreak int `json:"shortBreak"`
+	LongBreak  int `json:"longBreak"`
+	Rounds     int `json:"rounds"`
+}
+
+type CalendarState struct {
+	Path      string                 `json:"path"`
+	Sources   []CalendarSourceStatus `json:"sources"`
+	Events    []CalendarEvent        `json:"events"`
+	Errors    []string               `json:"errors,omitempty"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+// CalendarEvent is a single occurrence. Recurring events are expanded, so each
+// occurrence has its own entry sharing the UID.
+type CalendarEvent struct {
+	ID          string    `json:"id"`
+	UID         string    `json:"uid"`
+	Calendar    string    `json:"calendar"`
+	Color       string    `json:"color,omitempty"`
+	Summary     string    `json:"summary"`
+	Location    string    `json:"location,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	AllDay      bool      `json:"allDay"`
+	Recurring   bool      `json:"recurring,omitempty"`
+}
+
+type ScratchpadState struct {
+	Path    string               `json:"path"`
+	Backend string               `json:"backend"`
+	Pads    []ScratchpadPadState `json:"pads"`
+	Errors  []string             `json:"errors,omitempty"`
+}
+
+type ScratchpadToggleResult struct {
+	Name   string                 `json:"name"`
+	Action ScratchpadToggleAction `json:"action"`
+}
+
+type TermcolorsState struct {
+	// Terminals is the allowlist from termcolors.terminals in daemon.toml;
+	// nothing is recoloured while it is empty
+	Terminals []string           `json:"terminals"`
+	Targets   []TermcolorsTarget `json:"targets"`
+	Last      *TermcolorsResult  `json:"last,omitempty"`
+}
+
+// TermcolorsPalette is what gets sent to terminals: the 16 ANSI colors plus the
+// default foreground, background and cursor as #rrggbb. Empty defaults are
+// left as the terminal has them.
+type TermcolorsPalette struct {
+	Colors     []string `json:"colors"`
+	Foreground string   `json:"foreground,omitempty"`
+	Background string   `json:"background,omitempty"`
+	Cursor     string   `json:"cursor,omitempty"`
+}
+
+type TermcolorsResult struct {
+	Written []TermcolorsTarget `json:"written"`
+	Failed  []string           `json:"failed,omitempty"`
+}
+
+// ThermalState is the active fan/thermal profile and the ones the backend
+// accepts. Profile names are the backend's own, lowercased.
+type ThermalState struct {
+	Backend  string   `json:"backend"`
+	Profiles []string `json:"profiles"`
+	Active   string   `json:"active"`
+}
+
+type RemapState struct {
+	Path    string     `json:"path"`
+	Backend string     `json:"backend"`
+	Remaps  []KeyRemap `json:"remaps"`
+	Applied bool       `json:"applied"`
+	Errors  []string   `json:"errors,omitempty"`
+}
+
+// KeyRemap sends one key as another. Names are keyd's (capslock, esc,
+// leftcontrol, rightalt, compose, ...).
+type KeyRemap struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Line int    `json:"line,omitempty"`
+}
+
+// A11yState is the current settings and where the last change was written.
+// Failed names the places that could not be updated and why.
+type A11yState struct {
+	A11ySettings
+	Applied []string `json:"applied"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+// A11yUpdate changes only the settings that are set
+type A11yUpdate struct {
+	CursorSize   *int
+	TextScale    *float64
+	ReduceMotion *bool
+}
+
+type AudioState struct {
+	Streams []AudioStream         `json:"streams"`
+	Sinks   []AudioSink           `json:"sinks"`
+	Routes  map[string]AudioRoute `json:"routes"`
+}
+
+// AudioStream is one application playing audio, a PulseAudio sink input. App is
+// the name its settings are remembered under: the process binary, or the
+// application name when the client doesn't report one.
+type AudioStream struct {
+	ID     uint32  `json:"id"`
+	App    string  `json:"app"`
+	Name   string  `json:"name"`
+	Media  string  `json:"media,omitempty"`
+	Icon   string  `json:"icon,omitempty"`
+	Sink   string  `json:"sink"`
+	Volume float64 `json:"volume"`
+	Muted  bool    `json:"muted"`
+}
+
+// AudioRoute is what was last chosen for an application, restored whenever one
+// of its streams appears. Unset fields are left to the sound server.
+type AudioRoute struct {
+	Volume *float64 `json:"volume,omitempty"`
+	Muted  *bool    `json:"muted,omitempty"`
+	Sink   string   `json:"sink,omitempty"`
+}
+
+type WallpaperState struct {
+	Config     WallpaperConfig      `json:"config"`
+	Placements []WallpaperPlacement `json:"placements"`
+}
+
+// WallpaperPlacement is what one output draws: the Source part of the image, in
+// image pixels, scaled into Target, in the output's logical coordinates.
+// Target is smaller than the output when the mode leaves borders.
+type WallpaperPlacement struct {
+	Output      string        `json:"output"`
+	Path        string        `json:"path"`
+	Mode        WallpaperMode `json:"mode"`
+	Span        bool          `json:"span"`
+	ImageWidth  int           `json:"imageWidth"`
+	ImageHeight int           `json:"imageHeight"`
+	Source      WallpaperRect `json:"source"`
+	Target      WallpaperRect `json:"target"`
+	// Error is set when the image can no longer be read
+	Error string `json:"error,omitempty"`
+}
+
+// WallpaperMode is how an image is scaled to the area it covers: an output,
+// or the whole layout when spanning
+type WallpaperMode string
+
+// WallpaperSetOptions are the parameters of wallpaper.set. With no Output and
+// no Span the image goes on every output, replacing per-output choices.
+type WallpaperSetOptions struct {
+	Path   string
+	Output string
+	Mode   WallpaperMode
+	Span   bool
+}
+
+type SoundsState struct {
+	Config SoundsConfig `json:"config"`
+	// Player is the program sounds are played with
+	Player string `json:"player"`
+	// Ducking and Speech tell whether pactl and the speech command were
+	// found; events asking for them play without otherwise
+	Ducking bool `json:"ducking"`
+	Speech  bool `json:"speech"`
+	// Playing counts the events playing right now
+	Playing int `json:"playing"`
+}
+
+// SoundsEventConfig is how one event sounds. Sound is a name looked up in the
+// sound theme, such as message-new-instant, or an absolute file path.
+type SoundsEventConfig struct {
+	Enabled bool    `json:"enabled"`
+	Sound   string  `json:"sound,omitempty"`
+	Volume  float64 `json:"volume"`
+	// Duck lowers other playback while the event plays
+	Duck bool `json:"duck,omitempty"`
+	// Speak reads the text sounds.play was given after the sound
+	Speak bool `json:"speak,omitempty"`
+}
+
+// SoundsPlayResult tells what sounds.play started. Played is false when the
+// event is turned off; Path is empty when no sound was found and only
+// speech runs.
+type SoundsPlayResult struct {
+	Event  string `json:"event"`
+	Played bool   `json:"played"`
+	Path   string `json:"path,omitempty"`
+	Spoken bool   `json:"spoken"`
+}
+
+// Job is one background run of an IPC method. Progress goes from 0 to 1
+// and stays at 0 for work that cannot tell how far along it is; Message
+// names the current step. Result is the method's usual response once the
+// job succeeded.
+type Job struct {
+	ID         string     `json:"id"`
+	Method     string     `json:"method"`
+	Status     string     `json:"status"`
+	Progress   float64    `json:"progress"`
+	Message    string     `json:"message,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// JobsState lists the running and recent jobs, oldest first
+type JobsState struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// AppColorState is streamed to subscribers on every sample. While Following,
+// a new sample is taken whenever focus moves to another window.
+type AppColorState struct {
+	Backend   string          `json:"backend"`
+	Following bool            `json:"following"`
+	Current   *AppColorSample `json:"current,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// AppColorSample is the dominant color of a window's pixels. Color is the
+// accent dank16 picks from them, ready to seed a palette with.
+type AppColorSample struct {
+	AppID     string        `json:"appId"`
+	Title     string        `json:"title"`
+	Color     string        `json:"color"`
+	Swatches  []ColorSwatch `json:"swatches"`
+	SampledAt time.Time     `json:"sampledAt"`
+}
+
+type SettingsExport struct {
+	Path  string            `json:"path"`
+	Files []BackupFileEntry `json:"files"`
+}
+
+// SettingsRestore lists what was written and where previous copies were kept
+type SettingsRestore struct {
+	Manifest BackupManifest `json:"manifest"`
+	Restored []string       `json:"restored"`
+	Backups  []string       `json:"backups,omitempty"`
+	Skipped  []string       `json:"skipped,omitempty"`
+}
+
+type NotificationUrgency string
+
+// Deprecation tells a client to migrate off a method before it is removed
+type Deprecation struct {
+	Method      string `json:"method"`
+	Replacement string `json:"replacement,omitempty"`
+	Since       int    `json:"since"`
+	Message     string `json:"message"`
+}
+
+// Schema is a JSON Schema. Named struct types are emitted once under Defs
+// and referenced, which also keeps recursive types finite.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+type NetworkStatus string
+
+type ConnectionPreference string
+
+type WiredConnection struct {
+	Path     string `json:"path"`
+	ID       string `json:"id"`
+	UUID     string `json:"uuid"`
+	Type     string `json:"type"`
+	IsActive bool   `json:"isActive"`
+}
+
+type NetworkEventType string
+
+type WiFiBand string
+
+type WiFiRoamingMode string
+
+type WiredIPConfig struct {
+	IPs     []string `json:"ips"`
+	Gateway string   `json:"gateway"`
+	DNS     string   `json:"dns"`
+}
+
+type SessionEventType string
+
+type FreedesktopAccounts struct {
+	Available     bool   `json:"available"`
+	UserPath      string `json:"userPath"`
+	IconFile      string `json:"iconFile"`
+	RealName      string `json:"realName"`
+	UserName      string `json:"userName"`
+	AccountType   int32  `json:"accountType"`
+	HomeDirectory string `json:"homeDirectory"`
+	Shell         string `json:"shell"`
+	Email         string `json:"email"`
+	Language      string `json:"language"`
+	Location      string `json:"location"`
+	Locked        bool   `json:"locked"`
+	PasswordMode  int32  `json:"passwordMode"`
+	UID           uint64 `json:"uid"`
+}
+
+type FreedesktopSettings struct {
+	Available   bool   `json:"available"`
+	ColorScheme uint32 `json:"colorScheme"`
+}
+
+type GammaConfig struct {
+	Outputs        []string
+	LowTemp        int
+	HighTemp       int
+	Latitude       *float64
+	Longitude      *float64
+	UseIPLocation  bool
+	ManualSunrise  *time.Time
+	ManualSunset   *time.Time
+	ManualDuration *time.Duration
+	Gamma          float64
+	Enabled        bool
+}
+
+// GammaOutput combines wl_output and xdg-output data for one output. Scale is
+// the fractional scale derived from the pixel and logical sizes.
+type GammaOutput struct {
+	Name             string  `json:"name"`
+	Description      string  `json:"description,omitempty"`
+	Make             string  `json:"make,omitempty"`
+	Model            string  `json:"model,omitempty"`
+	PhysicalWidthMM  int32   `json:"physicalWidthMm"`
+	PhysicalHeightMM int32   `json:"physicalHeightMm"`
+	PixelWidth       int32   `json:"pixelWidth"`
+	PixelHeight      int32   `json:"pixelHeight"`
+	RefreshMHz       int32   `json:"refreshMhz"`
+	Transform        int32   `json:"transform"`
+	IntegerScale     int32   `json:"integerScale"`
+	LogicalX         int32   `json:"logicalX"`
+	LogicalY         int32   `json:"logicalY"`
+	LogicalWidth     int32   `json:"logicalWidth"`
+	LogicalHeight    int32   `json:"logicalHeight"`
+	Scale            float64 `json:"scale"`
+	DPI              float64 `json:"dpi"`
+	EffectiveDPI     float64 `json:"effectiveDpi"`
+	SuggestedScale   float64 `json:"suggestedScale"`
+}
+
+type BluetoothDevice struct {
+	Path          string `json:"path"`
+	Address       string `json:"address"`
+	Name          string `json:"name"`
+	Alias         string `json:"alias"`
+	Paired        bool   `json:"paired"`
+	Trusted       bool   `json:"trusted"`
+	Blocked       bool   `json:"blocked"`
+	Connected     bool   `json:"connected"`
+	Class         uint32 `json:"class"`
+	Icon          string `json:"icon"`
+	RSSI          int16  `json:"rssi"`
+	LegacyPairing bool   `json:"legacyPairing"`
+}
+
+// PrintJobSeverity says how much a job's state needs the user's attention
+type PrintJobSeverity string
+
+type CUPSState struct {
+	Printers map[string]*Printer `json:"printers"`
+}
+
+type PrintJobAction struct {
+	ID     string                 `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+type DWLOutput struct {
+	Name         string   `json:"name"`
+	Active       uint32   `json:"active"`
+	Tags         []DWLTag `json:"tags"`
+	Layout       uint32   `json:"layout"`
+	LayoutSymbol string   `json:"layoutSymbol"`
+	Title        string   `json:"title"`
+	AppID        string   `json:"appId"`
+}
+
+type BrightnessDeviceClass string
+
+// VCPFeature is one supported VCP code and, for non-continuous features,
+// its allowed values
+type VCPFeature struct {
+	Code   int    `json:"code"`
+	Name   string `json:"name,omitempty"`
+	Values []int  `json:"values,omitempty"`
+}
+
+// DDCFeatures summarises the VCP codes the API acts on
+type DDCFeatures struct {
+	Brightness  bool       `json:"brightness"`
+	Contrast    bool       `json:"contrast"`
+	InputSelect bool       `json:"inputSelect"`
+	Inputs      []DDCInput `json:"inputs,omitempty"`
+}
+
+type Sensor struct {
+	ID       string     `json:"id"`
+	Chip     string     `json:"chip"`
+	Label    string     `json:"label"`
+	Kind     SensorKind `json:"kind"`
+	Value    float64    `json:"value"`
+	Unit     string     `json:"unit"`
+	Critical float64    `json:"critical,omitempty"`
+	Warning  float64    `json:"warning,omitempty"`
+	Alerting bool       `json:"alerting"`
+}
+
+type PromptKind string
+
+// PromptResponse is the shell's answer. Secrets fill the prompt's Fields;
+// Remember asks the module to keep them, as network secrets are saved to
+// the connection.
+type PromptResponse struct {
+	Action   string            `json:"action"`
+	Value    string            `json:"value,omitempty"`
+	Secrets  map[string]string `json:"secrets,omitempty"`
+	Remember bool              `json:"remember,omitempty"`
+	TimedOut bool              `json:"timedOut,omitempty"`
+}
+
+// LauncherApp is one launchable .desktop entry. ID is the desktop file ID as
+// defined by the desktop entry spec, e.g. "org.gnome.Nautilus.desktop".
+type LauncherApp struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	GenericName string   `json:"genericName,omitempty"`
+	Comment     string   `json:"comment,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	IconPath    string   `json:"iconPath,omitempty"`
+	Exec        string   `json:"exec"`
+	Path        string   `json:"path,omitempty"`
+	Terminal    bool     `json:"terminal"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	File        string   `json:"file"`
+}
+
+// WindowRule matches newly opened windows by app ID and title regexes
+type WindowRule struct {
+	Name  string `json:"name"`
+	AppID string `json:"appId,omitempty"`
+	Title string `json:"title,omitempty"`
+	Line  int    `json:"line"`
+	WindowRuleActions
+}
+
+// WindowRuleConflict is two or more rules setting the same property of a
+// window to different values. The last rule in the file wins, as in the
+// compositors' own configs.
+type WindowRuleConflict struct {
+	Property string   `json:"property"`
+	Rules    []string `json:"rules"`
+	Values   []string `json:"values"`
+	// Window is the app ID that triggered the conflict, empty when the rules
+	// share the same matchers and always collide
+	Window string `json:"window,omitempty"`
+}
+
+// WindowRuleActions are what a rule does to a matching window. Unset fields
+// leave the compositor's own behaviour alone.
+type WindowRuleActions struct {
+	Workspace  string   `json:"workspace,omitempty"`
+	Output     string   `json:"output,omitempty"`
+	Floating   *bool    `json:"floating,omitempty"`
+	Fullscreen *bool    `json:"fullscreen,omitempty"`
+	Maximized  *bool    `json:"maximized,omitempty"`
+	Opacity    *float64 `json:"opacity,omitempty"`
+}
+
+type BackendStatus string
+
+type TimerKind string
+
+type PomodoroPhase string
+
+type CalendarSourceStatus struct {
+	CalendarSource
+	Events   int        `json:"events"`
+	LastSync *time.Time `json:"lastSync,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+type ScratchpadPadState struct {
+	ScratchpadPad
+	Running  bool                `json:"running"`
+	Visible  bool                `json:"visible"`
+	Geometry *ScratchpadGeometry `json:"geometry,omitempty"`
+}
+
+type ScratchpadToggleAction string
+
+// TermcolorsTarget is a shell's pseudo-terminal inside an allowed terminal
+// emulator
+type TermcolorsTarget struct {
+	TTY      string `json:"tty"`
+	Terminal string `json:"terminal"`
+	PID      int    `json:"pid"`
+}
+
+// A11ySettings are the accessibility options the shell's panel controls
+type A11ySettings struct {
+	CursorSize   int     `json:"cursorSize"`
+	TextScale    float64 `json:"textScale"`
+	ReduceMotion bool    `json:"reduceMotion"`
+}
+
+// AudioSink is an output device streams can be routed to
+type AudioSink struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// WallpaperConfig is what the user set. A span overrides everything else;
+// outputs without their own wallpaper show Default.
+type WallpaperConfig struct {
+	Default *WallpaperAssignment           `json:"default,omitempty"`
+	Outputs map[string]WallpaperAssignment `json:"outputs,omitempty"`
+	Span    *WallpaperAssignment           `json:"span,omitempty"`
+}
+
+// WallpaperRect is an area in pixels
+type WallpaperRect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// SoundsConfig is what the user set. Volume scales every event's own volume.
+type SoundsConfig struct {
+	Theme         string                       `json:"theme"`
+	Volume        float64                      `json:"volume"`
+	DuckLevel     float64                      `json:"duckLevel"`
+	SpeechCommand []string                     `json:"speechCommand,omitempty"`
+	Events        map[string]SoundsEventConfig `json:"events"`
+}
+
+// ColorSwatch is one color cluster of an image. Weight is the share of sampled
+// pixels it covers.
+type ColorSwatch struct {
+	Color  string  `json:"color"`
+	Weight float64 `json:"weight"`
+	Chroma float64 `json:"chroma"`
+}
+
+type BackupFileEntry struct {
+	Source string      `json:"source"`
+	Path   string      `json:"path"`
+	Mode   fs.FileMode `json:"mode"`
+	Size   int64       `json:"size"`
+}
+
+type BackupManifest struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Hostname  string            `json:"hostname,omitempty"`
+	Files     []BackupFileEntry `json:"files"`
+}
+
+type DWLTag struct {
+	Tag     uint32 `json:"tag"`
+	State   uint32 `json:"state"`
+	Clients uint32 `json:"clients"`
+	Focused uint32 `json:"focused"`
+}
+
+type DDCInput struct {
+	Value int    `json:"value"`
+	Name  string `json:"name"`
+}
+
+type SensorKind string
+
+// CalendarSource is one [[calendar]] block in calendars.toml. The daemon only
+// ever reads from a source.
+type CalendarSource struct {
+	Name  string             `json:"name"`
+	Kind  CalendarSourceKind `json:"kind"`
+	Path  string             `json:"path,omitempty"`
+	URL   string             `json:"url,omitempty"`
+	Color string             `json:"color,omitempty"`
+}
+
+// ScratchpadPad is a named drop-down window: the command that starts it and
+// the app ID its window is recognised by
+type ScratchpadPad struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	AppID   string `json:"appId"`
+	// Width and Height size the window the first time it is shown, zero
+	// leaves it to the compositor. Later toggles restore the remembered
+	// geometry instead.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	Line   int `json:"line,omitempty"`
+}
+
+// ScratchpadGeometry is a floating window's position and size in logical pixels
+type ScratchpadGeometry struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// WallpaperAssignment is a wallpaper as chosen by the user
+type WallpaperAssignment struct {
+	Path string        `json:"path"`
+	Mode WallpaperMode `json:"mode"`
+}
+
+type CalendarSourceKind string
@@ -0,0 +1,179 @@
+package dmsclient
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/backup"
+	"github.com/AvengeMedia/danklinux/internal/server/a11y"
+	"github.com/AvengeMedia/danklinux/internal/server/appcolor"
+	"github.com/AvengeMedia/danklinux/internal/server/audio"
+	"github.com/AvengeMedia/danklinux/internal/server/bluez"
+	"github.com/AvengeMedia/danklinux/internal/server/brightness"
+	"github.com/AvengeMedia/danklinux/internal/server/calendar"
+	"github.com/AvengeMedia/danklinux/internal/server/cups"
+	"github.com/AvengeMedia/danklinux/internal/server/dwl"
+	"github.com/AvengeMedia/danklinux/internal/server/freedesktop"
+	"github.com/AvengeMedia/danklinux/internal/server/health"
+	"github.com/AvengeMedia/danklinux/internal/server/jobs"
+	"github.com/AvengeMedia/danklinux/internal/server/launcher"
+	"github.com/AvengeMedia/danklinux/internal/server/loginctl"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/AvengeMedia/danklinux/internal/server/notifications"
+	"github.com/AvengeMedia/danklinux/internal/server/plugins"
+	"github.com/AvengeMedia/danklinux/internal/server/power"
+	"github.com/AvengeMedia/danklinux/internal/server/prompts"
+	"github.com/AvengeMedia/danklinux/internal/server/remap"
+	"github.com/AvengeMedia/danklinux/internal/server/rules"
+	"github.com/AvengeMedia/danklinux/internal/server/scratchpad"
+	"github.com/AvengeMedia/danklinux/internal/server/sensors"
+	"github.com/AvengeMedia/danklinux/internal/server/settings"
+	"github.com/AvengeMedia/danklinux/internal/server/sounds"
+	"github.com/AvengeMedia/danklinux/internal/server/termcolors"
+	"github.com/AvengeMedia/danklinux/internal/server/thermal"
+	"github.com/AvengeMedia/danklinux/internal/server/timers"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
+	"github.com/AvengeMedia/danklinux/internal/server/wayland"
+	"github.com/stretchr/testify/assert"
+)
+
+// jsonShape describes the JSON a type encodes to: object keys with the
+// shape of their values, element shapes and the kind of scalars. Type names
+// are left out, so a wire struct and the server type it mirrors have the
+// same shape exactly when they encode alike.
+func jsonShape(t reflect.Type, seen map[reflect.Type]bool) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonShape(t.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		return "[" + jsonShape(t.Elem(), seen) + "]"
+	case reflect.Map:
+		return "map[" + t.Key().Kind().String() + "]" + jsonShape(t.Elem(), seen)
+	case reflect.Struct:
+		if t.PkgPath() == "time" {
+			return t.String()
+		}
+		if seen[t] {
+			return "recursive"
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		var fields []string
+		for _, f := range reflect.VisibleFields(t) {
+			if !f.IsExported() || f.Anonymous {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fields = append(fields, fmt.Sprintf("%s%s:%s", name, opts, jsonShape(f.Type, seen)))
+		}
+		sort.Strings(fields)
+		return "{" + strings.Join(fields, " ") + "}"
+	default:
+		return t.Kind().String()
+	}
+}
+
+func TestWireTypesMatchServer(t *testing.T) {
+	pairs := []struct{ client, server any }{
+		{(*NetworkState)(nil), (*network.NetworkState)(nil)},
+		{(*NetworkEvent)(nil), (*network.NetworkEvent)(nil)},
+		{(*WiFiNetwork)(nil), (*network.WiFiNetwork)(nil)},
+		{(*WiFiRoaming)(nil), (*network.WiFiRoaming)(nil)},
+		{(*AppUsageReport)(nil), (*network.AppUsageReport)(nil)},
+		{(*AppUsage)(nil), (*network.AppUsage)(nil)},
+		{(*SpeedTestResult)(nil), (*network.SpeedTestResult)(nil)},
+		{(*LinkHistory)(nil), (*network.LinkHistory)(nil)},
+		{(*LinkSample)(nil), (*network.LinkSample)(nil)},
+		{(*NetworkInfo)(nil), (*network.NetworkInfoResponse)(nil)},
+		{(*WiredNetworkInfo)(nil), (*network.WiredNetworkInfoResponse)(nil)},
+		{(*VPNProfile)(nil), (*network.VPNProfile)(nil)},
+		{(*VPNActive)(nil), (*network.VPNActive)(nil)},
+		{(*TetherDevice)(nil), (*network.TetherDevice)(nil)},
+		{(*SessionState)(nil), (*loginctl.SessionState)(nil)},
+		{(*SessionEvent)(nil), (*loginctl.SessionEvent)(nil)},
+		{(*FreedesktopState)(nil), (*freedesktop.FreedeskState)(nil)},
+		{(*GammaState)(nil), (*wayland.State)(nil)},
+		{(*BluetoothState)(nil), (*bluez.BluetoothState)(nil)},
+		{(*BluetoothEvent)(nil), (*bluez.BluetoothEvent)(nil)},
+		{(*Printer)(nil), (*cups.Printer)(nil)},
+		{(*PrintJob)(nil), (*cups.Job)(nil)},
+		{(*PrintJobState)(nil), (*cups.JobState)(nil)},
+		{(*PrintJobStateInfo)(nil), (*cups.JobStateInfo)(nil)},
+		{(*PrintResult)(nil), (*cups.PrintResult)(nil)},
+		{(*CUPSServerSettings)(nil), (*cups.ServerSettings)(nil)},
+		{(*CUPSEvent)(nil), (*cups.CUPSEvent)(nil)},
+		{(*PrinterDevice)(nil), (*cups.Device)(nil)},
+		{(*AutoAddResult)(nil), (*cups.AutoAddResult)(nil)},
+		{(*PrintJobFailure)(nil), (*cups.JobFailure)(nil)},
+		{(*DWLState)(nil), (*dwl.State)(nil)},
+		{(*BrightnessState)(nil), (*brightness.State)(nil)},
+		{(*BrightnessDevice)(nil), (*brightness.Device)(nil)},
+		{(*DDCCapabilities)(nil), (*brightness.DDCCapabilities)(nil)},
+		{(*SensorsState)(nil), (*sensors.State)(nil)},
+		{(*Notification)(nil), (*notifications.Notification)(nil)},
+		{(*ForwardTarget)(nil), (*notifications.ForwardTarget)(nil)},
+		{(*ForwardConfig)(nil), (*notifications.ForwardConfig)(nil)},
+		{(*ForwardResult)(nil), (*notifications.ForwardResult)(nil)},
+		{(*Prompt)(nil), (*prompts.Prompt)(nil)},
+		{(*PromptEvent)(nil), (*prompts.Event)(nil)},
+		{(*LauncherResult)(nil), (*launcher.SearchResult)(nil)},
+		{(*FrecencyEntry)(nil), (*launcher.FrecencyEntry)(nil)},
+		{(*PowerState)(nil), (*power.State)(nil)},
+		{(*PowerPolicy)(nil), (*power.Policy)(nil)},
+		{(*PluginInfo)(nil), (*plugins.PluginInfo)(nil)},
+		{(*WindowRulesState)(nil), (*rules.State)(nil)},
+		{(*WindowRulesMatch)(nil), (*rules.MatchResult)(nil)},
+		{(*HealthState)(nil), (*health.State)(nil)},
+		{(*BackendHealth)(nil), (*health.BackendHealth)(nil)},
+		{(*TimersState)(nil), (*timers.State)(nil)},
+		{(*Timer)(nil), (*timers.Timer)(nil)},
+		{(*PomodoroConfig)(nil), (*timers.PomodoroConfig)(nil)},
+		{(*CalendarState)(nil), (*calendar.State)(nil)},
+		{(*CalendarEvent)(nil), (*calendar.Event)(nil)},
+		{(*ScratchpadState)(nil), (*scratchpad.State)(nil)},
+		{(*ScratchpadToggleResult)(nil), (*scratchpad.ToggleResult)(nil)},
+		{(*TermcolorsState)(nil), (*termcolors.State)(nil)},
+		{(*TermcolorsPalette)(nil), (*termcolors.Palette)(nil)},
+		{(*TermcolorsResult)(nil), (*termcolors.BroadcastResult)(nil)},
+		{(*ThermalState)(nil), (*thermal.State)(nil)},
+		{(*RemapState)(nil), (*remap.State)(nil)},
+		{(*KeyRemap)(nil), (*remap.Remap)(nil)},
+		{(*A11yState)(nil), (*a11y.State)(nil)},
+		{(*A11yUpdate)(nil), (*a11y.Update)(nil)},
+		{(*AudioState)(nil), (*audio.State)(nil)},
+		{(*AudioStream)(nil), (*audio.Stream)(nil)},
+		{(*AudioRoute)(nil), (*audio.Route)(nil)},
+		{(*WallpaperState)(nil), (*wallpaper.State)(nil)},
+		{(*WallpaperPlacement)(nil), (*wallpaper.Placement)(nil)},
+		{(*WallpaperMode)(nil), (*wallpaper.Mode)(nil)},
+		{(*WallpaperSetOptions)(nil), (*wallpaper.SetOptions)(nil)},
+		{(*SoundsState)(nil), (*sounds.State)(nil)},
+		{(*SoundsEventConfig)(nil), (*sounds.EventConfig)(nil)},
+		{(*SoundsPlayResult)(nil), (*sounds.PlayResult)(nil)},
+		{(*Job)(nil), (*jobs.Job)(nil)},
+		{(*JobsState)(nil), (*jobs.State)(nil)},
+		{(*AppColorState)(nil), (*appcolor.State)(nil)},
+		{(*AppColorSample)(nil), (*appcolor.Sample)(nil)},
+		{(*SettingsExport)(nil), (*settings.ExportResult)(nil)},
+		{(*SettingsRestore)(nil), (*backup.RestoreResult)(nil)},
+		{(*NotificationUrgency)(nil), (*notifications.Urgency)(nil)},
+		{(*Deprecation)(nil), (*models.Deprecation)(nil)},
+		{(*Schema)(nil), (*models.Schema)(nil)},
+	}
+	for _, p := range pairs {
+		client, server := reflect.TypeOf(p.client).Elem(), reflect.TypeOf(p.server).Elem()
+		assert.Equal(t, jsonShape(server, map[reflect.Type]bool{}), jsonShape(client, map[reflect.Type]bool{}),
+			"%s no longer matches %s", client.Name(), server)
+	}
+}
@@ -445,5 +445,7 @@ var (
 		"notify-sequence-numbers": TagInteger,
 		"notify-wait":             TagBoolean,
 		"notify-recipient-uri":    TagUri,
+		"notify-user-data":        TagString,
+		"my-subscriptions":        TagBoolean,
 	}
 )